@@ -26,7 +26,7 @@ type Config struct {
 	// Common settings
 	Mode     string `env:"MODE" validate:"required,oneof=dev prod test" default:"dev"`
 	LogLevel string `env:"LOG_LEVEL" validate:"omitempty,log_level" default:"info"`
-	Timezone string `env:"TZ" validate:"required" default:"UTC"`
+	Timezone string `env:"TZ" validate:"required,timezone" default:"UTC"`
 
 	// Redis configuration
 	RedisHost     string `env:"REDIS_HOST" validate:"required" default:"redis"`
@@ -37,6 +37,9 @@ type Config struct {
 	// Queue configuration
 	QueueConcurrency int `env:"QUEUE_CONCURRENCY" validate:"min=1" default:"128"`
 
+	// Wait within which the worker count recommended by the queue pressure endpoint clears the backlog
+	QueuePressureTargetWait time.Duration `env:"QUEUE_PRESSURE_TARGET_WAIT" default:"30s"`
+
 	// Producer configuration (for push endpoint)
 	ProducerConcurrency int `env:"PRODUCER_CONCURRENCY" validate:"min=1,max=128" default:"10"`
 
@@ -45,6 +48,30 @@ type Config struct {
 	BruteforceWindow      time.Duration `env:"BRUTEFORCE_WINDOW" default:"1m"`
 	BruteforceLockout     time.Duration `env:"BRUTEFORCE_LOCKOUT" default:"1m"`
 
+	// External identity provider tokens validated against a JWKS
+	JWKSURL        string        `env:"JWKS_URL" validate:"omitempty,url" default:""`
+	JWKSIssuer     string        `env:"JWKS_ISSUER" default:""`
+	JWKSAudience   string        `env:"JWKS_AUDIENCE" default:""`
+	JWKSEmailClaim string        `env:"JWKS_EMAIL_CLAIM" default:"email"`
+	JWKSRoleClaim  string        `env:"JWKS_ROLE_CLAIM" default:"roles"`
+	JWKSAdminRoles string        `env:"JWKS_ADMIN_ROLES" default:""`
+	JWKSCacheTTL   time.Duration `env:"JWKS_CACHE_TTL" default:"1h"`
+
+	// Audit log of monitor, notification channel and maintenance changes
+	AuditLogEnabled bool `env:"AUDIT_LOG_ENABLED" default:"true"`
+
+	// Prometheus metrics, served on GET /metrics at the API port
+	MetricsEnabled bool `env:"METRICS_ENABLED" default:"false"`
+
+	// Log and record notifications instead of sending them
+	NotificationTestMode bool `env:"NOTIFICATION_TEST_MODE" default:"false"`
+
+	// Periodic reachability check of proxies, 0 disables it
+	ProxyHealthCheckInterval time.Duration `env:"PROXY_HEALTH_CHECK_INTERVAL" default:"1m"`
+
+	// Batch websocket heartbeat broadcasts per room, 0 disables batching
+	WSHeartbeatBatchInterval time.Duration `env:"WS_HEARTBEAT_BATCH_INTERVAL" default:"0s"`
+
 	ServiceName string `env:"SERVICE_NAME" validate:"required,min=1" default:"peekaping:api"`
 }
 
@@ -97,6 +124,26 @@ func Validate(cfg *Config) error {
 		return fmt.Errorf("BRUTEFORCE_LOCKOUT must be a positive duration")
 	}
 
+	if cfg.WSHeartbeatBatchInterval < 0 {
+		return fmt.Errorf("WS_HEARTBEAT_BATCH_INTERVAL must not be negative")
+	}
+	if cfg.QueuePressureTargetWait <= 0 {
+		return fmt.Errorf("QUEUE_PRESSURE_TARGET_WAIT must be a positive duration")
+	}
+	if cfg.ProxyHealthCheckInterval < 0 {
+		return fmt.Errorf("PROXY_HEALTH_CHECK_INTERVAL must not be negative")
+	}
+
+	// Validate external token settings
+	if cfg.JWKSURL != "" {
+		if cfg.JWKSIssuer == "" || cfg.JWKSAudience == "" {
+			return fmt.Errorf("JWKS_ISSUER and JWKS_AUDIENCE are required when JWKS_URL is set")
+		}
+		if cfg.JWKSCacheTTL <= 0 {
+			return fmt.Errorf("JWKS_CACHE_TTL must be a positive duration")
+		}
+	}
+
 	return nil
 }
 
@@ -104,26 +151,39 @@ func Validate(cfg *Config) error {
 // This is needed for backward compatibility with existing code
 func (c *Config) ToInternalConfig() *config.Config {
 	return &config.Config{
-		Port:                  c.Port,
-		ClientURL:             c.ClientURL,
-		DBHost:                c.DBHost,
-		DBPort:                c.DBPort,
-		DBName:                c.DBName,
-		DBUser:                c.DBUser,
-		DBPass:                c.DBPass,
-		DBType:                c.DBType,
-		Mode:                  c.Mode,
-		LogLevel:              c.LogLevel,
-		Timezone:              c.Timezone,
-		RedisHost:             c.RedisHost,
-		RedisPort:             c.RedisPort,
-		RedisPassword:         c.RedisPassword,
-		RedisDB:               c.RedisDB,
-		QueueConcurrency:      c.QueueConcurrency,
-		ProducerConcurrency:   c.ProducerConcurrency,
-		BruteforceMaxAttempts: c.BruteforceMaxAttempts,
-		BruteforceWindow:      c.BruteforceWindow,
-		BruteforceLockout:     c.BruteforceLockout,
-		ServiceName:           c.ServiceName,
+		Port:                     c.Port,
+		ClientURL:                c.ClientURL,
+		DBHost:                   c.DBHost,
+		DBPort:                   c.DBPort,
+		DBName:                   c.DBName,
+		DBUser:                   c.DBUser,
+		DBPass:                   c.DBPass,
+		DBType:                   c.DBType,
+		Mode:                     c.Mode,
+		LogLevel:                 c.LogLevel,
+		Timezone:                 c.Timezone,
+		RedisHost:                c.RedisHost,
+		RedisPort:                c.RedisPort,
+		RedisPassword:            c.RedisPassword,
+		RedisDB:                  c.RedisDB,
+		QueueConcurrency:         c.QueueConcurrency,
+		QueuePressureTargetWait:  c.QueuePressureTargetWait,
+		ProducerConcurrency:      c.ProducerConcurrency,
+		BruteforceMaxAttempts:    c.BruteforceMaxAttempts,
+		BruteforceWindow:         c.BruteforceWindow,
+		BruteforceLockout:        c.BruteforceLockout,
+		JWKSURL:                  c.JWKSURL,
+		JWKSIssuer:               c.JWKSIssuer,
+		JWKSAudience:             c.JWKSAudience,
+		JWKSEmailClaim:           c.JWKSEmailClaim,
+		JWKSRoleClaim:            c.JWKSRoleClaim,
+		JWKSAdminRoles:           c.JWKSAdminRoles,
+		JWKSCacheTTL:             c.JWKSCacheTTL,
+		AuditLogEnabled:          c.AuditLogEnabled,
+		NotificationTestMode:     c.NotificationTestMode,
+		MetricsEnabled:           c.MetricsEnabled,
+		WSHeartbeatBatchInterval: c.WSHeartbeatBatchInterval,
+		ProxyHealthCheckInterval: c.ProxyHealthCheckInterval,
+		ServiceName:              c.ServiceName,
 	}
 }
@@ -11,7 +11,9 @@ import (
 	"peekaping/internal/config"
 	"peekaping/internal/infra"
 	"peekaping/internal/modules/api_key"
+	"peekaping/internal/modules/audit_log"
 	"peekaping/internal/modules/auth"
+	"peekaping/internal/modules/backup"
 	"peekaping/internal/modules/badge"
 	"peekaping/internal/modules/bruteforce"
 	"peekaping/internal/modules/certificate"
@@ -21,6 +23,8 @@ import (
 	"peekaping/internal/modules/healthcheck"
 	"peekaping/internal/modules/heartbeat"
 	"peekaping/internal/modules/maintenance"
+	"peekaping/internal/modules/maintenance_template"
+	"peekaping/internal/modules/metrics"
 	"peekaping/internal/modules/middleware"
 	"peekaping/internal/modules/monitor"
 	"peekaping/internal/modules/monitor_maintenance"
@@ -35,12 +39,15 @@ import (
 	"peekaping/internal/modules/setting"
 	"peekaping/internal/modules/stats"
 	"peekaping/internal/modules/status_page"
+	"peekaping/internal/modules/status_page_subscriber"
 	"peekaping/internal/modules/tag"
+	"peekaping/internal/modules/uptime_kuma"
 	"peekaping/internal/modules/websocket"
 	"peekaping/internal/utils"
 	"peekaping/internal/version"
 	"syscall"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/dig"
 	"go.uber.org/zap"
 )
@@ -66,7 +73,9 @@ func main() {
 		log.Fatalf("Failed to load and validate API config: %v", err)
 	}
 
-	os.Setenv("TZ", cfg.Timezone)
+	if err := config.ApplyTimezone(cfg.Timezone); err != nil {
+		log.Fatalf("Failed to apply timezone: %v", err)
+	}
 
 	container := dig.New()
 
@@ -98,6 +107,10 @@ func main() {
 	container.Provide(infra.ProvideAsynqInspector)
 	container.Provide(infra.ProvideQueueService)
 
+	// Provide the server and scheduler delivering delayed notifications
+	container.Provide(infra.ProvideNotificationAsynqServer)
+	container.Provide(infra.ProvideAsynqScheduler)
+
 	// Register dependencies in the correct order to handle circular dependencies
 	heartbeat.RegisterDependencies(container, internalCfg)
 	monitor.RegisterDependencies(container, internalCfg)
@@ -114,7 +127,9 @@ func main() {
 	stats.RegisterDependencies(container, internalCfg)
 	monitor_maintenance.RegisterDependencies(container, internalCfg)
 	maintenance.RegisterDependencies(container, internalCfg)
+	maintenance_template.RegisterDependencies(container, internalCfg)
 	status_page.RegisterDependencies(container, internalCfg)
+	status_page_subscriber.RegisterDependencies(container, internalCfg)
 	monitor_status_page.RegisterDependencies(container, internalCfg)
 	domain_status_page.RegisterDependencies(container, internalCfg)
 	tag.RegisterDependencies(container, internalCfg)
@@ -122,7 +137,21 @@ func main() {
 	badge.RegisterDependencies(container, internalCfg)
 	queue.RegisterDependencies(container, internalCfg)
 	api_key.RegisterDependencies(container, internalCfg)
+	audit_log.RegisterDependencies(container, internalCfg)
+	uptime_kuma.RegisterDependencies(container)
+	backup.RegisterDependencies(container)
 	middleware.RegisterDependencies(container)
+	metrics.RegisterDependencies(container)
+
+	// Collect monitor, queue and scheduling metrics for GET /metrics
+	if internalCfg.MetricsEnabled {
+		err = container.Invoke(func(collector *metrics.Collector) {
+			prometheus.MustRegister(collector)
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
 
 	// Start the event healthcheck listener
 	err = container.Invoke(func(listener *healthcheck.EventListener, eventBus events.EventBus) {
@@ -139,9 +168,18 @@ func main() {
 		settingService setting.Service,
 		notificationHistoryService notification_sent_history.Service,
 		tlsInfoService monitor_tls_info.Service,
+		auditLogService audit_log.Service,
 		logger *zap.SugaredLogger,
 	) {
-		cleanup.StartCleanupCron(heartbeatService, settingService, notificationHistoryService, tlsInfoService, logger)
+		cleanup.StartCleanupCron(heartbeatService, settingService, notificationHistoryService, tlsInfoService, auditLogService, logger)
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Start periodic proxy health checks
+	err = container.Invoke(func(healthChecker *proxy.HealthChecker) {
+		healthChecker.Start(context.Background())
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -174,6 +212,22 @@ func main() {
 		log.Fatal(err)
 	}
 
+	// Deliver the notifications delayed by business hours, grouping and digests
+	err = container.Invoke(func(w *notification_channel.NotificationWorker) error {
+		return w.Start(context.Background())
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Notify status page subscribers of status changes
+	err = container.Invoke(func(listener *status_page_subscriber.EventListener, eventBus events.EventBus) {
+		listener.Subscribe(eventBus)
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	// Start the monitor event listener
 	err = container.Invoke(func(listener *monitor.MonitorEventListener, eventBus events.EventBus) {
 		listener.Subscribe(eventBus)
@@ -186,6 +240,7 @@ func main() {
 	err = container.Invoke(func(
 		server *internal.Server,
 		eventBus events.EventBus,
+		notificationWorker *notification_channel.NotificationWorker,
 		logger *zap.SugaredLogger,
 	) error {
 		docs.SwaggerInfo.Host = "localhost:" + server.Cfg.Port
@@ -213,6 +268,8 @@ func main() {
 		// Wait for shutdown signal
 		<-sigChan
 		logger.Info("Shutdown signal received, starting graceful shutdown...")
+		notificationWorker.Stop()
+
 		// Close event bus
 		if err := eventBus.Close(); err != nil {
 			logger.Errorw("Failed to close event bus", "error", err)
@@ -0,0 +1 @@
+ALTER TABLE maintenances DROP COLUMN suppress_checks;
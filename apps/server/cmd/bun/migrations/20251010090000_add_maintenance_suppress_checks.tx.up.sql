@@ -0,0 +1,2 @@
+-- Allow maintenance windows to skip enqueuing checks entirely
+ALTER TABLE maintenances ADD COLUMN suppress_checks BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,2 @@
+ALTER TABLE monitors DROP COLUMN activated_at;
+ALTER TABLE monitors DROP COLUMN warmup_checks;
@@ -0,0 +1,5 @@
+-- Suppress notifications for the first N checks after a monitor is created or activated
+ALTER TABLE monitors ADD COLUMN warmup_checks INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE monitors ADD COLUMN activated_at TIMESTAMPTZ;
+
+UPDATE monitors SET activated_at = created_at WHERE active = TRUE;
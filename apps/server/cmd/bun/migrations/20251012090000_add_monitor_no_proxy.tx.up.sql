@@ -0,0 +1,2 @@
+-- Allow monitors to opt out of the global default proxy
+ALTER TABLE monitors ADD COLUMN no_proxy BOOLEAN NOT NULL DEFAULT FALSE;
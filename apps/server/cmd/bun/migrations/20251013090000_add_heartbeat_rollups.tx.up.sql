@@ -0,0 +1,20 @@
+-- Hourly/daily rollups of compacted heartbeats
+CREATE TABLE IF NOT EXISTS heartbeat_rollups (
+    id UUID PRIMARY KEY,
+    monitor_id UUID NOT NULL,
+    period VARCHAR(16) NOT NULL, -- hourly, daily
+    bucket TIMESTAMPTZ NOT NULL,
+    ping_min DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ping_avg DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ping_max DOUBLE PRECISION NOT NULL DEFAULT 0,
+    up INTEGER NOT NULL DEFAULT 0,
+    down INTEGER NOT NULL DEFAULT 0,
+    pending INTEGER NOT NULL DEFAULT 0,
+    maintenance INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (monitor_id) REFERENCES monitors(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_heartbeat_rollups_monitor_period_bucket ON heartbeat_rollups(monitor_id, period, bucket);
+CREATE INDEX IF NOT EXISTS idx_heartbeat_rollups_bucket ON heartbeat_rollups(bucket);
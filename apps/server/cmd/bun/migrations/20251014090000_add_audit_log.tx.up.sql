@@ -0,0 +1,14 @@
+-- Audit log of create/update/delete on monitors, notification channels and maintenances
+CREATE TABLE IF NOT EXISTS audit_log (
+    id UUID PRIMARY KEY,
+    entity_type VARCHAR(64) NOT NULL, -- monitor, notification_channel, maintenance
+    entity_id VARCHAR(255) NOT NULL,
+    action VARCHAR(16) NOT NULL, -- create, update, delete
+    actor_type VARCHAR(16) NOT NULL, -- user, api_key, system
+    actor_id VARCHAR(255),
+    changes TEXT NOT NULL, -- JSON object of field -> {old, new}
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log(created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log(entity_type, entity_id, created_at);
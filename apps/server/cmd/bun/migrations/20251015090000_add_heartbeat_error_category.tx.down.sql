@@ -0,0 +1 @@
+ALTER TABLE heartbeats DROP COLUMN error_category;
@@ -0,0 +1,2 @@
+-- Classification of failed checks, e.g. port_closed / port_filtered for TCP monitors
+ALTER TABLE heartbeats ADD COLUMN error_category VARCHAR(32) NOT NULL DEFAULT '';
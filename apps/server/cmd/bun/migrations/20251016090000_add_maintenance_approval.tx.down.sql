@@ -0,0 +1,2 @@
+ALTER TABLE maintenances DROP COLUMN approved_by;
+ALTER TABLE maintenances DROP COLUMN approval_status;
@@ -0,0 +1,3 @@
+-- Maintenance windows only take effect once approved, existing windows are approved
+ALTER TABLE maintenances ADD COLUMN approval_status VARCHAR(20) NOT NULL DEFAULT 'approved';
+ALTER TABLE maintenances ADD COLUMN approved_by VARCHAR(255);
@@ -0,0 +1,2 @@
+-- Optional bcrypt hashed password for private status pages
+ALTER TABLE status_pages ADD COLUMN password_hash VARCHAR(255);
@@ -0,0 +1,3 @@
+ALTER TABLE stats DROP COLUMN maintenance_seconds;
+ALTER TABLE stats DROP COLUMN down_seconds;
+ALTER TABLE stats DROP COLUMN up_seconds;
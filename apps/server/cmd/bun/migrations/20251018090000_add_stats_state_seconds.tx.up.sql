@@ -0,0 +1,4 @@
+-- Seconds spent in each state, used for time-weighted uptime
+ALTER TABLE stats ADD COLUMN up_seconds BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE stats ADD COLUMN down_seconds BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE stats ADD COLUMN maintenance_seconds BIGINT NOT NULL DEFAULT 0;
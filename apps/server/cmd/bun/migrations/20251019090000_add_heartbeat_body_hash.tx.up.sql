@@ -0,0 +1,2 @@
+-- Hash of the normalized response body for HTTP monitors with detect_body_change
+ALTER TABLE heartbeats ADD COLUMN body_hash VARCHAR(64) NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+ALTER TABLE monitors DROP COLUMN latency_checks;
+ALTER TABLE monitors DROP COLUMN latency_limit;
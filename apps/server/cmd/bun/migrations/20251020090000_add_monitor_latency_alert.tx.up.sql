@@ -0,0 +1,3 @@
+-- Add sustained high latency alert settings to monitors
+ALTER TABLE monitors ADD COLUMN latency_limit INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE monitors ADD COLUMN latency_checks INTEGER NOT NULL DEFAULT 0;
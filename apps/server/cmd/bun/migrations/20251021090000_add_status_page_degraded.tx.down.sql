@@ -0,0 +1,2 @@
+ALTER TABLE status_pages DROP COLUMN degraded_threshold;
+ALTER TABLE status_pages DROP COLUMN show_degraded;
@@ -0,0 +1,3 @@
+-- Render degraded monitors on status pages and let them affect the overall status
+ALTER TABLE status_pages ADD COLUMN show_degraded BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE status_pages ADD COLUMN degraded_threshold INTEGER NOT NULL DEFAULT 0;
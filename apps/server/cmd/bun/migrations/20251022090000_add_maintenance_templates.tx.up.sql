@@ -0,0 +1,23 @@
+-- Reusable maintenance recurrence templates applied to sets of monitors
+CREATE TABLE IF NOT EXISTS maintenance_templates (
+    id UUID PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    strategy VARCHAR(50) NOT NULL,
+    start_date_time VARCHAR(50),
+    end_date_time VARCHAR(50),
+    start_time VARCHAR(50),
+    end_time VARCHAR(50),
+    weekdays TEXT, -- JSON string for compatibility
+    days_of_month TEXT, -- JSON string for compatibility
+    interval_day INTEGER,
+    cron VARCHAR(255),
+    timezone VARCHAR(100),
+    duration INTEGER,
+    suppress_checks BOOLEAN NOT NULL DEFAULT FALSE,
+    maintenance_id UUID, -- maintenance window created when the template was applied
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (maintenance_id) REFERENCES maintenances(id) ON DELETE SET NULL
+);
@@ -0,0 +1 @@
+ALTER TABLE maintenances DROP COLUMN banner_message;
@@ -0,0 +1,2 @@
+-- Add banner_message to maintenances, shown on status pages while the window is active
+ALTER TABLE maintenances ADD COLUMN banner_message TEXT;
@@ -0,0 +1,2 @@
+ALTER TABLE monitor_notifications DROP COLUMN min_criticality;
+ALTER TABLE monitors DROP COLUMN criticality;
@@ -0,0 +1,3 @@
+-- Add criticality to monitors and the minimum criticality a notification channel is linked with
+ALTER TABLE monitors ADD COLUMN criticality VARCHAR(16) NOT NULL DEFAULT 'medium';
+ALTER TABLE monitor_notifications ADD COLUMN min_criticality VARCHAR(16) NOT NULL DEFAULT '';
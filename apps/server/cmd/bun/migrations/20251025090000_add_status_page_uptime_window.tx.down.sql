@@ -0,0 +1 @@
+ALTER TABLE status_pages DROP COLUMN uptime_window_days;
@@ -0,0 +1,2 @@
+-- Window the overall uptime of a status page is computed over, 0 uses the default
+ALTER TABLE status_pages ADD COLUMN uptime_window_days INTEGER NOT NULL DEFAULT 0;
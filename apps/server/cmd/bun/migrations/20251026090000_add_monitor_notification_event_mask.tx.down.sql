@@ -0,0 +1 @@
+ALTER TABLE monitor_notifications DROP COLUMN event_mask;
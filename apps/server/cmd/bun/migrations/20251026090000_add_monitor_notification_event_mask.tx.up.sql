@@ -0,0 +1,2 @@
+-- Add the bitmask of notification event types a monitor's notification channel is limited to
+ALTER TABLE monitor_notifications ADD COLUMN event_mask INTEGER NOT NULL DEFAULT 0;
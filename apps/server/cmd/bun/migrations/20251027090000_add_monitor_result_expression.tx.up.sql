@@ -0,0 +1,2 @@
+-- Add an optional CEL expression computing the final status of a monitor's checks
+ALTER TABLE monitors ADD COLUMN result_expression TEXT NOT NULL DEFAULT '';
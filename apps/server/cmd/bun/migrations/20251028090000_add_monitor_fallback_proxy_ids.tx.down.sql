@@ -0,0 +1 @@
+ALTER TABLE monitors DROP COLUMN fallback_proxy_ids;
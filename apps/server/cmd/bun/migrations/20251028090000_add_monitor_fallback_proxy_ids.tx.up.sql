@@ -0,0 +1,2 @@
+-- Add ordered fallback proxies, comma separated ids, used while a monitor's proxy is failing
+ALTER TABLE monitors ADD COLUMN fallback_proxy_ids TEXT NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+ALTER TABLE monitor_tls_info DROP COLUMN previous_issuer;
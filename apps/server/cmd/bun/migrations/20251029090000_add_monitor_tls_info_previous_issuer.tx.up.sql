@@ -0,0 +1,2 @@
+-- Keep the issuer a monitor's certificate had before it last changed
+ALTER TABLE monitor_tls_info ADD COLUMN previous_issuer TEXT NOT NULL DEFAULT '';
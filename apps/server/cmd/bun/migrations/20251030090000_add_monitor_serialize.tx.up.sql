@@ -0,0 +1,2 @@
+-- Let monitors skip a check while their previous check is still running
+ALTER TABLE monitors ADD COLUMN serialize BOOLEAN NOT NULL DEFAULT FALSE;
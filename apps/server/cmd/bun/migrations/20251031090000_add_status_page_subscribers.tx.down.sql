@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS status_page_subscribers;
+ALTER TABLE status_pages DROP COLUMN subscriber_channel_id;
+ALTER TABLE status_pages DROP COLUMN allow_subscriptions;
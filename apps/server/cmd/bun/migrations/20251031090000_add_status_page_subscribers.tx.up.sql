@@ -0,0 +1,20 @@
+-- Visitors subscribed to the status changes of a status page by email or webhook
+ALTER TABLE status_pages ADD COLUMN allow_subscriptions BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE status_pages ADD COLUMN subscriber_channel_id VARCHAR(255);
+
+CREATE TABLE IF NOT EXISTS status_page_subscribers (
+    id UUID PRIMARY KEY,
+    status_page_id UUID NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    target VARCHAR(2048) NOT NULL,
+    confirmed BOOLEAN NOT NULL DEFAULT FALSE,
+    confirm_token VARCHAR(64) NOT NULL,
+    unsubscribe_token VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (status_page_id) REFERENCES status_pages(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_status_page_subscribers_page ON status_page_subscribers(status_page_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_status_page_subscribers_confirm_token ON status_page_subscribers(confirm_token);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_status_page_subscribers_unsubscribe_token ON status_page_subscribers(unsubscribe_token);
@@ -0,0 +1,2 @@
+ALTER TABLE monitors DROP COLUMN down_message;
+ALTER TABLE monitors DROP COLUMN up_message;
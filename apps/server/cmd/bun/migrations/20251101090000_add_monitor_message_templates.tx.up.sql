@@ -0,0 +1,3 @@
+-- Add up and down message templates overriding the executor's message to monitors
+ALTER TABLE monitors ADD COLUMN up_message TEXT NOT NULL DEFAULT '';
+ALTER TABLE monitors ADD COLUMN down_message TEXT NOT NULL DEFAULT '';
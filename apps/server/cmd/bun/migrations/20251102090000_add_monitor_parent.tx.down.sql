@@ -0,0 +1,3 @@
+ALTER TABLE monitors DROP COLUMN parent_down_timeout;
+ALTER TABLE monitors DROP COLUMN skip_when_parent_down;
+ALTER TABLE monitors DROP COLUMN parent_id;
@@ -0,0 +1,4 @@
+-- Add the parent a monitor depends on and skipping its checks while the parent is down
+ALTER TABLE monitors ADD COLUMN parent_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE monitors ADD COLUMN skip_when_parent_down BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE monitors ADD COLUMN parent_down_timeout INTEGER NOT NULL DEFAULT 0;
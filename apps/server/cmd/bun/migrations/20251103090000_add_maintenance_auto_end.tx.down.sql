@@ -0,0 +1,2 @@
+ALTER TABLE maintenances DROP COLUMN recovery_checks;
+ALTER TABLE maintenances DROP COLUMN auto_end_on_recovery;
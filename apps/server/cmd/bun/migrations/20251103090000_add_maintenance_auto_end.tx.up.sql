@@ -0,0 +1,3 @@
+-- Let one-shot maintenance windows end once their monitors recover
+ALTER TABLE maintenances ADD COLUMN auto_end_on_recovery BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE maintenances ADD COLUMN recovery_checks INTEGER NOT NULL DEFAULT 0;
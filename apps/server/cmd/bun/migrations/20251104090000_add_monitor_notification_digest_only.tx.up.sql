@@ -0,0 +1,2 @@
+-- Let monitors send their status changes to a notification channel only in its digest
+ALTER TABLE monitor_notifications ADD COLUMN digest_only BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,2 @@
+-- Let a monitor notify a notification channel only once an outage lasted a number of consecutive failed checks
+ALTER TABLE monitor_notifications ADD COLUMN escalate_after INTEGER NOT NULL DEFAULT 0;
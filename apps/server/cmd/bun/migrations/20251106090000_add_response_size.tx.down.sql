@@ -0,0 +1,5 @@
+ALTER TABLE stats DROP COLUMN response_size_count;
+ALTER TABLE stats DROP COLUMN response_size_max;
+ALTER TABLE stats DROP COLUMN response_size_min;
+ALTER TABLE stats DROP COLUMN response_size;
+ALTER TABLE heartbeats DROP COLUMN response_size;
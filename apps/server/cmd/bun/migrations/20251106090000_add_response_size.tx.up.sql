@@ -0,0 +1,7 @@
+-- Size of the decoded response body of HTTP checks, NULL for other monitors
+ALTER TABLE heartbeats ADD COLUMN response_size BIGINT;
+-- Response size per stats bucket, averaged over the heartbeats that reported one
+ALTER TABLE stats ADD COLUMN response_size DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE stats ADD COLUMN response_size_min DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE stats ADD COLUMN response_size_max DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE stats ADD COLUMN response_size_count INTEGER NOT NULL DEFAULT 0;
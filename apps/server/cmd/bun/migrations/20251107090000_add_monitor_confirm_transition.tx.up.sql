@@ -0,0 +1,2 @@
+-- Let monitors confirm a change of state with an immediate second check
+ALTER TABLE monitors ADD COLUMN confirm_transition BOOLEAN NOT NULL DEFAULT FALSE;
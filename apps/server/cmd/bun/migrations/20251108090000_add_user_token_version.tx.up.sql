@@ -0,0 +1,2 @@
+-- Let users revoke the tokens issued to them by bumping a version embedded in each token
+ALTER TABLE users ADD COLUMN token_version INTEGER NOT NULL DEFAULT 0;
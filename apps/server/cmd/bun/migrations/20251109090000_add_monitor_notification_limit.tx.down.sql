@@ -0,0 +1,2 @@
+ALTER TABLE monitor_notifications DROP COLUMN outage_notifications;
+ALTER TABLE monitor_notifications DROP COLUMN notification_limit;
@@ -0,0 +1,3 @@
+-- Cap the down notifications a single outage of a monitor sends to a notification channel, counting those of the current outage
+ALTER TABLE monitor_notifications ADD COLUMN notification_limit INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE monitor_notifications ADD COLUMN outage_notifications INTEGER NOT NULL DEFAULT 0;
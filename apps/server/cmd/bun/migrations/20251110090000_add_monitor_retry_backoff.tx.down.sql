@@ -0,0 +1 @@
+ALTER TABLE monitors DROP COLUMN retry_backoff;
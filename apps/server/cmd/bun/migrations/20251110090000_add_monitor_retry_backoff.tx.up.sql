@@ -0,0 +1,2 @@
+-- Add retry backoff strategy to monitors
+ALTER TABLE monitors ADD COLUMN retry_backoff VARCHAR(16) NOT NULL DEFAULT 'fixed';
@@ -0,0 +1 @@
+ALTER TABLE maintenances DROP COLUMN requested_by;
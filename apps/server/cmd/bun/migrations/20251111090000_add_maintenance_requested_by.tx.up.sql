@@ -0,0 +1,2 @@
+-- The user or API key that created or last edited a maintenance window can't approve it
+ALTER TABLE maintenances ADD COLUMN requested_by VARCHAR(255);
@@ -0,0 +1,19 @@
+CREATE TABLE notification_sent_history_old (
+    id UUID PRIMARY KEY,
+    type VARCHAR(50) NOT NULL,
+    monitor_id VARCHAR(255) NOT NULL,
+    days INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    -- Create unique constraint for business logic
+    UNIQUE(type, monitor_id, days)
+);
+
+INSERT INTO notification_sent_history_old (id, type, monitor_id, days, created_at)
+SELECT id, type, monitor_id, days, created_at FROM notification_sent_history WHERE mode = 'sent';
+
+DROP TABLE notification_sent_history;
+ALTER TABLE notification_sent_history_old RENAME TO notification_sent_history;
+
+CREATE INDEX idx_notification_sent_history_type_monitor ON notification_sent_history(type, monitor_id);
+CREATE INDEX idx_notification_sent_history_created_at ON notification_sent_history(created_at);
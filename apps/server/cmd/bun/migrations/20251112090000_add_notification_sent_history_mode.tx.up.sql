@@ -0,0 +1,25 @@
+-- Test mode keeps every notification it logged instead of sending in the history, with
+-- its channel and rendered message. Only sent thresholds stay unique, so the table is
+-- rebuilt without the unique constraint.
+CREATE TABLE notification_sent_history_new (
+    id UUID PRIMARY KEY,
+    mode VARCHAR(20) NOT NULL DEFAULT 'sent',
+    type VARCHAR(50) NOT NULL,
+    monitor_id VARCHAR(255) NOT NULL,
+    days INTEGER NOT NULL,
+    channel_id VARCHAR(255),
+    message TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO notification_sent_history_new (id, mode, type, monitor_id, days, created_at)
+SELECT id, 'sent', type, monitor_id, days, created_at FROM notification_sent_history;
+
+DROP TABLE notification_sent_history;
+ALTER TABLE notification_sent_history_new RENAME TO notification_sent_history;
+
+-- Create indexes for efficient lookups
+CREATE UNIQUE INDEX idx_notification_sent_history_sent ON notification_sent_history(type, monitor_id, days) WHERE mode = 'sent';
+CREATE INDEX idx_notification_sent_history_type_monitor ON notification_sent_history(type, monitor_id);
+CREATE INDEX idx_notification_sent_history_mode_monitor ON notification_sent_history(mode, monitor_id, created_at);
+CREATE INDEX idx_notification_sent_history_created_at ON notification_sent_history(created_at);
@@ -0,0 +1,2 @@
+-- Confirmation links are resent only after a cooldown
+ALTER TABLE status_page_subscribers ADD COLUMN confirmation_sent_at TIMESTAMP;
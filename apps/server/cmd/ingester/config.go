@@ -21,7 +21,7 @@ type Config struct {
 	// Common settings
 	Mode     string `env:"MODE" validate:"required,oneof=dev prod test" default:"dev"`
 	LogLevel string `env:"LOG_LEVEL" validate:"omitempty,log_level" default:"info"`
-	Timezone string `env:"TZ" validate:"required" default:"UTC"`
+	Timezone string `env:"TZ" validate:"required,timezone" default:"UTC"`
 
 	// Redis configuration
 	RedisHost     string `env:"REDIS_HOST" validate:"required" default:"redis"`
@@ -32,6 +32,18 @@ type Config struct {
 	// Queue configuration
 	QueueConcurrency int `env:"QUEUE_CONCURRENCY" validate:"min=1" default:"128"`
 
+	// Heartbeat webhook configuration
+	HeartbeatWebhookURL      string `env:"HEARTBEAT_WEBHOOK_URL" validate:"omitempty,url" default:""`
+	HeartbeatWebhookMonitors string `env:"HEARTBEAT_WEBHOOK_MONITORS" default:""`
+	HeartbeatWebhookStatuses string `env:"HEARTBEAT_WEBHOOK_STATUSES" default:""`
+
+	// InfluxDB heartbeat mirror configuration
+	InfluxDBURL         string `env:"INFLUXDB_URL" validate:"omitempty,url" default:""`
+	InfluxDBToken       string `env:"INFLUXDB_TOKEN" default:""`
+	InfluxDBOrg         string `env:"INFLUXDB_ORG" default:""`
+	InfluxDBBucket      string `env:"INFLUXDB_BUCKET" default:""`
+	InfluxDBMeasurement string `env:"INFLUXDB_MEASUREMENT" default:"heartbeat"`
+
 	ServiceName string `env:"SERVICE_NAME" validate:"required,min=1" default:"peekaping:ingester"`
 }
 
@@ -100,5 +112,15 @@ func (c *Config) ToInternalConfig() *config.Config {
 		RedisDB:          c.RedisDB,
 		QueueConcurrency: c.QueueConcurrency,
 		ServiceName:      c.ServiceName,
+
+		HeartbeatWebhookURL:      c.HeartbeatWebhookURL,
+		HeartbeatWebhookMonitors: c.HeartbeatWebhookMonitors,
+		HeartbeatWebhookStatuses: c.HeartbeatWebhookStatuses,
+
+		InfluxDBURL:         c.InfluxDBURL,
+		InfluxDBToken:       c.InfluxDBToken,
+		InfluxDBOrg:         c.InfluxDBOrg,
+		InfluxDBBucket:      c.InfluxDBBucket,
+		InfluxDBMeasurement: c.InfluxDBMeasurement,
 	}
 }
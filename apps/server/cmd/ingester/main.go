@@ -14,10 +14,12 @@ import (
 	"peekaping/internal/modules/heartbeat"
 	"peekaping/internal/modules/ingester"
 	"peekaping/internal/modules/monitor_maintenance"
+	"peekaping/internal/modules/monitor_tag"
 	"peekaping/internal/modules/monitor_tls_info"
 	"peekaping/internal/modules/notification_sent_history"
 	"peekaping/internal/modules/setting"
 	"peekaping/internal/modules/stats"
+	"peekaping/internal/modules/tag"
 	"peekaping/internal/version"
 	"syscall"
 
@@ -36,7 +38,9 @@ func main() {
 	}
 
 	// Set timezone
-	os.Setenv("TZ", cfg.Timezone)
+	if err := config.ApplyTimezone(cfg.Timezone); err != nil {
+		log.Fatalf("Failed to apply timezone: %v", err)
+	}
 
 	// Create DI container
 	container := dig.New()
@@ -104,6 +108,8 @@ func main() {
 	monitor_maintenance.RegisterDependencies(container, internalCfg)
 	stats.RegisterDependencies(container, internalCfg)
 	setting.RegisterDependencies(container, internalCfg)
+	monitor_tag.RegisterDependencies(container, internalCfg)
+	tag.RegisterDependencies(container, internalCfg)
 
 	// Register ingester dependencies
 	ingester.RegisterDependencies(container)
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"time"
 
 	"peekaping/internal/config"
 
@@ -21,7 +22,7 @@ type Config struct {
 	// Common settings
 	Mode     string `env:"MODE" validate:"required,oneof=dev prod test" default:"dev"`
 	LogLevel string `env:"LOG_LEVEL" validate:"omitempty,log_level" default:"debug"`
-	Timezone string `env:"TZ" validate:"required" default:"UTC"`
+	Timezone string `env:"TZ" validate:"required,timezone" default:"UTC"`
 
 	// Redis configuration
 	RedisHost     string `env:"REDIS_HOST" validate:"required" default:"redis"`
@@ -30,7 +31,15 @@ type Config struct {
 	RedisDB       int    `env:"REDIS_DB" validate:"min=0,max=15" default:"0"`
 
 	// Producer configuration
-	ProducerConcurrency int `env:"PRODUCER_CONCURRENCY" validate:"min=1,max=128" default:"10"`
+	ProducerConcurrency int           `env:"PRODUCER_CONCURRENCY" validate:"min=1,max=128" default:"10"`
+	ProducerStartupRamp time.Duration `env:"PRODUCER_STARTUP_RAMP" default:"0s"` // spread first checks after gaining leadership, 0 disables
+
+	// Health check queue shards, must match the worker
+	HealthCheckQueueShards int `env:"HEALTHCHECK_QUEUE_SHARDS" validate:"min=1,max=64" default:"1"`
+
+	// Prometheus metrics (scheduling lag of due monitors)
+	MetricsEnabled bool   `env:"METRICS_ENABLED" default:"false"`
+	MetricsPort    string `env:"METRICS_PORT" validate:"omitempty,port" default:"9090"`
 
 	ServiceName string `env:"SERVICE_NAME" validate:"required,min=1" default:"peekaping:producer"`
 }
@@ -73,6 +82,10 @@ func Validate(cfg *Config) error {
 		return fmt.Errorf("database validation failed: %w", err)
 	}
 
+	if cfg.ProducerStartupRamp < 0 {
+		return fmt.Errorf("PRODUCER_STARTUP_RAMP must not be negative")
+	}
+
 	return nil
 }
 
@@ -80,20 +93,22 @@ func Validate(cfg *Config) error {
 // This is needed for backward compatibility with existing code
 func (c *Config) ToInternalConfig() *config.Config {
 	return &config.Config{
-		DBHost:              c.DBHost,
-		DBPort:              c.DBPort,
-		DBName:              c.DBName,
-		DBUser:              c.DBUser,
-		DBPass:              c.DBPass,
-		DBType:              c.DBType,
-		Mode:                c.Mode,
-		LogLevel:            c.LogLevel,
-		Timezone:            c.Timezone,
-		RedisHost:           c.RedisHost,
-		RedisPort:           c.RedisPort,
-		RedisPassword:       c.RedisPassword,
-		RedisDB:             c.RedisDB,
-		ProducerConcurrency: c.ProducerConcurrency,
-		ServiceName:         c.ServiceName,
+		DBHost:                 c.DBHost,
+		DBPort:                 c.DBPort,
+		DBName:                 c.DBName,
+		DBUser:                 c.DBUser,
+		DBPass:                 c.DBPass,
+		DBType:                 c.DBType,
+		Mode:                   c.Mode,
+		LogLevel:               c.LogLevel,
+		Timezone:               c.Timezone,
+		RedisHost:              c.RedisHost,
+		RedisPort:              c.RedisPort,
+		RedisPassword:          c.RedisPassword,
+		RedisDB:                c.RedisDB,
+		ProducerConcurrency:    c.ProducerConcurrency,
+		ProducerStartupRamp:    c.ProducerStartupRamp,
+		HealthCheckQueueShards: c.HealthCheckQueueShards,
+		ServiceName:            c.ServiceName,
 	}
 }
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"peekaping/internal"
@@ -39,7 +40,9 @@ func main() {
 		log.Fatalf("Failed to load and validate Producer config: %v", err)
 	}
 
-	os.Setenv("TZ", cfg.Timezone)
+	if err := config.ApplyTimezone(cfg.Timezone); err != nil {
+		log.Fatalf("Failed to apply timezone: %v", err)
+	}
 
 	container := dig.New()
 
@@ -103,6 +106,11 @@ func main() {
 
 		logger.Info("Producer started successfully")
 
+		var metricsServer *http.Server
+		if cfg.MetricsEnabled {
+			metricsServer = infra.StartMetricsServer(":"+cfg.MetricsPort, logger)
+		}
+
 		// Wait for termination signal
 		sigChan := make(chan os.Signal, 1)
 		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
@@ -113,6 +121,10 @@ func main() {
 		logger.Info("Shutdown signal received, stopping producer...")
 		prod.Stop()
 
+		if metricsServer != nil {
+			infra.StopMetricsServer(metricsServer, logger)
+		}
+
 		// Close event bus
 		if err := eventBus.Close(); err != nil {
 			logger.Errorw("Failed to close event bus", "error", err)
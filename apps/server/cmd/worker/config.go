@@ -2,8 +2,10 @@ package main
 
 import (
 	"fmt"
+	"time"
 
 	"peekaping/internal/config"
+	"peekaping/internal/modules/proxy"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -13,7 +15,7 @@ type Config struct {
 	// Common settings
 	Mode     string `env:"MODE" validate:"required,oneof=dev prod test" default:"dev"`
 	LogLevel string `env:"LOG_LEVEL" validate:"omitempty,log_level" default:"info"`
-	Timezone string `env:"TZ" validate:"required" default:"UTC"`
+	Timezone string `env:"TZ" validate:"required,timezone" default:"UTC"`
 
 	// Redis configuration (required for queue)
 	RedisHost     string `env:"REDIS_HOST" validate:"required" default:"redis"`
@@ -24,6 +26,35 @@ type Config struct {
 	// Queue configuration
 	QueueConcurrency int `env:"QUEUE_CONCURRENCY" validate:"min=1" default:"128"`
 
+	// Health check queue shards, must match the producer
+	HealthCheckQueueShards int `env:"HEALTHCHECK_QUEUE_SHARDS" validate:"min=1,max=64" default:"1"`
+
+	// Fallback proxy for monitors without a proxy of their own
+	DefaultProxyURL string `env:"DEFAULT_PROXY_URL" default:""`
+
+	// One connection pool per proxy for HTTP checks
+	ProxyPoolEnabled      bool          `env:"PROXY_POOL_ENABLED" default:"true"`
+	ProxyPoolMaxIdleConns int           `env:"PROXY_POOL_MAX_IDLE_CONNS" validate:"min=1" default:"4"`
+	ProxyPoolIdleTimeout  time.Duration `env:"PROXY_POOL_IDLE_TIMEOUT" default:"90s"`
+
+	// How long a failed proxy is skipped in favor of a monitor's fallback proxies
+	ProxyFailureCooldown time.Duration `env:"PROXY_FAILURE_COOLDOWN" default:"1m"`
+
+	// Shared DNS cache for HTTP, TCP and ping checks, bounded by the record TTL
+	DNSCacheEnabled bool          `env:"DNS_CACHE_ENABLED" default:"true"`
+	DNSCacheMinTTL  time.Duration `env:"DNS_CACHE_MIN_TTL" default:"5s"`
+	DNSCacheMaxTTL  time.Duration `env:"DNS_CACHE_MAX_TTL" default:"5m"`
+
+	// Share results of identical checks for this long, 0 disables the cache
+	ResultCacheTTL time.Duration `env:"RESULT_CACHE_TTL" default:"0s"`
+
+	// Abandon checks running this long past their timeout, 0 disables the guard
+	ExecutorHardTimeoutGrace time.Duration `env:"EXECUTOR_HARD_TIMEOUT_GRACE" default:"10s"`
+
+	// Prometheus metrics (execution duration and results per executor type)
+	MetricsEnabled bool   `env:"METRICS_ENABLED" default:"false"`
+	MetricsPort    string `env:"METRICS_PORT" validate:"omitempty,port" default:"9090"`
+
 	ServiceName string `env:"SERVICE_NAME" validate:"required,min=1" default:"peekaping:worker"`
 }
 
@@ -56,6 +87,32 @@ func Validate(cfg *Config) error {
 		return fmt.Errorf("QUEUE_CONCURRENCY must be at least 1")
 	}
 
+	if cfg.ExecutorHardTimeoutGrace < 0 {
+		return fmt.Errorf("EXECUTOR_HARD_TIMEOUT_GRACE must not be negative")
+	}
+
+	if cfg.ProxyPoolIdleTimeout < 0 {
+		return fmt.Errorf("PROXY_POOL_IDLE_TIMEOUT must not be negative")
+	}
+
+	if cfg.ProxyFailureCooldown < 0 {
+		return fmt.Errorf("PROXY_FAILURE_COOLDOWN must not be negative")
+	}
+
+	if cfg.ResultCacheTTL < 0 {
+		return fmt.Errorf("RESULT_CACHE_TTL must not be negative")
+	}
+
+	if cfg.DNSCacheMaxTTL < cfg.DNSCacheMinTTL {
+		return fmt.Errorf("DNS_CACHE_MAX_TTL must not be lower than DNS_CACHE_MIN_TTL")
+	}
+
+	if cfg.DefaultProxyURL != "" {
+		if _, err := proxy.ParseURL(cfg.DefaultProxyURL); err != nil {
+			return fmt.Errorf("DEFAULT_PROXY_URL is invalid: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -63,14 +120,27 @@ func Validate(cfg *Config) error {
 // This is needed for backward compatibility with existing code
 func (c *Config) ToInternalConfig() *config.Config {
 	return &config.Config{
-		Mode:             c.Mode,
-		LogLevel:         c.LogLevel,
-		Timezone:         c.Timezone,
-		RedisHost:        c.RedisHost,
-		RedisPort:        c.RedisPort,
-		RedisPassword:    c.RedisPassword,
-		RedisDB:          c.RedisDB,
-		QueueConcurrency: c.QueueConcurrency,
-		ServiceName:      c.ServiceName,
+		Mode:                     c.Mode,
+		LogLevel:                 c.LogLevel,
+		Timezone:                 c.Timezone,
+		RedisHost:                c.RedisHost,
+		RedisPort:                c.RedisPort,
+		RedisPassword:            c.RedisPassword,
+		RedisDB:                  c.RedisDB,
+		QueueConcurrency:         c.QueueConcurrency,
+		HealthCheckQueueShards:   c.HealthCheckQueueShards,
+		DefaultProxyURL:          c.DefaultProxyURL,
+		ProxyPoolEnabled:         c.ProxyPoolEnabled,
+		ProxyPoolMaxIdleConns:    c.ProxyPoolMaxIdleConns,
+		ProxyPoolIdleTimeout:     c.ProxyPoolIdleTimeout,
+		ProxyFailureCooldown:     c.ProxyFailureCooldown,
+		DNSCacheEnabled:          c.DNSCacheEnabled,
+		DNSCacheMinTTL:           c.DNSCacheMinTTL,
+		DNSCacheMaxTTL:           c.DNSCacheMaxTTL,
+		ResultCacheTTL:           c.ResultCacheTTL,
+		ExecutorHardTimeoutGrace: c.ExecutorHardTimeoutGrace,
+		MetricsEnabled:           c.MetricsEnabled,
+		MetricsPort:              c.MetricsPort,
+		ServiceName:              c.ServiceName,
 	}
 }
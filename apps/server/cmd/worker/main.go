@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"peekaping/internal"
@@ -30,7 +31,9 @@ func main() {
 	}
 
 	// Set timezone
-	os.Setenv("TZ", cfg.Timezone)
+	if err := config.ApplyTimezone(cfg.Timezone); err != nil {
+		log.Fatalf("Failed to apply timezone: %v", err)
+	}
 
 	// Create DI container
 	container := dig.New()
@@ -75,6 +78,11 @@ func main() {
 
 		logger.Info("Worker started successfully")
 
+		var metricsServer *http.Server
+		if cfg.MetricsEnabled {
+			metricsServer = infra.StartMetricsServer(":"+cfg.MetricsPort, logger)
+		}
+
 		// Wait for termination signal
 		sigChan := make(chan os.Signal, 1)
 		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
@@ -85,6 +93,10 @@ func main() {
 		logger.Info("Shutdown signal received, stopping worker...")
 		w.Stop()
 
+		if metricsServer != nil {
+			infra.StopMetricsServer(metricsServer, logger)
+		}
+
 		// Close event bus
 		if err := eventBus.Close(); err != nil {
 			logger.Errorw("Failed to close event bus", "error", err)
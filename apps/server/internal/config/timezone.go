@@ -0,0 +1,46 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// location is the server timezone resolved from TZ by ApplyTimezone
+var location atomic.Pointer[time.Location]
+
+// LoadTimezone resolves an IANA timezone name such as "UTC" or "Europe/Berlin"
+func LoadTimezone(name string) (*time.Location, error) {
+	if name == "" {
+		return nil, fmt.Errorf("timezone is empty")
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", name, err)
+	}
+	return loc, nil
+}
+
+// ApplyTimezone makes the configured timezone the server timezone returned by Location.
+// It also becomes time.Local, since Go reads TZ only once and setting it at runtime has
+// no effect on the standard library.
+func ApplyTimezone(name string) error {
+	loc, err := LoadTimezone(name)
+	if err != nil {
+		return err
+	}
+	location.Store(loc)
+	time.Local = loc
+	// Keep TZ in sync for child processes
+	os.Setenv("TZ", name)
+	return nil
+}
+
+// Location returns the server timezone, UTC until ApplyTimezone was called
+func Location() *time.Location {
+	if loc := location.Load(); loc != nil {
+		return loc
+	}
+	return time.UTC
+}
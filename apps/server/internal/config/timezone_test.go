@@ -0,0 +1,57 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadTimezone(t *testing.T) {
+	loc, err := LoadTimezone("Europe/Berlin")
+	require.NoError(t, err)
+	assert.Equal(t, "Europe/Berlin", loc.String())
+
+	_, err = LoadTimezone("Mars/Olympus")
+	assert.ErrorContains(t, err, `invalid timezone "Mars/Olympus"`)
+
+	_, err = LoadTimezone("")
+	assert.Error(t, err)
+}
+
+func TestApplyTimezone(t *testing.T) {
+	local := time.Local
+	t.Cleanup(func() {
+		time.Local = local
+		location.Store(nil)
+	})
+
+	assert.Equal(t, time.UTC, Location())
+
+	require.NoError(t, ApplyTimezone("America/New_York"))
+	assert.Equal(t, "America/New_York", Location().String())
+	assert.Equal(t, "America/New_York", time.Local.String())
+
+	assert.Error(t, ApplyTimezone("Invalid/Timezone"))
+	assert.Equal(t, "America/New_York", Location().String(), "an invalid timezone keeps the current one")
+}
+
+func TestLoadConfig_RejectsInvalidTimezone(t *testing.T) {
+	t.Setenv("DB_NAME", "peekaping")
+	t.Setenv("DB_TYPE", "sqlite")
+
+	t.Run("valid timezone", func(t *testing.T) {
+		t.Setenv("TZ", "Asia/Tokyo")
+		cfg, err := LoadConfig[Config](t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, "Asia/Tokyo", cfg.Timezone)
+	})
+
+	t.Run("invalid timezone", func(t *testing.T) {
+		t.Setenv("TZ", "Europe/Atlantis")
+		_, err := LoadConfig[Config](t.TempDir())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `Timezone must be a valid IANA timezone such as UTC or Europe/Berlin, got "Europe/Atlantis"`)
+	})
+}
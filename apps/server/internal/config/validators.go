@@ -15,6 +15,7 @@ func RegisterCustomValidators(v *validator.Validate) {
 	v.RegisterValidation("port", validatePort)
 	v.RegisterValidation("db_type", validateDBType)
 	v.RegisterValidation("log_level", validateLogLevel)
+	v.RegisterValidation("timezone", validateTimezone)
 }
 
 // validateDurationMin validates that a time.Duration is at least the specified minimum
@@ -86,3 +87,9 @@ func validateLogLevel(fl validator.FieldLevel) bool {
 
 	return false
 }
+
+// validateTimezone validates that a string is a timezone known to the tz database
+func validateTimezone(fl validator.FieldLevel) bool {
+	_, err := LoadTimezone(fl.Field().String())
+	return err == nil
+}
@@ -0,0 +1,42 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+)
+
+// StartMetricsServer serves the default Prometheus registry on GET /metrics at addr.
+// It is used by services without an HTTP API of their own.
+func StartMetricsServer(addr string, logger *zap.SugaredLogger) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", promhttp.Handler())
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		logger.Infow("Serving Prometheus metrics", "addr", addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Errorw("Metrics server failed", "error", err)
+		}
+	}()
+
+	return server
+}
+
+// StopMetricsServer shuts the metrics server down, waiting for in-flight scrapes
+func StopMetricsServer(server *http.Server, logger *zap.SugaredLogger) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		logger.Errorw("Failed to stop metrics server", "error", err)
+	}
+}
@@ -41,6 +41,8 @@ func ProvideAsynqServer(
 		DB:       cfg.RedisDB,
 	}
 
+	queues, strictPriority := workerQueues(cfg.HealthCheckQueueShards)
+
 	// Configure server with appropriate concurrency and queue priorities
 	// Note: Worker only processes healthcheck tasks. Ingester tasks are handled by a separate ingester service.
 	serverCfg := asynq.Config{
@@ -48,12 +50,7 @@ func ProvideAsynqServer(
 		Concurrency: cfg.QueueConcurrency,
 
 		// Queue priorities - higher value means higher priority
-		Queues: map[string]int{
-			"critical":    6, // Highest priority
-			"healthcheck": 5, // High priority for health checks
-			"default":     3, // Medium priority
-			"low":         1, // Lowest priority
-		},
+		Queues: queues,
 
 		// Error handler for logging failed tasks
 		ErrorHandler: asynq.ErrorHandlerFunc(func(ctx context.Context, task *asynq.Task, err error) {
@@ -64,8 +61,8 @@ func ProvideAsynqServer(
 			)
 		}),
 
-		// Enable strict priority mode
-		StrictPriority: true,
+		// Strict priority mode with a single health check queue, see workerQueues
+		StrictPriority: strictPriority,
 
 		// Logger adapter
 		Logger: NewAsynqLogger(logger),
@@ -77,6 +74,71 @@ func ProvideAsynqServer(
 	return server, nil
 }
 
+// notificationConcurrency is the number of notification tasks an API instance sends at once
+const notificationConcurrency = 10
+
+// ProvideNotificationAsynqServer creates the asynq.Server of the API, delivering the
+// notifications that were delayed by business hours, grouping or digests
+func ProvideNotificationAsynqServer(
+	cfg *config.Config,
+	logger *zap.SugaredLogger,
+) (*asynq.Server, error) {
+	redisOpt := asynq.RedisClientOpt{
+		Addr:     fmt.Sprintf("%s:%s", cfg.RedisHost, cfg.RedisPort),
+		Password: cfg.RedisPassword,
+		DB:       cfg.RedisDB,
+	}
+
+	serverCfg := asynq.Config{
+		Concurrency: notificationConcurrency,
+		Queues: map[string]int{
+			queue.NotificationQueue: 1,
+		},
+		ErrorHandler: asynq.ErrorHandlerFunc(func(ctx context.Context, task *asynq.Task, err error) {
+			logger.Errorw("Task processing failed",
+				"type", task.Type(),
+				"payload", string(task.Payload()),
+				"error", err,
+			)
+		}),
+		Logger: NewAsynqLogger(logger),
+	}
+
+	server := asynq.NewServer(redisOpt, serverCfg)
+
+	logger.Info("Successfully created Asynq server for notifications")
+	return server, nil
+}
+
+// workerQueues returns the queues of the worker with their priorities and whether they
+// are served in strict priority order. Strict mode always drains queues of equal
+// priority in the same order, which would starve the later health check shards, so
+// shards are picked by weight instead. Critical then weighs as much as all other queues
+// together: it is tried first on at least every other pick rather than on every one.
+func workerQueues(shards int) (map[string]int, bool) {
+	queues := map[string]int{
+		"default": 3, // Medium priority
+		"low":     1, // Lowest priority
+	}
+	// High priority for health checks, one queue per shard when they are sharded
+	for name, priority := range queue.HealthCheckQueues(shards) {
+		queues[name] = priority
+	}
+
+	strict := shards <= 1
+	critical := 6 // Highest priority
+	if !strict {
+		others := 0
+		for _, priority := range queues {
+			others += priority
+		}
+		critical = max(critical, others)
+	}
+	queues["critical"] = critical
+
+	return queues, strict
+}
+
 // AsynqLogger is an adapter to use zap logger with asynq
 type AsynqLogger struct {
 	logger *zap.SugaredLogger
@@ -399,6 +461,20 @@ func (s *queueServiceImpl) ListScheduledTasks(ctx context.Context, queueName str
 	return convertTaskInfoList(tasks), nil
 }
 
+// ListServers returns the worker servers currently running
+func (s *queueServiceImpl) ListServers(ctx context.Context) ([]*queue.ServerInfo, error) {
+	servers, err := s.inspector.Servers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list servers: %w", err)
+	}
+
+	result := make([]*queue.ServerInfo, 0, len(servers))
+	for _, server := range servers {
+		result = append(result, convertServerInfo(server))
+	}
+	return result, nil
+}
+
 // Close closes the queue connections
 func (s *queueServiceImpl) Close() error {
 	if err := s.client.Close(); err != nil {
@@ -477,5 +553,23 @@ func convertQueueInfo(info *asynq.QueueInfo) *queue.QueueInfo {
 		Failed:    info.Failed,
 		Paused:    info.Paused,
 		Timestamp: info.Timestamp,
+
+		Latency:        info.Latency,
+		ProcessedTotal: info.ProcessedTotal,
+	}
+}
+
+func convertServerInfo(info *asynq.ServerInfo) *queue.ServerInfo {
+	if info == nil {
+		return nil
+	}
+
+	return &queue.ServerInfo{
+		ID:            info.ID,
+		Host:          info.Host,
+		Concurrency:   info.Concurrency,
+		Queues:        info.Queues,
+		ActiveWorkers: len(info.ActiveWorkers),
+		Started:       info.Started,
 	}
 }
@@ -0,0 +1,200 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"peekaping/internal/config"
+	"peekaping/internal/modules/queue"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// processSkewedWorkload enqueues a backlog where a few hot monitors, all in the same
+// shard, queued many checks ahead of single checks of cold monitors. It runs a worker
+// with one slot and returns the order in which the monitors were checked.
+func processSkewedWorkload(t *testing.T, shards int, hot, cold []string, checksPerHot int) []string {
+	mr := miniredis.RunT(t)
+	cfg := &config.Config{
+		RedisHost:              mr.Host(),
+		RedisPort:              mr.Port(),
+		QueueConcurrency:       1,
+		HealthCheckQueueShards: shards,
+	}
+
+	client := asynq.NewClient(asynq.RedisClientOpt{Addr: mr.Addr()})
+	defer client.Close()
+
+	enqueue := func(monitorID string) {
+		task := asynq.NewTask("healthcheck:test", []byte(monitorID))
+		_, err := client.Enqueue(task, asynq.Queue(queue.HealthCheckQueueName(monitorID, shards)))
+		require.NoError(t, err)
+	}
+	for i := 0; i < checksPerHot; i++ {
+		for _, id := range hot {
+			enqueue(id)
+		}
+	}
+	for _, id := range cold {
+		enqueue(id)
+	}
+	total := len(hot)*checksPerHot + len(cold)
+
+	var mu sync.Mutex
+	var order []string
+	done := make(chan struct{})
+	mux := asynq.NewServeMux()
+	mux.HandleFunc("healthcheck:test", func(ctx context.Context, task *asynq.Task) error {
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, string(task.Payload()))
+		if len(order) == total {
+			close(done)
+		}
+		return nil
+	})
+
+	server, err := ProvideAsynqServer(cfg, zap.NewNop().Sugar())
+	require.NoError(t, err)
+	require.NoError(t, server.Start(mux))
+	defer server.Shutdown()
+
+	select {
+	case <-done:
+	case <-time.After(30 * time.Second):
+		t.Fatalf("only %d of %d checks processed", len(order), total)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	return append([]string(nil), order...)
+}
+
+// lastIndexOf returns the position of the last check of any of the monitors
+func lastIndexOf(order []string, monitorIDs []string) int {
+	wanted := make(map[string]bool)
+	for _, id := range monitorIDs {
+		wanted[id] = true
+	}
+	last := -1
+	for i, id := range order {
+		if wanted[id] {
+			last = i
+		}
+	}
+	return last
+}
+
+func TestHealthCheckQueueFairness(t *testing.T) {
+	const shards = 4
+	const checksPerHot = 30
+
+	// Hot monitors crowd shard 0, cold monitors live in the other shards
+	var hot, cold []string
+	for i := 0; len(hot) < 3 || len(cold) < 9; i++ {
+		id := fmt.Sprintf("monitor-%d", i)
+		if queue.HealthCheckQueueName(id, shards) == "healthcheck:0" {
+			if len(hot) < 3 {
+				hot = append(hot, id)
+			}
+		} else if len(cold) < 9 {
+			cold = append(cold, id)
+		}
+	}
+	backlog := len(hot) * checksPerHot
+
+	t.Run("single queue serves the backlog first", func(t *testing.T) {
+		order := processSkewedWorkload(t, 1, hot, cold, checksPerHot)
+		assert.Equal(t, backlog+len(cold)-1, lastIndexOf(order, cold))
+	})
+
+	t.Run("shards serve cold monitors alongside the backlog", func(t *testing.T) {
+		order := processSkewedWorkload(t, shards, hot, cold, checksPerHot)
+		// Each pick goes to shard 0 with a probability of at most one half while the other
+		// shards still have checks, so the cold monitors are done long before the backlog
+		assert.Less(t, lastIndexOf(order, cold), backlog/2)
+	})
+}
+
+func TestWorkerQueues(t *testing.T) {
+	t.Run("single queue is served in strict priority order", func(t *testing.T) {
+		queues, strict := workerQueues(1)
+		assert.True(t, strict)
+		assert.Equal(t, map[string]int{"critical": 6, "healthcheck": 5, "default": 3, "low": 1}, queues)
+	})
+
+	t.Run("shards weigh the same and critical as much as all the others", func(t *testing.T) {
+		queues, strict := workerQueues(4)
+		assert.False(t, strict)
+
+		others := 0
+		for name, priority := range queues {
+			if queue.IsHealthCheckQueue(name) {
+				assert.Equal(t, queues["healthcheck"], priority, name)
+			}
+			if name != "critical" {
+				others += priority
+			}
+		}
+		assert.Len(t, queues, 8)
+		assert.Equal(t, others, queues["critical"])
+	})
+}
+
+func TestCriticalQueueServedAheadOfShardBacklog(t *testing.T) {
+	const shards = 4
+	mr := miniredis.RunT(t)
+	cfg := &config.Config{
+		RedisHost:              mr.Host(),
+		RedisPort:              mr.Port(),
+		QueueConcurrency:       1,
+		HealthCheckQueueShards: shards,
+	}
+
+	client := asynq.NewClient(asynq.RedisClientOpt{Addr: mr.Addr()})
+	defer client.Close()
+
+	const backlog = 100
+	for i := 0; i < backlog; i++ {
+		id := fmt.Sprintf("monitor-%d", i)
+		_, err := client.Enqueue(asynq.NewTask("healthcheck:test", []byte(id)), asynq.Queue(queue.HealthCheckQueueName(id, shards)))
+		require.NoError(t, err)
+	}
+	_, err := client.Enqueue(asynq.NewTask("healthcheck:test", []byte("critical")), asynq.Queue("critical"))
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	processed := 0
+	position := make(chan int, 1)
+	mux := asynq.NewServeMux()
+	mux.HandleFunc("healthcheck:test", func(ctx context.Context, task *asynq.Task) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if string(task.Payload()) == "critical" {
+			position <- processed
+		}
+		processed++
+		return nil
+	})
+
+	server, err := ProvideAsynqServer(cfg, zap.NewNop().Sugar())
+	require.NoError(t, err)
+	require.NoError(t, server.Start(mux))
+	defer server.Shutdown()
+
+	select {
+	case pos := <-position:
+		// Critical is tried first on at least every other pick, so it doesn't wait behind
+		// the backlog of the shards. Failing this takes 20 picks in a row going elsewhere.
+		assert.Less(t, pos, 20)
+	case <-time.After(30 * time.Second):
+		t.Fatal("critical task not processed")
+	}
+}
@@ -0,0 +1,106 @@
+package audit_log
+
+import (
+	"net/http"
+	"peekaping/internal/utils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type Controller struct {
+	service Service
+	logger  *zap.SugaredLogger
+}
+
+func NewController(
+	service Service,
+	logger *zap.SugaredLogger,
+) *Controller {
+	return &Controller{
+		service,
+		logger,
+	}
+}
+
+// @Router		/audit [get]
+// @Summary		Get audit log entries
+// @Tags			Audit
+// @Produce		json
+// @Security  JwtAuth
+// @Security  ApiKeyAuth
+// @Param     entity    query     string  false  "Entity type (monitor, notification_channel, maintenance)"
+// @Param     entity_id query     string  false  "Entity ID"
+// @Param     from      query     string  false  "Start of the time range (RFC3339)"
+// @Param     to        query     string  false  "End of the time range (RFC3339)"
+// @Param     page      query     int     false  "Page number" default(0)
+// @Param     limit     query     int     false  "Items per page" default(50)
+// @Success		200	{object}	utils.ApiResponse[[]Model]
+// @Failure		400	{object}	utils.APIError[any]
+// @Failure		500	{object}	utils.APIError[any]
+func (c *Controller) FindAll(ctx *gin.Context) {
+	page, err := utils.GetQueryInt(ctx, "page", 0)
+	if err != nil || page < 0 {
+		ctx.JSON(http.StatusBadRequest, utils.NewFailResponse("Invalid page parameter"))
+		return
+	}
+
+	limit, err := utils.GetQueryInt(ctx, "limit", 50)
+	if err != nil || limit < 1 || limit > 500 {
+		ctx.JSON(http.StatusBadRequest, utils.NewFailResponse("Invalid limit parameter"))
+		return
+	}
+
+	entity := ctx.Query("entity")
+	switch entity {
+	case "", EntityMonitor, EntityNotificationChannel, EntityMaintenance:
+	default:
+		ctx.JSON(http.StatusBadRequest, utils.NewFailResponse("Invalid entity parameter"))
+		return
+	}
+
+	from, err := parseTimeQuery(ctx, "from")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewFailResponse("Invalid from parameter, expected RFC3339"))
+		return
+	}
+	to, err := parseTimeQuery(ctx, "to")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewFailResponse("Invalid to parameter, expected RFC3339"))
+		return
+	}
+	if from != nil && to != nil && from.After(*to) {
+		ctx.JSON(http.StatusBadRequest, utils.NewFailResponse("from must be before to"))
+		return
+	}
+
+	entries, err := c.service.FindAll(ctx, &FilterOptions{
+		EntityType: entity,
+		EntityID:   ctx.Query("entity_id"),
+		From:       from,
+		To:         to,
+		Page:       page,
+		Limit:      limit,
+	})
+	if err != nil {
+		c.logger.Errorw("Failed to fetch audit log", "error", err)
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewSuccessResponse("success", entries))
+}
+
+func parseTimeQuery(ctx *gin.Context, key string) (*time.Time, error) {
+	value := ctx.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	t = t.UTC()
+	return &t, nil
+}
@@ -0,0 +1,49 @@
+package audit_log
+
+import "time"
+
+const (
+	EntityMonitor             = "monitor"
+	EntityNotificationChannel = "notification_channel"
+	EntityMaintenance         = "maintenance"
+)
+
+// RetentionDaysSettingKey is the setting holding how many days entries are kept before
+// the cleanup cron deletes them. 0 keeps them forever.
+const RetentionDaysSettingKey = "AUDIT_LOG_RETENTION_DAYS"
+
+// DefaultRetentionDays is used while the retention setting is unset or invalid
+const DefaultRetentionDays = 365
+
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// FieldChange holds the previous and new value of a changed field
+type FieldChange struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+type Model struct {
+	ID         string                 `json:"id"`
+	EntityType string                 `json:"entity_type"`
+	EntityID   string                 `json:"entity_id"`
+	Action     string                 `json:"action"`
+	ActorType  string                 `json:"actor_type"`
+	ActorID    string                 `json:"actor_id"`
+	Changes    map[string]FieldChange `json:"changes"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
+
+// FilterOptions narrows down audit log queries. Zero values are ignored.
+type FilterOptions struct {
+	EntityType string
+	EntityID   string
+	From       *time.Time
+	To         *time.Time
+	Page       int
+	Limit      int
+}
@@ -0,0 +1,138 @@
+package audit_log
+
+import (
+	"context"
+	"peekaping/internal/config"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoModel struct {
+	ID         primitive.ObjectID     `bson:"_id"`
+	EntityType string                 `bson:"entity_type"`
+	EntityID   string                 `bson:"entity_id"`
+	Action     string                 `bson:"action"`
+	ActorType  string                 `bson:"actor_type"`
+	ActorID    string                 `bson:"actor_id"`
+	Changes    map[string]FieldChange `bson:"changes"`
+	CreatedAt  time.Time              `bson:"created_at"`
+}
+
+func toDomainModelFromMongo(mm *mongoModel) *Model {
+	changes := mm.Changes
+	if changes == nil {
+		changes = make(map[string]FieldChange)
+	}
+
+	return &Model{
+		ID:         mm.ID.Hex(),
+		EntityType: mm.EntityType,
+		EntityID:   mm.EntityID,
+		Action:     mm.Action,
+		ActorType:  mm.ActorType,
+		ActorID:    mm.ActorID,
+		Changes:    changes,
+		CreatedAt:  mm.CreatedAt,
+	}
+}
+
+type MongoRepositoryImpl struct {
+	client     *mongo.Client
+	db         *mongo.Database
+	collection *mongo.Collection
+}
+
+func NewMongoRepository(client *mongo.Client, cfg *config.Config) Repository {
+	db := client.Database(cfg.DBName)
+	collection := db.Collection("audit_log")
+	ctx := context.Background()
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "entity_type", Value: 1}, {Key: "entity_id", Value: 1}, {Key: "created_at", Value: -1}}},
+	})
+	if err != nil {
+		panic("Failed to create index on audit_log collection: " + err.Error())
+	}
+
+	return &MongoRepositoryImpl{client, db, collection}
+}
+
+func (r *MongoRepositoryImpl) Create(ctx context.Context, entity *Model) (*Model, error) {
+	mm := &mongoModel{
+		ID:         primitive.NewObjectID(),
+		EntityType: entity.EntityType,
+		EntityID:   entity.EntityID,
+		Action:     entity.Action,
+		ActorType:  entity.ActorType,
+		ActorID:    entity.ActorID,
+		Changes:    entity.Changes,
+		CreatedAt:  entity.CreatedAt,
+	}
+	if mm.CreatedAt.IsZero() {
+		mm.CreatedAt = time.Now().UTC()
+	}
+
+	_, err := r.collection.InsertOne(ctx, mm)
+	if err != nil {
+		return nil, err
+	}
+
+	return toDomainModelFromMongo(mm), nil
+}
+
+func (r *MongoRepositoryImpl) FindAll(ctx context.Context, filter *FilterOptions) ([]*Model, error) {
+	query := bson.M{}
+	if filter.EntityType != "" {
+		query["entity_type"] = filter.EntityType
+	}
+	if filter.EntityID != "" {
+		query["entity_id"] = filter.EntityID
+	}
+	if filter.From != nil || filter.To != nil {
+		createdAt := bson.M{}
+		if filter.From != nil {
+			createdAt["$gte"] = *filter.From
+		}
+		if filter.To != nil {
+			createdAt["$lte"] = *filter.To
+		}
+		query["created_at"] = createdAt
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64(filter.Page * filter.Limit)).
+		SetLimit(int64(filter.Limit))
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	models := make([]*Model, 0)
+	for cursor.Next(ctx) {
+		var mm mongoModel
+		if err := cursor.Decode(&mm); err != nil {
+			return nil, err
+		}
+		models = append(models, toDomainModelFromMongo(&mm))
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+	return models, nil
+}
+
+func (r *MongoRepositoryImpl) DeleteOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	result, err := r.collection.DeleteMany(ctx, bson.M{"created_at": bson.M{"$lt": cutoff}})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
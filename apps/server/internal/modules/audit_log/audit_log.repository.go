@@ -0,0 +1,12 @@
+package audit_log
+
+import (
+	"context"
+	"time"
+)
+
+type Repository interface {
+	Create(ctx context.Context, entity *Model) (*Model, error)
+	FindAll(ctx context.Context, filter *FilterOptions) ([]*Model, error)
+	DeleteOlderThan(ctx context.Context, cutoff time.Time) (int64, error)
+}
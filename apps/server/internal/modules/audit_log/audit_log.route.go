@@ -0,0 +1,33 @@
+package audit_log
+
+import (
+	"peekaping/internal/modules/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Route struct {
+	controller *Controller
+	middleware *middleware.AuthChain
+}
+
+func NewRoute(
+	controller *Controller,
+	middleware *middleware.AuthChain,
+) *Route {
+	return &Route{
+		controller,
+		middleware,
+	}
+}
+
+func (r *Route) ConnectRoute(
+	rg *gin.RouterGroup,
+	controller *Controller,
+) {
+	router := rg.Group("audit")
+
+	router.Use(r.middleware.AllAuth())
+
+	router.GET("", controller.FindAll)
+}
@@ -0,0 +1,220 @@
+package audit_log
+
+import (
+	"context"
+	"encoding/json"
+	"peekaping/internal/config"
+	"reflect"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const redactedValue = "[REDACTED]"
+
+// ignoredFields are bookkeeping fields that change on every write
+var ignoredFields = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+}
+
+// sensitiveFieldParts and sensitiveFieldSuffixes mark fields whose values must
+// never be written to the audit log
+var sensitiveFieldParts = []string{
+	"password",
+	"secret",
+	"token",
+	"apikey",
+	"authorization",
+	"webhook",
+	"headers",
+}
+
+var sensitiveFieldSuffixes = []string{
+	"_pass",
+	"_key",
+}
+
+type Service interface {
+	// Record stores an audit entry for a change of an entity. before is nil for
+	// creates and after is nil for deletes. Updates without changes are skipped
+	// and return nil.
+	Record(ctx context.Context, entityType, entityID, action string, before, after any) (*Model, error)
+	FindAll(ctx context.Context, filter *FilterOptions) ([]*Model, error)
+	// DeleteOlderThan removes the entries recorded before cutoff
+	DeleteOlderThan(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+type ServiceImpl struct {
+	repository Repository
+	enabled    bool
+	logger     *zap.SugaredLogger
+}
+
+func NewService(
+	repository Repository,
+	cfg *config.Config,
+	logger *zap.SugaredLogger,
+) Service {
+	return &ServiceImpl{
+		repository,
+		cfg.AuditLogEnabled,
+		logger.Named("[audit-log-service]"),
+	}
+}
+
+func (s *ServiceImpl) Record(ctx context.Context, entityType, entityID, action string, before, after any) (*Model, error) {
+	if !s.enabled {
+		return nil, nil
+	}
+
+	changes, err := Diff(before, after)
+	if err != nil {
+		return nil, err
+	}
+	if action == ActionUpdate && len(changes) == 0 {
+		return nil, nil
+	}
+
+	actorType, actorID := ActorFromContext(ctx)
+
+	return s.repository.Create(ctx, &Model{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		ActorType:  actorType,
+		ActorID:    actorID,
+		Changes:    changes,
+		CreatedAt:  time.Now().UTC(),
+	})
+}
+
+func (s *ServiceImpl) FindAll(ctx context.Context, filter *FilterOptions) ([]*Model, error) {
+	return s.repository.FindAll(ctx, filter)
+}
+
+func (s *ServiceImpl) DeleteOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	return s.repository.DeleteOlderThan(ctx, cutoff)
+}
+
+// ActorFromContext resolves who performed the request from the values set by the auth
+// middleware as an actor type (user, api_key or system) and id
+func ActorFromContext(ctx context.Context) (string, string) {
+	authType, _ := ctx.Value("authType").(string)
+	switch authType {
+	case "jwt":
+		userID, _ := ctx.Value("userId").(string)
+		return "user", userID
+	case "api_key":
+		apiKeyID, _ := ctx.Value("apiKeyId").(string)
+		return "api_key", apiKeyID
+	default:
+		return "system", ""
+	}
+}
+
+// Diff returns the fields that differ between before and after, keyed by their JSON
+// name. Fields holding a JSON object encoded as a string (e.g. monitor config) are
+// expanded so that changes are reported as "config.<field>".
+func Diff(before, after any) (map[string]FieldChange, error) {
+	beforeFields, err := flatten(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := flatten(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]FieldChange)
+	for key, oldValue := range beforeFields {
+		newValue, ok := afterFields[key]
+		if ok && reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		changes[key] = redact(key, FieldChange{Old: oldValue, New: newValue})
+	}
+	for key, newValue := range afterFields {
+		if _, ok := beforeFields[key]; ok {
+			continue
+		}
+		changes[key] = redact(key, FieldChange{Old: nil, New: newValue})
+	}
+
+	return changes, nil
+}
+
+func flatten(v any) (map[string]any, error) {
+	result := make(map[string]any)
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil()) {
+		return result, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	flattenInto(result, "", fields)
+	return result, nil
+}
+
+func flattenInto(result map[string]any, prefix string, fields map[string]any) {
+	for key, value := range fields {
+		if prefix == "" && ignoredFields[key] {
+			continue
+		}
+		name := key
+		if prefix != "" {
+			name = prefix + "." + key
+		}
+
+		switch typed := value.(type) {
+		case map[string]any:
+			flattenInto(result, name, typed)
+		case string:
+			var nested map[string]any
+			if strings.HasPrefix(strings.TrimSpace(typed), "{") && json.Unmarshal([]byte(typed), &nested) == nil {
+				flattenInto(result, name, nested)
+				continue
+			}
+			result[name] = value
+		default:
+			result[name] = value
+		}
+	}
+}
+
+func isSensitiveField(key string) bool {
+	lower := strings.ToLower(key)
+	for _, part := range sensitiveFieldParts {
+		if strings.Contains(lower, part) {
+			return true
+		}
+	}
+	for _, suffix := range sensitiveFieldSuffixes {
+		if strings.HasSuffix(lower, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func redact(key string, change FieldChange) FieldChange {
+	if !isSensitiveField(key) {
+		return change
+	}
+	if change.Old != nil {
+		change.Old = redactedValue
+	}
+	if change.New != nil {
+		change.New = redactedValue
+	}
+	return change
+}
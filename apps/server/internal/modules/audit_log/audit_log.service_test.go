@@ -0,0 +1,138 @@
+package audit_log
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testEntity struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Active    bool      `json:"active"`
+	Config    *string   `json:"config"`
+	Headers   string    `json:"headers"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestDiff(t *testing.T) {
+	t.Run("reports changed top-level and config fields", func(t *testing.T) {
+		before := &testEntity{ID: "1", Name: "a", Active: true, Config: strPtr(`{"url":"https://a","smtp_password":"x","port":25}`)}
+		after := &testEntity{ID: "1", Name: "b", Active: true, Config: strPtr(`{"url":"https://b","smtp_password":"y","port":25}`), UpdatedAt: time.Now()}
+
+		changes, err := Diff(before, after)
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]FieldChange{
+			"name":                 {Old: "a", New: "b"},
+			"config.url":           {Old: "https://a", New: "https://b"},
+			"config.smtp_password": {Old: redactedValue, New: redactedValue},
+		}, changes)
+	})
+
+	t.Run("create records all fields as new", func(t *testing.T) {
+		changes, err := Diff(nil, &testEntity{ID: "1", Name: "a"})
+		require.NoError(t, err)
+
+		assert.Equal(t, FieldChange{Old: nil, New: "a"}, changes["name"])
+		assert.Equal(t, FieldChange{Old: nil, New: "1"}, changes["id"])
+		assert.NotContains(t, changes, "updated_at")
+	})
+
+	t.Run("delete records all fields as removed", func(t *testing.T) {
+		var after *testEntity
+		changes, err := Diff(&testEntity{ID: "1", Name: "a"}, after)
+		require.NoError(t, err)
+
+		assert.Equal(t, FieldChange{Old: "a", New: nil}, changes["name"])
+	})
+
+	t.Run("headers are redacted", func(t *testing.T) {
+		before := &testEntity{Headers: `{"Authorization":"Bearer a"}`}
+		after := &testEntity{Headers: `{"Authorization":"Bearer b"}`}
+
+		changes, err := Diff(before, after)
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]FieldChange{
+			"headers.Authorization": {Old: redactedValue, New: redactedValue},
+		}, changes)
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		changes, err := Diff(&testEntity{ID: "1", Name: "a"}, &testEntity{ID: "1", Name: "a", UpdatedAt: time.Now()})
+		require.NoError(t, err)
+		assert.Empty(t, changes)
+	})
+}
+
+type recordingRepository struct {
+	created []*Model
+}
+
+func (r *recordingRepository) Create(ctx context.Context, entity *Model) (*Model, error) {
+	r.created = append(r.created, entity)
+	return entity, nil
+}
+
+func (r *recordingRepository) FindAll(ctx context.Context, filter *FilterOptions) ([]*Model, error) {
+	return r.created, nil
+}
+
+func (r *recordingRepository) DeleteOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestServiceImpl_Record(t *testing.T) {
+	t.Run("resolves actor from context", func(t *testing.T) {
+		repo := &recordingRepository{}
+		service := &ServiceImpl{repository: repo, enabled: true}
+
+		ctx := context.WithValue(context.Background(), "authType", "api_key")
+		ctx = context.WithValue(ctx, "apiKeyId", "key-1")
+
+		entry, err := service.Record(ctx, EntityMaintenance, "m1", ActionCreate, nil, &testEntity{Name: "a"})
+		require.NoError(t, err)
+		require.NotNil(t, entry)
+		assert.Equal(t, "api_key", entry.ActorType)
+		assert.Equal(t, "key-1", entry.ActorID)
+		assert.Len(t, repo.created, 1)
+	})
+
+	t.Run("system actor without auth", func(t *testing.T) {
+		repo := &recordingRepository{}
+		service := &ServiceImpl{repository: repo, enabled: true}
+
+		entry, err := service.Record(context.Background(), EntityMonitor, "m1", ActionDelete, &testEntity{Name: "a"}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "system", entry.ActorType)
+		assert.Empty(t, entry.ActorID)
+	})
+
+	t.Run("update without changes is skipped", func(t *testing.T) {
+		repo := &recordingRepository{}
+		service := &ServiceImpl{repository: repo, enabled: true}
+
+		entry, err := service.Record(context.Background(), EntityMonitor, "m1", ActionUpdate, &testEntity{Name: "a"}, &testEntity{Name: "a"})
+		require.NoError(t, err)
+		assert.Nil(t, entry)
+		assert.Empty(t, repo.created)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		repo := &recordingRepository{}
+		service := &ServiceImpl{repository: repo, enabled: false}
+
+		entry, err := service.Record(context.Background(), EntityMonitor, "m1", ActionCreate, nil, &testEntity{Name: "a"})
+		require.NoError(t, err)
+		assert.Nil(t, entry)
+		assert.Empty(t, repo.created)
+	})
+}
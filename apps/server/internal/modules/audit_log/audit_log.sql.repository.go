@@ -0,0 +1,130 @@
+package audit_log
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+type sqlModel struct {
+	bun.BaseModel `bun:"table:audit_log,alias:al"`
+
+	ID         string    `bun:"id,pk"`
+	EntityType string    `bun:"entity_type,notnull"`
+	EntityID   string    `bun:"entity_id,notnull"`
+	Action     string    `bun:"action,notnull"`
+	ActorType  string    `bun:"actor_type,notnull"`
+	ActorID    string    `bun:"actor_id"`
+	Changes    string    `bun:"changes,notnull"`
+	CreatedAt  time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+}
+
+func toDomainModelFromSQL(sm *sqlModel) *Model {
+	changes := make(map[string]FieldChange)
+	if sm.Changes != "" {
+		_ = json.Unmarshal([]byte(sm.Changes), &changes)
+	}
+
+	return &Model{
+		ID:         sm.ID,
+		EntityType: sm.EntityType,
+		EntityID:   sm.EntityID,
+		Action:     sm.Action,
+		ActorType:  sm.ActorType,
+		ActorID:    sm.ActorID,
+		Changes:    changes,
+		CreatedAt:  sm.CreatedAt,
+	}
+}
+
+func toSQLModel(m *Model) (*sqlModel, error) {
+	changes, err := json.Marshal(m.Changes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqlModel{
+		ID:         m.ID,
+		EntityType: m.EntityType,
+		EntityID:   m.EntityID,
+		Action:     m.Action,
+		ActorType:  m.ActorType,
+		ActorID:    m.ActorID,
+		Changes:    string(changes),
+		CreatedAt:  m.CreatedAt,
+	}, nil
+}
+
+type SQLRepositoryImpl struct {
+	db *bun.DB
+}
+
+func NewSQLRepository(db *bun.DB) Repository {
+	return &SQLRepositoryImpl{db: db}
+}
+
+func (r *SQLRepositoryImpl) Create(ctx context.Context, entity *Model) (*Model, error) {
+	sm, err := toSQLModel(entity)
+	if err != nil {
+		return nil, err
+	}
+	sm.ID = uuid.New().String()
+	if sm.CreatedAt.IsZero() {
+		sm.CreatedAt = time.Now().UTC()
+	}
+
+	_, err = r.db.NewInsert().Model(sm).Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return toDomainModelFromSQL(sm), nil
+}
+
+func (r *SQLRepositoryImpl) FindAll(ctx context.Context, filter *FilterOptions) ([]*Model, error) {
+	query := r.db.NewSelect().Model((*sqlModel)(nil))
+
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at <= ?", *filter.To)
+	}
+
+	query = query.Order("created_at DESC").
+		Limit(filter.Limit).
+		Offset(filter.Page * filter.Limit)
+
+	var sms []*sqlModel
+	if err := query.Scan(ctx, &sms); err != nil {
+		return nil, err
+	}
+
+	models := make([]*Model, 0, len(sms))
+	for _, sm := range sms {
+		models = append(models, toDomainModelFromSQL(sm))
+	}
+	return models, nil
+}
+
+func (r *SQLRepositoryImpl) DeleteOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	result, err := r.db.NewDelete().
+		Model((*sqlModel)(nil)).
+		Where("created_at < ?", cutoff).
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected, nil
+}
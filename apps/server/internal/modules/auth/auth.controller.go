@@ -199,6 +199,31 @@ func (c *Controller) UpdatePassword(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, utils.NewSuccessResponse[any]("Password updated successfully", nil))
 }
 
+// @Router	/auth/logout-all [post]
+// @Summary	Log out of all sessions
+// @Description	Revokes every access and refresh token issued to the user, including the one of the request
+// @Tags		Auth
+// @Produce	json
+// @Security JwtAuth
+// @Success	200	{object}	utils.ApiResponse[any]
+// @Failure	401	{object}	utils.APIError[any]
+// @Failure	500	{object}	utils.APIError[any]
+func (c *Controller) LogoutAll(ctx *gin.Context) {
+	userId, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, utils.NewFailResponse("Unauthorized"))
+		return
+	}
+
+	if err := c.service.RevokeSessions(ctx, userId.(string)); err != nil {
+		c.logger.Errorw("Failed to revoke sessions", "error", err)
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse(err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewSuccessResponse[any]("Logged out of all sessions", nil))
+}
+
 // @Router	/auth/2fa/setup [post]
 // @Summary	Enable 2FA (TOTP) for user
 // @Tags		Auth
@@ -14,5 +14,6 @@ func RegisterDependencies(container *dig.Container, cfg *config.Config) {
 	container.Provide(NewTokenMaker)
 	container.Provide(NewService)
 	container.Provide(NewController)
+	container.Provide(NewJWKSVerifier)
 	container.Provide(NewMiddlewareProvider)
 }
\ No newline at end of file
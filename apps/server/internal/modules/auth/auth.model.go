@@ -5,15 +5,17 @@ import (
 )
 
 type Model struct {
-	ID             string    `json:"id"`
-	Email          string    `json:"email"`
-	Password       string    `json:"-"`
-	Active         bool      `json:"active"`
-	TwoFASecret    string    `json:"-"`
-	TwoFAStatus    bool      `json:"twofa_status"`
-	TwoFALastToken string    `json:"-"`
-	CreatedAt      time.Time `json:"createdAt"`
-	UpdatedAt      time.Time `json:"updatedAt"`
+	ID             string `json:"id"`
+	Email          string `json:"email"`
+	Password       string `json:"-"`
+	Active         bool   `json:"active"`
+	TwoFASecret    string `json:"-"`
+	TwoFAStatus    bool   `json:"twofa_status"`
+	TwoFALastToken string `json:"-"`
+	// TokenVersion is embedded in issued tokens, bumping it revokes all of them
+	TokenVersion int       `json:"-"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
 }
 
 type UpdateModel struct {
@@ -23,4 +25,7 @@ type UpdateModel struct {
 	TwoFASecret    *string `json:"twofa_secret"`
 	TwoFAStatus    *bool   `json:"twofa_status"`
 	TwoFALastToken *string `json:"twofa_last_token"`
+	// IncrementTokenVersion bumps the token version in the same write, which revokes
+	// every issued token
+	IncrementTokenVersion bool `json:"-"`
 }
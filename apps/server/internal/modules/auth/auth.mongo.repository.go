@@ -19,6 +19,7 @@ type mongoModel struct {
 	TwoFASecret    string             `bson:"twofa_secret"`
 	TwoFAStatus    bool               `bson:"twofa_status"`
 	TwoFALastToken string             `bson:"twofa_last_token"`
+	TokenVersion   int                `bson:"token_version"`
 	CreatedAt      time.Time          `bson:"createdAt"`
 	UpdatedAt      time.Time          `bson:"updatedAt"`
 }
@@ -43,6 +44,7 @@ func toDomainModel(mm *mongoModel) *Model {
 		TwoFASecret:    mm.TwoFASecret,
 		TwoFAStatus:    mm.TwoFAStatus,
 		TwoFALastToken: mm.TwoFALastToken,
+		TokenVersion:   mm.TokenVersion,
 		CreatedAt:      mm.CreatedAt,
 		UpdatedAt:      mm.UpdatedAt,
 	}
@@ -138,6 +140,9 @@ func (r *RepositoryImpl) Update(ctx context.Context, id string, entity *UpdateMo
 
 	filter := bson.M{"_id": objectID}
 	update := bson.M{"$set": set}
+	if entity.IncrementTokenVersion {
+		update["$inc"] = bson.M{"token_version": 1}
+	}
 
 	_, err = r.collection.UpdateOne(ctx, filter, update)
 	return err
@@ -3,6 +3,7 @@ package auth
 import (
 	"context"
 	"errors"
+	"peekaping/internal/modules/events"
 	"time"
 
 	"github.com/pquerna/otp/totp"
@@ -15,6 +16,8 @@ type Service interface {
 	Login(ctx context.Context, dto LoginDto) (*LoginResponse, error)
 	RefreshToken(ctx context.Context, refreshToken string) (*LoginResponse, error)
 	UpdatePassword(ctx context.Context, userId string, dto UpdatePasswordDto) error
+	// RevokeSessions invalidates every access and refresh token issued to the user
+	RevokeSessions(ctx context.Context, userId string) error
 
 	// 2FA methods
 	SetupTwoFA(ctx context.Context, userId, password string) (secret string, provisioningURI string, err error)
@@ -25,21 +28,33 @@ type Service interface {
 type ServiceImpl struct {
 	repo       Repository
 	tokenMaker *TokenMaker
+	eventBus   events.EventBus
 	logger     *zap.SugaredLogger
 }
 
 func NewService(
 	repo Repository,
 	tokenMaker *TokenMaker,
+	eventBus events.EventBus,
 	logger *zap.SugaredLogger,
 ) Service {
 	return &ServiceImpl{
 		repo:       repo,
 		tokenMaker: tokenMaker,
+		eventBus:   eventBus,
 		logger:     logger.Named("[auth-service]"),
 	}
 }
 
+// publishSessionsRevoked lets open websocket connections of the user be closed, they
+// only check the token when connecting
+func (s *ServiceImpl) publishSessionsRevoked(userId string) {
+	s.eventBus.Publish(events.Event{
+		Type:    events.SessionsRevoked,
+		Payload: &events.SessionsRevokedPayload{UserID: userId},
+	})
+}
+
 func (s *ServiceImpl) Register(ctx context.Context, dto RegisterDto) (*LoginResponse, error) {
 	count, err := s.repo.FindAllCount(ctx)
 	if err != nil {
@@ -157,6 +172,9 @@ func (s *ServiceImpl) RefreshToken(ctx context.Context, refreshToken string) (*L
 	if err != nil || user == nil {
 		return nil, errors.New("user not found")
 	}
+	if user.TokenVersion != claims.TokenVersion {
+		return nil, errors.New("invalid refresh token")
+	}
 
 	// Generate new access token
 	accessToken, err := s.tokenMaker.CreateAccessToken(ctx, user)
@@ -196,9 +214,11 @@ func (s *ServiceImpl) UpdatePassword(ctx context.Context, userId string, dto Upd
 		return errors.New("failed to hash new password")
 	}
 
+	// Changing the password logs out every session
 	password := string(hashedPassword)
 	updateModel := &UpdateModel{
-		Password: &password,
+		Password:              &password,
+		IncrementTokenVersion: true,
 	}
 
 	// Update user in DB
@@ -206,7 +226,23 @@ func (s *ServiceImpl) UpdatePassword(ctx context.Context, userId string, dto Upd
 	if err != nil {
 		return errors.New("failed to update password")
 	}
+	s.publishSessionsRevoked(userId)
+
+	return nil
+}
+
+func (s *ServiceImpl) RevokeSessions(ctx context.Context, userId string) error {
+	user, err := s.repo.FindByID(ctx, userId)
+	if err != nil || user == nil {
+		return errors.New("user not found")
+	}
+
+	if err := s.repo.Update(ctx, userId, &UpdateModel{IncrementTokenVersion: true}); err != nil {
+		return errors.New("failed to revoke sessions")
+	}
+	s.publishSessionsRevoked(userId)
 
+	s.logger.Infow("Revoked all sessions", "userId", userId)
 	return nil
 }
 
@@ -18,6 +18,7 @@ type sqlModel struct {
 	TwoFASecret    string    `bun:"twofa_secret"`
 	TwoFAStatus    bool      `bun:"twofa_status,notnull,default:false"`
 	TwoFALastToken string    `bun:"twofa_last_token"`
+	TokenVersion   int       `bun:"token_version,notnull,default:0"`
 	CreatedAt      time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
 	UpdatedAt      time.Time `bun:"updated_at,nullzero,notnull,default:current_timestamp"`
 }
@@ -31,6 +32,7 @@ func toDomainModelFromSQL(sm *sqlModel) *Model {
 		TwoFASecret:    sm.TwoFASecret,
 		TwoFAStatus:    sm.TwoFAStatus,
 		TwoFALastToken: sm.TwoFALastToken,
+		TokenVersion:   sm.TokenVersion,
 		CreatedAt:      sm.CreatedAt,
 		UpdatedAt:      sm.UpdatedAt,
 	}
@@ -45,6 +47,7 @@ func toSQLModel(m *Model) *sqlModel {
 		TwoFASecret:    m.TwoFASecret,
 		TwoFAStatus:    m.TwoFAStatus,
 		TwoFALastToken: m.TwoFALastToken,
+		TokenVersion:   m.TokenVersion,
 		CreatedAt:      m.CreatedAt,
 		UpdatedAt:      m.UpdatedAt,
 	}
@@ -138,6 +141,10 @@ func (r *SQLRepositoryImpl) Update(ctx context.Context, id string, entity *Updat
 		query = query.Set("twofa_last_token = ?", *entity.TwoFALastToken)
 		hasUpdates = true
 	}
+	if entity.IncrementTokenVersion {
+		query = query.Set("token_version = token_version + 1")
+		hasUpdates = true
+	}
 
 	if !hasUpdates {
 		return nil
@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"github.com/uptrace/bun/driver/sqliteshim"
+)
+
+func TestSQLRepository_IncrementTokenVersion(t *testing.T) {
+	sqldb, err := sql.Open(sqliteshim.ShimName, "file:auth_token_version?mode=memory&cache=shared")
+	require.NoError(t, err)
+	db := bun.NewDB(sqldb, sqlitedialect.New())
+	t.Cleanup(func() { db.Close() })
+
+	ctx := context.Background()
+	_, err = db.NewCreateTable().Model((*sqlModel)(nil)).Exec(ctx)
+	require.NoError(t, err)
+
+	repo := NewSQLRepository(db)
+	user, err := repo.Create(ctx, &Model{Email: "admin@example.com", Password: "hash", Active: true})
+	require.NoError(t, err)
+
+	// Concurrent revocations each bump the version instead of writing the same value
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, repo.Update(ctx, user.ID, &UpdateModel{IncrementTokenVersion: true}))
+		}()
+	}
+	wg.Wait()
+
+	password := "new-hash"
+	require.NoError(t, repo.Update(ctx, user.ID, &UpdateModel{Password: &password, IncrementTokenVersion: true}))
+
+	updated, err := repo.FindByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 6, updated.TokenVersion)
+	assert.Equal(t, "new-hash", updated.Password)
+}
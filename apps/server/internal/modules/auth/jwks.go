@@ -0,0 +1,315 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"peekaping/internal/config"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+// jwksMinRefreshInterval keeps tokens with an unknown kid from making us refetch the key
+// set on every request
+const jwksMinRefreshInterval = 10 * time.Second
+
+var (
+	ErrUnknownSigningKey = errors.New("unknown signing key")
+	ErrMissingRole       = errors.New("token has no allowed role")
+	ErrUnverifiedEmail   = errors.New("token email is not verified")
+)
+
+// JWKSIdentity is what an identity provider token says about its bearer
+type JWKSIdentity struct {
+	Subject string
+	Email   string
+}
+
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// JWKSVerifier validates bearer JWTs issued by an external identity provider against the
+// public keys it publishes as a JSON Web Key Set
+type JWKSVerifier struct {
+	url        string
+	issuer     string
+	audience   string
+	emailClaim string
+	roleClaim  string
+	adminRoles map[string]bool
+	cacheTTL   time.Duration
+	client     *http.Client
+	mu         sync.Mutex
+	refresh    singleflight.Group
+	keys       map[string]any // kid -> *rsa.PublicKey or *ecdsa.PublicKey
+	fetchedAt  time.Time
+	logger     *zap.SugaredLogger
+}
+
+// NewJWKSVerifier creates the verifier from the JWKS_* settings. It returns nil when no
+// JWKS URL is configured, external tokens are then rejected.
+func NewJWKSVerifier(cfg *config.Config, logger *zap.SugaredLogger) (*JWKSVerifier, error) {
+	if cfg.JWKSURL == "" {
+		return nil, nil
+	}
+	if cfg.JWKSIssuer == "" || cfg.JWKSAudience == "" {
+		return nil, fmt.Errorf("JWKS_ISSUER and JWKS_AUDIENCE are required when JWKS_URL is set")
+	}
+
+	adminRoles := make(map[string]bool)
+	for _, role := range strings.Split(cfg.JWKSAdminRoles, ",") {
+		if role = strings.TrimSpace(role); role != "" {
+			adminRoles[role] = true
+		}
+	}
+
+	emailClaim := cfg.JWKSEmailClaim
+	if emailClaim == "" {
+		emailClaim = "email"
+	}
+	cacheTTL := cfg.JWKSCacheTTL
+	if cacheTTL <= 0 {
+		cacheTTL = time.Hour
+	}
+
+	return &JWKSVerifier{
+		url:        cfg.JWKSURL,
+		issuer:     cfg.JWKSIssuer,
+		audience:   cfg.JWKSAudience,
+		emailClaim: emailClaim,
+		roleClaim:  cfg.JWKSRoleClaim,
+		adminRoles: adminRoles,
+		cacheTTL:   cacheTTL,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		keys:       make(map[string]any),
+		logger:     logger.Named("[jwks]"),
+	}, nil
+}
+
+// Verify checks the token signature, expiry, issuer and audience and maps its claims to
+// an identity
+func (v *JWKSVerifier) Verify(ctx context.Context, tokenString string) (*JWKSIdentity, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		return v.key(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}),
+		jwt.WithIssuer(v.issuer),
+		jwt.WithAudience(v.audience),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	email, _ := claims[v.emailClaim].(string)
+	if email == "" {
+		return nil, fmt.Errorf("token has no %s claim", v.emailClaim)
+	}
+	// Providers that let users set their own email flag unverified ones, such an email
+	// could be another user's. Tokens without the claim are accepted.
+	if verified, ok := claims["email_verified"]; ok && !isTrueClaim(verified) {
+		return nil, ErrUnverifiedEmail
+	}
+	subject, _ := claims.GetSubject()
+
+	if len(v.adminRoles) > 0 && !v.hasAdminRole(claims[v.roleClaim]) {
+		return nil, ErrMissingRole
+	}
+
+	return &JWKSIdentity{Subject: subject, Email: email}, nil
+}
+
+// isTrueClaim accepts a boolean claim or its string form, which some providers send
+func isTrueClaim(claim any) bool {
+	switch value := claim.(type) {
+	case bool:
+		return value
+	case string:
+		return strings.EqualFold(value, "true")
+	}
+	return false
+}
+
+// hasAdminRole accepts a role claim holding a single string, a space-separated string
+// (OAuth scope style) or an array of strings
+func (v *JWKSVerifier) hasAdminRole(claim any) bool {
+	var roles []string
+	switch value := claim.(type) {
+	case string:
+		roles = strings.Fields(value)
+	case []any:
+		for _, item := range value {
+			if role, ok := item.(string); ok {
+				roles = append(roles, role)
+			}
+		}
+	}
+
+	for _, role := range roles {
+		if v.adminRoles[role] {
+			return true
+		}
+	}
+	return false
+}
+
+// key returns the public key for kid, refreshing the cached key set when it is stale or
+// doesn't know the kid, e.g. after the provider rotated its keys
+func (v *JWKSVerifier) key(ctx context.Context, kid string) (any, error) {
+	v.mu.Lock()
+	stale := time.Since(v.fetchedAt) > v.cacheTTL
+	key, ok := v.lookup(kid)
+	refresh := stale || time.Since(v.fetchedAt) > jwksMinRefreshInterval
+	v.mu.Unlock()
+
+	if ok && !stale {
+		return key, nil
+	}
+
+	if refresh {
+		// Concurrent requests share one fetch and the lock isn't held during it, so tokens
+		// with known keys don't wait for the provider
+		_, err, _ := v.refresh.Do("jwks", func() (any, error) {
+			keys, err := v.fetch(context.WithoutCancel(ctx))
+			if err != nil {
+				return nil, err
+			}
+			v.mu.Lock()
+			v.keys = keys
+			v.fetchedAt = time.Now()
+			v.mu.Unlock()
+			return nil, nil
+		})
+		if err != nil {
+			// Serve the previous keys while the provider can't be reached
+			v.logger.Warnw("Failed to fetch JWKS", "url", v.url, "error", err)
+		}
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if key, ok := v.lookup(kid); ok {
+		return key, nil
+	}
+	return nil, ErrUnknownSigningKey
+}
+
+// lookup finds the key for kid. Tokens without a kid are accepted when the set holds a
+// single key.
+func (v *JWKSVerifier) lookup(kid string) (any, bool) {
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, true
+		}
+	}
+	key, ok := v.keys[kid]
+	return key, ok
+}
+
+func (v *JWKSVerifier) fetch(ctx context.Context) (map[string]any, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %w", err)
+	}
+
+	keys := make(map[string]any, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			v.logger.Warnw("Skipping unusable JWK", "kid", jwk.Kid, "error", err)
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	return keys, nil
+}
+
+func (k *jsonWebKey) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeJWKInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeJWKInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeJWKInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeJWKInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeJWKInt(value string) (*big.Int, error) {
+	if value == "" {
+		return nil, errors.New("missing key parameter")
+	}
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,403 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"peekaping/internal/config"
+	"peekaping/internal/modules/shared"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const (
+	testIssuer   = "https://idp.example.com/"
+	testAudience = "peekaping"
+)
+
+// mockJWKS serves the public halves of the given keys as a JWKS and counts the fetches.
+// While gate is set responses wait for it to be closed.
+type mockJWKS struct {
+	server  *httptest.Server
+	keys    map[string]*rsa.PrivateKey
+	fetches atomic.Int32
+	gate    chan struct{}
+}
+
+func newMockJWKS(t *testing.T, kids ...string) *mockJWKS {
+	t.Helper()
+
+	m := &mockJWKS{keys: make(map[string]*rsa.PrivateKey)}
+	for _, kid := range kids {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		m.keys[kid] = key
+	}
+
+	m.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.fetches.Add(1)
+		if m.gate != nil {
+			<-m.gate
+		}
+
+		var set []map[string]string
+		for kid, key := range m.keys {
+			set = append(set, map[string]string{
+				"kid": kid,
+				"kty": "RSA",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": set})
+	}))
+	t.Cleanup(m.server.Close)
+
+	return m
+}
+
+func (m *mockJWKS) sign(t *testing.T, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(m.keys[kid])
+	require.NoError(t, err)
+	return signed
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":   testIssuer,
+		"aud":   testAudience,
+		"sub":   "idp-user-1",
+		"email": "admin@example.com",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func newTestJWKSVerifier(t *testing.T, url string, adminRoles string) *JWKSVerifier {
+	t.Helper()
+
+	v, err := NewJWKSVerifier(&config.Config{
+		JWKSURL:        url,
+		JWKSIssuer:     testIssuer,
+		JWKSAudience:   testAudience,
+		JWKSEmailClaim: "email",
+		JWKSRoleClaim:  "roles",
+		JWKSAdminRoles: adminRoles,
+		JWKSCacheTTL:   time.Hour,
+	}, zap.NewNop().Sugar())
+	require.NoError(t, err)
+	require.NotNil(t, v)
+	return v
+}
+
+func TestNewJWKSVerifier(t *testing.T) {
+	v, err := NewJWKSVerifier(&config.Config{}, zap.NewNop().Sugar())
+	assert.NoError(t, err)
+	assert.Nil(t, v, "disabled without a JWKS URL")
+
+	_, err = NewJWKSVerifier(&config.Config{JWKSURL: "https://idp.example.com/jwks"}, zap.NewNop().Sugar())
+	assert.Error(t, err, "issuer and audience are required")
+}
+
+func TestJWKSVerifier_Verify(t *testing.T) {
+	jwks := newMockJWKS(t, "key-1")
+	v := newTestJWKSVerifier(t, jwks.server.URL, "")
+	ctx := context.Background()
+
+	t.Run("valid token", func(t *testing.T) {
+		identity, err := v.Verify(ctx, jwks.sign(t, "key-1", validClaims()))
+		require.NoError(t, err)
+		assert.Equal(t, "admin@example.com", identity.Email)
+		assert.Equal(t, "idp-user-1", identity.Subject)
+	})
+
+	t.Run("wrong issuer", func(t *testing.T) {
+		claims := validClaims()
+		claims["iss"] = "https://evil.example.com/"
+		_, err := v.Verify(ctx, jwks.sign(t, "key-1", claims))
+		assert.ErrorIs(t, err, jwt.ErrTokenInvalidIssuer)
+	})
+
+	t.Run("wrong audience", func(t *testing.T) {
+		claims := validClaims()
+		claims["aud"] = []string{"another-app"}
+		_, err := v.Verify(ctx, jwks.sign(t, "key-1", claims))
+		assert.ErrorIs(t, err, jwt.ErrTokenInvalidAudience)
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		claims := validClaims()
+		claims["exp"] = time.Now().Add(-time.Minute).Unix()
+		_, err := v.Verify(ctx, jwks.sign(t, "key-1", claims))
+		assert.ErrorIs(t, err, jwt.ErrTokenExpired)
+	})
+
+	t.Run("missing email claim", func(t *testing.T) {
+		claims := validClaims()
+		delete(claims, "email")
+		_, err := v.Verify(ctx, jwks.sign(t, "key-1", claims))
+		assert.Error(t, err)
+	})
+
+	t.Run("unverified email", func(t *testing.T) {
+		for _, verified := range []any{false, "false"} {
+			claims := validClaims()
+			claims["email_verified"] = verified
+			_, err := v.Verify(ctx, jwks.sign(t, "key-1", claims))
+			assert.ErrorIs(t, err, ErrUnverifiedEmail)
+		}
+	})
+
+	t.Run("verified email", func(t *testing.T) {
+		for _, verified := range []any{true, "true"} {
+			claims := validClaims()
+			claims["email_verified"] = verified
+			_, err := v.Verify(ctx, jwks.sign(t, "key-1", claims))
+			assert.NoError(t, err)
+		}
+	})
+
+	t.Run("signed by a key outside the set", func(t *testing.T) {
+		other := newMockJWKS(t, "key-1")
+		_, err := v.Verify(ctx, other.sign(t, "key-1", validClaims()))
+		assert.Error(t, err)
+	})
+
+	t.Run("HMAC tokens are refused", func(t *testing.T) {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, validClaims()).SignedString([]byte("secret"))
+		require.NoError(t, err)
+		_, err = v.Verify(ctx, token)
+		assert.Error(t, err)
+	})
+
+	assert.Equal(t, int32(1), jwks.fetches.Load(), "keys are cached")
+}
+
+func TestJWKSVerifier_KeyRotation(t *testing.T) {
+	jwks := newMockJWKS(t, "key-1")
+	v := newTestJWKSVerifier(t, jwks.server.URL, "")
+	ctx := context.Background()
+
+	_, err := v.Verify(ctx, jwks.sign(t, "key-1", validClaims()))
+	require.NoError(t, err)
+
+	// The provider publishes a new key, an unknown kid refreshes the set once the
+	// minimum refresh interval has passed
+	rotated := newMockJWKS(t, "key-2")
+	jwks.keys["key-2"] = rotated.keys["key-2"]
+	v.fetchedAt = time.Now().Add(-jwksMinRefreshInterval - time.Second)
+
+	_, err = v.Verify(ctx, jwks.sign(t, "key-2", validClaims()))
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), jwks.fetches.Load())
+
+	// Unknown kids don't refetch again right away
+	_, err = v.Verify(ctx, jwks.sign(t, "key-1", validClaims()))
+	require.NoError(t, err)
+	rogue := newMockJWKS(t, "key-3")
+	_, err = v.Verify(ctx, rogue.sign(t, "key-3", validClaims()))
+	assert.ErrorIs(t, err, ErrUnknownSigningKey)
+	assert.Equal(t, int32(2), jwks.fetches.Load())
+}
+
+func TestJWKSVerifier_RefreshDoesNotBlockKnownKeys(t *testing.T) {
+	jwks := newMockJWKS(t, "key-1")
+	v := newTestJWKSVerifier(t, jwks.server.URL, "")
+	ctx := context.Background()
+
+	_, err := v.Verify(ctx, jwks.sign(t, "key-1", validClaims()))
+	require.NoError(t, err)
+	v.fetchedAt = time.Now().Add(-jwksMinRefreshInterval - time.Second)
+
+	// Tokens with an unknown kid wait for a single refresh
+	jwks.gate = make(chan struct{})
+	rogueToken := newMockJWKS(t, "key-3").sign(t, "key-3", validClaims())
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := v.Verify(ctx, rogueToken)
+			assert.ErrorIs(t, err, ErrUnknownSigningKey)
+		}()
+	}
+	require.Eventually(t, func() bool { return jwks.fetches.Load() == 2 }, time.Second, 5*time.Millisecond)
+
+	// while tokens with a cached key are verified right away
+	token := jwks.sign(t, "key-1", validClaims())
+	done := make(chan error, 1)
+	go func() {
+		_, err := v.Verify(ctx, token)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("verifying a cached key waited for the refresh")
+	}
+
+	close(jwks.gate)
+	wg.Wait()
+	assert.Equal(t, int32(2), jwks.fetches.Load())
+}
+
+func TestJWKSVerifier_AdminRoles(t *testing.T) {
+	jwks := newMockJWKS(t, "key-1")
+	v := newTestJWKSVerifier(t, jwks.server.URL, "peekaping-admin, ops")
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		roles any
+		ok    bool
+	}{
+		{"role in array", []string{"viewer", "ops"}, true},
+		{"role as string", "peekaping-admin", true},
+		{"space-separated roles", "read peekaping-admin", true},
+		{"no allowed role", []string{"viewer"}, false},
+		{"no role claim", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := validClaims()
+			if tt.roles != nil {
+				claims["roles"] = tt.roles
+			}
+			_, err := v.Verify(ctx, jwks.sign(t, "key-1", claims))
+			if tt.ok {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrMissingRole)
+			}
+		})
+	}
+}
+
+// mockUserRepository is a mock implementation of Repository for testing
+type mockUserRepository struct {
+	mock.Mock
+}
+
+func (m *mockUserRepository) Create(ctx context.Context, user *Model) (*Model, error) {
+	args := m.Called(ctx, user)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Model), args.Error(1)
+}
+
+func (m *mockUserRepository) FindByEmail(ctx context.Context, email string) (*Model, error) {
+	args := m.Called(ctx, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Model), args.Error(1)
+}
+
+func (m *mockUserRepository) FindByID(ctx context.Context, id string) (*Model, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Model), args.Error(1)
+}
+
+func (m *mockUserRepository) FindAllCount(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockUserRepository) Update(ctx context.Context, id string, entity *UpdateModel) error {
+	args := m.Called(ctx, id, entity)
+	return args.Error(0)
+}
+
+func TestMiddlewareProvider_Auth_ExternalToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	jwks := newMockJWKS(t, "key-1")
+
+	settingService := new(MockSettingService)
+	settingService.On("GetByKey", mock.Anything, "ACCESS_TOKEN_SECRET_KEY").
+		Return(&shared.SettingModel{Key: "ACCESS_TOKEN_SECRET_KEY", Value: "internal-secret"}, nil)
+
+	repo := new(mockUserRepository)
+	repo.On("FindByEmail", mock.Anything, "admin@example.com").Return(&Model{ID: "user-1", Email: "admin@example.com", Active: true}, nil)
+	repo.On("FindByEmail", mock.Anything, "stranger@example.com").Return(nil, nil)
+	repo.On("FindByID", mock.Anything, "user-2").Return(&Model{ID: "user-2", Active: true}, nil)
+
+	provider := NewMiddlewareProvider(
+		NewTokenMaker(settingService, zap.NewNop().Sugar()),
+		newTestJWKSVerifier(t, jwks.server.URL, ""),
+		repo,
+		zap.NewNop().Sugar(),
+	)
+
+	router := gin.New()
+	router.GET("/protected", provider.Auth(), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"userId": c.GetString("userId")})
+	})
+
+	request := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("mapped to the user with the same email", func(t *testing.T) {
+		rec := request(jwks.sign(t, "key-1", validClaims()))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"userId":"user-1"}`, rec.Body.String())
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		claims := validClaims()
+		claims["email"] = "stranger@example.com"
+		rec := request(jwks.sign(t, "key-1", claims))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("wrong audience", func(t *testing.T) {
+		claims := validClaims()
+		claims["aud"] = "another-app"
+		rec := request(jwks.sign(t, "key-1", claims))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("Peekaping tokens still work", func(t *testing.T) {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{
+			UserID: "user-2",
+			Type:   "access",
+			RegisteredClaims: jwt.RegisteredClaims{
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			},
+		}).SignedString([]byte("internal-secret"))
+		require.NoError(t, err)
+
+		rec := request(token)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"userId":"user-2"}`, rec.Body.String())
+	})
+}
@@ -20,6 +20,8 @@ type Claims struct {
 	UserID string `json:"userId"`
 	Email  string `json:"email"`
 	Type   string `json:"type"` // "access" or "refresh"
+	// TokenVersion is the user's token version when the token was issued
+	TokenVersion int `json:"ver,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -91,9 +93,10 @@ func (maker *TokenMaker) CreateRefreshToken(ctx context.Context, user *Model) (s
 
 func (maker *TokenMaker) createToken(user *Model, tokenType string, duration time.Duration, secretKey string) (string, error) {
 	claims := &Claims{
-		UserID: user.ID,
-		Email:  user.Email,
-		Type:   tokenType,
+		UserID:       user.ID,
+		Email:        user.Email,
+		Type:         tokenType,
+		TokenVersion: user.TokenVersion,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(duration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
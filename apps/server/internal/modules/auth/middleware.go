@@ -1,26 +1,36 @@
 package auth
 
 import (
+	"errors"
 	"net/http"
 	"peekaping/internal/utils"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 // MiddlewareProvider holds all middleware functions
 type MiddlewareProvider struct {
 	tokenMaker *TokenMaker
+	jwks       *JWKSVerifier
+	repo       Repository
+	logger     *zap.SugaredLogger
 }
 
-// NewMiddlewareProvider creates a new middleware provider
-func NewMiddlewareProvider(tokenMaker *TokenMaker) *MiddlewareProvider {
+// NewMiddlewareProvider creates a new middleware provider. jwks is nil unless external
+// identity provider tokens are enabled.
+func NewMiddlewareProvider(tokenMaker *TokenMaker, jwks *JWKSVerifier, repo Repository, logger *zap.SugaredLogger) *MiddlewareProvider {
 	return &MiddlewareProvider{
 		tokenMaker: tokenMaker,
+		jwks:       jwks,
+		repo:       repo,
+		logger:     logger.Named("[auth-middleware]"),
 	}
 }
 
-// Auth is a middleware that verifies JWT access tokens only
+// Auth is a middleware that verifies JWT access tokens. Tokens that Peekaping didn't
+// issue are checked against the identity provider's JWKS when one is configured.
 func (p *MiddlewareProvider) Auth() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get the Authorization header
@@ -50,6 +60,10 @@ func (p *MiddlewareProvider) Auth() gin.HandlerFunc {
 
 		// Verify the token
 		claims, err := p.tokenMaker.VerifyToken(c.Request.Context(), accessToken, "access")
+		if err != nil && p.jwks != nil {
+			p.authExternal(c, accessToken)
+			return
+		}
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, utils.NewFailResponse("Invalid or expired token"))
 			c.Abort()
@@ -63,6 +77,18 @@ func (p *MiddlewareProvider) Auth() gin.HandlerFunc {
 			return
 		}
 
+		if _, err := CheckSession(c.Request.Context(), p.repo, claims); err != nil {
+			if errors.Is(err, ErrRevokedToken) {
+				c.JSON(http.StatusUnauthorized, utils.NewFailResponse("Invalid or expired token"))
+				c.Abort()
+				return
+			}
+			p.logger.Errorw("Failed to find user for token", "error", err)
+			c.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
+			c.Abort()
+			return
+		}
+
 		// Set user information in the context
 		c.Set("userId", claims.UserID)
 		c.Set("email", claims.Email)
@@ -71,3 +97,35 @@ func (p *MiddlewareProvider) Auth() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// authExternal authenticates an identity provider token and maps it to the Peekaping
+// user with the same email
+func (p *MiddlewareProvider) authExternal(c *gin.Context, token string) {
+	identity, err := p.jwks.Verify(c.Request.Context(), token)
+	if err != nil {
+		p.logger.Debugw("External token rejected", "ip", c.ClientIP(), "error", err)
+		c.JSON(http.StatusUnauthorized, utils.NewFailResponse("Invalid or expired token"))
+		c.Abort()
+		return
+	}
+
+	user, err := p.repo.FindByEmail(c.Request.Context(), identity.Email)
+	if err != nil {
+		p.logger.Errorw("Failed to find user for external token", "error", err)
+		c.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
+		c.Abort()
+		return
+	}
+	if user == nil || !user.Active {
+		p.logger.Warnw("External token for unknown or inactive user", "email", identity.Email, "subject", identity.Subject)
+		c.JSON(http.StatusUnauthorized, utils.NewFailResponse("Invalid or expired token"))
+		c.Abort()
+		return
+	}
+
+	c.Set("userId", user.ID)
+	c.Set("email", user.Email)
+	c.Set("authType", "jwt")
+
+	c.Next()
+}
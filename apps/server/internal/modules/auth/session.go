@@ -0,0 +1,22 @@
+package auth
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrRevokedToken is returned for tokens issued before their user logged out of all sessions
+var ErrRevokedToken = errors.New("token has been revoked")
+
+// CheckSession loads the user of a verified token and rejects the token when the user
+// no longer exists or revoked its sessions since it was issued
+func CheckSession(ctx context.Context, repo Repository, claims *Claims) (*Model, error) {
+	user, err := repo.FindByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.TokenVersion != claims.TokenVersion {
+		return nil, ErrRevokedToken
+	}
+	return user, nil
+}
@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"peekaping/internal/modules/events"
+	"peekaping/internal/modules/shared"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// singleUserRepository keeps one user in memory, other Repository methods aren't used
+type singleUserRepository struct {
+	Repository
+	user Model
+}
+
+func (r *singleUserRepository) FindByID(ctx context.Context, id string) (*Model, error) {
+	if id != r.user.ID {
+		return nil, nil
+	}
+	user := r.user
+	return &user, nil
+}
+
+func (r *singleUserRepository) Update(ctx context.Context, id string, entity *UpdateModel) error {
+	if entity.IncrementTokenVersion {
+		r.user.TokenVersion++
+	}
+	return nil
+}
+
+// recordingEventBus keeps the published events
+type recordingEventBus struct {
+	published []events.Event
+}
+
+func (b *recordingEventBus) Subscribe(eventType events.EventType, handler events.EventHandler) {}
+
+func (b *recordingEventBus) Publish(event events.Event) {
+	b.published = append(b.published, event)
+}
+
+func (b *recordingEventBus) Close() error { return nil }
+
+func newSessionTokenMaker() *TokenMaker {
+	settingService := new(MockSettingService)
+	for key, value := range map[string]string{
+		"ACCESS_TOKEN_EXPIRED_IN":  "15m",
+		"ACCESS_TOKEN_SECRET_KEY":  "access-secret",
+		"REFRESH_TOKEN_EXPIRED_IN": "720h",
+		"REFRESH_TOKEN_SECRET_KEY": "refresh-secret",
+	} {
+		settingService.On("GetByKey", mock.Anything, key).Return(&shared.SettingModel{Key: key, Value: value}, nil)
+	}
+	return NewTokenMaker(settingService, zap.NewNop().Sugar())
+}
+
+func TestCheckSession(t *testing.T) {
+	repo := new(mockUserRepository)
+	repo.On("FindByID", mock.Anything, "user-1").Return(&Model{ID: "user-1", TokenVersion: 2}, nil)
+	repo.On("FindByID", mock.Anything, "deleted").Return(nil, nil)
+
+	user, err := CheckSession(context.Background(), repo, &Claims{UserID: "user-1", TokenVersion: 2})
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", user.ID)
+
+	_, err = CheckSession(context.Background(), repo, &Claims{UserID: "user-1", TokenVersion: 1})
+	assert.ErrorIs(t, err, ErrRevokedToken)
+
+	_, err = CheckSession(context.Background(), repo, &Claims{UserID: "deleted"})
+	assert.ErrorIs(t, err, ErrRevokedToken)
+}
+
+func TestMiddlewareProvider_Auth_RevokedSessions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tokenMaker := newSessionTokenMaker()
+
+	repo := &singleUserRepository{user: Model{ID: "user-1", Email: "admin@example.com", Active: true}}
+	user := &repo.user
+
+	provider := NewMiddlewareProvider(tokenMaker, nil, repo, zap.NewNop().Sugar())
+	eventBus := &recordingEventBus{}
+	service := NewService(repo, tokenMaker, eventBus, zap.NewNop().Sugar())
+
+	router := gin.New()
+	router.GET("/protected", provider.Auth(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	request := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	accessToken, err := tokenMaker.CreateAccessToken(context.Background(), user)
+	require.NoError(t, err)
+	refreshToken, err := tokenMaker.CreateRefreshToken(context.Background(), user)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, request(accessToken))
+
+	require.NoError(t, service.RevokeSessions(context.Background(), "user-1"))
+	assert.Equal(t, 1, user.TokenVersion)
+
+	// Open websocket connections of the user are closed
+	require.Len(t, eventBus.published, 1)
+	assert.Equal(t, events.SessionsRevoked, eventBus.published[0].Type)
+	assert.Equal(t, &events.SessionsRevokedPayload{UserID: "user-1"}, eventBus.published[0].Payload)
+
+	// Tokens issued before the revocation are rejected, new ones work
+	assert.Equal(t, http.StatusUnauthorized, request(accessToken))
+	_, err = service.RefreshToken(context.Background(), refreshToken)
+	assert.Error(t, err)
+
+	newAccessToken, err := tokenMaker.CreateAccessToken(context.Background(), user)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, request(newAccessToken))
+}
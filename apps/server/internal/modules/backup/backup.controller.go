@@ -0,0 +1,191 @@
+package backup
+
+import (
+	"fmt"
+	"net/http"
+	"peekaping/internal/modules/audit_log"
+	"peekaping/internal/modules/maintenance"
+	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/monitor_notification"
+	"peekaping/internal/modules/monitor_status_page"
+	"peekaping/internal/modules/monitor_tag"
+	"peekaping/internal/modules/notification_channel"
+	"peekaping/internal/modules/proxy"
+	"peekaping/internal/modules/setting"
+	"peekaping/internal/modules/status_page"
+	"peekaping/internal/modules/tag"
+	"peekaping/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/dig"
+	"go.uber.org/zap"
+)
+
+const (
+	// PassphraseHeader carries the passphrase the secrets of a bundle are encrypted with
+	PassphraseHeader = "X-Backup-Passphrase"
+	// maxRestoreMonitors bounds the monitors of a single bundle
+	maxRestoreMonitors = 10000
+)
+
+// MonitorRestorer creates monitors with the validation of POST /monitors, implemented by
+// the monitor controller
+type MonitorRestorer interface {
+	Restore(ctx *gin.Context, dto *monitor.CreateUpdateDto) (*monitor.Model, error)
+}
+
+// ControllerDependencies are the services whose entities are backed up
+type ControllerDependencies struct {
+	dig.In
+	MonitorRestorer            MonitorRestorer
+	MonitorService             monitor.Service
+	MonitorNotificationService monitor_notification.Service
+	MonitorTagService          monitor_tag.Service
+	ChannelService             notification_channel.Service
+	TagService                 tag.Service
+	ProxyService               proxy.Service
+	MaintenanceService         maintenance.Service
+	StatusPageService          status_page.Service
+	MonitorStatusPageService   monitor_status_page.Service
+	SettingService             setting.Service
+	AuditLogService            audit_log.Service
+	Logger                     *zap.SugaredLogger
+}
+
+type Controller struct {
+	monitorRestorer            MonitorRestorer
+	monitorService             monitor.Service
+	monitorNotificationService monitor_notification.Service
+	monitorTagService          monitor_tag.Service
+	channelService             notification_channel.Service
+	tagService                 tag.Service
+	proxyService               proxy.Service
+	maintenanceService         maintenance.Service
+	statusPageService          status_page.Service
+	monitorStatusPageService   monitor_status_page.Service
+	settingService             setting.Service
+	auditLogService            audit_log.Service
+	logger                     *zap.SugaredLogger
+}
+
+func NewController(deps ControllerDependencies) *Controller {
+	return &Controller{
+		monitorRestorer:            deps.MonitorRestorer,
+		monitorService:             deps.MonitorService,
+		monitorNotificationService: deps.MonitorNotificationService,
+		monitorTagService:          deps.MonitorTagService,
+		channelService:             deps.ChannelService,
+		tagService:                 deps.TagService,
+		proxyService:               deps.ProxyService,
+		maintenanceService:         deps.MaintenanceService,
+		statusPageService:          deps.StatusPageService,
+		monitorStatusPageService:   deps.MonitorStatusPageService,
+		settingService:             deps.SettingService,
+		auditLogService:            deps.AuditLogService,
+		logger:                     deps.Logger.Named("[backup]"),
+	}
+}
+
+// recordCreated writes the audit log entry of a restored entity like the controller of
+// its module does. Failures are only logged so that auditing never fails the restore.
+func (c *Controller) recordCreated(ctx *gin.Context, entityType, id string, created any) {
+	if _, err := c.auditLogService.Record(ctx, entityType, id, audit_log.ActionCreate, nil, created); err != nil {
+		c.logger.Errorw("Failed to record audit log", "entity_type", entityType, "id", id, "error", err)
+	}
+}
+
+// @Router		/admin/backup [get]
+// @Summary		Back up the configuration
+// @Description	Downloads the proxies, tags, notification channels, monitors, maintenance windows, status pages and settings as a JSON bundle for POST /admin/restore. With the X-Backup-Passphrase header the notification channel configs, monitor configs and push tokens and proxy passwords are encrypted with it, otherwise they are in plain text. Heartbeats, users and API keys are not included.
+// @Tags			Admin
+// @Produce		json
+// @Security  JwtAuth
+// @Security  ApiKeyAuth
+// @Param     X-Backup-Passphrase header string false "Passphrase the secrets are encrypted with"
+// @Success		200	{object}	BundleDto
+// @Failure		500	{object}	utils.APIError[any]
+func (c *Controller) Backup(ctx *gin.Context) {
+	bundle, err := c.exportBundle(ctx)
+	if err != nil {
+		c.logger.Errorw("Failed to export backup", "error", err)
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
+		return
+	}
+
+	if passphrase := ctx.GetHeader(PassphraseHeader); passphrase != "" {
+		if err := sealSecrets(bundle, passphrase); err != nil {
+			c.logger.Errorw("Failed to encrypt backup", "error", err)
+			ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
+			return
+		}
+	}
+
+	filename := fmt.Sprintf("peekaping-backup-%s.json", bundle.CreatedAt.Format("20060102-150405"))
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	ctx.JSON(http.StatusOK, bundle)
+}
+
+// @Router		/admin/restore [post]
+// @Summary		Restore a configuration backup
+// @Description	Recreates the entities of a bundle from GET /admin/backup and maps their references to the new ids. The bundle is refused as a whole when an entity references one that isn't in it. Tags and notification channels of the same name (and type) are reused, status pages whose slug is taken fail. Encrypted bundles need their passphrase in the X-Backup-Passphrase header. The report lists what became of every item.
+// @Tags			Admin
+// @Produce		json
+// @Accept		json
+// @Security  JwtAuth
+// @Security  ApiKeyAuth
+// @Param     X-Backup-Passphrase header string false "Passphrase the secrets are encrypted with"
+// @Param     body body   BundleDto  true  "Backup bundle"
+// @Success		200	{object}	utils.ApiResponse[RestoreReportDto]
+// @Failure		400	{object}	utils.APIError[any]
+// @Failure		500	{object}	utils.APIError[any]
+func (c *Controller) Restore(ctx *gin.Context) {
+	var bundle BundleDto
+	if err := ctx.ShouldBindJSON(&bundle); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewFailResponse(err.Error()))
+		return
+	}
+	if bundle.Version < 1 || bundle.Version > BundleVersion {
+		ctx.JSON(http.StatusBadRequest, utils.NewFailResponse(fmt.Sprintf("Unsupported backup version %d", bundle.Version)))
+		return
+	}
+	if len(bundle.Monitors) > maxRestoreMonitors {
+		ctx.JSON(http.StatusBadRequest, utils.NewFailResponse(fmt.Sprintf("At most %d monitors can be restored at once", maxRestoreMonitors)))
+		return
+	}
+
+	if bundle.Encryption != nil {
+		passphrase := ctx.GetHeader(PassphraseHeader)
+		if passphrase == "" {
+			ctx.JSON(http.StatusBadRequest, utils.NewFailResponse("The backup is encrypted, send its passphrase in the "+PassphraseHeader+" header"))
+			return
+		}
+		if err := openSecrets(&bundle, passphrase); err != nil {
+			ctx.JSON(http.StatusBadRequest, utils.NewFailResponse(err.Error()))
+			return
+		}
+	}
+
+	if problems := validateReferences(&bundle); len(problems) > 0 {
+		ctx.JSON(http.StatusBadRequest, utils.NewFailResponse("Invalid backup: "+strings.Join(problems, "; ")))
+		return
+	}
+
+	report, err := c.restoreBundle(ctx, &bundle)
+	if err != nil {
+		c.logger.Errorw("Failed to restore backup", "error", err)
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
+		return
+	}
+
+	restored, total := 0, 0
+	for _, section := range report.sections() {
+		restored += section.Created + section.Existing
+		total += len(section.Items)
+	}
+	ctx.JSON(http.StatusOK, utils.NewSuccessResponse(fmt.Sprintf("Restored %d of %d items", restored, total), report))
+}
+
+// now is replaced in tests
+var now = func() time.Time { return time.Now().UTC() }
@@ -0,0 +1,162 @@
+package backup
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	encryptionAlgorithm = "aes-256-gcm"
+	encryptionKDF       = "scrypt"
+	encryptedPrefix     = "enc:"
+)
+
+// ErrWrongPassphrase is returned when the secrets of a bundle can't be decrypted
+var ErrWrongPassphrase = errors.New("wrong backup passphrase")
+
+// secretBox encrypts the secrets of a bundle with a key derived from a passphrase
+type secretBox struct {
+	aead cipher.AEAD
+}
+
+// newSecretBox derives the key of an encrypted bundle
+func newSecretBox(passphrase string, encryption *EncryptionDto) (*secretBox, error) {
+	if encryption.Algorithm != encryptionAlgorithm || encryption.KDF != encryptionKDF {
+		return nil, fmt.Errorf("unsupported backup encryption %s with %s", encryption.Algorithm, encryption.KDF)
+	}
+	salt, err := base64.StdEncoding.DecodeString(encryption.Salt)
+	if err != nil || len(salt) == 0 {
+		return nil, errors.New("invalid backup encryption salt")
+	}
+
+	key, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &secretBox{aead: aead}, nil
+}
+
+func (b *secretBox) seal(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := b.aead.Seal(nonce, nonce, []byte(value), nil)
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// open decrypts a sealed value, values without the prefix are returned as they are
+func (b *secretBox) open(value string) (string, error) {
+	encoded, ok := strings.CutPrefix(value, encryptedPrefix)
+	if !ok {
+		return value, nil
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < b.aead.NonceSize() {
+		return "", ErrWrongPassphrase
+	}
+	plain, err := b.aead.Open(nil, sealed[:b.aead.NonceSize()], sealed[b.aead.NonceSize():], nil)
+	if err != nil {
+		return "", ErrWrongPassphrase
+	}
+	return string(plain), nil
+}
+
+// sealSecrets encrypts the notification channel configs, monitor configs and push tokens
+// and proxy passwords of a bundle
+func sealSecrets(bundle *BundleDto, passphrase string) error {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	bundle.Encryption = &EncryptionDto{
+		Algorithm: encryptionAlgorithm,
+		KDF:       encryptionKDF,
+		Salt:      base64.StdEncoding.EncodeToString(salt),
+	}
+	box, err := newSecretBox(passphrase, bundle.Encryption)
+	if err != nil {
+		return err
+	}
+	for i := range bundle.NotificationChannels {
+		if bundle.NotificationChannels[i].Config, err = box.seal(bundle.NotificationChannels[i].Config); err != nil {
+			return err
+		}
+	}
+	for i := range bundle.Monitors {
+		if bundle.Monitors[i].Config, err = box.seal(bundle.Monitors[i].Config); err != nil {
+			return err
+		}
+		if bundle.Monitors[i].PushToken, err = box.seal(bundle.Monitors[i].PushToken); err != nil {
+			return err
+		}
+	}
+	for i := range bundle.Proxies {
+		if bundle.Proxies[i].Password, err = box.seal(bundle.Proxies[i].Password); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// openSecrets decrypts the secrets sealed by sealSecrets. The bundle is left as it is
+// when one of them can't be decrypted.
+func openSecrets(bundle *BundleDto, passphrase string) error {
+	box, err := newSecretBox(passphrase, bundle.Encryption)
+	if err != nil {
+		return err
+	}
+	configs := make([]string, len(bundle.NotificationChannels))
+	for i := range bundle.NotificationChannels {
+		if configs[i], err = box.open(bundle.NotificationChannels[i].Config); err != nil {
+			return err
+		}
+	}
+	monitorConfigs := make([]string, len(bundle.Monitors))
+	pushTokens := make([]string, len(bundle.Monitors))
+	for i := range bundle.Monitors {
+		if monitorConfigs[i], err = box.open(bundle.Monitors[i].Config); err != nil {
+			return err
+		}
+		if pushTokens[i], err = box.open(bundle.Monitors[i].PushToken); err != nil {
+			return err
+		}
+	}
+	passwords := make([]string, len(bundle.Proxies))
+	for i := range bundle.Proxies {
+		if passwords[i], err = box.open(bundle.Proxies[i].Password); err != nil {
+			return err
+		}
+	}
+
+	for i := range bundle.NotificationChannels {
+		bundle.NotificationChannels[i].Config = configs[i]
+	}
+	for i := range bundle.Monitors {
+		bundle.Monitors[i].Config = monitorConfigs[i]
+		bundle.Monitors[i].PushToken = pushTokens[i]
+	}
+	for i := range bundle.Proxies {
+		bundle.Proxies[i].Password = passwords[i]
+	}
+	bundle.Encryption = nil
+	return nil
+}
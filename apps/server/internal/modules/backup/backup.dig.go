@@ -0,0 +1,13 @@
+package backup
+
+import (
+	"peekaping/internal/modules/monitor"
+
+	"go.uber.org/dig"
+)
+
+func RegisterDependencies(container *dig.Container) {
+	container.Provide(func(controller *monitor.MonitorController) MonitorRestorer { return controller })
+	container.Provide(NewController)
+	container.Provide(NewRoute)
+}
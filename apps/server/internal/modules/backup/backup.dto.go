@@ -0,0 +1,131 @@
+package backup
+
+import (
+	"peekaping/internal/modules/maintenance"
+	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/status_page"
+	"time"
+)
+
+// BundleVersion is the version of the bundle format written by this server
+const BundleVersion = 1
+
+// BundleDto is a backup of the configuration of a Peekaping instance. Entities reference
+// each other by their id in the bundle, restoring maps them to the ids of the entities
+// it creates.
+type BundleDto struct {
+	Version   int       `json:"version" example:"1"`
+	CreatedAt time.Time `json:"created_at"`
+	// Encryption describes how the secrets are encrypted, nil when they are in plain text
+	Encryption           *EncryptionDto           `json:"encryption,omitempty"`
+	Proxies              []ProxyDto               `json:"proxies"`
+	Tags                 []TagDto                 `json:"tags"`
+	NotificationChannels []NotificationChannelDto `json:"notification_channels"`
+	Monitors             []MonitorDto             `json:"monitors"`
+	Maintenances         []MaintenanceDto         `json:"maintenances"`
+	StatusPages          []StatusPageDto          `json:"status_pages"`
+	Settings             []SettingDto             `json:"settings"`
+}
+
+// EncryptionDto describes how the secrets of a bundle are encrypted: notification channel
+// configs and proxy passwords hold "enc:" followed by the base64 of the nonce and the
+// AES-256-GCM sealed value, with the key derived from the passphrase by scrypt
+type EncryptionDto struct {
+	Algorithm string `json:"algorithm" example:"aes-256-gcm"`
+	KDF       string `json:"kdf" example:"scrypt"`
+	Salt      string `json:"salt" example:"q83vEjRWeJCrze8SNFZ4kA=="`
+}
+
+type ProxyDto struct {
+	ID       string `json:"id"`
+	Protocol string `json:"protocol" example:"http"`
+	Host     string `json:"host" example:"proxy.internal"`
+	Port     int    `json:"port" example:"3128"`
+	Auth     bool   `json:"auth"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+type TagDto struct {
+	ID          string  `json:"id"`
+	Name        string  `json:"name" example:"Production"`
+	Color       string  `json:"color" example:"#3B82F6"`
+	Description *string `json:"description,omitempty"`
+}
+
+type NotificationChannelDto struct {
+	ID        string `json:"id"`
+	Name      string `json:"name" example:"Ops Slack"`
+	Type      string `json:"type" example:"slack"`
+	Active    bool   `json:"active"`
+	IsDefault bool   `json:"is_default"`
+	Config    string `json:"config"`
+}
+
+// MonitorDto is a monitor as created by POST /monitors, with the ids of its notification
+// channels, tags, proxies and parent taken from the bundle
+type MonitorDto struct {
+	ID string `json:"id"`
+	monitor.CreateUpdateDto
+}
+
+// MaintenanceDto is a maintenance window with the ids of its monitors taken from the bundle
+type MaintenanceDto struct {
+	ID string `json:"id"`
+	maintenance.CreateUpdateDto
+	// Rejected keeps a rejected window rejected, ApprovalStatus only takes pending or approved
+	Rejected   bool    `json:"rejected,omitempty"`
+	ApprovedBy *string `json:"approved_by,omitempty"`
+}
+
+// StatusPageDto is a status page with the ids of its monitors, in display order, and of
+// its subscriber channel taken from the bundle. Page passwords are only stored hashed and
+// can't be exported.
+type StatusPageDto struct {
+	ID string `json:"id"`
+	status_page.CreateStatusPageDTO
+	// HiddenMonitorIDs are the monitors of MonitorIDs hidden from the page
+	HiddenMonitorIDs  []string `json:"hidden_monitor_ids,omitempty"`
+	PasswordProtected bool     `json:"password_protected,omitempty"`
+}
+
+type SettingDto struct {
+	Key   string `json:"key" example:"check_during_maintenance"`
+	Value string `json:"value" example:"false"`
+	Type  string `json:"type" example:"bool"`
+}
+
+const (
+	StatusCreated  = "created"
+	StatusExisting = "existing"
+	StatusFailed   = "failed"
+)
+
+// ItemResultDto reports what became of one entity of a restored bundle
+type ItemResultDto struct {
+	Name string `json:"name" example:"API"`
+	// ID is the id of the entity in the bundle, NewID the one of the restored entity
+	ID       string   `json:"id"`
+	NewID    string   `json:"new_id,omitempty"`
+	Status   string   `json:"status" example:"created"`
+	Reason   string   `json:"reason,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// SectionReportDto sums up the restore of one kind of entity
+type SectionReportDto struct {
+	Created  int             `json:"created"`
+	Existing int             `json:"existing"`
+	Failed   int             `json:"failed"`
+	Items    []ItemResultDto `json:"items"`
+}
+
+type RestoreReportDto struct {
+	Proxies              SectionReportDto `json:"proxies"`
+	Tags                 SectionReportDto `json:"tags"`
+	NotificationChannels SectionReportDto `json:"notification_channels"`
+	Monitors             SectionReportDto `json:"monitors"`
+	Maintenances         SectionReportDto `json:"maintenances"`
+	StatusPages          SectionReportDto `json:"status_pages"`
+	Settings             SectionReportDto `json:"settings"`
+}
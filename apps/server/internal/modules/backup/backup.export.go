@@ -0,0 +1,378 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"peekaping/internal/modules/audit_log"
+	"peekaping/internal/modules/certificate"
+	"peekaping/internal/modules/maintenance"
+	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/notification_channel"
+	"peekaping/internal/modules/notification_sent_history"
+	"peekaping/internal/modules/producer"
+	"peekaping/internal/modules/proxy"
+	"peekaping/internal/modules/setting"
+	"peekaping/internal/modules/stats"
+	"peekaping/internal/modules/status_page"
+	"peekaping/internal/modules/tag"
+	"slices"
+	"sort"
+)
+
+// pageSize is how many entities are loaded at once while exporting
+const pageSize = 100
+
+// settingKeys are the settings a bundle carries. The token secrets are left out so a
+// restore doesn't sign out everyone and copies of a backup can't forge tokens.
+var settingKeys = []string{
+	"ACCESS_TOKEN_EXPIRED_IN",
+	"REFRESH_TOKEN_EXPIRED_IN",
+	"cert_expiry_notify_days",
+	certificate.ExpectedIssuersSettingKey,
+	maintenance.CheckDuringMaintenanceSettingKey,
+	maintenance.RequireApprovalSettingKey,
+	stats.UptimeMethodSettingKey,
+	setting.MonitoringPausedSettingKey,
+	notification_sent_history.RetentionDaysSettingKey,
+	audit_log.RetentionDaysSettingKey,
+	monitor.ImportanceWindowSettingKey,
+	producer.BatchClaimSettingKey,
+	producer.ClaimTickSettingKey,
+	proxy.DefaultProxySettingKey,
+	notification_channel.AdminNotificationChannelSettingKey,
+}
+
+// paginate calls fetch for pages of pageSize until one comes back short
+func paginate[T any](fetch func(page int) ([]T, error)) ([]T, error) {
+	all := make([]T, 0)
+	for page := 0; ; page++ {
+		items, err := fetch(page)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, items...)
+		if len(items) < pageSize {
+			return all, nil
+		}
+	}
+}
+
+// exportBundle reads the configuration into a bundle with the secrets in plain text
+func (c *Controller) exportBundle(ctx context.Context) (*BundleDto, error) {
+	bundle := &BundleDto{
+		Version:   BundleVersion,
+		CreatedAt: now(),
+	}
+
+	var err error
+	if bundle.Proxies, err = c.exportProxies(ctx); err != nil {
+		return nil, err
+	}
+	if bundle.Tags, err = c.exportTags(ctx); err != nil {
+		return nil, err
+	}
+	if bundle.NotificationChannels, err = c.exportChannels(ctx); err != nil {
+		return nil, err
+	}
+	if bundle.Monitors, err = c.exportMonitors(ctx); err != nil {
+		return nil, err
+	}
+	if bundle.Maintenances, err = c.exportMaintenances(ctx); err != nil {
+		return nil, err
+	}
+	if bundle.StatusPages, err = c.exportStatusPages(ctx); err != nil {
+		return nil, err
+	}
+	if bundle.Settings, err = c.exportSettings(ctx); err != nil {
+		return nil, err
+	}
+
+	return bundle, nil
+}
+
+func (c *Controller) exportProxies(ctx context.Context) ([]ProxyDto, error) {
+	proxies, err := paginate(func(page int) ([]*proxy.Model, error) {
+		return c.proxyService.FindAll(ctx, page, pageSize, "")
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch proxies: %w", err)
+	}
+
+	exported := make([]ProxyDto, 0, len(proxies))
+	for _, p := range proxies {
+		exported = append(exported, ProxyDto{
+			ID:       p.ID,
+			Protocol: p.Protocol,
+			Host:     p.Host,
+			Port:     p.Port,
+			Auth:     p.Auth,
+			Username: p.Username,
+			Password: p.Password,
+		})
+	}
+	return exported, nil
+}
+
+func (c *Controller) exportTags(ctx context.Context) ([]TagDto, error) {
+	tags, err := paginate(func(page int) ([]*tag.Model, error) {
+		return c.tagService.FindAll(ctx, page, pageSize, "")
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch tags: %w", err)
+	}
+
+	exported := make([]TagDto, 0, len(tags))
+	for _, t := range tags {
+		exported = append(exported, TagDto{
+			ID:          t.ID,
+			Name:        t.Name,
+			Color:       t.Color,
+			Description: t.Description,
+		})
+	}
+	return exported, nil
+}
+
+func (c *Controller) exportChannels(ctx context.Context) ([]NotificationChannelDto, error) {
+	channels, err := paginate(func(page int) ([]*notification_channel.Model, error) {
+		return c.channelService.FindAll(ctx, page, pageSize, "")
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch notification channels: %w", err)
+	}
+
+	exported := make([]NotificationChannelDto, 0, len(channels))
+	for _, ch := range channels {
+		dto := NotificationChannelDto{
+			ID:        ch.ID,
+			Name:      ch.Name,
+			Type:      ch.Type,
+			Active:    ch.Active,
+			IsDefault: ch.IsDefault,
+		}
+		if ch.Config != nil {
+			dto.Config = *ch.Config
+		}
+		exported = append(exported, dto)
+	}
+	return exported, nil
+}
+
+// exportMonitors returns the monitors with their notification channel and tag relations
+func (c *Controller) exportMonitors(ctx context.Context) ([]MonitorDto, error) {
+	monitors, err := paginate(func(page int) ([]*monitor.Model, error) {
+		return c.monitorService.FindAll(ctx, page, pageSize, "", nil, nil, nil)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch monitors: %w", err)
+	}
+
+	exported := make([]MonitorDto, 0, len(monitors))
+	for _, m := range monitors {
+		dto := MonitorDto{
+			ID: m.ID,
+			CreateUpdateDto: monitor.CreateUpdateDto{
+				Type:                       m.Type,
+				Name:                       m.Name,
+				Interval:                   m.Interval,
+				MaxRetries:                 m.MaxRetries,
+				RetryInterval:              m.RetryInterval,
+				RetryBackoff:               m.RetryBackoff,
+				Timeout:                    m.Timeout,
+				ResendInterval:             m.ResendInterval,
+				WarmupChecks:               m.WarmupChecks,
+				LatencyLimit:               m.LatencyLimit,
+				LatencyChecks:              m.LatencyChecks,
+				Criticality:                m.Criticality,
+				ResultExpression:           m.ResultExpression,
+				UpMessage:                  m.UpMessage,
+				DownMessage:                m.DownMessage,
+				Active:                     m.Active,
+				NotificationIds:            make([]string, 0),
+				NotificationMinCriticality: make(map[string]string),
+				NotificationEventTypes:     make(map[string][]string),
+				NotificationDigestOnly:     make(map[string]bool),
+				NotificationEscalateAfter:  make(map[string]int),
+				NotificationLimit:          make(map[string]int),
+				TagIds:                     make([]string, 0),
+				ProxyId:                    m.ProxyId,
+				FallbackProxyIds:           m.FallbackProxyIds,
+				NoProxy:                    m.NoProxy,
+				Serialize:                  m.Serialize,
+				ConfirmTransition:          m.ConfirmTransition,
+				ParentId:                   m.ParentId,
+				SkipWhenParentDown:         m.SkipWhenParentDown,
+				ParentDownTimeout:          m.ParentDownTimeout,
+				Config:                     m.Config,
+				PushToken:                  m.PushToken,
+			},
+		}
+
+		notificationRels, err := c.monitorNotificationService.FindByMonitorID(ctx, m.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch notifications of monitor %s: %w", m.ID, err)
+		}
+		for _, rel := range notificationRels {
+			id := rel.NotificationID
+			dto.NotificationIds = append(dto.NotificationIds, id)
+			if rel.MinCriticality != "" {
+				dto.NotificationMinCriticality[id] = rel.MinCriticality
+			}
+			if len(rel.EventTypes) > 0 {
+				dto.NotificationEventTypes[id] = rel.EventTypes
+			}
+			if rel.DigestOnly {
+				dto.NotificationDigestOnly[id] = true
+			}
+			if rel.EscalateAfter > 0 {
+				dto.NotificationEscalateAfter[id] = rel.EscalateAfter
+			}
+			if rel.NotificationLimit > 0 {
+				dto.NotificationLimit[id] = rel.NotificationLimit
+			}
+		}
+
+		tagRels, err := c.monitorTagService.FindByMonitorID(ctx, m.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch tags of monitor %s: %w", m.ID, err)
+		}
+		for _, rel := range tagRels {
+			dto.TagIds = append(dto.TagIds, rel.TagID)
+		}
+
+		exported = append(exported, dto)
+	}
+	return exported, nil
+}
+
+func (c *Controller) exportMaintenances(ctx context.Context) ([]MaintenanceDto, error) {
+	maintenances, err := paginate(func(page int) ([]*maintenance.Model, error) {
+		return c.maintenanceService.FindAll(ctx, page, pageSize, "", "")
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch maintenances: %w", err)
+	}
+
+	exported := make([]MaintenanceDto, 0, len(maintenances))
+	for _, m := range maintenances {
+		monitorIDs, err := c.maintenanceService.GetMonitors(ctx, m.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch monitors of maintenance %s: %w", m.ID, err)
+		}
+
+		dto := MaintenanceDto{
+			ID: m.ID,
+			CreateUpdateDto: maintenance.CreateUpdateDto{
+				Title:             m.Title,
+				Description:       m.Description,
+				Active:            m.Active,
+				Strategy:          m.Strategy,
+				StartDateTime:     m.StartDateTime,
+				EndDateTime:       m.EndDateTime,
+				StartTime:         m.StartTime,
+				EndTime:           m.EndTime,
+				Weekdays:          m.Weekdays,
+				DaysOfMonth:       m.DaysOfMonth,
+				IntervalDay:       m.IntervalDay,
+				Cron:              m.Cron,
+				Timezone:          m.Timezone,
+				Duration:          m.Duration,
+				SuppressChecks:    m.SuppressChecks,
+				AutoEndOnRecovery: m.AutoEndOnRecovery,
+				RecoveryChecks:    m.RecoveryChecks,
+				BannerMessage:     m.BannerMessage,
+				ApprovalStatus:    m.ApprovalStatus,
+				MonitorIds:        monitorIDs,
+			},
+			ApprovedBy: m.ApprovedBy,
+		}
+		if m.ApprovalStatus == maintenance.ApprovalStatusRejected {
+			dto.ApprovalStatus = maintenance.ApprovalStatusPending
+			dto.Rejected = true
+		}
+		exported = append(exported, dto)
+	}
+	return exported, nil
+}
+
+func (c *Controller) exportStatusPages(ctx context.Context) ([]StatusPageDto, error) {
+	pages, err := paginate(func(page int) ([]*status_page.Model, error) {
+		return c.statusPageService.FindAll(ctx, page, pageSize, "")
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch status pages: %w", err)
+	}
+
+	exported := make([]StatusPageDto, 0, len(pages))
+	for _, p := range pages {
+		sp, err := c.statusPageService.FindByIDWithMonitors(ctx, p.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch status page %s: %w", p.ID, err)
+		}
+		if sp == nil {
+			continue
+		}
+
+		rels, err := c.monitorStatusPageService.GetMonitorsForStatusPage(ctx, p.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch monitors of status page %s: %w", p.ID, err)
+		}
+		// The relations aren't returned in display order
+		sort.SliceStable(rels, func(i, j int) bool { return rels[i].Order < rels[j].Order })
+
+		dto := StatusPageDto{
+			ID: sp.ID,
+			CreateStatusPageDTO: status_page.CreateStatusPageDTO{
+				Slug:                  sp.Slug,
+				Title:                 sp.Title,
+				Description:           sp.Description,
+				Icon:                  sp.Icon,
+				Theme:                 sp.Theme,
+				Published:             sp.Published,
+				SearchEngineIndex:     sp.SearchEngineIndex,
+				ShowTags:              sp.ShowTags,
+				FooterText:            sp.FooterText,
+				CustomCSS:             sp.CustomCSS,
+				ShowPoweredBy:         sp.ShowPoweredBy,
+				GoogleAnalyticsTagID:  sp.GoogleAnalyticsTagID,
+				ShowCertificateExpiry: sp.ShowCertificateExpiry,
+				AutoRefreshInterval:   sp.AutoRefreshInterval,
+				ShowDegraded:          sp.ShowDegraded,
+				DegradedThreshold:     sp.DegradedThreshold,
+				UptimeWindowDays:      sp.UptimeWindowDays,
+				AllowSubscriptions:    sp.AllowSubscriptions,
+				SubscriberChannelID:   sp.SubscriberChannelID,
+				MonitorIDs:            make([]string, 0, len(rels)),
+				Domains:               sp.Domains,
+			},
+			PasswordProtected: sp.PasswordProtected,
+		}
+		for _, rel := range rels {
+			if slices.Contains(dto.MonitorIDs, rel.MonitorID) {
+				continue
+			}
+			dto.MonitorIDs = append(dto.MonitorIDs, rel.MonitorID)
+			if !rel.Active {
+				dto.HiddenMonitorIDs = append(dto.HiddenMonitorIDs, rel.MonitorID)
+			}
+		}
+		exported = append(exported, dto)
+	}
+	return exported, nil
+}
+
+// exportSettings returns the settings of settingKeys that are set
+func (c *Controller) exportSettings(ctx context.Context) ([]SettingDto, error) {
+	exported := make([]SettingDto, 0, len(settingKeys))
+	for _, key := range settingKeys {
+		s, err := c.settingService.GetByKey(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch setting %s: %w", key, err)
+		}
+		if s == nil {
+			continue
+		}
+		exported = append(exported, SettingDto{Key: key, Value: s.Value, Type: s.Type})
+	}
+	return exported, nil
+}
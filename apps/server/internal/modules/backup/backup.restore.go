@@ -0,0 +1,679 @@
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"peekaping/internal/modules/audit_log"
+	"peekaping/internal/modules/maintenance"
+	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/notification_channel"
+	"peekaping/internal/modules/proxy"
+	"peekaping/internal/modules/shared"
+	"peekaping/internal/modules/status_page"
+	"peekaping/internal/modules/tag"
+	"peekaping/internal/utils"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+// referenceSettings are the settings whose value is the id of a proxy or notification
+// channel of the bundle
+var referenceSettings = map[string]string{
+	proxy.DefaultProxySettingKey:                            "proxy",
+	notification_channel.AdminNotificationChannelSettingKey: "notification channel",
+}
+
+// idSet collects the ids of one section of a bundle and reports duplicates
+func idSet(kind string, ids []string, problems *[]string) map[string]bool {
+	set := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if id == "" {
+			*problems = append(*problems, fmt.Sprintf("a %s has no id", kind))
+			continue
+		}
+		if set[id] {
+			*problems = append(*problems, fmt.Sprintf("%s %s appears twice", kind, id))
+		}
+		set[id] = true
+	}
+	return set
+}
+
+func ids[T any](items []T, id func(*T) string) []string {
+	result := make([]string, 0, len(items))
+	for i := range items {
+		result = append(result, id(&items[i]))
+	}
+	return result
+}
+
+// validateReferences checks that every reference of the bundle points to an entity of
+// the bundle and that parents don't form a cycle. It returns the problems found.
+func validateReferences(bundle *BundleDto) []string {
+	problems := make([]string, 0)
+
+	proxies := idSet("proxy", ids(bundle.Proxies, func(p *ProxyDto) string { return p.ID }), &problems)
+	tags := idSet("tag", ids(bundle.Tags, func(t *TagDto) string { return t.ID }), &problems)
+	channels := idSet("notification channel", ids(bundle.NotificationChannels, func(n *NotificationChannelDto) string { return n.ID }), &problems)
+	monitors := idSet("monitor", ids(bundle.Monitors, func(m *MonitorDto) string { return m.ID }), &problems)
+	idSet("maintenance", ids(bundle.Maintenances, func(m *MaintenanceDto) string { return m.ID }), &problems)
+	idSet("status page", ids(bundle.StatusPages, func(s *StatusPageDto) string { return s.ID }), &problems)
+
+	missing := func(owner, kind, id string) {
+		problems = append(problems, fmt.Sprintf("%s references %s %s which is not in the backup", owner, kind, id))
+	}
+
+	for _, m := range bundle.Monitors {
+		owner := fmt.Sprintf("monitor %q", m.Name)
+		for _, id := range m.NotificationIds {
+			if !channels[id] {
+				missing(owner, "notification channel", id)
+			}
+		}
+		for _, id := range m.TagIds {
+			if !tags[id] {
+				missing(owner, "tag", id)
+			}
+		}
+		if m.ProxyId != "" && !proxies[m.ProxyId] {
+			missing(owner, "proxy", m.ProxyId)
+		}
+		for _, id := range m.FallbackProxyIds {
+			if !proxies[id] {
+				missing(owner, "proxy", id)
+			}
+		}
+		if m.ParentId != "" && !monitors[m.ParentId] {
+			missing(owner, "parent monitor", m.ParentId)
+		}
+	}
+	if _, err := parentOrder(bundle.Monitors); err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	for _, m := range bundle.Maintenances {
+		for _, id := range m.MonitorIds {
+			if !monitors[id] {
+				missing(fmt.Sprintf("maintenance %q", m.Title), "monitor", id)
+			}
+		}
+	}
+
+	for _, s := range bundle.StatusPages {
+		owner := fmt.Sprintf("status page %q", s.Slug)
+		for _, id := range s.MonitorIDs {
+			if !monitors[id] {
+				missing(owner, "monitor", id)
+			}
+		}
+		for _, id := range s.HiddenMonitorIDs {
+			if !slices.Contains(s.MonitorIDs, id) {
+				problems = append(problems, fmt.Sprintf("%s hides monitor %s which it doesn't show", owner, id))
+			}
+		}
+		if s.SubscriberChannelID != "" && !channels[s.SubscriberChannelID] {
+			missing(owner, "notification channel", s.SubscriberChannelID)
+		}
+	}
+
+	for _, s := range bundle.Settings {
+		kind, ok := referenceSettings[s.Key]
+		if !ok || s.Value == "" {
+			continue
+		}
+		if (kind == "proxy" && !proxies[s.Value]) || (kind != "proxy" && !channels[s.Value]) {
+			missing(fmt.Sprintf("setting %s", s.Key), kind, s.Value)
+		}
+	}
+
+	return problems
+}
+
+// parentOrder returns the indexes of the monitors with every parent before its children
+func parentOrder(monitors []MonitorDto) ([]int, error) {
+	index := make(map[string]int, len(monitors))
+	for i, m := range monitors {
+		index[m.ID] = i
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make([]int, len(monitors))
+	order := make([]int, 0, len(monitors))
+
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf("monitor %q is its own ancestor", monitors[i].Name)
+		}
+		state[i] = visiting
+		if parent, ok := index[monitors[i].ParentId]; ok && monitors[i].ParentId != "" {
+			if err := visit(parent); err != nil {
+				return err
+			}
+		}
+		state[i] = done
+		order = append(order, i)
+		return nil
+	}
+
+	for i := range monitors {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// add records the result of an item and counts it
+func (s *SectionReportDto) add(result ItemResultDto) {
+	switch result.Status {
+	case StatusCreated:
+		s.Created++
+	case StatusExisting:
+		s.Existing++
+	case StatusFailed:
+		s.Failed++
+	}
+	s.Items = append(s.Items, result)
+}
+
+func (r *RestoreReportDto) sections() []*SectionReportDto {
+	return []*SectionReportDto{&r.Proxies, &r.Tags, &r.NotificationChannels, &r.Monitors, &r.Maintenances, &r.StatusPages, &r.Settings}
+}
+
+func newReport() *RestoreReportDto {
+	report := &RestoreReportDto{}
+	for _, s := range report.sections() {
+		s.Items = make([]ItemResultDto, 0)
+	}
+	return report
+}
+
+// restoreBundle creates the entities of a validated bundle, those referenced first. The
+// new id of every restored entity is recorded by its id in the bundle. References to
+// entities that failed are dropped and reported as warnings.
+func (c *Controller) restoreBundle(ctx *gin.Context, bundle *BundleDto) (*RestoreReportDto, error) {
+	report := newReport()
+
+	proxyIDs := c.restoreProxies(ctx, bundle.Proxies, &report.Proxies)
+	tagIDs := c.restoreTags(ctx, bundle.Tags, &report.Tags)
+	channelIDs, err := c.restoreChannels(ctx, bundle.NotificationChannels, &report.NotificationChannels)
+	if err != nil {
+		return nil, err
+	}
+	monitorIDs, err := c.restoreMonitors(ctx, bundle.Monitors, proxyIDs, tagIDs, channelIDs, &report.Monitors)
+	if err != nil {
+		return nil, err
+	}
+	c.restoreMaintenances(ctx, bundle.Maintenances, monitorIDs, &report.Maintenances)
+	c.restoreStatusPages(ctx, bundle.StatusPages, monitorIDs, channelIDs, &report.StatusPages)
+	c.restoreSettings(ctx, bundle.Settings, proxyIDs, channelIDs, &report.Settings)
+
+	return report, nil
+}
+
+func (c *Controller) restoreProxies(ctx *gin.Context, proxies []ProxyDto, report *SectionReportDto) map[string]string {
+	restored := make(map[string]string, len(proxies))
+	for _, p := range proxies {
+		result := ItemResultDto{Name: fmt.Sprintf("%s://%s:%d", p.Protocol, p.Host, p.Port), ID: p.ID}
+
+		dto := &proxy.CreateUpdateDto{
+			Protocol: p.Protocol,
+			Host:     p.Host,
+			Port:     p.Port,
+			Auth:     p.Auth,
+			Username: p.Username,
+			Password: p.Password,
+		}
+		if err := utils.Validate.Struct(dto); err != nil {
+			result.Status = StatusFailed
+			result.Reason = err.Error()
+			report.add(result)
+			continue
+		}
+		created, err := c.proxyService.Create(ctx, dto)
+		if err != nil {
+			c.logger.Errorw("Failed to create proxy", "host", p.Host, "error", err)
+			result.Status = StatusFailed
+			result.Reason = "Internal server error"
+			report.add(result)
+			continue
+		}
+
+		result.Status = StatusCreated
+		result.NewID = created.ID
+		restored[p.ID] = created.ID
+		report.add(result)
+	}
+	return restored
+}
+
+// restoreTags reuses the tags that exist with the same name
+func (c *Controller) restoreTags(ctx *gin.Context, tags []TagDto, report *SectionReportDto) map[string]string {
+	restored := make(map[string]string, len(tags))
+	for _, t := range tags {
+		result := ItemResultDto{Name: t.Name, ID: t.ID}
+
+		existing, err := c.tagService.FindByName(ctx, t.Name)
+		if err != nil {
+			c.logger.Errorw("Failed to fetch tag", "name", t.Name, "error", err)
+			result.Status = StatusFailed
+			result.Reason = "Internal server error"
+			report.add(result)
+			continue
+		}
+		if existing != nil {
+			result.Status = StatusExisting
+			result.NewID = existing.ID
+			restored[t.ID] = existing.ID
+			report.add(result)
+			continue
+		}
+
+		dto := &tag.CreateUpdateDto{Name: t.Name, Color: t.Color, Description: t.Description}
+		if err := utils.Validate.Struct(dto); err != nil {
+			result.Status = StatusFailed
+			result.Reason = err.Error()
+			report.add(result)
+			continue
+		}
+		created, err := c.tagService.Create(ctx, dto)
+		if err != nil {
+			c.logger.Errorw("Failed to create tag", "name", t.Name, "error", err)
+			result.Status = StatusFailed
+			result.Reason = "Internal server error"
+			report.add(result)
+			continue
+		}
+
+		result.Status = StatusCreated
+		result.NewID = created.ID
+		restored[t.ID] = created.ID
+		report.add(result)
+	}
+	return restored
+}
+
+// restoreChannels reuses a notification channel with the same name and type, one with
+// the same name and another type fails the channel like the Uptime Kuma import
+func (c *Controller) restoreChannels(ctx *gin.Context, channels []NotificationChannelDto, report *SectionReportDto) (map[string]string, error) {
+	names, err := c.channelService.ChannelNames(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch notification channels: %w", err)
+	}
+	idsByName := make(map[string][]string, len(names))
+	for id, name := range names {
+		idsByName[name] = append(idsByName[name], id)
+	}
+
+	restored := make(map[string]string, len(channels))
+	for _, n := range channels {
+		result := ItemResultDto{Name: n.Name, ID: n.ID}
+
+		switch existing := idsByName[n.Name]; len(existing) {
+		case 0:
+			if err := notification_channel.ValidateConfig(n.Type, n.Config); err != nil {
+				result.Status = StatusFailed
+				result.Reason = err.Error()
+				break
+			}
+			created, err := c.channelService.Create(ctx, &notification_channel.CreateUpdateDto{
+				Name:      n.Name,
+				Type:      n.Type,
+				Active:    n.Active,
+				IsDefault: n.IsDefault,
+				Config:    n.Config,
+			})
+			if err != nil {
+				c.logger.Errorw("Failed to create notification channel", "name", n.Name, "error", err)
+				result.Status = StatusFailed
+				result.Reason = "Internal server error"
+				break
+			}
+			c.recordCreated(ctx, audit_log.EntityNotificationChannel, created.ID, created)
+			result.Status = StatusCreated
+			result.NewID = created.ID
+			idsByName[n.Name] = []string{created.ID}
+		case 1:
+			channel, err := c.channelService.FindByID(ctx, existing[0])
+			if err != nil || channel == nil {
+				c.logger.Errorw("Failed to fetch notification channel", "id", existing[0], "error", err)
+				result.Status = StatusFailed
+				result.Reason = "Internal server error"
+				break
+			}
+			if channel.Type != n.Type {
+				result.Status = StatusFailed
+				result.Reason = fmt.Sprintf("a %s notification channel named %q already exists", channel.Type, n.Name)
+				break
+			}
+			result.Status = StatusExisting
+			result.NewID = channel.ID
+		default:
+			result.Status = StatusFailed
+			result.Reason = fmt.Sprintf("several notification channels are named %q", n.Name)
+		}
+
+		if result.Status != StatusFailed {
+			restored[n.ID] = result.NewID
+		}
+		report.add(result)
+	}
+	return restored, nil
+}
+
+// restoreMonitors creates the monitors parents first so children can reference them
+func (c *Controller) restoreMonitors(ctx *gin.Context, monitors []MonitorDto, proxyIDs, tagIDs, channelIDs map[string]string, report *SectionReportDto) (map[string]string, error) {
+	order, err := parentOrder(monitors)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]ItemResultDto, len(monitors))
+	restored := make(map[string]string, len(monitors))
+	for _, i := range order {
+		m := &monitors[i]
+		result := ItemResultDto{Name: m.Name, ID: m.ID}
+
+		dto, warnings := remapMonitor(m, proxyIDs, tagIDs, channelIDs, restored)
+		result.Warnings = warnings
+
+		created, err := c.monitorRestorer.Restore(ctx, dto)
+		switch {
+		case errors.Is(err, monitor.ErrInvalidMonitor):
+			result.Status = StatusFailed
+			result.Reason = err.Error()
+		case err != nil:
+			c.logger.Errorw("Failed to restore monitor", "name", m.Name, "error", err)
+			result.Status = StatusFailed
+			result.Reason = "Internal server error"
+		default:
+			result.Status = StatusCreated
+			result.NewID = created.ID
+			restored[m.ID] = created.ID
+		}
+		results[i] = result
+	}
+
+	// Report in the order of the bundle
+	for _, result := range results {
+		report.add(result)
+	}
+	return restored, nil
+}
+
+// remapMonitor returns the create request of a monitor with the ids of the restored
+// entities, references to entities that weren't restored are dropped with a warning
+func remapMonitor(m *MonitorDto, proxyIDs, tagIDs, channelIDs, monitorIDs map[string]string) (*monitor.CreateUpdateDto, []string) {
+	dto := m.CreateUpdateDto
+	warnings := make([]string, 0)
+
+	dto.NotificationIds = make([]string, 0, len(m.NotificationIds))
+	dto.NotificationMinCriticality = make(map[string]string)
+	dto.NotificationEventTypes = make(map[string][]string)
+	dto.NotificationDigestOnly = make(map[string]bool)
+	dto.NotificationEscalateAfter = make(map[string]int)
+	dto.NotificationLimit = make(map[string]int)
+	for _, old := range m.NotificationIds {
+		id, ok := channelIDs[old]
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("notification channel %s was not restored", old))
+			continue
+		}
+		dto.NotificationIds = append(dto.NotificationIds, id)
+		if v, ok := m.NotificationMinCriticality[old]; ok {
+			dto.NotificationMinCriticality[id] = v
+		}
+		if v, ok := m.NotificationEventTypes[old]; ok {
+			dto.NotificationEventTypes[id] = v
+		}
+		if v, ok := m.NotificationDigestOnly[old]; ok {
+			dto.NotificationDigestOnly[id] = v
+		}
+		if v, ok := m.NotificationEscalateAfter[old]; ok {
+			dto.NotificationEscalateAfter[id] = v
+		}
+		if v, ok := m.NotificationLimit[old]; ok {
+			dto.NotificationLimit[id] = v
+		}
+	}
+
+	dto.TagIds = make([]string, 0, len(m.TagIds))
+	for _, old := range m.TagIds {
+		id, ok := tagIDs[old]
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("tag %s was not restored", old))
+			continue
+		}
+		dto.TagIds = append(dto.TagIds, id)
+	}
+
+	if m.ProxyId != "" {
+		dto.ProxyId = proxyIDs[m.ProxyId]
+		if dto.ProxyId == "" {
+			warnings = append(warnings, fmt.Sprintf("proxy %s was not restored", m.ProxyId))
+		}
+	}
+	if len(m.FallbackProxyIds) > 0 {
+		dto.FallbackProxyIds = make([]string, 0, len(m.FallbackProxyIds))
+		for _, old := range m.FallbackProxyIds {
+			id, ok := proxyIDs[old]
+			if !ok {
+				warnings = append(warnings, fmt.Sprintf("fallback proxy %s was not restored", old))
+				continue
+			}
+			dto.FallbackProxyIds = append(dto.FallbackProxyIds, id)
+		}
+	}
+
+	if m.ParentId != "" {
+		dto.ParentId = monitorIDs[m.ParentId]
+		if dto.ParentId == "" {
+			warnings = append(warnings, fmt.Sprintf("parent monitor %s was not restored", m.ParentId))
+		}
+	}
+
+	if len(warnings) == 0 {
+		warnings = nil
+	}
+	return &dto, warnings
+}
+
+// restoreMaintenances creates the windows with their approval. Rejected windows are
+// created pending and rejected afterwards since creating them rejected isn't allowed.
+func (c *Controller) restoreMaintenances(ctx *gin.Context, maintenances []MaintenanceDto, monitorIDs map[string]string, report *SectionReportDto) {
+	for _, m := range maintenances {
+		result := ItemResultDto{Name: m.Title, ID: m.ID}
+
+		dto := m.CreateUpdateDto
+		dto.MonitorIds = make([]string, 0, len(m.MonitorIds))
+		for _, old := range m.MonitorIds {
+			id, ok := monitorIDs[old]
+			if !ok {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("monitor %s was not restored", old))
+				continue
+			}
+			dto.MonitorIds = append(dto.MonitorIds, id)
+		}
+		if m.Rejected {
+			dto.ApprovalStatus = maintenance.ApprovalStatusPending
+		}
+
+		if err := utils.Validate.Struct(&dto); err != nil {
+			result.Status = StatusFailed
+			result.Reason = err.Error()
+			report.add(result)
+			continue
+		}
+		created, err := c.maintenanceService.Create(ctx, &dto)
+		if errors.Is(err, maintenance.ErrInvalidDateTimeWindow) || errors.Is(err, maintenance.ErrAutoEndRequiresOneShot) {
+			result.Status = StatusFailed
+			result.Reason = err.Error()
+			report.add(result)
+			continue
+		}
+		if err != nil {
+			c.logger.Errorw("Failed to create maintenance", "title", m.Title, "error", err)
+			result.Status = StatusFailed
+			result.Reason = "Internal server error"
+			report.add(result)
+			continue
+		}
+		result.Status = StatusCreated
+		result.NewID = created.ID
+
+		status := dto.ApprovalStatus
+		if m.Rejected {
+			status = maintenance.ApprovalStatusRejected
+		}
+		if m.ApprovedBy != nil || m.Rejected {
+			approvedBy := ""
+			if m.ApprovedBy != nil {
+				approvedBy = *m.ApprovedBy
+			}
+			approved, err := c.maintenanceService.SetApproval(ctx, created.ID, status, approvedBy)
+			if err != nil {
+				c.logger.Errorw("Failed to restore maintenance approval", "id", created.ID, "error", err)
+				result.Warnings = append(result.Warnings, fmt.Sprintf("approval status %s was not restored", status))
+			} else if approved != nil {
+				created = approved
+			}
+		}
+		c.recordCreated(ctx, audit_log.EntityMaintenance, created.ID, created)
+		report.add(result)
+	}
+}
+
+// restoreStatusPages creates the pages that don't clash with the slug of an existing one.
+// Password protected pages can't get their password back and are restored unpublished.
+func (c *Controller) restoreStatusPages(ctx *gin.Context, pages []StatusPageDto, monitorIDs, channelIDs map[string]string, report *SectionReportDto) {
+	for _, p := range pages {
+		result := ItemResultDto{Name: p.Slug, ID: p.ID}
+
+		existing, err := c.statusPageService.FindBySlug(ctx, p.Slug)
+		if err != nil {
+			c.logger.Errorw("Failed to fetch status page", "slug", p.Slug, "error", err)
+			result.Status = StatusFailed
+			result.Reason = "Internal server error"
+			report.add(result)
+			continue
+		}
+		if existing != nil {
+			result.Status = StatusFailed
+			result.Reason = fmt.Sprintf("a status page with slug %q already exists", p.Slug)
+			report.add(result)
+			continue
+		}
+
+		dto := p.CreateStatusPageDTO
+		dto.MonitorIDs = make([]string, 0, len(p.MonitorIDs))
+		for _, old := range p.MonitorIDs {
+			id, ok := monitorIDs[old]
+			if !ok {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("monitor %s was not restored", old))
+				continue
+			}
+			dto.MonitorIDs = append(dto.MonitorIDs, id)
+		}
+		if p.SubscriberChannelID != "" {
+			dto.SubscriberChannelID = channelIDs[p.SubscriberChannelID]
+			if dto.SubscriberChannelID == "" {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("notification channel %s was not restored", p.SubscriberChannelID))
+			}
+		}
+		if p.PasswordProtected && dto.Password == "" && dto.Published {
+			dto.Published = false
+			result.Warnings = append(result.Warnings, "the page password can't be restored, the page was unpublished")
+		}
+
+		if err := utils.Validate.Struct(&dto); err != nil {
+			result.Status = StatusFailed
+			result.Reason = err.Error()
+			report.add(result)
+			continue
+		}
+		created, err := c.statusPageService.Create(ctx, &dto)
+		var domainErr *status_page.DomainAlreadyUsedError
+		if errors.As(err, &domainErr) {
+			result.Status = StatusFailed
+			result.Reason = fmt.Sprintf("domain %s is already used by another status page", domainErr.Domain)
+			report.add(result)
+			continue
+		}
+		if err != nil {
+			c.logger.Errorw("Failed to create status page", "slug", p.Slug, "error", err)
+			result.Status = StatusFailed
+			result.Reason = "Internal server error"
+			report.add(result)
+			continue
+		}
+		result.Status = StatusCreated
+		result.NewID = created.ID
+
+		for _, old := range p.HiddenMonitorIDs {
+			id, ok := monitorIDs[old]
+			if !ok {
+				continue
+			}
+			if _, err := c.monitorStatusPageService.UpdateMonitorActiveStatus(ctx, created.ID, id, false); err != nil {
+				c.logger.Errorw("Failed to hide status page monitor", "id", created.ID, "monitor", id, "error", err)
+				result.Warnings = append(result.Warnings, fmt.Sprintf("monitor %s was not hidden", old))
+			}
+		}
+		report.add(result)
+	}
+}
+
+// restoreSettings sets the settings of settingKeys, others such as the token secrets are
+// refused
+func (c *Controller) restoreSettings(ctx *gin.Context, settings []SettingDto, proxyIDs, channelIDs map[string]string, report *SectionReportDto) {
+	for _, s := range settings {
+		result := ItemResultDto{Name: s.Key, ID: s.Key}
+
+		if !slices.Contains(settingKeys, s.Key) {
+			result.Status = StatusFailed
+			result.Reason = "the setting can't be restored"
+			report.add(result)
+			continue
+		}
+
+		value := s.Value
+		if kind, ok := referenceSettings[s.Key]; ok && value != "" {
+			ids := channelIDs
+			if kind == "proxy" {
+				ids = proxyIDs
+			}
+			if value = ids[s.Value]; value == "" {
+				result.Status = StatusFailed
+				result.Reason = fmt.Sprintf("%s %s was not restored", kind, s.Value)
+				report.add(result)
+				continue
+			}
+		}
+
+		dto := &shared.SettingCreateUpdateDto{Value: value, Type: s.Type}
+		if err := utils.Validate.Struct(dto); err != nil {
+			result.Status = StatusFailed
+			result.Reason = err.Error()
+			report.add(result)
+			continue
+		}
+		if _, err := c.settingService.SetByKey(ctx, s.Key, dto); err != nil {
+			c.logger.Errorw("Failed to set setting", "key", s.Key, "error", err)
+			result.Status = StatusFailed
+			result.Reason = "Internal server error"
+			report.add(result)
+			continue
+		}
+		result.Status = StatusCreated
+		report.add(result)
+	}
+}
@@ -0,0 +1,635 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"peekaping/internal/config"
+	"peekaping/internal/modules/audit_log"
+	"peekaping/internal/modules/maintenance"
+	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/monitor_notification"
+	"peekaping/internal/modules/monitor_status_page"
+	"peekaping/internal/modules/monitor_tag"
+	"peekaping/internal/modules/notification_channel"
+	"peekaping/internal/modules/notification_channel/providers"
+	"peekaping/internal/modules/proxy"
+	"peekaping/internal/modules/shared"
+	"peekaping/internal/modules/status_page"
+	"peekaping/internal/modules/tag"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// store is an in-memory instance handing out ids in creation order, so restoring the same
+// bundle into two stores gives the same ids
+type store struct {
+	seq                  int
+	proxies              []*proxy.Model
+	tags                 []*tag.Model
+	channels             []*notification_channel.Model
+	monitors             []*monitor.Model
+	monitorNotifications []*monitor_notification.Model
+	monitorTags          []*monitor_tag.Model
+	maintenances         []*maintenance.Model
+	maintenanceMonitors  map[string][]string
+	statusPages          []*status_page.StatusPageWithMonitorsResponseDTO
+	statusPageMonitors   []*monitor_status_page.Model
+	settings             map[string]*shared.SettingModel
+	auditEntries         []*audit_log.Model
+}
+
+func newStore() *store {
+	return &store{
+		maintenanceMonitors: make(map[string][]string),
+		settings:            make(map[string]*shared.SettingModel),
+	}
+}
+
+func (s *store) id(kind string) string {
+	s.seq++
+	return fmt.Sprintf("%s-%d", kind, s.seq)
+}
+
+func pageOf[T any](items []T, page, limit int) []T {
+	start := min(page*limit, len(items))
+	end := min(start+limit, len(items))
+	return items[start:end]
+}
+
+type fakeProxyService struct {
+	proxy.Service
+	s *store
+}
+
+func (f *fakeProxyService) FindAll(ctx context.Context, page int, limit int, q string) ([]*proxy.Model, error) {
+	return pageOf(f.s.proxies, page, limit), nil
+}
+
+func (f *fakeProxyService) Create(ctx context.Context, dto *proxy.CreateUpdateDto) (*proxy.Model, error) {
+	created := &proxy.Model{ID: f.s.id("proxy"), Protocol: dto.Protocol, Host: dto.Host, Port: dto.Port, Auth: dto.Auth, Username: dto.Username, Password: dto.Password}
+	f.s.proxies = append(f.s.proxies, created)
+	return created, nil
+}
+
+type fakeTagService struct {
+	tag.Service
+	s *store
+}
+
+func (f *fakeTagService) FindAll(ctx context.Context, page int, limit int, q string) ([]*tag.Model, error) {
+	return pageOf(f.s.tags, page, limit), nil
+}
+
+func (f *fakeTagService) FindByName(ctx context.Context, name string) (*tag.Model, error) {
+	for _, t := range f.s.tags {
+		if t.Name == name {
+			return t, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeTagService) Create(ctx context.Context, dto *tag.CreateUpdateDto) (*tag.Model, error) {
+	created := &tag.Model{ID: f.s.id("tag"), Name: dto.Name, Color: dto.Color, Description: dto.Description}
+	f.s.tags = append(f.s.tags, created)
+	return created, nil
+}
+
+type fakeChannelService struct {
+	notification_channel.Service
+	s *store
+}
+
+func (f *fakeChannelService) FindAll(ctx context.Context, page int, limit int, q string) ([]*notification_channel.Model, error) {
+	return pageOf(f.s.channels, page, limit), nil
+}
+
+func (f *fakeChannelService) ChannelNames(ctx context.Context) (map[string]string, error) {
+	names := make(map[string]string, len(f.s.channels))
+	for _, c := range f.s.channels {
+		names[c.ID] = c.Name
+	}
+	return names, nil
+}
+
+func (f *fakeChannelService) FindByID(ctx context.Context, id string) (*notification_channel.Model, error) {
+	for _, c := range f.s.channels {
+		if c.ID == id {
+			return c, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeChannelService) Create(ctx context.Context, dto *notification_channel.CreateUpdateDto) (*notification_channel.Model, error) {
+	config := dto.Config
+	created := &notification_channel.Model{ID: f.s.id("channel"), Name: dto.Name, Type: dto.Type, Active: dto.Active, IsDefault: dto.IsDefault, Config: &config}
+	f.s.channels = append(f.s.channels, created)
+	return created, nil
+}
+
+// fakeMonitors creates monitors with their relations like the monitor controller
+type fakeMonitors struct {
+	monitor.Service
+	s *store
+}
+
+func (f *fakeMonitors) Restore(ctx *gin.Context, dto *monitor.CreateUpdateDto) (*monitor.Model, error) {
+	if dto.ParentId != "" && !slices.ContainsFunc(f.s.monitors, func(m *monitor.Model) bool { return m.ID == dto.ParentId }) {
+		return nil, fmt.Errorf("%w: parent monitor not found", monitor.ErrInvalidMonitor)
+	}
+	created := &monitor.Model{
+		ID: f.s.id("monitor"), Type: dto.Type, Name: dto.Name, Interval: dto.Interval, MaxRetries: dto.MaxRetries,
+		RetryInterval: dto.RetryInterval, Timeout: dto.Timeout, ResendInterval: dto.ResendInterval, Criticality: dto.Criticality,
+		Active: dto.Active, ProxyId: dto.ProxyId, FallbackProxyIds: dto.FallbackProxyIds, ParentId: dto.ParentId,
+		SkipWhenParentDown: dto.SkipWhenParentDown, Config: dto.Config, PushToken: dto.PushToken,
+	}
+	f.s.monitors = append(f.s.monitors, created)
+	for _, id := range dto.NotificationIds {
+		f.s.monitorNotifications = append(f.s.monitorNotifications, &monitor_notification.Model{
+			MonitorID: created.ID, NotificationID: id,
+			MinCriticality: dto.NotificationMinCriticality[id], EventTypes: dto.NotificationEventTypes[id],
+			DigestOnly: dto.NotificationDigestOnly[id], EscalateAfter: dto.NotificationEscalateAfter[id],
+			NotificationLimit: dto.NotificationLimit[id],
+		})
+	}
+	for _, id := range dto.TagIds {
+		f.s.monitorTags = append(f.s.monitorTags, &monitor_tag.Model{MonitorID: created.ID, TagID: id})
+	}
+	return created, nil
+}
+
+func (f *fakeMonitors) FindAll(ctx context.Context, page int, limit int, q string, active *bool, status *int, tagIds []string) ([]*monitor.Model, error) {
+	return pageOf(f.s.monitors, page, limit), nil
+}
+
+type fakeMonitorNotificationService struct {
+	monitor_notification.Service
+	s *store
+}
+
+func (f *fakeMonitorNotificationService) FindByMonitorID(ctx context.Context, monitorID string) ([]*monitor_notification.Model, error) {
+	rels := make([]*monitor_notification.Model, 0)
+	for _, rel := range f.s.monitorNotifications {
+		if rel.MonitorID == monitorID {
+			rels = append(rels, rel)
+		}
+	}
+	return rels, nil
+}
+
+type fakeMonitorTagService struct {
+	monitor_tag.Service
+	s *store
+}
+
+func (f *fakeMonitorTagService) FindByMonitorID(ctx context.Context, monitorID string) ([]*monitor_tag.Model, error) {
+	rels := make([]*monitor_tag.Model, 0)
+	for _, rel := range f.s.monitorTags {
+		if rel.MonitorID == monitorID {
+			rels = append(rels, rel)
+		}
+	}
+	return rels, nil
+}
+
+type fakeMaintenanceService struct {
+	maintenance.Service
+	s *store
+}
+
+func (f *fakeMaintenanceService) FindAll(ctx context.Context, page int, limit int, q string, strategy string) ([]*maintenance.Model, error) {
+	return pageOf(f.s.maintenances, page, limit), nil
+}
+
+func (f *fakeMaintenanceService) GetMonitors(ctx context.Context, id string) ([]string, error) {
+	return f.s.maintenanceMonitors[id], nil
+}
+
+func (f *fakeMaintenanceService) Create(ctx context.Context, dto *maintenance.CreateUpdateDto) (*maintenance.Model, error) {
+	created := &maintenance.Model{
+		ID: f.s.id("maintenance"), Title: dto.Title, Description: dto.Description, Active: dto.Active,
+		Strategy: dto.Strategy, Duration: dto.Duration, BannerMessage: dto.BannerMessage, ApprovalStatus: dto.ApprovalStatus,
+	}
+	if created.ApprovalStatus == "" {
+		created.ApprovalStatus = maintenance.ApprovalStatusApproved
+	}
+	f.s.maintenances = append(f.s.maintenances, created)
+	f.s.maintenanceMonitors[created.ID] = dto.MonitorIds
+	return created, nil
+}
+
+func (f *fakeMaintenanceService) SetApproval(ctx context.Context, id string, status string, approvedBy string) (*maintenance.Model, error) {
+	for _, m := range f.s.maintenances {
+		if m.ID == id {
+			m.ApprovalStatus = status
+			m.ApprovedBy = &approvedBy
+			return m, nil
+		}
+	}
+	return nil, nil
+}
+
+type fakeStatusPages struct {
+	status_page.Service
+	s *store
+}
+
+func (f *fakeStatusPages) FindAll(ctx context.Context, page int, limit int, q string) ([]*status_page.Model, error) {
+	pages := make([]*status_page.Model, 0)
+	for _, p := range pageOf(f.s.statusPages, page, limit) {
+		pages = append(pages, &status_page.Model{ID: p.ID, Slug: p.Slug})
+	}
+	return pages, nil
+}
+
+func (f *fakeStatusPages) FindByIDWithMonitors(ctx context.Context, id string) (*status_page.StatusPageWithMonitorsResponseDTO, error) {
+	for _, p := range f.s.statusPages {
+		if p.ID == id {
+			return p, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeStatusPages) FindBySlug(ctx context.Context, slug string) (*status_page.Model, error) {
+	for _, p := range f.s.statusPages {
+		if p.Slug == slug {
+			return &status_page.Model{ID: p.ID, Slug: p.Slug}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeStatusPages) Create(ctx context.Context, dto *status_page.CreateStatusPageDTO) (*status_page.Model, error) {
+	created := &status_page.StatusPageWithMonitorsResponseDTO{
+		ID: f.s.id("page"), Slug: dto.Slug, Title: dto.Title, Published: dto.Published, ShowTags: dto.ShowTags,
+		PasswordProtected: dto.Password != "", AllowSubscriptions: dto.AllowSubscriptions,
+		SubscriberChannelID: dto.SubscriberChannelID, MonitorIDs: dto.MonitorIDs, Domains: dto.Domains,
+	}
+	f.s.statusPages = append(f.s.statusPages, created)
+	for i, id := range dto.MonitorIDs {
+		f.s.statusPageMonitors = append(f.s.statusPageMonitors, &monitor_status_page.Model{StatusPageID: created.ID, MonitorID: id, Order: i, Active: true})
+	}
+	return &status_page.Model{ID: created.ID, Slug: created.Slug}, nil
+}
+
+// fakeMonitorStatusPageService returns the monitors of a page newest first like the SQL
+// repository
+type fakeMonitorStatusPageService struct {
+	monitor_status_page.Service
+	s *store
+}
+
+func (f *fakeMonitorStatusPageService) GetMonitorsForStatusPage(ctx context.Context, statusPageID string) ([]*monitor_status_page.Model, error) {
+	rels := make([]*monitor_status_page.Model, 0)
+	for _, rel := range f.s.statusPageMonitors {
+		if rel.StatusPageID == statusPageID {
+			rels = append([]*monitor_status_page.Model{rel}, rels...)
+		}
+	}
+	return rels, nil
+}
+
+func (f *fakeMonitorStatusPageService) UpdateMonitorActiveStatus(ctx context.Context, statusPageID, monitorID string, active bool) (*monitor_status_page.Model, error) {
+	for _, rel := range f.s.statusPageMonitors {
+		if rel.StatusPageID == statusPageID && rel.MonitorID == monitorID {
+			rel.Active = active
+			return rel, nil
+		}
+	}
+	return nil, nil
+}
+
+type fakeSettingService struct {
+	shared.SettingService
+	s *store
+}
+
+func (f *fakeSettingService) GetByKey(ctx context.Context, key string) (*shared.SettingModel, error) {
+	return f.s.settings[key], nil
+}
+
+func (f *fakeSettingService) SetByKey(ctx context.Context, key string, dto *shared.SettingCreateUpdateDto) (*shared.SettingModel, error) {
+	f.s.settings[key] = &shared.SettingModel{Key: key, Value: dto.Value, Type: dto.Type}
+	return f.s.settings[key], nil
+}
+
+type fakeAuditRepository struct {
+	s *store
+}
+
+func (f *fakeAuditRepository) Create(ctx context.Context, entity *audit_log.Model) (*audit_log.Model, error) {
+	f.s.auditEntries = append(f.s.auditEntries, entity)
+	return entity, nil
+}
+
+func (f *fakeAuditRepository) FindAll(ctx context.Context, filter *audit_log.FilterOptions) ([]*audit_log.Model, error) {
+	return f.s.auditEntries, nil
+}
+
+func (f *fakeAuditRepository) DeleteOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	return 0, nil
+}
+
+func newTestController(s *store) *Controller {
+	monitors := &fakeMonitors{s: s}
+	return NewController(ControllerDependencies{
+		MonitorRestorer:            monitors,
+		MonitorService:             monitors,
+		MonitorNotificationService: &fakeMonitorNotificationService{s: s},
+		MonitorTagService:          &fakeMonitorTagService{s: s},
+		ChannelService:             &fakeChannelService{s: s},
+		TagService:                 &fakeTagService{s: s},
+		ProxyService:               &fakeProxyService{s: s},
+		MaintenanceService:         &fakeMaintenanceService{s: s},
+		StatusPageService:          &fakeStatusPages{s: s},
+		MonitorStatusPageService:   &fakeMonitorStatusPageService{s: s},
+		SettingService:             &fakeSettingService{s: s},
+		AuditLogService:            audit_log.NewService(&fakeAuditRepository{s: s}, &config.Config{AuditLogEnabled: true}, zap.NewNop().Sugar()),
+		Logger:                     zap.NewNop().Sugar(),
+	})
+}
+
+func ptr[T any](v T) *T { return &v }
+
+// seedBundle references entities by ids unlike those of a store, the child monitor
+// comes before its parent
+func seedBundle() *BundleDto {
+	slack := `{"slack_webhook_url":"https://hooks.slack.com/services/T/B/X"}`
+	return &BundleDto{
+		Version: BundleVersion,
+		Proxies: []ProxyDto{
+			{ID: "p1", Protocol: "http", Host: "proxy.internal", Port: 3128, Auth: true, Username: "monitor", Password: "s3cret"},
+			{ID: "p2", Protocol: "socks5", Host: "socks.internal", Port: 1080},
+		},
+		Tags: []TagDto{{ID: "t1", Name: "Production", Color: "#059669", Description: ptr("Customer facing")}},
+		NotificationChannels: []NotificationChannelDto{
+			{ID: "c1", Name: "Ops Slack", Type: "slack", Active: true, Config: slack},
+			{ID: "c2", Name: "Escalation", Type: "slack", Active: true, Config: slack},
+		},
+		Monitors: []MonitorDto{
+			{ID: "m2", CreateUpdateDto: monitor.CreateUpdateDto{
+				Type: "http", Name: "API", Interval: 60, RetryInterval: 60, Timeout: 16, Criticality: "high", Active: true,
+				NotificationIds:            []string{"c1", "c2"},
+				NotificationMinCriticality: map[string]string{"c1": "high"},
+				NotificationEventTypes:     map[string][]string{"c1": {"down", "up"}},
+				NotificationEscalateAfter:  map[string]int{"c2": 3},
+				NotificationLimit:          map[string]int{"c1": 5},
+				TagIds:                     []string{"t1"},
+				ProxyId:                    "p1",
+				FallbackProxyIds:           []string{"p2"},
+				ParentId:                   "m1",
+				SkipWhenParentDown:         true,
+				Config:                     `{"url":"https://api.example.com"}`,
+			}},
+			{ID: "m1", CreateUpdateDto: monitor.CreateUpdateDto{
+				Type: "push", Name: "Gateway", Interval: 60, RetryInterval: 60, Timeout: 16, Active: true,
+				NotificationIds: []string{"c1"}, NotificationDigestOnly: map[string]bool{"c1": true},
+				Config: `{"pushToken":"tok"}`, PushToken: "tok",
+			}},
+		},
+		Maintenances: []MaintenanceDto{
+			{ID: "w1", CreateUpdateDto: maintenance.CreateUpdateDto{Title: "Upgrade", Active: true, Strategy: "manual", MonitorIds: []string{"m1"}}, ApprovedBy: ptr("admin")},
+			{ID: "w2", CreateUpdateDto: maintenance.CreateUpdateDto{Title: "Freeze", Strategy: "manual", Duration: ptr(60), MonitorIds: []string{"m1", "m2"}}, Rejected: true, ApprovedBy: ptr("ops")},
+		},
+		StatusPages: []StatusPageDto{
+			{ID: "s1", CreateStatusPageDTO: status_page.CreateStatusPageDTO{
+				Slug: "status", Title: "Example status", Published: true, ShowTags: true, AllowSubscriptions: true,
+				SubscriberChannelID: "c2", MonitorIDs: []string{"m1", "m2"}, Domains: []string{"status.example.com"},
+			}, HiddenMonitorIDs: []string{"m2"}},
+			{ID: "s2", CreateStatusPageDTO: status_page.CreateStatusPageDTO{
+				Slug: "internal", Title: "Internal status", Published: true, Password: "hunter2", MonitorIDs: []string{"m2"},
+			}},
+		},
+		Settings: []SettingDto{
+			{Key: maintenance.CheckDuringMaintenanceSettingKey, Value: "true", Type: "bool"},
+			{Key: proxy.DefaultProxySettingKey, Value: "p2", Type: "string"},
+			{Key: notification_channel.AdminNotificationChannelSettingKey, Value: "c1", Type: "string"},
+		},
+	}
+}
+
+func registerProviders() {
+	notification_channel.RegisterNotificationChannelProvider("slack", providers.NewSlackSender(zap.NewNop().Sugar(), nil))
+}
+
+func postRestore(t *testing.T, c *Controller, bundle *BundleDto, passphrase string) *httptest.ResponseRecorder {
+	t.Helper()
+	body, err := json.Marshal(bundle)
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.POST("/admin/restore", c.Restore)
+	req := httptest.NewRequest(http.MethodPost, "/admin/restore", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if passphrase != "" {
+		req.Header.Set(PassphraseHeader, passphrase)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestBackup_RoundTrip(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	registerProviders()
+	createdAt := time.Date(2025, 11, 8, 12, 0, 0, 0, time.UTC)
+	defer func(orig func() time.Time) { now = orig }(now)
+	now = func() time.Time { return createdAt }
+
+	// Restore the seed into the source instance and back it up with encryption
+	source := newStore()
+	sourceController := newTestController(source)
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	seedReport, err := sourceController.restoreBundle(ctx, seedBundle())
+	require.NoError(t, err)
+	assert.Equal(t, 2, seedReport.Monitors.Created)
+	assert.Equal(t, "API", seedReport.Monitors.Items[0].Name)
+
+	router := gin.New()
+	router.GET("/admin/backup", sourceController.Backup)
+	req := httptest.NewRequest(http.MethodGet, "/admin/backup", nil)
+	req.Header.Set(PassphraseHeader, "correct horse")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `attachment; filename="peekaping-backup-20251108-120000.json"`, w.Header().Get("Content-Disposition"))
+	assert.NotContains(t, w.Body.String(), "s3cret")
+	assert.NotContains(t, w.Body.String(), "hooks.slack.com")
+	assert.NotContains(t, w.Body.String(), "api.example.com")
+	assert.NotContains(t, w.Body.String(), `"tok"`)
+	assert.NotContains(t, w.Body.String(), "ACCESS_TOKEN_SECRET_KEY")
+
+	var encrypted BundleDto
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &encrypted))
+	require.NotNil(t, encrypted.Encryption)
+
+	// Restore it into an empty instance
+	target := newStore()
+	targetController := newTestController(target)
+	w = postRestore(t, targetController, &encrypted, "correct horse")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp struct {
+		Message string           `json:"message"`
+		Data    RestoreReportDto `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "Restored 14 of 14 items", resp.Message)
+	assert.Equal(t, 2, resp.Data.Monitors.Created)
+	assert.Equal(t, []string{"the page password can't be restored, the page was unpublished"}, resp.Data.StatusPages.Items[1].Warnings)
+
+	// Both instances back up the same configuration
+	plain, err := sourceController.exportBundle(ctx)
+	require.NoError(t, err)
+	restored, err := targetController.exportBundle(ctx)
+	require.NoError(t, err)
+
+	// except for the password protected page
+	assert.False(t, restored.StatusPages[1].Published)
+	assert.False(t, restored.StatusPages[1].PasswordProtected)
+	restored.StatusPages[1].Published = true
+	restored.StatusPages[1].PasswordProtected = true
+	assert.Equal(t, plain, restored)
+
+	// Relationships point to the restored entities
+	gateway, api := target.monitors[0], target.monitors[1]
+	assert.Equal(t, "Gateway", gateway.Name)
+	assert.Equal(t, gateway.ID, api.ParentId)
+	assert.Equal(t, target.proxies[0].ID, api.ProxyId)
+	assert.Equal(t, []string{target.proxies[1].ID}, api.FallbackProxyIds)
+	assert.Equal(t, "s3cret", target.proxies[0].Password)
+	assert.Equal(t, target.proxies[1].ID, target.settings[proxy.DefaultProxySettingKey].Value)
+	assert.Equal(t, target.channels[1].ID, target.statusPages[0].SubscriberChannelID)
+	assert.Equal(t, []string{gateway.ID, api.ID}, plain.StatusPages[0].MonitorIDs)
+	assert.Equal(t, []string{api.ID}, restored.StatusPages[0].HiddenMonitorIDs)
+	assert.Equal(t, maintenance.ApprovalStatusRejected, target.maintenances[1].ApprovalStatus)
+	assert.Equal(t, "ops", *target.maintenances[1].ApprovedBy)
+	assert.Equal(t, map[string]int{target.channels[1].ID: 3}, restored.Monitors[1].NotificationEscalateAfter)
+	assert.Equal(t, map[string]int{target.channels[0].ID: 5}, restored.Monitors[1].NotificationLimit)
+}
+
+func TestBackup_RestoreReusesExisting(t *testing.T) {
+	registerProviders()
+	s := newStore()
+	c := newTestController(s)
+	s.tags = append(s.tags, &tag.Model{ID: "existing-tag", Name: "Production", Color: "#000000"})
+	s.channels = append(s.channels, &notification_channel.Model{ID: "existing-slack", Name: "Ops Slack", Type: "slack"})
+	s.channels = append(s.channels, &notification_channel.Model{ID: "existing-mail", Name: "Escalation", Type: "smtp"})
+	s.statusPages = append(s.statusPages, &status_page.StatusPageWithMonitorsResponseDTO{ID: "existing-page", Slug: "internal"})
+
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	report, err := c.restoreBundle(ctx, seedBundle())
+	require.NoError(t, err)
+
+	assert.Equal(t, ItemResultDto{Name: "Production", ID: "t1", NewID: "existing-tag", Status: StatusExisting}, report.Tags.Items[0])
+	assert.Equal(t, StatusExisting, report.NotificationChannels.Items[0].Status)
+	assert.Equal(t, ItemResultDto{Name: "Escalation", ID: "c2", Status: StatusFailed, Reason: `a smtp notification channel named "Escalation" already exists`}, report.NotificationChannels.Items[1])
+	assert.Equal(t, []string{"notification channel c2 was not restored"}, report.Monitors.Items[0].Warnings)
+	assert.Equal(t, 1, report.StatusPages.Created)
+	assert.Equal(t, `a status page with slug "internal" already exists`, report.StatusPages.Items[1].Reason)
+	assert.Equal(t, []string{"notification channel c2 was not restored"}, report.StatusPages.Items[0].Warnings)
+
+	// Only the created channels and maintenance windows are audited here, monitors are
+	// audited by the monitor controller
+	audited := make([]string, 0, len(s.auditEntries))
+	for _, entry := range s.auditEntries {
+		audited = append(audited, entry.EntityType+" "+entry.Action)
+	}
+	assert.Equal(t, []string{"maintenance create", "maintenance create"}, audited)
+
+	api := s.monitors[1]
+	assert.Equal(t, []string{"existing-tag"}, func() []string {
+		ids := make([]string, 0)
+		for _, rel := range s.monitorTags {
+			if rel.MonitorID == api.ID {
+				ids = append(ids, rel.TagID)
+			}
+		}
+		return ids
+	}())
+}
+
+func TestBackup_RestoreRejects(t *testing.T) {
+	registerProviders()
+	c := newTestController(newStore())
+
+	t.Run("missing references", func(t *testing.T) {
+		bundle := seedBundle()
+		bundle.Tags = nil
+		bundle.Maintenances[0].MonitorIds = []string{"m9"}
+		bundle.Settings[1].Value = "p9"
+
+		w := postRestore(t, c, bundle, "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `monitor \"API\" references tag t1 which is not in the backup`)
+		assert.Contains(t, w.Body.String(), `maintenance \"Upgrade\" references monitor m9 which is not in the backup`)
+		assert.Contains(t, w.Body.String(), "setting default_proxy_id references proxy p9 which is not in the backup")
+	})
+
+	t.Run("parent cycle", func(t *testing.T) {
+		bundle := seedBundle()
+		bundle.Monitors[1].ParentId = "m2"
+
+		assert.Contains(t, validateReferences(bundle), `monitor "API" is its own ancestor`)
+	})
+
+	t.Run("encrypted without passphrase", func(t *testing.T) {
+		bundle := seedBundle()
+		require.NoError(t, sealSecrets(bundle, "correct horse"))
+
+		w := postRestore(t, c, bundle, "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "The backup is encrypted")
+
+		w = postRestore(t, c, bundle, "wrong")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), ErrWrongPassphrase.Error())
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		bundle := seedBundle()
+		bundle.Version = BundleVersion + 1
+
+		w := postRestore(t, c, bundle, "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestSecretBox(t *testing.T) {
+	bundle := seedBundle()
+	require.NoError(t, sealSecrets(bundle, "correct horse"))
+	assert.Equal(t, "aes-256-gcm", bundle.Encryption.Algorithm)
+	assert.Contains(t, bundle.Proxies[0].Password, encryptedPrefix)
+	assert.Empty(t, bundle.Proxies[1].Password)
+	for _, m := range bundle.Monitors {
+		assert.Contains(t, m.Config, encryptedPrefix, m.Name)
+	}
+	assert.Empty(t, bundle.Monitors[0].PushToken)
+	assert.Contains(t, bundle.Monitors[1].PushToken, encryptedPrefix)
+	assert.NotEqual(t, bundle.NotificationChannels[0].Config, bundle.NotificationChannels[1].Config)
+
+	sealed, err := json.Marshal(bundle)
+	require.NoError(t, err)
+	assert.ErrorIs(t, openSecrets(bundle, "wrong"), ErrWrongPassphrase)
+	unchanged, err := json.Marshal(bundle)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(sealed), string(unchanged))
+	require.NoError(t, openSecrets(bundle, "correct horse"))
+	assert.Nil(t, bundle.Encryption)
+	assert.Equal(t, "s3cret", bundle.Proxies[0].Password)
+	assert.Equal(t, seedBundle().NotificationChannels, bundle.NotificationChannels)
+	assert.Equal(t, seedBundle().Monitors, bundle.Monitors)
+}
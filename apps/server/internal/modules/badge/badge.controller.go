@@ -297,6 +297,9 @@ func (c *Controller) GetCertExpBadge(ctx *gin.Context) {
 // @Param			label		query	string	false	"Custom label"
 // @Param			suffix		query	string	false	"Value suffix"
 // @Param			color		query	string	false	"Badge color"
+// @Param			unit		query	string	false	"Unit of the value and thresholds (ms, s)"
+// @Param			warn		query	number	false	"Response time from which the badge is orange"
+// @Param			crit		query	number	false	"Response time from which the badge is red"
 // @Success		200	{string}	string	"SVG badge"
 // @Failure		400	{object}	utils.APIError[any]
 // @Failure		404	{object}	utils.APIError[any]
@@ -321,6 +324,10 @@ func (c *Controller) GetResponseBadge(ctx *gin.Context) {
 	}
 
 	options := c.parseQueryOptions(ctx)
+	if err := ParseResponseTimeOptions(options, ctx.Query("unit"), ctx.Query("warn"), ctx.Query("crit")); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewFailResponse(err.Error()))
+		return
+	}
 
 	svg, err := c.service.GenerateResponseBadge(ctx, monitorID, options)
 	if err != nil {
@@ -7,6 +7,7 @@ import (
 	"peekaping/internal/modules/monitor_status_page"
 	"peekaping/internal/modules/monitor_tls_info"
 	"peekaping/internal/modules/stats"
+	"peekaping/internal/modules/status_page"
 
 	"go.uber.org/dig"
 	"go.uber.org/zap"
@@ -27,6 +28,7 @@ type ServiceDependencies struct {
 	StatsService             stats.Service
 	TLSInfoService           monitor_tls_info.Service
 	MonitorStatusPageService monitor_status_page.Service
+	StatusPageService        status_page.Service
 	Logger                   *zap.SugaredLogger
 }
 
@@ -38,6 +40,7 @@ func NewBadgeService(deps ServiceDependencies) Service {
 		statsService:             deps.StatsService,
 		tlsInfoService:           deps.TLSInfoService,
 		monitorStatusPageService: deps.MonitorStatusPageService,
+		statusPageService:        deps.StatusPageService,
 		svgGenerator:             NewSVGBadgeGenerator(),
 		logger:                   deps.Logger.Named("[badge-service]"),
 	}
@@ -2,6 +2,8 @@ package badge
 
 import (
 	"fmt"
+	"math"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -17,6 +19,12 @@ const (
 	BadgeTypeResponse BadgeType = "response"
 )
 
+// Units the response time badge can display
+const (
+	ResponseUnitMs = "ms"
+	ResponseUnitS  = "s"
+)
+
 // BadgeStyle represents the visual style of the badge
 type BadgeStyle string
 
@@ -61,6 +69,11 @@ type BadgeOptions struct {
 	// Certificate expiry options
 	WarnDays int `json:"warn_days"`
 	DownDays int `json:"down_days"`
+
+	// Response time options, thresholds are in milliseconds and 0 when not set
+	Unit   string  `json:"unit"`
+	WarnMs float64 `json:"warn_ms"`
+	CritMs float64 `json:"crit_ms"`
 }
 
 // DefaultBadgeOptions returns default badge options
@@ -77,6 +90,7 @@ func DefaultBadgeOptions() *BadgeOptions {
 		Suffix:     "",
 		WarnDays:   14,
 		DownDays:   7,
+		Unit:       ResponseUnitMs,
 	}
 }
 
@@ -207,3 +221,69 @@ func GetCertExpiryStatus(days int, options *BadgeOptions) (string, string) {
 		return fmt.Sprintf("%dd", days), options.UpColor
 	}
 }
+
+// ParseResponseTimeOptions sets the unit and the warn and crit thresholds of the response
+// time badge. Thresholds are given in the unit and must be positive, with warn not above
+// crit when both are set. Empty values keep the defaults.
+func ParseResponseTimeOptions(options *BadgeOptions, unit, warn, crit string) error {
+	switch unit {
+	case "":
+	case ResponseUnitMs, ResponseUnitS:
+		options.Unit = unit
+	default:
+		return fmt.Errorf("unit must be %s or %s", ResponseUnitMs, ResponseUnitS)
+	}
+
+	parse := func(name, value string) (float64, error) {
+		if value == "" {
+			return 0, nil
+		}
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) || v <= 0 {
+			return 0, fmt.Errorf("%s must be a positive number", name)
+		}
+		if options.Unit == ResponseUnitS {
+			v *= 1000
+		}
+		return v, nil
+	}
+
+	warnMs, err := parse("warn", warn)
+	if err != nil {
+		return err
+	}
+	critMs, err := parse("crit", crit)
+	if err != nil {
+		return err
+	}
+	if warnMs > 0 && critMs > 0 && warnMs > critMs {
+		return fmt.Errorf("warn must not be greater than crit")
+	}
+
+	options.WarnMs = warnMs
+	options.CritMs = critMs
+	return nil
+}
+
+// GetResponseTimeColor returns the color for a response time. A threshold counts as
+// reached at its exact value. Without thresholds the badge keeps its configured color.
+func GetResponseTimeColor(ms float64, options *BadgeOptions) string {
+	switch {
+	case options.WarnMs == 0 && options.CritMs == 0:
+		return options.Color
+	case options.CritMs > 0 && ms >= options.CritMs:
+		return options.DownColor
+	case options.WarnMs > 0 && ms >= options.WarnMs:
+		return "#fe7d37"
+	default:
+		return options.UpColor
+	}
+}
+
+// FormatResponseTime formats a response time in milliseconds in the given unit
+func FormatResponseTime(ms int, unit string) string {
+	if unit == ResponseUnitS {
+		return strconv.FormatFloat(float64(ms)/1000, 'f', -1, 64)
+	}
+	return strconv.Itoa(ms)
+}
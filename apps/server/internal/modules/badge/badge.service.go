@@ -8,7 +8,7 @@ import (
 	"peekaping/internal/modules/monitor_status_page"
 	"peekaping/internal/modules/monitor_tls_info"
 	"peekaping/internal/modules/stats"
-	"strconv"
+	"peekaping/internal/modules/status_page"
 	"time"
 
 	"go.uber.org/zap"
@@ -26,12 +26,18 @@ type Service interface {
 	IsMonitorPublic(ctx context.Context, monitorID string) (bool, error)
 }
 
+// statusPageFinder is the part of the status page service badges use
+type statusPageFinder interface {
+	FindByID(ctx context.Context, id string) (*status_page.Model, error)
+}
+
 type ServiceImpl struct {
 	monitorService           monitor.Service
 	heartbeatService         heartbeat.Service
 	statsService             stats.Service
 	tlsInfoService           monitor_tls_info.Service
 	monitorStatusPageService monitor_status_page.Service
+	statusPageService        statusPageFinder
 	svgGenerator             *SVGBadgeGenerator
 	logger                   *zap.SugaredLogger
 }
@@ -42,6 +48,7 @@ func NewService(
 	statsService stats.Service,
 	tlsInfoService monitor_tls_info.Service,
 	monitorStatusPageService monitor_status_page.Service,
+	statusPageService statusPageFinder,
 	logger *zap.SugaredLogger,
 ) Service {
 	return &ServiceImpl{
@@ -50,6 +57,7 @@ func NewService(
 		statsService:             statsService,
 		tlsInfoService:           tlsInfoService,
 		monitorStatusPageService: monitorStatusPageService,
+		statusPageService:        statusPageService,
 		svgGenerator:             NewSVGBadgeGenerator(),
 		logger:                   logger.Named("[badge-service]"),
 	}
@@ -81,8 +89,19 @@ func (s *ServiceImpl) IsMonitorPublic(ctx context.Context, monitorID string) (bo
 		return true, nil
 	}
 
-	// Monitor is public if it's on at least one status page
-	return len(statusPages) > 0, nil
+	// Monitor is public if it's on at least one status page without a password, the
+	// badges of protected pages would show it to visitors without the password
+	for _, link := range statusPages {
+		page, err := s.statusPageService.FindByID(ctx, link.StatusPageID)
+		if err != nil {
+			s.logger.Warnw("Failed to get status page of monitor", "monitorID", monitorID, "statusPageID", link.StatusPageID, "error", err)
+			continue
+		}
+		if page != nil && page.PasswordHash == "" {
+			return true, nil
+		}
+	}
+	return false, nil
 }
 
 func (s *ServiceImpl) GetMonitorBadgeData(ctx context.Context, monitorID string) (*MonitorBadgeData, error) {
@@ -113,7 +132,7 @@ func (s *ServiceImpl) GetMonitorBadgeData(ctx context.Context, monitorID string)
 	// Get 24h ping stats
 	stats24h, err := s.statsService.FindStatsByMonitorIDAndTimeRange(ctx, monitorID, since24h, now, stats.StatHourly)
 	if err == nil && len(stats24h) > 0 {
-		summary24h := s.statsService.StatPointsSummary(stats24h)
+		summary24h := s.statsService.StatPointsSummary(ctx, stats24h)
 		if summary24h.AvgPing != nil {
 			data.AvgPing24h = summary24h.AvgPing
 		}
@@ -125,7 +144,7 @@ func (s *ServiceImpl) GetMonitorBadgeData(ctx context.Context, monitorID string)
 	// Get 30d ping stats
 	stats30d, err := s.statsService.FindStatsByMonitorIDAndTimeRange(ctx, monitorID, since30d, now, stats.StatDaily)
 	if err == nil && len(stats30d) > 0 {
-		summary30d := s.statsService.StatPointsSummary(stats30d)
+		summary30d := s.statsService.StatPointsSummary(ctx, stats30d)
 		if summary30d.AvgPing != nil {
 			data.AvgPing30d = summary30d.AvgPing
 		}
@@ -137,7 +156,7 @@ func (s *ServiceImpl) GetMonitorBadgeData(ctx context.Context, monitorID string)
 	// Get 90d ping stats
 	stats90d, err := s.statsService.FindStatsByMonitorIDAndTimeRange(ctx, monitorID, since90d, now, stats.StatDaily)
 	if err == nil && len(stats90d) > 0 {
-		summary90d := s.statsService.StatPointsSummary(stats90d)
+		summary90d := s.statsService.StatPointsSummary(ctx, stats90d)
 		if summary90d.AvgPing != nil {
 			data.AvgPing90d = summary90d.AvgPing
 		}
@@ -201,7 +220,7 @@ func (s *ServiceImpl) getMonitorWithStats(ctx context.Context, monitorID string,
 		since24h := now.Add(-24 * time.Hour)
 		stats24h, err := s.statsService.FindStatsByMonitorIDAndTimeRange(ctx, monitorID, since24h, now, stats.StatHourly)
 		if err == nil && len(stats24h) > 0 {
-			summary24h := s.statsService.StatPointsSummary(stats24h)
+			summary24h := s.statsService.StatPointsSummary(ctx, stats24h)
 			if summary24h.AvgPing != nil {
 				data.AvgPing24h = summary24h.AvgPing
 			}
@@ -214,7 +233,7 @@ func (s *ServiceImpl) getMonitorWithStats(ctx context.Context, monitorID string,
 		since30d := now.Add(-30 * 24 * time.Hour)
 		stats30d, err := s.statsService.FindStatsByMonitorIDAndTimeRange(ctx, monitorID, since30d, now, stats.StatDaily)
 		if err == nil && len(stats30d) > 0 {
-			summary30d := s.statsService.StatPointsSummary(stats30d)
+			summary30d := s.statsService.StatPointsSummary(ctx, stats30d)
 			if summary30d.AvgPing != nil {
 				data.AvgPing30d = summary30d.AvgPing
 			}
@@ -227,7 +246,7 @@ func (s *ServiceImpl) getMonitorWithStats(ctx context.Context, monitorID string,
 		since90d := now.Add(-90 * 24 * time.Hour)
 		stats90d, err := s.statsService.FindStatsByMonitorIDAndTimeRange(ctx, monitorID, since90d, now, stats.StatDaily)
 		if err == nil && len(stats90d) > 0 {
-			summary90d := s.statsService.StatPointsSummary(stats90d)
+			summary90d := s.statsService.StatPointsSummary(ctx, stats90d)
 			if summary90d.AvgPing != nil {
 				data.AvgPing90d = summary90d.AvgPing
 			}
@@ -458,17 +477,24 @@ func (s *ServiceImpl) GenerateResponseBadge(ctx context.Context, monitorID strin
 		return "", err
 	}
 
+	unit := options.Unit
+	if unit == "" {
+		unit = ResponseUnitMs
+	}
+
 	var value string
+	color := options.Color
 	if data.LastPing != nil {
-		value = strconv.Itoa(*data.LastPing)
+		value = FormatResponseTime(*data.LastPing, unit)
+		color = GetResponseTimeColor(float64(*data.LastPing), options)
 	} else {
 		value = "N/A"
 	}
 
-	// Use custom suffix if provided, otherwise use default
+	// Use custom suffix if provided, otherwise the unit
 	suffix := options.Suffix
 	if suffix == "" {
-		suffix = "ms"
+		suffix = unit
 	}
 
 	label := getLabel(options.Label, "response")
@@ -478,7 +504,7 @@ func (s *ServiceImpl) GenerateResponseBadge(ctx context.Context, monitorID strin
 		Style:      options.Style,
 		Label:      FormatLabel(label, options.LabelPrefix, options.LabelSuffix),
 		Value:      FormatValue(value, options.Prefix, suffix),
-		Color:      options.Color,
+		Color:      color,
 		LabelColor: options.LabelColor,
 	}
 
@@ -10,6 +10,7 @@ import (
 	"peekaping/internal/modules/monitor_tls_info"
 	"peekaping/internal/modules/shared"
 	"peekaping/internal/modules/stats"
+	"peekaping/internal/modules/status_page"
 	"testing"
 	"time"
 
@@ -86,6 +87,11 @@ func (m *MockMonitorService) GetHeartbeats(ctx context.Context, id string, limit
 	return args.Get(0).([]*heartbeat.Model), args.Error(1)
 }
 
+func (m *MockMonitorService) ExportHeartbeats(ctx context.Context, id string, since, until time.Time, fn func(*heartbeat.Model) error) error {
+	args := m.Called(ctx, id, since, until, fn)
+	return args.Error(0)
+}
+
 func (m *MockMonitorService) RemoveProxyReference(ctx context.Context, proxyId string) error {
 	args := m.Called(ctx, proxyId)
 	return args.Error(0)
@@ -170,16 +176,39 @@ func (m *MockHeartbeatService) DeleteOlderThan(ctx context.Context, cutoff time.
 	return args.Get(0).(int64), args.Error(1)
 }
 
+func (m *MockHeartbeatService) CompactOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	args := m.Called(ctx, cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockHeartbeatService) DeleteRollupsOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	args := m.Called(ctx, cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (m *MockHeartbeatService) FindByMonitorIDPaginated(ctx context.Context, monitorID string, limit, page int, important *bool, reverse bool) ([]*heartbeat.Model, error) {
 	args := m.Called(ctx, monitorID, limit, page, important, reverse)
 	return args.Get(0).([]*heartbeat.Model), args.Error(1)
 }
 
+func (m *MockHeartbeatService) FindLatestByMonitorIDs(ctx context.Context, monitorIDs []string) (map[string]*heartbeat.Model, error) {
+	args := m.Called(ctx, monitorIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]*heartbeat.Model), args.Error(1)
+}
+
 func (m *MockHeartbeatService) DeleteByMonitorID(ctx context.Context, monitorID string) error {
 	args := m.Called(ctx, monitorID)
 	return args.Error(0)
 }
 
+func (m *MockHeartbeatService) StreamByMonitorID(ctx context.Context, monitorID string, since, until time.Time, fn func(*heartbeat.Model) error) error {
+	args := m.Called(ctx, monitorID, since, until, fn)
+	return args.Error(0)
+}
+
 type MockStatsService struct {
 	mock.Mock
 }
@@ -203,11 +232,16 @@ func (m *MockStatsService) FindStatsByMonitorIDAndTimeRangeWithInterval(ctx cont
 	return args.Get(0).([]*stats.Stat), args.Error(1)
 }
 
-func (m *MockStatsService) StatPointsSummary(statsList []*stats.Stat) *stats.Stats {
-	args := m.Called(statsList)
+func (m *MockStatsService) StatPointsSummary(ctx context.Context, statsList []*stats.Stat) *stats.Stats {
+	args := m.Called(ctx, statsList)
 	return args.Get(0).(*stats.Stats)
 }
 
+func (m *MockStatsService) DailyUptime(ctx context.Context, monitorID string, days int, now time.Time) ([]*stats.DayUptime, error) {
+	args := m.Called(ctx, monitorID, days, now)
+	return args.Get(0).([]*stats.DayUptime), args.Error(1)
+}
+
 func (m *MockStatsService) DeleteByMonitorID(ctx context.Context, monitorID string) error {
 	args := m.Called(ctx, monitorID)
 	return args.Error(0)
@@ -240,6 +274,11 @@ func (m *MockTLSInfoService) GetTLSInfoObject(ctx context.Context, monitorID str
 	return args.Error(0)
 }
 
+func (m *MockTLSInfoService) StorePreviousIssuer(ctx context.Context, monitorID string, issuer string) error {
+	args := m.Called(ctx, monitorID, issuer)
+	return args.Error(0)
+}
+
 func (m *MockTLSInfoService) DeleteTLSInfo(ctx context.Context, monitorID string) error {
 	args := m.Called(ctx, monitorID)
 	return args.Error(0)
@@ -348,6 +387,12 @@ func (m *MockMonitorStatusPageService) DeleteAllMonitorsForStatusPage(ctx contex
 	return args.Error(0)
 }
 
+type fakeStatusPages map[string]*status_page.Model
+
+func (f fakeStatusPages) FindByID(ctx context.Context, id string) (*status_page.Model, error) {
+	return f[id], nil
+}
+
 // Test setup helper
 func setupBadgeService() (*ServiceImpl, *MockMonitorService, *MockHeartbeatService, *MockStatsService, *MockTLSInfoService, *MockMonitorStatusPageService) {
 	mockMonitorService := &MockMonitorService{}
@@ -363,6 +408,7 @@ func setupBadgeService() (*ServiceImpl, *MockMonitorService, *MockHeartbeatServi
 		mockStatsService,
 		mockTLSInfoService,
 		mockMonitorStatusPageService,
+		fakeStatusPages{"page1": {ID: "page1", Slug: "public"}},
 		logger,
 	).(*ServiceImpl)
 
@@ -412,6 +458,7 @@ func TestNewService(t *testing.T) {
 	mockStatsService := &MockStatsService{}
 	mockTLSInfoService := &MockTLSInfoService{}
 	mockMonitorStatusPageService := &MockMonitorStatusPageService{}
+	statusPages := fakeStatusPages{}
 	logger := zap.NewNop().Sugar()
 
 	service := NewService(
@@ -420,6 +467,7 @@ func TestNewService(t *testing.T) {
 		mockStatsService,
 		mockTLSInfoService,
 		mockMonitorStatusPageService,
+		statusPages,
 		logger,
 	)
 
@@ -432,6 +480,7 @@ func TestNewService(t *testing.T) {
 	assert.Equal(t, mockStatsService, serviceImpl.statsService)
 	assert.Equal(t, mockTLSInfoService, serviceImpl.tlsInfoService)
 	assert.Equal(t, mockMonitorStatusPageService, serviceImpl.monitorStatusPageService)
+	assert.Equal(t, statusPages, serviceImpl.statusPageService)
 	assert.NotNil(t, serviceImpl.svgGenerator)
 	assert.NotNil(t, serviceImpl.logger)
 }
@@ -452,7 +501,7 @@ func TestServiceImpl_IsMonitorPublic(t *testing.T) {
 		}
 
 		statusPages := []*monitor_status_page.Model{
-			{ID: "page1", MonitorID: monitorID},
+			{ID: "link1", StatusPageID: "page1", MonitorID: monitorID},
 		}
 
 		mockMonitorService.On("FindByID", ctx, monitorID).Return(monitor, nil)
@@ -466,6 +515,34 @@ func TestServiceImpl_IsMonitorPublic(t *testing.T) {
 		mockStatusPageService.AssertExpectations(t)
 	})
 
+	t.Run("monitor is not public when only on password protected status pages", func(t *testing.T) {
+		service, mockMonitorService, _, _, _, mockStatusPageService := setupBadgeService()
+		service.statusPageService = fakeStatusPages{
+			"private": {ID: "private", Slug: "private", PasswordHash: "$2a$10$hash"},
+		}
+		monitorID := "monitor123"
+
+		monitor := &shared.Monitor{
+			ID:     monitorID,
+			Name:   "Test Monitor",
+			Active: true,
+		}
+
+		statusPages := []*monitor_status_page.Model{
+			{ID: "link1", StatusPageID: "private", MonitorID: monitorID},
+		}
+
+		mockMonitorService.On("FindByID", ctx, monitorID).Return(monitor, nil)
+		mockStatusPageService.On("GetStatusPagesForMonitor", ctx, monitorID).Return(statusPages, nil)
+
+		result, err := service.IsMonitorPublic(ctx, monitorID)
+
+		assert.NoError(t, err)
+		assert.False(t, result)
+		mockMonitorService.AssertExpectations(t)
+		mockStatusPageService.AssertExpectations(t)
+	})
+
 	t.Run("monitor is not public when not on any status page", func(t *testing.T) {
 		service, mockMonitorService, _, _, _, mockStatusPageService := setupBadgeService()
 		monitorID := "monitor123"
@@ -619,11 +696,11 @@ func TestServiceImpl_GetMonitorBadgeData(t *testing.T) {
 
 		mockMonitorService.On("FindByID", ctx, monitorID).Return(monitor, nil)
 		mockStatsService.On("FindStatsByMonitorIDAndTimeRange", ctx, monitorID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), stats.StatHourly).Return(stats24h, nil).Once()
-		mockStatsService.On("StatPointsSummary", stats24h).Return(summary24h).Once()
+		mockStatsService.On("StatPointsSummary", mock.Anything, stats24h).Return(summary24h).Once()
 		mockStatsService.On("FindStatsByMonitorIDAndTimeRange", ctx, monitorID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), stats.StatDaily).Return(stats30d, nil).Once()
-		mockStatsService.On("StatPointsSummary", stats30d).Return(summary30d).Once()
+		mockStatsService.On("StatPointsSummary", mock.Anything, stats30d).Return(summary30d).Once()
 		mockStatsService.On("FindStatsByMonitorIDAndTimeRange", ctx, monitorID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), stats.StatDaily).Return(stats90d, nil).Once()
-		mockStatsService.On("StatPointsSummary", stats90d).Return(summary90d).Once()
+		mockStatsService.On("StatPointsSummary", mock.Anything, stats90d).Return(summary90d).Once()
 		mockHeartbeatService.On("FindByMonitorIDPaginated", ctx, monitorID, 1, 0, (*bool)(nil), true).Return(heartbeats, nil)
 		mockTLSInfoService.On("GetTLSInfo", ctx, monitorID).Return(tlsInfo, nil)
 
@@ -735,9 +812,9 @@ func TestServiceImpl_GetMonitorBadgeData(t *testing.T) {
 
 		mockMonitorService.On("FindByID", ctx, monitorID).Return(monitor, nil)
 		mockStatsService.On("FindStatsByMonitorIDAndTimeRange", ctx, monitorID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), stats.StatHourly).Return(stats24h, nil).Once()
-		mockStatsService.On("StatPointsSummary", stats24h).Return(summary).Once()
+		mockStatsService.On("StatPointsSummary", mock.Anything, stats24h).Return(summary).Once()
 		mockStatsService.On("FindStatsByMonitorIDAndTimeRange", ctx, monitorID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), stats.StatDaily).Return(stats24h, nil).Twice()
-		mockStatsService.On("StatPointsSummary", stats24h).Return(summary).Twice()
+		mockStatsService.On("StatPointsSummary", mock.Anything, stats24h).Return(summary).Twice()
 		mockHeartbeatService.On("FindByMonitorIDPaginated", ctx, monitorID, 1, 0, (*bool)(nil), true).Return(heartbeats, nil)
 		mockTLSInfoService.On("GetTLSInfo", ctx, monitorID).Return(nil, errors.New("TLS error"))
 
@@ -902,7 +979,7 @@ func TestServiceImpl_GenerateUptimeBadge(t *testing.T) {
 
 		mockMonitorService.On("FindByID", ctx, monitorID).Return(monitor, nil)
 		mockStatsService.On("FindStatsByMonitorIDAndTimeRange", ctx, monitorID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), stats.StatHourly).Return(stats24h, nil)
-		mockStatsService.On("StatPointsSummary", stats24h).Return(summary)
+		mockStatsService.On("StatPointsSummary", mock.Anything, stats24h).Return(summary)
 
 		result, err := service.GenerateUptimeBadge(ctx, monitorID, duration, options)
 
@@ -937,7 +1014,7 @@ func TestServiceImpl_GenerateUptimeBadge(t *testing.T) {
 
 		mockMonitorService.On("FindByID", ctx, monitorID).Return(monitor, nil)
 		mockStatsService.On("FindStatsByMonitorIDAndTimeRange", ctx, monitorID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), stats.StatDaily).Return(stats30d, nil)
-		mockStatsService.On("StatPointsSummary", stats30d).Return(summary)
+		mockStatsService.On("StatPointsSummary", mock.Anything, stats30d).Return(summary)
 
 		result, err := service.GenerateUptimeBadge(ctx, monitorID, duration, options)
 
@@ -972,7 +1049,7 @@ func TestServiceImpl_GenerateUptimeBadge(t *testing.T) {
 
 		mockMonitorService.On("FindByID", ctx, monitorID).Return(monitor, nil)
 		mockStatsService.On("FindStatsByMonitorIDAndTimeRange", ctx, monitorID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), stats.StatDaily).Return(stats90d, nil)
-		mockStatsService.On("StatPointsSummary", stats90d).Return(summary)
+		mockStatsService.On("StatPointsSummary", mock.Anything, stats90d).Return(summary)
 
 		result, err := service.GenerateUptimeBadge(ctx, monitorID, duration, options)
 
@@ -1045,7 +1122,7 @@ func TestServiceImpl_GenerateUptimeBadge(t *testing.T) {
 
 		mockMonitorService.On("FindByID", ctx, monitorID).Return(monitor, nil)
 		mockStatsService.On("FindStatsByMonitorIDAndTimeRange", ctx, monitorID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), stats.StatHourly).Return(stats24h, nil)
-		mockStatsService.On("StatPointsSummary", stats24h).Return(summary)
+		mockStatsService.On("StatPointsSummary", mock.Anything, stats24h).Return(summary)
 
 		result, err := service.GenerateUptimeBadge(ctx, monitorID, duration, options)
 
@@ -1082,7 +1159,7 @@ func TestServiceImpl_GeneratePingBadge(t *testing.T) {
 
 		mockMonitorService.On("FindByID", ctx, monitorID).Return(monitor, nil)
 		mockStatsService.On("FindStatsByMonitorIDAndTimeRange", ctx, monitorID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), stats.StatHourly).Return(stats24h, nil)
-		mockStatsService.On("StatPointsSummary", stats24h).Return(summary)
+		mockStatsService.On("StatPointsSummary", mock.Anything, stats24h).Return(summary)
 
 		result, err := service.GeneratePingBadge(ctx, monitorID, duration, options)
 
@@ -1370,6 +1447,31 @@ func TestServiceImpl_GenerateResponseBadge(t *testing.T) {
 		mockMonitorService.AssertExpectations(t)
 		mockHeartbeatService.AssertExpectations(t)
 	})
+
+	t.Run("unit and thresholds", func(t *testing.T) {
+		service, mockMonitorService, mockHeartbeatService, _, _, _ := setupBadgeService()
+		monitorID := "monitor123"
+
+		monitor := &shared.Monitor{
+			ID:     monitorID,
+			Name:   "Test Monitor",
+			Status: shared.MonitorStatusUp,
+			Active: true,
+		}
+		heartbeats := []*heartbeat.Model{{ID: "hb1", MonitorID: monitorID, Ping: 1500}}
+
+		options := DefaultBadgeOptions()
+		assert.NoError(t, ParseResponseTimeOptions(options, "s", "0.5", "1"))
+
+		mockMonitorService.On("FindByID", ctx, monitorID).Return(monitor, nil)
+		mockHeartbeatService.On("FindByMonitorIDPaginated", ctx, monitorID, 1, 0, (*bool)(nil), true).Return(heartbeats, nil)
+
+		result, err := service.GenerateResponseBadge(ctx, monitorID, options)
+
+		assert.NoError(t, err)
+		assert.Contains(t, result, "1.5s")
+		assert.Contains(t, result, options.DownColor)
+	})
 }
 
 // Model utility method tests
@@ -1601,6 +1703,90 @@ func TestGetCertExpiryStatus(t *testing.T) {
 	}
 }
 
+func TestGetResponseTimeColor(t *testing.T) {
+	options := DefaultBadgeOptions()
+	options.WarnMs = 200
+	options.CritMs = 500
+
+	tests := []struct {
+		name          string
+		ms            float64
+		expectedColor string
+	}{
+		{"below warn", 199, options.UpColor},
+		{"at warn", 200, "#fe7d37"},
+		{"between warn and crit", 499, "#fe7d37"},
+		{"at crit", 500, options.DownColor},
+		{"above crit", 1200, options.DownColor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedColor, GetResponseTimeColor(tt.ms, options))
+		})
+	}
+
+	t.Run("only crit", func(t *testing.T) {
+		critOnly := DefaultBadgeOptions()
+		critOnly.CritMs = 500
+		assert.Equal(t, critOnly.UpColor, GetResponseTimeColor(499, critOnly))
+		assert.Equal(t, critOnly.DownColor, GetResponseTimeColor(500, critOnly))
+	})
+
+	t.Run("no thresholds keep the badge color", func(t *testing.T) {
+		plain := DefaultBadgeOptions()
+		plain.Color = "#123456"
+		assert.Equal(t, "#123456", GetResponseTimeColor(5000, plain))
+	})
+}
+
+func TestParseResponseTimeOptions(t *testing.T) {
+	t.Run("thresholds in seconds are converted", func(t *testing.T) {
+		options := DefaultBadgeOptions()
+		err := ParseResponseTimeOptions(options, "s", "0.25", "1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, ResponseUnitS, options.Unit)
+		assert.Equal(t, 250.0, options.WarnMs)
+		assert.Equal(t, 1000.0, options.CritMs)
+	})
+
+	t.Run("defaults without parameters", func(t *testing.T) {
+		options := DefaultBadgeOptions()
+		assert.NoError(t, ParseResponseTimeOptions(options, "", "", ""))
+		assert.Equal(t, ResponseUnitMs, options.Unit)
+		assert.Zero(t, options.WarnMs)
+		assert.Zero(t, options.CritMs)
+	})
+
+	t.Run("equal thresholds are allowed", func(t *testing.T) {
+		assert.NoError(t, ParseResponseTimeOptions(DefaultBadgeOptions(), "ms", "300", "300"))
+	})
+
+	invalid := []struct {
+		name, unit, warn, crit, expectedError string
+	}{
+		{"unknown unit", "min", "", "", "unit must be ms or s"},
+		{"non numeric warn", "", "fast", "", "warn must be a positive number"},
+		{"zero crit", "", "", "0", "crit must be a positive number"},
+		{"negative warn", "", "-5", "", "warn must be a positive number"},
+		{"infinite crit", "", "", "Inf", "crit must be a positive number"},
+		{"warn above crit", "", "800", "500", "warn must not be greater than crit"},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ParseResponseTimeOptions(DefaultBadgeOptions(), tt.unit, tt.warn, tt.crit)
+			assert.EqualError(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestFormatResponseTime(t *testing.T) {
+	assert.Equal(t, "1234", FormatResponseTime(1234, ResponseUnitMs))
+	assert.Equal(t, "1.234", FormatResponseTime(1234, ResponseUnitS))
+	assert.Equal(t, "0.12", FormatResponseTime(120, ResponseUnitS))
+}
+
 func TestFormatValue(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -1915,4 +2101,5 @@ func TestDefaultBadgeOptions(t *testing.T) {
 	assert.Equal(t, "", options.Suffix)
 	assert.Equal(t, 14, options.WarnDays)
 	assert.Equal(t, 7, options.DownDays)
+	assert.Equal(t, ResponseUnitMs, options.Unit)
 }
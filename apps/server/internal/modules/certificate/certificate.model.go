@@ -79,6 +79,26 @@ func parseCertificateInfo(cert *x509.Certificate, depth int, processed map[strin
 	return certInfo
 }
 
+// MissingSANs returns the expected subject alternative names that are not in the
+// certificate's ValidFor list. Names are compared case-insensitively and literally,
+// so a wildcard SAN does not satisfy a concrete host name.
+func MissingSANs(certInfo *CertificateInfo, expected []string) []string {
+	present := make(map[string]bool)
+	if certInfo != nil {
+		for _, name := range certInfo.ValidFor {
+			present[strings.ToLower(name)] = true
+		}
+	}
+
+	var missing []string
+	for _, name := range expected {
+		if !present[strings.ToLower(strings.TrimSpace(name))] {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
 // determineCertificateType determines the type of certificate
 func determineCertificateType(cert *x509.Certificate, depth int) string {
 	if depth == 0 {
@@ -0,0 +1,63 @@
+package certificate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMissingSANs(t *testing.T) {
+	certInfo := &CertificateInfo{
+		ValidFor: []string{"example.com", "www.example.com", "*.api.example.com", "127.0.0.1"},
+	}
+
+	tests := []struct {
+		name     string
+		certInfo *CertificateInfo
+		expected []string
+		missing  []string
+	}{
+		{
+			name:     "all present",
+			certInfo: certInfo,
+			expected: []string{"example.com", "www.example.com", "127.0.0.1"},
+			missing:  nil,
+		},
+		{
+			name:     "case insensitive",
+			certInfo: certInfo,
+			expected: []string{"WWW.Example.com"},
+			missing:  nil,
+		},
+		{
+			name:     "wildcard listed literally",
+			certInfo: certInfo,
+			expected: []string{"*.api.example.com"},
+			missing:  nil,
+		},
+		{
+			name:     "wildcard does not cover concrete name",
+			certInfo: certInfo,
+			expected: []string{"v1.api.example.com"},
+			missing:  []string{"v1.api.example.com"},
+		},
+		{
+			name:     "some missing",
+			certInfo: certInfo,
+			expected: []string{"example.com", "shop.example.com", "blog.example.com"},
+			missing:  []string{"shop.example.com", "blog.example.com"},
+		},
+		{
+			name:     "no certificate",
+			certInfo: nil,
+			expected: []string{"example.com"},
+			missing:  []string{"example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.missing, MissingSANs(tt.certInfo, tt.expected))
+		})
+	}
+}
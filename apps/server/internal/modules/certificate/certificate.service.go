@@ -29,9 +29,14 @@ type ServiceImpl struct {
 	logger                     *zap.SugaredLogger
 }
 
+// ExpectedIssuersSettingKey holds a JSON array of issuers a certificate may change to
+// without a notification, matched against the issuer DN, its CN or its O
+const ExpectedIssuersSettingKey = "cert_expected_issuers"
+
 // NotificationService interface for sending certificate expiry notifications
 type NotificationService interface {
 	SendCertificateExpiryNotification(ctx context.Context, monitorID string, monitorName string, certInfo *CertificateInfo, daysRemaining int, targetDays int) error
+	SendCertificateIssuerChangeNotification(ctx context.Context, monitorID string, previousIssuer string, certInfo *CertificateInfo) error
 }
 
 func NewService(
@@ -148,7 +153,74 @@ func (s *ServiceImpl) UpdateTLSInfo(ctx context.Context, monitorID string, tlsIn
 	}
 
 	// Store the new TLS info
-	return s.storeTLSInfo(ctx, monitorID, tlsInfo)
+	if err := s.storeTLSInfo(ctx, monitorID, tlsInfo); err != nil {
+		return err
+	}
+
+	if previousTLSInfo != nil && previousTLSInfo.CertInfo != nil &&
+		tlsInfo != nil && tlsInfo.CertInfo != nil &&
+		previousTLSInfo.CertInfo.Issuer != tlsInfo.CertInfo.Issuer {
+		s.handleIssuerChange(ctx, monitorID, previousTLSInfo.CertInfo.Issuer, tlsInfo.CertInfo)
+	}
+	return nil
+}
+
+// handleIssuerChange records the previous issuer and notifies about the change unless the
+// new issuer is expected
+func (s *ServiceImpl) handleIssuerChange(ctx context.Context, monitorID string, previousIssuer string, certInfo *CertificateInfo) {
+	s.logger.Infof("Certificate issuer changed for monitor %s: %s -> %s", monitorID, previousIssuer, certInfo.Issuer)
+
+	if err := s.tlsInfoService.StorePreviousIssuer(ctx, monitorID, previousIssuer); err != nil {
+		s.logger.Errorf("Failed to store previous issuer: %v", err)
+	}
+
+	expected, err := s.GetExpectedIssuers(ctx)
+	if err != nil {
+		s.logger.Errorf("Failed to get expected issuers: %v", err)
+	}
+	if IssuerExpected(certInfo.Issuer, expected) {
+		s.logger.Infof("New issuer of monitor %s is expected, skipping notification", monitorID)
+		return
+	}
+
+	if err := s.notificationService.SendCertificateIssuerChangeNotification(ctx, monitorID, previousIssuer, certInfo); err != nil {
+		s.logger.Errorf("Failed to send certificate issuer change notification: %v", err)
+	}
+}
+
+// GetExpectedIssuers retrieves the issuers certificates may change to without a notification
+func (s *ServiceImpl) GetExpectedIssuers(ctx context.Context) ([]string, error) {
+	setting, err := s.settingService.GetByKey(ctx, ExpectedIssuersSettingKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expected issuers setting: %w", err)
+	}
+	if setting == nil || setting.Value == "" {
+		return nil, nil
+	}
+
+	var issuers []string
+	if err := json.Unmarshal([]byte(setting.Value), &issuers); err != nil {
+		return nil, fmt.Errorf("failed to parse expected issuers setting: %w", err)
+	}
+	return issuers, nil
+}
+
+// IssuerExpected reports whether issuer matches one of expected, compared case-insensitively
+// with the whole DN, its common name or its organization
+func IssuerExpected(issuer string, expected []string) bool {
+	candidates := []string{issuer, extractCommonName(issuer), extractOrganization(issuer)}
+	for _, entry := range expected {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		for _, candidate := range candidates {
+			if candidate != "" && strings.EqualFold(entry, candidate) {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 // getPreviousTLSInfo retrieves previously stored TLS info for a monitor
@@ -264,6 +336,16 @@ func extractCommonName(subject string) string {
 	return subject
 }
 
+// extractOrganization extracts the organization from a certificate DN string
+func extractOrganization(dn string) string {
+	for _, part := range strings.Split(dn, ",") {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(part), "O="); ok {
+			return value
+		}
+	}
+	return ""
+}
+
 // Helper function to check if certificate expiry notification is enabled
 func (s *ServiceImpl) IsExpiryNotificationEnabled(ctx context.Context) bool {
 	days, err := s.GetNotificationDays(ctx)
@@ -284,6 +366,19 @@ type CertificateExpiryEvent struct {
 	Message       string           `json:"message"`
 }
 
+// CertificateIssuerChangeEvent represents a certificate issuer change event payload
+type CertificateIssuerChangeEvent struct {
+	MonitorID      string           `json:"monitor_id"`
+	PreviousIssuer string           `json:"previous_issuer"`
+	CertInfo       *CertificateInfo `json:"cert_info"`
+	Message        string           `json:"message"`
+}
+
+// IssuerChangeMessage describes an issuer change
+func IssuerChangeMessage(previousIssuer string, issuer string) string {
+	return fmt.Sprintf("Certificate issuer changed from %s to %s", previousIssuer, issuer)
+}
+
 // EventBasedNotificationService integrates with the existing notification system via events
 type EventBasedNotificationService struct {
 	eventBus events.EventBus
@@ -333,6 +428,28 @@ func (s *EventBasedNotificationService) SendCertificateExpiryNotification(
 	return nil
 }
 
+func (s *EventBasedNotificationService) SendCertificateIssuerChangeNotification(
+	ctx context.Context,
+	monitorID string,
+	previousIssuer string,
+	certInfo *CertificateInfo,
+) error {
+	message := IssuerChangeMessage(previousIssuer, certInfo.Issuer)
+
+	s.eventBus.Publish(events.Event{
+		Type: events.CertificateIssuerChanged,
+		Payload: &CertificateIssuerChangeEvent{
+			MonitorID:      monitorID,
+			PreviousIssuer: previousIssuer,
+			CertInfo:       certInfo,
+			Message:        message,
+		},
+	})
+
+	s.logger.Infof("Published certificate issuer change event for monitor %s: %s", monitorID, message)
+	return nil
+}
+
 // SimpleNotificationService is a basic implementation of NotificationService for backward compatibility
 type SimpleNotificationService struct {
 	logger *zap.SugaredLogger
@@ -359,3 +476,13 @@ func (s *SimpleNotificationService) SendCertificateExpiryNotification(
 	s.logger.Warnf("Certificate Expiry Notification: %s", message)
 	return nil
 }
+
+func (s *SimpleNotificationService) SendCertificateIssuerChangeNotification(
+	ctx context.Context,
+	monitorID string,
+	previousIssuer string,
+	certInfo *CertificateInfo,
+) error {
+	s.logger.Warnf("Certificate Issuer Change Notification for monitor '%s': %s", monitorID, IssuerChangeMessage(previousIssuer, certInfo.Issuer))
+	return nil
+}
@@ -0,0 +1,248 @@
+package certificate
+
+import (
+	"context"
+	"testing"
+
+	"peekaping/internal/modules/notification_sent_history"
+	"peekaping/internal/modules/shared"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+type mockSettingService struct {
+	mock.Mock
+}
+
+func (m *mockSettingService) GetByKey(ctx context.Context, key string) (*shared.SettingModel, error) {
+	args := m.Called(ctx, key)
+	setting, _ := args.Get(0).(*shared.SettingModel)
+	return setting, args.Error(1)
+}
+
+func (m *mockSettingService) SetByKey(ctx context.Context, key string, entity *shared.SettingCreateUpdateDto) (*shared.SettingModel, error) {
+	args := m.Called(ctx, key, entity)
+	setting, _ := args.Get(0).(*shared.SettingModel)
+	return setting, args.Error(1)
+}
+
+func (m *mockSettingService) DeleteByKey(ctx context.Context, key string) error {
+	return m.Called(ctx, key).Error(0)
+}
+
+func (m *mockSettingService) InitializeSettings(ctx context.Context) error {
+	return m.Called(ctx).Error(0)
+}
+
+type mockNotificationService struct {
+	mock.Mock
+}
+
+func (m *mockNotificationService) SendCertificateExpiryNotification(ctx context.Context, monitorID string, monitorName string, certInfo *CertificateInfo, daysRemaining int, targetDays int) error {
+	return m.Called(ctx, monitorID, monitorName, certInfo, daysRemaining, targetDays).Error(0)
+}
+
+func (m *mockNotificationService) SendCertificateIssuerChangeNotification(ctx context.Context, monitorID string, previousIssuer string, certInfo *CertificateInfo) error {
+	return m.Called(ctx, monitorID, previousIssuer, certInfo).Error(0)
+}
+
+type mockNotificationHistoryService struct {
+	mock.Mock
+}
+
+func (m *mockNotificationHistoryService) CheckIfNotificationSent(ctx context.Context, notificationType string, monitorID string, targetDays int) (bool, error) {
+	args := m.Called(ctx, notificationType, monitorID, targetDays)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockNotificationHistoryService) RecordNotificationSent(ctx context.Context, notificationType string, monitorID string, targetDays int) error {
+	return m.Called(ctx, notificationType, monitorID, targetDays).Error(0)
+}
+
+func (m *mockNotificationHistoryService) ClearNotificationHistory(ctx context.Context, monitorID string, notificationType string) error {
+	return m.Called(ctx, monitorID, notificationType).Error(0)
+}
+
+func (m *mockNotificationHistoryService) CleanupOldRecords(ctx context.Context, olderThanDays int) error {
+	return m.Called(ctx, olderThanDays).Error(0)
+}
+
+func (m *mockNotificationHistoryService) GetNotificationHistory(ctx context.Context, monitorID string, notificationType string) ([]*notification_sent_history.Model, error) {
+	args := m.Called(ctx, monitorID, notificationType)
+	history, _ := args.Get(0).([]*notification_sent_history.Model)
+	return history, args.Error(1)
+}
+
+func (m *mockNotificationHistoryService) RecordTestModeNotification(ctx context.Context, channelID string, monitorID string, message string) error {
+	return m.Called(ctx, channelID, monitorID, message).Error(0)
+}
+
+func (m *mockNotificationHistoryService) GetTestModeNotifications(ctx context.Context, monitorID string) ([]*notification_sent_history.Model, error) {
+	args := m.Called(ctx, monitorID)
+	notifications, _ := args.Get(0).([]*notification_sent_history.Model)
+	return notifications, args.Error(1)
+}
+
+type mockTLSInfoService struct {
+	mock.Mock
+}
+
+func (m *mockTLSInfoService) GetTLSInfo(ctx context.Context, monitorID string) (*TLSInfo, error) {
+	args := m.Called(ctx, monitorID)
+	info, _ := args.Get(0).(*TLSInfo)
+	return info, args.Error(1)
+}
+
+func (m *mockTLSInfoService) StoreTLSInfo(ctx context.Context, monitorID string, infoJSON string) error {
+	return m.Called(ctx, monitorID, infoJSON).Error(0)
+}
+
+func (m *mockTLSInfoService) StoreTLSInfoObject(ctx context.Context, monitorID string, info interface{}) error {
+	return m.Called(ctx, monitorID, info).Error(0)
+}
+
+// GetTLSInfoObject copies the stored TLS info of the first expectation into obj
+func (m *mockTLSInfoService) GetTLSInfoObject(ctx context.Context, monitorID string, obj interface{}) error {
+	args := m.Called(ctx, monitorID, obj)
+	if stored, ok := args.Get(1).(*TLSInfo); ok && stored != nil {
+		*obj.(*TLSInfo) = *stored
+	}
+	return args.Error(0)
+}
+
+func (m *mockTLSInfoService) StorePreviousIssuer(ctx context.Context, monitorID string, issuer string) error {
+	return m.Called(ctx, monitorID, issuer).Error(0)
+}
+
+func (m *mockTLSInfoService) DeleteTLSInfo(ctx context.Context, monitorID string) error {
+	return m.Called(ctx, monitorID).Error(0)
+}
+
+func (m *mockTLSInfoService) CleanupOldRecords(ctx context.Context, olderThanDays int) error {
+	return m.Called(ctx, olderThanDays).Error(0)
+}
+
+const (
+	letsEncryptIssuer = "CN=R3,O=Let's Encrypt,C=US"
+	digiCertIssuer    = "CN=DigiCert Global G2 TLS RSA SHA256 2020 CA1,O=DigiCert Inc,C=US"
+)
+
+func tlsInfoIssuedBy(issuer string, fingerprint string) *TLSInfo {
+	return &TLSInfo{
+		Valid: true,
+		CertInfo: &CertificateInfo{
+			Subject:        "CN=example.com",
+			Issuer:         issuer,
+			Fingerprint256: fingerprint,
+		},
+	}
+}
+
+func TestServiceImpl_UpdateTLSInfo_IssuerChange(t *testing.T) {
+	ctx := context.Background()
+	const monitorID = "monitor-1"
+
+	tests := []struct {
+		name            string
+		previous        *TLSInfo
+		current         *TLSInfo
+		expectedIssuers string
+		expectChange    bool
+		expectNotify    bool
+	}{
+		{
+			name:         "issuer changed",
+			previous:     tlsInfoIssuedBy(letsEncryptIssuer, "AA"),
+			current:      tlsInfoIssuedBy(digiCertIssuer, "BB"),
+			expectChange: true,
+			expectNotify: true,
+		},
+		{
+			name:            "new issuer in the allowlist by organization",
+			previous:        tlsInfoIssuedBy(letsEncryptIssuer, "AA"),
+			current:         tlsInfoIssuedBy(digiCertIssuer, "BB"),
+			expectedIssuers: `["digicert inc"]`,
+			expectChange:    true,
+			expectNotify:    false,
+		},
+		{
+			name:            "new issuer not in the allowlist",
+			previous:        tlsInfoIssuedBy(letsEncryptIssuer, "AA"),
+			current:         tlsInfoIssuedBy(digiCertIssuer, "BB"),
+			expectedIssuers: `["R3", "Sectigo Limited"]`,
+			expectChange:    true,
+			expectNotify:    true,
+		},
+		{
+			name:     "renewed by the same issuer",
+			previous: tlsInfoIssuedBy(letsEncryptIssuer, "AA"),
+			current:  tlsInfoIssuedBy(letsEncryptIssuer, "BB"),
+		},
+		{
+			name:    "first check",
+			current: tlsInfoIssuedBy(letsEncryptIssuer, "AA"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := &mockSettingService{}
+			notifications := &mockNotificationService{}
+			history := &mockNotificationHistoryService{}
+			tlsInfo := &mockTLSInfoService{}
+			service := NewService(settings, notifications, history, tlsInfo, zap.NewNop().Sugar())
+
+			tlsInfo.On("GetTLSInfoObject", ctx, monitorID, mock.Anything).Return(nil, tt.previous)
+			tlsInfo.On("StoreTLSInfoObject", ctx, monitorID, tt.current).Return(nil)
+			history.On("ClearNotificationHistory", ctx, monitorID, "certificate").Return(nil).Maybe()
+			if tt.expectChange {
+				tlsInfo.On("StorePreviousIssuer", ctx, monitorID, tt.previous.CertInfo.Issuer).Return(nil)
+				var setting *shared.SettingModel
+				if tt.expectedIssuers != "" {
+					setting = &shared.SettingModel{Key: ExpectedIssuersSettingKey, Value: tt.expectedIssuers}
+				}
+				settings.On("GetByKey", ctx, ExpectedIssuersSettingKey).Return(setting, nil)
+			}
+			if tt.expectNotify {
+				notifications.On("SendCertificateIssuerChangeNotification", ctx, monitorID, letsEncryptIssuer, tt.current.CertInfo).Return(nil)
+			}
+
+			err := service.UpdateTLSInfo(ctx, monitorID, tt.current)
+			assert.NoError(t, err)
+
+			tlsInfo.AssertExpectations(t)
+			settings.AssertExpectations(t)
+			notifications.AssertExpectations(t)
+			if !tt.expectNotify {
+				notifications.AssertNotCalled(t, "SendCertificateIssuerChangeNotification", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestIssuerExpected(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected []string
+		matches  bool
+	}{
+		{"full DN", []string{digiCertIssuer}, true},
+		{"common name", []string{"DigiCert Global G2 TLS RSA SHA256 2020 CA1"}, true},
+		{"organization case insensitive", []string{" DIGICERT INC "}, true},
+		{"other issuer", []string{"Let's Encrypt", "R3"}, false},
+		{"empty allowlist", nil, false},
+		{"blank entry", []string{""}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.matches, IssuerExpected(digiCertIssuer, tt.expected))
+		})
+	}
+}
+
+func TestIssuerChangeMessage(t *testing.T) {
+	assert.Equal(t, "Certificate issuer changed from CN=R3 to CN=E1", IssuerChangeMessage("CN=R3", "CN=E1"))
+}
@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"time"
 
+	"peekaping/internal/modules/audit_log"
 	"peekaping/internal/modules/heartbeat"
 	"peekaping/internal/modules/monitor_tls_info"
 	"peekaping/internal/modules/notification_sent_history"
@@ -35,11 +36,98 @@ func cleanupHeartbeats(heartbeatService heartbeat.Service, settingService settin
 	logger.Infow("Deleted old heartbeats", "count", deleted, "cutoff", cutoff)
 }
 
-func cleanupNotificationHistory(notificationHistoryService notification_sent_history.Service, logger *zap.SugaredLogger) {
+// cleanupHeartbeatRollups deletes hourly and daily rollups older than
+// HEARTBEAT_ROLLUP_KEEP_DAYS. Rollups are kept forever when the setting is missing or zero.
+func cleanupHeartbeatRollups(heartbeatService heartbeat.Service, settingService setting.Service, logger *zap.SugaredLogger) {
+	settingModel, err := settingService.GetByKey(context.Background(), "HEARTBEAT_ROLLUP_KEEP_DAYS")
+	if err != nil {
+		logger.Errorw("Failed to fetch HEARTBEAT_ROLLUP_KEEP_DAYS setting", "error", err)
+		return
+	}
+	if settingModel == nil {
+		return
+	}
+
+	keepDays, err := strconv.Atoi(settingModel.Value)
+	if err != nil || keepDays < 0 {
+		logger.Errorw("Invalid HEARTBEAT_ROLLUP_KEEP_DAYS value", "value", settingModel.Value, "error", err)
+		return
+	}
+	if keepDays == 0 {
+		return
+	}
+
+	cutoff := time.Now().UTC().AddDate(0, 0, -keepDays)
+	deleted, err := heartbeatService.DeleteRollupsOlderThan(context.Background(), cutoff)
+	if err != nil {
+		logger.Errorw("Failed to delete old heartbeat rollups", "error", err)
+		return
+	}
+	if deleted > 0 {
+		logger.Infow("Deleted old heartbeat rollups", "count", deleted, "cutoff", cutoff)
+	}
+}
+
+// compactHeartbeats rolls up raw heartbeats older than HEARTBEAT_COMPACT_AFTER_DAYS into
+// hourly/daily rollups. Compaction is disabled when the setting is missing or zero.
+func compactHeartbeats(heartbeatService heartbeat.Service, settingService setting.Service, logger *zap.SugaredLogger) {
+	settingModel, err := settingService.GetByKey(context.Background(), "HEARTBEAT_COMPACT_AFTER_DAYS")
+	if err != nil {
+		logger.Errorw("Failed to fetch HEARTBEAT_COMPACT_AFTER_DAYS setting", "error", err)
+		return
+	}
+	if settingModel == nil {
+		return
+	}
+
+	compactAfterDays, err := strconv.Atoi(settingModel.Value)
+	if err != nil || compactAfterDays < 0 {
+		logger.Errorw("Invalid HEARTBEAT_COMPACT_AFTER_DAYS value", "value", settingModel.Value, "error", err)
+		return
+	}
+	if compactAfterDays == 0 {
+		return
+	}
+
+	cutoff := time.Now().UTC().AddDate(0, 0, -compactAfterDays)
+	purged, err := heartbeatService.CompactOlderThan(context.Background(), cutoff)
+	if err != nil {
+		logger.Errorw("Failed to compact old heartbeats", "error", err)
+		return
+	}
+	if purged > 0 {
+		logger.Infow("Compacted old heartbeats into rollups", "count", purged, "cutoff", cutoff)
+	}
+}
+
+// notificationHistoryRetentionDays reads NOTIFICATION_HISTORY_RETENTION_DAYS, falling back
+// to the default when it is missing or invalid
+func notificationHistoryRetentionDays(settingService setting.Service, logger *zap.SugaredLogger) int {
+	settingModel, err := settingService.GetByKey(context.Background(), notification_sent_history.RetentionDaysSettingKey)
+	if err != nil {
+		logger.Errorw("Failed to fetch NOTIFICATION_HISTORY_RETENTION_DAYS setting", "error", err)
+		return notification_sent_history.DefaultRetentionDays
+	}
+	if settingModel == nil {
+		return notification_sent_history.DefaultRetentionDays
+	}
+
+	days, err := strconv.Atoi(settingModel.Value)
+	if err != nil || days < 0 {
+		logger.Errorw("Invalid NOTIFICATION_HISTORY_RETENTION_DAYS value", "value", settingModel.Value, "error", err)
+		return notification_sent_history.DefaultRetentionDays
+	}
+	return days
+}
+
+func cleanupNotificationHistory(notificationHistoryService notification_sent_history.Service, settingService setting.Service, logger *zap.SugaredLogger) {
+	olderThanDays := notificationHistoryRetentionDays(settingService, logger)
+	if olderThanDays == 0 {
+		return
+	}
+
 	logger.Info("Cleaning up old notification history records...")
 
-	// Clean up records older than 90 days
-	olderThanDays := 90
 	err := notificationHistoryService.CleanupOldRecords(context.Background(), olderThanDays)
 	if err != nil {
 		logger.Errorw("Failed to cleanup notification history", "error", err)
@@ -49,6 +137,43 @@ func cleanupNotificationHistory(notificationHistoryService notification_sent_his
 	logger.Infow("Successfully cleaned up notification history records", "older_than_days", olderThanDays)
 }
 
+// auditLogRetentionDays reads AUDIT_LOG_RETENTION_DAYS, falling back to the default when
+// it is missing or invalid
+func auditLogRetentionDays(settingService setting.Service, logger *zap.SugaredLogger) int {
+	settingModel, err := settingService.GetByKey(context.Background(), audit_log.RetentionDaysSettingKey)
+	if err != nil {
+		logger.Errorw("Failed to fetch AUDIT_LOG_RETENTION_DAYS setting", "error", err)
+		return audit_log.DefaultRetentionDays
+	}
+	if settingModel == nil {
+		return audit_log.DefaultRetentionDays
+	}
+
+	days, err := strconv.Atoi(settingModel.Value)
+	if err != nil || days < 0 {
+		logger.Errorw("Invalid AUDIT_LOG_RETENTION_DAYS value", "value", settingModel.Value, "error", err)
+		return audit_log.DefaultRetentionDays
+	}
+	return days
+}
+
+func cleanupAuditLog(auditLogService audit_log.Service, settingService setting.Service, logger *zap.SugaredLogger) {
+	keepDays := auditLogRetentionDays(settingService, logger)
+	if keepDays == 0 {
+		return
+	}
+
+	cutoff := time.Now().UTC().AddDate(0, 0, -keepDays)
+	deleted, err := auditLogService.DeleteOlderThan(context.Background(), cutoff)
+	if err != nil {
+		logger.Errorw("Failed to delete old audit log entries", "error", err)
+		return
+	}
+	if deleted > 0 {
+		logger.Infow("Deleted old audit log entries", "count", deleted, "cutoff", cutoff)
+	}
+}
+
 func cleanupMonitorTLSInfo(tlsInfoService monitor_tls_info.Service, logger *zap.SugaredLogger) {
 	logger.Info("Cleaning up old monitor TLS info records...")
 
@@ -69,6 +194,7 @@ func StartCleanupCron(
 	settingService setting.Service,
 	notificationHistoryService notification_sent_history.Service,
 	tlsInfoService monitor_tls_info.Service,
+	auditLogService audit_log.Service,
 	logger *zap.SugaredLogger,
 ) {
 	c := cron.New()
@@ -77,13 +203,25 @@ func StartCleanupCron(
 		cleanupHeartbeats(heartbeatService, settingService, logger)
 	})
 
+	c.AddFunc("30 * * * *", func() {
+		compactHeartbeats(heartbeatService, settingService, logger)
+	})
+
+	c.AddFunc("45 * * * *", func() {
+		cleanupHeartbeatRollups(heartbeatService, settingService, logger)
+	})
+
 	c.AddFunc("0 * * * *", func() {
-		cleanupNotificationHistory(notificationHistoryService, logger)
+		cleanupNotificationHistory(notificationHistoryService, settingService, logger)
 	})
 
 	c.AddFunc("0 * * * *", func() {
 		cleanupMonitorTLSInfo(tlsInfoService, logger)
 	})
 
+	c.AddFunc("15 * * * *", func() {
+		cleanupAuditLog(auditLogService, settingService, logger)
+	})
+
 	c.Start()
 }
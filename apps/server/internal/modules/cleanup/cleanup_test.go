@@ -0,0 +1,209 @@
+package cleanup
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"peekaping/internal/modules/audit_log"
+	"peekaping/internal/modules/heartbeat"
+	"peekaping/internal/modules/notification_sent_history"
+	"peekaping/internal/modules/shared"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type MockSettingService struct {
+	mock.Mock
+}
+
+func (m *MockSettingService) GetByKey(ctx context.Context, key string) (*shared.SettingModel, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*shared.SettingModel), args.Error(1)
+}
+
+func (m *MockSettingService) SetByKey(ctx context.Context, key string, entity *shared.SettingCreateUpdateDto) (*shared.SettingModel, error) {
+	args := m.Called(ctx, key, entity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*shared.SettingModel), args.Error(1)
+}
+
+func (m *MockSettingService) DeleteByKey(ctx context.Context, key string) error {
+	return m.Called(ctx, key).Error(0)
+}
+
+func (m *MockSettingService) InitializeSettings(ctx context.Context) error {
+	return m.Called(ctx).Error(0)
+}
+
+type MockNotificationHistoryService struct {
+	mock.Mock
+}
+
+func (m *MockNotificationHistoryService) CheckIfNotificationSent(ctx context.Context, notificationType string, monitorID string, targetDays int) (bool, error) {
+	args := m.Called(ctx, notificationType, monitorID, targetDays)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockNotificationHistoryService) RecordNotificationSent(ctx context.Context, notificationType string, monitorID string, targetDays int) error {
+	return m.Called(ctx, notificationType, monitorID, targetDays).Error(0)
+}
+
+func (m *MockNotificationHistoryService) ClearNotificationHistory(ctx context.Context, monitorID string, notificationType string) error {
+	return m.Called(ctx, monitorID, notificationType).Error(0)
+}
+
+func (m *MockNotificationHistoryService) CleanupOldRecords(ctx context.Context, olderThanDays int) error {
+	return m.Called(ctx, olderThanDays).Error(0)
+}
+
+func (m *MockNotificationHistoryService) GetNotificationHistory(ctx context.Context, monitorID string, notificationType string) ([]*notification_sent_history.Model, error) {
+	args := m.Called(ctx, monitorID, notificationType)
+	return args.Get(0).([]*notification_sent_history.Model), args.Error(1)
+}
+
+func (m *MockNotificationHistoryService) RecordTestModeNotification(ctx context.Context, channelID string, monitorID string, message string) error {
+	return m.Called(ctx, channelID, monitorID, message).Error(0)
+}
+
+func (m *MockNotificationHistoryService) GetTestModeNotifications(ctx context.Context, monitorID string) ([]*notification_sent_history.Model, error) {
+	args := m.Called(ctx, monitorID)
+	return args.Get(0).([]*notification_sent_history.Model), args.Error(1)
+}
+
+func TestCleanupNotificationHistory(t *testing.T) {
+	logger := zap.NewNop().Sugar()
+	key := notification_sent_history.RetentionDaysSettingKey
+
+	tests := []struct {
+		name         string
+		setting      *shared.SettingModel
+		settingErr   error
+		expectedDays int
+	}{
+		{"configured retention", &shared.SettingModel{Key: key, Value: "14"}, nil, 14},
+		{"unset uses the default", nil, nil, notification_sent_history.DefaultRetentionDays},
+		{"invalid value uses the default", &shared.SettingModel{Key: key, Value: "soon"}, nil, notification_sent_history.DefaultRetentionDays},
+		{"negative value uses the default", &shared.SettingModel{Key: key, Value: "-1"}, nil, notification_sent_history.DefaultRetentionDays},
+		{"lookup error uses the default", nil, errors.New("db down"), notification_sent_history.DefaultRetentionDays},
+		{"zero keeps records forever", &shared.SettingModel{Key: key, Value: "0"}, nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settingService := new(MockSettingService)
+			settingService.On("GetByKey", mock.Anything, key).Return(tt.setting, tt.settingErr)
+
+			historyService := new(MockNotificationHistoryService)
+			if tt.expectedDays > 0 {
+				historyService.On("CleanupOldRecords", mock.Anything, tt.expectedDays).Return(nil)
+			}
+
+			cleanupNotificationHistory(historyService, settingService, logger)
+
+			historyService.AssertExpectations(t)
+			if tt.expectedDays == 0 {
+				historyService.AssertNotCalled(t, "CleanupOldRecords", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+// rollupHeartbeatService records the cutoffs rollups are deleted with
+type rollupHeartbeatService struct {
+	heartbeat.Service
+	cutoffs []time.Time
+}
+
+func (s *rollupHeartbeatService) DeleteRollupsOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	s.cutoffs = append(s.cutoffs, cutoff)
+	return 0, nil
+}
+
+func TestCleanupHeartbeatRollups(t *testing.T) {
+	logger := zap.NewNop().Sugar()
+	key := "HEARTBEAT_ROLLUP_KEEP_DAYS"
+
+	tests := []struct {
+		name         string
+		setting      *shared.SettingModel
+		expectedDays int
+	}{
+		{"configured retention", &shared.SettingModel{Key: key, Value: "400"}, 400},
+		{"unset keeps rollups forever", nil, 0},
+		{"zero keeps rollups forever", &shared.SettingModel{Key: key, Value: "0"}, 0},
+		{"invalid value keeps rollups", &shared.SettingModel{Key: key, Value: "-3"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settingService := new(MockSettingService)
+			settingService.On("GetByKey", mock.Anything, key).Return(tt.setting, nil)
+			heartbeatService := &rollupHeartbeatService{}
+
+			cleanupHeartbeatRollups(heartbeatService, settingService, logger)
+
+			if tt.expectedDays == 0 {
+				assert.Empty(t, heartbeatService.cutoffs)
+				return
+			}
+			require.Len(t, heartbeatService.cutoffs, 1)
+			expected := time.Now().UTC().AddDate(0, 0, -tt.expectedDays)
+			assert.WithinDuration(t, expected, heartbeatService.cutoffs[0], time.Minute)
+		})
+	}
+}
+
+// auditLogService records the cutoffs audit entries are deleted with
+type auditLogService struct {
+	audit_log.Service
+	cutoffs []time.Time
+}
+
+func (s *auditLogService) DeleteOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	s.cutoffs = append(s.cutoffs, cutoff)
+	return 0, nil
+}
+
+func TestCleanupAuditLog(t *testing.T) {
+	logger := zap.NewNop().Sugar()
+	key := audit_log.RetentionDaysSettingKey
+
+	tests := []struct {
+		name         string
+		setting      *shared.SettingModel
+		expectedDays int
+	}{
+		{"configured retention", &shared.SettingModel{Key: key, Value: "30"}, 30},
+		{"unset uses the default", nil, audit_log.DefaultRetentionDays},
+		{"invalid value uses the default", &shared.SettingModel{Key: key, Value: "-3"}, audit_log.DefaultRetentionDays},
+		{"zero keeps entries forever", &shared.SettingModel{Key: key, Value: "0"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settingService := new(MockSettingService)
+			settingService.On("GetByKey", mock.Anything, key).Return(tt.setting, nil)
+			service := &auditLogService{}
+
+			cleanupAuditLog(service, settingService, logger)
+
+			if tt.expectedDays == 0 {
+				assert.Empty(t, service.cutoffs)
+				return
+			}
+			require.Len(t, service.cutoffs, 1)
+			expected := time.Now().UTC().AddDate(0, 0, -tt.expectedDays)
+			assert.WithinDuration(t, expected, service.cutoffs[0], time.Minute)
+		})
+	}
+}
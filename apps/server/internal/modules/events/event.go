@@ -1,5 +1,7 @@
 package events
 
+import "time"
+
 // EventType represents the type of event
 type EventType string
 
@@ -20,8 +22,20 @@ const (
 	ProxyDeleted EventType = "proxy.deleted"
 	// CertificateExpiry is emitted when a certificate is expiring
 	CertificateExpiry EventType = "certificate.expiry"
+	// CertificateIssuerChanged is emitted when a monitor's certificate is issued by another CA
+	CertificateIssuerChanged EventType = "certificate.issuer_changed"
 	// ImportantHeartbeat is emitted when a heartbeat is important for notification purposes
 	ImportantHeartbeat EventType = "important.heartbeat"
+	// HighLatency is emitted when response times stay above a monitor's latency limit
+	HighLatency EventType = "monitor.high_latency"
+	// ProxyHealthChanged is emitted when a proxy goes down or comes back up
+	ProxyHealthChanged EventType = "proxy.health_changed"
+	// MonitorLifecycle is emitted next to MonitorCreated and MonitorDeleted with who made
+	// the change
+	MonitorLifecycle EventType = "monitor.lifecycle"
+	// SessionsRevoked is emitted when every token of a user is revoked, by logging out of
+	// all sessions or changing the password
+	SessionsRevoked EventType = "auth.sessions_revoked"
 )
 
 // Event represents a generic event with a type and payload
@@ -53,3 +67,48 @@ type HeartbeatCreatedPayload struct {
 	Ping      int
 	Time      int64 // Unix seconds
 }
+
+// HighLatencyPayload represents the payload for high latency events. Recovered is set on
+// the event sent once the average response time is back under the limit.
+type HighLatencyPayload struct {
+	MonitorID   string    `json:"monitor_id"`
+	MonitorName string    `json:"monitor_name"`
+	AvgPingMs   int       `json:"avg_ping_ms"`
+	LimitMs     int       `json:"limit_ms"`
+	Checks      int       `json:"checks"`
+	Recovered   bool      `json:"recovered,omitempty"`
+	Time        time.Time `json:"time"`
+}
+
+// Monitor lifecycle actions
+const (
+	MonitorActionCreated = "created"
+	MonitorActionDeleted = "deleted"
+)
+
+// MonitorLifecyclePayload represents the payload for monitor lifecycle events
+type MonitorLifecyclePayload struct {
+	Action      string `json:"action"`
+	MonitorID   string `json:"monitor_id"`
+	MonitorName string `json:"monitor_name"`
+	MonitorType string `json:"monitor_type"`
+	// ActorType is user, api_key or system, ActorID is empty for system changes
+	ActorType string    `json:"actor_type"`
+	ActorID   string    `json:"actor_id,omitempty"`
+	Time      time.Time `json:"time"`
+}
+
+// ProxyHealthPayload represents the payload for proxy health changed events
+type ProxyHealthPayload struct {
+	ProxyID string    `json:"proxy_id"`
+	Host    string    `json:"host"`
+	Port    int       `json:"port"`
+	Healthy bool      `json:"healthy"`
+	Message string    `json:"message,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+// SessionsRevokedPayload represents the payload for sessions revoked events
+type SessionsRevokedPayload struct {
+	UserID string `json:"user_id"`
+}
@@ -0,0 +1,33 @@
+package executor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+)
+
+// maxHashedBodySize bounds how much of a response body is hashed for change detection
+const maxHashedBodySize = 1 << 20
+
+// hashBody returns the SHA-256 of the normalized response body. Only the first
+// maxHashedBodySize bytes are considered, matches of the ignore patterns are removed
+// and whitespace runs are collapsed, so dynamic regions and reformatting don't count
+// as content changes.
+func hashBody(body []byte, ignore []string) (string, error) {
+	if len(body) > maxHashedBodySize {
+		body = body[:maxHashedBodySize]
+	}
+
+	for _, pattern := range ignore {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return "", err
+		}
+		body = re.ReplaceAll(body, nil)
+	}
+
+	normalized := strings.Join(strings.Fields(string(body)), " ")
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -1,9 +1,13 @@
 package executor
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"net"
+	"net/http"
+	"net/url"
 	"peekaping/internal/modules/shared"
 	"strings"
 	"time"
@@ -14,11 +18,32 @@ import (
 
 type DNSConfig struct {
 	Host           string `json:"host" validate:"required" example:"example.com"`
-	ResolverServer string `json:"resolver_server" validate:"required,ip" example:"1.1.1.1"`
-	Port           int    `json:"port" validate:"required,min=1,max=65535" example:"53"`
+	ResolverServer string `json:"resolver_server" validate:"required_without=DoHURL,omitempty,ip" example:"1.1.1.1"`
+	Port           int    `json:"port" validate:"required_without=DoHURL,omitempty,min=1,max=65535" example:"53"`
 	ResolveType    string `json:"resolve_type" validate:"required,oneof=A AAAA CAA CNAME MX NS PTR SOA SRV TXT" example:"A"`
+	// DoHURL sends the queries to a DNS over HTTPS endpoint (RFC 8484) instead of the
+	// resolver server, through the monitor's proxy when it has one
+	DoHURL string `json:"doh_url" validate:"omitempty,url" example:"https://cloudflare-dns.com/dns-query"`
+	// ExpectedValues are the record values the lookup must return, e.g. the IPs of an A
+	// record or the hosts of MX records. SRV values are "target:port", CAA values the
+	// value of the property and SOA values the primary name server.
+	ExpectedValues []string `json:"expected_values" validate:"omitempty,dive,required"`
+	// MatchMode is "exact" (the default) for the returned values to be exactly the expected
+	// set, or "any" for at least one expected value to be returned
+	MatchMode string `json:"match_mode" validate:"omitempty,oneof=exact any" example:"exact"`
 }
 
+const (
+	DNSMatchExact = "exact"
+	DNSMatchAny   = "any"
+)
+
+// dohMediaType is the content type of DNS over HTTPS requests and responses
+const dohMediaType = "application/dns-message"
+
+// maxDoHResponseSize is the largest DNS message a DoH endpoint may return
+const maxDoHResponseSize = 65535
+
 type DNSExecutor struct {
 	logger *zap.SugaredLogger
 }
@@ -38,7 +63,17 @@ func (s *DNSExecutor) Validate(configJSON string) error {
 	if err != nil {
 		return err
 	}
-	return GenericValidator(cfg.(*DNSConfig))
+	dnsCfg := cfg.(*DNSConfig)
+	if err := GenericValidator(dnsCfg); err != nil {
+		return err
+	}
+	if dnsCfg.DoHURL != "" {
+		u, err := url.Parse(dnsCfg.DoHURL)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+			return fmt.Errorf("doh_url must be an http or https URL")
+		}
+	}
+	return nil
 }
 
 func (d *DNSExecutor) Execute(ctx context.Context, m *Monitor, proxyModel *Proxy) *Result {
@@ -50,6 +85,18 @@ func (d *DNSExecutor) Execute(ctx context.Context, m *Monitor, proxyModel *Proxy
 
 	d.logger.Debugf("execute dns cfg: %+v", cfg)
 
+	if m.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(m.Timeout)*time.Second)
+		defer cancel()
+	}
+
+	if cfg.DoHURL != "" {
+		startTime := time.Now().UTC()
+		values, message, err := d.lookupDoH(ctx, m, cfg, proxyModel)
+		return d.lookupResult(m, cfg, startTime, len(values) > 0, message, values, err)
+	}
+
 	// Create custom resolver with specified DNS server
 	r := &net.Resolver{
 		PreferGo: true,
@@ -64,6 +111,8 @@ func (d *DNSExecutor) Execute(ctx context.Context, m *Monitor, proxyModel *Proxy
 	startTime := time.Now().UTC()
 	var recordsFound bool
 	var message string
+	// values holds the returned records for the expected values check
+	var values []string
 
 	switch strings.ToUpper(cfg.ResolveType) {
 	case "A":
@@ -75,6 +124,7 @@ func (d *DNSExecutor) Execute(ctx context.Context, m *Monitor, proxyModel *Proxy
 			for i, ip := range ips {
 				ipStrings[i] = ip.String()
 			}
+			values = ipStrings
 			message = fmt.Sprintf("A records: %s", strings.Join(ipStrings, ", "))
 		}
 	case "AAAA":
@@ -86,6 +136,7 @@ func (d *DNSExecutor) Execute(ctx context.Context, m *Monitor, proxyModel *Proxy
 			for i, ip := range ips {
 				ipStrings[i] = ip.String()
 			}
+			values = ipStrings
 			message = fmt.Sprintf("AAAA records: %s", strings.Join(ipStrings, ", "))
 		}
 	case "CNAME":
@@ -93,6 +144,7 @@ func (d *DNSExecutor) Execute(ctx context.Context, m *Monitor, proxyModel *Proxy
 		cname, err = r.LookupCNAME(ctx, cfg.Host)
 		if err == nil && cname != "" {
 			recordsFound = true
+			values = []string{cname}
 			message = fmt.Sprintf("CNAME: %s", cname)
 		}
 	case "MX":
@@ -103,6 +155,7 @@ func (d *DNSExecutor) Execute(ctx context.Context, m *Monitor, proxyModel *Proxy
 			mxStrings := make([]string, len(mxRecords))
 			for i, mx := range mxRecords {
 				mxStrings[i] = fmt.Sprintf("%s (priority: %d)", mx.Host, mx.Pref)
+				values = append(values, mx.Host)
 			}
 			message = fmt.Sprintf("MX records: %s", strings.Join(mxStrings, ", "))
 		}
@@ -115,6 +168,7 @@ func (d *DNSExecutor) Execute(ctx context.Context, m *Monitor, proxyModel *Proxy
 			for i, ns := range nsRecords {
 				nsStrings[i] = ns.Host
 			}
+			values = nsStrings
 			message = fmt.Sprintf("NS records: %s", strings.Join(nsStrings, ", "))
 		}
 	case "TXT":
@@ -122,6 +176,7 @@ func (d *DNSExecutor) Execute(ctx context.Context, m *Monitor, proxyModel *Proxy
 		txtRecords, err = r.LookupTXT(ctx, cfg.Host)
 		if err == nil && len(txtRecords) > 0 {
 			recordsFound = true
+			values = txtRecords
 			message = fmt.Sprintf("TXT records: %s", strings.Join(txtRecords, "; "))
 		}
 	case "PTR":
@@ -131,6 +186,7 @@ func (d *DNSExecutor) Execute(ctx context.Context, m *Monitor, proxyModel *Proxy
 		names, err = r.LookupAddr(ctx, cfg.Host)
 		if err == nil && len(names) > 0 {
 			recordsFound = true
+			values = names
 			message = fmt.Sprintf("PTR records: %s", strings.Join(names, ", "))
 		}
 	case "SRV":
@@ -144,6 +200,7 @@ func (d *DNSExecutor) Execute(ctx context.Context, m *Monitor, proxyModel *Proxy
 			srvStrings := make([]string, len(srvRecords))
 			for i, srv := range srvRecords {
 				srvStrings[i] = fmt.Sprintf("%s:%d (priority: %d, weight: %d)", srv.Target, srv.Port, srv.Priority, srv.Weight)
+				values = append(values, fmt.Sprintf("%s:%d", strings.TrimSuffix(srv.Target, "."), srv.Port))
 			}
 			message = fmt.Sprintf("SRV records (cname: %s): %s", srvCname, strings.Join(srvStrings, ", "))
 		}
@@ -156,13 +213,14 @@ func (d *DNSExecutor) Execute(ctx context.Context, m *Monitor, proxyModel *Proxy
 		client.Timeout = time.Duration(m.Timeout) * time.Second
 
 		var resp *dns.Msg
-		resp, _, err = client.Exchange(msg, fmt.Sprintf("%s:%d", cfg.ResolverServer, cfg.Port))
+		resp, _, err = client.ExchangeContext(ctx, msg, fmt.Sprintf("%s:%d", cfg.ResolverServer, cfg.Port))
 
 		if err == nil && resp != nil && len(resp.Answer) > 0 {
 			var caaStrings []string
 			for _, ans := range resp.Answer {
 				if caa, ok := ans.(*dns.CAA); ok {
 					caaStrings = append(caaStrings, fmt.Sprintf("%d %s %q", caa.Flag, caa.Tag, caa.Value))
+					values = append(values, caa.Value)
 				}
 			}
 			if len(caaStrings) > 0 {
@@ -179,12 +237,13 @@ func (d *DNSExecutor) Execute(ctx context.Context, m *Monitor, proxyModel *Proxy
 		client.Timeout = time.Duration(m.Timeout) * time.Second
 
 		var resp *dns.Msg
-		resp, _, err = client.Exchange(msg, fmt.Sprintf("%s:%d", cfg.ResolverServer, cfg.Port))
+		resp, _, err = client.ExchangeContext(ctx, msg, fmt.Sprintf("%s:%d", cfg.ResolverServer, cfg.Port))
 
 		if err == nil && resp != nil && len(resp.Answer) > 0 {
 			for _, ans := range resp.Answer {
 				if soa, ok := ans.(*dns.SOA); ok {
 					recordsFound = true
+					values = []string{soa.Ns}
 					message = fmt.Sprintf("SOA: Primary NS: %s, Admin: %s, Serial: %d, Refresh: %d, Retry: %d, Expire: %d, Min TTL: %d",
 						soa.Ns, soa.Mbox, soa.Serial, soa.Refresh, soa.Retry, soa.Expire, soa.Minttl)
 					break
@@ -195,6 +254,11 @@ func (d *DNSExecutor) Execute(ctx context.Context, m *Monitor, proxyModel *Proxy
 		err = fmt.Errorf("unsupported record type: %s", cfg.ResolveType)
 	}
 
+	return d.lookupResult(m, cfg, startTime, recordsFound, message, values, err)
+}
+
+// lookupResult turns the outcome of a lookup into the check result
+func (d *DNSExecutor) lookupResult(m *Monitor, cfg *DNSConfig, startTime time.Time, recordsFound bool, message string, values []string, err error) *Result {
 	endTime := time.Now().UTC()
 
 	if err != nil {
@@ -216,6 +280,20 @@ func (d *DNSExecutor) Execute(ctx context.Context, m *Monitor, proxyModel *Proxy
 		}
 	}
 
+	if len(cfg.ExpectedValues) > 0 && !dnsValuesMatch(cfg.ResolveType, values, cfg.ExpectedValues, cfg.MatchMode) {
+		d.logger.Infof("DNS records don't match expected values: %s, %s", m.Name, message)
+		expectation := "expected"
+		if cfg.MatchMode == DNSMatchAny {
+			expectation = "expected any of"
+		}
+		return &Result{
+			Status:    shared.MonitorStatusDown,
+			Message:   fmt.Sprintf("%s (%s %s)", message, expectation, strings.Join(cfg.ExpectedValues, ", ")),
+			StartTime: startTime,
+			EndTime:   endTime,
+		}
+	}
+
 	d.logger.Infof("DNS lookup successful: %s, %s", m.Name, message)
 
 	return &Result{
@@ -225,3 +303,176 @@ func (d *DNSExecutor) Execute(ctx context.Context, m *Monitor, proxyModel *Proxy
 		EndTime:   endTime,
 	}
 }
+
+// lookupDoH resolves the record with a DNS over HTTPS POST request (RFC 8484) and returns
+// the values and message in the same format as the native lookups
+func (d *DNSExecutor) lookupDoH(ctx context.Context, m *Monitor, cfg *DNSConfig, proxyModel *Proxy) ([]string, string, error) {
+	qtype, ok := dns.StringToType[strings.ToUpper(cfg.ResolveType)]
+	if !ok {
+		return nil, "", fmt.Errorf("unsupported record type: %s", cfg.ResolveType)
+	}
+
+	name := dns.Fqdn(cfg.Host)
+	if qtype == dns.TypePTR && net.ParseIP(cfg.Host) != nil {
+		reverse, err := dns.ReverseAddr(cfg.Host)
+		if err != nil {
+			return nil, "", err
+		}
+		name = reverse
+	}
+
+	query := new(dns.Msg)
+	query.SetQuestion(name, qtype)
+	// RFC 8484 recommends an ID of 0 so identical queries can be cached
+	query.Id = 0
+	packed, err := query.Pack()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to build DNS query: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.DoHURL, bytes.NewReader(packed))
+	if err != nil {
+		return nil, "", err
+	}
+	setDefaultHeaders(req)
+	req.Header.Set("Content-Type", dohMediaType)
+	req.Header.Set("Accept", dohMediaType)
+
+	client := &http.Client{
+		Timeout:   time.Duration(m.Timeout) * time.Second,
+		Transport: buildProxyTransport(&http.Transport{Proxy: http.ProxyFromEnvironment}, proxyModel),
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("DoH server responded with status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxDoHResponseSize))
+	if err != nil {
+		return nil, "", err
+	}
+
+	answer := new(dns.Msg)
+	if err := answer.Unpack(body); err != nil {
+		return nil, "", fmt.Errorf("invalid DoH response: %w", err)
+	}
+	if answer.Rcode != dns.RcodeSuccess {
+		return nil, "", fmt.Errorf("DoH server answered %s", dns.RcodeToString[answer.Rcode])
+	}
+
+	values, message := dohRecords(qtype, name, answer.Answer)
+	return values, message, nil
+}
+
+// dohRecords extracts the values of the queried type from the answer section, skipping
+// the CNAME chain that leads to them
+func dohRecords(qtype uint16, name string, answers []dns.RR) ([]string, string) {
+	var values, records []string
+	var message string
+
+	for _, rr := range answers {
+		if rr.Header().Rrtype != qtype {
+			continue
+		}
+		switch record := rr.(type) {
+		case *dns.A:
+			values = append(values, record.A.String())
+		case *dns.AAAA:
+			values = append(values, record.AAAA.String())
+		case *dns.CNAME:
+			if len(values) == 0 {
+				values = append(values, record.Target)
+			}
+		case *dns.MX:
+			records = append(records, fmt.Sprintf("%s (priority: %d)", record.Mx, record.Preference))
+			values = append(values, record.Mx)
+		case *dns.NS:
+			values = append(values, record.Ns)
+		case *dns.TXT:
+			values = append(values, strings.Join(record.Txt, ""))
+		case *dns.PTR:
+			values = append(values, record.Ptr)
+		case *dns.SRV:
+			records = append(records, fmt.Sprintf("%s:%d (priority: %d, weight: %d)", record.Target, record.Port, record.Priority, record.Weight))
+			values = append(values, fmt.Sprintf("%s:%d", strings.TrimSuffix(record.Target, "."), record.Port))
+		case *dns.CAA:
+			records = append(records, fmt.Sprintf("%d %s %q", record.Flag, record.Tag, record.Value))
+			values = append(values, record.Value)
+		case *dns.SOA:
+			if len(values) == 0 {
+				values = append(values, record.Ns)
+				message = fmt.Sprintf("SOA: Primary NS: %s, Admin: %s, Serial: %d, Refresh: %d, Retry: %d, Expire: %d, Min TTL: %d",
+					record.Ns, record.Mbox, record.Serial, record.Refresh, record.Retry, record.Expire, record.Minttl)
+			}
+		}
+	}
+
+	switch qtype {
+	case dns.TypeA, dns.TypeAAAA, dns.TypePTR:
+		message = fmt.Sprintf("%s records: %s", dns.TypeToString[qtype], strings.Join(values, ", "))
+	case dns.TypeCNAME:
+		message = fmt.Sprintf("CNAME: %s", strings.Join(values, ""))
+	case dns.TypeMX:
+		message = fmt.Sprintf("MX records: %s", strings.Join(records, ", "))
+	case dns.TypeNS:
+		message = fmt.Sprintf("NS records: %s", strings.Join(values, ", "))
+	case dns.TypeTXT:
+		message = fmt.Sprintf("TXT records: %s", strings.Join(values, "; "))
+	case dns.TypeSRV:
+		message = fmt.Sprintf("SRV records (cname: %s): %s", name, strings.Join(records, ", "))
+	case dns.TypeCAA:
+		message = fmt.Sprintf("CAA records: %s", strings.Join(records, "; "))
+	}
+	return values, message
+}
+
+// dnsValuesMatch compares the returned record values with the expected ones. With
+// DNSMatchAny one shared value is enough, otherwise both sets must be equal.
+func dnsValuesMatch(recordType string, values, expected []string, matchMode string) bool {
+	got := make(map[string]bool, len(values))
+	for _, value := range values {
+		got[normalizeDNSValue(recordType, value)] = true
+	}
+	want := make(map[string]bool, len(expected))
+	for _, value := range expected {
+		want[normalizeDNSValue(recordType, value)] = true
+	}
+
+	if matchMode == DNSMatchAny {
+		for value := range want {
+			if got[value] {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(got) != len(want) {
+		return false
+	}
+	for value := range want {
+		if !got[value] {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeDNSValue lets equivalent values compare equal, e.g. "Mail.Example.com." and
+// "mail.example.com" or two spellings of the same IPv6 address
+func normalizeDNSValue(recordType, value string) string {
+	value = strings.TrimSpace(value)
+	switch strings.ToUpper(recordType) {
+	case "A", "AAAA":
+		if ip := net.ParseIP(value); ip != nil {
+			return ip.String()
+		}
+	case "TXT", "CAA":
+		return value
+	}
+	return strings.TrimSuffix(strings.ToLower(value), ".")
+}
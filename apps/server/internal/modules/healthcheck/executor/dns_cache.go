@@ -0,0 +1,264 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// TTLResolver resolves a host name to its addresses together with how long the
+// answer may be cached
+type TTLResolver interface {
+	LookupIPTTL(ctx context.Context, host string) ([]net.IP, time.Duration, error)
+}
+
+type dnsCacheEntry struct {
+	ips       []net.IP
+	expiresAt time.Time
+}
+
+// dnsLookupCall is a lookup in progress, concurrent callers for the same host wait for it
+type dnsLookupCall struct {
+	done chan struct{}
+	ips  []net.IP
+	err  error
+}
+
+// DNSCache is a shared, TTL respecting cache of host resolutions. Answers are kept
+// for their record TTL clamped to [minTTL, maxTTL], concurrent lookups of the same
+// host are collapsed into one and failed lookups are not cached. It satisfies
+// HostResolver so executors can use it in place of net.DefaultResolver.
+type DNSCache struct {
+	resolver TTLResolver
+	minTTL   time.Duration
+	maxTTL   time.Duration
+	now      func() time.Time
+
+	mu       sync.Mutex
+	entries  map[string]dnsCacheEntry
+	inflight map[string]*dnsLookupCall
+}
+
+func NewDNSCache(resolver TTLResolver, minTTL, maxTTL time.Duration) *DNSCache {
+	if maxTTL < minTTL {
+		maxTTL = minTTL
+	}
+	return &DNSCache{
+		resolver: resolver,
+		minTTL:   minTTL,
+		maxTTL:   maxTTL,
+		now:      time.Now,
+		entries:  make(map[string]dnsCacheEntry),
+		inflight: make(map[string]*dnsLookupCall),
+	}
+}
+
+// LookupIP returns the cached addresses of host, resolving it when the entry is
+// missing or expired. network filters the result like net.Resolver.LookupIP.
+func (c *DNSCache) LookupIP(ctx context.Context, network, host string) ([]net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return filterIPs([]net.IP{ip}, network, host)
+	}
+
+	key := strings.ToLower(host)
+
+	c.mu.Lock()
+	if entry, ok := c.entries[key]; ok && c.now().Before(entry.expiresAt) {
+		c.mu.Unlock()
+		return filterIPs(entry.ips, network, host)
+	}
+	call, ok := c.inflight[key]
+	if !ok {
+		call = &dnsLookupCall{done: make(chan struct{})}
+		c.inflight[key] = call
+		go c.lookup(key, call)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-call.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if call.err != nil {
+		return nil, call.err
+	}
+	return filterIPs(call.ips, network, host)
+}
+
+// lookup resolves key and stores the answer. It is detached from the caller's
+// context so one cancelled check does not fail the others waiting on it.
+func (c *DNSCache) lookup(key string, call *dnsLookupCall) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ips, ttl, err := c.resolver.LookupIPTTL(ctx, key)
+	if err == nil && len(ips) == 0 {
+		err = fmt.Errorf("no addresses found for %s", key)
+	}
+
+	c.mu.Lock()
+	if err == nil {
+		c.entries[key] = dnsCacheEntry{ips: ips, expiresAt: c.now().Add(c.clampTTL(ttl))}
+	}
+	delete(c.inflight, key)
+	c.mu.Unlock()
+
+	call.ips, call.err = ips, err
+	close(call.done)
+}
+
+func (c *DNSCache) clampTTL(ttl time.Duration) time.Duration {
+	if ttl < c.minTTL {
+		return c.minTTL
+	}
+	if ttl > c.maxTTL {
+		return c.maxTTL
+	}
+	return ttl
+}
+
+// DialContext resolves the dialed host through the cache and connects to its
+// addresses in order until one accepts the connection
+func (c *DNSCache) DialContext(dialer *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return dialer.DialContext(ctx, network, addr)
+		}
+		ips, err := c.LookupIP(ctx, ipNetwork(network), host)
+		if err != nil {
+			return nil, err
+		}
+		var lastErr error
+		for _, ip := range ips {
+			conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+			if err == nil {
+				return conn, nil
+			}
+			lastErr = err
+		}
+		return nil, lastErr
+	}
+}
+
+// ipNetwork maps a dial network such as "tcp4" to the matching lookup network
+func ipNetwork(network string) string {
+	switch {
+	case strings.HasSuffix(network, "4"):
+		return "ip4"
+	case strings.HasSuffix(network, "6"):
+		return "ip6"
+	default:
+		return "ip"
+	}
+}
+
+func filterIPs(ips []net.IP, network, host string) ([]net.IP, error) {
+	if network != "ip4" && network != "ip6" {
+		return ips, nil
+	}
+	filtered := make([]net.IP, 0, len(ips))
+	for _, ip := range ips {
+		if (ip.To4() != nil) == (network == "ip4") {
+			filtered = append(filtered, ip)
+		}
+	}
+	if len(filtered) == 0 {
+		return nil, fmt.Errorf("no %s addresses found for %s", network, host)
+	}
+	return filtered, nil
+}
+
+// SystemTTLResolver queries the nameservers from /etc/resolv.conf directly so record
+// TTLs are known. Names the nameservers can't answer (e.g. /etc/hosts entries) fall
+// back to the system resolver, whose answers have no TTL and get the minimum.
+type SystemTTLResolver struct {
+	config   *dns.ClientConfig
+	client   *dns.Client
+	fallback HostResolver
+}
+
+func NewSystemTTLResolver() *SystemTTLResolver {
+	config, _ := dns.ClientConfigFromFile("/etc/resolv.conf")
+	return &SystemTTLResolver{
+		config:   config,
+		client:   &dns.Client{Timeout: 5 * time.Second},
+		fallback: net.DefaultResolver,
+	}
+}
+
+func (r *SystemTTLResolver) LookupIPTTL(ctx context.Context, host string) ([]net.IP, time.Duration, error) {
+	if r.config != nil && len(r.config.Servers) > 0 {
+		ips, ttl, err := r.query(ctx, host)
+		if err == nil && len(ips) > 0 {
+			return ips, ttl, nil
+		}
+	}
+
+	ips, err := r.fallback.LookupIP(ctx, "ip", host)
+	if err != nil {
+		return nil, 0, err
+	}
+	return ips, 0, nil
+}
+
+// query asks the first nameserver that answers for the A and AAAA records of host.
+// The TTL is the lowest of all records in the answers, including CNAMEs.
+func (r *SystemTTLResolver) query(ctx context.Context, host string) ([]net.IP, time.Duration, error) {
+	var lastErr error
+	for _, server := range r.config.Servers {
+		address := net.JoinHostPort(server, r.config.Port)
+		var ips []net.IP
+		var minTTL uint32
+		haveTTL := false
+		failed := false
+
+		for _, qtype := range []uint16{dns.TypeA, dns.TypeAAAA} {
+			msg := new(dns.Msg)
+			msg.SetQuestion(dns.Fqdn(host), qtype)
+			msg.RecursionDesired = true
+
+			resp, _, err := r.client.ExchangeContext(ctx, msg, address)
+			if err != nil {
+				lastErr = err
+				failed = true
+				break
+			}
+			if resp.Rcode != dns.RcodeSuccess {
+				lastErr = fmt.Errorf("%s lookup for %s failed: %s", dns.TypeToString[qtype], host, dns.RcodeToString[resp.Rcode])
+				continue
+			}
+			for _, rr := range resp.Answer {
+				switch record := rr.(type) {
+				case *dns.A:
+					ips = append(ips, record.A)
+				case *dns.AAAA:
+					ips = append(ips, record.AAAA)
+				}
+				if ttl := rr.Header().Ttl; !haveTTL || ttl < minTTL {
+					minTTL = ttl
+					haveTTL = true
+				}
+			}
+		}
+
+		if failed {
+			continue
+		}
+		if len(ips) > 0 {
+			return ips, time.Duration(minTTL) * time.Second, nil
+		}
+	}
+
+	if lastErr == nil {
+		lastErr = errors.New("no nameserver returned an answer")
+	}
+	return nil, 0, lastErr
+}
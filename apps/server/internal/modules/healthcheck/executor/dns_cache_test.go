@@ -0,0 +1,245 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"peekaping/internal/modules/shared"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// countingTTLResolver answers every host with a fixed address and TTL and counts lookups
+type countingTTLResolver struct {
+	ips   []net.IP
+	ttl   time.Duration
+	calls atomic.Int64
+}
+
+func (r *countingTTLResolver) LookupIPTTL(ctx context.Context, host string) ([]net.IP, time.Duration, error) {
+	r.calls.Add(1)
+	return r.ips, r.ttl, nil
+}
+
+// newTestDNSCache returns a cache whose clock is controlled by the returned function
+func newTestDNSCache(resolver TTLResolver, minTTL, maxTTL time.Duration) (*DNSCache, func(time.Duration)) {
+	cache := NewDNSCache(resolver, minTTL, maxTTL)
+	now := time.Now()
+	var mu sync.Mutex
+	cache.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	advance := func(d time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		now = now.Add(d)
+	}
+	return cache, advance
+}
+
+func TestDNSCache_TTLExpiry(t *testing.T) {
+	resolver := &countingTTLResolver{ips: []net.IP{net.ParseIP("10.0.0.1")}, ttl: 30 * time.Second}
+	cache, advance := newTestDNSCache(resolver, time.Second, time.Hour)
+	ctx := context.Background()
+
+	ips, err := cache.LookupIP(ctx, "ip", "example.test")
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.1", ips[0].String())
+	assert.Equal(t, int64(1), resolver.calls.Load())
+
+	// Within the TTL the cached answer is reused
+	advance(29 * time.Second)
+	_, err = cache.LookupIP(ctx, "ip", "EXAMPLE.test")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), resolver.calls.Load())
+
+	// Once the TTL expires the host is resolved again
+	advance(time.Second)
+	_, err = cache.LookupIP(ctx, "ip", "example.test")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), resolver.calls.Load())
+}
+
+func TestDNSCache_ClampsTTL(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("below the minimum", func(t *testing.T) {
+		resolver := &countingTTLResolver{ips: []net.IP{net.ParseIP("10.0.0.1")}, ttl: 0}
+		cache, advance := newTestDNSCache(resolver, 5*time.Second, time.Minute)
+
+		_, _ = cache.LookupIP(ctx, "ip", "example.test")
+		advance(4 * time.Second)
+		_, _ = cache.LookupIP(ctx, "ip", "example.test")
+		assert.Equal(t, int64(1), resolver.calls.Load())
+
+		advance(time.Second)
+		_, _ = cache.LookupIP(ctx, "ip", "example.test")
+		assert.Equal(t, int64(2), resolver.calls.Load())
+	})
+
+	t.Run("above the maximum", func(t *testing.T) {
+		resolver := &countingTTLResolver{ips: []net.IP{net.ParseIP("10.0.0.1")}, ttl: 24 * time.Hour}
+		cache, advance := newTestDNSCache(resolver, 5*time.Second, time.Minute)
+
+		_, _ = cache.LookupIP(ctx, "ip", "example.test")
+		advance(time.Minute)
+		_, _ = cache.LookupIP(ctx, "ip", "example.test")
+		assert.Equal(t, int64(2), resolver.calls.Load())
+	})
+}
+
+func TestDNSCache_FiltersByNetwork(t *testing.T) {
+	resolver := &countingTTLResolver{ips: []net.IP{net.ParseIP("2001:db8::1"), net.ParseIP("10.0.0.1")}, ttl: time.Minute}
+	cache := NewDNSCache(resolver, time.Second, time.Hour)
+
+	ips, err := cache.LookupIP(context.Background(), "ip4", "example.test")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.1"}, ipStrings(ips))
+
+	ips, err = cache.LookupIP(context.Background(), "ip6", "example.test")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2001:db8::1"}, ipStrings(ips))
+	assert.Equal(t, int64(1), resolver.calls.Load())
+}
+
+func TestDNSCache_ConcurrentLookupsShareOneQuery(t *testing.T) {
+	resolver := &countingTTLResolver{ips: []net.IP{net.ParseIP("10.0.0.1")}, ttl: time.Minute}
+	cache := NewDNSCache(resolver, time.Second, time.Hour)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := cache.LookupIP(context.Background(), "ip", "example.test")
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(1), resolver.calls.Load())
+}
+
+func TestTCPExecutor_Execute_UsesDNSCache(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	resolver := &countingTTLResolver{ips: []net.IP{deadBackend, liveBackend}, ttl: time.Minute}
+	executor := NewTCPExecutor(zap.NewNop().Sugar())
+	executor.dnsCache = NewDNSCache(resolver, time.Second, time.Hour)
+
+	monitor := &Monitor{
+		ID:      "tcp-cached",
+		Name:    "Cached TCP",
+		Type:    "tcp",
+		Timeout: 2,
+		Config:  fmt.Sprintf(`{"host":"backend.test","port":%d}`, port),
+	}
+
+	// The first address refuses the connection, the next one is tried
+	for i := 0; i < 3; i++ {
+		result := executor.Execute(context.Background(), monitor, nil)
+		assert.Equal(t, shared.MonitorStatusUp, result.Status)
+	}
+	assert.Equal(t, int64(1), resolver.calls.Load())
+
+	// re_resolve bypasses the cache
+	executor.resolver = staticResolver{ips: []net.IP{liveBackend}}
+	monitor.Config = fmt.Sprintf(`{"host":"backend.test","port":%d,"re_resolve":true}`, port)
+	result := executor.Execute(context.Background(), monitor, nil)
+	assert.Equal(t, shared.MonitorStatusUp, result.Status)
+	assert.Equal(t, int64(1), resolver.calls.Load())
+
+	// dns_cache_bypass resolves with the system resolver, which knows localhost only
+	monitor.Config = fmt.Sprintf(`{"host":"localhost","port":%d,"dns_cache_bypass":true}`, port)
+	result = executor.Execute(context.Background(), monitor, nil)
+	assert.Equal(t, shared.MonitorStatusUp, result.Status)
+	assert.Equal(t, int64(1), resolver.calls.Load())
+}
+
+func TestHTTPExecutor_Execute_DNSCacheBypass(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	port := server.Listener.Addr().(*net.TCPAddr).Port
+
+	resolver := &countingTTLResolver{ips: []net.IP{net.ParseIP("127.0.0.1")}, ttl: time.Minute}
+	executor := NewHTTPExecutor(zap.NewNop().Sugar())
+	executor.dnsCache = NewDNSCache(resolver, time.Second, time.Hour)
+
+	monitor := &Monitor{
+		ID:      "http-bypass",
+		Name:    "HTTP bypass",
+		Type:    "http",
+		Timeout: 2,
+	}
+
+	monitor.Config = fmt.Sprintf(`{"url":"http://localhost:%d","method":"GET","encoding":"text","accepted_statuscodes":["2XX"],"authMethod":"none"}`, port)
+	result := executor.Execute(context.Background(), monitor, nil)
+	assert.Equal(t, shared.MonitorStatusUp, result.Status)
+	assert.Equal(t, int64(1), resolver.calls.Load())
+
+	// A fresh cache would be queried again unless the monitor bypasses it
+	monitor.Config = fmt.Sprintf(`{"url":"http://localhost:%d","method":"GET","encoding":"text","accepted_statuscodes":["2XX"],"authMethod":"none","dns_cache_bypass":true}`, port)
+	executor.dnsCache = NewDNSCache(resolver, time.Second, time.Hour)
+	result = executor.Execute(context.Background(), monitor, nil)
+	assert.Equal(t, shared.MonitorStatusUp, result.Status)
+	assert.Equal(t, int64(1), resolver.calls.Load())
+}
+
+func BenchmarkDNSCache_LookupIP(b *testing.B) {
+	resolver := &countingTTLResolver{ips: []net.IP{net.ParseIP("10.0.0.1")}, ttl: time.Minute}
+	cache := NewDNSCache(resolver, time.Second, time.Hour)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := cache.LookupIP(ctx, "ip", "example.test"); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(resolver.calls.Load()), "resolver-calls")
+}
+
+func BenchmarkDNSCache_Uncached(b *testing.B) {
+	resolver := &countingTTLResolver{ips: []net.IP{net.ParseIP("10.0.0.1")}, ttl: time.Minute}
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := resolver.LookupIPTTL(ctx, "example.test"); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(resolver.calls.Load()), "resolver-calls")
+}
+
+func ipStrings(ips []net.IP) []string {
+	out := make([]string, 0, len(ips))
+	for _, ip := range ips {
+		out = append(out, ip.String())
+	}
+	return out
+}
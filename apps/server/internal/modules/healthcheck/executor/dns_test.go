@@ -2,11 +2,19 @@ package executor
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"peekaping/internal/modules/shared"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/miekg/dns"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
 
@@ -415,3 +423,236 @@ func TestNewDNSExecutor(t *testing.T) {
 	assert.NotNil(t, executor)
 	assert.NotNil(t, executor.logger)
 }
+
+// startTestDNSServer serves records, given in zone file format, on a local UDP port and
+// returns the port
+func startTestDNSServer(t *testing.T, records ...string) int {
+	answers := make(map[uint16][]dns.RR)
+	for _, record := range records {
+		rr, err := dns.NewRR(record)
+		require.NoError(t, err)
+		answers[rr.Header().Rrtype] = append(answers[rr.Header().Rrtype], rr)
+	}
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := &dns.Server{
+		PacketConn: conn,
+		Handler: dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+			reply := new(dns.Msg)
+			reply.SetReply(r)
+			for _, rr := range answers[r.Question[0].Qtype] {
+				if rr.Header().Name == r.Question[0].Name {
+					reply.Answer = append(reply.Answer, rr)
+				}
+			}
+			w.WriteMsg(reply)
+		}),
+	}
+	go server.ActivateAndServe()
+	t.Cleanup(func() { server.Shutdown() })
+
+	return conn.LocalAddr().(*net.UDPAddr).Port
+}
+
+func TestDNSExecutor_Execute_ExpectedValues(t *testing.T) {
+	port := startTestDNSServer(t,
+		"example.com. 60 IN A 93.184.215.14",
+		"example.com. 60 IN A 93.184.215.15",
+		"example.com. 60 IN MX 10 Mail.Example.com.",
+	)
+	executor := NewDNSExecutor(zap.NewNop().Sugar())
+
+	tests := []struct {
+		name            string
+		resolveType     string
+		expectedValues  string
+		matchMode       string
+		expectedStatus  shared.MonitorStatus
+		expectedMessage string
+	}{
+		{
+			name:            "no expectation",
+			resolveType:     "A",
+			expectedValues:  `[]`,
+			expectedStatus:  shared.MonitorStatusUp,
+			expectedMessage: "A records: 93.184.215.14, 93.184.215.15",
+		},
+		{
+			name:            "exact set matches in any order",
+			resolveType:     "A",
+			expectedValues:  `["93.184.215.15", "93.184.215.14"]`,
+			expectedStatus:  shared.MonitorStatusUp,
+			expectedMessage: "A records: 93.184.215.14, 93.184.215.15",
+		},
+		{
+			name:            "exact set with a missing record",
+			resolveType:     "A",
+			expectedValues:  `["93.184.215.14"]`,
+			expectedStatus:  shared.MonitorStatusDown,
+			expectedMessage: "A records: 93.184.215.14, 93.184.215.15 (expected 93.184.215.14)",
+		},
+		{
+			name:            "hijacked record",
+			resolveType:     "A",
+			expectedValues:  `["203.0.113.7"]`,
+			matchMode:       DNSMatchAny,
+			expectedStatus:  shared.MonitorStatusDown,
+			expectedMessage: "A records: 93.184.215.14, 93.184.215.15 (expected any of 203.0.113.7)",
+		},
+		{
+			name:            "contains any",
+			resolveType:     "A",
+			expectedValues:  `["203.0.113.7", "93.184.215.15"]`,
+			matchMode:       DNSMatchAny,
+			expectedStatus:  shared.MonitorStatusUp,
+			expectedMessage: "A records: 93.184.215.14, 93.184.215.15",
+		},
+		{
+			name:            "host names ignore case and trailing dot",
+			resolveType:     "MX",
+			expectedValues:  `["mail.example.com"]`,
+			expectedStatus:  shared.MonitorStatusUp,
+			expectedMessage: "MX records: Mail.Example.com. (priority: 10)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := fmt.Sprintf(`{"host": "example.com", "resolver_server": "127.0.0.1", "port": %d, "resolve_type": %q, "expected_values": %s, "match_mode": %q}`,
+				port, tt.resolveType, tt.expectedValues, tt.matchMode)
+			require.NoError(t, executor.Validate(config))
+
+			monitor := &Monitor{ID: "test-monitor", Type: "dns", Name: "Test Monitor", Interval: 30, Timeout: 5, Config: config}
+			result := executor.Execute(context.Background(), monitor, nil)
+
+			assert.Equal(t, tt.expectedStatus, result.Status)
+			assert.Equal(t, tt.expectedMessage, result.Message)
+		})
+	}
+}
+
+func TestDNSExecutor_Validate_ExpectedValues(t *testing.T) {
+	executor := NewDNSExecutor(zap.NewNop().Sugar())
+	base := `"host": "example.com", "resolver_server": "1.1.1.1", "port": 53, "resolve_type": "A"`
+
+	assert.NoError(t, executor.Validate(`{`+base+`, "expected_values": ["1.2.3.4"], "match_mode": "any"}`))
+	assert.Error(t, executor.Validate(`{`+base+`, "expected_values": ["1.2.3.4"], "match_mode": "all"}`))
+	assert.Error(t, executor.Validate(`{`+base+`, "expected_values": [""]}`))
+}
+
+// startTestDoHServer answers RFC 8484 POST requests with the records matching the question
+func startTestDoHServer(t *testing.T, requests *atomic.Int32, records ...string) *httptest.Server {
+	answers := make(map[uint16][]dns.RR)
+	for _, record := range records {
+		rr, err := dns.NewRR(record)
+		require.NoError(t, err)
+		answers[rr.Header().Rrtype] = append(answers[rr.Header().Rrtype], rr)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/dns-message" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		query := new(dns.Msg)
+		if err := query.Unpack(body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		reply := new(dns.Msg)
+		reply.SetReply(query)
+		for _, rr := range answers[query.Question[0].Qtype] {
+			if rr.Header().Name == query.Question[0].Name {
+				reply.Answer = append(reply.Answer, rr)
+			}
+		}
+		if query.Question[0].Name == "missing.example.com." {
+			reply.Rcode = dns.RcodeNameError
+		}
+		packed, _ := reply.Pack()
+		w.Header().Set("Content-Type", "application/dns-message")
+		w.Write(packed)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDNSExecutor_Execute_DoH(t *testing.T) {
+	var requests atomic.Int32
+	server := startTestDoHServer(t, &requests,
+		"example.com. 60 IN A 93.184.215.14",
+		"example.com. 60 IN AAAA 2606:2800:21f:cb07::1",
+		"example.com. 60 IN MX 10 mail.example.com.",
+		"example.com. 60 IN TXT \"v=spf1\" \" -all\"",
+		"_sip._tcp.example.com. 60 IN SRV 10 5 5060 sip.example.com.",
+		"example.com. 60 IN CAA 0 issue \"letsencrypt.org\"",
+		"14.215.184.93.in-addr.arpa. 60 IN PTR example.com.",
+	)
+	executor := NewDNSExecutor(zap.NewNop().Sugar())
+
+	tests := []struct {
+		name            string
+		host            string
+		resolveType     string
+		expectedValues  string
+		expectedStatus  shared.MonitorStatus
+		expectedMessage string
+	}{
+		{"A", "example.com", "A", `["93.184.215.14"]`, shared.MonitorStatusUp, "A records: 93.184.215.14"},
+		{"AAAA", "example.com", "AAAA", `[]`, shared.MonitorStatusUp, "AAAA records: 2606:2800:21f:cb07::1"},
+		{"MX", "example.com", "MX", `["mail.example.com"]`, shared.MonitorStatusUp, "MX records: mail.example.com. (priority: 10)"},
+		{"TXT", "example.com", "TXT", `["v=spf1 -all"]`, shared.MonitorStatusUp, "TXT records: v=spf1 -all"},
+		{"SRV", "_sip._tcp.example.com", "SRV", `["sip.example.com:5060"]`, shared.MonitorStatusUp, "SRV records (cname: _sip._tcp.example.com.): sip.example.com.:5060 (priority: 10, weight: 5)"},
+		{"CAA", "example.com", "CAA", `["letsencrypt.org"]`, shared.MonitorStatusUp, `CAA records: 0 issue "letsencrypt.org"`},
+		{"PTR of an IP", "93.184.215.14", "PTR", `["example.com"]`, shared.MonitorStatusUp, "PTR records: example.com."},
+		{"unexpected value", "example.com", "A", `["203.0.113.7"]`, shared.MonitorStatusDown, "A records: 93.184.215.14 (expected 203.0.113.7)"},
+		{"no records", "example.com", "NS", `[]`, shared.MonitorStatusDown, "No NS records found for example.com"},
+		{"NXDOMAIN", "missing.example.com", "A", `[]`, shared.MonitorStatusDown, "DNS lookup failed: DoH server answered NXDOMAIN"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests.Store(0)
+			config := fmt.Sprintf(`{"host": %q, "doh_url": %q, "resolve_type": %q, "expected_values": %s}`,
+				tt.host, server.URL+"/dns-query", tt.resolveType, tt.expectedValues)
+			require.NoError(t, executor.Validate(config))
+
+			monitor := &Monitor{ID: "test-monitor", Type: "dns", Name: "Test Monitor", Interval: 30, Timeout: 5, Config: config}
+			result := executor.Execute(context.Background(), monitor, nil)
+
+			assert.Equal(t, tt.expectedStatus, result.Status)
+			assert.Equal(t, tt.expectedMessage, result.Message)
+			assert.Equal(t, int32(1), requests.Load())
+		})
+	}
+}
+
+func TestDNSExecutor_Execute_DoHErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	executor := NewDNSExecutor(zap.NewNop().Sugar())
+	monitor := &Monitor{ID: "test-monitor", Type: "dns", Name: "Test Monitor", Interval: 30, Timeout: 5,
+		Config: fmt.Sprintf(`{"host": "example.com", "doh_url": %q, "resolve_type": "A"}`, server.URL)}
+
+	result := executor.Execute(context.Background(), monitor, nil)
+	assert.Equal(t, shared.MonitorStatusDown, result.Status)
+	assert.Equal(t, "DNS lookup failed: DoH server responded with status 502", result.Message)
+}
+
+func TestDNSExecutor_Validate_DoH(t *testing.T) {
+	executor := NewDNSExecutor(zap.NewNop().Sugar())
+
+	assert.NoError(t, executor.Validate(`{"host": "example.com", "doh_url": "https://cloudflare-dns.com/dns-query", "resolve_type": "A"}`))
+	assert.Error(t, executor.Validate(`{"host": "example.com", "doh_url": "ftp://cloudflare-dns.com/dns-query", "resolve_type": "A"}`))
+	assert.Error(t, executor.Validate(`{"host": "example.com", "doh_url": "not a url", "resolve_type": "A"}`))
+	// Without a DoH URL the resolver server and port are still required
+	assert.Error(t, executor.Validate(`{"host": "example.com", "resolve_type": "A"}`))
+}
@@ -3,6 +3,7 @@ package executor
 import (
 	"context"
 	"fmt"
+	"peekaping/internal/config"
 	"peekaping/internal/modules/certificate"
 	"peekaping/internal/modules/shared"
 	"time"
@@ -16,6 +17,15 @@ type Result struct {
 	StartTime time.Time
 	EndTime   time.Time
 	TLSInfo   *certificate.TLSInfo `json:"tls_info,omitempty"`
+	// ErrorCategory classifies the failure, see shared.ErrorCategory* constants
+	ErrorCategory string
+	// BodyHash is set by HTTP monitors with detect_body_change, see hashBody
+	BodyHash string
+	// ResponseSize is the size in bytes of the decoded response body of HTTP monitors
+	ResponseSize *int64
+	// ResponseBody is the start of the response body of HTTP monitors, available to the
+	// monitor's result expression in the worker and not passed on to the ingester
+	ResponseBody string `json:"-"`
 }
 
 type Monitor = shared.Monitor
@@ -52,15 +62,41 @@ type ExecutorRegistry struct {
 
 func NewExecutorRegistry(
 	logger *zap.SugaredLogger,
+	cfg *config.Config,
 ) *ExecutorRegistry {
 	registry := make(map[string]Executor)
 
-	registry["http"] = NewHTTPExecutor(logger)
-	registry["http-keyword"] = NewHTTPExecutor(logger)
-	registry["http-json-query"] = NewHTTPExecutor(logger)
+	// One cache is shared by every executor so checks of the same host reuse answers
+	var dnsCache *DNSCache
+	if cfg.DNSCacheEnabled {
+		dnsCache = NewDNSCache(NewSystemTTLResolver(), cfg.DNSCacheMinTTL, cfg.DNSCacheMaxTTL)
+	}
+	// Checks through the same proxy share its connections across HTTP executors
+	var proxyPool *ProxyTransportPool
+	if cfg.ProxyPoolEnabled {
+		proxyPool = NewProxyTransportPool(cfg.ProxyPoolMaxIdleConns, cfg.ProxyPoolIdleTimeout)
+	}
+	newHTTPExecutor := func() *HTTPExecutor {
+		e := NewHTTPExecutor(logger)
+		e.dnsCache = dnsCache
+		e.proxyPool = proxyPool
+		return e
+	}
+	tcpExecutor := NewTCPExecutor(logger)
+	tcpExecutor.dnsCache = dnsCache
+	pingExecutor := NewPingExecutor(logger)
+	pingExecutor.dnsCache = dnsCache
+	transactionExecutor := NewTransactionExecutor(logger)
+	transactionExecutor.dnsCache = dnsCache
+
+	registry["http"] = newHTTPExecutor()
+	registry["http-keyword"] = newHTTPExecutor()
+	registry["http-json-query"] = newHTTPExecutor()
+	registry["http-transaction"] = transactionExecutor
 	registry["push"] = NewPushExecutor(logger)
-	registry["tcp"] = NewTCPExecutor(logger)
-	registry["ping"] = NewPingExecutor(logger)
+	registry["group"] = NewGroupExecutor(logger)
+	registry["tcp"] = tcpExecutor
+	registry["ping"] = pingExecutor
 	registry["dns"] = NewDNSExecutor(logger)
 	registry["docker"] = NewDockerExecutor(logger)
 	registry["grpc-keyword"] = NewGRPCExecutor(logger)
@@ -73,6 +109,7 @@ func NewExecutorRegistry(
 	registry["mqtt"] = NewMQTTExecutor(logger)
 	registry["rabbitmq"] = NewRabbitMQExecutor(logger)
 	registry["kafka-producer"] = NewKafkaProducerExecutor(logger)
+	registry["smtp"] = NewSMTPExecutor(logger)
 
 	return &ExecutorRegistry{
 		registry: registry,
@@ -2,6 +2,7 @@ package executor
 
 import (
 	"context"
+	"peekaping/internal/config"
 	"peekaping/internal/modules/heartbeat"
 	"testing"
 	"time"
@@ -59,7 +60,7 @@ func (m *ExecutorMockHeartbeatService) DeleteByMonitorID(ctx context.Context, mo
 func TestExecutorRegistry_GetExecutor(t *testing.T) {
 	// Setup
 	logger := zap.NewNop().Sugar()
-	registry := NewExecutorRegistry(logger)
+	registry := NewExecutorRegistry(logger, &config.Config{})
 
 	tests := []struct {
 		name          string
@@ -119,7 +120,7 @@ func TestExecutorRegistry_GetExecutor(t *testing.T) {
 func TestExecutorRegistry_ValidateConfig(t *testing.T) {
 	// Setup
 	logger := zap.NewNop().Sugar()
-	registry := NewExecutorRegistry(logger)
+	registry := NewExecutorRegistry(logger, &config.Config{})
 
 	tests := []struct {
 		name          string
@@ -264,7 +265,7 @@ func TestExecutorRegistry_ValidateConfig(t *testing.T) {
 func TestExecutorRegistry_ValidateConfig_Error_Logging(t *testing.T) {
 	// Setup with a logger that can be captured
 	logger := zap.NewNop().Sugar()
-	registry := NewExecutorRegistry(logger)
+	registry := NewExecutorRegistry(logger, &config.Config{})
 
 	// Test that errors are properly logged
 	err := registry.ValidateConfig("http", `{"invalid": "config"}`)
@@ -275,7 +276,7 @@ func TestExecutorRegistry_ValidateConfig_Error_Logging(t *testing.T) {
 func TestExecutorRegistry_Execute(t *testing.T) {
 	// Setup
 	logger := zap.NewNop().Sugar()
-	registry := NewExecutorRegistry(logger)
+	registry := NewExecutorRegistry(logger, &config.Config{})
 
 	tests := []struct {
 		name            string
@@ -412,7 +413,7 @@ func TestNewExecutorRegistry(t *testing.T) {
 	logger := zap.NewNop().Sugar()
 
 	// Test registry creation
-	registry := NewExecutorRegistry(logger)
+	registry := NewExecutorRegistry(logger, &config.Config{})
 
 	// Verify registry is properly initialized
 	assert.NotNil(t, registry)
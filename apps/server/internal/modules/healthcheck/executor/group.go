@@ -0,0 +1,118 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"peekaping/internal/modules/shared"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Aggregation rules of a group monitor
+const (
+	GroupRuleAny    = "any"    // up when at least one child is up
+	GroupRuleAll    = "all"    // up when every child is up
+	GroupRuleQuorum = "quorum" // up when the weighted share of up children reaches quorum_percent
+)
+
+type GroupChild struct {
+	MonitorID string `json:"monitor_id" validate:"required"`
+	// Weight of the child in the quorum, 1 when unset
+	Weight float64 `json:"weight,omitempty" validate:"omitempty,gt=0"`
+}
+
+type GroupConfig struct {
+	Children      []GroupChild `json:"children" validate:"required,min=1,dive"`
+	Rule          string       `json:"rule" validate:"required,oneof=any all quorum"`
+	QuorumPercent float64      `json:"quorum_percent,omitempty" validate:"required_if=Rule quorum,gte=0,lte=100"`
+}
+
+// GroupExecutor derives the status of a group from the latest heartbeats of its
+// children, which the producer attaches to the monitor as ChildHeartbeats
+type GroupExecutor struct {
+	logger *zap.SugaredLogger
+}
+
+func NewGroupExecutor(logger *zap.SugaredLogger) *GroupExecutor {
+	return &GroupExecutor{
+		logger: logger,
+	}
+}
+
+func (g *GroupExecutor) Unmarshal(configJSON string) (any, error) {
+	return GenericUnmarshal[GroupConfig](configJSON)
+}
+
+func (g *GroupExecutor) Validate(configJSON string) error {
+	cfg, err := g.Unmarshal(configJSON)
+	if err != nil {
+		return err
+	}
+	return GenericValidator(cfg.(*GroupConfig))
+}
+
+func (g *GroupExecutor) Execute(ctx context.Context, m *Monitor, proxyModel *Proxy) *Result {
+	startTime := time.Now().UTC()
+
+	cfgAny, err := g.Unmarshal(m.Config)
+	if err != nil {
+		return DownResult(err, startTime, time.Now().UTC())
+	}
+	cfg := cfgAny.(*GroupConfig)
+
+	up, ratio := groupRatio(cfg.Children, m.ChildHeartbeats)
+	status := shared.MonitorStatusDown
+	if groupIsUp(cfg, ratio) {
+		status = shared.MonitorStatusUp
+	}
+
+	message := fmt.Sprintf("%d/%d children up (%.1f%% weighted)", up, len(cfg.Children), ratio*100)
+	if cfg.Rule == GroupRuleQuorum {
+		message = fmt.Sprintf("%s, quorum %.1f%%", message, cfg.QuorumPercent)
+	}
+
+	g.logger.Debugf("Group %s: %s", m.Name, message)
+
+	return &Result{
+		Status:    status,
+		Message:   message,
+		StartTime: startTime,
+		EndTime:   time.Now().UTC(),
+	}
+}
+
+// groupRatio returns how many children are up and their weighted share of the group.
+// Children under maintenance count as up, children without a heartbeat as down.
+func groupRatio(children []GroupChild, heartbeats map[string]*shared.HeartBeatModel) (int, float64) {
+	up := 0
+	var upWeight, totalWeight float64
+	for _, child := range children {
+		weight := child.Weight
+		if weight == 0 {
+			weight = 1
+		}
+		totalWeight += weight
+
+		hb := heartbeats[child.MonitorID]
+		if hb != nil && (hb.Status == shared.MonitorStatusUp || hb.Status == shared.MonitorStatusMaintenance) {
+			up++
+			upWeight += weight
+		}
+	}
+	if totalWeight == 0 {
+		return up, 0
+	}
+	return up, upWeight / totalWeight
+}
+
+func groupIsUp(cfg *GroupConfig, ratio float64) bool {
+	switch cfg.Rule {
+	case GroupRuleAll:
+		return ratio >= 1
+	case GroupRuleQuorum:
+		return ratio*100 >= cfg.QuorumPercent
+	default:
+		return ratio > 0
+	}
+}
@@ -0,0 +1,159 @@
+package executor
+
+import (
+	"context"
+	"testing"
+
+	"peekaping/internal/modules/shared"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func childBeats(statuses map[string]shared.MonitorStatus) map[string]*shared.HeartBeatModel {
+	beats := make(map[string]*shared.HeartBeatModel, len(statuses))
+	for id, status := range statuses {
+		beats[id] = &shared.HeartBeatModel{MonitorID: id, Status: status}
+	}
+	return beats
+}
+
+func TestGroupExecutor_Validate(t *testing.T) {
+	executor := NewGroupExecutor(zap.NewNop().Sugar())
+
+	tests := []struct {
+		name    string
+		config  string
+		wantErr bool
+	}{
+		{"any rule", `{"children":[{"monitor_id":"a"}],"rule":"any"}`, false},
+		{"weighted quorum", `{"children":[{"monitor_id":"a","weight":3},{"monitor_id":"b"}],"rule":"quorum","quorum_percent":70}`, false},
+		{"quorum without percentage", `{"children":[{"monitor_id":"a"}],"rule":"quorum"}`, true},
+		{"percentage above 100", `{"children":[{"monitor_id":"a"}],"rule":"quorum","quorum_percent":120}`, true},
+		{"negative weight", `{"children":[{"monitor_id":"a","weight":-1}],"rule":"any"}`, true},
+		{"no children", `{"children":[],"rule":"all"}`, true},
+		{"unknown rule", `{"children":[{"monitor_id":"a"}],"rule":"most"}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := executor.Validate(tt.config)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestGroupExecutor_Execute(t *testing.T) {
+	executor := NewGroupExecutor(zap.NewNop().Sugar())
+
+	// 7 of 10 children up
+	sevenOfTen := map[string]shared.MonitorStatus{}
+	children := ""
+	for i, id := range []string{"c0", "c1", "c2", "c3", "c4", "c5", "c6", "c7", "c8", "c9"} {
+		if i > 0 {
+			children += ","
+		}
+		children += `{"monitor_id":"` + id + `"}`
+		if i < 7 {
+			sevenOfTen[id] = shared.MonitorStatusUp
+		} else {
+			sevenOfTen[id] = shared.MonitorStatusDown
+		}
+	}
+
+	weighted := `[{"monitor_id":"primary","weight":3},{"monitor_id":"replica-1"},{"monitor_id":"replica-2"}]`
+
+	tests := []struct {
+		name            string
+		config          string
+		heartbeats      map[string]*shared.HeartBeatModel
+		expectedStatus  shared.MonitorStatus
+		expectedMessage string
+	}{
+		{
+			name:            "percentage quorum reached",
+			config:          `{"children":[` + children + `],"rule":"quorum","quorum_percent":70}`,
+			heartbeats:      childBeats(sevenOfTen),
+			expectedStatus:  shared.MonitorStatusUp,
+			expectedMessage: "7/10 children up (70.0% weighted), quorum 70.0%",
+		},
+		{
+			name:            "percentage quorum missed",
+			config:          `{"children":[` + children + `],"rule":"quorum","quorum_percent":75}`,
+			heartbeats:      childBeats(sevenOfTen),
+			expectedStatus:  shared.MonitorStatusDown,
+			expectedMessage: "7/10 children up (70.0% weighted), quorum 75.0%",
+		},
+		{
+			name:   "weighted child carries the quorum",
+			config: `{"children":` + weighted + `,"rule":"quorum","quorum_percent":60}`,
+			heartbeats: childBeats(map[string]shared.MonitorStatus{
+				"primary":   shared.MonitorStatusUp,
+				"replica-1": shared.MonitorStatusDown,
+				"replica-2": shared.MonitorStatusDown,
+			}),
+			expectedStatus:  shared.MonitorStatusUp,
+			expectedMessage: "1/3 children up (60.0% weighted)",
+		},
+		{
+			name:   "weighted child down breaks the quorum",
+			config: `{"children":` + weighted + `,"rule":"quorum","quorum_percent":60}`,
+			heartbeats: childBeats(map[string]shared.MonitorStatus{
+				"primary":   shared.MonitorStatusDown,
+				"replica-1": shared.MonitorStatusUp,
+				"replica-2": shared.MonitorStatusUp,
+			}),
+			expectedStatus:  shared.MonitorStatusDown,
+			expectedMessage: "2/3 children up (40.0% weighted)",
+		},
+		{
+			name:   "any child up",
+			config: `{"children":[{"monitor_id":"a"},{"monitor_id":"b"}],"rule":"any"}`,
+			heartbeats: childBeats(map[string]shared.MonitorStatus{
+				"a": shared.MonitorStatusDown,
+				"b": shared.MonitorStatusUp,
+			}),
+			expectedStatus:  shared.MonitorStatusUp,
+			expectedMessage: "1/2 children up",
+		},
+		{
+			name:   "all requires every child",
+			config: `{"children":[{"monitor_id":"a"},{"monitor_id":"b"}],"rule":"all"}`,
+			heartbeats: childBeats(map[string]shared.MonitorStatus{
+				"a": shared.MonitorStatusUp,
+				"b": shared.MonitorStatusPending,
+			}),
+			expectedStatus:  shared.MonitorStatusDown,
+			expectedMessage: "1/2 children up",
+		},
+		{
+			name:   "maintenance counts as up, missing heartbeat as down",
+			config: `{"children":[{"monitor_id":"a"},{"monitor_id":"b"}],"rule":"quorum","quorum_percent":50}`,
+			heartbeats: childBeats(map[string]shared.MonitorStatus{
+				"a": shared.MonitorStatusMaintenance,
+			}),
+			expectedStatus:  shared.MonitorStatusUp,
+			expectedMessage: "1/2 children up (50.0% weighted)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			monitor := &Monitor{
+				ID:              "group-1",
+				Type:            "group",
+				Name:            "Database cluster",
+				Config:          tt.config,
+				ChildHeartbeats: tt.heartbeats,
+			}
+
+			result := executor.Execute(context.Background(), monitor, nil)
+			assert.Equal(t, tt.expectedStatus, result.Status)
+			assert.Contains(t, result.Message, tt.expectedMessage)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"peekaping/internal/modules/shared"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// WithHardTimeout wraps exec so a call is abandoned once it ran for the monitor timeout
+// plus grace. Executors are expected to honor the context deadline, this guards against
+// the ones that don't (system binaries, database drivers) holding a worker slot forever.
+// The abandoned call keeps running in the background until it returns on its own.
+func WithHardTimeout(executorType string, exec Executor, grace time.Duration, logger *zap.SugaredLogger) Executor {
+	return &guardedExecutor{Executor: exec, executorType: executorType, grace: grace, logger: logger}
+}
+
+type guardedExecutor struct {
+	Executor
+	executorType string
+	grace        time.Duration
+	logger       *zap.SugaredLogger
+}
+
+func (e *guardedExecutor) Execute(ctx context.Context, m *Monitor, proxyModel *Proxy) *Result {
+	start := time.Now().UTC()
+	limit := time.Duration(m.Timeout)*time.Second + e.grace
+
+	// Buffered so the abandoned goroutine can still deliver its result and exit
+	done := make(chan *Result, 1)
+	go func() {
+		done <- e.Executor.Execute(ctx, m, proxyModel)
+	}()
+
+	timer := time.NewTimer(limit)
+	defer timer.Stop()
+
+	select {
+	case result := <-done:
+		return result
+	case <-timer.C:
+		e.logger.Errorw("Executor exceeded hard timeout, abandoning it",
+			"executor_type", e.executorType,
+			"monitor_id", m.ID,
+			"monitor_name", m.Name,
+			"hard_timeout", limit,
+		)
+		return &Result{
+			Status:    shared.MonitorStatusDown,
+			Message:   fmt.Sprintf("check exceeded hard timeout of %s", limit),
+			StartTime: start,
+			EndTime:   time.Now().UTC(),
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"peekaping/internal/modules/shared"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// hangingExecutor ignores the context and blocks until released, like a stuck system
+// binary or database driver
+type hangingExecutor struct {
+	release chan struct{}
+}
+
+func (h *hangingExecutor) Unmarshal(configJSON string) (any, error) { return nil, nil }
+func (h *hangingExecutor) Validate(configJSON string) error         { return nil }
+
+func (h *hangingExecutor) Execute(ctx context.Context, m *Monitor, proxyModel *Proxy) *Result {
+	<-h.release
+	return &Result{Status: shared.MonitorStatusUp, Message: "finally done"}
+}
+
+func TestWithHardTimeout(t *testing.T) {
+	t.Run("hanging executor is abandoned after timeout plus grace", func(t *testing.T) {
+		hanging := &hangingExecutor{release: make(chan struct{})}
+		defer close(hanging.release)
+
+		guarded := WithHardTimeout("hanging", hanging, 50*time.Millisecond, zap.NewNop().Sugar())
+
+		start := time.Now()
+		result := guarded.Execute(context.Background(), &Monitor{ID: "mon-1", Name: "stuck", Timeout: 0}, nil)
+
+		require.NotNil(t, result)
+		assert.Less(t, time.Since(start), time.Second, "the worker slot is freed")
+		assert.Equal(t, shared.MonitorStatusDown, result.Status)
+		assert.Contains(t, result.Message, "check exceeded hard timeout")
+	})
+
+	t.Run("result of a timely executor is passed through", func(t *testing.T) {
+		hanging := &hangingExecutor{release: make(chan struct{})}
+		close(hanging.release)
+
+		guarded := WithHardTimeout("hanging", hanging, time.Second, zap.NewNop().Sugar())
+		result := guarded.Execute(context.Background(), &Monitor{ID: "mon-1", Name: "fine", Timeout: 1}, nil)
+
+		require.NotNil(t, result)
+		assert.Equal(t, shared.MonitorStatusUp, result.Status)
+		assert.Equal(t, "finally done", result.Message)
+	})
+}
@@ -15,6 +15,7 @@ import (
 	"peekaping/internal/modules/shared"
 	"peekaping/internal/utils"
 	"peekaping/internal/version"
+	"regexp"
 	"strconv"
 	"strings"
 	"sync"
@@ -58,6 +59,42 @@ func HTTPConfigStructLevelValidation(sl validator.StructLevel) {
 		// No validation needed
 	}
 
+	if _, err := parseHeaders(cfg.Headers); err != nil {
+		sl.ReportError(cfg.Headers, "Headers", "headers", "headers", "")
+	}
+
+	for _, pattern := range cfg.BodyChangeIgnore {
+		if _, err := regexp.Compile(pattern); err != nil {
+			sl.ReportError(cfg.BodyChangeIgnore, "BodyChangeIgnore", "body_change_ignore", "regexp", "")
+			break
+		}
+	}
+
+	// Array length bounds need a path and must not exclude every length
+	hasArrayBounds := cfg.MinArrayLength != nil || cfg.MaxArrayLength != nil
+	if hasArrayBounds && cfg.JsonPath == "" {
+		sl.ReportError(cfg.JsonPath, "JsonPath", "json_path", "required_with_array_length", "")
+	}
+	if cfg.JsonPath != "" && !hasArrayBounds {
+		sl.ReportError(cfg.MinArrayLength, "MinArrayLength", "min_array_length", "required_with_json_path", "")
+	}
+	if cfg.MinArrayLength != nil && cfg.MaxArrayLength != nil && *cfg.MinArrayLength > *cfg.MaxArrayLength {
+		sl.ReportError(cfg.MaxArrayLength, "MaxArrayLength", "max_array_length", "gtefield=MinArrayLength", "")
+	}
+
+	if cfg.MinResponseSize != nil && cfg.MaxResponseSize != nil && *cfg.MinResponseSize > *cfg.MaxResponseSize {
+		sl.ReportError(cfg.MaxResponseSize, "MaxResponseSize", "max_response_size", "gtefield=MinResponseSize", "")
+	}
+
+	// SAN assertions need a TLS connection
+	if len(cfg.ExpectedSAN) > 0 && !strings.HasPrefix(cfg.Url, "https://") {
+		sl.ReportError(cfg.ExpectedSAN, "ExpectedSAN", "expected_san", "required_https_url", "")
+	}
+
+	if _, err := parseStatusCodes(cfg.AcceptedStatusCodes); err != nil {
+		sl.ReportError(cfg.AcceptedStatusCodes, "AcceptedStatusCodes", "accepted_statuscodes", "status_codes", "")
+	}
+
 	// Authentication validation
 	switch cfg.AuthMethod {
 	case "none":
@@ -82,6 +119,14 @@ func HTTPConfigStructLevelValidation(sl validator.StructLevel) {
 		if cfg.AuthWorkstation == "" {
 			sl.ReportError(cfg.AuthWorkstation, "AuthWorkstation", "authWorkstation", "required_with_auth_ntlm", "")
 		}
+	case "negotiate":
+		// The domain may be part of a user principal name instead
+		if cfg.BasicAuthUser == "" {
+			sl.ReportError(cfg.BasicAuthUser, "BasicAuthUser", "basic_auth_user", "required_with_auth_negotiate", "")
+		}
+		if cfg.BasicAuthPass == "" {
+			sl.ReportError(cfg.BasicAuthPass, "BasicAuthPass", "basic_auth_pass", "required_with_auth_negotiate", "")
+		}
 	case "oauth2-cc":
 		if cfg.OauthAuthMethod != "client_secret_basic" && cfg.OauthAuthMethod != "client_secret_post" {
 			sl.ReportError(cfg.OauthAuthMethod, "OauthAuthMethod", "oauth_auth_method", "oneof=client_secret_basic client_secret_post", "")
@@ -108,23 +153,50 @@ func HTTPConfigStructLevelValidation(sl validator.StructLevel) {
 		if cfg.TlsKey == "" {
 			sl.ReportError(cfg.TlsKey, "TlsKey", "tlsKey", "required_with_auth_mtls", "")
 		}
-		if cfg.TlsCa == "" {
-			sl.ReportError(cfg.TlsCa, "TlsCa", "tlsCa", "required_with_auth_mtls", "")
-		}
+		// TlsCa is optional, the server certificate is verified against the system roots without it
 	}
 }
 
 type HTTPConfig struct {
 	Url string `json:"url" validate:"required,url"`
 
-	Method              string   `json:"method" validate:"required,oneof=GET POST PUT DELETE PATCH HEAD OPTIONS"`
-	Headers             string   `json:"headers" validate:"omitempty,json"`
-	Encoding            string   `json:"encoding" validate:"required,oneof=json form xml text"`
-	Body                string   `json:"body" validate:"omitempty"`
-	AcceptedStatusCodes []string `json:"accepted_statuscodes" validate:"required,dive,oneof=2XX 3XX 4XX 5XX"`
+	Method   string      `json:"method" validate:"required,oneof=GET POST PUT DELETE PATCH HEAD OPTIONS"`
+	Headers  HTTPHeaders `json:"headers" validate:"omitempty,json"`
+	Encoding string      `json:"encoding" validate:"required,oneof=json form xml text"`
+	Body     string      `json:"body" validate:"omitempty"`
+	// AcceptedStatusCodes lists the status codes, ranges and classes the monitor is up for,
+	// e.g. ["200-299", "401", "3XX"]. 200-299 when none are set.
+	AcceptedStatusCodes []string `json:"accepted_statuscodes"`
 	MaxRedirects        int      `json:"max_redirects" validate:"omitempty,min=0"`
 	IgnoreTlsErrors     bool     `json:"ignore_tls_errors"`
 	CheckCertExpiry     bool     `json:"check_cert_expiry"`
+	// CheckSessionResumption reports whether the server resumes TLS sessions
+	CheckSessionResumption bool `json:"check_session_resumption,omitempty"`
+	// FailOnInsecureRenegotiation fails the check when the server doesn't support secure
+	// renegotiation (RFC 5746)
+	FailOnInsecureRenegotiation bool `json:"fail_on_insecure_renegotiation,omitempty"`
+	// ExpectedSAN lists subject alternative names that must all be present in the server certificate
+	ExpectedSAN []string `json:"expected_san,omitempty" validate:"omitempty,dive,required"`
+	// ReResolve resolves the host on every check and sends the request to the fresh address
+	ReResolve bool `json:"re_resolve,omitempty"`
+	// CheckAllIPs sends the request to every resolved address and reports the monitor
+	// as degraded when only some of them fail
+	CheckAllIPs bool `json:"check_all_ips,omitempty"`
+	// DetectBodyChange hashes the response body so a change between checks can be flagged
+	DetectBodyChange bool `json:"detect_body_change,omitempty"`
+	// SourceIP is the local address the request is sent from, on hosts with several addresses
+	SourceIP string `json:"source_ip,omitempty" validate:"omitempty,ip" example:"192.168.1.10"`
+	// Resolver is the "host:port" of the DNS server the URL host is resolved with instead
+	// of the system resolver. Requests through a proxy are resolved by the proxy.
+	Resolver string `json:"resolver,omitempty" example:"10.0.0.53:53"`
+	// DNSCacheBypass resolves the URL host on every request instead of through the shared DNS cache
+	DNSCacheBypass bool `json:"dns_cache_bypass,omitempty"`
+	// BodyChangeIgnore lists regular expressions stripped from the body before hashing,
+	// for dynamic regions such as timestamps or CSRF tokens
+	BodyChangeIgnore []string `json:"body_change_ignore,omitempty" validate:"omitempty,dive,required"`
+	// AcceptEncoding is sent as the Accept-Encoding header, e.g. "gzip, br". Compressed
+	// responses are decoded before they are validated.
+	AcceptEncoding string `json:"accept_encoding,omitempty" example:"gzip, deflate, br"`
 
 	// Response validation fields
 	Keyword       string `json:"keyword,omitempty"`
@@ -132,9 +204,20 @@ type HTTPConfig struct {
 	JsonQuery     string `json:"json_query,omitempty"`
 	JsonCondition string `json:"json_condition,omitempty" validate:"omitempty,oneof='==' '!=' '>' '<' '>=' '<='"`
 	ExpectedValue string `json:"expected_value,omitempty"`
+	// JsonPath points at an array in the JSON response (gjson syntax, "@this" for the
+	// root) whose length must stay within MinArrayLength and MaxArrayLength
+	JsonPath       string `json:"json_path,omitempty"`
+	MinArrayLength *int   `json:"min_array_length,omitempty" validate:"omitempty,min=0"`
+	MaxArrayLength *int   `json:"max_array_length,omitempty" validate:"omitempty,min=0"`
+	// MinResponseSize and MaxResponseSize bound the size in bytes of the decoded response
+	// body, to catch truncated or bloated payloads
+	MinResponseSize *int64 `json:"min_response_size,omitempty" validate:"omitempty,min=0"`
+	MaxResponseSize *int64 `json:"max_response_size,omitempty" validate:"omitempty,min=0"`
+	// JsonAssertions must all hold for the check to be up
+	JsonAssertions []JSONAssertion `json:"json_assertions,omitempty" validate:"omitempty,max=50,dive"`
 
 	// Authentication fields
-	AuthMethod        string `json:"authMethod" validate:"required,oneof=none basic oauth2-cc ntlm mtls"`
+	AuthMethod        string `json:"authMethod" validate:"required,oneof=none basic oauth2-cc ntlm negotiate mtls"`
 	BasicAuthUser     string `json:"basic_auth_user,omitempty"`
 	BasicAuthPass     string `json:"basic_auth_pass,omitempty"`
 	AuthDomain        string `json:"authDomain,omitempty"`
@@ -144,14 +227,35 @@ type HTTPConfig struct {
 	OauthClientId     string `json:"oauth_client_id,omitempty"`
 	OauthClientSecret string `json:"oauth_client_secret,omitempty"`
 	OauthScopes       string `json:"oauth_scopes,omitempty"`
-	TlsCert           string `json:"tlsCert,omitempty"`
-	TlsKey            string `json:"tlsKey,omitempty"`
-	TlsCa             string `json:"tlsCa,omitempty"`
+	// TlsCert and TlsKey are the PEM encoded client certificate and key of mTLS, TlsCa
+	// the optional PEM encoded CA the server certificate is verified against
+	TlsCert string `json:"tlsCert,omitempty"`
+	TlsKey  string `json:"tlsKey,omitempty"`
+	TlsCa   string `json:"tlsCa,omitempty"`
+}
+
+// redacted is a copy of the config that is safe to log, without credentials or the
+// values of sensitive headers
+func (c HTTPConfig) redacted() HTTPConfig {
+	if headers, err := parseHeaders(c.Headers); err != nil {
+		c.Headers = HTTPHeaders(redactSecret(string(c.Headers)))
+	} else if headers != nil {
+		data, _ := json.Marshal(redactHeaders(headers))
+		c.Headers = HTTPHeaders(data)
+	}
+	c.BasicAuthPass = redactSecret(c.BasicAuthPass)
+	c.OauthClientSecret = redactSecret(c.OauthClientSecret)
+	c.TlsKey = redactSecret(c.TlsKey)
+	return c
 }
 
 type HTTPExecutor struct {
-	client *http.Client
-	logger *zap.SugaredLogger
+	logger   *zap.SugaredLogger
+	resolver HostResolver
+	// dnsCache is shared between executors, nil when caching is disabled
+	dnsCache *DNSCache
+	// proxyPool reuses connections of checks through a proxy, nil when pooling is disabled
+	proxyPool *ProxyTransportPool
 }
 
 // TLSInterceptor is a custom RoundTripper that captures TLS certificate information
@@ -192,7 +296,9 @@ func (t *TLSInterceptor) extractTLSInfo(tlsState *tls.ConnectionState) *certific
 	// Check if the certificate chain is verified
 	verified := len(tlsState.VerifiedChains) > 0
 
-	return certificate.ParseCertificateChain(serverCert, verified)
+	tlsInfo := certificate.ParseCertificateChain(serverCert, verified)
+	tlsInfo.ALPN = tlsState.NegotiatedProtocol
+	return tlsInfo
 }
 
 func (t *TLSInterceptor) GetTLSInfo() *certificate.TLSInfo {
@@ -211,8 +317,8 @@ func NewHTTPExecutor(logger *zap.SugaredLogger) *HTTPExecutor {
 	utils.Validate.RegisterStructValidation(HTTPConfigStructLevelValidation, HTTPConfig{})
 
 	return &HTTPExecutor{
-		client: &http.Client{},
-		logger: logger,
+		logger:   logger,
+		resolver: net.DefaultResolver,
 	}
 }
 
@@ -225,7 +331,31 @@ func (s *HTTPExecutor) Validate(configJSON string) error {
 	if err != nil {
 		return err
 	}
-	return GenericValidator(cfg.(*HTTPConfig))
+	httpCfg := cfg.(*HTTPConfig)
+	if err := GenericValidator(httpCfg); err != nil {
+		return err
+	}
+	if err := validateAcceptEncoding(httpCfg.AcceptEncoding); err != nil {
+		return err
+	}
+	return validateResolver(httpCfg.Resolver)
+}
+
+// checkExpectedSAN returns a failure message when the server certificate is missing
+// any of the expected subject alternative names, or an empty string when all are present
+func checkExpectedSAN(cfg *HTTPConfig, tlsInfo *certificate.TLSInfo) string {
+	if !strings.HasPrefix(cfg.Url, "https://") {
+		return "Expected SAN check requires an https:// URL"
+	}
+	if tlsInfo == nil || tlsInfo.CertInfo == nil {
+		return "Expected SAN check failed: no server certificate received"
+	}
+
+	missing := certificate.MissingSANs(tlsInfo.CertInfo, cfg.ExpectedSAN)
+	if len(missing) > 0 {
+		return fmt.Sprintf("Certificate is missing expected SAN(s): %s", strings.Join(missing, ", "))
+	}
+	return ""
 }
 
 // Helper to check if status code matches accepted patterns
@@ -266,6 +396,82 @@ func checkKeyword(responseBody, keyword string, invert bool) bool {
 	return found
 }
 
+// checkArrayLength resolves path in the JSON response and returns the length of the array
+// it points at, with whether the length is within the bounds. A nil bound is not checked.
+func checkArrayLength(responseBody, path string, minLength, maxLength *int) (int, bool, error) {
+	if !gjson.Valid(responseBody) {
+		return 0, false, fmt.Errorf("response is not valid JSON")
+	}
+
+	result := gjson.Get(responseBody, path)
+	if !result.Exists() {
+		return 0, false, fmt.Errorf("path '%s' not found", path)
+	}
+	if !result.IsArray() {
+		return 0, false, fmt.Errorf("path '%s' is not an array but %s", path, jsonKind(result))
+	}
+
+	length := len(result.Array())
+	if minLength != nil && length < *minLength {
+		return length, false, nil
+	}
+	if maxLength != nil && length > *maxLength {
+		return length, false, nil
+	}
+	return length, true, nil
+}
+
+// jsonKind names the JSON type of a result for messages
+func jsonKind(result gjson.Result) string {
+	switch result.Type {
+	case gjson.Null:
+		return "null"
+	case gjson.True, gjson.False:
+		return "a boolean"
+	case gjson.Number:
+		return "a number"
+	case gjson.String:
+		return "a string"
+	default:
+		return "an object"
+	}
+}
+
+// describeArrayBounds renders the expected array length for messages
+func describeArrayBounds(minLength, maxLength *int) string {
+	switch {
+	case minLength != nil && maxLength != nil:
+		return fmt.Sprintf("between %d and %d", *minLength, *maxLength)
+	case minLength != nil:
+		return fmt.Sprintf("at least %d", *minLength)
+	default:
+		return fmt.Sprintf("at most %d", *maxLength)
+	}
+}
+
+// checkResponseSize reports whether the body size is within the optional bounds
+func checkResponseSize(size int64, minSize, maxSize *int64) bool {
+	if minSize != nil && size < *minSize {
+		return false
+	}
+	if maxSize != nil && size > *maxSize {
+		return false
+	}
+	return true
+}
+
+// describeSizeBounds renders the expected response size for messages
+func describeSizeBounds(minSize, maxSize *int64) string {
+	switch {
+	case minSize != nil && maxSize != nil:
+		return fmt.Sprintf("between %d and %d", *minSize, *maxSize)
+	case minSize != nil:
+		return fmt.Sprintf("at least %d", *minSize)
+	default:
+		return fmt.Sprintf("at most %d", *maxSize)
+	}
+}
+
 // Helper to check JSON query and expected value
 func checkJsonQuery(responseBody, jsonQuery, condition, expectedValue string) (bool, error) {
 	if jsonQuery == "" && expectedValue == "" && condition == "" {
@@ -448,8 +654,65 @@ func (h *HTTPExecutor) Execute(ctx context.Context, m *Monitor, proxyModel *Prox
 	}
 	cfg := cfgAny.(*HTTPConfig)
 
-	h.logger.Debugf("execute http cfg: %+v", cfg)
+	h.logger.Debugf("execute http cfg: %+v", cfg.redacted())
+
+	// Requests through a proxy are resolved by the proxy, so backends can't be pinned
+	if (!cfg.ReResolve && !cfg.CheckAllIPs) || proxyModel != nil {
+		return h.executeRequest(ctx, m, cfg, proxyModel, nil)
+	}
+
+	startTime := time.Now().UTC()
+	u, err := url.Parse(cfg.Url)
+	if err != nil {
+		return DownResult(err, startTime, time.Now().UTC())
+	}
+	resolver := h.resolver
+	if cfg.Resolver != "" {
+		resolver = newCustomResolver(cfg.Resolver, time.Duration(m.Timeout)*time.Second)
+	}
+	ips, err := resolveHost(ctx, resolver, u.Hostname())
+	if err != nil {
+		result := DownResult(err, startTime, time.Now().UTC())
+		result.ErrorCategory = shared.ErrorCategoryNetwork
+		return result
+	}
 
+	if !cfg.CheckAllIPs {
+		return h.executeRequest(ctx, m, cfg, nil, ips[0])
+	}
+
+	var tlsInfo *certificate.TLSInfo
+	var bodyHash string
+	results := checkBackends(ips, func(ip net.IP) *Result {
+		return h.executeRequest(ctx, m, cfg, nil, ip)
+	})
+	backends := make([]BackendResult, 0, len(ips))
+	for i, ip := range ips {
+		result := results[i]
+		if tlsInfo == nil {
+			tlsInfo = result.TLSInfo
+		}
+		if bodyHash == "" {
+			bodyHash = result.BodyHash
+		}
+		backends = append(backends, BackendResult{
+			IP:            ip.String(),
+			Up:            result.Status == shared.MonitorStatusUp,
+			Message:       result.Message,
+			ErrorCategory: result.ErrorCategory,
+		})
+	}
+
+	result := summarizeBackends(backends, startTime, time.Now().UTC())
+	result.TLSInfo = tlsInfo
+	result.BodyHash = bodyHash
+	return result
+}
+
+// executeRequest runs a single request against the monitor URL. When pinnedIP is set the
+// connection to the monitor host goes to that address instead of resolving the host.
+func (h *HTTPExecutor) executeRequest(ctx context.Context, m *Monitor, cfg *HTTPConfig, proxyModel *Proxy, pinnedIP net.IP) (result *Result) {
+	// The body is sent byte for byte as configured, it is never treated as a template
 	var bodyReader io.Reader
 	if cfg.Body != "" {
 		bodyReader = bytes.NewReader([]byte(cfg.Body))
@@ -461,15 +724,23 @@ func (h *HTTPExecutor) Execute(ctx context.Context, m *Monitor, proxyModel *Prox
 	}
 	setDefaultHeaders(req)
 
-	if cfg.Headers != "" {
-		headersMap := make(map[string]string)
-		err := json.Unmarshal([]byte(cfg.Headers), &headersMap)
-		if err != nil {
-			return DownResult(fmt.Errorf("invalid headers json: %w", err), time.Now().UTC(), time.Now().UTC())
-		}
-		for k, v := range headersMap {
-			req.Header.Set(k, v)
-		}
+	dialer, err := sourceDialer(0, cfg.SourceIP)
+	if err != nil {
+		return DownResult(err, time.Now().UTC(), time.Now().UTC())
+	}
+	if cfg.Resolver != "" {
+		dialer.Resolver = newCustomResolver(cfg.Resolver, time.Duration(m.Timeout)*time.Second)
+	}
+
+	headers, err := parseHeaders(cfg.Headers)
+	if err != nil {
+		return DownResult(err, time.Now().UTC(), time.Now().UTC())
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if cfg.AcceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", cfg.AcceptEncoding)
 	}
 
 	// Determine effective max redirects value
@@ -486,21 +757,31 @@ func (h *HTTPExecutor) Execute(ctx context.Context, m *Monitor, proxyModel *Prox
 		return nil
 	}
 
-	switch cfg.Encoding {
-	case "json":
-		req.Header.Set("Content-Type", "application/json")
-	case "form":
-		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	case "xml":
-		req.Header.Set("Content-Type", "application/xml")
-	case "text":
-		req.Header.Set("Content-Type", "text/plain")
+	// A Content-Type of the headers wins over the one of the encoding
+	if req.Header.Get("Content-Type") == "" {
+		switch cfg.Encoding {
+		case "json":
+			req.Header.Set("Content-Type", "application/json")
+		case "form":
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		case "xml":
+			req.Header.Set("Content-Type", "application/xml")
+		case "text":
+			req.Header.Set("Content-Type", "text/plain")
+		}
 	}
 
 	// --- PROXY LOGIC ---
 
 	// Default transport with proxy if needed
 	baseTransport := &http.Transport{}
+	if pinnedIP != nil {
+		baseTransport.DialContext = pinnedDialContext(dialer, req.URL.Hostname(), pinnedIP)
+	} else if h.dnsCache != nil && proxyModel == nil && cfg.Resolver == "" && !cfg.DNSCacheBypass {
+		baseTransport.DialContext = h.dnsCache.DialContext(dialer)
+	} else if cfg.SourceIP != "" || cfg.Resolver != "" {
+		baseTransport.DialContext = dialer.DialContext
+	}
 
 	// Configure TLS settings if needed
 	if cfg.IgnoreTlsErrors {
@@ -510,7 +791,15 @@ func (h *HTTPExecutor) Execute(ctx context.Context, m *Monitor, proxyModel *Prox
 		baseTransport.TLSClientConfig.InsecureSkipVerify = true
 	}
 
-	transport := buildProxyTransport(baseTransport, proxyModel)
+	var transport http.RoundTripper
+	if h.proxyPool != nil && proxyModel != nil {
+		transport, err = h.proxyPool.Transport(proxyModel, cfg.IgnoreTlsErrors, cfg.SourceIP)
+		if err != nil {
+			return DownResult(err, time.Now().UTC(), time.Now().UTC())
+		}
+	} else {
+		transport = buildProxyTransport(baseTransport, proxyModel)
+	}
 
 	// Create TLS interceptor to capture certificate information
 	tlsInterceptor := NewTLSInterceptor(transport)
@@ -521,26 +810,27 @@ func (h *HTTPExecutor) Execute(ctx context.Context, m *Monitor, proxyModel *Prox
 	// Set timeout from monitor configuration
 	timeout := time.Duration(m.Timeout) * time.Second
 
+	// The client is built per request: backends of a check run concurrently, each with
+	// its own pinned transport
+	var client *http.Client
+
 	// --- AUTHENTICATION LOGIC ---
 	switch cfg.AuthMethod {
 	case "basic":
 		req.SetBasicAuth(cfg.BasicAuthUser, cfg.BasicAuthPass)
-	case "ntlm":
-		// NTLM authentication using github.com/Azure/go-ntlmssp
+	case "ntlm", "negotiate":
+		// NTLM authentication using github.com/Azure/go-ntlmssp, which answers NTLM and
+		// Negotiate challenges with the credentials of the basic auth header
 		ntlmTransport := ntlmssp.Negotiator{
 			RoundTripper: tlsInterceptor,
 		}
-		h.client = &http.Client{
+		client = &http.Client{
 			Transport:     &ntlmTransport,
 			Timeout:       time.Duration(m.Timeout) * time.Second,
 			CheckRedirect: checkRedirect,
 		}
 
-		if cfg.AuthDomain != "" {
-			req.SetBasicAuth(cfg.AuthDomain+"\\"+cfg.BasicAuthUser, cfg.BasicAuthPass)
-		} else {
-			req.SetBasicAuth(cfg.BasicAuthUser, cfg.BasicAuthPass)
-		}
+		req.SetBasicAuth(ntlmUser(cfg), cfg.BasicAuthPass)
 	case "oauth2-cc":
 		form := url.Values{}
 		form.Set("grant_type", "client_credentials")
@@ -583,29 +873,38 @@ func (h *HTTPExecutor) Execute(ctx context.Context, m *Monitor, proxyModel *Prox
 		if err != nil {
 			return DownResult(fmt.Errorf("invalid mTLS cert/key: %w", err), time.Now().UTC(), time.Now().UTC())
 		}
-		caCertPool := x509.NewCertPool()
-		if ok := caCertPool.AppendCertsFromPEM([]byte(cfg.TlsCa)); !ok {
-			return DownResult(fmt.Errorf("invalid mTLS CA cert"), time.Now().UTC(), time.Now().UTC())
-		}
 		mtlsTransport := &http.Transport{
 			TLSClientConfig: &tls.Config{
 				Certificates:       []tls.Certificate{cert},
-				RootCAs:            caCertPool,
 				InsecureSkipVerify: cfg.IgnoreTlsErrors,
 			},
 		}
+		if cfg.TlsCa != "" {
+			caCertPool := x509.NewCertPool()
+			if ok := caCertPool.AppendCertsFromPEM([]byte(cfg.TlsCa)); !ok {
+				return DownResult(fmt.Errorf("invalid mTLS CA cert"), time.Now().UTC(), time.Now().UTC())
+			}
+			mtlsTransport.TLSClientConfig.RootCAs = caCertPool
+		}
+		if pinnedIP != nil {
+			mtlsTransport.DialContext = pinnedDialContext(dialer, req.URL.Hostname(), pinnedIP)
+		} else if h.dnsCache != nil && proxyModel == nil && cfg.Resolver == "" && !cfg.DNSCacheBypass {
+			mtlsTransport.DialContext = h.dnsCache.DialContext(dialer)
+		} else if cfg.SourceIP != "" || cfg.Resolver != "" {
+			mtlsTransport.DialContext = dialer.DialContext
+		}
 		mtlsTransportWithProxy := buildProxyTransport(mtlsTransport, proxyModel)
 		mtlsTLSInterceptor := NewTLSInterceptor(mtlsTransportWithProxy)
 		activeTLSInterceptor = mtlsTLSInterceptor // Update the active interceptor for mTLS
-		h.client = &http.Client{
+		client = &http.Client{
 			Transport:     mtlsTLSInterceptor,
 			Timeout:       time.Duration(m.Timeout) * time.Second,
 			CheckRedirect: checkRedirect,
 		}
 	}
 
-	if cfg.AuthMethod != "mtls" && cfg.AuthMethod != "ntlm" {
-		h.client = &http.Client{
+	if cfg.AuthMethod != "mtls" && !usesNTLM(cfg.AuthMethod) {
+		client = &http.Client{
 			Timeout:       timeout,
 			CheckRedirect: checkRedirect,
 			Transport:     tlsInterceptor,
@@ -615,12 +914,17 @@ func (h *HTTPExecutor) Execute(ctx context.Context, m *Monitor, proxyModel *Prox
 	// Set user agent and accept headers
 
 	startTime := time.Now().UTC()
-	resp, err := h.client.Do(req)
+	resp, err := client.Do(req)
 	endTime := time.Now().UTC()
 
 	if err != nil {
 		h.logger.Infof("HTTP request failed: %s, %s", m.Name, err.Error())
 		result := DownResult(err, startTime, endTime)
+		if cfg.AuthMethod == "mtls" {
+			if message := describeClientCertRejection(err); message != "" {
+				result.Message = message
+			}
+		}
 		// Try to get TLS info even on error for HTTPS requests
 		if strings.HasPrefix(cfg.Url, "https://") && activeTLSInterceptor != nil {
 			result.TLSInfo = activeTLSInterceptor.GetTLSInfo()
@@ -637,18 +941,67 @@ func (h *HTTPExecutor) Execute(ctx context.Context, m *Monitor, proxyModel *Prox
 		tlsInfo = activeTLSInterceptor.GetTLSInfo()
 	}
 
-	if !isStatusAccepted(resp.StatusCode, cfg.AcceptedStatusCodes) {
+	if len(cfg.ExpectedSAN) > 0 {
+		if message := checkExpectedSAN(cfg, tlsInfo); message != "" {
+			return &Result{
+				Status:    shared.MonitorStatusDown,
+				Message:   message,
+				StartTime: startTime,
+				EndTime:   endTime,
+				TLSInfo:   tlsInfo,
+			}
+		}
+	}
+
+	// Requests through a proxy never reach the server's TLS endpoint directly
+	var tlsNote string
+	if (cfg.CheckSessionResumption || cfg.FailOnInsecureRenegotiation) && proxyModel == nil && tlsInfo != nil {
+		dial := baseTransport.DialContext
+		if dial == nil {
+			dial = dialer.DialContext
+		}
+		probeCtx, cancel := context.WithTimeout(ctx, timeout)
+		posture, err := probeTLSPosture(probeCtx, dial, tlsProbeAddr(req.URL), req.URL.Hostname())
+		cancel()
+		if err != nil {
+			h.logger.Warnf("TLS probe failed: %s, %v", m.Name, err)
+			tlsNote = fmt.Sprintf("TLS probe failed: %v", err)
+		} else {
+			tlsNote = applyTLSPosture(tlsInfo, posture, cfg.CheckSessionResumption)
+			if cfg.FailOnInsecureRenegotiation && !posture.SecureRenegotiation {
+				return &Result{
+					Status:        shared.MonitorStatusDown,
+					Message:       "Server allows insecure TLS renegotiation",
+					ErrorCategory: shared.ErrorCategoryTLS,
+					StartTime:     startTime,
+					EndTime:       endTime,
+					TLSInfo:       tlsInfo,
+				}
+			}
+		}
+	}
+
+	acceptedStatusCodes, err := cfg.acceptedStatusCodes()
+	if err != nil {
+		return DownResult(fmt.Errorf("invalid accepted status codes: %w", err), startTime, endTime)
+	}
+	if !acceptedStatusCodes.matches(resp.StatusCode) {
+		message := fmt.Sprintf("HTTP request failed with status: %d", resp.StatusCode)
+		if resp.StatusCode == http.StatusUnauthorized && usesNTLM(cfg.AuthMethod) {
+			message = describeNTLMRejection(resp, cfg)
+		}
 		return &Result{
 			Status:    shared.MonitorStatusDown,
-			Message:   fmt.Sprintf("HTTP request failed with status: %d", resp.StatusCode),
+			Message:   message,
 			StartTime: startTime,
 			EndTime:   endTime,
 			TLSInfo:   tlsInfo,
 		}
 	}
 
-	// Read response body for content validation
-	bodyBytes, err := io.ReadAll(resp.Body)
+	// Read and decode response body for content validation, up to maxResponseBodySize.
+	// Bodies in an unknown encoding are kept as they are, only a body check can fail on them.
+	bodyBytes, undecodedEncoding, err := readResponseBody(resp, maxResponseBodySize+1)
 	if err != nil {
 		return &Result{
 			Status:    shared.MonitorStatusDown,
@@ -658,8 +1011,43 @@ func (h *HTTPExecutor) Execute(ctx context.Context, m *Monitor, proxyModel *Prox
 			TLSInfo:   tlsInfo,
 		}
 	}
+	bodyTruncated := len(bodyBytes) > maxResponseBodySize
+	if bodyTruncated {
+		bodyBytes = bodyBytes[:maxResponseBodySize]
+	}
 	var responseBody = string(bodyBytes)
 	h.logger.Debugf("Response body length: %d", len(responseBody))
+	if undecodedEncoding != "" {
+		h.logger.Debugf("Response body of %s not decoded, unsupported Content-Encoding %q", m.Name, undecodedEncoding)
+	}
+
+	// Bodies are read up to maxResponseBodySize, larger ones are recorded with that size
+	responseSize := int64(len(bodyBytes))
+
+	// Keep the body and its size on every result from here on, the body for the
+	// monitor's result expression
+	defer func() {
+		if result.Status == shared.MonitorStatusDown && undecodedEncoding != "" {
+			result.Message += fmt.Sprintf(" (response body not decoded, unsupported Content-Encoding %q)", undecodedEncoding)
+		}
+		result.ResponseSize = &responseSize
+		if len(bodyBytes) > maxExpressionBodySize {
+			result.ResponseBody = string(bodyBytes[:maxExpressionBodySize])
+		} else {
+			result.ResponseBody = responseBody
+		}
+	}()
+
+	if !checkResponseSize(responseSize, cfg.MinResponseSize, cfg.MaxResponseSize) {
+		return &Result{
+			Status: shared.MonitorStatusDown,
+			Message: fmt.Sprintf("Response size check failed: body has %d bytes, expected %s",
+				responseSize, describeSizeBounds(cfg.MinResponseSize, cfg.MaxResponseSize)),
+			StartTime: startTime,
+			EndTime:   endTime,
+			TLSInfo:   tlsInfo,
+		}
+	}
 
 	// Check keyword if specified
 	if cfg.Keyword != "" {
@@ -709,11 +1097,69 @@ func (h *HTTPExecutor) Execute(ctx context.Context, m *Monitor, proxyModel *Prox
 		}
 	}
 
-	return &Result{
+	if len(cfg.JsonAssertions) > 0 {
+		message := checkJSONAssertions(responseBody, cfg.JsonAssertions)
+		if message != "" && bodyTruncated {
+			message = fmt.Sprintf("JSON assertion failed: response body exceeds %d bytes", maxResponseBodySize)
+		}
+		if message != "" {
+			return &Result{
+				Status:    shared.MonitorStatusDown,
+				Message:   message,
+				StartTime: startTime,
+				EndTime:   endTime,
+				TLSInfo:   tlsInfo,
+			}
+		}
+	}
+
+	message := fmt.Sprintf("%d - %s", resp.StatusCode, resp.Status)
+
+	// Check the array length if bounds are set
+	if cfg.JsonPath != "" {
+		length, ok, err := checkArrayLength(responseBody, cfg.JsonPath, cfg.MinArrayLength, cfg.MaxArrayLength)
+		if err != nil {
+			return &Result{
+				Status:    shared.MonitorStatusDown,
+				Message:   fmt.Sprintf("JSON array length check failed: %v", err),
+				StartTime: startTime,
+				EndTime:   endTime,
+				TLSInfo:   tlsInfo,
+			}
+		}
+		if !ok {
+			return &Result{
+				Status: shared.MonitorStatusDown,
+				Message: fmt.Sprintf("JSON array length check failed: '%s' has %d elements, expected %s",
+					cfg.JsonPath, length, describeArrayBounds(cfg.MinArrayLength, cfg.MaxArrayLength)),
+				StartTime: startTime,
+				EndTime:   endTime,
+				TLSInfo:   tlsInfo,
+			}
+		}
+		message = fmt.Sprintf("%s, '%s' has %d elements", message, cfg.JsonPath, length)
+	}
+	if tlsNote != "" {
+		message = fmt.Sprintf("%s, %s", message, tlsNote)
+	}
+
+	result = &Result{
 		Status:    shared.MonitorStatusUp,
-		Message:   fmt.Sprintf("%d - %s", resp.StatusCode, resp.Status),
+		Message:   message,
 		StartTime: startTime,
 		EndTime:   endTime,
 		TLSInfo:   tlsInfo,
 	}
+
+	// The hash is compared with the previous one by the ingester
+	if cfg.DetectBodyChange {
+		bodyHash, err := hashBody(bodyBytes, cfg.BodyChangeIgnore)
+		if err != nil {
+			h.logger.Warnf("Failed to hash response body: %s, %v", m.Name, err)
+		} else {
+			result.BodyHash = bodyHash
+		}
+	}
+
+	return result
 }
@@ -0,0 +1,102 @@
+package executor
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+// supportedContentEncodings are the encodings HTTP monitors can request and decode
+var supportedContentEncodings = map[string]bool{
+	"gzip":     true,
+	"x-gzip":   true,
+	"deflate":  true,
+	"br":       true,
+	"identity": true,
+}
+
+// validateAcceptEncoding checks that accept_encoding only lists decodable encodings,
+// e.g. "gzip, br;q=0.9"
+func validateAcceptEncoding(acceptEncoding string) error {
+	if acceptEncoding == "" {
+		return nil
+	}
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		encoding := strings.ToLower(strings.TrimSpace(strings.SplitN(part, ";", 2)[0]))
+		if !supportedContentEncodings[encoding] {
+			return fmt.Errorf("accept_encoding: unsupported encoding %q, use gzip, deflate, br or identity", encoding)
+		}
+	}
+	return nil
+}
+
+// readResponseBody reads up to limit bytes of the decoded response body. Content-Encoding
+// lists the encodings in the order they were applied, so they are undone from the last.
+// Go's transport already decodes gzip when it added Accept-Encoding itself, it doesn't
+// when the monitor sets the header. Decoding stops at an unknown encoding, which is
+// returned with the body as it is from there.
+func readResponseBody(resp *http.Response, limit int64) ([]byte, string, error) {
+	var body io.Reader = resp.Body
+	var undecoded string
+
+	encodings := strings.Split(resp.Header.Get("Content-Encoding"), ",")
+	for i := len(encodings) - 1; i >= 0; i-- {
+		encoding := strings.ToLower(strings.TrimSpace(encodings[i]))
+		buffered := bufio.NewReader(body)
+		if _, err := buffered.Peek(1); errors.Is(err, io.EOF) {
+			// Empty bodies, e.g. of HEAD requests, carry the header without content
+			return []byte{}, "", nil
+		}
+		if undecoded != "" {
+			body = buffered
+			break
+		}
+
+		switch encoding {
+		case "", "identity":
+			body = buffered
+		case "gzip", "x-gzip":
+			reader, err := gzip.NewReader(buffered)
+			if err != nil {
+				return nil, "", fmt.Errorf("invalid gzip body: %w", err)
+			}
+			defer reader.Close()
+			body = reader
+		case "deflate":
+			reader, err := newDeflateReader(buffered)
+			if err != nil {
+				return nil, "", fmt.Errorf("invalid deflate body: %w", err)
+			}
+			defer reader.Close()
+			body = reader
+		case "br":
+			body = brotli.NewReader(buffered)
+		default:
+			undecoded = encoding
+			body = buffered
+		}
+	}
+
+	// The limit applies to the decoded body so small compressed bodies can't expand
+	// without bound
+	bodyBytes, err := io.ReadAll(io.LimitReader(body, limit))
+	return bodyBytes, undecoded, err
+}
+
+// newDeflateReader reads "deflate" bodies, which should be zlib streams (RFC 9110) but
+// are raw deflate data from some servers
+func newDeflateReader(r *bufio.Reader) (io.ReadCloser, error) {
+	header, err := r.Peek(2)
+	if err == nil && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(r)
+	}
+	return flate.NewReader(r), nil
+}
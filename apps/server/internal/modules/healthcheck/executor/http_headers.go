@@ -0,0 +1,84 @@
+package executor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/http/httpguts"
+)
+
+// HTTPHeaders holds the request headers of an HTTP monitor as a JSON object of strings.
+// The config accepts the object itself or, as the web app sends it, a string holding
+// the object, and always stores the string.
+type HTTPHeaders string
+
+func (h *HTTPHeaders) UnmarshalJSON(data []byte) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		*h = HTTPHeaders(trimmed)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("headers must be an object or a string: %w", err)
+	}
+	*h = HTTPHeaders(s)
+	return nil
+}
+
+// parseHeaders decodes the headers and checks that names and values can be sent
+func parseHeaders(headers HTTPHeaders) (map[string]string, error) {
+	if headers == "" {
+		return nil, nil
+	}
+	var parsed map[string]string
+	if err := json.Unmarshal([]byte(headers), &parsed); err != nil {
+		return nil, fmt.Errorf("headers must be a JSON object of strings: %w", err)
+	}
+	for name, value := range parsed {
+		if !httpguts.ValidHeaderFieldName(name) {
+			return nil, fmt.Errorf("invalid header name %q", name)
+		}
+		if !httpguts.ValidHeaderFieldValue(value) {
+			return nil, fmt.Errorf("invalid value of header %q", name)
+		}
+	}
+	return parsed, nil
+}
+
+// sensitiveHeaderParts are substrings of the header names whose values aren't logged
+var sensitiveHeaderParts = []string{"auth", "cookie", "token", "secret", "key", "password", "session"}
+
+func isSensitiveHeader(name string) bool {
+	name = strings.ToLower(name)
+	for _, part := range sensitiveHeaderParts {
+		if strings.Contains(name, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactHeaders masks the values of sensitive headers for logging
+func redactHeaders(headers map[string]string) map[string]string {
+	if headers == nil {
+		return nil
+	}
+	redacted := make(map[string]string, len(headers))
+	for name, value := range headers {
+		if isSensitiveHeader(name) {
+			value = "[redacted]"
+		}
+		redacted[name] = value
+	}
+	return redacted
+}
+
+// redactSecret masks a credential of a config for logging
+func redactSecret(value string) string {
+	if value == "" {
+		return ""
+	}
+	return "[redacted]"
+}
@@ -0,0 +1,37 @@
+package executor
+
+import (
+	"fmt"
+	"strings"
+)
+
+// clientCertAlerts are the TLS alerts a server sends when it refuses the client certificate
+var clientCertAlerts = []string{
+	"bad certificate",
+	"unsupported certificate",
+	"certificate revoked",
+	"certificate expired",
+	"certificate unknown",
+	"unknown certificate authority",
+	"certificate required",
+	"access denied",
+}
+
+// describeClientCertRejection explains a failed request whose client certificate the
+// server refused, empty for other errors. Alerts are matched on the message because the
+// HTTP transport doesn't keep them wrapped.
+func describeClientCertRejection(err error) string {
+	const prefix = "remote error: tls: "
+	message := err.Error()
+	i := strings.Index(message, prefix)
+	if i < 0 {
+		return ""
+	}
+	alert := message[i+len(prefix):]
+	for _, known := range clientCertAlerts {
+		if strings.HasPrefix(alert, known) {
+			return fmt.Sprintf("Server rejected the client certificate: %s", known)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,44 @@
+package executor
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// usesNTLM reports whether the auth method authenticates with the NTLM handshake. The
+// negotiate method answers Negotiate challenges with NTLM tokens as well, Kerberos
+// tickets are not used.
+func usesNTLM(authMethod string) bool {
+	return authMethod == "ntlm" || authMethod == "negotiate"
+}
+
+// ntlmUser returns the user name sent in the NTLM handshake, qualified with the domain
+// when the config has one
+func ntlmUser(cfg *HTTPConfig) string {
+	if cfg.AuthDomain != "" {
+		return cfg.AuthDomain + "\\" + cfg.BasicAuthUser
+	}
+	return cfg.BasicAuthUser
+}
+
+// describeNTLMRejection explains a 401 answer to a request authenticated with the NTLM
+// handshake, telling a server that doesn't offer NTLM apart from rejected credentials
+func describeNTLMRejection(resp *http.Response, cfg *HTTPConfig) string {
+	method := strings.ToUpper(cfg.AuthMethod)
+	if cfg.AuthMethod == "negotiate" {
+		method = "Negotiate"
+	}
+
+	offered := resp.Header.Values("Www-Authenticate")
+	for _, challenge := range offered {
+		scheme, _, _ := strings.Cut(strings.TrimSpace(challenge), " ")
+		if strings.EqualFold(scheme, "NTLM") || strings.EqualFold(scheme, "Negotiate") {
+			return fmt.Sprintf("%s authentication failed: the server rejected the credentials of %s", method, ntlmUser(cfg))
+		}
+	}
+	if len(offered) == 0 {
+		return fmt.Sprintf("%s authentication failed: the server answered 401 without offering NTLM or Negotiate authentication", method)
+	}
+	return fmt.Sprintf("%s authentication failed: the server doesn't offer NTLM or Negotiate authentication, only %s", method, strings.Join(offered, ", "))
+}
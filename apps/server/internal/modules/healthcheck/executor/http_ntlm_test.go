@@ -0,0 +1,204 @@
+package executor
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"peekaping/internal/modules/shared"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/md4"
+)
+
+// ntlmServer is a mock IIS endpoint that requires the NTLM handshake with the scheme it
+// offers (NTLM or Negotiate), verifying the NTLMv2 response of the client against the
+// password of its domain
+type ntlmServer struct {
+	scheme   string
+	domain   string
+	password string
+	// users are the user names that authenticated
+	users []string
+}
+
+var ntlmServerChallenge = []byte{1, 2, 3, 4, 5, 6, 7, 8}
+
+func (s *ntlmServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	scheme, token, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+	msg, err := base64.StdEncoding.DecodeString(token)
+	if !strings.EqualFold(scheme, s.scheme) || err != nil || len(msg) < 12 {
+		s.unauthorized(w, "")
+		return
+	}
+
+	switch binary.LittleEndian.Uint32(msg[8:12]) {
+	case 1:
+		s.unauthorized(w, base64.StdEncoding.EncodeToString(s.challenge()))
+	case 3:
+		user, ok := s.verify(msg)
+		if !ok {
+			s.unauthorized(w, "")
+			return
+		}
+		s.users = append(s.users, user)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok"))
+	default:
+		s.unauthorized(w, "")
+	}
+}
+
+func (s *ntlmServer) unauthorized(w http.ResponseWriter, data string) {
+	challenge := s.scheme
+	if data != "" {
+		challenge += " " + data
+	}
+	w.Header().Set("WWW-Authenticate", challenge)
+	w.WriteHeader(http.StatusUnauthorized)
+}
+
+// challenge builds a type 2 message with unicode strings, the server challenge and the
+// domain as target name
+func (s *ntlmServer) challenge() []byte {
+	const headerSize = 48
+	target := utf16le(s.domain)
+
+	msg := make([]byte, headerSize, headerSize+len(target))
+	copy(msg, "NTLMSSP\x00")
+	binary.LittleEndian.PutUint32(msg[8:], 2)
+	binary.LittleEndian.PutUint16(msg[12:], uint16(len(target)))
+	binary.LittleEndian.PutUint16(msg[14:], uint16(len(target)))
+	binary.LittleEndian.PutUint32(msg[16:], headerSize)
+	// NTLMSSP_NEGOTIATE_UNICODE | NTLMSSP_NEGOTIATE_NTLM
+	binary.LittleEndian.PutUint32(msg[20:], 0x00000201)
+	copy(msg[24:], ntlmServerChallenge)
+	return append(msg, target...)
+}
+
+// verify checks the NTLMv2 proof of a type 3 message and returns its user name
+func (s *ntlmServer) verify(msg []byte) (string, bool) {
+	field := func(offset int) []byte {
+		if len(msg) < offset+8 {
+			return nil
+		}
+		length := int(binary.LittleEndian.Uint16(msg[offset:]))
+		start := int(binary.LittleEndian.Uint32(msg[offset+4:]))
+		if start+length > len(msg) {
+			return nil
+		}
+		return msg[start : start+length]
+	}
+
+	ntResponse := field(20)
+	user := fromUTF16LE(field(36))
+	if len(ntResponse) <= 16 {
+		return "", false
+	}
+
+	ntHash := md4.New()
+	ntHash.Write(utf16le(s.password))
+	v2Hash := hmac.New(md5.New, ntHash.Sum(nil))
+	v2Hash.Write(utf16le(strings.ToUpper(user) + s.domain))
+	proof := hmac.New(md5.New, v2Hash.Sum(nil))
+	proof.Write(ntlmServerChallenge)
+	proof.Write(ntResponse[16:])
+
+	return user, hmac.Equal(proof.Sum(nil), ntResponse[:16])
+}
+
+func utf16le(s string) []byte {
+	units := utf16.Encode([]rune(s))
+	b := make([]byte, 2*len(units))
+	for i, u := range units {
+		binary.LittleEndian.PutUint16(b[2*i:], u)
+	}
+	return b
+}
+
+func fromUTF16LE(b []byte) string {
+	units := make([]uint16, len(b)/2)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(b[2*i:])
+	}
+	return string(utf16.Decode(units))
+}
+
+func TestHTTPExecutor_Execute_NTLM(t *testing.T) {
+	executor := NewHTTPExecutor(zap.NewNop().Sugar())
+
+	config := func(url, authMethod, password string) string {
+		return `{
+			"url": "` + url + `",
+			"method": "GET",
+			"encoding": "json",
+			"accepted_statuscodes": ["2XX"],
+			"authMethod": "` + authMethod + `",
+			"basic_auth_user": "alice",
+			"basic_auth_pass": "` + password + `",
+			"authDomain": "CORP",
+			"authWorkstation": "MONITOR"
+		}`
+	}
+	execute := func(url, authMethod, password string) *Result {
+		return executor.Execute(context.Background(), &Monitor{
+			ID: "monitor1", Type: "http", Name: "Intranet", Interval: 30, Timeout: 5,
+			Config: config(url, authMethod, password),
+		}, nil)
+	}
+
+	tests := []struct {
+		name       string
+		scheme     string
+		authMethod string
+	}{
+		{"ntlm", "NTLM", "ntlm"},
+		{"negotiate", "Negotiate", "negotiate"},
+		{"ntlm method answering a negotiate challenge", "Negotiate", "ntlm"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &ntlmServer{scheme: tt.scheme, domain: "CORP", password: "s3cret"}
+			server := httptest.NewServer(mock)
+			defer server.Close()
+
+			result := execute(server.URL, tt.authMethod, "s3cret")
+
+			assert.Equal(t, shared.MonitorStatusUp, result.Status, result.Message)
+			assert.Equal(t, []string{"alice"}, mock.users)
+		})
+	}
+
+	t.Run("wrong password", func(t *testing.T) {
+		mock := &ntlmServer{scheme: "NTLM", domain: "CORP", password: "s3cret"}
+		server := httptest.NewServer(mock)
+		defer server.Close()
+
+		result := execute(server.URL, "ntlm", "wrong")
+
+		assert.Equal(t, shared.MonitorStatusDown, result.Status)
+		assert.Equal(t, `NTLM authentication failed: the server rejected the credentials of CORP\alice`, result.Message)
+		assert.Empty(t, mock.users)
+	})
+
+	t.Run("server without ntlm", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer server.Close()
+
+		result := execute(server.URL, "negotiate", "s3cret")
+
+		assert.Equal(t, shared.MonitorStatusDown, result.Status)
+		assert.Equal(t, `Negotiate authentication failed: the server doesn't offer NTLM or Negotiate authentication, only Bearer realm="api"`, result.Message)
+	})
+}
@@ -0,0 +1,78 @@
+package executor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type statusCodeRange struct {
+	from, to int
+}
+
+// statusCodeMatcher is a set of HTTP status codes made of single codes and ranges
+type statusCodeMatcher []statusCodeRange
+
+// defaultStatusCodes are accepted when a config sets no accepted_statuscodes
+var defaultStatusCodes = statusCodeMatcher{{200, 299}}
+
+// parseStatusCodes parses accepted status codes, each one a single code, an inclusive
+// range or a class, e.g. ["200-299", "401", "3XX"]
+func parseStatusCodes(entries []string) (statusCodeMatcher, error) {
+	var matcher statusCodeMatcher
+	for _, entry := range entries {
+		part := strings.TrimSpace(entry)
+		if part == "" {
+			return nil, fmt.Errorf("empty status code")
+		}
+
+		if len(part) == 3 && strings.EqualFold(part[1:], "XX") && part[0] >= '1' && part[0] <= '5' {
+			class := int(part[0]-'0') * 100
+			matcher = append(matcher, statusCodeRange{class, class + 99})
+			continue
+		}
+
+		fromStr, toStr, isRange := strings.Cut(part, "-")
+		from, err := parseStatusCode(fromStr)
+		if err != nil {
+			return nil, err
+		}
+		to := from
+		if isRange {
+			if to, err = parseStatusCode(toStr); err != nil {
+				return nil, err
+			}
+			if to < from {
+				return nil, fmt.Errorf("invalid status code range %q", part)
+			}
+		}
+		matcher = append(matcher, statusCodeRange{from, to})
+	}
+
+	return matcher, nil
+}
+
+func parseStatusCode(s string) (int, error) {
+	code, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || code < 100 || code > 599 {
+		return 0, fmt.Errorf("invalid status code %q", strings.TrimSpace(s))
+	}
+	return code, nil
+}
+
+func (m statusCodeMatcher) matches(statusCode int) bool {
+	for _, r := range m {
+		if statusCode >= r.from && statusCode <= r.to {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptedStatusCodes returns the status codes the monitor is up for
+func (c *HTTPConfig) acceptedStatusCodes() (statusCodeMatcher, error) {
+	if len(c.AcceptedStatusCodes) == 0 {
+		return defaultStatusCodes, nil
+	}
+	return parseStatusCodes(c.AcceptedStatusCodes)
+}
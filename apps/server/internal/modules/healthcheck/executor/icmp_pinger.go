@@ -0,0 +1,235 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+)
+
+// listenICMP opens a raw ICMP socket on the given local address, replaced in tests
+var listenICMP = func(address string) (net.PacketConn, error) {
+	return icmp.ListenPacket("ip4:icmp", address)
+}
+
+// icmpPinger sends echo requests of every ping check over one raw socket per source
+// address instead of a socket per check. A reader goroutine per socket hands each reply
+// to the check waiting for it, matched by echo ID, sequence number and peer.
+type icmpPinger struct {
+	mu      sync.Mutex
+	sockets map[string]*icmpSocket
+}
+
+func newICMPPinger() *icmpPinger {
+	return &icmpPinger{sockets: make(map[string]*icmpSocket)}
+}
+
+// Ping sends one echo request with data to dst from listenAddr and waits for the reply
+// until timeout or ctx is done. It returns the round trip time.
+func (p *icmpPinger) Ping(ctx context.Context, listenAddr string, dst *net.IPAddr, data []byte, timeout time.Duration) (time.Duration, error) {
+	socket, err := p.socket(listenAddr)
+	if err != nil {
+		return 0, err
+	}
+	return socket.ping(ctx, dst, data, timeout)
+}
+
+// socket returns the open socket of listenAddr, opening it on first use or after the
+// previous one failed
+func (p *icmpPinger) socket(listenAddr string) (*icmpSocket, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if socket, ok := p.sockets[listenAddr]; ok {
+		return socket, nil
+	}
+
+	conn, err := listenICMP(listenAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ICMP socket (try running as root): %v", err)
+	}
+
+	socket := &icmpSocket{
+		conn: conn,
+		// Raw sockets see every echo reply of the host, a random ID keeps replies to
+		// other processes (and other sockets of this one) apart
+		id:      rand.Intn(0xffff) + 1,
+		pending: make(map[int]*pendingEcho),
+	}
+	p.sockets[listenAddr] = socket
+
+	go func() {
+		err := socket.readLoop()
+		p.mu.Lock()
+		if p.sockets[listenAddr] == socket {
+			delete(p.sockets, listenAddr)
+		}
+		p.mu.Unlock()
+		socket.fail(err)
+	}()
+
+	return socket, nil
+}
+
+// Close closes every open socket, waiting pings fail
+func (p *icmpPinger) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for listenAddr, socket := range p.sockets {
+		socket.conn.Close()
+		delete(p.sockets, listenAddr)
+	}
+}
+
+type pendingEcho struct {
+	dst   net.IP
+	reply chan time.Time
+}
+
+type icmpSocket struct {
+	conn net.PacketConn
+	id   int
+
+	mu      sync.Mutex
+	seq     int
+	pending map[int]*pendingEcho
+	err     error
+}
+
+func (s *icmpSocket) ping(ctx context.Context, dst *net.IPAddr, data []byte, timeout time.Duration) (time.Duration, error) {
+	seq, echo, err := s.register(dst.IP)
+	if err != nil {
+		return 0, err
+	}
+	defer s.unregister(seq)
+
+	msg := &icmp.Message{
+		Type: ipv4.ICMPTypeEcho,
+		Code: 0,
+		Body: &icmp.Echo{
+			ID:   s.id,
+			Seq:  seq,
+			Data: data,
+		},
+	}
+	msgBytes, err := msg.Marshal(nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal ICMP message: %v", err)
+	}
+
+	start := time.Now()
+	if _, err := s.conn.WriteTo(msgBytes, dst); err != nil {
+		return 0, fmt.Errorf("failed to send ICMP packet: %v", err)
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case received, ok := <-echo.reply:
+		if !ok {
+			return 0, fmt.Errorf("failed to read ICMP reply: %v", s.failure())
+		}
+		return received.Sub(start), nil
+	case <-timer.C:
+		return 0, fmt.Errorf("failed to read ICMP reply: timeout after %v", timeout)
+	case <-ctx.Done():
+		return 0, fmt.Errorf("failed to read ICMP reply: %v", ctx.Err())
+	}
+}
+
+// register reserves the next free sequence number for an echo request to dst
+func (s *icmpSocket) register(dst net.IP) (int, *pendingEcho, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return 0, nil, fmt.Errorf("failed to read ICMP reply: %v", s.err)
+	}
+
+	// Sequence numbers are 16 bits, skip the ones still waiting for a reply
+	for i := 0; i <= 0xffff; i++ {
+		s.seq = (s.seq + 1) & 0xffff
+		if _, inUse := s.pending[s.seq]; !inUse {
+			echo := &pendingEcho{dst: dst, reply: make(chan time.Time, 1)}
+			s.pending[s.seq] = echo
+			return s.seq, echo, nil
+		}
+	}
+	return 0, nil, errors.New("too many ICMP echo requests in flight")
+}
+
+func (s *icmpSocket) unregister(seq int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.pending, seq)
+}
+
+// readLoop delivers echo replies to their pending requests until the socket fails
+func (s *icmpSocket) readLoop() error {
+	buf := make([]byte, 65536)
+	for {
+		n, peer, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			return err
+		}
+		received := time.Now()
+
+		// Protocol 1 for IPv4 ICMP
+		msg, err := icmp.ParseMessage(1, buf[:n])
+		if err != nil || msg.Type != ipv4.ICMPTypeEchoReply {
+			continue
+		}
+		echo, ok := msg.Body.(*icmp.Echo)
+		if !ok || echo.ID != s.id {
+			continue
+		}
+
+		s.mu.Lock()
+		pending, ok := s.pending[echo.Seq]
+		if ok && peerIP(peer).Equal(pending.dst) {
+			delete(s.pending, echo.Seq)
+			pending.reply <- received
+		}
+		s.mu.Unlock()
+	}
+}
+
+// fail records why the socket stopped and wakes up every waiting ping
+func (s *icmpSocket) fail(err error) {
+	s.conn.Close()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.err = err
+	for seq, pending := range s.pending {
+		close(pending.reply)
+		delete(s.pending, seq)
+	}
+}
+
+func (s *icmpSocket) failure() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.err
+}
+
+func peerIP(addr net.Addr) net.IP {
+	switch a := addr.(type) {
+	case *net.IPAddr:
+		return a.IP
+	case *net.UDPAddr:
+		return a.IP
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package executor
+
+import (
+	"context"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+)
+
+type fakeICMPPacket struct {
+	data []byte
+	peer net.Addr
+}
+
+// fakeICMPConn answers echo requests like a raw socket would. Replies arrive after a
+// delay and out of order, each preceded by a reply of a foreign process and a reply from
+// another host. Requests to a host in drop are never answered.
+type fakeICMPConn struct {
+	net.PacketConn
+	delay   func(dst net.IP) time.Duration
+	drop    func(dst net.IP) bool
+	packets chan fakeICMPPacket
+	closed  chan struct{}
+	once    sync.Once
+}
+
+func newFakeICMPConn() *fakeICMPConn {
+	return &fakeICMPConn{
+		delay:   func(net.IP) time.Duration { return 0 },
+		drop:    func(net.IP) bool { return false },
+		packets: make(chan fakeICMPPacket, 1024),
+		closed:  make(chan struct{}),
+	}
+}
+
+func (c *fakeICMPConn) WriteTo(b []byte, addr net.Addr) (int, error) {
+	msg, err := icmp.ParseMessage(1, b)
+	if err != nil {
+		return 0, err
+	}
+	echo := msg.Body.(*icmp.Echo)
+	dst := addr.(*net.IPAddr)
+
+	reply := func(id int, peer *net.IPAddr) {
+		replyBytes, _ := (&icmp.Message{
+			Type: ipv4.ICMPTypeEchoReply,
+			Body: &icmp.Echo{ID: id, Seq: echo.Seq, Data: echo.Data},
+		}).Marshal(nil)
+		select {
+		case c.packets <- fakeICMPPacket{data: replyBytes, peer: peer}:
+		case <-c.closed:
+		}
+	}
+
+	reply(echo.ID+1, dst)
+	reply(echo.ID, &net.IPAddr{IP: net.IPv4(192, 0, 2, 1)})
+	if !c.drop(dst.IP) {
+		go func() {
+			time.Sleep(c.delay(dst.IP))
+			reply(echo.ID, dst)
+		}()
+	}
+	return len(b), nil
+}
+
+func (c *fakeICMPConn) ReadFrom(b []byte) (int, net.Addr, error) {
+	select {
+	case packet := <-c.packets:
+		return copy(b, packet.data), packet.peer, nil
+	case <-c.closed:
+		return 0, nil, net.ErrClosed
+	}
+}
+
+func (c *fakeICMPConn) Close() error {
+	c.once.Do(func() { close(c.closed) })
+	return nil
+}
+
+// useFakeICMP makes the pingers of the test open fake sockets and counts the opens
+func useFakeICMP(t testing.TB, newConn func() *fakeICMPConn) *atomic.Int64 {
+	opens := new(atomic.Int64)
+	original := listenICMP
+	listenICMP = func(string) (net.PacketConn, error) {
+		opens.Add(1)
+		return newConn(), nil
+	}
+	t.Cleanup(func() { listenICMP = original })
+	return opens
+}
+
+func TestICMPPinger_ConcurrentPings(t *testing.T) {
+	opens := useFakeICMP(t, func() *fakeICMPConn {
+		conn := newFakeICMPConn()
+		// Later hosts answer sooner, so replies arrive in the opposite order of the requests
+		conn.delay = func(dst net.IP) time.Duration { return time.Duration(255-int(dst[15])) * 100 * time.Microsecond }
+		conn.drop = func(dst net.IP) bool { return dst[15]%5 == 0 }
+		return conn
+	})
+
+	pinger := newICMPPinger()
+	defer pinger.Close()
+
+	var wg sync.WaitGroup
+	errs := make([]error, 200)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			dst := &net.IPAddr{IP: net.IPv4(10, 0, 0, byte(i+1))}
+			_, errs[i] = pinger.Ping(context.Background(), "0.0.0.0", dst, []byte("Peekaping"), 500*time.Millisecond)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if (i+1)%5 == 0 {
+			// A reply meant for another check must never complete a dropped one
+			assert.ErrorContains(t, err, "timeout", "ping %d", i)
+		} else {
+			assert.NoError(t, err, "ping %d", i)
+		}
+	}
+	assert.Equal(t, int64(1), opens.Load())
+}
+
+func TestICMPPinger_SocketPerSourceAddress(t *testing.T) {
+	opens := useFakeICMP(t, newFakeICMPConn)
+
+	pinger := newICMPPinger()
+	defer pinger.Close()
+
+	dst := &net.IPAddr{IP: net.IPv4(10, 0, 0, 1)}
+	for _, listenAddr := range []string{"0.0.0.0", "192.168.1.10", "0.0.0.0", "192.168.1.10"} {
+		_, err := pinger.Ping(context.Background(), listenAddr, dst, nil, time.Second)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int64(2), opens.Load())
+}
+
+func TestICMPPinger_ReopensFailedSocket(t *testing.T) {
+	var conns []*fakeICMPConn
+	opens := useFakeICMP(t, func() *fakeICMPConn {
+		conn := newFakeICMPConn()
+		conns = append(conns, conn)
+		return conn
+	})
+
+	pinger := newICMPPinger()
+	defer pinger.Close()
+
+	dst := &net.IPAddr{IP: net.IPv4(10, 0, 0, 1)}
+	_, err := pinger.Ping(context.Background(), "0.0.0.0", dst, nil, time.Second)
+	require.NoError(t, err)
+
+	conns[0].Close()
+	require.Eventually(t, func() bool {
+		_, err := pinger.Ping(context.Background(), "0.0.0.0", dst, nil, time.Second)
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, int64(2), opens.Load())
+}
+
+func TestICMPPinger_ContextCanceled(t *testing.T) {
+	useFakeICMP(t, func() *fakeICMPConn {
+		conn := newFakeICMPConn()
+		conn.drop = func(net.IP) bool { return true }
+		return conn
+	})
+
+	pinger := newICMPPinger()
+	defer pinger.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := pinger.Ping(ctx, "0.0.0.0", &net.IPAddr{IP: net.IPv4(10, 0, 0, 1)}, nil, time.Minute)
+	assert.ErrorContains(t, err, "context canceled")
+}
+
+// BenchmarkICMPPing compares the sockets opened by the shared pinger with opening a socket
+// for every check, as native pings used to
+func BenchmarkICMPPing(b *testing.B) {
+	dst := &net.IPAddr{IP: net.IPv4(10, 0, 0, 1)}
+
+	b.Run("shared_socket", func(b *testing.B) {
+		opens := useFakeICMP(b, newFakeICMPConn)
+		pinger := newICMPPinger()
+		defer pinger.Close()
+
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				if _, err := pinger.Ping(context.Background(), "0.0.0.0", dst, nil, time.Second); err != nil {
+					b.Error(err)
+				}
+			}
+		})
+		b.ReportMetric(float64(opens.Load())/float64(b.N), "sockets/op")
+	})
+
+	b.Run("socket_per_check", func(b *testing.B) {
+		opens := useFakeICMP(b, newFakeICMPConn)
+
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				pinger := newICMPPinger()
+				if _, err := pinger.Ping(context.Background(), "0.0.0.0", dst, nil, time.Second); err != nil {
+					b.Error(err)
+				}
+				pinger.Close()
+			}
+		})
+		b.ReportMetric(float64(opens.Load())/float64(b.N), "sockets/op")
+	})
+}
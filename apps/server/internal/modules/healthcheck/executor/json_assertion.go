@@ -0,0 +1,163 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// maxResponseBodySize caps how much of an HTTP response body is read for validation
+const maxResponseBodySize = 10 << 20
+
+// JSONAssertion compares the value at a JSONPath of the response with an expected value.
+// Operators are eq, ne, gt, lt (numbers) and contains (substring of a string or element of
+// an array). Expected is any JSON value, e.g. "up", 3 or true.
+type JSONAssertion struct {
+	Path     string `json:"path" validate:"required" example:"$.db"`
+	Operator string `json:"operator" validate:"required,oneof=eq ne gt lt contains" example:"eq"`
+	Expected any    `json:"expected" example:"up"`
+}
+
+var (
+	jsonPathIndex     = regexp.MustCompile(`\[(\d+|\*)\]`)
+	jsonPathQuotedKey = regexp.MustCompile(`\[\s*(?:'([^']*)'|"([^"]*)")\s*\]`)
+)
+
+// toGJSONPath converts a JSONPath expression such as $.services[0]['db.primary'].status to
+// gjson syntax. Paths without a leading $ are taken as gjson paths already.
+func toGJSONPath(path string) string {
+	path = strings.TrimSpace(path)
+	if !strings.HasPrefix(path, "$") {
+		return path
+	}
+	path = strings.TrimPrefix(path, "$")
+
+	path = jsonPathQuotedKey.ReplaceAllStringFunc(path, func(match string) string {
+		groups := jsonPathQuotedKey.FindStringSubmatch(match)
+		key := groups[1] + groups[2]
+		return "." + strings.NewReplacer(".", `\.`, "*", `\*`, "?", `\?`).Replace(key)
+	})
+	path = jsonPathIndex.ReplaceAllStringFunc(path, func(match string) string {
+		index := match[1 : len(match)-1]
+		if index == "*" {
+			return ".#"
+		}
+		return "." + index
+	})
+
+	path = strings.TrimPrefix(path, ".")
+	if path == "" {
+		return "@this"
+	}
+	return path
+}
+
+// checkJSONAssertions evaluates every assertion against the response and returns a
+// message describing the failed ones, or an empty string when all pass
+func checkJSONAssertions(responseBody string, assertions []JSONAssertion) string {
+	if !gjson.Valid(responseBody) {
+		return "JSON assertion failed: response not JSON"
+	}
+
+	var failures []string
+	for _, assertion := range assertions {
+		if err := evaluateJSONAssertion(responseBody, assertion); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+	if len(failures) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("JSON assertion failed: %s", strings.Join(failures, "; "))
+}
+
+func evaluateJSONAssertion(responseBody string, assertion JSONAssertion) error {
+	result := gjson.Get(responseBody, toGJSONPath(assertion.Path))
+	if !result.Exists() {
+		return fmt.Errorf("%s not found", assertion.Path)
+	}
+
+	var actual any
+	if err := json.Unmarshal([]byte(result.Raw), &actual); err != nil {
+		return fmt.Errorf("%s is not a JSON value", assertion.Path)
+	}
+
+	var ok bool
+	switch assertion.Operator {
+	case "eq":
+		ok = jsonValuesEqual(actual, assertion.Expected)
+	case "ne":
+		ok = !jsonValuesEqual(actual, assertion.Expected)
+	case "gt", "lt":
+		actualNumber, isNumber := jsonNumber(actual)
+		expectedNumber, expectedIsNumber := jsonNumber(assertion.Expected)
+		if !isNumber || !expectedIsNumber {
+			return fmt.Errorf("%s is %s, %s needs numbers", assertion.Path, result.Raw, assertion.Operator)
+		}
+		if assertion.Operator == "gt" {
+			ok = actualNumber > expectedNumber
+		} else {
+			ok = actualNumber < expectedNumber
+		}
+	case "contains":
+		ok = jsonContains(actual, assertion.Expected)
+	default:
+		return fmt.Errorf("unsupported operator '%s'", assertion.Operator)
+	}
+
+	if !ok {
+		expected, _ := json.Marshal(assertion.Expected)
+		return fmt.Errorf("%s is %s, expected %s %s", assertion.Path, result.Raw, assertion.Operator, expected)
+	}
+	return nil
+}
+
+// jsonValuesEqual compares decoded JSON values. A string expected value also matches a
+// number or boolean with the same text, e.g. "3" matches 3.
+func jsonValuesEqual(actual, expected any) bool {
+	if reflect.DeepEqual(actual, expected) {
+		return true
+	}
+	if expectedString, ok := expected.(string); ok {
+		switch actual.(type) {
+		case float64, bool:
+			encoded, _ := json.Marshal(actual)
+			return string(encoded) == expectedString
+		}
+	}
+	return false
+}
+
+// jsonNumber reads a number, or a string holding one
+func jsonNumber(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case string:
+		number, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return number, err == nil
+	}
+	return 0, false
+}
+
+// jsonContains reports whether a string contains the expected text or an array contains
+// an element equal to the expected value
+func jsonContains(actual, expected any) bool {
+	switch v := actual.(type) {
+	case string:
+		expectedString, ok := expected.(string)
+		return ok && strings.Contains(v, expectedString)
+	case []any:
+		for _, element := range v {
+			if jsonValuesEqual(element, expected) {
+				return true
+			}
+		}
+	}
+	return false
+}
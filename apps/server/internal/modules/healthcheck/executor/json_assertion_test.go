@@ -0,0 +1,151 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"peekaping/internal/modules/shared"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestToGJSONPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"$.status", "status"},
+		{"$.services[0].status", "services.0.status"},
+		{"$['db.primary'].status", `db\.primary.status`},
+		{`$.checks["cache"]`, "checks.cache"},
+		{"$.services[*].name", "services.#.name"},
+		{"$", "@this"},
+		{"services.0.status", "services.0.status"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.expected, toGJSONPath(tt.path))
+		})
+	}
+}
+
+func TestCheckJSONAssertions(t *testing.T) {
+	const body = `{"status": "ok", "db": "down", "cache": "up", "queue": {"depth": 12}, "version": "1.4.2", "regions": ["eu", "us"]}`
+
+	tests := []struct {
+		name            string
+		body            string
+		assertions      []JSONAssertion
+		expectedMessage string
+	}{
+		{
+			name:       "all pass",
+			body:       body,
+			assertions: []JSONAssertion{{"$.status", "eq", "ok"}, {"$.queue.depth", "lt", float64(100)}, {"$.version", "ne", "1.4.1"}},
+		},
+		{
+			name:       "gt and contains",
+			body:       body,
+			assertions: []JSONAssertion{{"$.queue.depth", "gt", float64(10)}, {"$.version", "contains", "1.4"}, {"$.regions", "contains", "us"}},
+		},
+		{
+			name:       "string expected matches number",
+			body:       body,
+			assertions: []JSONAssertion{{"$.queue.depth", "eq", "12"}},
+		},
+		{
+			name:            "degraded dependency",
+			body:            body,
+			assertions:      []JSONAssertion{{"$.status", "eq", "ok"}, {"$.db", "eq", "up"}, {"$.cache", "eq", "up"}},
+			expectedMessage: `JSON assertion failed: $.db is "down", expected eq "up"`,
+		},
+		{
+			name:            "every failure is reported",
+			body:            body,
+			assertions:      []JSONAssertion{{"$.db", "eq", "up"}, {"$.queue.depth", "lt", float64(10)}, {"$.missing", "eq", "x"}},
+			expectedMessage: `JSON assertion failed: $.db is "down", expected eq "up"; $.queue.depth is 12, expected lt 10; $.missing not found`,
+		},
+		{
+			name:            "numeric operator on a string",
+			body:            body,
+			assertions:      []JSONAssertion{{"$.db", "gt", float64(1)}},
+			expectedMessage: `JSON assertion failed: $.db is "down", gt needs numbers`,
+		},
+		{
+			name:            "array does not contain",
+			body:            body,
+			assertions:      []JSONAssertion{{"$.regions", "contains", "ap"}},
+			expectedMessage: `JSON assertion failed: $.regions is ["eu", "us"], expected contains "ap"`,
+		},
+		{
+			name:            "not json",
+			body:            "<html>maintenance</html>",
+			assertions:      []JSONAssertion{{"$.status", "eq", "ok"}},
+			expectedMessage: "JSON assertion failed: response not JSON",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedMessage, checkJSONAssertions(tt.body, tt.assertions))
+		})
+	}
+}
+
+func TestHTTPExecutor_Execute_JSONAssertions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"status": "ok", "db": "down", "cache": "up"}`)
+	}))
+	defer server.Close()
+
+	executor := NewHTTPExecutor(zap.NewNop().Sugar())
+
+	tests := []struct {
+		name            string
+		assertions      string
+		expectedStatus  shared.MonitorStatus
+		expectedMessage string
+	}{
+		{
+			name:            "passing assertions",
+			assertions:      `[{"path": "$.status", "operator": "eq", "expected": "ok"}, {"path": "$.cache", "operator": "eq", "expected": "up"}]`,
+			expectedStatus:  shared.MonitorStatusUp,
+			expectedMessage: "200 - 200 OK",
+		},
+		{
+			name:            "failing assertion",
+			assertions:      `[{"path": "$.status", "operator": "eq", "expected": "ok"}, {"path": "$.db", "operator": "eq", "expected": "up"}]`,
+			expectedStatus:  shared.MonitorStatusDown,
+			expectedMessage: `JSON assertion failed: $.db is "down", expected eq "up"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := fmt.Sprintf(`{"url": %q, "method": "GET", "encoding": "json", "accepted_statuscodes": ["2XX"], "authMethod": "none", "json_assertions": %s}`,
+				server.URL, tt.assertions)
+			assert.NoError(t, executor.Validate(config))
+
+			monitor := &Monitor{
+				ID:       "test-monitor",
+				Type:     "http",
+				Name:     "Test Monitor",
+				Interval: 30,
+				Timeout:  5,
+				Config:   config,
+			}
+
+			result := executor.Execute(context.Background(), monitor, nil)
+			assert.Equal(t, tt.expectedStatus, result.Status)
+			assert.Equal(t, tt.expectedMessage, result.Message)
+		})
+	}
+
+	invalid := fmt.Sprintf(`{"url": %q, "method": "GET", "encoding": "json", "accepted_statuscodes": ["2XX"], "authMethod": "none", "json_assertions": [{"path": "$.db", "operator": "matches", "expected": "up"}]}`, server.URL)
+	assert.Error(t, executor.Validate(invalid))
+}
@@ -0,0 +1,105 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"peekaping/internal/modules/shared"
+	"sync"
+
+	"github.com/osteele/liquid"
+)
+
+var messageTemplateEngine = liquid.NewEngine()
+
+// MessageTemplate is a liquid template replacing the message of a check, e.g.
+// `SMTP relay {{ config.hostname }} answered in {{ ping }}ms`. It can use the executor's
+// message, the status ("up", "down" or "pending"), ping, error_category, the monitor's
+// name, type and parsed config, the response body and json of HTTP monitors and the tls
+// info of monitors checking certificates.
+type MessageTemplate struct {
+	template *liquid.Template
+}
+
+// CompileMessageTemplate parses a message template
+func CompileMessageTemplate(template string) (*MessageTemplate, error) {
+	parsed, err := messageTemplateEngine.ParseString(template)
+	if err != nil {
+		return nil, fmt.Errorf("invalid message template: %w", err)
+	}
+	return &MessageTemplate{template: parsed}, nil
+}
+
+// Render returns the message of result for monitor m
+func (t *MessageTemplate) Render(m *Monitor, result *Result, pingMs int) (string, error) {
+	return t.template.RenderString(messageTemplateBindings(m, result, pingMs))
+}
+
+func messageTemplateBindings(m *Monitor, result *Result, pingMs int) map[string]any {
+	status := "down"
+	switch result.Status {
+	case shared.MonitorStatusUp:
+		status = "up"
+	case shared.MonitorStatusPending:
+		status = "pending"
+	}
+
+	var config any = map[string]any{}
+	if m.Config != "" {
+		_ = json.Unmarshal([]byte(m.Config), &config)
+	}
+
+	var parsed any = map[string]any{}
+	if result.ResponseBody != "" {
+		_ = json.Unmarshal([]byte(result.ResponseBody), &parsed)
+	}
+
+	var tls any
+	if result.TLSInfo != nil {
+		if data, err := json.Marshal(result.TLSInfo); err == nil {
+			_ = json.Unmarshal(data, &tls)
+		}
+	}
+
+	return map[string]any{
+		"message":        result.Message,
+		"status":         status,
+		"ping":           pingMs,
+		"error_category": result.ErrorCategory,
+		"name":           m.Name,
+		"type":           m.Type,
+		"config":         config,
+		"body":           result.ResponseBody,
+		"json":           parsed,
+		"tls":            tls,
+	}
+}
+
+// MessageTemplateCache keeps parsed templates so a monitor's templates are parsed once
+// per worker instead of on every check
+type MessageTemplateCache struct {
+	mu        sync.Mutex
+	templates map[string]*MessageTemplate
+}
+
+func NewMessageTemplateCache() *MessageTemplateCache {
+	return &MessageTemplateCache{templates: make(map[string]*MessageTemplate)}
+}
+
+// Get returns the parsed template, parsing it on first use
+func (c *MessageTemplateCache) Get(template string) (*MessageTemplate, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if compiled, ok := c.templates[template]; ok {
+		return compiled, nil
+	}
+	compiled, err := CompileMessageTemplate(template)
+	if err != nil {
+		return nil, err
+	}
+	if len(c.templates) >= maxCachedExpressions {
+		c.templates = make(map[string]*MessageTemplate)
+	}
+	c.templates[template] = compiled
+	return compiled, nil
+}
@@ -0,0 +1,30 @@
+package executor
+
+import (
+	"peekaping/internal/modules/shared"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageTemplate_Render(t *testing.T) {
+	m := &Monitor{Name: "Mail", Type: "smtp", Config: `{"hostname": "mail.example.com", "port": 587}`}
+	result := &Result{
+		Status:  shared.MonitorStatusUp,
+		Message: "SMTP server is reachable",
+		TLSInfo: &shared.TLSInfo{Valid: true},
+	}
+
+	template, err := CompileMessageTemplate("Relay {{ config.hostname }}:{{ config.port }} is {{ status }} in {{ ping }}ms, tls valid: {{ tls.valid }} ({{ message }})")
+	require.NoError(t, err)
+
+	message, err := template.Render(m, result, 42)
+	require.NoError(t, err)
+	assert.Equal(t, "Relay mail.example.com:587 is up in 42ms, tls valid: true (SMTP server is reachable)", message)
+}
+
+func TestCompileMessageTemplate_Invalid(t *testing.T) {
+	_, err := CompileMessageTemplate("{% if status %}unterminated")
+	assert.Error(t, err)
+}
@@ -0,0 +1,82 @@
+package executor
+
+import (
+	"context"
+	"peekaping/internal/modules/shared"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Results recorded by ExecutorMetrics
+const (
+	ExecutionSuccess = "success" // the check reported the monitor up
+	ExecutionError   = "error"   // the check reported the monitor down or pending
+	ExecutionSkipped = "skipped" // the executor had nothing to report, e.g. a push in time
+)
+
+// ExecutorMetrics records how long checks take and how they end, labeled by executor
+// type, to spot monitor types that are slow or failing disproportionately
+type ExecutorMetrics struct {
+	duration   *prometheus.HistogramVec
+	executions *prometheus.CounterVec
+}
+
+func NewExecutorMetrics(registerer prometheus.Registerer) *ExecutorMetrics {
+	m := &ExecutorMetrics{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "peekaping",
+			Subsystem: "executor",
+			Name:      "execution_duration_seconds",
+			Help:      "Duration of health check executions by executor type.",
+			Buckets:   []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+		}, []string{"type"}),
+		executions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "peekaping",
+			Subsystem: "executor",
+			Name:      "executions_total",
+			Help:      "Health check executions by executor type and result (success, error or skipped).",
+		}, []string{"type", "result"}),
+	}
+	registerer.MustRegister(m.duration, m.executions)
+	return m
+}
+
+// ProvideExecutorMetrics registers the executor metrics with the default Prometheus registry
+func ProvideExecutorMetrics() *ExecutorMetrics {
+	return NewExecutorMetrics(prometheus.DefaultRegisterer)
+}
+
+// Instrument wraps exec so every Execute call is timed and counted under executorType
+func (m *ExecutorMetrics) Instrument(executorType string, exec Executor) Executor {
+	return &instrumentedExecutor{Executor: exec, executorType: executorType, metrics: m}
+}
+
+func (m *ExecutorMetrics) observe(executorType string, elapsed time.Duration, result *Result) {
+	m.duration.WithLabelValues(executorType).Observe(elapsed.Seconds())
+	m.executions.WithLabelValues(executorType, executionResult(result)).Inc()
+}
+
+func executionResult(result *Result) string {
+	switch {
+	case result == nil:
+		return ExecutionSkipped
+	case result.Status == shared.MonitorStatusUp:
+		return ExecutionSuccess
+	default:
+		return ExecutionError
+	}
+}
+
+type instrumentedExecutor struct {
+	Executor
+	executorType string
+	metrics      *ExecutorMetrics
+}
+
+func (e *instrumentedExecutor) Execute(ctx context.Context, m *Monitor, proxyModel *Proxy) *Result {
+	start := time.Now()
+	result := e.Executor.Execute(ctx, m, proxyModel)
+	e.metrics.observe(e.executorType, time.Since(start), result)
+	return result
+}
@@ -0,0 +1,76 @@
+package executor
+
+import (
+	"context"
+	"testing"
+
+	"peekaping/internal/modules/shared"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixedResultExecutor returns the results it was given, one per call
+type fixedResultExecutor struct {
+	results []*Result
+}
+
+func (e *fixedResultExecutor) Execute(ctx context.Context, m *Monitor, proxyModel *Proxy) *Result {
+	result := e.results[0]
+	e.results = e.results[1:]
+	return result
+}
+
+func (e *fixedResultExecutor) Validate(configJSON string) error { return nil }
+
+func (e *fixedResultExecutor) Unmarshal(configJSON string) (any, error) { return nil, nil }
+
+func TestExecutorMetrics_Instrument(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	metrics := NewExecutorMetrics(registry)
+
+	httpExec := metrics.Instrument("http", &fixedResultExecutor{results: []*Result{
+		{Status: shared.MonitorStatusUp},
+		{Status: shared.MonitorStatusUp},
+		{Status: shared.MonitorStatusDown},
+	}})
+	pushExec := metrics.Instrument("push", &fixedResultExecutor{results: []*Result{nil}})
+
+	for i := 0; i < 3; i++ {
+		httpExec.Execute(context.Background(), &Monitor{ID: "mon-1"}, nil)
+	}
+	result := pushExec.Execute(context.Background(), &Monitor{ID: "mon-2"}, nil)
+	assert.Nil(t, result)
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.executions.WithLabelValues("http", ExecutionSuccess)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.executions.WithLabelValues("http", ExecutionError)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.executions.WithLabelValues("push", ExecutionSkipped)))
+	assert.Equal(t, 0.0, testutil.ToFloat64(metrics.executions.WithLabelValues("push", ExecutionError)))
+
+	// One duration sample per execution
+	assert.Equal(t, uint64(3), histogramSampleCount(t, registry, "http"))
+	assert.Equal(t, uint64(1), histogramSampleCount(t, registry, "push"))
+
+	// The wrapper keeps the rest of the executor
+	assert.NoError(t, httpExec.Validate("{}"))
+}
+
+func histogramSampleCount(t *testing.T, registry *prometheus.Registry, executorType string) uint64 {
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != "peekaping_executor_execution_duration_seconds" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "type" && label.GetValue() == executorType {
+					return metric.GetHistogram().GetSampleCount()
+				}
+			}
+		}
+	}
+	return 0
+}
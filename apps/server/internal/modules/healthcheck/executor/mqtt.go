@@ -3,6 +3,7 @@ package executor
 import (
 	"context"
 	"crypto/rand"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"peekaping/internal/modules/shared"
@@ -14,16 +15,41 @@ import (
 	"go.uber.org/zap"
 )
 
+// MQTT check types. Without a check type, the check waits for expected_payload when it is
+// set and otherwise only connects and subscribes.
+const (
+	// MQTTCheckConnect is up once the broker accepted the connection and subscription
+	MQTTCheckConnect = "connect"
+	// MQTTCheckPayload is up once a message equal to expected_payload arrives on the topic
+	MQTTCheckPayload = "payload"
+	// MQTTCheckNone is up once any message arrives on the topic
+	MQTTCheckNone = "none"
+)
+
 type MQTTConfig struct {
 	Hostname       string `json:"hostname" validate:"required" example:"localhost"`
 	Port           int    `json:"port" validate:"required,min=1,max=65535" example:"1883"`
 	Topic          string `json:"topic" validate:"required" example:"test/topic"`
 	Username       string `json:"username" example:"user"`
 	Password       string `json:"password" example:"password"`
-	CheckType      string `json:"check_type" validate:"oneof=keyword json-query none" example:"keyword"`
+	UseTLS         bool   `json:"use_tls" example:"false"`
+	CheckType      string `json:"check_type" validate:"omitempty,oneof=keyword json-query none connect payload" example:"keyword"`
 	SuccessKeyword string `json:"success_keyword" example:"success"`
 	JsonPath       string `json:"json_path" example:"$.status"`
 	ExpectedValue  string `json:"expected_value" example:"ok"`
+	// ExpectedPayload is compared with the whole message, ignoring surrounding whitespace
+	ExpectedPayload string `json:"expected_payload" example:"online"`
+}
+
+// checkType resolves the check type of the config
+func (c *MQTTConfig) checkType() string {
+	if c.CheckType != "" {
+		return c.CheckType
+	}
+	if c.ExpectedPayload != "" {
+		return MQTTCheckPayload
+	}
+	return MQTTCheckConnect
 }
 
 type MQTTExecutor struct {
@@ -65,8 +91,12 @@ func (s *MQTTExecutor) Validate(configJSON string) error {
 		if strings.TrimSpace(config.ExpectedValue) == "" {
 			return fmt.Errorf("expected_value is required when check_type is 'json-query'")
 		}
+	} else if config.CheckType == MQTTCheckPayload {
+		if strings.TrimSpace(config.ExpectedPayload) == "" {
+			return fmt.Errorf("expected_payload is required when check_type is 'payload'")
+		}
 	}
-	// "none" check type requires no additional validation
+	// "none" and "connect" check types require no additional validation
 
 	return nil
 }
@@ -78,21 +108,22 @@ func (m *MQTTExecutor) Execute(ctx context.Context, monitor *Monitor, proxyModel
 	}
 	cfg := cfgAny.(*MQTTConfig)
 
-	m.logger.Debugf("execute mqtt cfg: %+v", cfg)
+	// The config holds the broker credentials and is never logged
+	m.logger.Debugf("execute mqtt: %s:%d, topic %s", cfg.Hostname, cfg.Port, cfg.Topic)
 
 	startTime := time.Now().UTC()
 
-	// Set default check type if not specified
-	if cfg.CheckType == "" {
-		cfg.CheckType = "none"
-	}
+	cfg.CheckType = cfg.checkType()
 
 	// Connect to MQTT broker and receive message
-	receivedMessage, err := m.mqttAsync(ctx, cfg.Hostname, cfg.Topic, map[string]interface{}{
-		"port":     cfg.Port,
-		"username": cfg.Username,
-		"password": cfg.Password,
-		"timeout":  time.Duration(monitor.Timeout) * time.Second,
+	receivedMessage, connectLatency, err := m.mqttAsync(ctx, cfg.Hostname, cfg.Topic, map[string]interface{}{
+		"port":             cfg.Port,
+		"username":         cfg.Username,
+		"password":         cfg.Password,
+		"use_tls":          cfg.UseTLS,
+		"timeout":          time.Duration(monitor.Timeout) * time.Second,
+		"wait_for_message": cfg.CheckType != MQTTCheckConnect,
+		"expected_payload": cfg.ExpectedPayload,
 	})
 
 	endTime := time.Now().UTC()
@@ -107,13 +138,30 @@ func (m *MQTTExecutor) Execute(ctx context.Context, monitor *Monitor, proxyModel
 		}
 	}
 
+	connected := fmt.Sprintf("Connected in %dms", connectLatency.Milliseconds())
+
 	// Perform check based on check type
-	if cfg.CheckType == "none" {
+	if cfg.CheckType == MQTTCheckConnect {
+		return &Result{
+			Status:    shared.MonitorStatusUp,
+			Message:   fmt.Sprintf("Topic: %s; Subscribed; %s", cfg.Topic, connected),
+			StartTime: startTime,
+			EndTime:   endTime,
+		}
+	} else if cfg.CheckType == MQTTCheckPayload {
+		// Only a matching message ends the wait, anything else timed out above
+		return &Result{
+			Status:    shared.MonitorStatusUp,
+			Message:   fmt.Sprintf("Topic: %s; Expected payload received; %s", cfg.Topic, connected),
+			StartTime: startTime,
+			EndTime:   endTime,
+		}
+	} else if cfg.CheckType == MQTTCheckNone {
 		// For "none" check type, any received message is considered success
 		if receivedMessage != "" {
 			return &Result{
 				Status:    shared.MonitorStatusUp,
-				Message:   fmt.Sprintf("Topic: %s; Message received", cfg.Topic),
+				Message:   fmt.Sprintf("Topic: %s; Message received; %s", cfg.Topic, connected),
 				StartTime: startTime,
 				EndTime:   endTime,
 			}
@@ -139,7 +187,7 @@ func (m *MQTTExecutor) Execute(ctx context.Context, monitor *Monitor, proxyModel
 		if receivedMessage != "" && strings.Contains(receivedMessage, cfg.SuccessKeyword) {
 			return &Result{
 				Status:    shared.MonitorStatusUp,
-				Message:   fmt.Sprintf("Topic: %s; Message: %s", cfg.Topic, receivedMessage),
+				Message:   fmt.Sprintf("Topic: %s; Message: %s; %s", cfg.Topic, receivedMessage, connected),
 				StartTime: startTime,
 				EndTime:   endTime,
 			}
@@ -208,7 +256,7 @@ func (m *MQTTExecutor) Execute(ctx context.Context, monitor *Monitor, proxyModel
 		if resultStr == cfg.ExpectedValue {
 			return &Result{
 				Status:    shared.MonitorStatusUp,
-				Message:   "Message received, expected value is found",
+				Message:   fmt.Sprintf("Message received, expected value is found; %s", connected),
 				StartTime: startTime,
 				EndTime:   endTime,
 			}
@@ -230,12 +278,18 @@ func (m *MQTTExecutor) Execute(ctx context.Context, monitor *Monitor, proxyModel
 	}
 }
 
-// mqttAsync connects to MQTT broker, subscribes to topic and receives message as string
-func (m *MQTTExecutor) mqttAsync(ctx context.Context, hostname, topic string, options map[string]interface{}) (string, error) {
+// mqttAsync connects to MQTT broker, subscribes to topic and receives message as string.
+// It also returns how long connecting took. Without wait_for_message it returns once the
+// subscription is accepted, with expected_payload it waits for a message equal to it.
+func (m *MQTTExecutor) mqttAsync(ctx context.Context, hostname, topic string, options map[string]interface{}) (string, time.Duration, error) {
 	port, _ := options["port"].(int)
 	username, _ := options["username"].(string)
 	password, _ := options["password"].(string)
+	useTLS, _ := options["use_tls"].(bool)
 	timeout, _ := options["timeout"].(time.Duration)
+	waitForMessage, _ := options["wait_for_message"].(bool)
+	expectedPayload, _ := options["expected_payload"].(string)
+	expectedPayload = strings.TrimSpace(expectedPayload)
 
 	if timeout == 0 {
 		timeout = 20 * time.Second
@@ -243,7 +297,11 @@ func (m *MQTTExecutor) mqttAsync(ctx context.Context, hostname, topic string, op
 
 	// Add MQTT protocol to hostname if not present
 	if !strings.HasPrefix(hostname, "mqtt://") && !strings.HasPrefix(hostname, "mqtts://") {
-		hostname = "mqtt://" + hostname
+		if useTLS {
+			hostname = "mqtts://" + hostname
+		} else {
+			hostname = "mqtt://" + hostname
+		}
 	}
 
 	// Generate random client ID
@@ -263,6 +321,13 @@ func (m *MQTTExecutor) mqttAsync(ctx context.Context, hostname, topic string, op
 	opts.SetWriteTimeout(timeout)
 	opts.SetKeepAlive(60 * time.Second)
 	opts.SetCleanSession(true)
+	// A health check connects once, retrying would hide a broker that is down
+	opts.SetConnectRetry(false)
+	opts.SetAutoReconnect(false)
+
+	if strings.HasPrefix(hostname, "mqtts://") {
+		opts.SetTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12})
+	}
 
 	if username != "" {
 		opts.SetUsername(username)
@@ -279,32 +344,44 @@ func (m *MQTTExecutor) mqttAsync(ctx context.Context, hostname, topic string, op
 	client := mqtt.NewClient(opts)
 
 	// Connect to broker
+	connectStart := time.Now()
 	if token := client.Connect(); token.Wait() && token.Error() != nil {
-		return "", fmt.Errorf("MQTT connection failed: %v", token.Error())
+		return "", 0, fmt.Errorf("MQTT connection failed: %v", token.Error())
 	}
+	connectLatency := time.Since(connectStart)
 
-	m.logger.Debugf("MQTT connected successfully")
+	m.logger.Debugf("MQTT connected successfully in %v", connectLatency)
 
 	// Set up message handler
 	messageHandler := func(client mqtt.Client, msg mqtt.Message) {
 		m.logger.Debugf("MQTT message received on topic %s", msg.Topic())
-		if msg.Topic() == topic {
-			select {
-			case messageChan <- string(msg.Payload()):
-			default:
-				// Channel full, ignore
-			}
+		if msg.Topic() != topic {
+			return
+		}
+		payload := string(msg.Payload())
+		if expectedPayload != "" && strings.TrimSpace(payload) != expectedPayload {
+			return
+		}
+		select {
+		case messageChan <- payload:
+		default:
+			// Channel full, ignore
 		}
 	}
 
 	// Subscribe to topic
 	if token := client.Subscribe(topic, 0, messageHandler); token.Wait() && token.Error() != nil {
 		client.Disconnect(100)
-		return "", fmt.Errorf("MQTT subscription failed: %v", token.Error())
+		return "", connectLatency, fmt.Errorf("MQTT subscription failed: %v", token.Error())
 	}
 
 	m.logger.Debugf("MQTT subscribed to topic %s", topic)
 
+	if !waitForMessage {
+		client.Disconnect(100)
+		return "", connectLatency, nil
+	}
+
 	// Wait for message or timeout
 	timeoutTimer := time.NewTimer(time.Duration(float64(timeout) * 0.8))
 	defer timeoutTimer.Stop()
@@ -312,15 +389,18 @@ func (m *MQTTExecutor) mqttAsync(ctx context.Context, hostname, topic string, op
 	select {
 	case message := <-messageChan:
 		client.Disconnect(100)
-		return message, nil
+		return message, connectLatency, nil
 	case err := <-errorChan:
 		client.Disconnect(100)
-		return "", err
+		return "", connectLatency, err
 	case <-timeoutTimer.C:
 		client.Disconnect(100)
-		return "", fmt.Errorf("timeout, message not received within %v", timeout)
+		if expectedPayload != "" {
+			return "", connectLatency, fmt.Errorf("timeout, no message matching the expected payload received within %v", timeout)
+		}
+		return "", connectLatency, fmt.Errorf("timeout, message not received within %v", timeout)
 	case <-ctx.Done():
 		client.Disconnect(100)
-		return "", fmt.Errorf("context cancelled")
+		return "", connectLatency, fmt.Errorf("context cancelled")
 	}
 }
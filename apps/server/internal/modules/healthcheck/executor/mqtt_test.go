@@ -0,0 +1,222 @@
+package executor
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"testing"
+
+	"peekaping/internal/modules/shared"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// fakeMQTTBroker speaks just enough MQTT 3.1.1 for one check: it answers CONNECT with
+// connackCode and, when accepted, publishes the scripted payloads on the topic a client
+// subscribes to
+type fakeMQTTBroker struct {
+	listener    net.Listener
+	connackCode byte
+	payloads    []string
+}
+
+func newFakeMQTTBroker(t *testing.T, connackCode byte, payloads ...string) *fakeMQTTBroker {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	broker := &fakeMQTTBroker{listener: listener, connackCode: connackCode, payloads: payloads}
+	go broker.serve()
+
+	return broker
+}
+
+func (b *fakeMQTTBroker) port() int {
+	return b.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (b *fakeMQTTBroker) serve() {
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+			return
+		}
+		go b.handle(conn)
+	}
+}
+
+func (b *fakeMQTTBroker) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	for {
+		packetType, body, err := readMQTTPacket(r)
+		if err != nil {
+			return
+		}
+
+		switch packetType {
+		case 1: // CONNECT
+			conn.Write([]byte{0x20, 0x02, 0x00, b.connackCode})
+			if b.connackCode != 0 {
+				return
+			}
+		case 8: // SUBSCRIBE
+			topicLen := binary.BigEndian.Uint16(body[2:4])
+			topic := string(body[4 : 4+topicLen])
+			conn.Write([]byte{0x90, 0x03, body[0], body[1], 0x00})
+			for _, payload := range b.payloads {
+				conn.Write(mqttPublishPacket(topic, payload))
+			}
+		case 12: // PINGREQ
+			conn.Write([]byte{0xD0, 0x00})
+		case 14: // DISCONNECT
+			return
+		}
+	}
+}
+
+func readMQTTPacket(r *bufio.Reader) (byte, []byte, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	length, multiplier := 0, 1
+	for {
+		digit, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length += int(digit&0x7f) * multiplier
+		if digit&0x80 == 0 {
+			break
+		}
+		multiplier *= 128
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return header >> 4, body, nil
+}
+
+func mqttPublishPacket(topic, payload string) []byte {
+	body := binary.BigEndian.AppendUint16(nil, uint16(len(topic)))
+	body = append(body, topic...)
+	body = append(body, payload...)
+
+	packet := []byte{0x30}
+	for length := len(body); ; {
+		digit := byte(length % 128)
+		length /= 128
+		if length > 0 {
+			digit |= 0x80
+		}
+		packet = append(packet, digit)
+		if length == 0 {
+			break
+		}
+	}
+	return append(packet, body...)
+}
+
+func mqttMonitor(t *testing.T, cfg MQTTConfig) *Monitor {
+	t.Helper()
+	config, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	return &Monitor{ID: "mon-1", Name: "broker", Type: "mqtt", Timeout: 1, Config: string(config)}
+}
+
+func TestMQTTExecutor_Validate(t *testing.T) {
+	executor := NewMQTTExecutor(zap.NewNop().Sugar())
+
+	tests := []struct {
+		name    string
+		config  string
+		wantErr bool
+	}{
+		{"connect only", `{"hostname":"broker","port":1883,"topic":"status"}`, false},
+		{"expected payload", `{"hostname":"broker","port":8883,"topic":"status","use_tls":true,"expected_payload":"online"}`, false},
+		{"payload check without expected payload", `{"hostname":"broker","port":1883,"topic":"status","check_type":"payload"}`, true},
+		{"keyword check without keyword", `{"hostname":"broker","port":1883,"topic":"status","check_type":"keyword"}`, true},
+		{"unknown check type", `{"hostname":"broker","port":1883,"topic":"status","check_type":"regex"}`, true},
+		{"missing topic", `{"hostname":"broker","port":1883}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := executor.Validate(tt.config)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestMQTTExecutor_Execute(t *testing.T) {
+	executor := NewMQTTExecutor(zap.NewNop().Sugar())
+
+	t.Run("connect and subscribe is up without expected payload", func(t *testing.T) {
+		broker := newFakeMQTTBroker(t, 0)
+
+		result := executor.Execute(context.Background(), mqttMonitor(t, MQTTConfig{Hostname: "127.0.0.1", Port: broker.port(), Topic: "devices/status"}), nil)
+
+		assert.Equal(t, shared.MonitorStatusUp, result.Status, result.Message)
+		assert.Contains(t, result.Message, "Subscribed")
+		assert.Regexp(t, `Connected in \d+ms`, result.Message)
+	})
+
+	t.Run("waits for the expected payload", func(t *testing.T) {
+		broker := newFakeMQTTBroker(t, 0, "starting", " online\n")
+
+		result := executor.Execute(context.Background(), mqttMonitor(t, MQTTConfig{Hostname: "127.0.0.1", Port: broker.port(), Topic: "devices/status", ExpectedPayload: "online"}), nil)
+
+		assert.Equal(t, shared.MonitorStatusUp, result.Status, result.Message)
+		assert.Contains(t, result.Message, "Expected payload received")
+	})
+
+	t.Run("other payloads time out", func(t *testing.T) {
+		broker := newFakeMQTTBroker(t, 0, "offline")
+
+		result := executor.Execute(context.Background(), mqttMonitor(t, MQTTConfig{Hostname: "127.0.0.1", Port: broker.port(), Topic: "devices/status", ExpectedPayload: "online"}), nil)
+
+		assert.Equal(t, shared.MonitorStatusDown, result.Status)
+		assert.Contains(t, result.Message, "no message matching the expected payload")
+	})
+
+	t.Run("rejected connection is down", func(t *testing.T) {
+		broker := newFakeMQTTBroker(t, 5) // not authorized
+
+		result := executor.Execute(context.Background(), mqttMonitor(t, MQTTConfig{Hostname: "127.0.0.1", Port: broker.port(), Topic: "devices/status", Username: "sensor", Password: "hunter2"}), nil)
+
+		assert.Equal(t, shared.MonitorStatusDown, result.Status)
+		assert.Contains(t, result.Message, "MQTT connection failed")
+	})
+
+	t.Run("credentials are not logged", func(t *testing.T) {
+		core, logs := observer.New(zapcore.DebugLevel)
+		executor := NewMQTTExecutor(zap.New(core).Sugar())
+		broker := newFakeMQTTBroker(t, 0)
+
+		result := executor.Execute(context.Background(), mqttMonitor(t, MQTTConfig{Hostname: "127.0.0.1", Port: broker.port(), Topic: "devices/status", Username: "sensor", Password: "hunter2"}), nil)
+
+		require.Equal(t, shared.MonitorStatusUp, result.Status, result.Message)
+		require.NotZero(t, logs.Len())
+		for _, entry := range logs.All() {
+			assert.NotContains(t, entry.Message, "hunter2")
+		}
+	})
+}
@@ -12,22 +12,42 @@ import (
 	"time"
 
 	"go.uber.org/zap"
-	"golang.org/x/net/icmp"
-	"golang.org/x/net/ipv4"
+)
+
+const (
+	PingModeICMP = "icmp"
+	PingModeTCP  = "tcp_ping"
 )
 
 type PingConfig struct {
 	Host       string `json:"host" validate:"required" example:"example.com"`
 	PacketSize int    `json:"packet_size" validate:"min=0,max=65507" example:"32"`
+	// Mode "icmp" (default) sends echo requests, "tcp_ping" measures the time to connect
+	// to Port instead, for networks blocking ICMP
+	Mode string `json:"ping_mode,omitempty" validate:"omitempty,oneof=icmp tcp_ping" example:"icmp"`
+	// Port is the TCP port connected to in "tcp_ping" mode
+	Port int `json:"port,omitempty" validate:"required_if=Mode tcp_ping,omitempty,min=1,max=65535" example:"443"`
+	// SourceIP is the local address the echo requests are sent from
+	SourceIP string `json:"source_ip,omitempty" validate:"omitempty,ip" example:"192.168.1.10"`
+	// Resolver is the "host:port" of the DNS server the host is resolved with instead of
+	// the system resolver
+	Resolver string `json:"resolver,omitempty" example:"10.0.0.53:53"`
+	// DNSCacheBypass resolves the host on every check instead of through the shared DNS cache
+	DNSCacheBypass bool `json:"dns_cache_bypass,omitempty"`
 }
 
 type PingExecutor struct {
 	logger *zap.SugaredLogger
+	// dnsCache is shared between executors, nil when caching is disabled
+	dnsCache *DNSCache
+	// pinger sends the native echo requests of all checks over shared sockets
+	pinger *icmpPinger
 }
 
 func NewPingExecutor(logger *zap.SugaredLogger) *PingExecutor {
 	return &PingExecutor{
 		logger: logger,
+		pinger: newICMPPinger(),
 	}
 }
 
@@ -40,7 +60,11 @@ func (s *PingExecutor) Validate(configJSON string) error {
 	if err != nil {
 		return err
 	}
-	return GenericValidator(cfg.(*PingConfig))
+	pingCfg := cfg.(*PingConfig)
+	if err := GenericValidator(pingCfg); err != nil {
+		return err
+	}
+	return validateResolver(pingCfg.Resolver)
 }
 
 func (p *PingExecutor) Execute(ctx context.Context, m *Monitor, proxyModel *Proxy) *Result {
@@ -59,13 +83,43 @@ func (p *PingExecutor) Execute(ctx context.Context, m *Monitor, proxyModel *Prox
 
 	startTime := time.Now().UTC()
 
+	if cfg.SourceIP != "" {
+		if _, err := localSourceIP(cfg.SourceIP); err != nil {
+			return DownResult(err, startTime, time.Now().UTC())
+		}
+	}
+
+	host := cfg.Host
+	if cfg.Resolver != "" {
+		// Both ping methods get the address so the system ping doesn't resolve the host itself
+		dst, err := p.resolve(ctx, host, newCustomResolver(cfg.Resolver, time.Duration(m.Timeout)*time.Second))
+		if err != nil {
+			return &Result{
+				Status:    shared.MonitorStatusDown,
+				Message:   fmt.Sprintf("Ping failed: failed to resolve host: %v", err),
+				StartTime: startTime,
+				EndTime:   time.Now().UTC(),
+			}
+		}
+		host = dst.IP.String()
+	}
+
+	if cfg.Mode == PingModeTCP {
+		return p.tcpPing(ctx, m, cfg, host)
+	}
+
+	var resolver HostResolver
+	if p.dnsCache != nil && !cfg.DNSCacheBypass {
+		resolver = p.dnsCache
+	}
+
 	// Try native ICMP first, fallback to system ping command
-	success, rtt, err := p.tryNativePing(ctx, cfg.Host, cfg.PacketSize, time.Duration(m.Timeout)*time.Second)
+	success, rtt, err := p.tryNativePing(ctx, host, resolver, cfg.SourceIP, cfg.PacketSize, time.Duration(m.Timeout)*time.Second)
 	if err != nil {
 		// Fallback to system ping command
 		p.logger.Debugf("Ping failed: %s, %s, %s", m.Name, err.Error(), "trying system ping")
 		startTime = time.Now().UTC() // reset start time
-		success, rtt, err = p.trySystemPing(ctx, cfg.Host, cfg.PacketSize, time.Duration(m.Timeout)*time.Second)
+		success, rtt, err = p.trySystemPing(ctx, host, cfg.SourceIP, cfg.PacketSize, time.Duration(m.Timeout)*time.Second)
 	}
 
 	endTime := time.Now().UTC()
@@ -99,93 +153,125 @@ func (p *PingExecutor) Execute(ctx context.Context, m *Monitor, proxyModel *Prox
 	}
 }
 
-// tryNativePing attempts to use native ICMP implementation
-func (p *PingExecutor) tryNativePing(ctx context.Context, host string, packetSize int, timeout time.Duration) (bool, time.Duration, error) {
-	// Resolve the host
-	dst, err := net.ResolveIPAddr("ip4", host)
-	if err != nil {
-		return false, 0, fmt.Errorf("failed to resolve host: %v", err)
+// tcpPing measures the time to establish a TCP connection to the port, closing it right
+// away. The result spans the connect only, so the ping is the connect RTT.
+func (p *PingExecutor) tcpPing(ctx context.Context, m *Monitor, cfg *PingConfig, host string) *Result {
+	startTime := time.Now().UTC()
+
+	if cfg.Resolver == "" && p.dnsCache != nil && !cfg.DNSCacheBypass {
+		ips, err := p.dnsCache.LookupIP(ctx, "ip", host)
+		if err != nil {
+			return &Result{
+				Status:        shared.MonitorStatusDown,
+				Message:       fmt.Sprintf("TCP ping failed: failed to resolve host: %v", err),
+				StartTime:     startTime,
+				EndTime:       time.Now().UTC(),
+				ErrorCategory: shared.ErrorCategoryNetwork,
+			}
+		}
+		host = ips[0].String()
 	}
 
-	// Try to open raw socket for ICMP
-	conn, err := icmp.ListenPacket("ip4:icmp", "0.0.0.0")
+	dialer, err := sourceDialer(time.Duration(m.Timeout)*time.Second, cfg.SourceIP)
 	if err != nil {
-		return false, 0, fmt.Errorf("failed to create ICMP socket (try running as root): %v", err)
+		return DownResult(err, startTime, time.Now().UTC())
 	}
-	defer conn.Close()
 
-	// Set timeout
-	deadline := time.Now().Add(timeout)
-	conn.SetDeadline(deadline)
+	startTime = time.Now().UTC()
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, strconv.Itoa(cfg.Port)))
+	endTime := time.Now().UTC()
 
-	// Create ICMP message with custom data size
-	// packetSize represents the data payload size (like ping -s flag)
-	dataSize := packetSize
-	if dataSize < 0 {
-		dataSize = 0
+	if err != nil {
+		_, category := ClassifyTCPDialError(err)
+		p.logger.Infof("TCP ping failed: %s, %s", m.Name, err.Error())
+		return &Result{
+			Status:        shared.MonitorStatusDown,
+			Message:       fmt.Sprintf("TCP ping failed: %v", err),
+			StartTime:     startTime,
+			EndTime:       endTime,
+			ErrorCategory: category,
+		}
 	}
-	data := make([]byte, dataSize)
-	copy(data, []byte("Peekaping"))
+	conn.Close()
 
-	p.logger.Debugf("Native ping: host=%s, dataSize=%d, totalPacketSize=%d", host, dataSize, dataSize+8)
+	rtt := endTime.Sub(startTime)
+	p.logger.Infof("TCP ping successful: %s, RTT: %v", m.Name, rtt)
 
-	msg := &icmp.Message{
-		Type: ipv4.ICMPTypeEcho,
-		Code: 0,
-		Body: &icmp.Echo{
-			ID:   1,
-			Seq:  1,
-			Data: data,
-		},
+	return &Result{
+		Status:    shared.MonitorStatusUp,
+		Message:   fmt.Sprintf("TCP ping to port %d successful, RTT: %v", cfg.Port, rtt),
+		StartTime: startTime,
+		EndTime:   endTime,
 	}
+}
 
-	msgBytes, err := msg.Marshal(nil)
+// resolve returns the IPv4 address of host through resolver when set, otherwise from
+// the system resolver
+func (p *PingExecutor) resolve(ctx context.Context, host string, resolver HostResolver) (*net.IPAddr, error) {
+	if resolver == nil {
+		return net.ResolveIPAddr("ip4", host)
+	}
+	ips, err := resolver.LookupIP(ctx, "ip4", host)
 	if err != nil {
-		return false, 0, fmt.Errorf("failed to marshal ICMP message: %v", err)
+		return nil, err
 	}
+	return &net.IPAddr{IP: ips[0]}, nil
+}
 
-	start := time.Now()
-	_, err = conn.WriteTo(msgBytes, dst)
+// tryNativePing attempts to use native ICMP implementation
+func (p *PingExecutor) tryNativePing(ctx context.Context, host string, resolver HostResolver, sourceIP string, packetSize int, timeout time.Duration) (bool, time.Duration, error) {
+	// Resolve the host
+	dst, err := p.resolve(ctx, host, resolver)
 	if err != nil {
-		return false, 0, fmt.Errorf("failed to send ICMP packet: %v", err)
+		return false, 0, fmt.Errorf("failed to resolve host: %v", err)
 	}
 
-	// Read response
-	reply := make([]byte, 1500)
-	n, peer, err := conn.ReadFrom(reply)
-	if err != nil {
-		return false, 0, fmt.Errorf("failed to read ICMP reply: %v", err)
+	// The raw ICMP socket is bound to the source IP when configured
+	listenAddr := "0.0.0.0"
+	if sourceIP != "" {
+		listenAddr = sourceIP
 	}
-	rtt := time.Since(start)
 
-	// Parse the reply - protocol 1 for IPv4 ICMP
-	replyMsg, err := icmp.ParseMessage(1, reply[:n])
-	if err != nil {
-		return false, 0, fmt.Errorf("failed to parse ICMP reply: %v", err)
+	// Create ICMP message with custom data size
+	// packetSize represents the data payload size (like ping -s flag)
+	dataSize := packetSize
+	if dataSize < 0 {
+		dataSize = 0
 	}
+	data := make([]byte, dataSize)
+	copy(data, []byte("Peekaping"))
 
-	if replyMsg.Type == ipv4.ICMPTypeEchoReply {
-		p.logger.Debugf("Received ICMP reply from %v", peer)
-		return true, rtt, nil
+	p.logger.Debugf("Native ping: host=%s, dataSize=%d, totalPacketSize=%d", host, dataSize, dataSize+8)
+
+	rtt, err := p.pinger.Ping(ctx, listenAddr, dst, data, timeout)
+	if err != nil {
+		return false, 0, err
 	}
 
-	return false, 0, fmt.Errorf("unexpected ICMP message type: %v", replyMsg.Type)
+	p.logger.Debugf("Received ICMP reply from %v", dst)
+	return true, rtt, nil
 }
 
 // trySystemPing falls back to using the system ping command
-func (p *PingExecutor) trySystemPing(ctx context.Context, host string, packetSize int, timeout time.Duration) (bool, time.Duration, error) {
-	var cmd *exec.Cmd
+func (p *PingExecutor) trySystemPing(ctx context.Context, host string, sourceIP string, packetSize int, timeout time.Duration) (bool, time.Duration, error) {
+	var args []string
+	sourceFlag := "-S"
 
 	p.logger.Debugf("System ping: host=%s, dataSize=%d, totalPacketSize=%d", host, packetSize, packetSize+8)
 
 	switch runtime.GOOS {
 	case "windows":
-		cmd = exec.CommandContext(ctx, "ping", "-n", "1", "-l", strconv.Itoa(packetSize), "-w", strconv.Itoa(int(timeout.Milliseconds())), host)
+		args = []string{"-n", "1", "-l", strconv.Itoa(packetSize), "-w", strconv.Itoa(int(timeout.Milliseconds()))}
 	case "darwin":
-		cmd = exec.CommandContext(ctx, "ping", "-c", "1", "-s", strconv.Itoa(packetSize), "-W", strconv.Itoa(int(timeout.Milliseconds())), host)
+		args = []string{"-c", "1", "-s", strconv.Itoa(packetSize), "-W", strconv.Itoa(int(timeout.Milliseconds()))}
 	default: // linux and others
-		cmd = exec.CommandContext(ctx, "ping", "-c", "1", "-s", strconv.Itoa(packetSize), "-W", strconv.Itoa(int(timeout.Seconds())), host)
+		args = []string{"-c", "1", "-s", strconv.Itoa(packetSize), "-W", strconv.Itoa(int(timeout.Seconds()))}
+		sourceFlag = "-I"
+	}
+	if sourceIP != "" {
+		args = append(args, sourceFlag, sourceIP)
 	}
+	cmd := exec.CommandContext(ctx, "ping", append(args, host)...)
 
 	start := time.Now()
 	output, err := cmd.Output()
@@ -0,0 +1,105 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"peekaping/internal/modules/shared"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestPingExecutor_Validate(t *testing.T) {
+	executor := NewPingExecutor(zap.NewNop().Sugar())
+
+	tests := []struct {
+		name          string
+		config        string
+		expectedError bool
+	}{
+		{name: "default mode", config: `{"host":"example.com","packet_size":32}`},
+		{name: "icmp mode", config: `{"host":"example.com","packet_size":32,"ping_mode":"icmp"}`},
+		{name: "tcp ping", config: `{"host":"example.com","packet_size":32,"ping_mode":"tcp_ping","port":443}`},
+		{name: "tcp ping without port", config: `{"host":"example.com","packet_size":32,"ping_mode":"tcp_ping"}`, expectedError: true},
+		{name: "tcp ping with invalid port", config: `{"host":"example.com","packet_size":32,"ping_mode":"tcp_ping","port":70000}`, expectedError: true},
+		{name: "unknown mode", config: `{"host":"example.com","packet_size":32,"ping_mode":"udp"}`, expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := executor.Validate(tt.config)
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPingExecutor_Execute_TCPPing(t *testing.T) {
+	executor := NewPingExecutor(zap.NewNop().Sugar())
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	openPort := listener.Addr().(*net.TCPAddr).Port
+	defer listener.Close()
+
+	// Grab a free port and release it so that connecting to it is refused
+	closedListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closedPort := closedListener.Addr().(*net.TCPAddr).Port
+	closedListener.Close()
+
+	newMonitor := func(port int) *Monitor {
+		return &Monitor{
+			ID:      "monitor1",
+			Name:    "ping",
+			Type:    "ping",
+			Timeout: 2,
+			Config:  fmt.Sprintf(`{"host":"127.0.0.1","packet_size":32,"ping_mode":"tcp_ping","port":%d}`, port),
+		}
+	}
+
+	t.Run("reports the connect rtt", func(t *testing.T) {
+		before := time.Now().UTC()
+		result := executor.Execute(context.Background(), newMonitor(openPort), nil)
+		after := time.Now().UTC()
+
+		require.NotNil(t, result)
+		assert.Equal(t, shared.MonitorStatusUp, result.Status, result.Message)
+		assert.Empty(t, result.ErrorCategory)
+
+		// The result spans the connect only and the message carries the same duration
+		rtt := result.EndTime.Sub(result.StartTime)
+		assert.False(t, result.StartTime.Before(before))
+		assert.False(t, result.EndTime.After(after))
+		assert.Positive(t, rtt)
+		assert.Equal(t, fmt.Sprintf("TCP ping to port %d successful, RTT: %v", openPort, rtt), result.Message)
+	})
+
+	t.Run("closed port", func(t *testing.T) {
+		result := executor.Execute(context.Background(), newMonitor(closedPort), nil)
+
+		require.NotNil(t, result)
+		assert.Equal(t, shared.MonitorStatusDown, result.Status)
+		assert.True(t, strings.HasPrefix(result.Message, "TCP ping failed: "), result.Message)
+		assert.Equal(t, shared.ErrorCategoryPortClosed, result.ErrorCategory)
+	})
+
+	t.Run("no answer before the timeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 0)
+		defer cancel()
+
+		result := executor.Execute(ctx, newMonitor(openPort), nil)
+
+		require.NotNil(t, result)
+		assert.Equal(t, shared.MonitorStatusDown, result.Status)
+		assert.Equal(t, shared.ErrorCategoryPortFiltered, result.ErrorCategory)
+	})
+}
@@ -0,0 +1,92 @@
+package executor
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// maxPooledProxyTransports bounds the transports a pool keeps, the pool starts over once
+// it is reached
+const maxPooledProxyTransports = 256
+
+// ProxyTransportPool keeps one transport per proxy so checks going through the same proxy
+// reuse its connections instead of opening new ones on every check
+type ProxyTransportPool struct {
+	mu           sync.Mutex
+	transports   map[string]http.RoundTripper
+	maxIdleConns int
+	idleTimeout  time.Duration
+}
+
+func NewProxyTransportPool(maxIdleConns int, idleTimeout time.Duration) *ProxyTransportPool {
+	return &ProxyTransportPool{
+		transports:   make(map[string]http.RoundTripper),
+		maxIdleConns: maxIdleConns,
+		idleTimeout:  idleTimeout,
+	}
+}
+
+// Transport returns the pooled transport for the proxy, creating it on first use. Checks
+// only share a transport when their TLS verification and source IP are the same.
+func (p *ProxyTransportPool) Transport(proxyModel *Proxy, ignoreTlsErrors bool, sourceIP string) (http.RoundTripper, error) {
+	key := proxyTransportKey(proxyModel, ignoreTlsErrors, sourceIP)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if transport, ok := p.transports[key]; ok {
+		return transport, nil
+	}
+
+	dialer, err := sourceDialer(0, sourceIP)
+	if err != nil {
+		return nil, err
+	}
+	base := &http.Transport{
+		MaxIdleConnsPerHost: p.maxIdleConns,
+		IdleConnTimeout:     p.idleTimeout,
+	}
+	if sourceIP != "" {
+		base.DialContext = dialer.DialContext
+	}
+	if ignoreTlsErrors {
+		base.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	transport := buildProxyTransport(base, proxyModel)
+
+	if len(p.transports) >= maxPooledProxyTransports {
+		p.closeIdleConnections()
+		p.transports = make(map[string]http.RoundTripper)
+	}
+	p.transports[key] = transport
+	return transport, nil
+}
+
+// Size returns the number of pooled transports
+func (p *ProxyTransportPool) Size() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.transports)
+}
+
+func (p *ProxyTransportPool) closeIdleConnections() {
+	for _, transport := range p.transports {
+		if t, ok := transport.(*http.Transport); ok {
+			t.CloseIdleConnections()
+		}
+	}
+}
+
+// proxyTransportKey identifies a proxy and the transport settings. Credentials are part
+// of the key, hashed so they aren't kept in it as plain text.
+func proxyTransportKey(proxyModel *Proxy, ignoreTlsErrors bool, sourceIP string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%d\x00%t\x00%s\x00%s\x00%t\x00%s",
+		proxyModel.Protocol, proxyModel.Host, proxyModel.Port, proxyModel.Auth,
+		proxyModel.Username, proxyModel.Password, ignoreTlsErrors, sourceIP)))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,112 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"peekaping/internal/modules/shared"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newForwardProxy serves a plain HTTP forward proxy and counts the connections clients
+// open to it
+func newForwardProxy(t *testing.T) (*Proxy, *atomic.Int32) {
+	var connections atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		outReq, _ := http.NewRequestWithContext(r.Context(), r.Method, r.URL.String(), r.Body)
+		resp, err := http.DefaultTransport.RoundTrip(outReq)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		defer resp.Body.Close()
+		w.WriteHeader(resp.StatusCode)
+		io.Copy(w, resp.Body)
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			connections.Add(1)
+		}
+	}
+	server.Start()
+	t.Cleanup(server.Close)
+
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(u.Port())
+	require.NoError(t, err)
+	return &Proxy{ID: "proxy-1", Protocol: "http", Host: u.Hostname(), Port: port}, &connections
+}
+
+func TestHTTPExecutor_ProxyPoolReusesConnections(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+	defer target.Close()
+
+	monitor := &Monitor{
+		ID:       "test-monitor",
+		Type:     "http",
+		Name:     "Test Monitor",
+		Interval: 30,
+		Timeout:  5,
+		Config:   fmt.Sprintf(`{"url": %q, "method": "GET", "encoding": "json", "accepted_statuscodes": ["2XX"], "authMethod": "none"}`, target.URL),
+	}
+
+	tests := []struct {
+		name                string
+		pool                *ProxyTransportPool
+		expectedConnections int32
+	}{
+		{"pooled", NewProxyTransportPool(4, time.Minute), 1},
+		{"not pooled", nil, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proxyModel, connections := newForwardProxy(t)
+			executor := NewHTTPExecutor(zap.NewNop().Sugar())
+			executor.proxyPool = tt.pool
+
+			for range 3 {
+				result := executor.Execute(context.Background(), monitor, proxyModel)
+				require.Equal(t, shared.MonitorStatusUp, result.Status, result.Message)
+			}
+
+			assert.Equal(t, tt.expectedConnections, connections.Load())
+		})
+	}
+}
+
+func TestProxyTransportPool_Transport(t *testing.T) {
+	pool := NewProxyTransportPool(4, time.Minute)
+	proxyModel := &Proxy{Protocol: "http", Host: "proxy.internal", Port: 3128}
+
+	first, err := pool.Transport(proxyModel, false, "")
+	require.NoError(t, err)
+	second, err := pool.Transport(&Proxy{Protocol: "http", Host: "proxy.internal", Port: 3128}, false, "")
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+
+	insecure, err := pool.Transport(proxyModel, true, "")
+	require.NoError(t, err)
+	assert.NotSame(t, first, insecure)
+
+	authenticated, err := pool.Transport(&Proxy{Protocol: "http", Host: "proxy.internal", Port: 3128, Auth: true, Username: "user", Password: "secret"}, false, "")
+	require.NoError(t, err)
+	assert.NotSame(t, first, authenticated)
+
+	assert.Equal(t, 3, pool.Size())
+}
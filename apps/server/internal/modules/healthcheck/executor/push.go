@@ -10,6 +10,14 @@ import (
 
 type PushConfig struct {
 	PushToken string `json:"pushToken" validate:"required"`
+	// GracePeriod is how many seconds past the interval a push may be late before the
+	// monitor is considered silent
+	GracePeriod int `json:"grace_period,omitempty" validate:"omitempty,min=0"`
+}
+
+// MaxGap is the longest acceptable time between two pushes
+func (c *PushConfig) MaxGap(interval int) time.Duration {
+	return time.Duration(interval+c.GracePeriod) * time.Second
 }
 
 type PushExecutor struct {
@@ -41,12 +49,17 @@ func (s *PushExecutor) Execute(ctx context.Context, m *Monitor, proxyModel *Prox
 	var status shared.MonitorStatus
 	var message string
 
+	maxGap := time.Duration(m.Interval) * time.Second
+	if cfg, err := GenericUnmarshal[PushConfig](m.Config); err == nil {
+		maxGap = cfg.MaxGap(m.Interval)
+	}
+
 	if m.LastHeartbeat != nil {
 		s.logger.Infof("Latest heartbeat: %v", m.LastHeartbeat)
 		timeSince := time.Since(m.LastHeartbeat.Time)
 		s.logger.Infof("Time since last heartbeat: %v", timeSince)
 
-		if m.LastHeartbeat.Status == 1 && timeSince <= time.Duration(m.Interval)*time.Second {
+		if m.LastHeartbeat.Status == 1 && timeSince <= maxGap {
 			s.logger.Infof("Push received in time")
 			return nil
 		} else {
@@ -61,9 +74,10 @@ func (s *PushExecutor) Execute(ctx context.Context, m *Monitor, proxyModel *Prox
 	}
 
 	return &Result{
-		Status:    status,
-		Message:   message,
-		StartTime: startTime,
-		EndTime:   endTime,
+		Status:        status,
+		Message:       message,
+		StartTime:     startTime,
+		EndTime:       endTime,
+		ErrorCategory: shared.ErrorCategoryNoHeartbeat,
 	}
 }
@@ -2,12 +2,17 @@ package executor
 
 import (
 	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http/httptest"
 	"net/url"
 	"peekaping/internal/modules/shared"
 	"testing"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
 
@@ -688,3 +693,152 @@ MIGfMA0GCSqGSIb3DQEBAQUAA4GNADCBiQKBgQC7
 		})
 	}
 }
+
+func TestRedisExecutor_Validate_HostAndCommand(t *testing.T) {
+	executor := NewRedisExecutor(zap.NewNop().Sugar())
+
+	tests := []struct {
+		name      string
+		config    string
+		wantError bool
+	}{
+		{"host only", `{"host": "localhost"}`, false},
+		{"host with options", `{"host": "redis.internal", "port": 6380, "password": "secret", "db": 2, "use_tls": true, "ignore_tls_errors": true}`, false},
+		{"custom command", `{"host": "localhost", "command": "GET \"health:status\"", "expected_response": "ok"}`, false},
+		{"config get", `{"host": "localhost", "command": "CONFIG GET maxmemory"}`, false},
+		{"read-only command", `{"host": "localhost", "command": "info replication"}`, false},
+		{"no host or connection string", `{"port": 6379}`, true},
+		{"invalid host", `{"host": "bad host"}`, true},
+		{"invalid port", `{"host": "localhost", "port": 70000}`, true},
+		{"destructive command", `{"host": "localhost", "command": "flushall"}`, true},
+		{"config set", `{"host": "localhost", "command": "CONFIG SET maxmemory 1"}`, true},
+		{"write command", `{"host": "localhost", "command": "INCR counter"}`, true},
+		{"blocking command", `{"host": "localhost", "command": "BLMPOP 0 1 queue LEFT"}`, true},
+		{"unknown command", `{"host": "localhost", "command": "SOMEMODULE.CMD key"}`, true},
+		{"unterminated quote", `{"host": "localhost", "command": "GET \"key"}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := executor.Validate(tt.config)
+			if tt.wantError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSplitRedisCommand(t *testing.T) {
+	args, err := splitRedisCommand(`HGET  "user:1 profile" 'display name'`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"HGET", "user:1 profile", "display name"}, args)
+
+	args, err = splitRedisCommand(`GET ""`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"GET", ""}, args)
+}
+
+func redisHostMonitor(t *testing.T, server *miniredis.Miniredis, extra string) *Monitor {
+	config := fmt.Sprintf(`{"host": %q, "port": %s%s}`, server.Host(), server.Port(), extra)
+	require.NoError(t, NewRedisExecutor(zap.NewNop().Sugar()).Validate(config))
+	return &Monitor{ID: "test-monitor", Name: "Test Redis Monitor", Type: "redis", Timeout: 5, Config: config}
+}
+
+func TestRedisExecutor_Execute_HostConfig(t *testing.T) {
+	server := miniredis.RunT(t)
+	server.RequireAuth("secret")
+	server.Select(1)
+	server.Set("health:status", "ok")
+	server.Select(0)
+
+	executor := NewRedisExecutor(zap.NewNop().Sugar())
+
+	tests := []struct {
+		name            string
+		extra           string
+		expectedStatus  shared.MonitorStatus
+		expectedMessage string
+	}{
+		{
+			name:            "ping",
+			extra:           `, "password": "secret"`,
+			expectedStatus:  shared.MonitorStatusUp,
+			expectedMessage: "Redis ping successful: PONG, ping: ",
+		},
+		{
+			name:            "wrong password",
+			extra:           `, "password": "wrong"`,
+			expectedStatus:  shared.MonitorStatusDown,
+			expectedMessage: "Redis ping failed: WRONGPASS",
+		},
+		{
+			name:            "missing password",
+			expectedStatus:  shared.MonitorStatusDown,
+			expectedMessage: "Redis ping failed: NOAUTH",
+		},
+		{
+			name:            "command in another db",
+			extra:           `, "password": "secret", "db": 1, "command": "GET health:status", "expected_response": "ok"`,
+			expectedStatus:  shared.MonitorStatusUp,
+			expectedMessage: "Redis command GET successful: ok, ping: ",
+		},
+		{
+			name:            "unexpected response",
+			extra:           `, "password": "secret", "command": "GET health:status", "expected_response": "ok"`,
+			expectedStatus:  shared.MonitorStatusDown,
+			expectedMessage: `Redis GET returned "(nil)", expected "ok"`,
+		},
+		{
+			name:            "command error",
+			extra:           `, "password": "secret", "command": "HGET health:status"`,
+			expectedStatus:  shared.MonitorStatusDown,
+			expectedMessage: "Redis command HGET failed: ERR wrong number of arguments",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := executor.Execute(context.Background(), redisHostMonitor(t, server, tt.extra), nil)
+
+			assert.Equal(t, tt.expectedStatus, result.Status, result.Message)
+			assert.Contains(t, result.Message, tt.expectedMessage)
+		})
+	}
+}
+
+func TestRedisExecutor_Execute_ConnectionRefused(t *testing.T) {
+	server := miniredis.RunT(t)
+	monitor := redisHostMonitor(t, server, "")
+	server.Close()
+
+	result := NewRedisExecutor(zap.NewNop().Sugar()).Execute(context.Background(), monitor, nil)
+
+	assert.Equal(t, shared.MonitorStatusDown, result.Status)
+	assert.Contains(t, result.Message, "Redis ping failed")
+}
+
+func TestRedisExecutor_Execute_UseTLS(t *testing.T) {
+	// Borrow the self-signed certificate of an httptest server
+	tlsServer := httptest.NewTLSServer(nil)
+	certificate := tlsServer.TLS.Certificates[0]
+	tlsServer.Close()
+
+	server, err := miniredis.RunTLS(&tls.Config{Certificates: []tls.Certificate{certificate}})
+	require.NoError(t, err)
+	defer server.Close()
+
+	executor := NewRedisExecutor(zap.NewNop().Sugar())
+
+	result := executor.Execute(context.Background(), redisHostMonitor(t, server, `, "use_tls": true, "ignore_tls_errors": true`), nil)
+	assert.Equal(t, shared.MonitorStatusUp, result.Status, result.Message)
+
+	result = executor.Execute(context.Background(), redisHostMonitor(t, server, `, "use_tls": true`), nil)
+	assert.Equal(t, shared.MonitorStatusDown, result.Status)
+	assert.Contains(t, result.Message, "certificate")
+
+	connectionString := fmt.Sprintf(`{"databaseConnectionString": "rediss://%s", "ignore_tls_errors": true}`, server.Addr())
+	result = executor.Execute(context.Background(), &Monitor{Type: "redis", Timeout: 5, Config: connectionString}, nil)
+	assert.Equal(t, shared.MonitorStatusUp, result.Status, result.Message)
+}
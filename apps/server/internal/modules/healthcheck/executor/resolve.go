@@ -0,0 +1,143 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"peekaping/internal/modules/shared"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HostResolver resolves a host name to its IP addresses, *net.Resolver satisfies it
+type HostResolver interface {
+	LookupIP(ctx context.Context, network, host string) ([]net.IP, error)
+}
+
+// BackendResult is the outcome of checking a single resolved address of a host
+type BackendResult struct {
+	IP      string
+	Up      bool
+	Message string
+	// ErrorCategory classifies the failure, see shared.ErrorCategory* constants
+	ErrorCategory string
+}
+
+// validateResolver checks that a monitor's resolver is a "host:port" address
+func validateResolver(address string) error {
+	if address == "" {
+		return nil
+	}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil || host == "" {
+		return fmt.Errorf("resolver must be a host:port address, e.g. 10.0.0.53:53")
+	}
+	if p, err := strconv.Atoi(port); err != nil || p < 1 || p > 65535 {
+		return fmt.Errorf("resolver port must be between 1 and 65535")
+	}
+	return nil
+}
+
+// newCustomResolver returns a resolver that sends its queries to the DNS server at
+// address over UDP or TCP instead of the system resolver, for split-horizon DNS where
+// the target resolves differently from the monitoring host
+func newCustomResolver(address string, timeout time.Duration) *net.Resolver {
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			dialer := &net.Dialer{Timeout: timeout}
+			return dialer.DialContext(ctx, network, address)
+		},
+	}
+}
+
+// resolveHost resolves the host on every call, bypassing any cached addresses.
+// IP literals are returned as is.
+func resolveHost(ctx context.Context, resolver HostResolver, host string) ([]net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IP{ip}, nil
+	}
+
+	ips, err := resolver.LookupIP(ctx, "ip", host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", host, err)
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("no addresses found for %s", host)
+	}
+	return ips, nil
+}
+
+// pinnedDialContext returns a dial function that connects to the given IP whenever the
+// dialed address targets host, so every request of a check reaches the same backend.
+// Other hosts (e.g. redirect targets) are dialed normally.
+func pinnedDialContext(dialer *net.Dialer, host string, ip net.IP) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		addrHost, port, err := net.SplitHostPort(addr)
+		if err != nil || !strings.EqualFold(addrHost, host) {
+			return dialer.DialContext(ctx, network, addr)
+		}
+		return dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+	}
+}
+
+// checkBackends runs check against every address at the same time, so each one gets the
+// whole monitor timeout, and returns the results in the order of the addresses
+func checkBackends(ips []net.IP, check func(ip net.IP) *Result) []*Result {
+	results := make([]*Result, len(ips))
+	var wg sync.WaitGroup
+	for i, ip := range ips {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = check(ip)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// summarizeBackends folds the per-address results into a single check result. The monitor
+// is up when every backend is up, degraded (up with the degraded error category) when only
+// some fail and down when none respond. The message lists the result of every address.
+func summarizeBackends(results []BackendResult, startTime, endTime time.Time) *Result {
+	var failed []BackendResult
+	parts := make([]string, 0, len(results))
+	for _, r := range results {
+		state := "up"
+		if !r.Up {
+			state = "down"
+			failed = append(failed, r)
+		}
+		parts = append(parts, fmt.Sprintf("%s %s (%s)", r.IP, state, r.Message))
+	}
+	details := strings.Join(parts, "; ")
+
+	switch {
+	case len(failed) == 0:
+		return &Result{
+			Status:    shared.MonitorStatusUp,
+			Message:   fmt.Sprintf("All %d backends up: %s", len(results), details),
+			StartTime: startTime,
+			EndTime:   endTime,
+		}
+	case len(failed) < len(results):
+		return &Result{
+			Status:        shared.MonitorStatusUp,
+			Message:       fmt.Sprintf("Degraded: %d of %d backends down: %s", len(failed), len(results), details),
+			StartTime:     startTime,
+			EndTime:       endTime,
+			ErrorCategory: shared.ErrorCategoryDegraded,
+		}
+	default:
+		return &Result{
+			Status:        shared.MonitorStatusDown,
+			Message:       fmt.Sprintf("All %d backends down: %s", len(results), details),
+			StartTime:     startTime,
+			EndTime:       endTime,
+			ErrorCategory: failed[0].ErrorCategory,
+		}
+	}
+}
@@ -0,0 +1,312 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"peekaping/internal/modules/shared"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// staticResolver resolves every host to a fixed list of addresses
+type staticResolver struct {
+	ips []net.IP
+	err error
+}
+
+func (r staticResolver) LookupIP(ctx context.Context, network, host string) ([]net.IP, error) {
+	return r.ips, r.err
+}
+
+// Only 127.0.0.1 has a listener in these tests, other loopback addresses refuse connections
+var (
+	liveBackend = net.ParseIP("127.0.0.1")
+	deadBackend = net.ParseIP("127.0.0.2")
+)
+
+func TestTCPExecutor_Execute_CheckAllIPs(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	monitor := &Monitor{
+		ID:      "tcp-lb",
+		Name:    "Load balanced TCP",
+		Type:    "tcp",
+		Timeout: 2,
+		Config:  fmt.Sprintf(`{"host":"backend.test","port":%d,"check_all_ips":true}`, port),
+	}
+
+	t.Run("one backend down marks the monitor degraded", func(t *testing.T) {
+		executor := NewTCPExecutor(zap.NewNop().Sugar())
+		executor.resolver = staticResolver{ips: []net.IP{liveBackend, deadBackend}}
+
+		result := executor.Execute(context.Background(), monitor, nil)
+
+		require.NotNil(t, result)
+		assert.Equal(t, shared.MonitorStatusUp, result.Status)
+		assert.Equal(t, shared.ErrorCategoryDegraded, result.ErrorCategory)
+		assert.Contains(t, result.Message, "Degraded: 1 of 2 backends down")
+		assert.Contains(t, result.Message, fmt.Sprintf("127.0.0.1 up (TCP port %d is open)", port))
+		assert.Contains(t, result.Message, "127.0.0.2 down")
+	})
+
+	t.Run("all backends up", func(t *testing.T) {
+		executor := NewTCPExecutor(zap.NewNop().Sugar())
+		executor.resolver = staticResolver{ips: []net.IP{liveBackend}}
+
+		result := executor.Execute(context.Background(), monitor, nil)
+
+		assert.Equal(t, shared.MonitorStatusUp, result.Status)
+		assert.Empty(t, result.ErrorCategory)
+		assert.Contains(t, result.Message, "All 1 backends up")
+	})
+
+	t.Run("all backends down", func(t *testing.T) {
+		executor := NewTCPExecutor(zap.NewNop().Sugar())
+		executor.resolver = staticResolver{ips: []net.IP{deadBackend, net.ParseIP("127.0.0.3")}}
+
+		result := executor.Execute(context.Background(), monitor, nil)
+
+		assert.Equal(t, shared.MonitorStatusDown, result.Status)
+		assert.Equal(t, shared.ErrorCategoryPortClosed, result.ErrorCategory)
+		assert.Contains(t, result.Message, "All 2 backends down")
+	})
+
+	t.Run("resolution failure", func(t *testing.T) {
+		executor := NewTCPExecutor(zap.NewNop().Sugar())
+		executor.resolver = staticResolver{err: errors.New("no such host")}
+
+		result := executor.Execute(context.Background(), monitor, nil)
+
+		assert.Equal(t, shared.MonitorStatusDown, result.Status)
+		assert.Equal(t, shared.ErrorCategoryNetwork, result.ErrorCategory)
+		assert.Contains(t, result.Message, "failed to resolve backend.test")
+	})
+}
+
+func TestHTTPExecutor_Execute_ResolvedBackends(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	port := server.Listener.Addr().(*net.TCPAddr).Port
+
+	newMonitor := func(options string) *Monitor {
+		return &Monitor{
+			ID:      "http-lb",
+			Name:    "Load balanced HTTP",
+			Type:    "http",
+			Timeout: 2,
+			Config: fmt.Sprintf(`{
+				"url": "http://backend.test:%d/health",
+				"method": "GET",
+				"encoding": "json",
+				"accepted_statuscodes": ["2XX"],
+				"authMethod": "none",
+				%s
+			}`, port, options),
+		}
+	}
+
+	t.Run("one backend down marks the monitor degraded", func(t *testing.T) {
+		executor := NewHTTPExecutor(zap.NewNop().Sugar())
+		executor.resolver = staticResolver{ips: []net.IP{liveBackend, deadBackend}}
+
+		result := executor.Execute(context.Background(), newMonitor(`"check_all_ips": true`), nil)
+
+		require.NotNil(t, result)
+		assert.Equal(t, shared.MonitorStatusUp, result.Status)
+		assert.Equal(t, shared.ErrorCategoryDegraded, result.ErrorCategory)
+		assert.Contains(t, result.Message, "Degraded: 1 of 2 backends down")
+		assert.Contains(t, result.Message, "127.0.0.1 up (200 - 200 OK)")
+		assert.Contains(t, result.Message, "127.0.0.2 down")
+	})
+
+	t.Run("re-resolve sends the request to the resolved address", func(t *testing.T) {
+		executor := NewHTTPExecutor(zap.NewNop().Sugar())
+		executor.resolver = staticResolver{ips: []net.IP{liveBackend}}
+
+		result := executor.Execute(context.Background(), newMonitor(`"re_resolve": true`), nil)
+
+		assert.Equal(t, shared.MonitorStatusUp, result.Status)
+		assert.Equal(t, "200 - 200 OK", result.Message)
+	})
+}
+
+func TestHTTPExecutor_Execute_ResolvedBackendsUseOwnAddress(t *testing.T) {
+	// The wildcard listener accepts every loopback address and answers with a status
+	// telling which one was dialed, e.g. 203 for 127.0.0.3
+	listener, err := net.Listen("tcp", "0.0.0.0:0")
+	require.NoError(t, err)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		addr := r.Context().Value(http.LocalAddrContextKey).(net.Addr)
+		w.WriteHeader(200 + int(addr.(*net.TCPAddr).IP.To4()[3]))
+	}))
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+
+	ips := []net.IP{net.ParseIP("127.0.0.1"), net.ParseIP("127.0.0.2"), net.ParseIP("127.0.0.3"), net.ParseIP("127.0.0.4")}
+	executor := NewHTTPExecutor(zap.NewNop().Sugar())
+	executor.resolver = staticResolver{ips: ips}
+	monitor := &Monitor{
+		ID:      "http-lb",
+		Name:    "Load balanced HTTP",
+		Type:    "http",
+		Timeout: 2,
+		Config: fmt.Sprintf(`{
+			"url": "http://backend.test:%d/health",
+			"method": "GET",
+			"encoding": "json",
+			"accepted_statuscodes": ["2XX"],
+			"authMethod": "none",
+			"check_all_ips": true
+		}`, listener.Addr().(*net.TCPAddr).Port),
+	}
+
+	// Run it a few times, a shared client only mixes the backends up now and then
+	for range 10 {
+		result := executor.Execute(context.Background(), monitor, nil)
+
+		require.NotNil(t, result)
+		assert.Equal(t, shared.MonitorStatusUp, result.Status)
+		for _, ip := range ips {
+			assert.Contains(t, result.Message, fmt.Sprintf("%s up (%d", ip, 200+int(ip.To4()[3])))
+		}
+	}
+}
+
+func TestCheckBackends(t *testing.T) {
+	ips := []net.IP{net.ParseIP("10.0.0.1"), net.ParseIP("10.0.0.2"), net.ParseIP("10.0.0.3")}
+
+	// Every check waits for all of them to start, which only finishes when they run at once
+	var started sync.WaitGroup
+	started.Add(len(ips))
+	results := checkBackends(ips, func(ip net.IP) *Result {
+		started.Done()
+		started.Wait()
+		return &Result{Status: shared.MonitorStatusUp, Message: ip.String()}
+	})
+
+	require.Len(t, results, len(ips))
+	for i, ip := range ips {
+		assert.Equal(t, ip.String(), results[i].Message)
+	}
+}
+
+func TestSummarizeBackends(t *testing.T) {
+	start := time.Now()
+	end := start.Add(time.Second)
+
+	result := summarizeBackends([]BackendResult{
+		{IP: "10.0.0.1", Up: true, Message: "ok"},
+		{IP: "10.0.0.2", Up: false, Message: "timeout", ErrorCategory: shared.ErrorCategoryPortFiltered},
+		{IP: "10.0.0.3", Up: true, Message: "ok"},
+	}, start, end)
+
+	assert.Equal(t, shared.MonitorStatusUp, result.Status)
+	assert.Equal(t, shared.ErrorCategoryDegraded, result.ErrorCategory)
+	assert.Equal(t, "Degraded: 1 of 3 backends down: 10.0.0.1 up (ok); 10.0.0.2 down (timeout); 10.0.0.3 up (ok)", result.Message)
+	assert.Equal(t, start, result.StartTime)
+	assert.Equal(t, end, result.EndTime)
+}
+
+func TestValidateResolver(t *testing.T) {
+	assert.NoError(t, validateResolver(""))
+	assert.NoError(t, validateResolver("10.0.0.53:53"))
+	assert.NoError(t, validateResolver("dns.internal:5353"))
+	assert.NoError(t, validateResolver("[fd00::53]:53"))
+	assert.Error(t, validateResolver("10.0.0.53"))
+	assert.Error(t, validateResolver(":53"))
+	assert.Error(t, validateResolver("10.0.0.53:0"))
+	assert.Error(t, validateResolver("10.0.0.53:dns"))
+}
+
+func TestExecutors_CustomResolver(t *testing.T) {
+	// The host only exists on the custom DNS server, the system resolver can't find it
+	dnsPort := startTestDNSServer(t, "app.internal.test. 60 IN A 127.0.0.1")
+	resolver := fmt.Sprintf("127.0.0.1:%d", dnsPort)
+
+	t.Run("tcp", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer listener.Close()
+		go func() {
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				conn.Close()
+			}
+		}()
+		port := listener.Addr().(*net.TCPAddr).Port
+
+		// The shared cache stands in for the system resolver and points at a dead address
+		cached := &countingTTLResolver{ips: []net.IP{deadBackend}, ttl: time.Minute}
+		executor := NewTCPExecutor(zap.NewNop().Sugar())
+		executor.dnsCache = NewDNSCache(cached, time.Second, time.Minute)
+		for _, options := range []string{``, `,"re_resolve":true`} {
+			config := fmt.Sprintf(`{"host":"app.internal.test","port":%d,"resolver":%q%s}`, port, resolver, options)
+			require.NoError(t, executor.Validate(config))
+
+			result := executor.Execute(context.Background(), &Monitor{Name: "tcp", Type: "tcp", Timeout: 2, Config: config}, nil)
+
+			assert.Equal(t, shared.MonitorStatusUp, result.Status, result.Message)
+		}
+		assert.Zero(t, cached.calls.Load())
+	})
+
+	t.Run("http", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+		port := server.Listener.Addr().(*net.TCPAddr).Port
+
+		cached := &countingTTLResolver{ips: []net.IP{deadBackend}, ttl: time.Minute}
+		executor := NewHTTPExecutor(zap.NewNop().Sugar())
+		executor.dnsCache = NewDNSCache(cached, time.Second, time.Minute)
+		for _, options := range []string{``, `,"re_resolve":true`} {
+			config := fmt.Sprintf(`{"url":"http://app.internal.test:%d/health","method":"GET","encoding":"json","accepted_statuscodes":["2XX"],"authMethod":"none","resolver":%q%s}`,
+				port, resolver, options)
+			require.NoError(t, executor.Validate(config))
+
+			result := executor.Execute(context.Background(), &Monitor{Name: "http", Type: "http", Timeout: 2, Config: config}, nil)
+
+			assert.Equal(t, shared.MonitorStatusUp, result.Status, result.Message)
+		}
+		assert.Zero(t, cached.calls.Load())
+	})
+
+	t.Run("ping resolution failure", func(t *testing.T) {
+		executor := NewPingExecutor(zap.NewNop().Sugar())
+		config := fmt.Sprintf(`{"host":"missing.internal.test","packet_size":32,"resolver":%q}`, resolver)
+		require.NoError(t, executor.Validate(config))
+
+		result := executor.Execute(context.Background(), &Monitor{Name: "ping", Type: "ping", Timeout: 2, Config: config}, nil)
+
+		assert.Equal(t, shared.MonitorStatusDown, result.Status)
+		assert.Contains(t, result.Message, "Ping failed: failed to resolve host")
+	})
+}
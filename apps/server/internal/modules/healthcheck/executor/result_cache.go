@@ -0,0 +1,155 @@
+package executor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// uncachedExecutorTypes depend on the state of the monitor itself rather than on its
+// target, so two monitors with the same config don't share a result
+var uncachedExecutorTypes = map[string]bool{
+	"push":  true,
+	"group": true,
+}
+
+type resultCacheEntry struct {
+	result    Result
+	expiresAt time.Time
+}
+
+// resultCall is a check in progress, identical checks started meanwhile wait for it
+type resultCall struct {
+	done   chan struct{}
+	result *Result
+}
+
+// ResultCache shares check results between monitors with the same executor type, config,
+// timeout and proxy. A result is reused for ttl after its check finished and identical
+// checks running at the same time make a single call to the target.
+type ResultCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu       sync.Mutex
+	entries  map[string]resultCacheEntry
+	inflight map[string]*resultCall
+}
+
+func NewResultCache(ttl time.Duration) *ResultCache {
+	return &ResultCache{
+		ttl:      ttl,
+		now:      time.Now,
+		entries:  make(map[string]resultCacheEntry),
+		inflight: make(map[string]*resultCall),
+	}
+}
+
+// Wrap returns exec with its results shared through the cache. Executor types that
+// depend on the monitor's own state are returned unchanged.
+func (c *ResultCache) Wrap(executorType string, exec Executor) Executor {
+	if uncachedExecutorTypes[executorType] {
+		return exec
+	}
+	return &cachedExecutor{Executor: exec, executorType: executorType, cache: c}
+}
+
+type cachedExecutor struct {
+	Executor
+	executorType string
+	cache        *ResultCache
+}
+
+func (e *cachedExecutor) Execute(ctx context.Context, m *Monitor, proxyModel *Proxy) *Result {
+	key, err := resultCacheKey(e.executorType, m, proxyModel)
+	if err != nil {
+		return e.Executor.Execute(ctx, m, proxyModel)
+	}
+	return e.cache.do(ctx, key, func() *Result {
+		return e.Executor.Execute(ctx, m, proxyModel)
+	})
+}
+
+// do returns the fresh cached result for key, waits for an identical check in progress or
+// runs check and caches its result. Every caller gets its own copy of the result.
+func (c *ResultCache) do(ctx context.Context, key string, check func() *Result) *Result {
+	c.mu.Lock()
+	if entry, ok := c.entries[key]; ok && c.now().Before(entry.expiresAt) {
+		c.mu.Unlock()
+		result := entry.result
+		return &result
+	}
+	if call, ok := c.inflight[key]; ok {
+		c.mu.Unlock()
+		select {
+		case <-call.done:
+			return copyResult(call.result)
+		case <-ctx.Done():
+			return check()
+		}
+	}
+	call := &resultCall{done: make(chan struct{})}
+	c.inflight[key] = call
+	c.mu.Unlock()
+
+	result := check()
+
+	c.mu.Lock()
+	// A check cut short by its context says nothing about the target
+	if result != nil && ctx.Err() == nil {
+		c.entries[key] = resultCacheEntry{result: *result, expiresAt: c.now().Add(c.ttl)}
+	}
+	c.removeExpired()
+	delete(c.inflight, key)
+	c.mu.Unlock()
+
+	call.result = result
+	close(call.done)
+	return copyResult(result)
+}
+
+// removeExpired drops stale entries so configs that are no longer checked don't pile up,
+// the caller holds the lock
+func (c *ResultCache) removeExpired() {
+	now := c.now()
+	for key, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+func copyResult(result *Result) *Result {
+	if result == nil {
+		return nil
+	}
+	copied := *result
+	return &copied
+}
+
+// resultCacheKey hashes what decides the outcome of a check. The config is decoded and
+// encoded again so key order and whitespace don't matter.
+func resultCacheKey(executorType string, m *Monitor, proxyModel *Proxy) (string, error) {
+	var config any
+	if err := json.Unmarshal([]byte(m.Config), &config); err != nil {
+		return "", err
+	}
+	normalized, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+
+	proxyKey := ""
+	if proxyModel != nil {
+		proxyKey = fmt.Sprintf("%s://%s:%s@%s:%d", proxyModel.Protocol, proxyModel.Username, proxyModel.Password, proxyModel.Host, proxyModel.Port)
+	}
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\x00%d\x00%s\x00", executorType, m.Timeout, proxyKey)
+	hash.Write(normalized)
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,111 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"peekaping/internal/modules/shared"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newResultCacheTestServer(t *testing.T, delay time.Duration) (*httptest.Server, *atomic.Int32) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		time.Sleep(delay)
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func newResultCacheTestMonitor(id, config string) *Monitor {
+	return &Monitor{ID: id, Name: id, Type: "http", Interval: 30, Timeout: 5, Config: config}
+}
+
+func TestResultCache_IdenticalMonitorsShareOneCheck(t *testing.T) {
+	server, requests := newResultCacheTestServer(t, 0)
+	cache := NewResultCache(10 * time.Second)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	exec := cache.Wrap("http", NewHTTPExecutor(zap.NewNop().Sugar()))
+
+	// Same config with a different key order and spacing
+	first := newResultCacheTestMonitor("first", fmt.Sprintf(`{"url": %q, "method": "GET", "encoding": "json", "accepted_statuscodes": ["2XX"], "authMethod": "none"}`, server.URL))
+	second := newResultCacheTestMonitor("second", fmt.Sprintf(`{"method":"GET","url":%q,"authMethod":"none","encoding":"json","accepted_statuscodes":["2XX"]}`, server.URL))
+
+	firstResult := exec.Execute(context.Background(), first, nil)
+	secondResult := exec.Execute(context.Background(), second, nil)
+
+	assert.Equal(t, shared.MonitorStatusUp, firstResult.Status)
+	assert.Equal(t, firstResult.Message, secondResult.Message)
+	assert.Equal(t, int32(1), requests.Load())
+
+	// Callers get their own copy to adjust
+	secondResult.Message = "changed"
+	assert.NotEqual(t, "changed", exec.Execute(context.Background(), first, nil).Message)
+	assert.Equal(t, int32(1), requests.Load())
+
+	now = now.Add(11 * time.Second)
+	exec.Execute(context.Background(), second, nil)
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+func TestResultCache_SimultaneousChecksMakeOneCall(t *testing.T) {
+	server, requests := newResultCacheTestServer(t, 100*time.Millisecond)
+	exec := NewResultCache(10*time.Second).Wrap("http", NewHTTPExecutor(zap.NewNop().Sugar()))
+	config := fmt.Sprintf(`{"url": %q, "method": "GET", "encoding": "json", "accepted_statuscodes": ["2XX"], "authMethod": "none"}`, server.URL)
+
+	var wg sync.WaitGroup
+	results := make([]*Result, 5)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = exec.Execute(context.Background(), newResultCacheTestMonitor(fmt.Sprintf("monitor-%d", i), config), nil)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), requests.Load())
+	for _, result := range results {
+		require.NotNil(t, result)
+		assert.Equal(t, shared.MonitorStatusUp, result.Status)
+	}
+}
+
+func TestResultCache_DifferentChecksAreNotShared(t *testing.T) {
+	server, requests := newResultCacheTestServer(t, 0)
+	exec := NewResultCache(10*time.Second).Wrap("http", NewHTTPExecutor(zap.NewNop().Sugar()))
+	config := `{"url": %q, "method": %q, "encoding": "json", "accepted_statuscodes": ["2XX"], "authMethod": "none"}`
+
+	get := newResultCacheTestMonitor("get", fmt.Sprintf(config, server.URL, "GET"))
+	head := newResultCacheTestMonitor("head", fmt.Sprintf(config, server.URL, "HEAD"))
+	slow := newResultCacheTestMonitor("slow", fmt.Sprintf(config, server.URL, "GET"))
+	slow.Timeout = 10
+
+	exec.Execute(context.Background(), get, nil)
+	exec.Execute(context.Background(), head, nil)
+	exec.Execute(context.Background(), slow, nil)
+	assert.Equal(t, int32(3), requests.Load())
+
+	// Through an unreachable proxy the check fails instead of reusing the direct result
+	result := exec.Execute(context.Background(), get, &Proxy{Protocol: "http", Host: "127.0.0.1", Port: 1})
+	assert.Equal(t, shared.MonitorStatusDown, result.Status)
+}
+
+func TestResultCache_WrapSkipsMonitorStateExecutors(t *testing.T) {
+	cache := NewResultCache(time.Minute)
+	push := NewPushExecutor(zap.NewNop().Sugar())
+
+	assert.Same(t, push, cache.Wrap("push", push))
+	assert.NotSame(t, push, cache.Wrap("http", push))
+}
@@ -0,0 +1,204 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"peekaping/internal/modules/shared"
+	"reflect"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+// maxExpressionBodySize caps the response body kept for result expressions
+const maxExpressionBodySize = 1 << 20
+
+// maxExpressionCost caps the runtime cost of an evaluation, so an expression looping
+// over a large body can't hold the worker. Typical expressions cost well under 1000.
+const maxExpressionCost = 1_000_000
+
+// expressionInterruptCheckFrequency is how many comprehension iterations run between
+// checks of the evaluation context
+const expressionInterruptCheckFrequency = 100
+
+// maxCachedExpressions bounds the compiled expressions a worker keeps, the cache starts
+// over once it is reached
+const maxCachedExpressions = 1000
+
+var mapStringAnyType = reflect.TypeOf(map[string]any{})
+
+var resultExpressionEnv = sync.OnceValues(func() (*cel.Env, error) {
+	return cel.NewEnv(
+		// "up" or "down"
+		cel.Variable("status", cel.StringType),
+		cel.Variable("message", cel.StringType),
+		// Response time in milliseconds
+		cel.Variable("ping", cel.IntType),
+		cel.Variable("error_category", cel.StringType),
+		// Response body of HTTP monitors, empty for other types
+		cel.Variable("body", cel.StringType),
+		// Response body parsed as JSON, an empty map when it isn't JSON
+		cel.Variable("json", cel.DynType),
+	)
+})
+
+// ResultExpression is a CEL expression that computes the final status and message of a
+// check from the executor result, e.g. `status == "up" && ping < 100 && body.contains("ok")`.
+// It returns a bool (up or down) or a map with "status" ("up"/"down" or a bool) and an
+// optional "message".
+type ResultExpression struct {
+	program cel.Program
+}
+
+// CompileResultExpression parses and type-checks an expression
+func CompileResultExpression(expression string) (*ResultExpression, error) {
+	env, err := resultExpressionEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("invalid result expression: %w", issues.Err())
+	}
+
+	outputType := ast.OutputType()
+	if !outputType.IsExactType(cel.BoolType) && !outputType.IsExactType(cel.DynType) &&
+		outputType.Kind() != types.MapKind {
+		return nil, fmt.Errorf("result expression must return a bool or a map, got %s", outputType)
+	}
+
+	program, err := env.Program(ast,
+		cel.CostLimit(maxExpressionCost),
+		cel.InterruptCheckFrequency(expressionInterruptCheckFrequency),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid result expression: %w", err)
+	}
+	return &ResultExpression{program: program}, nil
+}
+
+// Apply evaluates the expression against result and updates its status and message. An
+// expression that fails to evaluate, exceeds its cost limit or outlives ctx turns the
+// result down.
+func (e *ResultExpression) Apply(ctx context.Context, result *Result, pingMs int) {
+	status, message, err := e.evaluate(ctx, result, pingMs)
+	if err != nil {
+		result.Status = shared.MonitorStatusDown
+		result.Message = fmt.Sprintf("Result expression error: %v", err)
+		return
+	}
+
+	if status == shared.MonitorStatusDown && result.Status == shared.MonitorStatusUp && message == "" {
+		message = fmt.Sprintf("Result expression evaluated to down: %s", result.Message)
+	}
+	if status == shared.MonitorStatusUp && result.Status != shared.MonitorStatusUp {
+		// The failure no longer applies
+		result.ErrorCategory = ""
+	}
+	result.Status = status
+	if message != "" {
+		result.Message = message
+	}
+}
+
+func (e *ResultExpression) evaluate(ctx context.Context, result *Result, pingMs int) (shared.MonitorStatus, string, error) {
+	status := "down"
+	if result.Status == shared.MonitorStatusUp {
+		status = "up"
+	}
+
+	var parsed any = map[string]any{}
+	if result.ResponseBody != "" {
+		var body any
+		if err := json.Unmarshal([]byte(result.ResponseBody), &body); err == nil {
+			parsed = body
+		}
+	}
+
+	out, _, err := e.program.ContextEval(ctx, map[string]any{
+		"status":         status,
+		"message":        result.Message,
+		"ping":           pingMs,
+		"error_category": result.ErrorCategory,
+		"body":           result.ResponseBody,
+		"json":           parsed,
+	})
+	if err != nil {
+		return 0, "", err
+	}
+
+	if up, ok := out.Value().(bool); ok {
+		return statusOf(up), "", nil
+	}
+	return expressionMapResult(out)
+}
+
+// expressionMapResult reads the status and message of a map returned by an expression
+func expressionMapResult(out ref.Val) (shared.MonitorStatus, string, error) {
+	native, err := out.ConvertToNative(mapStringAnyType)
+	if err != nil {
+		return 0, "", fmt.Errorf("expression returned %s, expected a bool or a map", out.Type().TypeName())
+	}
+	fields := native.(map[string]any)
+
+	var status shared.MonitorStatus
+	switch value := fields["status"].(type) {
+	case bool:
+		status = statusOf(value)
+	case string:
+		switch value {
+		case "up":
+			status = shared.MonitorStatusUp
+		case "down":
+			status = shared.MonitorStatusDown
+		default:
+			return 0, "", fmt.Errorf("status must be \"up\" or \"down\", got %q", value)
+		}
+	default:
+		return 0, "", fmt.Errorf("expression map needs a \"status\" of \"up\", \"down\" or a bool")
+	}
+
+	message, _ := fields["message"].(string)
+	return status, message, nil
+}
+
+func statusOf(up bool) shared.MonitorStatus {
+	if up {
+		return shared.MonitorStatusUp
+	}
+	return shared.MonitorStatusDown
+}
+
+// ResultExpressionCache keeps compiled expressions so a monitor's expression is compiled
+// once per worker instead of on every check
+type ResultExpressionCache struct {
+	mu          sync.Mutex
+	expressions map[string]*ResultExpression
+}
+
+func NewResultExpressionCache() *ResultExpressionCache {
+	return &ResultExpressionCache{expressions: make(map[string]*ResultExpression)}
+}
+
+// Get returns the compiled expression, compiling it on first use
+func (c *ResultExpressionCache) Get(expression string) (*ResultExpression, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if compiled, ok := c.expressions[expression]; ok {
+		return compiled, nil
+	}
+	compiled, err := CompileResultExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+	if len(c.expressions) >= maxCachedExpressions {
+		c.expressions = make(map[string]*ResultExpression)
+	}
+	c.expressions[expression] = compiled
+	return compiled, nil
+}
@@ -0,0 +1,222 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"peekaping/internal/modules/shared"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestCompileResultExpression(t *testing.T) {
+	tests := []struct {
+		name        string
+		expression  string
+		expectedErr string
+	}{
+		{"bool", `status == "up" && ping < 100`, ""},
+		{"map", `{"status": ping < 100 ? "up" : "down", "message": "slow"}`, ""},
+		{"json field", `json.status == "ok"`, ""},
+		{"syntax error", `ping <`, "invalid result expression"},
+		{"unknown variable", `latency < 100`, "undeclared reference"},
+		{"wrong type", `ping + 1`, "must return a bool or a map"},
+		{"string result", `message`, "must return a bool or a map"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CompileResultExpression(tt.expression)
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.expectedErr)
+			}
+		})
+	}
+}
+
+func TestResultExpression_Apply(t *testing.T) {
+	const latencyAndBody = `status == "up" && ping < 100 && body.contains("healthy")`
+
+	tests := []struct {
+		name            string
+		expression      string
+		result          Result
+		ping            int
+		expectedStatus  shared.MonitorStatus
+		expectedMessage string
+	}{
+		{
+			name:            "fast with keyword stays up",
+			expression:      latencyAndBody,
+			result:          Result{Status: shared.MonitorStatusUp, Message: "200 - 200 OK", ResponseBody: `{"state": "healthy"}`},
+			ping:            42,
+			expectedStatus:  shared.MonitorStatusUp,
+			expectedMessage: "200 - 200 OK",
+		},
+		{
+			name:            "slow with keyword goes down",
+			expression:      latencyAndBody,
+			result:          Result{Status: shared.MonitorStatusUp, Message: "200 - 200 OK", ResponseBody: `{"state": "healthy"}`},
+			ping:            250,
+			expectedStatus:  shared.MonitorStatusDown,
+			expectedMessage: "Result expression evaluated to down: 200 - 200 OK",
+		},
+		{
+			name:            "fast without keyword goes down",
+			expression:      latencyAndBody,
+			result:          Result{Status: shared.MonitorStatusUp, Message: "200 - 200 OK", ResponseBody: `{"state": "starting"}`},
+			ping:            42,
+			expectedStatus:  shared.MonitorStatusDown,
+			expectedMessage: "Result expression evaluated to down: 200 - 200 OK",
+		},
+		{
+			name:            "down check stays down",
+			expression:      latencyAndBody,
+			result:          Result{Status: shared.MonitorStatusDown, Message: "HTTP request failed with status: 503", ResponseBody: "healthy"},
+			ping:            10,
+			expectedStatus:  shared.MonitorStatusDown,
+			expectedMessage: "HTTP request failed with status: 503",
+		},
+		{
+			name:            "map sets status and message",
+			expression:      `ping > 500 ? {"status": "down", "message": "Too slow: " + string(ping) + "ms"} : {"status": "up", "message": "Queue depth " + string(json.queue.depth)}`,
+			result:          Result{Status: shared.MonitorStatusUp, Message: "200 - 200 OK", ResponseBody: `{"queue": {"depth": 3}}`},
+			ping:            600,
+			expectedStatus:  shared.MonitorStatusDown,
+			expectedMessage: "Too slow: 600ms",
+		},
+		{
+			name:            "map reads json",
+			expression:      `{"status": json.queue.depth < 10, "message": "Queue depth " + string(json.queue.depth)}`,
+			result:          Result{Status: shared.MonitorStatusUp, Message: "200 - 200 OK", ResponseBody: `{"queue": {"depth": 3}}`},
+			ping:            20,
+			expectedStatus:  shared.MonitorStatusUp,
+			expectedMessage: "Queue depth 3",
+		},
+		{
+			name:            "expression can accept a failed check",
+			expression:      `status == "up" || message.contains("404")`,
+			result:          Result{Status: shared.MonitorStatusDown, Message: "HTTP request failed with status: 404", ErrorCategory: shared.ErrorCategoryNetwork},
+			ping:            20,
+			expectedStatus:  shared.MonitorStatusUp,
+			expectedMessage: "HTTP request failed with status: 404",
+		},
+		{
+			name:            "evaluation error turns the check down",
+			expression:      `json.missing.field == 1`,
+			result:          Result{Status: shared.MonitorStatusUp, Message: "200 - 200 OK", ResponseBody: `{}`},
+			ping:            20,
+			expectedStatus:  shared.MonitorStatusDown,
+			expectedMessage: "Result expression error: no such key: missing",
+		},
+		{
+			name:            "invalid status in map",
+			expression:      `{"status": "degraded"}`,
+			result:          Result{Status: shared.MonitorStatusUp, Message: "200 - 200 OK"},
+			ping:            20,
+			expectedStatus:  shared.MonitorStatusDown,
+			expectedMessage: `Result expression error: status must be "up" or "down", got "degraded"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expression, err := CompileResultExpression(tt.expression)
+			require.NoError(t, err)
+
+			result := tt.result
+			expression.Apply(context.Background(), &result, tt.ping)
+
+			assert.Equal(t, tt.expectedStatus, result.Status)
+			assert.Equal(t, tt.expectedMessage, result.Message)
+			if result.Status == shared.MonitorStatusUp {
+				assert.Empty(t, result.ErrorCategory)
+			}
+		})
+	}
+}
+
+func numberListBody(n int) string {
+	items := make([]string, n)
+	for i := range items {
+		items[i] = fmt.Sprint(i)
+	}
+	return "[" + strings.Join(items, ",") + "]"
+}
+
+func TestResultExpression_Apply_OverBudget(t *testing.T) {
+	body := numberListBody(200)
+
+	expression, err := CompileResultExpression(`json.all(a, json.all(b, json.all(c, true)))`)
+	require.NoError(t, err)
+
+	result := Result{Status: shared.MonitorStatusUp, Message: "200 - 200 OK", ResponseBody: body}
+	expression.Apply(context.Background(), &result, 10)
+
+	assert.Equal(t, shared.MonitorStatusDown, result.Status)
+	assert.Contains(t, result.Message, "Result expression error")
+	assert.Contains(t, result.Message, "cost limit exceeded")
+}
+
+func TestResultExpression_Apply_ContextCancelled(t *testing.T) {
+	expression, err := CompileResultExpression(`json.all(a, json.all(b, true))`)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Within the cost budget, so only the cancelled context can stop it
+	result := Result{Status: shared.MonitorStatusUp, Message: "200 - 200 OK", ResponseBody: numberListBody(200)}
+	expression.Apply(ctx, &result, 10)
+
+	assert.Equal(t, shared.MonitorStatusDown, result.Status)
+	assert.Contains(t, result.Message, "Result expression error")
+}
+
+func TestResultExpression_HTTPLatencyAndBody(t *testing.T) {
+	var delay atomic.Int64
+	var body atomic.Value
+	body.Store(`{"state": "healthy"}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Duration(delay.Load()))
+		fmt.Fprint(w, body.Load())
+	}))
+	defer server.Close()
+
+	executor := NewHTTPExecutor(zap.NewNop().Sugar())
+	monitor := &Monitor{
+		ID:       "test-monitor",
+		Type:     "http",
+		Name:     "Test Monitor",
+		Interval: 30,
+		Timeout:  5,
+		Config: fmt.Sprintf(`{"url": %q, "method": "GET", "encoding": "json", "accepted_statuscodes": ["2XX"], "authMethod": "none"}`,
+			server.URL),
+	}
+	expression, err := CompileResultExpression(`status == "up" && ping < 150 && json.state == "healthy"`)
+	require.NoError(t, err)
+
+	check := func() *Result {
+		result := executor.Execute(context.Background(), monitor, nil)
+		expression.Apply(context.Background(), result, int(result.EndTime.Sub(result.StartTime).Milliseconds()))
+		return result
+	}
+
+	assert.Equal(t, shared.MonitorStatusUp, check().Status)
+
+	body.Store(`{"state": "degraded"}`)
+	assert.Equal(t, shared.MonitorStatusDown, check().Status)
+
+	body.Store(`{"state": "healthy"}`)
+	delay.Store(int64(200 * time.Millisecond))
+	assert.Equal(t, shared.MonitorStatusDown, check().Status)
+}
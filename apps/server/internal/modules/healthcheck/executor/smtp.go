@@ -0,0 +1,239 @@
+package executor
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"peekaping/internal/modules/shared"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	SMTPSecurityNone     = "none"
+	SMTPSecurityStartTLS = "starttls"
+	SMTPSecurityTLS      = "tls"
+
+	// defaultSMTPRelayAttempts is how many RCPT TO probes are made when the server keeps
+	// answering with a transient (4xx) reply, e.g. because of greylisting
+	defaultSMTPRelayAttempts = 3
+	// defaultSMTPRelayRetryDelay is the wait between two probes in milliseconds
+	defaultSMTPRelayRetryDelay = 2000
+)
+
+type SMTPConfig struct {
+	Host string `json:"host" validate:"required" example:"mail.example.com"`
+	Port int    `json:"port" validate:"required,min=1,max=65535" example:"25"`
+	// Security is "none" (default), "starttls" or "tls" for implicit TLS
+	Security       string `json:"security,omitempty" validate:"omitempty,oneof=none starttls tls" example:"starttls"`
+	IgnoreTLSError bool   `json:"ignore_tls_error,omitempty"`
+	// OpenRelayTest asks the server to relay from RelayFrom to RelayTo, both outside its
+	// domains. The monitor is down when the recipient is accepted.
+	OpenRelayTest bool   `json:"open_relay_test,omitempty"`
+	RelayFrom     string `json:"relay_from,omitempty" validate:"required_if=OpenRelayTest true,omitempty,email" example:"probe@example.org"`
+	RelayTo       string `json:"relay_to,omitempty" validate:"required_if=OpenRelayTest true,omitempty,email" example:"probe@example.net"`
+	// RelayAttempts is how many times the probe is made while the server answers with a
+	// transient 4xx reply, 3 when unset. RelayRetryDelay is the wait between attempts in
+	// milliseconds, 2000 when unset.
+	RelayAttempts   int `json:"relay_attempts,omitempty" validate:"omitempty,min=1,max=10" example:"3"`
+	RelayRetryDelay int `json:"relay_retry_delay,omitempty" validate:"omitempty,min=0" example:"2000"`
+}
+
+// SMTPRelayVerdict is the conclusion of an open-relay probe
+type SMTPRelayVerdict string
+
+const (
+	SMTPRelayOpen         SMTPRelayVerdict = "open"         // the recipient was accepted
+	SMTPRelayRejected     SMTPRelayVerdict = "rejected"     // permanent 5xx rejection
+	SMTPRelayInconclusive SMTPRelayVerdict = "inconclusive" // only transient 4xx replies
+)
+
+type SMTPExecutor struct {
+	logger *zap.SugaredLogger
+}
+
+func NewSMTPExecutor(logger *zap.SugaredLogger) *SMTPExecutor {
+	return &SMTPExecutor{
+		logger: logger,
+	}
+}
+
+func (s *SMTPExecutor) Unmarshal(configJSON string) (any, error) {
+	return GenericUnmarshal[SMTPConfig](configJSON)
+}
+
+func (s *SMTPExecutor) Validate(configJSON string) error {
+	cfg, err := s.Unmarshal(configJSON)
+	if err != nil {
+		return err
+	}
+	return GenericValidator(cfg.(*SMTPConfig))
+}
+
+func (s *SMTPExecutor) Execute(ctx context.Context, m *Monitor, proxyModel *Proxy) *Result {
+	cfgAny, err := s.Unmarshal(m.Config)
+	if err != nil {
+		return DownResult(err, time.Now().UTC(), time.Now().UTC())
+	}
+	cfg := cfgAny.(*SMTPConfig)
+
+	startTime := time.Now().UTC()
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, time.Duration(m.Timeout)*time.Second)
+	defer cancel()
+
+	client, err := s.connect(timeoutCtx, cfg)
+	if err != nil {
+		s.logger.Infof("SMTP connection failed: %s, %v", m.Name, err)
+		return DownResult(fmt.Errorf("SMTP connection failed: %w", err), startTime, time.Now().UTC())
+	}
+	defer client.Close()
+
+	if !cfg.OpenRelayTest {
+		_ = client.Quit()
+		return &Result{
+			Status:    shared.MonitorStatusUp,
+			Message:   "SMTP server is responding",
+			StartTime: startTime,
+			EndTime:   time.Now().UTC(),
+		}
+	}
+
+	verdict, reply, err := s.probeRelay(timeoutCtx, client, cfg)
+	endTime := time.Now().UTC()
+	if err != nil {
+		s.logger.Infof("SMTP open relay test failed: %s, %v", m.Name, err)
+		return DownResult(fmt.Errorf("open relay test failed: %w", err), startTime, endTime)
+	}
+	_ = client.Quit()
+
+	switch verdict {
+	case SMTPRelayOpen:
+		return &Result{
+			Status:    shared.MonitorStatusDown,
+			Message:   fmt.Sprintf("Open relay: server accepted mail for %s", cfg.RelayTo),
+			StartTime: startTime,
+			EndTime:   endTime,
+		}
+	case SMTPRelayRejected:
+		return &Result{
+			Status:    shared.MonitorStatusUp,
+			Message:   fmt.Sprintf("Relay rejected: %s", reply),
+			StartTime: startTime,
+			EndTime:   endTime,
+		}
+	default:
+		// Greylisting alone does not prove the server relays, so the monitor stays up
+		return &Result{
+			Status:    shared.MonitorStatusUp,
+			Message:   fmt.Sprintf("Relay test inconclusive, temporarily rejected %d times: %s", relayAttempts(cfg), reply),
+			StartTime: startTime,
+			EndTime:   endTime,
+		}
+	}
+}
+
+// connect opens the SMTP session and greets the server, negotiating TLS as configured
+func (s *SMTPExecutor) connect(ctx context.Context, cfg *SMTPConfig) (*smtp.Client, error) {
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+	tlsConfig := &tls.Config{ServerName: cfg.Host, InsecureSkipVerify: cfg.IgnoreTLSError}
+
+	var conn net.Conn
+	var err error
+	if cfg.Security == SMTPSecurityTLS {
+		dialer := &tls.Dialer{Config: tlsConfig}
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	} else {
+		var dialer net.Dialer
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := client.Hello("localhost"); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	if cfg.Security == SMTPSecurityStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			client.Close()
+			return nil, errors.New("server does not support STARTTLS")
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+
+	return client, nil
+}
+
+func relayAttempts(cfg *SMTPConfig) int {
+	if cfg.RelayAttempts > 0 {
+		return cfg.RelayAttempts
+	}
+	return defaultSMTPRelayAttempts
+}
+
+// probeRelay sends MAIL FROM and RCPT TO for an outside recipient. A transient 4xx reply
+// (greylisting, rate limiting) is retried after RSET, only an accepted recipient or a
+// permanent 5xx reply is a stable result. Errors other than SMTP replies are returned.
+func (s *SMTPExecutor) probeRelay(ctx context.Context, client *smtp.Client, cfg *SMTPConfig) (SMTPRelayVerdict, string, error) {
+	attempts := relayAttempts(cfg)
+	delay := time.Duration(defaultSMTPRelayRetryDelay) * time.Millisecond
+	if cfg.RelayRetryDelay > 0 {
+		delay = time.Duration(cfg.RelayRetryDelay) * time.Millisecond
+	}
+
+	var reply string
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			if err := client.Reset(); err != nil {
+				return "", "", err
+			}
+			select {
+			case <-ctx.Done():
+				return SMTPRelayInconclusive, reply, nil
+			case <-time.After(delay):
+			}
+		}
+
+		err := client.Mail(cfg.RelayFrom)
+		if err == nil {
+			err = client.Rcpt(cfg.RelayTo)
+		}
+		if err == nil {
+			return SMTPRelayOpen, "", nil
+		}
+
+		var smtpErr *textproto.Error
+		if !errors.As(err, &smtpErr) {
+			return "", "", err
+		}
+		reply = smtpErr.Error()
+
+		if smtpErr.Code >= 500 {
+			return SMTPRelayRejected, reply, nil
+		}
+
+		s.logger.Debugf("SMTP relay probe got transient reply (attempt %d/%d): %s", attempt, attempts, reply)
+	}
+
+	return SMTPRelayInconclusive, reply, nil
+}
@@ -0,0 +1,165 @@
+package executor
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"peekaping/internal/modules/shared"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeSMTPServer answers one SMTP session, replying to each RCPT TO with the next
+// scripted reply. It records how many RCPT TO commands it received.
+type fakeSMTPServer struct {
+	listener    net.Listener
+	rcptReplies []string
+
+	mu    sync.Mutex
+	rcpts int
+}
+
+func newFakeSMTPServer(t *testing.T, rcptReplies ...string) *fakeSMTPServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	server := &fakeSMTPServer{listener: listener, rcptReplies: rcptReplies}
+	go server.serve()
+
+	return server
+}
+
+func (f *fakeSMTPServer) serve() {
+	conn, err := f.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { fmt.Fprintf(conn, "%s\r\n", line) }
+
+	reply("220 mail.test ESMTP")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.Fields(line)[0])
+
+		switch command {
+		case "EHLO":
+			reply("250-mail.test")
+			reply("250 8BITMIME")
+		case "MAIL", "RSET":
+			reply("250 OK")
+		case "RCPT":
+			f.mu.Lock()
+			next := f.rcptReplies[min(f.rcpts, len(f.rcptReplies)-1)]
+			f.rcpts++
+			f.mu.Unlock()
+			reply(next)
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func (f *fakeSMTPServer) config(extra string) string {
+	addr := f.listener.Addr().(*net.TCPAddr)
+	return fmt.Sprintf(`{"host":"127.0.0.1","port":%d,"open_relay_test":true,"relay_from":"probe@example.org","relay_to":"probe@example.net","relay_retry_delay":1%s}`, addr.Port, extra)
+}
+
+func (f *fakeSMTPServer) rcptCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.rcpts
+}
+
+func TestSMTPExecutor_OpenRelayTest(t *testing.T) {
+	tests := []struct {
+		name           string
+		rcptReplies    []string
+		extraConfig    string
+		expectedStatus shared.MonitorStatus
+		expectedMsg    string
+		expectedRcpts  int
+	}{
+		{
+			name:           "greylisted then accepted is an open relay",
+			rcptReplies:    []string{"451 4.7.1 Greylisted, try again later", "250 OK"},
+			expectedStatus: shared.MonitorStatusDown,
+			expectedMsg:    "Open relay",
+			expectedRcpts:  2,
+		},
+		{
+			name:           "hard rejection is not retried",
+			rcptReplies:    []string{"550 5.7.1 Relaying denied"},
+			expectedStatus: shared.MonitorStatusUp,
+			expectedMsg:    "Relay rejected",
+			expectedRcpts:  1,
+		},
+		{
+			name:           "greylisted then rejected",
+			rcptReplies:    []string{"450 4.2.0 Greylisted", "554 5.7.1 Relay access denied"},
+			expectedStatus: shared.MonitorStatusUp,
+			expectedMsg:    "Relay rejected",
+			expectedRcpts:  2,
+		},
+		{
+			name:           "greylisted on every attempt is inconclusive",
+			rcptReplies:    []string{"451 4.7.1 Greylisted"},
+			extraConfig:    `,"relay_attempts":4`,
+			expectedStatus: shared.MonitorStatusUp,
+			expectedMsg:    "inconclusive",
+			expectedRcpts:  4,
+		},
+		{
+			name:           "accepted right away",
+			rcptReplies:    []string{"250 OK"},
+			expectedStatus: shared.MonitorStatusDown,
+			expectedMsg:    "Open relay",
+			expectedRcpts:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeSMTPServer(t, tt.rcptReplies...)
+			executor := NewSMTPExecutor(zap.NewNop().Sugar())
+
+			result := executor.Execute(context.Background(), &Monitor{
+				Name:    "mail",
+				Timeout: 5,
+				Config:  server.config(tt.extraConfig),
+			}, nil)
+
+			require.NotNil(t, result)
+			assert.Equal(t, tt.expectedStatus, result.Status, result.Message)
+			assert.Contains(t, result.Message, tt.expectedMsg)
+			assert.Equal(t, tt.expectedRcpts, server.rcptCount())
+		})
+	}
+}
+
+func TestSMTPExecutor_Validate(t *testing.T) {
+	executor := NewSMTPExecutor(zap.NewNop().Sugar())
+
+	assert.NoError(t, executor.Validate(`{"host":"mail.example.com","port":25}`))
+	assert.Error(t, executor.Validate(`{"host":"mail.example.com","port":25,"open_relay_test":true}`), "relay addresses are required")
+	assert.Error(t, executor.Validate(`{"host":"mail.example.com","port":25,"security":"ssl"}`))
+	assert.Error(t, executor.Validate(`{"host":"mail.example.com","port":25,"open_relay_test":true,"relay_from":"a@example.org","relay_to":"a@example.net","relay_attempts":11}`))
+}
@@ -0,0 +1,53 @@
+package executor
+
+import (
+	"fmt"
+	"net"
+	"time"
+)
+
+// interfaceAddrs lists the addresses of the local interfaces, replaced in tests
+var interfaceAddrs = net.InterfaceAddrs
+
+// localSourceIP parses sourceIP and checks that it is assigned to a local interface, so a
+// monitor bound to a missing address fails with a clear message instead of a bind error
+func localSourceIP(sourceIP string) (net.IP, error) {
+	ip := net.ParseIP(sourceIP)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid source IP %q", sourceIP)
+	}
+
+	addrs, err := interfaceAddrs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list local addresses: %w", err)
+	}
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok {
+			continue
+		}
+		// The whole loopback range is bound to the loopback interface
+		if ipNet.IP.Equal(ip) || (ip.IsLoopback() && ipNet.Contains(ip)) {
+			return ip, nil
+		}
+	}
+
+	return nil, fmt.Errorf("source IP %s is not assigned to a local interface", sourceIP)
+}
+
+// sourceDialer returns a dialer whose connections originate from sourceIP. An empty
+// sourceIP leaves the choice of the local address to the operating system.
+func sourceDialer(timeout time.Duration, sourceIP string) (*net.Dialer, error) {
+	dialer := &net.Dialer{Timeout: timeout}
+	if sourceIP == "" {
+		return dialer, nil
+	}
+
+	ip, err := localSourceIP(sourceIP)
+	if err != nil {
+		return nil, err
+	}
+	dialer.LocalAddr = &net.TCPAddr{IP: ip}
+
+	return dialer, nil
+}
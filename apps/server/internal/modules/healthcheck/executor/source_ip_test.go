@@ -0,0 +1,129 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"peekaping/internal/modules/shared"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// testSourceIP is a loopback address other than 127.0.0.1, so a connection coming from it
+// proves the dialer was bound
+const testSourceIP = "127.0.0.2"
+
+func requireSourceIPBindable(t *testing.T) {
+	t.Helper()
+	listener, err := net.Listen("tcp", net.JoinHostPort(testSourceIP, "0"))
+	if err != nil {
+		t.Skipf("cannot bind %s on this host: %v", testSourceIP, err)
+	}
+	listener.Close()
+}
+
+func TestLocalSourceIP(t *testing.T) {
+	original := interfaceAddrs
+	t.Cleanup(func() { interfaceAddrs = original })
+	interfaceAddrs = func() ([]net.Addr, error) {
+		return []net.Addr{
+			&net.IPNet{IP: net.ParseIP("127.0.0.1"), Mask: net.CIDRMask(8, 32)},
+			&net.IPNet{IP: net.ParseIP("192.168.1.10"), Mask: net.CIDRMask(24, 32)},
+		}, nil
+	}
+
+	ip, err := localSourceIP("192.168.1.10")
+	require.NoError(t, err)
+	assert.Equal(t, "192.168.1.10", ip.String())
+
+	_, err = localSourceIP("127.0.0.2")
+	assert.NoError(t, err, "loopback range is local")
+
+	_, err = localSourceIP("192.168.1.11")
+	assert.ErrorContains(t, err, "not assigned to a local interface")
+
+	_, err = localSourceIP("not-an-ip")
+	assert.ErrorContains(t, err, "invalid source IP")
+}
+
+func TestSourceDialer(t *testing.T) {
+	dialer, err := sourceDialer(0, "")
+	require.NoError(t, err)
+	assert.Nil(t, dialer.LocalAddr)
+
+	dialer, err = sourceDialer(0, "127.0.0.1")
+	require.NoError(t, err)
+	require.NotNil(t, dialer.LocalAddr)
+	assert.Equal(t, "127.0.0.1", dialer.LocalAddr.(*net.TCPAddr).IP.String())
+}
+
+func TestTCPExecutor_SourceIP(t *testing.T) {
+	requireSourceIPBindable(t)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	remote := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		host, _, _ := net.SplitHostPort(conn.RemoteAddr().String())
+		remote <- host
+		conn.Close()
+	}()
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	executor := NewTCPExecutor(zap.NewNop().Sugar())
+	result := executor.Execute(context.Background(), &Monitor{
+		Name:    "tcp",
+		Timeout: 5,
+		Config:  fmt.Sprintf(`{"host":"127.0.0.1","port":%d,"source_ip":"%s"}`, port, testSourceIP),
+	}, nil)
+
+	require.Equal(t, shared.MonitorStatusUp, result.Status, result.Message)
+	assert.Equal(t, testSourceIP, <-remote)
+}
+
+func TestHTTPExecutor_SourceIP(t *testing.T) {
+	requireSourceIPBindable(t)
+
+	remote := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, _ := net.SplitHostPort(r.RemoteAddr)
+		remote <- host
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	executor := NewHTTPExecutor(zap.NewNop().Sugar())
+	result := executor.Execute(context.Background(), &Monitor{
+		Name:    "http",
+		Timeout: 5,
+		Config:  fmt.Sprintf(`{"url":"%s","method":"GET","encoding":"json","accepted_statuscodes":["2XX"],"authMethod":"none","source_ip":"%s"}`, server.URL, testSourceIP),
+	}, nil)
+
+	require.Equal(t, shared.MonitorStatusUp, result.Status, result.Message)
+	assert.Equal(t, testSourceIP, <-remote)
+}
+
+func TestTCPExecutor_SourceIPNotLocal(t *testing.T) {
+	executor := NewTCPExecutor(zap.NewNop().Sugar())
+	result := executor.Execute(context.Background(), &Monitor{
+		Name:    "tcp",
+		Timeout: 1,
+		// 192.0.2.0/24 is reserved for documentation and never assigned locally
+		Config: `{"host":"127.0.0.1","port":80,"source_ip":"192.0.2.1"}`,
+	}, nil)
+
+	assert.Equal(t, shared.MonitorStatusDown, result.Status)
+	assert.Contains(t, result.Message, "source IP 192.0.2.1 is not assigned to a local interface")
+}
@@ -2,26 +2,82 @@ package executor
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net"
+	"os"
 	"peekaping/internal/modules/shared"
+	"strings"
+	"syscall"
 	"time"
 
 	"go.uber.org/zap"
 )
 
+const (
+	TCPCheckModeFull = "full"
+	TCPCheckModeSYN  = "syn"
+)
+
+// TCPPortState is the state of a port as observed by a connection attempt
+type TCPPortState string
+
+const (
+	TCPPortOpen        TCPPortState = "open"        // connection established
+	TCPPortClosed      TCPPortState = "closed"      // connection refused (RST)
+	TCPPortFiltered    TCPPortState = "filtered"    // no answer before the timeout
+	TCPPortUnreachable TCPPortState = "unreachable" // any other network error
+)
+
 type TCPConfig struct {
 	Host string `json:"host" validate:"required" example:"example.com"`
 	Port int    `json:"port" validate:"required,min=1,max=65535" example:"80"`
+	// CheckMode "full" (default) completes the handshake and closes gracefully,
+	// "syn" aborts the connection right after it is established and reports
+	// whether the port is open, closed or filtered
+	CheckMode string `json:"tcp_check_mode,omitempty" validate:"omitempty,oneof=full syn" example:"full"`
+	// ReResolve resolves the host on every check and connects to the fresh address
+	ReResolve bool `json:"re_resolve,omitempty"`
+	// CheckAllIPs connects to every resolved address and reports the monitor as
+	// degraded when only some of them fail
+	CheckAllIPs bool `json:"check_all_ips,omitempty"`
+	// SourceIP is the local address the connection is made from, on hosts with several addresses
+	SourceIP string `json:"source_ip,omitempty" validate:"omitempty,ip" example:"192.168.1.10"`
+	// Resolver is the "host:port" of the DNS server the host is resolved with instead of
+	// the system resolver
+	Resolver string `json:"resolver,omitempty" example:"10.0.0.53:53"`
+	// DNSCacheBypass resolves the host on every connect instead of through the shared DNS cache
+	DNSCacheBypass bool `json:"dns_cache_bypass,omitempty"`
+	// HoldOpenMs keeps the connection open this many milliseconds after connecting,
+	// reading until then. The monitor is down when the server closes or resets it
+	// earlier. The hold ends at the monitor timeout at the latest. Ignored in "syn" mode.
+	HoldOpenMs int `json:"hold_open_ms,omitempty" validate:"omitempty,min=1" example:"5000"`
+	// SendData is written to the connection once it is established. Ignored in "syn" mode.
+	SendData string `json:"send_data,omitempty" example:"PING\r\n"`
+	// ExpectData must appear in what the server sends after connecting (and after
+	// SendData), read up to maxTCPResponseSize bytes within the monitor timeout.
+	// Ignored in "syn" mode.
+	ExpectData string `json:"expect_data,omitempty" example:"+PONG"`
 }
 
+// maxTCPResponseSize bounds the bytes read from the server when looking for ExpectData
+const maxTCPResponseSize = 64 * 1024
+
+// maxTCPResponseInResult bounds the response quoted in the message of a failed match
+const maxTCPResponseInResult = 100
+
 type TCPExecutor struct {
-	logger *zap.SugaredLogger
+	logger   *zap.SugaredLogger
+	resolver HostResolver
+	// dnsCache is shared between executors, nil when caching is disabled
+	dnsCache *DNSCache
 }
 
 func NewTCPExecutor(logger *zap.SugaredLogger) *TCPExecutor {
 	return &TCPExecutor{
-		logger: logger,
+		logger:   logger,
+		resolver: net.DefaultResolver,
 	}
 }
 
@@ -34,7 +90,32 @@ func (s *TCPExecutor) Validate(configJSON string) error {
 	if err != nil {
 		return err
 	}
-	return GenericValidator(cfg.(*TCPConfig))
+	tcpCfg := cfg.(*TCPConfig)
+	if err := GenericValidator(tcpCfg); err != nil {
+		return err
+	}
+	return validateResolver(tcpCfg.Resolver)
+}
+
+// ClassifyTCPDialError maps a dial error to the observed port state and the
+// heartbeat error category. A nil error means the port is open.
+func ClassifyTCPDialError(err error) (TCPPortState, string) {
+	if err == nil {
+		return TCPPortOpen, ""
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return TCPPortClosed, shared.ErrorCategoryPortClosed
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, os.ErrDeadlineExceeded) ||
+		(errors.As(err, &netErr) && netErr.Timeout()) {
+		return TCPPortFiltered, shared.ErrorCategoryPortFiltered
+	}
+
+	return TCPPortUnreachable, shared.ErrorCategoryNetwork
 }
 
 func (t *TCPExecutor) Execute(ctx context.Context, m *Monitor, proxyModel *Proxy) *Result {
@@ -46,28 +127,120 @@ func (t *TCPExecutor) Execute(ctx context.Context, m *Monitor, proxyModel *Proxy
 
 	t.logger.Debugf("execute tcp cfg: %+v", cfg)
 
-	address := fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
+	if !cfg.ReResolve && !cfg.CheckAllIPs {
+		if t.dnsCache == nil || cfg.Resolver != "" || cfg.DNSCacheBypass {
+			return t.dial(ctx, m, cfg, cfg.Host)
+		}
+		return t.dialCached(ctx, m, cfg)
+	}
+
+	startTime := time.Now().UTC()
+	resolver := t.resolver
+	if cfg.Resolver != "" {
+		resolver = newCustomResolver(cfg.Resolver, time.Duration(m.Timeout)*time.Second)
+	}
+	ips, err := resolveHost(ctx, resolver, cfg.Host)
+	if err != nil {
+		result := DownResult(err, startTime, time.Now().UTC())
+		result.ErrorCategory = shared.ErrorCategoryNetwork
+		return result
+	}
+
+	if !cfg.CheckAllIPs {
+		return t.dial(ctx, m, cfg, ips[0].String())
+	}
+
+	results := checkBackends(ips, func(ip net.IP) *Result {
+		return t.dial(ctx, m, cfg, ip.String())
+	})
+	backends := make([]BackendResult, 0, len(ips))
+	for i, ip := range ips {
+		result := results[i]
+		backends = append(backends, BackendResult{
+			IP:            ip.String(),
+			Up:            result.Status == shared.MonitorStatusUp,
+			Message:       result.Message,
+			ErrorCategory: result.ErrorCategory,
+		})
+	}
+
+	return summarizeBackends(backends, startTime, time.Now().UTC())
+}
+
+// dialCached resolves the host through the shared DNS cache and tries its addresses
+// in order, like the dialer does for a host name
+func (t *TCPExecutor) dialCached(ctx context.Context, m *Monitor, cfg *TCPConfig) *Result {
+	startTime := time.Now().UTC()
+	ips, err := t.dnsCache.LookupIP(ctx, "ip", cfg.Host)
+	if err != nil {
+		result := DownResult(err, startTime, time.Now().UTC())
+		result.ErrorCategory = shared.ErrorCategoryNetwork
+		return result
+	}
+
+	var result *Result
+	for _, ip := range ips {
+		result = t.dial(ctx, m, cfg, ip.String())
+		if result.Status == shared.MonitorStatusUp {
+			break
+		}
+	}
+	result.StartTime = startTime
+	return result
+}
+
+// dial connects to the configured port on host, which is either the configured
+// host or one of its resolved addresses
+func (t *TCPExecutor) dial(ctx context.Context, m *Monitor, cfg *TCPConfig, host string) *Result {
+	address := net.JoinHostPort(host, fmt.Sprintf("%d", cfg.Port))
 
 	startTime := time.Now().UTC()
 
-	// Create a custom dialer with timeout
-	dialer := &net.Dialer{
-		Timeout: time.Duration(m.Timeout) * time.Second,
+	// Create a custom dialer with timeout, bound to the source IP when configured
+	dialer, err := sourceDialer(time.Duration(m.Timeout)*time.Second, cfg.SourceIP)
+	if err != nil {
+		return DownResult(err, startTime, time.Now().UTC())
+	}
+	if cfg.Resolver != "" {
+		dialer.Resolver = newCustomResolver(cfg.Resolver, time.Duration(m.Timeout)*time.Second)
 	}
 
 	conn, err := dialer.DialContext(ctx, "tcp", address)
 	endTime := time.Now().UTC()
 
+	state, category := ClassifyTCPDialError(err)
+
 	if err != nil {
-		t.logger.Infof("TCP connection failed: %s, %s", m.Name, err.Error())
+		t.logger.Infof("TCP connection failed: %s, %s (%s)", m.Name, err.Error(), state)
 		return &Result{
-			Status:    shared.MonitorStatusDown,
-			Message:   fmt.Sprintf("TCP connection failed: %v", err),
-			StartTime: startTime,
-			EndTime:   endTime,
+			Status:        shared.MonitorStatusDown,
+			Message:       t.failureMessage(cfg, m, state, err),
+			StartTime:     startTime,
+			EndTime:       endTime,
+			ErrorCategory: category,
 		}
 	}
 
+	if cfg.CheckMode == TCPCheckModeSYN {
+		// Abort with RST instead of a graceful FIN so the target does not
+		// keep a half-closed connection around
+		if tcpConn, ok := conn.(*net.TCPConn); ok {
+			_ = tcpConn.SetLinger(0)
+		}
+	}
+
+	if (cfg.SendData != "" || cfg.ExpectData != "") && cfg.CheckMode != TCPCheckModeSYN {
+		if result := t.exchange(ctx, m, cfg, conn, startTime, endTime); result.Status != shared.MonitorStatusUp || cfg.HoldOpenMs == 0 {
+			conn.Close()
+			return result
+		}
+	}
+
+	if cfg.HoldOpenMs > 0 && cfg.CheckMode != TCPCheckModeSYN {
+		defer conn.Close()
+		return t.holdOpen(ctx, m, cfg, conn, startTime, endTime)
+	}
+
 	// Close the connection immediately as we only need to test connectivity
 	conn.Close()
 
@@ -80,3 +253,144 @@ func (t *TCPExecutor) Execute(ctx context.Context, m *Monitor, proxyModel *Proxy
 		EndTime:   endTime,
 	}
 }
+
+// exchange writes SendData and reads the response until it contains ExpectData, checking
+// the protocol behind the port rather than only that it accepts connections. The result
+// keeps the connect time as its end so the ping stays the connect latency.
+func (t *TCPExecutor) exchange(ctx context.Context, m *Monitor, cfg *TCPConfig, conn net.Conn, startTime, endTime time.Time) *Result {
+	deadline := time.Now().Add(time.Duration(m.Timeout) * time.Second)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return DownResult(err, startTime, endTime)
+	}
+
+	down := func(message string) *Result {
+		t.logger.Infof("TCP exchange failed: %s, %s", m.Name, message)
+		return &Result{
+			Status:        shared.MonitorStatusDown,
+			Message:       message,
+			StartTime:     startTime,
+			EndTime:       endTime,
+			ErrorCategory: shared.ErrorCategoryNetwork,
+		}
+	}
+
+	if cfg.SendData != "" {
+		if _, err := io.WriteString(conn, cfg.SendData); err != nil {
+			return down(fmt.Sprintf("TCP send failed: %v", err))
+		}
+	}
+
+	if cfg.ExpectData == "" {
+		return &Result{
+			Status:    shared.MonitorStatusUp,
+			Message:   fmt.Sprintf("TCP port %d is open, data sent", cfg.Port),
+			StartTime: startTime,
+			EndTime:   endTime,
+		}
+	}
+
+	response := make([]byte, 0, 1024)
+	buf := make([]byte, 1024)
+	for {
+		n, err := conn.Read(buf[:min(len(buf), maxTCPResponseSize-len(response))])
+		response = append(response, buf[:n]...)
+		if strings.Contains(string(response), cfg.ExpectData) {
+			t.logger.Infof("TCP response matched: %s", m.Name)
+			return &Result{
+				Status:    shared.MonitorStatusUp,
+				Message:   fmt.Sprintf("TCP port %d is open, response contains %q", cfg.Port, cfg.ExpectData),
+				StartTime: startTime,
+				EndTime:   endTime,
+			}
+		}
+		if err == nil && len(response) < maxTCPResponseSize {
+			continue
+		}
+
+		var netErr net.Error
+		if err != nil && !errors.Is(err, io.EOF) && !(errors.As(err, &netErr) && netErr.Timeout()) {
+			return down(fmt.Sprintf("TCP read failed: %v", err))
+		}
+		if len(response) == 0 {
+			return down(fmt.Sprintf("TCP response doesn't contain %q, nothing received", cfg.ExpectData))
+		}
+		return &Result{
+			Status:    shared.MonitorStatusDown,
+			Message:   fmt.Sprintf("TCP response doesn't contain %q, received %q", cfg.ExpectData, truncateTCPResponse(response)),
+			StartTime: startTime,
+			EndTime:   endTime,
+		}
+	}
+}
+
+func truncateTCPResponse(response []byte) string {
+	if len(response) <= maxTCPResponseInResult {
+		return string(response)
+	}
+	return string(response[:maxTCPResponseInResult]) + "..."
+}
+
+// holdOpen reads from the connection until the hold duration is over, catching servers
+// that accept a connection and then drop it. Data sent by the server is discarded. The
+// result keeps the connect time as its end so the ping stays the connect latency.
+func (t *TCPExecutor) holdOpen(ctx context.Context, m *Monitor, cfg *TCPConfig, conn net.Conn, startTime, endTime time.Time) *Result {
+	connectedAt := time.Now()
+	deadline := connectedAt.Add(time.Duration(cfg.HoldOpenMs) * time.Millisecond)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := conn.SetReadDeadline(deadline); err != nil {
+		return DownResult(err, startTime, endTime)
+	}
+
+	buf := make([]byte, 1024)
+	for {
+		_, err := conn.Read(buf)
+		if err == nil {
+			continue
+		}
+
+		held := time.Since(connectedAt).Round(time.Millisecond)
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			t.logger.Infof("TCP connection held open: %s, %s", m.Name, held)
+			return &Result{
+				Status:    shared.MonitorStatusUp,
+				Message:   fmt.Sprintf("TCP port %d is open, connection stayed open for %s", cfg.Port, held),
+				StartTime: startTime,
+				EndTime:   endTime,
+			}
+		}
+
+		reason := err.Error()
+		if errors.Is(err, io.EOF) {
+			reason = "closed by the server"
+		}
+		t.logger.Infof("TCP connection dropped: %s, after %s: %s", m.Name, held, reason)
+		return &Result{
+			Status:        shared.MonitorStatusDown,
+			Message:       fmt.Sprintf("TCP connection dropped after %s: %s", held, reason),
+			StartTime:     startTime,
+			EndTime:       endTime,
+			ErrorCategory: shared.ErrorCategoryNetwork,
+		}
+	}
+}
+
+func (t *TCPExecutor) failureMessage(cfg *TCPConfig, m *Monitor, state TCPPortState, err error) string {
+	if cfg.CheckMode != TCPCheckModeSYN {
+		return fmt.Sprintf("TCP connection failed: %v", err)
+	}
+
+	switch state {
+	case TCPPortClosed:
+		return fmt.Sprintf("TCP port %d is closed (connection refused)", cfg.Port)
+	case TCPPortFiltered:
+		return fmt.Sprintf("TCP port %d is filtered (no response within %ds)", cfg.Port, m.Timeout)
+	default:
+		return fmt.Sprintf("TCP port %d is unreachable: %v", cfg.Port, err)
+	}
+}
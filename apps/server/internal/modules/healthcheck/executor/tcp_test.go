@@ -0,0 +1,309 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"peekaping/internal/modules/shared"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestTCPExecutor_Validate(t *testing.T) {
+	executor := NewTCPExecutor(zap.NewNop().Sugar())
+
+	tests := []struct {
+		name          string
+		config        string
+		expectedError bool
+	}{
+		{name: "default mode", config: `{"host":"example.com","port":80}`},
+		{name: "full mode", config: `{"host":"example.com","port":80,"tcp_check_mode":"full"}`},
+		{name: "syn mode", config: `{"host":"example.com","port":80,"tcp_check_mode":"syn"}`},
+		{name: "unknown mode", config: `{"host":"example.com","port":80,"tcp_check_mode":"ack"}`, expectedError: true},
+		{name: "missing host", config: `{"port":80}`, expectedError: true},
+		{name: "invalid port", config: `{"host":"example.com","port":70000}`, expectedError: true},
+		{name: "hold open", config: `{"host":"example.com","port":80,"hold_open_ms":5000}`},
+		{name: "negative hold open", config: `{"host":"example.com","port":80,"hold_open_ms":-1}`, expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := executor.Validate(tt.config)
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestClassifyTCPDialError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		state    TCPPortState
+		category string
+	}{
+		{
+			name:     "connected",
+			err:      nil,
+			state:    TCPPortOpen,
+			category: "",
+		},
+		{
+			name:     "refused",
+			err:      &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)},
+			state:    TCPPortClosed,
+			category: shared.ErrorCategoryPortClosed,
+		},
+		{
+			name:     "dial timeout",
+			err:      &net.OpError{Op: "dial", Net: "tcp", Err: timeoutError{}},
+			state:    TCPPortFiltered,
+			category: shared.ErrorCategoryPortFiltered,
+		},
+		{
+			name:     "context deadline",
+			err:      fmt.Errorf("dial: %w", context.DeadlineExceeded),
+			state:    TCPPortFiltered,
+			category: shared.ErrorCategoryPortFiltered,
+		},
+		{
+			name:     "host unreachable",
+			err:      &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.EHOSTUNREACH)},
+			state:    TCPPortUnreachable,
+			category: shared.ErrorCategoryNetwork,
+		},
+		{
+			name:     "dns failure",
+			err:      &net.DNSError{Err: "no such host", Name: "invalid.example"},
+			state:    TCPPortUnreachable,
+			category: shared.ErrorCategoryNetwork,
+		},
+		{
+			name:     "generic error",
+			err:      errors.New("boom"),
+			state:    TCPPortUnreachable,
+			category: shared.ErrorCategoryNetwork,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, category := ClassifyTCPDialError(tt.err)
+			assert.Equal(t, tt.state, state)
+			assert.Equal(t, tt.category, category)
+		})
+	}
+}
+
+func TestTCPExecutor_Execute_SYNMode(t *testing.T) {
+	executor := NewTCPExecutor(zap.NewNop().Sugar())
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	openPort := listener.Addr().(*net.TCPAddr).Port
+	defer listener.Close()
+
+	// Grab a free port and release it so that connecting to it is refused
+	closedListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closedPort := closedListener.Addr().(*net.TCPAddr).Port
+	closedListener.Close()
+
+	newMonitor := func(port int, mode string) *Monitor {
+		return &Monitor{
+			ID:      "monitor1",
+			Name:    "tcp",
+			Type:    "tcp",
+			Timeout: 2,
+			Config:  fmt.Sprintf(`{"host":"127.0.0.1","port":%d,"tcp_check_mode":"%s"}`, port, mode),
+		}
+	}
+
+	t.Run("open port", func(t *testing.T) {
+		result := executor.Execute(context.Background(), newMonitor(openPort, TCPCheckModeSYN), nil)
+		require.NotNil(t, result)
+		assert.Equal(t, shared.MonitorStatusUp, result.Status)
+		assert.Equal(t, fmt.Sprintf("TCP port %d is open", openPort), result.Message)
+		assert.Empty(t, result.ErrorCategory)
+	})
+
+	t.Run("closed port", func(t *testing.T) {
+		result := executor.Execute(context.Background(), newMonitor(closedPort, TCPCheckModeSYN), nil)
+		require.NotNil(t, result)
+		assert.Equal(t, shared.MonitorStatusDown, result.Status)
+		assert.Equal(t, fmt.Sprintf("TCP port %d is closed (connection refused)", closedPort), result.Message)
+		assert.Equal(t, shared.ErrorCategoryPortClosed, result.ErrorCategory)
+	})
+
+	t.Run("closed port in full mode keeps the raw error message", func(t *testing.T) {
+		result := executor.Execute(context.Background(), newMonitor(closedPort, TCPCheckModeFull), nil)
+		require.NotNil(t, result)
+		assert.Equal(t, shared.MonitorStatusDown, result.Status)
+		assert.Contains(t, result.Message, "TCP connection failed")
+		assert.Equal(t, shared.ErrorCategoryPortClosed, result.ErrorCategory)
+	})
+
+	t.Run("filtered port", func(t *testing.T) {
+		// An already expired context behaves like a SYN that never gets an answer
+		ctx, cancel := context.WithTimeout(context.Background(), 0)
+		defer cancel()
+
+		result := executor.Execute(ctx, newMonitor(openPort, TCPCheckModeSYN), nil)
+		require.NotNil(t, result)
+		assert.Equal(t, shared.MonitorStatusDown, result.Status)
+		assert.Equal(t, fmt.Sprintf("TCP port %d is filtered (no response within 2s)", openPort), result.Message)
+		assert.Equal(t, shared.ErrorCategoryPortFiltered, result.ErrorCategory)
+	})
+}
+
+func TestTCPExecutor_Execute_HoldOpen(t *testing.T) {
+	executor := NewTCPExecutor(zap.NewNop().Sugar())
+
+	// serve accepts connections and hands each one to handle
+	serve := func(t *testing.T, handle func(conn net.Conn)) int {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		t.Cleanup(func() { listener.Close() })
+
+		go func() {
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				go handle(conn)
+			}
+		}()
+		return listener.Addr().(*net.TCPAddr).Port
+	}
+
+	newMonitor := func(port int) *Monitor {
+		return &Monitor{
+			ID:      "monitor1",
+			Name:    "tcp",
+			Type:    "tcp",
+			Timeout: 2,
+			Config:  fmt.Sprintf(`{"host":"127.0.0.1","port":%d,"hold_open_ms":300}`, port),
+		}
+	}
+
+	t.Run("server closing right after connect is down", func(t *testing.T) {
+		port := serve(t, func(conn net.Conn) { conn.Close() })
+
+		result := executor.Execute(context.Background(), newMonitor(port), nil)
+		require.NotNil(t, result)
+		assert.Equal(t, shared.MonitorStatusDown, result.Status)
+		assert.Contains(t, result.Message, "TCP connection dropped after")
+		assert.Equal(t, shared.ErrorCategoryNetwork, result.ErrorCategory)
+	})
+
+	t.Run("server keeping the connection is up", func(t *testing.T) {
+		port := serve(t, func(conn net.Conn) {
+			defer conn.Close()
+			// A banner must not end the hold
+			fmt.Fprint(conn, "220 ready\r\n")
+			_, _ = io.Copy(io.Discard, conn)
+		})
+
+		result := executor.Execute(context.Background(), newMonitor(port), nil)
+		require.NotNil(t, result)
+		assert.Equal(t, shared.MonitorStatusUp, result.Status, result.Message)
+		assert.Contains(t, result.Message, "connection stayed open for")
+
+		held, err := time.ParseDuration(result.Message[strings.LastIndex(result.Message, " ")+1:])
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, held, 300*time.Millisecond)
+	})
+}
+
+func TestTCPExecutor_Execute_SendExpect(t *testing.T) {
+	executor := NewTCPExecutor(zap.NewNop().Sugar())
+
+	// serve accepts one connection at a time and hands it to handle
+	serve := func(t *testing.T, handle func(conn net.Conn)) int {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		t.Cleanup(func() { listener.Close() })
+
+		go func() {
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				handle(conn)
+				conn.Close()
+			}
+		}()
+		return listener.Addr().(*net.TCPAddr).Port
+	}
+
+	newMonitor := func(port int, config string) *Monitor {
+		return &Monitor{
+			ID:      "monitor1",
+			Name:    "tcp",
+			Type:    "tcp",
+			Timeout: 1,
+			Config:  fmt.Sprintf(`{"host":"127.0.0.1","port":%d%s}`, port, config),
+		}
+	}
+
+	// echo answers each line with "+" followed by the line
+	echo := serve(t, func(conn net.Conn) {
+		buf := make([]byte, 64)
+		n, _ := conn.Read(buf)
+		fmt.Fprintf(conn, "+%s", buf[:n])
+	})
+	banner := serve(t, func(conn net.Conn) {
+		fmt.Fprint(conn, "SSH-2.0-OpenSSH_9.6\r\n")
+		time.Sleep(100 * time.Millisecond)
+	})
+	silent := serve(t, func(conn net.Conn) {
+		_, _ = io.Copy(io.Discard, conn)
+	})
+
+	tests := []struct {
+		name     string
+		port     int
+		config   string
+		status   shared.MonitorStatus
+		message  string
+		category string
+	}{
+		{"sent data answered", echo, `,"send_data":"PING\r\n","expect_data":"+PING"`, shared.MonitorStatusUp, fmt.Sprintf(`TCP port %d is open, response contains "+PING"`, echo), ""},
+		{"banner on connect", banner, `,"expect_data":"SSH-2.0"`, shared.MonitorStatusUp, fmt.Sprintf(`TCP port %d is open, response contains "SSH-2.0"`, banner), ""},
+		{"banner mismatch", banner, `,"expect_data":"220 "`, shared.MonitorStatusDown, `TCP response doesn't contain "220 ", received "SSH-2.0-OpenSSH_9.6\r\n"`, ""},
+		{"send only", silent, `,"send_data":"hello"`, shared.MonitorStatusUp, fmt.Sprintf("TCP port %d is open, data sent", silent), ""},
+		{"no answer", silent, `,"send_data":"PING\r\n","expect_data":"+PONG"`, shared.MonitorStatusDown, `TCP response doesn't contain "+PONG", nothing received`, shared.ErrorCategoryNetwork},
+		{"ignored in syn mode", silent, `,"tcp_check_mode":"syn","expect_data":"+PONG"`, shared.MonitorStatusUp, fmt.Sprintf("TCP port %d is open", silent), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := executor.Execute(context.Background(), newMonitor(tt.port, tt.config), nil)
+			require.NotNil(t, result)
+			assert.Equal(t, tt.status, result.Status)
+			assert.Equal(t, tt.message, result.Message)
+			assert.Equal(t, tt.category, result.ErrorCategory)
+		})
+	}
+}
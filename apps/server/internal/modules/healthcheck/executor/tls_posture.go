@@ -0,0 +1,208 @@
+package executor
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"net"
+	"net/url"
+	"peekaping/internal/modules/certificate"
+	"sync"
+	"time"
+)
+
+const (
+	// tlsTicketWait is how long the probe reads after a TLS 1.3 handshake for the session
+	// tickets the server sends once the handshake completes
+	tlsTicketWait = 250 * time.Millisecond
+
+	tlsExtensionSupportedVersions = 0x002b
+	tlsExtensionRenegotiationInfo = 0xff01
+)
+
+// TLSPosture is what a probe of the server's TLS endpoint found out
+type TLSPosture struct {
+	// ALPN is the protocol the server picked out of h2 and http/1.1
+	ALPN string
+	// SessionResumption is whether a second handshake resumed the session of the first
+	SessionResumption bool
+	// SecureRenegotiation is whether the server supports RFC 5746 secure renegotiation.
+	// TLS 1.3 has no renegotiation at all so it always counts as secure.
+	SecureRenegotiation bool
+}
+
+// applyTLSPosture records the probed posture in the TLS info of the check and returns the
+// note added to the message of a successful check
+func applyTLSPosture(tlsInfo *certificate.TLSInfo, posture *TLSPosture, reportResumption bool) string {
+	if tlsInfo.ALPN == "" {
+		tlsInfo.ALPN = posture.ALPN
+	}
+	tlsInfo.SecureRenegotiation = &posture.SecureRenegotiation
+	if !reportResumption {
+		return ""
+	}
+	tlsInfo.SessionResumption = &posture.SessionResumption
+	if posture.SessionResumption {
+		return "TLS session resumption supported"
+	}
+	return "TLS session resumption not supported"
+}
+
+// tlsProbeAddr is the "host:port" of the TLS endpoint of u
+func tlsProbeAddr(u *url.URL) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+	return net.JoinHostPort(u.Hostname(), "443")
+}
+
+type dialContextFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// probeTLSPosture handshakes twice with addr, sharing a session cache between the
+// handshakes to find out whether the server resumes sessions, and inspects the first
+// ServerHello for the renegotiation_info extension
+func probeTLSPosture(ctx context.Context, dial dialContextFunc, addr, serverName string) (*TLSPosture, error) {
+	cfg := &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: true, // the certificate is verified by the check itself
+		NextProtos:         []string{"h2", "http/1.1"},
+		ClientSessionCache: tls.NewLRUClientSessionCache(1),
+	}
+
+	first, serverHello, err := tlsProbeHandshake(ctx, dial, addr, cfg)
+	if err != nil {
+		return nil, err
+	}
+	posture := &TLSPosture{ALPN: first.NegotiatedProtocol}
+	if first.Version >= tls.VersionTLS13 {
+		posture.SecureRenegotiation = true
+	} else if posture.SecureRenegotiation, err = serverHelloHasRenegotiationInfo(serverHello); err != nil {
+		return nil, err
+	}
+
+	second, _, err := tlsProbeHandshake(ctx, dial, addr, cfg)
+	if err != nil {
+		return nil, err
+	}
+	posture.SessionResumption = second.DidResume
+
+	return posture, nil
+}
+
+// tlsProbeHandshake returns the state of a completed handshake along with the raw bytes
+// the server sent during it
+func tlsProbeHandshake(ctx context.Context, dial dialContextFunc, addr string, cfg *tls.Config) (*tls.ConnectionState, []byte, error) {
+	raw, err := dial(ctx, "tcp", addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	recorder := &recordingConn{Conn: raw}
+	conn := tls.Client(recorder, cfg)
+	defer conn.Close()
+
+	if err := conn.HandshakeContext(ctx); err != nil {
+		return nil, nil, err
+	}
+	recorder.stop()
+
+	state := conn.ConnectionState()
+	if state.Version >= tls.VersionTLS13 {
+		// Session tickets arrive after the handshake and are only processed on read
+		_ = conn.SetReadDeadline(time.Now().Add(tlsTicketWait))
+		_, _ = conn.Read(make([]byte, 1))
+	}
+
+	return &state, recorder.bytes(), nil
+}
+
+// recordingConn keeps the bytes read from the connection until stopped
+type recordingConn struct {
+	net.Conn
+
+	mu      sync.Mutex
+	data    []byte
+	stopped bool
+}
+
+func (c *recordingConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	c.mu.Lock()
+	if !c.stopped {
+		c.data = append(c.data, p[:n]...)
+	}
+	c.mu.Unlock()
+	return n, err
+}
+
+func (c *recordingConn) stop() {
+	c.mu.Lock()
+	c.stopped = true
+	c.mu.Unlock()
+}
+
+func (c *recordingConn) bytes() []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.data
+}
+
+var errNoServerHello = errors.New("no ServerHello received")
+
+// serverHelloHasRenegotiationInfo reports whether the ServerHello at the start of the
+// handshake records in data carries the renegotiation_info extension (RFC 5746). A server
+// answering with TLS 1.3 through supported_versions also counts as secure.
+func serverHelloHasRenegotiationInfo(data []byte) (bool, error) {
+	// The handshake messages may be split over several records
+	var handshake []byte
+	for len(data) >= 5 {
+		length := int(binary.BigEndian.Uint16(data[3:5]))
+		if data[0] != 22 || len(data) < 5+length {
+			break
+		}
+		handshake = append(handshake, data[5:5+length]...)
+		data = data[5+length:]
+	}
+
+	if len(handshake) < 4 || handshake[0] != 2 {
+		return false, errNoServerHello
+	}
+	length := int(handshake[1])<<16 | int(handshake[2])<<8 | int(handshake[3])
+	if len(handshake) < 4+length {
+		return false, errNoServerHello
+	}
+	hello := handshake[4 : 4+length]
+
+	// version, random, session id, cipher suite and compression method
+	offset := 2 + 32
+	if len(hello) <= offset {
+		return false, errNoServerHello
+	}
+	offset += 1 + int(hello[offset]) + 2 + 1
+	if len(hello) < offset+2 {
+		// No extensions at all
+		return false, nil
+	}
+	extensions := hello[offset+2:]
+	if end := int(binary.BigEndian.Uint16(hello[offset:])); end < len(extensions) {
+		extensions = extensions[:end]
+	}
+
+	for len(extensions) >= 4 {
+		kind := binary.BigEndian.Uint16(extensions)
+		size := int(binary.BigEndian.Uint16(extensions[2:]))
+		if len(extensions) < 4+size {
+			break
+		}
+		body := extensions[4 : 4+size]
+		switch {
+		case kind == tlsExtensionRenegotiationInfo:
+			return true, nil
+		case kind == tlsExtensionSupportedVersions && size == 2 && binary.BigEndian.Uint16(body) >= tls.VersionTLS13:
+			return true, nil
+		}
+		extensions = extensions[4+size:]
+	}
+
+	return false, nil
+}
@@ -0,0 +1,158 @@
+package executor
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"peekaping/internal/modules/shared"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func tlsPostureMonitor(url string) *Monitor {
+	return &Monitor{
+		ID:       "monitor1",
+		Type:     "http",
+		Name:     "TLS posture",
+		Interval: 30,
+		Timeout:  5,
+		Config: fmt.Sprintf(`{
+			"url": "%s",
+			"method": "GET",
+			"encoding": "json",
+			"accepted_statuscodes": ["2XX"],
+			"authMethod": "none",
+			"ignore_tls_errors": true,
+			"check_session_resumption": true,
+			"fail_on_insecure_renegotiation": true
+		}`, url),
+	}
+}
+
+func TestHTTPExecutor_Execute_TLSPosture(t *testing.T) {
+	executor := NewHTTPExecutor(zap.NewNop().Sugar())
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	t.Run("TLS 1.3 server with session tickets", func(t *testing.T) {
+		server := httptest.NewUnstartedServer(handler)
+		server.EnableHTTP2 = true
+		server.StartTLS()
+		defer server.Close()
+
+		result := executor.Execute(context.Background(), tlsPostureMonitor(server.URL), nil)
+
+		assert.Equal(t, shared.MonitorStatusUp, result.Status, result.Message)
+		assert.Contains(t, result.Message, "TLS session resumption supported")
+		require.NotNil(t, result.TLSInfo)
+		assert.Equal(t, "h2", result.TLSInfo.ALPN)
+		require.NotNil(t, result.TLSInfo.SessionResumption)
+		assert.True(t, *result.TLSInfo.SessionResumption)
+		require.NotNil(t, result.TLSInfo.SecureRenegotiation)
+		assert.True(t, *result.TLSInfo.SecureRenegotiation)
+	})
+
+	t.Run("TLS 1.2 server without session tickets", func(t *testing.T) {
+		server := httptest.NewUnstartedServer(handler)
+		server.TLS = &tls.Config{MaxVersion: tls.VersionTLS12, SessionTicketsDisabled: true}
+		server.StartTLS()
+		defer server.Close()
+
+		result := executor.Execute(context.Background(), tlsPostureMonitor(server.URL), nil)
+
+		assert.Equal(t, shared.MonitorStatusUp, result.Status, result.Message)
+		assert.Contains(t, result.Message, "TLS session resumption not supported")
+		require.NotNil(t, result.TLSInfo)
+		assert.Equal(t, "http/1.1", result.TLSInfo.ALPN)
+		require.NotNil(t, result.TLSInfo.SessionResumption)
+		assert.False(t, *result.TLSInfo.SessionResumption)
+		require.NotNil(t, result.TLSInfo.SecureRenegotiation)
+		assert.True(t, *result.TLSInfo.SecureRenegotiation)
+	})
+
+	t.Run("options off", func(t *testing.T) {
+		server := httptest.NewTLSServer(handler)
+		defer server.Close()
+
+		monitor := tlsPostureMonitor(server.URL)
+		monitor.Config = fmt.Sprintf(`{
+			"url": "%s",
+			"method": "GET",
+			"encoding": "json",
+			"accepted_statuscodes": ["2XX"],
+			"authMethod": "none",
+			"ignore_tls_errors": true
+		}`, server.URL)
+		result := executor.Execute(context.Background(), monitor, nil)
+
+		assert.Equal(t, shared.MonitorStatusUp, result.Status)
+		assert.NotContains(t, result.Message, "resumption")
+		require.NotNil(t, result.TLSInfo)
+		assert.Nil(t, result.TLSInfo.SessionResumption)
+		assert.Nil(t, result.TLSInfo.SecureRenegotiation)
+	})
+}
+
+// serverHelloRecord builds a handshake record holding a TLS 1.2 ServerHello with the
+// given extensions
+func serverHelloRecord(extensions ...uint16) []byte {
+	hello := []byte{0x03, 0x03}
+	hello = append(hello, make([]byte, 32)...) // random
+	hello = append(hello, 0)                   // empty session id
+	hello = append(hello, 0xc0, 0x2f, 0)       // cipher suite, no compression
+	var exts []byte
+	for _, ext := range extensions {
+		exts = binary.BigEndian.AppendUint16(exts, ext)
+		if ext == tlsExtensionRenegotiationInfo {
+			exts = append(exts, 0, 1, 0)
+		} else {
+			exts = append(exts, 0, 0)
+		}
+	}
+	if extensions != nil {
+		hello = binary.BigEndian.AppendUint16(hello, uint16(len(exts)))
+		hello = append(hello, exts...)
+	}
+
+	handshake := []byte{2, 0, byte(len(hello) >> 8), byte(len(hello))}
+	handshake = append(handshake, hello...)
+	record := []byte{22, 0x03, 0x03}
+	record = binary.BigEndian.AppendUint16(record, uint16(len(handshake)))
+	return append(record, handshake...)
+}
+
+func TestServerHelloHasRenegotiationInfo(t *testing.T) {
+	secure, err := serverHelloHasRenegotiationInfo(serverHelloRecord(0x0017, tlsExtensionRenegotiationInfo))
+	require.NoError(t, err)
+	assert.True(t, secure)
+
+	secure, err = serverHelloHasRenegotiationInfo(serverHelloRecord(0x0017, 0x000b))
+	require.NoError(t, err)
+	assert.False(t, secure, "server without renegotiation_info")
+
+	secure, err = serverHelloHasRenegotiationInfo(serverHelloRecord())
+	require.NoError(t, err)
+	assert.False(t, secure, "server without extensions")
+
+	// A ServerHello split over two records
+	record := serverHelloRecord(tlsExtensionRenegotiationInfo)
+	handshake := record[5:]
+	split := []byte{22, 0x03, 0x03, 0, 10}
+	split = append(split, handshake[:10]...)
+	split = append(split, 22, 0x03, 0x03)
+	split = binary.BigEndian.AppendUint16(split, uint16(len(handshake)-10))
+	split = append(split, handshake[10:]...)
+	secure, err = serverHelloHasRenegotiationInfo(split)
+	require.NoError(t, err)
+	assert.True(t, secure)
+
+	_, err = serverHelloHasRenegotiationInfo([]byte{21, 0x03, 0x03, 0, 2, 2, 40})
+	assert.ErrorIs(t, err, errNoServerHello)
+}
@@ -0,0 +1,343 @@
+package executor
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"peekaping/internal/modules/shared"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
+	"go.uber.org/zap"
+)
+
+// Where a transaction step extracts a variable from
+const (
+	TransactionSourceJSON   = "json"
+	TransactionSourceRegex  = "regex"
+	TransactionSourceHeader = "header"
+)
+
+// TransactionConfig is an ordered list of HTTP requests run as one check, e.g. a login
+// followed by a request with the token it returned. Cookies carry over between steps.
+type TransactionConfig struct {
+	Steps           []TransactionStep `json:"steps" validate:"required,min=1,max=20,dive"`
+	IgnoreTlsErrors bool              `json:"ignore_tls_errors"`
+	// SourceIP is the local address the requests are sent from, on hosts with several addresses
+	SourceIP string `json:"source_ip,omitempty" validate:"omitempty,ip" example:"192.168.1.10"`
+	// DNSCacheBypass resolves the step hosts on every request instead of through the shared DNS cache
+	DNSCacheBypass bool `json:"dns_cache_bypass,omitempty"`
+}
+
+// redacted is a copy of the config that is safe to log, without the values of sensitive
+// headers
+func (c TransactionConfig) redacted() TransactionConfig {
+	steps := make([]TransactionStep, len(c.Steps))
+	for i, step := range c.Steps {
+		step.Headers = redactHeaders(step.Headers)
+		steps[i] = step
+	}
+	c.Steps = steps
+	return c
+}
+
+// TransactionStep is one request of a transaction. Url, Headers and Body may reference
+// variables extracted by earlier steps as {{name}}.
+type TransactionStep struct {
+	Name                string            `json:"name"`
+	Url                 string            `json:"url" validate:"required"`
+	Method              string            `json:"method" validate:"required,oneof=GET POST PUT DELETE PATCH HEAD OPTIONS"`
+	Headers             map[string]string `json:"headers,omitempty"`
+	Body                string            `json:"body,omitempty"`
+	AcceptedStatusCodes []string          `json:"accepted_statuscodes" validate:"required,dive,oneof=2XX 3XX 4XX 5XX"`
+	// Extract stores values of the response in variables for the following steps
+	Extract []TransactionExtract `json:"extract,omitempty" validate:"omitempty,dive"`
+
+	// Response assertions, all set ones must pass
+	Keyword       string `json:"keyword,omitempty"`
+	InvertKeyword bool   `json:"invert_keyword,omitempty"`
+	JsonQuery     string `json:"json_query,omitempty"`
+	JsonCondition string `json:"json_condition,omitempty" validate:"omitempty,oneof='==' '!=' '>' '<' '>=' '<='"`
+	ExpectedValue string `json:"expected_value,omitempty"`
+}
+
+// TransactionExtract reads a value of a step's response into a variable. Expression is a
+// gjson path for json, a regular expression for regex (its first group when it has one)
+// and a header name for header.
+type TransactionExtract struct {
+	Variable   string `json:"variable" validate:"required"`
+	Source     string `json:"source" validate:"required,oneof=json regex header"`
+	Expression string `json:"expression" validate:"required"`
+}
+
+var (
+	transactionVariableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	transactionPlaceholder  = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+)
+
+type TransactionExecutor struct {
+	logger *zap.SugaredLogger
+	// dnsCache is shared between executors, nil when caching is disabled
+	dnsCache *DNSCache
+}
+
+func NewTransactionExecutor(logger *zap.SugaredLogger) *TransactionExecutor {
+	return &TransactionExecutor{
+		logger: logger,
+	}
+}
+
+func (t *TransactionExecutor) Unmarshal(configJSON string) (any, error) {
+	return GenericUnmarshal[TransactionConfig](configJSON)
+}
+
+func (t *TransactionExecutor) Validate(configJSON string) error {
+	cfgAny, err := t.Unmarshal(configJSON)
+	if err != nil {
+		return err
+	}
+	cfg := cfgAny.(*TransactionConfig)
+	if err := GenericValidator(cfg); err != nil {
+		return err
+	}
+
+	for i, step := range cfg.Steps {
+		for _, extract := range step.Extract {
+			if !transactionVariableName.MatchString(extract.Variable) {
+				return fmt.Errorf("step %d: invalid variable name %q, use letters, digits and underscores", i+1, extract.Variable)
+			}
+			if extract.Source == TransactionSourceRegex {
+				if _, err := regexp.Compile(extract.Expression); err != nil {
+					return fmt.Errorf("step %d: invalid regex for variable %q: %w", i+1, extract.Variable, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (t *TransactionExecutor) Execute(ctx context.Context, m *Monitor, proxyModel *Proxy) *Result {
+	cfgAny, err := t.Unmarshal(m.Config)
+	if err != nil {
+		return DownResult(err, time.Now().UTC(), time.Now().UTC())
+	}
+	cfg := cfgAny.(*TransactionConfig)
+
+	t.logger.Debugf("execute http transaction cfg: %+v", cfg.redacted())
+
+	client, err := t.newClient(cfg, proxyModel)
+	if err != nil {
+		return DownResult(err, time.Now().UTC(), time.Now().UTC())
+	}
+
+	// The monitor timeout covers the whole transaction, not each step
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(m.Timeout)*time.Second)
+	defer cancel()
+
+	variables := make(map[string]string)
+	startTime := time.Now().UTC()
+	for i, step := range cfg.Steps {
+		if err := t.runStep(ctx, client, &step, variables); err != nil {
+			t.logger.Infof("HTTP transaction failed: %s, %s: %s", m.Name, stepLabel(i, &step), err.Error())
+			result := DownResult(fmt.Errorf("%s failed: %w", stepLabel(i, &step), err), startTime, time.Now().UTC())
+			var netErr *transactionNetworkError
+			if errors.As(err, &netErr) {
+				result.ErrorCategory = shared.ErrorCategoryNetwork
+			}
+			return result
+		}
+	}
+	endTime := time.Now().UTC()
+
+	return &Result{
+		Status:    shared.MonitorStatusUp,
+		Message:   fmt.Sprintf("All %d steps passed in %dms", len(cfg.Steps), endTime.Sub(startTime).Milliseconds()),
+		StartTime: startTime,
+		EndTime:   endTime,
+	}
+}
+
+// newClient builds the client shared by the steps of one check, with its own cookie jar
+func (t *TransactionExecutor) newClient(cfg *TransactionConfig, proxyModel *Proxy) (*http.Client, error) {
+	dialer, err := sourceDialer(0, cfg.SourceIP)
+	if err != nil {
+		return nil, err
+	}
+
+	baseTransport := &http.Transport{}
+	if t.dnsCache != nil && proxyModel == nil && !cfg.DNSCacheBypass {
+		baseTransport.DialContext = t.dnsCache.DialContext(dialer)
+	} else if cfg.SourceIP != "" {
+		baseTransport.DialContext = dialer.DialContext
+	}
+	if cfg.IgnoreTlsErrors {
+		baseTransport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Client{
+		Transport: buildProxyTransport(baseTransport, proxyModel),
+		Jar:       jar,
+	}, nil
+}
+
+// transactionNetworkError marks a step that failed before a response was received
+type transactionNetworkError struct {
+	err error
+}
+
+func (e *transactionNetworkError) Error() string { return e.err.Error() }
+
+func (e *transactionNetworkError) Unwrap() error { return e.err }
+
+// runStep sends one step, checks its response and stores its extracted variables
+func (t *TransactionExecutor) runStep(ctx context.Context, client *http.Client, step *TransactionStep, variables map[string]string) error {
+	rawURL, err := substituteVariables(step.Url, variables)
+	if err != nil {
+		return err
+	}
+	if u, err := url.Parse(rawURL); err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("invalid url %q", rawURL)
+	}
+	body, err := substituteVariables(step.Body, variables)
+	if err != nil {
+		return err
+	}
+
+	var bodyReader io.Reader
+	if body != "" {
+		bodyReader = strings.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, step.Method, rawURL, bodyReader)
+	if err != nil {
+		return err
+	}
+	setDefaultHeaders(req)
+	for name, value := range step.Headers {
+		value, err := substituteVariables(value, variables)
+		if err != nil {
+			return err
+		}
+		req.Header.Set(name, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return &transactionNetworkError{err: err}
+	}
+	defer resp.Body.Close()
+
+	if !isStatusAccepted(resp.StatusCode, step.AcceptedStatusCodes) {
+		return fmt.Errorf("HTTP request failed with status: %d", resp.StatusCode)
+	}
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %v", err)
+	}
+	responseBody := string(bodyBytes)
+
+	if step.Keyword != "" && !checkKeyword(responseBody, step.Keyword, step.InvertKeyword) {
+		if step.InvertKeyword {
+			return fmt.Errorf("keyword '%s' found in response (expected absent)", step.Keyword)
+		}
+		return fmt.Errorf("keyword '%s' not found in response", step.Keyword)
+	}
+
+	if step.JsonQuery != "" {
+		isValid, err := checkJsonQuery(responseBody, step.JsonQuery, step.JsonCondition, step.ExpectedValue)
+		if err != nil {
+			return fmt.Errorf("JSON query validation error: %v", err)
+		}
+		if !isValid {
+			condition := step.JsonCondition
+			if condition == "" {
+				condition = "=="
+			}
+			return fmt.Errorf("JSON query validation failed: query '%s' with condition '%s' and expected value '%s'",
+				step.JsonQuery, condition, step.ExpectedValue)
+		}
+	}
+
+	for _, extract := range step.Extract {
+		value, err := extractValue(&extract, resp.Header, responseBody)
+		if err != nil {
+			return fmt.Errorf("failed to extract %q: %w", extract.Variable, err)
+		}
+		variables[extract.Variable] = value
+	}
+	return nil
+}
+
+// extractValue reads the value of one extraction from a response
+func extractValue(extract *TransactionExtract, header http.Header, body string) (string, error) {
+	switch extract.Source {
+	case TransactionSourceJSON:
+		if !gjson.Valid(body) {
+			return "", errors.New("response is not valid JSON")
+		}
+		result := gjson.Get(body, extract.Expression)
+		if !result.Exists() {
+			return "", fmt.Errorf("JSON path '%s' not found in response", extract.Expression)
+		}
+		return result.String(), nil
+	case TransactionSourceRegex:
+		re, err := regexp.Compile(extract.Expression)
+		if err != nil {
+			return "", err
+		}
+		match := re.FindStringSubmatch(body)
+		if match == nil {
+			return "", fmt.Errorf("regex '%s' did not match the response", extract.Expression)
+		}
+		if len(match) > 1 {
+			return match[1], nil
+		}
+		return match[0], nil
+	case TransactionSourceHeader:
+		values := header.Values(extract.Expression)
+		if len(values) == 0 {
+			return "", fmt.Errorf("header '%s' not found in response", extract.Expression)
+		}
+		return values[0], nil
+	default:
+		return "", fmt.Errorf("unsupported source: %s", extract.Source)
+	}
+}
+
+// substituteVariables replaces {{name}} placeholders with extracted values. Referencing a
+// variable no earlier step extracted is an error rather than an empty value.
+func substituteVariables(value string, variables map[string]string) (string, error) {
+	var missing []string
+	result := transactionPlaceholder.ReplaceAllStringFunc(value, func(placeholder string) string {
+		name := transactionPlaceholder.FindStringSubmatch(placeholder)[1]
+		v, ok := variables[name]
+		if !ok {
+			missing = append(missing, name)
+			return placeholder
+		}
+		return v
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("undefined variable(s): %s", strings.Join(missing, ", "))
+	}
+	return result, nil
+}
+
+func stepLabel(i int, step *TransactionStep) string {
+	if step.Name != "" {
+		return fmt.Sprintf("Step %d (%s)", i+1, step.Name)
+	}
+	return fmt.Sprintf("Step %d", i+1)
+}
@@ -0,0 +1,248 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"peekaping/internal/modules/shared"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestTransactionExecutor_Validate(t *testing.T) {
+	executor := NewTransactionExecutor(zap.NewNop().Sugar())
+
+	tests := []struct {
+		name          string
+		config        string
+		expectedError bool
+	}{
+		{
+			name: "valid transaction",
+			config: `{"steps": [
+				{"name": "login", "url": "http://example.com/login", "method": "POST", "accepted_statuscodes": ["2XX"],
+				 "extract": [{"variable": "token", "source": "json", "expression": "token"}]},
+				{"url": "http://example.com/me", "method": "GET", "accepted_statuscodes": ["2XX"],
+				 "headers": {"Authorization": "Bearer {{token}}"}}
+			]}`,
+			expectedError: false,
+		},
+		{
+			name:          "no steps",
+			config:        `{"steps": []}`,
+			expectedError: true,
+		},
+		{
+			name:          "invalid method",
+			config:        `{"steps": [{"url": "http://example.com", "method": "FETCH", "accepted_statuscodes": ["2XX"]}]}`,
+			expectedError: true,
+		},
+		{
+			name: "unknown extract source",
+			config: `{"steps": [{"url": "http://example.com", "method": "GET", "accepted_statuscodes": ["2XX"],
+				"extract": [{"variable": "token", "source": "cookie", "expression": "session"}]}]}`,
+			expectedError: true,
+		},
+		{
+			name: "invalid variable name",
+			config: `{"steps": [{"url": "http://example.com", "method": "GET", "accepted_statuscodes": ["2XX"],
+				"extract": [{"variable": "my-token", "source": "json", "expression": "token"}]}]}`,
+			expectedError: true,
+		},
+		{
+			name: "invalid regex",
+			config: `{"steps": [{"url": "http://example.com", "method": "GET", "accepted_statuscodes": ["2XX"],
+				"extract": [{"variable": "token", "source": "regex", "expression": "token=("}]}]}`,
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := executor.Validate(tt.config)
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// newLoginServer serves a login endpoint returning a token and a profile endpoint that
+// requires it as a bearer token
+func newLoginServer(t *testing.T) *httptest.Server {
+	const token = "secret-token-42"
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		var credentials struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+		}
+		if r.Method != http.MethodPost || json.NewDecoder(r.Body).Decode(&credentials) != nil ||
+			credentials.Username != "admin" || credentials.Password != "hunter2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("X-Request-Id", "req-1")
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"data": {"token": %q, "expires_in": 3600}}`, token)
+	})
+	mux.HandleFunc("/profile", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"user": {"name": "admin", "request": %q}}`, r.Header.Get("X-Correlation-Id"))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func loginTransactionConfig(serverURL, password, extractPath, expectedName string) string {
+	return fmt.Sprintf(`{"steps": [
+		{
+			"name": "login",
+			"url": "%[1]s/login",
+			"method": "POST",
+			"headers": {"Content-Type": "application/json"},
+			"body": "{\"username\": \"admin\", \"password\": \"%[2]s\"}",
+			"accepted_statuscodes": ["2XX"],
+			"extract": [
+				{"variable": "token", "source": "json", "expression": %[3]q},
+				{"variable": "request_id", "source": "header", "expression": "X-Request-Id"}
+			]
+		},
+		{
+			"name": "fetch profile",
+			"url": "%[1]s/profile",
+			"method": "GET",
+			"headers": {"Authorization": "Bearer {{token}}", "X-Correlation-Id": "{{ request_id }}"},
+			"accepted_statuscodes": ["2XX"],
+			"json_query": "user.name",
+			"json_condition": "==",
+			"expected_value": %[4]q
+		}
+	]}`, serverURL, password, extractPath, expectedName)
+}
+
+func TestTransactionExecutor_Execute_LoginThenFetch(t *testing.T) {
+	server := newLoginServer(t)
+	executor := NewTransactionExecutor(zap.NewNop().Sugar())
+
+	tests := []struct {
+		name            string
+		config          string
+		expectedStatus  shared.MonitorStatus
+		expectedMessage string
+	}{
+		{
+			name:            "token is extracted and used by the next step",
+			config:          loginTransactionConfig(server.URL, "hunter2", "data.token", "admin"),
+			expectedStatus:  shared.MonitorStatusUp,
+			expectedMessage: "All 2 steps passed",
+		},
+		{
+			name:            "login rejected",
+			config:          loginTransactionConfig(server.URL, "wrong", "data.token", "admin"),
+			expectedStatus:  shared.MonitorStatusDown,
+			expectedMessage: "Step 1 (login) failed: HTTP request failed with status: 401",
+		},
+		{
+			name:            "token missing from login response",
+			config:          loginTransactionConfig(server.URL, "hunter2", "data.access_token", "admin"),
+			expectedStatus:  shared.MonitorStatusDown,
+			expectedMessage: `Step 1 (login) failed: failed to extract "token": JSON path 'data.access_token' not found in response`,
+		},
+		{
+			name:            "assertion of the second step fails",
+			config:          loginTransactionConfig(server.URL, "hunter2", "data.token", "root"),
+			expectedStatus:  shared.MonitorStatusDown,
+			expectedMessage: "Step 2 (fetch profile) failed: JSON query validation failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, executor.Validate(tt.config))
+
+			monitor := &Monitor{
+				ID:       "test-monitor",
+				Type:     "http-transaction",
+				Name:     "Test Monitor",
+				Interval: 30,
+				Timeout:  5,
+				Config:   tt.config,
+			}
+
+			result := executor.Execute(context.Background(), monitor, nil)
+
+			assert.Equal(t, tt.expectedStatus, result.Status)
+			assert.Contains(t, result.Message, tt.expectedMessage)
+		})
+	}
+}
+
+func TestTransactionExecutor_Execute_RegexExtractAndCookies(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/form", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc"})
+		fmt.Fprint(w, `<form><input name="csrf" value="csrf-123"></form>`)
+	})
+	mux.HandleFunc("/submit", func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie("session")
+		if err != nil || cookie.Value != "abc" || r.URL.Query().Get("csrf") != "csrf-123" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fmt.Fprint(w, "saved")
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	config := fmt.Sprintf(`{"steps": [
+		{"url": "%[1]s/form", "method": "GET", "accepted_statuscodes": ["2XX"],
+		 "extract": [{"variable": "csrf", "source": "regex", "expression": "name=\"csrf\" value=\"([^\"]+)\""}]},
+		{"url": "%[1]s/submit?csrf={{csrf}}", "method": "POST", "accepted_statuscodes": ["2XX"], "keyword": "saved"}
+	]}`, server.URL)
+
+	executor := NewTransactionExecutor(zap.NewNop().Sugar())
+	require.NoError(t, executor.Validate(config))
+
+	result := executor.Execute(context.Background(), &Monitor{Type: "http-transaction", Timeout: 5, Config: config}, nil)
+	assert.Equal(t, shared.MonitorStatusUp, result.Status, result.Message)
+}
+
+func TestTransactionExecutor_Execute_UndefinedVariable(t *testing.T) {
+	executor := NewTransactionExecutor(zap.NewNop().Sugar())
+	config := `{"steps": [{"url": "http://127.0.0.1/{{missing}}", "method": "GET", "accepted_statuscodes": ["2XX"]}]}`
+
+	result := executor.Execute(context.Background(), &Monitor{Type: "http-transaction", Timeout: 5, Config: config}, nil)
+
+	assert.Equal(t, shared.MonitorStatusDown, result.Status)
+	assert.Equal(t, "Step 1 failed: undefined variable(s): missing", result.Message)
+}
+
+func TestTransactionExecutor_Execute_NetworkError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	serverURL := server.URL
+	server.Close()
+
+	executor := NewTransactionExecutor(zap.NewNop().Sugar())
+	config := fmt.Sprintf(`{"steps": [{"name": "home", "url": %q, "method": "GET", "accepted_statuscodes": ["2XX"]}]}`, serverURL)
+
+	result := executor.Execute(context.Background(), &Monitor{Type: "http-transaction", Timeout: 5, Config: config}, nil)
+
+	assert.Equal(t, shared.MonitorStatusDown, result.Status)
+	assert.Contains(t, result.Message, "Step 1 (home) failed:")
+	assert.Equal(t, shared.ErrorCategoryNetwork, result.ErrorCategory)
+}
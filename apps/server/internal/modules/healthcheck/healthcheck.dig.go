@@ -10,4 +10,5 @@ func RegisterDependencies(container *dig.Container) {
 	container.Provide(NewHealthCheck)
 	container.Provide(NewEventListener)
 	container.Provide(executor.NewExecutorRegistry)
+	container.Provide(executor.ProvideExecutorMetrics)
 }
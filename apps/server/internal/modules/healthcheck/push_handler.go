@@ -37,6 +37,8 @@ type PushIngesterPayload struct {
 	MonitorMaxRetries  int                  `json:"monitor_max_retries"`
 	MonitorRetryInt    int                  `json:"monitor_retry_interval"`
 	MonitorResendInt   int                  `json:"monitor_resend_interval"`
+	MonitorWarmup      int                  `json:"monitor_warmup_checks"`
+	MonitorActivatedAt *time.Time           `json:"monitor_activated_at,omitempty"`
 	MonitorConfig      string               `json:"monitor_config"`
 	Status             shared.MonitorStatus `json:"status"`
 	Message            string               `json:"message"`
@@ -46,6 +48,7 @@ type PushIngesterPayload struct {
 	IsUnderMaintenance bool                 `json:"is_under_maintenance"`
 	TLSInfo            interface{}          `json:"tls_info,omitempty"`
 	CheckCertExpiry    bool                 `json:"check_cert_expiry"`
+	ErrorCategory      string               `json:"error_category,omitempty"`
 }
 
 func RegisterPushEndpoint(
@@ -99,6 +102,8 @@ func RegisterPushEndpoint(
 			MonitorMaxRetries:  monitor.MaxRetries,
 			MonitorRetryInt:    monitor.RetryInterval,
 			MonitorResendInt:   monitor.ResendInterval,
+			MonitorWarmup:      monitor.WarmupChecks,
+			MonitorActivatedAt: monitor.ActivatedAt,
 			MonitorConfig:      monitor.Config,
 			Status:             status,
 			Message:            msg,
@@ -5,15 +5,20 @@ import (
 )
 
 type CreateUpdateDto struct {
-	MonitorID string        `json:"monitor_id"`
-	Status    MonitorStatus `json:"status"`
-	Msg       string        `json:"msg"`
-	Ping      int           `json:"ping"`
-	Duration  int           `json:"duration"`
-	DownCount int           `json:"down_count"`
-	Retries   int           `json:"retries"`
-	Important bool          `json:"important"`
-	Time      time.Time     `json:"time"`
-	EndTime   time.Time     `json:"end_time"`
-	Notified  bool          `json:"notified"`
+	MonitorID     string        `json:"monitor_id"`
+	Status        MonitorStatus `json:"status"`
+	Msg           string        `json:"msg"`
+	Ping          int           `json:"ping"`
+	Duration      int           `json:"duration"`
+	DownCount     int           `json:"down_count"`
+	Retries       int           `json:"retries"`
+	Important     bool          `json:"important"`
+	Time          time.Time     `json:"time"`
+	EndTime       time.Time     `json:"end_time"`
+	Notified      bool          `json:"notified"`
+	ErrorCategory string        `json:"error_category"`
+	BodyHash      string        `json:"body_hash"`
+	ResponseSize  *int64        `json:"response_size"`
+	// AlertsSuppressed is passed on to the heartbeat events, see Model.AlertsSuppressed
+	AlertsSuppressed bool `json:"alerts_suppressed"`
 }
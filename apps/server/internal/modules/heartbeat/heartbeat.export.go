@@ -0,0 +1,139 @@
+package heartbeat
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"peekaping/internal/modules/shared"
+	"strconv"
+	"time"
+)
+
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatJSON = "json"
+)
+
+// ExportRow is a heartbeat as written by an export
+type ExportRow struct {
+	Timestamp time.Time `json:"timestamp"`
+	Status    string    `json:"status"`
+	Ping      int       `json:"ping"`
+	Message   string    `json:"message"`
+}
+
+func NewExportRow(hb *Model) ExportRow {
+	return ExportRow{
+		Timestamp: hb.Time.UTC(),
+		Status:    statusName(hb.Status),
+		Ping:      hb.Ping,
+		Message:   hb.Msg,
+	}
+}
+
+func statusName(status MonitorStatus) string {
+	switch status {
+	case shared.MonitorStatusDown:
+		return "down"
+	case shared.MonitorStatusUp:
+		return "up"
+	case shared.MonitorStatusPending:
+		return "pending"
+	case shared.MonitorStatusMaintenance:
+		return "maintenance"
+	default:
+		return strconv.Itoa(int(status))
+	}
+}
+
+// ExportWriter writes heartbeats one at a time so an export never holds more than one
+// heartbeat in memory. Close completes the document and must be called once at the end.
+type ExportWriter interface {
+	ContentType() string
+	Write(hb *Model) error
+	Close() error
+}
+
+// NewExportWriter returns the writer of format, "csv" or "json"
+func NewExportWriter(format string, w io.Writer) (ExportWriter, error) {
+	switch format {
+	case ExportFormatCSV:
+		return &csvExportWriter{writer: csv.NewWriter(w)}, nil
+	case ExportFormatJSON:
+		return &jsonExportWriter{writer: w}, nil
+	default:
+		return nil, fmt.Errorf("unsupported export format %q, expected csv or json", format)
+	}
+}
+
+type csvExportWriter struct {
+	writer  *csv.Writer
+	started bool
+}
+
+func (e *csvExportWriter) ContentType() string {
+	return "text/csv; charset=utf-8"
+}
+
+func (e *csvExportWriter) start() error {
+	if e.started {
+		return nil
+	}
+	e.started = true
+	return e.writer.Write([]string{"timestamp", "status", "ping", "message"})
+}
+
+func (e *csvExportWriter) Write(hb *Model) error {
+	if err := e.start(); err != nil {
+		return err
+	}
+	row := NewExportRow(hb)
+	return e.writer.Write([]string{
+		row.Timestamp.Format(time.RFC3339Nano),
+		row.Status,
+		strconv.Itoa(row.Ping),
+		row.Message,
+	})
+}
+
+func (e *csvExportWriter) Close() error {
+	if err := e.start(); err != nil {
+		return err
+	}
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+// jsonExportWriter writes a JSON array of ExportRow
+type jsonExportWriter struct {
+	writer  io.Writer
+	started bool
+}
+
+func (e *jsonExportWriter) ContentType() string {
+	return "application/json; charset=utf-8"
+}
+
+func (e *jsonExportWriter) Write(hb *Model) error {
+	data, err := json.Marshal(NewExportRow(hb))
+	if err != nil {
+		return err
+	}
+	separator := ","
+	if !e.started {
+		separator = "["
+		e.started = true
+	}
+	_, err = e.writer.Write(append([]byte(separator), data...))
+	return err
+}
+
+func (e *jsonExportWriter) Close() error {
+	end := "]"
+	if !e.started {
+		end = "[]"
+	}
+	_, err := io.WriteString(e.writer, end)
+	return err
+}
@@ -0,0 +1,32 @@
+package heartbeat
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportWriter_Empty(t *testing.T) {
+	tests := map[string]string{
+		ExportFormatCSV:  "timestamp,status,ping,message\n",
+		ExportFormatJSON: "[]",
+	}
+
+	for format, expected := range tests {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			writer, err := NewExportWriter(format, &buf)
+			require.NoError(t, err)
+
+			require.NoError(t, writer.Close())
+			assert.Equal(t, expected, buf.String())
+		})
+	}
+}
+
+func TestNewExportWriter_UnsupportedFormat(t *testing.T) {
+	_, err := NewExportWriter("xml", &bytes.Buffer{})
+	assert.ErrorContains(t, err, `unsupported export format "xml"`)
+}
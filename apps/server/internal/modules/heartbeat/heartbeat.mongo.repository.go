@@ -4,7 +4,8 @@ import (
 	"context"
 	"errors"
 	"peekaping/internal/config"
-
+	"peekaping/internal/modules/shared"
+	"sort"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -14,40 +15,78 @@ import (
 )
 
 type mongoModel struct {
-	ID        primitive.ObjectID `bson:"_id"`
-	MonitorID primitive.ObjectID `bson:"monitor_id"`
-	Status    MonitorStatus      `bson:"status"`
-	Msg       string             `bson:"msg"`
-	Ping      int                `bson:"ping"`
-	Duration  int                `bson:"duration"`
-	DownCount int                `bson:"down_count"`
-	Retries   int                `bson:"retries"`
-	Important bool               `bson:"important"`
-	Time      time.Time          `bson:"time"`
-	EndTime   time.Time          `bson:"end_time"`
-	Notified  bool               `bson:"notified"`
+	ID            primitive.ObjectID `bson:"_id"`
+	MonitorID     primitive.ObjectID `bson:"monitor_id"`
+	Status        MonitorStatus      `bson:"status"`
+	Msg           string             `bson:"msg"`
+	Ping          int                `bson:"ping"`
+	Duration      int                `bson:"duration"`
+	DownCount     int                `bson:"down_count"`
+	Retries       int                `bson:"retries"`
+	Important     bool               `bson:"important"`
+	Time          time.Time          `bson:"time"`
+	EndTime       time.Time          `bson:"end_time"`
+	Notified      bool               `bson:"notified"`
+	ErrorCategory string             `bson:"error_category,omitempty"`
+	BodyHash      string             `bson:"body_hash,omitempty"`
+	ResponseSize  *int64             `bson:"response_size,omitempty"`
+}
+
+type mongoRollupModel struct {
+	ID          primitive.ObjectID `bson:"_id"`
+	MonitorID   primitive.ObjectID `bson:"monitor_id"`
+	Period      string             `bson:"period"`
+	Bucket      time.Time          `bson:"bucket"`
+	PingMin     float64            `bson:"ping_min"`
+	PingAvg     float64            `bson:"ping_avg"`
+	PingMax     float64            `bson:"ping_max"`
+	Up          int                `bson:"up"`
+	Down        int                `bson:"down"`
+	Pending     int                `bson:"pending"`
+	Maintenance int                `bson:"maintenance"`
+	UpdatedAt   time.Time          `bson:"updated_at"`
+}
+
+func toRollupDomainModel(mm *mongoRollupModel) *Rollup {
+	return &Rollup{
+		ID:          mm.ID.Hex(),
+		MonitorID:   mm.MonitorID.Hex(),
+		Period:      RollupPeriod(mm.Period),
+		Bucket:      mm.Bucket,
+		PingMin:     mm.PingMin,
+		PingAvg:     mm.PingAvg,
+		PingMax:     mm.PingMax,
+		Up:          mm.Up,
+		Down:        mm.Down,
+		Pending:     mm.Pending,
+		Maintenance: mm.Maintenance,
+	}
 }
 
 type RepositoryImpl struct {
-	client     *mongo.Client
-	db         *mongo.Database
-	collection *mongo.Collection
+	client           *mongo.Client
+	db               *mongo.Database
+	collection       *mongo.Collection
+	rollupCollection *mongo.Collection
 }
 
 func toDomainModel(mm *mongoModel) *Model {
 	return &Model{
-		ID:        mm.ID.Hex(),
-		MonitorID: mm.MonitorID.Hex(),
-		Status:    mm.Status,
-		Msg:       mm.Msg,
-		Ping:      mm.Ping,
-		Duration:  mm.Duration,
-		DownCount: mm.DownCount,
-		Retries:   mm.Retries,
-		Important: mm.Important,
-		Time:      mm.Time,
-		EndTime:   mm.EndTime,
-		Notified:  mm.Notified,
+		ID:            mm.ID.Hex(),
+		MonitorID:     mm.MonitorID.Hex(),
+		Status:        mm.Status,
+		Msg:           mm.Msg,
+		Ping:          mm.Ping,
+		Duration:      mm.Duration,
+		DownCount:     mm.DownCount,
+		Retries:       mm.Retries,
+		Important:     mm.Important,
+		Time:          mm.Time,
+		EndTime:       mm.EndTime,
+		Notified:      mm.Notified,
+		ErrorCategory: mm.ErrorCategory,
+		BodyHash:      mm.BodyHash,
+		ResponseSize:  mm.ResponseSize,
 	}
 }
 
@@ -64,7 +103,21 @@ func NewMongoRepository(client *mongo.Client, cfg *config.Config) Repository {
 		panic("Failed to create index on heartbeat collection:" + err.Error())
 	}
 
-	return &RepositoryImpl{client, db, collection}
+	rollupCollection := db.Collection("heartbeat_rollups")
+	_, err = rollupCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "monitor_id", Value: 1}, {Key: "period", Value: 1}, {Key: "bucket", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		panic("Failed to create index on heartbeat_rollups collection:" + err.Error())
+	}
+
+	return &RepositoryImpl{
+		client:           client,
+		db:               db,
+		collection:       collection,
+		rollupCollection: rollupCollection,
+	}
 }
 
 func (r *RepositoryImpl) Create(ctx context.Context, entity *Model) (*Model, error) {
@@ -74,18 +127,21 @@ func (r *RepositoryImpl) Create(ctx context.Context, entity *Model) (*Model, err
 	}
 
 	mm := &mongoModel{
-		ID:        primitive.NewObjectID(),
-		MonitorID: monitorID,
-		Status:    entity.Status,
-		Msg:       entity.Msg,
-		Ping:      entity.Ping,
-		Duration:  entity.Duration,
-		DownCount: entity.DownCount,
-		Retries:   entity.Retries,
-		Important: entity.Important,
-		Time:      entity.Time,
-		EndTime:   entity.EndTime,
-		Notified:  entity.Notified,
+		ID:            primitive.NewObjectID(),
+		MonitorID:     monitorID,
+		Status:        entity.Status,
+		Msg:           entity.Msg,
+		Ping:          entity.Ping,
+		Duration:      entity.Duration,
+		DownCount:     entity.DownCount,
+		Retries:       entity.Retries,
+		Important:     entity.Important,
+		Time:          entity.Time,
+		EndTime:       entity.EndTime,
+		Notified:      entity.Notified,
+		ErrorCategory: entity.ErrorCategory,
+		BodyHash:      entity.BodyHash,
+		ResponseSize:  entity.ResponseSize,
 	}
 
 	_, err = r.collection.InsertOne(ctx, mm)
@@ -186,63 +242,177 @@ func (r *RepositoryImpl) FindActive(ctx context.Context) ([]*Model, error) {
 	return entities, nil
 }
 
-func (r *RepositoryImpl) FindUptimeStatsByMonitorID(ctx context.Context, monitorID string, periods map[string]time.Duration, now time.Time) (map[string]float64, error) {
-	objectID, err := primitive.ObjectIDFromHex(monitorID)
+// latestByMonitorIDsPipeline keeps the most recent heartbeat of each of the monitors
+func latestByMonitorIDsPipeline(monitorIDs []primitive.ObjectID) bson.A {
+	return bson.A{
+		bson.M{"$match": bson.M{"monitor_id": bson.M{"$in": monitorIDs}}},
+		bson.M{"$sort": bson.M{"time": -1}},
+		bson.M{"$group": bson.M{"_id": "$monitor_id", "latest": bson.M{"$first": "$$ROOT"}}},
+		bson.M{"$replaceRoot": bson.M{"newRoot": "$latest"}},
+	}
+}
+
+func (r *RepositoryImpl) FindLatestByMonitorIDs(ctx context.Context, monitorIDs []string) ([]*Model, error) {
+	if len(monitorIDs) == 0 {
+		return nil, nil
+	}
+
+	objectIDs := make([]primitive.ObjectID, 0, len(monitorIDs))
+	for _, id := range monitorIDs {
+		objectID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, err
+		}
+		objectIDs = append(objectIDs, objectID)
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, latestByMonitorIDsPipeline(objectIDs))
 	if err != nil {
 		return nil, err
 	}
+	defer cursor.Close(ctx)
 
-	facets := bson.M{}
-	for name, duration := range periods {
-		start := now.Add(-duration)
-		facets[name] = bson.A{
-			bson.M{"$match": bson.M{
-				"monitor_id": objectID,
-				"time":       bson.M{"$gte": start, "$lte": now},
-			}},
-			bson.M{"$group": bson.M{
-				"_id":  nil,
-				"up":   bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$status", 1}}, 1, 0}}},
-				"down": bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$status", 0}}, 1, 0}}},
-			}},
+	var models []*Model
+	for cursor.Next(ctx) {
+		var mm mongoModel
+		if err := cursor.Decode(&mm); err != nil {
+			return nil, err
 		}
+		models = append(models, toDomainModel(&mm))
 	}
-	pipeline := bson.A{
-		bson.M{"$facet": facets},
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+	return models, nil
+}
+
+// uptimeCountsPipeline counts the UP heartbeats of a monitor since the given time. Uptime
+// on MongoDB has always been UP out of UP and DOWN heartbeats, so pending and maintenance
+// heartbeats are left out of the total.
+func uptimeCountsPipeline(monitorID primitive.ObjectID, since time.Time) bson.A {
+	countStatus := func(statuses ...shared.MonitorStatus) bson.M {
+		values := bson.A{}
+		for _, status := range statuses {
+			values = append(values, int(status))
+		}
+		return bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$in": bson.A{"$status", values}}, 1, 0}}}
+	}
+
+	return bson.A{
+		bson.M{"$match": bson.M{
+			"monitor_id": monitorID,
+			"time":       bson.M{"$gte": since},
+		}},
+		bson.M{"$group": bson.M{
+			"_id":   nil,
+			"up":    countStatus(shared.MonitorStatusUp),
+			"total": countStatus(shared.MonitorStatusUp, shared.MonitorStatusDown),
+		}},
+	}
+}
+
+func (r *RepositoryImpl) FindUptimeCountsByMonitorID(ctx context.Context, monitorID string, since time.Time) (*UptimeCounts, error) {
+	objectID, err := primitive.ObjectIDFromHex(monitorID)
+	if err != nil {
+		return nil, err
 	}
-	cursor, err := r.collection.Aggregate(ctx, pipeline)
+
+	cursor, err := r.collection.Aggregate(ctx, uptimeCountsPipeline(objectID, since))
 	if err != nil {
 		return nil, err
 	}
 	defer cursor.Close(ctx)
 
-	var facetResult []map[string][]struct {
-		Up   int `bson:"up"`
-		Down int `bson:"down"`
+	var results []struct {
+		Up    int `bson:"up"`
+		Total int `bson:"total"`
 	}
-	if err := cursor.All(ctx, &facetResult); err != nil {
+	if err := cursor.All(ctx, &results); err != nil {
 		return nil, err
 	}
-	if len(facetResult) == 0 {
-		return nil, nil
+	if len(results) == 0 {
+		return &UptimeCounts{}, nil
+	}
+	return &UptimeCounts{Up: results[0].Up, Total: results[0].Total}, nil
+}
+
+func (r *RepositoryImpl) FindOldestTime(ctx context.Context) (*time.Time, error) {
+	var mm mongoModel
+	opts := options.FindOne().SetSort(bson.M{"time": 1})
+	err := r.collection.FindOne(ctx, bson.M{}, opts).Decode(&mm)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
 	}
-	result := make(map[string]float64)
-	for name := range periods {
-		arr := facetResult[0][name]
-		if len(arr) == 0 {
-			result[name] = 0
-			continue
+	return &mm.Time, nil
+}
+
+func (r *RepositoryImpl) FindByTimeRange(ctx context.Context, since, until time.Time) ([]*Model, error) {
+	filter := bson.M{"time": bson.M{"$gte": since, "$lt": until}}
+	opts := options.Find().SetSort(bson.M{"time": 1})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var models []*Model
+	for cursor.Next(ctx) {
+		var mm mongoModel
+		if err := cursor.Decode(&mm); err != nil {
+			return nil, err
 		}
-		up := arr[0].Up
-		down := arr[0].Down
-		total := up + down
-		if total > 0 {
-			result[name] = float64(up) / float64(total) * 100
-		} else {
-			result[name] = 0
+		models = append(models, toDomainModel(&mm))
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+	return models, nil
+}
+
+func (r *RepositoryImpl) FindMonitorIDsByTimeRange(ctx context.Context, since, until time.Time) ([]string, error) {
+	filter := bson.M{"time": bson.M{"$gte": since, "$lt": until}}
+	values, err := r.collection.Distinct(ctx, "monitor_id", filter)
+	if err != nil {
+		return nil, err
+	}
+
+	monitorIDs := make([]string, 0, len(values))
+	for _, value := range values {
+		if objectID, ok := value.(primitive.ObjectID); ok {
+			monitorIDs = append(monitorIDs, objectID.Hex())
 		}
 	}
-	return result, nil
+	sort.Strings(monitorIDs)
+	return monitorIDs, nil
+}
+
+func (r *RepositoryImpl) StreamByMonitorID(ctx context.Context, monitorID string, since, until time.Time, fn func(*Model) error) error {
+	objectID, err := primitive.ObjectIDFromHex(monitorID)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{"monitor_id": objectID, "time": bson.M{"$gte": since, "$lt": until}}
+	opts := options.Find().SetSort(bson.M{"time": 1}).SetBatchSize(1000)
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var mm mongoModel
+		if err := cursor.Decode(&mm); err != nil {
+			return err
+		}
+		if err := fn(toDomainModel(&mm)); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
 }
 
 func (r *RepositoryImpl) DeleteOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
@@ -311,6 +481,29 @@ func (r *RepositoryImpl) FindByMonitorIDPaginated(
 	return models, nil
 }
 
+func (r *RepositoryImpl) DeleteByTimeRange(ctx context.Context, since, until time.Time) (int64, error) {
+	filter := bson.M{"time": bson.M{"$gte": since, "$lt": until}}
+	result, err := r.collection.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+func (r *RepositoryImpl) DeleteByMonitorIDAndTimeRange(ctx context.Context, monitorID string, since, until time.Time) (int64, error) {
+	objectID, err := primitive.ObjectIDFromHex(monitorID)
+	if err != nil {
+		return 0, err
+	}
+
+	filter := bson.M{"monitor_id": objectID, "time": bson.M{"$gte": since, "$lt": until}}
+	result, err := r.collection.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
 func (r *RepositoryImpl) DeleteByMonitorID(ctx context.Context, monitorID string) error {
 	objectID, err := primitive.ObjectIDFromHex(monitorID)
 	if err != nil {
@@ -319,5 +512,117 @@ func (r *RepositoryImpl) DeleteByMonitorID(ctx context.Context, monitorID string
 
 	filter := bson.M{"monitor_id": objectID}
 	_, err = r.collection.DeleteMany(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.rollupCollection.DeleteMany(ctx, filter)
+	return err
+}
+
+func (r *RepositoryImpl) UpsertRollup(ctx context.Context, rollup *Rollup) error {
+	monitorID, err := primitive.ObjectIDFromHex(rollup.MonitorID)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{
+		"monitor_id": monitorID,
+		"period":     string(rollup.Period),
+		"bucket":     rollup.Bucket,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"ping_min":    rollup.PingMin,
+			"ping_avg":    rollup.PingAvg,
+			"ping_max":    rollup.PingMax,
+			"up":          rollup.Up,
+			"down":        rollup.Down,
+			"pending":     rollup.Pending,
+			"maintenance": rollup.Maintenance,
+			"updated_at":  time.Now().UTC(),
+		},
+		"$setOnInsert": bson.M{"_id": primitive.NewObjectID()},
+	}
+
+	// Rollups are recomputed from raw heartbeats, so an existing bucket is replaced rather than merged
+	_, err = r.rollupCollection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
 	return err
 }
+
+func (r *RepositoryImpl) FindRollupsByTimeRange(ctx context.Context, period RollupPeriod, since, until time.Time) ([]*Rollup, error) {
+	filter := bson.M{
+		"period": string(period),
+		"bucket": bson.M{"$gte": since, "$lt": until},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "monitor_id", Value: 1}, {Key: "bucket", Value: 1}})
+	cursor, err := r.rollupCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rollups []*Rollup
+	for cursor.Next(ctx) {
+		var mm mongoRollupModel
+		if err := cursor.Decode(&mm); err != nil {
+			return nil, err
+		}
+		rollups = append(rollups, toRollupDomainModel(&mm))
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+	return rollups, nil
+}
+
+// rollupUptimeCountsPipeline is uptimeCountsPipeline for rollups, counting UP out of UP and
+// DOWN heartbeats as well
+func rollupUptimeCountsPipeline(monitorID primitive.ObjectID, period RollupPeriod, since time.Time) bson.A {
+	return bson.A{
+		bson.M{"$match": bson.M{
+			"monitor_id": monitorID,
+			"period":     string(period),
+			"bucket":     bson.M{"$gte": since},
+		}},
+		bson.M{"$group": bson.M{
+			"_id":   nil,
+			"up":    bson.M{"$sum": "$up"},
+			"total": bson.M{"$sum": bson.M{"$add": bson.A{"$up", "$down"}}},
+		}},
+	}
+}
+
+func (r *RepositoryImpl) FindRollupUptimeCountsByMonitorID(ctx context.Context, monitorID string, period RollupPeriod, since time.Time) (*UptimeCounts, error) {
+	objectID, err := primitive.ObjectIDFromHex(monitorID)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := r.rollupCollection.Aggregate(ctx, rollupUptimeCountsPipeline(objectID, period, since))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Up    int `bson:"up"`
+		Total int `bson:"total"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return &UptimeCounts{}, nil
+	}
+	return &UptimeCounts{Up: results[0].Up, Total: results[0].Total}, nil
+}
+
+func (r *RepositoryImpl) DeleteRollupsOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	filter := bson.M{"bucket": bson.M{"$lt": cutoff}}
+	result, err := r.rollupCollection.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
@@ -0,0 +1,72 @@
+package heartbeat
+
+import (
+	"testing"
+	"time"
+
+	"peekaping/internal/modules/shared"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// countMatching evaluates a {"$sum": {"$cond": [{"$in": ["$status", statuses]}, 1, 0]}}
+// accumulator of the uptime pipeline over heartbeats
+func countMatching(t *testing.T, accumulator any, heartbeats []*Model) int {
+	cond := accumulator.(bson.M)["$sum"].(bson.M)["$cond"].(bson.A)
+	in := cond[0].(bson.M)["$in"].(bson.A)
+	require.Equal(t, "$status", in[0])
+
+	count := 0
+	for _, hb := range heartbeats {
+		for _, status := range in[1].(bson.A) {
+			if status == int(hb.Status) {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+func TestLatestByMonitorIDsPipeline(t *testing.T) {
+	monitorIDs := []primitive.ObjectID{primitive.NewObjectID(), primitive.NewObjectID()}
+
+	pipeline := latestByMonitorIDsPipeline(monitorIDs)
+
+	require.Len(t, pipeline, 4)
+	assert.Equal(t, bson.M{"monitor_id": bson.M{"$in": monitorIDs}}, pipeline[0].(bson.M)["$match"])
+	// The newest heartbeat comes first in each monitor's group
+	assert.Equal(t, bson.M{"time": -1}, pipeline[1].(bson.M)["$sort"])
+	assert.Equal(t, bson.M{"_id": "$monitor_id", "latest": bson.M{"$first": "$$ROOT"}}, pipeline[2].(bson.M)["$group"])
+}
+
+func TestUptimeCountsPipeline_CountsUpOutOfUpAndDown(t *testing.T) {
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	monitorID := primitive.NewObjectID()
+
+	pipeline := uptimeCountsPipeline(monitorID, since)
+	require.Len(t, pipeline, 2)
+	assert.Equal(t, bson.M{"monitor_id": monitorID, "time": bson.M{"$gte": since}}, pipeline[0].(bson.M)["$match"])
+
+	heartbeats := []*Model{
+		beat("mon-1", shared.MonitorStatusUp, 100, since),
+		beat("mon-1", shared.MonitorStatusUp, 100, since),
+		beat("mon-1", shared.MonitorStatusUp, 100, since),
+		beat("mon-1", shared.MonitorStatusDown, 0, since),
+		beat("mon-1", shared.MonitorStatusPending, 0, since),
+		beat("mon-1", shared.MonitorStatusMaintenance, 0, since),
+	}
+	group := pipeline[1].(bson.M)["$group"].(bson.M)
+
+	// Pending and maintenance heartbeats don't lower the uptime: 3 of 4, not 3 of 6
+	assert.Equal(t, 3, countMatching(t, group["up"], heartbeats))
+	assert.Equal(t, 4, countMatching(t, group["total"], heartbeats))
+
+	t.Run("rollups count up and down only as well", func(t *testing.T) {
+		group := rollupUptimeCountsPipeline(monitorID, RollupHourly, since)[1].(bson.M)["$group"].(bson.M)
+		assert.Equal(t, bson.M{"$sum": "$up"}, group["up"])
+		assert.Equal(t, bson.M{"$sum": bson.M{"$add": bson.A{"$up", "$down"}}}, group["total"])
+	})
+}
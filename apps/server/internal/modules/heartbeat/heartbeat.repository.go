@@ -20,12 +20,26 @@ type Repository interface {
 		important *bool,
 		reverse bool,
 	) ([]*Model, error)
-	FindUptimeStatsByMonitorID(
-		ctx context.Context,
-		monitorID string,
-		periods map[string]time.Duration,
-		now time.Time,
-	) (map[string]float64, error)
+	// FindLatestByMonitorIDs returns the most recent heartbeat of each of the monitors in
+	// one query. Monitors without heartbeats are left out.
+	FindLatestByMonitorIDs(ctx context.Context, monitorIDs []string) ([]*Model, error)
+	FindUptimeCountsByMonitorID(ctx context.Context, monitorID string, since time.Time) (*UptimeCounts, error)
+	FindOldestTime(ctx context.Context) (*time.Time, error)
+	FindByTimeRange(ctx context.Context, since, until time.Time) ([]*Model, error)
+	// FindMonitorIDsByTimeRange returns the IDs of the monitors with heartbeats in [since, until)
+	FindMonitorIDsByTimeRange(ctx context.Context, since, until time.Time) ([]string, error)
+	// StreamByMonitorID calls fn for each heartbeat of the monitor in [since, until) in
+	// time order, reading them in batches instead of loading all of them. It stops at
+	// the first error returned by fn.
+	StreamByMonitorID(ctx context.Context, monitorID string, since, until time.Time, fn func(*Model) error) error
 	DeleteOlderThan(ctx context.Context, cutoff time.Time) (int64, error)
+	DeleteByTimeRange(ctx context.Context, since, until time.Time) (int64, error)
+	DeleteByMonitorIDAndTimeRange(ctx context.Context, monitorID string, since, until time.Time) (int64, error)
 	DeleteByMonitorID(ctx context.Context, monitorID string) error
+
+	// Rollups of compacted heartbeats
+	UpsertRollup(ctx context.Context, rollup *Rollup) error
+	FindRollupsByTimeRange(ctx context.Context, period RollupPeriod, since, until time.Time) ([]*Rollup, error)
+	FindRollupUptimeCountsByMonitorID(ctx context.Context, monitorID string, period RollupPeriod, since time.Time) (*UptimeCounts, error)
+	DeleteRollupsOlderThan(ctx context.Context, cutoff time.Time) (int64, error)
 }
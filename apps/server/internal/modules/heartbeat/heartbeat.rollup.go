@@ -0,0 +1,203 @@
+package heartbeat
+
+import (
+	"sort"
+	"time"
+
+	"peekaping/internal/modules/shared"
+)
+
+type RollupPeriod string
+
+const (
+	RollupHourly RollupPeriod = "hourly"
+	RollupDaily  RollupPeriod = "daily"
+)
+
+// RollupPeriods lists the rollup granularities written when heartbeats are compacted
+var RollupPeriods = []RollupPeriod{RollupHourly, RollupDaily}
+
+// Bucket returns the bucket size of the rollup period
+func (p RollupPeriod) Bucket() time.Duration {
+	if p == RollupDaily {
+		return 24 * time.Hour
+	}
+	return time.Hour
+}
+
+// Rollup is a compacted summary of the heartbeats of one monitor within a bucket.
+// Ping statistics only cover UP heartbeats, matching the stats module.
+type Rollup struct {
+	ID          string       `json:"id"`
+	MonitorID   string       `json:"monitor_id"`
+	Period      RollupPeriod `json:"period"`
+	Bucket      time.Time    `json:"bucket"`
+	PingMin     float64      `json:"ping_min"`
+	PingAvg     float64      `json:"ping_avg"`
+	PingMax     float64      `json:"ping_max"`
+	Up          int          `json:"up"`
+	Down        int          `json:"down"`
+	Pending     int          `json:"pending"`
+	Maintenance int          `json:"maintenance"`
+}
+
+// Total returns the number of heartbeats summarised by the rollup
+func (r *Rollup) Total() int {
+	return r.Up + r.Down + r.Pending + r.Maintenance
+}
+
+// Uptime returns the ratio of UP heartbeats in the rollup (0-1)
+func (r *Rollup) Uptime() float64 {
+	total := r.Total()
+	if total == 0 {
+		return 0
+	}
+	return float64(r.Up) / float64(total)
+}
+
+// UptimeCounts holds the number of UP heartbeats and the total number of heartbeats in a range
+type UptimeCounts struct {
+	Up    int
+	Total int
+}
+
+// BuildRollups aggregates heartbeats into rollups of the given period, one per monitor and bucket.
+// Rollups are returned ordered by monitor and bucket.
+func BuildRollups(heartbeats []*Model, period RollupPeriod) []*Rollup {
+	builder := newRollupBuilder(period)
+	for _, hb := range heartbeats {
+		builder.Add(hb)
+	}
+	return builder.Rollups()
+}
+
+type rollupKey struct {
+	monitorID string
+	bucket    int64
+}
+
+// rollupBuilder aggregates heartbeats into rollups one at a time, so heartbeats can be
+// streamed from the database instead of loaded at once
+type rollupBuilder struct {
+	period   RollupPeriod
+	rollups  map[rollupKey]*Rollup
+	pingSums map[rollupKey]float64
+}
+
+func newRollupBuilder(period RollupPeriod) *rollupBuilder {
+	return &rollupBuilder{
+		period:   period,
+		rollups:  make(map[rollupKey]*Rollup),
+		pingSums: make(map[rollupKey]float64),
+	}
+}
+
+// Add counts the heartbeat in the rollup of its monitor and bucket
+func (b *rollupBuilder) Add(hb *Model) {
+	bucket := hb.Time.UTC().Truncate(b.period.Bucket())
+	k := rollupKey{monitorID: hb.MonitorID, bucket: bucket.Unix()}
+
+	rollup, ok := b.rollups[k]
+	if !ok {
+		rollup = &Rollup{
+			MonitorID: hb.MonitorID,
+			Period:    b.period,
+			Bucket:    bucket,
+		}
+		b.rollups[k] = rollup
+	}
+
+	switch hb.Status {
+	case shared.MonitorStatusUp:
+		ping := float64(hb.Ping)
+		if rollup.Up == 0 || ping < rollup.PingMin {
+			rollup.PingMin = ping
+		}
+		if rollup.Up == 0 || ping > rollup.PingMax {
+			rollup.PingMax = ping
+		}
+		rollup.Up++
+		b.pingSums[k] += ping
+	case shared.MonitorStatusDown:
+		rollup.Down++
+	case shared.MonitorStatusPending:
+		rollup.Pending++
+	case shared.MonitorStatusMaintenance:
+		rollup.Maintenance++
+	}
+}
+
+// Rollups returns the rollups built so far ordered by monitor and bucket
+func (b *rollupBuilder) Rollups() []*Rollup {
+	result := make([]*Rollup, 0, len(b.rollups))
+	for k, rollup := range b.rollups {
+		if rollup.Up > 0 {
+			rollup.PingAvg = b.pingSums[k] / float64(rollup.Up)
+		}
+		result = append(result, rollup)
+	}
+
+	sortRollups(result)
+	return result
+}
+
+// MergeRollups combines finer rollups (e.g. hourly) into rollups of a coarser period (e.g. daily)
+func MergeRollups(rollups []*Rollup, period RollupPeriod) []*Rollup {
+	type key struct {
+		monitorID string
+		bucket    int64
+	}
+
+	bucketSize := period.Bucket()
+	merged := make(map[key]*Rollup)
+	pingSums := make(map[key]float64)
+
+	for _, r := range rollups {
+		bucket := r.Bucket.UTC().Truncate(bucketSize)
+		k := key{monitorID: r.MonitorID, bucket: bucket.Unix()}
+
+		m, ok := merged[k]
+		if !ok {
+			m = &Rollup{
+				MonitorID: r.MonitorID,
+				Period:    period,
+				Bucket:    bucket,
+			}
+			merged[k] = m
+		}
+
+		if r.Up > 0 {
+			if m.Up == 0 || r.PingMin < m.PingMin {
+				m.PingMin = r.PingMin
+			}
+			if m.Up == 0 || r.PingMax > m.PingMax {
+				m.PingMax = r.PingMax
+			}
+			pingSums[k] += r.PingAvg * float64(r.Up)
+		}
+		m.Up += r.Up
+		m.Down += r.Down
+		m.Pending += r.Pending
+		m.Maintenance += r.Maintenance
+	}
+
+	result := make([]*Rollup, 0, len(merged))
+	for k, m := range merged {
+		if m.Up > 0 {
+			m.PingAvg = pingSums[k] / float64(m.Up)
+		}
+		result = append(result, m)
+	}
+
+	sortRollups(result)
+	return result
+}
+
+func sortRollups(rollups []*Rollup) {
+	sort.Slice(rollups, func(i, j int) bool {
+		if rollups[i].MonitorID != rollups[j].MonitorID {
+			return rollups[i].MonitorID < rollups[j].MonitorID
+		}
+		return rollups[i].Bucket.Before(rollups[j].Bucket)
+	})
+}
@@ -0,0 +1,95 @@
+package heartbeat
+
+import (
+	"testing"
+	"time"
+
+	"peekaping/internal/modules/shared"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func beat(monitorID string, status shared.MonitorStatus, ping int, at time.Time) *Model {
+	return &Model{MonitorID: monitorID, Status: status, Ping: ping, Time: at}
+}
+
+func TestBuildRollups(t *testing.T) {
+	base := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	heartbeats := []*Model{
+		beat("mon-1", shared.MonitorStatusUp, 100, base.Add(1*time.Minute)),
+		beat("mon-1", shared.MonitorStatusUp, 300, base.Add(2*time.Minute)),
+		beat("mon-1", shared.MonitorStatusDown, 0, base.Add(3*time.Minute)),
+		beat("mon-1", shared.MonitorStatusPending, 0, base.Add(4*time.Minute)),
+		beat("mon-1", shared.MonitorStatusMaintenance, 0, base.Add(5*time.Minute)),
+		beat("mon-1", shared.MonitorStatusUp, 50, base.Add(61*time.Minute)),
+		beat("mon-2", shared.MonitorStatusDown, 0, base.Add(10*time.Minute)),
+	}
+
+	rollups := BuildRollups(heartbeats, RollupHourly)
+	require.Len(t, rollups, 3)
+
+	first := rollups[0]
+	assert.Equal(t, "mon-1", first.MonitorID)
+	assert.Equal(t, RollupHourly, first.Period)
+	assert.Equal(t, base, first.Bucket)
+	assert.Equal(t, 2, first.Up)
+	assert.Equal(t, 1, first.Down)
+	assert.Equal(t, 1, first.Pending)
+	assert.Equal(t, 1, first.Maintenance)
+	assert.Equal(t, 5, first.Total())
+	assert.Equal(t, 100.0, first.PingMin)
+	assert.Equal(t, 200.0, first.PingAvg)
+	assert.Equal(t, 300.0, first.PingMax)
+	assert.InDelta(t, 0.4, first.Uptime(), 1e-9)
+
+	second := rollups[1]
+	assert.Equal(t, "mon-1", second.MonitorID)
+	assert.Equal(t, base.Add(time.Hour), second.Bucket)
+	assert.Equal(t, 1, second.Up)
+	assert.Equal(t, 50.0, second.PingAvg)
+
+	third := rollups[2]
+	assert.Equal(t, "mon-2", third.MonitorID)
+	assert.Equal(t, 0, third.Up)
+	assert.Equal(t, 1, third.Down)
+	assert.Equal(t, 0.0, third.PingMin)
+	assert.Equal(t, 0.0, third.PingAvg)
+	assert.Equal(t, 0.0, third.PingMax)
+	assert.Equal(t, 0.0, third.Uptime())
+}
+
+func TestMergeRollupsMatchesRawAggregates(t *testing.T) {
+	day := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var heartbeats []*Model
+	for i := 0; i < 24*6; i++ {
+		at := day.Add(time.Duration(i) * 10 * time.Minute)
+		switch {
+		case i%7 == 0:
+			heartbeats = append(heartbeats, beat("mon-1", shared.MonitorStatusDown, 0, at))
+		case i%11 == 0:
+			heartbeats = append(heartbeats, beat("mon-1", shared.MonitorStatusMaintenance, 0, at))
+		default:
+			heartbeats = append(heartbeats, beat("mon-1", shared.MonitorStatusUp, 20+i%37, at))
+		}
+	}
+
+	fromHourly := MergeRollups(BuildRollups(heartbeats, RollupHourly), RollupDaily)
+	fromRaw := BuildRollups(heartbeats, RollupDaily)
+
+	require.Len(t, fromHourly, 1)
+	require.Len(t, fromRaw, 1)
+
+	merged, raw := fromHourly[0], fromRaw[0]
+	assert.Equal(t, raw.Bucket, merged.Bucket)
+	assert.Equal(t, RollupDaily, merged.Period)
+	assert.Equal(t, raw.Up, merged.Up)
+	assert.Equal(t, raw.Down, merged.Down)
+	assert.Equal(t, raw.Pending, merged.Pending)
+	assert.Equal(t, raw.Maintenance, merged.Maintenance)
+	assert.Equal(t, raw.PingMin, merged.PingMin)
+	assert.Equal(t, raw.PingMax, merged.PingMax)
+	assert.InDelta(t, raw.PingAvg, merged.PingAvg, 1e-9)
+}
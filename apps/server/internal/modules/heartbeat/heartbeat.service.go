@@ -16,8 +16,14 @@ type Service interface {
 
 	FindUptimeStatsByMonitorID(ctx context.Context, monitorID string, periods map[string]time.Duration, now time.Time) (map[string]float64, error)
 	DeleteOlderThan(ctx context.Context, cutoff time.Time) (int64, error)
+	CompactOlderThan(ctx context.Context, cutoff time.Time) (int64, error)
+	DeleteRollupsOlderThan(ctx context.Context, cutoff time.Time) (int64, error)
 	FindByMonitorIDPaginated(ctx context.Context, monitorID string, limit, page int, important *bool, reverse bool) ([]*Model, error)
+	// FindLatestByMonitorIDs returns the most recent heartbeat of each monitor by monitor
+	// ID, in one query. Monitors without heartbeats are left out.
+	FindLatestByMonitorIDs(ctx context.Context, monitorIDs []string) (map[string]*Model, error)
 	DeleteByMonitorID(ctx context.Context, monitorID string) error
+	StreamByMonitorID(ctx context.Context, monitorID string, since, until time.Time, fn func(*Model) error) error
 }
 
 type ServiceImpl struct {
@@ -40,23 +46,27 @@ func NewService(
 
 func (mr *ServiceImpl) Create(ctx context.Context, entity *CreateUpdateDto) (*Model, error) {
 	createModel := &Model{
-		MonitorID: entity.MonitorID,
-		Status:    entity.Status,
-		Msg:       entity.Msg,
-		Ping:      entity.Ping,
-		Duration:  entity.Duration,
-		DownCount: entity.DownCount,
-		Retries:   entity.Retries,
-		Important: entity.Important,
-		Time:      entity.Time,
-		EndTime:   entity.EndTime,
-		Notified:  entity.Notified,
+		MonitorID:     entity.MonitorID,
+		Status:        entity.Status,
+		Msg:           entity.Msg,
+		Ping:          entity.Ping,
+		Duration:      entity.Duration,
+		DownCount:     entity.DownCount,
+		Retries:       entity.Retries,
+		Important:     entity.Important,
+		Time:          entity.Time,
+		EndTime:       entity.EndTime,
+		Notified:      entity.Notified,
+		ErrorCategory: entity.ErrorCategory,
+		BodyHash:      entity.BodyHash,
+		ResponseSize:  entity.ResponseSize,
 	}
 
 	created, err := mr.repository.Create(ctx, createModel)
 	if err != nil {
 		return nil, err
 	}
+	created.AlertsSuppressed = entity.AlertsSuppressed
 	// Emit HeartbeatCreated event
 	mr.eventBus.Publish(events.Event{
 		Type:    events.HeartbeatEvent,
@@ -87,18 +97,139 @@ func (mr *ServiceImpl) Delete(ctx context.Context, id string) error {
 	return mr.repository.Delete(ctx, id)
 }
 
+// FindUptimeStatsByMonitorID returns uptime percentages for each period. Compacted
+// history is read from hourly rollups and recent history from raw heartbeats; the
+// two never overlap because raw rows are purged once they are rolled up.
 func (mr *ServiceImpl) FindUptimeStatsByMonitorID(ctx context.Context, monitorID string, periods map[string]time.Duration, now time.Time) (map[string]float64, error) {
-	return mr.repository.FindUptimeStatsByMonitorID(ctx, monitorID, periods, now)
+	stats := make(map[string]float64, len(periods))
+
+	for name, duration := range periods {
+		since := now.Add(-duration)
+
+		raw, err := mr.repository.FindUptimeCountsByMonitorID(ctx, monitorID, since)
+		if err != nil {
+			return nil, err
+		}
+
+		rolledUp, err := mr.repository.FindRollupUptimeCountsByMonitorID(ctx, monitorID, RollupHourly, since)
+		if err != nil {
+			return nil, err
+		}
+
+		up := raw.Up + rolledUp.Up
+		total := raw.Total + rolledUp.Total
+		if total > 0 {
+			stats[name] = float64(up) / float64(total) * 100
+		} else {
+			stats[name] = 0
+		}
+	}
+
+	return stats, nil
 }
 
+// DeleteOlderThan purges raw heartbeats older than the cutoff. The complete days among them
+// are rolled up first, so uptime keeps covering them through the rollups, which have their
+// own retention.
 func (mr *ServiceImpl) DeleteOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
-	return mr.repository.DeleteOlderThan(ctx, cutoff)
+	compacted, err := mr.CompactOlderThan(ctx, cutoff)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted, err := mr.repository.DeleteOlderThan(ctx, cutoff)
+	if err != nil {
+		return compacted, err
+	}
+
+	return compacted + deleted, nil
+}
+
+// DeleteRollupsOlderThan purges hourly and daily rollups whose bucket starts before the cutoff
+func (mr *ServiceImpl) DeleteRollupsOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	return mr.repository.DeleteRollupsOlderThan(ctx, cutoff)
+}
+
+// CompactOlderThan rolls raw heartbeats older than the cutoff up into hourly and
+// daily rollups and purges the raw rows. Work is done one monitor and UTC day at a
+// time, streaming the heartbeats, so memory stays bounded by a day of rollups. The
+// cutoff is truncated to a day boundary, so every rollup bucket is built from a
+// complete set of heartbeats. Rollups are replaced on upsert, which makes a rerun
+// after a partial failure safe. Returns the number of raw heartbeats purged.
+func (mr *ServiceImpl) CompactOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	cutoff = cutoff.UTC().Truncate(24 * time.Hour)
+
+	oldest, err := mr.repository.FindOldestTime(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if oldest == nil || !oldest.Before(cutoff) {
+		return 0, nil
+	}
+
+	var purged int64
+	for day := oldest.UTC().Truncate(24 * time.Hour); day.Before(cutoff); day = day.Add(24 * time.Hour) {
+		dayEnd := day.Add(24 * time.Hour)
+
+		monitorIDs, err := mr.repository.FindMonitorIDsByTimeRange(ctx, day, dayEnd)
+		if err != nil {
+			return purged, err
+		}
+		for _, monitorID := range monitorIDs {
+			deleted, err := mr.compactMonitorDay(ctx, monitorID, day, dayEnd)
+			if err != nil {
+				return purged, err
+			}
+			purged += deleted
+		}
+	}
+
+	return purged, nil
+}
+
+// compactMonitorDay writes the hourly and daily rollups of one monitor for a day and then
+// purges its raw heartbeats of that day
+func (mr *ServiceImpl) compactMonitorDay(ctx context.Context, monitorID string, day, dayEnd time.Time) (int64, error) {
+	builder := newRollupBuilder(RollupHourly)
+	err := mr.repository.StreamByMonitorID(ctx, monitorID, day, dayEnd, func(hb *Model) error {
+		builder.Add(hb)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	hourly := builder.Rollups()
+	for _, rollup := range append(hourly, MergeRollups(hourly, RollupDaily)...) {
+		if err := mr.repository.UpsertRollup(ctx, rollup); err != nil {
+			return 0, err
+		}
+	}
+
+	return mr.repository.DeleteByMonitorIDAndTimeRange(ctx, monitorID, day, dayEnd)
 }
 
 func (mr *ServiceImpl) FindByMonitorIDPaginated(ctx context.Context, monitorID string, limit, page int, important *bool, reverse bool) ([]*Model, error) {
 	return mr.repository.FindByMonitorIDPaginated(ctx, monitorID, limit, page, important, reverse)
 }
 
+func (mr *ServiceImpl) FindLatestByMonitorIDs(ctx context.Context, monitorIDs []string) (map[string]*Model, error) {
+	latest, err := mr.repository.FindLatestByMonitorIDs(ctx, monitorIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	byMonitor := make(map[string]*Model, len(latest))
+	for _, hb := range latest {
+		byMonitor[hb.MonitorID] = hb
+	}
+	return byMonitor, nil
+}
+
 func (mr *ServiceImpl) DeleteByMonitorID(ctx context.Context, monitorID string) error {
 	return mr.repository.DeleteByMonitorID(ctx, monitorID)
 }
+
+func (mr *ServiceImpl) StreamByMonitorID(ctx context.Context, monitorID string, since, until time.Time, fn func(*Model) error) error {
+	return mr.repository.StreamByMonitorID(ctx, monitorID, since, until, fn)
+}
@@ -0,0 +1,343 @@
+package heartbeat
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"peekaping/internal/modules/shared"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"github.com/uptrace/bun/driver/sqliteshim"
+	"go.uber.org/zap"
+)
+
+func setupTestDB(t *testing.T) *bun.DB {
+	sqldb, err := sql.Open(sqliteshim.ShimName, "file::memory:")
+	require.NoError(t, err)
+	sqldb.SetMaxOpenConns(1)
+
+	db := bun.NewDB(sqldb, sqlitedialect.New())
+
+	_, err = db.Exec(`
+		CREATE TABLE heartbeats (
+			id TEXT PRIMARY KEY,
+			monitor_id TEXT NOT NULL,
+			status INTEGER NOT NULL,
+			msg TEXT,
+			ping INTEGER,
+			duration INTEGER,
+			down_count INTEGER,
+			retries INTEGER,
+			important BOOLEAN NOT NULL DEFAULT FALSE,
+			time DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			end_time DATETIME,
+			notified BOOLEAN NOT NULL DEFAULT FALSE,
+			error_category TEXT NOT NULL DEFAULT '',
+			body_hash TEXT NOT NULL DEFAULT '',
+			response_size INTEGER
+		)
+	`)
+	require.NoError(t, err)
+
+	_, err = db.Exec(`
+		CREATE TABLE heartbeat_rollups (
+			id TEXT PRIMARY KEY,
+			monitor_id TEXT NOT NULL,
+			period TEXT NOT NULL,
+			bucket DATETIME NOT NULL,
+			ping_min REAL NOT NULL DEFAULT 0,
+			ping_avg REAL NOT NULL DEFAULT 0,
+			ping_max REAL NOT NULL DEFAULT 0,
+			up INTEGER NOT NULL DEFAULT 0,
+			down INTEGER NOT NULL DEFAULT 0,
+			pending INTEGER NOT NULL DEFAULT 0,
+			maintenance INTEGER NOT NULL DEFAULT 0,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (monitor_id, period, bucket)
+		)
+	`)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	return db
+}
+
+// insertHeartbeat writes a heartbeat with an explicit time, bypassing Create which stamps time.Now()
+func insertHeartbeat(t *testing.T, db *bun.DB, monitorID string, status shared.MonitorStatus, ping int, at time.Time) {
+	sm := &sqlModel{
+		ID:        uuid.New().String(),
+		MonitorID: monitorID,
+		Status:    int(status),
+		Ping:      ping,
+		Time:      at.UTC(),
+	}
+	_, err := db.NewInsert().Model(sm).Exec(context.Background())
+	require.NoError(t, err)
+}
+
+func TestServiceImpl_CompactOlderThan(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB(t)
+	repo := NewSQLRepository(db)
+	service := &ServiceImpl{repository: repo, logger: zap.NewNop().Sugar()}
+
+	now := time.Date(2025, 3, 20, 12, 30, 0, 0, time.UTC)
+	oldDay := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	// Two days of old history for two monitors, plus recent raw data
+	for i := 0; i < 2*24*4; i++ {
+		at := oldDay.Add(time.Duration(i) * 15 * time.Minute)
+		status := shared.MonitorStatusUp
+		if i%5 == 0 {
+			status = shared.MonitorStatusDown
+		} else if i%13 == 0 {
+			status = shared.MonitorStatusMaintenance
+		}
+		insertHeartbeat(t, db, "mon-1", status, 10+i%50, at)
+		insertHeartbeat(t, db, "mon-2", shared.MonitorStatusUp, 200+i%7, at.Add(time.Minute))
+	}
+	for i := 0; i < 10; i++ {
+		status := shared.MonitorStatusUp
+		if i == 3 {
+			status = shared.MonitorStatusDown
+		}
+		insertHeartbeat(t, db, "mon-1", status, 42, now.Add(-time.Duration(i)*time.Hour))
+	}
+
+	periods := map[string]time.Duration{
+		"24h":  24 * time.Hour,
+		"30d":  30 * 24 * time.Hour,
+		"365d": 365 * 24 * time.Hour,
+	}
+
+	// Capture raw aggregates before anything is purged
+	oldRaw, err := repo.FindByTimeRange(ctx, oldDay, oldDay.Add(48*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, oldRaw, 2*2*24*4)
+	expectedHourly := BuildRollups(oldRaw, RollupHourly)
+	expectedDaily := BuildRollups(oldRaw, RollupDaily)
+
+	uptimeBefore, err := service.FindUptimeStatsByMonitorID(ctx, "mon-1", periods, now)
+	require.NoError(t, err)
+
+	purged, err := service.CompactOlderThan(ctx, now.AddDate(0, 0, -7))
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(oldRaw)), purged)
+
+	t.Run("raw rows older than the cutoff are purged", func(t *testing.T) {
+		remaining, err := repo.FindByTimeRange(ctx, oldDay, now.AddDate(0, 0, -7))
+		require.NoError(t, err)
+		assert.Empty(t, remaining)
+
+		recent, err := repo.FindByTimeRange(ctx, now.Add(-24*time.Hour), now.Add(time.Second))
+		require.NoError(t, err)
+		assert.Len(t, recent, 10)
+	})
+
+	t.Run("hourly rollups match raw aggregates", func(t *testing.T) {
+		hourly, err := repo.FindRollupsByTimeRange(ctx, RollupHourly, oldDay, oldDay.Add(48*time.Hour))
+		require.NoError(t, err)
+		assertRollupsEqual(t, expectedHourly, hourly)
+	})
+
+	t.Run("daily rollups match raw aggregates", func(t *testing.T) {
+		daily, err := repo.FindRollupsByTimeRange(ctx, RollupDaily, oldDay, oldDay.Add(48*time.Hour))
+		require.NoError(t, err)
+		require.Len(t, daily, 4)
+		assertRollupsEqual(t, expectedDaily, daily)
+	})
+
+	t.Run("uptime reads rollups for old ranges and raw for recent", func(t *testing.T) {
+		uptimeAfter, err := service.FindUptimeStatsByMonitorID(ctx, "mon-1", periods, now)
+		require.NoError(t, err)
+		for name, before := range uptimeBefore {
+			assert.InDelta(t, before, uptimeAfter[name], 1e-9, name)
+		}
+		assert.InDelta(t, 90.0, uptimeAfter["24h"], 1e-9)
+	})
+
+	t.Run("rerunning compaction is a no-op", func(t *testing.T) {
+		purged, err := service.CompactOlderThan(ctx, now.AddDate(0, 0, -7))
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), purged)
+
+		daily, err := repo.FindRollupsByTimeRange(ctx, RollupDaily, oldDay, oldDay.Add(48*time.Hour))
+		require.NoError(t, err)
+		assertRollupsEqual(t, expectedDaily, daily)
+	})
+}
+
+func TestServiceImpl_CompactOlderThan_Idempotent(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB(t)
+	repo := NewSQLRepository(db)
+	service := &ServiceImpl{repository: repo, logger: zap.NewNop().Sugar()}
+
+	day := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 24; i++ {
+		insertHeartbeat(t, db, "mon-1", shared.MonitorStatusUp, 100, day.Add(time.Duration(i)*time.Hour))
+	}
+
+	// A rollup left behind by an interrupted run must be replaced, not added to
+	require.NoError(t, repo.UpsertRollup(ctx, &Rollup{MonitorID: "mon-1", Period: RollupHourly, Bucket: day, Up: 99}))
+
+	_, err := service.CompactOlderThan(ctx, day.AddDate(0, 0, 2))
+	require.NoError(t, err)
+
+	daily, err := repo.FindRollupsByTimeRange(ctx, RollupDaily, day, day.Add(24*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, daily, 1)
+	assert.Equal(t, 24, daily[0].Up)
+	assert.Equal(t, 100.0, daily[0].PingAvg)
+}
+
+func TestServiceImpl_DeleteOlderThan_KeepsRollups(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB(t)
+	repo := NewSQLRepository(db)
+	service := &ServiceImpl{repository: repo, logger: zap.NewNop().Sugar()}
+
+	now := time.Date(2025, 3, 20, 12, 30, 0, 0, time.UTC)
+	oldDay := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 24; i++ {
+		status := shared.MonitorStatusUp
+		if i%4 == 0 {
+			status = shared.MonitorStatusDown
+		}
+		insertHeartbeat(t, db, "mon-1", status, 100, oldDay.Add(time.Duration(i)*time.Hour))
+	}
+	// Older rollups from an earlier compaction
+	require.NoError(t, repo.UpsertRollup(ctx, &Rollup{MonitorID: "mon-1", Period: RollupHourly, Bucket: oldDay.AddDate(0, 0, -10), Up: 24}))
+	require.NoError(t, repo.UpsertRollup(ctx, &Rollup{MonitorID: "mon-1", Period: RollupDaily, Bucket: oldDay.AddDate(0, 0, -10), Up: 24}))
+
+	periods := map[string]time.Duration{"30d": 30 * 24 * time.Hour}
+	uptimeBefore, err := service.FindUptimeStatsByMonitorID(ctx, "mon-1", periods, now)
+	require.NoError(t, err)
+
+	deleted, err := service.DeleteOlderThan(ctx, now.AddDate(0, 0, -7))
+	require.NoError(t, err)
+	assert.Equal(t, int64(24), deleted)
+
+	raw, err := repo.FindByTimeRange(ctx, oldDay, now)
+	require.NoError(t, err)
+	assert.Empty(t, raw)
+
+	// Uptime keeps covering the purged heartbeats through their rollups
+	uptimeAfter, err := service.FindUptimeStatsByMonitorID(ctx, "mon-1", periods, now)
+	require.NoError(t, err)
+	assert.InDelta(t, uptimeBefore["30d"], uptimeAfter["30d"], 1e-9)
+	assert.InDelta(t, 87.5, uptimeAfter["30d"], 1e-9)
+
+	daily, err := repo.FindRollupsByTimeRange(ctx, RollupDaily, oldDay.AddDate(0, 0, -10), now)
+	require.NoError(t, err)
+	assert.Len(t, daily, 2)
+
+	// Rollups go with their own retention
+	_, err = service.DeleteRollupsOlderThan(ctx, oldDay)
+	require.NoError(t, err)
+	daily, err = repo.FindRollupsByTimeRange(ctx, RollupDaily, oldDay.AddDate(0, 0, -10), now)
+	require.NoError(t, err)
+	require.Len(t, daily, 1)
+	assert.Equal(t, oldDay, daily[0].Bucket.UTC())
+}
+
+func TestServiceImpl_CompactOlderThan_NothingToCompact(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB(t)
+	repo := NewSQLRepository(db)
+	service := &ServiceImpl{repository: repo, logger: zap.NewNop().Sugar()}
+
+	purged, err := service.CompactOlderThan(ctx, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), purged)
+
+	insertHeartbeat(t, db, "mon-1", shared.MonitorStatusUp, 100, time.Now().Add(-time.Hour))
+	purged, err = service.CompactOlderThan(ctx, time.Now().AddDate(0, 0, -1))
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), purged)
+}
+
+func assertRollupsEqual(t *testing.T, expected, actual []*Rollup) {
+	t.Helper()
+	require.Len(t, actual, len(expected))
+	for i := range expected {
+		e, a := expected[i], actual[i]
+		assert.Equal(t, e.MonitorID, a.MonitorID)
+		assert.Equal(t, e.Period, a.Period)
+		assert.True(t, e.Bucket.Equal(a.Bucket), "bucket %s != %s", e.Bucket, a.Bucket)
+		assert.Equal(t, e.Up, a.Up)
+		assert.Equal(t, e.Down, a.Down)
+		assert.Equal(t, e.Pending, a.Pending)
+		assert.Equal(t, e.Maintenance, a.Maintenance)
+		assert.Equal(t, e.PingMin, a.PingMin)
+		assert.Equal(t, e.PingMax, a.PingMax)
+		assert.InDelta(t, e.PingAvg, a.PingAvg, 1e-9)
+	}
+}
+
+func TestServiceImpl_FindLatestByMonitorIDs(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB(t)
+	service := &ServiceImpl{repository: NewSQLRepository(db), logger: zap.NewNop().Sugar()}
+
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	insertHeartbeat(t, db, "mon-1", shared.MonitorStatusUp, 10, now.Add(-2*time.Minute))
+	insertHeartbeat(t, db, "mon-1", shared.MonitorStatusDown, 20, now)
+	insertHeartbeat(t, db, "mon-1", shared.MonitorStatusUp, 30, now.Add(-time.Minute))
+	insertHeartbeat(t, db, "mon-2", shared.MonitorStatusUp, 40, now.Add(-time.Hour))
+	insertHeartbeat(t, db, "mon-3", shared.MonitorStatusUp, 50, now)
+
+	latest, err := service.FindLatestByMonitorIDs(ctx, []string{"mon-1", "mon-2", "mon-4"})
+	require.NoError(t, err)
+
+	require.Len(t, latest, 2, "only the requested monitors with heartbeats")
+	assert.Equal(t, shared.MonitorStatusDown, latest["mon-1"].Status)
+	assert.Equal(t, 20, latest["mon-1"].Ping)
+	assert.Equal(t, 40, latest["mon-2"].Ping)
+
+	latest, err = service.FindLatestByMonitorIDs(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, latest)
+}
+
+func TestServiceImpl_StreamByMonitorID(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB(t)
+	service := &ServiceImpl{repository: NewSQLRepository(db), logger: zap.NewNop().Sugar()}
+
+	since := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	until := since.Add(time.Hour)
+	insertHeartbeat(t, db, "mon-1", shared.MonitorStatusUp, 30, since.Add(30*time.Minute))
+	insertHeartbeat(t, db, "mon-1", shared.MonitorStatusDown, 10, since)
+	insertHeartbeat(t, db, "mon-1", shared.MonitorStatusUp, 20, since.Add(-time.Minute))
+	insertHeartbeat(t, db, "mon-1", shared.MonitorStatusUp, 40, until)
+	insertHeartbeat(t, db, "mon-2", shared.MonitorStatusUp, 50, since.Add(time.Minute))
+
+	var pings []int
+	err := service.StreamByMonitorID(ctx, "mon-1", since, until, func(hb *Model) error {
+		pings = append(pings, hb.Ping)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int{10, 30}, pings, "heartbeats in [since, until) of the monitor, oldest first")
+
+	stop := errors.New("stop")
+	calls := 0
+	err = service.StreamByMonitorID(ctx, "mon-1", since, until, func(hb *Model) error {
+		calls++
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, calls)
+}
@@ -13,51 +13,110 @@ import (
 type sqlModel struct {
 	bun.BaseModel `bun:"table:heartbeats,alias:h"`
 
-	ID        string    `bun:"id,pk"`
-	MonitorID string    `bun:"monitor_id,notnull"`
-	Status    int       `bun:"status,notnull"`
-	Msg       string    `bun:"msg"`
-	Ping      int       `bun:"ping"`
-	Duration  int       `bun:"duration"`
-	DownCount int       `bun:"down_count"`
-	Retries   int       `bun:"retries"`
-	Important bool      `bun:"important,notnull,default:false"`
-	Time      time.Time `bun:"time,nullzero,notnull,default:current_timestamp"`
-	EndTime   time.Time `bun:"end_time,nullzero"`
-	Notified  bool      `bun:"notified,notnull,default:false"`
+	ID            string    `bun:"id,pk"`
+	MonitorID     string    `bun:"monitor_id,notnull"`
+	Status        int       `bun:"status,notnull"`
+	Msg           string    `bun:"msg"`
+	Ping          int       `bun:"ping"`
+	Duration      int       `bun:"duration"`
+	DownCount     int       `bun:"down_count"`
+	Retries       int       `bun:"retries"`
+	Important     bool      `bun:"important,notnull,default:false"`
+	Time          time.Time `bun:"time,nullzero,notnull,default:current_timestamp"`
+	EndTime       time.Time `bun:"end_time,nullzero"`
+	Notified      bool      `bun:"notified,notnull,default:false"`
+	ErrorCategory string    `bun:"error_category,notnull,default:''"`
+	BodyHash      string    `bun:"body_hash,notnull,default:''"`
+	ResponseSize  *int64    `bun:"response_size"`
 }
 
 func toDomainModelFromSQL(sm *sqlModel) *Model {
 	return &Model{
-		ID:        sm.ID,
-		MonitorID: sm.MonitorID,
-		Status:    MonitorStatus(sm.Status),
-		Msg:       sm.Msg,
-		Ping:      sm.Ping,
-		Duration:  sm.Duration,
-		DownCount: sm.DownCount,
-		Retries:   sm.Retries,
-		Important: sm.Important,
-		Time:      sm.Time,
-		EndTime:   sm.EndTime,
-		Notified:  sm.Notified,
+		ID:            sm.ID,
+		MonitorID:     sm.MonitorID,
+		Status:        MonitorStatus(sm.Status),
+		Msg:           sm.Msg,
+		Ping:          sm.Ping,
+		Duration:      sm.Duration,
+		DownCount:     sm.DownCount,
+		Retries:       sm.Retries,
+		Important:     sm.Important,
+		Time:          sm.Time,
+		EndTime:       sm.EndTime,
+		Notified:      sm.Notified,
+		ErrorCategory: sm.ErrorCategory,
+		BodyHash:      sm.BodyHash,
+		ResponseSize:  sm.ResponseSize,
 	}
 }
 
 func toSQLModel(m *Model) *sqlModel {
 	return &sqlModel{
-		ID:        m.ID,
-		MonitorID: m.MonitorID,
-		Status:    int(m.Status),
-		Msg:       m.Msg,
-		Ping:      m.Ping,
-		Duration:  m.Duration,
-		DownCount: m.DownCount,
-		Retries:   m.Retries,
-		Important: m.Important,
-		Time:      m.Time,
-		EndTime:   m.EndTime,
-		Notified:  m.Notified,
+		ID:            m.ID,
+		MonitorID:     m.MonitorID,
+		Status:        int(m.Status),
+		Msg:           m.Msg,
+		Ping:          m.Ping,
+		Duration:      m.Duration,
+		DownCount:     m.DownCount,
+		Retries:       m.Retries,
+		Important:     m.Important,
+		Time:          m.Time,
+		EndTime:       m.EndTime,
+		Notified:      m.Notified,
+		ErrorCategory: m.ErrorCategory,
+		BodyHash:      m.BodyHash,
+		ResponseSize:  m.ResponseSize,
+	}
+}
+
+type rollupSQLModel struct {
+	bun.BaseModel `bun:"table:heartbeat_rollups,alias:hr"`
+
+	ID          string    `bun:"id,pk"`
+	MonitorID   string    `bun:"monitor_id,notnull"`
+	Period      string    `bun:"period,notnull"`
+	Bucket      time.Time `bun:"bucket,notnull"`
+	PingMin     float64   `bun:"ping_min,notnull,default:0"`
+	PingAvg     float64   `bun:"ping_avg,notnull,default:0"`
+	PingMax     float64   `bun:"ping_max,notnull,default:0"`
+	Up          int       `bun:"up,notnull,default:0"`
+	Down        int       `bun:"down,notnull,default:0"`
+	Pending     int       `bun:"pending,notnull,default:0"`
+	Maintenance int       `bun:"maintenance,notnull,default:0"`
+	CreatedAt   time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+	UpdatedAt   time.Time `bun:"updated_at,nullzero,notnull,default:current_timestamp"`
+}
+
+func toRollupDomainModelFromSQL(sm *rollupSQLModel) *Rollup {
+	return &Rollup{
+		ID:          sm.ID,
+		MonitorID:   sm.MonitorID,
+		Period:      RollupPeriod(sm.Period),
+		Bucket:      sm.Bucket,
+		PingMin:     sm.PingMin,
+		PingAvg:     sm.PingAvg,
+		PingMax:     sm.PingMax,
+		Up:          sm.Up,
+		Down:        sm.Down,
+		Pending:     sm.Pending,
+		Maintenance: sm.Maintenance,
+	}
+}
+
+func toRollupSQLModel(r *Rollup) *rollupSQLModel {
+	return &rollupSQLModel{
+		ID:          r.ID,
+		MonitorID:   r.MonitorID,
+		Period:      string(r.Period),
+		Bucket:      r.Bucket,
+		PingMin:     r.PingMin,
+		PingAvg:     r.PingAvg,
+		PingMax:     r.PingMax,
+		Up:          r.Up,
+		Down:        r.Down,
+		Pending:     r.Pending,
+		Maintenance: r.Maintenance,
 	}
 }
 
@@ -176,41 +235,124 @@ func (r *SQLRepositoryImpl) FindByMonitorIDPaginated(
 	return models, nil
 }
 
-func (r *SQLRepositoryImpl) FindUptimeStatsByMonitorID(
-	ctx context.Context,
-	monitorID string,
-	periods map[string]time.Duration,
-	now time.Time,
-) (map[string]float64, error) {
-	stats := make(map[string]float64)
+func (r *SQLRepositoryImpl) FindLatestByMonitorIDs(ctx context.Context, monitorIDs []string) ([]*Model, error) {
+	if len(monitorIDs) == 0 {
+		return nil, nil
+	}
 
-	for name, duration := range periods {
-		since := now.Add(-duration)
+	var sms []*sqlModel
+	err := r.db.NewSelect().
+		Model(&sms).
+		Where("h.monitor_id IN (?)", bun.In(monitorIDs)).
+		Where("h.time = (SELECT MAX(latest.time) FROM heartbeats AS latest WHERE latest.monitor_id = h.monitor_id)").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-		var result struct {
-			Total int `bun:"total"`
-			Up    int `bun:"up"`
+	// Heartbeats sharing the latest time of a monitor are returned once
+	seen := make(map[string]bool, len(sms))
+	models := make([]*Model, 0, len(sms))
+	for _, sm := range sms {
+		if seen[sm.MonitorID] {
+			continue
 		}
+		seen[sm.MonitorID] = true
+		models = append(models, toDomainModelFromSQL(sm))
+	}
+	return models, nil
+}
 
-		err := r.db.NewSelect().
-			Model((*sqlModel)(nil)).
-			ColumnExpr("COUNT(*) as total").
-			ColumnExpr("COUNT(CASE WHEN status = ? THEN 1 END) as up", 1).
-			Where("monitor_id = ? AND time >= ?", monitorID, since).
-			Scan(ctx, &result)
+func (r *SQLRepositoryImpl) FindUptimeCountsByMonitorID(ctx context.Context, monitorID string, since time.Time) (*UptimeCounts, error) {
+	var result struct {
+		Total int `bun:"total"`
+		Up    int `bun:"up"`
+	}
 
-		if err != nil {
-			return nil, err
-		}
+	err := r.db.NewSelect().
+		Model((*sqlModel)(nil)).
+		ColumnExpr("COUNT(*) as total").
+		ColumnExpr("COUNT(CASE WHEN status = ? THEN 1 END) as up", int(shared.MonitorStatusUp)).
+		Where("monitor_id = ? AND time >= ?", monitorID, since).
+		Scan(ctx, &result)
+	if err != nil {
+		return nil, err
+	}
 
-		if result.Total > 0 {
-			stats[name] = float64(result.Up) / float64(result.Total) * 100
-		} else {
-			stats[name] = 0
+	return &UptimeCounts{Up: result.Up, Total: result.Total}, nil
+}
+
+func (r *SQLRepositoryImpl) FindOldestTime(ctx context.Context) (*time.Time, error) {
+	sm := new(sqlModel)
+	err := r.db.NewSelect().
+		Model(sm).
+		Column("time").
+		Order("time ASC").
+		Limit(1).
+		Scan(ctx)
+	if err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			return nil, nil
 		}
+		return nil, err
+	}
+	return &sm.Time, nil
+}
+
+func (r *SQLRepositoryImpl) FindByTimeRange(ctx context.Context, since, until time.Time) ([]*Model, error) {
+	var sms []*sqlModel
+	err := r.db.NewSelect().
+		Model(&sms).
+		Where("time >= ? AND time < ?", since, until).
+		Order("time ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	return stats, nil
+	models := make([]*Model, 0, len(sms))
+	for _, sm := range sms {
+		models = append(models, toDomainModelFromSQL(sm))
+	}
+	return models, nil
+}
+
+func (r *SQLRepositoryImpl) FindMonitorIDsByTimeRange(ctx context.Context, since, until time.Time) ([]string, error) {
+	var monitorIDs []string
+	err := r.db.NewSelect().
+		Model((*sqlModel)(nil)).
+		Distinct().
+		Column("monitor_id").
+		Where("time >= ? AND time < ?", since, until).
+		Order("monitor_id ASC").
+		Scan(ctx, &monitorIDs)
+	if err != nil {
+		return nil, err
+	}
+	return monitorIDs, nil
+}
+
+func (r *SQLRepositoryImpl) StreamByMonitorID(ctx context.Context, monitorID string, since, until time.Time, fn func(*Model) error) error {
+	rows, err := r.db.NewSelect().
+		Model((*sqlModel)(nil)).
+		Where("monitor_id = ? AND time >= ? AND time < ?", monitorID, since, until).
+		Order("time ASC").
+		Rows(ctx)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sm sqlModel
+		if err := r.db.ScanRow(ctx, rows, &sm); err != nil {
+			return err
+		}
+		if err := fn(toDomainModelFromSQL(&sm)); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
 }
 
 func (r *SQLRepositoryImpl) DeleteOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
@@ -226,10 +368,139 @@ func (r *SQLRepositoryImpl) DeleteOlderThan(ctx context.Context, cutoff time.Tim
 	return rowsAffected, nil
 }
 
+func (r *SQLRepositoryImpl) DeleteByTimeRange(ctx context.Context, since, until time.Time) (int64, error) {
+	result, err := r.db.NewDelete().
+		Model((*sqlModel)(nil)).
+		Where("time >= ? AND time < ?", since, until).
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected, nil
+}
+
+func (r *SQLRepositoryImpl) DeleteByMonitorIDAndTimeRange(ctx context.Context, monitorID string, since, until time.Time) (int64, error) {
+	result, err := r.db.NewDelete().
+		Model((*sqlModel)(nil)).
+		Where("monitor_id = ? AND time >= ? AND time < ?", monitorID, since, until).
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected, nil
+}
+
 func (r *SQLRepositoryImpl) DeleteByMonitorID(ctx context.Context, monitorID string) error {
 	_, err := r.db.NewDelete().
 		Model((*sqlModel)(nil)).
 		Where("monitor_id = ?", monitorID).
 		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.NewDelete().
+		Model((*rollupSQLModel)(nil)).
+		Where("monitor_id = ?", monitorID).
+		Exec(ctx)
 	return err
 }
+
+func (r *SQLRepositoryImpl) UpsertRollup(ctx context.Context, rollup *Rollup) error {
+	sm := toRollupSQLModel(rollup)
+	sm.UpdatedAt = time.Now()
+
+	// Rollups are recomputed from raw heartbeats, so an existing bucket is replaced rather than merged
+	result, err := r.db.NewUpdate().
+		Model(sm).
+		Where("monitor_id = ? AND period = ? AND bucket = ?", sm.MonitorID, sm.Period, sm.Bucket).
+		Set("ping_min = ?", sm.PingMin).
+		Set("ping_avg = ?", sm.PingAvg).
+		Set("ping_max = ?", sm.PingMax).
+		Set("up = ?", sm.Up).
+		Set("down = ?", sm.Down).
+		Set("pending = ?", sm.Pending).
+		Set("maintenance = ?", sm.Maintenance).
+		Set("updated_at = ?", sm.UpdatedAt).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		sm.ID = uuid.New().String()
+		sm.CreatedAt = sm.UpdatedAt
+		_, err = r.db.NewInsert().Model(sm).Exec(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *SQLRepositoryImpl) FindRollupsByTimeRange(ctx context.Context, period RollupPeriod, since, until time.Time) ([]*Rollup, error) {
+	var sms []*rollupSQLModel
+	err := r.db.NewSelect().
+		Model(&sms).
+		Where("period = ? AND bucket >= ? AND bucket < ?", string(period), since, until).
+		Order("monitor_id ASC", "bucket ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rollups := make([]*Rollup, 0, len(sms))
+	for _, sm := range sms {
+		rollups = append(rollups, toRollupDomainModelFromSQL(sm))
+	}
+	return rollups, nil
+}
+
+func (r *SQLRepositoryImpl) FindRollupUptimeCountsByMonitorID(ctx context.Context, monitorID string, period RollupPeriod, since time.Time) (*UptimeCounts, error) {
+	var result struct {
+		Up          int `bun:"up"`
+		Down        int `bun:"down"`
+		Pending     int `bun:"pending"`
+		Maintenance int `bun:"maintenance"`
+	}
+
+	err := r.db.NewSelect().
+		Model((*rollupSQLModel)(nil)).
+		ColumnExpr("COALESCE(SUM(up), 0) as up").
+		ColumnExpr("COALESCE(SUM(down), 0) as down").
+		ColumnExpr("COALESCE(SUM(pending), 0) as pending").
+		ColumnExpr("COALESCE(SUM(maintenance), 0) as maintenance").
+		Where("monitor_id = ? AND period = ? AND bucket >= ?", monitorID, string(period), since).
+		Scan(ctx, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UptimeCounts{
+		Up:    result.Up,
+		Total: result.Up + result.Down + result.Pending + result.Maintenance,
+	}, nil
+}
+
+func (r *SQLRepositoryImpl) DeleteRollupsOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	result, err := r.db.NewDelete().
+		Model((*rollupSQLModel)(nil)).
+		Where("bucket < ?", cutoff).
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected, nil
+}
@@ -31,6 +31,8 @@ type IngesterTaskPayload struct {
 	MonitorMaxRetries  int                  `json:"monitor_max_retries"`
 	MonitorRetryInt    int                  `json:"monitor_retry_interval"`
 	MonitorResendInt   int                  `json:"monitor_resend_interval"`
+	MonitorWarmup      int                  `json:"monitor_warmup_checks"`
+	MonitorActivatedAt *time.Time           `json:"monitor_activated_at,omitempty"`
 	MonitorConfig      string               `json:"monitor_config"`
 	Status             shared.MonitorStatus `json:"status"`
 	Message            string               `json:"message"`
@@ -38,8 +40,12 @@ type IngesterTaskPayload struct {
 	StartTime          time.Time            `json:"start_time"`
 	EndTime            time.Time            `json:"end_time"`
 	IsUnderMaintenance bool                 `json:"is_under_maintenance"`
+	AlertsSuppressed   bool                 `json:"alerts_suppressed,omitempty"`
 	TLSInfo            *certificate.TLSInfo `json:"tls_info,omitempty"`
 	CheckCertExpiry    bool                 `json:"check_cert_expiry"`
+	ErrorCategory      string               `json:"error_category,omitempty"`
+	BodyHash           string               `json:"body_hash,omitempty"`
+	ResponseSize       *int64               `json:"response_size,omitempty"`
 }
 
 // IngesterTaskHandler handles ingester tasks from the queue
@@ -48,6 +54,8 @@ type IngesterTaskHandler struct {
 	certificateService        certificate.Service
 	monitorMaintenanceService monitor_maintenance.Service
 	eventBus                  events.EventBus
+	webhook                   *HeartbeatWebhook
+	influx                    *InfluxWriter
 	logger                    *zap.SugaredLogger
 }
 
@@ -57,6 +65,8 @@ func NewIngesterTaskHandler(
 	certificateService certificate.Service,
 	monitorMaintenanceService monitor_maintenance.Service,
 	eventBus events.EventBus,
+	webhook *HeartbeatWebhook,
+	influx *InfluxWriter,
 	logger *zap.SugaredLogger,
 ) *IngesterTaskHandler {
 	return &IngesterTaskHandler{
@@ -64,6 +74,8 @@ func NewIngesterTaskHandler(
 		certificateService:        certificateService,
 		monitorMaintenanceService: monitorMaintenanceService,
 		eventBus:                  eventBus,
+		webhook:                   webhook,
+		influx:                    influx,
 		logger:                    logger.With("component", "ingester_handler"),
 	}
 }
@@ -125,6 +137,85 @@ func (h *IngesterTaskHandler) isImportantForNotification(prevBeatStatus, currBea
 		(prevBeatStatus == pending && currBeatStatus == down)
 }
 
+// isWarmingUp checks whether the current check is one of the first MonitorWarmup checks
+// since the monitor was created or activated, counting the stored heartbeats after activation
+func (h *IngesterTaskHandler) isWarmingUp(ctx context.Context, payload *IngesterTaskPayload) bool {
+	if payload.MonitorWarmup <= 0 {
+		return false
+	}
+
+	recentBeats, err := h.heartbeatService.FindByMonitorIDPaginated(ctx, payload.MonitorID, payload.MonitorWarmup, 0, nil, false)
+	if err != nil {
+		h.logger.Errorw("Failed to get recent heartbeats for warm-up check",
+			"monitor_id", payload.MonitorID,
+			"error", err,
+		)
+		return false
+	}
+
+	checksSinceActivation := 0
+	for _, beat := range recentBeats {
+		if payload.MonitorActivatedAt != nil && beat.Time.Before(*payload.MonitorActivatedAt) {
+			break
+		}
+		checksSinceActivation++
+	}
+
+	h.logger.Debugw("Warm-up check count",
+		"monitor_id", payload.MonitorID,
+		"checks_since_activation", checksSinceActivation,
+		"warmup_checks", payload.MonitorWarmup,
+	)
+
+	return checksSinceActivation < payload.MonitorWarmup
+}
+
+// detectBodyChange compares the body hash of hb with the last one recorded and marks
+// hb as degraded when the content changed. Beats without a hash (e.g. failed checks)
+// carry the previous hash forward so the comparison survives outages.
+func (h *IngesterTaskHandler) detectBodyChange(previousBeat *heartbeat.Model, hb *heartbeat.CreateUpdateDto) bool {
+	if hb.BodyHash == "" {
+		hb.BodyHash = previousBeat.BodyHash
+		return false
+	}
+	if previousBeat.BodyHash == "" || previousBeat.BodyHash == hb.BodyHash || hb.Status != shared.MonitorStatusUp {
+		return false
+	}
+
+	h.logger.Infow("Response body changed", "monitor_id", hb.MonitorID)
+	hb.ErrorCategory = shared.ErrorCategoryBodyChanged
+	hb.Msg = fmt.Sprintf("Response body changed: %s", hb.Msg)
+	return true
+}
+
+// storeSkippedHeartbeat stores the heartbeat of a check that did not run because the
+// previous one was still running. It keeps the previous status, retries and body hash, so
+// it neither changes the monitor's state nor notifies.
+func (h *IngesterTaskHandler) storeSkippedHeartbeat(ctx context.Context, payload *IngesterTaskPayload, previousBeat *heartbeat.Model, hb *heartbeat.CreateUpdateDto) error {
+	hb.Status = shared.MonitorStatusPending
+	if previousBeat != nil {
+		hb.Status = previousBeat.Status
+		hb.BodyHash = previousBeat.BodyHash
+	}
+
+	dbHb, err := h.heartbeatService.Create(ctx, hb)
+	if err != nil {
+		h.logger.Errorw("Failed to create heartbeat",
+			"monitor_id", payload.MonitorID,
+			"error", err,
+		)
+		return fmt.Errorf("failed to create heartbeat: %w", err)
+	}
+
+	if h.webhook != nil {
+		h.webhook.Post(dbHb, payload)
+	}
+	if h.influx != nil {
+		h.influx.Write(dbHb, payload)
+	}
+	return nil
+}
+
 // processHeartbeat processes and stores the heartbeat
 func (h *IngesterTaskHandler) processHeartbeat(ctx context.Context, payload *IngesterTaskPayload) error {
 	// Get the previous heartbeat
@@ -143,22 +234,35 @@ func (h *IngesterTaskHandler) processHeartbeat(ctx context.Context, payload *Ing
 	isFirstBeat := previousBeat == nil
 
 	hb := &heartbeat.CreateUpdateDto{
-		MonitorID: payload.MonitorID,
-		Status:    payload.Status,
-		Msg:       payload.Message,
-		Ping:      payload.PingMs,
-		Duration:  0,
-		DownCount: 0,
-		Retries:   0,
-		Important: false,
-		Time:      payload.StartTime,
-		EndTime:   payload.EndTime,
-		Notified:  false,
+		MonitorID:        payload.MonitorID,
+		Status:           payload.Status,
+		Msg:              payload.Message,
+		Ping:             payload.PingMs,
+		Duration:         0,
+		DownCount:        0,
+		Retries:          0,
+		Important:        false,
+		Time:             payload.StartTime,
+		EndTime:          payload.EndTime,
+		Notified:         false,
+		ErrorCategory:    payload.ErrorCategory,
+		BodyHash:         payload.BodyHash,
+		ResponseSize:     payload.ResponseSize,
+		AlertsSuppressed: payload.AlertsSuppressed,
 	}
 
 	if !isFirstBeat {
 		hb.DownCount = previousBeat.DownCount
 		hb.Retries = previousBeat.Retries
+
+		// Seconds covered by this beat, used for time-weighted uptime
+		if elapsed := payload.StartTime.Sub(previousBeat.Time); elapsed > 0 {
+			hb.Duration = capBeatDuration(int(elapsed.Seconds()), payload)
+		}
+	}
+
+	if payload.ErrorCategory == shared.ErrorCategorySkipped {
+		return h.storeSkippedHeartbeat(ctx, payload, previousBeat, hb)
 	}
 
 	// Mark as pending if max retries is set and retries is less than max retries
@@ -171,6 +275,8 @@ func (h *IngesterTaskHandler) processHeartbeat(ctx context.Context, payload *Ing
 		hb.Retries = 0
 	}
 
+	bodyChanged := !isFirstBeat && h.detectBodyChange(previousBeat, hb)
+
 	isImportant := isFirstBeat || h.isImportantBeat(previousBeat.Status, hb.Status)
 	shouldNotify := false
 
@@ -199,6 +305,37 @@ func (h *IngesterTaskHandler) processHeartbeat(ctx context.Context, payload *Ing
 		}
 	}
 
+	// A changed response body is reported even though the monitor stays up
+	if bodyChanged {
+		hb.Important = true
+		shouldNotify = true
+		hb.Notified = true
+	}
+
+	// Notifications are suppressed during warm-up, the heartbeat itself is still stored. An
+	// outage that started during warm-up is notified by the first check after it.
+	if shouldNotify && h.isWarmingUp(ctx, payload) {
+		h.logger.Debugw("Suppressing notification during monitor warm-up",
+			"monitor_name", payload.MonitorName,
+			"warmup_checks", payload.MonitorWarmup,
+		)
+		shouldNotify = false
+		hb.Notified = false
+	}
+
+	// Checks in maintenance windows that keep running are flagged by the producer, the
+	// listener skips their notifications so they aren't recorded as notified
+	if payload.AlertsSuppressed {
+		hb.Notified = false
+	} else if !shouldNotify && h.outageNotNotified(ctx, payload, previousBeat, hb) {
+		// The outage started while its alerts were held back, it is notified once they no
+		// longer are. The beat becomes the important one of the outage.
+		h.logger.Debugw("Notifying outage held back so far", "monitor_name", payload.MonitorName)
+		hb.Important = true
+		shouldNotify = true
+		hb.Notified = true
+	}
+
 	// Log status
 	if payload.Status == shared.MonitorStatusUp {
 		h.logger.Debugw("Monitor up",
@@ -263,6 +400,13 @@ func (h *IngesterTaskHandler) processHeartbeat(ctx context.Context, payload *Ing
 		return fmt.Errorf("failed to create heartbeat: %w", err)
 	}
 
+	if h.webhook != nil {
+		h.webhook.Post(dbHb, payload)
+	}
+	if h.influx != nil {
+		h.influx.Write(dbHb, payload)
+	}
+
 	// Publish events
 	if isFirstBeat || previousBeat.Status != hb.Status {
 		h.eventBus.Publish(events.Event{
@@ -280,3 +424,41 @@ func (h *IngesterTaskHandler) processHeartbeat(ctx context.Context, payload *Ing
 
 	return nil
 }
+
+// outageNotNotified reports whether the monitor is still down in an outage that wasn't
+// notified, i.e. the latest important beat, the start of the outage, is a down beat that
+// didn't notify
+func (h *IngesterTaskHandler) outageNotNotified(ctx context.Context, payload *IngesterTaskPayload, previousBeat *heartbeat.Model, hb *heartbeat.CreateUpdateDto) bool {
+	if previousBeat == nil || previousBeat.Status != shared.MonitorStatusDown || hb.Status != shared.MonitorStatusDown {
+		return false
+	}
+
+	important := true
+	importantBeats, err := h.heartbeatService.FindByMonitorIDPaginated(ctx, payload.MonitorID, 1, 0, &important, false)
+	if err != nil {
+		h.logger.Errorw("Failed to get the start of the outage",
+			"monitor_id", payload.MonitorID,
+			"error", err,
+		)
+		return false
+	}
+	if len(importantBeats) == 0 {
+		return false
+	}
+	start := importantBeats[0]
+	return start.Status == shared.MonitorStatusDown && !start.Notified && !h.isWarmingUp(ctx, payload)
+}
+
+// capBeatDuration limits the seconds a beat covers to twice the monitor interval, or its
+// timeout when the interval is unknown. After a gap in checks, e.g. while the workers were
+// down or the monitor paused, the beat would otherwise count the whole gap in the uptime.
+func capBeatDuration(seconds int, payload *IngesterTaskPayload) int {
+	limit := 2 * payload.MonitorInterval
+	if limit <= 0 {
+		limit = 2 * payload.MonitorTimeout
+	}
+	if limit > 0 && seconds > limit {
+		return limit
+	}
+	return seconds
+}
@@ -0,0 +1,536 @@
+package ingester
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"peekaping/internal/modules/events"
+	"peekaping/internal/modules/heartbeat"
+	"peekaping/internal/modules/shared"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+// MockHeartbeatService is a mock implementation of heartbeat.Service
+type MockHeartbeatService struct {
+	mock.Mock
+}
+
+func (m *MockHeartbeatService) Create(ctx context.Context, entity *heartbeat.CreateUpdateDto) (*heartbeat.Model, error) {
+	args := m.Called(ctx, entity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*heartbeat.Model), args.Error(1)
+}
+
+func (m *MockHeartbeatService) FindByID(ctx context.Context, id string) (*heartbeat.Model, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*heartbeat.Model), args.Error(1)
+}
+
+func (m *MockHeartbeatService) FindAll(ctx context.Context, page int, limit int) ([]*heartbeat.Model, error) {
+	args := m.Called(ctx, page, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*heartbeat.Model), args.Error(1)
+}
+
+func (m *MockHeartbeatService) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockHeartbeatService) FindUptimeStatsByMonitorID(ctx context.Context, monitorID string, periods map[string]time.Duration, now time.Time) (map[string]float64, error) {
+	args := m.Called(ctx, monitorID, periods, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]float64), args.Error(1)
+}
+
+func (m *MockHeartbeatService) DeleteOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	args := m.Called(ctx, cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockHeartbeatService) CompactOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	args := m.Called(ctx, cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockHeartbeatService) DeleteRollupsOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	args := m.Called(ctx, cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockHeartbeatService) FindByMonitorIDPaginated(ctx context.Context, monitorID string, limit, page int, important *bool, reverse bool) ([]*heartbeat.Model, error) {
+	args := m.Called(ctx, monitorID, limit, page, important, reverse)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*heartbeat.Model), args.Error(1)
+}
+
+func (m *MockHeartbeatService) FindLatestByMonitorIDs(ctx context.Context, monitorIDs []string) (map[string]*heartbeat.Model, error) {
+	args := m.Called(ctx, monitorIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]*heartbeat.Model), args.Error(1)
+}
+
+func (m *MockHeartbeatService) DeleteByMonitorID(ctx context.Context, monitorID string) error {
+	args := m.Called(ctx, monitorID)
+	return args.Error(0)
+}
+
+func (m *MockHeartbeatService) StreamByMonitorID(ctx context.Context, monitorID string, since, until time.Time, fn func(*heartbeat.Model) error) error {
+	args := m.Called(ctx, monitorID, since, until, fn)
+	return args.Error(0)
+}
+
+// MockEventBus is a mock implementation of events.EventBus
+type MockEventBus struct {
+	mock.Mock
+}
+
+func (m *MockEventBus) Subscribe(eventType events.EventType, handler events.EventHandler) {
+	m.Called(eventType, handler)
+}
+
+func (m *MockEventBus) Publish(event events.Event) {
+	m.Called(event)
+}
+
+func (m *MockEventBus) Close() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func setupIngesterHandler() (*IngesterTaskHandler, *MockHeartbeatService, *MockEventBus) {
+	mockHeartbeatSvc := new(MockHeartbeatService)
+	mockEventBus := new(MockEventBus)
+
+	handler := &IngesterTaskHandler{
+		heartbeatService: mockHeartbeatSvc,
+		eventBus:         mockEventBus,
+		logger:           zap.NewNop().Sugar(),
+	}
+
+	return handler, mockHeartbeatSvc, mockEventBus
+}
+
+func isEventType(eventType events.EventType) interface{} {
+	return mock.MatchedBy(func(e events.Event) bool {
+		return e.Type == eventType
+	})
+}
+
+func beatsAt(status shared.MonitorStatus, times ...time.Time) []*heartbeat.Model {
+	beats := make([]*heartbeat.Model, 0, len(times))
+	for _, t := range times {
+		beats = append(beats, &heartbeat.Model{MonitorID: "mon-1", Status: status, Time: t})
+	}
+	return beats
+}
+
+func TestProcessHeartbeat_Warmup(t *testing.T) {
+	ctx := context.Background()
+	activatedAt := time.Now().UTC().Add(-time.Hour)
+
+	newPayload := func() *IngesterTaskPayload {
+		return &IngesterTaskPayload{
+			MonitorID:          "mon-1",
+			MonitorName:        "Test Monitor",
+			MonitorType:        "http",
+			MonitorInterval:    60,
+			MonitorWarmup:      3,
+			MonitorActivatedAt: &activatedAt,
+			Status:             shared.MonitorStatusDown,
+			StartTime:          time.Now().UTC(),
+			EndTime:            time.Now().UTC(),
+		}
+	}
+
+	t.Run("notifications are suppressed during warm-up", func(t *testing.T) {
+		handler, mockHeartbeatSvc, mockEventBus := setupIngesterHandler()
+		payload := newPayload()
+
+		recent := beatsAt(shared.MonitorStatusUp, activatedAt.Add(2*time.Minute))
+		mockHeartbeatSvc.On("FindByMonitorIDPaginated", ctx, "mon-1", 1, 0, (*bool)(nil), false).Return(recent, nil)
+		mockHeartbeatSvc.On("FindByMonitorIDPaginated", ctx, "mon-1", 3, 0, (*bool)(nil), false).Return(recent, nil)
+		mockHeartbeatSvc.On("Create", ctx, mock.MatchedBy(func(dto *heartbeat.CreateUpdateDto) bool {
+			return dto.Status == shared.MonitorStatusDown && dto.Important && !dto.Notified
+		})).Return(&heartbeat.Model{ID: "hb-1", MonitorID: "mon-1", Status: shared.MonitorStatusDown}, nil)
+		mockEventBus.On("Publish", isEventType(events.MonitorStatusChanged)).Return()
+
+		err := handler.processHeartbeat(ctx, payload)
+		assert.NoError(t, err)
+
+		mockHeartbeatSvc.AssertExpectations(t)
+		mockEventBus.AssertExpectations(t)
+		mockEventBus.AssertNotCalled(t, "Publish", isEventType(events.ImportantHeartbeat))
+	})
+
+	t.Run("checks before activation are not counted", func(t *testing.T) {
+		handler, mockHeartbeatSvc, mockEventBus := setupIngesterHandler()
+		payload := newPayload()
+
+		recent := append(
+			beatsAt(shared.MonitorStatusUp, activatedAt.Add(time.Minute)),
+			beatsAt(shared.MonitorStatusUp, activatedAt.Add(-time.Minute), activatedAt.Add(-2*time.Minute))...,
+		)
+		mockHeartbeatSvc.On("FindByMonitorIDPaginated", ctx, "mon-1", 1, 0, (*bool)(nil), false).Return(recent[:1], nil)
+		mockHeartbeatSvc.On("FindByMonitorIDPaginated", ctx, "mon-1", 3, 0, (*bool)(nil), false).Return(recent, nil)
+		mockHeartbeatSvc.On("Create", ctx, mock.MatchedBy(func(dto *heartbeat.CreateUpdateDto) bool {
+			return !dto.Notified
+		})).Return(&heartbeat.Model{ID: "hb-1", MonitorID: "mon-1", Status: shared.MonitorStatusDown}, nil)
+		mockEventBus.On("Publish", isEventType(events.MonitorStatusChanged)).Return()
+
+		err := handler.processHeartbeat(ctx, payload)
+		assert.NoError(t, err)
+
+		mockHeartbeatSvc.AssertExpectations(t)
+		mockEventBus.AssertNotCalled(t, "Publish", isEventType(events.ImportantHeartbeat))
+	})
+
+	t.Run("notifications are sent after warm-up", func(t *testing.T) {
+		handler, mockHeartbeatSvc, mockEventBus := setupIngesterHandler()
+		payload := newPayload()
+
+		recent := beatsAt(shared.MonitorStatusUp,
+			activatedAt.Add(3*time.Minute),
+			activatedAt.Add(2*time.Minute),
+			activatedAt.Add(time.Minute),
+		)
+		mockHeartbeatSvc.On("FindByMonitorIDPaginated", ctx, "mon-1", 1, 0, (*bool)(nil), false).Return(recent[:1], nil)
+		mockHeartbeatSvc.On("FindByMonitorIDPaginated", ctx, "mon-1", 3, 0, (*bool)(nil), false).Return(recent, nil)
+		mockHeartbeatSvc.On("Create", ctx, mock.MatchedBy(func(dto *heartbeat.CreateUpdateDto) bool {
+			return dto.Notified
+		})).Return(&heartbeat.Model{ID: "hb-1", MonitorID: "mon-1", Status: shared.MonitorStatusDown}, nil)
+		mockEventBus.On("Publish", isEventType(events.MonitorStatusChanged)).Return()
+		mockEventBus.On("Publish", isEventType(events.ImportantHeartbeat)).Return()
+
+		err := handler.processHeartbeat(ctx, payload)
+		assert.NoError(t, err)
+
+		mockHeartbeatSvc.AssertExpectations(t)
+		mockEventBus.AssertExpectations(t)
+	})
+
+	t.Run("outage held back by warm-up is notified once it ends", func(t *testing.T) {
+		handler, mockHeartbeatSvc, mockEventBus := setupIngesterHandler()
+		payload := newPayload()
+
+		recent := beatsAt(shared.MonitorStatusDown,
+			activatedAt.Add(3*time.Minute),
+			activatedAt.Add(2*time.Minute),
+			activatedAt.Add(time.Minute),
+		)
+		outageStart := &heartbeat.Model{MonitorID: "mon-1", Status: shared.MonitorStatusDown, Important: true, Time: activatedAt.Add(time.Minute)}
+		mockHeartbeatSvc.On("FindByMonitorIDPaginated", ctx, "mon-1", 1, 0, (*bool)(nil), false).Return(recent[:1], nil)
+		mockHeartbeatSvc.On("FindByMonitorIDPaginated", ctx, "mon-1", 3, 0, (*bool)(nil), false).Return(recent, nil)
+		mockHeartbeatSvc.On("FindByMonitorIDPaginated", ctx, "mon-1", 1, 0, isImportantFilter, false).Return([]*heartbeat.Model{outageStart}, nil)
+		mockHeartbeatSvc.On("Create", ctx, mock.MatchedBy(func(dto *heartbeat.CreateUpdateDto) bool {
+			return dto.Status == shared.MonitorStatusDown && dto.Important && dto.Notified
+		})).Return(&heartbeat.Model{ID: "hb-4", MonitorID: "mon-1", Status: shared.MonitorStatusDown}, nil)
+		mockEventBus.On("Publish", isEventType(events.MonitorStatusChanged)).Return().Maybe()
+		mockEventBus.On("Publish", isEventType(events.ImportantHeartbeat)).Return()
+
+		err := handler.processHeartbeat(ctx, payload)
+		assert.NoError(t, err)
+
+		mockHeartbeatSvc.AssertExpectations(t)
+		mockEventBus.AssertExpectations(t)
+	})
+
+	t.Run("outage is held back until warm-up ends", func(t *testing.T) {
+		handler, mockHeartbeatSvc, mockEventBus := setupIngesterHandler()
+		payload := newPayload()
+
+		recent := beatsAt(shared.MonitorStatusDown, activatedAt.Add(2*time.Minute), activatedAt.Add(time.Minute))
+		outageStart := &heartbeat.Model{MonitorID: "mon-1", Status: shared.MonitorStatusDown, Important: true, Time: activatedAt.Add(time.Minute)}
+		mockHeartbeatSvc.On("FindByMonitorIDPaginated", ctx, "mon-1", 1, 0, (*bool)(nil), false).Return(recent[:1], nil)
+		mockHeartbeatSvc.On("FindByMonitorIDPaginated", ctx, "mon-1", 3, 0, (*bool)(nil), false).Return(recent, nil)
+		mockHeartbeatSvc.On("FindByMonitorIDPaginated", ctx, "mon-1", 1, 0, isImportantFilter, false).Return([]*heartbeat.Model{outageStart}, nil)
+		mockHeartbeatSvc.On("Create", ctx, mock.MatchedBy(func(dto *heartbeat.CreateUpdateDto) bool {
+			return !dto.Important && !dto.Notified
+		})).Return(&heartbeat.Model{ID: "hb-3", MonitorID: "mon-1", Status: shared.MonitorStatusDown}, nil)
+		mockEventBus.On("Publish", isEventType(events.MonitorStatusChanged)).Return().Maybe()
+
+		err := handler.processHeartbeat(ctx, payload)
+		assert.NoError(t, err)
+
+		mockHeartbeatSvc.AssertExpectations(t)
+		mockEventBus.AssertNotCalled(t, "Publish", isEventType(events.ImportantHeartbeat))
+	})
+
+	t.Run("warm-up disabled", func(t *testing.T) {
+		handler, mockHeartbeatSvc, mockEventBus := setupIngesterHandler()
+		payload := newPayload()
+		payload.MonitorWarmup = 0
+
+		recent := beatsAt(shared.MonitorStatusUp, activatedAt.Add(time.Minute))
+		mockHeartbeatSvc.On("FindByMonitorIDPaginated", ctx, "mon-1", 1, 0, (*bool)(nil), false).Return(recent, nil)
+		mockHeartbeatSvc.On("Create", ctx, mock.MatchedBy(func(dto *heartbeat.CreateUpdateDto) bool {
+			return dto.Notified
+		})).Return(&heartbeat.Model{ID: "hb-1", MonitorID: "mon-1", Status: shared.MonitorStatusDown}, nil)
+		mockEventBus.On("Publish", isEventType(events.MonitorStatusChanged)).Return()
+		mockEventBus.On("Publish", isEventType(events.ImportantHeartbeat)).Return()
+
+		err := handler.processHeartbeat(ctx, payload)
+		assert.NoError(t, err)
+
+		mockHeartbeatSvc.AssertExpectations(t)
+		mockEventBus.AssertExpectations(t)
+	})
+}
+
+// isImportantFilter matches the filter of queries for important beats
+var isImportantFilter = mock.MatchedBy(func(important *bool) bool { return important != nil && *important })
+
+func TestProcessHeartbeat_SuppressedOutage(t *testing.T) {
+	ctx := context.Background()
+
+	newPayload := func(alertsSuppressed bool) *IngesterTaskPayload {
+		return &IngesterTaskPayload{
+			MonitorID:        "mon-1",
+			MonitorName:      "Backup job",
+			MonitorType:      "push",
+			MonitorInterval:  60,
+			Status:           shared.MonitorStatusDown,
+			StartTime:        time.Now().UTC(),
+			EndTime:          time.Now().UTC(),
+			ErrorCategory:    shared.ErrorCategoryNoHeartbeat,
+			AlertsSuppressed: alertsSuppressed,
+		}
+	}
+	previous := beatsAt(shared.MonitorStatusDown, time.Now().UTC().Add(-time.Minute))
+
+	t.Run("outage starting in a maintenance window is not recorded as notified", func(t *testing.T) {
+		handler, mockHeartbeatSvc, mockEventBus := setupIngesterHandler()
+
+		mockHeartbeatSvc.On("FindByMonitorIDPaginated", ctx, "mon-1", 1, 0, (*bool)(nil), false).
+			Return(beatsAt(shared.MonitorStatusUp, time.Now().UTC().Add(-time.Minute)), nil)
+		mockHeartbeatSvc.On("Create", ctx, mock.MatchedBy(func(dto *heartbeat.CreateUpdateDto) bool {
+			return dto.Important && !dto.Notified && dto.AlertsSuppressed
+		})).Return(&heartbeat.Model{ID: "hb-1", MonitorID: "mon-1", Status: shared.MonitorStatusDown}, nil)
+		mockEventBus.On("Publish", mock.Anything).Return()
+
+		assert.NoError(t, handler.processHeartbeat(ctx, newPayload(true)))
+		mockHeartbeatSvc.AssertExpectations(t)
+	})
+
+	t.Run("outage still going after the window is notified", func(t *testing.T) {
+		handler, mockHeartbeatSvc, mockEventBus := setupIngesterHandler()
+
+		mockHeartbeatSvc.On("FindByMonitorIDPaginated", ctx, "mon-1", 1, 0, (*bool)(nil), false).Return(previous, nil)
+		mockHeartbeatSvc.On("FindByMonitorIDPaginated", ctx, "mon-1", 1, 0, isImportantFilter, false).Return([]*heartbeat.Model{
+			{MonitorID: "mon-1", Status: shared.MonitorStatusDown, Important: true, Notified: false},
+		}, nil)
+		mockHeartbeatSvc.On("Create", ctx, mock.MatchedBy(func(dto *heartbeat.CreateUpdateDto) bool {
+			return dto.Important && dto.Notified
+		})).Return(&heartbeat.Model{ID: "hb-2", MonitorID: "mon-1", Status: shared.MonitorStatusDown}, nil)
+		mockEventBus.On("Publish", isEventType(events.ImportantHeartbeat)).Return()
+
+		assert.NoError(t, handler.processHeartbeat(ctx, newPayload(false)))
+		mockHeartbeatSvc.AssertExpectations(t)
+		mockEventBus.AssertExpectations(t)
+	})
+
+	t.Run("notified outage is not notified again", func(t *testing.T) {
+		handler, mockHeartbeatSvc, mockEventBus := setupIngesterHandler()
+
+		mockHeartbeatSvc.On("FindByMonitorIDPaginated", ctx, "mon-1", 1, 0, (*bool)(nil), false).Return(previous, nil)
+		mockHeartbeatSvc.On("FindByMonitorIDPaginated", ctx, "mon-1", 1, 0, isImportantFilter, false).Return([]*heartbeat.Model{
+			{MonitorID: "mon-1", Status: shared.MonitorStatusDown, Important: true, Notified: true},
+		}, nil)
+		mockHeartbeatSvc.On("Create", ctx, mock.MatchedBy(func(dto *heartbeat.CreateUpdateDto) bool {
+			return !dto.Important && !dto.Notified
+		})).Return(&heartbeat.Model{ID: "hb-2", MonitorID: "mon-1", Status: shared.MonitorStatusDown}, nil)
+
+		assert.NoError(t, handler.processHeartbeat(ctx, newPayload(false)))
+		mockHeartbeatSvc.AssertExpectations(t)
+		mockEventBus.AssertNotCalled(t, "Publish", mock.Anything)
+	})
+
+	t.Run("outage in the window is not notified yet", func(t *testing.T) {
+		handler, mockHeartbeatSvc, mockEventBus := setupIngesterHandler()
+
+		mockHeartbeatSvc.On("FindByMonitorIDPaginated", ctx, "mon-1", 1, 0, (*bool)(nil), false).Return(previous, nil)
+		mockHeartbeatSvc.On("Create", ctx, mock.MatchedBy(func(dto *heartbeat.CreateUpdateDto) bool {
+			return !dto.Important && !dto.Notified
+		})).Return(&heartbeat.Model{ID: "hb-2", MonitorID: "mon-1", Status: shared.MonitorStatusDown}, nil)
+
+		assert.NoError(t, handler.processHeartbeat(ctx, newPayload(true)))
+		mockHeartbeatSvc.AssertExpectations(t)
+		mockEventBus.AssertNotCalled(t, "Publish", mock.Anything)
+	})
+}
+
+func TestProcessHeartbeat_BodyChange(t *testing.T) {
+	ctx := context.Background()
+
+	newPayload := func(bodyHash string) *IngesterTaskPayload {
+		return &IngesterTaskPayload{
+			MonitorID:       "mon-1",
+			MonitorName:     "Landing page",
+			MonitorType:     "http",
+			MonitorInterval: 60,
+			Status:          shared.MonitorStatusUp,
+			Message:         "200 - 200 OK",
+			StartTime:       time.Now().UTC(),
+			EndTime:         time.Now().UTC(),
+			BodyHash:        bodyHash,
+		}
+	}
+	previous := func(bodyHash string) []*heartbeat.Model {
+		return []*heartbeat.Model{{
+			MonitorID: "mon-1",
+			Status:    shared.MonitorStatusUp,
+			Time:      time.Now().UTC().Add(-time.Minute),
+			BodyHash:  bodyHash,
+		}}
+	}
+
+	t.Run("changed body notifies", func(t *testing.T) {
+		handler, mockHeartbeatSvc, mockEventBus := setupIngesterHandler()
+
+		mockHeartbeatSvc.On("FindByMonitorIDPaginated", ctx, "mon-1", 1, 0, (*bool)(nil), false).Return(previous("hash-a"), nil)
+		mockHeartbeatSvc.On("Create", ctx, mock.MatchedBy(func(dto *heartbeat.CreateUpdateDto) bool {
+			return dto.Status == shared.MonitorStatusUp &&
+				dto.ErrorCategory == shared.ErrorCategoryBodyChanged &&
+				dto.BodyHash == "hash-b" &&
+				dto.Important && dto.Notified
+		})).Return(&heartbeat.Model{ID: "hb-1", MonitorID: "mon-1", Status: shared.MonitorStatusUp}, nil)
+		mockEventBus.On("Publish", isEventType(events.ImportantHeartbeat)).Return()
+
+		err := handler.processHeartbeat(ctx, newPayload("hash-b"))
+		assert.NoError(t, err)
+
+		mockHeartbeatSvc.AssertExpectations(t)
+		mockEventBus.AssertExpectations(t)
+	})
+
+	t.Run("stable body does not notify", func(t *testing.T) {
+		handler, mockHeartbeatSvc, mockEventBus := setupIngesterHandler()
+
+		mockHeartbeatSvc.On("FindByMonitorIDPaginated", ctx, "mon-1", 1, 0, (*bool)(nil), false).Return(previous("hash-a"), nil)
+		mockHeartbeatSvc.On("Create", ctx, mock.MatchedBy(func(dto *heartbeat.CreateUpdateDto) bool {
+			return dto.ErrorCategory == "" && dto.BodyHash == "hash-a" && !dto.Important && !dto.Notified
+		})).Return(&heartbeat.Model{ID: "hb-1", MonitorID: "mon-1", Status: shared.MonitorStatusUp}, nil)
+
+		err := handler.processHeartbeat(ctx, newPayload("hash-a"))
+		assert.NoError(t, err)
+
+		mockHeartbeatSvc.AssertExpectations(t)
+		mockEventBus.AssertNotCalled(t, "Publish", mock.Anything)
+	})
+
+	t.Run("beats without a hash keep the previous one", func(t *testing.T) {
+		handler, mockHeartbeatSvc, mockEventBus := setupIngesterHandler()
+		payload := newPayload("")
+		payload.Status = shared.MonitorStatusDown
+
+		mockHeartbeatSvc.On("FindByMonitorIDPaginated", ctx, "mon-1", 1, 0, (*bool)(nil), false).Return(previous("hash-a"), nil)
+		mockHeartbeatSvc.On("Create", ctx, mock.MatchedBy(func(dto *heartbeat.CreateUpdateDto) bool {
+			return dto.BodyHash == "hash-a" && dto.ErrorCategory == ""
+		})).Return(&heartbeat.Model{ID: "hb-1", MonitorID: "mon-1", Status: shared.MonitorStatusDown}, nil)
+		mockEventBus.On("Publish", mock.Anything).Return()
+
+		err := handler.processHeartbeat(ctx, payload)
+		assert.NoError(t, err)
+
+		mockHeartbeatSvc.AssertExpectations(t)
+	})
+}
+
+func TestCapBeatDuration(t *testing.T) {
+	tests := []struct {
+		name     string
+		seconds  int
+		interval int
+		timeout  int
+		expected int
+	}{
+		{"within the interval", 60, 60, 30, 60},
+		{"gap capped at twice the interval", 3600, 60, 30, 120},
+		{"timeout when the interval is unknown", 3600, 0, 30, 60},
+		{"no cap without interval or timeout", 3600, 0, 0, 3600},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := &IngesterTaskPayload{MonitorInterval: tt.interval, MonitorTimeout: tt.timeout}
+			assert.Equal(t, tt.expected, capBeatDuration(tt.seconds, payload))
+		})
+	}
+}
+
+func TestProcessHeartbeat_SkippedCheck(t *testing.T) {
+	ctx := context.Background()
+
+	newPayload := func() *IngesterTaskPayload {
+		return &IngesterTaskPayload{
+			MonitorID:         "mon-1",
+			MonitorName:       "Database",
+			MonitorType:       "postgres",
+			MonitorInterval:   60,
+			MonitorMaxRetries: 3,
+			MonitorResendInt:  1,
+			Status:            shared.MonitorStatusPending,
+			Message:           "Check skipped, previous still running",
+			StartTime:         time.Now().UTC(),
+			EndTime:           time.Now().UTC(),
+			ErrorCategory:     shared.ErrorCategorySkipped,
+		}
+	}
+
+	t.Run("keeps the previous status without notifying", func(t *testing.T) {
+		handler, mockHeartbeatSvc, mockEventBus := setupIngesterHandler()
+
+		mockHeartbeatSvc.On("FindByMonitorIDPaginated", ctx, "mon-1", 1, 0, (*bool)(nil), false).Return([]*heartbeat.Model{{
+			MonitorID: "mon-1",
+			Status:    shared.MonitorStatusDown,
+			Retries:   4,
+			DownCount: 2,
+			BodyHash:  "hash-a",
+			Time:      time.Now().UTC().Add(-time.Minute),
+		}}, nil)
+		mockHeartbeatSvc.On("Create", ctx, mock.MatchedBy(func(dto *heartbeat.CreateUpdateDto) bool {
+			return dto.Status == shared.MonitorStatusDown &&
+				dto.ErrorCategory == shared.ErrorCategorySkipped &&
+				dto.Retries == 4 && dto.DownCount == 2 && dto.BodyHash == "hash-a" &&
+				!dto.Important && !dto.Notified
+		})).Return(&heartbeat.Model{ID: "hb-1", MonitorID: "mon-1", Status: shared.MonitorStatusDown}, nil)
+
+		err := handler.processHeartbeat(ctx, newPayload())
+		assert.NoError(t, err)
+
+		mockHeartbeatSvc.AssertExpectations(t)
+		mockEventBus.AssertNotCalled(t, "Publish", mock.Anything)
+	})
+
+	t.Run("first beat is pending", func(t *testing.T) {
+		handler, mockHeartbeatSvc, mockEventBus := setupIngesterHandler()
+
+		mockHeartbeatSvc.On("FindByMonitorIDPaginated", ctx, "mon-1", 1, 0, (*bool)(nil), false).Return([]*heartbeat.Model{}, nil)
+		mockHeartbeatSvc.On("Create", ctx, mock.MatchedBy(func(dto *heartbeat.CreateUpdateDto) bool {
+			return dto.Status == shared.MonitorStatusPending && !dto.Important && !dto.Notified
+		})).Return(&heartbeat.Model{ID: "hb-1", MonitorID: "mon-1", Status: shared.MonitorStatusPending}, nil)
+
+		err := handler.processHeartbeat(ctx, newPayload())
+		assert.NoError(t, err)
+
+		mockHeartbeatSvc.AssertExpectations(t)
+		mockEventBus.AssertNotCalled(t, "Publish", mock.Anything)
+	})
+}
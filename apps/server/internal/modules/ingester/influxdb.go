@@ -0,0 +1,270 @@
+package ingester
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"peekaping/internal/config"
+	"peekaping/internal/modules/heartbeat"
+	"peekaping/internal/modules/monitor_tag"
+	"peekaping/internal/modules/tag"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// influxQueueSize bounds the points waiting to be written, newer ones are dropped when
+	// InfluxDB can't keep up
+	influxQueueSize = 5000
+	// influxBatchSize is the most points sent in one write request
+	influxBatchSize = 500
+	// influxFlushInterval is how often a partial batch is written
+	influxFlushInterval = time.Second
+	// influxTagsTTL is how long the tag names of a monitor are reused before being fetched again
+	influxTagsTTL = time.Minute
+)
+
+// influxPoint is a heartbeat waiting to be written
+type influxPoint struct {
+	hb      *heartbeat.Model
+	payload *IngesterTaskPayload
+}
+
+// influxMonitorTags are the tag names of a monitor as last fetched
+type influxMonitorTags struct {
+	names     []string
+	fetchedAt time.Time
+}
+
+// InfluxWriter mirrors heartbeats written by the ingester to InfluxDB as line protocol
+// points. The primary database stays the source of truth: points are batched and written
+// in the background, failed writes are dropped and nothing ever blocks ingestion.
+type InfluxWriter struct {
+	writeURL    string
+	token       string
+	measurement string
+	client      *http.Client
+	queue       chan influxPoint
+	ctx         context.Context
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+	logger      *zap.SugaredLogger
+
+	// Tag names are only fetched and cached by the writing goroutine
+	monitorTagService monitor_tag.Service
+	tagService        tag.Service
+	monitorTags       map[string]influxMonitorTags
+}
+
+// NewInfluxWriter creates the InfluxDB writer from INFLUXDB_* settings and starts writing.
+// It returns nil when no URL is configured.
+func NewInfluxWriter(
+	cfg *config.Config,
+	monitorTagService monitor_tag.Service,
+	tagService tag.Service,
+	logger *zap.SugaredLogger,
+) (*InfluxWriter, error) {
+	if cfg.InfluxDBURL == "" {
+		return nil, nil
+	}
+	if cfg.InfluxDBBucket == "" {
+		return nil, fmt.Errorf("INFLUXDB_BUCKET is required when INFLUXDB_URL is set")
+	}
+
+	writeURL, err := url.JoinPath(cfg.InfluxDBURL, "/api/v2/write")
+	if err != nil {
+		return nil, fmt.Errorf("invalid INFLUXDB_URL: %w", err)
+	}
+	query := url.Values{}
+	query.Set("org", cfg.InfluxDBOrg)
+	query.Set("bucket", cfg.InfluxDBBucket)
+	query.Set("precision", "ns")
+
+	measurement := cfg.InfluxDBMeasurement
+	if measurement == "" {
+		measurement = "heartbeat"
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &InfluxWriter{
+		writeURL:    writeURL + "?" + query.Encode(),
+		token:       cfg.InfluxDBToken,
+		measurement: measurement,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		queue:       make(chan influxPoint, influxQueueSize),
+		ctx:         ctx,
+		cancel:      cancel,
+		logger:      logger.With("component", "influx_writer"),
+
+		monitorTagService: monitorTagService,
+		tagService:        tagService,
+		monitorTags:       make(map[string]influxMonitorTags),
+	}
+
+	w.wg.Add(1)
+	go w.run()
+
+	return w, nil
+}
+
+// Write queues a heartbeat point without waiting for it
+func (w *InfluxWriter) Write(hb *heartbeat.Model, payload *IngesterTaskPayload) {
+	select {
+	case w.queue <- influxPoint{hb: hb, payload: payload}:
+	default:
+		w.logger.Warnw("InfluxDB queue is full, dropping heartbeat", "monitor_id", hb.MonitorID)
+	}
+}
+
+// Close writes the points still queued and stops the writer
+func (w *InfluxWriter) Close() {
+	w.cancel()
+	w.wg.Wait()
+}
+
+func (w *InfluxWriter) run() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(influxFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]string, 0, influxBatchSize)
+	flush := func(ctx context.Context) {
+		if len(batch) == 0 {
+			return
+		}
+		if err := w.send(ctx, batch); err != nil {
+			w.logger.Warnw("Failed to write heartbeats to InfluxDB", "points", len(batch), "error", err)
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			// Write what is left with a short deadline of its own
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			for {
+				select {
+				case point := <-w.queue:
+					batch = append(batch, w.format(ctx, point))
+					if len(batch) == influxBatchSize {
+						flush(ctx)
+					}
+				default:
+					flush(ctx)
+					return
+				}
+			}
+		case point := <-w.queue:
+			batch = append(batch, w.format(w.ctx, point))
+			if len(batch) == influxBatchSize {
+				flush(w.ctx)
+			}
+		case <-ticker.C:
+			flush(w.ctx)
+		}
+	}
+}
+
+func (w *InfluxWriter) send(ctx context.Context, lines []string) error {
+	body := strings.Join(lines, "\n")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.writeURL, bytes.NewBufferString(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if w.token != "" {
+		req.Header.Set("Authorization", "Token "+w.token)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (w *InfluxWriter) format(ctx context.Context, point influxPoint) string {
+	return formatInfluxPoint(w.measurement, point.hb, point.payload, w.tagNames(ctx, point.hb.MonitorID))
+}
+
+// tagNames returns the sorted tag names of the monitor, fetched at most once per
+// influxTagsTTL. The last names fetched are kept when fetching fails.
+func (w *InfluxWriter) tagNames(ctx context.Context, monitorID string) []string {
+	cached, ok := w.monitorTags[monitorID]
+	if (ok && time.Since(cached.fetchedAt) < influxTagsTTL) || w.monitorTagService == nil || w.tagService == nil {
+		return cached.names
+	}
+
+	rels, err := w.monitorTagService.FindByMonitorID(ctx, monitorID)
+	if err != nil {
+		w.logger.Warnw("Failed to get monitor tags", "monitor_id", monitorID, "error", err)
+		return cached.names
+	}
+	var names []string
+	for _, rel := range rels {
+		t, err := w.tagService.FindByID(ctx, rel.TagID)
+		if err != nil || t == nil {
+			w.logger.Warnw("Failed to get tag", "monitor_id", monitorID, "tag_id", rel.TagID, "error", err)
+			continue
+		}
+		names = append(names, t.Name)
+	}
+	sort.Strings(names)
+
+	w.monitorTags[monitorID] = influxMonitorTags{names: names, fetchedAt: time.Now()}
+	return names
+}
+
+// formatInfluxPoint renders a heartbeat as an InfluxDB line protocol point, tagged with the
+// monitor and the comma separated names of its tags, and timestamped with the heartbeat time
+func formatInfluxPoint(measurement string, hb *heartbeat.Model, payload *IngesterTaskPayload, tagNames []string) string {
+	var b strings.Builder
+
+	b.WriteString(influxMeasurementEscaper.Replace(measurement))
+	for _, tag := range [][2]string{
+		{"monitor_id", hb.MonitorID},
+		{"monitor_name", payload.MonitorName},
+		{"monitor_type", payload.MonitorType},
+		{"tags", strings.Join(tagNames, ",")},
+	} {
+		// InfluxDB rejects empty tag values
+		if tag[1] == "" {
+			continue
+		}
+		b.WriteString("," + tag[0] + "=" + influxTagEscaper.Replace(tag[1]))
+	}
+
+	b.WriteString(" status=" + strconv.Itoa(int(hb.Status)) + "i")
+	b.WriteString(",ping=" + strconv.Itoa(hb.Ping) + "i")
+	b.WriteString(",important=" + strconv.FormatBool(hb.Important))
+	b.WriteString(`,message="` + influxStringEscaper.Replace(hb.Msg) + `"`)
+
+	b.WriteString(" " + strconv.FormatInt(hb.Time.UnixNano(), 10))
+
+	return b.String()
+}
+
+// Escaping follows the line protocol: commas and spaces in measurements, commas, equal
+// signs and spaces in tag values, double quotes and backslashes in string fields. Line
+// breaks can't be escaped in measurements and tags and become spaces, string fields keep
+// them as they are.
+var (
+	influxMeasurementEscaper = strings.NewReplacer(",", `\,`, " ", `\ `, "\r\n", `\ `, "\n", `\ `, "\r", `\ `)
+	influxTagEscaper         = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `, "\r\n", `\ `, "\n", `\ `, "\r", `\ `)
+	influxStringEscaper      = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+)
@@ -0,0 +1,201 @@
+package ingester
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"peekaping/internal/config"
+	"peekaping/internal/modules/heartbeat"
+	"peekaping/internal/modules/monitor_tag"
+	"peekaping/internal/modules/shared"
+	"peekaping/internal/modules/tag"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeMonitorTagService only implements the tag lookups of the writer
+type fakeMonitorTagService struct {
+	monitor_tag.Service
+	tagIDs []string
+}
+
+func (f *fakeMonitorTagService) FindByMonitorID(ctx context.Context, monitorID string) ([]*monitor_tag.Model, error) {
+	rels := make([]*monitor_tag.Model, 0, len(f.tagIDs))
+	for _, id := range f.tagIDs {
+		rels = append(rels, &monitor_tag.Model{MonitorID: monitorID, TagID: id})
+	}
+	return rels, nil
+}
+
+type fakeTagService struct {
+	tag.Service
+}
+
+func (f *fakeTagService) FindByID(ctx context.Context, id string) (*tag.Model, error) {
+	return &tag.Model{ID: id, Name: map[string]string{"tag-1": "prod", "tag-2": "api"}[id]}, nil
+}
+
+func TestNewInfluxWriter_Disabled(t *testing.T) {
+	w, err := NewInfluxWriter(&config.Config{}, nil, nil, zap.NewNop().Sugar())
+
+	assert.NoError(t, err)
+	assert.Nil(t, w)
+}
+
+func TestNewInfluxWriter_RequiresBucket(t *testing.T) {
+	_, err := NewInfluxWriter(&config.Config{InfluxDBURL: "http://localhost:8086"}, nil, nil, zap.NewNop().Sugar())
+
+	assert.Error(t, err)
+}
+
+func TestFormatInfluxPoint(t *testing.T) {
+	at := time.Unix(1700000000, 123)
+
+	tests := []struct {
+		name     string
+		hb       *heartbeat.Model
+		payload  *IngesterTaskPayload
+		tags     []string
+		expected string
+	}{
+		{
+			name:     "up heartbeat",
+			hb:       &heartbeat.Model{MonitorID: "mon-1", Status: shared.MonitorStatusUp, Ping: 42, Msg: "200 - OK", Time: at},
+			payload:  &IngesterTaskPayload{MonitorName: "API", MonitorType: "http"},
+			expected: `heartbeat,monitor_id=mon-1,monitor_name=API,monitor_type=http status=1i,ping=42i,important=false,message="200 - OK" 1700000000000000123`,
+		},
+		{
+			name:     "special characters are escaped",
+			hb:       &heartbeat.Model{MonitorID: "mon-2", Status: shared.MonitorStatusDown, Important: true, Msg: `said "no" at C:\`, Time: at},
+			payload:  &IngesterTaskPayload{MonitorName: "My API, prod=eu", MonitorType: "http"},
+			expected: `heartbeat,monitor_id=mon-2,monitor_name=My\ API\,\ prod\=eu,monitor_type=http status=0i,ping=0i,important=true,message="said \"no\" at C:\\" 1700000000000000123`,
+		},
+		{
+			name:     "line breaks",
+			hb:       &heartbeat.Model{MonitorID: "mon-2", Status: shared.MonitorStatusDown, Msg: "connection refused\nretrying", Time: at},
+			payload:  &IngesterTaskPayload{MonitorName: "API\nprod", MonitorType: "http"},
+			expected: "heartbeat,monitor_id=mon-2,monitor_name=API\\ prod,monitor_type=http status=0i,ping=0i,important=false,message=\"connection refused\nretrying\" 1700000000000000123",
+		},
+		{
+			name:     "monitor tags",
+			hb:       &heartbeat.Model{MonitorID: "mon-1", Status: shared.MonitorStatusUp, Ping: 42, Time: at},
+			payload:  &IngesterTaskPayload{MonitorName: "API", MonitorType: "http"},
+			tags:     []string{"eu west", "prod"},
+			expected: `heartbeat,monitor_id=mon-1,monitor_name=API,monitor_type=http,tags=eu\ west\,prod status=1i,ping=42i,important=false,message="" 1700000000000000123`,
+		},
+		{
+			name:     "empty tags are left out",
+			hb:       &heartbeat.Model{MonitorID: "mon-3", Status: shared.MonitorStatusPending, Time: at},
+			payload:  &IngesterTaskPayload{},
+			expected: `heartbeat,monitor_id=mon-3 status=2i,ping=0i,important=false,message="" 1700000000000000123`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, formatInfluxPoint("heartbeat", tt.hb, tt.payload, tt.tags))
+		})
+	}
+}
+
+func TestInfluxWriter_WritesPoints(t *testing.T) {
+	type request struct {
+		path, query, auth, body string
+	}
+	received := make(chan request, 10)
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- request{r.URL.Path, r.URL.RawQuery, r.Header.Get("Authorization"), string(body)}
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	w, err := NewInfluxWriter(&config.Config{
+		InfluxDBURL:    server.URL,
+		InfluxDBToken:  "secret",
+		InfluxDBOrg:    "peekaping",
+		InfluxDBBucket: "heartbeats",
+	}, &fakeMonitorTagService{tagIDs: []string{"tag-2", "tag-1"}}, &fakeTagService{}, zap.NewNop().Sugar())
+	require.NoError(t, err)
+	defer w.Close()
+
+	payload := &IngesterTaskPayload{MonitorName: "API", MonitorType: "http"}
+	w.Write(&heartbeat.Model{MonitorID: "mon-1", Status: shared.MonitorStatusUp, Time: time.Unix(1, 0)}, payload)
+	w.Write(&heartbeat.Model{MonitorID: "mon-1", Status: shared.MonitorStatusDown, Time: time.Unix(2, 0)}, payload)
+
+	var lines []string
+	deadline := time.After(5 * time.Second)
+	for len(lines) < 2 {
+		select {
+		case req := <-received:
+			assert.Equal(t, "/api/v2/write", req.path)
+			assert.Contains(t, req.query, "bucket=heartbeats")
+			assert.Contains(t, req.query, "org=peekaping")
+			assert.Equal(t, "Token secret", req.auth)
+			lines = append(lines, strings.Split(req.body, "\n")...)
+		case <-deadline:
+			t.Fatalf("points were not written, got %v", lines)
+		}
+	}
+
+	assert.Contains(t, lines[0], `,tags=api\,prod status=1i`)
+	assert.Contains(t, lines[1], `,tags=api\,prod status=0i`)
+}
+
+func TestInfluxWriter_FailuresDoNotBlockIngestion(t *testing.T) {
+	ctx := context.Background()
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		// Writes hang until the test has finished ingesting, then fail
+		<-release
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	w, err := NewInfluxWriter(&config.Config{InfluxDBURL: server.URL, InfluxDBBucket: "heartbeats"}, nil, nil, zap.NewNop().Sugar())
+	require.NoError(t, err)
+
+	handler, mockHeartbeatSvc, _ := setupIngesterHandler()
+	handler.influx = w
+
+	previous := []*heartbeat.Model{{MonitorID: "mon-1", Status: shared.MonitorStatusUp, Time: time.Now().UTC().Add(-time.Minute)}}
+	mockHeartbeatSvc.On("FindByMonitorIDPaginated", ctx, "mon-1", 1, 0, (*bool)(nil), false).Return(previous, nil)
+	mockHeartbeatSvc.On("Create", ctx, mock.Anything).Return(&heartbeat.Model{ID: "hb-1", MonitorID: "mon-1", Status: shared.MonitorStatusUp}, nil)
+
+	done := make(chan error)
+	go func() {
+		var err error
+		for i := 0; i < influxQueueSize+10 && err == nil; i++ {
+			err = handler.processHeartbeat(ctx, &IngesterTaskPayload{
+				MonitorID: "mon-1",
+				Status:    shared.MonitorStatusUp,
+				StartTime: time.Now().UTC(),
+				EndTime:   time.Now().UTC(),
+			})
+		}
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("ingestion was blocked by InfluxDB")
+	}
+
+	// Every heartbeat still reached the primary database
+	mockHeartbeatSvc.AssertNumberOfCalls(t, "Create", influxQueueSize+10)
+
+	close(release)
+	w.Close()
+}
@@ -13,6 +13,12 @@ import (
 
 // RegisterDependencies registers ingester dependencies in the DI container
 func RegisterDependencies(container *dig.Container) {
+	// Provide optional heartbeat webhook
+	container.Provide(NewHeartbeatWebhook)
+
+	// Provide optional InfluxDB heartbeat mirror
+	container.Provide(NewInfluxWriter)
+
 	// Provide ingester task handler
 	container.Provide(ProvideIngesterTaskHandler)
 
@@ -26,6 +32,8 @@ func ProvideIngesterTaskHandler(
 	certificateService certificate.Service,
 	monitorMaintenanceService monitor_maintenance.Service,
 	eventBus events.EventBus,
+	webhook *HeartbeatWebhook,
+	influx *InfluxWriter,
 	logger *zap.SugaredLogger,
 ) *IngesterTaskHandler {
 	return NewIngesterTaskHandler(
@@ -33,6 +41,8 @@ func ProvideIngesterTaskHandler(
 		certificateService,
 		monitorMaintenanceService,
 		eventBus,
+		webhook,
+		influx,
 		logger,
 	)
 }
@@ -51,5 +51,11 @@ func (i *Ingester) Start(ctx context.Context) error {
 func (i *Ingester) Stop() {
 	i.logger.Info("Stopping ingester")
 	i.server.Shutdown()
+	if i.handler.webhook != nil {
+		i.handler.webhook.Close()
+	}
+	if i.handler.influx != nil {
+		i.handler.influx.Close()
+	}
 	i.logger.Info("Ingester stopped")
 }
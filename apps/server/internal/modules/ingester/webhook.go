@@ -0,0 +1,196 @@
+package ingester
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"peekaping/internal/config"
+	"peekaping/internal/modules/heartbeat"
+	"peekaping/internal/modules/shared"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// heartbeatWebhookQueueSize bounds the heartbeats waiting to be posted, newer ones are
+	// dropped when the endpoint can't keep up
+	heartbeatWebhookQueueSize = 1000
+	// heartbeatWebhookMaxRetries is how many times a failed post is retried
+	heartbeatWebhookMaxRetries = 3
+)
+
+// HeartbeatWebhookBody is the JSON posted to the heartbeat webhook for every heartbeat
+type HeartbeatWebhookBody struct {
+	MonitorName string `json:"monitor_name"`
+	MonitorType string `json:"monitor_type"`
+	*heartbeat.Model
+}
+
+// HeartbeatWebhook posts every heartbeat written by the ingester to an external URL.
+// Delivery is best effort: posts happen in the background, failed ones are retried a
+// bounded number of times and nothing ever blocks ingestion.
+type HeartbeatWebhook struct {
+	url        string
+	monitors   map[string]bool
+	statuses   map[shared.MonitorStatus]bool
+	client     *http.Client
+	retryDelay time.Duration
+	queue      chan []byte
+	ctx        context.Context
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+	logger     *zap.SugaredLogger
+}
+
+// NewHeartbeatWebhook creates the heartbeat webhook from HEARTBEAT_WEBHOOK_* settings and
+// starts delivering. It returns nil when no URL is configured.
+func NewHeartbeatWebhook(cfg *config.Config, logger *zap.SugaredLogger) (*HeartbeatWebhook, error) {
+	if cfg.HeartbeatWebhookURL == "" {
+		return nil, nil
+	}
+
+	statuses := make(map[shared.MonitorStatus]bool)
+	for _, s := range splitList(cfg.HeartbeatWebhookStatuses) {
+		status, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid HEARTBEAT_WEBHOOK_STATUSES value %q: %w", s, err)
+		}
+		statuses[shared.MonitorStatus(status)] = true
+	}
+
+	monitors := make(map[string]bool)
+	for _, id := range splitList(cfg.HeartbeatWebhookMonitors) {
+		monitors[id] = true
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &HeartbeatWebhook{
+		url:        cfg.HeartbeatWebhookURL,
+		monitors:   monitors,
+		statuses:   statuses,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		retryDelay: time.Second,
+		queue:      make(chan []byte, heartbeatWebhookQueueSize),
+		ctx:        ctx,
+		cancel:     cancel,
+		logger:     logger.With("component", "heartbeat_webhook"),
+	}
+
+	w.wg.Add(1)
+	go w.run()
+
+	return w, nil
+}
+
+// splitList splits a comma-separated setting, ignoring blanks
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// matches reports whether a heartbeat passes the monitor and status filters
+func (w *HeartbeatWebhook) matches(hb *heartbeat.Model) bool {
+	if len(w.monitors) > 0 && !w.monitors[hb.MonitorID] {
+		return false
+	}
+	if len(w.statuses) > 0 && !w.statuses[hb.Status] {
+		return false
+	}
+	return true
+}
+
+// Post queues a heartbeat for delivery without waiting for it
+func (w *HeartbeatWebhook) Post(hb *heartbeat.Model, payload *IngesterTaskPayload) {
+	if !w.matches(hb) {
+		return
+	}
+
+	body, err := json.Marshal(&HeartbeatWebhookBody{
+		MonitorName: payload.MonitorName,
+		MonitorType: payload.MonitorType,
+		Model:       hb,
+	})
+	if err != nil {
+		w.logger.Errorw("Failed to marshal heartbeat webhook body", "monitor_id", hb.MonitorID, "error", err)
+		return
+	}
+
+	select {
+	case w.queue <- body:
+	default:
+		w.logger.Warnw("Heartbeat webhook queue is full, dropping heartbeat", "monitor_id", hb.MonitorID)
+	}
+}
+
+// Close stops delivering, heartbeats still queued are dropped
+func (w *HeartbeatWebhook) Close() {
+	w.cancel()
+	w.wg.Wait()
+}
+
+func (w *HeartbeatWebhook) run() {
+	defer w.wg.Done()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case body := <-w.queue:
+			w.deliver(body)
+		}
+	}
+}
+
+// deliver posts one heartbeat, retrying with exponential backoff
+func (w *HeartbeatWebhook) deliver(body []byte) {
+	delay := w.retryDelay
+
+	for attempt := 0; attempt <= heartbeatWebhookMaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-w.ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+
+		err := w.send(body)
+		if err == nil {
+			return
+		}
+		w.logger.Warnw("Failed to post heartbeat webhook", "attempt", attempt+1, "error", err)
+	}
+
+	w.logger.Errorw("Giving up on heartbeat webhook after retries", "retries", heartbeatWebhookMaxRetries)
+}
+
+func (w *HeartbeatWebhook) send(body []byte) error {
+	req, err := http.NewRequestWithContext(w.ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
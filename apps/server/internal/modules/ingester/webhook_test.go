@@ -0,0 +1,183 @@
+package ingester
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"peekaping/internal/config"
+	"peekaping/internal/modules/heartbeat"
+	"peekaping/internal/modules/shared"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestWebhook(t *testing.T, cfg *config.Config) *HeartbeatWebhook {
+	t.Helper()
+
+	w, err := NewHeartbeatWebhook(cfg, zap.NewNop().Sugar())
+	require.NoError(t, err)
+	require.NotNil(t, w)
+	w.retryDelay = time.Millisecond
+	t.Cleanup(w.Close)
+
+	return w
+}
+
+func TestNewHeartbeatWebhook_Disabled(t *testing.T) {
+	w, err := NewHeartbeatWebhook(&config.Config{}, zap.NewNop().Sugar())
+
+	assert.NoError(t, err)
+	assert.Nil(t, w)
+}
+
+func TestNewHeartbeatWebhook_InvalidStatuses(t *testing.T) {
+	_, err := NewHeartbeatWebhook(&config.Config{
+		HeartbeatWebhookURL:      "http://example.com/hook",
+		HeartbeatWebhookStatuses: "0,down",
+	}, zap.NewNop().Sugar())
+
+	assert.Error(t, err)
+}
+
+func TestProcessHeartbeat_PostsToWebhook(t *testing.T) {
+	ctx := context.Background()
+	received := make(chan HeartbeatWebhookBody, 10)
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var body HeartbeatWebhookBody
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		received <- body
+	}))
+	defer server.Close()
+
+	handler, mockHeartbeatSvc, mockEventBus := setupIngesterHandler()
+	handler.webhook = newTestWebhook(t, &config.Config{HeartbeatWebhookURL: server.URL})
+
+	previous := []*heartbeat.Model{{MonitorID: "mon-1", Status: shared.MonitorStatusUp, Time: time.Now().UTC().Add(-time.Minute)}}
+	mockHeartbeatSvc.On("FindByMonitorIDPaginated", ctx, "mon-1", 1, 0, (*bool)(nil), false).Return(previous, nil)
+	mockHeartbeatSvc.On("Create", ctx, mock.Anything).Return(&heartbeat.Model{
+		ID:        "hb-1",
+		MonitorID: "mon-1",
+		Status:    shared.MonitorStatusUp,
+		Msg:       "200 - OK",
+		Ping:      42,
+	}, nil)
+
+	err := handler.processHeartbeat(ctx, &IngesterTaskPayload{
+		MonitorID:   "mon-1",
+		MonitorName: "API",
+		MonitorType: "http",
+		Status:      shared.MonitorStatusUp,
+		Message:     "200 - OK",
+		PingMs:      42,
+		StartTime:   time.Now().UTC(),
+		EndTime:     time.Now().UTC(),
+	})
+	require.NoError(t, err)
+
+	select {
+	case body := <-received:
+		assert.Equal(t, "API", body.MonitorName)
+		assert.Equal(t, "http", body.MonitorType)
+		assert.Equal(t, "hb-1", body.ID)
+		assert.Equal(t, "mon-1", body.MonitorID)
+		assert.Equal(t, 42, body.Ping)
+	case <-time.After(2 * time.Second):
+		t.Fatal("heartbeat was not posted to the webhook")
+	}
+
+	mockHeartbeatSvc.AssertExpectations(t)
+	mockEventBus.AssertExpectations(t)
+}
+
+func TestHeartbeatWebhook_Filters(t *testing.T) {
+	received := make(chan string, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var body HeartbeatWebhookBody
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		received <- body.ID
+	}))
+	defer server.Close()
+
+	w := newTestWebhook(t, &config.Config{
+		HeartbeatWebhookURL:      server.URL,
+		HeartbeatWebhookMonitors: "mon-1, mon-2",
+		HeartbeatWebhookStatuses: "0",
+	})
+
+	payload := &IngesterTaskPayload{MonitorName: "API"}
+	w.Post(&heartbeat.Model{ID: "other-monitor", MonitorID: "mon-3", Status: shared.MonitorStatusDown}, payload)
+	w.Post(&heartbeat.Model{ID: "up-beat", MonitorID: "mon-1", Status: shared.MonitorStatusUp}, payload)
+	w.Post(&heartbeat.Model{ID: "down-beat", MonitorID: "mon-2", Status: shared.MonitorStatusDown}, payload)
+
+	select {
+	case id := <-received:
+		assert.Equal(t, "down-beat", id)
+	case <-time.After(2 * time.Second):
+		t.Fatal("matching heartbeat was not posted")
+	}
+
+	select {
+	case id := <-received:
+		t.Fatalf("unexpected heartbeat posted: %s", id)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestHeartbeatWebhook_FailuresDoNotBlockIngestion(t *testing.T) {
+	ctx := context.Background()
+	var attempts atomic.Int32
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		// The first attempt hangs until the test has finished ingesting
+		if attempts.Add(1) == 1 {
+			<-release
+		}
+		rw.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	handler, mockHeartbeatSvc, _ := setupIngesterHandler()
+	handler.webhook = newTestWebhook(t, &config.Config{HeartbeatWebhookURL: server.URL})
+
+	previous := []*heartbeat.Model{{MonitorID: "mon-1", Status: shared.MonitorStatusUp, Time: time.Now().UTC().Add(-time.Minute)}}
+	mockHeartbeatSvc.On("FindByMonitorIDPaginated", ctx, "mon-1", 1, 0, (*bool)(nil), false).Return(previous, nil)
+	mockHeartbeatSvc.On("Create", ctx, mock.Anything).Return(&heartbeat.Model{ID: "hb-1", MonitorID: "mon-1", Status: shared.MonitorStatusUp}, nil)
+
+	done := make(chan error)
+	go func() {
+		var err error
+		for i := 0; i < 3 && err == nil; i++ {
+			err = handler.processHeartbeat(ctx, &IngesterTaskPayload{
+				MonitorID: "mon-1",
+				Status:    shared.MonitorStatusUp,
+				StartTime: time.Now().UTC(),
+				EndTime:   time.Now().UTC(),
+			})
+		}
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("ingestion was blocked by the webhook")
+	}
+	close(release)
+
+	// Every heartbeat gets the first attempt plus the bounded retries
+	expected := int32(3 * (heartbeatWebhookMaxRetries + 1))
+	assert.Eventually(t, func() bool { return attempts.Load() == expected }, 2*time.Second, 5*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, expected, attempts.Load())
+}
@@ -1,8 +1,10 @@
 package maintenance
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"peekaping/internal/modules/audit_log"
 	"peekaping/internal/utils"
 
 	"github.com/gin-gonic/gin"
@@ -11,17 +13,36 @@ import (
 )
 
 type Controller struct {
-	service Service
-	logger  *zap.SugaredLogger
+	service         Service
+	logger          *zap.SugaredLogger
+	auditLogService audit_log.Service
 }
 
 func NewController(
 	service Service,
 	logger *zap.SugaredLogger,
+	auditLogService audit_log.Service,
 ) *Controller {
 	return &Controller{
 		service,
 		logger,
+		auditLogService,
+	}
+}
+
+// recordAudit writes an audit log entry for a maintenance change. Failures are
+// only logged so that auditing never fails the request itself.
+func (ic *Controller) recordAudit(ctx *gin.Context, id, action string, before, after *Model) {
+	var beforeValue, afterValue any
+	if before != nil {
+		beforeValue = before
+	}
+	if after != nil {
+		afterValue = after
+	}
+
+	if _, err := ic.auditLogService.Record(ctx, audit_log.EntityMaintenance, id, action, beforeValue, afterValue); err != nil {
+		ic.logger.Errorw("Failed to record maintenance audit log", "id", id, "error", err)
 	}
 }
 
@@ -96,13 +117,20 @@ func (ic *Controller) Create(ctx *gin.Context) {
 		return
 	}
 
+	_, entity.RequestedBy = audit_log.ActorFromContext(ctx)
 	created, err := ic.service.Create(ctx, entity)
+	if errors.Is(err, ErrInvalidDateTimeWindow) || errors.Is(err, ErrAutoEndRequiresOneShot) {
+		ctx.JSON(http.StatusBadRequest, utils.NewFailResponse(err.Error()))
+		return
+	}
 	if err != nil {
 		ic.logger.Errorw("Failed to create maintenance", "error", err)
 		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
 		return
 	}
 
+	ic.recordAudit(ctx, created.ID, audit_log.ActionCreate, nil, created)
+
 	ctx.JSON(http.StatusCreated, utils.NewSuccessResponse("Maintenance created successfully", created))
 }
 
@@ -140,24 +168,31 @@ func (ic *Controller) FindByID(ctx *gin.Context) {
 	}
 
 	response := &MaintenanceResponseDto{
-		ID:            entity.ID,
-		Title:         entity.Title,
-		Description:   entity.Description,
-		Active:        entity.Active,
-		Strategy:      entity.Strategy,
-		StartDateTime: entity.StartDateTime,
-		EndDateTime:   entity.EndDateTime,
-		StartTime:     entity.StartTime,
-		EndTime:       entity.EndTime,
-		Weekdays:      entity.Weekdays,
-		DaysOfMonth:   entity.DaysOfMonth,
-		IntervalDay:   entity.IntervalDay,
-		Cron:          entity.Cron,
-		Timezone:      entity.Timezone,
-		Duration:      entity.Duration,
-		CreatedAt:     entity.CreatedAt,
-		UpdatedAt:     entity.UpdatedAt,
-		MonitorIds:    monitorIds,
+		ID:                entity.ID,
+		Title:             entity.Title,
+		Description:       entity.Description,
+		Active:            entity.Active,
+		Strategy:          entity.Strategy,
+		StartDateTime:     entity.StartDateTime,
+		EndDateTime:       entity.EndDateTime,
+		StartTime:         entity.StartTime,
+		EndTime:           entity.EndTime,
+		Weekdays:          entity.Weekdays,
+		DaysOfMonth:       entity.DaysOfMonth,
+		IntervalDay:       entity.IntervalDay,
+		Cron:              entity.Cron,
+		Timezone:          entity.Timezone,
+		Duration:          entity.Duration,
+		SuppressChecks:    entity.SuppressChecks,
+		AutoEndOnRecovery: entity.AutoEndOnRecovery,
+		RecoveryChecks:    entity.RecoveryChecks,
+		BannerMessage:     entity.BannerMessage,
+		ApprovalStatus:    entity.ApprovalStatus,
+		ApprovedBy:        entity.ApprovedBy,
+		RequestedBy:       entity.RequestedBy,
+		CreatedAt:         entity.CreatedAt,
+		UpdatedAt:         entity.UpdatedAt,
+		MonitorIds:        monitorIds,
 	}
 
 	ctx.JSON(http.StatusOK, utils.NewSuccessResponse("success", response))
@@ -189,13 +224,27 @@ func (ic *Controller) UpdateFull(ctx *gin.Context) {
 		return
 	}
 
+	previous, err := ic.service.FindByID(ctx, id)
+	if err != nil {
+		ic.logger.Errorw("Failed to fetch maintenance before update", "error", err)
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
+		return
+	}
+
+	_, entity.RequestedBy = audit_log.ActorFromContext(ctx)
 	updated, err := ic.service.UpdateFull(ctx, id, &entity)
+	if errors.Is(err, ErrInvalidDateTimeWindow) || errors.Is(err, ErrAutoEndRequiresOneShot) {
+		ctx.JSON(http.StatusBadRequest, utils.NewFailResponse(err.Error()))
+		return
+	}
 	if err != nil {
 		ic.logger.Errorw("Failed to update maintenance", "error", err)
 		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
 		return
 	}
 
+	ic.recordAudit(ctx, id, audit_log.ActionUpdate, previous, updated)
+
 	ctx.JSON(http.StatusOK, utils.NewSuccessResponse("maintenance updated successfully", updated))
 }
 
@@ -220,13 +269,27 @@ func (ic *Controller) UpdatePartial(ctx *gin.Context) {
 		return
 	}
 
+	previous, err := ic.service.FindByID(ctx, id)
+	if err != nil {
+		ic.logger.Errorw("Failed to fetch maintenance before update", "error", err)
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
+		return
+	}
+
+	_, entity.RequestedBy = audit_log.ActorFromContext(ctx)
 	updated, err := ic.service.UpdatePartial(ctx, id, &entity)
+	if errors.Is(err, ErrInvalidDateTimeWindow) || errors.Is(err, ErrAutoEndRequiresOneShot) {
+		ctx.JSON(http.StatusBadRequest, utils.NewFailResponse(err.Error()))
+		return
+	}
 	if err != nil {
 		ic.logger.Errorw("Failed to update maintenance", "error", err)
 		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
 		return
 	}
 
+	ic.recordAudit(ctx, id, audit_log.ActionUpdate, previous, updated)
+
 	ctx.JSON(http.StatusOK, utils.NewSuccessResponse("maintenance updated successfully", updated))
 }
 
@@ -243,13 +306,24 @@ func (ic *Controller) UpdatePartial(ctx *gin.Context) {
 func (ic *Controller) Delete(ctx *gin.Context) {
 	id := ctx.Param("id")
 
-	err := ic.service.Delete(ctx, id)
+	previous, err := ic.service.FindByID(ctx, id)
+	if err != nil {
+		ic.logger.Errorw("Failed to fetch maintenance before delete", "error", err)
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
+		return
+	}
+
+	err = ic.service.Delete(ctx, id)
 	if err != nil {
 		ic.logger.Errorw("Failed to delete maintenance", "error", err)
 		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
 		return
 	}
 
+	if previous != nil {
+		ic.recordAudit(ctx, id, audit_log.ActionDelete, previous, nil)
+	}
+
 	ctx.JSON(http.StatusOK, utils.NewSuccessResponse[any]("Maintenance deleted successfully", nil))
 }
 
@@ -266,11 +340,17 @@ func (ic *Controller) Delete(ctx *gin.Context) {
 func (ic *Controller) Pause(ctx *gin.Context) {
 	fmt.Println("Pausing maintenance")
 	id := ctx.Param("id")
+	previous, err := ic.service.FindByID(ctx, id)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Failed to pause maintenance"))
+		return
+	}
 	updated, err := ic.service.SetActive(ctx, id, false)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Failed to pause maintenance"))
 		return
 	}
+	ic.recordAudit(ctx, id, audit_log.ActionUpdate, previous, updated)
 	ctx.JSON(http.StatusOK, utils.NewSuccessResponse("Paused", updated))
 }
 
@@ -286,10 +366,70 @@ func (ic *Controller) Pause(ctx *gin.Context) {
 // @Failure		500	{object}	utils.APIError[any]
 func (ic *Controller) Resume(ctx *gin.Context) {
 	id := ctx.Param("id")
+	previous, err := ic.service.FindByID(ctx, id)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Failed to resume maintenance"))
+		return
+	}
 	updated, err := ic.service.SetActive(ctx, id, true)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Failed to resume maintenance"))
 		return
 	}
+	ic.recordAudit(ctx, id, audit_log.ActionUpdate, previous, updated)
 	ctx.JSON(http.StatusOK, utils.NewSuccessResponse("Resumed", updated))
 }
+
+// @Router		/maintenances/{id}/approve [patch]
+// @Summary		Approve maintenance
+// @Tags			Maintenances
+// @Produce		json
+// @Security BearerAuth
+// @Param       id   path      string  true  "Maintenance ID"
+// @Success		200	{object}	utils.ApiResponse[Model]
+// @Failure		400	{object}	utils.APIError[any]
+// @Failure		403	{object}	utils.APIError[any]
+// @Failure		404	{object}	utils.APIError[any]
+// @Failure		500	{object}	utils.APIError[any]
+func (ic *Controller) Approve(ctx *gin.Context) {
+	ic.setApproval(ctx, ApprovalStatusApproved, "approve", "Approved")
+}
+
+// @Router		/maintenances/{id}/reject [patch]
+// @Summary		Reject maintenance
+// @Tags			Maintenances
+// @Produce		json
+// @Security BearerAuth
+// @Param       id   path      string  true  "Maintenance ID"
+// @Success		200	{object}	utils.ApiResponse[Model]
+// @Failure		400	{object}	utils.APIError[any]
+// @Failure		404	{object}	utils.APIError[any]
+// @Failure		500	{object}	utils.APIError[any]
+func (ic *Controller) Reject(ctx *gin.Context) {
+	ic.setApproval(ctx, ApprovalStatusRejected, "reject", "Rejected")
+}
+
+func (ic *Controller) setApproval(ctx *gin.Context, status, verb, message string) {
+	id := ctx.Param("id")
+	previous, err := ic.service.FindByID(ctx, id)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Failed to "+verb+" maintenance"))
+		return
+	}
+	if previous == nil {
+		ctx.JSON(http.StatusNotFound, utils.NewFailResponse("Maintenance not found"))
+		return
+	}
+	_, approvedBy := audit_log.ActorFromContext(ctx)
+	updated, err := ic.service.SetApproval(ctx, id, status, approvedBy)
+	if errors.Is(err, ErrSelfApproval) {
+		ctx.JSON(http.StatusForbidden, utils.NewFailResponse(err.Error()))
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Failed to "+verb+" maintenance"))
+		return
+	}
+	ic.recordAudit(ctx, id, audit_log.ActionUpdate, previous, updated)
+	ctx.JSON(http.StatusOK, utils.NewSuccessResponse(message, updated))
+}
@@ -3,21 +3,58 @@ package maintenance
 import "time"
 
 type Model struct {
-	ID            string    `json:"id"`
-	Title         string    `json:"title"`
-	Description   string    `json:"description"`
-	Active        bool      `json:"active"`
-	Strategy      string    `json:"strategy"`
-	StartDateTime *string   `json:"start_date_time,omitempty"`
-	EndDateTime   *string   `json:"end_date_time,omitempty"`
-	StartTime     *string   `json:"start_time,omitempty"`
-	EndTime       *string   `json:"end_time,omitempty"`
-	Weekdays      []int     `json:"weekdays,omitempty"`
-	DaysOfMonth   []int     `json:"days_of_month,omitempty"`
-	IntervalDay   *int      `json:"interval_day,omitempty"`
-	Cron          *string   `json:"cron,omitempty"`
-	Timezone      *string   `json:"timezone,omitempty"`
-	Duration      *int      `json:"duration,omitempty"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID            string  `json:"id"`
+	Title         string  `json:"title"`
+	Description   string  `json:"description"`
+	Active        bool    `json:"active"`
+	Strategy      string  `json:"strategy"`
+	StartDateTime *string `json:"start_date_time,omitempty"`
+	EndDateTime   *string `json:"end_date_time,omitempty"`
+	StartTime     *string `json:"start_time,omitempty"`
+	EndTime       *string `json:"end_time,omitempty"`
+	Weekdays      []int   `json:"weekdays,omitempty"`
+	DaysOfMonth   []int   `json:"days_of_month,omitempty"`
+	IntervalDay   *int    `json:"interval_day,omitempty"`
+	Cron          *string `json:"cron,omitempty"`
+	Timezone      *string `json:"timezone,omitempty"`
+	Duration      *int    `json:"duration,omitempty"`
+	// SuppressChecks skips enqueuing checks entirely while the window is active
+	SuppressChecks bool `json:"suppress_checks"`
+	// AutoEndOnRecovery deactivates a single or manual window once the monitors are up
+	// for RecoveryChecks consecutive checks. Checks keep running during the window.
+	AutoEndOnRecovery bool `json:"auto_end_on_recovery"`
+	// RecoveryChecks is the number of consecutive up checks that end the window,
+	// DefaultRecoveryChecks when unset
+	RecoveryChecks int `json:"recovery_checks"`
+	// BannerMessage is shown on the status pages of the affected monitors while the window is active
+	BannerMessage *string `json:"banner_message,omitempty"`
+	// ApprovalStatus is pending, approved or rejected, only approved windows take effect
+	ApprovalStatus string `json:"approval_status"`
+	// ApprovedBy is the user or API key that approved or rejected the window
+	ApprovedBy *string `json:"approved_by,omitempty"`
+	// RequestedBy is the user or API key that created or last edited the window, it
+	// can't approve the window itself
+	RequestedBy *string   `json:"requested_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
 }
+
+const (
+	ApprovalStatusPending  = "pending"
+	ApprovalStatusApproved = "approved"
+	ApprovalStatusRejected = "rejected"
+)
+
+// IsApproved reports whether the window may take effect. Windows created before
+// approvals existed have no status and count as approved.
+func (m *Model) IsApproved() bool {
+	return m.ApprovalStatus == "" || m.ApprovalStatus == ApprovalStatusApproved
+}
+
+// CheckDuringMaintenanceSettingKey is the global setting that keeps checks running while a
+// maintenance window is active. Alerts for the affected monitors are still suppressed.
+const CheckDuringMaintenanceSettingKey = "check_during_maintenance"
+
+// RequireApprovalSettingKey is the global setting that creates every maintenance window
+// as pending, whatever approval status the request asks for
+const RequireApprovalSettingKey = "maintenance_requires_approval"
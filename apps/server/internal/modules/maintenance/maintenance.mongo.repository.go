@@ -13,62 +13,80 @@ import (
 )
 
 type mongoModel struct {
-	ID            primitive.ObjectID `bson:"_id"`
-	Title         string             `bson:"title"`
-	Description   string             `bson:"description"`
-	Active        bool               `bson:"active"`
-	Strategy      string             `bson:"strategy"`
-	StartDateTime *string            `bson:"start_date_time,omitempty"`
-	EndDateTime   *string            `bson:"end_date_time,omitempty"`
-	StartTime     *string            `bson:"start_time,omitempty"`
-	EndTime       *string            `bson:"end_time,omitempty"`
-	Weekdays      []int              `bson:"weekdays,omitempty"`
-	DaysOfMonth   []int              `bson:"days_of_month,omitempty"`
-	IntervalDay   *int               `bson:"interval_day,omitempty"`
-	Cron          *string            `bson:"cron,omitempty"`
-	Timezone      *string            `bson:"timezone,omitempty"`
-	Duration      *int               `bson:"duration,omitempty"`
-	CreatedAt     time.Time          `bson:"created_at"`
-	UpdatedAt     time.Time          `bson:"updated_at"`
+	ID                primitive.ObjectID `bson:"_id"`
+	Title             string             `bson:"title"`
+	Description       string             `bson:"description"`
+	Active            bool               `bson:"active"`
+	Strategy          string             `bson:"strategy"`
+	StartDateTime     *string            `bson:"start_date_time,omitempty"`
+	EndDateTime       *string            `bson:"end_date_time,omitempty"`
+	StartTime         *string            `bson:"start_time,omitempty"`
+	EndTime           *string            `bson:"end_time,omitempty"`
+	Weekdays          []int              `bson:"weekdays,omitempty"`
+	DaysOfMonth       []int              `bson:"days_of_month,omitempty"`
+	IntervalDay       *int               `bson:"interval_day,omitempty"`
+	Cron              *string            `bson:"cron,omitempty"`
+	Timezone          *string            `bson:"timezone,omitempty"`
+	Duration          *int               `bson:"duration,omitempty"`
+	SuppressChecks    bool               `bson:"suppress_checks"`
+	AutoEndOnRecovery bool               `bson:"auto_end_on_recovery"`
+	RecoveryChecks    int                `bson:"recovery_checks"`
+	BannerMessage     *string            `bson:"banner_message,omitempty"`
+	ApprovalStatus    string             `bson:"approval_status,omitempty"`
+	ApprovedBy        *string            `bson:"approved_by,omitempty"`
+	RequestedBy       *string            `bson:"requested_by,omitempty"`
+	CreatedAt         time.Time          `bson:"created_at"`
+	UpdatedAt         time.Time          `bson:"updated_at"`
 }
 
 type mongoUpdateModel struct {
-	Title         *string `bson:"title,omitempty"`
-	Description   *string `bson:"description,omitempty"`
-	Active        *bool   `bson:"active,omitempty"`
-	Strategy      *string `bson:"strategy,omitempty"`
-	StartDateTime *string `bson:"start_date_time,omitempty"`
-	EndDateTime   *string `bson:"end_date_time,omitempty"`
-	StartTime     *string `bson:"start_time,omitempty"`
-	EndTime       *string `bson:"end_time,omitempty"`
-	Weekdays      []int   `bson:"weekdays,omitempty"`
-	DaysOfMonth   []int   `bson:"days_of_month,omitempty"`
-	IntervalDay   *int    `bson:"interval_day,omitempty"`
-	Cron          *string `bson:"cron,omitempty"`
-	Timezone      *string `bson:"timezone,omitempty"`
-	Duration      *int    `bson:"duration,omitempty"`
-	UpdatedAt     *string `bson:"updated_at,omitempty"`
+	Title             *string `bson:"title,omitempty"`
+	Description       *string `bson:"description,omitempty"`
+	Active            *bool   `bson:"active,omitempty"`
+	Strategy          *string `bson:"strategy,omitempty"`
+	StartDateTime     *string `bson:"start_date_time,omitempty"`
+	EndDateTime       *string `bson:"end_date_time,omitempty"`
+	StartTime         *string `bson:"start_time,omitempty"`
+	EndTime           *string `bson:"end_time,omitempty"`
+	Weekdays          []int   `bson:"weekdays,omitempty"`
+	DaysOfMonth       []int   `bson:"days_of_month,omitempty"`
+	IntervalDay       *int    `bson:"interval_day,omitempty"`
+	Cron              *string `bson:"cron,omitempty"`
+	Timezone          *string `bson:"timezone,omitempty"`
+	Duration          *int    `bson:"duration,omitempty"`
+	SuppressChecks    *bool   `bson:"suppress_checks,omitempty"`
+	AutoEndOnRecovery *bool   `bson:"auto_end_on_recovery,omitempty"`
+	RecoveryChecks    *int    `bson:"recovery_checks,omitempty"`
+	BannerMessage     *string `bson:"banner_message,omitempty"`
+	UpdatedAt         *string `bson:"updated_at,omitempty"`
 }
 
 func toDomainModel(mm *mongoModel) *Model {
 	return &Model{
-		ID:            mm.ID.Hex(),
-		Title:         mm.Title,
-		Description:   mm.Description,
-		Active:        mm.Active,
-		Strategy:      mm.Strategy,
-		StartDateTime: mm.StartDateTime,
-		EndDateTime:   mm.EndDateTime,
-		StartTime:     mm.StartTime,
-		EndTime:       mm.EndTime,
-		Weekdays:      mm.Weekdays,
-		DaysOfMonth:   mm.DaysOfMonth,
-		IntervalDay:   mm.IntervalDay,
-		Cron:          mm.Cron,
-		Timezone:      mm.Timezone,
-		Duration:      mm.Duration,
-		CreatedAt:     mm.CreatedAt,
-		UpdatedAt:     mm.UpdatedAt,
+		ID:                mm.ID.Hex(),
+		Title:             mm.Title,
+		Description:       mm.Description,
+		Active:            mm.Active,
+		Strategy:          mm.Strategy,
+		StartDateTime:     mm.StartDateTime,
+		EndDateTime:       mm.EndDateTime,
+		StartTime:         mm.StartTime,
+		EndTime:           mm.EndTime,
+		Weekdays:          mm.Weekdays,
+		DaysOfMonth:       mm.DaysOfMonth,
+		IntervalDay:       mm.IntervalDay,
+		Cron:              mm.Cron,
+		Timezone:          mm.Timezone,
+		Duration:          mm.Duration,
+		SuppressChecks:    mm.SuppressChecks,
+		AutoEndOnRecovery: mm.AutoEndOnRecovery,
+		RecoveryChecks:    mm.RecoveryChecks,
+		BannerMessage:     mm.BannerMessage,
+		ApprovalStatus:    mm.ApprovalStatus,
+		ApprovedBy:        mm.ApprovedBy,
+		RequestedBy:       mm.RequestedBy,
+		CreatedAt:         mm.CreatedAt,
+		UpdatedAt:         mm.UpdatedAt,
 	}
 }
 
@@ -94,21 +112,27 @@ func NewMongoRepository(client *mongo.Client, cfg *config.Config) Repository {
 
 func (r *MongoRepositoryImpl) Create(ctx context.Context, entity *CreateUpdateDto) (*Model, error) {
 	mm := &mongoModel{
-		ID:            primitive.NewObjectID(),
-		Title:         entity.Title,
-		Description:   entity.Description,
-		Active:        entity.Active,
-		Strategy:      entity.Strategy,
-		StartDateTime: entity.StartDateTime,
-		EndDateTime:   entity.EndDateTime,
-		Weekdays:      entity.Weekdays,
-		DaysOfMonth:   entity.DaysOfMonth,
-		IntervalDay:   entity.IntervalDay,
-		Cron:          entity.Cron,
-		Timezone:      entity.Timezone,
-		Duration:      entity.Duration,
-		CreatedAt:     time.Now(),
-		UpdatedAt:     time.Now(),
+		ID:                primitive.NewObjectID(),
+		Title:             entity.Title,
+		Description:       entity.Description,
+		Active:            entity.Active,
+		Strategy:          entity.Strategy,
+		StartDateTime:     entity.StartDateTime,
+		EndDateTime:       entity.EndDateTime,
+		Weekdays:          entity.Weekdays,
+		DaysOfMonth:       entity.DaysOfMonth,
+		IntervalDay:       entity.IntervalDay,
+		Cron:              entity.Cron,
+		Timezone:          entity.Timezone,
+		Duration:          entity.Duration,
+		SuppressChecks:    entity.SuppressChecks,
+		AutoEndOnRecovery: entity.AutoEndOnRecovery,
+		RecoveryChecks:    entity.RecoveryChecks,
+		BannerMessage:     entity.BannerMessage,
+		ApprovalStatus:    entity.ApprovalStatus,
+		RequestedBy:       requestedBy(entity.RequestedBy),
+		CreatedAt:         time.Now(),
+		UpdatedAt:         time.Now(),
 	}
 
 	_, err := r.collection.InsertOne(ctx, mm)
@@ -191,22 +215,26 @@ func (r *MongoRepositoryImpl) UpdateFull(ctx context.Context, id string, entity
 	}
 
 	mm := &mongoModel{
-		ID:            objectID,
-		Title:         entity.Title,
-		Description:   entity.Description,
-		Active:        entity.Active,
-		Strategy:      entity.Strategy,
-		StartDateTime: entity.StartDateTime,
-		EndDateTime:   entity.EndDateTime,
-		StartTime:     entity.StartTime,
-		EndTime:       entity.EndTime,
-		Weekdays:      entity.Weekdays,
-		DaysOfMonth:   entity.DaysOfMonth,
-		IntervalDay:   entity.IntervalDay,
-		Cron:          entity.Cron,
-		Timezone:      entity.Timezone,
-		Duration:      entity.Duration,
-		UpdatedAt:     time.Now(),
+		ID:                objectID,
+		Title:             entity.Title,
+		Description:       entity.Description,
+		Active:            entity.Active,
+		Strategy:          entity.Strategy,
+		StartDateTime:     entity.StartDateTime,
+		EndDateTime:       entity.EndDateTime,
+		StartTime:         entity.StartTime,
+		EndTime:           entity.EndTime,
+		Weekdays:          entity.Weekdays,
+		DaysOfMonth:       entity.DaysOfMonth,
+		IntervalDay:       entity.IntervalDay,
+		Cron:              entity.Cron,
+		Timezone:          entity.Timezone,
+		Duration:          entity.Duration,
+		SuppressChecks:    entity.SuppressChecks,
+		AutoEndOnRecovery: entity.AutoEndOnRecovery,
+		RecoveryChecks:    entity.RecoveryChecks,
+		BannerMessage:     entity.BannerMessage,
+		UpdatedAt:         time.Now(),
 	}
 
 	filter := bson.M{"_id": objectID}
@@ -230,21 +258,25 @@ func (r *MongoRepositoryImpl) UpdatePartial(ctx context.Context, id string, enti
 	nowStr := now.Format(time.RFC3339)
 
 	update := &mongoUpdateModel{
-		Title:         entity.Title,
-		Description:   entity.Description,
-		Active:        entity.Active,
-		Strategy:      entity.Strategy,
-		StartDateTime: entity.StartDateTime,
-		EndDateTime:   entity.EndDateTime,
-		StartTime:     entity.StartTime,
-		EndTime:       entity.EndTime,
-		Weekdays:      entity.Weekdays,
-		DaysOfMonth:   entity.DaysOfMonth,
-		IntervalDay:   entity.IntervalDay,
-		Cron:          entity.Cron,
-		Timezone:      entity.Timezone,
-		Duration:      entity.Duration,
-		UpdatedAt:     &nowStr,
+		Title:             entity.Title,
+		Description:       entity.Description,
+		Active:            entity.Active,
+		Strategy:          entity.Strategy,
+		StartDateTime:     entity.StartDateTime,
+		EndDateTime:       entity.EndDateTime,
+		StartTime:         entity.StartTime,
+		EndTime:           entity.EndTime,
+		Weekdays:          entity.Weekdays,
+		DaysOfMonth:       entity.DaysOfMonth,
+		IntervalDay:       entity.IntervalDay,
+		Cron:              entity.Cron,
+		Timezone:          entity.Timezone,
+		Duration:          entity.Duration,
+		SuppressChecks:    entity.SuppressChecks,
+		AutoEndOnRecovery: entity.AutoEndOnRecovery,
+		RecoveryChecks:    entity.RecoveryChecks,
+		BannerMessage:     entity.BannerMessage,
+		UpdatedAt:         &nowStr,
 	}
 
 	filter := bson.M{"_id": objectID}
@@ -291,6 +323,37 @@ func (r *MongoRepositoryImpl) SetActive(ctx context.Context, id string, active b
 	return r.FindByID(ctx, id)
 }
 
+func (r *MongoRepositoryImpl) SetApproval(ctx context.Context, id string, status string, approvedBy string) (*Model, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	update := bson.M{"$set": bson.M{"approval_status": status, "approved_by": approvedBy, "updated_at": now}}
+	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
+	if err != nil {
+		return nil, err
+	}
+	return r.FindByID(ctx, id)
+}
+
+func (r *MongoRepositoryImpl) ResetApproval(ctx context.Context, id string, requestedBy string) (*Model, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	update := bson.M{
+		"$set":   bson.M{"approval_status": ApprovalStatusPending, "requested_by": requestedBy, "updated_at": now},
+		"$unset": bson.M{"approved_by": ""},
+	}
+	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
+	if err != nil {
+		return nil, err
+	}
+	return r.FindByID(ctx, id)
+}
+
 // GetMaintenancesByMonitorID returns all active maintenances for a given monitor_id
 func (r *MongoRepositoryImpl) GetMaintenancesByMonitorID(ctx context.Context, monitorID string) ([]*Model, error) {
 	objectID, err := primitive.ObjectIDFromHex(monitorID)
@@ -11,5 +11,15 @@ type Repository interface {
 	Delete(ctx context.Context, id string) error
 
 	SetActive(ctx context.Context, id string, active bool) (*Model, error)
+	SetApproval(ctx context.Context, id string, status string, approvedBy string) (*Model, error)
+	ResetApproval(ctx context.Context, id string, requestedBy string) (*Model, error)
 	GetMaintenancesByMonitorID(ctx context.Context, monitorID string) ([]*Model, error)
 }
+
+// requestedBy returns the stored form of the requester, nil when it is unknown
+func requestedBy(id string) *string {
+	if id == "" {
+		return nil
+	}
+	return &id
+}
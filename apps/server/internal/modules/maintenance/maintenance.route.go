@@ -37,4 +37,6 @@ func (uc *Route) ConnectRoute(
 
 	router.PATCH(":id/pause", uc.controller.Pause)
 	router.PATCH(":id/resume", uc.controller.Resume)
+	router.PATCH(":id/approve", uc.controller.Approve)
+	router.PATCH(":id/reject", uc.controller.Reject)
 }
@@ -2,14 +2,38 @@ package maintenance
 
 import (
 	"context"
+	"errors"
+	"strconv"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
 
+	"peekaping/internal/modules/audit_log"
 	"peekaping/internal/modules/maintenance/utils"
 	"peekaping/internal/modules/monitor_maintenance"
+	"peekaping/internal/modules/shared"
 )
 
+// ErrInvalidDateTimeWindow is returned when the start or end date time of a maintenance
+// doesn't parse or the window ends before it starts
+var ErrInvalidDateTimeWindow = utils.ErrInvalidDateTimeWindow
+
+// Window is one occurrence of a maintenance, from Start up to but excluding End
+type Window = utils.Window
+
+// ErrAutoEndRequiresOneShot is returned when auto_end_on_recovery is set on a recurring
+// maintenance
+var ErrAutoEndRequiresOneShot = errors.New("auto end on recovery is only supported for single and manual maintenances")
+
+// ErrSelfApproval is returned when the user or API key that created or last edited a
+// maintenance window tries to approve it
+var ErrSelfApproval = errors.New("a maintenance window can't be approved by the one who requested it")
+
+// DefaultRecoveryChecks is the number of consecutive up checks ending a window with
+// auto_end_on_recovery when it has no recovery_checks
+const DefaultRecoveryChecks = 3
+
 type Service interface {
 	Create(ctx context.Context, entity *CreateUpdateDto) (*Model, error)
 	FindByID(ctx context.Context, id string) (*Model, error)
@@ -20,19 +44,36 @@ type Service interface {
 
 	SetActive(ctx context.Context, id string, active bool) (*Model, error)
 
+	// SetApproval approves or rejects a maintenance window on behalf of approvedBy
+	SetApproval(ctx context.Context, id string, status string, approvedBy string) (*Model, error)
+
 	// GetStatus returns whether the maintenance is currently active
 	IsUnderMaintenance(ctx context.Context, maintenance *Model) (bool, error)
 
+	// IsUnderMaintenanceAt returns whether the maintenance window covers the given instant
+	IsUnderMaintenanceAt(ctx context.Context, maintenance *Model, at time.Time) (bool, error)
+
+	// NextWindow returns the window of the maintenance in progress at the given instant or
+	// else its next one, nil when there is none
+	NextWindow(ctx context.Context, maintenance *Model, after time.Time) (*Window, error)
+
 	// Get maintenances by monitor ID
 	GetMaintenancesByMonitorID(ctx context.Context, monitorID string) ([]*Model, error)
 
 	// Get monitors for a maintenance
 	GetMonitors(ctx context.Context, id string) ([]string, error)
+
+	// EndOnRecovery deactivates a window with auto_end_on_recovery once its monitors
+	// recovered, reporting whether it did. latest maps each monitor of the window to
+	// its most recent heartbeats, newest first.
+	EndOnRecovery(ctx context.Context, maintenance *Model, latest map[string][]*shared.HeartBeatModel) (bool, error)
 }
 
 type ServiceImpl struct {
 	repository                Repository
 	monitorMaintenanceService monitor_maintenance.Service
+	settingService            shared.SettingService
+	auditLogService           audit_log.Service
 	logger                    *zap.SugaredLogger
 	cronGenerator             utils.CronGeneratorInterface
 	timeWindowChecker         utils.TimeWindowCheckerInterface
@@ -43,11 +84,15 @@ type ServiceImpl struct {
 func NewService(
 	repository Repository,
 	monitorMaintenanceService monitor_maintenance.Service,
+	settingService shared.SettingService,
+	auditLogService audit_log.Service,
 	logger *zap.SugaredLogger,
 ) Service {
 	return &ServiceImpl{
 		repository:                repository,
 		monitorMaintenanceService: monitorMaintenanceService,
+		settingService:            settingService,
+		auditLogService:           auditLogService,
 		logger:                    logger.Named("[maintenance-service]"),
 		cronGenerator:             utils.NewCronGenerator(),
 		timeWindowChecker:         utils.NewTimeWindowChecker(logger),
@@ -57,6 +102,19 @@ func NewService(
 }
 
 func (mr *ServiceImpl) Create(ctx context.Context, entity *CreateUpdateDto) (*Model, error) {
+	if err := validateAutoEnd(entity.Strategy, entity.AutoEndOnRecovery); err != nil {
+		return nil, err
+	}
+
+	if err := mr.validator.ValidateDateTimeWindow(&utils.ValidationParams{
+		Strategy:      &entity.Strategy,
+		StartDateTime: entity.StartDateTime,
+		EndDateTime:   entity.EndDateTime,
+		Timezone:      entity.Timezone,
+	}); err != nil {
+		return nil, err
+	}
+
 	// Validate cron and duration
 	if err := mr.validator.ValidateCronAndDuration(&utils.ValidationParams{
 		Cron:     entity.Cron,
@@ -89,6 +147,14 @@ func (mr *ServiceImpl) Create(ctx context.Context, entity *CreateUpdateDto) (*Mo
 		mr.logger.Debugf("Calculated duration from start/end times: %d minutes", duration)
 	}
 
+	// Windows wait for approval when the setting requires it, otherwise those that do not
+	// ask for approval take effect right away
+	if mr.requiresApproval(ctx) {
+		entity.ApprovalStatus = ApprovalStatusPending
+	} else if entity.ApprovalStatus == "" {
+		entity.ApprovalStatus = ApprovalStatusApproved
+	}
+
 	// Store times directly without timezone conversion
 	created, err := mr.repository.Create(ctx, entity)
 	if err != nil {
@@ -125,6 +191,19 @@ func (mr *ServiceImpl) FindAll(ctx context.Context, page int, limit int, q strin
 }
 
 func (mr *ServiceImpl) UpdateFull(ctx context.Context, id string, entity *CreateUpdateDto) (*Model, error) {
+	if err := validateAutoEnd(entity.Strategy, entity.AutoEndOnRecovery); err != nil {
+		return nil, err
+	}
+
+	if err := mr.validator.ValidateDateTimeWindow(&utils.ValidationParams{
+		Strategy:      &entity.Strategy,
+		StartDateTime: entity.StartDateTime,
+		EndDateTime:   entity.EndDateTime,
+		Timezone:      entity.Timezone,
+	}); err != nil {
+		return nil, err
+	}
+
 	// Validate cron and duration
 	if err := mr.validator.ValidateCronAndDuration(&utils.ValidationParams{
 		Cron:     entity.Cron,
@@ -157,12 +236,23 @@ func (mr *ServiceImpl) UpdateFull(ctx context.Context, id string, entity *Create
 		mr.logger.Debugf("Calculated duration from start/end times: %d minutes", duration)
 	}
 
+	previous, err := mr.repository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
 	// Store times directly without timezone conversion
 	updated, err := mr.repository.UpdateFull(ctx, id, entity)
 	if err != nil {
 		return nil, err
 	}
 
+	if mr.approvalApplies(ctx, previous) {
+		if updated, err = mr.repository.ResetApproval(ctx, id, entity.RequestedBy); err != nil {
+			return nil, err
+		}
+	}
+
 	// Handle monitor IDs if provided
 	if entity.MonitorIds != nil {
 		err = mr.monitorMaintenanceService.SetMonitors(ctx, id, entity.MonitorIds)
@@ -175,6 +265,61 @@ func (mr *ServiceImpl) UpdateFull(ctx context.Context, id string, entity *Create
 }
 
 func (mr *ServiceImpl) UpdatePartial(ctx context.Context, id string, entity *PartialUpdateDto) (*Model, error) {
+	// Validate the window the maintenance ends up with
+	if entity.Strategy != nil || entity.StartDateTime != nil || entity.EndDateTime != nil || entity.Timezone != nil {
+		current, err := mr.repository.FindByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		params := &utils.ValidationParams{
+			Strategy:      entity.Strategy,
+			StartDateTime: entity.StartDateTime,
+			EndDateTime:   entity.EndDateTime,
+			Timezone:      entity.Timezone,
+		}
+		if current != nil {
+			if params.Strategy == nil {
+				params.Strategy = &current.Strategy
+			}
+			if params.StartDateTime == nil {
+				params.StartDateTime = current.StartDateTime
+			}
+			if params.EndDateTime == nil {
+				params.EndDateTime = current.EndDateTime
+			}
+			if params.Timezone == nil {
+				params.Timezone = current.Timezone
+			}
+		}
+		if err := mr.validator.ValidateDateTimeWindow(params); err != nil {
+			return nil, err
+		}
+	}
+
+	// Validate that the maintenance doesn't end up recurring with auto end on recovery
+	if entity.Strategy != nil || entity.AutoEndOnRecovery != nil {
+		current, err := mr.repository.FindByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		var strategy string
+		var autoEnd bool
+		if current != nil {
+			strategy, autoEnd = current.Strategy, current.AutoEndOnRecovery
+		}
+		if entity.Strategy != nil {
+			strategy = *entity.Strategy
+		}
+		if entity.AutoEndOnRecovery != nil {
+			autoEnd = *entity.AutoEndOnRecovery
+		}
+		if err := validateAutoEnd(strategy, autoEnd); err != nil {
+			return nil, err
+		}
+	}
+
 	// If strategy is being updated, we might need to regenerate cron expression
 	if entity.Strategy != nil {
 		// Get the current maintenance to merge with partial update
@@ -260,12 +405,23 @@ func (mr *ServiceImpl) UpdatePartial(ctx context.Context, id string, entity *Par
 		return nil, err
 	}
 
+	previous, err := mr.repository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
 	// Store times directly without timezone conversion
 	updated, err := mr.repository.UpdatePartial(ctx, id, entity)
 	if err != nil {
 		return nil, err
 	}
 
+	if mr.approvalApplies(ctx, previous) {
+		if updated, err = mr.repository.ResetApproval(ctx, id, entity.RequestedBy); err != nil {
+			return nil, err
+		}
+	}
+
 	// Handle monitor IDs if provided
 	if entity.MonitorIds != nil {
 		err = mr.monitorMaintenanceService.SetMonitors(ctx, id, entity.MonitorIds)
@@ -290,9 +446,67 @@ func (mr *ServiceImpl) SetActive(ctx context.Context, id string, active bool) (*
 	return model, nil
 }
 
+func (mr *ServiceImpl) SetApproval(ctx context.Context, id string, status string, approvedBy string) (*Model, error) {
+	if status == ApprovalStatusApproved {
+		current, err := mr.repository.FindByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if current != nil && current.RequestedBy != nil && *current.RequestedBy == approvedBy {
+			return nil, ErrSelfApproval
+		}
+	}
+
+	model, err := mr.repository.SetApproval(ctx, id, status, approvedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+// requiresApproval reports whether the global setting makes every window wait for approval
+func (mr *ServiceImpl) requiresApproval(ctx context.Context) bool {
+	setting, err := mr.settingService.GetByKey(ctx, RequireApprovalSettingKey)
+	if err != nil {
+		mr.logger.Warnw("Failed to fetch maintenance approval setting", "error", err)
+		return false
+	}
+	if setting == nil {
+		return false
+	}
+	enabled, err := strconv.ParseBool(strings.TrimSpace(setting.Value))
+	if err != nil {
+		mr.logger.Warnw("Invalid maintenance approval setting", "value", setting.Value, "error", err)
+		return false
+	}
+	return enabled
+}
+
+// approvalApplies reports whether an edit of the window sends it back for approval. That is
+// the case when approvals are required or the window already went through the approval flow,
+// windows approved automatically stay approved.
+func (mr *ServiceImpl) approvalApplies(ctx context.Context, previous *Model) bool {
+	if previous == nil {
+		return false
+	}
+	if previous.ApprovedBy != nil || previous.ApprovalStatus == ApprovalStatusPending || previous.ApprovalStatus == ApprovalStatusRejected {
+		return true
+	}
+	return mr.requiresApproval(ctx)
+}
+
 // IsUnderMaintenance determines if the maintenance is currently active based on strategy and timing
 func (mr *ServiceImpl) IsUnderMaintenance(ctx context.Context, maintenance *Model) (bool, error) {
-	mr.logger.Debugf("Checking if maintenance %s is under maintenance", maintenance.ID)
+	return mr.IsUnderMaintenanceAt(ctx, maintenance, time.Now())
+}
+
+// IsUnderMaintenanceAt determines if the maintenance window covers the given instant.
+// Windows are half-open: a check scheduled exactly at the start is inside the window,
+// one scheduled exactly at the end is not. Both the producer (using the scheduled tick)
+// and the notification listener (using the heartbeat time) rely on this boundary.
+func (mr *ServiceImpl) IsUnderMaintenanceAt(ctx context.Context, maintenance *Model, at time.Time) (bool, error) {
+	mr.logger.Debugf("Checking if maintenance %s is under maintenance at %s", maintenance.ID, at)
 	mr.logger.Debugf("Maintenance: %+v", maintenance)
 
 	// If not active, return false
@@ -300,6 +514,11 @@ func (mr *ServiceImpl) IsUnderMaintenance(ctx context.Context, maintenance *Mode
 		return false, nil
 	}
 
+	// Pending and rejected windows never suppress alerts
+	if !maintenance.IsApproved() {
+		return false, nil
+	}
+
 	if maintenance.Strategy == "manual" {
 		return maintenance.Active, nil
 	}
@@ -314,21 +533,10 @@ func (mr *ServiceImpl) IsUnderMaintenance(ctx context.Context, maintenance *Mode
 
 	// Load timezone
 	loc := mr.timeUtils.LoadTimezone(timezone)
-	now := time.Now().In(loc)
+	now := at.In(loc)
 
 	// Create time window parameters
-	timeWindowParams := &utils.TimeWindowParams{
-		StartDateTime: maintenance.StartDateTime,
-		EndDateTime:   maintenance.EndDateTime,
-		StartTime:     maintenance.StartTime,
-		EndTime:       maintenance.EndTime,
-		IntervalDay:   maintenance.IntervalDay,
-		Cron:          maintenance.Cron,
-		Duration:      maintenance.Duration,
-		Weekdays:      maintenance.Weekdays,
-		DaysOfMonth:   maintenance.DaysOfMonth,
-		Timezone:      maintenance.Timezone,
-	}
+	timeWindowParams := toTimeWindowParams(maintenance)
 
 	isInDateTimePeriod, err := mr.timeWindowChecker.IsInDateTimePeriod(timeWindowParams, now, loc)
 	if err != nil {
@@ -369,6 +577,104 @@ func (mr *ServiceImpl) IsUnderMaintenance(ctx context.Context, maintenance *Mode
 	return false, nil
 }
 
+// NextWindow returns the window of the maintenance in progress at the given instant or,
+// when there is none, the next one to start. Inactive and unapproved maintenances have no
+// windows, neither have manual ones, which last as long as they are active.
+func (mr *ServiceImpl) NextWindow(ctx context.Context, maintenance *Model, after time.Time) (*Window, error) {
+	if !maintenance.Active || !maintenance.IsApproved() || maintenance.Strategy == "manual" {
+		return nil, nil
+	}
+
+	timezone := mr.timeUtils.GetDefaultTimezone()
+	if maintenance.Timezone != nil && *maintenance.Timezone != "" {
+		timezone = *maintenance.Timezone
+	}
+	loc := mr.timeUtils.LoadTimezone(timezone)
+
+	return utils.NextWindow(maintenance.Strategy, toTimeWindowParams(maintenance), after, loc)
+}
+
+// toTimeWindowParams returns the schedule of the maintenance for the time window checks
+func toTimeWindowParams(maintenance *Model) *utils.TimeWindowParams {
+	return &utils.TimeWindowParams{
+		StartDateTime: maintenance.StartDateTime,
+		EndDateTime:   maintenance.EndDateTime,
+		StartTime:     maintenance.StartTime,
+		EndTime:       maintenance.EndTime,
+		IntervalDay:   maintenance.IntervalDay,
+		Cron:          maintenance.Cron,
+		Duration:      maintenance.Duration,
+		Weekdays:      maintenance.Weekdays,
+		DaysOfMonth:   maintenance.DaysOfMonth,
+		Timezone:      maintenance.Timezone,
+	}
+}
+
+// validateAutoEnd checks that auto end on recovery is only set on one-shot windows
+func validateAutoEnd(strategy string, autoEnd bool) error {
+	if autoEnd && strategy != "single" && strategy != "manual" {
+		return ErrAutoEndRequiresOneShot
+	}
+	return nil
+}
+
+// EndOnRecovery deactivates a window with auto_end_on_recovery once the last
+// RecoveryChecks heartbeats of every monitor of the window are up. Only heartbeats since
+// the window started count: its start date time for single windows, the time it was last
+// updated (e.g. switched on) for manual ones.
+func (mr *ServiceImpl) EndOnRecovery(ctx context.Context, maintenance *Model, latest map[string][]*shared.HeartBeatModel) (bool, error) {
+	if !maintenance.AutoEndOnRecovery || !maintenance.Active || len(latest) == 0 {
+		return false, nil
+	}
+
+	since := maintenance.UpdatedAt
+	if maintenance.Strategy == "single" {
+		if maintenance.StartDateTime == nil {
+			return false, nil
+		}
+		timezone := mr.timeUtils.GetDefaultTimezone()
+		if maintenance.Timezone != nil && *maintenance.Timezone != "" {
+			timezone = *maintenance.Timezone
+		}
+		start, err := utils.ParseDateTime(*maintenance.StartDateTime, mr.timeUtils.LoadTimezone(timezone))
+		if err != nil {
+			return false, err
+		}
+		since = start
+	}
+
+	checks := maintenance.RecoveryChecks
+	if checks <= 0 {
+		checks = DefaultRecoveryChecks
+	}
+
+	for _, heartbeats := range latest {
+		if len(heartbeats) < checks {
+			return false, nil
+		}
+		for _, hb := range heartbeats[:checks] {
+			if hb.Status != shared.MonitorStatusUp || hb.Time.Before(since) {
+				return false, nil
+			}
+		}
+	}
+
+	previous := *maintenance
+	if _, err := mr.repository.SetActive(ctx, maintenance.ID, false); err != nil {
+		return false, err
+	}
+	maintenance.Active = false
+
+	// The producer ends the window, the audit entry has the system as actor
+	if _, err := mr.auditLogService.Record(ctx, audit_log.EntityMaintenance, maintenance.ID, audit_log.ActionUpdate, &previous, maintenance); err != nil {
+		mr.logger.Errorw("Failed to record maintenance audit log", "id", maintenance.ID, "error", err)
+	}
+
+	mr.logger.Infow("Ended maintenance after its monitors recovered", "maintenance_id", maintenance.ID, "checks", checks)
+
+	return true, nil
+}
+
 // generateCronExpression generates a cron expression based on the maintenance strategy and parameters
 func (mr *ServiceImpl) generateCronExpression(dto *CreateUpdateDto) (*string, error) {
 	params := &utils.CronParams{
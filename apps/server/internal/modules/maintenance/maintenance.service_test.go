@@ -10,8 +10,11 @@ import (
 	"github.com/stretchr/testify/mock"
 	"go.uber.org/zap"
 
+	"peekaping/internal/config"
+	"peekaping/internal/modules/audit_log"
 	"peekaping/internal/modules/maintenance/utils"
 	"peekaping/internal/modules/monitor_maintenance"
+	"peekaping/internal/modules/shared"
 )
 
 // Mock dependencies
@@ -69,6 +72,22 @@ func (m *MockRepository) SetActive(ctx context.Context, id string, active bool)
 	return args.Get(0).(*Model), args.Error(1)
 }
 
+func (m *MockRepository) SetApproval(ctx context.Context, id string, status string, approvedBy string) (*Model, error) {
+	args := m.Called(ctx, id, status, approvedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Model), args.Error(1)
+}
+
+func (m *MockRepository) ResetApproval(ctx context.Context, id string, requestedBy string) (*Model, error) {
+	args := m.Called(ctx, id, requestedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Model), args.Error(1)
+}
+
 func (m *MockRepository) GetMaintenancesByMonitorID(ctx context.Context, monitorID string) ([]*Model, error) {
 	args := m.Called(ctx, monitorID)
 	return args.Get(0).([]*Model), args.Error(1)
@@ -197,6 +216,66 @@ func (m *MockValidator) ValidateCronAndDuration(params *utils.ValidationParams)
 	return args.Error(0)
 }
 
+// ValidateDateTimeWindow runs the real validation so the tests cover the window rules
+func (m *MockValidator) ValidateDateTimeWindow(params *utils.ValidationParams) error {
+	return utils.NewValidator().ValidateDateTimeWindow(params)
+}
+
+type MockSettingService struct {
+	mock.Mock
+}
+
+func (m *MockSettingService) GetByKey(ctx context.Context, key string) (*shared.SettingModel, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*shared.SettingModel), args.Error(1)
+}
+
+func (m *MockSettingService) SetByKey(ctx context.Context, key string, entity *shared.SettingCreateUpdateDto) (*shared.SettingModel, error) {
+	args := m.Called(ctx, key, entity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*shared.SettingModel), args.Error(1)
+}
+
+func (m *MockSettingService) DeleteByKey(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockSettingService) InitializeSettings(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+// requireApproval turns on the setting creating every window as pending
+func requireApproval(service *ServiceImpl) {
+	settings := &MockSettingService{}
+	settings.On("GetByKey", mock.Anything, RequireApprovalSettingKey).Return(&shared.SettingModel{Key: RequireApprovalSettingKey, Value: "true", Type: "bool"}, nil)
+	service.settingService = settings
+}
+
+// auditRepository keeps the recorded audit entries
+type auditRepository struct {
+	entries []*audit_log.Model
+}
+
+func (r *auditRepository) Create(ctx context.Context, entity *audit_log.Model) (*audit_log.Model, error) {
+	r.entries = append(r.entries, entity)
+	return entity, nil
+}
+
+func (r *auditRepository) FindAll(ctx context.Context, filter *audit_log.FilterOptions) ([]*audit_log.Model, error) {
+	return r.entries, nil
+}
+
+func (r *auditRepository) DeleteOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	return 0, nil
+}
+
 // Helper functions for creating test data
 func createTestService() (*ServiceImpl, *MockRepository, *MockMonitorMaintenanceService, *MockCronGenerator, *MockTimeWindowChecker, *MockTimeUtils, *MockValidator) {
 	mockRepo := &MockRepository{}
@@ -205,12 +284,16 @@ func createTestService() (*ServiceImpl, *MockRepository, *MockMonitorMaintenance
 	mockTimeWindowChecker := &MockTimeWindowChecker{}
 	mockTimeUtils := &MockTimeUtils{}
 	mockValidator := &MockValidator{}
+	mockSettingService := &MockSettingService{}
+	mockSettingService.On("GetByKey", mock.Anything, RequireApprovalSettingKey).Return(nil, nil).Maybe()
 
 	logger := zap.NewNop().Sugar()
 
 	service := &ServiceImpl{
 		repository:                mockRepo,
 		monitorMaintenanceService: mockMonitorMaintenanceService,
+		settingService:            mockSettingService,
+		auditLogService:           audit_log.NewService(&auditRepository{}, &config.Config{}, logger),
 		logger:                    logger,
 		cronGenerator:             mockCronGenerator,
 		timeWindowChecker:         mockTimeWindowChecker,
@@ -224,19 +307,23 @@ func createTestService() (*ServiceImpl, *MockRepository, *MockMonitorMaintenance
 func createTestCreateUpdateDto() *CreateUpdateDto {
 	startTime := "09:00"
 	endTime := "17:00"
+	startDateTime := "2024-01-01T09:00"
+	endDateTime := "2024-01-01T17:00"
 	duration := 480
 	timezone := "UTC"
 
 	return &CreateUpdateDto{
-		Title:       "Test Maintenance",
-		Description: "Test Description",
-		Active:      true,
-		Strategy:    "single",
-		StartTime:   &startTime,
-		EndTime:     &endTime,
-		Duration:    &duration,
-		Timezone:    &timezone,
-		MonitorIds:  []string{"monitor1", "monitor2"},
+		Title:         "Test Maintenance",
+		Description:   "Test Description",
+		Active:        true,
+		Strategy:      "single",
+		StartDateTime: &startDateTime,
+		EndDateTime:   &endDateTime,
+		StartTime:     &startTime,
+		EndTime:       &endTime,
+		Duration:      &duration,
+		Timezone:      &timezone,
+		MonitorIds:    []string{"monitor1", "monitor2"},
 	}
 }
 
@@ -406,6 +493,7 @@ func TestServiceImpl_UpdateFull_Success(t *testing.T) {
 	mockValidator.On("ValidateCronAndDuration", mock.AnythingOfType("*utils.ValidationParams")).Return(nil)
 	// For "single" strategy, cron generator returns nil
 	mockCronGenerator.On("GenerateCronExpression", dto.Strategy, mock.AnythingOfType("*utils.CronParams")).Return(nil, nil)
+	mockRepo.On("FindByID", mock.Anything, "test-id").Return(createTestModel(), nil)
 	mockRepo.On("UpdateFull", mock.Anything, "test-id", dto).Return(expectedModel, nil)
 	mockMonitorMaintenanceService.On("SetMonitors", mock.Anything, "test-id", dto.MonitorIds).Return(nil)
 
@@ -513,6 +601,169 @@ func TestServiceImpl_SetActive_Success(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+// Test SetApproval method
+func TestServiceImpl_SetApproval_Success(t *testing.T) {
+	service, mockRepo, _, _, _, _, _ := createTestService()
+
+	approver := "user-1"
+	expectedModel := createTestModel()
+	expectedModel.ApprovalStatus = ApprovalStatusApproved
+	expectedModel.ApprovedBy = &approver
+
+	requester := "user-2"
+	current := createTestModel()
+	current.ApprovalStatus = ApprovalStatusPending
+	current.RequestedBy = &requester
+
+	mockRepo.On("FindByID", mock.Anything, "test-id").Return(current, nil)
+	mockRepo.On("SetApproval", mock.Anything, "test-id", ApprovalStatusApproved, approver).Return(expectedModel, nil)
+
+	result, err := service.SetApproval(context.Background(), "test-id", ApprovalStatusApproved, approver)
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedModel, result)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestServiceImpl_SetApproval_RejectsSelfApproval(t *testing.T) {
+	service, mockRepo, _, _, _, _, _ := createTestService()
+
+	requester := "user-1"
+	current := createTestModel()
+	current.ApprovalStatus = ApprovalStatusPending
+	current.RequestedBy = &requester
+	mockRepo.On("FindByID", mock.Anything, "test-id").Return(current, nil)
+
+	_, err := service.SetApproval(context.Background(), "test-id", ApprovalStatusApproved, requester)
+
+	assert.ErrorIs(t, err, ErrSelfApproval)
+	mockRepo.AssertNotCalled(t, "SetApproval", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestServiceImpl_SetApproval_RequesterMayReject(t *testing.T) {
+	service, mockRepo, _, _, _, _, _ := createTestService()
+
+	requester := "user-1"
+	mockRepo.On("SetApproval", mock.Anything, "test-id", ApprovalStatusRejected, requester).Return(createTestModel(), nil)
+
+	_, err := service.SetApproval(context.Background(), "test-id", ApprovalStatusRejected, requester)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestServiceImpl_Create_RequiredApprovalForcesPending(t *testing.T) {
+	service, mockRepo, mockMonitorMaintenanceService, mockCronGenerator, _, _, mockValidator := createTestService()
+	requireApproval(service)
+
+	dto := createTestCreateUpdateDto()
+	dto.ApprovalStatus = ApprovalStatusApproved
+	mockValidator.On("ValidateCronAndDuration", mock.AnythingOfType("*utils.ValidationParams")).Return(nil)
+	mockCronGenerator.On("GenerateCronExpression", dto.Strategy, mock.AnythingOfType("*utils.CronParams")).Return(nil, nil)
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(d *CreateUpdateDto) bool {
+		return d.ApprovalStatus == ApprovalStatusPending
+	})).Return(createTestModel(), nil)
+	mockMonitorMaintenanceService.On("SetMonitors", mock.Anything, "test-id", dto.MonitorIds).Return(nil)
+
+	_, err := service.Create(context.Background(), dto)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestServiceImpl_UpdateFull_ResetsApproval(t *testing.T) {
+	service, mockRepo, mockMonitorMaintenanceService, mockCronGenerator, _, _, mockValidator := createTestService()
+
+	approver := "user-2"
+	current := createTestModel()
+	current.ApprovalStatus = ApprovalStatusApproved
+	current.ApprovedBy = &approver
+	pending := createTestModel()
+	pending.ApprovalStatus = ApprovalStatusPending
+
+	dto := createTestCreateUpdateDto()
+	dto.RequestedBy = "user-1"
+	mockValidator.On("ValidateCronAndDuration", mock.AnythingOfType("*utils.ValidationParams")).Return(nil)
+	mockCronGenerator.On("GenerateCronExpression", dto.Strategy, mock.AnythingOfType("*utils.CronParams")).Return(nil, nil)
+	mockRepo.On("FindByID", mock.Anything, "test-id").Return(current, nil)
+	mockRepo.On("UpdateFull", mock.Anything, "test-id", dto).Return(current, nil)
+	mockRepo.On("ResetApproval", mock.Anything, "test-id", "user-1").Return(pending, nil)
+	mockMonitorMaintenanceService.On("SetMonitors", mock.Anything, "test-id", dto.MonitorIds).Return(nil)
+
+	result, err := service.UpdateFull(context.Background(), "test-id", dto)
+
+	assert.NoError(t, err)
+	assert.Equal(t, ApprovalStatusPending, result.ApprovalStatus)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestServiceImpl_UpdatePartial_ResetsApprovalWhenRequired(t *testing.T) {
+	service, mockRepo, _, _, _, mockTimeUtils, mockValidator := createTestService()
+	requireApproval(service)
+
+	title := "Updated Title"
+	dto := &PartialUpdateDto{Title: &title, RequestedBy: "user-1"}
+	current := createTestModel()
+	current.ApprovalStatus = ApprovalStatusApproved
+	pending := createTestModel()
+	pending.ApprovalStatus = ApprovalStatusPending
+
+	mockRepo.On("FindByID", mock.Anything, "test-id").Return(current, nil)
+	mockTimeUtils.On("CalculateDurationFromTimes", *current.StartTime, *current.EndTime).Return(480, nil)
+	mockValidator.On("ValidateCronAndDuration", mock.AnythingOfType("*utils.ValidationParams")).Return(nil)
+	mockRepo.On("UpdatePartial", mock.Anything, "test-id", dto).Return(current, nil)
+	mockRepo.On("ResetApproval", mock.Anything, "test-id", "user-1").Return(pending, nil)
+
+	result, err := service.UpdatePartial(context.Background(), "test-id", dto)
+
+	assert.NoError(t, err)
+	assert.Equal(t, ApprovalStatusPending, result.ApprovalStatus)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestServiceImpl_Create_DefaultsToApproved(t *testing.T) {
+	service, mockRepo, mockMonitorMaintenanceService, mockCronGenerator, _, _, mockValidator := createTestService()
+
+	dto := createTestCreateUpdateDto()
+	mockValidator.On("ValidateCronAndDuration", mock.AnythingOfType("*utils.ValidationParams")).Return(nil)
+	mockCronGenerator.On("GenerateCronExpression", dto.Strategy, mock.AnythingOfType("*utils.CronParams")).Return(nil, nil)
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(d *CreateUpdateDto) bool {
+		return d.ApprovalStatus == ApprovalStatusApproved
+	})).Return(createTestModel(), nil)
+	mockMonitorMaintenanceService.On("SetMonitors", mock.Anything, "test-id", dto.MonitorIds).Return(nil)
+
+	_, err := service.Create(context.Background(), dto)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestServiceImpl_IsUnderMaintenance_RequiresApproval(t *testing.T) {
+	service, _, _, _, _, _, _ := createTestService()
+
+	maintenance := createTestModel()
+	maintenance.Strategy = "manual"
+	maintenance.Active = true
+
+	// A pending window does not suppress alerts
+	maintenance.ApprovalStatus = ApprovalStatusPending
+	result, err := service.IsUnderMaintenance(context.Background(), maintenance)
+	assert.NoError(t, err)
+	assert.False(t, result)
+
+	// Once approved it takes effect
+	maintenance.ApprovalStatus = ApprovalStatusApproved
+	result, err = service.IsUnderMaintenance(context.Background(), maintenance)
+	assert.NoError(t, err)
+	assert.True(t, result)
+
+	// A rejected window never takes effect
+	maintenance.ApprovalStatus = ApprovalStatusRejected
+	result, err = service.IsUnderMaintenance(context.Background(), maintenance)
+	assert.NoError(t, err)
+	assert.False(t, result)
+}
+
 // Test IsUnderMaintenance method
 func TestServiceImpl_IsUnderMaintenance_ManualStrategy(t *testing.T) {
 	service, _, _, _, _, _, _ := createTestService()
@@ -633,6 +884,74 @@ func TestServiceImpl_IsUnderMaintenance_CronStrategy(t *testing.T) {
 	mockTimeWindowChecker.AssertExpectations(t)
 }
 
+func TestServiceImpl_IsUnderMaintenanceAt_WindowEdges(t *testing.T) {
+	service, _, _, _, _, _, _ := createTestService()
+	service.timeUtils = utils.NewTimeUtils()
+	service.timeWindowChecker = utils.NewTimeWindowChecker(zap.NewNop().Sugar())
+
+	maintenance := createTestModel()
+	maintenance.Strategy = "single"
+	startDateTime := "2024-01-01T09:00"
+	endDateTime := "2024-01-01T17:00"
+	maintenance.StartDateTime = &startDateTime
+	maintenance.EndDateTime = &endDateTime
+
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 1, 1, 17, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		at       time.Time
+		expected bool
+	}{
+		{"just before start", start.Add(-time.Second), false},
+		{"exactly at start", start, true},
+		{"just after start", start.Add(time.Second), true},
+		{"just before end", end.Add(-time.Second), true},
+		{"exactly at end", end, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := service.IsUnderMaintenanceAt(context.Background(), maintenance, tt.at)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestServiceImpl_IsUnderMaintenanceAt_CronWindowEdges(t *testing.T) {
+	service, _, _, _, _, _, _ := createTestService()
+	service.timeUtils = utils.NewTimeUtils()
+	service.timeWindowChecker = utils.NewTimeWindowChecker(zap.NewNop().Sugar())
+
+	maintenance := createTestModel()
+	maintenance.Strategy = "cron"
+	startDateTime := "2024-01-01T00:00"
+	endDateTime := "2024-12-31T23:59"
+	cron := "0 9 * * *"
+	duration := 60
+	maintenance.StartDateTime = &startDateTime
+	maintenance.EndDateTime = &endDateTime
+	maintenance.Cron = &cron
+	maintenance.Duration = &duration
+
+	start := time.Date(2024, 3, 5, 9, 0, 0, 0, time.UTC)
+
+	before, err := service.IsUnderMaintenanceAt(context.Background(), maintenance, start.Add(-time.Second))
+	assert.NoError(t, err)
+	assert.False(t, before)
+
+	after, err := service.IsUnderMaintenanceAt(context.Background(), maintenance, start.Add(time.Second))
+	assert.NoError(t, err)
+	assert.True(t, after)
+
+	atEnd, err := service.IsUnderMaintenanceAt(context.Background(), maintenance, start.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.False(t, atEnd)
+}
+
 // Test GetMaintenancesByMonitorID method
 func TestServiceImpl_GetMaintenancesByMonitorID_Success(t *testing.T) {
 	service, mockRepo, _, _, _, _, _ := createTestService()
@@ -771,3 +1090,243 @@ func TestServiceImpl_IsUnderMaintenance_UnknownStrategy(t *testing.T) {
 	mockTimeUtils.AssertExpectations(t)
 	mockTimeWindowChecker.AssertExpectations(t)
 }
+
+func TestServiceImpl_Create_InvalidDateTimeWindow(t *testing.T) {
+	end := "2024-01-01T08:00"
+	invalid := "2024-01-01 09:00"
+
+	tests := []struct {
+		name   string
+		modify func(dto *CreateUpdateDto)
+	}{
+		{"end before start", func(dto *CreateUpdateDto) { dto.EndDateTime = &end }},
+		{"end equal to start", func(dto *CreateUpdateDto) { dto.EndDateTime = dto.StartDateTime }},
+		{"unparsable start", func(dto *CreateUpdateDto) { dto.StartDateTime = &invalid }},
+		{"single without end", func(dto *CreateUpdateDto) { dto.EndDateTime = nil }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mockRepo, _, _, _, _, _ := createTestService()
+
+			dto := createTestCreateUpdateDto()
+			tt.modify(dto)
+
+			result, err := service.Create(context.Background(), dto)
+
+			assert.ErrorIs(t, err, ErrInvalidDateTimeWindow)
+			assert.Nil(t, result)
+			mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestServiceImpl_Create_RFC3339Window(t *testing.T) {
+	service, mockRepo, mockMonitorMaintenanceService, mockCronGenerator, _, _, mockValidator := createTestService()
+
+	dto := createTestCreateUpdateDto()
+	start := "2024-01-01T09:00:00+01:00"
+	end := "2024-01-01T09:30:00Z"
+	dto.StartDateTime = &start
+	dto.EndDateTime = &end
+
+	mockValidator.On("ValidateCronAndDuration", mock.AnythingOfType("*utils.ValidationParams")).Return(nil)
+	mockCronGenerator.On("GenerateCronExpression", dto.Strategy, mock.AnythingOfType("*utils.CronParams")).Return(nil, nil)
+	mockRepo.On("Create", mock.Anything, dto).Return(createTestModel(), nil)
+	mockMonitorMaintenanceService.On("SetMonitors", mock.Anything, "test-id", dto.MonitorIds).Return(nil)
+
+	_, err := service.Create(context.Background(), dto)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestServiceImpl_UpdatePartial_ValidatesMergedWindow(t *testing.T) {
+	service, mockRepo, _, _, _, _, _ := createTestService()
+
+	current := createTestModel()
+	start := "2024-01-01T09:00"
+	end := "2024-01-01T17:00"
+	current.StartDateTime = &start
+	current.EndDateTime = &end
+
+	// Only the start changes, past the current end
+	newStart := "2024-01-02T09:00"
+	mockRepo.On("FindByID", mock.Anything, "test-id").Return(current, nil)
+
+	result, err := service.UpdatePartial(context.Background(), "test-id", &PartialUpdateDto{StartDateTime: &newStart})
+
+	assert.ErrorIs(t, err, ErrInvalidDateTimeWindow)
+	assert.Nil(t, result)
+	mockRepo.AssertNotCalled(t, "UpdatePartial", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestServiceImpl_IsUnderMaintenanceAt_SingleWindowInTimezone(t *testing.T) {
+	service, _, _, _, _, _, _ := createTestService()
+	service.timeUtils = utils.NewTimeUtils()
+	service.timeWindowChecker = utils.NewTimeWindowChecker(zap.NewNop().Sugar())
+
+	maintenance := createTestModel()
+	maintenance.Strategy = "single"
+	timezone := "America/New_York"
+	startDateTime := "2024-01-01T09:00"
+	endDateTime := "2024-01-01T10:00:00-05:00"
+	maintenance.Timezone = &timezone
+	maintenance.StartDateTime = &startDateTime
+	maintenance.EndDateTime = &endDateTime
+
+	// 09:00-10:00 in New York is 14:00-15:00 UTC
+	tests := []struct {
+		at       time.Time
+		expected bool
+	}{
+		{time.Date(2024, 1, 1, 9, 30, 0, 0, time.UTC), false},
+		{time.Date(2024, 1, 1, 14, 0, 0, 0, time.UTC), true},
+		{time.Date(2024, 1, 1, 14, 59, 0, 0, time.UTC), true},
+		{time.Date(2024, 1, 1, 15, 0, 0, 0, time.UTC), false},
+	}
+	for _, tt := range tests {
+		result, err := service.IsUnderMaintenanceAt(context.Background(), maintenance, tt.at)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expected, result, tt.at)
+	}
+
+	invalid := "not a date"
+	maintenance.EndDateTime = &invalid
+	_, err := service.IsUnderMaintenanceAt(context.Background(), maintenance, time.Date(2024, 1, 1, 14, 0, 0, 0, time.UTC))
+	assert.Error(t, err)
+}
+
+func TestServiceImpl_Create_AutoEndRequiresOneShot(t *testing.T) {
+	service, mockRepo, _, _, _, _, _ := createTestService()
+
+	dto := createTestCreateUpdateDto()
+	dto.Strategy = "recurring-weekday"
+	dto.AutoEndOnRecovery = true
+
+	_, err := service.Create(context.Background(), dto)
+
+	assert.ErrorIs(t, err, ErrAutoEndRequiresOneShot)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestServiceImpl_EndOnRecovery(t *testing.T) {
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	startDateTime := "2024-01-01T09:00"
+
+	newMaintenance := func() *Model {
+		m := createTestModel()
+		m.Strategy = "single"
+		m.Active = true
+		m.StartDateTime = &startDateTime
+		m.Timezone = nil
+		m.AutoEndOnRecovery = true
+		m.RecoveryChecks = 2
+		return m
+	}
+	beats := func(statuses ...shared.MonitorStatus) []*shared.HeartBeatModel {
+		heartbeats := make([]*shared.HeartBeatModel, len(statuses))
+		for i, status := range statuses {
+			// Newest first, one minute apart
+			heartbeats[i] = &shared.HeartBeatModel{Status: status, Time: start.Add(time.Duration(10-i) * time.Minute)}
+		}
+		return heartbeats
+	}
+	setup := func() (*ServiceImpl, *MockRepository) {
+		service, mockRepo, _, _, _, mockTimeUtils, _ := createTestService()
+		mockTimeUtils.On("GetDefaultTimezone").Return("UTC")
+		mockTimeUtils.On("LoadTimezone", "UTC").Return(time.UTC)
+		return service, mockRepo
+	}
+
+	t.Run("recovery ends the window", func(t *testing.T) {
+		service, mockRepo := setup()
+		audit := &auditRepository{}
+		service.auditLogService = audit_log.NewService(audit, &config.Config{AuditLogEnabled: true}, zap.NewNop().Sugar())
+		maintenance := newMaintenance()
+		mockRepo.On("SetActive", mock.Anything, maintenance.ID, false).Return(maintenance, nil)
+
+		ended, err := service.EndOnRecovery(context.Background(), maintenance, map[string][]*shared.HeartBeatModel{
+			"mon-1": beats(shared.MonitorStatusUp, shared.MonitorStatusUp, shared.MonitorStatusDown),
+		})
+
+		assert.NoError(t, err)
+		assert.True(t, ended)
+		assert.False(t, maintenance.Active)
+		mockRepo.AssertExpectations(t)
+
+		if assert.Len(t, audit.entries, 1) {
+			entry := audit.entries[0]
+			assert.Equal(t, audit_log.EntityMaintenance, entry.EntityType)
+			assert.Equal(t, maintenance.ID, entry.EntityID)
+			assert.Equal(t, audit_log.ActionUpdate, entry.Action)
+			assert.Equal(t, "system", entry.ActorType)
+			assert.Equal(t, map[string]audit_log.FieldChange{"active": {Old: true, New: false}}, entry.Changes)
+		}
+	})
+
+	t.Run("continued outage keeps the window active", func(t *testing.T) {
+		service, mockRepo := setup()
+		maintenance := newMaintenance()
+
+		ended, err := service.EndOnRecovery(context.Background(), maintenance, map[string][]*shared.HeartBeatModel{
+			"mon-1": beats(shared.MonitorStatusUp, shared.MonitorStatusDown),
+		})
+
+		assert.NoError(t, err)
+		assert.False(t, ended)
+		assert.True(t, maintenance.Active)
+		mockRepo.AssertNotCalled(t, "SetActive", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("every monitor of the window has to recover", func(t *testing.T) {
+		service, mockRepo := setup()
+
+		ended, err := service.EndOnRecovery(context.Background(), newMaintenance(), map[string][]*shared.HeartBeatModel{
+			"mon-1": beats(shared.MonitorStatusUp, shared.MonitorStatusUp),
+			"mon-2": beats(shared.MonitorStatusDown, shared.MonitorStatusDown),
+		})
+
+		assert.NoError(t, err)
+		assert.False(t, ended)
+		mockRepo.AssertNotCalled(t, "SetActive", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("checks before the window don't count", func(t *testing.T) {
+		service, mockRepo := setup()
+		heartbeats := beats(shared.MonitorStatusUp, shared.MonitorStatusUp)
+		heartbeats[1].Time = start.Add(-time.Minute)
+
+		ended, err := service.EndOnRecovery(context.Background(), newMaintenance(), map[string][]*shared.HeartBeatModel{"mon-1": heartbeats})
+
+		assert.NoError(t, err)
+		assert.False(t, ended)
+		mockRepo.AssertNotCalled(t, "SetActive", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("defaults to DefaultRecoveryChecks", func(t *testing.T) {
+		service, _ := setup()
+		maintenance := newMaintenance()
+		maintenance.RecoveryChecks = 0
+
+		ended, err := service.EndOnRecovery(context.Background(), maintenance, map[string][]*shared.HeartBeatModel{
+			"mon-1": beats(shared.MonitorStatusUp, shared.MonitorStatusUp),
+		})
+
+		assert.NoError(t, err)
+		assert.False(t, ended, "2 up checks are fewer than the default")
+	})
+
+	t.Run("windows without the option stay", func(t *testing.T) {
+		service, _ := setup()
+		maintenance := newMaintenance()
+		maintenance.AutoEndOnRecovery = false
+
+		ended, err := service.EndOnRecovery(context.Background(), maintenance, map[string][]*shared.HeartBeatModel{
+			"mon-1": beats(shared.MonitorStatusUp, shared.MonitorStatusUp),
+		})
+
+		assert.NoError(t, err)
+		assert.False(t, ended)
+	})
+}
@@ -12,23 +12,30 @@ import (
 type sqlModel struct {
 	bun.BaseModel `bun:"table:maintenances,alias:m"`
 
-	ID            string    `bun:"id,pk"`
-	Title         string    `bun:"title,notnull"`
-	Description   string    `bun:"description"`
-	Active        bool      `bun:"active,notnull,default:true"`
-	Strategy      string    `bun:"strategy,notnull"`
-	StartDateTime *string   `bun:"start_date_time"`
-	EndDateTime   *string   `bun:"end_date_time"`
-	StartTime     *string   `bun:"start_time"`
-	EndTime       *string   `bun:"end_time"`
-	Weekdays      string    `bun:"weekdays"`      // Store as JSON string for compatibility
-	DaysOfMonth   string    `bun:"days_of_month"` // Store as JSON string for compatibility
-	IntervalDay   *int      `bun:"interval_day"`
-	Cron          *string   `bun:"cron"`
-	Timezone      *string   `bun:"timezone"`
-	Duration      *int      `bun:"duration"`
-	CreatedAt     time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
-	UpdatedAt     time.Time `bun:"updated_at,nullzero,notnull,default:current_timestamp"`
+	ID                string    `bun:"id,pk"`
+	Title             string    `bun:"title,notnull"`
+	Description       string    `bun:"description"`
+	Active            bool      `bun:"active,notnull,default:true"`
+	Strategy          string    `bun:"strategy,notnull"`
+	StartDateTime     *string   `bun:"start_date_time"`
+	EndDateTime       *string   `bun:"end_date_time"`
+	StartTime         *string   `bun:"start_time"`
+	EndTime           *string   `bun:"end_time"`
+	Weekdays          string    `bun:"weekdays"`      // Store as JSON string for compatibility
+	DaysOfMonth       string    `bun:"days_of_month"` // Store as JSON string for compatibility
+	IntervalDay       *int      `bun:"interval_day"`
+	Cron              *string   `bun:"cron"`
+	Timezone          *string   `bun:"timezone"`
+	Duration          *int      `bun:"duration"`
+	SuppressChecks    bool      `bun:"suppress_checks,notnull,default:false"`
+	AutoEndOnRecovery bool      `bun:"auto_end_on_recovery,notnull,default:false"`
+	RecoveryChecks    int       `bun:"recovery_checks,notnull,default:0"`
+	BannerMessage     *string   `bun:"banner_message"`
+	ApprovalStatus    string    `bun:"approval_status,notnull,default:'approved'"`
+	ApprovedBy        *string   `bun:"approved_by"`
+	RequestedBy       *string   `bun:"requested_by"`
+	CreatedAt         time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+	UpdatedAt         time.Time `bun:"updated_at,nullzero,notnull,default:current_timestamp"`
 }
 
 func toDomainModelFromSQL(sm *sqlModel) *Model {
@@ -44,23 +51,30 @@ func toDomainModelFromSQL(sm *sqlModel) *Model {
 	}
 
 	return &Model{
-		ID:            sm.ID,
-		Title:         sm.Title,
-		Description:   sm.Description,
-		Active:        sm.Active,
-		Strategy:      sm.Strategy,
-		StartDateTime: sm.StartDateTime,
-		EndDateTime:   sm.EndDateTime,
-		StartTime:     sm.StartTime,
-		EndTime:       sm.EndTime,
-		Weekdays:      weekdays,
-		DaysOfMonth:   daysOfMonth,
-		IntervalDay:   sm.IntervalDay,
-		Cron:          sm.Cron,
-		Timezone:      sm.Timezone,
-		Duration:      sm.Duration,
-		CreatedAt:     sm.CreatedAt,
-		UpdatedAt:     sm.UpdatedAt,
+		ID:                sm.ID,
+		Title:             sm.Title,
+		Description:       sm.Description,
+		Active:            sm.Active,
+		Strategy:          sm.Strategy,
+		StartDateTime:     sm.StartDateTime,
+		EndDateTime:       sm.EndDateTime,
+		StartTime:         sm.StartTime,
+		EndTime:           sm.EndTime,
+		Weekdays:          weekdays,
+		DaysOfMonth:       daysOfMonth,
+		IntervalDay:       sm.IntervalDay,
+		Cron:              sm.Cron,
+		Timezone:          sm.Timezone,
+		Duration:          sm.Duration,
+		SuppressChecks:    sm.SuppressChecks,
+		AutoEndOnRecovery: sm.AutoEndOnRecovery,
+		RecoveryChecks:    sm.RecoveryChecks,
+		BannerMessage:     sm.BannerMessage,
+		ApprovalStatus:    sm.ApprovalStatus,
+		ApprovedBy:        sm.ApprovedBy,
+		RequestedBy:       sm.RequestedBy,
+		CreatedAt:         sm.CreatedAt,
+		UpdatedAt:         sm.UpdatedAt,
 	}
 }
 
@@ -78,23 +92,29 @@ func (r *SQLRepositoryImpl) Create(ctx context.Context, entity *CreateUpdateDto)
 	daysOfMonthJSON, _ := json.Marshal(entity.DaysOfMonth)
 
 	sm := &sqlModel{
-		ID:            uuid.New().String(),
-		Title:         entity.Title,
-		Description:   entity.Description,
-		Active:        entity.Active,
-		Strategy:      entity.Strategy,
-		StartDateTime: entity.StartDateTime,
-		EndDateTime:   entity.EndDateTime,
-		StartTime:     entity.StartTime,
-		EndTime:       entity.EndTime,
-		Weekdays:      string(weekdaysJSON),
-		DaysOfMonth:   string(daysOfMonthJSON),
-		IntervalDay:   entity.IntervalDay,
-		Cron:          entity.Cron,
-		Timezone:      entity.Timezone,
-		Duration:      entity.Duration,
-		CreatedAt:     time.Now(),
-		UpdatedAt:     time.Now(),
+		ID:                uuid.New().String(),
+		Title:             entity.Title,
+		Description:       entity.Description,
+		Active:            entity.Active,
+		Strategy:          entity.Strategy,
+		StartDateTime:     entity.StartDateTime,
+		EndDateTime:       entity.EndDateTime,
+		StartTime:         entity.StartTime,
+		EndTime:           entity.EndTime,
+		Weekdays:          string(weekdaysJSON),
+		DaysOfMonth:       string(daysOfMonthJSON),
+		IntervalDay:       entity.IntervalDay,
+		Cron:              entity.Cron,
+		Timezone:          entity.Timezone,
+		Duration:          entity.Duration,
+		SuppressChecks:    entity.SuppressChecks,
+		AutoEndOnRecovery: entity.AutoEndOnRecovery,
+		RecoveryChecks:    entity.RecoveryChecks,
+		BannerMessage:     entity.BannerMessage,
+		ApprovalStatus:    entity.ApprovalStatus,
+		RequestedBy:       requestedBy(entity.RequestedBy),
+		CreatedAt:         time.Now(),
+		UpdatedAt:         time.Now(),
 	}
 
 	_, err := r.db.NewInsert().Model(sm).Returning("*").Exec(ctx)
@@ -151,22 +171,26 @@ func (r *SQLRepositoryImpl) UpdateFull(ctx context.Context, id string, entity *C
 	daysOfMonthJSON, _ := json.Marshal(entity.DaysOfMonth)
 
 	sm := &sqlModel{
-		ID:            id,
-		Title:         entity.Title,
-		Description:   entity.Description,
-		Active:        entity.Active,
-		Strategy:      entity.Strategy,
-		StartDateTime: entity.StartDateTime,
-		EndDateTime:   entity.EndDateTime,
-		StartTime:     entity.StartTime,
-		EndTime:       entity.EndTime,
-		Weekdays:      string(weekdaysJSON),
-		DaysOfMonth:   string(daysOfMonthJSON),
-		IntervalDay:   entity.IntervalDay,
-		Cron:          entity.Cron,
-		Timezone:      entity.Timezone,
-		Duration:      entity.Duration,
-		UpdatedAt:     time.Now(),
+		ID:                id,
+		Title:             entity.Title,
+		Description:       entity.Description,
+		Active:            entity.Active,
+		Strategy:          entity.Strategy,
+		StartDateTime:     entity.StartDateTime,
+		EndDateTime:       entity.EndDateTime,
+		StartTime:         entity.StartTime,
+		EndTime:           entity.EndTime,
+		Weekdays:          string(weekdaysJSON),
+		DaysOfMonth:       string(daysOfMonthJSON),
+		IntervalDay:       entity.IntervalDay,
+		Cron:              entity.Cron,
+		Timezone:          entity.Timezone,
+		Duration:          entity.Duration,
+		SuppressChecks:    entity.SuppressChecks,
+		AutoEndOnRecovery: entity.AutoEndOnRecovery,
+		RecoveryChecks:    entity.RecoveryChecks,
+		BannerMessage:     entity.BannerMessage,
+		UpdatedAt:         time.Now(),
 	}
 
 	_, err := r.db.NewUpdate().
@@ -244,6 +268,22 @@ func (r *SQLRepositoryImpl) UpdatePartial(ctx context.Context, id string, entity
 		query = query.Set("duration = ?", *entity.Duration)
 		hasUpdates = true
 	}
+	if entity.SuppressChecks != nil {
+		query = query.Set("suppress_checks = ?", *entity.SuppressChecks)
+		hasUpdates = true
+	}
+	if entity.AutoEndOnRecovery != nil {
+		query = query.Set("auto_end_on_recovery = ?", *entity.AutoEndOnRecovery)
+		hasUpdates = true
+	}
+	if entity.RecoveryChecks != nil {
+		query = query.Set("recovery_checks = ?", *entity.RecoveryChecks)
+		hasUpdates = true
+	}
+	if entity.BannerMessage != nil {
+		query = query.Set("banner_message = ?", *entity.BannerMessage)
+		hasUpdates = true
+	}
 
 	if !hasUpdates {
 		return r.FindByID(ctx, id)
@@ -279,6 +319,37 @@ func (r *SQLRepositoryImpl) SetActive(ctx context.Context, id string, active boo
 	return r.FindByID(ctx, id)
 }
 
+func (r *SQLRepositoryImpl) SetApproval(ctx context.Context, id string, status string, approvedBy string) (*Model, error) {
+	_, err := r.db.NewUpdate().
+		Model((*sqlModel)(nil)).
+		Set("approval_status = ?", status).
+		Set("approved_by = ?", approvedBy).
+		Set("updated_at = ?", time.Now()).
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.FindByID(ctx, id)
+}
+
+func (r *SQLRepositoryImpl) ResetApproval(ctx context.Context, id string, requestedBy string) (*Model, error) {
+	_, err := r.db.NewUpdate().
+		Model((*sqlModel)(nil)).
+		Set("approval_status = ?", ApprovalStatusPending).
+		Set("approved_by = NULL").
+		Set("requested_by = ?", requestedBy).
+		Set("updated_at = ?", time.Now()).
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.FindByID(ctx, id)
+}
+
 // GetMaintenancesByMonitorID returns all active maintenances for a given monitor_id
 func (r *SQLRepositoryImpl) GetMaintenancesByMonitorID(ctx context.Context, monitorID string) ([]*Model, error) {
 	var sms []*sqlModel
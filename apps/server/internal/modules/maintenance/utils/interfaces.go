@@ -29,4 +29,5 @@ type TimeUtilsInterface interface {
 // ValidatorInterface defines the interface for validation
 type ValidatorInterface interface {
 	ValidateCronAndDuration(params *ValidationParams) error
+	ValidateDateTimeWindow(params *ValidationParams) error
 }
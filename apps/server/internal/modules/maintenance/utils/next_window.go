@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"errors"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Window is one occurrence of a maintenance schedule, from Start up to but excluding End
+type Window struct {
+	Start time.Time
+	End   time.Time
+}
+
+// NextWindow returns the window of a maintenance schedule in progress at after or, when
+// there is none, the next one to start. Windows are limited to the StartDateTime to
+// EndDateTime period like IsInDateTimePeriod does, nil means no window is left. Only
+// single, recurring-interval and cron based strategies have windows.
+func NextWindow(strategy string, params *TimeWindowParams, after time.Time, loc *time.Location) (*Window, error) {
+	if params.StartDateTime == nil || params.EndDateTime == nil {
+		return nil, errors.New("maintenance has no start or end date time")
+	}
+	periodStart, err := ParseDateTime(*params.StartDateTime, loc)
+	if err != nil {
+		return nil, err
+	}
+	periodEnd, err := ParseDateTime(*params.EndDateTime, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	after = after.In(loc)
+	if !after.Before(periodEnd) {
+		return nil, nil
+	}
+
+	// Occurrences overlapping the start of the period are only in effect from there on
+	from := after
+	if from.Before(periodStart) {
+		from = periodStart
+	}
+
+	var window *Window
+	switch {
+	case strategy == "single":
+		window = &Window{Start: periodStart, End: periodEnd}
+	case strategy == "recurring-interval":
+		window, err = nextIntervalWindow(params, periodStart, from, loc)
+	case params.Cron != nil && *params.Cron != "":
+		window, err = nextCronWindow(params, from)
+	}
+	if err != nil || window == nil || !window.Start.Before(periodEnd) {
+		return nil, err
+	}
+
+	if window.Start.Before(periodStart) {
+		window.Start = periodStart
+	}
+	if window.End.After(periodEnd) {
+		window.End = periodEnd
+	}
+	return window, nil
+}
+
+// nextCronWindow returns the first cron window ending after from
+func nextCronWindow(params *TimeWindowParams, from time.Time) (*Window, error) {
+	if params.Duration == nil || *params.Duration <= 0 {
+		return nil, nil
+	}
+
+	parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+	schedule, err := parser.Parse(*params.Cron)
+	if err != nil {
+		return nil, err
+	}
+
+	// The first run after from-duration is the one in progress at from, if any
+	duration := time.Duration(*params.Duration) * time.Minute
+	start := schedule.Next(from.Add(-duration))
+	if start.IsZero() {
+		return nil, nil
+	}
+	return &Window{Start: start, End: start.Add(duration)}, nil
+}
+
+// nextIntervalWindow returns the first daily window of a recurring-interval schedule
+// ending after from. Maintenance days are counted from periodStart the same way as in
+// IsInRecurringIntervalWindow.
+func nextIntervalWindow(params *TimeWindowParams, periodStart, from time.Time, loc *time.Location) (*Window, error) {
+	if params.IntervalDay == nil || *params.IntervalDay <= 0 {
+		return nil, errors.New("maintenance has no valid interval day")
+	}
+	if params.StartTime == nil || params.EndTime == nil {
+		return nil, errors.New("maintenance has no start or end time for daily window")
+	}
+	startTime, err := time.Parse("15:04", *params.StartTime)
+	if err != nil {
+		return nil, errors.New("invalid start time format")
+	}
+	endTime, err := time.Parse("15:04", *params.EndTime)
+	if err != nil {
+		return nil, errors.New("invalid end time format")
+	}
+
+	// Starting the day before from catches a cross-day window still in progress
+	interval := *params.IntervalDay
+	for day := -1; day <= interval; day++ {
+		start := time.Date(from.Year(), from.Month(), from.Day()+day, startTime.Hour(), startTime.Minute(), 0, 0, loc)
+		end := time.Date(from.Year(), from.Month(), from.Day()+day, endTime.Hour(), endTime.Minute(), 0, 0, loc)
+		if endTime.Before(startTime) {
+			end = end.AddDate(0, 0, 1)
+		}
+		if !end.After(from) || !end.After(start) {
+			continue
+		}
+
+		daysSinceStart := int(start.Sub(periodStart).Hours() / 24)
+		if daysSinceStart < 0 || daysSinceStart%interval != 0 {
+			continue
+		}
+		return &Window{Start: start, End: end}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextWindow(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	str := func(s string) *string { return &s }
+	num := func(n int) *int { return &n }
+	at := func(value string) time.Time {
+		parsed, err := time.ParseInLocation("2006-01-02T15:04", value, loc)
+		require.NoError(t, err)
+		return parsed
+	}
+	window := func(start, end string) *Window {
+		return &Window{Start: at(start), End: at(end)}
+	}
+
+	single := &TimeWindowParams{StartDateTime: str("2025-10-10T22:00"), EndDateTime: str("2025-10-11T02:00")}
+	// Every other day from 23:00 to 01:00
+	interval := &TimeWindowParams{
+		StartDateTime: str("2025-10-01T00:00"), EndDateTime: str("2025-10-20T00:00"),
+		StartTime: str("23:00"), EndTime: str("01:00"), IntervalDay: num(2),
+	}
+	// Mondays from 02:00 for two hours
+	weekly := &TimeWindowParams{
+		StartDateTime: str("2025-10-01T00:00"), EndDateTime: str("2025-12-01T00:00"),
+		Cron: str("0 2 * * 1"), Duration: num(120),
+	}
+
+	tests := []struct {
+		name     string
+		strategy string
+		params   *TimeWindowParams
+		after    time.Time
+		expected *Window
+	}{
+		{"single before it starts", "single", single, at("2025-10-01T12:00"), window("2025-10-10T22:00", "2025-10-11T02:00")},
+		{"single in progress", "single", single, at("2025-10-11T01:00"), window("2025-10-10T22:00", "2025-10-11T02:00")},
+		{"single over", "single", single, at("2025-10-11T02:00"), nil},
+		{"interval on a maintenance day", "recurring-interval", interval, at("2025-10-03T12:00"), window("2025-10-03T23:00", "2025-10-04T01:00")},
+		{"interval skips the days in between", "recurring-interval", interval, at("2025-10-04T12:00"), window("2025-10-05T23:00", "2025-10-06T01:00")},
+		{"interval in progress across midnight", "recurring-interval", interval, at("2025-10-04T00:30"), window("2025-10-03T23:00", "2025-10-04T01:00")},
+		{"interval cut at the end of the period", "recurring-interval", interval, at("2025-10-19T12:00"), window("2025-10-19T23:00", "2025-10-20T00:00")},
+		{"interval over", "recurring-interval", interval, at("2025-10-20T00:00"), nil},
+		{"cron next occurrence", "recurring-weekday", weekly, at("2025-10-08T12:00"), window("2025-10-13T02:00", "2025-10-13T04:00")},
+		{"cron in progress", "cron", weekly, at("2025-10-13T03:59"), window("2025-10-13T02:00", "2025-10-13T04:00")},
+		{"cron before the period", "cron", weekly, at("2025-09-01T00:00"), window("2025-10-06T02:00", "2025-10-06T04:00")},
+		{"manual has no window", "manual", single, at("2025-10-01T12:00"), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NextWindow(tt.strategy, tt.params, tt.after, loc)
+			require.NoError(t, err)
+			if tt.expected == nil {
+				assert.Nil(t, got)
+				return
+			}
+			require.NotNil(t, got)
+			assert.True(t, tt.expected.Start.Equal(got.Start), "start %s, expected %s", got.Start, tt.expected.Start)
+			assert.True(t, tt.expected.End.Equal(got.End), "end %s, expected %s", got.End, tt.expected.End)
+		})
+	}
+
+	t.Run("missing period", func(t *testing.T) {
+		_, err := NextWindow("single", &TimeWindowParams{}, at("2025-10-01T12:00"), loc)
+		assert.Error(t, err)
+	})
+}
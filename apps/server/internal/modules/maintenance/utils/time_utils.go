@@ -2,6 +2,8 @@ package utils
 
 import (
 	"errors"
+	"fmt"
+	"peekaping/internal/config"
 	"time"
 )
 
@@ -45,10 +47,27 @@ func (tu *TimeUtils) CalculateDurationFromTimes(startTime, endTime string) (int,
 	return duration, nil
 }
 
+// dateTimeLayout is the wall-clock format of maintenance windows, read in the maintenance's
+// timezone. RFC3339 values carry their own offset.
+const dateTimeLayout = "2006-01-02T15:04"
+
+// ParseDateTime parses the start or end of a maintenance window, either a RFC3339 instant
+// or a wall-clock "2006-01-02T15:04" in loc
+func ParseDateTime(value string, loc *time.Location) (time.Time, error) {
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed.In(loc), nil
+	}
+	parsed, err := time.ParseInLocation(dateTimeLayout, value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date time %q, expected RFC3339 or YYYY-MM-DDTHH:MM", value)
+	}
+	return parsed, nil
+}
+
 // LoadTimezone loads a timezone location, with fallback to UTC if invalid
 func (tu *TimeUtils) LoadTimezone(timezone string) *time.Location {
 	if timezone == "SAME_AS_SERVER" {
-		timezone = time.Now().Location().String()
+		return config.Location()
 	}
 
 	loc, err := time.LoadLocation(timezone)
@@ -4,6 +4,8 @@ import (
 	"testing"
 	"time"
 
+	"peekaping/internal/config"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -89,7 +91,7 @@ func TestTimeUtils_LoadTimezone(t *testing.T) {
 		{
 			name:     "same as server",
 			timezone: "SAME_AS_SERVER",
-			expected: time.Now().Location().String(),
+			expected: config.Location().String(),
 		},
 		{
 			name:     "invalid timezone falls back to UTC",
@@ -200,3 +202,24 @@ func TestTimeUtils_IsCrossDayWindow(t *testing.T) {
 		})
 	}
 }
+
+func TestParseDateTime(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	assert.NoError(t, err)
+
+	// Wall-clock values are read in the given timezone
+	parsed, err := ParseDateTime("2024-06-01T09:00", berlin)
+	assert.NoError(t, err)
+	assert.True(t, parsed.Equal(time.Date(2024, 6, 1, 7, 0, 0, 0, time.UTC)))
+
+	// RFC3339 values keep their own offset
+	parsed, err = ParseDateTime("2024-06-01T09:00:00Z", berlin)
+	assert.NoError(t, err)
+	assert.True(t, parsed.Equal(time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)))
+	assert.Equal(t, berlin, parsed.Location())
+
+	_, err = ParseDateTime("2024-13-01T09:00", berlin)
+	assert.Error(t, err)
+	_, err = ParseDateTime("tomorrow", berlin)
+	assert.Error(t, err)
+}
@@ -2,7 +2,6 @@ package utils
 
 import (
 	"errors"
-	"fmt"
 	"time"
 
 	"github.com/robfig/cron/v3"
@@ -43,12 +42,17 @@ func (twc *TimeWindowChecker) IsInDateTimePeriod(params *TimeWindowParams, now t
 		return false, errors.New("maintenance has no start or end date time")
 	}
 
-	// Set database time to timezone from db without converting
-	startDateInTz := twc.convertToTimezone(*params.StartDateTime, loc)
-	endDateInTz := twc.convertToTimezone(*params.EndDateTime, loc)
+	startDateInTz, err := ParseDateTime(*params.StartDateTime, loc)
+	if err != nil {
+		return false, err
+	}
+	endDateInTz, err := ParseDateTime(*params.EndDateTime, loc)
+	if err != nil {
+		return false, err
+	}
 
-	// Check if we're within the date time window
-	return now.After(startDateInTz) && now.Before(endDateInTz), nil
+	// Windows are half-open: the start minute is inside, the end minute is not
+	return !now.Before(startDateInTz) && now.Before(endDateInTz), nil
 }
 
 // IsInCronMaintenanceWindow checks if the current time falls within a cron-based maintenance window
@@ -74,7 +78,6 @@ func (twc *TimeWindowChecker) IsInCronMaintenanceWindow(params *TimeWindowParams
 	searchStart := now.Add(-duration)
 
 	lastRun := schedule.Next(searchStart)
-	fmt.Println("lastRun", lastRun)
 	if lastRun.After(now) {
 		// The next run is in the future, so no run happened within the window
 		twc.logger.Debugf("lastRun is after now", now)
@@ -114,7 +117,10 @@ func (twc *TimeWindowChecker) IsInRecurringIntervalWindow(params *TimeWindowPara
 	}
 
 	// Convert start date to the maintenance timezone
-	startDateInTz := twc.convertToTimezone(*params.StartDateTime, loc)
+	startDateInTz, err := ParseDateTime(*params.StartDateTime, loc)
+	if err != nil {
+		return false, err
+	}
 
 	// Calculate days since the start date
 	daysSinceStart := int(now.Sub(startDateInTz).Hours() / 24)
@@ -136,7 +142,7 @@ func (twc *TimeWindowChecker) IsInRecurringIntervalWindow(params *TimeWindowPara
 		// For cross-day windows, we need to check two possible windows:
 		// 1. Window starting today: todayStart to tomorrow's endTime
 		todayEnd = todayEnd.Add(24 * time.Hour)
-		if now.After(todayStart) && now.Before(todayEnd) {
+		if !now.Before(todayStart) && now.Before(todayEnd) {
 			return true, nil
 		}
 
@@ -144,7 +150,7 @@ func (twc *TimeWindowChecker) IsInRecurringIntervalWindow(params *TimeWindowPara
 		yesterdayStart := todayStart.Add(-24 * time.Hour)
 		todayEndOriginal := time.Date(now.Year(), now.Month(), now.Day(),
 			endTime.Hour(), endTime.Minute(), 0, 0, loc)
-		if now.After(yesterdayStart) && now.Before(todayEndOriginal) {
+		if !now.Before(yesterdayStart) && now.Before(todayEndOriginal) {
 			return true, nil
 		}
 
@@ -152,7 +158,7 @@ func (twc *TimeWindowChecker) IsInRecurringIntervalWindow(params *TimeWindowPara
 	}
 
 	// Check if we're within the daily time window
-	return now.After(todayStart) && now.Before(todayEnd), nil
+	return !now.Before(todayStart) && now.Before(todayEnd), nil
 }
 
 // IsInRecurringWeekdayWindow checks if the current time falls within a recurring weekday maintenance window
@@ -202,7 +208,7 @@ func (twc *TimeWindowChecker) IsInRecurringWeekdayWindow(params *TimeWindowParam
 		// For cross-day windows, we need to check two possible windows:
 		// 1. Window starting today: todayStart to tomorrow's endTime
 		todayEnd = todayEnd.Add(24 * time.Hour)
-		if now.After(todayStart) && now.Before(todayEnd) {
+		if !now.Before(todayStart) && now.Before(todayEnd) {
 			return true, nil
 		}
 
@@ -210,7 +216,7 @@ func (twc *TimeWindowChecker) IsInRecurringWeekdayWindow(params *TimeWindowParam
 		yesterdayStart := todayStart.Add(-24 * time.Hour)
 		todayEndOriginal := time.Date(now.Year(), now.Month(), now.Day(),
 			endTime.Hour(), endTime.Minute(), 0, 0, loc)
-		if now.After(yesterdayStart) && now.Before(todayEndOriginal) {
+		if !now.Before(yesterdayStart) && now.Before(todayEndOriginal) {
 			return true, nil
 		}
 
@@ -218,7 +224,7 @@ func (twc *TimeWindowChecker) IsInRecurringWeekdayWindow(params *TimeWindowParam
 	}
 
 	// Check if we're within the daily time window
-	return now.After(todayStart) && now.Before(todayEnd), nil
+	return !now.Before(todayStart) && now.Before(todayEnd), nil
 }
 
 // IsInRecurringDayOfMonthWindow checks if the current time falls within a recurring day of month maintenance window
@@ -268,7 +274,7 @@ func (twc *TimeWindowChecker) IsInRecurringDayOfMonthWindow(params *TimeWindowPa
 		// For cross-day windows, we need to check two possible windows:
 		// 1. Window starting today: todayStart to tomorrow's endTime
 		todayEnd = todayEnd.Add(24 * time.Hour)
-		if now.After(todayStart) && now.Before(todayEnd) {
+		if !now.Before(todayStart) && now.Before(todayEnd) {
 			return true, nil
 		}
 
@@ -276,7 +282,7 @@ func (twc *TimeWindowChecker) IsInRecurringDayOfMonthWindow(params *TimeWindowPa
 		yesterdayStart := todayStart.Add(-24 * time.Hour)
 		todayEndOriginal := time.Date(now.Year(), now.Month(), now.Day(),
 			endTime.Hour(), endTime.Minute(), 0, 0, loc)
-		if now.After(yesterdayStart) && now.Before(todayEndOriginal) {
+		if !now.Before(yesterdayStart) && now.Before(todayEndOriginal) {
 			return true, nil
 		}
 
@@ -284,19 +290,5 @@ func (twc *TimeWindowChecker) IsInRecurringDayOfMonthWindow(params *TimeWindowPa
 	}
 
 	// Check if we're within the daily time window
-	return now.After(todayStart) && now.Before(todayEnd), nil
-}
-
-// convertToTimezone constructs a time in the specified timezone from the components of another time
-func (twc *TimeWindowChecker) convertToTimezone(inputTime string, loc *time.Location) time.Time {
-	parsedTime, err := time.Parse("2006-01-02T15:04", inputTime)
-	if err != nil {
-		return time.Time{}
-	}
-
-	return time.Date(
-		parsedTime.Year(), parsedTime.Month(), parsedTime.Day(),
-		parsedTime.Hour(), parsedTime.Minute(), parsedTime.Second(),
-		parsedTime.Nanosecond(), loc,
-	)
+	return !now.Before(todayStart) && now.Before(todayEnd), nil
 }
@@ -1,6 +1,10 @@
 package utils
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 // Validator handles validation logic for maintenance operations
 type Validator struct{}
@@ -12,11 +16,17 @@ func NewValidator() *Validator {
 
 // ValidationParams contains the parameters needed for validation
 type ValidationParams struct {
-	Cron     *string
-	Duration *int
-	Strategy *string
+	Cron          *string
+	Duration      *int
+	Strategy      *string
+	StartDateTime *string
+	EndDateTime   *string
+	Timezone      *string
 }
 
+// ErrInvalidDateTimeWindow wraps the errors of ValidateDateTimeWindow
+var ErrInvalidDateTimeWindow = errors.New("invalid maintenance window")
+
 // ValidateCronAndDuration validates that if cron is provided, duration is also required
 func (v *Validator) ValidateCronAndDuration(params *ValidationParams) error {
 	if params.Cron != nil && *params.Cron != "" {
@@ -27,6 +37,40 @@ func (v *Validator) ValidateCronAndDuration(params *ValidationParams) error {
 	return nil
 }
 
+// ValidateDateTimeWindow validates that the start and end date times parse and that the
+// end is after the start. The single strategy needs both since its window is exactly
+// that range.
+func (v *Validator) ValidateDateTimeWindow(params *ValidationParams) error {
+	if params.Strategy != nil && *params.Strategy == "single" &&
+		(params.StartDateTime == nil || *params.StartDateTime == "" || params.EndDateTime == nil || *params.EndDateTime == "") {
+		return fmt.Errorf("%w: start and end date time are required for a single maintenance", ErrInvalidDateTimeWindow)
+	}
+
+	timezone := "UTC"
+	if params.Timezone != nil && *params.Timezone != "" {
+		timezone = *params.Timezone
+	}
+	loc := NewTimeUtils().LoadTimezone(timezone)
+
+	var start, end time.Time
+	var err error
+	if params.StartDateTime != nil && *params.StartDateTime != "" {
+		if start, err = ParseDateTime(*params.StartDateTime, loc); err != nil {
+			return fmt.Errorf("%w: start: %v", ErrInvalidDateTimeWindow, err)
+		}
+	}
+	if params.EndDateTime != nil && *params.EndDateTime != "" {
+		if end, err = ParseDateTime(*params.EndDateTime, loc); err != nil {
+			return fmt.Errorf("%w: end: %v", ErrInvalidDateTimeWindow, err)
+		}
+	}
+	if !start.IsZero() && !end.IsZero() && !end.After(start) {
+		return fmt.Errorf("%w: end date time must be after start date time", ErrInvalidDateTimeWindow)
+	}
+
+	return nil
+}
+
 // ValidateStrategy validates that the strategy is one of the supported values
 func (v *Validator) ValidateStrategy(strategy string) error {
 	validStrategies := map[string]bool{
@@ -0,0 +1,268 @@
+package maintenance_template
+
+import (
+	"errors"
+	"net/http"
+	"peekaping/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type Controller struct {
+	service Service
+	logger  *zap.SugaredLogger
+}
+
+func NewController(
+	service Service,
+	logger *zap.SugaredLogger,
+) *Controller {
+	return &Controller{
+		service,
+		logger,
+	}
+}
+
+// @Router		/maintenance-templates [get]
+// @Summary		Get maintenance templates
+// @Tags			Maintenance Templates
+// @Produce		json
+// @Security  JwtAuth
+// @Security  ApiKeyAuth
+// @Param     q    query     string  false  "Search query"
+// @Param     page query     int     false  "Page number" default(1)
+// @Param     limit query    int     false  "Items per page" default(10)
+// @Success		200	{object}	utils.ApiResponse[[]Model]
+// @Failure		400	{object}	utils.APIError[any]
+// @Failure		500	{object}	utils.APIError[any]
+func (ic *Controller) FindAll(ctx *gin.Context) {
+	page, err := utils.GetQueryInt(ctx, "page", 0)
+	if err != nil || page < 0 {
+		ctx.JSON(http.StatusBadRequest, utils.NewFailResponse("Invalid page parameter"))
+		return
+	}
+
+	limit, err := utils.GetQueryInt(ctx, "limit", 10)
+	if err != nil || limit < 1 {
+		ctx.JSON(http.StatusBadRequest, utils.NewFailResponse("Invalid limit parameter"))
+		return
+	}
+
+	entities, err := ic.service.FindAll(ctx, page, limit, ctx.Query("q"))
+	if err != nil {
+		ic.logger.Errorw("Failed to fetch maintenance templates", "error", err)
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewSuccessResponse("success", entities))
+}
+
+// @Router		/maintenance-templates [post]
+// @Summary		Create maintenance template
+// @Tags			Maintenance Templates
+// @Produce		json
+// @Accept		json
+// @Security  JwtAuth
+// @Security  ApiKeyAuth
+// @Param     body body   CreateUpdateDto  true  "Maintenance template object"
+// @Success		201	{object}	utils.ApiResponse[Model]
+// @Failure		400	{object}	utils.APIError[any]
+// @Failure		500	{object}	utils.APIError[any]
+func (ic *Controller) Create(ctx *gin.Context) {
+	var entity *CreateUpdateDto
+	if err := ctx.ShouldBindJSON(&entity); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewFailResponse(err.Error()))
+		return
+	}
+
+	if err := utils.Validate.Struct(entity); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewFailResponse(err.Error()))
+		return
+	}
+
+	created, err := ic.service.Create(ctx, entity)
+	if err != nil {
+		ic.logger.Errorw("Failed to create maintenance template", "error", err)
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, utils.NewSuccessResponse("Maintenance template created successfully", created))
+}
+
+// @Router		/maintenance-templates/{id} [get]
+// @Summary		Get maintenance template by ID
+// @Tags			Maintenance Templates
+// @Produce		json
+// @Security  JwtAuth
+// @Security  ApiKeyAuth
+// @Param       id   path      string  true  "Maintenance template ID"
+// @Success		200	{object}	utils.ApiResponse[Model]
+// @Failure		404	{object}	utils.APIError[any]
+// @Failure		500	{object}	utils.APIError[any]
+func (ic *Controller) FindByID(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	entity, err := ic.service.FindByID(ctx, id)
+	if err != nil {
+		ic.logger.Errorw("Failed to fetch maintenance template", "error", err)
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
+		return
+	}
+
+	if entity == nil {
+		ctx.JSON(http.StatusNotFound, utils.NewFailResponse("Maintenance template not found"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewSuccessResponse("success", entity))
+}
+
+// @Router		/maintenance-templates/{id} [put]
+// @Summary		Update maintenance template
+// @Description	Also updates the recurrence of the maintenance window created from the template
+// @Tags			Maintenance Templates
+// @Produce		json
+// @Accept		json
+// @Security  JwtAuth
+// @Security  ApiKeyAuth
+// @Param       id   path      string  true  "Maintenance template ID"
+// @Param       body body   CreateUpdateDto  true  "Maintenance template object"
+// @Success		200	{object}	utils.ApiResponse[Model]
+// @Failure		400	{object}	utils.APIError[any]
+// @Failure		404	{object}	utils.APIError[any]
+// @Failure		500	{object}	utils.APIError[any]
+func (ic *Controller) UpdateFull(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	var entity CreateUpdateDto
+	if err := ctx.ShouldBindJSON(&entity); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewFailResponse(err.Error()))
+		return
+	}
+
+	if err := utils.Validate.Struct(entity); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewFailResponse(err.Error()))
+		return
+	}
+
+	updated, err := ic.service.UpdateFull(ctx, id, &entity)
+	if err != nil {
+		ic.logger.Errorw("Failed to update maintenance template", "error", err)
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
+		return
+	}
+
+	if updated == nil {
+		ctx.JSON(http.StatusNotFound, utils.NewFailResponse("Maintenance template not found"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewSuccessResponse("Maintenance template updated successfully", updated))
+}
+
+// @Router		/maintenance-templates/{id} [delete]
+// @Summary		Delete maintenance template
+// @Description	Also deletes the maintenance window created from the template
+// @Tags			Maintenance Templates
+// @Produce		json
+// @Security  JwtAuth
+// @Security  ApiKeyAuth
+// @Param       id   path      string  true  "Maintenance template ID"
+// @Success		200	{object}	utils.ApiResponse[any]
+// @Failure		500	{object}	utils.APIError[any]
+func (ic *Controller) Delete(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	if err := ic.service.Delete(ctx, id); err != nil {
+		ic.logger.Errorw("Failed to delete maintenance template", "error", err)
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewSuccessResponse[any]("Maintenance template deleted successfully", nil))
+}
+
+// @Router		/maintenance-templates/{id}/apply [post]
+// @Summary		Apply maintenance template
+// @Description	Links the monitors and the monitors of the tags to the maintenance window of the template, creating the window on first use
+// @Tags			Maintenance Templates
+// @Produce		json
+// @Accept		json
+// @Security  JwtAuth
+// @Security  ApiKeyAuth
+// @Param       id   path      string  true  "Maintenance template ID"
+// @Param       body body   ApplyDto  true  "Monitors and tags"
+// @Success		200	{object}	utils.ApiResponse[ApplyResultDto]
+// @Failure		400	{object}	utils.APIError[any]
+// @Failure		404	{object}	utils.APIError[any]
+// @Failure		500	{object}	utils.APIError[any]
+func (ic *Controller) Apply(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	var entity ApplyDto
+	if err := ctx.ShouldBindJSON(&entity); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewFailResponse(err.Error()))
+		return
+	}
+
+	result, err := ic.service.Apply(ctx, id, &entity)
+	if err != nil {
+		if errors.Is(err, ErrNoMonitors) {
+			ctx.JSON(http.StatusBadRequest, utils.NewFailResponse(err.Error()))
+			return
+		}
+		ic.logger.Errorw("Failed to apply maintenance template", "id", id, "error", err)
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
+		return
+	}
+
+	if result == nil {
+		ctx.JSON(http.StatusNotFound, utils.NewFailResponse("Maintenance template not found"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewSuccessResponse("Maintenance template applied successfully", result))
+}
+
+// @Router		/maintenance-templates/{id}/unapply [post]
+// @Summary		Unapply maintenance template
+// @Description	Unlinks the monitors and the monitors of the tags, or every monitor when the body selects none. The maintenance window is deleted once no monitors are left.
+// @Tags			Maintenance Templates
+// @Produce		json
+// @Accept		json
+// @Security  JwtAuth
+// @Security  ApiKeyAuth
+// @Param       id   path      string  true  "Maintenance template ID"
+// @Param       body body   ApplyDto  false  "Monitors and tags"
+// @Success		200	{object}	utils.ApiResponse[ApplyResultDto]
+// @Failure		400	{object}	utils.APIError[any]
+// @Failure		404	{object}	utils.APIError[any]
+// @Failure		500	{object}	utils.APIError[any]
+func (ic *Controller) Unapply(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	var entity ApplyDto
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&entity); err != nil {
+			ctx.JSON(http.StatusBadRequest, utils.NewFailResponse(err.Error()))
+			return
+		}
+	}
+
+	result, err := ic.service.Unapply(ctx, id, &entity)
+	if err != nil {
+		ic.logger.Errorw("Failed to unapply maintenance template", "id", id, "error", err)
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
+		return
+	}
+
+	if result == nil {
+		ctx.JSON(http.StatusNotFound, utils.NewFailResponse("Maintenance template not found"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewSuccessResponse("Maintenance template unapplied successfully", result))
+}
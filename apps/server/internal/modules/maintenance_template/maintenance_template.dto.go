@@ -0,0 +1,32 @@
+package maintenance_template
+
+type CreateUpdateDto struct {
+	Name           string  `json:"name" validate:"required"`
+	Title          string  `json:"title" validate:"required"`
+	Description    string  `json:"description"`
+	Strategy       string  `json:"strategy" validate:"required"`
+	StartDateTime  *string `json:"start_date_time,omitempty" validate:"omitempty,datetime=2006-01-02T15:04"`
+	EndDateTime    *string `json:"end_date_time,omitempty" validate:"omitempty,datetime=2006-01-02T15:04"`
+	StartTime      *string `json:"start_time,omitempty"`
+	EndTime        *string `json:"end_time,omitempty"`
+	Weekdays       []int   `json:"weekdays,omitempty" validate:"dive,min=0,max=6"`
+	DaysOfMonth    []int   `json:"days_of_month,omitempty"`
+	IntervalDay    *int    `json:"interval_day,omitempty"`
+	Cron           *string `json:"cron,omitempty"`
+	Timezone       *string `json:"timezone,omitempty"`
+	Duration       *int    `json:"duration,omitempty" validate:"omitempty,min=1"`
+	SuppressChecks bool    `json:"suppress_checks"`
+}
+
+// ApplyDto selects the monitors a template is applied to or removed from. Monitors
+// carrying any of the tags are included.
+type ApplyDto struct {
+	MonitorIds []string `json:"monitor_ids,omitempty"`
+	TagIds     []string `json:"tag_ids,omitempty"`
+}
+
+// ApplyResultDto is the maintenance window of a template and the monitors linked to it
+type ApplyResultDto struct {
+	MaintenanceID *string  `json:"maintenance_id,omitempty"`
+	MonitorIds    []string `json:"monitor_ids"`
+}
@@ -0,0 +1,28 @@
+package maintenance_template
+
+import "time"
+
+// Model is a reusable maintenance recurrence. Applying it to monitors creates a single
+// maintenance window from the template and links the monitors to it.
+type Model struct {
+	ID             string  `json:"id"`
+	Name           string  `json:"name"`
+	Title          string  `json:"title"`
+	Description    string  `json:"description"`
+	Strategy       string  `json:"strategy"`
+	StartDateTime  *string `json:"start_date_time,omitempty"`
+	EndDateTime    *string `json:"end_date_time,omitempty"`
+	StartTime      *string `json:"start_time,omitempty"`
+	EndTime        *string `json:"end_time,omitempty"`
+	Weekdays       []int   `json:"weekdays,omitempty"`
+	DaysOfMonth    []int   `json:"days_of_month,omitempty"`
+	IntervalDay    *int    `json:"interval_day,omitempty"`
+	Cron           *string `json:"cron,omitempty"`
+	Timezone       *string `json:"timezone,omitempty"`
+	Duration       *int    `json:"duration,omitempty"`
+	SuppressChecks bool    `json:"suppress_checks"`
+	// MaintenanceID is the maintenance window created from the template, empty until applied
+	MaintenanceID *string   `json:"maintenance_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
@@ -0,0 +1,219 @@
+package maintenance_template
+
+import (
+	"context"
+	"peekaping/internal/config"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoModel struct {
+	ID             primitive.ObjectID `bson:"_id"`
+	Name           string             `bson:"name"`
+	Title          string             `bson:"title"`
+	Description    string             `bson:"description"`
+	Strategy       string             `bson:"strategy"`
+	StartDateTime  *string            `bson:"start_date_time,omitempty"`
+	EndDateTime    *string            `bson:"end_date_time,omitempty"`
+	StartTime      *string            `bson:"start_time,omitempty"`
+	EndTime        *string            `bson:"end_time,omitempty"`
+	Weekdays       []int              `bson:"weekdays,omitempty"`
+	DaysOfMonth    []int              `bson:"days_of_month,omitempty"`
+	IntervalDay    *int               `bson:"interval_day,omitempty"`
+	Cron           *string            `bson:"cron,omitempty"`
+	Timezone       *string            `bson:"timezone,omitempty"`
+	Duration       *int               `bson:"duration,omitempty"`
+	SuppressChecks bool               `bson:"suppress_checks"`
+	MaintenanceID  *string            `bson:"maintenance_id,omitempty"`
+	CreatedAt      time.Time          `bson:"created_at"`
+	UpdatedAt      time.Time          `bson:"updated_at"`
+}
+
+func toDomainModel(mm *mongoModel) *Model {
+	return &Model{
+		ID:             mm.ID.Hex(),
+		Name:           mm.Name,
+		Title:          mm.Title,
+		Description:    mm.Description,
+		Strategy:       mm.Strategy,
+		StartDateTime:  mm.StartDateTime,
+		EndDateTime:    mm.EndDateTime,
+		StartTime:      mm.StartTime,
+		EndTime:        mm.EndTime,
+		Weekdays:       mm.Weekdays,
+		DaysOfMonth:    mm.DaysOfMonth,
+		IntervalDay:    mm.IntervalDay,
+		Cron:           mm.Cron,
+		Timezone:       mm.Timezone,
+		Duration:       mm.Duration,
+		SuppressChecks: mm.SuppressChecks,
+		MaintenanceID:  mm.MaintenanceID,
+		CreatedAt:      mm.CreatedAt,
+		UpdatedAt:      mm.UpdatedAt,
+	}
+}
+
+type MongoRepositoryImpl struct {
+	client     *mongo.Client
+	db         *mongo.Database
+	collection *mongo.Collection
+}
+
+func NewMongoRepository(client *mongo.Client, cfg *config.Config) Repository {
+	db := client.Database(cfg.DBName)
+	collection := db.Collection("maintenance_template")
+
+	return &MongoRepositoryImpl{client, db, collection}
+}
+
+func (r *MongoRepositoryImpl) Create(ctx context.Context, entity *CreateUpdateDto) (*Model, error) {
+	mm := &mongoModel{
+		ID:             primitive.NewObjectID(),
+		Name:           entity.Name,
+		Title:          entity.Title,
+		Description:    entity.Description,
+		Strategy:       entity.Strategy,
+		StartDateTime:  entity.StartDateTime,
+		EndDateTime:    entity.EndDateTime,
+		StartTime:      entity.StartTime,
+		EndTime:        entity.EndTime,
+		Weekdays:       entity.Weekdays,
+		DaysOfMonth:    entity.DaysOfMonth,
+		IntervalDay:    entity.IntervalDay,
+		Cron:           entity.Cron,
+		Timezone:       entity.Timezone,
+		Duration:       entity.Duration,
+		SuppressChecks: entity.SuppressChecks,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+
+	_, err := r.collection.InsertOne(ctx, mm)
+	if err != nil {
+		return nil, err
+	}
+
+	return toDomainModel(mm), nil
+}
+
+func (r *MongoRepositoryImpl) FindByID(ctx context.Context, id string) (*Model, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var mm mongoModel
+	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&mm)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return toDomainModel(&mm), nil
+}
+
+func (r *MongoRepositoryImpl) FindAll(ctx context.Context, page int, limit int, q string) ([]*Model, error) {
+	var entities []*Model
+
+	skip := int64(page * limit)
+	limit64 := int64(limit)
+
+	options := &options.FindOptions{
+		Skip:  &skip,
+		Limit: &limit64,
+		Sort:  bson.D{{Key: "created_at", Value: -1}},
+	}
+
+	filter := bson.M{}
+	if q != "" {
+		filter["$or"] = bson.A{
+			bson.M{"name": bson.M{"$regex": q, "$options": "i"}},
+			bson.M{"title": bson.M{"$regex": q, "$options": "i"}},
+		}
+	}
+
+	cursor, err := r.collection.Find(ctx, filter, options)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var mm mongoModel
+		if err := cursor.Decode(&mm); err != nil {
+			return nil, err
+		}
+		entities = append(entities, toDomainModel(&mm))
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return entities, nil
+}
+
+func (r *MongoRepositoryImpl) UpdateFull(ctx context.Context, id string, entity *CreateUpdateDto) (*Model, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	update := bson.M{"$set": bson.M{
+		"name":            entity.Name,
+		"title":           entity.Title,
+		"description":     entity.Description,
+		"strategy":        entity.Strategy,
+		"start_date_time": entity.StartDateTime,
+		"end_date_time":   entity.EndDateTime,
+		"start_time":      entity.StartTime,
+		"end_time":        entity.EndTime,
+		"weekdays":        entity.Weekdays,
+		"days_of_month":   entity.DaysOfMonth,
+		"interval_day":    entity.IntervalDay,
+		"cron":            entity.Cron,
+		"timezone":        entity.Timezone,
+		"duration":        entity.Duration,
+		"suppress_checks": entity.SuppressChecks,
+		"updated_at":      time.Now(),
+	}}
+
+	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.FindByID(ctx, id)
+}
+
+func (r *MongoRepositoryImpl) Delete(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	return err
+}
+
+func (r *MongoRepositoryImpl) SetMaintenanceID(ctx context.Context, id string, maintenanceID *string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{"$set": bson.M{"updated_at": time.Now()}}
+	if maintenanceID != nil {
+		update["$set"].(bson.M)["maintenance_id"] = *maintenanceID
+	} else {
+		update["$unset"] = bson.M{"maintenance_id": ""}
+	}
+
+	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
+	return err
+}
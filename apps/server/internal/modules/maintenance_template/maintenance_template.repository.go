@@ -0,0 +1,14 @@
+package maintenance_template
+
+import "context"
+
+type Repository interface {
+	Create(ctx context.Context, entity *CreateUpdateDto) (*Model, error)
+	FindByID(ctx context.Context, id string) (*Model, error)
+	FindAll(ctx context.Context, page int, limit int, q string) ([]*Model, error)
+	UpdateFull(ctx context.Context, id string, entity *CreateUpdateDto) (*Model, error)
+	Delete(ctx context.Context, id string) error
+
+	// SetMaintenanceID links the template to the maintenance created from it, nil unlinks it
+	SetMaintenanceID(ctx context.Context, id string, maintenanceID *string) error
+}
@@ -0,0 +1,39 @@
+package maintenance_template
+
+import (
+	"peekaping/internal/modules/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Route struct {
+	controller *Controller
+	middleware *middleware.AuthChain
+}
+
+func NewRoute(
+	controller *Controller,
+	middleware *middleware.AuthChain,
+) *Route {
+	return &Route{
+		controller,
+		middleware,
+	}
+}
+
+func (uc *Route) ConnectRoute(
+	rg *gin.RouterGroup,
+	controller *Controller,
+) {
+	router := rg.Group("maintenance-templates")
+
+	router.Use(uc.middleware.AllAuth())
+	router.GET("", uc.controller.FindAll)
+	router.POST("", uc.controller.Create)
+	router.GET(":id", uc.controller.FindByID)
+	router.PUT(":id", uc.controller.UpdateFull)
+	router.DELETE(":id", uc.controller.Delete)
+
+	router.POST(":id/apply", uc.controller.Apply)
+	router.POST(":id/unapply", uc.controller.Unapply)
+}
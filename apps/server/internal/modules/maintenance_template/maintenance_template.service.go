@@ -0,0 +1,298 @@
+package maintenance_template
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+
+	"peekaping/internal/modules/audit_log"
+	"peekaping/internal/modules/maintenance"
+	"peekaping/internal/modules/monitor_maintenance"
+	"peekaping/internal/modules/monitor_tag"
+)
+
+// ErrNoMonitors is returned when an apply request selects no monitors
+var ErrNoMonitors = errors.New("no monitors selected")
+
+type Service interface {
+	Create(ctx context.Context, entity *CreateUpdateDto) (*Model, error)
+	FindByID(ctx context.Context, id string) (*Model, error)
+	FindAll(ctx context.Context, page int, limit int, q string) ([]*Model, error)
+	UpdateFull(ctx context.Context, id string, entity *CreateUpdateDto) (*Model, error)
+	Delete(ctx context.Context, id string) error
+
+	// Apply links the selected monitors to the maintenance window of the template,
+	// creating the window on first use. It returns nil when the template does not exist.
+	Apply(ctx context.Context, id string, entity *ApplyDto) (*ApplyResultDto, error)
+
+	// Unapply unlinks the selected monitors, or all monitors when none are selected. The
+	// maintenance window is deleted once no monitors are left.
+	Unapply(ctx context.Context, id string, entity *ApplyDto) (*ApplyResultDto, error)
+}
+
+type ServiceImpl struct {
+	repository                Repository
+	maintenanceService        maintenance.Service
+	monitorMaintenanceService monitor_maintenance.Service
+	monitorTagService         monitor_tag.Service
+	auditLogService           audit_log.Service
+	logger                    *zap.SugaredLogger
+}
+
+func NewService(
+	repository Repository,
+	maintenanceService maintenance.Service,
+	monitorMaintenanceService monitor_maintenance.Service,
+	monitorTagService monitor_tag.Service,
+	auditLogService audit_log.Service,
+	logger *zap.SugaredLogger,
+) Service {
+	return &ServiceImpl{
+		repository:                repository,
+		maintenanceService:        maintenanceService,
+		monitorMaintenanceService: monitorMaintenanceService,
+		monitorTagService:         monitorTagService,
+		auditLogService:           auditLogService,
+		logger:                    logger.Named("[maintenance-template-service]"),
+	}
+}
+
+// recordAudit writes an audit log entry for a change of the maintenance window of a
+// template like the maintenance controller does. Failures are only logged.
+func (s *ServiceImpl) recordAudit(ctx context.Context, id, action string, before, after *maintenance.Model) {
+	var beforeValue, afterValue any
+	if before != nil {
+		beforeValue = before
+	}
+	if after != nil {
+		afterValue = after
+	}
+
+	if _, err := s.auditLogService.Record(ctx, audit_log.EntityMaintenance, id, action, beforeValue, afterValue); err != nil {
+		s.logger.Errorw("Failed to record maintenance audit log", "id", id, "error", err)
+	}
+}
+
+func (s *ServiceImpl) Create(ctx context.Context, entity *CreateUpdateDto) (*Model, error) {
+	return s.repository.Create(ctx, entity)
+}
+
+func (s *ServiceImpl) FindByID(ctx context.Context, id string) (*Model, error) {
+	return s.repository.FindByID(ctx, id)
+}
+
+func (s *ServiceImpl) FindAll(ctx context.Context, page int, limit int, q string) ([]*Model, error) {
+	return s.repository.FindAll(ctx, page, limit, q)
+}
+
+// UpdateFull updates the template and carries the new recurrence over to its maintenance
+// window, keeping the window's active state and linked monitors
+func (s *ServiceImpl) UpdateFull(ctx context.Context, id string, entity *CreateUpdateDto) (*Model, error) {
+	updated, err := s.repository.UpdateFull(ctx, id, entity)
+	if err != nil || updated == nil {
+		return updated, err
+	}
+
+	linked, err := s.linkedMaintenance(ctx, updated)
+	if err != nil {
+		return nil, err
+	}
+	if linked != nil {
+		dto := toMaintenanceDto(updated, nil)
+		dto.Active = linked.Active
+		updatedMaintenance, err := s.maintenanceService.UpdateFull(ctx, linked.ID, dto)
+		if err != nil {
+			return nil, err
+		}
+		s.recordAudit(ctx, linked.ID, audit_log.ActionUpdate, linked, updatedMaintenance)
+	}
+
+	return updated, nil
+}
+
+// Delete removes the template together with the maintenance window created from it
+func (s *ServiceImpl) Delete(ctx context.Context, id string) error {
+	template, err := s.repository.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if template != nil {
+		linked, err := s.linkedMaintenance(ctx, template)
+		if err != nil {
+			return err
+		}
+		if linked != nil {
+			if err := s.maintenanceService.Delete(ctx, linked.ID); err != nil {
+				return err
+			}
+			s.recordAudit(ctx, linked.ID, audit_log.ActionDelete, linked, nil)
+		}
+	}
+
+	return s.repository.Delete(ctx, id)
+}
+
+func (s *ServiceImpl) Apply(ctx context.Context, id string, entity *ApplyDto) (*ApplyResultDto, error) {
+	template, err := s.repository.FindByID(ctx, id)
+	if err != nil || template == nil {
+		return nil, err
+	}
+
+	monitorIDs, err := s.resolveMonitors(ctx, entity)
+	if err != nil {
+		return nil, err
+	}
+	if len(monitorIDs) == 0 {
+		return nil, ErrNoMonitors
+	}
+
+	linked, err := s.linkedMaintenance(ctx, template)
+	if err != nil {
+		return nil, err
+	}
+
+	// Applying again adds monitors to the existing window
+	if linked != nil {
+		existing, err := s.monitorMaintenanceService.GetMonitors(ctx, linked.ID)
+		if err != nil {
+			return nil, err
+		}
+		merged := union(existing, monitorIDs)
+		if err := s.monitorMaintenanceService.SetMonitors(ctx, linked.ID, merged); err != nil {
+			return nil, err
+		}
+		return &ApplyResultDto{MaintenanceID: &linked.ID, MonitorIds: merged}, nil
+	}
+
+	dto := toMaintenanceDto(template, monitorIDs)
+	dto.Active = true
+	created, err := s.maintenanceService.Create(ctx, dto)
+	if err != nil {
+		return nil, err
+	}
+	s.recordAudit(ctx, created.ID, audit_log.ActionCreate, nil, created)
+	if err := s.repository.SetMaintenanceID(ctx, template.ID, &created.ID); err != nil {
+		return nil, err
+	}
+
+	s.logger.Infow("Applied maintenance template", "template_id", template.ID, "maintenance_id", created.ID, "monitors", len(monitorIDs))
+
+	return &ApplyResultDto{MaintenanceID: &created.ID, MonitorIds: monitorIDs}, nil
+}
+
+func (s *ServiceImpl) Unapply(ctx context.Context, id string, entity *ApplyDto) (*ApplyResultDto, error) {
+	template, err := s.repository.FindByID(ctx, id)
+	if err != nil || template == nil {
+		return nil, err
+	}
+
+	linked, err := s.linkedMaintenance(ctx, template)
+	if err != nil {
+		return nil, err
+	}
+	if linked == nil {
+		return &ApplyResultDto{MonitorIds: []string{}}, nil
+	}
+
+	var remaining []string
+	if len(entity.MonitorIds) > 0 || len(entity.TagIds) > 0 {
+		selected, err := s.resolveMonitors(ctx, entity)
+		if err != nil {
+			return nil, err
+		}
+		existing, err := s.monitorMaintenanceService.GetMonitors(ctx, linked.ID)
+		if err != nil {
+			return nil, err
+		}
+		remaining = difference(existing, selected)
+	}
+
+	if len(remaining) == 0 {
+		if err := s.maintenanceService.Delete(ctx, linked.ID); err != nil {
+			return nil, err
+		}
+		s.recordAudit(ctx, linked.ID, audit_log.ActionDelete, linked, nil)
+		if err := s.repository.SetMaintenanceID(ctx, template.ID, nil); err != nil {
+			return nil, err
+		}
+		return &ApplyResultDto{MonitorIds: []string{}}, nil
+	}
+
+	if err := s.monitorMaintenanceService.SetMonitors(ctx, linked.ID, remaining); err != nil {
+		return nil, err
+	}
+	return &ApplyResultDto{MaintenanceID: &linked.ID, MonitorIds: remaining}, nil
+}
+
+// linkedMaintenance returns the maintenance window created from the template, nil when
+// it was never applied or the window has since been deleted
+func (s *ServiceImpl) linkedMaintenance(ctx context.Context, template *Model) (*maintenance.Model, error) {
+	if template.MaintenanceID == nil {
+		return nil, nil
+	}
+	return s.maintenanceService.FindByID(ctx, *template.MaintenanceID)
+}
+
+// resolveMonitors returns the selected monitor IDs plus the monitors of the selected tags
+func (s *ServiceImpl) resolveMonitors(ctx context.Context, entity *ApplyDto) ([]string, error) {
+	monitorIDs := union(nil, entity.MonitorIds)
+	for _, tagID := range entity.TagIds {
+		monitorTags, err := s.monitorTagService.FindByTagID(ctx, tagID)
+		if err != nil {
+			return nil, err
+		}
+		for _, mt := range monitorTags {
+			monitorIDs = union(monitorIDs, []string{mt.MonitorID})
+		}
+	}
+	return monitorIDs, nil
+}
+
+func toMaintenanceDto(template *Model, monitorIDs []string) *maintenance.CreateUpdateDto {
+	return &maintenance.CreateUpdateDto{
+		Title:          template.Title,
+		Description:    template.Description,
+		Strategy:       template.Strategy,
+		StartDateTime:  template.StartDateTime,
+		EndDateTime:    template.EndDateTime,
+		StartTime:      template.StartTime,
+		EndTime:        template.EndTime,
+		Weekdays:       template.Weekdays,
+		DaysOfMonth:    template.DaysOfMonth,
+		IntervalDay:    template.IntervalDay,
+		Cron:           template.Cron,
+		Timezone:       template.Timezone,
+		Duration:       template.Duration,
+		SuppressChecks: template.SuppressChecks,
+		MonitorIds:     monitorIDs,
+	}
+}
+
+// union appends the IDs of b missing from a, keeping the order
+func union(a, b []string) []string {
+	seen := make(map[string]bool, len(a))
+	result := make([]string, 0, len(a)+len(b))
+	for _, id := range append(append([]string{}, a...), b...) {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+// difference returns the IDs of a that are not in b
+func difference(a, b []string) []string {
+	removed := make(map[string]bool, len(b))
+	for _, id := range b {
+		removed[id] = true
+	}
+	var result []string
+	for _, id := range a {
+		if !removed[id] {
+			result = append(result, id)
+		}
+	}
+	return result
+}
@@ -0,0 +1,519 @@
+package maintenance_template
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"peekaping/internal/config"
+	"peekaping/internal/modules/audit_log"
+	"peekaping/internal/modules/maintenance"
+	"peekaping/internal/modules/monitor_maintenance"
+	"peekaping/internal/modules/monitor_tag"
+	"peekaping/internal/modules/shared"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) Create(ctx context.Context, entity *CreateUpdateDto) (*Model, error) {
+	args := m.Called(ctx, entity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Model), args.Error(1)
+}
+
+func (m *MockRepository) FindByID(ctx context.Context, id string) (*Model, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Model), args.Error(1)
+}
+
+func (m *MockRepository) FindAll(ctx context.Context, page int, limit int, q string) ([]*Model, error) {
+	args := m.Called(ctx, page, limit, q)
+	return args.Get(0).([]*Model), args.Error(1)
+}
+
+func (m *MockRepository) UpdateFull(ctx context.Context, id string, entity *CreateUpdateDto) (*Model, error) {
+	args := m.Called(ctx, id, entity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Model), args.Error(1)
+}
+
+func (m *MockRepository) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockRepository) SetMaintenanceID(ctx context.Context, id string, maintenanceID *string) error {
+	args := m.Called(ctx, id, maintenanceID)
+	return args.Error(0)
+}
+
+type MockMaintenanceService struct {
+	mock.Mock
+}
+
+func (m *MockMaintenanceService) Create(ctx context.Context, entity *maintenance.CreateUpdateDto) (*maintenance.Model, error) {
+	args := m.Called(ctx, entity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*maintenance.Model), args.Error(1)
+}
+
+func (m *MockMaintenanceService) FindByID(ctx context.Context, id string) (*maintenance.Model, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*maintenance.Model), args.Error(1)
+}
+
+func (m *MockMaintenanceService) FindAll(ctx context.Context, page int, limit int, q string, strategy string) ([]*maintenance.Model, error) {
+	args := m.Called(ctx, page, limit, q, strategy)
+	return args.Get(0).([]*maintenance.Model), args.Error(1)
+}
+
+func (m *MockMaintenanceService) UpdateFull(ctx context.Context, id string, entity *maintenance.CreateUpdateDto) (*maintenance.Model, error) {
+	args := m.Called(ctx, id, entity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*maintenance.Model), args.Error(1)
+}
+
+func (m *MockMaintenanceService) UpdatePartial(ctx context.Context, id string, entity *maintenance.PartialUpdateDto) (*maintenance.Model, error) {
+	args := m.Called(ctx, id, entity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*maintenance.Model), args.Error(1)
+}
+
+func (m *MockMaintenanceService) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockMaintenanceService) SetActive(ctx context.Context, id string, active bool) (*maintenance.Model, error) {
+	args := m.Called(ctx, id, active)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*maintenance.Model), args.Error(1)
+}
+
+func (m *MockMaintenanceService) SetApproval(ctx context.Context, id string, status string, approvedBy string) (*maintenance.Model, error) {
+	args := m.Called(ctx, id, status, approvedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*maintenance.Model), args.Error(1)
+}
+
+func (m *MockMaintenanceService) IsUnderMaintenance(ctx context.Context, entity *maintenance.Model) (bool, error) {
+	args := m.Called(ctx, entity)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMaintenanceService) IsUnderMaintenanceAt(ctx context.Context, entity *maintenance.Model, at time.Time) (bool, error) {
+	args := m.Called(ctx, entity, at)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMaintenanceService) NextWindow(ctx context.Context, entity *maintenance.Model, after time.Time) (*maintenance.Window, error) {
+	args := m.Called(ctx, entity, after)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*maintenance.Window), args.Error(1)
+}
+
+func (m *MockMaintenanceService) GetMaintenancesByMonitorID(ctx context.Context, monitorID string) ([]*maintenance.Model, error) {
+	args := m.Called(ctx, monitorID)
+	return args.Get(0).([]*maintenance.Model), args.Error(1)
+}
+
+func (m *MockMaintenanceService) GetMonitors(ctx context.Context, id string) ([]string, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockMaintenanceService) EndOnRecovery(ctx context.Context, maintenance *maintenance.Model, latest map[string][]*shared.HeartBeatModel) (bool, error) {
+	args := m.Called(ctx, maintenance, latest)
+	return args.Bool(0), args.Error(1)
+}
+
+type MockMonitorMaintenanceService struct {
+	mock.Mock
+}
+
+func (m *MockMonitorMaintenanceService) Create(ctx context.Context, monitorID string, maintenanceID string) (*monitor_maintenance.Model, error) {
+	args := m.Called(ctx, monitorID, maintenanceID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*monitor_maintenance.Model), args.Error(1)
+}
+
+func (m *MockMonitorMaintenanceService) FindByID(ctx context.Context, id string) (*monitor_maintenance.Model, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*monitor_maintenance.Model), args.Error(1)
+}
+
+func (m *MockMonitorMaintenanceService) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockMonitorMaintenanceService) FindByMonitorID(ctx context.Context, monitorID string) ([]*monitor_maintenance.Model, error) {
+	args := m.Called(ctx, monitorID)
+	return args.Get(0).([]*monitor_maintenance.Model), args.Error(1)
+}
+
+func (m *MockMonitorMaintenanceService) FindByMaintenanceID(ctx context.Context, maintenanceID string) ([]*monitor_maintenance.Model, error) {
+	args := m.Called(ctx, maintenanceID)
+	return args.Get(0).([]*monitor_maintenance.Model), args.Error(1)
+}
+
+func (m *MockMonitorMaintenanceService) DeleteByMonitorID(ctx context.Context, monitorID string) error {
+	args := m.Called(ctx, monitorID)
+	return args.Error(0)
+}
+
+func (m *MockMonitorMaintenanceService) DeleteByMaintenanceID(ctx context.Context, maintenanceID string) error {
+	args := m.Called(ctx, maintenanceID)
+	return args.Error(0)
+}
+
+func (m *MockMonitorMaintenanceService) SetMonitors(ctx context.Context, maintenanceID string, monitorIDs []string) error {
+	args := m.Called(ctx, maintenanceID, monitorIDs)
+	return args.Error(0)
+}
+
+func (m *MockMonitorMaintenanceService) GetMonitors(ctx context.Context, maintenanceID string) ([]string, error) {
+	args := m.Called(ctx, maintenanceID)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+type MockMonitorTagService struct {
+	mock.Mock
+}
+
+func (m *MockMonitorTagService) Create(ctx context.Context, monitorID string, tagID string) (*monitor_tag.Model, error) {
+	args := m.Called(ctx, monitorID, tagID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*monitor_tag.Model), args.Error(1)
+}
+
+func (m *MockMonitorTagService) FindByID(ctx context.Context, id string) (*monitor_tag.Model, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*monitor_tag.Model), args.Error(1)
+}
+
+func (m *MockMonitorTagService) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockMonitorTagService) FindByMonitorID(ctx context.Context, monitorID string) ([]*monitor_tag.Model, error) {
+	args := m.Called(ctx, monitorID)
+	return args.Get(0).([]*monitor_tag.Model), args.Error(1)
+}
+
+func (m *MockMonitorTagService) FindByTagID(ctx context.Context, tagID string) ([]*monitor_tag.Model, error) {
+	args := m.Called(ctx, tagID)
+	return args.Get(0).([]*monitor_tag.Model), args.Error(1)
+}
+
+func (m *MockMonitorTagService) DeleteByMonitorID(ctx context.Context, monitorID string) error {
+	args := m.Called(ctx, monitorID)
+	return args.Error(0)
+}
+
+func (m *MockMonitorTagService) DeleteByTagID(ctx context.Context, tagID string) error {
+	args := m.Called(ctx, tagID)
+	return args.Error(0)
+}
+
+func (m *MockMonitorTagService) DeleteByMonitorAndTag(ctx context.Context, monitorID string, tagID string) error {
+	args := m.Called(ctx, monitorID, tagID)
+	return args.Error(0)
+}
+
+type testDeps struct {
+	repo                      *MockRepository
+	maintenanceService        *MockMaintenanceService
+	monitorMaintenanceService *MockMonitorMaintenanceService
+	monitorTagService         *MockMonitorTagService
+	auditRepository           *auditRepository
+}
+
+// auditRepository keeps the recorded audit entries
+type auditRepository struct {
+	entries []*audit_log.Model
+}
+
+func (r *auditRepository) Create(ctx context.Context, entity *audit_log.Model) (*audit_log.Model, error) {
+	r.entries = append(r.entries, entity)
+	return entity, nil
+}
+
+func (r *auditRepository) FindAll(ctx context.Context, filter *audit_log.FilterOptions) ([]*audit_log.Model, error) {
+	return r.entries, nil
+}
+
+func (r *auditRepository) DeleteOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	return 0, nil
+}
+
+func setupService() (Service, *testDeps) {
+	deps := &testDeps{
+		repo:                      &MockRepository{},
+		maintenanceService:        &MockMaintenanceService{},
+		monitorMaintenanceService: &MockMonitorMaintenanceService{},
+		monitorTagService:         &MockMonitorTagService{},
+		auditRepository:           &auditRepository{},
+	}
+	logger := zap.NewNop().Sugar()
+	auditLogService := audit_log.NewService(deps.auditRepository, &config.Config{AuditLogEnabled: true}, logger)
+	service := NewService(deps.repo, deps.maintenanceService, deps.monitorMaintenanceService, deps.monitorTagService, auditLogService, logger)
+	return service, deps
+}
+
+func (d *testDeps) assertExpectations(t *testing.T) {
+	d.repo.AssertExpectations(t)
+	d.maintenanceService.AssertExpectations(t)
+	d.monitorMaintenanceService.AssertExpectations(t)
+	d.monitorTagService.AssertExpectations(t)
+}
+
+func weeklyTemplate() *Model {
+	startTime := "02:00"
+	endTime := "04:00"
+	timezone := "Europe/Berlin"
+	return &Model{
+		ID:        "tpl-1",
+		Name:      "Weekly patching",
+		Title:     "Patch window",
+		Strategy:  "recurring-weekday",
+		StartTime: &startTime,
+		EndTime:   &endTime,
+		Weekdays:  []int{0},
+		Timezone:  &timezone,
+	}
+}
+
+func TestApply_CreatesLinkedMaintenance(t *testing.T) {
+	ctx := context.Background()
+	service, deps := setupService()
+	template := weeklyTemplate()
+
+	deps.repo.On("FindByID", ctx, "tpl-1").Return(template, nil)
+	deps.monitorTagService.On("FindByTagID", ctx, "tag-db").Return([]*monitor_tag.Model{
+		{MonitorID: "mon-2", TagID: "tag-db"},
+		{MonitorID: "mon-3", TagID: "tag-db"},
+	}, nil)
+
+	var created *maintenance.CreateUpdateDto
+	deps.maintenanceService.On("Create", ctx, mock.AnythingOfType("*maintenance.CreateUpdateDto")).
+		Run(func(args mock.Arguments) { created = args.Get(1).(*maintenance.CreateUpdateDto) }).
+		Return(&maintenance.Model{ID: "maint-1"}, nil)
+	deps.repo.On("SetMaintenanceID", ctx, "tpl-1", mock.MatchedBy(func(id *string) bool { return id != nil && *id == "maint-1" })).Return(nil)
+
+	result, err := service.Apply(ctx, "tpl-1", &ApplyDto{
+		MonitorIds: []string{"mon-1", "mon-2"},
+		TagIds:     []string{"tag-db"},
+	})
+
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.Equal(t, "maint-1", *result.MaintenanceID)
+	assert.Equal(t, []string{"mon-1", "mon-2", "mon-3"}, result.MonitorIds)
+
+	// The window carries the template's recurrence and links the monitors
+	require.NotNil(t, created)
+	assert.Equal(t, "Patch window", created.Title)
+	assert.Equal(t, "recurring-weekday", created.Strategy)
+	assert.Equal(t, template.StartTime, created.StartTime)
+	assert.Equal(t, template.EndTime, created.EndTime)
+	assert.Equal(t, []int{0}, created.Weekdays)
+	assert.Equal(t, template.Timezone, created.Timezone)
+	assert.True(t, created.Active)
+	assert.Equal(t, []string{"mon-1", "mon-2", "mon-3"}, created.MonitorIds)
+
+	require.Len(t, deps.auditRepository.entries, 1)
+	assert.Equal(t, audit_log.EntityMaintenance, deps.auditRepository.entries[0].EntityType)
+	assert.Equal(t, "maint-1", deps.auditRepository.entries[0].EntityID)
+	assert.Equal(t, audit_log.ActionCreate, deps.auditRepository.entries[0].Action)
+
+	deps.assertExpectations(t)
+}
+
+func TestApply_AddsMonitorsToExistingMaintenance(t *testing.T) {
+	ctx := context.Background()
+	service, deps := setupService()
+	template := weeklyTemplate()
+	maintenanceID := "maint-1"
+	template.MaintenanceID = &maintenanceID
+
+	deps.repo.On("FindByID", ctx, "tpl-1").Return(template, nil)
+	deps.maintenanceService.On("FindByID", ctx, "maint-1").Return(&maintenance.Model{ID: "maint-1"}, nil)
+	deps.monitorMaintenanceService.On("GetMonitors", ctx, "maint-1").Return([]string{"mon-1"}, nil)
+	deps.monitorMaintenanceService.On("SetMonitors", ctx, "maint-1", []string{"mon-1", "mon-4"}).Return(nil)
+
+	result, err := service.Apply(ctx, "tpl-1", &ApplyDto{MonitorIds: []string{"mon-4", "mon-1"}})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mon-1", "mon-4"}, result.MonitorIds)
+	deps.maintenanceService.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	deps.assertExpectations(t)
+}
+
+func TestApply_RecreatesDeletedMaintenance(t *testing.T) {
+	ctx := context.Background()
+	service, deps := setupService()
+	template := weeklyTemplate()
+	maintenanceID := "maint-gone"
+	template.MaintenanceID = &maintenanceID
+
+	deps.repo.On("FindByID", ctx, "tpl-1").Return(template, nil)
+	deps.maintenanceService.On("FindByID", ctx, "maint-gone").Return(nil, nil)
+	deps.maintenanceService.On("Create", ctx, mock.AnythingOfType("*maintenance.CreateUpdateDto")).Return(&maintenance.Model{ID: "maint-2"}, nil)
+	deps.repo.On("SetMaintenanceID", ctx, "tpl-1", mock.MatchedBy(func(id *string) bool { return id != nil && *id == "maint-2" })).Return(nil)
+
+	result, err := service.Apply(ctx, "tpl-1", &ApplyDto{MonitorIds: []string{"mon-1"}})
+
+	require.NoError(t, err)
+	assert.Equal(t, "maint-2", *result.MaintenanceID)
+	deps.assertExpectations(t)
+}
+
+func TestApply_NoMonitors(t *testing.T) {
+	ctx := context.Background()
+	service, deps := setupService()
+
+	deps.repo.On("FindByID", ctx, "tpl-1").Return(weeklyTemplate(), nil)
+	deps.monitorTagService.On("FindByTagID", ctx, "empty-tag").Return([]*monitor_tag.Model{}, nil)
+
+	result, err := service.Apply(ctx, "tpl-1", &ApplyDto{TagIds: []string{"empty-tag"}})
+
+	assert.ErrorIs(t, err, ErrNoMonitors)
+	assert.Nil(t, result)
+	deps.assertExpectations(t)
+}
+
+func TestApply_TemplateNotFound(t *testing.T) {
+	ctx := context.Background()
+	service, deps := setupService()
+
+	deps.repo.On("FindByID", ctx, "missing").Return(nil, nil)
+
+	result, err := service.Apply(ctx, "missing", &ApplyDto{MonitorIds: []string{"mon-1"}})
+
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+	deps.assertExpectations(t)
+}
+
+func TestUnapply_RemovesSelectedMonitors(t *testing.T) {
+	ctx := context.Background()
+	service, deps := setupService()
+	template := weeklyTemplate()
+	maintenanceID := "maint-1"
+	template.MaintenanceID = &maintenanceID
+
+	deps.repo.On("FindByID", ctx, "tpl-1").Return(template, nil)
+	deps.maintenanceService.On("FindByID", ctx, "maint-1").Return(&maintenance.Model{ID: "maint-1"}, nil)
+	deps.monitorMaintenanceService.On("GetMonitors", ctx, "maint-1").Return([]string{"mon-1", "mon-2", "mon-3"}, nil)
+	deps.monitorMaintenanceService.On("SetMonitors", ctx, "maint-1", []string{"mon-1", "mon-3"}).Return(nil)
+
+	result, err := service.Unapply(ctx, "tpl-1", &ApplyDto{MonitorIds: []string{"mon-2"}})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mon-1", "mon-3"}, result.MonitorIds)
+	deps.assertExpectations(t)
+}
+
+func TestUnapply_DeletesMaintenanceWhenNoMonitorsLeft(t *testing.T) {
+	ctx := context.Background()
+	service, deps := setupService()
+	template := weeklyTemplate()
+	maintenanceID := "maint-1"
+	template.MaintenanceID = &maintenanceID
+
+	deps.repo.On("FindByID", ctx, "tpl-1").Return(template, nil)
+	deps.maintenanceService.On("FindByID", ctx, "maint-1").Return(&maintenance.Model{ID: "maint-1"}, nil)
+	deps.maintenanceService.On("Delete", ctx, "maint-1").Return(nil)
+	deps.repo.On("SetMaintenanceID", ctx, "tpl-1", (*string)(nil)).Return(nil)
+
+	result, err := service.Unapply(ctx, "tpl-1", &ApplyDto{})
+
+	require.NoError(t, err)
+	assert.Nil(t, result.MaintenanceID)
+	assert.Empty(t, result.MonitorIds)
+	require.Len(t, deps.auditRepository.entries, 1)
+	assert.Equal(t, audit_log.ActionDelete, deps.auditRepository.entries[0].Action)
+	deps.assertExpectations(t)
+}
+
+func TestUpdateFull_SyncsLinkedMaintenance(t *testing.T) {
+	ctx := context.Background()
+	service, deps := setupService()
+	updated := weeklyTemplate()
+	maintenanceID := "maint-1"
+	updated.MaintenanceID = &maintenanceID
+	updated.Weekdays = []int{6}
+	dto := &CreateUpdateDto{Name: updated.Name, Title: updated.Title, Strategy: updated.Strategy, Weekdays: []int{6}}
+
+	deps.repo.On("UpdateFull", ctx, "tpl-1", dto).Return(updated, nil)
+	deps.maintenanceService.On("FindByID", ctx, "maint-1").Return(&maintenance.Model{ID: "maint-1", Active: false}, nil)
+	deps.maintenanceService.On("UpdateFull", ctx, "maint-1", mock.MatchedBy(func(m *maintenance.CreateUpdateDto) bool {
+		// Links are left alone and a paused window stays paused
+		return m.MonitorIds == nil && !m.Active && len(m.Weekdays) == 1 && m.Weekdays[0] == 6
+	})).Return(&maintenance.Model{ID: "maint-1"}, nil)
+
+	result, err := service.UpdateFull(ctx, "tpl-1", dto)
+
+	require.NoError(t, err)
+	assert.Equal(t, updated, result)
+	deps.assertExpectations(t)
+}
+
+func TestDelete_RemovesLinkedMaintenance(t *testing.T) {
+	ctx := context.Background()
+	service, deps := setupService()
+	template := weeklyTemplate()
+	maintenanceID := "maint-1"
+	template.MaintenanceID = &maintenanceID
+
+	deps.repo.On("FindByID", ctx, "tpl-1").Return(template, nil)
+	deps.maintenanceService.On("FindByID", ctx, "maint-1").Return(&maintenance.Model{ID: "maint-1"}, nil)
+	deps.maintenanceService.On("Delete", ctx, "maint-1").Return(nil)
+	deps.repo.On("Delete", ctx, "tpl-1").Return(nil)
+
+	require.NoError(t, service.Delete(ctx, "tpl-1"))
+	require.Len(t, deps.auditRepository.entries, 1)
+	assert.Equal(t, audit_log.ActionDelete, deps.auditRepository.entries[0].Action)
+	deps.assertExpectations(t)
+}
@@ -0,0 +1,194 @@
+package maintenance_template
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+type sqlModel struct {
+	bun.BaseModel `bun:"table:maintenance_templates,alias:mt"`
+
+	ID             string    `bun:"id,pk"`
+	Name           string    `bun:"name,notnull"`
+	Title          string    `bun:"title,notnull"`
+	Description    string    `bun:"description"`
+	Strategy       string    `bun:"strategy,notnull"`
+	StartDateTime  *string   `bun:"start_date_time"`
+	EndDateTime    *string   `bun:"end_date_time"`
+	StartTime      *string   `bun:"start_time"`
+	EndTime        *string   `bun:"end_time"`
+	Weekdays       string    `bun:"weekdays"`      // Store as JSON string for compatibility
+	DaysOfMonth    string    `bun:"days_of_month"` // Store as JSON string for compatibility
+	IntervalDay    *int      `bun:"interval_day"`
+	Cron           *string   `bun:"cron"`
+	Timezone       *string   `bun:"timezone"`
+	Duration       *int      `bun:"duration"`
+	SuppressChecks bool      `bun:"suppress_checks,notnull,default:false"`
+	MaintenanceID  *string   `bun:"maintenance_id"`
+	CreatedAt      time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+	UpdatedAt      time.Time `bun:"updated_at,nullzero,notnull,default:current_timestamp"`
+}
+
+func toDomainModelFromSQL(sm *sqlModel) *Model {
+	// Parse JSON strings back to arrays
+	var weekdays []int
+	var daysOfMonth []int
+
+	if sm.Weekdays != "" {
+		json.Unmarshal([]byte(sm.Weekdays), &weekdays)
+	}
+	if sm.DaysOfMonth != "" {
+		json.Unmarshal([]byte(sm.DaysOfMonth), &daysOfMonth)
+	}
+
+	return &Model{
+		ID:             sm.ID,
+		Name:           sm.Name,
+		Title:          sm.Title,
+		Description:    sm.Description,
+		Strategy:       sm.Strategy,
+		StartDateTime:  sm.StartDateTime,
+		EndDateTime:    sm.EndDateTime,
+		StartTime:      sm.StartTime,
+		EndTime:        sm.EndTime,
+		Weekdays:       weekdays,
+		DaysOfMonth:    daysOfMonth,
+		IntervalDay:    sm.IntervalDay,
+		Cron:           sm.Cron,
+		Timezone:       sm.Timezone,
+		Duration:       sm.Duration,
+		SuppressChecks: sm.SuppressChecks,
+		MaintenanceID:  sm.MaintenanceID,
+		CreatedAt:      sm.CreatedAt,
+		UpdatedAt:      sm.UpdatedAt,
+	}
+}
+
+type SQLRepositoryImpl struct {
+	db *bun.DB
+}
+
+func NewSQLRepository(db *bun.DB) Repository {
+	return &SQLRepositoryImpl{db: db}
+}
+
+func (r *SQLRepositoryImpl) Create(ctx context.Context, entity *CreateUpdateDto) (*Model, error) {
+	// Marshal arrays to JSON strings
+	weekdaysJSON, _ := json.Marshal(entity.Weekdays)
+	daysOfMonthJSON, _ := json.Marshal(entity.DaysOfMonth)
+
+	sm := &sqlModel{
+		ID:             uuid.New().String(),
+		Name:           entity.Name,
+		Title:          entity.Title,
+		Description:    entity.Description,
+		Strategy:       entity.Strategy,
+		StartDateTime:  entity.StartDateTime,
+		EndDateTime:    entity.EndDateTime,
+		StartTime:      entity.StartTime,
+		EndTime:        entity.EndTime,
+		Weekdays:       string(weekdaysJSON),
+		DaysOfMonth:    string(daysOfMonthJSON),
+		IntervalDay:    entity.IntervalDay,
+		Cron:           entity.Cron,
+		Timezone:       entity.Timezone,
+		Duration:       entity.Duration,
+		SuppressChecks: entity.SuppressChecks,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+
+	_, err := r.db.NewInsert().Model(sm).Returning("*").Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return toDomainModelFromSQL(sm), nil
+}
+
+func (r *SQLRepositoryImpl) FindByID(ctx context.Context, id string) (*Model, error) {
+	sm := new(sqlModel)
+	err := r.db.NewSelect().Model(sm).Where("id = ?", id).Scan(ctx)
+	if err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return toDomainModelFromSQL(sm), nil
+}
+
+func (r *SQLRepositoryImpl) FindAll(ctx context.Context, page int, limit int, q string) ([]*Model, error) {
+	query := r.db.NewSelect().Model((*sqlModel)(nil))
+
+	if q != "" {
+		query = query.Where("LOWER(name) LIKE ? OR LOWER(title) LIKE ?", "%"+q+"%", "%"+q+"%")
+	}
+
+	query = query.Order("created_at DESC").
+		Limit(limit).
+		Offset(page * limit)
+
+	var sms []*sqlModel
+	err := query.Scan(ctx, &sms)
+	if err != nil {
+		return nil, err
+	}
+
+	var models []*Model
+	for _, sm := range sms {
+		models = append(models, toDomainModelFromSQL(sm))
+	}
+	return models, nil
+}
+
+func (r *SQLRepositoryImpl) UpdateFull(ctx context.Context, id string, entity *CreateUpdateDto) (*Model, error) {
+	// Marshal arrays to JSON strings
+	weekdaysJSON, _ := json.Marshal(entity.Weekdays)
+	daysOfMonthJSON, _ := json.Marshal(entity.DaysOfMonth)
+
+	_, err := r.db.NewUpdate().
+		Model((*sqlModel)(nil)).
+		Set("name = ?", entity.Name).
+		Set("title = ?", entity.Title).
+		Set("description = ?", entity.Description).
+		Set("strategy = ?", entity.Strategy).
+		Set("start_date_time = ?", entity.StartDateTime).
+		Set("end_date_time = ?", entity.EndDateTime).
+		Set("start_time = ?", entity.StartTime).
+		Set("end_time = ?", entity.EndTime).
+		Set("weekdays = ?", string(weekdaysJSON)).
+		Set("days_of_month = ?", string(daysOfMonthJSON)).
+		Set("interval_day = ?", entity.IntervalDay).
+		Set("cron = ?", entity.Cron).
+		Set("timezone = ?", entity.Timezone).
+		Set("duration = ?", entity.Duration).
+		Set("suppress_checks = ?", entity.SuppressChecks).
+		Set("updated_at = ?", time.Now()).
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.FindByID(ctx, id)
+}
+
+func (r *SQLRepositoryImpl) Delete(ctx context.Context, id string) error {
+	_, err := r.db.NewDelete().Model((*sqlModel)(nil)).Where("id = ?", id).Exec(ctx)
+	return err
+}
+
+func (r *SQLRepositoryImpl) SetMaintenanceID(ctx context.Context, id string, maintenanceID *string) error {
+	_, err := r.db.NewUpdate().
+		Model((*sqlModel)(nil)).
+		Set("maintenance_id = ?", maintenanceID).
+		Set("updated_at = ?", time.Now()).
+		Where("id = ?", id).
+		Exec(ctx)
+	return err
+}
@@ -0,0 +1,150 @@
+package metrics
+
+import (
+	"context"
+	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/producer"
+	"peekaping/internal/modules/queue"
+	"peekaping/internal/modules/shared"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// collectTimeout bounds the database, queue and Redis reads of a scrape
+const collectTimeout = 10 * time.Second
+
+// monitorStatuses are the status labels of the monitors gauge
+var monitorStatuses = map[shared.MonitorStatus]string{
+	shared.MonitorStatusDown:        "down",
+	shared.MonitorStatusUp:          "up",
+	shared.MonitorStatusPending:     "pending",
+	shared.MonitorStatusMaintenance: "maintenance",
+}
+
+// Collector reads the state of monitors, queues and the producer's schedule at scrape
+// time, so the API serves it without polling. A source failing to answer is logged and
+// left out of the scrape instead of failing it.
+type Collector struct {
+	monitorService monitor.Service
+	queueService   queue.Service
+	rdb            *redis.Client
+	logger         *zap.SugaredLogger
+	now            func() time.Time
+
+	activeMonitors *prometheus.Desc
+	monitors       *prometheus.Desc
+	queueTasks     *prometheus.Desc
+	queueLatency   *prometheus.Desc
+	queuePaused    *prometheus.Desc
+	schedulingLag  *prometheus.Desc
+}
+
+func NewCollector(
+	monitorService monitor.Service,
+	queueService queue.Service,
+	rdb *redis.Client,
+	logger *zap.SugaredLogger,
+) *Collector {
+	return &Collector{
+		monitorService: monitorService,
+		queueService:   queueService,
+		rdb:            rdb,
+		logger:         logger.Named("[metrics-collector]"),
+		now:            time.Now,
+
+		activeMonitors: prometheus.NewDesc("peekaping_monitors_active",
+			"Number of active monitors.", nil, nil),
+		monitors: prometheus.NewDesc("peekaping_monitors",
+			"Number of active monitors by current status (up, down, pending or maintenance).", []string{"status"}, nil),
+		queueTasks: prometheus.NewDesc("peekaping_queue_tasks",
+			"Number of tasks in a queue by state (pending, active, scheduled, retry or archived).", []string{"queue", "state"}, nil),
+		queueLatency: prometheus.NewDesc("peekaping_queue_latency_seconds",
+			"Time the oldest pending task of a queue has been waiting.", []string{"queue"}, nil),
+		queuePaused: prometheus.NewDesc("peekaping_queue_paused",
+			"Whether task processing of a queue is paused (1) or not (0).", []string{"queue"}, nil),
+		schedulingLag: prometheus.NewDesc("peekaping_producer_due_lag_seconds",
+			"Time the most overdue monitor has been waiting for the producer to enqueue its check.", nil, nil),
+	}
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.activeMonitors
+	ch <- c.monitors
+	ch <- c.queueTasks
+	ch <- c.queueLatency
+	ch <- c.queuePaused
+	ch <- c.schedulingLag
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+
+	c.collectMonitors(ctx, ch)
+	c.collectQueues(ctx, ch)
+	c.collectSchedulingLag(ctx, ch)
+}
+
+func (c *Collector) collectMonitors(ctx context.Context, ch chan<- prometheus.Metric) {
+	monitors, err := c.monitorService.FindActive(ctx)
+	if err != nil {
+		c.logger.Errorw("Failed to collect monitor metrics", "error", err)
+		return
+	}
+
+	counts := make(map[shared.MonitorStatus]int, len(monitorStatuses))
+	for _, m := range monitors {
+		counts[m.Status]++
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.activeMonitors, prometheus.GaugeValue, float64(len(monitors)))
+	for status, label := range monitorStatuses {
+		ch <- prometheus.MustNewConstMetric(c.monitors, prometheus.GaugeValue, float64(counts[status]), label)
+	}
+}
+
+func (c *Collector) collectQueues(ctx context.Context, ch chan<- prometheus.Metric) {
+	queues, err := c.queueService.ListQueues(ctx)
+	if err != nil {
+		c.logger.Errorw("Failed to collect queue metrics", "error", err)
+		return
+	}
+
+	for _, q := range queues {
+		for state, count := range map[string]int{
+			"pending":   q.Pending,
+			"active":    q.Active,
+			"scheduled": q.Scheduled,
+			"retry":     q.Retry,
+			"archived":  q.Archived,
+		} {
+			ch <- prometheus.MustNewConstMetric(c.queueTasks, prometheus.GaugeValue, float64(count), q.Queue, state)
+		}
+		ch <- prometheus.MustNewConstMetric(c.queueLatency, prometheus.GaugeValue, q.Latency.Seconds(), q.Queue)
+
+		paused := 0.0
+		if q.Paused {
+			paused = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.queuePaused, prometheus.GaugeValue, paused, q.Queue)
+	}
+}
+
+// collectSchedulingLag reports how long the earliest due monitor of the producer's due
+// set is overdue, 0 while the producer keeps up
+func (c *Collector) collectSchedulingLag(ctx context.Context, ch chan<- prometheus.Metric) {
+	due, err := c.rdb.ZRangeWithScores(ctx, producer.SchedDueKey, 0, 0).Result()
+	if err != nil {
+		c.logger.Errorw("Failed to collect scheduling lag", "error", err)
+		return
+	}
+
+	var lag time.Duration
+	if len(due) > 0 {
+		lag = max(c.now().Sub(time.UnixMilli(int64(due[0].Score))), 0)
+	}
+	ch <- prometheus.MustNewConstMetric(c.schedulingLag, prometheus.GaugeValue, lag.Seconds())
+}
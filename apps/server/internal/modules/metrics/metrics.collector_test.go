@@ -0,0 +1,126 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/producer"
+	"peekaping/internal/modules/queue"
+	"peekaping/internal/modules/shared"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type fakeMonitorService struct {
+	monitor.Service
+	monitors []*monitor.Model
+	err      error
+}
+
+func (f *fakeMonitorService) FindActive(ctx context.Context) ([]*monitor.Model, error) {
+	return f.monitors, f.err
+}
+
+type fakeQueueService struct {
+	queue.Service
+	queues []*queue.QueueInfo
+}
+
+func (f *fakeQueueService) ListQueues(ctx context.Context) ([]*queue.QueueInfo, error) {
+	return f.queues, nil
+}
+
+func setupCollector(t *testing.T, monitors *fakeMonitorService, queues *fakeQueueService) (*Collector, *redis.Client) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	t.Cleanup(mr.Close)
+
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	return NewCollector(monitors, queues, rdb, zap.NewNop().Sugar()), rdb
+}
+
+func TestCollector_Collect(t *testing.T) {
+	now := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	monitors := &fakeMonitorService{monitors: []*monitor.Model{
+		{ID: "m1", Status: shared.MonitorStatusUp},
+		{ID: "m2", Status: shared.MonitorStatusUp},
+		{ID: "m3", Status: shared.MonitorStatusDown},
+		{ID: "m4", Status: shared.MonitorStatusPending},
+	}}
+	queues := &fakeQueueService{queues: []*queue.QueueInfo{
+		{Queue: "healthcheck", Pending: 12, Active: 4, Scheduled: 1, Retry: 2, Latency: 3 * time.Second},
+		{Queue: "ingester", Pending: 0, Active: 1, Archived: 5, Paused: true},
+	}}
+	collector, rdb := setupCollector(t, monitors, queues)
+	collector.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	require.NoError(t, rdb.ZAdd(ctx, producer.SchedDueKey,
+		redis.Z{Score: float64(now.Add(-1500 * time.Millisecond).UnixMilli()), Member: "m1"},
+		redis.Z{Score: float64(now.Add(time.Minute).UnixMilli()), Member: "m2"},
+	).Err())
+
+	expected := `
+# HELP peekaping_monitors Number of active monitors by current status (up, down, pending or maintenance).
+# TYPE peekaping_monitors gauge
+peekaping_monitors{status="down"} 1
+peekaping_monitors{status="maintenance"} 0
+peekaping_monitors{status="pending"} 1
+peekaping_monitors{status="up"} 2
+# HELP peekaping_monitors_active Number of active monitors.
+# TYPE peekaping_monitors_active gauge
+peekaping_monitors_active 4
+# HELP peekaping_producer_due_lag_seconds Time the most overdue monitor has been waiting for the producer to enqueue its check.
+# TYPE peekaping_producer_due_lag_seconds gauge
+peekaping_producer_due_lag_seconds 1.5
+# HELP peekaping_queue_latency_seconds Time the oldest pending task of a queue has been waiting.
+# TYPE peekaping_queue_latency_seconds gauge
+peekaping_queue_latency_seconds{queue="healthcheck"} 3
+peekaping_queue_latency_seconds{queue="ingester"} 0
+# HELP peekaping_queue_paused Whether task processing of a queue is paused (1) or not (0).
+# TYPE peekaping_queue_paused gauge
+peekaping_queue_paused{queue="healthcheck"} 0
+peekaping_queue_paused{queue="ingester"} 1
+# HELP peekaping_queue_tasks Number of tasks in a queue by state (pending, active, scheduled, retry or archived).
+# TYPE peekaping_queue_tasks gauge
+peekaping_queue_tasks{queue="healthcheck",state="active"} 4
+peekaping_queue_tasks{queue="healthcheck",state="archived"} 0
+peekaping_queue_tasks{queue="healthcheck",state="pending"} 12
+peekaping_queue_tasks{queue="healthcheck",state="retry"} 2
+peekaping_queue_tasks{queue="healthcheck",state="scheduled"} 1
+peekaping_queue_tasks{queue="ingester",state="active"} 1
+peekaping_queue_tasks{queue="ingester",state="archived"} 5
+peekaping_queue_tasks{queue="ingester",state="pending"} 0
+peekaping_queue_tasks{queue="ingester",state="retry"} 0
+peekaping_queue_tasks{queue="ingester",state="scheduled"} 0
+`
+	assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected)))
+
+	t.Run("no lag while the producer keeps up", func(t *testing.T) {
+		require.NoError(t, rdb.ZRem(ctx, producer.SchedDueKey, "m1").Err())
+		expected := `
+# HELP peekaping_producer_due_lag_seconds Time the most overdue monitor has been waiting for the producer to enqueue its check.
+# TYPE peekaping_producer_due_lag_seconds gauge
+peekaping_producer_due_lag_seconds 0
+`
+		assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected), "peekaping_producer_due_lag_seconds"))
+	})
+
+	t.Run("a failing source is left out of the scrape", func(t *testing.T) {
+		monitors.err = errors.New("database is down")
+		assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(""), "peekaping_monitors", "peekaping_monitors_active"))
+		// The queue and scheduling lag metrics are still collected
+		assert.Equal(t, 15, testutil.CollectAndCount(collector))
+	})
+}
@@ -0,0 +1,11 @@
+package metrics
+
+import (
+	"go.uber.org/dig"
+)
+
+// RegisterDependencies registers the collector of the API's /metrics endpoint, which is
+// only registered with Prometheus when metrics are enabled
+func RegisterDependencies(container *dig.Container) {
+	container.Provide(NewCollector)
+}
@@ -0,0 +1,99 @@
+package monitor
+
+import (
+	"context"
+	"peekaping/internal/modules/heartbeat"
+	"strconv"
+	"time"
+)
+
+// ImportanceWindowSettingKey is the global setting holding the heartbeat importance window
+// in seconds. Status changes reverted within the window are left out of a monitor's event
+// log, 0 (the default) lists every important heartbeat.
+const ImportanceWindowSettingKey = "heartbeat_importance_window_seconds"
+
+const (
+	// eventBatchSize is how many important heartbeats are read at a time to fill a page
+	eventBatchSize = 200
+	// maxScannedEvents bounds the important heartbeats read for one page of events
+	maxScannedEvents = 5000
+)
+
+// importanceWindow reads the configured importance window, 0 when unset or invalid
+func (mr *MonitorServiceImpl) importanceWindow(ctx context.Context) time.Duration {
+	if mr.settingService == nil {
+		return 0
+	}
+	setting, err := mr.settingService.GetByKey(ctx, ImportanceWindowSettingKey)
+	if err != nil {
+		mr.logger.Warnw("Failed to read heartbeat importance window setting", "error", err)
+		return 0
+	}
+	if setting == nil {
+		return 0
+	}
+	seconds, err := strconv.Atoi(setting.Value)
+	if err != nil || seconds < 0 {
+		mr.logger.Warnw("Invalid heartbeat importance window setting", "value", setting.Value)
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// findCollapsedEvents returns a page of important heartbeats with flaps shorter than window
+// left out. Heartbeats are read newest first until the page and two older events, which
+// decide whether the oldest ones on the page are part of a flap, are known.
+func (mr *MonitorServiceImpl) findCollapsedEvents(ctx context.Context, id string, limit, page int, reverse bool, window time.Duration) ([]*heartbeat.Model, error) {
+	important := true
+	needed := (page+1)*limit + 2
+
+	var events, collapsed []*heartbeat.Model
+	for batch := 0; ; batch++ {
+		fetched, err := mr.heartbeatService.FindByMonitorIDPaginated(ctx, id, eventBatchSize, batch, &important, false)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, fetched...)
+		collapsed = collapseFlaps(events, window)
+		if len(fetched) < eventBatchSize || len(collapsed) >= needed || len(events) >= maxScannedEvents {
+			break
+		}
+	}
+
+	start := page * limit
+	if start >= len(collapsed) {
+		return nil, nil
+	}
+	end := min(start+limit, len(collapsed))
+	result := append([]*heartbeat.Model(nil), collapsed[start:end]...)
+
+	if reverse {
+		for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+			result[i], result[j] = result[j], result[i]
+		}
+	}
+	return result, nil
+}
+
+// collapseFlaps drops pairs of important heartbeats where the monitor changed status and
+// changed back within window, e.g. a 10 second blip of a monitor that is otherwise up.
+// events are ordered newest first, as is the result.
+func collapseFlaps(events []*heartbeat.Model, window time.Duration) []*heartbeat.Model {
+	kept := make([]*heartbeat.Model, 0, len(events))
+	for i := len(events) - 1; i >= 0; i-- {
+		event := events[i]
+		n := len(kept)
+		// The status before the last kept change is needed to tell that event reverts it
+		if n >= 2 && event.Status == kept[n-2].Status && event.Status != kept[n-1].Status &&
+			event.Time.Sub(kept[n-1].Time) < window {
+			kept = kept[:n-1]
+			continue
+		}
+		kept = append(kept, event)
+	}
+
+	for i, j := 0, len(kept)-1; i < j; i, j = i+1, j-1 {
+		kept[i], kept[j] = kept[j], kept[i]
+	}
+	return kept
+}
@@ -1,13 +1,19 @@
 package monitor
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
+	"peekaping/internal/modules/audit_log"
+	"peekaping/internal/modules/healthcheck/executor"
+	"peekaping/internal/modules/heartbeat"
 	"peekaping/internal/modules/monitor_notification"
 	"peekaping/internal/modules/monitor_tag"
 	"peekaping/internal/modules/monitor_tls_info"
+	"peekaping/internal/modules/tag"
 	"peekaping/internal/utils"
+	"slices"
 	"strings"
 	"time"
 
@@ -21,6 +27,9 @@ type MonitorController struct {
 	monitorNotificationService monitor_notification.Service
 	monitorTagService          monitor_tag.Service
 	tlsInfoService             monitor_tls_info.Service
+	auditLogService            audit_log.Service
+	tagService                 tag.Service
+	channelNames               NotificationChannelNames
 }
 
 func NewMonitorController(
@@ -29,6 +38,9 @@ func NewMonitorController(
 	monitorNotificationService monitor_notification.Service,
 	monitorTagService monitor_tag.Service,
 	tlsInfoService monitor_tls_info.Service,
+	auditLogService audit_log.Service,
+	tagService tag.Service,
+	channelNames NotificationChannelNames,
 ) *MonitorController {
 	utils.Validate.RegisterStructValidation(CreateUpdateDtoStructLevelValidation, CreateUpdateDto{})
 
@@ -38,6 +50,25 @@ func NewMonitorController(
 		monitorNotificationService,
 		monitorTagService,
 		tlsInfoService,
+		auditLogService,
+		tagService,
+		channelNames,
+	}
+}
+
+// recordAudit writes an audit log entry for a monitor change. Failures are only
+// logged so that auditing never fails the request itself.
+func (ic *MonitorController) recordAudit(ctx *gin.Context, id, action string, before, after *Model) {
+	var beforeValue, afterValue any
+	if before != nil {
+		beforeValue = before
+	}
+	if after != nil {
+		afterValue = after
+	}
+
+	if _, err := ic.auditLogService.Record(ctx, audit_log.EntityMonitor, id, action, beforeValue, afterValue); err != nil {
+		ic.logger.Errorw("Failed to record monitor audit log", "id", id, "error", err)
 	}
 }
 
@@ -134,50 +165,86 @@ func (ic *MonitorController) Create(ctx *gin.Context) {
 		return
 	}
 
-	if err := utils.Validate.Struct(monitor); err != nil {
+	if err := ic.validateCreate(ctx, monitor); err != nil {
 		ctx.JSON(http.StatusBadRequest, utils.NewFailResponse(err.Error()))
 		return
 	}
 
+	createdMonitor, err := ic.createMonitor(ctx, monitor)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, utils.NewSuccessResponse("Monitor created successfully", createdMonitor))
+}
+
+// validateCreate checks a new monitor: its struct tags, the config of its type, the
+// children of groups, the result expression, the message templates and the parent
+func (ic *MonitorController) validateCreate(ctx context.Context, monitor *CreateUpdateDto) error {
+	if err := utils.Validate.Struct(monitor); err != nil {
+		return err
+	}
+
 	// Validate monitor type and config
 	if err := ic.monitorService.ValidateMonitorConfig(monitor.Type, monitor.Config); err != nil {
-		ctx.JSON(http.StatusBadRequest, utils.NewFailResponse(fmt.Sprintf("Invalid monitor configuration: %v", err)))
-		return
+		return fmt.Errorf("Invalid monitor configuration: %v", err)
+	}
+
+	if err := ic.validateGroupChildren(ctx, "", monitor.Type, monitor.Config); err != nil {
+		return err
+	}
+
+	if err := validateResultExpression(monitor.ResultExpression); err != nil {
+		return err
 	}
 
+	if err := validateMessageTemplates(monitor.UpMessage, monitor.DownMessage); err != nil {
+		return err
+	}
+
+	return ic.validateParent(ctx, "", monitor.ParentId)
+}
+
+// createMonitor creates a validated monitor with its notification and tag relations and
+// records it in the audit log. Errors are logged.
+func (ic *MonitorController) createMonitor(ctx *gin.Context, monitor *CreateUpdateDto) (*Model, error) {
 	createdMonitor, err := ic.monitorService.Create(ctx, monitor)
 	if err != nil {
 		ic.logger.Errorw("Failed to create monitor", "error", err)
-		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
-		return
+		return nil, err
 	}
 	ic.logger.Infof("Created monitor: %+v\n", createdMonitor)
 
 	// Handle multiple notification IDs
-	if len(monitor.NotificationIds) > 0 {
-		for _, notificationId := range monitor.NotificationIds {
-			_, err = ic.monitorNotificationService.Create(ctx, createdMonitor.ID, notificationId)
-			if err != nil {
-				ic.logger.Errorw("Failed to create monitor-notification record", "error", err)
-				ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
-				return
-			}
+	for _, notificationId := range monitor.NotificationIds {
+		if _, err := ic.monitorNotificationService.Create(ctx, createdMonitor.ID, notificationId, notificationSettings(monitor, notificationId)); err != nil {
+			ic.logger.Errorw("Failed to create monitor-notification record", "error", err)
+			return nil, err
 		}
 	}
 
 	// Handle multiple tag IDs
-	if len(monitor.TagIds) > 0 {
-		for _, tagId := range monitor.TagIds {
-			_, err = ic.monitorTagService.Create(ctx, createdMonitor.ID, tagId)
-			if err != nil {
-				ic.logger.Errorw("Failed to create monitor-tag record", "error", err)
-				ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
-				return
-			}
+	for _, tagId := range monitor.TagIds {
+		if _, err := ic.monitorTagService.Create(ctx, createdMonitor.ID, tagId); err != nil {
+			ic.logger.Errorw("Failed to create monitor-tag record", "error", err)
+			return nil, err
 		}
 	}
 
-	ctx.JSON(http.StatusCreated, utils.NewSuccessResponse("Monitor created successfully", createdMonitor))
+	ic.recordAudit(ctx, createdMonitor.ID, audit_log.ActionCreate, nil, createdMonitor)
+	return createdMonitor, nil
+}
+
+// notificationSettings returns the options the monitor gives a notification channel
+func notificationSettings(monitor *CreateUpdateDto, notificationID string) monitor_notification.Settings {
+	return monitor_notification.Settings{
+		MinCriticality:    monitor.NotificationMinCriticality[notificationID],
+		EventTypes:        monitor.NotificationEventTypes[notificationID],
+		DigestOnly:        monitor.NotificationDigestOnly[notificationID],
+		EscalateAfter:     monitor.NotificationEscalateAfter[notificationID],
+		NotificationLimit: monitor.NotificationLimit[notificationID],
+	}
 }
 
 // @Router		/monitors/{id} [get]
@@ -213,8 +280,28 @@ func (ic *MonitorController) FindByID(ctx *gin.Context) {
 		return
 	}
 	notificationIds := make([]string, 0, len(notificationRels))
+	notificationMinCriticality := make(map[string]string)
+	notificationEventTypes := make(map[string][]string)
+	notificationDigestOnly := make(map[string]bool)
+	notificationEscalateAfter := make(map[string]int)
+	notificationLimit := make(map[string]int)
 	for _, rel := range notificationRels {
 		notificationIds = append(notificationIds, rel.NotificationID)
+		if rel.MinCriticality != "" {
+			notificationMinCriticality[rel.NotificationID] = rel.MinCriticality
+		}
+		if len(rel.EventTypes) > 0 {
+			notificationEventTypes[rel.NotificationID] = rel.EventTypes
+		}
+		if rel.DigestOnly {
+			notificationDigestOnly[rel.NotificationID] = true
+		}
+		if rel.EscalateAfter > 0 {
+			notificationEscalateAfter[rel.NotificationID] = rel.EscalateAfter
+		}
+		if rel.NotificationLimit > 0 {
+			notificationLimit[rel.NotificationID] = rel.NotificationLimit
+		}
 	}
 
 	// Fetch tag_ids
@@ -231,22 +318,42 @@ func (ic *MonitorController) FindByID(ctx *gin.Context) {
 
 	// Compose response with notification_ids and tag_ids
 	response := MonitorResponseDto{
-		ID:              monitor.ID,
-		Name:            monitor.Name,
-		Interval:        monitor.Interval,
-		Timeout:         monitor.Timeout,
-		Type:            monitor.Type,
-		Active:          monitor.Active,
-		MaxRetries:      monitor.MaxRetries,
-		RetryInterval:   monitor.RetryInterval,
-		ResendInterval:  monitor.ResendInterval,
-		Status:          int(monitor.Status),
-		CreatedAt:       monitor.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:       monitor.UpdatedAt.Format(time.RFC3339),
-		NotificationIds: notificationIds,
-		TagIds:          tagIds,
-		ProxyId:         monitor.ProxyId,
-		Config:          monitor.Config,
+		ID:                         monitor.ID,
+		Name:                       monitor.Name,
+		Interval:                   monitor.Interval,
+		Timeout:                    monitor.Timeout,
+		Type:                       monitor.Type,
+		Active:                     monitor.Active,
+		MaxRetries:                 monitor.MaxRetries,
+		RetryInterval:              monitor.RetryInterval,
+		RetryBackoff:               monitor.RetryBackoff,
+		ResendInterval:             monitor.ResendInterval,
+		WarmupChecks:               monitor.WarmupChecks,
+		LatencyLimit:               monitor.LatencyLimit,
+		LatencyChecks:              monitor.LatencyChecks,
+		Criticality:                monitor.Criticality,
+		ResultExpression:           monitor.ResultExpression,
+		UpMessage:                  monitor.UpMessage,
+		DownMessage:                monitor.DownMessage,
+		Status:                     int(monitor.Status),
+		CreatedAt:                  monitor.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:                  monitor.UpdatedAt.Format(time.RFC3339),
+		NotificationIds:            notificationIds,
+		NotificationMinCriticality: notificationMinCriticality,
+		NotificationEventTypes:     notificationEventTypes,
+		NotificationDigestOnly:     notificationDigestOnly,
+		NotificationEscalateAfter:  notificationEscalateAfter,
+		NotificationLimit:          notificationLimit,
+		TagIds:                     tagIds,
+		ProxyId:                    monitor.ProxyId,
+		FallbackProxyIds:           monitor.FallbackProxyIds,
+		NoProxy:                    monitor.NoProxy,
+		Serialize:                  monitor.Serialize,
+		ConfirmTransition:          monitor.ConfirmTransition,
+		ParentId:                   monitor.ParentId,
+		SkipWhenParentDown:         monitor.SkipWhenParentDown,
+		ParentDownTimeout:          monitor.ParentDownTimeout,
+		Config:                     monitor.Config,
 	}
 
 	ctx.JSON(http.StatusOK, utils.NewSuccessResponse("success", response))
@@ -285,6 +392,33 @@ func (ic *MonitorController) UpdateFull(ctx *gin.Context) {
 		return
 	}
 
+	if err := ic.validateGroupChildren(ctx, id, monitor.Type, monitor.Config); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewFailResponse(err.Error()))
+		return
+	}
+
+	if err := validateResultExpression(monitor.ResultExpression); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewFailResponse(err.Error()))
+		return
+	}
+
+	if err := validateMessageTemplates(monitor.UpMessage, monitor.DownMessage); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewFailResponse(err.Error()))
+		return
+	}
+
+	if err := ic.validateParent(ctx, id, monitor.ParentId); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewFailResponse(err.Error()))
+		return
+	}
+
+	previousMonitor, err := ic.monitorService.FindByID(ctx, id)
+	if err != nil {
+		ic.logger.Errorw("Failed to fetch monitor before update", "error", err)
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
+		return
+	}
+
 	updatedMonitor, err := ic.monitorService.UpdateFull(ctx, id, &monitor)
 	if err != nil {
 		ic.logger.Errorw("Failed to update monitor", "error", err)
@@ -306,7 +440,7 @@ func (ic *MonitorController) UpdateFull(ctx *gin.Context) {
 
 	// Create new notification relations
 	for _, notificationId := range monitor.NotificationIds {
-		_, err = ic.monitorNotificationService.Create(ctx, id, notificationId)
+		_, err := ic.monitorNotificationService.Create(ctx, id, notificationId, notificationSettings(&monitor, notificationId))
 		if err != nil {
 			ic.logger.Errorw("Failed to create monitor-notification record", "error", err)
 			ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
@@ -332,6 +466,8 @@ func (ic *MonitorController) UpdateFull(ctx *gin.Context) {
 		}
 	}
 
+	ic.recordAudit(ctx, id, audit_log.ActionUpdate, previousMonitor, updatedMonitor)
+
 	ctx.JSON(http.StatusOK, utils.NewSuccessResponse("Monitor updated successfully", updatedMonitor))
 }
 
@@ -362,6 +498,41 @@ func (ic *MonitorController) UpdatePartial(ctx *gin.Context) {
 			ctx.JSON(http.StatusBadRequest, utils.NewFailResponse(fmt.Sprintf("Invalid monitor configuration: %v", err)))
 			return
 		}
+		if err := ic.validateGroupChildren(ctx, id, *monitor.Type, *monitor.Config); err != nil {
+			ctx.JSON(http.StatusBadRequest, utils.NewFailResponse(err.Error()))
+			return
+		}
+	}
+
+	if monitor.ResultExpression != nil {
+		if err := validateResultExpression(*monitor.ResultExpression); err != nil {
+			ctx.JSON(http.StatusBadRequest, utils.NewFailResponse(err.Error()))
+			return
+		}
+	}
+
+	for _, template := range []*string{monitor.UpMessage, monitor.DownMessage} {
+		if template == nil {
+			continue
+		}
+		if err := validateMessageTemplates(*template); err != nil {
+			ctx.JSON(http.StatusBadRequest, utils.NewFailResponse(err.Error()))
+			return
+		}
+	}
+
+	if monitor.ParentId != nil {
+		if err := ic.validateParent(ctx, id, *monitor.ParentId); err != nil {
+			ctx.JSON(http.StatusBadRequest, utils.NewFailResponse(err.Error()))
+			return
+		}
+	}
+
+	previousMonitor, err := ic.monitorService.FindByID(ctx, id)
+	if err != nil {
+		ic.logger.Errorw("Failed to fetch monitor before update", "error", err)
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
+		return
 	}
 
 	updatedMonitor, err := ic.monitorService.UpdatePartial(ctx, id, &monitor, false)
@@ -403,13 +574,60 @@ func (ic *MonitorController) UpdatePartial(ctx *gin.Context) {
 		// Add new relations not already present
 		for _, nid := range monitor.NotificationIds {
 			if _, found := existingMap[nid]; !found {
-				if _, err := ic.monitorNotificationService.Create(ctx, id, nid); err != nil {
+				settings := monitor_notification.Settings{
+					MinCriticality:    monitor.NotificationMinCriticality[nid],
+					EventTypes:        monitor.NotificationEventTypes[nid],
+					DigestOnly:        monitor.NotificationDigestOnly[nid],
+					EscalateAfter:     monitor.NotificationEscalateAfter[nid],
+					NotificationLimit: monitor.NotificationLimit[nid],
+				}
+				if _, err := ic.monitorNotificationService.Create(ctx, id, nid, settings); err != nil {
 					ic.logger.Warnw("Failed to create monitor-notification relation", "error", err)
 				}
 			}
 		}
 	}
 
+	// Apply the provided options to the notification relations, new ones were created with them
+	if len(monitor.NotificationMinCriticality) > 0 || len(monitor.NotificationEventTypes) > 0 || len(monitor.NotificationDigestOnly) > 0 ||
+		len(monitor.NotificationEscalateAfter) > 0 || len(monitor.NotificationLimit) > 0 {
+		existing, err := ic.monitorNotificationService.FindByMonitorID(ctx, id)
+		if err != nil {
+			ic.logger.Errorw("Failed to fetch monitor-notification relations", "error", err)
+			ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
+			return
+		}
+
+		for _, rel := range existing {
+			nid := rel.NotificationID
+			if level, found := monitor.NotificationMinCriticality[nid]; found && level != rel.MinCriticality {
+				if err := ic.monitorNotificationService.SetMinCriticality(ctx, rel.ID, level); err != nil {
+					ic.logger.Warnw("Failed to update monitor-notification minimum criticality", "error", err)
+				}
+			}
+			if eventTypes, found := monitor.NotificationEventTypes[nid]; found && !slices.Equal(eventTypes, rel.EventTypes) {
+				if err := ic.monitorNotificationService.SetEventTypes(ctx, rel.ID, eventTypes); err != nil {
+					ic.logger.Warnw("Failed to update monitor-notification event types", "error", err)
+				}
+			}
+			if digestOnly, found := monitor.NotificationDigestOnly[nid]; found && digestOnly != rel.DigestOnly {
+				if err := ic.monitorNotificationService.SetDigestOnly(ctx, rel.ID, digestOnly); err != nil {
+					ic.logger.Warnw("Failed to update monitor-notification digest only", "error", err)
+				}
+			}
+			if escalateAfter, found := monitor.NotificationEscalateAfter[nid]; found && escalateAfter != rel.EscalateAfter {
+				if err := ic.monitorNotificationService.SetEscalateAfter(ctx, rel.ID, escalateAfter); err != nil {
+					ic.logger.Warnw("Failed to update monitor-notification escalation", "error", err)
+				}
+			}
+			if limit, found := monitor.NotificationLimit[nid]; found && limit != rel.NotificationLimit {
+				if err := ic.monitorNotificationService.SetNotificationLimit(ctx, rel.ID, limit); err != nil {
+					ic.logger.Warnw("Failed to update monitor-notification limit", "error", err)
+				}
+			}
+		}
+	}
+
 	// Handle tag IDs if they are being updated
 	if len(monitor.TagIds) > 0 {
 		// Replace all monitor-tag relations in an optimized way
@@ -449,6 +667,8 @@ func (ic *MonitorController) UpdatePartial(ctx *gin.Context) {
 		}
 	}
 
+	ic.recordAudit(ctx, id, audit_log.ActionUpdate, previousMonitor, updatedMonitor)
+
 	ctx.JSON(http.StatusOK, utils.NewSuccessResponse("Monitor updated successfully", updatedMonitor))
 }
 
@@ -465,13 +685,24 @@ func (ic *MonitorController) UpdatePartial(ctx *gin.Context) {
 func (ic *MonitorController) Delete(ctx *gin.Context) {
 	id := ctx.Param("id")
 
-	err := ic.monitorService.Delete(ctx, id)
+	previousMonitor, err := ic.monitorService.FindByID(ctx, id)
+	if err != nil {
+		ic.logger.Errorw("Failed to fetch monitor before delete", "error", err)
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
+		return
+	}
+
+	err = ic.monitorService.Delete(ctx, id)
 	if err != nil {
 		ic.logger.Errorw("Failed to delete monitor", "error", err)
 		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
 		return
 	}
 
+	if previousMonitor != nil {
+		ic.recordAudit(ctx, id, audit_log.ActionDelete, previousMonitor, nil)
+	}
+
 	ctx.JSON(http.StatusOK, utils.NewSuccessResponse[any]("Monitor deleted successfully", nil))
 }
 
@@ -534,6 +765,179 @@ func (ic *MonitorController) FindByMonitorIDPaginated(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, utils.NewSuccessResponse("success", results))
 }
 
+// @Router	/monitors/{id}/heartbeats/export [get]
+// @Summary	Export the heartbeats of a monitor as CSV or JSON
+// @Description	Streams the timestamp, status, ping and message of every heartbeat in the time range, oldest first
+// @Tags		Monitors
+// @Produce	text/csv,json
+// @Security BearerAuth
+// @Param	id	path	string	true	"Monitor ID"
+// @Param	format	query	string	false	"csv (default) or json"
+// @Param	from	query	string	false	"Start time (RFC3339, default all heartbeats)"
+// @Param	to	query	string	false	"End time (RFC3339, default now)"
+// @Success	200	{array}	heartbeat.ExportRow
+// @Failure	400	{object}	utils.APIError[any]
+// @Failure	404	{object}	utils.APIError[any]
+// @Failure	500	{object}	utils.APIError[any]
+func (ic *MonitorController) ExportHeartbeats(ctx *gin.Context) {
+	id := ctx.Param("id")
+	format := ctx.DefaultQuery("format", heartbeat.ExportFormatCSV)
+
+	var from time.Time
+	var err error
+	if fromStr := ctx.Query("from"); fromStr != "" {
+		from, err = time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, utils.NewFailResponse("Invalid 'from' parameter (must be RFC3339)"))
+			return
+		}
+	}
+
+	to := time.Now().UTC()
+	if toStr := ctx.Query("to"); toStr != "" {
+		to, err = time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, utils.NewFailResponse("Invalid 'to' parameter (must be RFC3339)"))
+			return
+		}
+	}
+
+	if to.Before(from) {
+		ctx.JSON(http.StatusBadRequest, utils.NewFailResponse("'to' must be after 'from'"))
+		return
+	}
+
+	writer, err := heartbeat.NewExportWriter(format, ctx.Writer)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewFailResponse(err.Error()))
+		return
+	}
+
+	monitor, err := ic.monitorService.FindByID(ctx, id)
+	if err != nil {
+		ic.logger.Errorw("Failed to fetch monitor", "error", err)
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
+		return
+	}
+	if monitor == nil {
+		ctx.JSON(http.StatusNotFound, utils.NewFailResponse("Monitor not found"))
+		return
+	}
+
+	ctx.Header("Content-Type", writer.ContentType())
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="heartbeats-%s.%s"`, id, format))
+	ctx.Status(http.StatusOK)
+
+	// The response is already underway, a failure can only cut it short
+	err = ic.monitorService.ExportHeartbeats(ctx, id, from, to, writer.Write)
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		ic.logger.Errorw("Failed to export heartbeats", "id", id, "error", err)
+	}
+}
+
+// @Router		/monitors/export [get]
+// @Summary		Export all monitors as portable JSON definitions
+// @Description	Tags and notification channels are referenced by name. Proxies and parent monitors are left out.
+// @Tags			Monitors
+// @Produce		json
+// @Security  JwtAuth
+// @Security  ApiKeyAuth
+// @Success		200	{array}		ExportDto
+// @Failure		500	{object}	utils.APIError[any]
+func (ic *MonitorController) ExportMonitors(ctx *gin.Context) {
+	exported, err := ic.exportMonitors(ctx)
+	if err != nil {
+		ic.logger.Errorw("Failed to export monitors", "error", err)
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
+		return
+	}
+
+	ctx.Header("Content-Disposition", `attachment; filename="monitors.json"`)
+	ctx.JSON(http.StatusOK, exported)
+}
+
+// @Router		/monitors/import [post]
+// @Summary		Import monitors from JSON definitions
+// @Description	Accepts the output of the export. Every monitor is validated and created on its own, the result of each is reported by its index.
+// @Tags			Monitors
+// @Produce		json
+// @Accept		json
+// @Security  JwtAuth
+// @Security  ApiKeyAuth
+// @Param     body body   []ExportDto  true  "Monitor definitions"
+// @Success		200	{object}	utils.ApiResponse[[]ImportResultDto]
+// @Failure		400	{object}	utils.APIError[any]
+// @Failure		500	{object}	utils.APIError[any]
+func (ic *MonitorController) ImportMonitors(ctx *gin.Context) {
+	var items []ExportDto
+	if err := ctx.ShouldBindJSON(&items); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewFailResponse(err.Error()))
+		return
+	}
+	if len(items) > maxImportMonitors {
+		ctx.JSON(http.StatusBadRequest, utils.NewFailResponse(fmt.Sprintf("At most %d monitors can be imported at once", maxImportMonitors)))
+		return
+	}
+
+	results, err := ic.Import(ctx, items)
+	if err != nil {
+		ic.logger.Errorw("Failed to import monitors", "error", err)
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
+		return
+	}
+
+	imported := 0
+	for _, result := range results {
+		if result.Success {
+			imported++
+		}
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewSuccessResponse(fmt.Sprintf("Imported %d of %d monitors", imported, len(items)), results))
+}
+
+// Import validates and creates every monitor definition on its own, resolving tags and
+// notification channels by name. The result of each is reported by its index, the error
+// is only returned when the notification channels can't be loaded.
+func (ic *MonitorController) Import(ctx *gin.Context, items []ExportDto) ([]ImportResultDto, error) {
+	channelNames, err := ic.channelNames.ChannelNames(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch notification channels: %w", err)
+	}
+	channelIDs := channelIDsByName(channelNames)
+
+	results := make([]ImportResultDto, 0, len(items))
+	for i := range items {
+		result := ImportResultDto{Index: i, Name: items[i].Name}
+
+		dto, err := ic.toCreateDto(ctx, &items[i], channelIDs)
+		if err == nil {
+			err = ic.validateCreate(ctx, dto)
+		}
+		if err != nil {
+			result.Error = err.Error()
+			results = append(results, result)
+			continue
+		}
+
+		created, err := ic.createMonitor(ctx, dto)
+		if err != nil {
+			result.Error = "Internal server error"
+			results = append(results, result)
+			continue
+		}
+
+		result.Success = true
+		result.ID = created.ID
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
 // @Router /monitors/{id}/stats/points [get]
 // @Summary Get monitor stat points (ping/up/down) from stats tables
 // @Tags Monitors
@@ -738,3 +1142,98 @@ func (ic *MonitorController) GetTLSInfo(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, utils.NewSuccessResponse("success", tlsInfo))
 }
+
+// validateResultExpression compiles a monitor's result expression so mistakes are
+// reported when saving rather than on every check in the worker
+func validateResultExpression(expression string) error {
+	if expression == "" {
+		return nil
+	}
+	_, err := executor.CompileResultExpression(expression)
+	return err
+}
+
+// maxParentDepth bounds the walk up a chain of parent monitors
+const maxParentDepth = 32
+
+// validateParent checks that the parent of monitor id exists and that following the
+// parents from it never leads back to the monitor. id is empty for new monitors.
+func (ic *MonitorController) validateParent(ctx context.Context, id, parentID string) error {
+	if parentID == "" {
+		return nil
+	}
+	if parentID == id {
+		return errors.New("a monitor can't be its own parent")
+	}
+
+	current := parentID
+	for depth := 0; depth < maxParentDepth; depth++ {
+		parent, err := ic.monitorService.FindByID(ctx, current)
+		if err != nil {
+			return err
+		}
+		if parent == nil {
+			if current == parentID {
+				return errors.New("parent monitor not found")
+			}
+			return nil
+		}
+		if parent.ParentId == "" {
+			return nil
+		}
+		if id != "" && parent.ParentId == id {
+			return errors.New("parent monitor depends on this monitor")
+		}
+		current = parent.ParentId
+	}
+	return errors.New("parent monitor chain is too deep")
+}
+
+// validateGroupChildren checks that every child of a group monitor exists and isn't the
+// group itself. id is empty for new monitors.
+func (ic *MonitorController) validateGroupChildren(ctx context.Context, id, monitorType, config string) error {
+	if monitorType != "group" {
+		return nil
+	}
+	cfg, err := executor.GenericUnmarshal[executor.GroupConfig](config)
+	if err != nil {
+		return err
+	}
+
+	childIDs := make([]string, 0, len(cfg.Children))
+	for _, child := range cfg.Children {
+		if id != "" && child.MonitorID == id {
+			return errors.New("a group monitor can't be its own child")
+		}
+		childIDs = append(childIDs, child.MonitorID)
+	}
+
+	children, err := ic.monitorService.FindByIDs(ctx, childIDs)
+	if err != nil {
+		return err
+	}
+	found := make(map[string]bool, len(children))
+	for _, child := range children {
+		found[child.ID] = true
+	}
+	for _, childID := range childIDs {
+		if !found[childID] {
+			return fmt.Errorf("group child monitor %s not found", childID)
+		}
+	}
+	return nil
+}
+
+// validateMessageTemplates parses a monitor's up and down message templates so mistakes
+// are reported when saving rather than on every check in the worker
+func validateMessageTemplates(templates ...string) error {
+	for _, template := range templates {
+		if template == "" {
+			continue
+		}
+		if _, err := executor.CompileMessageTemplate(template); err != nil {
+			return err
+		}
+	}
+	return nil
+}
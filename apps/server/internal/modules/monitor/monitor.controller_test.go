@@ -0,0 +1,287 @@
+package monitor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"peekaping/internal/config"
+	"peekaping/internal/modules/audit_log"
+	"peekaping/internal/modules/heartbeat"
+	"peekaping/internal/modules/monitor_notification"
+	"peekaping/internal/modules/shared"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type MockAuditLogRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditLogRepository) Create(ctx context.Context, entity *audit_log.Model) (*audit_log.Model, error) {
+	args := m.Called(ctx, entity)
+	return entity, args.Error(0)
+}
+
+func (m *MockAuditLogRepository) FindAll(ctx context.Context, filter *audit_log.FilterOptions) ([]*audit_log.Model, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]*audit_log.Model), args.Error(1)
+}
+
+func (m *MockAuditLogRepository) DeleteOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	args := m.Called(ctx, cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func setupMonitorControllerRouter(controller *MonitorController) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("authType", "jwt")
+		c.Set("userId", "user-1")
+		c.Next()
+	})
+	router.PATCH("/monitors/:id", controller.UpdatePartial)
+	router.PUT("/monitors/:id", controller.UpdateFull)
+	router.GET("/monitors/:id/heartbeats/export", controller.ExportHeartbeats)
+	return router
+}
+
+func TestMonitorController_UpdatePartial_RecordsAuditLog(t *testing.T) {
+	service, mockRepo, _, _, _, _, _, _ := setupMonitorService()
+	auditRepo := &MockAuditLogRepository{}
+	auditService := audit_log.NewService(auditRepo, &config.Config{AuditLogEnabled: true}, zap.NewNop().Sugar())
+
+	controller := &MonitorController{
+		monitorService:  service,
+		logger:          zap.NewNop().Sugar(),
+		auditLogService: auditService,
+	}
+	router := setupMonitorControllerRouter(controller)
+
+	before := &Model{
+		ID:       "monitor123",
+		Type:     "http",
+		Name:     "API",
+		Interval: 60,
+		Timeout:  16,
+		Active:   true,
+		Config:   `{"url":"https://example.com","method":"GET","basic_auth_pass":"old-secret-password"}`,
+	}
+	after := *before
+	after.Name = "API v2"
+	after.Interval = 30
+	after.Config = `{"url":"https://example.com/v2","method":"GET","basic_auth_pass":"new-secret-password"}`
+	after.UpdatedAt = time.Now()
+
+	mockRepo.On("FindByID", mock.Anything, "monitor123").Return(before, nil).Once()
+	mockRepo.On("UpdatePartial", mock.Anything, "monitor123", mock.Anything).Return(nil)
+	mockRepo.On("FindByID", mock.Anything, "monitor123").Return(&after, nil).Once()
+
+	var recorded *audit_log.Model
+	auditRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		recorded = args.Get(1).(*audit_log.Model)
+	}).Return(nil)
+
+	body := `{"name":"API v2","interval":30,"config":"{\"url\":\"https://example.com/v2\",\"method\":\"GET\",\"basic_auth_pass\":\"new-secret-password\"}"}`
+	req := httptest.NewRequest(http.MethodPatch, "/monitors/monitor123", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NotNil(t, recorded)
+
+	assert.Equal(t, audit_log.EntityMonitor, recorded.EntityType)
+	assert.Equal(t, "monitor123", recorded.EntityID)
+	assert.Equal(t, audit_log.ActionUpdate, recorded.Action)
+	assert.Equal(t, "user", recorded.ActorType)
+	assert.Equal(t, "user-1", recorded.ActorID)
+
+	assert.Len(t, recorded.Changes, 4)
+	assert.Equal(t, audit_log.FieldChange{Old: "API", New: "API v2"}, recorded.Changes["name"])
+	assert.Equal(t, audit_log.FieldChange{Old: float64(60), New: float64(30)}, recorded.Changes["interval"])
+	assert.Equal(t, audit_log.FieldChange{Old: "https://example.com", New: "https://example.com/v2"}, recorded.Changes["config.url"])
+	assert.Equal(t, audit_log.FieldChange{Old: "[REDACTED]", New: "[REDACTED]"}, recorded.Changes["config.basic_auth_pass"])
+	assert.NotContains(t, recorded.Changes, "updated_at")
+
+	mockRepo.AssertExpectations(t)
+	auditRepo.AssertExpectations(t)
+}
+
+func TestMonitorController_UpdatePartial_NoChangesSkipsAuditLog(t *testing.T) {
+	service, mockRepo, _, _, _, _, _, _ := setupMonitorService()
+	auditRepo := &MockAuditLogRepository{}
+	auditService := audit_log.NewService(auditRepo, &config.Config{AuditLogEnabled: true}, zap.NewNop().Sugar())
+
+	controller := &MonitorController{
+		monitorService:  service,
+		logger:          zap.NewNop().Sugar(),
+		auditLogService: auditService,
+	}
+	router := setupMonitorControllerRouter(controller)
+
+	existing := &Model{ID: "monitor123", Type: "http", Name: "API", Interval: 60}
+	mockRepo.On("FindByID", mock.Anything, "monitor123").Return(existing, nil)
+	mockRepo.On("UpdatePartial", mock.Anything, "monitor123", mock.Anything).Return(nil)
+
+	req := httptest.NewRequest(http.MethodPatch, "/monitors/monitor123", strings.NewReader(`{"name":"API"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	auditRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestMonitorController_UpdatePartial_AppliesNotificationSettings(t *testing.T) {
+	service, mockRepo, _, _, mockNotificationService, _, _, _ := setupMonitorService()
+	controller := &MonitorController{
+		monitorService:             service,
+		logger:                     zap.NewNop().Sugar(),
+		monitorNotificationService: mockNotificationService,
+		auditLogService:            audit_log.NewService(&MockAuditLogRepository{}, &config.Config{}, zap.NewNop().Sugar()),
+	}
+	router := setupMonitorControllerRouter(controller)
+
+	existing := &Model{ID: "monitor123", Type: "http", Name: "API", Interval: 60}
+	mockRepo.On("FindByID", mock.Anything, "monitor123").Return(existing, nil)
+	mockRepo.On("UpdatePartial", mock.Anything, "monitor123", mock.Anything).Return(nil)
+
+	// The relations are read once and each changed option is written
+	mockNotificationService.On("FindByMonitorID", mock.Anything, "monitor123").Return([]*monitor_notification.Model{
+		{ID: "rel-1", NotificationID: "chan-1", MinCriticality: "low", NotificationLimit: 5},
+		{ID: "rel-2", NotificationID: "chan-2", EscalateAfter: 3},
+	}, nil).Once()
+	mockNotificationService.On("SetMinCriticality", mock.Anything, "rel-1", "high").Return(nil).Once()
+	mockNotificationService.On("SetDigestOnly", mock.Anything, "rel-1", true).Return(nil).Once()
+	mockNotificationService.On("SetEventTypes", mock.Anything, "rel-2", []string{"down", "up"}).Return(nil).Once()
+	mockNotificationService.On("SetEscalateAfter", mock.Anything, "rel-2", 0).Return(nil).Once()
+
+	body := `{
+		"notification_min_criticality": {"chan-1": "high"},
+		"notification_digest_only": {"chan-1": true},
+		"notification_limit": {"chan-1": 5},
+		"notification_event_types": {"chan-2": ["down", "up"]},
+		"notification_escalate_after": {"chan-2": 0}
+	}`
+	req := httptest.NewRequest(http.MethodPatch, "/monitors/monitor123", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	mockNotificationService.AssertExpectations(t)
+	mockNotificationService.AssertNotCalled(t, "SetNotificationLimit", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestMonitorController_ExportHeartbeats(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	heartbeats := []*heartbeat.Model{
+		{Time: from.Add(time.Minute), Status: shared.MonitorStatusUp, Ping: 42, Msg: "200 - OK"},
+		{Time: from.Add(2 * time.Minute), Status: shared.MonitorStatusDown, Ping: 0, Msg: "timeout, retrying"},
+	}
+
+	setup := func() (*gin.Engine, *MockMonitorRepository, *MockHeartbeatService) {
+		service, mockRepo, mockHeartbeatService, _, _, _, _, _ := setupMonitorService()
+		controller := &MonitorController{monitorService: service, logger: zap.NewNop().Sugar()}
+		mockRepo.On("FindByID", mock.Anything, "monitor123").Return(&Model{ID: "monitor123"}, nil).Maybe()
+		mockRepo.On("FindByID", mock.Anything, "missing").Return(nil, nil).Maybe()
+		mockHeartbeatService.On("StreamByMonitorID", mock.Anything, "monitor123", from, to, mock.Anything).
+			Run(func(args mock.Arguments) {
+				fn := args.Get(4).(func(*heartbeat.Model) error)
+				for _, hb := range heartbeats {
+					require.NoError(t, fn(hb))
+				}
+			}).
+			Return(nil).Maybe()
+		return setupMonitorControllerRouter(controller), mockRepo, mockHeartbeatService
+	}
+	get := func(router *gin.Engine, url string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+		return rec
+	}
+	const query = "from=2025-01-01T00:00:00Z&to=2025-01-02T00:00:00Z"
+
+	t.Run("csv", func(t *testing.T) {
+		router, _, mockHeartbeatService := setup()
+
+		rec := get(router, "/monitors/monitor123/heartbeats/export?format=csv&"+query)
+
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="heartbeats-monitor123.csv"`, rec.Header().Get("Content-Disposition"))
+		assert.Equal(t, "timestamp,status,ping,message\n"+
+			"2025-01-01T00:01:00Z,up,42,200 - OK\n"+
+			"2025-01-01T00:02:00Z,down,0,\"timeout, retrying\"\n", rec.Body.String())
+		mockHeartbeatService.AssertExpectations(t)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		router, _, _ := setup()
+
+		rec := get(router, "/monitors/monitor123/heartbeats/export?format=json&"+query)
+
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"))
+		assert.JSONEq(t, `[
+			{"timestamp": "2025-01-01T00:01:00Z", "status": "up", "ping": 42, "message": "200 - OK"},
+			{"timestamp": "2025-01-01T00:02:00Z", "status": "down", "ping": 0, "message": "timeout, retrying"}
+		]`, rec.Body.String())
+	})
+
+	t.Run("unknown monitor", func(t *testing.T) {
+		router, _, _ := setup()
+		assert.Equal(t, http.StatusNotFound, get(router, "/monitors/missing/heartbeats/export?"+query).Code)
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		router, _, mockHeartbeatService := setup()
+		assert.Equal(t, http.StatusBadRequest, get(router, "/monitors/monitor123/heartbeats/export?format=xml").Code)
+		assert.Equal(t, http.StatusBadRequest, get(router, "/monitors/monitor123/heartbeats/export?from=yesterday").Code)
+		assert.Equal(t, http.StatusBadRequest, get(router, "/monitors/monitor123/heartbeats/export?from=2025-01-02T00:00:00Z&to=2025-01-01T00:00:00Z").Code)
+		mockHeartbeatService.AssertNotCalled(t, "StreamByMonitorID", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestMonitorController_ValidateGroupChildren(t *testing.T) {
+	service, mockRepo, _, _, _, _, _, _ := setupMonitorService()
+	controller := &MonitorController{monitorService: service, logger: zap.NewNop().Sugar()}
+	ctx := context.Background()
+
+	mockRepo.On("FindByIDs", mock.Anything, []string{"db-1", "db-2"}).
+		Return([]*Model{{ID: "db-1"}, {ID: "db-2"}}, nil)
+	mockRepo.On("FindByIDs", mock.Anything, []string{"db-1", "db-missing"}).
+		Return([]*Model{{ID: "db-1"}}, nil)
+
+	t.Run("existing children", func(t *testing.T) {
+		err := controller.validateGroupChildren(ctx, "group-1", "group",
+			`{"children":[{"monitor_id":"db-1"},{"monitor_id":"db-2"}],"rule":"all"}`)
+		assert.NoError(t, err)
+	})
+
+	t.Run("missing child", func(t *testing.T) {
+		err := controller.validateGroupChildren(ctx, "group-1", "group",
+			`{"children":[{"monitor_id":"db-1"},{"monitor_id":"db-missing"}],"rule":"all"}`)
+		assert.EqualError(t, err, "group child monitor db-missing not found")
+	})
+
+	t.Run("group as its own child", func(t *testing.T) {
+		err := controller.validateGroupChildren(ctx, "group-1", "group",
+			`{"children":[{"monitor_id":"db-1"},{"monitor_id":"group-1"}],"rule":"all"}`)
+		assert.EqualError(t, err, "a group monitor can't be its own child")
+	})
+
+	t.Run("other monitor types are not checked", func(t *testing.T) {
+		err := controller.validateGroupChildren(ctx, "http-1", "http", `{"url":"https://example.com"}`)
+		assert.NoError(t, err)
+	})
+}
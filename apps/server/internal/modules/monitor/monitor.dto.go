@@ -3,36 +3,90 @@ package monitor
 import "peekaping/internal/modules/heartbeat"
 
 type CreateUpdateDto struct {
-	Type            string   `json:"type" validate:"required" example:"http"`
-	Name            string   `json:"name" validate:"required,min=3" example:"My Monitor"`
-	Interval        int      `json:"interval" validate:"min=20" example:"60"`
-	MaxRetries      int      `json:"max_retries" validate:"min=0" example:"3"`
-	RetryInterval   int      `json:"retry_interval" validate:"min=20" example:"60"`
-	Timeout         int      `json:"timeout" validate:"min=16" example:"16"`
-	ResendInterval  int      `json:"resend_interval" validate:"min=0" example:"10"`
+	Type           string `json:"type" validate:"required" example:"http"`
+	Name           string `json:"name" validate:"required,min=3" example:"My Monitor"`
+	Interval       int    `json:"interval" validate:"min=20" example:"60"`
+	MaxRetries     int    `json:"max_retries" validate:"min=0" example:"3"`
+	RetryInterval  int    `json:"retry_interval" validate:"min=20" example:"60"`
+	RetryBackoff   string `json:"retry_backoff,omitempty" validate:"omitempty,oneof=fixed linear exponential" example:"exponential"`
+	Timeout        int    `json:"timeout" validate:"min=16" example:"16"`
+	ResendInterval int    `json:"resend_interval" validate:"min=0" example:"10"`
+	WarmupChecks   int    `json:"warmup_checks" validate:"min=0" example:"0"`
+	LatencyLimit   int    `json:"latency_limit" validate:"min=0" example:"0"`
+	LatencyChecks  int    `json:"latency_checks" validate:"min=0" example:"0"`
+	Criticality    string `json:"criticality,omitempty" validate:"omitempty,oneof=low medium high critical" example:"medium"`
+	// ResultExpression is a CEL expression computing the final status from the check result
+	ResultExpression string `json:"result_expression,omitempty" validate:"omitempty,max=4096" example:"status == 'up' && ping < 100"`
+	// UpMessage and DownMessage are liquid templates replacing the executor's message
+	UpMessage       string   `json:"up_message,omitempty" validate:"omitempty,max=1024" example:"Mail relay accepts connections ({{ ping }}ms)"`
+	DownMessage     string   `json:"down_message,omitempty" validate:"omitempty,max=1024" example:"Mail relay unreachable: {{ message }}"`
 	Active          bool     `json:"active" example:"true"`
 	NotificationIds []string `json:"notification_ids" validate:"required" example:"6830ad485361f19c598d6d90"`
-	TagIds          []string `json:"tag_ids" example:"6830ad485361f19c598d6d90,6830ad485361f19c598d6d91"`
-	ProxyId         string   `json:"proxy_id" example:"6830ad485361f19c598d6d90"`
-	Config          string   `json:"config"`
-	PushToken       string   `json:"push_token"`
+	// NotificationMinCriticality limits a channel to monitors of at least this criticality,
+	// keyed by notification id
+	NotificationMinCriticality map[string]string `json:"notification_min_criticality,omitempty" validate:"omitempty,dive,oneof=low medium high critical"`
+	// NotificationEventTypes limits a channel to these event types, keyed by notification id
+	NotificationEventTypes map[string][]string `json:"notification_event_types,omitempty" validate:"omitempty,dive,dive,oneof=down up cert_expiry degraded flapping high_latency cert_issuer_change"`
+	// NotificationDigestOnly sends the status changes to a channel only in its digest,
+	// keyed by notification id
+	NotificationDigestOnly map[string]bool `json:"notification_digest_only,omitempty"`
+	// NotificationEscalateAfter notifies a channel only once an outage lasted this many
+	// consecutive failed checks, keyed by notification id
+	NotificationEscalateAfter map[string]int `json:"notification_escalate_after,omitempty" validate:"omitempty,dive,min=0,max=100"`
+	// NotificationLimit caps the down notifications a single outage sends to a channel,
+	// keyed by notification id
+	NotificationLimit map[string]int `json:"notification_limit,omitempty" validate:"omitempty,dive,min=0,max=1000"`
+	TagIds            []string       `json:"tag_ids" example:"6830ad485361f19c598d6d90,6830ad485361f19c598d6d91"`
+	ProxyId           string         `json:"proxy_id" example:"6830ad485361f19c598d6d90"`
+	// FallbackProxyIds are tried in order while the proxies before them are failing
+	FallbackProxyIds  []string `json:"fallback_proxy_ids,omitempty" validate:"omitempty,max=10,unique,dive,required" example:"6830ad485361f19c598d6d91"`
+	NoProxy           bool     `json:"no_proxy" example:"false"`
+	Serialize         bool     `json:"serialize" example:"false"`
+	ConfirmTransition bool     `json:"confirm_transition" example:"false"`
+	// ParentId is the monitor this one depends on, SkipWhenParentDown skips the checks
+	// while it is down for at most ParentDownTimeout seconds (0 for no limit)
+	ParentId           string `json:"parent_id" example:"6830ad485361f19c598d6d90"`
+	SkipWhenParentDown bool   `json:"skip_when_parent_down" example:"false"`
+	ParentDownTimeout  int    `json:"parent_down_timeout" validate:"min=0" example:"0"`
+	Config             string `json:"config"`
+	PushToken          string `json:"push_token"`
 }
 
 type PartialUpdateDto struct {
-	Name            *string                  `json:"name,omitempty" example:"My Monitor"`
-	Interval        *int                     `json:"interval,omitempty" example:"60"`
-	Timeout         *int                     `json:"timeout,omitempty" example:"16"`
-	Type            *string                  `json:"type,omitempty" example:"http"`
-	MaxRetries      *int                     `json:"max_retries,omitempty" example:"3"`
-	RetryInterval   *int                     `json:"retry_interval,omitempty" example:"60"`
-	ResendInterval  *int                     `json:"resend_interval,omitempty" example:"10"`
-	Active          *bool                    `json:"active,omitempty" example:"true"`
-	NotificationIds []string                 `json:"notification_ids,omitempty" example:"6830ad485361f19c598d6d90"`
-	TagIds          []string                 `json:"tag_ids,omitempty" example:"6830ad485361f19c598d6d90,6830ad485361f19c598d6d91"`
-	ProxyId         *string                  `json:"proxy_id,omitempty" example:"6830ad485361f19c598d6d90"`
-	Status          *heartbeat.MonitorStatus `json:"status,omitempty" example:"1"`
-	Config          *string                  `json:"config,omitempty"`
-	PushToken       *string                  `json:"push_token,omitempty"`
+	Name                       *string                  `json:"name,omitempty" example:"My Monitor"`
+	Interval                   *int                     `json:"interval,omitempty" example:"60"`
+	Timeout                    *int                     `json:"timeout,omitempty" example:"16"`
+	Type                       *string                  `json:"type,omitempty" example:"http"`
+	MaxRetries                 *int                     `json:"max_retries,omitempty" example:"3"`
+	RetryInterval              *int                     `json:"retry_interval,omitempty" example:"60"`
+	RetryBackoff               *string                  `json:"retry_backoff,omitempty" validate:"omitempty,oneof=fixed linear exponential" example:"exponential"`
+	ResendInterval             *int                     `json:"resend_interval,omitempty" example:"10"`
+	WarmupChecks               *int                     `json:"warmup_checks,omitempty" validate:"omitempty,min=0" example:"0"`
+	LatencyLimit               *int                     `json:"latency_limit,omitempty" validate:"omitempty,min=0" example:"0"`
+	LatencyChecks              *int                     `json:"latency_checks,omitempty" validate:"omitempty,min=0" example:"0"`
+	Criticality                *string                  `json:"criticality,omitempty" validate:"omitempty,oneof=low medium high critical" example:"medium"`
+	ResultExpression           *string                  `json:"result_expression,omitempty" validate:"omitempty,max=4096"`
+	UpMessage                  *string                  `json:"up_message,omitempty" validate:"omitempty,max=1024"`
+	DownMessage                *string                  `json:"down_message,omitempty" validate:"omitempty,max=1024"`
+	Active                     *bool                    `json:"active,omitempty" example:"true"`
+	NotificationIds            []string                 `json:"notification_ids,omitempty" example:"6830ad485361f19c598d6d90"`
+	NotificationMinCriticality map[string]string        `json:"notification_min_criticality,omitempty" validate:"omitempty,dive,oneof=low medium high critical"`
+	NotificationEventTypes     map[string][]string      `json:"notification_event_types,omitempty" validate:"omitempty,dive,dive,oneof=down up cert_expiry degraded flapping high_latency cert_issuer_change"`
+	NotificationDigestOnly     map[string]bool          `json:"notification_digest_only,omitempty"`
+	NotificationEscalateAfter  map[string]int           `json:"notification_escalate_after,omitempty" validate:"omitempty,dive,min=0,max=100"`
+	NotificationLimit          map[string]int           `json:"notification_limit,omitempty" validate:"omitempty,dive,min=0,max=1000"`
+	TagIds                     []string                 `json:"tag_ids,omitempty" example:"6830ad485361f19c598d6d90,6830ad485361f19c598d6d91"`
+	ProxyId                    *string                  `json:"proxy_id,omitempty" example:"6830ad485361f19c598d6d90"`
+	FallbackProxyIds           *[]string                `json:"fallback_proxy_ids,omitempty" validate:"omitempty,max=10,unique,dive,required" example:"6830ad485361f19c598d6d91"`
+	NoProxy                    *bool                    `json:"no_proxy,omitempty" example:"false"`
+	Serialize                  *bool                    `json:"serialize,omitempty" example:"false"`
+	ConfirmTransition          *bool                    `json:"confirm_transition,omitempty" example:"false"`
+	ParentId                   *string                  `json:"parent_id,omitempty" example:"6830ad485361f19c598d6d90"`
+	SkipWhenParentDown         *bool                    `json:"skip_when_parent_down,omitempty" example:"false"`
+	ParentDownTimeout          *int                     `json:"parent_down_timeout,omitempty" validate:"omitempty,min=0" example:"0"`
+	Status                     *heartbeat.MonitorStatus `json:"status,omitempty" example:"1"`
+	Config                     *string                  `json:"config,omitempty"`
+	PushToken                  *string                  `json:"push_token,omitempty"`
 }
 
 // UptimeStatsDto represents uptime percentages for various periods
@@ -45,23 +99,43 @@ type UptimeStatsDto struct {
 }
 
 type MonitorResponseDto struct {
-	ID              string   `json:"id" example:"60c72b2f9b1e8b6f1f8e4b1a"`
-	Name            string   `json:"name" example:"My Monitor"`
-	Interval        int      `json:"interval" example:"60"`
-	Timeout         int      `json:"timeout" example:"10"`
-	Type            string   `json:"type" example:"http"`
-	Active          bool     `json:"active" example:"true" default:"true"`
-	Status          int      `json:"status" example:"1"`
-	MaxRetries      int      `json:"max_retries" example:"3"`
-	RetryInterval   int      `json:"retry_interval" example:"10"`
-	ResendInterval  int      `json:"resend_interval" example:"3"`
-	CreatedAt       string   `json:"created_at" example:"2024-06-01T12:00:00Z"`
-	UpdatedAt       string   `json:"updated_at" example:"2024-06-01T12:00:00Z"`
-	NotificationIds []string `json:"notification_ids" example:"6830ad485361f19c598d6d90"`
-	TagIds          []string `json:"tag_ids" example:"6830ad485361f19c598d6d90,6830ad485361f19c598d6d91"`
-	ProxyId         string   `json:"proxy_id" example:"6830ad485361f19c598d6d90"`
-	Config          string   `json:"config"`
-	PushToken       string   `json:"push_token"`
+	ID                         string              `json:"id" example:"60c72b2f9b1e8b6f1f8e4b1a"`
+	Name                       string              `json:"name" example:"My Monitor"`
+	Interval                   int                 `json:"interval" example:"60"`
+	Timeout                    int                 `json:"timeout" example:"10"`
+	Type                       string              `json:"type" example:"http"`
+	Active                     bool                `json:"active" example:"true" default:"true"`
+	Status                     int                 `json:"status" example:"1"`
+	MaxRetries                 int                 `json:"max_retries" example:"3"`
+	RetryInterval              int                 `json:"retry_interval" example:"10"`
+	RetryBackoff               string              `json:"retry_backoff" example:"fixed"`
+	ResendInterval             int                 `json:"resend_interval" example:"3"`
+	WarmupChecks               int                 `json:"warmup_checks" example:"0"`
+	LatencyLimit               int                 `json:"latency_limit" example:"0"`
+	LatencyChecks              int                 `json:"latency_checks" example:"0"`
+	Criticality                string              `json:"criticality" example:"medium"`
+	ResultExpression           string              `json:"result_expression"`
+	UpMessage                  string              `json:"up_message"`
+	DownMessage                string              `json:"down_message"`
+	CreatedAt                  string              `json:"created_at" example:"2024-06-01T12:00:00Z"`
+	UpdatedAt                  string              `json:"updated_at" example:"2024-06-01T12:00:00Z"`
+	NotificationIds            []string            `json:"notification_ids" example:"6830ad485361f19c598d6d90"`
+	NotificationMinCriticality map[string]string   `json:"notification_min_criticality,omitempty"`
+	NotificationEventTypes     map[string][]string `json:"notification_event_types,omitempty"`
+	NotificationDigestOnly     map[string]bool     `json:"notification_digest_only,omitempty"`
+	NotificationEscalateAfter  map[string]int      `json:"notification_escalate_after,omitempty"`
+	NotificationLimit          map[string]int      `json:"notification_limit,omitempty"`
+	TagIds                     []string            `json:"tag_ids" example:"6830ad485361f19c598d6d90,6830ad485361f19c598d6d91"`
+	ProxyId                    string              `json:"proxy_id" example:"6830ad485361f19c598d6d90"`
+	FallbackProxyIds           []string            `json:"fallback_proxy_ids" example:"6830ad485361f19c598d6d91"`
+	NoProxy                    bool                `json:"no_proxy" example:"false"`
+	Serialize                  bool                `json:"serialize" example:"false"`
+	ConfirmTransition          bool                `json:"confirm_transition" example:"false"`
+	ParentId                   string              `json:"parent_id" example:"6830ad485361f19c598d6d90"`
+	SkipWhenParentDown         bool                `json:"skip_when_parent_down" example:"false"`
+	ParentDownTimeout          int                 `json:"parent_down_timeout" example:"0"`
+	Config                     string              `json:"config"`
+	PushToken                  string              `json:"push_token"`
 }
 
 // StatPointsSummaryDto represents stat points and summary for a period
@@ -72,12 +146,21 @@ type MonitorResponseDto struct {
 // @Property minPing number "Minimum ping in the period"
 // @Property avgPing number "Average ping in the period"
 // @Property uptime number "Uptime percentage (0-100) in the period"
+// @Property uptimeMethod string "Method the uptime was computed with: count or time"
+// @Property maxResponseSize number "Largest response body in bytes in the period, HTTP monitors only"
+// @Property minResponseSize number "Smallest response body in bytes in the period, HTTP monitors only"
+// @Property avgResponseSize number "Average response body size in bytes in the period, HTTP monitors only"
 type StatPointsSummaryDto struct {
-	Points  []*StatPoint `json:"points"`
-	MaxPing *float64     `json:"maxPing"`
-	MinPing *float64     `json:"minPing"`
-	AvgPing *float64     `json:"avgPing"`
-	Uptime  *float64     `json:"uptime"`
+	Points       []*StatPoint `json:"points"`
+	MaxPing      *float64     `json:"maxPing"`
+	MinPing      *float64     `json:"minPing"`
+	AvgPing      *float64     `json:"avgPing"`
+	Uptime       *float64     `json:"uptime"`
+	UptimeMethod string       `json:"uptimeMethod"`
+
+	MaxResponseSize *float64 `json:"maxResponseSize"`
+	MinResponseSize *float64 `json:"minResponseSize"`
+	AvgResponseSize *float64 `json:"avgResponseSize"`
 }
 
 // CustomUptimeStatsDto represents uptime percentages for 24h, 30d, 365d
@@ -88,3 +171,51 @@ type CustomUptimeStatsDto struct {
 	Uptime30d  float64 `json:"30d"`
 	Uptime365d float64 `json:"365d"`
 }
+
+// ExportDto is a portable monitor definition for bulk export and import. Tags and
+// notification channels are referenced by name, so it can be imported into another
+// instance with channels and tags of the same names.
+type ExportDto struct {
+	Type              string                  `json:"type" example:"http"`
+	Name              string                  `json:"name" example:"My Monitor"`
+	Interval          int                     `json:"interval" example:"60"`
+	MaxRetries        int                     `json:"max_retries" example:"3"`
+	RetryInterval     int                     `json:"retry_interval" example:"60"`
+	RetryBackoff      string                  `json:"retry_backoff,omitempty" example:"fixed"`
+	Timeout           int                     `json:"timeout" example:"16"`
+	ResendInterval    int                     `json:"resend_interval" example:"10"`
+	WarmupChecks      int                     `json:"warmup_checks" example:"0"`
+	LatencyLimit      int                     `json:"latency_limit" example:"0"`
+	LatencyChecks     int                     `json:"latency_checks" example:"0"`
+	Criticality       string                  `json:"criticality,omitempty" example:"medium"`
+	ResultExpression  string                  `json:"result_expression,omitempty"`
+	UpMessage         string                  `json:"up_message,omitempty"`
+	DownMessage       string                  `json:"down_message,omitempty"`
+	Active            bool                    `json:"active" example:"true"`
+	NoProxy           bool                    `json:"no_proxy" example:"false"`
+	Serialize         bool                    `json:"serialize" example:"false"`
+	ConfirmTransition bool                    `json:"confirm_transition" example:"false"`
+	Config            string                  `json:"config"`
+	PushToken         string                  `json:"push_token,omitempty"`
+	Tags              []string                `json:"tags" example:"Production"`
+	Notifications     []NotificationExportDto `json:"notifications"`
+}
+
+// NotificationExportDto references a notification channel of an exported monitor by name
+type NotificationExportDto struct {
+	Name              string   `json:"name" example:"Ops Slack"`
+	MinCriticality    string   `json:"min_criticality,omitempty" example:"high"`
+	EventTypes        []string `json:"event_types,omitempty" example:"down,up"`
+	DigestOnly        bool     `json:"digest_only,omitempty" example:"false"`
+	EscalateAfter     int      `json:"escalate_after,omitempty" example:"0"`
+	NotificationLimit int      `json:"notification_limit,omitempty" example:"0"`
+}
+
+// ImportResultDto reports whether one monitor of a bulk import was created
+type ImportResultDto struct {
+	Index   int    `json:"index" example:"0"`
+	Name    string `json:"name" example:"My Monitor"`
+	Success bool   `json:"success" example:"true"`
+	ID      string `json:"id,omitempty" example:"6830ad485361f19c598d6d90"`
+	Error   string `json:"error,omitempty" example:"notification channel \"Ops Slack\" not found"`
+}
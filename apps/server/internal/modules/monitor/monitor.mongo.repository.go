@@ -15,38 +15,70 @@ import (
 )
 
 type mongoModel struct {
-	ID             primitive.ObjectID      `bson:"_id"`
-	Type           string                  `bson:"type"`
-	Name           string                  `bson:"name"`
-	Interval       int                     `bson:"interval"`
-	Timeout        int                     `bson:"timeout"`
-	MaxRetries     int                     `bson:"max_retries"`
-	RetryInterval  int                     `bson:"retry_interval"`
-	ResendInterval int                     `bson:"resend_interval"`
-	Active         bool                    `bson:"active"`
-	Status         heartbeat.MonitorStatus `bson:"status"`
-	CreatedAt      time.Time               `bson:"created_at"`
-	UpdatedAt      time.Time               `bson:"updated_at"`
-	Config         string                  `bson:"config"`
-	ProxyId        *primitive.ObjectID     `bson:"proxy_id,omitempty"`
-	PushToken      string                  `bson:"push_token"`
+	ID                 primitive.ObjectID      `bson:"_id"`
+	Type               string                  `bson:"type"`
+	Name               string                  `bson:"name"`
+	Interval           int                     `bson:"interval"`
+	Timeout            int                     `bson:"timeout"`
+	MaxRetries         int                     `bson:"max_retries"`
+	RetryInterval      int                     `bson:"retry_interval"`
+	RetryBackoff       string                  `bson:"retry_backoff"`
+	ResendInterval     int                     `bson:"resend_interval"`
+	WarmupChecks       int                     `bson:"warmup_checks"`
+	LatencyLimit       int                     `bson:"latency_limit"`
+	LatencyChecks      int                     `bson:"latency_checks"`
+	Criticality        string                  `bson:"criticality"`
+	ResultExpression   string                  `bson:"result_expression"`
+	UpMessage          string                  `bson:"up_message"`
+	DownMessage        string                  `bson:"down_message"`
+	NoProxy            bool                    `bson:"no_proxy"`
+	Serialize          bool                    `bson:"serialize"`
+	ConfirmTransition  bool                    `bson:"confirm_transition"`
+	ParentId           string                  `bson:"parent_id"`
+	SkipWhenParentDown bool                    `bson:"skip_when_parent_down"`
+	ParentDownTimeout  int                     `bson:"parent_down_timeout"`
+	Active             bool                    `bson:"active"`
+	Status             heartbeat.MonitorStatus `bson:"status"`
+	CreatedAt          time.Time               `bson:"created_at"`
+	UpdatedAt          time.Time               `bson:"updated_at"`
+	Config             string                  `bson:"config"`
+	ProxyId            *primitive.ObjectID     `bson:"proxy_id,omitempty"`
+	FallbackProxyIds   []string                `bson:"fallback_proxy_ids,omitempty"`
+	PushToken          string                  `bson:"push_token"`
+	ActivatedAt        *time.Time              `bson:"activated_at,omitempty"`
 }
 
 type mongoUpdateModel struct {
-	Type           *string                  `bson:"type,omitempty"`
-	Name           *string                  `bson:"name,omitempty"`
-	Interval       *int                     `bson:"interval,omitempty"`
-	Timeout        *int                     `bson:"timeout,omitempty"`
-	MaxRetries     *int                     `bson:"max_retries,omitempty"`
-	RetryInterval  *int                     `bson:"retry_interval,omitempty"`
-	ResendInterval *int                     `bson:"resend_interval,omitempty"`
-	Active         *bool                    `bson:"active,omitempty"`
-	Status         *heartbeat.MonitorStatus `bson:"status,omitempty"`
-	Config         *string                  `bson:"config,omitempty"`
-	ProxyId        *primitive.ObjectID      `bson:"proxy_id,omitempty"`
-	PushToken      *string                  `bson:"push_token,omitempty"`
-	CreatedAt      *time.Time               `bson:"created_at,omitempty"`
-	UpdatedAt      *time.Time               `bson:"updated_at,omitempty"`
+	Type               *string                  `bson:"type,omitempty"`
+	Name               *string                  `bson:"name,omitempty"`
+	Interval           *int                     `bson:"interval,omitempty"`
+	Timeout            *int                     `bson:"timeout,omitempty"`
+	MaxRetries         *int                     `bson:"max_retries,omitempty"`
+	RetryInterval      *int                     `bson:"retry_interval,omitempty"`
+	RetryBackoff       *string                  `bson:"retry_backoff,omitempty"`
+	ResendInterval     *int                     `bson:"resend_interval,omitempty"`
+	WarmupChecks       *int                     `bson:"warmup_checks,omitempty"`
+	LatencyLimit       *int                     `bson:"latency_limit,omitempty"`
+	LatencyChecks      *int                     `bson:"latency_checks,omitempty"`
+	Criticality        *string                  `bson:"criticality,omitempty"`
+	ResultExpression   *string                  `bson:"result_expression,omitempty"`
+	UpMessage          *string                  `bson:"up_message,omitempty"`
+	DownMessage        *string                  `bson:"down_message,omitempty"`
+	NoProxy            *bool                    `bson:"no_proxy,omitempty"`
+	Serialize          *bool                    `bson:"serialize,omitempty"`
+	ConfirmTransition  *bool                    `bson:"confirm_transition,omitempty"`
+	ParentId           *string                  `bson:"parent_id,omitempty"`
+	SkipWhenParentDown *bool                    `bson:"skip_when_parent_down,omitempty"`
+	ParentDownTimeout  *int                     `bson:"parent_down_timeout,omitempty"`
+	Active             *bool                    `bson:"active,omitempty"`
+	Status             *heartbeat.MonitorStatus `bson:"status,omitempty"`
+	Config             *string                  `bson:"config,omitempty"`
+	ProxyId            *primitive.ObjectID      `bson:"proxy_id,omitempty"`
+	FallbackProxyIds   *[]string                `bson:"fallback_proxy_ids,omitempty"`
+	PushToken          *string                  `bson:"push_token,omitempty"`
+	ActivatedAt        *time.Time               `bson:"activated_at,omitempty"`
+	CreatedAt          *time.Time               `bson:"created_at,omitempty"`
+	UpdatedAt          *time.Time               `bson:"updated_at,omitempty"`
 }
 
 func toDomainModel(mm *mongoModel) *Model {
@@ -57,21 +89,37 @@ func toDomainModel(mm *mongoModel) *Model {
 		proxyId = ""
 	}
 	return &Model{
-		ID:             mm.ID.Hex(),
-		Type:           mm.Type,
-		Name:           mm.Name,
-		Interval:       mm.Interval,
-		Timeout:        mm.Timeout,
-		MaxRetries:     mm.MaxRetries,
-		RetryInterval:  mm.RetryInterval,
-		ResendInterval: mm.ResendInterval,
-		Active:         mm.Active,
-		Status:         mm.Status,
-		Config:         mm.Config,
-		ProxyId:        proxyId,
-		PushToken:      mm.PushToken,
-		CreatedAt:      mm.CreatedAt,
-		UpdatedAt:      mm.UpdatedAt,
+		ID:                 mm.ID.Hex(),
+		Type:               mm.Type,
+		Name:               mm.Name,
+		Interval:           mm.Interval,
+		Timeout:            mm.Timeout,
+		MaxRetries:         mm.MaxRetries,
+		RetryInterval:      mm.RetryInterval,
+		RetryBackoff:       mm.RetryBackoff,
+		ResendInterval:     mm.ResendInterval,
+		WarmupChecks:       mm.WarmupChecks,
+		LatencyLimit:       mm.LatencyLimit,
+		LatencyChecks:      mm.LatencyChecks,
+		Criticality:        mm.Criticality,
+		ResultExpression:   mm.ResultExpression,
+		UpMessage:          mm.UpMessage,
+		DownMessage:        mm.DownMessage,
+		NoProxy:            mm.NoProxy,
+		Serialize:          mm.Serialize,
+		ConfirmTransition:  mm.ConfirmTransition,
+		ParentId:           mm.ParentId,
+		SkipWhenParentDown: mm.SkipWhenParentDown,
+		ParentDownTimeout:  mm.ParentDownTimeout,
+		Active:             mm.Active,
+		Status:             mm.Status,
+		Config:             mm.Config,
+		ProxyId:            proxyId,
+		FallbackProxyIds:   mm.FallbackProxyIds,
+		PushToken:          mm.PushToken,
+		ActivatedAt:        mm.ActivatedAt,
+		CreatedAt:          mm.CreatedAt,
+		UpdatedAt:          mm.UpdatedAt,
 	}
 }
 
@@ -132,21 +180,37 @@ func (r *MonitorRepositoryImpl) Create(ctx context.Context, monitor *Model) (*Mo
 	}
 
 	mm := &mongoModel{
-		ID:             primitive.NewObjectID(),
-		Type:           monitor.Type,
-		Name:           monitor.Name,
-		Interval:       monitor.Interval,
-		Timeout:        monitor.Timeout,
-		MaxRetries:     monitor.MaxRetries,
-		RetryInterval:  monitor.RetryInterval,
-		ResendInterval: monitor.ResendInterval,
-		Active:         monitor.Active,
-		Status:         0,
-		CreatedAt:      time.Now().UTC(),
-		UpdatedAt:      time.Now().UTC(),
-		Config:         monitor.Config,
-		ProxyId:        proxyObjectID,
-		PushToken:      monitor.PushToken,
+		ID:                 primitive.NewObjectID(),
+		Type:               monitor.Type,
+		Name:               monitor.Name,
+		Interval:           monitor.Interval,
+		Timeout:            monitor.Timeout,
+		MaxRetries:         monitor.MaxRetries,
+		RetryInterval:      monitor.RetryInterval,
+		RetryBackoff:       monitor.RetryBackoff,
+		ResendInterval:     monitor.ResendInterval,
+		WarmupChecks:       monitor.WarmupChecks,
+		LatencyLimit:       monitor.LatencyLimit,
+		LatencyChecks:      monitor.LatencyChecks,
+		Criticality:        monitor.Criticality,
+		ResultExpression:   monitor.ResultExpression,
+		UpMessage:          monitor.UpMessage,
+		DownMessage:        monitor.DownMessage,
+		NoProxy:            monitor.NoProxy,
+		Serialize:          monitor.Serialize,
+		ConfirmTransition:  monitor.ConfirmTransition,
+		ParentId:           monitor.ParentId,
+		SkipWhenParentDown: monitor.SkipWhenParentDown,
+		ParentDownTimeout:  monitor.ParentDownTimeout,
+		Active:             monitor.Active,
+		Status:             0,
+		CreatedAt:          time.Now().UTC(),
+		UpdatedAt:          time.Now().UTC(),
+		Config:             monitor.Config,
+		ProxyId:            proxyObjectID,
+		FallbackProxyIds:   monitor.FallbackProxyIds,
+		PushToken:          monitor.PushToken,
+		ActivatedAt:        monitor.ActivatedAt,
 	}
 
 	_, err := r.collection.InsertOne(ctx, mm)
@@ -312,18 +376,36 @@ func (r *MonitorRepositoryImpl) FindAll(
 
 func buildSetMapFromModelForUpdate(m *Model, preserveCreatedAt time.Time, includeProxyId bool, proxyObjectID primitive.ObjectID) bson.M {
 	set := bson.M{
-		"type":            m.Type,
-		"name":            m.Name,
-		"interval":        m.Interval,
-		"timeout":         m.Timeout,
-		"max_retries":     m.MaxRetries,
-		"retry_interval":  m.RetryInterval,
-		"resend_interval": m.ResendInterval,
-		"active":          m.Active,
-		"status":          0,                 // or m.Status if available
-		"created_at":      preserveCreatedAt, // Preserve original created_at
-		"updated_at":      time.Now().UTC(),
-		"config":          m.Config,
+		"type":                  m.Type,
+		"name":                  m.Name,
+		"interval":              m.Interval,
+		"timeout":               m.Timeout,
+		"max_retries":           m.MaxRetries,
+		"retry_interval":        m.RetryInterval,
+		"retry_backoff":         m.RetryBackoff,
+		"resend_interval":       m.ResendInterval,
+		"warmup_checks":         m.WarmupChecks,
+		"latency_limit":         m.LatencyLimit,
+		"latency_checks":        m.LatencyChecks,
+		"criticality":           m.Criticality,
+		"result_expression":     m.ResultExpression,
+		"up_message":            m.UpMessage,
+		"down_message":          m.DownMessage,
+		"no_proxy":              m.NoProxy,
+		"serialize":             m.Serialize,
+		"confirm_transition":    m.ConfirmTransition,
+		"parent_id":             m.ParentId,
+		"skip_when_parent_down": m.SkipWhenParentDown,
+		"parent_down_timeout":   m.ParentDownTimeout,
+		"active":                m.Active,
+		"status":                0,                 // or m.Status if available
+		"created_at":            preserveCreatedAt, // Preserve original created_at
+		"updated_at":            time.Now().UTC(),
+		"config":                m.Config,
+		"fallback_proxy_ids":    m.FallbackProxyIds,
+	}
+	if m.ActivatedAt != nil {
+		set["activated_at"] = *m.ActivatedAt
 	}
 	if includeProxyId {
 		set["proxy_id"] = proxyObjectID
@@ -351,9 +433,57 @@ func buildSetMapFromUpdateModel(mu *mongoUpdateModel, includeProxyId bool, proxy
 	if mu.RetryInterval != nil {
 		set["retry_interval"] = *mu.RetryInterval
 	}
+	if mu.RetryBackoff != nil {
+		set["retry_backoff"] = *mu.RetryBackoff
+	}
 	if mu.ResendInterval != nil {
 		set["resend_interval"] = *mu.ResendInterval
 	}
+	if mu.WarmupChecks != nil {
+		set["warmup_checks"] = *mu.WarmupChecks
+	}
+	if mu.LatencyLimit != nil {
+		set["latency_limit"] = *mu.LatencyLimit
+	}
+	if mu.LatencyChecks != nil {
+		set["latency_checks"] = *mu.LatencyChecks
+	}
+	if mu.Criticality != nil {
+		set["criticality"] = *mu.Criticality
+	}
+	if mu.ResultExpression != nil {
+		set["result_expression"] = *mu.ResultExpression
+	}
+	if mu.UpMessage != nil {
+		set["up_message"] = *mu.UpMessage
+	}
+	if mu.DownMessage != nil {
+		set["down_message"] = *mu.DownMessage
+	}
+	if mu.NoProxy != nil {
+		set["no_proxy"] = *mu.NoProxy
+	}
+	if mu.Serialize != nil {
+		set["serialize"] = *mu.Serialize
+	}
+	if mu.ConfirmTransition != nil {
+		set["confirm_transition"] = *mu.ConfirmTransition
+	}
+	if mu.ParentId != nil {
+		set["parent_id"] = *mu.ParentId
+	}
+	if mu.SkipWhenParentDown != nil {
+		set["skip_when_parent_down"] = *mu.SkipWhenParentDown
+	}
+	if mu.ParentDownTimeout != nil {
+		set["parent_down_timeout"] = *mu.ParentDownTimeout
+	}
+	if mu.FallbackProxyIds != nil {
+		set["fallback_proxy_ids"] = *mu.FallbackProxyIds
+	}
+	if mu.ActivatedAt != nil {
+		set["activated_at"] = *mu.ActivatedAt
+	}
 	if mu.Active != nil {
 		set["active"] = *mu.Active
 	}
@@ -427,20 +557,36 @@ func (r *MonitorRepositoryImpl) UpdatePartial(ctx context.Context, id string, mo
 	}
 
 	mu := &mongoUpdateModel{
-		Type:           monitor.Type,
-		Name:           monitor.Name,
-		Interval:       monitor.Interval,
-		Timeout:        monitor.Timeout,
-		MaxRetries:     monitor.MaxRetries,
-		RetryInterval:  monitor.RetryInterval,
-		ResendInterval: monitor.ResendInterval,
-		Active:         monitor.Active,
-		Status:         monitor.Status,
-		CreatedAt:      monitor.CreatedAt,
-		UpdatedAt:      monitor.UpdatedAt,
-		Config:         monitor.Config,
-		ProxyId:        proxyObjectID,
-		PushToken:      monitor.PushToken,
+		Type:               monitor.Type,
+		Name:               monitor.Name,
+		Interval:           monitor.Interval,
+		Timeout:            monitor.Timeout,
+		MaxRetries:         monitor.MaxRetries,
+		RetryInterval:      monitor.RetryInterval,
+		RetryBackoff:       monitor.RetryBackoff,
+		ResendInterval:     monitor.ResendInterval,
+		WarmupChecks:       monitor.WarmupChecks,
+		LatencyLimit:       monitor.LatencyLimit,
+		LatencyChecks:      monitor.LatencyChecks,
+		Criticality:        monitor.Criticality,
+		ResultExpression:   monitor.ResultExpression,
+		UpMessage:          monitor.UpMessage,
+		DownMessage:        monitor.DownMessage,
+		NoProxy:            monitor.NoProxy,
+		Serialize:          monitor.Serialize,
+		ConfirmTransition:  monitor.ConfirmTransition,
+		ParentId:           monitor.ParentId,
+		SkipWhenParentDown: monitor.SkipWhenParentDown,
+		ParentDownTimeout:  monitor.ParentDownTimeout,
+		Active:             monitor.Active,
+		Status:             monitor.Status,
+		CreatedAt:          monitor.CreatedAt,
+		UpdatedAt:          monitor.UpdatedAt,
+		Config:             monitor.Config,
+		ProxyId:            proxyObjectID,
+		FallbackProxyIds:   monitor.FallbackProxyIds,
+		PushToken:          monitor.PushToken,
+		ActivatedAt:        monitor.ActivatedAt,
 	}
 
 	objectID, err := primitive.ObjectIDFromHex(id)
@@ -30,6 +30,8 @@ func (uc *MonitorRoute) ConnectRoute(
 
 	router.GET("", uc.monitorController.FindAll)
 	router.GET("batch", uc.monitorController.FindByIDs)
+	router.GET("export", uc.monitorController.ExportMonitors)
+	router.POST("import", uc.monitorController.ImportMonitors)
 	router.POST("", uc.monitorController.Create)
 	router.GET(":id", uc.monitorController.FindByID)
 	router.PUT(":id", uc.monitorController.UpdateFull)
@@ -37,6 +39,7 @@ func (uc *MonitorRoute) ConnectRoute(
 	router.DELETE(":id", uc.monitorController.Delete)
 	router.POST(":id/reset", uc.monitorController.ResetMonitorData)
 	router.GET(":id/heartbeats", uc.monitorController.FindByMonitorIDPaginated)
+	router.GET(":id/heartbeats/export", uc.monitorController.ExportHeartbeats)
 	router.GET(":id/stats/uptime", uc.monitorController.GetUptimeStats)
 	router.GET(":id/stats/points", uc.monitorController.GetStatPoints)
 	router.GET(":id/tls", uc.monitorController.GetTLSInfo)
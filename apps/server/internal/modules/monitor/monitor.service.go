@@ -3,6 +3,7 @@ package monitor
 import (
 	"context"
 	"fmt"
+	"peekaping/internal/modules/audit_log"
 	"peekaping/internal/modules/events"
 	"peekaping/internal/modules/healthcheck/executor"
 	"peekaping/internal/modules/heartbeat"
@@ -28,6 +29,7 @@ type Service interface {
 	ValidateMonitorConfig(monitorType string, configJSON string) error
 
 	GetHeartbeats(ctx context.Context, id string, limit, page int, important *bool, reverse bool) ([]*heartbeat.Model, error)
+	ExportHeartbeats(ctx context.Context, id string, since, until time.Time, fn func(*heartbeat.Model) error) error
 
 	RemoveProxyReference(ctx context.Context, proxyId string) error
 	FindByProxyId(ctx context.Context, proxyId string) ([]*Model, error)
@@ -46,7 +48,11 @@ type StatPoint struct {
 	Ping        float64 `json:"ping"`
 	PingMin     float64 `json:"ping_min"`
 	PingMax     float64 `json:"ping_max"`
-	Timestamp   int64   `json:"timestamp"`
+	// Response body sizes in bytes, 0 when no heartbeat of the point reported one
+	ResponseSize    float64 `json:"response_size"`
+	ResponseSizeMin float64 `json:"response_size_min"`
+	ResponseSizeMax float64 `json:"response_size_max"`
+	Timestamp       int64   `json:"timestamp"`
 }
 
 type MonitorServiceImpl struct {
@@ -57,6 +63,7 @@ type MonitorServiceImpl struct {
 	monitorTagService          monitor_tag.Service
 	executorRegistry           *executor.ExecutorRegistry
 	statPointsService          stats.Service
+	settingService             shared.SettingService
 	logger                     *zap.SugaredLogger
 }
 
@@ -68,6 +75,7 @@ func NewMonitorService(
 	monitorTagService monitor_tag.Service,
 	executorRegistry *executor.ExecutorRegistry,
 	statPointsService stats.Service,
+	settingService shared.SettingService,
 	logger *zap.SugaredLogger,
 ) Service {
 	return &MonitorServiceImpl{
@@ -78,25 +86,44 @@ func NewMonitorService(
 		monitorTagService,
 		executorRegistry,
 		statPointsService,
+		settingService,
 		logger.Named("[monitor-service]"),
 	}
 }
 
 func (mr *MonitorServiceImpl) Create(ctx context.Context, monitorCreateDto *CreateUpdateDto) (*Model, error) {
 	createModel := &Model{
-		Type:           monitorCreateDto.Type,
-		Name:           monitorCreateDto.Name,
-		Interval:       monitorCreateDto.Interval,
-		Timeout:        monitorCreateDto.Timeout,
-		MaxRetries:     monitorCreateDto.MaxRetries,
-		RetryInterval:  monitorCreateDto.RetryInterval,
-		ResendInterval: monitorCreateDto.ResendInterval,
-		Active:         monitorCreateDto.Active,
-		Status:         shared.MonitorStatusUp,
-		CreatedAt:      time.Now().UTC(),
-		Config:         monitorCreateDto.Config,
-		ProxyId:        monitorCreateDto.ProxyId,
-		PushToken:      monitorCreateDto.PushToken,
+		Type:               monitorCreateDto.Type,
+		Name:               monitorCreateDto.Name,
+		Interval:           monitorCreateDto.Interval,
+		Timeout:            monitorCreateDto.Timeout,
+		MaxRetries:         monitorCreateDto.MaxRetries,
+		RetryInterval:      monitorCreateDto.RetryInterval,
+		RetryBackoff:       retryBackoffOrDefault(monitorCreateDto.RetryBackoff),
+		ResendInterval:     monitorCreateDto.ResendInterval,
+		WarmupChecks:       monitorCreateDto.WarmupChecks,
+		LatencyLimit:       monitorCreateDto.LatencyLimit,
+		LatencyChecks:      monitorCreateDto.LatencyChecks,
+		Criticality:        criticalityOrDefault(monitorCreateDto.Criticality),
+		ResultExpression:   monitorCreateDto.ResultExpression,
+		UpMessage:          monitorCreateDto.UpMessage,
+		DownMessage:        monitorCreateDto.DownMessage,
+		Active:             monitorCreateDto.Active,
+		Status:             shared.MonitorStatusUp,
+		CreatedAt:          time.Now().UTC(),
+		Config:             monitorCreateDto.Config,
+		ProxyId:            monitorCreateDto.ProxyId,
+		FallbackProxyIds:   monitorCreateDto.FallbackProxyIds,
+		NoProxy:            monitorCreateDto.NoProxy,
+		Serialize:          monitorCreateDto.Serialize,
+		ConfirmTransition:  monitorCreateDto.ConfirmTransition,
+		ParentId:           monitorCreateDto.ParentId,
+		SkipWhenParentDown: monitorCreateDto.SkipWhenParentDown,
+		ParentDownTimeout:  monitorCreateDto.ParentDownTimeout,
+		PushToken:          monitorCreateDto.PushToken,
+	}
+	if createModel.Active {
+		createModel.ActivatedAt = &createModel.CreatedAt
 	}
 
 	createdModel, err := mr.monitorRepository.Create(ctx, createModel)
@@ -109,10 +136,29 @@ func (mr *MonitorServiceImpl) Create(ctx context.Context, monitorCreateDto *Crea
 		Type:    events.MonitorCreated,
 		Payload: createdModel,
 	})
+	mr.publishLifecycle(ctx, events.MonitorActionCreated, createdModel)
 
 	return createdModel, nil
 }
 
+// publishLifecycle emits a MonitorLifecycle event for the admin notification with the
+// actor of the request
+func (mr *MonitorServiceImpl) publishLifecycle(ctx context.Context, action string, m *Model) {
+	actorType, actorID := audit_log.ActorFromContext(ctx)
+	mr.eventBus.Publish(events.Event{
+		Type: events.MonitorLifecycle,
+		Payload: &events.MonitorLifecyclePayload{
+			Action:      action,
+			MonitorID:   m.ID,
+			MonitorName: m.Name,
+			MonitorType: m.Type,
+			ActorType:   actorType,
+			ActorID:     actorID,
+			Time:        time.Now().UTC(),
+		},
+	})
+}
+
 func (mr *MonitorServiceImpl) FindByID(ctx context.Context, id string) (*Model, error) {
 	return mr.monitorRepository.FindByID(ctx, id)
 }
@@ -139,21 +185,46 @@ func (mr *MonitorServiceImpl) FindActivePaginated(ctx context.Context, page int,
 }
 
 func (mr *MonitorServiceImpl) UpdateFull(ctx context.Context, id string, monitor *CreateUpdateDto) (*Model, error) {
+	var activatedAt *time.Time
+	if monitor.Active {
+		current, err := mr.monitorRepository.FindByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		activatedAt = nextActivatedAt(current)
+	}
+
 	model := &Model{
-		ID:             id,
-		Name:           monitor.Name,
-		Type:           monitor.Type,
-		Interval:       monitor.Interval,
-		Timeout:        monitor.Timeout,
-		MaxRetries:     monitor.MaxRetries,
-		RetryInterval:  monitor.RetryInterval,
-		ResendInterval: monitor.ResendInterval,
-		Active:         monitor.Active,
-		Status:         shared.MonitorStatusUp,
-		UpdatedAt:      time.Now().UTC(),
-		Config:         monitor.Config,
-		ProxyId:        monitor.ProxyId,
-		PushToken:      monitor.PushToken,
+		ID:                 id,
+		Name:               monitor.Name,
+		Type:               monitor.Type,
+		Interval:           monitor.Interval,
+		Timeout:            monitor.Timeout,
+		MaxRetries:         monitor.MaxRetries,
+		RetryInterval:      monitor.RetryInterval,
+		RetryBackoff:       retryBackoffOrDefault(monitor.RetryBackoff),
+		ResendInterval:     monitor.ResendInterval,
+		WarmupChecks:       monitor.WarmupChecks,
+		LatencyLimit:       monitor.LatencyLimit,
+		LatencyChecks:      monitor.LatencyChecks,
+		Criticality:        criticalityOrDefault(monitor.Criticality),
+		ResultExpression:   monitor.ResultExpression,
+		UpMessage:          monitor.UpMessage,
+		DownMessage:        monitor.DownMessage,
+		Active:             monitor.Active,
+		Status:             shared.MonitorStatusUp,
+		UpdatedAt:          time.Now().UTC(),
+		Config:             monitor.Config,
+		ProxyId:            monitor.ProxyId,
+		FallbackProxyIds:   monitor.FallbackProxyIds,
+		NoProxy:            monitor.NoProxy,
+		Serialize:          monitor.Serialize,
+		ConfirmTransition:  monitor.ConfirmTransition,
+		ParentId:           monitor.ParentId,
+		SkipWhenParentDown: monitor.SkipWhenParentDown,
+		ParentDownTimeout:  monitor.ParentDownTimeout,
+		PushToken:          monitor.PushToken,
+		ActivatedAt:        activatedAt,
 	}
 
 	err := mr.monitorRepository.UpdateFull(ctx, id, model)
@@ -171,20 +242,45 @@ func (mr *MonitorServiceImpl) UpdateFull(ctx context.Context, id string, monitor
 }
 
 func (mr *MonitorServiceImpl) UpdatePartial(ctx context.Context, id string, monitor *PartialUpdateDto, noPublish bool) (*Model, error) {
+	var activatedAt *time.Time
+	if monitor.Active != nil && *monitor.Active {
+		current, err := mr.monitorRepository.FindByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		activatedAt = nextActivatedAt(current)
+	}
+
 	model := &UpdateModel{
-		ID:             &id,
-		Type:           monitor.Type,
-		Name:           monitor.Name,
-		Interval:       monitor.Interval,
-		Timeout:        monitor.Timeout,
-		MaxRetries:     monitor.MaxRetries,
-		RetryInterval:  monitor.RetryInterval,
-		ResendInterval: monitor.ResendInterval,
-		Active:         monitor.Active,
-		Status:         monitor.Status,
-		Config:         monitor.Config,
-		ProxyId:        monitor.ProxyId,
-		PushToken:      monitor.PushToken,
+		ID:                 &id,
+		Type:               monitor.Type,
+		Name:               monitor.Name,
+		Interval:           monitor.Interval,
+		Timeout:            monitor.Timeout,
+		MaxRetries:         monitor.MaxRetries,
+		RetryInterval:      monitor.RetryInterval,
+		RetryBackoff:       monitor.RetryBackoff,
+		ResendInterval:     monitor.ResendInterval,
+		WarmupChecks:       monitor.WarmupChecks,
+		LatencyLimit:       monitor.LatencyLimit,
+		LatencyChecks:      monitor.LatencyChecks,
+		Criticality:        monitor.Criticality,
+		ResultExpression:   monitor.ResultExpression,
+		UpMessage:          monitor.UpMessage,
+		DownMessage:        monitor.DownMessage,
+		Active:             monitor.Active,
+		Status:             monitor.Status,
+		Config:             monitor.Config,
+		ProxyId:            monitor.ProxyId,
+		FallbackProxyIds:   monitor.FallbackProxyIds,
+		NoProxy:            monitor.NoProxy,
+		Serialize:          monitor.Serialize,
+		ConfirmTransition:  monitor.ConfirmTransition,
+		ParentId:           monitor.ParentId,
+		SkipWhenParentDown: monitor.SkipWhenParentDown,
+		ParentDownTimeout:  monitor.ParentDownTimeout,
+		PushToken:          monitor.PushToken,
+		ActivatedAt:        activatedAt,
 	}
 
 	err := mr.monitorRepository.UpdatePartial(ctx, id, model)
@@ -210,7 +306,13 @@ func (mr *MonitorServiceImpl) UpdatePartial(ctx context.Context, id string, moni
 }
 
 func (mr *MonitorServiceImpl) Delete(ctx context.Context, id string) error {
-	err := mr.monitorRepository.Delete(ctx, id)
+	// Loaded first so the lifecycle event can still name the monitor
+	deleted, err := mr.monitorRepository.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = mr.monitorRepository.Delete(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -225,6 +327,9 @@ func (mr *MonitorServiceImpl) Delete(ctx context.Context, id string) error {
 		Type:    events.MonitorDeleted,
 		Payload: id,
 	})
+	if deleted != nil {
+		mr.publishLifecycle(ctx, events.MonitorActionDeleted, deleted)
+	}
 
 	return nil
 }
@@ -240,9 +345,20 @@ func (mr *MonitorServiceImpl) ValidateMonitorConfig(
 }
 
 func (mr *MonitorServiceImpl) GetHeartbeats(ctx context.Context, id string, limit, page int, important *bool, reverse bool) ([]*heartbeat.Model, error) {
+	if important != nil && *important {
+		if window := mr.importanceWindow(ctx); window > 0 {
+			return mr.findCollapsedEvents(ctx, id, limit, page, reverse, window)
+		}
+	}
 	return mr.heartbeatService.FindByMonitorIDPaginated(ctx, id, limit, page, important, reverse)
 }
 
+// ExportHeartbeats passes every heartbeat of the monitor in [since, until) to fn in time
+// order, streaming them from the database
+func (mr *MonitorServiceImpl) ExportHeartbeats(ctx context.Context, id string, since, until time.Time, fn func(*heartbeat.Model) error) error {
+	return mr.heartbeatService.StreamByMonitorID(ctx, id, since, until, fn)
+}
+
 func (mr *MonitorServiceImpl) RemoveProxyReference(ctx context.Context, proxyId string) error {
 	return mr.monitorRepository.RemoveProxyReference(ctx, proxyId)
 }
@@ -288,18 +404,27 @@ func (mr *MonitorServiceImpl) GetStatPoints(ctx context.Context, id string, sinc
 			Ping:        s.Ping,
 			PingMin:     s.PingMin,
 			PingMax:     s.PingMax,
-			Timestamp:   s.Timestamp.Unix() * 1000,
+
+			ResponseSize:    s.ResponseSize,
+			ResponseSizeMin: s.ResponseSizeMin,
+			ResponseSizeMax: s.ResponseSizeMax,
+			Timestamp:       s.Timestamp.Unix() * 1000,
 		})
 	}
 
-	stats := mr.statPointsService.StatPointsSummary(statsList)
+	stats := mr.statPointsService.StatPointsSummary(ctx, statsList)
 
 	return &StatPointsSummaryDto{
-		Points:  points,
-		MaxPing: stats.MaxPing,
-		MinPing: stats.MinPing,
-		AvgPing: stats.AvgPing,
-		Uptime:  stats.Uptime,
+		Points:       points,
+		MaxPing:      stats.MaxPing,
+		MinPing:      stats.MinPing,
+		AvgPing:      stats.AvgPing,
+		Uptime:       stats.Uptime,
+		UptimeMethod: string(stats.UptimeMethod),
+
+		MaxResponseSize: stats.MaxResponseSize,
+		MinResponseSize: stats.MinResponseSize,
+		AvgResponseSize: stats.AvgResponseSize,
 	}, nil
 }
 
@@ -320,7 +445,7 @@ func (mr *MonitorServiceImpl) GetUptimeStats(ctx context.Context, id string) (*C
 		if err != nil {
 			return nil, err
 		}
-		summary := mr.statPointsService.StatPointsSummary(statsList)
+		summary := mr.statPointsService.StatPointsSummary(ctx, statsList)
 		uptime := 0.0
 		if summary.Uptime != nil {
 			uptime = *summary.Uptime
@@ -392,3 +517,29 @@ func (mr *MonitorServiceImpl) ResetMonitorData(ctx context.Context, id string) e
 
 	return nil
 }
+
+// retryBackoffOrDefault stores monitors created or replaced without a retry backoff as fixed
+func retryBackoffOrDefault(backoff string) string {
+	if backoff == "" {
+		return shared.RetryBackoffFixed
+	}
+	return backoff
+}
+
+// criticalityOrDefault stores monitors created or replaced without a criticality as medium
+func criticalityOrDefault(criticality string) string {
+	if criticality == "" {
+		return shared.CriticalityMedium
+	}
+	return criticality
+}
+
+// nextActivatedAt returns the activation time to store for a monitor that is (still) active
+// after an update: the existing one if it was already active, otherwise now
+func nextActivatedAt(current *Model) *time.Time {
+	if current != nil && current.Active && current.ActivatedAt != nil {
+		return current.ActivatedAt
+	}
+	now := time.Now().UTC()
+	return &now
+}
@@ -3,6 +3,8 @@ package monitor
 import (
 	"context"
 	"errors"
+	"fmt"
+	"peekaping/internal/config"
 	"peekaping/internal/infra"
 	"peekaping/internal/modules/events"
 	"peekaping/internal/modules/healthcheck/executor"
@@ -125,16 +127,39 @@ func (m *MockHeartbeatService) DeleteOlderThan(ctx context.Context, cutoff time.
 	return args.Get(0).(int64), args.Error(1)
 }
 
+func (m *MockHeartbeatService) CompactOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	args := m.Called(ctx, cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockHeartbeatService) DeleteRollupsOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	args := m.Called(ctx, cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (m *MockHeartbeatService) FindByMonitorIDPaginated(ctx context.Context, monitorID string, limit, page int, important *bool, reverse bool) ([]*heartbeat.Model, error) {
 	args := m.Called(ctx, monitorID, limit, page, important, reverse)
 	return args.Get(0).([]*heartbeat.Model), args.Error(1)
 }
 
+func (m *MockHeartbeatService) FindLatestByMonitorIDs(ctx context.Context, monitorIDs []string) (map[string]*heartbeat.Model, error) {
+	args := m.Called(ctx, monitorIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]*heartbeat.Model), args.Error(1)
+}
+
 func (m *MockHeartbeatService) DeleteByMonitorID(ctx context.Context, monitorID string) error {
 	args := m.Called(ctx, monitorID)
 	return args.Error(0)
 }
 
+func (m *MockHeartbeatService) StreamByMonitorID(ctx context.Context, monitorID string, since, until time.Time, fn func(*heartbeat.Model) error) error {
+	args := m.Called(ctx, monitorID, since, until, fn)
+	return args.Error(0)
+}
+
 type MockEventBus struct {
 	mock.Mock
 }
@@ -143,12 +168,20 @@ func (m *MockEventBus) Publish(event events.Event) {
 	m.Called(event)
 }
 
+func (m *MockEventBus) Subscribe(eventType events.EventType, handler events.EventHandler) {
+	m.Called(eventType, handler)
+}
+
+func (m *MockEventBus) Close() error {
+	return m.Called().Error(0)
+}
+
 type MockMonitorNotificationService struct {
 	mock.Mock
 }
 
-func (m *MockMonitorNotificationService) Create(ctx context.Context, monitorID string, notificationID string) (*monitor_notification.Model, error) {
-	args := m.Called(ctx, monitorID, notificationID)
+func (m *MockMonitorNotificationService) Create(ctx context.Context, monitorID string, notificationID string, settings monitor_notification.Settings) (*monitor_notification.Model, error) {
+	args := m.Called(ctx, monitorID, notificationID, settings)
 	return args.Get(0).(*monitor_notification.Model), args.Error(1)
 }
 
@@ -177,6 +210,36 @@ func (m *MockMonitorNotificationService) DeleteByNotificationID(ctx context.Cont
 	return args.Error(0)
 }
 
+func (m *MockMonitorNotificationService) SetMinCriticality(ctx context.Context, id string, minCriticality string) error {
+	args := m.Called(ctx, id, minCriticality)
+	return args.Error(0)
+}
+
+func (m *MockMonitorNotificationService) SetEventTypes(ctx context.Context, id string, eventTypes []string) error {
+	args := m.Called(ctx, id, eventTypes)
+	return args.Error(0)
+}
+
+func (m *MockMonitorNotificationService) SetDigestOnly(ctx context.Context, id string, digestOnly bool) error {
+	args := m.Called(ctx, id, digestOnly)
+	return args.Error(0)
+}
+
+func (m *MockMonitorNotificationService) SetEscalateAfter(ctx context.Context, id string, escalateAfter int) error {
+	args := m.Called(ctx, id, escalateAfter)
+	return args.Error(0)
+}
+
+func (m *MockMonitorNotificationService) SetNotificationLimit(ctx context.Context, id string, notificationLimit int) error {
+	args := m.Called(ctx, id, notificationLimit)
+	return args.Error(0)
+}
+
+func (m *MockMonitorNotificationService) SetOutageNotifications(ctx context.Context, id string, outageNotifications int) error {
+	args := m.Called(ctx, id, outageNotifications)
+	return args.Error(0)
+}
+
 type MockMonitorTagService struct {
 	mock.Mock
 }
@@ -258,11 +321,46 @@ func (m *MockStatsService) FindStatsByMonitorIDAndTimeRange(ctx context.Context,
 	return args.Get(0).([]*stats.Stat), args.Error(1)
 }
 
-func (m *MockStatsService) StatPointsSummary(statsList []*stats.Stat) *stats.Stats {
-	args := m.Called(statsList)
+func (m *MockStatsService) StatPointsSummary(ctx context.Context, statsList []*stats.Stat) *stats.Stats {
+	args := m.Called(ctx, statsList)
 	return args.Get(0).(*stats.Stats)
 }
 
+func (m *MockStatsService) DailyUptime(ctx context.Context, monitorID string, days int, now time.Time) ([]*stats.DayUptime, error) {
+	args := m.Called(ctx, monitorID, days, now)
+	return args.Get(0).([]*stats.DayUptime), args.Error(1)
+}
+
+type MockSettingService struct {
+	mock.Mock
+}
+
+func (m *MockSettingService) GetByKey(ctx context.Context, key string) (*shared.SettingModel, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*shared.SettingModel), args.Error(1)
+}
+
+func (m *MockSettingService) SetByKey(ctx context.Context, key string, entity *shared.SettingCreateUpdateDto) (*shared.SettingModel, error) {
+	args := m.Called(ctx, key, entity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*shared.SettingModel), args.Error(1)
+}
+
+func (m *MockSettingService) DeleteByKey(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockSettingService) InitializeSettings(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
 // Test setup helper
 func setupMonitorService() (*MonitorServiceImpl, *MockMonitorRepository, *MockHeartbeatService, *MockEventBus, *MockMonitorNotificationService, *MockMonitorTagService, *MockExecutorRegistry, *MockStatsService) {
 	mockRepo := &MockMonitorRepository{}
@@ -283,7 +381,7 @@ func setupMonitorService() (*MonitorServiceImpl, *MockMonitorRepository, *MockHe
 	realEventBus := infra.NewRedisEventBus(redisClient, logger)
 
 	// Create a real ExecutorRegistry since the service expects a pointer to ExecutorRegistry
-	realExecutorRegistry := executor.NewExecutorRegistry(logger)
+	realExecutorRegistry := executor.NewExecutorRegistry(logger, &config.Config{})
 
 	service := NewMonitorService(
 		mockRepo,
@@ -293,6 +391,7 @@ func setupMonitorService() (*MonitorServiceImpl, *MockMonitorRepository, *MockHe
 		mockTagService,
 		realExecutorRegistry,
 		mockStatsService,
+		nil,
 		logger,
 	).(*MonitorServiceImpl)
 
@@ -566,6 +665,7 @@ func TestMonitorService_Delete(t *testing.T) {
 		service, mockRepo, mockHeartbeatService, _, mockNotificationService, mockTagService, _, mockStatsService := setupMonitorService()
 		monitorID := "monitor123"
 
+		mockRepo.On("FindByID", ctx, monitorID).Return(&Model{ID: monitorID, Name: "Test Monitor"}, nil)
 		mockRepo.On("Delete", ctx, monitorID).Return(nil)
 		mockNotificationService.On("DeleteByMonitorID", ctx, monitorID).Return(nil)
 		mockTagService.On("DeleteByMonitorID", ctx, monitorID).Return(nil)
@@ -586,6 +686,7 @@ func TestMonitorService_Delete(t *testing.T) {
 		service, mockRepo, _, _, _, _, _, _ := setupMonitorService()
 		monitorID := "monitor123"
 
+		mockRepo.On("FindByID", ctx, monitorID).Return(&Model{ID: monitorID, Name: "Test Monitor"}, nil)
 		mockRepo.On("Delete", ctx, monitorID).Return(errors.New("delete failed"))
 
 		err := service.Delete(ctx, monitorID)
@@ -599,6 +700,7 @@ func TestMonitorService_Delete(t *testing.T) {
 		service, mockRepo, mockHeartbeatService, _, mockNotificationService, mockTagService, _, mockStatsService := setupMonitorService()
 		monitorID := "monitor123"
 
+		mockRepo.On("FindByID", ctx, monitorID).Return(&Model{ID: monitorID, Name: "Test Monitor"}, nil)
 		mockRepo.On("Delete", ctx, monitorID).Return(nil)
 		mockNotificationService.On("DeleteByMonitorID", ctx, monitorID).Return(errors.New("cleanup error"))
 		mockTagService.On("DeleteByMonitorID", ctx, monitorID).Return(errors.New("cleanup error"))
@@ -612,6 +714,61 @@ func TestMonitorService_Delete(t *testing.T) {
 	})
 }
 
+func TestMonitorService_LifecycleEvents(t *testing.T) {
+	isLifecycle := func(action string) func(events.Event) bool {
+		return func(e events.Event) bool {
+			if e.Type != events.MonitorLifecycle {
+				return false
+			}
+			payload, ok := e.Payload.(*events.MonitorLifecyclePayload)
+			return ok && payload.Action == action &&
+				payload.MonitorID == "monitor123" &&
+				payload.MonitorName == "Test Monitor" &&
+				payload.ActorType == "user" &&
+				payload.ActorID == "user-1"
+		}
+	}
+
+	setup := func() (*MonitorServiceImpl, *MockMonitorRepository, *MockEventBus) {
+		service, mockRepo, mockHeartbeatService, mockEventBus, mockNotificationService, mockTagService, _, mockStatsService := setupMonitorService()
+		service.eventBus = mockEventBus
+		mockNotificationService.On("DeleteByMonitorID", mock.Anything, mock.Anything).Return(nil)
+		mockTagService.On("DeleteByMonitorID", mock.Anything, mock.Anything).Return(nil)
+		mockHeartbeatService.On("DeleteByMonitorID", mock.Anything, mock.Anything).Return(nil)
+		mockStatsService.On("DeleteByMonitorID", mock.Anything, mock.Anything).Return(nil)
+		return service, mockRepo, mockEventBus
+	}
+
+	// Values the auth middleware sets for a JWT authenticated request
+	ctx := context.WithValue(context.Background(), "authType", "jwt")
+	ctx = context.WithValue(ctx, "userId", "user-1")
+
+	t.Run("create publishes who created the monitor", func(t *testing.T) {
+		service, mockRepo, mockEventBus := setup()
+		mockRepo.On("Create", ctx, mock.Anything).Return(&Model{ID: "monitor123", Name: "Test Monitor", Type: "http"}, nil)
+		mockEventBus.On("Publish", mock.MatchedBy(func(e events.Event) bool { return e.Type == events.MonitorCreated })).Return()
+		mockEventBus.On("Publish", mock.MatchedBy(isLifecycle(events.MonitorActionCreated))).Return().Once()
+
+		_, err := service.Create(ctx, &CreateUpdateDto{Type: "http", Name: "Test Monitor"})
+
+		assert.NoError(t, err)
+		mockEventBus.AssertExpectations(t)
+	})
+
+	t.Run("delete publishes who deleted the monitor", func(t *testing.T) {
+		service, mockRepo, mockEventBus := setup()
+		mockRepo.On("FindByID", ctx, "monitor123").Return(&Model{ID: "monitor123", Name: "Test Monitor", Type: "http"}, nil)
+		mockRepo.On("Delete", ctx, "monitor123").Return(nil)
+		mockEventBus.On("Publish", mock.MatchedBy(func(e events.Event) bool { return e.Type == events.MonitorDeleted })).Return()
+		mockEventBus.On("Publish", mock.MatchedBy(isLifecycle(events.MonitorActionDeleted))).Return().Once()
+
+		err := service.Delete(ctx, "monitor123")
+
+		assert.NoError(t, err)
+		mockEventBus.AssertExpectations(t)
+	})
+}
+
 func TestMonitorService_ValidateMonitorConfig(t *testing.T) {
 	t.Run("successful validation", func(t *testing.T) {
 		service, _, _, _, _, _, _, _ := setupMonitorService()
@@ -693,6 +850,125 @@ func TestMonitorService_GetHeartbeats(t *testing.T) {
 	})
 }
 
+// statusChanges builds important heartbeats, newest first, from status changes given
+// oldest first as offsets from start
+func statusChanges(start time.Time, changes ...any) []*heartbeat.Model {
+	events := make([]*heartbeat.Model, 0, len(changes)/2)
+	for i := 0; i < len(changes); i += 2 {
+		events = append([]*heartbeat.Model{{
+			ID:        fmt.Sprintf("hb%d", i/2+1),
+			Status:    changes[i].(shared.MonitorStatus),
+			Time:      start.Add(changes[i+1].(time.Duration)),
+			Important: true,
+		}}, events...)
+	}
+	return events
+}
+
+func eventIDs(events []*heartbeat.Model) []string {
+	ids := make([]string, len(events))
+	for i, event := range events {
+		ids[i] = event.ID
+	}
+	return ids
+}
+
+func TestMonitorService_GetHeartbeats_ImportanceWindow(t *testing.T) {
+	ctx := context.Background()
+	monitorID := "monitor123"
+	start := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	important := true
+	up, down := shared.MonitorStatusUp, shared.MonitorStatusDown
+
+	setup := func(window string, events []*heartbeat.Model) (*MonitorServiceImpl, *MockHeartbeatService) {
+		service, _, mockHeartbeatService, _, _, _, _, _ := setupMonitorService()
+		mockSettingService := &MockSettingService{}
+		if window == "" {
+			mockSettingService.On("GetByKey", ctx, ImportanceWindowSettingKey).Return(nil, nil)
+		} else {
+			mockSettingService.On("GetByKey", ctx, ImportanceWindowSettingKey).Return(&shared.SettingModel{Key: ImportanceWindowSettingKey, Value: window, Type: "int"}, nil)
+		}
+		service.settingService = mockSettingService
+		mockHeartbeatService.On("FindByMonitorIDPaginated", ctx, monitorID, eventBatchSize, 0, &important, false).Return(events, nil)
+		return service, mockHeartbeatService
+	}
+
+	t.Run("brief flap is collapsed", func(t *testing.T) {
+		// Up, a 10 second blip, then up again
+		events := statusChanges(start, up, time.Duration(0), down, time.Hour, up, time.Hour+10*time.Second)
+		service, mockHeartbeatService := setup("30", events)
+
+		result, err := service.GetHeartbeats(ctx, monitorID, 10, 0, &important, false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"hb1"}, eventIDs(result))
+		mockHeartbeatService.AssertExpectations(t)
+	})
+
+	t.Run("sustained outage keeps distinct events", func(t *testing.T) {
+		events := statusChanges(start, up, time.Duration(0), down, time.Hour, up, time.Hour+5*time.Minute)
+		service, _ := setup("30", events)
+
+		result, err := service.GetHeartbeats(ctx, monitorID, 10, 0, &important, false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"hb3", "hb2", "hb1"}, eventIDs(result))
+	})
+
+	t.Run("flap next to an outage only drops the flap", func(t *testing.T) {
+		events := statusChanges(start,
+			up, time.Duration(0),
+			down, time.Hour, // 5 minute outage
+			up, time.Hour+5*time.Minute,
+			down, 2*time.Hour, // 10 second blip
+			up, 2*time.Hour+10*time.Second,
+		)
+		service, _ := setup("30", events)
+
+		result, err := service.GetHeartbeats(ctx, monitorID, 10, 0, &important, true)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"hb1", "hb2", "hb3"}, eventIDs(result))
+	})
+
+	t.Run("pages over collapsed events", func(t *testing.T) {
+		events := statusChanges(start,
+			up, time.Duration(0),
+			down, time.Hour,
+			up, time.Hour+5*time.Second,
+			down, 2*time.Hour,
+			up, 3*time.Hour,
+		)
+		service, _ := setup("30", events)
+
+		result, err := service.GetHeartbeats(ctx, monitorID, 2, 1, &important, false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"hb1"}, eventIDs(result))
+	})
+
+	t.Run("window not configured returns raw events", func(t *testing.T) {
+		events := statusChanges(start, up, time.Duration(0), down, time.Hour, up, time.Hour+10*time.Second)
+		service, mockHeartbeatService := setup("", nil)
+		mockHeartbeatService.On("FindByMonitorIDPaginated", ctx, monitorID, 10, 0, &important, false).Return(events, nil)
+
+		result, err := service.GetHeartbeats(ctx, monitorID, 10, 0, &important, false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, events, result)
+	})
+
+	t.Run("all heartbeats are not collapsed", func(t *testing.T) {
+		service, mockHeartbeatService := setup("30", nil)
+		mockHeartbeatService.On("FindByMonitorIDPaginated", ctx, monitorID, 10, 0, (*bool)(nil), false).Return([]*heartbeat.Model{}, nil)
+
+		result, err := service.GetHeartbeats(ctx, monitorID, 10, 0, nil, false)
+
+		assert.NoError(t, err)
+		assert.Empty(t, result)
+	})
+}
+
 func TestMonitorService_GetStatPoints(t *testing.T) {
 	ctx := context.Background()
 
@@ -729,7 +1005,7 @@ func TestMonitorService_GetStatPoints(t *testing.T) {
 
 		mockRepo.On("FindByID", ctx, monitorID).Return(monitor, nil)
 		mockStatsService.On("FindStatsByMonitorIDAndTimeRangeWithInterval", ctx, monitorID, since, until, stats.StatHourly, monitor.Interval).Return(statsList, nil)
-		mockStatsService.On("StatPointsSummary", statsList).Return(summary)
+		mockStatsService.On("StatPointsSummary", mock.Anything, statsList).Return(summary)
 
 		result, err := service.GetStatPoints(ctx, monitorID, since, until, granularity)
 
@@ -843,7 +1119,7 @@ func TestMonitorService_GetUptimeStats(t *testing.T) {
 
 		// Mock calls for each time period
 		mockStatsService.On("FindStatsByMonitorIDAndTimeRange", ctx, monitorID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), stats.StatDaily).Return(statsList, nil).Times(4)
-		mockStatsService.On("StatPointsSummary", statsList).Return(summary).Times(4)
+		mockStatsService.On("StatPointsSummary", mock.Anything, statsList).Return(summary).Times(4)
 
 		result, err := service.GetUptimeStats(ctx, monitorID)
 
@@ -867,7 +1143,7 @@ func TestMonitorService_GetUptimeStats(t *testing.T) {
 		}
 
 		mockStatsService.On("FindStatsByMonitorIDAndTimeRange", ctx, monitorID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), stats.StatDaily).Return(statsList, nil).Times(4)
-		mockStatsService.On("StatPointsSummary", statsList).Return(summary).Times(4)
+		mockStatsService.On("StatPointsSummary", mock.Anything, statsList).Return(summary).Times(4)
 
 		result, err := service.GetUptimeStats(ctx, monitorID)
 
@@ -1238,7 +1514,7 @@ func TestNewMonitorService(t *testing.T) {
 
 	// Create real instances for dependencies that expect concrete types
 	realEventBus := infra.NewRedisEventBus(redisClient, logger)
-	realExecutorRegistry := executor.NewExecutorRegistry(logger)
+	realExecutorRegistry := executor.NewExecutorRegistry(logger, &config.Config{})
 
 	service := NewMonitorService(
 		mockRepo,
@@ -1248,6 +1524,7 @@ func TestNewMonitorService(t *testing.T) {
 		mockTagService,
 		realExecutorRegistry,
 		mockStatsService,
+		nil,
 		logger,
 	)
 
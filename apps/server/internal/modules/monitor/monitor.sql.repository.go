@@ -3,6 +3,7 @@ package monitor
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"peekaping/internal/modules/shared"
@@ -14,21 +15,37 @@ import (
 type sqlModel struct {
 	bun.BaseModel `bun:"table:monitors,alias:m"`
 
-	ID             string               `bun:"id,pk"`
-	Type           string               `bun:"type,notnull"`
-	Name           string               `bun:"name,notnull"`
-	Interval       int                  `bun:"interval,notnull"`
-	Timeout        int                  `bun:"timeout,notnull"`
-	MaxRetries     int                  `bun:"max_retries,notnull"`
-	RetryInterval  int                  `bun:"retry_interval,notnull"`
-	ResendInterval int                  `bun:"resend_interval,notnull"`
-	Active         bool                 `bun:"active,notnull,default:true"`
-	Status         shared.MonitorStatus `bun:"status,notnull,default:0"`
-	CreatedAt      time.Time            `bun:"created_at,nullzero,notnull,default:current_timestamp"`
-	UpdatedAt      time.Time            `bun:"updated_at,nullzero,notnull,default:current_timestamp"`
-	Config         string               `bun:"config"`
-	ProxyId        *string              `bun:"proxy_id"`
-	PushToken      string               `bun:"push_token"`
+	ID                 string               `bun:"id,pk"`
+	Type               string               `bun:"type,notnull"`
+	Name               string               `bun:"name,notnull"`
+	Interval           int                  `bun:"interval,notnull"`
+	Timeout            int                  `bun:"timeout,notnull"`
+	MaxRetries         int                  `bun:"max_retries,notnull"`
+	RetryInterval      int                  `bun:"retry_interval,notnull"`
+	RetryBackoff       string               `bun:"retry_backoff,notnull,default:'fixed'"`
+	ResendInterval     int                  `bun:"resend_interval,notnull"`
+	WarmupChecks       int                  `bun:"warmup_checks,notnull,default:0"`
+	LatencyLimit       int                  `bun:"latency_limit,notnull,default:0"`
+	LatencyChecks      int                  `bun:"latency_checks,notnull,default:0"`
+	Criticality        string               `bun:"criticality,notnull,default:'medium'"`
+	ResultExpression   string               `bun:"result_expression,notnull,default:''"`
+	UpMessage          string               `bun:"up_message,notnull,default:''"`
+	DownMessage        string               `bun:"down_message,notnull,default:''"`
+	NoProxy            bool                 `bun:"no_proxy,notnull,default:false"`
+	Serialize          bool                 `bun:"serialize,notnull,default:false"`
+	ConfirmTransition  bool                 `bun:"confirm_transition,notnull,default:false"`
+	ParentId           string               `bun:"parent_id,notnull,default:''"`
+	SkipWhenParentDown bool                 `bun:"skip_when_parent_down,notnull,default:false"`
+	ParentDownTimeout  int                  `bun:"parent_down_timeout,notnull,default:0"`
+	Active             bool                 `bun:"active,notnull,default:true"`
+	Status             shared.MonitorStatus `bun:"status,notnull,default:0"`
+	CreatedAt          time.Time            `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+	UpdatedAt          time.Time            `bun:"updated_at,nullzero,notnull,default:current_timestamp"`
+	Config             string               `bun:"config"`
+	ProxyId            *string              `bun:"proxy_id"`
+	FallbackProxyIds   string               `bun:"fallback_proxy_ids,notnull,default:''"`
+	PushToken          string               `bun:"push_token"`
+	ActivatedAt        *time.Time           `bun:"activated_at"`
 }
 
 func toDomainModelFromSQL(sm *sqlModel) *Model {
@@ -39,21 +56,37 @@ func toDomainModelFromSQL(sm *sqlModel) *Model {
 	}
 
 	return &Model{
-		ID:             sm.ID,
-		Type:           sm.Type,
-		Name:           sm.Name,
-		Interval:       sm.Interval,
-		Timeout:        sm.Timeout,
-		MaxRetries:     sm.MaxRetries,
-		RetryInterval:  sm.RetryInterval,
-		ResendInterval: sm.ResendInterval,
-		Active:         sm.Active,
-		Status:         sm.Status,
-		CreatedAt:      sm.CreatedAt,
-		UpdatedAt:      sm.UpdatedAt,
-		Config:         sm.Config,
-		ProxyId:        proxyId,
-		PushToken:      sm.PushToken,
+		ID:                 sm.ID,
+		Type:               sm.Type,
+		Name:               sm.Name,
+		Interval:           sm.Interval,
+		Timeout:            sm.Timeout,
+		MaxRetries:         sm.MaxRetries,
+		RetryInterval:      sm.RetryInterval,
+		RetryBackoff:       sm.RetryBackoff,
+		ResendInterval:     sm.ResendInterval,
+		WarmupChecks:       sm.WarmupChecks,
+		LatencyLimit:       sm.LatencyLimit,
+		LatencyChecks:      sm.LatencyChecks,
+		Criticality:        sm.Criticality,
+		ResultExpression:   sm.ResultExpression,
+		UpMessage:          sm.UpMessage,
+		DownMessage:        sm.DownMessage,
+		NoProxy:            sm.NoProxy,
+		Serialize:          sm.Serialize,
+		ConfirmTransition:  sm.ConfirmTransition,
+		ParentId:           sm.ParentId,
+		SkipWhenParentDown: sm.SkipWhenParentDown,
+		ParentDownTimeout:  sm.ParentDownTimeout,
+		Active:             sm.Active,
+		Status:             sm.Status,
+		CreatedAt:          sm.CreatedAt,
+		UpdatedAt:          sm.UpdatedAt,
+		Config:             sm.Config,
+		ProxyId:            proxyId,
+		FallbackProxyIds:   splitProxyIds(sm.FallbackProxyIds),
+		PushToken:          sm.PushToken,
+		ActivatedAt:        sm.ActivatedAt,
 	}
 }
 
@@ -65,21 +98,37 @@ func toSQLModel(m *Model) *sqlModel {
 	}
 
 	return &sqlModel{
-		ID:             m.ID,
-		Type:           m.Type,
-		Name:           m.Name,
-		Interval:       m.Interval,
-		Timeout:        m.Timeout,
-		MaxRetries:     m.MaxRetries,
-		RetryInterval:  m.RetryInterval,
-		ResendInterval: m.ResendInterval,
-		Active:         m.Active,
-		Status:         m.Status,
-		CreatedAt:      m.CreatedAt,
-		UpdatedAt:      m.UpdatedAt,
-		Config:         m.Config,
-		ProxyId:        proxyId,
-		PushToken:      m.PushToken,
+		ID:                 m.ID,
+		Type:               m.Type,
+		Name:               m.Name,
+		Interval:           m.Interval,
+		Timeout:            m.Timeout,
+		MaxRetries:         m.MaxRetries,
+		RetryInterval:      m.RetryInterval,
+		RetryBackoff:       m.RetryBackoff,
+		ResendInterval:     m.ResendInterval,
+		WarmupChecks:       m.WarmupChecks,
+		LatencyLimit:       m.LatencyLimit,
+		LatencyChecks:      m.LatencyChecks,
+		Criticality:        m.Criticality,
+		ResultExpression:   m.ResultExpression,
+		UpMessage:          m.UpMessage,
+		DownMessage:        m.DownMessage,
+		NoProxy:            m.NoProxy,
+		Serialize:          m.Serialize,
+		ConfirmTransition:  m.ConfirmTransition,
+		ParentId:           m.ParentId,
+		SkipWhenParentDown: m.SkipWhenParentDown,
+		ParentDownTimeout:  m.ParentDownTimeout,
+		Active:             m.Active,
+		Status:             m.Status,
+		CreatedAt:          m.CreatedAt,
+		UpdatedAt:          m.UpdatedAt,
+		Config:             m.Config,
+		ProxyId:            proxyId,
+		FallbackProxyIds:   strings.Join(m.FallbackProxyIds, ","),
+		PushToken:          m.PushToken,
+		ActivatedAt:        m.ActivatedAt,
 	}
 }
 
@@ -281,10 +330,70 @@ func (r *SQLRepositoryImpl) UpdatePartial(ctx context.Context, id string, monito
 		query = query.Set("retry_interval = ?", *monitor.RetryInterval)
 		hasUpdates = true
 	}
+	if monitor.RetryBackoff != nil {
+		query = query.Set("retry_backoff = ?", *monitor.RetryBackoff)
+		hasUpdates = true
+	}
 	if monitor.ResendInterval != nil {
 		query = query.Set("resend_interval = ?", *monitor.ResendInterval)
 		hasUpdates = true
 	}
+	if monitor.WarmupChecks != nil {
+		query = query.Set("warmup_checks = ?", *monitor.WarmupChecks)
+		hasUpdates = true
+	}
+	if monitor.LatencyLimit != nil {
+		query = query.Set("latency_limit = ?", *monitor.LatencyLimit)
+		hasUpdates = true
+	}
+	if monitor.LatencyChecks != nil {
+		query = query.Set("latency_checks = ?", *monitor.LatencyChecks)
+		hasUpdates = true
+	}
+	if monitor.Criticality != nil {
+		query = query.Set("criticality = ?", *monitor.Criticality)
+		hasUpdates = true
+	}
+	if monitor.ResultExpression != nil {
+		query = query.Set("result_expression = ?", *monitor.ResultExpression)
+		hasUpdates = true
+	}
+	if monitor.UpMessage != nil {
+		query = query.Set("up_message = ?", *monitor.UpMessage)
+		hasUpdates = true
+	}
+	if monitor.DownMessage != nil {
+		query = query.Set("down_message = ?", *monitor.DownMessage)
+		hasUpdates = true
+	}
+	if monitor.NoProxy != nil {
+		query = query.Set("no_proxy = ?", *monitor.NoProxy)
+		hasUpdates = true
+	}
+	if monitor.Serialize != nil {
+		query = query.Set("serialize = ?", *monitor.Serialize)
+		hasUpdates = true
+	}
+	if monitor.ConfirmTransition != nil {
+		query = query.Set("confirm_transition = ?", *monitor.ConfirmTransition)
+		hasUpdates = true
+	}
+	if monitor.ParentId != nil {
+		query = query.Set("parent_id = ?", *monitor.ParentId)
+		hasUpdates = true
+	}
+	if monitor.SkipWhenParentDown != nil {
+		query = query.Set("skip_when_parent_down = ?", *monitor.SkipWhenParentDown)
+		hasUpdates = true
+	}
+	if monitor.ParentDownTimeout != nil {
+		query = query.Set("parent_down_timeout = ?", *monitor.ParentDownTimeout)
+		hasUpdates = true
+	}
+	if monitor.FallbackProxyIds != nil {
+		query = query.Set("fallback_proxy_ids = ?", strings.Join(*monitor.FallbackProxyIds, ","))
+		hasUpdates = true
+	}
 	if monitor.Active != nil {
 		query = query.Set("active = ?", *monitor.Active)
 		hasUpdates = true
@@ -310,6 +419,10 @@ func (r *SQLRepositoryImpl) UpdatePartial(ctx context.Context, id string, monito
 		query = query.Set("push_token = ?", *monitor.PushToken)
 		hasUpdates = true
 	}
+	if monitor.ActivatedAt != nil {
+		query = query.Set("activated_at = ?", *monitor.ActivatedAt)
+		hasUpdates = true
+	}
 
 	if !hasUpdates {
 		return nil
@@ -364,3 +477,11 @@ func (r *SQLRepositoryImpl) FindOneByPushToken(ctx context.Context, pushToken st
 	}
 	return toDomainModelFromSQL(sm), nil
 }
+
+// splitProxyIds reads the comma separated fallback_proxy_ids column
+func splitProxyIds(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
@@ -30,14 +30,30 @@ func setupTestDB(t *testing.T) *bun.DB {
 			timeout INTEGER NOT NULL,
 			max_retries INTEGER NOT NULL,
 			retry_interval INTEGER NOT NULL,
+			retry_backoff TEXT NOT NULL DEFAULT 'fixed',
 			resend_interval INTEGER NOT NULL,
+			warmup_checks INTEGER NOT NULL DEFAULT 0,
+			latency_limit INTEGER NOT NULL DEFAULT 0,
+			latency_checks INTEGER NOT NULL DEFAULT 0,
+			criticality TEXT NOT NULL DEFAULT 'medium',
+			result_expression TEXT NOT NULL DEFAULT '',
+			up_message TEXT NOT NULL DEFAULT '',
+			down_message TEXT NOT NULL DEFAULT '',
+			no_proxy BOOLEAN NOT NULL DEFAULT FALSE,
+			serialize BOOLEAN NOT NULL DEFAULT FALSE,
+			confirm_transition BOOLEAN NOT NULL DEFAULT FALSE,
+			parent_id TEXT NOT NULL DEFAULT '',
+			skip_when_parent_down BOOLEAN NOT NULL DEFAULT FALSE,
+			parent_down_timeout INTEGER NOT NULL DEFAULT 0,
 			active BOOLEAN NOT NULL DEFAULT TRUE,
 			status INTEGER NOT NULL DEFAULT 0,
 			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 			config TEXT,
 			proxy_id TEXT,
-			push_token TEXT
+			fallback_proxy_ids TEXT NOT NULL DEFAULT '',
+			push_token TEXT,
+			activated_at DATETIME
 		)
 	`)
 	require.NoError(t, err)
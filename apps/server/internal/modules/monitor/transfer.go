@@ -0,0 +1,235 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// exportPageSize is how many monitors are loaded at once while exporting
+	exportPageSize = 100
+	// maxImportMonitors bounds the monitors of a single import request
+	maxImportMonitors = 1000
+)
+
+// ErrInvalidMonitor wraps the reasons a restored monitor definition is refused
+var ErrInvalidMonitor = errors.New("invalid monitor")
+
+// NotificationChannelNames looks notification channels up by name for monitor export
+// and import. It is implemented by the notification_channel module, which depends on
+// this one.
+type NotificationChannelNames interface {
+	// ChannelNames returns the names of all notification channels by id
+	ChannelNames(ctx context.Context) (map[string]string, error)
+}
+
+// exportMonitors returns the definitions of all monitors with their tags and notification
+// channels referenced by name. Proxies and parents are instance specific and left out.
+func (ic *MonitorController) exportMonitors(ctx context.Context) ([]ExportDto, error) {
+	channelNames, err := ic.channelNames.ChannelNames(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch notification channels: %w", err)
+	}
+	tagNames := make(map[string]string)
+
+	exported := make([]ExportDto, 0)
+	for page := 0; ; page++ {
+		monitors, err := ic.monitorService.FindAll(ctx, page, exportPageSize, "", nil, nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch monitors: %w", err)
+		}
+
+		for _, m := range monitors {
+			dto := ExportDto{
+				Type:              m.Type,
+				Name:              m.Name,
+				Interval:          m.Interval,
+				MaxRetries:        m.MaxRetries,
+				RetryInterval:     m.RetryInterval,
+				RetryBackoff:      m.RetryBackoff,
+				Timeout:           m.Timeout,
+				ResendInterval:    m.ResendInterval,
+				WarmupChecks:      m.WarmupChecks,
+				LatencyLimit:      m.LatencyLimit,
+				LatencyChecks:     m.LatencyChecks,
+				Criticality:       m.Criticality,
+				ResultExpression:  m.ResultExpression,
+				UpMessage:         m.UpMessage,
+				DownMessage:       m.DownMessage,
+				Active:            m.Active,
+				NoProxy:           m.NoProxy,
+				Serialize:         m.Serialize,
+				ConfirmTransition: m.ConfirmTransition,
+				Config:            m.Config,
+				PushToken:         m.PushToken,
+				Tags:              make([]string, 0),
+				Notifications:     make([]NotificationExportDto, 0),
+			}
+
+			notificationRels, err := ic.monitorNotificationService.FindByMonitorID(ctx, m.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch notifications of monitor %s: %w", m.ID, err)
+			}
+			for _, rel := range notificationRels {
+				name, ok := channelNames[rel.NotificationID]
+				if !ok {
+					continue
+				}
+				dto.Notifications = append(dto.Notifications, NotificationExportDto{
+					Name:              name,
+					MinCriticality:    rel.MinCriticality,
+					EventTypes:        rel.EventTypes,
+					DigestOnly:        rel.DigestOnly,
+					EscalateAfter:     rel.EscalateAfter,
+					NotificationLimit: rel.NotificationLimit,
+				})
+			}
+
+			tagRels, err := ic.monitorTagService.FindByMonitorID(ctx, m.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch tags of monitor %s: %w", m.ID, err)
+			}
+			for _, rel := range tagRels {
+				name, ok := tagNames[rel.TagID]
+				if !ok {
+					t, err := ic.tagService.FindByID(ctx, rel.TagID)
+					if err != nil {
+						return nil, fmt.Errorf("failed to fetch tag %s: %w", rel.TagID, err)
+					}
+					if t != nil {
+						name = t.Name
+					}
+					tagNames[rel.TagID] = name
+				}
+				if name != "" {
+					dto.Tags = append(dto.Tags, name)
+				}
+			}
+
+			exported = append(exported, dto)
+		}
+
+		if len(monitors) < exportPageSize {
+			return exported, nil
+		}
+	}
+}
+
+// channelIDsByName inverts the channel names. Names used by several channels map to an
+// empty id, since a reference to them is ambiguous.
+func channelIDsByName(names map[string]string) map[string]string {
+	ids := make(map[string]string, len(names))
+	for id, name := range names {
+		if _, taken := ids[name]; taken {
+			ids[name] = ""
+			continue
+		}
+		ids[name] = id
+	}
+	return ids
+}
+
+// toCreateDto resolves the tag and notification channel names of an imported monitor
+func (ic *MonitorController) toCreateDto(ctx context.Context, item *ExportDto, channelIDs map[string]string) (*CreateUpdateDto, error) {
+	dto := &CreateUpdateDto{
+		Type:                       item.Type,
+		Name:                       item.Name,
+		Interval:                   item.Interval,
+		MaxRetries:                 item.MaxRetries,
+		RetryInterval:              item.RetryInterval,
+		RetryBackoff:               item.RetryBackoff,
+		Timeout:                    item.Timeout,
+		ResendInterval:             item.ResendInterval,
+		WarmupChecks:               item.WarmupChecks,
+		LatencyLimit:               item.LatencyLimit,
+		LatencyChecks:              item.LatencyChecks,
+		Criticality:                item.Criticality,
+		ResultExpression:           item.ResultExpression,
+		UpMessage:                  item.UpMessage,
+		DownMessage:                item.DownMessage,
+		Active:                     item.Active,
+		NoProxy:                    item.NoProxy,
+		Serialize:                  item.Serialize,
+		ConfirmTransition:          item.ConfirmTransition,
+		Config:                     item.Config,
+		PushToken:                  item.PushToken,
+		NotificationIds:            make([]string, 0, len(item.Notifications)),
+		NotificationMinCriticality: make(map[string]string),
+		NotificationEventTypes:     make(map[string][]string),
+		NotificationDigestOnly:     make(map[string]bool),
+		NotificationEscalateAfter:  make(map[string]int),
+		NotificationLimit:          make(map[string]int),
+		TagIds:                     make([]string, 0, len(item.Tags)),
+	}
+
+	for _, n := range item.Notifications {
+		id, ok := channelIDs[n.Name]
+		if !ok {
+			return nil, fmt.Errorf("notification channel %q not found", n.Name)
+		}
+		if id == "" {
+			return nil, fmt.Errorf("several notification channels are named %q", n.Name)
+		}
+		dto.NotificationIds = append(dto.NotificationIds, id)
+		if n.MinCriticality != "" {
+			dto.NotificationMinCriticality[id] = n.MinCriticality
+		}
+		if len(n.EventTypes) > 0 {
+			dto.NotificationEventTypes[id] = n.EventTypes
+		}
+		if n.DigestOnly {
+			dto.NotificationDigestOnly[id] = true
+		}
+		if n.EscalateAfter > 0 {
+			dto.NotificationEscalateAfter[id] = n.EscalateAfter
+		}
+		if n.NotificationLimit > 0 {
+			dto.NotificationLimit[id] = n.NotificationLimit
+		}
+	}
+
+	for _, name := range item.Tags {
+		t, err := ic.tagService.FindByName(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch tag %q: %w", name, err)
+		}
+		if t == nil {
+			return nil, fmt.Errorf("tag %q not found", name)
+		}
+		dto.TagIds = append(dto.TagIds, t.ID)
+	}
+
+	if item.PushToken != "" {
+		existing, err := ic.monitorService.FindOneByPushToken(ctx, item.PushToken)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check push token: %w", err)
+		}
+		if existing != nil {
+			return nil, fmt.Errorf("push token is already used by monitor %q", existing.Name)
+		}
+	}
+
+	return dto, nil
+}
+
+// Restore creates a monitor from a complete definition that references its tags,
+// notification channels, proxies and parent by id, with the validation of POST /monitors.
+// It is used to restore backups, validation failures wrap ErrInvalidMonitor.
+func (ic *MonitorController) Restore(ctx *gin.Context, dto *CreateUpdateDto) (*Model, error) {
+	if dto.PushToken != "" {
+		existing, err := ic.monitorService.FindOneByPushToken(ctx, dto.PushToken)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check push token: %w", err)
+		}
+		if existing != nil {
+			return nil, fmt.Errorf("%w: push token is already used by monitor %q", ErrInvalidMonitor, existing.Name)
+		}
+	}
+	if err := ic.validateCreate(ctx, dto); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMonitor, err)
+	}
+	return ic.createMonitor(ctx, dto)
+}
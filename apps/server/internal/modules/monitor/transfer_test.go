@@ -0,0 +1,184 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"peekaping/internal/config"
+	"peekaping/internal/modules/audit_log"
+	"peekaping/internal/modules/monitor_notification"
+	"peekaping/internal/modules/monitor_tag"
+	"peekaping/internal/modules/tag"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeTagService resolves tags from a fixed list, other tag.Service methods aren't used
+type fakeTagService struct {
+	tag.Service
+	tags []*tag.Model
+}
+
+func (f *fakeTagService) FindByID(ctx context.Context, id string) (*tag.Model, error) {
+	for _, t := range f.tags {
+		if t.ID == id {
+			return t, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeTagService) FindByName(ctx context.Context, name string) (*tag.Model, error) {
+	for _, t := range f.tags {
+		if t.Name == name {
+			return t, nil
+		}
+	}
+	return nil, nil
+}
+
+type fakeChannelNames map[string]string
+
+func (f fakeChannelNames) ChannelNames(ctx context.Context) (map[string]string, error) {
+	return f, nil
+}
+
+func setupTransferController() (*gin.Engine, *MockMonitorRepository, *MockMonitorNotificationService, *MockMonitorTagService) {
+	service, mockRepo, _, _, mockNotificationService, mockTagService, _, _ := setupMonitorService()
+	controller := &MonitorController{
+		monitorService:             service,
+		logger:                     zap.NewNop().Sugar(),
+		monitorNotificationService: mockNotificationService,
+		monitorTagService:          mockTagService,
+		auditLogService:            audit_log.NewService(&MockAuditLogRepository{}, &config.Config{}, zap.NewNop().Sugar()),
+		tagService: &fakeTagService{tags: []*tag.Model{
+			{ID: "tag-prod", Name: "Production"},
+			{ID: "tag-api", Name: "API"},
+		}},
+		channelNames: fakeChannelNames{
+			"chan-slack": "Ops Slack",
+			"chan-mail":  "Email",
+			"chan-dup-1": "Pager",
+			"chan-dup-2": "Pager",
+		},
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/monitors/export", controller.ExportMonitors)
+	router.POST("/monitors/import", controller.ImportMonitors)
+	return router, mockRepo, mockNotificationService, mockTagService
+}
+
+func TestMonitorController_ExportMonitors(t *testing.T) {
+	router, mockRepo, mockNotificationService, mockTagService := setupTransferController()
+
+	monitors := []*Model{
+		{ID: "mon-1", Type: "http", Name: "API", Interval: 60, RetryInterval: 60, Timeout: 16, Active: true, Criticality: "high",
+			ProxyId: "proxy-1", ParentId: "mon-2", Config: `{"url":"https://example.com"}`},
+		{ID: "mon-2", Type: "push", Name: "Backup job", Interval: 3600, RetryInterval: 60, Timeout: 16, PushToken: "push-token"},
+	}
+	mockRepo.On("FindAll", mock.Anything, 0, exportPageSize, "", (*bool)(nil), (*int)(nil), []string(nil)).Return(monitors, nil)
+	mockNotificationService.On("FindByMonitorID", mock.Anything, "mon-1").Return([]*monitor_notification.Model{
+		{NotificationID: "chan-slack", MinCriticality: "high", EventTypes: []string{"down"}, DigestOnly: true},
+		{NotificationID: "chan-deleted"},
+	}, nil)
+	mockNotificationService.On("FindByMonitorID", mock.Anything, "mon-2").Return([]*monitor_notification.Model{}, nil)
+	mockTagService.On("FindByMonitorID", mock.Anything, "mon-1").Return([]*monitor_tag.Model{{TagID: "tag-prod"}, {TagID: "tag-api"}}, nil)
+	mockTagService.On("FindByMonitorID", mock.Anything, "mon-2").Return([]*monitor_tag.Model{{TagID: "tag-prod"}}, nil)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/monitors/export", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, `attachment; filename="monitors.json"`, rec.Header().Get("Content-Disposition"))
+	assert.JSONEq(t, `[
+		{
+			"type": "http", "name": "API", "interval": 60, "max_retries": 0, "retry_interval": 60, "timeout": 16,
+			"resend_interval": 0, "warmup_checks": 0, "latency_limit": 0, "latency_checks": 0, "criticality": "high",
+			"active": true, "no_proxy": false, "serialize": false, "confirm_transition": false, "config": "{\"url\":\"https://example.com\"}",
+			"tags": ["Production", "API"],
+			"notifications": [{"name": "Ops Slack", "min_criticality": "high", "event_types": ["down"], "digest_only": true}]
+		},
+		{
+			"type": "push", "name": "Backup job", "interval": 3600, "max_retries": 0, "retry_interval": 60, "timeout": 16,
+			"resend_interval": 0, "warmup_checks": 0, "latency_limit": 0, "latency_checks": 0,
+			"active": false, "no_proxy": false, "serialize": false, "confirm_transition": false, "config": "", "push_token": "push-token",
+			"tags": ["Production"],
+			"notifications": []
+		}
+	]`, rec.Body.String())
+}
+
+func TestMonitorController_ImportMonitors(t *testing.T) {
+	router, mockRepo, mockNotificationService, mockTagService := setupTransferController()
+
+	mockRepo.On("FindOneByPushToken", mock.Anything, "taken-token").Return(&Model{ID: "mon-9", Name: "Old job"}, nil)
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(m *Model) bool { return m.Name == "API" })).
+		Return(&Model{ID: "new-1", Name: "API"}, nil)
+	mockNotificationService.On("Create", mock.Anything, "new-1", "chan-slack", monitor_notification.Settings{MinCriticality: "high", EventTypes: []string{"down"}, DigestOnly: true}).
+		Return(&monitor_notification.Model{ID: "rel-1"}, nil)
+	mockTagService.On("Create", mock.Anything, "new-1", "tag-prod").Return(&monitor_tag.Model{}, nil)
+
+	body := `[
+		{
+			"type": "http", "name": "API", "interval": 60, "retry_interval": 60, "timeout": 16, "active": true,
+			"config": "{\"url\":\"https://example.com\",\"method\":\"GET\",\"encoding\":\"json\",\"accepted_statuscodes\":[\"2XX\"],\"authMethod\":\"none\"}",
+			"tags": ["Production"],
+			"notifications": [{"name": "Ops Slack", "min_criticality": "high", "event_types": ["down"], "digest_only": true}]
+		},
+		{"type": "http", "name": "Unknown channel", "interval": 60, "retry_interval": 60, "timeout": 16, "notifications": [{"name": "Teams"}]},
+		{"type": "http", "name": "Ambiguous channel", "interval": 60, "retry_interval": 60, "timeout": 16, "notifications": [{"name": "Pager"}]},
+		{"type": "http", "name": "Unknown tag", "interval": 60, "retry_interval": 60, "timeout": 16, "tags": ["Staging"]},
+		{"type": "http", "name": "Bad config", "interval": 60, "retry_interval": 60, "timeout": 16, "config": "{}"},
+		{"type": "http", "name": "Too often", "interval": 5, "retry_interval": 60, "timeout": 16},
+		{"type": "push", "name": "Taken token", "interval": 60, "retry_interval": 60, "timeout": 16, "push_token": "taken-token"}
+	]`
+	req := httptest.NewRequest(http.MethodPost, "/monitors/import", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var response struct {
+		Message string            `json:"message"`
+		Data    []ImportResultDto `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, "Imported 1 of 7 monitors", response.Message)
+	require.Len(t, response.Data, 7)
+
+	assert.Equal(t, ImportResultDto{Index: 0, Name: "API", Success: true, ID: "new-1"}, response.Data[0])
+	assert.Equal(t, `notification channel "Teams" not found`, response.Data[1].Error)
+	assert.Equal(t, `several notification channels are named "Pager"`, response.Data[2].Error)
+	assert.Equal(t, `tag "Staging" not found`, response.Data[3].Error)
+	assert.Contains(t, response.Data[4].Error, "Invalid monitor configuration")
+	assert.Contains(t, response.Data[5].Error, "Interval")
+	assert.Equal(t, `push token is already used by monitor "Old job"`, response.Data[6].Error)
+	for _, result := range response.Data[1:] {
+		assert.False(t, result.Success, result.Name)
+		assert.Empty(t, result.ID, result.Name)
+	}
+
+	mockRepo.AssertNumberOfCalls(t, "Create", 1)
+	mockNotificationService.AssertExpectations(t)
+	mockTagService.AssertExpectations(t)
+}
+
+func TestMonitorController_ImportMonitors_InvalidBody(t *testing.T) {
+	router, _, _, _ := setupTransferController()
+
+	req := httptest.NewRequest(http.MethodPost, "/monitors/import", strings.NewReader(`{"name": "not a list"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
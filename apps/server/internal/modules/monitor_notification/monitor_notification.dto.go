@@ -4,3 +4,12 @@ type CreateDto struct {
 	MonitorID      string `json:"monitor_id"`
 	NotificationID string `json:"notification_id"`
 }
+
+// Settings are the options of a notification channel for a monitor, see Model
+type Settings struct {
+	MinCriticality    string
+	EventTypes        []string
+	DigestOnly        bool
+	EscalateAfter     int
+	NotificationLimit int
+}
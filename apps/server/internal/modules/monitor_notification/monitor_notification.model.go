@@ -3,9 +3,27 @@ package monitor_notification
 import "time"
 
 type Model struct {
-	ID             string    `json:"id"`
-	MonitorID      string    `json:"monitor_id"`
-	NotificationID string    `json:"notification_id"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
+	ID             string `json:"id"`
+	MonitorID      string `json:"monitor_id"`
+	NotificationID string `json:"notification_id"`
+	// MinCriticality is the lowest monitor criticality this channel is notified for,
+	// empty means every monitor
+	MinCriticality string `json:"min_criticality,omitempty"`
+	// EventTypes are the notification event types (see shared.NotificationEventDown etc.)
+	// this channel is notified of, empty means every type
+	EventTypes []string `json:"event_types,omitempty"`
+	// DigestOnly collects the status changes of the monitor for the channel's digest
+	// instead of notifying each of them
+	DigestOnly bool `json:"digest_only,omitempty"`
+	// EscalateAfter holds the channel back until an outage lasted this many consecutive
+	// failed checks, for escalating long outages to a second channel. 0 notifies at once.
+	EscalateAfter int `json:"escalate_after,omitempty"`
+	// NotificationLimit caps the down notifications, the first one and the resends, a
+	// single outage sends to the channel. The recovery is always sent. 0 means no cap.
+	NotificationLimit int `json:"notification_limit,omitempty"`
+	// OutageNotifications counts the down notifications sent for the current outage,
+	// it is reset when the monitor recovers
+	OutageNotifications int       `json:"outage_notifications,omitempty"`
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"peekaping/internal/config"
+	"peekaping/internal/modules/shared"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -13,20 +14,32 @@ import (
 )
 
 type mongoModel struct {
-	ID             primitive.ObjectID `bson:"_id"`
-	MonitorID      primitive.ObjectID `bson:"monitor_id"`
-	NotificationID primitive.ObjectID `bson:"notification_id"`
-	CreatedAt      time.Time          `bson:"created_at"`
-	UpdatedAt      time.Time          `bson:"updated_at"`
+	ID                  primitive.ObjectID `bson:"_id"`
+	MonitorID           primitive.ObjectID `bson:"monitor_id"`
+	NotificationID      primitive.ObjectID `bson:"notification_id"`
+	MinCriticality      string             `bson:"min_criticality,omitempty"`
+	EventMask           int                `bson:"event_mask,omitempty"`
+	DigestOnly          bool               `bson:"digest_only,omitempty"`
+	EscalateAfter       int                `bson:"escalate_after,omitempty"`
+	NotificationLimit   int                `bson:"notification_limit,omitempty"`
+	OutageNotifications int                `bson:"outage_notifications,omitempty"`
+	CreatedAt           time.Time          `bson:"created_at"`
+	UpdatedAt           time.Time          `bson:"updated_at"`
 }
 
 func toDomainModel(mm *mongoModel) *Model {
 	return &Model{
-		ID:             mm.ID.Hex(),
-		MonitorID:      mm.MonitorID.Hex(),
-		NotificationID: mm.NotificationID.Hex(),
-		CreatedAt:      mm.CreatedAt,
-		UpdatedAt:      mm.UpdatedAt,
+		ID:                  mm.ID.Hex(),
+		MonitorID:           mm.MonitorID.Hex(),
+		NotificationID:      mm.NotificationID.Hex(),
+		MinCriticality:      mm.MinCriticality,
+		EventTypes:          shared.NotificationEventTypes(mm.EventMask),
+		DigestOnly:          mm.DigestOnly,
+		EscalateAfter:       mm.EscalateAfter,
+		NotificationLimit:   mm.NotificationLimit,
+		OutageNotifications: mm.OutageNotifications,
+		CreatedAt:           mm.CreatedAt,
+		UpdatedAt:           mm.UpdatedAt,
 	}
 }
 
@@ -68,11 +81,17 @@ func (r *RepositoryImpl) Create(ctx context.Context, model *Model) (*Model, erro
 	}
 
 	mm := &mongoModel{
-		ID:             primitive.NewObjectID(),
-		MonitorID:      monitorObjectID,
-		NotificationID: notificationObjectID,
-		CreatedAt:      time.Now().UTC(),
-		UpdatedAt:      time.Now().UTC(),
+		ID:                  primitive.NewObjectID(),
+		MonitorID:           monitorObjectID,
+		NotificationID:      notificationObjectID,
+		MinCriticality:      model.MinCriticality,
+		EventMask:           shared.NotificationEventMask(model.EventTypes),
+		DigestOnly:          model.DigestOnly,
+		EscalateAfter:       model.EscalateAfter,
+		NotificationLimit:   model.NotificationLimit,
+		OutageNotifications: model.OutageNotifications,
+		CreatedAt:           time.Now().UTC(),
+		UpdatedAt:           time.Now().UTC(),
 	}
 
 	_, err = r.collection.InsertOne(ctx, mm)
@@ -164,3 +183,69 @@ func (r *RepositoryImpl) DeleteByNotificationID(ctx context.Context, notificatio
 	_, err = r.collection.DeleteMany(ctx, filter)
 	return err
 }
+
+func (r *RepositoryImpl) UpdateMinCriticality(ctx context.Context, id string, minCriticality string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+	filter := bson.M{"_id": objectID}
+	update := bson.M{"$set": bson.M{"min_criticality": minCriticality, "updated_at": time.Now().UTC()}}
+	_, err = r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+func (r *RepositoryImpl) UpdateEventTypes(ctx context.Context, id string, eventTypes []string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+	filter := bson.M{"_id": objectID}
+	update := bson.M{"$set": bson.M{"event_mask": shared.NotificationEventMask(eventTypes), "updated_at": time.Now().UTC()}}
+	_, err = r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+func (r *RepositoryImpl) UpdateDigestOnly(ctx context.Context, id string, digestOnly bool) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+	filter := bson.M{"_id": objectID}
+	update := bson.M{"$set": bson.M{"digest_only": digestOnly, "updated_at": time.Now().UTC()}}
+	_, err = r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+func (r *RepositoryImpl) UpdateEscalateAfter(ctx context.Context, id string, escalateAfter int) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+	filter := bson.M{"_id": objectID}
+	update := bson.M{"$set": bson.M{"escalate_after": escalateAfter, "updated_at": time.Now().UTC()}}
+	_, err = r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+func (r *RepositoryImpl) UpdateNotificationLimit(ctx context.Context, id string, notificationLimit int) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+	filter := bson.M{"_id": objectID}
+	update := bson.M{"$set": bson.M{"notification_limit": notificationLimit, "updated_at": time.Now().UTC()}}
+	_, err = r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+func (r *RepositoryImpl) UpdateOutageNotifications(ctx context.Context, id string, outageNotifications int) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+	filter := bson.M{"_id": objectID}
+	update := bson.M{"$set": bson.M{"outage_notifications": outageNotifications, "updated_at": time.Now().UTC()}}
+	_, err = r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
@@ -11,4 +11,10 @@ type Repository interface {
 	Delete(ctx context.Context, id string) error
 	DeleteByMonitorID(ctx context.Context, monitorID string) error
 	DeleteByNotificationID(ctx context.Context, notificationID string) error
+	UpdateMinCriticality(ctx context.Context, id string, minCriticality string) error
+	UpdateEventTypes(ctx context.Context, id string, eventTypes []string) error
+	UpdateDigestOnly(ctx context.Context, id string, digestOnly bool) error
+	UpdateEscalateAfter(ctx context.Context, id string, escalateAfter int) error
+	UpdateNotificationLimit(ctx context.Context, id string, notificationLimit int) error
+	UpdateOutageNotifications(ctx context.Context, id string, outageNotifications int) error
 }
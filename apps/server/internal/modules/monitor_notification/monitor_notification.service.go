@@ -7,12 +7,18 @@ import (
 )
 
 type Service interface {
-	Create(ctx context.Context, monitorID string, notificationID string) (*Model, error)
+	Create(ctx context.Context, monitorID string, notificationID string, settings Settings) (*Model, error)
 	FindByID(ctx context.Context, id string) (*Model, error)
 	Delete(ctx context.Context, id string) error
 	FindByMonitorID(ctx context.Context, monitorID string) ([]*Model, error)
 	DeleteByMonitorID(ctx context.Context, monitorID string) error
 	DeleteByNotificationID(ctx context.Context, notificationID string) error
+	SetMinCriticality(ctx context.Context, id string, minCriticality string) error
+	SetEventTypes(ctx context.Context, id string, eventTypes []string) error
+	SetDigestOnly(ctx context.Context, id string, digestOnly bool) error
+	SetEscalateAfter(ctx context.Context, id string, escalateAfter int) error
+	SetNotificationLimit(ctx context.Context, id string, notificationLimit int) error
+	SetOutageNotifications(ctx context.Context, id string, outageNotifications int) error
 }
 
 type ServiceImpl struct {
@@ -30,10 +36,15 @@ func NewService(
 	}
 }
 
-func (mr *ServiceImpl) Create(ctx context.Context, monitorID string, notificationID string) (*Model, error) {
+func (mr *ServiceImpl) Create(ctx context.Context, monitorID string, notificationID string, settings Settings) (*Model, error) {
 	createModel := &Model{
-		MonitorID:      monitorID,
-		NotificationID: notificationID,
+		MonitorID:         monitorID,
+		NotificationID:    notificationID,
+		MinCriticality:    settings.MinCriticality,
+		EventTypes:        settings.EventTypes,
+		DigestOnly:        settings.DigestOnly,
+		EscalateAfter:     settings.EscalateAfter,
+		NotificationLimit: settings.NotificationLimit,
 	}
 
 	return mr.repository.Create(ctx, createModel)
@@ -58,3 +69,27 @@ func (mr *ServiceImpl) DeleteByMonitorID(ctx context.Context, monitorID string)
 func (mr *ServiceImpl) DeleteByNotificationID(ctx context.Context, notificationID string) error {
 	return mr.repository.DeleteByNotificationID(ctx, notificationID)
 }
+
+func (mr *ServiceImpl) SetMinCriticality(ctx context.Context, id string, minCriticality string) error {
+	return mr.repository.UpdateMinCriticality(ctx, id, minCriticality)
+}
+
+func (mr *ServiceImpl) SetEventTypes(ctx context.Context, id string, eventTypes []string) error {
+	return mr.repository.UpdateEventTypes(ctx, id, eventTypes)
+}
+
+func (mr *ServiceImpl) SetDigestOnly(ctx context.Context, id string, digestOnly bool) error {
+	return mr.repository.UpdateDigestOnly(ctx, id, digestOnly)
+}
+
+func (mr *ServiceImpl) SetEscalateAfter(ctx context.Context, id string, escalateAfter int) error {
+	return mr.repository.UpdateEscalateAfter(ctx, id, escalateAfter)
+}
+
+func (mr *ServiceImpl) SetNotificationLimit(ctx context.Context, id string, notificationLimit int) error {
+	return mr.repository.UpdateNotificationLimit(ctx, id, notificationLimit)
+}
+
+func (mr *ServiceImpl) SetOutageNotifications(ctx context.Context, id string, outageNotifications int) error {
+	return mr.repository.UpdateOutageNotifications(ctx, id, outageNotifications)
+}
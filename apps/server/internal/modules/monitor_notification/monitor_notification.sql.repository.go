@@ -2,6 +2,7 @@ package monitor_notification
 
 import (
 	"context"
+	"peekaping/internal/modules/shared"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,17 +15,29 @@ type sqlModel struct {
 	ID                    string    `bun:"id,pk"`
 	MonitorID             string    `bun:"monitor_id,notnull"`
 	NotificationChannelID string    `bun:"notification_channel_id,notnull"`
+	MinCriticality        string    `bun:"min_criticality,notnull,default:''"`
+	EventMask             int       `bun:"event_mask,notnull,default:0"`
+	DigestOnly            bool      `bun:"digest_only,notnull,default:false"`
+	EscalateAfter         int       `bun:"escalate_after,notnull,default:0"`
+	NotificationLimit     int       `bun:"notification_limit,notnull,default:0"`
+	OutageNotifications   int       `bun:"outage_notifications,notnull,default:0"`
 	CreatedAt             time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
 	UpdatedAt             time.Time `bun:"updated_at,nullzero,notnull,default:current_timestamp"`
 }
 
 func toDomainModelFromSQL(sm *sqlModel) *Model {
 	return &Model{
-		ID:             sm.ID,
-		MonitorID:      sm.MonitorID,
-		NotificationID: sm.NotificationChannelID,
-		CreatedAt:      sm.CreatedAt,
-		UpdatedAt:      sm.UpdatedAt,
+		ID:                  sm.ID,
+		MonitorID:           sm.MonitorID,
+		NotificationID:      sm.NotificationChannelID,
+		MinCriticality:      sm.MinCriticality,
+		EventTypes:          shared.NotificationEventTypes(sm.EventMask),
+		DigestOnly:          sm.DigestOnly,
+		EscalateAfter:       sm.EscalateAfter,
+		NotificationLimit:   sm.NotificationLimit,
+		OutageNotifications: sm.OutageNotifications,
+		CreatedAt:           sm.CreatedAt,
+		UpdatedAt:           sm.UpdatedAt,
 	}
 }
 
@@ -33,6 +46,12 @@ func toSQLModel(m *Model) *sqlModel {
 		ID:                    m.ID,
 		MonitorID:             m.MonitorID,
 		NotificationChannelID: m.NotificationID,
+		MinCriticality:        m.MinCriticality,
+		EventMask:             shared.NotificationEventMask(m.EventTypes),
+		DigestOnly:            m.DigestOnly,
+		EscalateAfter:         m.EscalateAfter,
+		NotificationLimit:     m.NotificationLimit,
+		OutageNotifications:   m.OutageNotifications,
 		CreatedAt:             m.CreatedAt,
 		UpdatedAt:             m.UpdatedAt,
 	}
@@ -104,3 +123,63 @@ func (r *SQLRepositoryImpl) DeleteByNotificationID(ctx context.Context, notifica
 	_, err := r.db.NewDelete().Model((*sqlModel)(nil)).Where("notification_channel_id = ?", notificationID).Exec(ctx)
 	return err
 }
+
+func (r *SQLRepositoryImpl) UpdateMinCriticality(ctx context.Context, id string, minCriticality string) error {
+	_, err := r.db.NewUpdate().
+		Model((*sqlModel)(nil)).
+		Set("min_criticality = ?", minCriticality).
+		Set("updated_at = ?", time.Now()).
+		Where("id = ?", id).
+		Exec(ctx)
+	return err
+}
+
+func (r *SQLRepositoryImpl) UpdateEventTypes(ctx context.Context, id string, eventTypes []string) error {
+	_, err := r.db.NewUpdate().
+		Model((*sqlModel)(nil)).
+		Set("event_mask = ?", shared.NotificationEventMask(eventTypes)).
+		Set("updated_at = ?", time.Now()).
+		Where("id = ?", id).
+		Exec(ctx)
+	return err
+}
+
+func (r *SQLRepositoryImpl) UpdateDigestOnly(ctx context.Context, id string, digestOnly bool) error {
+	_, err := r.db.NewUpdate().
+		Model((*sqlModel)(nil)).
+		Set("digest_only = ?", digestOnly).
+		Set("updated_at = ?", time.Now()).
+		Where("id = ?", id).
+		Exec(ctx)
+	return err
+}
+
+func (r *SQLRepositoryImpl) UpdateEscalateAfter(ctx context.Context, id string, escalateAfter int) error {
+	_, err := r.db.NewUpdate().
+		Model((*sqlModel)(nil)).
+		Set("escalate_after = ?", escalateAfter).
+		Set("updated_at = ?", time.Now()).
+		Where("id = ?", id).
+		Exec(ctx)
+	return err
+}
+
+func (r *SQLRepositoryImpl) UpdateNotificationLimit(ctx context.Context, id string, notificationLimit int) error {
+	_, err := r.db.NewUpdate().
+		Model((*sqlModel)(nil)).
+		Set("notification_limit = ?", notificationLimit).
+		Set("updated_at = ?", time.Now()).
+		Where("id = ?", id).
+		Exec(ctx)
+	return err
+}
+
+func (r *SQLRepositoryImpl) UpdateOutageNotifications(ctx context.Context, id string, outageNotifications int) error {
+	_, err := r.db.NewUpdate().
+		Model((*sqlModel)(nil)).
+		Set("outage_notifications = ?", outageNotifications).
+		Set("updated_at = ?", time.Now()).
+		Where("id = ?", id).
+		Exec(ctx)
+	return err
+}
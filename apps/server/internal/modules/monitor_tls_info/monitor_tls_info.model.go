@@ -6,11 +6,13 @@ import (
 )
 
 type Model struct {
-	ID        string    `json:"id"`
-	MonitorID string    `json:"monitor_id"`
-	InfoJSON  string    `json:"info_json"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        string `json:"id"`
+	MonitorID string `json:"monitor_id"`
+	InfoJSON  string `json:"info_json"`
+	// PreviousIssuer is the issuer of the certificate before the last issuer change
+	PreviousIssuer string    `json:"previous_issuer"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
 }
 
 type CreateDto struct {
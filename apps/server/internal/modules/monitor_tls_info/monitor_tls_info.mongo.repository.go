@@ -12,11 +12,12 @@ import (
 )
 
 type mongoModel struct {
-	ID        primitive.ObjectID `bson:"_id"`
-	MonitorID string             `bson:"monitor_id"`
-	InfoJSON  string             `bson:"info_json"`
-	CreatedAt time.Time          `bson:"created_at"`
-	UpdatedAt time.Time          `bson:"updated_at"`
+	ID             primitive.ObjectID `bson:"_id"`
+	MonitorID      string             `bson:"monitor_id"`
+	InfoJSON       string             `bson:"info_json"`
+	PreviousIssuer string             `bson:"previous_issuer,omitempty"`
+	CreatedAt      time.Time          `bson:"created_at"`
+	UpdatedAt      time.Time          `bson:"updated_at"`
 }
 
 func toDomainModelFromMongo(mm *mongoModel) *Model {
@@ -24,11 +25,12 @@ func toDomainModelFromMongo(mm *mongoModel) *Model {
 		return nil
 	}
 	return &Model{
-		ID:        mm.ID.Hex(),
-		MonitorID: mm.MonitorID,
-		InfoJSON:  mm.InfoJSON,
-		CreatedAt: mm.CreatedAt,
-		UpdatedAt: mm.UpdatedAt,
+		ID:             mm.ID.Hex(),
+		MonitorID:      mm.MonitorID,
+		InfoJSON:       mm.InfoJSON,
+		PreviousIssuer: mm.PreviousIssuer,
+		CreatedAt:      mm.CreatedAt,
+		UpdatedAt:      mm.UpdatedAt,
 	}
 }
 
@@ -130,6 +132,16 @@ func (r *MongoRepositoryImpl) Upsert(ctx context.Context, monitorID string, info
 	return r.GetByMonitorID(ctx, monitorID)
 }
 
+func (r *MongoRepositoryImpl) SetPreviousIssuer(ctx context.Context, monitorID string, issuer string) error {
+	filter := bson.M{"monitor_id": monitorID}
+	update := bson.M{
+		"$set": bson.M{"previous_issuer": issuer},
+	}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
 func (r *MongoRepositoryImpl) Delete(ctx context.Context, monitorID string) error {
 	filter := bson.M{"monitor_id": monitorID}
 	_, err := r.collection.DeleteOne(ctx, filter)
@@ -17,6 +17,9 @@ type Repository interface {
 	// Upsert creates or updates TLS info for a monitor
 	Upsert(ctx context.Context, monitorID string, infoJSON string) (*Model, error)
 
+	// SetPreviousIssuer records the issuer a monitor's certificate had before it changed
+	SetPreviousIssuer(ctx context.Context, monitorID string, issuer string) error
+
 	// Delete removes TLS info for a monitor
 	Delete(ctx context.Context, monitorID string) error
 
@@ -21,6 +21,9 @@ type Service interface {
 	// GetTLSInfoObject retrieves TLS info and unmarshals it into the provided object
 	GetTLSInfoObject(ctx context.Context, monitorID string, obj interface{}) error
 
+	// StorePreviousIssuer records the issuer before a certificate issuer change
+	StorePreviousIssuer(ctx context.Context, monitorID string, issuer string) error
+
 	// DeleteTLSInfo removes TLS info for a monitor
 	DeleteTLSInfo(ctx context.Context, monitorID string) error
 
@@ -107,6 +110,15 @@ func (s *ServiceImpl) GetTLSInfoObject(ctx context.Context, monitorID string, ob
 	return nil
 }
 
+func (s *ServiceImpl) StorePreviousIssuer(ctx context.Context, monitorID string, issuer string) error {
+	s.logger.Debugf("Storing previous certificate issuer for monitor: %s", monitorID)
+
+	if err := s.repository.SetPreviousIssuer(ctx, monitorID, issuer); err != nil {
+		return fmt.Errorf("failed to store previous issuer: %w", err)
+	}
+	return nil
+}
+
 func (s *ServiceImpl) DeleteTLSInfo(ctx context.Context, monitorID string) error {
 	s.logger.Debugf("Deleting TLS info for monitor: %s", monitorID)
 
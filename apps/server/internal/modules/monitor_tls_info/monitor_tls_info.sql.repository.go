@@ -11,20 +11,22 @@ import (
 type sqlModel struct {
 	bun.BaseModel `bun:"table:monitor_tls_info,alias:mti"`
 
-	ID        string    `bun:"id,pk"`
-	MonitorID string    `bun:"monitor_id,notnull,unique"`
-	InfoJSON  string    `bun:"info_json,notnull"`
-	CreatedAt time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
-	UpdatedAt time.Time `bun:"updated_at,nullzero,notnull,default:current_timestamp"`
+	ID             string    `bun:"id,pk"`
+	MonitorID      string    `bun:"monitor_id,notnull,unique"`
+	InfoJSON       string    `bun:"info_json,notnull"`
+	PreviousIssuer string    `bun:"previous_issuer,notnull,default:''"`
+	CreatedAt      time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+	UpdatedAt      time.Time `bun:"updated_at,nullzero,notnull,default:current_timestamp"`
 }
 
 func toDomainModelFromSQL(sm *sqlModel) *Model {
 	return &Model{
-		ID:        sm.ID,
-		MonitorID: sm.MonitorID,
-		InfoJSON:  sm.InfoJSON,
-		CreatedAt: sm.CreatedAt,
-		UpdatedAt: sm.UpdatedAt,
+		ID:             sm.ID,
+		MonitorID:      sm.MonitorID,
+		InfoJSON:       sm.InfoJSON,
+		PreviousIssuer: sm.PreviousIssuer,
+		CreatedAt:      sm.CreatedAt,
+		UpdatedAt:      sm.UpdatedAt,
 	}
 }
 
@@ -113,6 +115,16 @@ func (r *SQLRepositoryImpl) Upsert(ctx context.Context, monitorID string, infoJS
 	return r.GetByMonitorID(ctx, monitorID)
 }
 
+func (r *SQLRepositoryImpl) SetPreviousIssuer(ctx context.Context, monitorID string, issuer string) error {
+	_, err := r.db.NewUpdate().
+		Model((*sqlModel)(nil)).
+		Set("previous_issuer = ?", issuer).
+		Where("monitor_id = ?", monitorID).
+		Exec(ctx)
+
+	return err
+}
+
 func (r *SQLRepositoryImpl) Delete(ctx context.Context, monitorID string) error {
 	_, err := r.db.NewDelete().
 		Model((*sqlModel)(nil)).
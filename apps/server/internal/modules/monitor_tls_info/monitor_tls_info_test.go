@@ -35,6 +35,11 @@ func (m *MockRepository) Upsert(ctx context.Context, monitorID string, infoJSON
 	return args.Get(0).(*Model), args.Error(1)
 }
 
+func (m *MockRepository) SetPreviousIssuer(ctx context.Context, monitorID string, issuer string) error {
+	args := m.Called(ctx, monitorID, issuer)
+	return args.Error(0)
+}
+
 func (m *MockRepository) Delete(ctx context.Context, monitorID string) error {
 	args := m.Called(ctx, monitorID)
 	return args.Error(0)
@@ -110,6 +115,16 @@ func TestMonitorTLSInfoService(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("StorePreviousIssuer", func(t *testing.T) {
+		mockRepo.ExpectedCalls = nil // Reset expectations
+		mockRepo.On("SetPreviousIssuer", ctx, monitorID, "CN=R3,O=Let's Encrypt,C=US").Return(nil)
+
+		err := service.StorePreviousIssuer(ctx, monitorID, "CN=R3,O=Let's Encrypt,C=US")
+		assert.NoError(t, err)
+
+		mockRepo.AssertExpectations(t)
+	})
+
 	t.Run("DeleteTLSInfo", func(t *testing.T) {
 		mockRepo.ExpectedCalls = nil // Reset expectations
 		mockRepo.On("Delete", ctx, monitorID).Return(nil)
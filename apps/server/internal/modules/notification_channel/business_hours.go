@@ -0,0 +1,238 @@
+package notification_channel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"peekaping/internal/modules/heartbeat"
+	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/queue"
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+// What happens to notifications outside a channel's business hours
+const (
+	OffHoursDefer = "defer"
+	OffHoursDrop  = "drop"
+)
+
+// BusinessHours limits when a channel is notified. Monitors keep running checks and
+// recording heartbeats around the clock, only the delivery of their notifications outside
+// the hours is deferred to the next opening or dropped.
+type BusinessHours struct {
+	// Days are the weekdays the hours apply to, 0 is Sunday. Empty means Monday to Friday.
+	Days []int `json:"days"`
+	// Start and End are the opening hours as HH:MM, End is exclusive
+	Start string `json:"start"`
+	End   string `json:"end"`
+	// Timezone is the IANA timezone of the hours, the server timezone when empty
+	Timezone string `json:"timezone"`
+	// OffHours is OffHoursDefer (default) or OffHoursDrop
+	OffHours string `json:"off_hours"`
+}
+
+var defaultBusinessDays = []int{1, 2, 3, 4, 5}
+
+// Validate checks that the hours can be evaluated
+func (b *BusinessHours) Validate() error {
+	start, err := parseClock(b.Start)
+	if err != nil {
+		return fmt.Errorf("business_hours.start: %w", err)
+	}
+	end, err := parseClock(b.End)
+	if err != nil {
+		return fmt.Errorf("business_hours.end: %w", err)
+	}
+	if end <= start {
+		return fmt.Errorf("business_hours.end must be after business_hours.start")
+	}
+	for _, day := range b.Days {
+		if day < 0 || day > 6 {
+			return fmt.Errorf("business_hours.days must be between 0 (Sunday) and 6 (Saturday), got %d", day)
+		}
+	}
+	if b.Timezone != "" {
+		if _, err := time.LoadLocation(b.Timezone); err != nil {
+			return fmt.Errorf("business_hours.timezone: %w", err)
+		}
+	}
+	if b.OffHours != "" && b.OffHours != OffHoursDefer && b.OffHours != OffHoursDrop {
+		return fmt.Errorf("business_hours.off_hours must be %q or %q", OffHoursDefer, OffHoursDrop)
+	}
+	return nil
+}
+
+// location returns the timezone of the hours, fallback when none is set or it is unknown
+func (b *BusinessHours) location(fallback *time.Location) *time.Location {
+	if b.Timezone != "" {
+		if loc, err := time.LoadLocation(b.Timezone); err == nil {
+			return loc
+		}
+	}
+	if fallback == nil {
+		return time.UTC
+	}
+	return fallback
+}
+
+func (b *BusinessHours) days() []int {
+	if len(b.Days) == 0 {
+		return defaultBusinessDays
+	}
+	return b.Days
+}
+
+func (b *BusinessHours) isBusinessDay(weekday time.Weekday) bool {
+	for _, day := range b.days() {
+		if time.Weekday(day) == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOpen reports whether t falls within the hours
+func (b *BusinessHours) IsOpen(t time.Time, fallback *time.Location) bool {
+	start, errStart := parseClock(b.Start)
+	end, errEnd := parseClock(b.End)
+	if errStart != nil || errEnd != nil {
+		// Hours that can't be evaluated don't hold anything back
+		return true
+	}
+
+	local := t.In(b.location(fallback))
+	minute := local.Hour()*60 + local.Minute()
+	return b.isBusinessDay(local.Weekday()) && minute >= start && minute < end
+}
+
+// NextOpen returns the next time at or after t the hours open
+func (b *BusinessHours) NextOpen(t time.Time, fallback *time.Location) time.Time {
+	if b.IsOpen(t, fallback) {
+		return t
+	}
+	start, _ := parseClock(b.Start)
+
+	local := t.In(b.location(fallback))
+	for i := 0; i <= 7; i++ {
+		day := local.AddDate(0, 0, i)
+		opening := time.Date(day.Year(), day.Month(), day.Day(), start/60, start%60, 0, 0, day.Location())
+		if b.isBusinessDay(opening.Weekday()) && opening.After(t) {
+			return opening
+		}
+	}
+	return t
+}
+
+// parseClock parses HH:MM into minutes since midnight
+func parseClock(value string) (int, error) {
+	clock, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	return clock.Hour()*60 + clock.Minute(), nil
+}
+
+// deferredNotification is the payload of a notification held back until its channel's
+// business hours
+type deferredNotification struct {
+	ChannelID  string           `json:"channel_id"`
+	Message    string           `json:"message"`
+	Monitor    *monitor.Model   `json:"monitor"`
+	Heartbeat  *heartbeat.Model `json:"heartbeat,omitempty"`
+	OccurredAt time.Time        `json:"occurred_at"`
+}
+
+// holdOffHours defers or drops a notification when the channel is outside its business
+// hours and reports whether it was held back. key identifies the kind of event the
+// notification is about, a newer notification of the same monitor and kind replaces a
+// deferred one. Deferred notifications are tasks of the notification queue processed
+// when the hours open.
+func (l *NotificationEventListener) holdOffHours(notificationChannel *Model, options ChannelOptions, key string, message string, monitorModel *monitor.Model, hb *heartbeat.Model) bool {
+	hours := options.BusinessHours
+	if hours == nil {
+		return false
+	}
+
+	now := l.clock()
+	if hours.IsOpen(now, l.location) {
+		return false
+	}
+
+	if hours.OffHours == OffHoursDrop {
+		l.logger.Infof("Dropping notification to %s for monitor %s: outside business hours", notificationChannel.Name, monitorModel.ID)
+		return true
+	}
+
+	if l.queueService == nil {
+		l.logger.Warnf("Can't defer notification to %s for monitor %s without a queue, notifying right away", notificationChannel.Name, monitorModel.ID)
+		return false
+	}
+
+	ctx := context.Background()
+	deferred := &deferredNotification{
+		ChannelID:  notificationChannel.ID,
+		Message:    message,
+		Monitor:    monitorModel,
+		Heartbeat:  hb,
+		OccurredAt: now,
+	}
+	openAt := hours.NextOpen(now, l.location)
+	taskID := "deferred:" + notificationChannel.ID + ":" + monitorModel.ID + ":" + key
+
+	// Replace the notification deferred for the same monitor and kind, if any
+	_ = l.queueService.DeleteTask(ctx, queue.NotificationQueue, taskID)
+	_, err := l.queueService.Enqueue(ctx, TaskTypeDeferredNotification, deferred, &queue.EnqueueOptions{
+		Queue:     queue.NotificationQueue,
+		MaxRetry:  3,
+		Timeout:   time.Minute,
+		ProcessAt: &openAt,
+		TaskID:    taskID,
+	})
+	if err != nil {
+		l.logger.Errorf("Failed to defer notification to %s for monitor %s, notifying right away: %v", notificationChannel.Name, monitorModel.ID, err)
+		return false
+	}
+
+	l.logger.Infof("Deferring notification to %s for monitor %s until %s: outside business hours", notificationChannel.Name, monitorModel.ID, openAt.Format(time.RFC3339))
+	return true
+}
+
+// ProcessDeferredTask sends a deferred notification once its channel's business hours
+// open. The channel is loaded again so changes made in the meantime are respected.
+func (l *NotificationEventListener) ProcessDeferredTask(ctx context.Context, task *asynq.Task) error {
+	var deferred deferredNotification
+	if err := json.Unmarshal(task.Payload(), &deferred); err != nil || deferred.Monitor == nil {
+		l.logger.Errorf("Failed to unmarshal deferred notification payload: %v", err)
+		return nil
+	}
+
+	notificationChannel, err := l.service.FindByID(ctx, deferred.ChannelID)
+	if err != nil || notificationChannel == nil || notificationChannel.Config == nil {
+		l.logger.Warnf("Dropping deferred notification for monitor %s: channel %s is gone, error: %v", deferred.Monitor.ID, deferred.ChannelID, err)
+		return nil
+	}
+
+	integration, ok := GetNotificationChannelProvider(notificationChannel.Type)
+	if !ok {
+		l.logger.Warnf("No integration registered for notification type: %s", notificationChannel.Type)
+		return nil
+	}
+
+	message := fmt.Sprintf("%s\n\nDeferred outside business hours, occurred at %s", deferred.Message, deferred.OccurredAt.Format(time.RFC3339))
+	if err := integration.Send(ctx, *notificationChannel.Config, message, deferred.Monitor, deferred.Heartbeat); err != nil {
+		l.logger.Errorf("Failed to send deferred notification: %s, error: %v", notificationChannel.Name, err)
+		return err
+	}
+
+	l.logger.Infof("Deferred notification sent to: %s for monitor: %s", notificationChannel.Name, deferred.Monitor.ID)
+	return nil
+}
+
+func (l *NotificationEventListener) clock() time.Time {
+	if l.now != nil {
+		return l.now()
+	}
+	return time.Now()
+}
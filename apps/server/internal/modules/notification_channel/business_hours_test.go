@@ -0,0 +1,260 @@
+package notification_channel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"peekaping/internal/modules/events"
+	"peekaping/internal/modules/heartbeat"
+	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/monitor_notification"
+	"peekaping/internal/modules/queue"
+	"peekaping/internal/modules/shared"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestBusinessHours_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		hours   BusinessHours
+		wantErr bool
+	}{
+		{"weekdays nine to five", BusinessHours{Start: "09:00", End: "17:00"}, false},
+		{"explicit days, timezone and policy", BusinessHours{Days: []int{0, 6}, Start: "10:00", End: "14:30", Timezone: "Europe/Berlin", OffHours: OffHoursDrop}, false},
+		{"invalid start", BusinessHours{Start: "9am", End: "17:00"}, true},
+		{"end before start", BusinessHours{Start: "17:00", End: "09:00"}, true},
+		{"invalid day", BusinessHours{Days: []int{7}, Start: "09:00", End: "17:00"}, true},
+		{"unknown timezone", BusinessHours{Start: "09:00", End: "17:00", Timezone: "Mars/Olympus"}, true},
+		{"unknown off-hours policy", BusinessHours{Start: "09:00", End: "17:00", OffHours: "queue"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.hours.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestBusinessHours_IsOpenAndNextOpen(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	hours := &BusinessHours{Start: "09:00", End: "17:00", Timezone: "Europe/Berlin"}
+
+	// 2025-10-06 is a Monday
+	tests := []struct {
+		name     string
+		at       time.Time
+		open     bool
+		nextOpen time.Time
+	}{
+		{"monday morning", time.Date(2025, 10, 6, 10, 0, 0, 0, berlin), true, time.Date(2025, 10, 6, 10, 0, 0, 0, berlin)},
+		{"before opening", time.Date(2025, 10, 6, 8, 59, 0, 0, berlin), false, time.Date(2025, 10, 6, 9, 0, 0, 0, berlin)},
+		{"closing time is off-hours", time.Date(2025, 10, 6, 17, 0, 0, 0, berlin), false, time.Date(2025, 10, 7, 9, 0, 0, 0, berlin)},
+		{"friday night waits for monday", time.Date(2025, 10, 10, 22, 0, 0, 0, berlin), false, time.Date(2025, 10, 13, 9, 0, 0, 0, berlin)},
+		{"saturday", time.Date(2025, 10, 11, 12, 0, 0, 0, berlin), false, time.Date(2025, 10, 13, 9, 0, 0, 0, berlin)},
+		{"other timezones are converted", time.Date(2025, 10, 6, 7, 30, 0, 0, time.UTC), true, time.Date(2025, 10, 6, 7, 30, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.open, hours.IsOpen(tt.at, time.UTC))
+			assert.True(t, tt.nextOpen.Equal(hours.NextOpen(tt.at, time.UTC)), "next open %s", hours.NextOpen(tt.at, time.UTC))
+		})
+	}
+
+	t.Run("falls back to the server timezone", func(t *testing.T) {
+		local := &BusinessHours{Start: "09:00", End: "17:00"}
+		at := time.Date(2025, 10, 6, 8, 0, 0, 0, time.UTC) // 10:00 in Berlin
+		assert.False(t, local.IsOpen(at, time.UTC))
+		assert.True(t, local.IsOpen(at, berlin))
+	})
+}
+
+// recordingQueue keeps the tasks enqueued on the notification queue, replacing those
+// with the same task ID like asynq rejects duplicates
+type recordingQueue struct {
+	queue.Service
+	mu    sync.Mutex
+	tasks []*recordedTask
+}
+
+type recordedTask struct {
+	taskType string
+	payload  []byte
+	opts     *queue.EnqueueOptions
+}
+
+func (q *recordingQueue) Enqueue(ctx context.Context, taskType string, payload interface{}, opts *queue.EnqueueOptions) (*queue.TaskInfo, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, task := range q.tasks {
+		if opts.TaskID != "" && task.opts.TaskID == opts.TaskID {
+			return nil, fmt.Errorf("task ID conflicts with another task")
+		}
+	}
+	q.tasks = append(q.tasks, &recordedTask{taskType: taskType, payload: data, opts: opts})
+	return &queue.TaskInfo{ID: opts.TaskID, Queue: opts.Queue, Type: taskType}, nil
+}
+
+func (q *recordingQueue) DeleteTask(ctx context.Context, queueName, taskID string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, task := range q.tasks {
+		if task.opts.Queue == queueName && task.opts.TaskID == taskID {
+			q.tasks = append(q.tasks[:i], q.tasks[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("task not found")
+}
+
+func (q *recordingQueue) enqueued() []*recordedTask {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]*recordedTask(nil), q.tasks...)
+}
+
+func (t *recordedTask) asynqTask() *asynq.Task {
+	return asynq.NewTask(t.taskType, t.payload)
+}
+
+func TestHandleNotifyEvent_BusinessHours(t *testing.T) {
+	occurredAt := time.Date(2025, 10, 11, 22, 59, 0, 0, time.UTC)
+	hb := &heartbeat.Model{ID: "hb-1", MonitorID: "mon-1", Status: shared.MonitorStatusDown, Msg: "connection refused", Important: true, Time: occurredAt}
+	recovery := &heartbeat.Model{ID: "hb-2", MonitorID: "mon-1", Status: shared.MonitorStatusUp, Msg: "200 OK", Important: true, Time: occurredAt}
+	mon := &monitor.Model{ID: "mon-1", Name: "API"}
+	isMonitor := mock.MatchedBy(func(m *monitor.Model) bool { return m.ID == mon.ID })
+	isHeartbeat := func(id string) interface{} {
+		return mock.MatchedBy(func(h *heartbeat.Model) bool { return h != nil && h.ID == id })
+	}
+
+	t.Cleanup(func() { delete(NotificationChannelProviderRegistry, "mock_business_hours") })
+
+	// 2025-10-06 is a Monday
+	inHours := time.Date(2025, 10, 6, 10, 0, 0, 0, time.UTC)
+	saturdayNight := time.Date(2025, 10, 11, 23, 0, 0, 0, time.UTC)
+	mondayOpening := time.Date(2025, 10, 13, 9, 0, 0, 0, time.UTC)
+
+	setup := func(channelConfig string, now time.Time) (*NotificationEventListener, *MockProvider, *recordingQueue) {
+		provider := new(MockProvider)
+		RegisterNotificationChannelProvider("mock_business_hours", provider)
+		repo := new(MockRepository)
+		monitorSvc := new(MockMonitorService)
+		monitorNotificationSvc := new(MockMonitorNotificationService)
+		heartbeatSvc := new(MockHeartbeatService)
+
+		channel := &Model{ID: "chan-1", Name: "Pager", Type: "mock_business_hours", Active: true, Config: &channelConfig}
+		monitorNotificationSvc.On("FindByMonitorID", mock.Anything, "mon-1").Return([]*monitor_notification.Model{{MonitorID: "mon-1", NotificationID: "chan-1"}}, nil)
+		repo.On("FindByID", mock.Anything, "chan-1").Return(channel, nil)
+		monitorSvc.On("FindByID", mock.Anything, "mon-1").Return(mon, nil)
+		provider.On("Validate", channelConfig).Return(nil)
+		heartbeatSvc.On("FindByMonitorIDPaginated", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return([]*heartbeat.Model{}, nil)
+
+		tasks := &recordingQueue{}
+		l := &NotificationEventListener{
+			service:                    createTestService(repo, monitorNotificationSvc),
+			monitorSvc:                 monitorSvc,
+			heartbeatService:           heartbeatSvc,
+			monitorNotificationService: monitorNotificationSvc,
+			queueService:               tasks,
+			location:                   time.UTC,
+			logger:                     zap.NewNop().Sugar(),
+			now:                        func() time.Time { return now },
+		}
+		return l, provider, tasks
+	}
+
+	t.Run("in hours is delivered immediately", func(t *testing.T) {
+		config := `{"business_hours":{"start":"09:00","end":"17:00"}}`
+		l, provider, tasks := setup(config, inHours)
+		provider.On("Send", mock.Anything, config, "connection refused", mon, mock.Anything).Return(nil).Once()
+
+		l.handleNotifyEvent(events.Event{Type: events.ImportantHeartbeat, Payload: hb})
+
+		provider.AssertExpectations(t)
+		assert.Empty(t, tasks.enqueued())
+	})
+
+	t.Run("off hours is deferred to the next opening", func(t *testing.T) {
+		config := `{"business_hours":{"start":"09:00","end":"17:00","off_hours":"defer"}}`
+		l, provider, tasks := setup(config, saturdayNight)
+
+		l.handleNotifyEvent(events.Event{Type: events.ImportantHeartbeat, Payload: hb})
+
+		provider.AssertNotCalled(t, "Send", mock.Anything, config, mock.Anything, mock.Anything, mock.Anything)
+		enqueued := tasks.enqueued()
+		require.Len(t, enqueued, 1)
+		assert.Equal(t, TaskTypeDeferredNotification, enqueued[0].taskType)
+		assert.Equal(t, queue.NotificationQueue, enqueued[0].opts.Queue)
+		require.NotNil(t, enqueued[0].opts.ProcessAt)
+		assert.True(t, mondayOpening.Equal(*enqueued[0].opts.ProcessAt))
+
+		provider.On("Send", mock.Anything, config, mock.MatchedBy(func(message string) bool {
+			return strings.HasPrefix(message, "connection refused") &&
+				strings.HasSuffix(message, "Deferred outside business hours, occurred at 2025-10-11T23:00:00Z")
+		}), isMonitor, isHeartbeat("hb-1")).Return(nil).Once()
+
+		require.NoError(t, l.ProcessDeferredTask(context.Background(), enqueued[0].asynqTask()))
+
+		provider.AssertExpectations(t)
+	})
+
+	t.Run("a newer off-hours notification of the same event replaces the deferred one", func(t *testing.T) {
+		config := `{"business_hours":{"start":"09:00","end":"17:00"}}`
+		l, _, tasks := setup(config, saturdayNight)
+
+		l.handleNotifyEvent(events.Event{Type: events.ImportantHeartbeat, Payload: hb})
+		l.handleNotifyEvent(events.Event{Type: events.ImportantHeartbeat, Payload: hb})
+
+		assert.Len(t, tasks.enqueued(), 1)
+	})
+
+	t.Run("a recovery is deferred next to the outage", func(t *testing.T) {
+		config := `{"business_hours":{"start":"09:00","end":"17:00"}}`
+		l, provider, tasks := setup(config, saturdayNight)
+
+		l.handleNotifyEvent(events.Event{Type: events.ImportantHeartbeat, Payload: hb})
+		l.handleNotifyEvent(events.Event{Type: events.ImportantHeartbeat, Payload: recovery})
+
+		enqueued := tasks.enqueued()
+		require.Len(t, enqueued, 2)
+
+		provider.On("Send", mock.Anything, config, mock.Anything, isMonitor, isHeartbeat("hb-1")).Return(nil).Once()
+		provider.On("Send", mock.Anything, config, mock.Anything, isMonitor, isHeartbeat("hb-2")).Return(nil).Once()
+		for _, task := range enqueued {
+			require.NoError(t, l.ProcessDeferredTask(context.Background(), task.asynqTask()))
+		}
+		provider.AssertExpectations(t)
+	})
+
+	t.Run("off hours is dropped when configured", func(t *testing.T) {
+		config := `{"business_hours":{"start":"09:00","end":"17:00","off_hours":"drop"}}`
+		l, provider, tasks := setup(config, saturdayNight)
+
+		l.handleNotifyEvent(events.Event{Type: events.ImportantHeartbeat, Payload: hb})
+
+		provider.AssertNotCalled(t, "Send", mock.Anything, config, mock.Anything, mock.Anything, mock.Anything)
+		assert.Empty(t, tasks.enqueued())
+	})
+}
@@ -0,0 +1,81 @@
+package notification_channel
+
+import (
+	"context"
+	"fmt"
+	"peekaping/internal/modules/queue"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"go.uber.org/zap"
+)
+
+// Task types of the notifications delivered later through the notification queue
+const (
+	TaskTypeDeferredNotification   = "notification:deferred"
+	TaskTypeFlushNotificationGroup = "notification:flush_group"
+	TaskTypeSendDigests            = "notification:send_digests"
+)
+
+// digestsCronSpec is how often the scheduler looks for due digests
+const digestsCronSpec = "* * * * *"
+
+// NotificationWorker delivers the notifications held back by business hours, grouping
+// windows or digests from the notification queue. They are kept in Redis, so they survive
+// restarts, and each one is delivered once whatever the number of API instances.
+type NotificationWorker struct {
+	server    *asynq.Server
+	scheduler *asynq.Scheduler
+	mux       *asynq.ServeMux
+	listener  *NotificationEventListener
+	logger    *zap.SugaredLogger
+}
+
+// NewNotificationWorker creates a new notification worker
+func NewNotificationWorker(
+	server *asynq.Server,
+	scheduler *asynq.Scheduler,
+	listener *NotificationEventListener,
+	logger *zap.SugaredLogger,
+) *NotificationWorker {
+	return &NotificationWorker{
+		server:    server,
+		scheduler: scheduler,
+		mux:       asynq.NewServeMux(),
+		listener:  listener,
+		logger:    logger.With("component", "notification_worker"),
+	}
+}
+
+// Start starts processing the notification queue
+func (w *NotificationWorker) Start(ctx context.Context) error {
+	w.mux.HandleFunc(TaskTypeDeferredNotification, w.listener.ProcessDeferredTask)
+	w.mux.HandleFunc(TaskTypeFlushNotificationGroup, w.listener.ProcessFlushGroupTask)
+	w.mux.HandleFunc(TaskTypeSendDigests, w.listener.ProcessSendDigestsTask)
+
+	if err := w.server.Start(w.mux); err != nil {
+		return err
+	}
+
+	// Every API instance runs the scheduler, the unique option keeps one task per minute
+	_, err := w.scheduler.Register(digestsCronSpec, asynq.NewTask(TaskTypeSendDigests, nil),
+		asynq.Queue(queue.NotificationQueue),
+		asynq.Unique(30*time.Second),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register digest task: %w", err)
+	}
+	if err := w.scheduler.Start(); err != nil {
+		return fmt.Errorf("failed to start notification scheduler: %w", err)
+	}
+
+	w.logger.Info("Notification worker started")
+	return nil
+}
+
+// Stop stops the notification worker gracefully
+func (w *NotificationWorker) Stop() {
+	w.scheduler.Shutdown()
+	w.server.Shutdown()
+	w.logger.Info("Notification worker stopped")
+}
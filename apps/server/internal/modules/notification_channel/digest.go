@@ -0,0 +1,249 @@
+package notification_channel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"peekaping/internal/modules/heartbeat"
+	"peekaping/internal/modules/monitor"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
+	"github.com/robfig/cron/v3"
+)
+
+// NotificationDigest sends the status changes of monitors in digest only mode for the
+// channel as one summary on a schedule, instead of one notification per change
+type NotificationDigest struct {
+	// Schedule is a cron expression such as "0 9 * * 1-5" or a descriptor such as
+	// "@hourly" or "@daily", evaluated in the server timezone
+	Schedule string `json:"schedule"`
+}
+
+// Validate checks that the digest schedule can be parsed
+func (d *NotificationDigest) Validate() error {
+	if strings.TrimSpace(d.Schedule) == "" {
+		return fmt.Errorf("digest.schedule is required")
+	}
+	if _, err := cron.ParseStandard(d.Schedule); err != nil {
+		return fmt.Errorf("digest.schedule is invalid: %w", err)
+	}
+	return nil
+}
+
+// notificationDigestKeyPrefix prefixes the Redis hash collecting the status changes of a
+// channel's next digest, keyed by channel ID. The time collection started is kept under
+// the same key with a ":since" suffix.
+const notificationDigestKeyPrefix = "peekaping:notification_digest:"
+
+// NotificationDigestsDueKey is the sorted set of the channels with a digest to send,
+// scored by the Unix time it is due
+const NotificationDigestsDueKey = "peekaping:notification_digests:due"
+
+// digestItem is a status change collected for a digest
+type digestItem struct {
+	Monitor   *monitor.Model   `json:"monitor"`
+	Heartbeat *heartbeat.Model `json:"heartbeat"`
+}
+
+// digestEntry is the latest status change of a monitor waiting for the digest
+type digestEntry struct {
+	monitor   *monitor.Model
+	heartbeat *heartbeat.Model
+	changes   int
+}
+
+// pendingDigest holds the status changes of a channel when its digest is sent
+type pendingDigest struct {
+	channelID string
+	since     time.Time
+	entries   map[string]*digestEntry
+	// order keeps the monitors in the order of their first change
+	order []string
+	// last is the entry of the latest status change
+	last *digestEntry
+}
+
+// collectDigest adds a status change of a monitor in digest only mode to the channel's
+// next digest, marking it due at the next scheduled time when it is the first change, and
+// reports whether it was collected. Changes are kept in Redis until the periodic digest
+// task sends them. Channels without a digest are notified right away so nothing is lost.
+func (l *NotificationEventListener) collectDigest(notificationChannel *Model, options ChannelOptions, monitorModel *monitor.Model, hb *heartbeat.Model) bool {
+	digest := options.Digest
+	if digest == nil {
+		l.logger.Warnf("Monitor %s is digest only for %s, but the channel has no digest schedule, notifying right away", monitorModel.ID, notificationChannel.Name)
+		return false
+	}
+	schedule, err := cron.ParseStandard(digest.Schedule)
+	if err != nil {
+		l.logger.Warnf("Invalid digest schedule of %s, notifying right away: %v", notificationChannel.Name, err)
+		return false
+	}
+	if l.redis == nil {
+		l.logger.Warnf("Can't collect the digest of %s without Redis, notifying right away", notificationChannel.Name)
+		return false
+	}
+
+	ctx := context.Background()
+	item, err := json.Marshal(digestItem{Monitor: monitorModel, Heartbeat: hb})
+	if err != nil {
+		l.logger.Errorf("Failed to marshal digest item: %v", err)
+		return false
+	}
+
+	now := l.clock()
+	location := l.location
+	if location == nil {
+		location = time.UTC
+	}
+	next := schedule.Next(now.In(location))
+
+	key := notificationDigestKeyPrefix + notificationChannel.ID
+	_, err = l.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key, heartbeatKey(hb), item)
+		pipe.SetNX(ctx, key+":since", now.Format(time.RFC3339Nano), 0)
+		pipe.ZAddNX(ctx, NotificationDigestsDueKey, redis.Z{Score: float64(next.Unix()), Member: notificationChannel.ID})
+		return nil
+	})
+	if err != nil {
+		l.logger.Errorf("Failed to collect the digest of %s, notifying right away: %v", notificationChannel.Name, err)
+		return false
+	}
+
+	l.logger.Debugf("Status change of monitor %s collected for the digest of %s", monitorModel.ID, notificationChannel.Name)
+	return true
+}
+
+// ProcessSendDigestsTask sends the digests that are due. It runs every minute from the
+// scheduler, each due channel is claimed so overlapping runs send its digest once.
+func (l *NotificationEventListener) ProcessSendDigestsTask(ctx context.Context, task *asynq.Task) error {
+	due, err := l.redis.ZRangeByScore(ctx, NotificationDigestsDueKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(l.clock().Unix(), 10),
+	}).Result()
+	if err != nil {
+		return fmt.Errorf("failed to get due digests: %w", err)
+	}
+
+	for _, channelID := range due {
+		claimed, err := l.redis.ZRem(ctx, NotificationDigestsDueKey, channelID).Result()
+		if err != nil {
+			return fmt.Errorf("failed to claim digest of %s: %w", channelID, err)
+		}
+		if claimed == 0 {
+			continue
+		}
+
+		pending, err := l.takeDigest(ctx, channelID)
+		if err != nil {
+			l.logger.Errorf("Failed to take digest of %s: %v", channelID, err)
+			continue
+		}
+		if pending != nil {
+			l.sendDigest(ctx, pending)
+		}
+	}
+
+	return nil
+}
+
+// takeDigest removes the collected status changes of a channel from Redis and returns
+// them, nil when there are none
+func (l *NotificationEventListener) takeDigest(ctx context.Context, channelID string) (*pendingDigest, error) {
+	key := notificationDigestKeyPrefix + channelID
+	var itemsCmd *redis.MapStringStringCmd
+	var sinceCmd *redis.StringCmd
+	_, err := l.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		itemsCmd = pipe.HGetAll(ctx, key)
+		sinceCmd = pipe.Get(ctx, key+":since")
+		pipe.Del(ctx, key, key+":since")
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	var items []digestItem
+	for _, value := range itemsCmd.Val() {
+		var item digestItem
+		if err := json.Unmarshal([]byte(value), &item); err != nil || item.Monitor == nil || item.Heartbeat == nil {
+			l.logger.Warnf("Skipping invalid digest item of %s: %v", channelID, err)
+			continue
+		}
+		items = append(items, item)
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Heartbeat.Time.Before(items[j].Heartbeat.Time)
+	})
+
+	pending := &pendingDigest{
+		channelID: channelID,
+		since:     items[0].Heartbeat.Time,
+		entries:   make(map[string]*digestEntry),
+	}
+	if since, err := time.Parse(time.RFC3339Nano, sinceCmd.Val()); err == nil {
+		pending.since = since
+	}
+	for _, item := range items {
+		entry, ok := pending.entries[item.Monitor.ID]
+		if !ok {
+			entry = &digestEntry{monitor: item.Monitor}
+			pending.entries[item.Monitor.ID] = entry
+			pending.order = append(pending.order, item.Monitor.ID)
+		}
+		entry.heartbeat = item.Heartbeat
+		entry.changes++
+		pending.last = entry
+	}
+
+	return pending, nil
+}
+
+// sendDigest sends the collected status changes of a channel as one message. The channel
+// is loaded again so changes made in the meantime are respected.
+func (l *NotificationEventListener) sendDigest(ctx context.Context, pending *pendingDigest) {
+	notificationChannel, err := l.service.FindByID(ctx, pending.channelID)
+	if err != nil || notificationChannel == nil || notificationChannel.Config == nil {
+		l.logger.Warnf("Dropping digest of %d monitors: channel %s is gone, error: %v", len(pending.order), pending.channelID, err)
+		return
+	}
+
+	message := l.formatDigestMessage(pending)
+	// The digest is about several monitors. Providers get the latest status change for
+	// context, so incident tools don't open an incident for a digest of recoveries.
+	last := pending.last
+
+	l.deliver(ctx, notificationChannel, "digest", "digest", message, last.monitor, last.heartbeat)
+}
+
+// formatDigestMessage lists the latest status of every monitor in a digest
+func (l *NotificationEventListener) formatDigestMessage(pending *pendingDigest) string {
+	var sb strings.Builder
+	since := pending.since
+	if l.location != nil {
+		since = since.In(l.location)
+	}
+	fmt.Fprintf(&sb, "📋 Digest: %d monitors changed status since %s\n", len(pending.order), since.Format("2006-01-02 15:04 MST"))
+
+	for _, monitorID := range pending.order {
+		entry := pending.entries[monitorID]
+		icon, status := heartbeatStatusLabel(entry.heartbeat)
+		fmt.Fprintf(&sb, "\n%s %s: %s", icon, entry.monitor.Name, status)
+		if entry.heartbeat != nil && entry.heartbeat.Msg != "" {
+			fmt.Fprintf(&sb, " - %s", entry.heartbeat.Msg)
+		}
+		if entry.changes > 1 {
+			fmt.Fprintf(&sb, " (%d changes)", entry.changes)
+		}
+	}
+
+	return sb.String()
+}
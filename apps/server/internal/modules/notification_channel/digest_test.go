@@ -0,0 +1,167 @@
+package notification_channel
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"peekaping/internal/modules/events"
+	"peekaping/internal/modules/heartbeat"
+	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/monitor_notification"
+	"peekaping/internal/modules/shared"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestNotificationDigest_Validate(t *testing.T) {
+	assert.NoError(t, (&NotificationDigest{Schedule: "@hourly"}).Validate())
+	assert.NoError(t, (&NotificationDigest{Schedule: "0 9 * * 1-5"}).Validate())
+	assert.Error(t, (&NotificationDigest{}).Validate())
+	assert.Error(t, (&NotificationDigest{Schedule: "every hour"}).Validate())
+
+	assert.NoError(t, validateChannelOptions(`{"digest":{"schedule":"@daily"}}`))
+	assert.Error(t, validateChannelOptions(`{"digest":{"schedule":"61 * * * *"}}`))
+}
+
+func TestHandleNotifyEvent_Digest(t *testing.T) {
+	monitors := map[string]*monitor.Model{
+		"mon-1": {ID: "mon-1", Name: "API"},
+		"mon-2": {ID: "mon-2", Name: "Web"},
+		"mon-3": {ID: "mon-3", Name: "Billing"},
+	}
+	// mon-3 is not in digest only mode
+	digestOnly := map[string]bool{"mon-1": true, "mon-2": true}
+	// 10:20, so an hourly digest is due at 11:00
+	start := time.Date(2025, 10, 6, 10, 20, 0, 0, time.UTC)
+	dueAt := time.Date(2025, 10, 6, 11, 0, 0, 0, time.UTC)
+	changes := 0
+	heartbeatOf := func(monitorID string, status shared.MonitorStatus, msg string) *heartbeat.Model {
+		changes++
+		return &heartbeat.Model{ID: fmt.Sprintf("hb-%d", changes), MonitorID: monitorID, Status: status, Msg: msg, Important: true, Time: start.Add(time.Duration(changes) * time.Second)}
+	}
+	isMonitor := func(id string) interface{} {
+		return mock.MatchedBy(func(m *monitor.Model) bool { return m.ID == id })
+	}
+
+	t.Cleanup(func() { delete(NotificationChannelProviderRegistry, "mock_digest") })
+
+	setup := func(channelConfig string) (*NotificationEventListener, *MockProvider, *miniredis.Miniredis, *time.Time) {
+		provider := new(MockProvider)
+		RegisterNotificationChannelProvider("mock_digest", provider)
+		repo := new(MockRepository)
+		monitorSvc := new(MockMonitorService)
+		monitorNotificationSvc := new(MockMonitorNotificationService)
+		heartbeatSvc := new(MockHeartbeatService)
+
+		channel := &Model{ID: "chan-1", Name: "Ops", Type: "mock_digest", Active: true, Config: &channelConfig}
+		for id, m := range monitors {
+			monitorNotificationSvc.On("FindByMonitorID", mock.Anything, id).
+				Return([]*monitor_notification.Model{{MonitorID: id, NotificationID: "chan-1", DigestOnly: digestOnly[id]}}, nil)
+			monitorSvc.On("FindByID", mock.Anything, id).Return(m, nil)
+		}
+		repo.On("FindByID", mock.Anything, "chan-1").Return(channel, nil)
+		provider.On("Validate", channelConfig).Return(nil)
+		heartbeatSvc.On("FindByMonitorIDPaginated", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return([]*heartbeat.Model{}, nil)
+
+		mr := miniredis.RunT(t)
+		now := start
+		l := &NotificationEventListener{
+			service:                    createTestService(repo, monitorNotificationSvc),
+			monitorSvc:                 monitorSvc,
+			heartbeatService:           heartbeatSvc,
+			monitorNotificationService: monitorNotificationSvc,
+			redis:                      redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+			location:                   time.UTC,
+			logger:                     zap.NewNop().Sugar(),
+			now:                        func() time.Time { return now },
+		}
+		return l, provider, mr, &now
+	}
+
+	sendDigests := func(t *testing.T, l *NotificationEventListener) {
+		require.NoError(t, l.ProcessSendDigestsTask(context.Background(), asynq.NewTask(TaskTypeSendDigests, nil)))
+	}
+
+	t.Run("changes are collected and sent as one digest at the scheduled time", func(t *testing.T) {
+		config := `{"digest":{"schedule":"@hourly"}}`
+		l, provider, mr, now := setup(config)
+		provider.On("Send", mock.Anything, config, mock.Anything, isMonitor("mon-3"), mock.Anything).Return(nil).Once()
+
+		l.handleNotifyEvent(events.Event{Type: events.ImportantHeartbeat, Payload: heartbeatOf("mon-1", shared.MonitorStatusDown, "timeout")})
+		l.handleNotifyEvent(events.Event{Type: events.ImportantHeartbeat, Payload: heartbeatOf("mon-2", shared.MonitorStatusDown, "502 Bad Gateway")})
+		l.handleNotifyEvent(events.Event{Type: events.ImportantHeartbeat, Payload: heartbeatOf("mon-1", shared.MonitorStatusUp, "200 OK")})
+		l.handleNotifyEvent(events.Event{Type: events.ImportantHeartbeat, Payload: heartbeatOf("mon-3", shared.MonitorStatusDown, "timeout")})
+
+		// Only the monitor outside digest only mode was notified right away
+		provider.AssertNumberOfCalls(t, "Send", 1)
+		score, err := mr.ZScore(NotificationDigestsDueKey, "chan-1")
+		require.NoError(t, err)
+		assert.Equal(t, float64(dueAt.Unix()), score)
+
+		// Not due yet
+		sendDigests(t, l)
+		provider.AssertNumberOfCalls(t, "Send", 1)
+
+		// Providers get the latest status change, the recovery of the API
+		var sent string
+		provider.On("Send", mock.Anything, config, mock.Anything, isMonitor("mon-1"), mock.MatchedBy(func(h *heartbeat.Model) bool {
+			return h != nil && h.Status == shared.MonitorStatusUp
+		})).Run(func(args mock.Arguments) { sent = args.String(2) }).Return(nil).Once()
+		*now = dueAt
+		sendDigests(t, l)
+		// A run overlapping with the first one doesn't send it again
+		sendDigests(t, l)
+
+		provider.AssertExpectations(t)
+		assert.True(t, strings.HasPrefix(sent, "📋 Digest: 2 monitors changed status since 2025-10-06 10:20 UTC"), sent)
+		assert.Contains(t, sent, "✅ API: UP - 200 OK (2 changes)")
+		assert.Contains(t, sent, "🔴 Web: DOWN - 502 Bad Gateway")
+		assert.Less(t, strings.Index(sent, "API"), strings.Index(sent, "Web"))
+
+		// The next change starts a new digest, due at the following hour
+		l.handleNotifyEvent(events.Event{Type: events.ImportantHeartbeat, Payload: heartbeatOf("mon-2", shared.MonitorStatusUp, "200 OK")})
+		score, err = mr.ZScore(NotificationDigestsDueKey, "chan-1")
+		require.NoError(t, err)
+		assert.Equal(t, float64(dueAt.Add(time.Hour).Unix()), score)
+	})
+
+	t.Run("a change received by several instances is collected once", func(t *testing.T) {
+		config := `{"digest":{"schedule":"@hourly"}}`
+		l, provider, _, now := setup(config)
+
+		hb := heartbeatOf("mon-2", shared.MonitorStatusDown, "502 Bad Gateway")
+		l.handleNotifyEvent(events.Event{Type: events.ImportantHeartbeat, Payload: hb})
+		l.handleNotifyEvent(events.Event{Type: events.ImportantHeartbeat, Payload: hb})
+
+		var sent string
+		provider.On("Send", mock.Anything, config, mock.Anything, isMonitor("mon-2"), mock.Anything).
+			Run(func(args mock.Arguments) { sent = args.String(2) }).Return(nil).Once()
+		*now = dueAt
+		sendDigests(t, l)
+
+		provider.AssertExpectations(t)
+		assert.Contains(t, sent, "🔴 Web: DOWN - 502 Bad Gateway")
+		assert.NotContains(t, sent, "changes)")
+	})
+
+	t.Run("without a digest schedule changes are notified right away", func(t *testing.T) {
+		config := `{}`
+		l, provider, mr, _ := setup(config)
+		provider.On("Send", mock.Anything, config, mock.Anything, isMonitor("mon-1"), mock.Anything).Return(nil).Once()
+
+		l.handleNotifyEvent(events.Event{Type: events.ImportantHeartbeat, Payload: heartbeatOf("mon-1", shared.MonitorStatusDown, "timeout")})
+
+		provider.AssertExpectations(t)
+		assert.False(t, mr.Exists(NotificationDigestsDueKey))
+	})
+}
@@ -0,0 +1,114 @@
+package notification_channel
+
+import (
+	"context"
+	"fmt"
+	"peekaping/internal/modules/heartbeat"
+	"peekaping/internal/modules/shared"
+)
+
+// maxNotifyAfterFailures caps the failure threshold of a channel, it bounds the heartbeat
+// history read for every notification
+const maxNotifyAfterFailures = 100
+
+// validateNotifyAfterFailures checks the notify_after_failures channel option
+func validateNotifyAfterFailures(n int) error {
+	if n < 0 || n > maxNotifyAfterFailures {
+		return fmt.Errorf("notify_after_failures must be between 0 and %d", maxNotifyAfterFailures)
+	}
+	return nil
+}
+
+// waitsForFailures reports whether a channel of the monitor waits for more than one
+// consecutive failure, through the escalation of the monitor or its notify_after_failures
+// option. Channels are only fetched when no escalation does.
+func (l *NotificationEventListener) waitsForFailures(ctx context.Context, monitorID string) bool {
+	links, err := l.monitorNotificationService.FindByMonitorID(ctx, monitorID)
+	if err != nil {
+		l.logger.Errorf("Failed to get monitor-notification records: %v", err)
+		return false
+	}
+
+	for _, mn := range links {
+		if mn.EscalateAfter > 1 {
+			return true
+		}
+	}
+	for _, mn := range links {
+		channel, err := l.service.FindByID(ctx, mn.NotificationID)
+		if err != nil || channel == nil || channel.Config == nil {
+			continue
+		}
+		if l.parseChannelOptions(*channel.Config).NotifyAfterFailures > 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// belowFailureThreshold reports whether a channel waiting for threshold consecutive
+// failures, from its notify_after_failures option or the escalation of the monitor, must
+// not be notified of the heartbeat. The down notification is sent with the failure
+// reaching the threshold, recoveries only after outages that reached it, so transient
+// blips stay silent. continuedOutage is set for the later failures of an outage, which
+// only matter to channels waiting for one of them.
+func (l *NotificationEventListener) belowFailureThreshold(ctx context.Context, threshold int, hb *heartbeat.Model, continuedOutage bool) bool {
+	if threshold <= 1 {
+		return continuedOutage
+	}
+
+	switch hb.Status {
+	case shared.MonitorStatusDown:
+		// One more than the threshold tells the failure reaching it from later ones
+		failures := l.countConsecutiveFailures(ctx, hb, threshold+1, true)
+		if continuedOutage {
+			return failures != threshold
+		}
+		return failures < threshold
+	case shared.MonitorStatusUp:
+		if continuedOutage {
+			return true
+		}
+		return l.countConsecutiveFailures(ctx, hb, threshold+1, false) < threshold
+	default:
+		return continuedOutage
+	}
+}
+
+// countConsecutiveFailures counts the down heartbeats of the monitor in a row up to hb,
+// including hb itself when includeSelf is set, reading at most limit heartbeats
+func (l *NotificationEventListener) countConsecutiveFailures(ctx context.Context, hb *heartbeat.Model, limit int, includeSelf bool) int {
+	history, err := l.heartbeatService.FindByMonitorIDPaginated(ctx, hb.MonitorID, limit, 0, nil, false)
+	if err != nil {
+		l.logger.Warnf("Failed to get heartbeat history for monitor %s: %v", hb.MonitorID, err)
+		return 0
+	}
+
+	// History is ordered newest first and may hold heartbeats stored after hb
+	failures := 0
+	for _, prev := range history {
+		if prev.ID == hb.ID {
+			if includeSelf {
+				failures++
+			}
+			continue
+		}
+		if prev.Time.After(hb.Time) {
+			continue
+		}
+		if prev.Status != shared.MonitorStatusDown {
+			break
+		}
+		failures++
+	}
+	return failures
+}
+
+// escalationNote heads the notifications of a channel a monitor escalates to after a
+// number of failures, so it is clear the outage already went on for a while
+func escalationNote(hb *heartbeat.Model, escalateAfter int) string {
+	if hb.Status != shared.MonitorStatusDown {
+		return fmt.Sprintf("⏫ Escalated outage (%d failed checks in a row) is over\n", escalateAfter)
+	}
+	return fmt.Sprintf("⏫ Escalated: %d failed checks in a row\n", escalateAfter)
+}
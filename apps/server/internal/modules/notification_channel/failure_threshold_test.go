@@ -0,0 +1,223 @@
+package notification_channel
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"peekaping/internal/modules/events"
+	"peekaping/internal/modules/heartbeat"
+	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/monitor_notification"
+	"peekaping/internal/modules/shared"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+func TestHandleNotifyEvent_NotifyAfterFailures(t *testing.T) {
+	mon := &monitor.Model{ID: "mon-1", Name: "API"}
+	start := time.Date(2025, 10, 6, 10, 0, 0, 0, time.UTC)
+
+	// beats builds the history of the monitor from oldest to newest and returns it newest
+	// first, like the heartbeat service
+	beats := func(statuses ...shared.MonitorStatus) []*heartbeat.Model {
+		history := make([]*heartbeat.Model, len(statuses))
+		for i, status := range statuses {
+			history[len(statuses)-1-i] = &heartbeat.Model{
+				ID:        fmt.Sprintf("hb-%d", i),
+				MonitorID: "mon-1",
+				Status:    status,
+				Msg:       "connection refused",
+				Time:      start.Add(time.Duration(i) * time.Minute),
+			}
+		}
+		return history
+	}
+	down, up := shared.MonitorStatusDown, shared.MonitorStatusUp
+
+	t.Cleanup(func() { delete(NotificationChannelProviderRegistry, "mock_failures") })
+
+	setup := func(channelConfig string, history []*heartbeat.Model) (*NotificationEventListener, *MockProvider) {
+		provider := new(MockProvider)
+		RegisterNotificationChannelProvider("mock_failures", provider)
+		repo := new(MockRepository)
+		monitorSvc := new(MockMonitorService)
+		monitorNotificationSvc := new(MockMonitorNotificationService)
+		heartbeatSvc := new(MockHeartbeatService)
+
+		channel := &Model{ID: "chan-1", Name: "Pager", Type: "mock_failures", Active: true, Config: &channelConfig}
+		monitorNotificationSvc.On("FindByMonitorID", mock.Anything, "mon-1").Return([]*monitor_notification.Model{{MonitorID: "mon-1", NotificationID: "chan-1"}}, nil)
+		repo.On("FindByID", mock.Anything, "chan-1").Return(channel, nil)
+		monitorSvc.On("FindByID", mock.Anything, "mon-1").Return(mon, nil)
+		provider.On("Validate", channelConfig).Return(nil)
+		provider.On("Send", mock.Anything, channelConfig, mock.Anything, mon, mock.Anything).Return(nil)
+		heartbeatSvc.On("FindByMonitorIDPaginated", mock.Anything, "mon-1", mock.Anything, 0, (*bool)(nil), false).
+			Return(history, nil)
+		// Recovery messages look up the down transition
+		heartbeatSvc.On("FindByMonitorIDPaginated", mock.Anything, "mon-1", mock.Anything, 0, mock.MatchedBy(func(important *bool) bool { return important != nil }), false).
+			Return([]*heartbeat.Model{}, nil)
+
+		l := &NotificationEventListener{
+			service:                    createTestService(repo, monitorNotificationSvc),
+			monitorSvc:                 monitorSvc,
+			heartbeatService:           heartbeatSvc,
+			monitorNotificationService: monitorNotificationSvc,
+			logger:                     zap.NewNop().Sugar(),
+		}
+		return l, provider
+	}
+
+	threeFailures := `{"notify_after_failures":3}`
+
+	tests := []struct {
+		name      string
+		config    string
+		history   []*heartbeat.Model
+		event     events.EventType
+		important bool
+		sent      bool
+	}{
+		{"first failure is held back", threeFailures, beats(up, down), events.ImportantHeartbeat, true, false},
+		{"second failure is held back", threeFailures, beats(up, down, down), events.HeartbeatEvent, false, false},
+		{"failure reaching the threshold is sent", threeFailures, beats(up, down, down, down), events.HeartbeatEvent, false, true},
+		{"later failures are not sent again", threeFailures, beats(up, down, down, down, down), events.HeartbeatEvent, false, false},
+		{"resends after the threshold are sent", threeFailures, beats(down, down, down, down), events.ImportantHeartbeat, true, true},
+		{"recovery after a notified outage is sent", threeFailures, beats(down, down, down, up), events.ImportantHeartbeat, true, true},
+		{"recovery after a blip is held back", threeFailures, beats(up, down, up), events.ImportantHeartbeat, true, false},
+		{"without threshold the first failure is sent", `{}`, beats(up, down), events.ImportantHeartbeat, true, true},
+		{"without threshold later failures are ignored", `{}`, beats(up, down, down, down), events.HeartbeatEvent, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, provider := setup(tt.config, tt.history)
+			hb := *tt.history[0]
+			hb.Important = tt.important
+			hb.Notified = tt.important
+
+			if tt.event == events.ImportantHeartbeat {
+				l.handleNotifyEvent(events.Event{Type: tt.event, Payload: &hb})
+			} else {
+				l.handleHeartbeatEvent(events.Event{Type: tt.event, Payload: &hb})
+			}
+
+			if tt.sent {
+				provider.AssertNumberOfCalls(t, "Send", 1)
+			} else {
+				provider.AssertNotCalled(t, "Send", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestHandleHeartbeatEvent_WithoutThreshold(t *testing.T) {
+	monitorNotificationSvc := new(MockMonitorNotificationService)
+	repo := new(MockRepository)
+	monitorSvc := new(MockMonitorService)
+	config := `{"notify_after_failures":1}`
+
+	monitorNotificationSvc.On("FindByMonitorID", mock.Anything, "mon-1").Return([]*monitor_notification.Model{
+		{MonitorID: "mon-1", NotificationID: "chan-1", EscalateAfter: 1},
+	}, nil)
+	repo.On("FindByID", mock.Anything, "chan-1").Return(&Model{ID: "chan-1", Name: "Pager", Type: "mock_failures", Active: true, Config: &config}, nil)
+
+	l := &NotificationEventListener{
+		service:                    createTestService(repo, monitorNotificationSvc),
+		monitorSvc:                 monitorSvc,
+		monitorNotificationService: monitorNotificationSvc,
+		logger:                     zap.NewNop().Sugar(),
+	}
+
+	hb := &heartbeat.Model{ID: "hb-1", MonitorID: "mon-1", Status: shared.MonitorStatusDown}
+	l.handleHeartbeatEvent(events.Event{Type: events.HeartbeatEvent, Payload: hb})
+
+	// The failure stops before the monitor is fetched for the notification
+	monitorSvc.AssertNotCalled(t, "FindByID", mock.Anything, mock.Anything)
+}
+
+func TestValidateChannelOptions_NotifyAfterFailures(t *testing.T) {
+	assert.NoError(t, validateChannelOptions(`{"notify_after_failures":5}`))
+	assert.Error(t, validateChannelOptions(`{"notify_after_failures":-1}`))
+	assert.Error(t, validateChannelOptions(`{"notify_after_failures":101}`))
+}
+
+func TestHandleNotifyEvent_EscalateAfter(t *testing.T) {
+	mon := &monitor.Model{ID: "mon-1", Name: "API"}
+	start := time.Date(2025, 10, 6, 10, 0, 0, 0, time.UTC)
+	down, up := shared.MonitorStatusDown, shared.MonitorStatusUp
+
+	t.Cleanup(func() { delete(NotificationChannelProviderRegistry, "mock_escalation") })
+
+	primaryConfig, managerConfig := `{"url":"https://primary.example.com"}`, `{"url":"https://manager.example.com"}`
+	provider := new(MockProvider)
+	RegisterNotificationChannelProvider("mock_escalation", provider)
+	repo := new(MockRepository)
+	monitorSvc := new(MockMonitorService)
+	monitorNotificationSvc := new(MockMonitorNotificationService)
+	heartbeatSvc := new(MockHeartbeatService)
+
+	monitorNotificationSvc.On("FindByMonitorID", mock.Anything, "mon-1").Return([]*monitor_notification.Model{
+		{MonitorID: "mon-1", NotificationID: "chan-primary"},
+		{MonitorID: "mon-1", NotificationID: "chan-manager", EscalateAfter: 3},
+	}, nil)
+	repo.On("FindByID", mock.Anything, "chan-primary").Return(&Model{ID: "chan-primary", Name: "On call", Type: "mock_escalation", Active: true, Config: &primaryConfig}, nil)
+	repo.On("FindByID", mock.Anything, "chan-manager").Return(&Model{ID: "chan-manager", Name: "Manager", Type: "mock_escalation", Active: true, Config: &managerConfig}, nil)
+	monitorSvc.On("FindByID", mock.Anything, "mon-1").Return(mon, nil)
+	provider.On("Validate", mock.Anything).Return(nil)
+	provider.On("Send", mock.Anything, mock.Anything, mock.Anything, mon, mock.Anything).Return(nil)
+	heartbeatSvc.On("FindByMonitorIDPaginated", mock.Anything, "mon-1", mock.Anything, 0, mock.MatchedBy(func(important *bool) bool { return important != nil }), false).
+		Return([]*heartbeat.Model{}, nil)
+
+	l := &NotificationEventListener{
+		service:                    createTestService(repo, monitorNotificationSvc),
+		monitorSvc:                 monitorSvc,
+		heartbeatService:           heartbeatSvc,
+		monitorNotificationService: monitorNotificationSvc,
+		logger:                     zap.NewNop().Sugar(),
+	}
+
+	// An outage of four failed checks followed by a recovery, fed to the listener one
+	// heartbeat at a time like the ingester does
+	statuses := []shared.MonitorStatus{up, down, down, down, down, up}
+	var history []*heartbeat.Model
+	sentTo := func(config string) int {
+		n := 0
+		for _, call := range provider.Calls {
+			if call.Method == "Send" && call.Arguments.Get(1) == config {
+				n++
+			}
+		}
+		return n
+	}
+
+	expected := []struct{ primary, manager int }{{0, 0}, {1, 0}, {1, 0}, {1, 1}, {1, 1}, {2, 2}}
+	for i, status := range statuses {
+		hb := &heartbeat.Model{ID: fmt.Sprintf("hb-%d", i), MonitorID: "mon-1", Status: status, Msg: "connection refused", Time: start.Add(time.Duration(i) * time.Minute)}
+		important := i > 0 && status != statuses[i-1]
+		hb.Important, hb.Notified = important, important
+		history = append([]*heartbeat.Model{hb}, history...)
+
+		heartbeatSvc.ExpectedCalls = heartbeatSvc.ExpectedCalls[:1]
+		heartbeatSvc.On("FindByMonitorIDPaginated", mock.Anything, "mon-1", mock.Anything, 0, (*bool)(nil), false).Return(history, nil)
+
+		if i > 0 {
+			event := events.Event{Type: events.HeartbeatEvent, Payload: hb}
+			if important {
+				l.handleNotifyEvent(events.Event{Type: events.ImportantHeartbeat, Payload: hb})
+			}
+			l.handleHeartbeatEvent(event)
+		}
+
+		assert.Equal(t, expected[i].primary, sentTo(primaryConfig), "primary after heartbeat %d", i)
+		assert.Equal(t, expected[i].manager, sentTo(managerConfig), "manager after heartbeat %d", i)
+	}
+
+	// The escalated channel is told the outage lasted long enough to reach it
+	for _, call := range provider.Calls {
+		if call.Method == "Send" && call.Arguments.Get(1) == managerConfig {
+			assert.Contains(t, call.Arguments.String(2), "⏫ Escalated")
+		}
+	}
+}
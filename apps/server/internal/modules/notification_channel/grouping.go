@@ -0,0 +1,279 @@
+package notification_channel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"peekaping/internal/modules/heartbeat"
+	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/queue"
+	"peekaping/internal/modules/shared"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
+)
+
+// maxGroupingWindow caps how long notifications can be held back for grouping
+const maxGroupingWindow = 3600
+
+// NotificationGrouping collects the status changes routed to a channel for a while so a
+// burst of them, e.g. when a shared dependency goes down, arrives as a single message.
+// Every status change is delivered once its window closes, at most Window seconds late.
+type NotificationGrouping struct {
+	// Window is how many seconds status changes are collected, starting with the first one
+	Window int `json:"window"`
+	// Threshold is the number of status changes in a window above which they are sent as
+	// one message listing all affected monitors. Up to it they are sent one by one.
+	Threshold int `json:"threshold"`
+}
+
+// Validate checks that the grouping can be applied
+func (g *NotificationGrouping) Validate() error {
+	if g.Window < 1 || g.Window > maxGroupingWindow {
+		return fmt.Errorf("grouping.window must be between 1 and %d seconds", maxGroupingWindow)
+	}
+	if g.Threshold < 1 {
+		return fmt.Errorf("grouping.threshold must be at least 1")
+	}
+	return nil
+}
+
+// notificationGroupKeyPrefix prefixes the Redis hash holding the open grouping window of a
+// channel, keyed by channel ID. The window's opening time is kept under the same key with
+// an ":opened" suffix.
+const notificationGroupKeyPrefix = "peekaping:notification_group:"
+
+// groupKeyGrace keeps a grouping window in Redis for a while after it should have been
+// flushed, so a late flush still finds it
+const groupKeyGrace = time.Hour
+
+// groupedNotification is a status change waiting in its channel's grouping window
+type groupedNotification struct {
+	Message   string           `json:"message"`
+	Monitor   *monitor.Model   `json:"monitor"`
+	Heartbeat *heartbeat.Model `json:"heartbeat"`
+}
+
+// notificationGroup is a closed grouping window of a channel
+type notificationGroup struct {
+	channelID string
+	openedAt  time.Time
+	items     []groupedNotification
+}
+
+// flushGroupPayload is the payload of the task closing a channel's grouping window
+type flushGroupPayload struct {
+	ChannelID string `json:"channel_id"`
+}
+
+// heartbeatKey identifies a heartbeat among the status changes collected in Redis. Every
+// API instance receives the heartbeat event, keying by it keeps the change once.
+func heartbeatKey(hb *heartbeat.Model) string {
+	if hb.ID != "" {
+		return hb.ID
+	}
+	return hb.MonitorID + "/" + hb.Time.Format(time.RFC3339Nano)
+}
+
+// groupNotification adds a status change to the open grouping window of the channel,
+// opening one when there is none, and reports whether it was held back. Windows are kept
+// in Redis and closed by a task of the notification queue, so they survive restarts.
+func (l *NotificationEventListener) groupNotification(notificationChannel *Model, options ChannelOptions, message string, monitorModel *monitor.Model, hb *heartbeat.Model) bool {
+	grouping := options.Grouping
+	if grouping == nil || grouping.Validate() != nil {
+		return false
+	}
+	if l.redis == nil || l.queueService == nil {
+		l.logger.Warnf("Can't group notifications to %s without Redis, notifying right away", notificationChannel.Name)
+		return false
+	}
+
+	ctx := context.Background()
+	item, err := json.Marshal(groupedNotification{Message: message, Monitor: monitorModel, Heartbeat: hb})
+	if err != nil {
+		l.logger.Errorf("Failed to marshal grouped notification: %v", err)
+		return false
+	}
+
+	key := notificationGroupKeyPrefix + notificationChannel.ID
+	window := time.Duration(grouping.Window) * time.Second
+	ttl := window + groupKeyGrace
+
+	// The change is added before the window is opened, so a flush running in between
+	// leaves it to the next window instead of losing it
+	_, err = l.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key, heartbeatKey(hb), item)
+		pipe.Expire(ctx, key, ttl)
+		return nil
+	})
+	if err != nil {
+		l.logger.Errorf("Failed to group notification to %s, notifying right away: %v", notificationChannel.Name, err)
+		return false
+	}
+
+	opened, err := l.redis.SetNX(ctx, key+":opened", l.clock().Format(time.RFC3339Nano), ttl).Result()
+	if err != nil {
+		l.logger.Errorf("Failed to open grouping window of %s: %v", notificationChannel.Name, err)
+		return true
+	}
+	if !opened {
+		return true
+	}
+
+	_, err = l.queueService.Enqueue(ctx, TaskTypeFlushNotificationGroup, &flushGroupPayload{ChannelID: notificationChannel.ID}, &queue.EnqueueOptions{
+		Queue:     queue.NotificationQueue,
+		MaxRetry:  3,
+		Timeout:   time.Minute,
+		ProcessIn: &window,
+	})
+	if err != nil {
+		// Without a flush the window would never close, the next change opens a new one
+		// and takes the collected changes along
+		l.logger.Errorf("Failed to schedule grouping window of %s, notifying right away: %v", notificationChannel.Name, err)
+		l.redis.HDel(ctx, key, heartbeatKey(hb))
+		l.redis.Del(ctx, key+":opened")
+		return false
+	}
+
+	l.logger.Debugf("Grouping notifications to %s for %ds", notificationChannel.Name, grouping.Window)
+	return true
+}
+
+// ProcessFlushGroupTask closes the grouping window of a channel and sends its status
+// changes
+func (l *NotificationEventListener) ProcessFlushGroupTask(ctx context.Context, task *asynq.Task) error {
+	var payload flushGroupPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		l.logger.Errorf("Failed to unmarshal notification group payload: %v", err)
+		return nil
+	}
+
+	key := notificationGroupKeyPrefix + payload.ChannelID
+	var itemsCmd *redis.MapStringStringCmd
+	var openedCmd *redis.StringCmd
+	_, err := l.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		itemsCmd = pipe.HGetAll(ctx, key)
+		openedCmd = pipe.Get(ctx, key+":opened")
+		pipe.Del(ctx, key, key+":opened")
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return fmt.Errorf("failed to take notification group of %s: %w", payload.ChannelID, err)
+	}
+
+	group := &notificationGroup{channelID: payload.ChannelID}
+	for _, value := range itemsCmd.Val() {
+		var item groupedNotification
+		if err := json.Unmarshal([]byte(value), &item); err != nil || item.Monitor == nil || item.Heartbeat == nil {
+			l.logger.Warnf("Skipping invalid grouped notification of %s: %v", payload.ChannelID, err)
+			continue
+		}
+		group.items = append(group.items, item)
+	}
+	if len(group.items) == 0 {
+		return nil
+	}
+	sort.SliceStable(group.items, func(i, j int) bool {
+		return group.items[i].Heartbeat.Time.Before(group.items[j].Heartbeat.Time)
+	})
+
+	group.openedAt = group.items[0].Heartbeat.Time
+	if openedAt, err := time.Parse(time.RFC3339Nano, openedCmd.Val()); err == nil {
+		group.openedAt = openedAt
+	}
+
+	l.flushGroup(ctx, group)
+	return nil
+}
+
+// flushGroup sends the status changes of a closed grouping window, as one message when
+// there are more than the threshold. The channel is loaded again so changes made in the
+// meantime are respected.
+func (l *NotificationEventListener) flushGroup(ctx context.Context, group *notificationGroup) {
+	notificationChannel, err := l.service.FindByID(ctx, group.channelID)
+	if err != nil || notificationChannel == nil || notificationChannel.Config == nil {
+		l.logger.Warnf("Dropping %d grouped notifications: channel %s is gone, error: %v", len(group.items), group.channelID, err)
+		return
+	}
+
+	integration, ok := GetNotificationChannelProvider(notificationChannel.Type)
+	if !ok {
+		l.logger.Warnf("No integration registered for notification type: %s", notificationChannel.Type)
+		return
+	}
+
+	options := l.parseChannelOptions(*notificationChannel.Config)
+	threshold := 0
+	if options.Grouping != nil {
+		threshold = options.Grouping.Threshold
+	}
+
+	if threshold == 0 || len(group.items) <= threshold {
+		for _, item := range group.items {
+			eventType := shared.HeartbeatNotificationEvent(item.Heartbeat.Status, item.Heartbeat.ErrorCategory)
+			if l.holdOffHours(notificationChannel, options, eventType, item.Message, item.Monitor, item.Heartbeat) {
+				continue
+			}
+			if err := integration.Send(ctx, *notificationChannel.Config, item.Message, item.Monitor, item.Heartbeat); err != nil {
+				l.logger.Errorf("Failed to send notification: %s, error: %v", notificationChannel.Name, err)
+			} else {
+				l.logger.Infof("Notification sent to: %s for monitor: %s", notificationChannel.Name, item.Monitor.ID)
+			}
+		}
+		return
+	}
+
+	// The aggregated message is about several monitors. Providers get the last status
+	// change for context, so incident tools resolve on a window of recoveries instead of
+	// opening an incident for it.
+	message := formatGroupedMessage(group, l.clock())
+	last := group.items[len(group.items)-1]
+	if l.holdOffHours(notificationChannel, options, "grouped", message, last.Monitor, last.Heartbeat) {
+		return
+	}
+	if err := integration.Send(ctx, *notificationChannel.Config, message, last.Monitor, last.Heartbeat); err != nil {
+		l.logger.Errorf("Failed to send grouped notification: %s, error: %v", notificationChannel.Name, err)
+	} else {
+		l.logger.Infof("Grouped notification of %d status changes sent to: %s", len(group.items), notificationChannel.Name)
+	}
+}
+
+// formatGroupedMessage creates the message listing the status changes of a grouping window
+func formatGroupedMessage(group *notificationGroup, closedAt time.Time) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "📢 %d monitors changed status within %s\n", len(group.items), formatDowntime(closedAt.Sub(group.openedAt)))
+
+	for _, item := range group.items {
+		icon, status := heartbeatStatusLabel(item.Heartbeat)
+		fmt.Fprintf(&sb, "\n%s %s: %s", icon, item.Monitor.Name, status)
+		if item.Heartbeat != nil && item.Heartbeat.Msg != "" {
+			fmt.Fprintf(&sb, " - %s", item.Heartbeat.Msg)
+		}
+	}
+
+	return sb.String()
+}
+
+// heartbeatStatusLabel returns the icon and name of the status of a heartbeat for
+// messages listing several monitors
+func heartbeatStatusLabel(hb *heartbeat.Model) (string, string) {
+	if hb == nil {
+		return "❔", "UNKNOWN"
+	}
+	switch hb.Status {
+	case shared.MonitorStatusDown:
+		return "🔴", "DOWN"
+	case shared.MonitorStatusUp:
+		return "✅", "UP"
+	case shared.MonitorStatusPending:
+		return "⏳", "PENDING"
+	case shared.MonitorStatusMaintenance:
+		return "🚧", "MAINTENANCE"
+	default:
+		return "❔", "UNKNOWN"
+	}
+}
@@ -0,0 +1,216 @@
+package notification_channel
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"peekaping/internal/modules/events"
+	"peekaping/internal/modules/heartbeat"
+	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/monitor_notification"
+	"peekaping/internal/modules/shared"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestNotificationGrouping_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		grouping NotificationGrouping
+		wantErr  bool
+	}{
+		{"one minute above two", NotificationGrouping{Window: 60, Threshold: 2}, false},
+		{"longest window", NotificationGrouping{Window: maxGroupingWindow, Threshold: 1}, false},
+		{"missing window", NotificationGrouping{Threshold: 2}, true},
+		{"window too long", NotificationGrouping{Window: maxGroupingWindow + 1, Threshold: 2}, true},
+		{"missing threshold", NotificationGrouping{Window: 60}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.grouping.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	t.Run("checked with the channel options", func(t *testing.T) {
+		assert.NoError(t, validateChannelOptions(`{"grouping":{"window":30,"threshold":3}}`))
+		assert.Error(t, validateChannelOptions(`{"grouping":{"window":0,"threshold":3}}`))
+	})
+}
+
+func TestHandleNotifyEvent_Grouping(t *testing.T) {
+	monitorIDs := []string{"mon-1", "mon-2", "mon-3"}
+	monitors := map[string]*monitor.Model{
+		"mon-1": {ID: "mon-1", Name: "API"},
+		"mon-2": {ID: "mon-2", Name: "Web"},
+		"mon-3": {ID: "mon-3", Name: "Worker"},
+	}
+	start := time.Date(2025, 10, 6, 10, 0, 0, 0, time.UTC)
+	heartbeatOf := func(monitorID string, status shared.MonitorStatus, msg string, at time.Time) *heartbeat.Model {
+		return &heartbeat.Model{ID: "hb-" + monitorID + "-" + msg, MonitorID: monitorID, Status: status, Msg: msg, Important: true, Time: at}
+	}
+	downOf := func(i int) *heartbeat.Model {
+		return heartbeatOf(monitorIDs[i], shared.MonitorStatusDown, "connection refused", start.Add(time.Duration(i)*time.Second))
+	}
+	isMonitor := func(id string) interface{} {
+		return mock.MatchedBy(func(m *monitor.Model) bool { return m.ID == id })
+	}
+	isHeartbeat := func(id string) interface{} {
+		return mock.MatchedBy(func(h *heartbeat.Model) bool { return h != nil && h.ID == id })
+	}
+
+	t.Cleanup(func() { delete(NotificationChannelProviderRegistry, "mock_grouping") })
+
+	setup := func(channelConfig string) (*NotificationEventListener, *MockProvider, *recordingQueue) {
+		provider := new(MockProvider)
+		RegisterNotificationChannelProvider("mock_grouping", provider)
+		repo := new(MockRepository)
+		monitorSvc := new(MockMonitorService)
+		monitorNotificationSvc := new(MockMonitorNotificationService)
+		heartbeatSvc := new(MockHeartbeatService)
+
+		channel := &Model{ID: "chan-1", Name: "Pager", Type: "mock_grouping", Active: true, Config: &channelConfig}
+		for _, id := range monitorIDs {
+			monitorNotificationSvc.On("FindByMonitorID", mock.Anything, id).Return([]*monitor_notification.Model{{MonitorID: id, NotificationID: "chan-1"}}, nil)
+			monitorSvc.On("FindByID", mock.Anything, id).Return(monitors[id], nil)
+		}
+		repo.On("FindByID", mock.Anything, "chan-1").Return(channel, nil)
+		provider.On("Validate", channelConfig).Return(nil)
+		heartbeatSvc.On("FindByMonitorIDPaginated", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return([]*heartbeat.Model{}, nil)
+
+		mr := miniredis.RunT(t)
+		tasks := &recordingQueue{}
+		l := &NotificationEventListener{
+			service:                    createTestService(repo, monitorNotificationSvc),
+			monitorSvc:                 monitorSvc,
+			heartbeatService:           heartbeatSvc,
+			monitorNotificationService: monitorNotificationSvc,
+			queueService:               tasks,
+			redis:                      redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+			location:                   time.UTC,
+			logger:                     zap.NewNop().Sugar(),
+			now:                        func() time.Time { return start.Add(time.Minute) },
+		}
+		return l, provider, tasks
+	}
+
+	flush := func(t *testing.T, l *NotificationEventListener, task *recordedTask) {
+		assert.Equal(t, TaskTypeFlushNotificationGroup, task.taskType)
+		require.NoError(t, l.ProcessFlushGroupTask(context.Background(), task.asynqTask()))
+	}
+
+	t.Run("without grouping each change is sent right away", func(t *testing.T) {
+		config := `{}`
+		l, provider, tasks := setup(config)
+		provider.On("Send", mock.Anything, config, "connection refused", mock.Anything, mock.Anything).Return(nil).Times(3)
+
+		for i := range monitorIDs {
+			l.handleNotifyEvent(events.Event{Type: events.ImportantHeartbeat, Payload: downOf(i)})
+		}
+
+		provider.AssertExpectations(t)
+		assert.Empty(t, tasks.enqueued())
+	})
+
+	t.Run("changes above the threshold are aggregated", func(t *testing.T) {
+		config := `{"grouping":{"window":60,"threshold":2}}`
+		l, provider, tasks := setup(config)
+
+		for i := range monitorIDs {
+			l.handleNotifyEvent(events.Event{Type: events.ImportantHeartbeat, Payload: downOf(i)})
+		}
+
+		provider.AssertNotCalled(t, "Send", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		enqueued := tasks.enqueued()
+		require.Len(t, enqueued, 1)
+		require.NotNil(t, enqueued[0].opts.ProcessIn)
+		assert.Equal(t, 60*time.Second, *enqueued[0].opts.ProcessIn)
+
+		// Providers get the last status change of the window
+		var sent string
+		provider.On("Send", mock.Anything, config, mock.Anything, isMonitor("mon-3"), isHeartbeat(downOf(2).ID)).
+			Run(func(args mock.Arguments) { sent = args.String(2) }).Return(nil).Once()
+		flush(t, l, enqueued[0])
+
+		provider.AssertExpectations(t)
+		assert.True(t, strings.HasPrefix(sent, "📢 3 monitors changed status"), sent)
+		for _, name := range []string{"API", "Web", "Worker"} {
+			assert.Contains(t, sent, "🔴 "+name+": DOWN - connection refused")
+		}
+		assert.Less(t, strings.Index(sent, "API"), strings.Index(sent, "Worker"))
+	})
+
+	t.Run("a window of recoveries is sent with a recovery heartbeat", func(t *testing.T) {
+		config := `{"grouping":{"window":60,"threshold":2}}`
+		l, provider, tasks := setup(config)
+
+		for i, id := range monitorIDs {
+			l.handleNotifyEvent(events.Event{Type: events.ImportantHeartbeat, Payload: heartbeatOf(id, shared.MonitorStatusUp, "200 OK", start.Add(time.Duration(i)*time.Second))})
+		}
+
+		provider.On("Send", mock.Anything, config, mock.Anything, isMonitor("mon-3"), mock.MatchedBy(func(h *heartbeat.Model) bool {
+			return h != nil && h.Status == shared.MonitorStatusUp
+		})).Return(nil).Once()
+		flush(t, l, tasks.enqueued()[0])
+
+		provider.AssertExpectations(t)
+	})
+
+	t.Run("changes up to the threshold are sent one by one", func(t *testing.T) {
+		config := `{"grouping":{"window":60,"threshold":2}}`
+		l, provider, tasks := setup(config)
+
+		for i := range monitorIDs[:2] {
+			l.handleNotifyEvent(events.Event{Type: events.ImportantHeartbeat, Payload: downOf(i)})
+		}
+		enqueued := tasks.enqueued()
+		require.Len(t, enqueued, 1)
+
+		provider.On("Send", mock.Anything, config, "connection refused", isMonitor("mon-1"), mock.Anything).Return(nil).Once()
+		provider.On("Send", mock.Anything, config, "connection refused", isMonitor("mon-2"), mock.Anything).Return(nil).Once()
+		flush(t, l, enqueued[0])
+
+		provider.AssertExpectations(t)
+	})
+
+	t.Run("a change received by several instances is grouped once", func(t *testing.T) {
+		config := `{"grouping":{"window":60,"threshold":1}}`
+		l, provider, tasks := setup(config)
+
+		l.handleNotifyEvent(events.Event{Type: events.ImportantHeartbeat, Payload: downOf(0)})
+		l.handleNotifyEvent(events.Event{Type: events.ImportantHeartbeat, Payload: downOf(0)})
+
+		enqueued := tasks.enqueued()
+		require.Len(t, enqueued, 1)
+		provider.On("Send", mock.Anything, config, "connection refused", isMonitor("mon-1"), mock.Anything).Return(nil).Once()
+		flush(t, l, enqueued[0])
+
+		provider.AssertExpectations(t)
+	})
+
+	t.Run("a new window opens after a flush", func(t *testing.T) {
+		config := `{"grouping":{"window":60,"threshold":2}}`
+		l, provider, tasks := setup(config)
+		provider.On("Send", mock.Anything, config, "connection refused", mock.Anything, mock.Anything).Return(nil)
+
+		l.handleNotifyEvent(events.Event{Type: events.ImportantHeartbeat, Payload: downOf(0)})
+		flush(t, l, tasks.enqueued()[0])
+		l.handleNotifyEvent(events.Event{Type: events.ImportantHeartbeat, Payload: downOf(1)})
+
+		require.Len(t, tasks.enqueued(), 2)
+		provider.AssertNumberOfCalls(t, "Send", 1)
+	})
+}
@@ -10,9 +10,17 @@ import (
 	"peekaping/internal/modules/heartbeat"
 	"peekaping/internal/modules/monitor"
 	"peekaping/internal/modules/monitor_notification"
+	"peekaping/internal/modules/monitor_tag"
 	"peekaping/internal/modules/notification_channel/providers"
+	"peekaping/internal/modules/notification_sent_history"
+	"peekaping/internal/modules/queue"
+	"peekaping/internal/modules/shared"
+	"peekaping/internal/modules/tag"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/redis/go-redis/v9"
 	"go.uber.org/dig"
 	"go.uber.org/zap"
 )
@@ -23,7 +31,21 @@ type NotificationEventListener struct {
 	monitorSvc                 monitor.Service
 	heartbeatService           heartbeat.Service
 	monitorNotificationService monitor_notification.Service
-	logger                     *zap.SugaredLogger
+	notificationHistoryService notification_sent_history.Service
+	settingService             shared.SettingService
+	monitorTagService          monitor_tag.Service
+	tagService                 tag.Service
+	testMode                   bool
+	// location is the server timezone, used for business hours without a timezone
+	location *time.Location
+	logger   *zap.SugaredLogger
+
+	// queueService schedules the notifications waiting for their channel's business hours
+	// and the closing of grouping windows. The status changes of grouping windows and
+	// digests are kept in redis.
+	queueService queue.Service
+	redis        *redis.Client
+	now          func() time.Time
 }
 
 type NotificationEventListenerParams struct {
@@ -32,39 +54,61 @@ type NotificationEventListenerParams struct {
 	MonitorSvc                 monitor.Service
 	HeartbeatService           heartbeat.Service
 	MonitorNotificationService monitor_notification.Service
+	NotificationHistoryService notification_sent_history.Service
+	SettingService             shared.SettingService
+	MonitorTagService          monitor_tag.Service
+	TagService                 tag.Service
+	QueueService               queue.Service
+	Redis                      *redis.Client
+	Metrics                    *NotificationMetrics `optional:"true"`
 	Logger                     *zap.SugaredLogger
 	Config                     *config.Config
 }
 
 func NewNotificationEventListener(p NotificationEventListenerParams) *NotificationEventListener {
-	RegisterNotificationChannelProvider("smtp", providers.NewEmailSender(p.Logger))
-	RegisterNotificationChannelProvider("telegram", providers.NewTelegramSender(p.Logger))
-	RegisterNotificationChannelProvider("webhook", providers.NewWebhookSender(p.Logger))
-	RegisterNotificationChannelProvider("slack", providers.NewSlackSender(p.Logger, p.Config))
-	RegisterNotificationChannelProvider("ntfy", providers.NewNTFYSender(p.Logger))
-	RegisterNotificationChannelProvider("pagerduty", providers.NewPagerDutySender(p.Logger, p.Config))
-	RegisterNotificationChannelProvider("opsgenie", providers.NewOpsgenieSender(p.Logger))
-	RegisterNotificationChannelProvider("google_chat", providers.NewGoogleChatSender(p.Logger, p.Config))
-	RegisterNotificationChannelProvider("grafana_oncall", providers.NewGrafanaOncallSender(p.Logger))
-	RegisterNotificationChannelProvider("signal", providers.NewSignalSender(p.Logger))
-	RegisterNotificationChannelProvider("gotify", providers.NewGotifySender(p.Logger))
-	RegisterNotificationChannelProvider("pushover", providers.NewPushoverSender(p.Logger))
-	RegisterNotificationChannelProvider("mattermost", providers.NewMattermostSender(p.Logger))
-	RegisterNotificationChannelProvider("matrix", providers.NewMatrixSender(p.Logger))
-	RegisterNotificationChannelProvider("discord", providers.NewDiscordSender(p.Logger))
-	RegisterNotificationChannelProvider("wecom", providers.NewWeComSender(p.Logger))
-	RegisterNotificationChannelProvider("whatsapp", providers.NewWhatsAppSender(p.Logger))
-	RegisterNotificationChannelProvider("twilio", providers.NewTwilioSender(p.Logger))
-	RegisterNotificationChannelProvider("sendgrid", providers.NewSendGridSender(p.Logger))
-	RegisterNotificationChannelProvider("pushbullet", providers.NewPushbulletSender(p.Logger))
-	RegisterNotificationChannelProvider("pagertree", providers.NewPagerTreeSender(p.Logger))
-	RegisterNotificationChannelProvider("line", providers.NewLineSender(p.Logger))
+	register := func(name string, provider NotificationChannelProvider) {
+		RegisterNotificationChannelProvider(name, p.Metrics.Instrument(name, provider))
+	}
+	register("smtp", providers.NewEmailSender(p.Logger))
+	register("telegram", providers.NewTelegramSender(p.Logger))
+	register("webhook", providers.NewWebhookSender(p.Logger))
+	register("slack", providers.NewSlackSender(p.Logger, p.Config))
+	register("ntfy", providers.NewNTFYSender(p.Logger))
+	register("pagerduty", providers.NewPagerDutySender(p.Logger, p.Config))
+	register("opsgenie", providers.NewOpsgenieSender(p.Logger))
+	register("google_chat", providers.NewGoogleChatSender(p.Logger, p.Config))
+	register("teams_workflow", providers.NewTeamsWorkflowSender(p.Logger, p.Config))
+	register("teams", providers.NewTeamsSender(p.Logger, p.Config))
+	register("grafana_oncall", providers.NewGrafanaOncallSender(p.Logger))
+	register("signal", providers.NewSignalSender(p.Logger))
+	register("gotify", providers.NewGotifySender(p.Logger))
+	register("pushover", providers.NewPushoverSender(p.Logger))
+	register("mattermost", providers.NewMattermostSender(p.Logger))
+	register("matrix", providers.NewMatrixSender(p.Logger))
+	register("matrix_webhook", providers.NewMatrixWebhookSender(p.Logger))
+	register("discord", providers.NewDiscordSender(p.Logger))
+	register("wecom", providers.NewWeComSender(p.Logger))
+	register("whatsapp", providers.NewWhatsAppSender(p.Logger))
+	register("twilio", providers.NewTwilioSender(p.Logger))
+	register("sendgrid", providers.NewSendGridSender(p.Logger))
+	register("pushbullet", providers.NewPushbulletSender(p.Logger))
+	register("pagertree", providers.NewPagerTreeSender(p.Logger))
+	register("line", providers.NewLineSender(p.Logger))
+	register("grpc_notifier", providers.NewGRPCNotifierSender(p.Logger))
 
 	return &NotificationEventListener{
 		service:                    p.Service,
 		monitorSvc:                 p.MonitorSvc,
 		heartbeatService:           p.HeartbeatService,
 		monitorNotificationService: p.MonitorNotificationService,
+		notificationHistoryService: p.NotificationHistoryService,
+		settingService:             p.SettingService,
+		monitorTagService:          p.MonitorTagService,
+		tagService:                 p.TagService,
+		queueService:               p.QueueService,
+		redis:                      p.Redis,
+		testMode:                   p.Config.NotificationTestMode,
+		location:                   config.Location(),
 		logger:                     p.Logger,
 	}
 }
@@ -72,21 +116,66 @@ func NewNotificationEventListener(p NotificationEventListenerParams) *Notificati
 // Subscribe subscribes to NotifyEvent and sends notifications
 func (l *NotificationEventListener) Subscribe(eventBus events.EventBus) {
 	eventBus.Subscribe(events.ImportantHeartbeat, l.handleNotifyEvent)
+	eventBus.Subscribe(events.HeartbeatEvent, l.handleHeartbeatEvent)
 	eventBus.Subscribe(events.CertificateExpiry, l.handleCertificateExpiryEvent)
+	eventBus.Subscribe(events.CertificateIssuerChanged, l.handleCertificateIssuerChangeEvent)
+	eventBus.Subscribe(events.HighLatency, l.handleHighLatencyEvent)
+	eventBus.Subscribe(events.ProxyHealthChanged, l.handleProxyHealthEvent)
+	eventBus.Subscribe(events.MonitorLifecycle, l.handleMonitorLifecycleEvent)
 }
 
 func (l *NotificationEventListener) handleNotifyEvent(event events.Event) {
-	ctx := context.Background()
+	hb, ok := infra.UnmarshalEventPayload[heartbeat.Model](event)
+	if !ok {
+		l.logger.Errorf("Failed to unmarshal heartbeat event payload")
+		return
+	}
+
+	l.notifyHeartbeat(hb, false)
+}
 
+// handleHeartbeatEvent passes the failures of an ongoing outage that were not notified to
+// the channels waiting for a number of consecutive failures
+func (l *NotificationEventListener) handleHeartbeatEvent(event events.Event) {
 	hb, ok := infra.UnmarshalEventPayload[heartbeat.Model](event)
 	if !ok {
 		l.logger.Errorf("Failed to unmarshal heartbeat event payload")
 		return
 	}
 
+	// Notified heartbeats arrive as important heartbeats as well
+	if hb.Status != shared.MonitorStatusDown || hb.Notified || hb.Important {
+		return
+	}
+
+	// Most monitors have no channel waiting for consecutive failures, their failures
+	// stop here instead of going through the whole notification path
+	if !l.waitsForFailures(context.Background(), hb.MonitorID) {
+		return
+	}
+
+	l.notifyHeartbeat(hb, true)
+}
+
+// notifyHeartbeat sends the notifications of a heartbeat to the channels of its monitor.
+// continuedOutage is set for failures after the first one of an outage, see
+// belowFailureThreshold.
+func (l *NotificationEventListener) notifyHeartbeat(hb *heartbeat.Model, continuedOutage bool) {
+	ctx := context.Background()
+
 	monitorID := hb.MonitorID
 
-	l.logger.Infof("Notification event received for monitor: %s", monitorID)
+	// Every failure of an outage comes through here, only log what is notified
+	if !continuedOutage {
+		l.logger.Infof("Notification event received for monitor: %s", monitorID)
+	}
+
+	// Checks keep running in maintenance windows with the global toggle or
+	// auto_end_on_recovery, the producer flags them so their alerts are skipped
+	if hb.AlertsSuppressed {
+		l.logger.Infof("Skipping notification for monitor %s: check time %s falls within a maintenance window", monitorID, hb.Time)
+		return
+	}
 
 	// Get monitor-notification records
 	monitorNotifications, err := l.monitorNotificationService.FindByMonitorID(ctx, monitorID)
@@ -95,8 +184,39 @@ func (l *NotificationEventListener) handleNotifyEvent(event events.Event) {
 		return
 	}
 
+	// A recovery ends the outage even for channels that aren't notified of it
+	l.resetOutageNotifications(ctx, monitorNotifications, hb)
+
+	// Fetch monitor details for context
+	monitorModel, err := l.monitorSvc.FindByID(ctx, monitorID)
+	if err != nil || monitorModel == nil {
+		l.logger.Warn("Monitor not found for notification context")
+		return
+	}
+
+	eventType := shared.HeartbeatNotificationEvent(hb.Status, hb.ErrorCategory)
+
+	// Tags are only fetched once a channel is notified
+	tags := sync.OnceValue(func() []string { return l.monitorTagNames(ctx, monitorID) })
+
 	var notificationChannels []*Model
+	// digestOnly holds the channels the monitor only reaches through their digest,
+	// escalateAfter the failures in a row the monitor escalates to a channel after
+	// and links the monitor-notification of every channel
+	digestOnly := make(map[string]bool)
+	escalateAfter := make(map[string]int)
+	links := make(map[string]*monitor_notification.Model)
 	for _, mn := range monitorNotifications {
+		if l.isBelowMinCriticality(monitorModel, mn) || l.isEventTypeDisabled(mn, eventType) {
+			continue
+		}
+		links[mn.NotificationID] = mn
+		if mn.DigestOnly {
+			digestOnly[mn.NotificationID] = true
+		}
+		if mn.EscalateAfter > 0 {
+			escalateAfter[mn.NotificationID] = mn.EscalateAfter
+		}
 		l.logger.Infof("Monitor notification: %s", mn.NotificationID)
 		notification, err := l.service.FindByID(ctx, mn.NotificationID)
 		if err != nil {
@@ -110,36 +230,119 @@ func (l *NotificationEventListener) handleNotifyEvent(event events.Event) {
 		}
 	}
 
-	// Fetch monitor details for context
-	monitorModel, err := l.monitorSvc.FindByID(ctx, monitorID)
-	if err != nil || monitorModel == nil {
-		l.logger.Warn("Monitor not found for notification context")
-		return
-	}
-
 	for _, notificationChannel := range notificationChannels {
-		integration, ok := GetNotificationChannelProvider(notificationChannel.Type)
+		integration, options, ok := l.channelProvider(notificationChannel)
 		if !ok {
-			l.logger.Warnf("No integration registered for notification type: %s", notificationChannel.Type)
 			continue
 		}
-		if notificationChannel.Config == nil {
-			l.logger.Warnf("No config for notification: %s", notificationChannel.Name)
+
+		threshold := max(options.NotifyAfterFailures, escalateAfter[notificationChannel.ID])
+		if l.belowFailureThreshold(ctx, threshold, hb, continuedOutage) {
+			l.logger.Debugf("Skipping notification %s for monitor %s: below %d consecutive failures", notificationChannel.Name, monitorID, threshold)
 			continue
 		}
 
-		// validate config
-		if err := integration.Validate(*notificationChannel.Config); err != nil {
-			l.logger.Errorf("Failed to validate notification config: %s, error: %v", notificationChannel.Name, err)
+		if l.overNotificationLimit(ctx, links[notificationChannel.ID], hb) {
+			l.logger.Debugf("Skipping notification %s for monitor %s: the outage reached the notification limit", notificationChannel.Name, monitorID)
 			continue
 		}
 
-		err := integration.Send(ctx, *notificationChannel.Config, hb.Msg, monitorModel, hb)
-		if err != nil {
-			l.logger.Errorf("Failed to send notification: %s, error: %v", notificationChannel.Name, err)
-		} else {
-			l.logger.Infof("Notification sent to: %s for monitor: %s", notificationChannel.Name, monitorID)
+		if digestOnly[notificationChannel.ID] && l.collectDigest(notificationChannel, options, monitorModel, hb) {
+			continue
 		}
+
+		message := l.buildHeartbeatMessage(ctx, options, monitorModel, hb, tags())
+		if escalateAfter[notificationChannel.ID] > 1 {
+			message = escalationNote(hb, escalateAfter[notificationChannel.ID]) + message
+		}
+
+		// Test mode records every notification, grouped ones included
+		if !l.testMode && !options.TestMode && l.groupNotification(notificationChannel, options, message, monitorModel, hb) {
+			continue
+		}
+
+		l.send(ctx, integration, notificationChannel, options, "heartbeat", eventType, message, monitorModel, hb)
+	}
+}
+
+// isBelowMinCriticality reports whether the channel of a monitor-notification record only
+// wants notifications of monitors more critical than this one
+func (l *NotificationEventListener) isBelowMinCriticality(monitorModel *monitor.Model, mn *monitor_notification.Model) bool {
+	if shared.MeetsMinCriticality(monitorModel.Criticality, mn.MinCriticality) {
+		return false
+	}
+	l.logger.Debugf("Skipping notification %s for monitor %s: criticality %q is below %q", mn.NotificationID, monitorModel.ID, monitorModel.Criticality, mn.MinCriticality)
+	return true
+}
+
+// isEventTypeDisabled reports whether the monitor-notification record is limited to other
+// event types than eventType
+func (l *NotificationEventListener) isEventTypeDisabled(mn *monitor_notification.Model, eventType string) bool {
+	if shared.NotificationEventEnabled(mn.EventTypes, eventType) {
+		return false
+	}
+	l.logger.Debugf("Skipping notification %s for monitor %s: %q events are disabled", mn.NotificationID, mn.MonitorID, eventType)
+	return true
+}
+
+// channelProvider gets the provider of a channel and parses its options. It reports
+// false when nothing can be sent through the channel because its type is unknown or its
+// config is missing or invalid.
+func (l *NotificationEventListener) channelProvider(notificationChannel *Model) (NotificationChannelProvider, ChannelOptions, bool) {
+	integration, ok := GetNotificationChannelProvider(notificationChannel.Type)
+	if !ok {
+		l.logger.Warnf("No integration registered for notification type: %s", notificationChannel.Type)
+		return nil, ChannelOptions{}, false
+	}
+	if notificationChannel.Config == nil {
+		l.logger.Warnf("No config for notification: %s", notificationChannel.Name)
+		return nil, ChannelOptions{}, false
+	}
+
+	if err := integration.Validate(*notificationChannel.Config); err != nil {
+		l.logger.Errorf("Failed to validate notification config: %s, error: %v", notificationChannel.Name, err)
+		return nil, ChannelOptions{}, false
+	}
+
+	return integration, l.parseChannelOptions(*notificationChannel.Config), true
+}
+
+// deliver sends a notification through a channel, see send. kind names the notification
+// in the logs and key identifies it while it is held off.
+func (l *NotificationEventListener) deliver(ctx context.Context, notificationChannel *Model, kind string, key string, message string, monitorModel *monitor.Model, hb *heartbeat.Model) {
+	integration, options, ok := l.channelProvider(notificationChannel)
+	if !ok {
+		return
+	}
+	l.send(ctx, integration, notificationChannel, options, kind, key, message, monitorModel, hb)
+}
+
+// send records the notification instead of sending it in test mode and holds it off
+// outside the channel's business hours, otherwise it is sent through the provider
+func (l *NotificationEventListener) send(ctx context.Context, integration NotificationChannelProvider, notificationChannel *Model, options ChannelOptions, kind string, key string, message string, monitorModel *monitor.Model, hb *heartbeat.Model) {
+	if l.testMode || options.TestMode {
+		l.recordTestModeNotification(ctx, notificationChannel, monitorModel.ID, message)
+		return
+	}
+
+	if l.holdOffHours(notificationChannel, options, key, message, monitorModel, hb) {
+		return
+	}
+
+	if err := integration.Send(ctx, *notificationChannel.Config, message, monitorModel, hb); err != nil {
+		l.logger.Errorf("Failed to send %s notification: %s, error: %v", kind, notificationChannel.Name, err)
+	} else {
+		l.logger.Infof("Sent %s notification to: %s for monitor: %s", kind, notificationChannel.Name, monitorModel.ID)
+	}
+}
+
+// recordTestModeNotification logs the rendered message in place of sending it and records
+// it in the notification history so routing can be validated without reaching providers
+func (l *NotificationEventListener) recordTestModeNotification(ctx context.Context, notificationChannel *Model, monitorID string, message string) {
+	l.logger.Infof("Test mode: notification to %s (%s) for monitor %s not sent, message: %s", notificationChannel.Name, notificationChannel.Type, monitorID, message)
+
+	if err := l.notificationHistoryService.RecordTestModeNotification(ctx, notificationChannel.ID, monitorID, message); err != nil {
+		l.logger.Errorf("Failed to record test mode notification for monitor %s: %v", monitorID, err)
 	}
 }
 
@@ -166,9 +369,19 @@ func (l *NotificationEventListener) handleCertificateExpiryEvent(event events.Ev
 		return
 	}
 
+	// Fetch monitor details for context
+	monitorModel, err := l.monitorSvc.FindByID(ctx, certEvent.MonitorID)
+	if err != nil || monitorModel == nil {
+		l.logger.Warn("Monitor not found for certificate expiry notification context")
+		return
+	}
+
 	// Get notification channels
 	var notificationChannels []*Model
 	for _, mn := range monitorNotifications {
+		if l.isBelowMinCriticality(monitorModel, mn) || l.isEventTypeDisabled(mn, shared.NotificationEventCertExpiry) {
+			continue
+		}
 		l.logger.Infof("Monitor notification: %s", mn.NotificationID)
 		notification, err := l.service.FindByID(ctx, mn.NotificationID)
 		if err != nil {
@@ -182,41 +395,13 @@ func (l *NotificationEventListener) handleCertificateExpiryEvent(event events.Ev
 		}
 	}
 
-	// Fetch monitor details for context
-	monitorModel, err := l.monitorSvc.FindByID(ctx, certEvent.MonitorID)
-	if err != nil || monitorModel == nil {
-		l.logger.Warn("Monitor not found for certificate expiry notification context")
-		return
-	}
-
 	// Send notifications through all configured channels
-	for _, notificationChannel := range notificationChannels {
-		integration, ok := GetNotificationChannelProvider(notificationChannel.Type)
-		if !ok {
-			l.logger.Warnf("No integration registered for notification type: %s", notificationChannel.Type)
-			continue
-		}
-		if notificationChannel.Config == nil {
-			l.logger.Warnf("No config for notification: %s", notificationChannel.Name)
-			continue
-		}
-
-		// Validate config
-		if err := integration.Validate(*notificationChannel.Config); err != nil {
-			l.logger.Errorf("Failed to validate notification config: %s, error: %v", notificationChannel.Name, err)
-			continue
-		}
+	// Create a formatted message for certificate expiry
+	message := l.formatCertificateExpiryMessage(certEvent, monitorModel)
 
-		// Create a formatted message for certificate expiry
-		message := l.formatCertificateExpiryMessage(certEvent, monitorModel)
-
-		// Send notification (we pass nil for heartbeat since this is a certificate expiry notification)
-		err := integration.Send(ctx, *notificationChannel.Config, message, monitorModel, nil)
-		if err != nil {
-			l.logger.Errorf("Failed to send certificate expiry notification: %s, error: %v", notificationChannel.Name, err)
-		} else {
-			l.logger.Infof("Certificate expiry notification sent to: %s for monitor: %s", notificationChannel.Name, certEvent.MonitorID)
-		}
+	for _, notificationChannel := range notificationChannels {
+		// We pass nil for heartbeat since this is a certificate expiry notification
+		l.deliver(ctx, notificationChannel, "certificate expiry", shared.NotificationEventCertExpiry, message, monitorModel, nil)
 	}
 }
 
@@ -252,6 +437,139 @@ func (l *NotificationEventListener) formatCertificateExpiryMessage(certEvent *ce
 	return message
 }
 
+func (l *NotificationEventListener) handleCertificateIssuerChangeEvent(event events.Event) {
+	ctx := context.Background()
+
+	issuerEvent, ok := infra.UnmarshalEventPayload[certificate.CertificateIssuerChangeEvent](event)
+	if !ok || issuerEvent.CertInfo == nil {
+		l.logger.Errorf("Failed to unmarshal certificate issuer change event payload")
+		return
+	}
+
+	l.logger.Infof("Certificate issuer change event received for monitor: %s", issuerEvent.MonitorID)
+
+	monitorNotifications, err := l.monitorNotificationService.FindByMonitorID(ctx, issuerEvent.MonitorID)
+	if err != nil {
+		l.logger.Errorf("Failed to get monitor-notification records: %v", err)
+		return
+	}
+
+	if len(monitorNotifications) == 0 {
+		l.logger.Debugf("No notification channels configured for monitor %s", issuerEvent.MonitorID)
+		return
+	}
+
+	monitorModel, err := l.monitorSvc.FindByID(ctx, issuerEvent.MonitorID)
+	if err != nil || monitorModel == nil {
+		l.logger.Warn("Monitor not found for certificate issuer change notification context")
+		return
+	}
+
+	message := formatCertificateIssuerChangeMessage(issuerEvent, monitorModel)
+
+	for _, mn := range monitorNotifications {
+		if l.isBelowMinCriticality(monitorModel, mn) || l.isEventTypeDisabled(mn, shared.NotificationEventCertIssuer) {
+			continue
+		}
+
+		notificationChannel, err := l.service.FindByID(ctx, mn.NotificationID)
+		if err != nil || notificationChannel == nil {
+			l.logger.Errorf("Failed to get notification by ID: %s, error: %v", mn.NotificationID, err)
+			continue
+		}
+
+		l.deliver(ctx, notificationChannel, "certificate issuer change", shared.NotificationEventCertIssuer, message, monitorModel, nil)
+	}
+}
+
+// formatCertificateIssuerChangeMessage creates the message for certificate issuer change notifications
+func formatCertificateIssuerChangeMessage(issuerEvent *certificate.CertificateIssuerChangeEvent, monitor *monitor.Model) string {
+	return fmt.Sprintf(
+		"🔏 Certificate Issuer Changed\n\n"+
+			"Monitor: %s\n"+
+			"%s\n"+
+			"Certificate: %s\n"+
+			"Fingerprint (SHA-256): %s",
+		monitor.Name,
+		certificate.IssuerChangeMessage(issuerEvent.PreviousIssuer, issuerEvent.CertInfo.Issuer),
+		extractCommonName(issuerEvent.CertInfo.Subject),
+		issuerEvent.CertInfo.Fingerprint256,
+	)
+}
+
+func (l *NotificationEventListener) handleHighLatencyEvent(event events.Event) {
+	ctx := context.Background()
+
+	latencyEvent, ok := infra.UnmarshalEventPayload[events.HighLatencyPayload](event)
+	if !ok {
+		l.logger.Errorf("Failed to unmarshal high latency event payload")
+		return
+	}
+
+	l.logger.Infof("High latency event received for monitor: %s, recovered: %t", latencyEvent.MonitorID, latencyEvent.Recovered)
+
+	monitorNotifications, err := l.monitorNotificationService.FindByMonitorID(ctx, latencyEvent.MonitorID)
+	if err != nil {
+		l.logger.Errorf("Failed to get monitor-notification records: %v", err)
+		return
+	}
+
+	if len(monitorNotifications) == 0 {
+		l.logger.Debugf("No notification channels configured for monitor %s", latencyEvent.MonitorID)
+		return
+	}
+
+	monitorModel, err := l.monitorSvc.FindByID(ctx, latencyEvent.MonitorID)
+	if err != nil || monitorModel == nil {
+		l.logger.Warn("Monitor not found for high latency notification context")
+		return
+	}
+
+	message := formatHighLatencyMessage(latencyEvent)
+
+	for _, mn := range monitorNotifications {
+		if l.isBelowMinCriticality(monitorModel, mn) || l.isEventTypeDisabled(mn, shared.NotificationEventHighLatency) {
+			continue
+		}
+
+		notificationChannel, err := l.service.FindByID(ctx, mn.NotificationID)
+		if err != nil || notificationChannel == nil {
+			l.logger.Errorf("Failed to get notification by ID: %s, error: %v", mn.NotificationID, err)
+			continue
+		}
+
+		// No heartbeat is attached, the monitor is still up
+		l.deliver(ctx, notificationChannel, "high latency", shared.NotificationEventHighLatency, message, monitorModel, nil)
+	}
+}
+
+// formatHighLatencyMessage creates the message for sustained high latency notifications
+// and for their recovery
+func formatHighLatencyMessage(latencyEvent *events.HighLatencyPayload) string {
+	if latencyEvent.Recovered {
+		return fmt.Sprintf(
+			"✅ Performance Recovered\n\n"+
+				"Monitor: %s\n"+
+				"Average response time: %d ms over the last %d checks\n"+
+				"Latency limit: %d ms",
+			latencyEvent.MonitorName,
+			latencyEvent.AvgPingMs,
+			latencyEvent.Checks,
+			latencyEvent.LimitMs,
+		)
+	}
+	return fmt.Sprintf(
+		"🐢 Performance Degraded\n\n"+
+			"Monitor: %s\n"+
+			"Average response time: %d ms over the last %d checks\n"+
+			"Latency limit: %d ms",
+		latencyEvent.MonitorName,
+		latencyEvent.AvgPingMs,
+		latencyEvent.Checks,
+		latencyEvent.LimitMs,
+	)
+}
+
 // extractCommonName extracts the common name from a certificate subject string
 func extractCommonName(subject string) string {
 	// Simple extraction - in a real implementation you might want to use proper DN parsing
@@ -264,3 +582,165 @@ func extractCommonName(subject string) string {
 	}
 	return subject
 }
+
+// handleProxyHealthEvent alerts the channels of the active monitors checked through the
+// proxy. Each channel is notified once, with the first of its monitors as context.
+func (l *NotificationEventListener) handleProxyHealthEvent(event events.Event) {
+	ctx := context.Background()
+
+	proxyEvent, ok := infra.UnmarshalEventPayload[events.ProxyHealthPayload](event)
+	if !ok {
+		l.logger.Errorf("Failed to unmarshal proxy health event payload")
+		return
+	}
+
+	l.logger.Infof("Proxy health event received for proxy: %s, healthy: %t", proxyEvent.ProxyID, proxyEvent.Healthy)
+
+	monitors, err := l.monitorSvc.FindByProxyId(ctx, proxyEvent.ProxyID)
+	if err != nil {
+		l.logger.Errorf("Failed to get monitors of proxy %s: %v", proxyEvent.ProxyID, err)
+		return
+	}
+
+	var affected []*monitor.Model
+	for _, m := range monitors {
+		if m.Active {
+			affected = append(affected, m)
+		}
+	}
+	if len(affected) == 0 {
+		l.logger.Debugf("No active monitors use proxy %s", proxyEvent.ProxyID)
+		return
+	}
+
+	message := formatProxyHealthMessage(proxyEvent, affected)
+
+	// A proxy outage takes its monitors down, so channels filter it like a monitor event
+	eventType := shared.NotificationEventDown
+	if proxyEvent.Healthy {
+		eventType = shared.NotificationEventUp
+	}
+
+	notified := make(map[string]bool)
+	for _, monitorModel := range affected {
+		monitorNotifications, err := l.monitorNotificationService.FindByMonitorID(ctx, monitorModel.ID)
+		if err != nil {
+			l.logger.Errorf("Failed to get monitor-notification records: %v", err)
+			continue
+		}
+
+		for _, mn := range monitorNotifications {
+			if notified[mn.NotificationID] || l.isBelowMinCriticality(monitorModel, mn) || l.isEventTypeDisabled(mn, eventType) {
+				continue
+			}
+			notified[mn.NotificationID] = true
+
+			notificationChannel, err := l.service.FindByID(ctx, mn.NotificationID)
+			if err != nil || notificationChannel == nil {
+				l.logger.Errorf("Failed to get notification by ID: %s, error: %v", mn.NotificationID, err)
+				continue
+			}
+
+			l.deliver(ctx, notificationChannel, "proxy health", "proxy/"+proxyEvent.ProxyID, message, monitorModel, nil)
+		}
+	}
+}
+
+// formatProxyHealthMessage creates the message for proxy down and recovery notifications
+func formatProxyHealthMessage(proxyEvent *events.ProxyHealthPayload, affected []*monitor.Model) string {
+	names := make([]string, 0, len(affected))
+	for _, m := range affected {
+		names = append(names, m.Name)
+	}
+
+	address := fmt.Sprintf("%s:%d", proxyEvent.Host, proxyEvent.Port)
+	if !proxyEvent.Healthy {
+		return fmt.Sprintf(
+			"🔌 Proxy Down\n\n"+
+				"Proxy: %s\n"+
+				"Error: %s\n"+
+				"Affected monitors: %s\n\n"+
+				"Failures of these monitors are caused by the proxy, not their targets.",
+			address, proxyEvent.Message, strings.Join(names, ", "),
+		)
+	}
+	return fmt.Sprintf(
+		"✅ Proxy Up\n\n"+
+			"Proxy: %s is reachable again\n"+
+			"Affected monitors: %s",
+		address, strings.Join(names, ", "),
+	)
+}
+
+// handleMonitorLifecycleEvent tells the admin notification channel that a monitor was
+// created or deleted. Nothing is sent unless AdminNotificationChannelSettingKey is set.
+func (l *NotificationEventListener) handleMonitorLifecycleEvent(event events.Event) {
+	ctx := context.Background()
+
+	lifecycleEvent, ok := infra.UnmarshalEventPayload[events.MonitorLifecyclePayload](event)
+	if !ok {
+		l.logger.Errorf("Failed to unmarshal monitor lifecycle event payload")
+		return
+	}
+
+	if l.settingService == nil {
+		return
+	}
+	setting, err := l.settingService.GetByKey(ctx, AdminNotificationChannelSettingKey)
+	if err != nil {
+		l.logger.Errorf("Failed to read admin notification channel setting: %v", err)
+		return
+	}
+	if setting == nil || strings.TrimSpace(setting.Value) == "" {
+		return
+	}
+	channelID := strings.TrimSpace(setting.Value)
+
+	notificationChannel, err := l.service.FindByID(ctx, channelID)
+	if err != nil || notificationChannel == nil {
+		l.logger.Errorf("Failed to get admin notification channel by ID: %s, error: %v", channelID, err)
+		return
+	}
+
+	message := formatMonitorLifecycleMessage(lifecycleEvent)
+
+	// A deleted monitor can't be loaded anymore, providers get what the event carries
+	monitorModel := &monitor.Model{
+		ID:   lifecycleEvent.MonitorID,
+		Name: lifecycleEvent.MonitorName,
+		Type: lifecycleEvent.MonitorType,
+	}
+
+	l.deliver(ctx, notificationChannel, "monitor "+lifecycleEvent.Action, "lifecycle/"+lifecycleEvent.Action, message, monitorModel, nil)
+}
+
+// formatMonitorLifecycleMessage creates the message for monitor created and deleted
+// notifications
+func formatMonitorLifecycleMessage(lifecycleEvent *events.MonitorLifecyclePayload) string {
+	title := "➕ Monitor Created"
+	if lifecycleEvent.Action == events.MonitorActionDeleted {
+		title = "🗑️ Monitor Deleted"
+	}
+
+	actor := "system"
+	switch lifecycleEvent.ActorType {
+	case "user":
+		actor = "user " + lifecycleEvent.ActorID
+	case "api_key":
+		actor = "API key " + lifecycleEvent.ActorID
+	}
+
+	return fmt.Sprintf(
+		"%s\n\n"+
+			"Monitor: %s (%s)\n"+
+			"ID: %s\n"+
+			"Changed by: %s\n"+
+			"Time: %s",
+		title,
+		lifecycleEvent.MonitorName,
+		lifecycleEvent.MonitorType,
+		lifecycleEvent.MonitorID,
+		actor,
+		lifecycleEvent.Time.Format(time.RFC3339),
+	)
+}
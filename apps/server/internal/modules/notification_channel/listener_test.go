@@ -0,0 +1,580 @@
+package notification_channel
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"peekaping/internal/modules/events"
+	"peekaping/internal/modules/heartbeat"
+	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/monitor_notification"
+	"peekaping/internal/modules/notification_sent_history"
+	"peekaping/internal/modules/shared"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// MockMonitorService implements monitor.Service interface for testing
+type MockMonitorService struct {
+	mock.Mock
+}
+
+func (m *MockMonitorService) Create(ctx context.Context, monitor *monitor.CreateUpdateDto) (*shared.Monitor, error) {
+	args := m.Called(ctx, monitor)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*shared.Monitor), args.Error(1)
+}
+
+func (m *MockMonitorService) FindByID(ctx context.Context, id string) (*shared.Monitor, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*shared.Monitor), args.Error(1)
+}
+
+func (m *MockMonitorService) FindByIDs(ctx context.Context, ids []string) ([]*shared.Monitor, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*shared.Monitor), args.Error(1)
+}
+
+func (m *MockMonitorService) FindAll(ctx context.Context, page int, limit int, q string, active *bool, status *int, tagIds []string) ([]*shared.Monitor, error) {
+	args := m.Called(ctx, page, limit, q, active, status, tagIds)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*shared.Monitor), args.Error(1)
+}
+
+func (m *MockMonitorService) FindActive(ctx context.Context) ([]*shared.Monitor, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*shared.Monitor), args.Error(1)
+}
+
+func (m *MockMonitorService) UpdateFull(ctx context.Context, id string, monitor *monitor.CreateUpdateDto) (*shared.Monitor, error) {
+	args := m.Called(ctx, id, monitor)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*shared.Monitor), args.Error(1)
+}
+
+func (m *MockMonitorService) UpdatePartial(ctx context.Context, id string, monitor *monitor.PartialUpdateDto, noPublish bool) (*shared.Monitor, error) {
+	args := m.Called(ctx, id, monitor, noPublish)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*shared.Monitor), args.Error(1)
+}
+
+func (m *MockMonitorService) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockMonitorService) ValidateMonitorConfig(monitorType string, configJSON string) error {
+	args := m.Called(monitorType, configJSON)
+	return args.Error(0)
+}
+
+func (m *MockMonitorService) GetHeartbeats(ctx context.Context, id string, limit, page int, important *bool, reverse bool) ([]*heartbeat.Model, error) {
+	args := m.Called(ctx, id, limit, page, important, reverse)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*heartbeat.Model), args.Error(1)
+}
+
+func (m *MockMonitorService) ExportHeartbeats(ctx context.Context, id string, since, until time.Time, fn func(*heartbeat.Model) error) error {
+	args := m.Called(ctx, id, since, until, fn)
+	return args.Error(0)
+}
+
+func (m *MockMonitorService) RemoveProxyReference(ctx context.Context, proxyID string) error {
+	args := m.Called(ctx, proxyID)
+	return args.Error(0)
+}
+
+func (m *MockMonitorService) FindByProxyId(ctx context.Context, proxyId string) ([]*shared.Monitor, error) {
+	args := m.Called(ctx, proxyId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*shared.Monitor), args.Error(1)
+}
+
+func (m *MockMonitorService) GetStatPoints(ctx context.Context, id string, since, until time.Time, granularity string) (*monitor.StatPointsSummaryDto, error) {
+	args := m.Called(ctx, id, since, until, granularity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*monitor.StatPointsSummaryDto), args.Error(1)
+}
+
+func (m *MockMonitorService) GetUptimeStats(ctx context.Context, id string) (*monitor.CustomUptimeStatsDto, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*monitor.CustomUptimeStatsDto), args.Error(1)
+}
+
+func (m *MockMonitorService) FindOneByPushToken(ctx context.Context, pushToken string) (*shared.Monitor, error) {
+	args := m.Called(ctx, pushToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*shared.Monitor), args.Error(1)
+}
+
+func (m *MockMonitorService) ResetMonitorData(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockMonitorService) FindActivePaginated(ctx context.Context, page int, limit int) ([]*shared.Monitor, error) {
+	args := m.Called(ctx, page, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*shared.Monitor), args.Error(1)
+}
+
+// MockNotificationHistoryService implements the notification_sent_history.Service interface for testing
+type MockNotificationHistoryService struct {
+	mock.Mock
+}
+
+func (m *MockNotificationHistoryService) CheckIfNotificationSent(ctx context.Context, notificationType string, monitorID string, targetDays int) (bool, error) {
+	args := m.Called(ctx, notificationType, monitorID, targetDays)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockNotificationHistoryService) RecordNotificationSent(ctx context.Context, notificationType string, monitorID string, targetDays int) error {
+	args := m.Called(ctx, notificationType, monitorID, targetDays)
+	return args.Error(0)
+}
+
+func (m *MockNotificationHistoryService) ClearNotificationHistory(ctx context.Context, monitorID string, notificationType string) error {
+	args := m.Called(ctx, monitorID, notificationType)
+	return args.Error(0)
+}
+
+func (m *MockNotificationHistoryService) CleanupOldRecords(ctx context.Context, olderThanDays int) error {
+	args := m.Called(ctx, olderThanDays)
+	return args.Error(0)
+}
+
+func (m *MockNotificationHistoryService) GetNotificationHistory(ctx context.Context, monitorID string, notificationType string) ([]*notification_sent_history.Model, error) {
+	args := m.Called(ctx, monitorID, notificationType)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*notification_sent_history.Model), args.Error(1)
+}
+
+func (m *MockNotificationHistoryService) RecordTestModeNotification(ctx context.Context, channelID string, monitorID string, message string) error {
+	args := m.Called(ctx, channelID, monitorID, message)
+	return args.Error(0)
+}
+
+func (m *MockNotificationHistoryService) GetTestModeNotifications(ctx context.Context, monitorID string) ([]*notification_sent_history.Model, error) {
+	args := m.Called(ctx, monitorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*notification_sent_history.Model), args.Error(1)
+}
+
+// MockProvider implements the NotificationChannelProvider interface for testing
+type MockProvider struct {
+	mock.Mock
+}
+
+func (m *MockProvider) Send(ctx context.Context, configJSON, message string, monitor *monitor.Model, heartbeat *heartbeat.Model) error {
+	args := m.Called(ctx, configJSON, message, monitor, heartbeat)
+	return args.Error(0)
+}
+
+func (m *MockProvider) Validate(configJSON string) error {
+	args := m.Called(configJSON)
+	return args.Error(0)
+}
+
+func (m *MockProvider) Unmarshal(configJSON string) (any, error) {
+	args := m.Called(configJSON)
+	return args.Get(0), args.Error(1)
+}
+
+func TestHandleNotifyEvent_Maintenance(t *testing.T) {
+	at := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	// A check that ran during maintenance because the global toggle keeps checks going
+	beat := func(alertsSuppressed bool) *heartbeat.Model {
+		return &heartbeat.Model{ID: "hb-1", MonitorID: "mon-1", Status: shared.MonitorStatusDown, Msg: "connection refused", Important: true, Time: at, AlertsSuppressed: alertsSuppressed}
+	}
+
+	t.Run("alerts are suppressed for checks inside a maintenance window", func(t *testing.T) {
+		monitorNotificationSvc := new(MockMonitorNotificationService)
+		l := &NotificationEventListener{
+			monitorNotificationService: monitorNotificationSvc,
+			logger:                     zap.NewNop().Sugar(),
+		}
+
+		l.handleNotifyEvent(events.Event{Type: events.ImportantHeartbeat, Payload: beat(true)})
+
+		monitorNotificationSvc.AssertNotCalled(t, "FindByMonitorID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("alerts are sent once the window is over", func(t *testing.T) {
+		monitorNotificationSvc := new(MockMonitorNotificationService)
+		l := &NotificationEventListener{
+			monitorNotificationService: monitorNotificationSvc,
+			logger:                     zap.NewNop().Sugar(),
+		}
+
+		// Stop right after the lookup, the channels themselves are not under test here
+		monitorNotificationSvc.On("FindByMonitorID", mock.Anything, "mon-1").Return([]*monitor_notification.Model{}, assert.AnError)
+
+		l.handleNotifyEvent(events.Event{Type: events.ImportantHeartbeat, Payload: beat(false)})
+
+		monitorNotificationSvc.AssertExpectations(t)
+	})
+
+	t.Run("the flag survives the event bus", func(t *testing.T) {
+		data, err := json.Marshal(beat(true))
+		require.NoError(t, err)
+
+		var decoded heartbeat.Model
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.True(t, decoded.AlertsSuppressed)
+	})
+}
+
+func TestHandleNotifyEvent_TestMode(t *testing.T) {
+	hb := &heartbeat.Model{ID: "hb-1", MonitorID: "mon-1", Status: shared.MonitorStatusDown, Msg: "connection refused", Important: true, Time: time.Now()}
+	mon := &monitor.Model{ID: "mon-1", Name: "API"}
+
+	provider := new(MockProvider)
+	RegisterNotificationChannelProvider("mock_test_mode", provider)
+	t.Cleanup(func() { delete(NotificationChannelProviderRegistry, "mock_test_mode") })
+
+	setup := func(testMode bool, channelConfig string) (*NotificationEventListener, *MockNotificationHistoryService) {
+		repo := new(MockRepository)
+		monitorSvc := new(MockMonitorService)
+		monitorNotificationSvc := new(MockMonitorNotificationService)
+		historySvc := new(MockNotificationHistoryService)
+
+		channel := &Model{ID: "chan-1", Name: "Staging", Type: "mock_test_mode", Active: true, Config: &channelConfig}
+		monitorNotificationSvc.On("FindByMonitorID", mock.Anything, "mon-1").Return([]*monitor_notification.Model{{MonitorID: "mon-1", NotificationID: "chan-1"}}, nil)
+		repo.On("FindByID", mock.Anything, "chan-1").Return(channel, nil)
+		monitorSvc.On("FindByID", mock.Anything, "mon-1").Return(mon, nil)
+		provider.On("Validate", channelConfig).Return(nil)
+
+		l := &NotificationEventListener{
+			service:                    createTestService(repo, monitorNotificationSvc),
+			monitorSvc:                 monitorSvc,
+			monitorNotificationService: monitorNotificationSvc,
+			notificationHistoryService: historySvc,
+			testMode:                   testMode,
+			logger:                     zap.NewNop().Sugar(),
+		}
+		return l, historySvc
+	}
+
+	t.Run("global test mode records history without sending", func(t *testing.T) {
+		l, historySvc := setup(true, `{}`)
+		historySvc.On("RecordTestModeNotification", mock.Anything, "chan-1", "mon-1", mock.MatchedBy(func(message string) bool {
+			return strings.Contains(message, "connection refused")
+		})).Return(nil)
+
+		l.handleNotifyEvent(events.Event{Type: events.ImportantHeartbeat, Payload: hb})
+
+		historySvc.AssertExpectations(t)
+		provider.AssertNotCalled(t, "Send", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("per-channel test mode records history without sending", func(t *testing.T) {
+		l, historySvc := setup(false, `{"test_mode":true}`)
+		historySvc.On("RecordTestModeNotification", mock.Anything, "chan-1", "mon-1", mock.MatchedBy(func(message string) bool {
+			return strings.Contains(message, "connection refused")
+		})).Return(nil)
+
+		l.handleNotifyEvent(events.Event{Type: events.ImportantHeartbeat, Payload: hb})
+
+		historySvc.AssertExpectations(t)
+		provider.AssertNotCalled(t, "Send", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("notifications are sent when test mode is off", func(t *testing.T) {
+		l, historySvc := setup(false, `{"test_mode":false}`)
+		provider.On("Send", mock.Anything, `{"test_mode":false}`, "connection refused", mon, mock.Anything).Return(nil).Once()
+
+		l.handleNotifyEvent(events.Event{Type: events.ImportantHeartbeat, Payload: hb})
+
+		provider.AssertExpectations(t)
+		historySvc.AssertNotCalled(t, "RecordTestModeNotification", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestHandleNotifyEvent_MinCriticality(t *testing.T) {
+	hb := &heartbeat.Model{ID: "hb-1", MonitorID: "mon-1", Status: shared.MonitorStatusDown, Msg: "connection refused", Important: true, Time: time.Now()}
+	channelConfig := `{}`
+	t.Cleanup(func() { delete(NotificationChannelProviderRegistry, "mock_criticality") })
+
+	tests := []struct {
+		name           string
+		criticality    string
+		minCriticality string
+		expectSent     bool
+	}{
+		{"no minimum notifies every monitor", shared.CriticalityLow, "", true},
+		{"low monitor skips a high-only channel", shared.CriticalityLow, shared.CriticalityHigh, false},
+		{"critical monitor reaches a high-only channel", shared.CriticalityCritical, shared.CriticalityHigh, true},
+		{"equal criticality is notified", shared.CriticalityHigh, shared.CriticalityHigh, true},
+		{"monitor without criticality counts as medium", "", shared.CriticalityHigh, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockRepository)
+			monitorSvc := new(MockMonitorService)
+			monitorNotificationSvc := new(MockMonitorNotificationService)
+			provider := new(MockProvider)
+			RegisterNotificationChannelProvider("mock_criticality", provider)
+			provider.On("Validate", channelConfig).Return(nil)
+
+			mon := &monitor.Model{ID: "mon-1", Name: "API", Criticality: tt.criticality}
+			channel := &Model{ID: "chan-1", Name: "On-call", Type: "mock_criticality", Active: true, Config: &channelConfig}
+			monitorNotificationSvc.On("FindByMonitorID", mock.Anything, "mon-1").Return([]*monitor_notification.Model{{MonitorID: "mon-1", NotificationID: "chan-1", MinCriticality: tt.minCriticality}}, nil)
+			repo.On("FindByID", mock.Anything, "chan-1").Return(channel, nil)
+			monitorSvc.On("FindByID", mock.Anything, "mon-1").Return(mon, nil)
+
+			l := &NotificationEventListener{
+				service:                    createTestService(repo, monitorNotificationSvc),
+				monitorSvc:                 monitorSvc,
+				monitorNotificationService: monitorNotificationSvc,
+				logger:                     zap.NewNop().Sugar(),
+			}
+
+			if tt.expectSent {
+				provider.On("Send", mock.Anything, channelConfig, "connection refused", mon, mock.Anything).Return(nil).Once()
+			}
+
+			l.handleNotifyEvent(events.Event{Type: events.ImportantHeartbeat, Payload: hb})
+
+			if tt.expectSent {
+				provider.AssertExpectations(t)
+			} else {
+				provider.AssertNotCalled(t, "Send", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				repo.AssertNotCalled(t, "FindByID", mock.Anything, "chan-1")
+			}
+		})
+	}
+}
+
+func TestFormatHighLatencyMessage(t *testing.T) {
+	latency := &events.HighLatencyPayload{MonitorName: "API", AvgPingMs: 900, LimitMs: 500, Checks: 3}
+	assert.Equal(t, "🐢 Performance Degraded\n\nMonitor: API\nAverage response time: 900 ms over the last 3 checks\nLatency limit: 500 ms",
+		formatHighLatencyMessage(latency))
+
+	latency.AvgPingMs, latency.Recovered = 300, true
+	assert.Equal(t, "✅ Performance Recovered\n\nMonitor: API\nAverage response time: 300 ms over the last 3 checks\nLatency limit: 500 ms",
+		formatHighLatencyMessage(latency))
+}
+
+func TestNotificationEventTypes(t *testing.T) {
+	now := time.Now()
+	channelConfig := `{}`
+	t.Cleanup(func() { delete(NotificationChannelProviderRegistry, "mock_event_types") })
+
+	downBeat := &heartbeat.Model{ID: "hb-1", MonitorID: "mon-1", Status: shared.MonitorStatusDown, Msg: "connection refused", Important: true, Time: now}
+	upBeat := &heartbeat.Model{ID: "hb-2", MonitorID: "mon-1", Status: shared.MonitorStatusUp, Msg: "200 - OK", Important: true, Time: now}
+	changedBeat := &heartbeat.Model{ID: "hb-3", MonitorID: "mon-1", Status: shared.MonitorStatusUp, Msg: "Response body changed", ErrorCategory: shared.ErrorCategoryBodyChanged, Important: true, Time: now}
+	latency := &events.HighLatencyPayload{MonitorID: "mon-1", MonitorName: "API", AvgPingMs: 900, LimitMs: 500, Checks: 3, Time: now}
+
+	tests := []struct {
+		name       string
+		eventTypes []string
+		event      events.Event
+		expectSent bool
+	}{
+		{"all types enabled by default", nil, events.Event{Type: events.ImportantHeartbeat, Payload: downBeat}, true},
+		{"down enabled", []string{shared.NotificationEventDown}, events.Event{Type: events.ImportantHeartbeat, Payload: downBeat}, true},
+		{"down disabled", []string{shared.NotificationEventUp}, events.Event{Type: events.ImportantHeartbeat, Payload: downBeat}, false},
+		{"up enabled", []string{shared.NotificationEventUp}, events.Event{Type: events.ImportantHeartbeat, Payload: upBeat}, true},
+		{"up disabled", []string{shared.NotificationEventDown}, events.Event{Type: events.ImportantHeartbeat, Payload: upBeat}, false},
+		{"body change is a degraded event", []string{shared.NotificationEventDegraded}, events.Event{Type: events.ImportantHeartbeat, Payload: changedBeat}, true},
+		{"degraded disabled", []string{shared.NotificationEventDown, shared.NotificationEventUp}, events.Event{Type: events.ImportantHeartbeat, Payload: changedBeat}, false},
+		{"high latency enabled", []string{shared.NotificationEventHighLatency}, events.Event{Type: events.HighLatency, Payload: latency}, true},
+		{"high latency disabled", []string{shared.NotificationEventDown, shared.NotificationEventUp}, events.Event{Type: events.HighLatency, Payload: latency}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockRepository)
+			monitorSvc := new(MockMonitorService)
+			monitorNotificationSvc := new(MockMonitorNotificationService)
+			heartbeatSvc := new(MockHeartbeatService)
+			heartbeatSvc.On("FindByMonitorIDPaginated", mock.Anything, "mon-1", mock.Anything, 0, mock.Anything, false).Return([]*heartbeat.Model{}, nil)
+			provider := new(MockProvider)
+			RegisterNotificationChannelProvider("mock_event_types", provider)
+			provider.On("Validate", channelConfig).Return(nil)
+
+			mon := &monitor.Model{ID: "mon-1", Name: "API"}
+			channel := &Model{ID: "chan-1", Name: "On-call", Type: "mock_event_types", Active: true, Config: &channelConfig}
+			monitorNotificationSvc.On("FindByMonitorID", mock.Anything, "mon-1").Return([]*monitor_notification.Model{{MonitorID: "mon-1", NotificationID: "chan-1", EventTypes: tt.eventTypes}}, nil)
+			repo.On("FindByID", mock.Anything, "chan-1").Return(channel, nil)
+			monitorSvc.On("FindByID", mock.Anything, "mon-1").Return(mon, nil)
+
+			l := &NotificationEventListener{
+				service:                    createTestService(repo, monitorNotificationSvc),
+				monitorSvc:                 monitorSvc,
+				heartbeatService:           heartbeatSvc,
+				monitorNotificationService: monitorNotificationSvc,
+				logger:                     zap.NewNop().Sugar(),
+			}
+
+			if tt.expectSent {
+				provider.On("Send", mock.Anything, channelConfig, mock.Anything, mon, mock.Anything).Return(nil).Once()
+			}
+
+			switch tt.event.Type {
+			case events.ImportantHeartbeat:
+				l.handleNotifyEvent(tt.event)
+			case events.HighLatency:
+				l.handleHighLatencyEvent(tt.event)
+			}
+
+			if tt.expectSent {
+				provider.AssertExpectations(t)
+			} else {
+				provider.AssertNotCalled(t, "Send", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				repo.AssertNotCalled(t, "FindByID", mock.Anything, "chan-1")
+			}
+		})
+	}
+}
+
+type MockSettingService struct {
+	mock.Mock
+}
+
+func (m *MockSettingService) GetByKey(ctx context.Context, key string) (*shared.SettingModel, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*shared.SettingModel), args.Error(1)
+}
+
+func (m *MockSettingService) SetByKey(ctx context.Context, key string, entity *shared.SettingCreateUpdateDto) (*shared.SettingModel, error) {
+	args := m.Called(ctx, key, entity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*shared.SettingModel), args.Error(1)
+}
+
+func (m *MockSettingService) DeleteByKey(ctx context.Context, key string) error {
+	return m.Called(ctx, key).Error(0)
+}
+
+func (m *MockSettingService) InitializeSettings(ctx context.Context) error {
+	return m.Called(ctx).Error(0)
+}
+
+func TestHandleMonitorLifecycleEvent(t *testing.T) {
+	channelConfig := `{}`
+	t.Cleanup(func() { delete(NotificationChannelProviderRegistry, "mock_admin") })
+
+	payload := &events.MonitorLifecyclePayload{
+		Action:      events.MonitorActionDeleted,
+		MonitorID:   "mon-1",
+		MonitorName: "API",
+		MonitorType: "http",
+		ActorType:   "user",
+		ActorID:     "user-1",
+		Time:        time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("admin channel is notified when configured", func(t *testing.T) {
+		repo := new(MockRepository)
+		settingSvc := new(MockSettingService)
+		provider := new(MockProvider)
+		RegisterNotificationChannelProvider("mock_admin", provider)
+
+		channel := &Model{ID: "chan-admin", Name: "Admins", Type: "mock_admin", Active: true, Config: &channelConfig}
+		settingSvc.On("GetByKey", mock.Anything, AdminNotificationChannelSettingKey).Return(&shared.SettingModel{Value: "chan-admin"}, nil)
+		repo.On("FindByID", mock.Anything, "chan-admin").Return(channel, nil)
+		provider.On("Validate", channelConfig).Return(nil)
+		provider.On("Send", mock.Anything, channelConfig, mock.MatchedBy(func(message string) bool {
+			return strings.Contains(message, "Monitor Deleted") &&
+				strings.Contains(message, "API (http)") &&
+				strings.Contains(message, "Changed by: user user-1")
+		}), mock.MatchedBy(func(m *monitor.Model) bool {
+			return m.ID == "mon-1" && m.Name == "API"
+		}), mock.Anything).Return(nil).Once()
+
+		l := &NotificationEventListener{
+			service:        createTestService(repo, new(MockMonitorNotificationService)),
+			settingService: settingSvc,
+			logger:         zap.NewNop().Sugar(),
+		}
+
+		l.handleMonitorLifecycleEvent(events.Event{Type: events.MonitorLifecycle, Payload: payload})
+
+		provider.AssertExpectations(t)
+	})
+
+	t.Run("nothing is sent without an admin channel", func(t *testing.T) {
+		repo := new(MockRepository)
+		settingSvc := new(MockSettingService)
+		settingSvc.On("GetByKey", mock.Anything, AdminNotificationChannelSettingKey).Return(nil, nil)
+
+		l := &NotificationEventListener{
+			service:        createTestService(repo, new(MockMonitorNotificationService)),
+			settingService: settingSvc,
+			logger:         zap.NewNop().Sugar(),
+		}
+
+		l.handleMonitorLifecycleEvent(events.Event{Type: events.MonitorLifecycle, Payload: payload})
+
+		settingSvc.AssertExpectations(t)
+		repo.AssertNotCalled(t, "FindByID", mock.Anything, mock.Anything)
+	})
+}
+
+func TestFormatMonitorLifecycleMessage(t *testing.T) {
+	payload := &events.MonitorLifecyclePayload{
+		Action:      events.MonitorActionCreated,
+		MonitorID:   "mon-1",
+		MonitorName: "API",
+		MonitorType: "http",
+		ActorType:   "api_key",
+		ActorID:     "key-1",
+		Time:        time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC),
+	}
+
+	message := formatMonitorLifecycleMessage(payload)
+	assert.Contains(t, message, "Monitor Created")
+	assert.Contains(t, message, "Changed by: API key key-1")
+	assert.Contains(t, message, "2025-06-01T10:00:00Z")
+
+	payload.ActorType = "system"
+	payload.ActorID = ""
+	assert.Contains(t, formatMonitorLifecycleMessage(payload), "Changed by: system")
+}
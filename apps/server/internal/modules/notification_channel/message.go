@@ -0,0 +1,170 @@
+package notification_channel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"peekaping/internal/modules/heartbeat"
+	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/notification_channel/providers"
+	"peekaping/internal/modules/shared"
+	"strings"
+	"time"
+
+	liquid "github.com/osteele/liquid"
+)
+
+const (
+	// downtimeLookupLimit bounds how many important heartbeats are scanned to find the down transition
+	downtimeLookupLimit = 10
+	// failureSnippetLength is how many characters of the previous failure a recovery message quotes
+	failureSnippetLength = 200
+)
+
+// ChannelOptions holds the provider independent settings of a notification channel.
+// They live next to the provider specific settings in the channel config.
+type ChannelOptions struct {
+	DownTemplate string `json:"down_template"`
+	UpTemplate   string `json:"up_template"`
+	// TestMode logs and records notifications instead of sending them
+	TestMode bool `json:"test_mode"`
+	// HideFailureReason leaves the previous failure out of recovery messages
+	HideFailureReason bool `json:"hide_failure_reason"`
+	// BusinessHours limits delivery to opening hours, nil delivers around the clock
+	BusinessHours *BusinessHours `json:"business_hours"`
+	// Grouping sends bursts of status changes as one message, nil sends each right away
+	Grouping *NotificationGrouping `json:"grouping"`
+	// NotifyAfterFailures holds back down notifications until the monitor failed that many
+	// consecutive checks, independent of its retries. 0 and 1 notify on the first failure.
+	NotifyAfterFailures int `json:"notify_after_failures"`
+	// Digest sends the status changes of monitors in digest only mode as one summary on a
+	// schedule, nil notifies them right away
+	Digest *NotificationDigest `json:"digest"`
+	// IncludeTags adds the monitor's tags to messages without a template
+	IncludeTags bool `json:"include_tags"`
+}
+
+// parseChannelOptions reads the provider independent settings from a channel config
+func (l *NotificationEventListener) parseChannelOptions(configJSON string) ChannelOptions {
+	var options ChannelOptions
+	if err := json.Unmarshal([]byte(configJSON), &options); err != nil {
+		l.logger.Warnf("Failed to parse notification channel options: %v", err)
+	}
+	return options
+}
+
+// validateChannelOptions checks the provider independent settings of a channel config
+func validateChannelOptions(configJSON string) error {
+	var options ChannelOptions
+	if err := json.Unmarshal([]byte(configJSON), &options); err != nil {
+		// Malformed configs are reported by the provider validation
+		return nil
+	}
+	if options.BusinessHours != nil {
+		if err := options.BusinessHours.Validate(); err != nil {
+			return err
+		}
+	}
+	if err := validateNotifyAfterFailures(options.NotifyAfterFailures); err != nil {
+		return err
+	}
+	if options.Digest != nil {
+		if err := options.Digest.Validate(); err != nil {
+			return err
+		}
+	}
+	if options.Grouping != nil {
+		return options.Grouping.Validate()
+	}
+	return nil
+}
+
+// buildHeartbeatMessage renders the message for an important heartbeat, picking the down or
+// up template based on the transition direction. Recovery messages carry the outage duration
+// and the failure reason of the last down transition. tags are the names of the monitor's
+// tags, available to templates and added to default messages with IncludeTags.
+func (l *NotificationEventListener) buildHeartbeatMessage(ctx context.Context, options ChannelOptions, m *monitor.Model, hb *heartbeat.Model, tags []string) string {
+	message := hb.Msg
+	template := options.DownTemplate
+	bindings := providers.PrepareTemplateBindings(m, hb, hb.Msg)
+	bindings["tags"] = tags
+
+	if hb.Status == shared.MonitorStatusUp {
+		template = options.UpTemplate
+		if down := l.findDownTransition(ctx, hb); down != nil {
+			downtime := hb.Time.Sub(down.Time)
+			formatted := formatDowntime(downtime)
+			bindings["downtime"] = formatted
+			bindings["downtime_seconds"] = int64(downtime.Seconds())
+			bindings["previous_failure"] = down.Msg
+			bindings["previous_failure_category"] = down.ErrorCategory
+			message = fmt.Sprintf("%s (down for %s)", hb.Msg, formatted)
+			if !options.HideFailureReason && down.Msg != "" {
+				message += ". " + formatPreviousFailure(down)
+			}
+		}
+	}
+
+	if template == "" {
+		if options.IncludeTags && len(tags) > 0 {
+			message += ". " + formatTags(tags)
+		}
+		return message
+	}
+
+	rendered, err := liquid.NewEngine().ParseAndRenderString(template, bindings)
+	if err != nil {
+		l.logger.Warnf("Failed to render message template for monitor %s: %v", hb.MonitorID, err)
+		return message
+	}
+
+	return rendered
+}
+
+// findDownTransition returns the most recent important DOWN heartbeat before the given
+// recovery heartbeat, nil when the monitor did not go down since it was last up
+func (l *NotificationEventListener) findDownTransition(ctx context.Context, hb *heartbeat.Model) *heartbeat.Model {
+	important := true
+	history, err := l.heartbeatService.FindByMonitorIDPaginated(ctx, hb.MonitorID, downtimeLookupLimit, 0, &important, false)
+	if err != nil {
+		l.logger.Warnf("Failed to get heartbeat history for monitor %s: %v", hb.MonitorID, err)
+		return nil
+	}
+
+	// History is ordered newest first and already contains the recovery heartbeat itself
+	for _, prev := range history {
+		if prev.ID == hb.ID || !prev.Time.Before(hb.Time) {
+			continue
+		}
+		switch prev.Status {
+		case shared.MonitorStatusDown:
+			return prev
+		case shared.MonitorStatusUp:
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// formatPreviousFailure quotes the failure that took the monitor down, e.g.
+// "Previous failure: timeout after 10s [timeout]"
+func formatPreviousFailure(down *heartbeat.Model) string {
+	snippet := strings.TrimSpace(down.Msg)
+	if runes := []rune(snippet); len(runes) > failureSnippetLength {
+		snippet = string(runes[:failureSnippetLength]) + "…"
+	}
+	if down.ErrorCategory != "" {
+		return fmt.Sprintf("Previous failure: %s [%s]", snippet, down.ErrorCategory)
+	}
+	return "Previous failure: " + snippet
+}
+
+// formatDowntime renders a duration with second precision, e.g. "1h2m5s"
+func formatDowntime(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d < time.Second {
+		return "0s"
+	}
+	return d.String()
+}
@@ -0,0 +1,197 @@
+package notification_channel
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"peekaping/internal/modules/heartbeat"
+	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/shared"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+// MockHeartbeatService implements the heartbeat.Service interface for testing
+type MockHeartbeatService struct {
+	mock.Mock
+}
+
+func (m *MockHeartbeatService) Create(ctx context.Context, entity *heartbeat.CreateUpdateDto) (*heartbeat.Model, error) {
+	args := m.Called(ctx, entity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*heartbeat.Model), args.Error(1)
+}
+
+func (m *MockHeartbeatService) FindByID(ctx context.Context, id string) (*heartbeat.Model, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*heartbeat.Model), args.Error(1)
+}
+
+func (m *MockHeartbeatService) FindAll(ctx context.Context, page int, limit int) ([]*heartbeat.Model, error) {
+	args := m.Called(ctx, page, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*heartbeat.Model), args.Error(1)
+}
+
+func (m *MockHeartbeatService) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockHeartbeatService) FindUptimeStatsByMonitorID(ctx context.Context, monitorID string, periods map[string]time.Duration, now time.Time) (map[string]float64, error) {
+	args := m.Called(ctx, monitorID, periods, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]float64), args.Error(1)
+}
+
+func (m *MockHeartbeatService) DeleteOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	args := m.Called(ctx, cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockHeartbeatService) CompactOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	args := m.Called(ctx, cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockHeartbeatService) DeleteRollupsOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	args := m.Called(ctx, cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockHeartbeatService) FindByMonitorIDPaginated(ctx context.Context, monitorID string, limit, page int, important *bool, reverse bool) ([]*heartbeat.Model, error) {
+	args := m.Called(ctx, monitorID, limit, page, important, reverse)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*heartbeat.Model), args.Error(1)
+}
+
+func (m *MockHeartbeatService) FindLatestByMonitorIDs(ctx context.Context, monitorIDs []string) (map[string]*heartbeat.Model, error) {
+	args := m.Called(ctx, monitorIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]*heartbeat.Model), args.Error(1)
+}
+
+func (m *MockHeartbeatService) DeleteByMonitorID(ctx context.Context, monitorID string) error {
+	args := m.Called(ctx, monitorID)
+	return args.Error(0)
+}
+
+func (m *MockHeartbeatService) StreamByMonitorID(ctx context.Context, monitorID string, since, until time.Time, fn func(*heartbeat.Model) error) error {
+	args := m.Called(ctx, monitorID, since, until, fn)
+	return args.Error(0)
+}
+
+func TestBuildHeartbeatMessage(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	mon := &monitor.Model{ID: "mon-1", Name: "API"}
+
+	down := &heartbeat.Model{ID: "hb-down", MonitorID: "mon-1", Status: shared.MonitorStatusDown, Msg: "connection refused", Important: true, Time: base}
+	pending := &heartbeat.Model{ID: "hb-pending", MonitorID: "mon-1", Status: shared.MonitorStatusPending, Msg: "timeout", Important: true, Time: base.Add(-time.Minute)}
+	previousUp := &heartbeat.Model{ID: "hb-prev-up", MonitorID: "mon-1", Status: shared.MonitorStatusUp, Msg: "200 - OK", Important: true, Time: base.Add(-time.Hour)}
+	up := &heartbeat.Model{ID: "hb-up", MonitorID: "mon-1", Status: shared.MonitorStatusUp, Msg: "200 - OK", Important: true, Time: base.Add(1*time.Hour + 2*time.Minute + 5*time.Second)}
+
+	newListener := func(history []*heartbeat.Model) (*NotificationEventListener, *MockHeartbeatService) {
+		hbSvc := new(MockHeartbeatService)
+		hbSvc.On("FindByMonitorIDPaginated", ctx, "mon-1", downtimeLookupLimit, 0, mock.Anything, false).Return(history, nil)
+		return &NotificationEventListener{heartbeatService: hbSvc, logger: zap.NewNop().Sugar()}, hbSvc
+	}
+
+	t.Run("recovery message includes downtime and previous failure by default", func(t *testing.T) {
+		l, hbSvc := newListener([]*heartbeat.Model{up, down, pending, previousUp})
+
+		msg := l.buildHeartbeatMessage(ctx, l.parseChannelOptions(`{}`), mon, up, nil)
+
+		assert.Equal(t, "200 - OK (down for 1h2m5s). Previous failure: connection refused", msg)
+		hbSvc.AssertExpectations(t)
+	})
+
+	t.Run("recovery message includes the failure category", func(t *testing.T) {
+		timeout := &heartbeat.Model{ID: "hb-timeout", MonitorID: "mon-1", Status: shared.MonitorStatusDown, Msg: "dial tcp: i/o timeout after 10s", ErrorCategory: shared.ErrorCategoryPortFiltered, Important: true, Time: base}
+		l, _ := newListener([]*heartbeat.Model{up, timeout, previousUp})
+
+		msg := l.buildHeartbeatMessage(ctx, l.parseChannelOptions(`{}`), mon, up, nil)
+
+		assert.Equal(t, "200 - OK (down for 1h2m5s). Previous failure: dial tcp: i/o timeout after 10s [port_filtered]", msg)
+	})
+
+	t.Run("previous failure can be hidden", func(t *testing.T) {
+		l, _ := newListener([]*heartbeat.Model{up, down, previousUp})
+
+		msg := l.buildHeartbeatMessage(ctx, l.parseChannelOptions(`{"hide_failure_reason":true}`), mon, up, nil)
+
+		assert.Equal(t, "200 - OK (down for 1h2m5s)", msg)
+	})
+
+	t.Run("up template can use the previous failure", func(t *testing.T) {
+		l, _ := newListener([]*heartbeat.Model{up, down, previousUp})
+
+		msg := l.buildHeartbeatMessage(ctx, l.parseChannelOptions(`{"up_template":"{{ name }} recovered, was: {{ previous_failure }}"}`), mon, up, nil)
+
+		assert.Equal(t, "API recovered, was: connection refused", msg)
+	})
+
+	t.Run("recovery uses up template with downtime bindings", func(t *testing.T) {
+		l, _ := newListener([]*heartbeat.Model{up, down, previousUp})
+
+		msg := l.buildHeartbeatMessage(ctx, l.parseChannelOptions(`{"up_template":"{{ name }} is back after {{ downtime }} ({{ downtime_seconds }}s)","down_template":"{{ name }} is down"}`), mon, up, nil)
+
+		assert.Equal(t, "API is back after 1h2m5s (3725s)", msg)
+	})
+
+	t.Run("down notification uses down template", func(t *testing.T) {
+		hbSvc := new(MockHeartbeatService)
+		l := &NotificationEventListener{heartbeatService: hbSvc, logger: zap.NewNop().Sugar()}
+
+		msg := l.buildHeartbeatMessage(ctx, l.parseChannelOptions(`{"up_template":"{{ name }} is back","down_template":"{{ name }} is {{ status }}: {{ msg }}"}`), mon, down, nil)
+
+		assert.Equal(t, "API is DOWN: connection refused", msg)
+		hbSvc.AssertNotCalled(t, "FindByMonitorIDPaginated", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("recovery without a down transition keeps the heartbeat message", func(t *testing.T) {
+		l, _ := newListener([]*heartbeat.Model{up, pending, previousUp})
+
+		msg := l.buildHeartbeatMessage(ctx, l.parseChannelOptions(`{}`), mon, up, nil)
+
+		assert.Equal(t, "200 - OK", msg)
+	})
+
+	t.Run("invalid template falls back to the default message", func(t *testing.T) {
+		l, _ := newListener([]*heartbeat.Model{up, down})
+
+		msg := l.buildHeartbeatMessage(ctx, l.parseChannelOptions(`{"up_template":"{% if %}"}`), mon, up, nil)
+
+		assert.Equal(t, "200 - OK (down for 1h2m5s). Previous failure: connection refused", msg)
+	})
+}
+
+func TestFormatPreviousFailure(t *testing.T) {
+	long := strings.Repeat("x", failureSnippetLength+50)
+
+	assert.Equal(t, "Previous failure: refused [network]", formatPreviousFailure(&heartbeat.Model{Msg: " refused ", ErrorCategory: "network"}))
+	assert.Equal(t, "Previous failure: "+long[:failureSnippetLength]+"…", formatPreviousFailure(&heartbeat.Model{Msg: long}))
+}
+
+func TestFormatDowntime(t *testing.T) {
+	assert.Equal(t, "0s", formatDowntime(200*time.Millisecond))
+	assert.Equal(t, "45s", formatDowntime(45*time.Second+300*time.Millisecond))
+	assert.Equal(t, "2h0m0s", formatDowntime(2*time.Hour))
+}
@@ -0,0 +1,68 @@
+package notification_channel
+
+import (
+	"context"
+	"peekaping/internal/modules/heartbeat"
+	"peekaping/internal/modules/monitor"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Results recorded by NotificationMetrics
+const (
+	SendSuccess = "success"
+	SendFailure = "failure"
+)
+
+// NotificationMetrics counts notification sends by channel type and result, to spot
+// channels failing to deliver
+type NotificationMetrics struct {
+	sent *prometheus.CounterVec
+}
+
+func NewNotificationMetrics(registerer prometheus.Registerer) *NotificationMetrics {
+	m := &NotificationMetrics{
+		sent: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "peekaping",
+			Subsystem: "notification",
+			Name:      "sends_total",
+			Help:      "Notification sends by channel type and result (success or failure).",
+		}, []string{"type", "result"}),
+	}
+	registerer.MustRegister(m.sent)
+	return m
+}
+
+// ProvideNotificationMetrics registers the notification metrics with the default Prometheus registry
+func ProvideNotificationMetrics() *NotificationMetrics {
+	return NewNotificationMetrics(prometheus.DefaultRegisterer)
+}
+
+// Instrument wraps provider so every Send call is counted under channelType. Without
+// metrics the provider is returned as is.
+func (m *NotificationMetrics) Instrument(channelType string, provider NotificationChannelProvider) NotificationChannelProvider {
+	if m == nil {
+		return provider
+	}
+	return &instrumentedProvider{NotificationChannelProvider: provider, channelType: channelType, metrics: m}
+}
+
+func (m *NotificationMetrics) observe(channelType string, err error) {
+	result := SendSuccess
+	if err != nil {
+		result = SendFailure
+	}
+	m.sent.WithLabelValues(channelType, result).Inc()
+}
+
+type instrumentedProvider struct {
+	NotificationChannelProvider
+	channelType string
+	metrics     *NotificationMetrics
+}
+
+func (p *instrumentedProvider) Send(ctx context.Context, configJSON, message string, m *monitor.Model, hb *heartbeat.Model) error {
+	err := p.NotificationChannelProvider.Send(ctx, configJSON, message, m, hb)
+	p.metrics.observe(p.channelType, err)
+	return err
+}
@@ -0,0 +1,54 @@
+package notification_channel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"peekaping/internal/modules/heartbeat"
+	"peekaping/internal/modules/monitor"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// fixedErrorProvider returns the errors it was given, one per Send call
+type fixedErrorProvider struct {
+	errs []error
+}
+
+func (p *fixedErrorProvider) Send(ctx context.Context, configJSON, message string, m *monitor.Model, hb *heartbeat.Model) error {
+	err := p.errs[0]
+	p.errs = p.errs[1:]
+	return err
+}
+
+func (p *fixedErrorProvider) Validate(configJSON string) error { return nil }
+
+func (p *fixedErrorProvider) Unmarshal(configJSON string) (any, error) { return nil, nil }
+
+func TestNotificationMetrics_Instrument(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	metrics := NewNotificationMetrics(registry)
+
+	slack := metrics.Instrument("slack", &fixedErrorProvider{errs: []error{nil, nil, errors.New("invalid_token")}})
+	webhook := metrics.Instrument("webhook", &fixedErrorProvider{errs: []error{nil}})
+
+	ctx := context.Background()
+	assert.NoError(t, slack.Send(ctx, "{}", "up", nil, nil))
+	assert.NoError(t, slack.Send(ctx, "{}", "up", nil, nil))
+	assert.EqualError(t, slack.Send(ctx, "{}", "down", nil, nil), "invalid_token")
+	assert.NoError(t, webhook.Send(ctx, "{}", "up", nil, nil))
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.sent.WithLabelValues("slack", SendSuccess)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.sent.WithLabelValues("slack", SendFailure)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.sent.WithLabelValues("webhook", SendSuccess)))
+	assert.Equal(t, 0.0, testutil.ToFloat64(metrics.sent.WithLabelValues("webhook", SendFailure)))
+
+	t.Run("without metrics the provider is not wrapped", func(t *testing.T) {
+		var none *NotificationMetrics
+		provider := &fixedErrorProvider{}
+		assert.Same(t, provider, none.Instrument("slack", provider))
+	})
+}
@@ -0,0 +1,38 @@
+package notification_channel
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// monitorTagNames returns the sorted names of the monitor's tags, nil when it has none or
+// they can't be fetched
+func (l *NotificationEventListener) monitorTagNames(ctx context.Context, monitorID string) []string {
+	if l.monitorTagService == nil || l.tagService == nil {
+		return nil
+	}
+
+	rels, err := l.monitorTagService.FindByMonitorID(ctx, monitorID)
+	if err != nil {
+		l.logger.Warnf("Failed to get tags of monitor %s: %v", monitorID, err)
+		return nil
+	}
+
+	var names []string
+	for _, rel := range rels {
+		t, err := l.tagService.FindByID(ctx, rel.TagID)
+		if err != nil || t == nil {
+			l.logger.Warnf("Failed to get tag %s of monitor %s: %v", rel.TagID, monitorID, err)
+			continue
+		}
+		names = append(names, t.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// formatTags renders tag names for a default message, e.g. "Tags: prod, api"
+func formatTags(tags []string) string {
+	return "Tags: " + strings.Join(tags, ", ")
+}
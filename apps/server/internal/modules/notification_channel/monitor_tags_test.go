@@ -0,0 +1,187 @@
+package notification_channel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"peekaping/internal/modules/events"
+	"peekaping/internal/modules/heartbeat"
+	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/monitor_notification"
+	"peekaping/internal/modules/monitor_tag"
+	"peekaping/internal/modules/shared"
+	"peekaping/internal/modules/tag"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+type MockMonitorTagService struct {
+	mock.Mock
+}
+
+func (m *MockMonitorTagService) Create(ctx context.Context, monitorID string, tagID string) (*monitor_tag.Model, error) {
+	args := m.Called(ctx, monitorID, tagID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*monitor_tag.Model), args.Error(1)
+}
+
+func (m *MockMonitorTagService) FindByID(ctx context.Context, id string) (*monitor_tag.Model, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*monitor_tag.Model), args.Error(1)
+}
+
+func (m *MockMonitorTagService) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockMonitorTagService) FindByMonitorID(ctx context.Context, monitorID string) ([]*monitor_tag.Model, error) {
+	args := m.Called(ctx, monitorID)
+	return args.Get(0).([]*monitor_tag.Model), args.Error(1)
+}
+
+func (m *MockMonitorTagService) FindByTagID(ctx context.Context, tagID string) ([]*monitor_tag.Model, error) {
+	args := m.Called(ctx, tagID)
+	return args.Get(0).([]*monitor_tag.Model), args.Error(1)
+}
+
+func (m *MockMonitorTagService) DeleteByMonitorID(ctx context.Context, monitorID string) error {
+	args := m.Called(ctx, monitorID)
+	return args.Error(0)
+}
+
+func (m *MockMonitorTagService) DeleteByTagID(ctx context.Context, tagID string) error {
+	args := m.Called(ctx, tagID)
+	return args.Error(0)
+}
+
+func (m *MockMonitorTagService) DeleteByMonitorAndTag(ctx context.Context, monitorID string, tagID string) error {
+	args := m.Called(ctx, monitorID, tagID)
+	return args.Error(0)
+}
+
+type MockTagService struct {
+	mock.Mock
+}
+
+func (m *MockTagService) Create(ctx context.Context, entity *tag.CreateUpdateDto) (*tag.Model, error) {
+	args := m.Called(ctx, entity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*tag.Model), args.Error(1)
+}
+
+func (m *MockTagService) FindByID(ctx context.Context, id string) (*tag.Model, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*tag.Model), args.Error(1)
+}
+
+func (m *MockTagService) FindAll(ctx context.Context, page int, limit int, q string) ([]*tag.Model, error) {
+	args := m.Called(ctx, page, limit, q)
+	return args.Get(0).([]*tag.Model), args.Error(1)
+}
+
+func (m *MockTagService) UpdateFull(ctx context.Context, id string, entity *tag.CreateUpdateDto) (*tag.Model, error) {
+	args := m.Called(ctx, id, entity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*tag.Model), args.Error(1)
+}
+
+func (m *MockTagService) UpdatePartial(ctx context.Context, id string, entity *tag.PartialUpdateDto) (*tag.Model, error) {
+	args := m.Called(ctx, id, entity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*tag.Model), args.Error(1)
+}
+
+func (m *MockTagService) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockTagService) FindByName(ctx context.Context, name string) (*tag.Model, error) {
+	args := m.Called(ctx, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*tag.Model), args.Error(1)
+}
+
+func TestHandleNotifyEvent_MonitorTags(t *testing.T) {
+	mon := &monitor.Model{ID: "mon-1", Name: "API"}
+
+	t.Cleanup(func() { delete(NotificationChannelProviderRegistry, "mock_tags") })
+
+	configs := map[string]string{
+		"chan-tags":     `{"url":"https://tags.example.com","include_tags":true}`,
+		"chan-plain":    `{"url":"https://plain.example.com"}`,
+		"chan-template": `{"url":"https://template.example.com","include_tags":true,"down_template":"{{ name }} [{{ tags | join: \"|\" }}]: {{ msg }}"}`,
+	}
+	provider := new(MockProvider)
+	RegisterNotificationChannelProvider("mock_tags", provider)
+	repo := new(MockRepository)
+	monitorSvc := new(MockMonitorService)
+	monitorNotificationSvc := new(MockMonitorNotificationService)
+	monitorTagSvc := new(MockMonitorTagService)
+	tagSvc := new(MockTagService)
+
+	var links []*monitor_notification.Model
+	for id := range configs {
+		config := configs[id]
+		links = append(links, &monitor_notification.Model{ID: "mn-" + id, MonitorID: "mon-1", NotificationID: id})
+		repo.On("FindByID", mock.Anything, id).Return(&Model{ID: id, Name: id, Type: "mock_tags", Active: true, Config: &config}, nil)
+	}
+	monitorNotificationSvc.On("FindByMonitorID", mock.Anything, "mon-1").Return(links, nil)
+	monitorSvc.On("FindByID", mock.Anything, "mon-1").Return(mon, nil)
+	monitorTagSvc.On("FindByMonitorID", mock.Anything, "mon-1").Return([]*monitor_tag.Model{
+		{ID: "mt-1", MonitorID: "mon-1", TagID: "tag-prod"},
+		{ID: "mt-2", MonitorID: "mon-1", TagID: "tag-api"},
+	}, nil)
+	tagSvc.On("FindByID", mock.Anything, "tag-prod").Return(&tag.Model{ID: "tag-prod", Name: "prod"}, nil)
+	tagSvc.On("FindByID", mock.Anything, "tag-api").Return(&tag.Model{ID: "tag-api", Name: "api"}, nil)
+	provider.On("Validate", mock.Anything).Return(nil)
+	provider.On("Send", mock.Anything, mock.Anything, mock.Anything, mon, mock.Anything).Return(nil)
+
+	l := &NotificationEventListener{
+		service:                    createTestService(repo, monitorNotificationSvc),
+		monitorSvc:                 monitorSvc,
+		monitorNotificationService: monitorNotificationSvc,
+		monitorTagService:          monitorTagSvc,
+		tagService:                 tagSvc,
+		logger:                     zap.NewNop().Sugar(),
+	}
+
+	hb := &heartbeat.Model{
+		ID: "hb-1", MonitorID: "mon-1", Status: shared.MonitorStatusDown, Msg: "connection refused",
+		Time: time.Date(2025, 10, 6, 10, 0, 0, 0, time.UTC), Important: true, Notified: true,
+	}
+	l.handleNotifyEvent(events.Event{Type: events.ImportantHeartbeat, Payload: hb})
+
+	messages := make(map[string]string)
+	for _, call := range provider.Calls {
+		if call.Method == "Send" {
+			messages[call.Arguments.String(1)] = call.Arguments.String(2)
+		}
+	}
+	assert.Equal(t, "connection refused. Tags: api, prod", messages[configs["chan-tags"]])
+	assert.Equal(t, "connection refused", messages[configs["chan-plain"]])
+	assert.Equal(t, "API [api|prod]: connection refused", messages[configs["chan-template"]])
+
+	// The tags are fetched once for all channels
+	monitorTagSvc.AssertNumberOfCalls(t, "FindByMonitorID", 1)
+}
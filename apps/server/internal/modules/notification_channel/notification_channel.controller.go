@@ -1,28 +1,50 @@
 package notification_channel
 
 import (
+	"context"
 	"net/http"
+	"peekaping/internal/modules/audit_log"
 	"peekaping/internal/modules/heartbeat"
 	"peekaping/internal/modules/monitor"
 	"peekaping/internal/modules/shared"
 	"peekaping/internal/utils"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
 type Controller struct {
-	service Service
-	logger  *zap.SugaredLogger
+	service         Service
+	logger          *zap.SugaredLogger
+	auditLogService audit_log.Service
 }
 
 func NewController(
 	service Service,
 	logger *zap.SugaredLogger,
+	auditLogService audit_log.Service,
 ) *Controller {
 	return &Controller{
 		service,
 		logger,
+		auditLogService,
+	}
+}
+
+// recordAudit writes an audit log entry for a notification channel change.
+// Failures are only logged so that auditing never fails the request itself.
+func (ic *Controller) recordAudit(ctx *gin.Context, id, action string, before, after *Model) {
+	var beforeValue, afterValue any
+	if before != nil {
+		beforeValue = before
+	}
+	if after != nil {
+		afterValue = after
+	}
+
+	if _, err := ic.auditLogService.Record(ctx, audit_log.EntityNotificationChannel, id, action, beforeValue, afterValue); err != nil {
+		ic.logger.Errorw("Failed to record notification channel audit log", "id", id, "error", err)
 	}
 }
 
@@ -99,6 +121,10 @@ func (ic *Controller) Create(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, utils.NewFailResponse("Invalid config: "+err.Error()))
 		return
 	}
+	if err := validateChannelOptions(notification_channel.Config); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewFailResponse("Invalid config: "+err.Error()))
+		return
+	}
 
 	createdNotification, err := ic.service.Create(ctx, notification_channel)
 	if err != nil {
@@ -107,6 +133,8 @@ func (ic *Controller) Create(ctx *gin.Context) {
 		return
 	}
 
+	ic.recordAudit(ctx, createdNotification.ID, audit_log.ActionCreate, nil, createdNotification)
+
 	ctx.JSON(http.StatusCreated, utils.NewSuccessResponse("Notification created successfully", createdNotification))
 }
 
@@ -164,6 +192,18 @@ func (ic *Controller) UpdateFull(ctx *gin.Context) {
 		return
 	}
 
+	if err := validateChannelOptions(notification.Config); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewFailResponse("Invalid config: "+err.Error()))
+		return
+	}
+
+	previousNotification, err := ic.service.FindByID(ctx, id)
+	if err != nil {
+		ic.logger.Errorw("Failed to fetch notification before update", "error", err)
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
+		return
+	}
+
 	updatedNotification, err := ic.service.UpdateFull(ctx, id, &notification)
 	if err != nil {
 		ic.logger.Errorw("Failed to update notification", "error", err)
@@ -171,6 +211,8 @@ func (ic *Controller) UpdateFull(ctx *gin.Context) {
 		return
 	}
 
+	ic.recordAudit(ctx, id, audit_log.ActionUpdate, previousNotification, updatedNotification)
+
 	ctx.JSON(http.StatusOK, utils.NewSuccessResponse("notification updated successfully", updatedNotification))
 }
 
@@ -201,6 +243,13 @@ func (ic *Controller) UpdatePartial(ctx *gin.Context) {
 		return
 	}
 
+	previousNotification, err := ic.service.FindByID(ctx, id)
+	if err != nil {
+		ic.logger.Errorw("Failed to fetch notification before update", "error", err)
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
+		return
+	}
+
 	updatedNotification, err := ic.service.UpdatePartial(ctx, id, &notification)
 	if err != nil {
 		ic.logger.Errorw("Failed to update notification", "error", err)
@@ -208,6 +257,8 @@ func (ic *Controller) UpdatePartial(ctx *gin.Context) {
 		return
 	}
 
+	ic.recordAudit(ctx, id, audit_log.ActionUpdate, previousNotification, updatedNotification)
+
 	ctx.JSON(http.StatusOK, utils.NewSuccessResponse("notification updated successfully", updatedNotification))
 }
 
@@ -224,13 +275,24 @@ func (ic *Controller) UpdatePartial(ctx *gin.Context) {
 func (ic *Controller) Delete(ctx *gin.Context) {
 	id := ctx.Param("id")
 
-	err := ic.service.Delete(ctx, id)
+	previousNotification, err := ic.service.FindByID(ctx, id)
+	if err != nil {
+		ic.logger.Errorw("Failed to fetch notification before delete", "error", err)
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
+		return
+	}
+
+	err = ic.service.Delete(ctx, id)
 	if err != nil {
 		ic.logger.Errorw("Failed to delete notification", "error", err)
 		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
 		return
 	}
 
+	if previousNotification != nil {
+		ic.recordAudit(ctx, id, audit_log.ActionDelete, previousNotification, nil)
+	}
+
 	ctx.JSON(http.StatusOK, utils.NewSuccessResponse[any]("Notification deleted successfully", nil))
 }
 
@@ -269,7 +331,69 @@ func (ic *Controller) Test(ctx *gin.Context) {
 		return
 	}
 
-	// Create a test message and monitor for the notification
+	err = sendTestNotification(ctx, integration, notificationChannel.Config)
+	if err != nil {
+		ic.logger.Errorw("Failed to send test notification", "error", err)
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Failed to send test notification: "+err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewSuccessResponse[any]("Test notification sent successfully", nil))
+}
+
+// @Router		/notification-channels/{id}/test [post]
+// @Summary		Test saved notification channel
+// @Tags			Notification channels
+// @Produce		json
+// @Security  JwtAuth
+// @Security  ApiKeyAuth
+// @Param       id   path      string  true  "Notification ID"
+// @Success		200	{object}	utils.ApiResponse[any]
+// @Failure		400	{object}	utils.APIError[any]
+// @Failure		404	{object}	utils.APIError[any]
+// @Failure		500	{object}	utils.APIError[any]
+func (ic *Controller) TestByID(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	notificationChannel, err := ic.service.FindByID(ctx, id)
+	if err != nil {
+		ic.logger.Errorw("Failed to fetch notification", "error", err)
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
+		return
+	}
+
+	if notificationChannel == nil {
+		ctx.JSON(http.StatusNotFound, utils.NewFailResponse("Notification not found"))
+		return
+	}
+
+	integration, ok := GetNotificationChannelProvider(notificationChannel.Type)
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, utils.NewFailResponse("Unsupported notification type"))
+		return
+	}
+
+	configJSON := ""
+	if notificationChannel.Config != nil {
+		configJSON = *notificationChannel.Config
+	}
+	if err := integration.Validate(configJSON); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewFailResponse("Invalid config: "+err.Error()))
+		return
+	}
+
+	if err := sendTestNotification(ctx, integration, configJSON); err != nil {
+		ic.logger.Errorw("Failed to send test notification", "id", id, "error", err)
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Failed to send test notification: "+err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewSuccessResponse[any]("Test notification sent successfully", nil))
+}
+
+// sendTestNotification sends a test message about a synthetic down heartbeat of a
+// synthetic monitor through the provider
+func sendTestNotification(ctx context.Context, integration NotificationChannelProvider, configJSON string) error {
 	testMessage := "This is a test notification from Peekaping"
 	testMonitor := &monitor.Model{
 		Name: "Test Monitor",
@@ -278,15 +402,8 @@ func (ic *Controller) Test(ctx *gin.Context) {
 	testHeartbeat := &heartbeat.Model{
 		Status: shared.MonitorStatusDown,
 		Msg:    testMessage,
+		Time:   time.Now().UTC(),
 	}
 
-	// Send the test notification
-	err = integration.Send(ctx, notificationChannel.Config, testMessage, testMonitor, testHeartbeat)
-	if err != nil {
-		ic.logger.Errorw("Failed to send test notification", "error", err)
-		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Failed to send test notification: "+err.Error()))
-		return
-	}
-
-	ctx.JSON(http.StatusOK, utils.NewSuccessResponse[any]("Test notification sent successfully", nil))
+	return integration.Send(ctx, configJSON, testMessage, testMonitor, testHeartbeat)
 }
@@ -0,0 +1,86 @@
+package notification_channel
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"peekaping/internal/modules/heartbeat"
+	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/shared"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+func TestController_TestByID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Cleanup(func() { delete(NotificationChannelProviderRegistry, "mock_test_by_id") })
+
+	validConfig := `{"url":"https://hooks.example.com"}`
+	invalidConfig := `{}`
+	newProvider := func() *MockProvider {
+		provider := new(MockProvider)
+		provider.On("Validate", validConfig).Return(nil)
+		provider.On("Validate", invalidConfig).Return(errors.New("url is required"))
+		RegisterNotificationChannelProvider("mock_test_by_id", provider)
+		return provider
+	}
+
+	repo := new(MockRepository)
+	repo.On("FindByID", mock.Anything, "chan-ok").Return(&Model{ID: "chan-ok", Type: "mock_test_by_id", Config: &validConfig}, nil)
+	repo.On("FindByID", mock.Anything, "chan-invalid").Return(&Model{ID: "chan-invalid", Type: "mock_test_by_id", Config: &invalidConfig}, nil)
+	repo.On("FindByID", mock.Anything, "chan-missing").Return(nil, nil)
+
+	controller := NewController(createTestService(repo, new(MockMonitorNotificationService)), zap.NewNop().Sugar(), nil)
+	router := gin.New()
+	router.POST("/notification-channels/:id/test", controller.TestByID)
+
+	send := func(id string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/notification-channels/"+id+"/test", nil)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("sends a test notification", func(t *testing.T) {
+		provider := newProvider()
+		provider.On("Send", mock.Anything, validConfig, mock.Anything,
+			mock.MatchedBy(func(m *monitor.Model) bool { return m.Name == "Test Monitor" }),
+			mock.MatchedBy(func(hb *heartbeat.Model) bool {
+				return hb.Status == shared.MonitorStatusDown && !hb.Time.IsZero()
+			})).Return(nil)
+
+		w := send("chan-ok")
+		assert.Equal(t, http.StatusOK, w.Code)
+		provider.AssertCalled(t, "Send", mock.Anything, validConfig, "This is a test notification from Peekaping", mock.Anything, mock.Anything)
+	})
+
+	t.Run("returns the provider error", func(t *testing.T) {
+		provider := newProvider()
+		provider.On("Send", mock.Anything, validConfig, mock.Anything, mock.Anything, mock.Anything).
+			Return(errors.New("webhook returned status code: 403"))
+
+		w := send("chan-ok")
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Contains(t, w.Body.String(), "webhook returned status code: 403")
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		provider := newProvider()
+
+		w := send("chan-invalid")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Invalid config: url is required")
+		provider.AssertNotCalled(t, "Send", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown channel", func(t *testing.T) {
+		w := send("chan-missing")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
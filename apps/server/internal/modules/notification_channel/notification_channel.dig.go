@@ -2,6 +2,7 @@ package notification_channel
 
 import (
 	"peekaping/internal/config"
+	"peekaping/internal/modules/monitor"
 	"peekaping/internal/utils"
 
 	"go.uber.org/dig"
@@ -10,7 +11,10 @@ import (
 func RegisterDependencies(container *dig.Container, cfg *config.Config) {
 	utils.RegisterRepositoryByDBType(container, cfg, NewSQLRepository, NewMongoRepository)
 	container.Provide(NewService)
+	container.Provide(func(service Service) monitor.NotificationChannelNames { return service })
 	container.Provide(NewController)
 	container.Provide(NewRoute)
+	container.Provide(ProvideNotificationMetrics)
 	container.Provide(NewNotificationEventListener)
+	container.Provide(NewNotificationWorker)
 }
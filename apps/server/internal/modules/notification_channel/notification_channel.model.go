@@ -23,3 +23,7 @@ type UpdateModel struct {
 	CreatedAt *time.Time `json:"created_at"`
 	UpdatedAt *time.Time `json:"updated_at"`
 }
+
+// AdminNotificationChannelSettingKey is the global setting holding the id of the channel
+// notified when monitors are created or deleted. Unset or empty disables it.
+const AdminNotificationChannelSettingKey = "admin_notification_channel_id"
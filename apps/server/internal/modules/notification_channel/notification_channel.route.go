@@ -36,4 +36,5 @@ func (uc *Route) ConnectRoute(
 	router.PUT("/:id", controller.UpdateFull)
 	router.PATCH("/:id", controller.UpdatePartial)
 	router.DELETE("/:id", controller.Delete)
+	router.POST("/:id/test", controller.TestByID)
 }
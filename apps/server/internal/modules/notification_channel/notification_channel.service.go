@@ -14,6 +14,8 @@ type Service interface {
 	UpdateFull(ctx context.Context, id string, entity *CreateUpdateDto) (*Model, error)
 	UpdatePartial(ctx context.Context, id string, entity *PartialUpdateDto) (*Model, error)
 	Delete(ctx context.Context, id string) error
+	// ChannelNames returns the names of all notification channels by id
+	ChannelNames(ctx context.Context) (map[string]string, error)
 }
 
 type ServiceImpl struct {
@@ -116,3 +118,20 @@ func (mr *ServiceImpl) Delete(ctx context.Context, id string) error {
 
 	return nil
 }
+
+func (mr *ServiceImpl) ChannelNames(ctx context.Context) (map[string]string, error) {
+	const pageSize = 100
+	names := make(map[string]string)
+	for page := 0; ; page++ {
+		entities, err := mr.repository.FindAll(ctx, page, pageSize, "")
+		if err != nil {
+			return nil, err
+		}
+		for _, entity := range entities {
+			names[entity.ID] = entity.Name
+		}
+		if len(entities) < pageSize {
+			return names, nil
+		}
+	}
+}
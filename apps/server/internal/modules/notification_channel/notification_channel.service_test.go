@@ -62,8 +62,8 @@ type MockMonitorNotificationService struct {
 	mock.Mock
 }
 
-func (m *MockMonitorNotificationService) Create(ctx context.Context, monitorID string, notificationID string) (*monitor_notification.Model, error) {
-	args := m.Called(ctx, monitorID, notificationID)
+func (m *MockMonitorNotificationService) Create(ctx context.Context, monitorID string, notificationID string, settings monitor_notification.Settings) (*monitor_notification.Model, error) {
+	args := m.Called(ctx, monitorID, notificationID, settings)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -101,6 +101,36 @@ func (m *MockMonitorNotificationService) DeleteByNotificationID(ctx context.Cont
 	return args.Error(0)
 }
 
+func (m *MockMonitorNotificationService) SetMinCriticality(ctx context.Context, id string, minCriticality string) error {
+	args := m.Called(ctx, id, minCriticality)
+	return args.Error(0)
+}
+
+func (m *MockMonitorNotificationService) SetEventTypes(ctx context.Context, id string, eventTypes []string) error {
+	args := m.Called(ctx, id, eventTypes)
+	return args.Error(0)
+}
+
+func (m *MockMonitorNotificationService) SetDigestOnly(ctx context.Context, id string, digestOnly bool) error {
+	args := m.Called(ctx, id, digestOnly)
+	return args.Error(0)
+}
+
+func (m *MockMonitorNotificationService) SetEscalateAfter(ctx context.Context, id string, escalateAfter int) error {
+	args := m.Called(ctx, id, escalateAfter)
+	return args.Error(0)
+}
+
+func (m *MockMonitorNotificationService) SetNotificationLimit(ctx context.Context, id string, notificationLimit int) error {
+	args := m.Called(ctx, id, notificationLimit)
+	return args.Error(0)
+}
+
+func (m *MockMonitorNotificationService) SetOutageNotifications(ctx context.Context, id string, outageNotifications int) error {
+	args := m.Called(ctx, id, outageNotifications)
+	return args.Error(0)
+}
+
 // Helper function to create a test service
 func createTestService(mockRepo *MockRepository, mockMonitorNotificationService *MockMonitorNotificationService) Service {
 	logger, _ := zap.NewDevelopment()
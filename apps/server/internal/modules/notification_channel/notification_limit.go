@@ -0,0 +1,49 @@
+package notification_channel
+
+import (
+	"context"
+	"peekaping/internal/modules/heartbeat"
+	"peekaping/internal/modules/monitor_notification"
+	"peekaping/internal/modules/shared"
+)
+
+// overNotificationLimit reports whether the channel of a monitor-notification link already
+// got all the down notifications its notification_limit allows for the current outage.
+// Otherwise the heartbeat is counted as one more notification of the outage. Recoveries
+// and links without a limit are never over it. An important heartbeat starts a new
+// outage, in case its recovery fell within a maintenance window and wasn't counted.
+func (l *NotificationEventListener) overNotificationLimit(ctx context.Context, mn *monitor_notification.Model, hb *heartbeat.Model) bool {
+	if mn == nil || mn.NotificationLimit <= 0 || hb.Status != shared.MonitorStatusDown {
+		return false
+	}
+	if hb.Important {
+		mn.OutageNotifications = 0
+	}
+	if mn.OutageNotifications >= mn.NotificationLimit {
+		return true
+	}
+
+	mn.OutageNotifications++
+	if err := l.monitorNotificationService.SetOutageNotifications(ctx, mn.ID, mn.OutageNotifications); err != nil {
+		l.logger.Warnf("Failed to count the outage notifications of monitor-notification %s: %v", mn.ID, err)
+	}
+	return false
+}
+
+// resetOutageNotifications clears the notification counts of the links of a recovered
+// monitor, so its next outage is notified again
+func (l *NotificationEventListener) resetOutageNotifications(ctx context.Context, monitorNotifications []*monitor_notification.Model, hb *heartbeat.Model) {
+	if hb.Status != shared.MonitorStatusUp {
+		return
+	}
+	for _, mn := range monitorNotifications {
+		if mn.OutageNotifications == 0 {
+			continue
+		}
+		if err := l.monitorNotificationService.SetOutageNotifications(ctx, mn.ID, 0); err != nil {
+			l.logger.Warnf("Failed to reset the outage notifications of monitor-notification %s: %v", mn.ID, err)
+			continue
+		}
+		mn.OutageNotifications = 0
+	}
+}
@@ -0,0 +1,92 @@
+package notification_channel
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"peekaping/internal/modules/events"
+	"peekaping/internal/modules/heartbeat"
+	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/monitor_notification"
+	"peekaping/internal/modules/shared"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+func TestHandleNotifyEvent_NotificationLimit(t *testing.T) {
+	mon := &monitor.Model{ID: "mon-1", Name: "API"}
+	start := time.Date(2025, 10, 6, 10, 0, 0, 0, time.UTC)
+	down, up := shared.MonitorStatusDown, shared.MonitorStatusUp
+
+	t.Cleanup(func() { delete(NotificationChannelProviderRegistry, "mock_limit") })
+
+	plainConfig, limitedConfig := `{"url":"https://plain.example.com"}`, `{"url":"https://limited.example.com"}`
+	provider := new(MockProvider)
+	RegisterNotificationChannelProvider("mock_limit", provider)
+	repo := new(MockRepository)
+	monitorSvc := new(MockMonitorService)
+	monitorNotificationSvc := new(MockMonitorNotificationService)
+	heartbeatSvc := new(MockHeartbeatService)
+
+	// outageNotifications is the count stored for the limited link
+	outageNotifications := 0
+	monitorNotificationSvc.On("SetOutageNotifications", mock.Anything, "mn-limited", mock.Anything).
+		Run(func(args mock.Arguments) { outageNotifications = args.Int(2) }).
+		Return(nil)
+	repo.On("FindByID", mock.Anything, "chan-plain").Return(&Model{ID: "chan-plain", Name: "Plain", Type: "mock_limit", Active: true, Config: &plainConfig}, nil)
+	repo.On("FindByID", mock.Anything, "chan-limited").Return(&Model{ID: "chan-limited", Name: "Limited", Type: "mock_limit", Active: true, Config: &limitedConfig}, nil)
+	monitorSvc.On("FindByID", mock.Anything, "mon-1").Return(mon, nil)
+	provider.On("Validate", mock.Anything).Return(nil)
+	provider.On("Send", mock.Anything, mock.Anything, mock.Anything, mon, mock.Anything).Return(nil)
+	heartbeatSvc.On("FindByMonitorIDPaginated", mock.Anything, "mon-1", mock.Anything, 0, mock.Anything, false).Return([]*heartbeat.Model{}, nil)
+
+	l := &NotificationEventListener{
+		service:                    createTestService(repo, monitorNotificationSvc),
+		monitorSvc:                 monitorSvc,
+		heartbeatService:           heartbeatSvc,
+		monitorNotificationService: monitorNotificationSvc,
+		logger:                     zap.NewNop().Sugar(),
+	}
+
+	sentTo := func(config string) int {
+		n := 0
+		for _, call := range provider.Calls {
+			if call.Method == "Send" && call.Arguments.Get(1) == config {
+				n++
+			}
+		}
+		return n
+	}
+
+	// Two outages with resends of the down notification, the limited channel gets at most
+	// three down notifications per outage and every recovery
+	beats := []struct {
+		status    shared.MonitorStatus
+		important bool
+	}{
+		{down, true}, {down, false}, {down, false}, {down, false}, {down, false}, {up, true}, {down, true}, {down, false},
+	}
+	expected := []struct{ plain, limited int }{{1, 1}, {2, 2}, {3, 3}, {4, 3}, {5, 3}, {6, 4}, {7, 5}, {8, 6}}
+	for i, beat := range beats {
+		monitorNotificationSvc.ExpectedCalls = monitorNotificationSvc.ExpectedCalls[:1]
+		monitorNotificationSvc.On("FindByMonitorID", mock.Anything, "mon-1").Return([]*monitor_notification.Model{
+			{ID: "mn-plain", MonitorID: "mon-1", NotificationID: "chan-plain"},
+			{ID: "mn-limited", MonitorID: "mon-1", NotificationID: "chan-limited", NotificationLimit: 3, OutageNotifications: outageNotifications},
+		}, nil)
+
+		hb := &heartbeat.Model{
+			ID: fmt.Sprintf("hb-%d", i), MonitorID: "mon-1", Status: beat.status, Msg: "connection refused",
+			Time: start.Add(time.Duration(i) * time.Minute), Important: beat.important, Notified: true,
+		}
+		l.handleNotifyEvent(events.Event{Type: events.ImportantHeartbeat, Payload: hb})
+
+		assert.Equal(t, expected[i].plain, sentTo(plainConfig), "plain after heartbeat %d", i)
+		assert.Equal(t, expected[i].limited, sentTo(limitedConfig), "limited after heartbeat %d", i)
+	}
+
+	// The recovery reset the count, the second outage counts from there
+	assert.Equal(t, 2, outageNotifications)
+}
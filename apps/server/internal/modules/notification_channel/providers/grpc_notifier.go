@@ -0,0 +1,199 @@
+package providers
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"peekaping/internal/modules/heartbeat"
+	"peekaping/internal/modules/monitor"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// GRPCNotifierMethod is the Notify RPC of the Notifier service, see grpc_notifier.proto
+const GRPCNotifierMethod = "/peekaping.notifier.v1.Notifier/Notify"
+
+const (
+	grpcNotifierMaxRetries  = 3
+	grpcNotifierRetryDelay  = 500 * time.Millisecond
+	grpcNotifierMaxDelay    = 5 * time.Second
+	grpcNotifierCallTimeout = 10 * time.Second
+)
+
+type GRPCNotifierConfig struct {
+	// Endpoint is the host:port of the gateway
+	Endpoint  string `json:"endpoint" validate:"required,hostname_port"`
+	UseTLS    bool   `json:"use_tls"`
+	AuthToken string `json:"auth_token"`
+	// TLSCACert is a PEM bundle the server certificate is verified against instead of the
+	// system roots
+	TLSCACert     string `json:"tls_ca_cert"`
+	TLSSkipVerify bool   `json:"tls_skip_verify"`
+}
+
+type GRPCNotifierSender struct {
+	logger     *zap.SugaredLogger
+	maxRetries int
+	retryDelay time.Duration
+}
+
+// NewGRPCNotifierSender creates a GRPCNotifierSender
+func NewGRPCNotifierSender(logger *zap.SugaredLogger) *GRPCNotifierSender {
+	return &GRPCNotifierSender{
+		logger:     logger,
+		maxRetries: grpcNotifierMaxRetries,
+		retryDelay: grpcNotifierRetryDelay,
+	}
+}
+
+func (g *GRPCNotifierSender) Unmarshal(configJSON string) (any, error) {
+	return GenericUnmarshal[GRPCNotifierConfig](configJSON)
+}
+
+func (g *GRPCNotifierSender) Validate(configJSON string) error {
+	cfg, err := g.Unmarshal(configJSON)
+	if err != nil {
+		return err
+	}
+	grpcCfg := cfg.(*GRPCNotifierConfig)
+
+	if !grpcCfg.UseTLS && (grpcCfg.TLSCACert != "" || grpcCfg.TLSSkipVerify) {
+		return fmt.Errorf("tls_ca_cert and tls_skip_verify require use_tls")
+	}
+	if grpcCfg.TLSCACert != "" && !x509.NewCertPool().AppendCertsFromPEM([]byte(grpcCfg.TLSCACert)) {
+		return fmt.Errorf("tls_ca_cert contains no valid PEM certificate")
+	}
+
+	return GenericValidator(grpcCfg)
+}
+
+func (g *GRPCNotifierSender) Send(
+	ctx context.Context,
+	configJSON string,
+	message string,
+	monitor *monitor.Model,
+	heartbeat *heartbeat.Model,
+) error {
+	cfgAny, err := g.Unmarshal(configJSON)
+	if err != nil {
+		return err
+	}
+	cfg := cfgAny.(*GRPCNotifierConfig)
+
+	g.logger.Infof("Sending gRPC notification to: %s", cfg.Endpoint)
+
+	request, err := grpcNotifierRequest(message, monitor, heartbeat)
+	if err != nil {
+		return err
+	}
+
+	transportCredentials, err := grpcNotifierCredentials(cfg)
+	if err != nil {
+		return err
+	}
+	conn, err := grpc.NewClient(cfg.Endpoint, grpc.WithTransportCredentials(transportCredentials))
+	if err != nil {
+		return fmt.Errorf("failed to create gRPC client: %w", err)
+	}
+	defer conn.Close()
+
+	if cfg.AuthToken != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+cfg.AuthToken)
+	}
+
+	delay := g.retryDelay
+	for attempt := 0; ; attempt++ {
+		err = g.notify(ctx, conn, request)
+		if err == nil {
+			g.logger.Infof("gRPC notification sent successfully to: %s", cfg.Endpoint)
+			return nil
+		}
+		if attempt >= g.maxRetries || !isTransientGRPCError(err) {
+			return fmt.Errorf("gRPC notification to %s failed after %d attempt(s): %w", cfg.Endpoint, attempt+1, err)
+		}
+
+		g.logger.Warnw("gRPC notification failed, retrying", "endpoint", cfg.Endpoint, "attempt", attempt+1, "delay", delay, "error", err)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("gRPC notification to %s canceled: %w", cfg.Endpoint, ctx.Err())
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, grpcNotifierMaxDelay)
+	}
+}
+
+func (g *GRPCNotifierSender) notify(ctx context.Context, conn *grpc.ClientConn, request *structpb.Struct) error {
+	callCtx, cancel := context.WithTimeout(ctx, grpcNotifierCallTimeout)
+	defer cancel()
+
+	return conn.Invoke(callCtx, GRPCNotifierMethod, request, &emptypb.Empty{})
+}
+
+// isTransientGRPCError reports whether a failed call may succeed when repeated
+func isTransientGRPCError(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted, codes.Aborted, codes.DeadlineExceeded:
+		return true
+	}
+	return false
+}
+
+func grpcNotifierCredentials(cfg *GRPCNotifierConfig) (credentials.TransportCredentials, error) {
+	if !cfg.UseTLS {
+		return insecure.NewCredentials(), nil
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: cfg.TLSSkipVerify}
+	if cfg.TLSCACert != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(cfg.TLSCACert)) {
+			return nil, fmt.Errorf("tls_ca_cert contains no valid PEM certificate")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+// grpcNotifierRequest builds the Notify request, with the same fields as the JSON webhook
+func grpcNotifierRequest(message string, monitor *monitor.Model, heartbeat *heartbeat.Model) (*structpb.Struct, error) {
+	data := map[string]any{
+		"msg": message,
+	}
+	if monitor != nil {
+		data["monitor"] = monitor
+	}
+	if heartbeat != nil {
+		data["heartbeat"] = heartbeat
+	}
+
+	// Round trip through JSON so the models become plain maps structpb accepts
+	jsonBytes, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal notification: %w", err)
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(jsonBytes, &fields); err != nil {
+		return nil, fmt.Errorf("failed to marshal notification: %w", err)
+	}
+
+	request, err := structpb.NewStruct(fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build gRPC request: %w", err)
+	}
+	return request, nil
+}
@@ -0,0 +1,167 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"peekaping/internal/modules/heartbeat"
+	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/shared"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// fakeNotifier implements the Notifier service of grpc_notifier.proto. The first len(failures)
+// calls fail with the given codes.
+type fakeNotifier struct {
+	mu        sync.Mutex
+	failures  []codes.Code
+	calls     int
+	delivered []*structpb.Struct
+	auth      []string
+}
+
+func (n *fakeNotifier) notify(ctx context.Context, request *structpb.Struct) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.calls++
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		n.auth = append(n.auth, md.Get("authorization")...)
+	}
+	if n.calls <= len(n.failures) {
+		return status.Error(n.failures[n.calls-1], "gateway busy")
+	}
+	n.delivered = append(n.delivered, request)
+	return nil
+}
+
+// startFakeNotifier serves notifier on a local port and returns its address
+func startFakeNotifier(t *testing.T, notifier *fakeNotifier) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "peekaping.notifier.v1.Notifier",
+		HandlerType: (*any)(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: "Notify",
+			Handler: func(_ any, ctx context.Context, dec func(any) error, _ grpc.UnaryServerInterceptor) (any, error) {
+				request := &structpb.Struct{}
+				if err := dec(request); err != nil {
+					return nil, err
+				}
+				if err := notifier.notify(ctx, request); err != nil {
+					return nil, err
+				}
+				return &emptypb.Empty{}, nil
+			},
+		}},
+	}, notifier)
+
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+	return listener.Addr().String()
+}
+
+func newTestGRPCNotifierSender() *GRPCNotifierSender {
+	sender := NewGRPCNotifierSender(zap.NewNop().Sugar())
+	sender.retryDelay = time.Millisecond
+	return sender
+}
+
+func TestGRPCNotifierSender_Validate(t *testing.T) {
+	sender := NewGRPCNotifierSender(zap.NewNop().Sugar())
+
+	tests := []struct {
+		name    string
+		config  string
+		wantErr bool
+	}{
+		{"plaintext", `{"endpoint": "alerts.internal:9000"}`, false},
+		{"tls with token", `{"endpoint": "alerts.internal:9000", "use_tls": true, "auth_token": "secret"}`, false},
+		{"missing endpoint", `{"use_tls": true}`, true},
+		{"endpoint without port", `{"endpoint": "alerts.internal"}`, true},
+		{"tls option without tls", `{"endpoint": "alerts.internal:9000", "tls_skip_verify": true}`, true},
+		{"invalid ca", `{"endpoint": "alerts.internal:9000", "use_tls": true, "tls_ca_cert": "not a certificate"}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := sender.Validate(tt.config)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestGRPCNotifierSender_Send(t *testing.T) {
+	notifier := &fakeNotifier{}
+	endpoint := startFakeNotifier(t, notifier)
+
+	config := fmt.Sprintf(`{"endpoint": %q, "auth_token": "secret"}`, endpoint)
+	mon := &monitor.Model{ID: "monitor-1", Name: "API"}
+	hb := &heartbeat.Model{MonitorID: "monitor-1", Status: shared.MonitorStatusDown, Msg: "connection refused"}
+
+	err := newTestGRPCNotifierSender().Send(context.Background(), config, "API is down", mon, hb)
+	require.NoError(t, err)
+
+	require.Len(t, notifier.delivered, 1)
+	request := notifier.delivered[0].AsMap()
+	assert.Equal(t, "API is down", request["msg"])
+	assert.Equal(t, "API", request["monitor"].(map[string]any)["name"])
+	assert.Equal(t, "connection refused", request["heartbeat"].(map[string]any)["msg"])
+	assert.Equal(t, []string{"Bearer secret"}, notifier.auth)
+}
+
+func TestGRPCNotifierSender_RetriesUnavailable(t *testing.T) {
+	notifier := &fakeNotifier{failures: []codes.Code{codes.Unavailable, codes.Unavailable}}
+	endpoint := startFakeNotifier(t, notifier)
+
+	err := newTestGRPCNotifierSender().Send(context.Background(), fmt.Sprintf(`{"endpoint": %q}`, endpoint), "API is down", nil, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, notifier.calls)
+	require.Len(t, notifier.delivered, 1)
+	assert.Equal(t, "API is down", notifier.delivered[0].AsMap()["msg"])
+}
+
+func TestGRPCNotifierSender_GivesUpAfterRetries(t *testing.T) {
+	notifier := &fakeNotifier{failures: []codes.Code{codes.Unavailable, codes.Unavailable, codes.Unavailable, codes.Unavailable, codes.Unavailable}}
+	endpoint := startFakeNotifier(t, notifier)
+
+	err := newTestGRPCNotifierSender().Send(context.Background(), fmt.Sprintf(`{"endpoint": %q}`, endpoint), "API is down", nil, nil)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed after 4 attempt(s)")
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, 4, notifier.calls)
+	assert.Empty(t, notifier.delivered)
+}
+
+func TestGRPCNotifierSender_DoesNotRetryPermanentErrors(t *testing.T) {
+	notifier := &fakeNotifier{failures: []codes.Code{codes.InvalidArgument}}
+	endpoint := startFakeNotifier(t, notifier)
+
+	err := newTestGRPCNotifierSender().Send(context.Background(), fmt.Sprintf(`{"endpoint": %q}`, endpoint), "API is down", nil, nil)
+
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, 1, notifier.calls)
+}
@@ -0,0 +1,129 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"peekaping/internal/modules/heartbeat"
+	"peekaping/internal/modules/monitor"
+	"peekaping/internal/version"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	MatrixWebhookFormatText = "text"
+	MatrixWebhookFormatHTML = "html"
+)
+
+// MatrixWebhookConfig posts to a Matrix webhook bridge such as hookshot or the maubot
+// webhook plugin, which deliver to the room the webhook was created for
+type MatrixWebhookConfig struct {
+	WebhookURL string `json:"webhook_url" validate:"required,url,startswith=http"`
+	// Format is "text" (the default) or "html", which also sends an HTML body
+	Format string `json:"format" validate:"omitempty,oneof=text html"`
+}
+
+type MatrixWebhookSender struct {
+	logger *zap.SugaredLogger
+	client *http.Client
+}
+
+// NewMatrixWebhookSender creates a MatrixWebhookSender
+func NewMatrixWebhookSender(logger *zap.SugaredLogger) *MatrixWebhookSender {
+	return &MatrixWebhookSender{
+		logger: logger,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+func (m *MatrixWebhookSender) Unmarshal(configJSON string) (any, error) {
+	return GenericUnmarshal[MatrixWebhookConfig](configJSON)
+}
+
+func (m *MatrixWebhookSender) Validate(configJSON string) error {
+	cfg, err := m.Unmarshal(configJSON)
+	if err != nil {
+		return err
+	}
+	return GenericValidator(cfg.(*MatrixWebhookConfig))
+}
+
+func (m *MatrixWebhookSender) Send(
+	ctx context.Context,
+	configJSON string,
+	message string,
+	monitor *monitor.Model,
+	heartbeat *heartbeat.Model,
+) error {
+	cfgAny, err := m.Unmarshal(configJSON)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	cfg := cfgAny.(*MatrixWebhookConfig)
+
+	m.logger.Infof("Sending Matrix webhook notification")
+
+	jsonData, err := json.Marshal(m.buildPayload(cfg, message, monitor, heartbeat))
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", cfg.WebhookURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Peekaping-Matrix-Webhook/"+version.Version)
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send Matrix webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("Matrix webhook returned status code: %d", resp.StatusCode)
+	}
+
+	m.logger.Infof("Matrix webhook notification sent successfully")
+	return nil
+}
+
+// buildPayload renders the webhook body. Bridges post "text" (and "html" when set) to
+// the room; the monitor fields are there for bridges templating their own message.
+func (m *MatrixWebhookSender) buildPayload(cfg *MatrixWebhookConfig, message string, mon *monitor.Model, hb *heartbeat.Model) map[string]any {
+	payload := map[string]any{
+		"text": message,
+	}
+
+	if mon != nil {
+		payload["monitor"] = mon.Name
+	}
+	if hb != nil {
+		payload["status"] = humanReadableStatus(int(hb.Status))
+		payload["time"] = hb.Time.UTC().Format(time.RFC3339)
+	}
+
+	if cfg.Format == MatrixWebhookFormatHTML {
+		var sb strings.Builder
+		if mon != nil && hb != nil {
+			fmt.Fprintf(&sb, "<p>%s <strong>%s</strong> is %s</p>",
+				humanReadableStatusIcons(int(hb.Status)), html.EscapeString(mon.Name), humanReadableStatus(int(hb.Status)))
+		}
+		sb.WriteString("<p>")
+		sb.WriteString(strings.ReplaceAll(html.EscapeString(message), "\n", "<br>"))
+		sb.WriteString("</p>")
+		payload["html"] = sb.String()
+	}
+
+	return payload
+}
@@ -0,0 +1,107 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"peekaping/internal/modules/heartbeat"
+	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/shared"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestMatrixWebhookConfig_Validate(t *testing.T) {
+	sender := NewMatrixWebhookSender(zap.NewNop().Sugar())
+
+	tests := []struct {
+		name    string
+		config  string
+		wantErr bool
+	}{
+		{name: "valid webhook url", config: `{"webhook_url": "https://hookshot.example.com/webhook/abc"}`},
+		{name: "html format", config: `{"webhook_url": "https://hookshot.example.com/webhook/abc", "format": "html"}`},
+		{name: "missing url", config: `{}`, wantErr: true},
+		{name: "not a url", config: `{"webhook_url": "not-a-url"}`, wantErr: true},
+		{name: "not http", config: `{"webhook_url": "ftp://example.com/hook"}`, wantErr: true},
+		{name: "unknown format", config: `{"webhook_url": "https://hookshot.example.com/webhook/abc", "format": "xml"}`, wantErr: true},
+		{name: "invalid json", config: `{`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := sender.Validate(tt.config)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestMatrixWebhookSender_BuildPayload(t *testing.T) {
+	sender := NewMatrixWebhookSender(zap.NewNop().Sugar())
+	m := &monitor.Model{ID: "mon-1", Name: "API <prod>", Type: "http"}
+	hb := &heartbeat.Model{Status: shared.MonitorStatusDown, Time: time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC)}
+
+	t.Run("text", func(t *testing.T) {
+		payload := sender.buildPayload(&MatrixWebhookConfig{}, "Request timed out", m, hb)
+
+		assert.Equal(t, map[string]any{
+			"text":    "Request timed out",
+			"monitor": "API <prod>",
+			"status":  "DOWN",
+			"time":    "2025-05-01T10:00:00Z",
+		}, payload)
+	})
+
+	t.Run("html", func(t *testing.T) {
+		payload := sender.buildPayload(&MatrixWebhookConfig{Format: MatrixWebhookFormatHTML}, "Request timed out\n<b>after 5s</b>", m, hb)
+
+		assert.Equal(t, "Request timed out\n<b>after 5s</b>", payload["text"])
+		assert.Equal(t, "<p>❌ <strong>API &lt;prod&gt;</strong> is DOWN</p><p>Request timed out<br>&lt;b&gt;after 5s&lt;/b&gt;</p>", payload["html"])
+	})
+
+	t.Run("without monitor", func(t *testing.T) {
+		payload := sender.buildPayload(&MatrixWebhookConfig{Format: MatrixWebhookFormatHTML}, "Test", nil, nil)
+
+		assert.Equal(t, map[string]any{"text": "Test", "html": "<p>Test</p>"}, payload)
+	})
+}
+
+func TestMatrixWebhookSender_Send(t *testing.T) {
+	var received map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		body, _ := io.ReadAll(r.Body)
+		require.NoError(t, json.Unmarshal(body, &received))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sender := NewMatrixWebhookSender(zap.NewNop().Sugar())
+	m := &monitor.Model{ID: "mon-1", Name: "API", Type: "http"}
+	hb := &heartbeat.Model{Status: shared.MonitorStatusUp, Time: time.Now()}
+
+	err := sender.Send(context.Background(), `{"webhook_url": "`+server.URL+`"}`, "API is up", m, hb)
+	require.NoError(t, err)
+	assert.Equal(t, "API is up", received["text"])
+	assert.Equal(t, "UP", received["status"])
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer failing.Close()
+
+	err = sender.Send(context.Background(), `{"webhook_url": "`+failing.URL+`"}`, "API is up", m, hb)
+	assert.Error(t, err)
+}
@@ -0,0 +1,93 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"peekaping/internal/config"
+	"peekaping/internal/modules/heartbeat"
+	"peekaping/internal/modules/monitor"
+	"peekaping/internal/version"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type TeamsConfig struct {
+	WebhookURL string `json:"webhook_url" validate:"required,url,startswith=https://"`
+}
+
+// TeamsSender posts Adaptive Cards to a Microsoft Teams incoming webhook. The card is the
+// same as the one of TeamsWorkflowSender: red for down, green for up.
+type TeamsSender struct {
+	logger *zap.SugaredLogger
+	client *http.Client
+	config *config.Config
+}
+
+// NewTeamsSender creates a TeamsSender
+func NewTeamsSender(logger *zap.SugaredLogger, config *config.Config) *TeamsSender {
+	return &TeamsSender{
+		logger: logger,
+		config: config,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+func (t *TeamsSender) Unmarshal(configJSON string) (any, error) {
+	return GenericUnmarshal[TeamsConfig](configJSON)
+}
+
+func (t *TeamsSender) Validate(configJSON string) error {
+	cfg, err := t.Unmarshal(configJSON)
+	if err != nil {
+		return err
+	}
+	return GenericValidator(cfg.(*TeamsConfig))
+}
+
+func (t *TeamsSender) Send(
+	ctx context.Context,
+	configJSON string,
+	message string,
+	m *monitor.Model,
+	hb *heartbeat.Model,
+) error {
+	cfgAny, err := t.Unmarshal(configJSON)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	cfg := cfgAny.(*TeamsConfig)
+
+	t.logger.Infof("Sending Teams notification")
+
+	jsonData, err := json.Marshal(teamsAdaptiveCardMessage(message, m, hb, t.config))
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", cfg.WebhookURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Peekaping-Teams/"+version.Version)
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("Teams webhook returned status code: %d", resp.StatusCode)
+	}
+
+	t.logger.Infof("Teams notification sent successfully")
+	return nil
+}
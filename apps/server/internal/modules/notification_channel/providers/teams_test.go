@@ -0,0 +1,101 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"peekaping/internal/config"
+	"peekaping/internal/modules/heartbeat"
+	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/shared"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestTeamsConfig_Validate(t *testing.T) {
+	sender := NewTeamsSender(zap.NewNop().Sugar(), nil)
+
+	tests := []struct {
+		name    string
+		config  string
+		wantErr bool
+	}{
+		{name: "valid webhook url", config: `{"webhook_url": "https://example.webhook.office.com/webhookb2/abc/IncomingWebhook/def/ghi"}`},
+		{name: "missing url", config: `{}`, wantErr: true},
+		{name: "not a url", config: `{"webhook_url": "not-a-url"}`, wantErr: true},
+		{name: "plain http", config: `{"webhook_url": "http://example.com/hook"}`, wantErr: true},
+		{name: "invalid json", config: `{`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := sender.Validate(tt.config)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTeamsSender_Send(t *testing.T) {
+	var received map[string]any
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		body, _ := io.ReadAll(r.Body)
+		require.NoError(t, json.Unmarshal(body, &received))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sender := NewTeamsSender(zap.NewNop().Sugar(), &config.Config{})
+	sender.client = server.Client()
+
+	m := &monitor.Model{ID: "mon-1", Name: "API", Type: "http"}
+	at := time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC)
+	configJSON := `{"webhook_url": "` + server.URL + `"}`
+
+	t.Run("down", func(t *testing.T) {
+		hb := &heartbeat.Model{Status: shared.MonitorStatusDown, Time: at}
+		require.NoError(t, sender.Send(context.Background(), configJSON, "Request timed out", m, hb))
+
+		_, card := decodeAdaptiveCard(t, received)
+		body := card["body"].([]any)
+		assert.Equal(t, "attention", body[0].(map[string]any)["style"])
+		assert.Equal(t, "Request timed out", body[1].(map[string]any)["text"])
+
+		facts := factsOf(card)
+		assert.Equal(t, "API", facts["Monitor"])
+		assert.Equal(t, "DOWN", facts["Status"])
+		assert.Equal(t, "2025-05-01T10:00:00Z", facts["Time"])
+	})
+
+	t.Run("up", func(t *testing.T) {
+		hb := &heartbeat.Model{Status: shared.MonitorStatusUp, Time: at}
+		require.NoError(t, sender.Send(context.Background(), configJSON, "OK", m, hb))
+
+		_, card := decodeAdaptiveCard(t, received)
+		assert.Equal(t, "good", card["body"].([]any)[0].(map[string]any)["style"])
+		assert.Equal(t, "UP", factsOf(card)["Status"])
+	})
+
+	t.Run("webhook error", func(t *testing.T) {
+		failing := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer failing.Close()
+		sender.client = failing.Client()
+
+		err := sender.Send(context.Background(), `{"webhook_url": "`+failing.URL+`"}`, "down", m, nil)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,227 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"peekaping/internal/config"
+	"peekaping/internal/modules/heartbeat"
+	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/shared"
+	"peekaping/internal/version"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type TeamsWorkflowConfig struct {
+	WorkflowURL string `json:"workflow_url" validate:"required,url,startswith=https://"`
+}
+
+// TeamsWorkflowSender posts Adaptive Cards to a Microsoft Teams Workflows (Power Automate)
+// webhook trigger. Unlike the legacy Office 365 connector, Workflows expect a "message"
+// envelope with the card in an attachment.
+type TeamsWorkflowSender struct {
+	logger *zap.SugaredLogger
+	client *http.Client
+	config *config.Config
+}
+
+// NewTeamsWorkflowSender creates a TeamsWorkflowSender
+func NewTeamsWorkflowSender(logger *zap.SugaredLogger, config *config.Config) *TeamsWorkflowSender {
+	return &TeamsWorkflowSender{
+		logger: logger,
+		config: config,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+func (t *TeamsWorkflowSender) Unmarshal(configJSON string) (any, error) {
+	return GenericUnmarshal[TeamsWorkflowConfig](configJSON)
+}
+
+func (t *TeamsWorkflowSender) Validate(configJSON string) error {
+	cfg, err := t.Unmarshal(configJSON)
+	if err != nil {
+		return err
+	}
+	return GenericValidator(cfg.(*TeamsWorkflowConfig))
+}
+
+func (t *TeamsWorkflowSender) Send(
+	ctx context.Context,
+	configJSON string,
+	message string,
+	m *monitor.Model,
+	hb *heartbeat.Model,
+) error {
+	cfgAny, err := t.Unmarshal(configJSON)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	cfg := cfgAny.(*TeamsWorkflowConfig)
+
+	t.logger.Infof("Sending Teams workflow notification")
+
+	jsonData, err := json.Marshal(t.buildPayload(message, m, hb))
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", cfg.WorkflowURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Peekaping-TeamsWorkflow/"+version.Version)
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("Teams workflow returned status code: %d", resp.StatusCode)
+	}
+
+	t.logger.Infof("Teams workflow notification sent successfully")
+	return nil
+}
+
+// buildPayload renders the Adaptive Card wrapped in the Workflows message envelope
+func (t *TeamsWorkflowSender) buildPayload(message string, m *monitor.Model, hb *heartbeat.Model) map[string]any {
+	return teamsAdaptiveCardMessage(message, m, hb, t.config)
+}
+
+// teamsAdaptiveCardMessage renders the Adaptive Card of a notification wrapped in the
+// message envelope both Workflows and incoming webhooks accept
+// See https://adaptivecards.io/explorer/ for the card schema
+func teamsAdaptiveCardMessage(message string, m *monitor.Model, hb *heartbeat.Model, cfg *config.Config) map[string]any {
+	title := "Peekaping Alert"
+	style := "default"
+
+	if m != nil && hb != nil {
+		switch hb.Status {
+		case shared.MonitorStatusUp:
+			title = fmt.Sprintf("✅ %s is back online", m.Name)
+		case shared.MonitorStatusDown:
+			title = fmt.Sprintf("🔴 %s went down", m.Name)
+		default:
+			title = fmt.Sprintf("%s %s is %s", humanReadableStatusIcons(int(hb.Status)), m.Name, strings.ToLower(humanReadableStatus(int(hb.Status))))
+		}
+		style = teamsContainerStyle(hb.Status)
+	}
+
+	facts := []map[string]string{}
+	if m != nil {
+		facts = append(facts, map[string]string{"title": "Monitor", "value": m.Name})
+		facts = append(facts, map[string]string{"title": "Type", "value": m.Type})
+		if address := monitorAddress(m); address != "" {
+			facts = append(facts, map[string]string{"title": "Address", "value": address})
+		}
+	}
+	if hb != nil {
+		facts = append(facts, map[string]string{"title": "Status", "value": humanReadableStatus(int(hb.Status))})
+		facts = append(facts, map[string]string{"title": "Time", "value": hb.Time.UTC().Format(time.RFC3339)})
+		if hb.Status == shared.MonitorStatusUp {
+			facts = append(facts, map[string]string{"title": "Response time", "value": fmt.Sprintf("%d ms", hb.Ping)})
+		}
+	}
+
+	body := []map[string]any{
+		{
+			"type":  "Container",
+			"style": style,
+			"bleed": true,
+			"items": []map[string]any{
+				{
+					"type":   "TextBlock",
+					"text":   title,
+					"weight": "Bolder",
+					"size":   "Medium",
+					"wrap":   true,
+				},
+			},
+		},
+		{
+			"type": "TextBlock",
+			"text": message,
+			"wrap": true,
+		},
+	}
+	if len(facts) > 0 {
+		body = append(body, map[string]any{
+			"type":  "FactSet",
+			"facts": facts,
+		})
+	}
+
+	card := map[string]any{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.4",
+		"msteams": map[string]string{"width": "Full"},
+		"body":    body,
+	}
+
+	if m != nil && cfg != nil && cfg.ClientURL != "" {
+		card["actions"] = []map[string]any{
+			{
+				"type":  "Action.OpenUrl",
+				"title": "Visit Peekaping",
+				"url":   fmt.Sprintf("%s/monitors/%s", strings.TrimRight(cfg.ClientURL, "/"), m.ID),
+			},
+		}
+	}
+
+	return map[string]any{
+		"type": "message",
+		"attachments": []map[string]any{
+			{
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				"contentUrl":  nil,
+				"content":     card,
+			},
+		},
+	}
+}
+
+// teamsContainerStyle maps a monitor status to an Adaptive Card container style (color)
+func teamsContainerStyle(status shared.MonitorStatus) string {
+	switch status {
+	case shared.MonitorStatusUp:
+		return "good"
+	case shared.MonitorStatusDown:
+		return "attention"
+	case shared.MonitorStatusPending:
+		return "warning"
+	case shared.MonitorStatusMaintenance:
+		return "accent"
+	default:
+		return "default"
+	}
+}
+
+// monitorAddress returns the checked URL or host of a monitor, if its config has one
+func monitorAddress(m *monitor.Model) string {
+	if m.Config == "" {
+		return ""
+	}
+	var cfg map[string]any
+	if err := json.Unmarshal([]byte(m.Config), &cfg); err != nil {
+		return ""
+	}
+	for _, key := range []string{"url", "hostname", "host"} {
+		if value, ok := cfg[key].(string); ok && value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,177 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"peekaping/internal/config"
+	"peekaping/internal/modules/heartbeat"
+	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/shared"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestTeamsWorkflowConfig_Validate(t *testing.T) {
+	sender := NewTeamsWorkflowSender(zap.NewNop().Sugar(), nil)
+
+	tests := []struct {
+		name    string
+		config  string
+		wantErr bool
+	}{
+		{name: "valid workflow url", config: `{"workflow_url": "https://prod-00.westus.logic.azure.com/workflows/abc/triggers/manual/paths/invoke?sig=xyz"}`},
+		{name: "missing url", config: `{}`, wantErr: true},
+		{name: "not a url", config: `{"workflow_url": "not-a-url"}`, wantErr: true},
+		{name: "plain http", config: `{"workflow_url": "http://example.com/hook"}`, wantErr: true},
+		{name: "invalid json", config: `{`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := sender.Validate(tt.config)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// decodeAdaptiveCard round-trips the payload through JSON and returns the envelope and card
+func decodeAdaptiveCard(t *testing.T, payload any) (map[string]any, map[string]any) {
+	t.Helper()
+	data, err := json.Marshal(payload)
+	require.NoError(t, err)
+
+	var envelope map[string]any
+	require.NoError(t, json.Unmarshal(data, &envelope))
+
+	attachments := envelope["attachments"].([]any)
+	require.Len(t, attachments, 1)
+	attachment := attachments[0].(map[string]any)
+	assert.Equal(t, "application/vnd.microsoft.card.adaptive", attachment["contentType"])
+	assert.Contains(t, attachment, "contentUrl")
+
+	return envelope, attachment["content"].(map[string]any)
+}
+
+func factsOf(card map[string]any) map[string]string {
+	facts := map[string]string{}
+	for _, element := range card["body"].([]any) {
+		el := element.(map[string]any)
+		if el["type"] != "FactSet" {
+			continue
+		}
+		for _, f := range el["facts"].([]any) {
+			fact := f.(map[string]any)
+			facts[fact["title"].(string)] = fact["value"].(string)
+		}
+	}
+	return facts
+}
+
+func TestTeamsWorkflowSender_BuildPayload(t *testing.T) {
+	sender := NewTeamsWorkflowSender(zap.NewNop().Sugar(), &config.Config{ClientURL: "https://peekaping.example.com/"})
+
+	m := &monitor.Model{ID: "mon-1", Name: "API", Type: "http", Config: `{"url":"https://api.example.com/health"}`}
+	at := time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("down", func(t *testing.T) {
+		hb := &heartbeat.Model{Status: shared.MonitorStatusDown, Time: at, Msg: "timeout"}
+		envelope, card := decodeAdaptiveCard(t, sender.buildPayload("Request timed out", m, hb))
+
+		assert.Equal(t, "message", envelope["type"])
+		assert.Equal(t, "AdaptiveCard", card["type"])
+		assert.Equal(t, "1.4", card["version"])
+
+		body := card["body"].([]any)
+		header := body[0].(map[string]any)
+		assert.Equal(t, "Container", header["type"])
+		assert.Equal(t, "attention", header["style"])
+		title := header["items"].([]any)[0].(map[string]any)
+		assert.Equal(t, "🔴 API went down", title["text"])
+
+		messageBlock := body[1].(map[string]any)
+		assert.Equal(t, "Request timed out", messageBlock["text"])
+
+		facts := factsOf(card)
+		assert.Equal(t, "API", facts["Monitor"])
+		assert.Equal(t, "http", facts["Type"])
+		assert.Equal(t, "https://api.example.com/health", facts["Address"])
+		assert.Equal(t, "DOWN", facts["Status"])
+		assert.Equal(t, "2025-05-01T10:00:00Z", facts["Time"])
+		assert.NotContains(t, facts, "Response time")
+
+		actions := card["actions"].([]any)
+		require.Len(t, actions, 1)
+		action := actions[0].(map[string]any)
+		assert.Equal(t, "Action.OpenUrl", action["type"])
+		assert.Equal(t, "https://peekaping.example.com/monitors/mon-1", action["url"])
+	})
+
+	t.Run("up", func(t *testing.T) {
+		hb := &heartbeat.Model{Status: shared.MonitorStatusUp, Time: at, Ping: 123}
+		_, card := decodeAdaptiveCard(t, sender.buildPayload("OK", m, hb))
+
+		header := card["body"].([]any)[0].(map[string]any)
+		assert.Equal(t, "good", header["style"])
+		assert.Equal(t, "✅ API is back online", header["items"].([]any)[0].(map[string]any)["text"])
+		assert.Equal(t, "123 ms", factsOf(card)["Response time"])
+	})
+
+	t.Run("status colors", func(t *testing.T) {
+		assert.Equal(t, "warning", teamsContainerStyle(shared.MonitorStatusPending))
+		assert.Equal(t, "accent", teamsContainerStyle(shared.MonitorStatusMaintenance))
+	})
+
+	t.Run("generic message without monitor", func(t *testing.T) {
+		_, card := decodeAdaptiveCard(t, sender.buildPayload("Test notification", nil, nil))
+
+		body := card["body"].([]any)
+		header := body[0].(map[string]any)
+		assert.Equal(t, "default", header["style"])
+		assert.Equal(t, "Peekaping Alert", header["items"].([]any)[0].(map[string]any)["text"])
+		assert.Len(t, body, 2, "no fact set without monitor details")
+		assert.NotContains(t, card, "actions")
+	})
+}
+
+func TestTeamsWorkflowSender_Send(t *testing.T) {
+	var received map[string]any
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		body, _ := io.ReadAll(r.Body)
+		require.NoError(t, json.Unmarshal(body, &received))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	sender := NewTeamsWorkflowSender(zap.NewNop().Sugar(), &config.Config{})
+	sender.client = server.Client()
+
+	m := &monitor.Model{ID: "mon-1", Name: "API", Type: "http"}
+	hb := &heartbeat.Model{Status: shared.MonitorStatusDown, Time: time.Now()}
+
+	err := sender.Send(context.Background(), `{"workflow_url": "`+server.URL+`"}`, "down", m, hb)
+	require.NoError(t, err)
+	assert.Equal(t, "message", received["type"])
+
+	failing := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer failing.Close()
+	sender.client = failing.Client()
+
+	err = sender.Send(context.Background(), `{"workflow_url": "`+failing.URL+`"}`, "down", m, hb)
+	assert.Error(t, err)
+}
@@ -1,5 +1,7 @@
 package notification_channel
 
+import "fmt"
+
 var NotificationChannelProviderRegistry = make(map[string]NotificationChannelProvider)
 
 func RegisterNotificationChannelProvider(name string, provider NotificationChannelProvider) {
@@ -10,3 +12,19 @@ func GetNotificationChannelProvider(name string) (NotificationChannelProvider, b
 	n, ok := NotificationChannelProviderRegistry[name]
 	return n, ok
 }
+
+// ValidateConfig checks the config of a channel of the given type the same way creating
+// the channel through the API does
+func ValidateConfig(channelType, configJSON string) error {
+	provider, ok := GetNotificationChannelProvider(channelType)
+	if !ok {
+		return fmt.Errorf("unsupported notification type %q", channelType)
+	}
+	if err := provider.Validate(configJSON); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	if err := validateChannelOptions(configJSON); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	return nil
+}
@@ -4,11 +4,28 @@ import (
 	"time"
 )
 
+// RetentionDaysSettingKey is the setting holding how many days records are kept
+// before the cleanup cron deletes them. 0 keeps them forever.
+const RetentionDaysSettingKey = "NOTIFICATION_HISTORY_RETENTION_DAYS"
+
+// DefaultRetentionDays is used while the retention setting is unset or invalid
+const DefaultRetentionDays = 90
+
+// Record modes. Sent records are unique per type, monitor and days, while test mode
+// keeps one record per channel, monitor and send.
+const (
+	ModeSent = "sent"
+	ModeTest = "test"
+)
+
 type Model struct {
 	ID        string    `json:"id"`
+	Mode      string    `json:"mode"`
 	Type      string    `json:"type"` // "certificate", "monitor", etc.
 	MonitorID string    `json:"monitor_id"`
-	Days      int       `json:"days"` // Threshold days (e.g., 7, 14, 21)
+	Days      int       `json:"days"`                 // Threshold days (e.g., 7, 14, 21)
+	ChannelID string    `json:"channel_id,omitempty"` // Test mode only
+	Message   string    `json:"message,omitempty"`    // Test mode only, the rendered message
 	CreatedAt time.Time `json:"created_at"`
 }
 
@@ -13,9 +13,12 @@ import (
 
 type mongoModel struct {
 	ID        primitive.ObjectID `bson:"_id"`
+	Mode      string             `bson:"mode"`
 	Type      string             `bson:"type"`
 	MonitorID string             `bson:"monitor_id"`
 	Days      int                `bson:"days"`
+	ChannelID string             `bson:"channel_id,omitempty"`
+	Message   string             `bson:"message,omitempty"`
 	CreatedAt time.Time          `bson:"created_at"`
 }
 
@@ -25,9 +28,12 @@ func toDomainModelFromMongo(mm *mongoModel) *Model {
 	}
 	return &Model{
 		ID:        mm.ID.Hex(),
+		Mode:      mm.Mode,
 		Type:      mm.Type,
 		MonitorID: mm.MonitorID,
 		Days:      mm.Days,
+		ChannelID: mm.ChannelID,
+		Message:   mm.Message,
 		CreatedAt: mm.CreatedAt,
 	}
 }
@@ -42,6 +48,10 @@ func NewMongoRepository(client *mongo.Client, cfg *config.Config) Repository {
 	db := client.Database(cfg.DBName)
 	collection := db.Collection("notification_sent_history")
 
+	// Only sent records are unique, test mode keeps one record per send. The unique
+	// index predating test mode covers every record, so it is replaced.
+	collection.Indexes().DropOne(context.Background(), "type_1_monitor_id_1_days_1")
+
 	// Create compound index for uniqueness and performance
 	indexModel := mongo.IndexModel{
 		Keys: bson.D{
@@ -49,7 +59,10 @@ func NewMongoRepository(client *mongo.Client, cfg *config.Config) Repository {
 			{Key: "monitor_id", Value: 1},
 			{Key: "days", Value: 1},
 		},
-		Options: options.Index().SetUnique(true),
+		Options: options.Index().
+			SetName("type_1_monitor_id_1_days_1_sent").
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"mode": ModeSent}),
 	}
 	collection.Indexes().CreateOne(context.Background(), indexModel)
 
@@ -59,6 +72,16 @@ func NewMongoRepository(client *mongo.Client, cfg *config.Config) Repository {
 	}
 	collection.Indexes().CreateOne(context.Background(), createdAtIndex)
 
+	// Create index for listing test mode records
+	testModeIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "mode", Value: 1},
+			{Key: "monitor_id", Value: 1},
+			{Key: "created_at", Value: 1},
+		},
+	}
+	collection.Indexes().CreateOne(context.Background(), testModeIndex)
+
 	return &MongoRepositoryImpl{client, db, collection}
 }
 
@@ -80,6 +103,7 @@ func (r *MongoRepositoryImpl) CheckIfSent(ctx context.Context, notificationType
 func (r *MongoRepositoryImpl) RecordSent(ctx context.Context, dto *CreateDto) error {
 	mm := &mongoModel{
 		ID:        primitive.NewObjectID(),
+		Mode:      ModeSent,
 		Type:      dto.Type,
 		MonitorID: dto.MonitorID,
 		Days:      dto.Days,
@@ -96,6 +120,7 @@ func (r *MongoRepositoryImpl) RecordSent(ctx context.Context, dto *CreateDto) er
 	update := bson.M{
 		"$setOnInsert": bson.M{
 			"_id":        mm.ID,
+			"mode":       mm.Mode,
 			"type":       mm.Type,
 			"monitor_id": mm.MonitorID,
 			"days":       mm.Days,
@@ -154,3 +179,43 @@ func (r *MongoRepositoryImpl) GetByMonitorAndType(ctx context.Context, monitorID
 
 	return models, nil
 }
+
+func (r *MongoRepositoryImpl) RecordTestMode(ctx context.Context, notification *Model) error {
+	mm := &mongoModel{
+		ID:        primitive.NewObjectID(),
+		Mode:      ModeTest,
+		MonitorID: notification.MonitorID,
+		ChannelID: notification.ChannelID,
+		Message:   notification.Message,
+		CreatedAt: notification.CreatedAt,
+	}
+
+	_, err := r.collection.InsertOne(ctx, mm)
+	return err
+}
+
+func (r *MongoRepositoryImpl) GetTestModeByMonitor(ctx context.Context, monitorID string) ([]*Model, error) {
+	filter := bson.M{
+		"mode":       ModeTest,
+		"monitor_id": monitorID,
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var mongoModels []*mongoModel
+	if err = cursor.All(ctx, &mongoModels); err != nil {
+		return nil, err
+	}
+
+	models := make([]*Model, 0, len(mongoModels))
+	for _, mm := range mongoModels {
+		models = append(models, toDomainModelFromMongo(mm))
+	}
+
+	return models, nil
+}
@@ -19,4 +19,10 @@ type Repository interface {
 
 	// GetByMonitorAndType gets all notification history for a monitor and type
 	GetByMonitorAndType(ctx context.Context, monitorID string, notificationType string) ([]*Model, error)
+
+	// RecordTestMode records a notification that test mode kept from being sent
+	RecordTestMode(ctx context.Context, notification *Model) error
+
+	// GetTestModeByMonitor gets the test mode notifications of a monitor, oldest first
+	GetTestModeByMonitor(ctx context.Context, monitorID string) ([]*Model, error)
 }
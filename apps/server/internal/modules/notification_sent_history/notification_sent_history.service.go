@@ -3,6 +3,7 @@ package notification_sent_history
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"go.uber.org/zap"
 )
@@ -22,6 +23,13 @@ type Service interface {
 
 	// GetNotificationHistory gets all notification history for a monitor and type
 	GetNotificationHistory(ctx context.Context, monitorID string, notificationType string) ([]*Model, error)
+
+	// RecordTestModeNotification records the rendered message of a notification that test
+	// mode kept from being sent
+	RecordTestModeNotification(ctx context.Context, channelID string, monitorID string, message string) error
+
+	// GetTestModeNotifications gets the test mode notifications of a monitor, oldest first
+	GetTestModeNotifications(ctx context.Context, monitorID string) ([]*Model, error)
 }
 
 type ServiceImpl struct {
@@ -106,3 +114,24 @@ func (s *ServiceImpl) GetNotificationHistory(ctx context.Context, monitorID stri
 	s.logger.Debugf("Found %d notification history records for monitor %s", len(history), monitorID)
 	return history, nil
 }
+
+func (s *ServiceImpl) RecordTestModeNotification(ctx context.Context, channelID string, monitorID string, message string) error {
+	err := s.repository.RecordTestMode(ctx, &Model{
+		ChannelID: channelID,
+		MonitorID: monitorID,
+		Message:   message,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record test mode notification: %w", err)
+	}
+	return nil
+}
+
+func (s *ServiceImpl) GetTestModeNotifications(ctx context.Context, monitorID string) ([]*Model, error) {
+	notifications, err := s.repository.GetTestModeByMonitor(ctx, monitorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get test mode notifications: %w", err)
+	}
+	return notifications, nil
+}
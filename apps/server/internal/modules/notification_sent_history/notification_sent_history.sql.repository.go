@@ -12,18 +12,24 @@ type sqlModel struct {
 	bun.BaseModel `bun:"table:notification_sent_history,alias:nsh"`
 
 	ID        string    `bun:"id,pk"`
+	Mode      string    `bun:"mode,notnull"`
 	Type      string    `bun:"type,notnull"`
 	MonitorID string    `bun:"monitor_id,notnull"`
 	Days      int       `bun:"days,notnull"`
+	ChannelID string    `bun:"channel_id,nullzero"`
+	Message   string    `bun:"message,nullzero"`
 	CreatedAt time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
 }
 
 func toDomainModelFromSQL(sm *sqlModel) *Model {
 	return &Model{
 		ID:        sm.ID,
+		Mode:      sm.Mode,
 		Type:      sm.Type,
 		MonitorID: sm.MonitorID,
 		Days:      sm.Days,
+		ChannelID: sm.ChannelID,
+		Message:   sm.Message,
 		CreatedAt: sm.CreatedAt,
 	}
 }
@@ -52,16 +58,18 @@ func (r *SQLRepositoryImpl) CheckIfSent(ctx context.Context, notificationType st
 func (r *SQLRepositoryImpl) RecordSent(ctx context.Context, dto *CreateDto) error {
 	sm := &sqlModel{
 		ID:        uuid.New().String(),
+		Mode:      ModeSent,
 		Type:      dto.Type,
 		MonitorID: dto.MonitorID,
 		Days:      dto.Days,
 		CreatedAt: time.Now(),
 	}
 
-	// Use INSERT ... ON CONFLICT DO NOTHING to handle duplicates gracefully
+	// Use INSERT ... ON CONFLICT DO NOTHING to handle duplicates gracefully, only sent
+	// records are unique
 	_, err := r.db.NewInsert().
 		Model(sm).
-		On("CONFLICT (type, monitor_id, days) DO NOTHING").
+		On("CONFLICT (type, monitor_id, days) WHERE mode = 'sent' DO NOTHING").
 		Exec(ctx)
 
 	return err
@@ -107,3 +115,37 @@ func (r *SQLRepositoryImpl) GetByMonitorAndType(ctx context.Context, monitorID s
 
 	return models, nil
 }
+
+func (r *SQLRepositoryImpl) RecordTestMode(ctx context.Context, notification *Model) error {
+	sm := &sqlModel{
+		ID:        uuid.New().String(),
+		Mode:      ModeTest,
+		MonitorID: notification.MonitorID,
+		ChannelID: notification.ChannelID,
+		Message:   notification.Message,
+		CreatedAt: notification.CreatedAt,
+	}
+
+	_, err := r.db.NewInsert().Model(sm).Exec(ctx)
+	return err
+}
+
+func (r *SQLRepositoryImpl) GetTestModeByMonitor(ctx context.Context, monitorID string) ([]*Model, error) {
+	var sqlModels []*sqlModel
+
+	err := r.db.NewSelect().
+		Model(&sqlModels).
+		Where("mode = ? AND monitor_id = ?", ModeTest, monitorID).
+		Order("created_at ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	models := make([]*Model, 0, len(sqlModels))
+	for _, sm := range sqlModels {
+		models = append(models, toDomainModelFromSQL(sm))
+	}
+
+	return models, nil
+}
@@ -0,0 +1,119 @@
+package notification_sent_history
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"github.com/uptrace/bun/driver/sqliteshim"
+)
+
+func setupTestDB(t *testing.T) *bun.DB {
+	sqldb, err := sql.Open(sqliteshim.ShimName, "file::memory:")
+	require.NoError(t, err)
+
+	db := bun.NewDB(sqldb, sqlitedialect.New())
+
+	_, err = db.Exec(`
+		CREATE TABLE notification_sent_history (
+			id TEXT PRIMARY KEY,
+			mode TEXT NOT NULL DEFAULT 'sent',
+			type TEXT NOT NULL,
+			monitor_id TEXT NOT NULL,
+			days INTEGER NOT NULL,
+			channel_id TEXT,
+			message TEXT,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	require.NoError(t, err)
+
+	_, err = db.Exec(`CREATE UNIQUE INDEX idx_notification_sent_history_sent ON notification_sent_history(type, monitor_id, days) WHERE mode = 'sent'`)
+	require.NoError(t, err)
+
+	_, err = db.Exec(`CREATE INDEX idx_notification_sent_history_created_at ON notification_sent_history(created_at)`)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func insertRecord(t *testing.T, db *bun.DB, id string, days int, createdAt time.Time) {
+	_, err := db.NewInsert().Model(&sqlModel{
+		ID:        id,
+		Mode:      ModeSent,
+		Type:      "certificate",
+		MonitorID: "monitor-1",
+		Days:      days,
+		CreatedAt: createdAt,
+	}).Exec(context.Background())
+	require.NoError(t, err)
+}
+
+func TestSQLRepository_CleanupOldRecords(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB(t)
+	repo := NewSQLRepository(db)
+
+	now := time.Now()
+	insertRecord(t, db, "expired", 7, now.AddDate(0, 0, -120))
+	insertRecord(t, db, "just-expired", 14, now.AddDate(0, 0, -31))
+	insertRecord(t, db, "recent", 21, now.AddDate(0, 0, -29))
+	insertRecord(t, db, "new", 30, now)
+
+	require.NoError(t, repo.CleanupOldRecords(ctx, 30))
+
+	var ids []string
+	err := db.NewSelect().Model((*sqlModel)(nil)).Column("id").Order("days ASC").Scan(ctx, &ids)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"recent", "new"}, ids)
+}
+
+func TestSQLRepository_RecordTestMode(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB(t)
+	repo := NewSQLRepository(db)
+
+	now := time.Now()
+	sends := []*Model{
+		{ChannelID: "channel-1", MonitorID: "monitor-1", Message: "API is down", CreatedAt: now.AddDate(0, 0, -40)},
+		{ChannelID: "channel-1", MonitorID: "monitor-1", Message: "API is up", CreatedAt: now.Add(-time.Minute)},
+		{ChannelID: "channel-1", MonitorID: "monitor-1", Message: "API is down", CreatedAt: now},
+		{ChannelID: "channel-2", MonitorID: "monitor-2", Message: "Web is down", CreatedAt: now},
+	}
+	for _, n := range sends {
+		require.NoError(t, repo.RecordTestMode(ctx, n))
+	}
+
+	// Every send is kept with its message, repeated messages included
+	notifications, err := repo.GetTestModeByMonitor(ctx, "monitor-1")
+	require.NoError(t, err)
+	var messages []string
+	for _, n := range notifications {
+		assert.Equal(t, "channel-1", n.ChannelID)
+		messages = append(messages, n.Message)
+	}
+	assert.Equal(t, []string{"API is down", "API is up", "API is down"}, messages)
+
+	// Test mode records don't count as sent thresholds and don't block them
+	require.NoError(t, repo.RecordSent(ctx, &CreateDto{Type: "certificate", MonitorID: "monitor-1", Days: 7}))
+	require.NoError(t, repo.RecordSent(ctx, &CreateDto{Type: "certificate", MonitorID: "monitor-1", Days: 7}))
+	sent, err := repo.GetByMonitorAndType(ctx, "monitor-1", "certificate")
+	require.NoError(t, err)
+	require.Len(t, sent, 1)
+	assert.Equal(t, ModeSent, sent[0].Mode)
+
+	require.NoError(t, repo.CleanupOldRecords(ctx, 30))
+
+	notifications, err = repo.GetTestModeByMonitor(ctx, "monitor-1")
+	require.NoError(t, err)
+	assert.Len(t, notifications, 2)
+	for _, n := range notifications {
+		assert.Equal(t, ModeTest, n.Mode)
+	}
+}
@@ -40,6 +40,16 @@ func (m *MockRepository) GetByMonitorAndType(ctx context.Context, monitorID stri
 	return args.Get(0).([]*Model), args.Error(1)
 }
 
+func (m *MockRepository) RecordTestMode(ctx context.Context, notification *Model) error {
+	args := m.Called(ctx, notification)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetTestModeByMonitor(ctx context.Context, monitorID string) ([]*Model, error) {
+	args := m.Called(ctx, monitorID)
+	return args.Get(0).([]*Model), args.Error(1)
+}
+
 func TestNotificationSentHistoryService(t *testing.T) {
 	logger := zap.NewNop().Sugar()
 	mockRepo := new(MockRepository)
@@ -0,0 +1,76 @@
+package producer
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// BatchClaimSettingKey is the global setting for the most due monitors a producer
+	// goroutine claims per tick
+	BatchClaimSettingKey = "producer_batch_claim"
+	// ClaimTickSettingKey is the global setting for how often, in milliseconds, a producer
+	// goroutine claims due monitors
+	ClaimTickSettingKey = "producer_claim_tick_ms"
+
+	minBatchClaim = 1
+	maxBatchClaim = 1000
+	minClaimTick  = 10 * time.Millisecond
+	maxClaimTick  = 10 * time.Second
+
+	// claimLimitsRefresh is how long the claim settings are cached
+	claimLimitsRefresh = 10 * time.Second
+)
+
+// claimLimits bounds how fast the producer moves due monitors to the queue
+type claimLimits struct {
+	batch int
+	tick  time.Duration
+}
+
+// currentClaimLimits returns the batch size and claim tick from the settings, falling back
+// to BatchClaim and ClaimTick when they are unset or out of bounds
+func (p *Producer) currentClaimLimits(ctx context.Context) claimLimits {
+	p.claimMu.Lock()
+	defer p.claimMu.Unlock()
+
+	if !p.claimCheckedAt.IsZero() && time.Since(p.claimCheckedAt) < claimLimitsRefresh {
+		return p.claimLimits
+	}
+	p.claimCheckedAt = time.Now()
+
+	limits := claimLimits{
+		batch: p.readClaimSetting(ctx, BatchClaimSettingKey, BatchClaim, minBatchClaim, maxBatchClaim),
+		tick: time.Duration(p.readClaimSetting(ctx, ClaimTickSettingKey, int(ClaimTick/time.Millisecond),
+			int(minClaimTick/time.Millisecond), int(maxClaimTick/time.Millisecond))) * time.Millisecond,
+	}
+	if limits != p.claimLimits && p.claimLimits != (claimLimits{}) {
+		p.logger.Infow("Producer claim limits changed", "batch", limits.batch, "tick", limits.tick)
+	}
+	p.claimLimits = limits
+	return limits
+}
+
+// readClaimSetting reads an integer setting, returning fallback when it is unset, invalid
+// or outside [min, max]
+func (p *Producer) readClaimSetting(ctx context.Context, key string, fallback, min, max int) int {
+	if p.settingService == nil {
+		return fallback
+	}
+	s, err := p.settingService.GetByKey(ctx, key)
+	if err != nil {
+		p.logger.Warnw("Failed to fetch producer claim setting", "key", key, "error", err)
+		return fallback
+	}
+	if s == nil || strings.TrimSpace(s.Value) == "" {
+		return fallback
+	}
+	value, err := strconv.Atoi(strings.TrimSpace(s.Value))
+	if err != nil || value < min || value > max {
+		p.logger.Warnw("Ignoring out of range producer claim setting", "key", key, "value", s.Value, "min", min, "max", max)
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,125 @@
+package producer
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"peekaping/internal/modules/maintenance"
+	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/queue"
+	"peekaping/internal/modules/shared"
+	"peekaping/internal/modules/worker"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func claimSettingService(batch, tickMs string) *MockSettingService {
+	m := new(MockSettingService)
+	for key, value := range map[string]string{BatchClaimSettingKey: batch, ClaimTickSettingKey: tickMs} {
+		if value == "" {
+			m.On("GetByKey", mock.Anything, key).Return(nil, nil)
+		} else {
+			m.On("GetByKey", mock.Anything, key).Return(&shared.SettingModel{Key: key, Value: value, Type: "int"}, nil)
+		}
+	}
+	return m
+}
+
+func TestCurrentClaimLimits(t *testing.T) {
+	tests := []struct {
+		name          string
+		batch         string
+		tickMs        string
+		expectedBatch int
+		expectedTick  time.Duration
+	}{
+		{"unset uses defaults", "", "", BatchClaim, ClaimTick},
+		{"configured", "200", "250", 200, 250 * time.Millisecond},
+		{"batch above the maximum", "5000", "", BatchClaim, ClaimTick},
+		{"batch below the minimum", "0", "", BatchClaim, ClaimTick},
+		{"tick below the minimum", "", "1", BatchClaim, ClaimTick},
+		{"tick above the maximum", "", "60000", BatchClaim, ClaimTick},
+		{"not a number", "lots", "fast", BatchClaim, ClaimTick},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			producer := &Producer{logger: zap.NewNop().Sugar(), settingService: claimSettingService(tt.batch, tt.tickMs)}
+
+			limits := producer.currentClaimLimits(context.Background())
+
+			assert.Equal(t, tt.expectedBatch, limits.batch)
+			assert.Equal(t, tt.expectedTick, limits.tick)
+		})
+	}
+
+	t.Run("limits are cached", func(t *testing.T) {
+		settingSvc := claimSettingService("10", "100")
+		producer := &Producer{logger: zap.NewNop().Sugar(), settingService: settingSvc}
+
+		producer.currentClaimLimits(context.Background())
+		producer.currentClaimLimits(context.Background())
+
+		settingSvc.AssertNumberOfCalls(t, "GetByKey", 2)
+	})
+}
+
+func TestRunProducer_BatchClaimLimitsTasksPerTick(t *testing.T) {
+	client, mr := setupTestRedis(t)
+	defer mr.Close()
+
+	mockMonitorSvc := new(MockMonitorService)
+	mockMaintenanceSvc := new(MockMaintenanceService)
+	mockQueueSvc := new(MockQueueService)
+	settingSvc := claimSettingService("2", "1000")
+	settingSvc.On("GetByKey", mock.Anything, mock.Anything).Return(nil, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	producer := &Producer{
+		rdb:                client,
+		logger:             zap.NewNop().Sugar(),
+		ctx:                ctx,
+		cancel:             cancel,
+		monitorService:     mockMonitorSvc,
+		maintenanceService: mockMaintenanceSvc,
+		queueService:       mockQueueSvc,
+		settingService:     settingSvc,
+	}
+
+	// Due just now, so the producer isn't behind and keeps to the configured limits
+	var enqueued atomic.Int32
+	dueMs := time.Now().UnixMilli()
+	for i := range 5 {
+		id := fmt.Sprintf("mon-%d", i)
+		mon := &monitor.Model{ID: id, Name: "Test Monitor", Type: "http", Active: true, Interval: 60, Timeout: 30}
+		mockMonitorSvc.On("FindByID", mock.Anything, id).Return(mon, nil)
+		mockMaintenanceSvc.On("GetMaintenancesByMonitorID", mock.Anything, id).Return([]*maintenance.Model{}, nil)
+		require.NoError(t, client.ZAdd(ctx, SchedDueKey, redis.Z{Score: float64(dueMs), Member: id}).Err())
+	}
+	mockQueueSvc.On("EnqueueUnique", mock.Anything, worker.TaskTypeHealthCheck, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Run(func(mock.Arguments) { enqueued.Add(1) }).
+		Return(&queue.TaskInfo{ID: "task-1"}, nil)
+
+	producer.wg.Add(1)
+	go producer.runProducer(0)
+	defer func() {
+		cancel()
+		producer.wg.Wait()
+	}()
+
+	// The first tick enqueues one batch, the rest waits for the next tick
+	require.Eventually(t, func() bool { return enqueued.Load() == 2 }, time.Second, 10*time.Millisecond)
+	time.Sleep(300 * time.Millisecond)
+	assert.Equal(t, int32(2), enqueued.Load())
+	assert.Equal(t, int64(3), client.ZCount(ctx, SchedDueKey, "-inf", strconv.FormatInt(dueMs, 10)).Val())
+
+	require.Eventually(t, func() bool { return enqueued.Load() == 4 }, 2*time.Second, 10*time.Millisecond)
+}
@@ -13,16 +13,17 @@ const (
 
 	// With high concurrency (128 workers), use smaller batches to reduce contention
 	// Smaller batches = more frequent claims = better work distribution
-	BatchClaim          = 50                    // max items to claim per tick
+	BatchClaim          = 50                    // default max items to claim per tick
 	LeaseTTL            = 10 * time.Second      // how long an item can sit in "lease" while enqueuing
 	ReclaimEvery        = 2 * time.Second       // how often to sweep expired leases
-	ClaimTick           = 50 * time.Millisecond // how often to check for due monitors (increased slightly for 128 workers)
+	ClaimTick           = 50 * time.Millisecond // default of how often to check for due monitors (increased slightly for 128 workers)
 	ConcurrentProducers = 128                   // number of concurrent producer goroutines
 )
 
 // Lua scripts for atomic operations
 const (
 	// CLAIM: move due items (score <= now_ms) from due → lease with lease expiry.
+	// Returns id, due_ms pairs.
 	claimLua = `
 local due   = KEYS[1]
 local lease = KEYS[2]
@@ -30,13 +31,12 @@ local now   = tonumber(ARGV[1])
 local limit = tonumber(ARGV[2])
 local lms   = tonumber(ARGV[3])
 
-local ids = redis.call('ZRANGEBYSCORE', due, '-inf', now, 'LIMIT', 0, limit)
-if #ids == 0 then return ids end
-for i=1,#ids do
-  redis.call('ZREM', due, ids[i])
-  redis.call('ZADD', lease, now + lms, ids[i])
+local items = redis.call('ZRANGEBYSCORE', due, '-inf', now, 'WITHSCORES', 'LIMIT', 0, limit)
+for i=1,#items,2 do
+  redis.call('ZREM', due, items[i])
+  redis.call('ZADD', lease, now + lms, items[i])
 end
-return ids
+return items
 `
 
 	// RESCHEDULE: move a claimed item lease → due at next_ts_ms
@@ -48,6 +48,28 @@ local next  = tonumber(ARGV[2])
 redis.call('ZREM', lease, id)
 redis.call('ZADD', due, next, id)
 return 1
+`
+
+	// ENSURE: add an item to due at next_ts_ms unless it is already due or leased
+	ensureScheduledLua = `
+local due   = KEYS[1]
+local lease = KEYS[2]
+local id    = ARGV[1]
+local next  = tonumber(ARGV[2])
+if redis.call('ZSCORE', due, id) or redis.call('ZSCORE', lease, id) then return 0 end
+redis.call('ZADD', due, next, id)
+return 1
+`
+
+	// ADVANCE: move a due item to next_ts_ms if that is earlier than its due time
+	advanceLua = `
+local due   = KEYS[1]
+local id    = ARGV[1]
+local next  = tonumber(ARGV[2])
+local score = redis.call('ZSCORE', due, id)
+if not score or tonumber(score) <= next then return 0 end
+redis.call('ZADD', due, next, id)
+return 1
 `
 
 	// RECLAIM: move expired leases (score <= now_ms) back to due at now_ms
@@ -0,0 +1,107 @@
+package producer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/queue"
+	"peekaping/internal/modules/shared"
+	"peekaping/internal/modules/worker"
+)
+
+// ParentDownMessage is the heartbeat message of a check skipped because the parent of
+// the monitor is down
+const ParentDownMessage = "Check skipped: parent down"
+
+// parentDown reports whether the checks of a monitor with skip_when_parent_down should be
+// skipped at the given time: its parent is active, its latest heartbeat is down and, with
+// a parent_down_timeout, it went down less than the timeout ago. Errors fetching the
+// parent are logged and the check runs as usual.
+func (p *Producer) parentDown(ctx context.Context, mon *monitor.Model, at time.Time) bool {
+	if !mon.SkipWhenParentDown || mon.ParentId == "" {
+		return false
+	}
+
+	parent, err := p.monitorService.FindByID(ctx, mon.ParentId)
+	if err != nil {
+		p.logger.Warnw("Failed to fetch parent monitor", "monitor_id", mon.ID, "parent_id", mon.ParentId, "error", err)
+		return false
+	}
+	// A paused parent keeps its last status, which says nothing about the dependency now
+	if parent == nil || !parent.Active {
+		return false
+	}
+
+	latest, err := p.heartbeatService.FindByMonitorIDPaginated(ctx, parent.ID, 1, 0, nil, false)
+	if err != nil {
+		p.logger.Warnw("Failed to fetch latest heartbeat of parent monitor", "monitor_id", mon.ID, "parent_id", parent.ID, "error", err)
+		return false
+	}
+	if len(latest) == 0 || latest[0].Status != shared.MonitorStatusDown {
+		return false
+	}
+
+	if mon.ParentDownTimeout <= 0 {
+		return true
+	}
+
+	// The latest important beat is the one the parent went down with
+	downSince := latest[0].Time
+	important := true
+	changes, err := p.heartbeatService.FindByMonitorIDPaginated(ctx, parent.ID, 1, 0, &important, false)
+	if err != nil {
+		p.logger.Warnw("Failed to fetch status change of parent monitor", "monitor_id", mon.ID, "parent_id", parent.ID, "error", err)
+	} else if len(changes) > 0 && changes[0].Status == shared.MonitorStatusDown {
+		downSince = changes[0].Time
+	}
+
+	return at.Sub(downSince) < time.Duration(mon.ParentDownTimeout)*time.Second
+}
+
+// skipForParent records a skipped heartbeat for a check that did not run because the
+// parent of the monitor is down. Like skipped checks of serialized monitors, the ingester
+// keeps the monitor's status.
+func (p *Producer) skipForParent(ctx context.Context, mon *monitor.Model, at time.Time) error {
+	now := time.Now().UTC()
+	payload := worker.IngesterTaskPayload{
+		MonitorID:          mon.ID,
+		MonitorName:        mon.Name,
+		MonitorType:        mon.Type,
+		MonitorInterval:    mon.Interval,
+		MonitorTimeout:     mon.Timeout,
+		MonitorMaxRetries:  mon.MaxRetries,
+		MonitorRetryInt:    mon.RetryInterval,
+		MonitorResendInt:   mon.ResendInterval,
+		MonitorWarmup:      mon.WarmupChecks,
+		MonitorActivatedAt: mon.ActivatedAt,
+		MonitorConfig:      mon.Config,
+		Status:             shared.MonitorStatusPending,
+		Message:            ParentDownMessage,
+		StartTime:          now,
+		EndTime:            now,
+		ErrorCategory:      shared.ErrorCategorySkipped,
+	}
+
+	opts := &queue.EnqueueOptions{
+		Queue:     "ingester",
+		MaxRetry:  3,
+		Timeout:   2 * time.Minute,
+		Retention: 1 * time.Hour,
+	}
+
+	// One result per scheduled tick, even if the tick is processed twice
+	uniqueKey := fmt.Sprintf("ingest:parent-down:%s:%d", mon.ID, at.UnixMilli())
+	ttl := time.Duration(mon.Interval) * time.Second
+
+	if _, err := p.queueService.EnqueueUnique(ctx, worker.TaskTypeIngester, payload, uniqueKey, ttl, opts); err != nil {
+		return fmt.Errorf("failed to enqueue skipped check result: %w", err)
+	}
+
+	p.logger.Infow("Skipped check while parent monitor is down",
+		"monitor_id", mon.ID,
+		"parent_id", mon.ParentId)
+
+	return nil
+}
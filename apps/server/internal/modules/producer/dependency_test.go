@@ -0,0 +1,132 @@
+package producer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"peekaping/internal/modules/heartbeat"
+	"peekaping/internal/modules/maintenance"
+	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/queue"
+	"peekaping/internal/modules/shared"
+	"peekaping/internal/modules/worker"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+func TestProcessMonitor_SkipWhenParentDown(t *testing.T) {
+	now := time.Now().UTC()
+
+	// latest is the parent's latest heartbeat, change the one it last changed status with
+	setup := func(child *monitor.Model, latest, change *heartbeat.Model) (*Producer, *MockQueueService) {
+		mockMonitorSvc := new(MockMonitorService)
+		mockMaintenanceSvc := new(MockMaintenanceService)
+		mockHeartbeatSvc := new(MockHeartbeatService)
+		mockQueueSvc := new(MockQueueService)
+
+		producer := &Producer{
+			logger:             zap.NewNop().Sugar(),
+			monitorService:     mockMonitorSvc,
+			maintenanceService: mockMaintenanceSvc,
+			heartbeatService:   mockHeartbeatSvc,
+			queueService:       mockQueueSvc,
+			settingService:     newMockSettingServiceWithoutDefaultProxy(),
+		}
+
+		parent := &monitor.Model{ID: "router", Name: "Router", Type: "ping", Active: true, Interval: 60}
+		mockMonitorSvc.On("FindByID", mock.Anything, "child").Return(child, nil)
+		mockMonitorSvc.On("FindByID", mock.Anything, "router").Return(parent, nil)
+		mockMaintenanceSvc.On("GetMaintenancesByMonitorID", mock.Anything, "child").Return([]*maintenance.Model{}, nil)
+		mockHeartbeatSvc.On("FindByMonitorIDPaginated", mock.Anything, "router", 1, 0, (*bool)(nil), false).
+			Return([]*heartbeat.Model{latest}, nil)
+		mockHeartbeatSvc.On("FindByMonitorIDPaginated", mock.Anything, "router", 1, 0, mock.AnythingOfType("*bool"), false).
+			Return([]*heartbeat.Model{change}, nil)
+
+		return producer, mockQueueSvc
+	}
+
+	newChild := func(timeout int) *monitor.Model {
+		return &monitor.Model{
+			ID:                 "child",
+			Name:               "Web server",
+			Type:               "http",
+			Active:             true,
+			Interval:           30,
+			Timeout:            16,
+			Config:             `{"url":"https://example.com"}`,
+			ParentId:           "router",
+			SkipWhenParentDown: true,
+			ParentDownTimeout:  timeout,
+		}
+	}
+	down := &heartbeat.Model{MonitorID: "router", Status: shared.MonitorStatusDown, Time: now.Add(-30 * time.Second)}
+	wentDown := &heartbeat.Model{MonitorID: "router", Status: shared.MonitorStatusDown, Important: true, Time: now.Add(-5 * time.Minute)}
+	up := &heartbeat.Model{MonitorID: "router", Status: shared.MonitorStatusUp, Time: now.Add(-30 * time.Second)}
+	cameUp := &heartbeat.Model{MonitorID: "router", Status: shared.MonitorStatusUp, Important: true, Time: now.Add(-30 * time.Second)}
+
+	expectHealthCheck := func(mockQueueSvc *MockQueueService) {
+		mockQueueSvc.On("EnqueueUnique", mock.Anything, worker.TaskTypeHealthCheck, mock.Anything, "healthcheck:child", mock.Anything, mock.Anything).
+			Return(&queue.TaskInfo{ID: "task-1"}, nil)
+	}
+
+	t.Run("skips the check while the parent is down", func(t *testing.T) {
+		producer, mockQueueSvc := setup(newChild(0), down, wentDown)
+		mockQueueSvc.On("EnqueueUnique", mock.Anything, worker.TaskTypeIngester, mock.MatchedBy(func(payload worker.IngesterTaskPayload) bool {
+			return payload.MonitorID == "child" &&
+				payload.Message == ParentDownMessage &&
+				payload.ErrorCategory == shared.ErrorCategorySkipped
+		}), mock.AnythingOfType("string"), mock.Anything, mock.MatchedBy(func(opts *queue.EnqueueOptions) bool {
+			return opts.Queue == "ingester"
+		})).Return(&queue.TaskInfo{ID: "task-1"}, nil)
+
+		interval, err := producer.processMonitor(context.Background(), "child", now.UnixMilli())
+		assert.NoError(t, err)
+		assert.Equal(t, 30, interval)
+		mockQueueSvc.AssertExpectations(t)
+		mockQueueSvc.AssertNotCalled(t, "EnqueueUnique", mock.Anything, worker.TaskTypeHealthCheck, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("resumes checks once the parent recovers", func(t *testing.T) {
+		producer, mockQueueSvc := setup(newChild(0), up, cameUp)
+		expectHealthCheck(mockQueueSvc)
+
+		_, err := producer.processMonitor(context.Background(), "child", now.UnixMilli())
+		assert.NoError(t, err)
+		mockQueueSvc.AssertExpectations(t)
+		mockQueueSvc.AssertNotCalled(t, "EnqueueUnique", mock.Anything, worker.TaskTypeIngester, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("skips within the parent down timeout", func(t *testing.T) {
+		producer, mockQueueSvc := setup(newChild(600), down, wentDown)
+		mockQueueSvc.On("EnqueueUnique", mock.Anything, worker.TaskTypeIngester, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(&queue.TaskInfo{ID: "task-1"}, nil)
+
+		_, err := producer.processMonitor(context.Background(), "child", now.UnixMilli())
+		assert.NoError(t, err)
+		mockQueueSvc.AssertNotCalled(t, "EnqueueUnique", mock.Anything, worker.TaskTypeHealthCheck, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("resumes checks after the parent down timeout", func(t *testing.T) {
+		// The parent went down 5 minutes ago, longer than the 2 minute timeout
+		producer, mockQueueSvc := setup(newChild(120), down, wentDown)
+		expectHealthCheck(mockQueueSvc)
+
+		_, err := producer.processMonitor(context.Background(), "child", now.UnixMilli())
+		assert.NoError(t, err)
+		mockQueueSvc.AssertExpectations(t)
+	})
+
+	t.Run("checks as usual without the option", func(t *testing.T) {
+		child := newChild(0)
+		child.SkipWhenParentDown = false
+		producer, mockQueueSvc := setup(child, down, wentDown)
+		expectHealthCheck(mockQueueSvc)
+
+		_, err := producer.processMonitor(context.Background(), "child", now.UnixMilli())
+		assert.NoError(t, err)
+		mockQueueSvc.AssertExpectations(t)
+	})
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"peekaping/internal/modules/events"
+	"peekaping/internal/modules/heartbeat"
 	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/shared"
 
 	"go.uber.org/zap"
 )
@@ -42,6 +44,11 @@ func (el *EventListener) Subscribe(eventBus events.EventBus) {
 		el.handleMonitorDeleted(event)
 	})
 
+	// Subscribe to heartbeat events to schedule retries
+	eventBus.Subscribe(events.HeartbeatEvent, func(event events.Event) {
+		el.handleHeartbeat(event)
+	})
+
 	el.logger.Info("Successfully subscribed to monitor events")
 }
 
@@ -123,6 +130,33 @@ func (el *EventListener) handleMonitorDeleted(event events.Event) {
 	}
 }
 
+// handleHeartbeat schedules the retry of a monitor whose check failed while it has retries
+// left, which the ingester records as pending
+func (el *EventListener) handleHeartbeat(event events.Event) {
+	// Only process events if we are the leader
+	if !el.producer.leaderElection.IsLeader() {
+		return
+	}
+
+	var hb heartbeat.Model
+	if err := el.unmarshalPayload(event.Payload, &hb); err != nil {
+		el.logger.Errorw("Failed to unmarshal heartbeat event", "error", err)
+		return
+	}
+
+	if hb.Status != shared.MonitorStatusPending {
+		return
+	}
+
+	ctx := context.Background()
+	if err := el.producer.ScheduleRetry(ctx, hb.MonitorID, hb.Retries); err != nil {
+		el.logger.Errorw("Failed to schedule monitor retry",
+			"monitor_id", hb.MonitorID,
+			"error", err,
+		)
+	}
+}
+
 // unmarshalPayload unmarshals the event payload from JSON
 func (el *EventListener) unmarshalPayload(payload interface{}, target interface{}) error {
 	// Payload can be either json.RawMessage or already unmarshaled data
@@ -6,8 +6,11 @@ import (
 	"testing"
 
 	"peekaping/internal/modules/events"
+	"peekaping/internal/modules/heartbeat"
 	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/shared"
 
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go.uber.org/zap"
@@ -42,11 +45,12 @@ func TestEventListener_Subscribe(t *testing.T) {
 	eventBus.On("Subscribe", events.MonitorCreated, mock.Anything).Return()
 	eventBus.On("Subscribe", events.MonitorUpdated, mock.Anything).Return()
 	eventBus.On("Subscribe", events.MonitorDeleted, mock.Anything).Return()
+	eventBus.On("Subscribe", events.HeartbeatEvent, mock.Anything).Return()
 
 	eventListener.Subscribe(eventBus)
 
-	// Verify Subscribe was called 3 times
-	eventBus.AssertNumberOfCalls(t, "Subscribe", 3)
+	// Verify Subscribe was called 4 times
+	eventBus.AssertNumberOfCalls(t, "Subscribe", 4)
 }
 
 func TestEventListener_HandleMonitorCreated(t *testing.T) {
@@ -239,6 +243,51 @@ func TestEventListener_HandleMonitorDeleted(t *testing.T) {
 	})
 }
 
+func TestEventListener_HandleHeartbeat(t *testing.T) {
+	client, mr := setupTestRedis(t)
+	defer mr.Close()
+
+	logger := zap.NewNop().Sugar()
+	le := NewLeaderElection(client, "node1", logger)
+	ctx := context.Background()
+	le.tryBecomeLeader(ctx)
+
+	mockMonitorSvc := new(MockMonitorService)
+	mockMonitorSvc.On("FindByID", mock.Anything, "monitor-123").Return(&monitor.Model{
+		ID: "monitor-123", Type: "http", Active: true, Interval: 300, RetryInterval: 30, RetryBackoff: shared.RetryBackoffLinear,
+	}, nil)
+	producer := &Producer{
+		rdb:              client,
+		logger:           logger,
+		ctx:              ctx,
+		leaderElection:   le,
+		monitorService:   mockMonitorSvc,
+		monitorIntervals: make(map[string]int),
+	}
+	eventListener := NewEventListener(producer, logger)
+
+	nowMs := producer.redisNowMs()
+	regular := float64(nowMs + 300_000)
+	client.ZAdd(ctx, SchedDueKey, redis.Z{Score: regular, Member: "monitor-123"})
+
+	handle := func(status shared.MonitorStatus, retries int) float64 {
+		payload, err := json.Marshal(heartbeat.Model{MonitorID: "monitor-123", Status: status, Retries: retries})
+		assert.NoError(t, err)
+		eventListener.handleHeartbeat(events.Event{Type: events.HeartbeatEvent, Payload: json.RawMessage(payload)})
+		score, err := client.ZScore(ctx, SchedDueKey, "monitor-123").Result()
+		assert.NoError(t, err)
+		return score
+	}
+
+	// Down and up checks keep the regular schedule
+	assert.Equal(t, regular, handle(shared.MonitorStatusDown, 4))
+	assert.Equal(t, regular, handle(shared.MonitorStatusUp, 0))
+
+	// The second retry of a linear backoff waits twice the retry interval
+	score := handle(shared.MonitorStatusPending, 2)
+	assert.InDelta(t, float64(nowMs+60_000), score, 1000)
+}
+
 func TestEventListener_UnmarshalPayload(t *testing.T) {
 	logger := zap.NewNop().Sugar()
 	eventListener := &EventListener{logger: logger}
@@ -0,0 +1,99 @@
+package producer
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// catchUpLag is how late due monitors may be claimed before a producer goroutine
+	// claims faster than the configured limits
+	catchUpLag = time.Second
+	// lagWarnThreshold is the scheduling lag above which the producer logs a warning
+	lagWarnThreshold = 5 * time.Second
+	// lagWarnEvery limits the scheduling lag warnings while the producer is behind
+	lagWarnEvery = 30 * time.Second
+)
+
+// claimedMonitor is a monitor claimed from the due set with the time it was due at
+type claimedMonitor struct {
+	ID    string
+	DueMs int64
+}
+
+// SchedulerMetrics records how late due monitors are claimed, i.e. the time between a
+// monitor becoming due and its check being enqueued
+type SchedulerMetrics struct {
+	lag prometheus.Histogram
+}
+
+func NewSchedulerMetrics(registerer prometheus.Registerer) *SchedulerMetrics {
+	m := &SchedulerMetrics{
+		lag: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "peekaping",
+			Subsystem: "producer",
+			Name:      "scheduling_lag_seconds",
+			Help:      "Time between a monitor becoming due and its health check being enqueued.",
+			Buckets:   []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+		}),
+	}
+	registerer.MustRegister(m.lag)
+	return m
+}
+
+// ProvideSchedulerMetrics registers the scheduler metrics with the default Prometheus registry
+func ProvideSchedulerMetrics() *SchedulerMetrics {
+	return NewSchedulerMetrics(prometheus.DefaultRegisterer)
+}
+
+func (m *SchedulerMetrics) observeLag(lag time.Duration) {
+	if m == nil {
+		return
+	}
+	m.lag.Observe(lag.Seconds())
+}
+
+// schedulingLag returns the lag of the latest claimed monitor. Claims take the most
+// overdue monitors first, so it is the largest lag of the batch.
+func schedulingLag(claimed []claimedMonitor, nowMs int64) time.Duration {
+	var lag time.Duration
+	for _, c := range claimed {
+		if l := time.Duration(nowMs-c.DueMs) * time.Millisecond; l > lag {
+			lag = l
+		}
+	}
+	return lag
+}
+
+// adaptClaimLimits returns the limits for the next claim. While claims come back full and
+// late, the batch doubles and the tick halves up to maxBatchClaim and minClaimTick so the
+// producer catches up with bursts of due monitors, e.g. all monitors of an interval
+// becoming due at the same aligned boundary. Once it caught up the configured limits apply.
+func adaptClaimLimits(configured, current claimLimits, claimed int, lag time.Duration) claimLimits {
+	if claimed < current.batch || lag < catchUpLag {
+		return configured
+	}
+	return claimLimits{
+		batch: max(configured.batch, min(current.batch*2, maxBatchClaim)),
+		tick:  min(configured.tick, max(current.tick/2, minClaimTick)),
+	}
+}
+
+// warnSchedulingLag logs when due monitors are enqueued late, at most once per lagWarnEvery
+func (p *Producer) warnSchedulingLag(workerID int, lag time.Duration, limits claimLimits) {
+	if lag < lagWarnThreshold {
+		return
+	}
+	p.lagMu.Lock()
+	defer p.lagMu.Unlock()
+	if !p.lagWarnedAt.IsZero() && time.Since(p.lagWarnedAt) < lagWarnEvery {
+		return
+	}
+	p.lagWarnedAt = time.Now()
+	p.logger.Warnw("Producer is behind schedule, due monitors are enqueued late",
+		"worker_id", workerID,
+		"lag", lag,
+		"batch", limits.batch,
+		"tick", limits.tick)
+}
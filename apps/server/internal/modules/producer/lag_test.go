@@ -0,0 +1,140 @@
+package producer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"peekaping/internal/modules/maintenance"
+	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/queue"
+	"peekaping/internal/modules/worker"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestAdaptClaimLimits(t *testing.T) {
+	configured := claimLimits{batch: 50, tick: 50 * time.Millisecond}
+
+	tests := []struct {
+		name     string
+		current  claimLimits
+		claimed  int
+		lag      time.Duration
+		expected claimLimits
+	}{
+		{"keeping up", configured, 50, 100 * time.Millisecond, configured},
+		{"late but the batch wasn't full", configured, 20, time.Minute, configured},
+		{"full and late", configured, 50, 2 * time.Second, claimLimits{batch: 100, tick: 25 * time.Millisecond}},
+		{"bounded", claimLimits{batch: 800, tick: 12 * time.Millisecond}, 800, 2 * time.Second, claimLimits{batch: maxBatchClaim, tick: minClaimTick}},
+		{"caught up", claimLimits{batch: 400, tick: minClaimTick}, 120, 2 * time.Second, configured},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, adaptClaimLimits(configured, tt.current, tt.claimed, tt.lag))
+		})
+	}
+}
+
+func TestSchedulingLag(t *testing.T) {
+	claimed := []claimedMonitor{{ID: "mon-1", DueMs: 1000}, {ID: "mon-2", DueMs: 4000}, {ID: "mon-3", DueMs: 2500}}
+	assert.Equal(t, 4*time.Second, schedulingLag(claimed, 5000))
+	assert.Zero(t, schedulingLag(nil, 5000))
+}
+
+func TestProducer_KeepsUpWithAlignedBurst(t *testing.T) {
+	if testing.Short() {
+		t.Skip("schedules 10k monitors")
+	}
+
+	client, mr := setupTestRedis(t)
+	defer mr.Close()
+
+	const monitors = 10000
+	const goroutines = 4
+
+	mockMonitorSvc := new(MockMonitorService)
+	mockMaintenanceSvc := new(MockMaintenanceService)
+	mockQueueSvc := new(MockQueueService)
+	// Without catching up 4 goroutines claiming 10 per 100ms need 25s for the burst
+	settingSvc := claimSettingService("10", "100")
+	settingSvc.On("GetByKey", mock.Anything, mock.Anything).Return(nil, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	registry := prometheus.NewRegistry()
+	producer := &Producer{
+		rdb:                client,
+		logger:             zap.NewNop().Sugar(),
+		ctx:                ctx,
+		cancel:             cancel,
+		monitorService:     mockMonitorSvc,
+		maintenanceService: mockMaintenanceSvc,
+		queueService:       mockQueueSvc,
+		settingService:     settingSvc,
+		metrics:            NewSchedulerMetrics(registry),
+	}
+
+	mon := &monitor.Model{ID: "mon", Name: "Test Monitor", Type: "http", Active: true, Interval: 60, Timeout: 30}
+	mockMonitorSvc.On("FindByID", mock.Anything, mock.Anything).Return(mon, nil)
+	mockMaintenanceSvc.On("GetMaintenancesByMonitorID", mock.Anything, mock.Anything).Return([]*maintenance.Model{}, nil)
+
+	// Every monitor with the same interval is due at the same aligned boundary
+	dueMs := time.Now().UnixMilli()
+	members := make([]redis.Z, 0, monitors)
+	for i := range monitors {
+		members = append(members, redis.Z{Score: float64(dueMs), Member: fmt.Sprintf("mon-%d", i)})
+	}
+	require.NoError(t, client.ZAdd(ctx, SchedDueKey, members...).Err())
+	mockQueueSvc.On("EnqueueUnique", mock.Anything, worker.TaskTypeHealthCheck, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(&queue.TaskInfo{ID: "task-1"}, nil)
+
+	// Lag is measured on a simulated clock, so it only depends on the claim limits and not
+	// on how fast the machine enqueues. Like runProducer, each goroutine claims one batch
+	// per tick at the time of the tick and adapts its limits to the claim.
+	configured := producer.currentClaimLimits(ctx)
+	limits := make([]claimLimits, goroutines)
+	nextMs := make([]int64, goroutines)
+	for i := range goroutines {
+		limits[i] = configured
+		nextMs[i] = dueMs
+	}
+	lags := make([]time.Duration, 0, monitors)
+	for len(lags) < monitors {
+		i := 0
+		for j := range goroutines {
+			if nextMs[j] < nextMs[i] {
+				i = j
+			}
+		}
+		nowMs := nextMs[i]
+
+		claimed, err := producer.claimDueMonitors(ctx, nowMs, limits[i].batch, int64(LeaseTTL/time.Millisecond))
+		require.NoError(t, err)
+		require.NotEmpty(t, claimed)
+		for _, c := range claimed {
+			lags = append(lags, time.Duration(nowMs-c.DueMs)*time.Millisecond)
+		}
+		producer.processClaimed(ctx, i, claimed, nowMs)
+
+		limits[i] = adaptClaimLimits(configured, limits[i], len(claimed), schedulingLag(claimed, nowMs))
+		nextMs[i] += limits[i].tick.Milliseconds()
+	}
+
+	sort.Slice(lags, func(i, j int) bool { return lags[i] < lags[j] })
+	assert.Less(t, lags[monitors*99/100-1], 10*time.Second, "p99 scheduling lag")
+
+	// Every enqueued check is recorded in the lag histogram
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	require.Len(t, families, 1)
+	assert.GreaterOrEqual(t, families[0].GetMetric()[0].GetHistogram().GetSampleCount(), uint64(monitors))
+}
@@ -40,6 +40,7 @@ func TestRunLeadershipMonitor(t *testing.T) {
 			nil,
 			nil,
 			le,
+			nil,
 			cfg,
 			logger,
 		)
@@ -92,6 +93,7 @@ func TestRunLeadershipMonitor(t *testing.T) {
 			nil,
 			nil,
 			le,
+			nil,
 			cfg,
 			logger,
 		)
@@ -152,6 +154,7 @@ func TestStartJobProcessing(t *testing.T) {
 			nil,
 			nil,
 			le,
+			nil,
 			cfg,
 			logger,
 		)
@@ -194,6 +197,7 @@ func TestStartMonitorSyncing(t *testing.T) {
 			nil,
 			nil,
 			le,
+			nil,
 			cfg,
 			logger,
 		)
@@ -242,6 +246,7 @@ func TestStartMonitorSyncing(t *testing.T) {
 			nil,
 			nil,
 			le,
+			nil,
 			cfg,
 			logger,
 		)
@@ -284,6 +289,7 @@ func TestStopMonitorSyncing(t *testing.T) {
 			nil,
 			nil,
 			le,
+			nil,
 			cfg,
 			logger,
 		)
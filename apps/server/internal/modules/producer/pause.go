@@ -0,0 +1,59 @@
+package producer
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"peekaping/internal/modules/setting"
+)
+
+// monitoringPausedRefresh is how long the global pause switch is cached, so the producer
+// loops don't read the setting on every claim tick
+const monitoringPausedRefresh = time.Second
+
+// isMonitoringPaused reports whether the global monitoring_paused setting is on. While it
+// is, the producer claims nothing: due monitors keep their slot in the schedule and are
+// picked up as soon as monitoring is resumed.
+func (p *Producer) isMonitoringPaused(ctx context.Context) bool {
+	p.pauseMu.Lock()
+	defer p.pauseMu.Unlock()
+
+	if !p.pauseCheckedAt.IsZero() && time.Since(p.pauseCheckedAt) < monitoringPausedRefresh {
+		return p.paused
+	}
+	p.pauseCheckedAt = time.Now()
+
+	paused := p.readMonitoringPaused(ctx)
+	if paused != p.paused {
+		if paused {
+			p.logger.Infow("Monitoring paused, no checks will be enqueued")
+		} else {
+			p.logger.Infow("Monitoring resumed")
+		}
+	}
+	p.paused = paused
+	return paused
+}
+
+func (p *Producer) readMonitoringPaused(ctx context.Context) bool {
+	if p.settingService == nil {
+		return false
+	}
+	s, err := p.settingService.GetByKey(ctx, setting.MonitoringPausedSettingKey)
+	if err != nil {
+		// Keep the last known state rather than flipping on a transient error
+		p.logger.Warnw("Failed to fetch monitoring paused setting", "error", err)
+		return p.paused
+	}
+	if s == nil {
+		return false
+	}
+	paused, err := strconv.ParseBool(strings.TrimSpace(s.Value))
+	if err != nil {
+		p.logger.Warnw("Invalid monitoring paused setting", "value", s.Value, "error", err)
+		return false
+	}
+	return paused
+}
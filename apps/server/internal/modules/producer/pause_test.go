@@ -0,0 +1,134 @@
+package producer
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"peekaping/internal/modules/maintenance"
+	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/queue"
+	"peekaping/internal/modules/setting"
+	"peekaping/internal/modules/shared"
+	"peekaping/internal/modules/worker"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// pauseSettingService serves the monitoring_paused setting from a switch the test can flip
+// while the producer loop is running, other keys go to the mock
+type pauseSettingService struct {
+	*MockSettingService
+	paused atomic.Bool
+}
+
+func (s *pauseSettingService) GetByKey(ctx context.Context, key string) (*shared.SettingModel, error) {
+	if key == setting.MonitoringPausedSettingKey {
+		return &shared.SettingModel{Key: key, Value: strconv.FormatBool(s.paused.Load()), Type: "bool"}, nil
+	}
+	return s.MockSettingService.GetByKey(ctx, key)
+}
+
+func TestIsMonitoringPaused(t *testing.T) {
+	t.Run("reads and caches the setting", func(t *testing.T) {
+		mockSettingSvc := new(MockSettingService)
+		mockSettingSvc.On("GetByKey", mock.Anything, setting.MonitoringPausedSettingKey).
+			Return(&shared.SettingModel{Value: "true"}, nil).Once()
+
+		producer := &Producer{logger: zap.NewNop().Sugar(), settingService: mockSettingSvc}
+
+		assert.True(t, producer.isMonitoringPaused(context.Background()))
+		assert.True(t, producer.isMonitoringPaused(context.Background()), "cached value is used")
+		mockSettingSvc.AssertExpectations(t)
+	})
+
+	t.Run("missing or invalid setting is not paused", func(t *testing.T) {
+		mockSettingSvc := new(MockSettingService)
+		mockSettingSvc.On("GetByKey", mock.Anything, setting.MonitoringPausedSettingKey).Return(nil, nil).Once()
+		mockSettingSvc.On("GetByKey", mock.Anything, setting.MonitoringPausedSettingKey).
+			Return(&shared.SettingModel{Value: "maybe"}, nil).Once()
+
+		producer := &Producer{logger: zap.NewNop().Sugar(), settingService: mockSettingSvc}
+
+		assert.False(t, producer.isMonitoringPaused(context.Background()))
+		producer.pauseCheckedAt = time.Time{}
+		assert.False(t, producer.isMonitoringPaused(context.Background()))
+		mockSettingSvc.AssertExpectations(t)
+	})
+
+	t.Run("keeps last state when the setting can't be read", func(t *testing.T) {
+		mockSettingSvc := new(MockSettingService)
+		mockSettingSvc.On("GetByKey", mock.Anything, setting.MonitoringPausedSettingKey).Return(nil, assert.AnError)
+
+		producer := &Producer{logger: zap.NewNop().Sugar(), settingService: mockSettingSvc, paused: true}
+
+		assert.True(t, producer.isMonitoringPaused(context.Background()))
+	})
+}
+
+func TestRunProducer_PauseAndResume(t *testing.T) {
+	client, mr := setupTestRedis(t)
+	defer mr.Close()
+
+	mockMonitorSvc := new(MockMonitorService)
+	mockMaintenanceSvc := new(MockMaintenanceService)
+	mockQueueSvc := new(MockQueueService)
+	settingSvc := &pauseSettingService{MockSettingService: newMockSettingServiceWithoutDefaultProxy()}
+	settingSvc.paused.Store(true)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	producer := &Producer{
+		rdb:                client,
+		logger:             zap.NewNop().Sugar(),
+		ctx:                ctx,
+		cancel:             cancel,
+		monitorService:     mockMonitorSvc,
+		maintenanceService: mockMaintenanceSvc,
+		queueService:       mockQueueSvc,
+		settingService:     settingSvc,
+	}
+
+	mon := &monitor.Model{ID: "mon-1", Name: "Test Monitor", Type: "http", Active: true, Interval: 60, Timeout: 30}
+	enqueued := make(chan struct{}, 1)
+	mockMonitorSvc.On("FindByID", mock.Anything, "mon-1").Return(mon, nil)
+	mockMaintenanceSvc.On("GetMaintenancesByMonitorID", mock.Anything, "mon-1").Return([]*maintenance.Model{}, nil)
+	mockQueueSvc.On("EnqueueUnique", mock.Anything, worker.TaskTypeHealthCheck, mock.Anything, "healthcheck:mon-1", mock.Anything, mock.Anything).
+		Run(func(mock.Arguments) { enqueued <- struct{}{} }).
+		Return(&queue.TaskInfo{ID: "task-1"}, nil).Once()
+
+	// mon-1 is overdue
+	require.NoError(t, client.ZAdd(ctx, SchedDueKey, redis.Z{Score: 0, Member: "mon-1"}).Err())
+
+	producer.wg.Add(1)
+	go producer.runProducer(0)
+	defer func() {
+		cancel()
+		producer.wg.Wait()
+	}()
+
+	// While paused nothing is claimed and the schedule is left as it was
+	time.Sleep(300 * time.Millisecond)
+	score, err := client.ZScore(ctx, SchedDueKey, "mon-1").Result()
+	require.NoError(t, err)
+	assert.Equal(t, float64(0), score)
+	assert.Zero(t, client.ZCard(ctx, SchedLeaseKey).Val())
+	mockQueueSvc.AssertNotCalled(t, "EnqueueUnique", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	// Unpausing picks the overdue monitor up and reschedules it
+	settingSvc.paused.Store(false)
+	select {
+	case <-enqueued:
+	case <-time.After(3 * time.Second):
+		t.Fatal("check was not enqueued after monitoring was resumed")
+	}
+	assert.Eventually(t, func() bool {
+		score, err := client.ZScore(ctx, SchedDueKey, "mon-1").Result()
+		return err == nil && score > 0
+	}, 2*time.Second, 10*time.Millisecond)
+}
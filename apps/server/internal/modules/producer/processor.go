@@ -4,9 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
+	"peekaping/internal/modules/healthcheck/executor"
+	"peekaping/internal/modules/maintenance"
+	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/proxy"
 	"peekaping/internal/modules/queue"
 	"peekaping/internal/modules/shared"
 	"peekaping/internal/modules/worker"
@@ -14,7 +19,7 @@ import (
 
 // claimDueMonitors atomically claims a batch of due monitors from the due queue
 // It moves monitors from the due set (where score <= nowMs) to the lease set with a lease expiry
-func (p *Producer) claimDueMonitors(ctx context.Context, nowMs int64, maxMonitors int, leaseTTLMs int64) ([]string, error) {
+func (p *Producer) claimDueMonitors(ctx context.Context, nowMs int64, maxMonitors int, leaseTTLMs int64) ([]claimedMonitor, error) {
 	result, err := claimScript.Run(
 		ctx,
 		p.rdb,
@@ -26,13 +31,24 @@ func (p *Producer) claimDueMonitors(ctx context.Context, nowMs int64, maxMonitor
 	if err != nil {
 		return nil, err
 	}
-	return toStringSlice(result), nil
+
+	items := toStringSlice(result)
+	claimed := make([]claimedMonitor, 0, len(items)/2)
+	for i := 0; i+1 < len(items); i += 2 {
+		dueMs, err := strconv.ParseFloat(items[i+1], 64)
+		if err != nil {
+			dueMs = float64(nowMs)
+		}
+		claimed = append(claimed, claimedMonitor{ID: items[i], DueMs: int64(dueMs)})
+	}
+	return claimed, nil
 }
 
 // runProducer is the main producer loop
 func (p *Producer) runProducer(workerID int) error {
 	defer p.wg.Done()
 
+	var limits claimLimits
 	for {
 		select {
 		case <-p.ctx.Done():
@@ -40,11 +56,24 @@ func (p *Producer) runProducer(workerID int) error {
 		default:
 		}
 
+		configured := p.currentClaimLimits(p.ctx)
+		if limits == (claimLimits{}) {
+			limits = configured
+		}
+
+		// While monitoring is paused nothing is claimed, the due set is left untouched
+		if p.isMonitoringPaused(p.ctx) {
+			limits = configured
+			time.Sleep(limits.tick)
+			continue
+		}
+
+		tickStart := time.Now()
 		nowMs := p.redisNowMs()
 		leaseTTLMs := int64(LeaseTTL / time.Millisecond)
 
 		// Atomically claim a batch of due monitors
-		ids, err := p.claimDueMonitors(p.ctx, nowMs, BatchClaim, leaseTTLMs)
+		claimed, err := p.claimDueMonitors(p.ctx, nowMs, limits.batch, leaseTTLMs)
 		if err != nil {
 			p.logger.Errorw("Claim error", "worker_id", workerID, "error", err)
 			time.Sleep(100 * time.Millisecond)
@@ -52,82 +81,166 @@ func (p *Producer) runProducer(workerID int) error {
 		}
 
 		// If no monitors were claimed, sleep until next check
-		if len(ids) == 0 {
+		if len(claimed) == 0 {
+			limits = configured
 			// Sleep until next check
-			time.Sleep(ClaimTick)
+			time.Sleep(limits.tick)
 			continue
 		}
 
-		p.logger.Debugw("Claimed monitors for scheduling", "worker_id", workerID, "count", len(ids))
+		lag := schedulingLag(claimed, nowMs)
+		p.logger.Debugw("Claimed monitors for scheduling", "worker_id", workerID, "count", len(claimed), "lag", lag)
+		p.warnSchedulingLag(workerID, lag, limits)
 
 		// Process each claimed monitor with a timeout context
 		// This ensures that claimed monitors can complete processing even during shutdown
-		// Use a generous timeout to handle large batches (up to BatchClaim=1000 monitors)
+		// Use a generous timeout to handle large batches (up to maxBatchClaim monitors)
 		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
-		pipe := p.rdb.Pipeline()
-		for _, monitorID := range ids {
-			interval, err := p.processMonitor(ctx, monitorID, nowMs)
-			if err != nil {
-				p.logger.Errorw("Failed to process monitor",
-					"worker_id", workerID,
-					"monitor_id", monitorID,
-					"error", err)
-				// Don't reschedule on error - let lease expire and be reclaimed
-				continue
+		p.processClaimed(ctx, workerID, claimed, nowMs)
+		cancel()
+
+		// Claim at most one batch per tick, so bursts of due monitors reach the queue at
+		// no more than batch / tick per producer goroutine. A goroutine falling behind
+		// claims larger batches more often until it caught up.
+		limits = adaptClaimLimits(configured, limits, len(claimed), lag)
+		if wait := limits.tick - time.Since(tickStart); wait > 0 {
+			select {
+			case <-p.ctx.Done():
+			case <-time.After(wait):
 			}
+		}
+	}
+}
 
-			// Skip rescheduling if interval is invalid (e.g., monitor was deleted or deactivated)
+// processClaimed enqueues the checks of claimed monitors and moves each of them from the
+// lease set back to the due set at its next interval
+func (p *Producer) processClaimed(ctx context.Context, workerID int, claimed []claimedMonitor, nowMs int64) {
+	start := time.Now()
+	pipe := p.rdb.Pipeline()
+	for _, c := range claimed {
+		monitorID := c.ID
+		interval, err := p.processMonitor(ctx, monitorID, nowMs)
+		p.metrics.observeLag(time.Duration(nowMs-c.DueMs)*time.Millisecond + time.Since(start))
+		if err != nil {
+			p.logger.Errorw("Failed to process monitor",
+				"worker_id", workerID,
+				"monitor_id", monitorID,
+				"error", err)
+			// Retry at the next interval like any other check, so a monitor whose checks
+			// keep failing to enqueue stays in the schedule. Only when the interval is
+			// unknown the lease is left to expire and be reclaimed.
+			if interval <= 0 {
+				interval = p.cachedInterval(monitorID)
+			}
 			if interval <= 0 {
-				p.logger.Debugw("Skipping reschedule for monitor with invalid interval",
-					"worker_id", workerID,
-					"monitor_id", monitorID)
 				continue
 			}
-
-			// Calculate next execution time
-			next := nextAligned(time.UnixMilli(nowMs).UTC(), time.Duration(interval)*time.Second)
-			pipe.Eval(
-				ctx,
-				reschedLua,
-				[]string{SchedLeaseKey, SchedDueKey},
-				monitorID,
-				next.UnixMilli(),
-			)
+		} else if interval <= 0 {
+			// Skip rescheduling if interval is invalid (e.g., monitor was deleted or deactivated)
+			p.logger.Debugw("Skipping reschedule for monitor with invalid interval",
+				"worker_id", workerID,
+				"monitor_id", monitorID)
+			continue
 		}
 
-		if _, err := pipe.Exec(ctx); err != nil {
-			p.logger.Errorw("Resched pipeline error", "worker_id", workerID, "error", err)
-		}
-		cancel()
+		// Calculate next execution time
+		next := nextAligned(time.UnixMilli(nowMs).UTC(), time.Duration(interval)*time.Second)
+		pipe.Eval(
+			ctx,
+			reschedLua,
+			[]string{SchedLeaseKey, SchedDueKey},
+			monitorID,
+			next.UnixMilli(),
+		)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		p.logger.Errorw("Resched pipeline error", "worker_id", workerID, "error", err)
 	}
 }
 
-func (p *Producer) isUnderMaintenance(ctx context.Context, monitorID string) (bool, error) {
+// cachedInterval returns the interval the schedule knows for a monitor, 0 when unknown
+func (p *Producer) cachedInterval(monitorID string) int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.monitorIntervals[monitorID]
+}
+
+// isUnderMaintenance checks whether any maintenance attached to the monitor covers
+// the given scheduled time. suppressChecks is true when one of the covering windows
+// asks for checks to be skipped entirely rather than recorded as maintenance beats.
+// runChecks is true when a covering window ends on recovery, which needs the checks to
+// run as usual. Such windows are ended here once their monitors recovered.
+func (p *Producer) isUnderMaintenance(ctx context.Context, monitorID string, at time.Time) (underMaintenance bool, suppressChecks bool, runChecks bool, err error) {
 	maintenances, err := p.maintenanceService.GetMaintenancesByMonitorID(ctx, monitorID)
 	if err != nil {
-		return false, err
+		return false, false, false, err
 	}
 
 	p.logger.Debugf("Found %d maintenances for monitor %s", len(maintenances), monitorID)
 
 	for _, m := range maintenances {
-		underMaintenance, err := p.maintenanceService.IsUnderMaintenance(ctx, m)
+		active, err := p.maintenanceService.IsUnderMaintenanceAt(ctx, m, at)
 		if err != nil {
 			p.logger.Warnf("Failed to get maintenance status for maintenance %s: %v", m.ID, err)
 			continue
 		}
 
+		if active && m.AutoEndOnRecovery && !m.SuppressChecks {
+			if p.endMaintenanceOnRecovery(ctx, m) {
+				continue
+			}
+			runChecks = true
+		}
+
 		// If any maintenance is under-maintenance, the monitor is under maintenance
-		if underMaintenance {
-			return true, nil
+		if active {
+			underMaintenance = true
+			if m.SuppressChecks {
+				return true, true, false, nil
+			}
+		}
+	}
+
+	return underMaintenance, false, runChecks, nil
+}
+
+// endMaintenanceOnRecovery ends a window with auto_end_on_recovery once the latest checks
+// of all its monitors are up and reports whether it did. Errors are logged and keep the
+// window going.
+func (p *Producer) endMaintenanceOnRecovery(ctx context.Context, m *maintenance.Model) bool {
+	monitorIDs, err := p.maintenanceService.GetMonitors(ctx, m.ID)
+	if err != nil {
+		p.logger.Warnw("Failed to fetch monitors of maintenance", "maintenance_id", m.ID, "error", err)
+		return false
+	}
+
+	checks := m.RecoveryChecks
+	if checks <= 0 {
+		checks = maintenance.DefaultRecoveryChecks
+	}
+
+	latest := make(map[string][]*shared.HeartBeatModel, len(monitorIDs))
+	for _, id := range monitorIDs {
+		heartbeats, err := p.heartbeatService.FindByMonitorIDPaginated(ctx, id, checks, 0, nil, false)
+		if err != nil {
+			p.logger.Warnw("Failed to fetch latest heartbeats for maintenance recovery", "maintenance_id", m.ID, "monitor_id", id, "error", err)
+			return false
 		}
+		latest[id] = heartbeats
 	}
 
-	return false, nil
+	ended, err := p.maintenanceService.EndOnRecovery(ctx, m, latest)
+	if err != nil {
+		p.logger.Warnw("Failed to end maintenance on recovery", "maintenance_id", m.ID, "error", err)
+		return false
+	}
+	return ended
 }
 
 // processMonitor loads monitor config and enqueues a health check task
-// Returns the monitor interval (for rescheduling) and any error
+// Returns the monitor interval (for rescheduling, also along with an error once the
+// monitor is loaded) and any error
 func (p *Producer) processMonitor(ctx context.Context, monitorID string, nowMs int64) (int, error) {
 	start := time.Now()
 	// Fetch monitor from database
@@ -147,34 +260,41 @@ func (p *Producer) processMonitor(ctx context.Context, monitorID string, nowMs i
 		return 0, nil
 	}
 
-	isUnderMaintenance, err := p.isUnderMaintenance(ctx, monitorID)
+	// Maintenance is evaluated against the scheduled tick, not wall-clock time, so a
+	// window that becomes active mid-check only affects checks scheduled from then on
+	scheduledAt := time.UnixMilli(nowMs).UTC()
+	isUnderMaintenance, suppressChecks, runChecks, err := p.isUnderMaintenance(ctx, monitorID, scheduledAt)
 	if err != nil {
 		p.logger.Errorw("Failed to check if monitor is under maintenance", "monitor_id", monitorID, "error", err)
-		return 0, err
+		return mon.Interval, err
 	}
 
-	// Fetch proxy if configured
-	var proxyData *worker.ProxyData
-	if mon.ProxyId != "" {
-		proxyModel, err := p.proxyService.FindByID(ctx, mon.ProxyId)
-		if err != nil {
-			p.logger.Warnw("Failed to fetch proxy, continuing without it",
-				"monitor_id", monitorID,
-				"proxy_id", mon.ProxyId,
-				"error", err)
-		} else {
-			proxyData = &worker.ProxyData{
-				ID:       proxyModel.ID,
-				Protocol: proxyModel.Protocol,
-				Host:     proxyModel.Host,
-				Port:     proxyModel.Port,
-				Auth:     proxyModel.Auth,
-				Username: proxyModel.Username,
-				Password: proxyModel.Password,
-			}
+	// With the global toggle on, or while a window waits for the monitor to recover,
+	// checks run as usual during maintenance and only their alerts are suppressed. The
+	// flag travels with the check to the heartbeat events the notification listener gets.
+	alertsSuppressed := false
+	if isUnderMaintenance && (runChecks || p.checkDuringMaintenance(ctx)) {
+		p.logger.Debugw("Running check during maintenance", "monitor_id", monitorID, "scheduled_at", scheduledAt)
+		isUnderMaintenance, suppressChecks, alertsSuppressed = false, false, true
+	}
+
+	if suppressChecks {
+		p.logger.Debugw("Skipping check suppressed by maintenance", "monitor_id", monitorID, "scheduled_at", scheduledAt)
+		return mon.Interval, nil
+	}
+
+	// Checks against systems depending on a failing parent only add load during the
+	// outage. Maintenance checks still run so the maintenance status is recorded.
+	if !isUnderMaintenance && p.parentDown(ctx, mon, scheduledAt) {
+		if err := p.skipForParent(ctx, mon, scheduledAt); err != nil {
+			return mon.Interval, err
 		}
+		return mon.Interval, nil
 	}
 
+	// Fetch proxy if configured
+	proxyData := p.resolveProxy(ctx, mon)
+
 	// Check if certificate expiry checking is enabled in monitor configuration
 	// This applies to monitors that support TLS (http, tcp)
 	checkCertExpiry := false
@@ -200,19 +320,40 @@ func (p *Producer) processMonitor(ctx context.Context, monitorID string, nowMs i
 			"check_cert_expiry", checkCertExpiry)
 	}
 
-	// For push monitors, fetch the latest heartbeat to include in the payload
+	// Push monitors and monitors confirming transitions get the latest heartbeat in the
+	// payload, the worker compares the result of the check with its status
 	var lastHeartbeat *shared.HeartBeatModel
-	if mon.Type == "push" {
+	if mon.Type == "push" || mon.ConfirmTransition {
 		latestHeartbeats, err := p.heartbeatService.FindByMonitorIDPaginated(ctx, mon.ID, 1, 0, nil, false)
-		if err != nil {
-			p.logger.Warnw("Failed to fetch latest heartbeat for push monitor",
+		switch {
+		case err != nil && mon.Type == "push":
+			// Without the last heartbeat the watchdog can't tell a silent agent apart
+			return mon.Interval, fmt.Errorf("failed to fetch latest heartbeat for push monitor: %w", err)
+		case err != nil:
+			p.logger.Warnw("Failed to fetch latest heartbeat, the check won't be confirmed",
 				"monitor_id", mon.ID,
 				"error", err)
-		} else if len(latestHeartbeats) > 0 {
+		case len(latestHeartbeats) > 0:
 			lastHeartbeat = latestHeartbeats[0]
 		}
 	}
 
+	if mon.Type == "push" {
+		// Outside maintenance push monitors are watched by the producer, no check is enqueued
+		if !isUnderMaintenance {
+			if err := p.watchPushMonitor(ctx, mon, lastHeartbeat, scheduledAt, alertsSuppressed); err != nil {
+				return mon.Interval, err
+			}
+			return mon.Interval, nil
+		}
+	}
+
+	// Group monitors are evaluated from the latest heartbeat of each child
+	var childHeartbeats map[string]*shared.HeartBeatModel
+	if mon.Type == "group" {
+		childHeartbeats = p.fetchChildHeartbeats(ctx, mon)
+	}
+
 	// Create health check task payload
 	payload := worker.HealthCheckTaskPayload{
 		MonitorID:          mon.ID,
@@ -223,17 +364,31 @@ func (p *Producer) processMonitor(ctx context.Context, monitorID string, nowMs i
 		MaxRetries:         mon.MaxRetries,
 		RetryInterval:      mon.RetryInterval,
 		ResendInterval:     mon.ResendInterval,
+		WarmupChecks:       mon.WarmupChecks,
+		LatencyLimit:       mon.LatencyLimit,
+		LatencyChecks:      mon.LatencyChecks,
+		ResultExpression:   mon.ResultExpression,
+		UpMessage:          mon.UpMessage,
+		DownMessage:        mon.DownMessage,
+		ActivatedAt:        mon.ActivatedAt,
 		Config:             mon.Config,
 		Proxy:              proxyData,
+		FallbackProxies:    p.resolveFallbackProxies(ctx, mon),
+		NoProxy:            mon.NoProxy,
+		Serialize:          mon.Serialize,
+		ConfirmTransition:  mon.ConfirmTransition,
 		LastHeartbeat:      lastHeartbeat,
-		ScheduledAt:        time.UnixMilli(nowMs).UTC(),
+		ChildHeartbeats:    childHeartbeats,
+		ScheduledAt:        scheduledAt,
 		IsUnderMaintenance: isUnderMaintenance,
+		AlertsSuppressed:   alertsSuppressed,
 		CheckCertExpiry:    checkCertExpiry,
 	}
 
 	// Enqueue task to worker queue
+	queueName := queue.HealthCheckQueueName(mon.ID, p.healthCheckShards)
 	opts := &queue.EnqueueOptions{
-		Queue:     "healthcheck",
+		Queue:     queueName,
 		MaxRetry:  0,
 		Timeout:   time.Duration(mon.Timeout) * time.Second,
 		Retention: 0,
@@ -252,24 +407,20 @@ func (p *Producer) processMonitor(ctx context.Context, monitorID string, nowMs i
 		if strings.Contains(errMsg, "task ID conflicts") ||
 			strings.Contains(errMsg, "duplicated") ||
 			strings.Contains(errMsg, "already exists") {
-			// try to check if task exists for a while and is archived
-			// if yes, then remove it if it sits longer than
-			// (mon.Timeout + mon.RetryInterval) * mon.MaxRetries
-			existing, exErr := p.queueService.GetTaskInfo(ctx, "healthcheck", uniqueKey)
+			// An archived task failed for good and never runs again, it only holds on to
+			// the task id. Replace it so the monitor keeps being checked.
+			existing, exErr := p.queueService.GetTaskInfo(ctx, queueName, uniqueKey)
 			if exErr != nil {
 				p.logger.Errorf("Error getting duplicate task info: %v", exErr)
-			} else {
-				tooLongThreshold := (mon.Timeout + mon.RetryInterval) * mon.MaxRetries
-				deadline := existing.LastFailedAt.Add(time.Second * time.Duration(tooLongThreshold))
-				now := time.Now().UTC()
-				p.logger.Debugf("now: %s, deadline: %s", now, deadline)
-				if now.After(deadline) && existing.State == "archived" {
-					p.logger.Warnf("Duplicate task exists for more than %d seconds, removing", tooLongThreshold)
-					if cancelErr := p.queueService.DeleteTask(ctx, "healthcheck", uniqueKey); cancelErr != nil {
-						p.logger.Errorf("Error removing duplicate task: %v", cancelErr)
-					}
-					return mon.Interval, nil
+			} else if existing.State == "archived" {
+				p.logger.Warnw("Replacing failed health check task", "monitor_id", mon.ID, "last_error", existing.LastErr)
+				if err := p.queueService.DeleteTask(ctx, queueName, uniqueKey); err != nil {
+					return mon.Interval, fmt.Errorf("failed to remove failed health check task: %w", err)
+				}
+				if _, err := p.queueService.EnqueueUnique(ctx, worker.TaskTypeHealthCheck, payload, uniqueKey, ttl, opts); err != nil {
+					return mon.Interval, fmt.Errorf("failed to enqueue health check: %w", err)
 				}
+				return mon.Interval, nil
 			}
 			// This is not an error - the task is already queued, which is exactly what we want
 			// This commonly happens when multiple workers process monitors concurrently
@@ -279,7 +430,7 @@ func (p *Producer) processMonitor(ctx context.Context, monitorID string, nowMs i
 			return mon.Interval, nil
 		}
 		// This is a real error
-		return 0, fmt.Errorf("failed to enqueue health check: %w", err)
+		return mon.Interval, fmt.Errorf("failed to enqueue health check: %w", err)
 	}
 
 	p.logger.Infow("Enqueued health check",
@@ -290,3 +441,147 @@ func (p *Producer) processMonitor(ctx context.Context, monitorID string, nowMs i
 
 	return mon.Interval, nil
 }
+
+// resolveProxy returns the proxy a check should egress through. A proxy set on the
+// monitor wins, otherwise the global default_proxy_id setting is used unless the
+// monitor opted out with no_proxy. When nothing resolves here the worker may still
+// fall back to its DEFAULT_PROXY_URL.
+func (p *Producer) resolveProxy(ctx context.Context, mon *monitor.Model) *worker.ProxyData {
+	proxyID := mon.ProxyId
+	if proxyID == "" {
+		if mon.NoProxy {
+			return nil
+		}
+		proxyID = p.defaultProxyID(ctx)
+		if proxyID == "" {
+			return nil
+		}
+	}
+
+	return p.fetchProxy(ctx, mon, proxyID)
+}
+
+// resolveFallbackProxies returns the monitor's fallback proxies in order, leaving out
+// those that can't be fetched
+func (p *Producer) resolveFallbackProxies(ctx context.Context, mon *monitor.Model) []*worker.ProxyData {
+	var fallbacks []*worker.ProxyData
+	for _, proxyID := range mon.FallbackProxyIds {
+		if proxyData := p.fetchProxy(ctx, mon, proxyID); proxyData != nil {
+			fallbacks = append(fallbacks, proxyData)
+		}
+	}
+	return fallbacks
+}
+
+func (p *Producer) fetchProxy(ctx context.Context, mon *monitor.Model, proxyID string) *worker.ProxyData {
+	proxyModel, err := p.proxyService.FindByID(ctx, proxyID)
+	if err != nil || proxyModel == nil {
+		p.logger.Warnw("Failed to fetch proxy, continuing without it",
+			"monitor_id", mon.ID,
+			"proxy_id", proxyID,
+			"error", err)
+		return nil
+	}
+
+	return &worker.ProxyData{
+		ID:       proxyModel.ID,
+		Protocol: proxyModel.Protocol,
+		Host:     proxyModel.Host,
+		Port:     proxyModel.Port,
+		Auth:     proxyModel.Auth,
+		Username: proxyModel.Username,
+		Password: proxyModel.Password,
+	}
+}
+
+// defaultProxyRefresh is how long the global default proxy is cached, so checks of
+// monitors without a proxy don't read the setting every time
+const defaultProxyRefresh = 10 * time.Second
+
+// defaultProxyID returns the global default proxy from settings, an empty string when
+// none is configured
+func (p *Producer) defaultProxyID(ctx context.Context) string {
+	p.defaultProxyMu.Lock()
+	defer p.defaultProxyMu.Unlock()
+
+	if !p.defaultProxyCheckedAt.IsZero() && time.Since(p.defaultProxyCheckedAt) < defaultProxyRefresh {
+		return p.defaultProxy
+	}
+	p.defaultProxyCheckedAt = time.Now()
+
+	setting, err := p.settingService.GetByKey(ctx, proxy.DefaultProxySettingKey)
+	if err != nil {
+		// Keep the last known proxy rather than dropping it on a transient error
+		p.logger.Warnw("Failed to fetch default proxy setting", "error", err)
+		return p.defaultProxy
+	}
+	p.defaultProxy = ""
+	if setting != nil {
+		p.defaultProxy = strings.TrimSpace(setting.Value)
+	}
+	return p.defaultProxy
+}
+
+// checkDuringMaintenanceRefresh is how long the check_during_maintenance setting is
+// cached, so checks of monitors under maintenance don't read it every time
+const checkDuringMaintenanceRefresh = 10 * time.Second
+
+// checkDuringMaintenance reports whether the global setting asks for checks to keep
+// running while a maintenance window is active
+func (p *Producer) checkDuringMaintenance(ctx context.Context) bool {
+	p.maintenanceChecksMu.Lock()
+	defer p.maintenanceChecksMu.Unlock()
+
+	if !p.maintenanceChecksCheckedAt.IsZero() && time.Since(p.maintenanceChecksCheckedAt) < checkDuringMaintenanceRefresh {
+		return p.maintenanceChecks
+	}
+	p.maintenanceChecksCheckedAt = time.Now()
+
+	setting, err := p.settingService.GetByKey(ctx, maintenance.CheckDuringMaintenanceSettingKey)
+	if err != nil {
+		// Keep the last known value rather than flipping on a transient error
+		p.logger.Warnw("Failed to fetch check during maintenance setting", "error", err)
+		return p.maintenanceChecks
+	}
+	p.maintenanceChecks = false
+	if setting == nil {
+		return false
+	}
+	enabled, err := strconv.ParseBool(strings.TrimSpace(setting.Value))
+	if err != nil {
+		p.logger.Warnw("Invalid check during maintenance setting", "value", setting.Value, "error", err)
+		return false
+	}
+	p.maintenanceChecks = enabled
+	return enabled
+}
+
+// fetchChildHeartbeats returns the latest heartbeat of every child of a group monitor.
+// Children that can't be loaded are left out and count as down for the group.
+func (p *Producer) fetchChildHeartbeats(ctx context.Context, mon *monitor.Model) map[string]*shared.HeartBeatModel {
+	cfg, err := executor.GenericUnmarshal[executor.GroupConfig](mon.Config)
+	if err != nil {
+		p.logger.Warnw("Failed to parse group monitor config", "monitor_id", mon.ID, "error", err)
+		return nil
+	}
+
+	childIDs := make([]string, 0, len(cfg.Children))
+	for _, child := range cfg.Children {
+		// A group can't depend on itself
+		if child.MonitorID != mon.ID {
+			childIDs = append(childIDs, child.MonitorID)
+		}
+	}
+	if len(childIDs) == 0 {
+		return nil
+	}
+
+	heartbeats, err := p.heartbeatService.FindLatestByMonitorIDs(ctx, childIDs)
+	if err != nil {
+		p.logger.Warnw("Failed to fetch latest heartbeats for group children",
+			"monitor_id", mon.ID,
+			"error", err)
+		return nil
+	}
+	return heartbeats
+}
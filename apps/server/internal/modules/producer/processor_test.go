@@ -4,15 +4,20 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
+	"peekaping/internal/modules/heartbeat"
 	"peekaping/internal/modules/maintenance"
 	"peekaping/internal/modules/monitor"
 	"peekaping/internal/modules/proxy"
 	"peekaping/internal/modules/queue"
+	"peekaping/internal/modules/shared"
 	"peekaping/internal/modules/worker"
 
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
 
@@ -29,7 +34,7 @@ func TestIsUnderMaintenance(t *testing.T) {
 		ctx := context.Background()
 		mockMaintenanceSvc.On("GetMaintenancesByMonitorID", ctx, "mon-1").Return([]*maintenance.Model{}, nil)
 
-		result, err := producer.isUnderMaintenance(ctx, "mon-1")
+		result, _, _, err := producer.isUnderMaintenance(ctx, "mon-1", time.Now())
 		assert.NoError(t, err)
 		assert.False(t, result)
 
@@ -51,9 +56,9 @@ func TestIsUnderMaintenance(t *testing.T) {
 		}
 
 		mockMaintenanceSvc.On("GetMaintenancesByMonitorID", ctx, "mon-1").Return(maintenances, nil)
-		mockMaintenanceSvc.On("IsUnderMaintenance", ctx, maintenances[0]).Return(true, nil)
+		mockMaintenanceSvc.On("IsUnderMaintenanceAt", ctx, maintenances[0], mock.AnythingOfType("time.Time")).Return(true, nil)
 
-		result, err := producer.isUnderMaintenance(ctx, "mon-1")
+		result, _, _, err := producer.isUnderMaintenance(ctx, "mon-1", time.Now())
 		assert.NoError(t, err)
 		assert.True(t, result)
 
@@ -76,16 +81,43 @@ func TestIsUnderMaintenance(t *testing.T) {
 		}
 
 		mockMaintenanceSvc.On("GetMaintenancesByMonitorID", ctx, "mon-1").Return(maintenances, nil)
-		mockMaintenanceSvc.On("IsUnderMaintenance", ctx, maintenances[0]).Return(false, nil)
-		mockMaintenanceSvc.On("IsUnderMaintenance", ctx, maintenances[1]).Return(true, nil)
+		mockMaintenanceSvc.On("IsUnderMaintenanceAt", ctx, maintenances[0], mock.AnythingOfType("time.Time")).Return(false, nil)
+		mockMaintenanceSvc.On("IsUnderMaintenanceAt", ctx, maintenances[1], mock.AnythingOfType("time.Time")).Return(true, nil)
 
-		result, err := producer.isUnderMaintenance(ctx, "mon-1")
+		result, _, _, err := producer.isUnderMaintenance(ctx, "mon-1", time.Now())
 		assert.NoError(t, err)
 		assert.True(t, result)
 
 		mockMaintenanceSvc.AssertExpectations(t)
 	})
 
+	t.Run("maintenance with suppress_checks", func(t *testing.T) {
+		logger := zap.NewNop().Sugar()
+		mockMaintenanceSvc := new(MockMaintenanceService)
+
+		producer := &Producer{
+			logger:             logger,
+			maintenanceService: mockMaintenanceSvc,
+		}
+
+		ctx := context.Background()
+		maintenances := []*maintenance.Model{
+			{ID: "maint-1"},
+			{ID: "maint-2", SuppressChecks: true},
+		}
+
+		mockMaintenanceSvc.On("GetMaintenancesByMonitorID", ctx, "mon-1").Return(maintenances, nil)
+		mockMaintenanceSvc.On("IsUnderMaintenanceAt", ctx, maintenances[0], mock.AnythingOfType("time.Time")).Return(true, nil)
+		mockMaintenanceSvc.On("IsUnderMaintenanceAt", ctx, maintenances[1], mock.AnythingOfType("time.Time")).Return(true, nil)
+
+		result, suppress, _, err := producer.isUnderMaintenance(ctx, "mon-1", time.Now())
+		assert.NoError(t, err)
+		assert.True(t, result)
+		assert.True(t, suppress)
+
+		mockMaintenanceSvc.AssertExpectations(t)
+	})
+
 	t.Run("error getting maintenances", func(t *testing.T) {
 		logger := zap.NewNop().Sugar()
 		mockMaintenanceSvc := new(MockMaintenanceService)
@@ -98,7 +130,7 @@ func TestIsUnderMaintenance(t *testing.T) {
 		ctx := context.Background()
 		mockMaintenanceSvc.On("GetMaintenancesByMonitorID", ctx, "mon-1").Return(nil, errors.New("database error"))
 
-		result, err := producer.isUnderMaintenance(ctx, "mon-1")
+		result, _, _, err := producer.isUnderMaintenance(ctx, "mon-1", time.Now())
 		assert.Error(t, err)
 		assert.False(t, result)
 
@@ -118,6 +150,7 @@ func TestProcessMonitor(t *testing.T) {
 			monitorService:     mockMonitorSvc,
 			maintenanceService: mockMaintenanceSvc,
 			queueService:       mockQueueSvc,
+			settingService:     newMockSettingServiceWithoutDefaultProxy(),
 		}
 
 		ctx := context.Background()
@@ -204,6 +237,7 @@ func TestProcessMonitor(t *testing.T) {
 			maintenanceService: mockMaintenanceSvc,
 			proxyService:       mockProxySvc,
 			queueService:       mockQueueSvc,
+			settingService:     newMockSettingServiceWithoutDefaultProxy(),
 		}
 
 		ctx := context.Background()
@@ -258,6 +292,7 @@ func TestProcessMonitor(t *testing.T) {
 			monitorService:     mockMonitorSvc,
 			maintenanceService: mockMaintenanceSvc,
 			queueService:       mockQueueSvc,
+			settingService:     newMockSettingServiceWithoutDefaultProxy(),
 		}
 
 		ctx := context.Background()
@@ -275,7 +310,7 @@ func TestProcessMonitor(t *testing.T) {
 
 		mockMonitorSvc.On("FindByID", ctx, "mon-1").Return(mon, nil)
 		mockMaintenanceSvc.On("GetMaintenancesByMonitorID", ctx, "mon-1").Return(maintenances, nil)
-		mockMaintenanceSvc.On("IsUnderMaintenance", ctx, maintenances[0]).Return(true, nil)
+		mockMaintenanceSvc.On("IsUnderMaintenanceAt", ctx, maintenances[0], mock.AnythingOfType("time.Time")).Return(true, nil)
 		mockQueueSvc.On("EnqueueUnique", ctx, worker.TaskTypeHealthCheck, mock.MatchedBy(func(payload worker.HealthCheckTaskPayload) bool {
 			return payload.IsUnderMaintenance == true
 		}), "healthcheck:mon-1", mock.AnythingOfType("time.Duration"), mock.AnythingOfType("*queue.EnqueueOptions")).Return(&queue.TaskInfo{ID: "task-123"}, nil)
@@ -289,6 +324,133 @@ func TestProcessMonitor(t *testing.T) {
 		mockQueueSvc.AssertExpectations(t)
 	})
 
+	t.Run("maintenance is evaluated at the scheduled tick", func(t *testing.T) {
+		logger := zap.NewNop().Sugar()
+		mockMonitorSvc := new(MockMonitorService)
+		mockMaintenanceSvc := new(MockMaintenanceService)
+		mockQueueSvc := new(MockQueueService)
+
+		producer := &Producer{
+			logger:             logger,
+			monitorService:     mockMonitorSvc,
+			maintenanceService: mockMaintenanceSvc,
+			queueService:       mockQueueSvc,
+			settingService:     newMockSettingServiceWithoutDefaultProxy(),
+		}
+
+		ctx := context.Background()
+		mon := &monitor.Model{
+			ID:       "mon-1",
+			Name:     "Test Monitor",
+			Type:     "http",
+			Active:   true,
+			Interval: 60,
+		}
+
+		maintenances := []*maintenance.Model{
+			{ID: "maint-1"},
+		}
+		nowMs := int64(1234567890)
+
+		mockMonitorSvc.On("FindByID", ctx, "mon-1").Return(mon, nil)
+		mockMaintenanceSvc.On("GetMaintenancesByMonitorID", ctx, "mon-1").Return(maintenances, nil)
+		mockMaintenanceSvc.On("IsUnderMaintenanceAt", ctx, maintenances[0], time.UnixMilli(nowMs).UTC()).Return(false, nil)
+		mockQueueSvc.On("EnqueueUnique", ctx, worker.TaskTypeHealthCheck, mock.MatchedBy(func(payload worker.HealthCheckTaskPayload) bool {
+			return !payload.IsUnderMaintenance && payload.ScheduledAt.Equal(time.UnixMilli(nowMs))
+		}), "healthcheck:mon-1", mock.AnythingOfType("time.Duration"), mock.AnythingOfType("*queue.EnqueueOptions")).Return(&queue.TaskInfo{ID: "task-123"}, nil)
+
+		interval, err := producer.processMonitor(ctx, "mon-1", nowMs)
+		assert.NoError(t, err)
+		assert.Equal(t, 60, interval)
+
+		mockMaintenanceSvc.AssertExpectations(t)
+		mockQueueSvc.AssertExpectations(t)
+	})
+
+	t.Run("suppressed checks are rescheduled without enqueuing", func(t *testing.T) {
+		logger := zap.NewNop().Sugar()
+		mockMonitorSvc := new(MockMonitorService)
+		mockMaintenanceSvc := new(MockMaintenanceService)
+		mockQueueSvc := new(MockQueueService)
+
+		producer := &Producer{
+			logger:             logger,
+			monitorService:     mockMonitorSvc,
+			maintenanceService: mockMaintenanceSvc,
+			queueService:       mockQueueSvc,
+			settingService:     newMockSettingServiceWithoutDefaultProxy(),
+		}
+
+		ctx := context.Background()
+		mon := &monitor.Model{
+			ID:       "mon-1",
+			Name:     "Monitor Under Maintenance",
+			Type:     "http",
+			Active:   true,
+			Interval: 60,
+		}
+
+		maintenances := []*maintenance.Model{
+			{ID: "maint-1", SuppressChecks: true},
+		}
+
+		mockMonitorSvc.On("FindByID", ctx, "mon-1").Return(mon, nil)
+		mockMaintenanceSvc.On("GetMaintenancesByMonitorID", ctx, "mon-1").Return(maintenances, nil)
+		mockMaintenanceSvc.On("IsUnderMaintenanceAt", ctx, maintenances[0], mock.AnythingOfType("time.Time")).Return(true, nil)
+
+		interval, err := producer.processMonitor(ctx, "mon-1", 1234567890)
+		assert.NoError(t, err)
+		assert.Equal(t, 60, interval)
+
+		mockMaintenanceSvc.AssertExpectations(t)
+		mockQueueSvc.AssertNotCalled(t, "EnqueueUnique", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("checks run during maintenance when the global toggle is on", func(t *testing.T) {
+		logger := zap.NewNop().Sugar()
+		mockMonitorSvc := new(MockMonitorService)
+		mockMaintenanceSvc := new(MockMaintenanceService)
+		mockQueueSvc := new(MockQueueService)
+		mockSettingSvc := new(MockSettingService)
+
+		producer := &Producer{
+			logger:             logger,
+			monitorService:     mockMonitorSvc,
+			maintenanceService: mockMaintenanceSvc,
+			queueService:       mockQueueSvc,
+			settingService:     mockSettingSvc,
+		}
+
+		ctx := context.Background()
+		mon := &monitor.Model{
+			ID:       "mon-1",
+			Name:     "Monitor Under Maintenance",
+			Type:     "http",
+			Active:   true,
+			Interval: 60,
+		}
+
+		maintenances := []*maintenance.Model{
+			{ID: "maint-1", SuppressChecks: true},
+		}
+
+		mockMonitorSvc.On("FindByID", ctx, "mon-1").Return(mon, nil)
+		mockMaintenanceSvc.On("GetMaintenancesByMonitorID", ctx, "mon-1").Return(maintenances, nil)
+		mockMaintenanceSvc.On("IsUnderMaintenanceAt", ctx, maintenances[0], mock.AnythingOfType("time.Time")).Return(true, nil)
+		mockSettingSvc.On("GetByKey", ctx, maintenance.CheckDuringMaintenanceSettingKey).Return(&shared.SettingModel{Value: "true"}, nil)
+		mockSettingSvc.On("GetByKey", ctx, proxy.DefaultProxySettingKey).Return(nil, nil)
+		mockQueueSvc.On("EnqueueUnique", ctx, worker.TaskTypeHealthCheck, mock.MatchedBy(func(payload worker.HealthCheckTaskPayload) bool {
+			return !payload.IsUnderMaintenance && payload.AlertsSuppressed
+		}), "healthcheck:mon-1", mock.AnythingOfType("time.Duration"), mock.AnythingOfType("*queue.EnqueueOptions")).Return(&queue.TaskInfo{ID: "task-123"}, nil)
+
+		interval, err := producer.processMonitor(ctx, "mon-1", 1234567890)
+		assert.NoError(t, err)
+		assert.Equal(t, 60, interval)
+
+		mockSettingSvc.AssertExpectations(t)
+		mockQueueSvc.AssertExpectations(t)
+	})
+
 	t.Run("handle duplicate task error", func(t *testing.T) {
 		logger := zap.NewNop().Sugar()
 		mockMonitorSvc := new(MockMonitorService)
@@ -300,6 +462,7 @@ func TestProcessMonitor(t *testing.T) {
 			monitorService:     mockMonitorSvc,
 			maintenanceService: mockMaintenanceSvc,
 			queueService:       mockQueueSvc,
+			settingService:     newMockSettingServiceWithoutDefaultProxy(),
 		}
 
 		ctx := context.Background()
@@ -355,6 +518,7 @@ func TestProcessMonitor(t *testing.T) {
 			monitorService:     mockMonitorSvc,
 			maintenanceService: mockMaintenanceSvc,
 			queueService:       mockQueueSvc,
+			settingService:     newMockSettingServiceWithoutDefaultProxy(),
 		}
 
 		ctx := context.Background()
@@ -393,6 +557,7 @@ func TestProcessMonitor(t *testing.T) {
 			monitorService:     mockMonitorSvc,
 			maintenanceService: mockMaintenanceSvc,
 			queueService:       mockQueueSvc,
+			settingService:     newMockSettingServiceWithoutDefaultProxy(),
 		}
 
 		ctx := context.Background()
@@ -431,6 +596,7 @@ func TestProcessMonitor(t *testing.T) {
 			monitorService:     mockMonitorSvc,
 			maintenanceService: mockMaintenanceSvc,
 			queueService:       mockQueueSvc,
+			settingService:     newMockSettingServiceWithoutDefaultProxy(),
 		}
 
 		ctx := context.Background()
@@ -457,3 +623,409 @@ func TestProcessMonitor(t *testing.T) {
 		mockQueueSvc.AssertExpectations(t)
 	})
 }
+
+func TestResolveProxy(t *testing.T) {
+	ctx := context.Background()
+	defaultProxy := &proxy.Model{
+		ID:       "proxy-default",
+		Protocol: "http",
+		Host:     "egress.internal",
+		Port:     3128,
+	}
+	defaultProxySetting := &shared.SettingModel{Key: proxy.DefaultProxySettingKey, Value: "proxy-default", Type: "string"}
+
+	t.Run("monitor without proxy uses the global default", func(t *testing.T) {
+		mockProxySvc := new(MockProxyService)
+		mockSettingSvc := new(MockSettingService)
+		producer := &Producer{
+			logger:         zap.NewNop().Sugar(),
+			proxyService:   mockProxySvc,
+			settingService: mockSettingSvc,
+		}
+
+		mockSettingSvc.On("GetByKey", ctx, proxy.DefaultProxySettingKey).Return(defaultProxySetting, nil)
+		mockProxySvc.On("FindByID", ctx, "proxy-default").Return(defaultProxy, nil)
+
+		proxyData := producer.resolveProxy(ctx, &monitor.Model{ID: "mon-1"})
+		assert.NotNil(t, proxyData)
+		assert.Equal(t, "proxy-default", proxyData.ID)
+		assert.Equal(t, "egress.internal", proxyData.Host)
+		assert.Equal(t, 3128, proxyData.Port)
+
+		mockSettingSvc.AssertExpectations(t)
+		mockProxySvc.AssertExpectations(t)
+	})
+
+	t.Run("global default is read once per refresh", func(t *testing.T) {
+		mockProxySvc := new(MockProxyService)
+		mockSettingSvc := new(MockSettingService)
+		producer := &Producer{
+			logger:         zap.NewNop().Sugar(),
+			proxyService:   mockProxySvc,
+			settingService: mockSettingSvc,
+		}
+
+		mockSettingSvc.On("GetByKey", ctx, proxy.DefaultProxySettingKey).Return(defaultProxySetting, nil)
+		mockProxySvc.On("FindByID", ctx, "proxy-default").Return(defaultProxy, nil)
+
+		for _, id := range []string{"mon-1", "mon-2", "mon-1"} {
+			proxyData := producer.resolveProxy(ctx, &monitor.Model{ID: id})
+			require.NotNil(t, proxyData)
+			assert.Equal(t, "proxy-default", proxyData.ID)
+		}
+		mockSettingSvc.AssertNumberOfCalls(t, "GetByKey", 1)
+
+		// Once the cached value expired the setting is read again
+		producer.defaultProxyCheckedAt = time.Now().Add(-defaultProxyRefresh)
+		producer.resolveProxy(ctx, &monitor.Model{ID: "mon-1"})
+		mockSettingSvc.AssertNumberOfCalls(t, "GetByKey", 2)
+	})
+
+	t.Run("monitor proxy takes precedence over the global default", func(t *testing.T) {
+		mockProxySvc := new(MockProxyService)
+		mockSettingSvc := new(MockSettingService)
+		producer := &Producer{
+			logger:         zap.NewNop().Sugar(),
+			proxyService:   mockProxySvc,
+			settingService: mockSettingSvc,
+		}
+
+		mockProxySvc.On("FindByID", ctx, "proxy-1").Return(&proxy.Model{ID: "proxy-1", Protocol: "socks5", Host: "socks.internal", Port: 1080}, nil)
+
+		proxyData := producer.resolveProxy(ctx, &monitor.Model{ID: "mon-1", ProxyId: "proxy-1"})
+		assert.NotNil(t, proxyData)
+		assert.Equal(t, "proxy-1", proxyData.ID)
+
+		mockSettingSvc.AssertNotCalled(t, "GetByKey", mock.Anything, mock.Anything)
+		mockProxySvc.AssertExpectations(t)
+	})
+
+	t.Run("monitor with no_proxy skips the global default", func(t *testing.T) {
+		mockProxySvc := new(MockProxyService)
+		mockSettingSvc := new(MockSettingService)
+		producer := &Producer{
+			logger:         zap.NewNop().Sugar(),
+			proxyService:   mockProxySvc,
+			settingService: mockSettingSvc,
+		}
+
+		proxyData := producer.resolveProxy(ctx, &monitor.Model{ID: "mon-1", NoProxy: true})
+		assert.Nil(t, proxyData)
+
+		mockSettingSvc.AssertNotCalled(t, "GetByKey", mock.Anything, mock.Anything)
+		mockProxySvc.AssertNotCalled(t, "FindByID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("no global default configured", func(t *testing.T) {
+		mockProxySvc := new(MockProxyService)
+		producer := &Producer{
+			logger:         zap.NewNop().Sugar(),
+			proxyService:   mockProxySvc,
+			settingService: newMockSettingServiceWithoutDefaultProxy(),
+		}
+
+		proxyData := producer.resolveProxy(ctx, &monitor.Model{ID: "mon-1"})
+		assert.Nil(t, proxyData)
+
+		mockProxySvc.AssertNotCalled(t, "FindByID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("deleted default proxy is ignored", func(t *testing.T) {
+		mockProxySvc := new(MockProxyService)
+		mockSettingSvc := new(MockSettingService)
+		producer := &Producer{
+			logger:         zap.NewNop().Sugar(),
+			proxyService:   mockProxySvc,
+			settingService: mockSettingSvc,
+		}
+
+		mockSettingSvc.On("GetByKey", ctx, proxy.DefaultProxySettingKey).Return(defaultProxySetting, nil)
+		mockProxySvc.On("FindByID", ctx, "proxy-default").Return(nil, nil)
+
+		proxyData := producer.resolveProxy(ctx, &monitor.Model{ID: "mon-1"})
+		assert.Nil(t, proxyData)
+	})
+
+	t.Run("fallback proxies keep their order and skip deleted ones", func(t *testing.T) {
+		mockProxySvc := new(MockProxyService)
+		producer := &Producer{
+			logger:       zap.NewNop().Sugar(),
+			proxyService: mockProxySvc,
+		}
+
+		mockProxySvc.On("FindByID", ctx, "proxy-2").Return(&proxy.Model{ID: "proxy-2", Protocol: "http", Host: "second.internal", Port: 3128}, nil)
+		mockProxySvc.On("FindByID", ctx, "proxy-deleted").Return(nil, nil)
+		mockProxySvc.On("FindByID", ctx, "proxy-3").Return(&proxy.Model{ID: "proxy-3", Protocol: "http", Host: "third.internal", Port: 3128}, nil)
+
+		fallbacks := producer.resolveFallbackProxies(ctx, &monitor.Model{ID: "mon-1", ProxyId: "proxy-1", FallbackProxyIds: []string{"proxy-2", "proxy-deleted", "proxy-3"}})
+		require.Len(t, fallbacks, 2)
+		assert.Equal(t, "proxy-2", fallbacks[0].ID)
+		assert.Equal(t, "proxy-3", fallbacks[1].ID)
+		mockProxySvc.AssertExpectations(t)
+	})
+
+	t.Run("payload carries the global proxy and no_proxy flag", func(t *testing.T) {
+		mockMonitorSvc := new(MockMonitorService)
+		mockMaintenanceSvc := new(MockMaintenanceService)
+		mockProxySvc := new(MockProxyService)
+		mockSettingSvc := new(MockSettingService)
+		mockQueueSvc := new(MockQueueService)
+
+		producer := &Producer{
+			logger:             zap.NewNop().Sugar(),
+			monitorService:     mockMonitorSvc,
+			maintenanceService: mockMaintenanceSvc,
+			proxyService:       mockProxySvc,
+			settingService:     mockSettingSvc,
+			queueService:       mockQueueSvc,
+		}
+
+		mon := &monitor.Model{
+			ID:       "mon-1",
+			Name:     "No explicit proxy",
+			Type:     "http",
+			Active:   true,
+			Interval: 60,
+			Timeout:  30,
+		}
+
+		mockMonitorSvc.On("FindByID", ctx, "mon-1").Return(mon, nil)
+		mockMaintenanceSvc.On("GetMaintenancesByMonitorID", ctx, "mon-1").Return([]*maintenance.Model{}, nil)
+		mockSettingSvc.On("GetByKey", ctx, proxy.DefaultProxySettingKey).Return(defaultProxySetting, nil)
+		mockProxySvc.On("FindByID", ctx, "proxy-default").Return(defaultProxy, nil)
+		mockQueueSvc.On("EnqueueUnique", ctx, worker.TaskTypeHealthCheck, mock.MatchedBy(func(payload worker.HealthCheckTaskPayload) bool {
+			return payload.Proxy != nil && payload.Proxy.ID == "proxy-default" && !payload.NoProxy
+		}), "healthcheck:mon-1", mock.AnythingOfType("time.Duration"), mock.AnythingOfType("*queue.EnqueueOptions")).Return(&queue.TaskInfo{ID: "task-123"}, nil)
+
+		interval, err := producer.processMonitor(ctx, "mon-1", 1234567890)
+		assert.NoError(t, err)
+		assert.Equal(t, 60, interval)
+
+		mockQueueSvc.AssertExpectations(t)
+	})
+}
+
+func TestProcessMonitor_GroupChildHeartbeats(t *testing.T) {
+	mockMonitorSvc := new(MockMonitorService)
+	mockMaintenanceSvc := new(MockMaintenanceService)
+	mockHeartbeatSvc := new(MockHeartbeatService)
+	mockQueueSvc := new(MockQueueService)
+
+	producer := &Producer{
+		logger:             zap.NewNop().Sugar(),
+		monitorService:     mockMonitorSvc,
+		maintenanceService: mockMaintenanceSvc,
+		heartbeatService:   mockHeartbeatSvc,
+		queueService:       mockQueueSvc,
+		settingService:     newMockSettingServiceWithoutDefaultProxy(),
+	}
+
+	ctx := context.Background()
+	mon := &monitor.Model{
+		ID:       "group-1",
+		Name:     "Database cluster",
+		Type:     "group",
+		Active:   true,
+		Interval: 60,
+		Config:   `{"children":[{"monitor_id":"db-1","weight":2},{"monitor_id":"db-2"},{"monitor_id":"db-3"},{"monitor_id":"group-1"}],"rule":"quorum","quorum_percent":50}`,
+	}
+
+	mockMonitorSvc.On("FindByID", ctx, "group-1").Return(mon, nil)
+	mockMaintenanceSvc.On("GetMaintenancesByMonitorID", ctx, "group-1").Return([]*maintenance.Model{}, nil)
+	// The latest heartbeats of all children are read at once, the group itself is never
+	// one of its children
+	mockHeartbeatSvc.On("FindLatestByMonitorIDs", ctx, []string{"db-1", "db-2", "db-3"}).
+		Return(map[string]*heartbeat.Model{"db-1": {MonitorID: "db-1", Status: shared.MonitorStatusUp}}, nil).Once()
+	mockQueueSvc.On("EnqueueUnique", ctx, worker.TaskTypeHealthCheck, mock.MatchedBy(func(payload worker.HealthCheckTaskPayload) bool {
+		return len(payload.ChildHeartbeats) == 1 &&
+			payload.ChildHeartbeats["db-1"].Status == shared.MonitorStatusUp
+	}), "healthcheck:group-1", mock.AnythingOfType("time.Duration"), mock.AnythingOfType("*queue.EnqueueOptions")).Return(&queue.TaskInfo{ID: "task-1"}, nil)
+
+	interval, err := producer.processMonitor(ctx, "group-1", time.Now().UnixMilli())
+	assert.NoError(t, err)
+	assert.Equal(t, 60, interval)
+
+	mockHeartbeatSvc.AssertExpectations(t)
+	mockQueueSvc.AssertExpectations(t)
+	mockHeartbeatSvc.AssertNotCalled(t, "FindByMonitorIDPaginated", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestProcessClaimed_EnqueueFailuresKeepMonitorScheduled(t *testing.T) {
+	client, mr := setupTestRedis(t)
+	defer mr.Close()
+
+	mockMonitorSvc := new(MockMonitorService)
+	mockMaintenanceSvc := new(MockMaintenanceService)
+	mockQueueSvc := new(MockQueueService)
+	producer := &Producer{
+		rdb:                client,
+		logger:             zap.NewNop().Sugar(),
+		monitorService:     mockMonitorSvc,
+		maintenanceService: mockMaintenanceSvc,
+		queueService:       mockQueueSvc,
+		settingService:     newMockSettingServiceWithoutDefaultProxy(),
+		monitorIntervals:   map[string]int{"mon-1": 60, "mon-2": 30},
+	}
+
+	ctx := context.Background()
+	mon := &monitor.Model{ID: "mon-1", Name: "Flaky Queue", Type: "http", Active: true, Interval: 60, Timeout: 16}
+	mockMonitorSvc.On("FindByID", ctx, "mon-1").Return(mon, nil)
+	mockMonitorSvc.On("FindByID", ctx, "mon-2").Return(nil, errors.New("database unavailable"))
+	mockMaintenanceSvc.On("GetMaintenancesByMonitorID", ctx, "mon-1").Return([]*maintenance.Model{}, nil)
+	mockQueueSvc.On("EnqueueUnique", ctx, worker.TaskTypeHealthCheck, mock.Anything, "healthcheck:mon-1", mock.Anything, mock.Anything).
+		Return(nil, errors.New("redis: connection refused"))
+
+	nowMs := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC).UnixMilli()
+	client.ZAdd(ctx, SchedDueKey, redis.Z{Score: float64(nowMs), Member: "mon-1"})
+	client.ZAdd(ctx, SchedDueKey, redis.Z{Score: float64(nowMs), Member: "mon-2"})
+
+	for attempt := 0; attempt < 5; attempt++ {
+		claimed, err := producer.claimDueMonitors(ctx, nowMs, 10, int64(LeaseTTL/time.Millisecond))
+		require.NoError(t, err)
+		ids := make([]string, 0, len(claimed))
+		for _, c := range claimed {
+			ids = append(ids, c.ID)
+		}
+		require.ElementsMatch(t, []string{"mon-1", "mon-2"}, ids, "attempt %d", attempt)
+
+		producer.processClaimed(ctx, 0, claimed, nowMs)
+
+		// Both failed and both are due again at their next interval, none is left leased
+		leased, err := client.ZCard(ctx, SchedLeaseKey).Result()
+		require.NoError(t, err)
+		assert.Zero(t, leased)
+
+		now := time.UnixMilli(nowMs).UTC()
+		score, err := client.ZScore(ctx, SchedDueKey, "mon-1").Result()
+		require.NoError(t, err)
+		assert.Equal(t, float64(nextAligned(now, time.Minute).UnixMilli()), score)
+		score, err = client.ZScore(ctx, SchedDueKey, "mon-2").Result()
+		require.NoError(t, err)
+		assert.Equal(t, float64(nextAligned(now, 30*time.Second).UnixMilli()), score)
+
+		nowMs = nextAligned(now, time.Minute).UnixMilli()
+	}
+}
+
+func TestProcessMonitor_ReplacesArchivedTask(t *testing.T) {
+	mockMonitorSvc := new(MockMonitorService)
+	mockMaintenanceSvc := new(MockMaintenanceService)
+	mockQueueSvc := new(MockQueueService)
+	producer := &Producer{
+		logger:             zap.NewNop().Sugar(),
+		monitorService:     mockMonitorSvc,
+		maintenanceService: mockMaintenanceSvc,
+		queueService:       mockQueueSvc,
+		settingService:     newMockSettingServiceWithoutDefaultProxy(),
+	}
+
+	ctx := context.Background()
+	mon := &monitor.Model{ID: "mon-1", Name: "Test Monitor", Type: "http", Active: true, Interval: 60, Timeout: 16, MaxRetries: 3, RetryInterval: 60}
+	mockMonitorSvc.On("FindByID", ctx, "mon-1").Return(mon, nil)
+	mockMaintenanceSvc.On("GetMaintenancesByMonitorID", ctx, "mon-1").Return([]*maintenance.Model{}, nil)
+	mockQueueSvc.On("EnqueueUnique", ctx, worker.TaskTypeHealthCheck, mock.Anything, "healthcheck:mon-1", mock.Anything, mock.Anything).
+		Return(nil, errors.New("task ID conflicts with another task")).Once()
+	mockQueueSvc.On("GetTaskInfo", ctx, "healthcheck", "healthcheck:mon-1").
+		Return(&queue.TaskInfo{ID: "healthcheck:mon-1", State: "archived", LastFailedAt: time.Now().UTC()}, nil)
+	mockQueueSvc.On("DeleteTask", ctx, "healthcheck", "healthcheck:mon-1").Return(nil)
+	mockQueueSvc.On("EnqueueUnique", ctx, worker.TaskTypeHealthCheck, mock.Anything, "healthcheck:mon-1", mock.Anything, mock.Anything).
+		Return(&queue.TaskInfo{ID: "healthcheck:mon-1"}, nil).Once()
+
+	interval, err := producer.processMonitor(ctx, "mon-1", time.Now().UnixMilli())
+	require.NoError(t, err)
+	assert.Equal(t, 60, interval)
+	mockQueueSvc.AssertExpectations(t)
+	mockQueueSvc.AssertNumberOfCalls(t, "EnqueueUnique", 2)
+}
+
+func TestProcessMonitor_MaintenanceAutoEndOnRecovery(t *testing.T) {
+	setup := func(ended bool) (*Producer, *MockMaintenanceService, *MockQueueService) {
+		mockMonitorSvc := new(MockMonitorService)
+		mockMaintenanceSvc := new(MockMaintenanceService)
+		mockHeartbeatSvc := new(MockHeartbeatService)
+		mockQueueSvc := new(MockQueueService)
+
+		producer := &Producer{
+			logger:             zap.NewNop().Sugar(),
+			monitorService:     mockMonitorSvc,
+			maintenanceService: mockMaintenanceSvc,
+			heartbeatService:   mockHeartbeatSvc,
+			queueService:       mockQueueSvc,
+			settingService:     newMockSettingServiceWithoutDefaultProxy(),
+		}
+
+		mon := &monitor.Model{ID: "mon-1", Name: "API", Type: "http", Active: true, Interval: 60}
+		window := &maintenance.Model{ID: "maint-1", Strategy: "manual", Active: true, AutoEndOnRecovery: true, RecoveryChecks: 2}
+		latest := []*heartbeat.Model{{MonitorID: "mon-1", Status: shared.MonitorStatusUp}}
+
+		mockMonitorSvc.On("FindByID", mock.Anything, "mon-1").Return(mon, nil)
+		mockMaintenanceSvc.On("GetMaintenancesByMonitorID", mock.Anything, "mon-1").Return([]*maintenance.Model{window}, nil)
+		mockMaintenanceSvc.On("IsUnderMaintenanceAt", mock.Anything, window, mock.AnythingOfType("time.Time")).Return(true, nil)
+		mockMaintenanceSvc.On("GetMonitors", mock.Anything, "maint-1").Return([]string{"mon-1"}, nil)
+		mockHeartbeatSvc.On("FindByMonitorIDPaginated", mock.Anything, "mon-1", 2, 0, (*bool)(nil), false).Return(latest, nil)
+		mockMaintenanceSvc.On("EndOnRecovery", mock.Anything, window, map[string][]*shared.HeartBeatModel{"mon-1": latest}).Return(ended, nil)
+
+		return producer, mockMaintenanceSvc, mockQueueSvc
+	}
+
+	// Either way the check runs as usual, so the recovery can be observed. Its alerts are
+	// suppressed while the window lasts.
+	expectCheck := func(mockQueueSvc *MockQueueService, alertsSuppressed bool) {
+		mockQueueSvc.On("EnqueueUnique", mock.Anything, worker.TaskTypeHealthCheck, mock.MatchedBy(func(payload worker.HealthCheckTaskPayload) bool {
+			return !payload.IsUnderMaintenance && payload.AlertsSuppressed == alertsSuppressed
+		}), "healthcheck:mon-1", mock.Anything, mock.Anything).Return(&queue.TaskInfo{ID: "task-1"}, nil)
+	}
+
+	t.Run("outage keeps the window", func(t *testing.T) {
+		producer, mockMaintenanceSvc, mockQueueSvc := setup(false)
+		expectCheck(mockQueueSvc, true)
+
+		underMaintenance, _, runChecks, err := producer.isUnderMaintenance(context.Background(), "mon-1", time.Now())
+		assert.NoError(t, err)
+		assert.True(t, underMaintenance)
+		assert.True(t, runChecks)
+
+		_, err = producer.processMonitor(context.Background(), "mon-1", time.Now().UnixMilli())
+		assert.NoError(t, err)
+		mockMaintenanceSvc.AssertExpectations(t)
+		mockQueueSvc.AssertExpectations(t)
+	})
+
+	t.Run("recovery ends the window", func(t *testing.T) {
+		producer, mockMaintenanceSvc, mockQueueSvc := setup(true)
+		expectCheck(mockQueueSvc, false)
+
+		underMaintenance, _, runChecks, err := producer.isUnderMaintenance(context.Background(), "mon-1", time.Now())
+		assert.NoError(t, err)
+		assert.False(t, underMaintenance)
+		assert.False(t, runChecks)
+
+		_, err = producer.processMonitor(context.Background(), "mon-1", time.Now().UnixMilli())
+		assert.NoError(t, err)
+		mockMaintenanceSvc.AssertExpectations(t)
+		mockQueueSvc.AssertExpectations(t)
+	})
+}
+
+func TestCheckDuringMaintenance(t *testing.T) {
+	t.Run("reads and caches the setting", func(t *testing.T) {
+		mockSettingSvc := new(MockSettingService)
+		mockSettingSvc.On("GetByKey", mock.Anything, maintenance.CheckDuringMaintenanceSettingKey).
+			Return(&shared.SettingModel{Value: "true"}, nil).Once()
+
+		producer := &Producer{logger: zap.NewNop().Sugar(), settingService: mockSettingSvc}
+
+		assert.True(t, producer.checkDuringMaintenance(context.Background()))
+		assert.True(t, producer.checkDuringMaintenance(context.Background()), "cached value is used")
+		mockSettingSvc.AssertExpectations(t)
+	})
+
+	t.Run("keeps last value when the setting can't be read", func(t *testing.T) {
+		mockSettingSvc := new(MockSettingService)
+		mockSettingSvc.On("GetByKey", mock.Anything, maintenance.CheckDuringMaintenanceSettingKey).Return(nil, assert.AnError)
+
+		producer := &Producer{logger: zap.NewNop().Sugar(), settingService: mockSettingSvc, maintenanceChecks: true}
+
+		assert.True(t, producer.checkDuringMaintenance(context.Background()))
+	})
+}
@@ -23,6 +23,8 @@ func RegisterDependencies(container *dig.Container) {
 		return NewLeaderElection(client, nodeID, logger)
 	})
 
+	container.Provide(ProvideSchedulerMetrics)
+
 	// Provide producer
 	container.Provide(NewProducer)
 
@@ -29,6 +29,7 @@ func NewProducer(
 	settingService shared.SettingService,
 	heartbeatService heartbeat.Service,
 	leaderElection *LeaderElection,
+	metrics *SchedulerMetrics,
 	cfg *config.Config,
 	logger *zap.SugaredLogger,
 ) *Producer {
@@ -56,6 +57,9 @@ func NewProducer(
 		scheduleRefreshInterval: 30 * time.Second, // Refresh schedule every 30 seconds
 		leaderElection:          leaderElection,
 		concurrency:             concurrency,
+		startupRamp:             cfg.ProducerStartupRamp,
+		healthCheckShards:       cfg.HealthCheckQueueShards,
+		metrics:                 metrics,
 	}
 }
 
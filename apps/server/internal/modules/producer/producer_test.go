@@ -38,6 +38,7 @@ func TestNewProducer(t *testing.T) {
 			nil,
 			nil,
 			le,
+			nil,
 			cfg,
 			logger,
 		)
@@ -75,6 +76,7 @@ func TestNewProducer(t *testing.T) {
 			nil,
 			nil,
 			le,
+			nil,
 			cfg,
 			logger,
 		)
@@ -111,6 +113,7 @@ func TestProducerStartStop(t *testing.T) {
 			nil,
 			nil,
 			le,
+			nil,
 			cfg,
 			logger,
 		)
@@ -154,6 +157,7 @@ func TestProducerContext(t *testing.T) {
 			nil,
 			nil,
 			le,
+			nil,
 			cfg,
 			logger,
 		)
@@ -206,6 +210,7 @@ func TestProducerMonitorIntervals(t *testing.T) {
 			nil,
 			nil,
 			le,
+			nil,
 			cfg,
 			logger,
 		)
@@ -240,6 +245,7 @@ func TestProducerMonitorIntervals(t *testing.T) {
 			nil,
 			nil,
 			le,
+			nil,
 			cfg,
 			logger,
 		)
@@ -0,0 +1,94 @@
+package producer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"peekaping/internal/modules/healthcheck/executor"
+	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/queue"
+	"peekaping/internal/modules/shared"
+	"peekaping/internal/modules/worker"
+)
+
+// pushHeartbeatGap reports whether a push monitor has gone silent at the given time,
+// i.e. nothing was pushed for longer than the interval plus the configured grace
+// period. A monitor that never received a push is measured from its activation.
+// Beats written by the watchdog are not pushes, so the monitor stays down until the
+// agent reports again.
+func pushHeartbeatGap(mon *monitor.Model, lastHeartbeat *shared.HeartBeatModel, at time.Time) (bool, string) {
+	maxGap := time.Duration(mon.Interval) * time.Second
+	if cfg, err := executor.GenericUnmarshal[executor.PushConfig](mon.Config); err == nil {
+		maxGap = cfg.MaxGap(mon.Interval)
+	}
+
+	if lastHeartbeat == nil {
+		if mon.ActivatedAt != nil && at.Sub(*mon.ActivatedAt) <= maxGap {
+			return false, ""
+		}
+		return true, "No heartbeat received yet"
+	}
+
+	if lastHeartbeat.ErrorCategory != shared.ErrorCategoryNoHeartbeat && at.Sub(lastHeartbeat.Time) <= maxGap {
+		return false, ""
+	}
+	return true, fmt.Sprintf("No heartbeat received within %s", maxGap)
+}
+
+// watchPushMonitor is the passive counterpart of a health check. Push monitors have
+// nothing to probe, so instead of enqueueing a check the producer compares the age of
+// the last push with the maximum acceptable gap and, once it is exceeded, hands a down
+// result straight to the ingester, which records it and fires the notifications.
+// alertsSuppressed is set in maintenance windows that keep the checks running, the
+// ingester notifies the outage once the window ended if the monitor is still down.
+func (p *Producer) watchPushMonitor(ctx context.Context, mon *monitor.Model, lastHeartbeat *shared.HeartBeatModel, at time.Time, alertsSuppressed bool) error {
+	silent, message := pushHeartbeatGap(mon, lastHeartbeat, at)
+	if !silent {
+		p.logger.Debugw("Push monitor heartbeat received in time", "monitor_id", mon.ID)
+		return nil
+	}
+
+	now := time.Now().UTC()
+	payload := worker.IngesterTaskPayload{
+		MonitorID:          mon.ID,
+		MonitorName:        mon.Name,
+		MonitorType:        mon.Type,
+		MonitorInterval:    mon.Interval,
+		MonitorTimeout:     mon.Timeout,
+		MonitorMaxRetries:  mon.MaxRetries,
+		MonitorRetryInt:    mon.RetryInterval,
+		MonitorResendInt:   mon.ResendInterval,
+		MonitorWarmup:      mon.WarmupChecks,
+		MonitorActivatedAt: mon.ActivatedAt,
+		MonitorConfig:      mon.Config,
+		Status:             shared.MonitorStatusDown,
+		Message:            message,
+		StartTime:          now,
+		EndTime:            now,
+		ErrorCategory:      shared.ErrorCategoryNoHeartbeat,
+		AlertsSuppressed:   alertsSuppressed,
+	}
+
+	opts := &queue.EnqueueOptions{
+		Queue:     "ingester",
+		MaxRetry:  3,
+		Timeout:   2 * time.Minute,
+		Retention: 1 * time.Hour,
+	}
+
+	// One result per scheduled tick, even if the tick is processed twice
+	uniqueKey := fmt.Sprintf("ingest:push-watchdog:%s:%d", mon.ID, at.UnixMilli())
+	ttl := time.Duration(mon.Interval) * time.Second
+
+	if _, err := p.queueService.EnqueueUnique(ctx, worker.TaskTypeIngester, payload, uniqueKey, ttl, opts); err != nil {
+		return fmt.Errorf("failed to enqueue missing heartbeat result: %w", err)
+	}
+
+	p.logger.Infow("Push monitor missed its heartbeat",
+		"monitor_id", mon.ID,
+		"monitor_name", mon.Name,
+		"message", message)
+
+	return nil
+}
@@ -0,0 +1,205 @@
+package producer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"peekaping/internal/modules/heartbeat"
+	"peekaping/internal/modules/maintenance"
+	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/queue"
+	"peekaping/internal/modules/shared"
+	"peekaping/internal/modules/worker"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+func TestPushHeartbeatGap(t *testing.T) {
+	now := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	activatedAt := now.Add(-time.Hour)
+	mon := &monitor.Model{
+		ID:          "push-1",
+		Type:        "push",
+		Interval:    60,
+		ActivatedAt: &activatedAt,
+		Config:      `{"pushToken":"token","grace_period":30}`,
+	}
+
+	tests := []struct {
+		name          string
+		mon           *monitor.Model
+		lastHeartbeat *shared.HeartBeatModel
+		wantSilent    bool
+	}{
+		{
+			name:          "push within the interval",
+			mon:           mon,
+			lastHeartbeat: &shared.HeartBeatModel{Status: shared.MonitorStatusUp, Time: now.Add(-50 * time.Second)},
+			wantSilent:    false,
+		},
+		{
+			name:          "late push within the grace period",
+			mon:           mon,
+			lastHeartbeat: &shared.HeartBeatModel{Status: shared.MonitorStatusUp, Time: now.Add(-90 * time.Second)},
+			wantSilent:    false,
+		},
+		{
+			name:          "gap exceeds interval plus grace",
+			mon:           mon,
+			lastHeartbeat: &shared.HeartBeatModel{Status: shared.MonitorStatusUp, Time: now.Add(-91 * time.Second)},
+			wantSilent:    true,
+		},
+		{
+			name:          "recent pushed down status is not a gap",
+			mon:           mon,
+			lastHeartbeat: &shared.HeartBeatModel{Status: shared.MonitorStatusDown, Time: now.Add(-10 * time.Second)},
+			wantSilent:    false,
+		},
+		{
+			name: "previous watchdog beat keeps the monitor down",
+			mon:  mon,
+			lastHeartbeat: &shared.HeartBeatModel{
+				Status:        shared.MonitorStatusDown,
+				Time:          now.Add(-10 * time.Second),
+				ErrorCategory: shared.ErrorCategoryNoHeartbeat,
+			},
+			wantSilent: true,
+		},
+		{
+			name:       "never pushed since activation",
+			mon:        mon,
+			wantSilent: true,
+		},
+		{
+			name: "recently activated monitor is given time for its first push",
+			mon: func() *monitor.Model {
+				recent := now.Add(-30 * time.Second)
+				m := *mon
+				m.ActivatedAt = &recent
+				return &m
+			}(),
+			wantSilent: false,
+		},
+		{
+			name: "no grace period configured",
+			mon: &monitor.Model{
+				ID:       "push-2",
+				Type:     "push",
+				Interval: 60,
+				Config:   `{"pushToken":"token"}`,
+			},
+			lastHeartbeat: &shared.HeartBeatModel{Status: shared.MonitorStatusUp, Time: now.Add(-61 * time.Second)},
+			wantSilent:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			silent, message := pushHeartbeatGap(tt.mon, tt.lastHeartbeat, now)
+			assert.Equal(t, tt.wantSilent, silent)
+			if tt.wantSilent {
+				assert.NotEmpty(t, message)
+			}
+		})
+	}
+}
+
+func TestProcessMonitor_PushWatchdog(t *testing.T) {
+	setup := func(lastBeatAge time.Duration, inMaintenance bool) (*Producer, *MockQueueService) {
+		mockMonitorSvc := new(MockMonitorService)
+		mockMaintenanceSvc := new(MockMaintenanceService)
+		mockHeartbeatSvc := new(MockHeartbeatService)
+		mockQueueSvc := new(MockQueueService)
+
+		producer := &Producer{
+			logger:             zap.NewNop().Sugar(),
+			monitorService:     mockMonitorSvc,
+			maintenanceService: mockMaintenanceSvc,
+			heartbeatService:   mockHeartbeatSvc,
+			queueService:       mockQueueSvc,
+			settingService:     newMockSettingServiceWithoutDefaultProxy(),
+		}
+
+		mon := &monitor.Model{
+			ID:             "push-1",
+			Name:           "Backup job",
+			Type:           "push",
+			Active:         true,
+			Interval:       60,
+			Timeout:        16,
+			ResendInterval: 10,
+			Config:         `{"pushToken":"token","grace_period":30}`,
+		}
+		mockMonitorSvc.On("FindByID", mock.Anything, "push-1").Return(mon, nil)
+		if inMaintenance {
+			// A window ending on recovery keeps the checks running with their alerts suppressed
+			window := &maintenance.Model{ID: "maint-1", AutoEndOnRecovery: true}
+			mockMaintenanceSvc.On("GetMaintenancesByMonitorID", mock.Anything, "push-1").Return([]*maintenance.Model{window}, nil)
+			mockMaintenanceSvc.On("IsUnderMaintenanceAt", mock.Anything, window, mock.AnythingOfType("time.Time")).Return(true, nil)
+			mockMaintenanceSvc.On("GetMonitors", mock.Anything, "maint-1").Return([]string{"push-1"}, nil)
+			mockHeartbeatSvc.On("FindByMonitorIDPaginated", mock.Anything, "push-1", maintenance.DefaultRecoveryChecks, 0, (*bool)(nil), false).Return([]*heartbeat.Model{}, nil)
+			mockMaintenanceSvc.On("EndOnRecovery", mock.Anything, window, mock.Anything).Return(false, nil)
+		} else {
+			mockMaintenanceSvc.On("GetMaintenancesByMonitorID", mock.Anything, "push-1").Return([]*maintenance.Model{}, nil)
+		}
+		mockHeartbeatSvc.On("FindByMonitorIDPaginated", mock.Anything, "push-1", 1, 0, (*bool)(nil), false).Return([]*heartbeat.Model{
+			{MonitorID: "push-1", Status: shared.MonitorStatusUp, Time: time.Now().UTC().Add(-lastBeatAge)},
+		}, nil)
+
+		return producer, mockQueueSvc
+	}
+
+	t.Run("heartbeat in time enqueues nothing", func(t *testing.T) {
+		producer, mockQueueSvc := setup(20*time.Second, false)
+
+		interval, err := producer.processMonitor(context.Background(), "push-1", time.Now().UnixMilli())
+		assert.NoError(t, err)
+		assert.Equal(t, 60, interval)
+		mockQueueSvc.AssertNotCalled(t, "EnqueueUnique", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("missing heartbeat marks the monitor down", func(t *testing.T) {
+		producer, mockQueueSvc := setup(5*time.Minute, false)
+		mockQueueSvc.On("EnqueueUnique", mock.Anything, worker.TaskTypeIngester, mock.MatchedBy(func(payload worker.IngesterTaskPayload) bool {
+			return payload.MonitorID == "push-1" &&
+				payload.Status == shared.MonitorStatusDown &&
+				payload.ErrorCategory == shared.ErrorCategoryNoHeartbeat &&
+				payload.MonitorResendInt == 10 &&
+				!payload.AlertsSuppressed
+		}), mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration"), mock.MatchedBy(func(opts *queue.EnqueueOptions) bool {
+			return opts.Queue == "ingester"
+		})).Return(&queue.TaskInfo{ID: "task-1"}, nil)
+
+		interval, err := producer.processMonitor(context.Background(), "push-1", time.Now().UnixMilli())
+		assert.NoError(t, err)
+		assert.Equal(t, 60, interval)
+		mockQueueSvc.AssertExpectations(t)
+		mockQueueSvc.AssertNotCalled(t, "EnqueueUnique", mock.Anything, worker.TaskTypeHealthCheck, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("missing heartbeat in a maintenance window suppresses alerts", func(t *testing.T) {
+		producer, mockQueueSvc := setup(5*time.Minute, true)
+		mockQueueSvc.On("EnqueueUnique", mock.Anything, worker.TaskTypeIngester, mock.MatchedBy(func(payload worker.IngesterTaskPayload) bool {
+			return payload.Status == shared.MonitorStatusDown && payload.AlertsSuppressed
+		}), mock.Anything, mock.Anything, mock.Anything).Return(&queue.TaskInfo{ID: "task-1"}, nil)
+
+		_, err := producer.processMonitor(context.Background(), "push-1", time.Now().UnixMilli())
+		assert.NoError(t, err)
+		mockQueueSvc.AssertExpectations(t)
+	})
+
+	t.Run("enqueue failure is returned so the monitor is retried", func(t *testing.T) {
+		producer, mockQueueSvc := setup(5*time.Minute, false)
+		mockQueueSvc.On("EnqueueUnique", mock.Anything, worker.TaskTypeIngester, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(nil, errors.New("redis unavailable"))
+
+		interval, err := producer.processMonitor(context.Background(), "push-1", time.Now().UnixMilli())
+		assert.Error(t, err)
+		// Still rescheduled at the next interval
+		assert.Equal(t, 60, interval)
+	})
+}
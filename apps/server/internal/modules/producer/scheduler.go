@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"time"
 
+	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/shared"
+
 	"github.com/redis/go-redis/v9"
 )
 
@@ -43,6 +46,7 @@ func (p *Producer) initializeSchedule() error {
 	newlyScheduledCount := 0
 	removedCount := 0
 	activeMonitorIDs := make(map[string]bool)
+	var newMonitors []*monitor.Model
 	nowMs := p.redisNowMs()
 	now := time.UnixMilli(nowMs).UTC()
 
@@ -67,7 +71,6 @@ func (p *Producer) initializeSchedule() error {
 		}
 
 		// Process monitors in this page
-		pipe := p.rdb.Pipeline()
 		for _, mon := range monitors {
 			if mon.Interval <= 0 {
 				p.logger.Warnw("Skipping monitor with invalid interval", "monitor_id", mon.ID, "interval", mon.Interval)
@@ -82,22 +85,12 @@ func (p *Producer) initializeSchedule() error {
 
 			// Only schedule if not already in Redis
 			if !existingMonitorIDs[mon.ID] {
-				// Schedule monitor immediately for first check
-				pipe.ZAdd(p.ctx, SchedDueKey, redis.Z{
-					Score:  float64(now.UnixMilli()),
-					Member: mon.ID,
-				})
-				newlyScheduledCount++
-				p.logger.Debugw("Scheduled new monitor for immediate first check", "monitor_id", mon.ID, "scheduled_at", now)
+				newMonitors = append(newMonitors, mon)
 			} else {
 				p.logger.Debugw("Monitor already scheduled, skipping reschedule but interval cached", "monitor_id", mon.ID)
 			}
 		}
 
-		if _, err := pipe.Exec(p.ctx); err != nil {
-			return fmt.Errorf("failed to schedule monitors (page %d): %w", page, err)
-		}
-
 		totalMonitors += len(monitors)
 		page++
 
@@ -107,8 +100,24 @@ func (p *Producer) initializeSchedule() error {
 		}
 	}
 
-	// Remove monitors that are in Redis but not active in database
+	// Schedule first checks of new monitors, spread over the startup ramp
 	pipe := p.rdb.Pipeline()
+	for i, mon := range newMonitors {
+		scheduledAt := rampScheduleTime(now, i, len(newMonitors), p.startupRamp, mon.Interval)
+		pipe.ZAdd(p.ctx, SchedDueKey, redis.Z{
+			Score:  float64(scheduledAt.UnixMilli()),
+			Member: mon.ID,
+		})
+		newlyScheduledCount++
+		p.logger.Debugw("Scheduled new monitor for first check", "monitor_id", mon.ID, "scheduled_at", scheduledAt)
+	}
+
+	if _, err := pipe.Exec(p.ctx); err != nil {
+		return fmt.Errorf("failed to schedule monitors: %w", err)
+	}
+
+	// Remove monitors that are in Redis but not active in database
+	pipe = p.rdb.Pipeline()
 	for monitorID := range existingMonitorIDs {
 		if !activeMonitorIDs[monitorID] {
 			pipe.ZRem(p.ctx, SchedDueKey, monitorID)
@@ -127,12 +136,29 @@ func (p *Producer) initializeSchedule() error {
 
 	p.logger.Infow("Initialized schedule",
 		"total_active_monitors", totalMonitors,
+		"startup_ramp", p.startupRamp,
 		"newly_scheduled", newlyScheduledCount,
 		"already_scheduled", len(existingMonitorIDs)-removedCount,
 		"removed_stale", removedCount)
 	return nil
 }
 
+// rampScheduleTime returns the first check time of the index-th of total newly
+// scheduled monitors. Monitors are spaced evenly over the ramp window so a cold
+// start does not enqueue every monitor at once, but a monitor never waits longer
+// than its own interval. Without a ramp every monitor is due immediately.
+func rampScheduleTime(now time.Time, index, total int, ramp time.Duration, intervalSeconds int) time.Time {
+	if ramp <= 0 || total <= 1 {
+		return now
+	}
+
+	offset := ramp * time.Duration(index) / time.Duration(total)
+	if maxOffset := time.Duration(intervalSeconds) * time.Second; intervalSeconds > 0 && offset >= maxOffset {
+		offset = maxOffset - time.Millisecond
+	}
+	return now.Add(offset)
+}
+
 // reclaimExpiredLeases atomically moves expired leases back to the due queue
 // It moves items from the lease set where score <= nowMs back to the due set
 func (p *Producer) reclaimExpiredLeases(ctx context.Context, nowMs int64, maxItems int) ([]string, error) {
@@ -213,6 +239,7 @@ func (p *Producer) refreshSchedule() error {
 		}
 
 		pipe := p.rdb.Pipeline()
+		restores := make(map[string]*redis.Cmd)
 
 		for _, mon := range monitors {
 			if mon.Interval <= 0 {
@@ -225,6 +252,13 @@ func (p *Producer) refreshSchedule() error {
 			oldInterval, exists := p.monitorIntervals[mon.ID]
 			p.mu.RUnlock()
 
+			// A scheduled monitor must be in the due or the lease set, one that got lost
+			// from both would never be checked again
+			if exists && oldInterval == mon.Interval {
+				restores[mon.ID] = pipe.Eval(p.ctx, ensureScheduledLua, []string{SchedDueKey, SchedLeaseKey}, mon.ID, nowMs)
+				continue
+			}
+
 			// If monitor is new or interval changed, reschedule it
 			if !exists || oldInterval != mon.Interval {
 				p.mu.Lock()
@@ -264,6 +298,11 @@ func (p *Producer) refreshSchedule() error {
 		if _, err := pipe.Exec(p.ctx); err != nil {
 			return fmt.Errorf("failed to refresh schedule (page %d): %w", page, err)
 		}
+		for monitorID, cmd := range restores {
+			if restored, _ := cmd.Int(); restored == 1 {
+				p.logger.Warnw("Restored monitor missing from the schedule", "monitor_id", monitorID)
+			}
+		}
 
 		page++
 
@@ -394,6 +433,32 @@ func (p *Producer) UpdateMonitor(ctx context.Context, monitorID string) error {
 	return p.ScheduleMonitor(ctx, monitorID, mon.Interval)
 }
 
+// ScheduleRetry brings the next check of a retrying monitor forward to its retry delay, see
+// shared.RetryDelay. Monitors already due sooner, leased or not scheduled are left alone.
+func (p *Producer) ScheduleRetry(ctx context.Context, monitorID string, attempt int) error {
+	mon, err := p.monitorService.FindByID(ctx, monitorID)
+	if err != nil {
+		return fmt.Errorf("failed to find monitor: %w", err)
+	}
+	// Push monitors are not checked by the producer, retrying only brings their
+	// missing heartbeat check forward
+	if mon == nil || !mon.Active || mon.Type == "push" || mon.RetryInterval <= 0 {
+		return nil
+	}
+
+	delay := shared.RetryDelay(mon.RetryBackoff, mon.RetryInterval, mon.Interval, attempt)
+	next := p.redisNowMs() + delay.Milliseconds()
+	moved, err := p.rdb.Eval(ctx, advanceLua, []string{SchedDueKey}, monitorID, next).Int()
+	if err != nil {
+		return fmt.Errorf("failed to schedule retry: %w", err)
+	}
+
+	if moved == 1 {
+		p.logger.Debugw("Scheduled retry", "monitor_id", monitorID, "attempt", attempt, "backoff", mon.RetryBackoff, "delay", delay)
+	}
+	return nil
+}
+
 // RemoveMonitor removes a monitor from the schedule
 func (p *Producer) RemoveMonitor(ctx context.Context, monitorID string) error {
 	return p.UnscheduleMonitor(ctx, monitorID)
@@ -2,10 +2,12 @@ package producer
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
 	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/shared"
 
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
@@ -581,6 +583,103 @@ func TestInitializeSchedule(t *testing.T) {
 	})
 }
 
+func TestInitializeSchedule_StartupRamp(t *testing.T) {
+	t.Run("spreads first checks across the ramp window", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+
+		mockMonitorSvc := new(MockMonitorService)
+		ramp := 150 * time.Second
+
+		producer := &Producer{
+			rdb:              client,
+			logger:           zap.NewNop().Sugar(),
+			ctx:              context.Background(),
+			monitorService:   mockMonitorSvc,
+			monitorIntervals: make(map[string]int),
+			startupRamp:      ramp,
+		}
+
+		ctx := context.Background()
+
+		// 150 monitors across two pages
+		var firstPage, secondPage []*monitor.Model
+		for i := 0; i < 150; i++ {
+			mon := &monitor.Model{ID: fmt.Sprintf("mon-%03d", i), Active: true, Interval: 300}
+			if i < 100 {
+				firstPage = append(firstPage, mon)
+			} else {
+				secondPage = append(secondPage, mon)
+			}
+		}
+		mockMonitorSvc.On("FindActivePaginated", ctx, 0, 100).Return(firstPage, nil)
+		mockMonitorSvc.On("FindActivePaginated", ctx, 1, 100).Return(secondPage, nil)
+
+		before := producer.redisNowMs()
+		err := producer.initializeSchedule()
+		require.NoError(t, err)
+
+		scheduled, err := client.ZRangeWithScores(ctx, SchedDueKey, 0, -1).Result()
+		require.NoError(t, err)
+		require.Len(t, scheduled, 150)
+
+		first := int64(scheduled[0].Score)
+		last := int64(scheduled[len(scheduled)-1].Score)
+		assert.GreaterOrEqual(t, first, before)
+		assert.Less(t, last-first, ramp.Milliseconds())
+		assert.Greater(t, last-first, ramp.Milliseconds()*9/10, "first checks should cover the ramp window")
+
+		// Evenly spaced: every monitor gets its own 1s slot
+		for i := 1; i < len(scheduled); i++ {
+			assert.Equal(t, int64(1000), int64(scheduled[i].Score)-int64(scheduled[i-1].Score))
+		}
+
+		mockMonitorSvc.AssertExpectations(t)
+	})
+
+	t.Run("no ramp schedules everything immediately", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+
+		mockMonitorSvc := new(MockMonitorService)
+		producer := &Producer{
+			rdb:              client,
+			logger:           zap.NewNop().Sugar(),
+			ctx:              context.Background(),
+			monitorService:   mockMonitorSvc,
+			monitorIntervals: make(map[string]int),
+		}
+
+		ctx := context.Background()
+		monitors := []*monitor.Model{
+			{ID: "mon-1", Active: true, Interval: 60},
+			{ID: "mon-2", Active: true, Interval: 60},
+			{ID: "mon-3", Active: true, Interval: 60},
+		}
+		mockMonitorSvc.On("FindActivePaginated", ctx, 0, 100).Return(monitors, nil)
+
+		require.NoError(t, producer.initializeSchedule())
+
+		scheduled, err := client.ZRangeWithScores(ctx, SchedDueKey, 0, -1).Result()
+		require.NoError(t, err)
+		require.Len(t, scheduled, 3)
+		assert.Equal(t, scheduled[0].Score, scheduled[2].Score)
+	})
+}
+
+func TestRampScheduleTime(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, now, rampScheduleTime(now, 5, 10, 0, 60))
+	assert.Equal(t, now, rampScheduleTime(now, 0, 1, time.Minute, 60))
+	assert.Equal(t, now, rampScheduleTime(now, 0, 4, time.Minute, 60))
+	assert.Equal(t, now.Add(30*time.Second), rampScheduleTime(now, 2, 4, time.Minute, 60))
+	assert.Equal(t, now.Add(45*time.Second), rampScheduleTime(now, 3, 4, time.Minute, 60))
+
+	// A monitor never waits longer than its own interval
+	assert.Equal(t, now.Add(20*time.Second-time.Millisecond), rampScheduleTime(now, 3, 4, 10*time.Minute, 20))
+}
+
 func TestRefreshSchedule(t *testing.T) {
 	t.Run("successfully refresh schedule", func(t *testing.T) {
 		client, mr := setupTestRedis(t)
@@ -673,4 +772,126 @@ func TestRefreshSchedule(t *testing.T) {
 
 		mockMonitorSvc.AssertExpectations(t)
 	})
+
+	t.Run("restore monitor missing from both sets", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+
+		mockMonitorSvc := new(MockMonitorService)
+		producer := &Producer{
+			rdb:              client,
+			logger:           zap.NewNop().Sugar(),
+			ctx:              context.Background(),
+			monitorService:   mockMonitorSvc,
+			monitorIntervals: map[string]int{"mon-1": 60, "mon-2": 60},
+		}
+
+		ctx := context.Background()
+		leaseExpiry := float64(time.Now().Add(time.Hour).UnixMilli())
+		client.ZAdd(ctx, SchedLeaseKey, redis.Z{Score: leaseExpiry, Member: "mon-2"})
+
+		mon1 := monitor.Model{ID: "mon-1", Active: true, Interval: 60}
+		mon2 := monitor.Model{ID: "mon-2", Active: true, Interval: 60}
+		mockMonitorSvc.On("FindActivePaginated", ctx, 0, 100).Return([]*monitor.Model{&mon1, &mon2}, nil)
+
+		require.NoError(t, producer.refreshSchedule())
+
+		_, err := client.ZScore(ctx, SchedDueKey, "mon-1").Result()
+		assert.NoError(t, err)
+
+		// A leased monitor is left to the producer holding it
+		_, err = client.ZScore(ctx, SchedDueKey, "mon-2").Result()
+		assert.Equal(t, redis.Nil, err)
+		score, err := client.ZScore(ctx, SchedLeaseKey, "mon-2").Result()
+		require.NoError(t, err)
+		assert.Equal(t, leaseExpiry, score)
+	})
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		backoff  string
+		attempt  int
+		expected time.Duration
+	}{
+		{shared.RetryBackoffFixed, 1, 20 * time.Second},
+		{shared.RetryBackoffFixed, 5, 20 * time.Second},
+		{"", 3, 20 * time.Second},
+		{shared.RetryBackoffLinear, 1, 20 * time.Second},
+		{shared.RetryBackoffLinear, 3, 60 * time.Second},
+		{shared.RetryBackoffLinear, 20, 300 * time.Second},
+		{shared.RetryBackoffExponential, 1, 20 * time.Second},
+		{shared.RetryBackoffExponential, 2, 40 * time.Second},
+		{shared.RetryBackoffExponential, 4, 160 * time.Second},
+		{shared.RetryBackoffExponential, 5, 300 * time.Second},
+		{shared.RetryBackoffExponential, 100, 300 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s attempt %d", tt.backoff, tt.attempt), func(t *testing.T) {
+			assert.Equal(t, tt.expected, shared.RetryDelay(tt.backoff, 20, 300, tt.attempt))
+		})
+	}
+
+	// A retry interval above the interval is capped as well
+	assert.Equal(t, 60*time.Second, shared.RetryDelay(shared.RetryBackoffFixed, 120, 60, 1))
+}
+
+func TestScheduleRetry(t *testing.T) {
+	client, mr := setupTestRedis(t)
+	defer mr.Close()
+
+	now := time.Date(2025, 11, 10, 9, 0, 0, 0, time.UTC)
+	mr.SetTime(now)
+
+	mockMonitorSvc := new(MockMonitorService)
+	producer := &Producer{
+		rdb:              client,
+		logger:           zap.NewNop().Sugar(),
+		ctx:              context.Background(),
+		monitorService:   mockMonitorSvc,
+		monitorIntervals: make(map[string]int),
+	}
+	ctx := context.Background()
+
+	mockMonitorSvc.On("FindByID", ctx, "mon-exp").Return(&monitor.Model{
+		ID: "mon-exp", Type: "http", Active: true, Interval: 300, RetryInterval: 20, RetryBackoff: shared.RetryBackoffExponential,
+	}, nil)
+	mockMonitorSvc.On("FindByID", ctx, "mon-push").Return(&monitor.Model{
+		ID: "mon-push", Type: "push", Active: true, Interval: 300, RetryInterval: 20,
+	}, nil)
+
+	// dueAfter schedules the monitor at its regular next check and returns when it is due
+	// after the retry was scheduled
+	dueAfter := func(monitorID string, attempt int) time.Duration {
+		t.Helper()
+		require.NoError(t, client.ZAdd(ctx, SchedDueKey, redis.Z{Score: float64(now.Add(300 * time.Second).UnixMilli()), Member: monitorID}).Err())
+		require.NoError(t, producer.ScheduleRetry(ctx, monitorID, attempt))
+		score, err := client.ZScore(ctx, SchedDueKey, monitorID).Result()
+		require.NoError(t, err)
+		return time.UnixMilli(int64(score)).Sub(now)
+	}
+
+	assert.Equal(t, 20*time.Second, dueAfter("mon-exp", 1))
+	assert.Equal(t, 80*time.Second, dueAfter("mon-exp", 3))
+	assert.Equal(t, 300*time.Second, dueAfter("mon-exp", 6), "backoff capped at the interval")
+	assert.Equal(t, 300*time.Second, dueAfter("mon-push", 1), "push monitors are not retried")
+
+	t.Run("a monitor due sooner keeps its time", func(t *testing.T) {
+		require.NoError(t, client.ZAdd(ctx, SchedDueKey, redis.Z{Score: float64(now.Add(5 * time.Second).UnixMilli()), Member: "mon-exp"}).Err())
+		require.NoError(t, producer.ScheduleRetry(ctx, "mon-exp", 1))
+
+		score, err := client.ZScore(ctx, SchedDueKey, "mon-exp").Result()
+		require.NoError(t, err)
+		assert.Equal(t, float64(now.Add(5*time.Second).UnixMilli()), score)
+	})
+
+	t.Run("a leased monitor is not added to the due set", func(t *testing.T) {
+		require.NoError(t, client.ZRem(ctx, SchedDueKey, "mon-exp").Err())
+		require.NoError(t, client.ZAdd(ctx, SchedLeaseKey, redis.Z{Score: float64(now.Add(time.Minute).UnixMilli()), Member: "mon-exp"}).Err())
+		require.NoError(t, producer.ScheduleRetry(ctx, "mon-exp", 1))
+
+		_, err := client.ZScore(ctx, SchedDueKey, "mon-exp").Result()
+		assert.Equal(t, redis.Nil, err)
+	})
 }
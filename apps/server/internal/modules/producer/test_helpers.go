@@ -11,6 +11,8 @@ import (
 	"peekaping/internal/modules/monitor"
 	"peekaping/internal/modules/proxy"
 	"peekaping/internal/modules/queue"
+	"peekaping/internal/modules/setting"
+	"peekaping/internal/modules/shared"
 	"peekaping/internal/modules/stats"
 
 	"github.com/alicebob/miniredis/v2"
@@ -130,6 +132,11 @@ func (m *MockMonitorService) GetHeartbeats(ctx context.Context, id string, limit
 	return args.Get(0).([]*heartbeat.Model), args.Error(1)
 }
 
+func (m *MockMonitorService) ExportHeartbeats(ctx context.Context, id string, since, until time.Time, fn func(*heartbeat.Model) error) error {
+	args := m.Called(ctx, id, since, until, fn)
+	return args.Error(0)
+}
+
 func (m *MockMonitorService) RemoveProxyReference(ctx context.Context, proxyId string) error {
 	args := m.Called(ctx, proxyId)
 	return args.Error(0)
@@ -198,6 +205,19 @@ func (m *MockMaintenanceService) IsUnderMaintenance(ctx context.Context, maint *
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *MockMaintenanceService) IsUnderMaintenanceAt(ctx context.Context, maint *maintenance.Model, at time.Time) (bool, error) {
+	args := m.Called(ctx, maint, at)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMaintenanceService) NextWindow(ctx context.Context, maint *maintenance.Model, after time.Time) (*maintenance.Window, error) {
+	args := m.Called(ctx, maint, after)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*maintenance.Window), args.Error(1)
+}
+
 func (m *MockMaintenanceService) Delete(ctx context.Context, id string) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
@@ -243,6 +263,14 @@ func (m *MockMaintenanceService) SetActive(ctx context.Context, id string, activ
 	return args.Get(0).(*maintenance.Model), args.Error(1)
 }
 
+func (m *MockMaintenanceService) SetApproval(ctx context.Context, id string, status string, approvedBy string) (*maintenance.Model, error) {
+	args := m.Called(ctx, id, status, approvedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*maintenance.Model), args.Error(1)
+}
+
 func (m *MockMaintenanceService) GetMonitors(ctx context.Context, id string) ([]string, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -251,6 +279,11 @@ func (m *MockMaintenanceService) GetMonitors(ctx context.Context, id string) ([]
 	return args.Get(0).([]string), args.Error(1)
 }
 
+func (m *MockMaintenanceService) EndOnRecovery(ctx context.Context, maintenance *maintenance.Model, latest map[string][]*shared.HeartBeatModel) (bool, error) {
+	args := m.Called(ctx, maintenance, latest)
+	return args.Bool(0), args.Error(1)
+}
+
 // MockProxyService for testing
 type MockProxyService struct {
 	mock.Mock
@@ -301,6 +334,89 @@ func (m *MockProxyService) UpdatePartial(ctx context.Context, id string, dto *pr
 	return args.Get(0).(*proxy.Model), args.Error(1)
 }
 
+// MockHeartbeatService for testing
+type MockHeartbeatService struct {
+	mock.Mock
+}
+
+func (m *MockHeartbeatService) Create(ctx context.Context, entity *heartbeat.CreateUpdateDto) (*heartbeat.Model, error) {
+	args := m.Called(ctx, entity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*heartbeat.Model), args.Error(1)
+}
+
+func (m *MockHeartbeatService) FindByID(ctx context.Context, id string) (*heartbeat.Model, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*heartbeat.Model), args.Error(1)
+}
+
+func (m *MockHeartbeatService) FindAll(ctx context.Context, page int, limit int) ([]*heartbeat.Model, error) {
+	args := m.Called(ctx, page, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*heartbeat.Model), args.Error(1)
+}
+
+func (m *MockHeartbeatService) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockHeartbeatService) FindUptimeStatsByMonitorID(ctx context.Context, monitorID string, periods map[string]time.Duration, now time.Time) (map[string]float64, error) {
+	args := m.Called(ctx, monitorID, periods, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]float64), args.Error(1)
+}
+
+func (m *MockHeartbeatService) DeleteOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	args := m.Called(ctx, cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockHeartbeatService) CompactOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	args := m.Called(ctx, cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockHeartbeatService) DeleteRollupsOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	args := m.Called(ctx, cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockHeartbeatService) FindByMonitorIDPaginated(ctx context.Context, monitorID string, limit, page int, important *bool, reverse bool) ([]*heartbeat.Model, error) {
+	args := m.Called(ctx, monitorID, limit, page, important, reverse)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*heartbeat.Model), args.Error(1)
+}
+
+func (m *MockHeartbeatService) FindLatestByMonitorIDs(ctx context.Context, monitorIDs []string) (map[string]*heartbeat.Model, error) {
+	args := m.Called(ctx, monitorIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]*heartbeat.Model), args.Error(1)
+}
+
+func (m *MockHeartbeatService) DeleteByMonitorID(ctx context.Context, monitorID string) error {
+	args := m.Called(ctx, monitorID)
+	return args.Error(0)
+}
+
+func (m *MockHeartbeatService) StreamByMonitorID(ctx context.Context, monitorID string, since, until time.Time, fn func(*heartbeat.Model) error) error {
+	args := m.Called(ctx, monitorID, since, until, fn)
+	return args.Error(0)
+}
+
 // MockQueueService for testing
 type MockQueueService struct {
 	mock.Mock
@@ -382,6 +498,14 @@ func (m *MockQueueService) ListActiveTasks(ctx context.Context, queueName string
 	return args.Get(0).([]*queue.TaskInfo), args.Error(1)
 }
 
+func (m *MockQueueService) ListServers(ctx context.Context) ([]*queue.ServerInfo, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*queue.ServerInfo), args.Error(1)
+}
+
 func (m *MockQueueService) ListScheduledTasks(ctx context.Context, queueName string, pageSize, pageNum int) ([]*queue.TaskInfo, error) {
 	args := m.Called(ctx, queueName, pageSize, pageNum)
 	if args.Get(0) == nil {
@@ -466,15 +590,65 @@ func (m *MockStatsService) FindStatsByMonitorIDAndTimeRangeWithInterval(ctx cont
 	return args.Get(0).([]*stats.Stat), args.Error(1)
 }
 
-func (m *MockStatsService) StatPointsSummary(statsList []*stats.Stat) *stats.Stats {
-	args := m.Called(statsList)
+func (m *MockStatsService) StatPointsSummary(ctx context.Context, statsList []*stats.Stat) *stats.Stats {
+	args := m.Called(ctx, statsList)
 	if args.Get(0) == nil {
 		return nil
 	}
 	return args.Get(0).(*stats.Stats)
 }
 
+func (m *MockStatsService) DailyUptime(ctx context.Context, monitorID string, days int, now time.Time) ([]*stats.DayUptime, error) {
+	args := m.Called(ctx, monitorID, days, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*stats.DayUptime), args.Error(1)
+}
+
 func (m *MockStatsService) DeleteByMonitorID(ctx context.Context, monitorID string) error {
 	args := m.Called(ctx, monitorID)
 	return args.Error(0)
 }
+
+// MockSettingService for testing
+type MockSettingService struct {
+	mock.Mock
+}
+
+func (m *MockSettingService) GetByKey(ctx context.Context, key string) (*shared.SettingModel, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*shared.SettingModel), args.Error(1)
+}
+
+func (m *MockSettingService) SetByKey(ctx context.Context, key string, entity *shared.SettingCreateUpdateDto) (*shared.SettingModel, error) {
+	args := m.Called(ctx, key, entity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*shared.SettingModel), args.Error(1)
+}
+
+func (m *MockSettingService) DeleteByKey(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockSettingService) InitializeSettings(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+// newMockSettingServiceWithoutDefaultProxy returns a setting service with no global default proxy configured
+func newMockSettingServiceWithoutDefaultProxy() *MockSettingService {
+	m := new(MockSettingService)
+	m.On("GetByKey", mock.Anything, proxy.DefaultProxySettingKey).Return(nil, nil).Maybe()
+	m.On("GetByKey", mock.Anything, maintenance.CheckDuringMaintenanceSettingKey).Return(nil, nil).Maybe()
+	m.On("GetByKey", mock.Anything, setting.MonitoringPausedSettingKey).Return(nil, nil).Maybe()
+	m.On("GetByKey", mock.Anything, BatchClaimSettingKey).Return(nil, nil).Maybe()
+	m.On("GetByKey", mock.Anything, ClaimTickSettingKey).Return(nil, nil).Maybe()
+	return m
+}
@@ -19,23 +19,40 @@ import (
 
 // Producer is responsible for scheduling monitor health checks
 type Producer struct {
-	rdb                     *redis.Client
-	queueService            queue.Service
-	monitorService          monitor.Service
-	proxyService            proxy.Service
-	maintenanceService      maintenance.Service
-	monitorNotificationSvc  monitor_notification.Service
-	settingService          shared.SettingService
-	heartbeatService        heartbeat.Service
-	logger                  *zap.SugaredLogger
-	ctx                     context.Context
-	cancel                  context.CancelFunc
-	syncCtx                 context.Context    // context for monitor syncing (leader-only tasks)
-	syncCancel              context.CancelFunc // cancel function for monitor syncing
-	wg                      sync.WaitGroup
-	mu                      sync.RWMutex
-	monitorIntervals        map[string]int // monitor_id -> interval in seconds
-	scheduleRefreshInterval time.Duration
-	leaderElection          *LeaderElection
-	concurrency             int // number of concurrent producer goroutines
+	rdb                        *redis.Client
+	queueService               queue.Service
+	monitorService             monitor.Service
+	proxyService               proxy.Service
+	maintenanceService         maintenance.Service
+	monitorNotificationSvc     monitor_notification.Service
+	settingService             shared.SettingService
+	heartbeatService           heartbeat.Service
+	logger                     *zap.SugaredLogger
+	ctx                        context.Context
+	cancel                     context.CancelFunc
+	syncCtx                    context.Context    // context for monitor syncing (leader-only tasks)
+	syncCancel                 context.CancelFunc // cancel function for monitor syncing
+	wg                         sync.WaitGroup
+	mu                         sync.RWMutex
+	monitorIntervals           map[string]int // monitor_id -> interval in seconds
+	scheduleRefreshInterval    time.Duration
+	leaderElection             *LeaderElection
+	concurrency                int           // number of concurrent producer goroutines
+	startupRamp                time.Duration // window to spread first checks over after gaining leadership
+	healthCheckShards          int           // number of health check queues tasks are sharded over
+	pauseMu                    sync.Mutex
+	paused                     bool      // last known value of the global monitoring_paused setting
+	pauseCheckedAt             time.Time // when paused was last read from the settings
+	claimMu                    sync.Mutex
+	claimLimits                claimLimits // last read producer_batch_claim and producer_claim_tick_ms
+	claimCheckedAt             time.Time   // when claimLimits was last read from the settings
+	defaultProxyMu             sync.Mutex
+	defaultProxy               string    // last read default_proxy_id setting
+	defaultProxyCheckedAt      time.Time // when defaultProxy was last read from the settings
+	maintenanceChecksMu        sync.Mutex
+	maintenanceChecks          bool      // last read check_during_maintenance setting
+	maintenanceChecksCheckedAt time.Time // when maintenanceChecks was last read from the settings
+	metrics                    *SchedulerMetrics
+	lagMu                      sync.Mutex
+	lagWarnedAt                time.Time // when the last scheduling lag warning was logged
 }
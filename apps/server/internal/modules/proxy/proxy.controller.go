@@ -9,18 +9,21 @@ import (
 )
 
 type Controller struct {
-	service Service
-	logger  *zap.SugaredLogger
+	service       Service
+	healthChecker *HealthChecker
+	logger        *zap.SugaredLogger
 }
 
 func NewController(
 	service Service,
+	healthChecker *HealthChecker,
 	logger *zap.SugaredLogger,
 ) *Controller {
 	// Register custom struct-level validation if needed
 	// validate.RegisterStructValidation(CreateUpdateDtoStructLevelValidation, CreateUpdateDto{})
 	return &Controller{
 		service,
+		healthChecker,
 		logger,
 	}
 }
@@ -124,6 +127,35 @@ func (ic *Controller) FindByID(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, utils.NewSuccessResponse("success", entity))
 }
 
+// @Router		/proxies/{id}/health [get]
+// @Summary		Get proxy health
+// @Description	Returns the result of the last reachability check of the proxy, checking it now when it wasn't checked yet
+// @Tags			Proxies
+// @Produce		json
+// @Security  JwtAuth
+// @Security  ApiKeyAuth
+// @Param       id   path    string  true  "Proxy ID"
+// @Success		200	{object}	utils.ApiResponse[HealthStatus]
+// @Failure		404	{object}	utils.APIError[any]
+// @Failure		500	{object}	utils.APIError[any]
+func (ic *Controller) Health(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	entity, err := ic.service.FindByID(ctx, id)
+	if err != nil {
+		ic.logger.Errorw("Failed to fetch proxy", "error", err)
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
+		return
+	}
+
+	if entity == nil {
+		ctx.JSON(http.StatusNotFound, utils.NewFailResponse("Proxy not found"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewSuccessResponse("success", ic.healthChecker.Status(ctx, entity)))
+}
+
 // @Router		/proxies/{id} [put]
 // @Summary		Update proxy
 // @Tags			Proxies
@@ -0,0 +1,164 @@
+package proxy
+
+import (
+	"context"
+	"net"
+	"peekaping/internal/config"
+	"peekaping/internal/modules/events"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ProbeTimeout bounds how long a proxy probe waits for the TCP connection
+const ProbeTimeout = 5 * time.Second
+
+// Probe reports whether a TCP connection to the proxy can be opened. It does not
+// authenticate, a proxy that accepts connections is considered up.
+func Probe(ctx context.Context, p *Model) error {
+	ctx, cancel := context.WithTimeout(ctx, ProbeTimeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(p.Host, strconv.Itoa(p.Port)))
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// HealthStatus is the result of the last probe of a proxy
+type HealthStatus struct {
+	ProxyID   string    `json:"proxy_id"`
+	Healthy   bool      `json:"healthy"`
+	Message   string    `json:"message,omitempty"`
+	LatencyMs int       `json:"latency_ms"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// HealthChecker probes every proxy periodically, keeps the last result of each and
+// publishes a ProxyHealthChanged event when a proxy goes down or comes back up
+type HealthChecker struct {
+	service  Service
+	eventBus events.EventBus
+	interval time.Duration
+	probe    func(ctx context.Context, p *Model) error
+	mu       sync.Mutex
+	statuses map[string]*HealthStatus
+	logger   *zap.SugaredLogger
+}
+
+func NewHealthChecker(
+	service Service,
+	eventBus events.EventBus,
+	cfg *config.Config,
+	logger *zap.SugaredLogger,
+) *HealthChecker {
+	return &HealthChecker{
+		service:  service,
+		eventBus: eventBus,
+		interval: cfg.ProxyHealthCheckInterval,
+		probe:    Probe,
+		statuses: make(map[string]*HealthStatus),
+		logger:   logger.Named("[proxy-health]"),
+	}
+}
+
+// Start runs CheckAll every interval until ctx is done. Nothing is started when the
+// interval is 0.
+func (h *HealthChecker) Start(ctx context.Context) {
+	if h.interval <= 0 {
+		h.logger.Info("Proxy health checks are disabled")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(h.interval)
+		defer ticker.Stop()
+
+		for {
+			h.CheckAll(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// CheckAll probes every proxy
+func (h *HealthChecker) CheckAll(ctx context.Context) {
+	const pageSize = 100
+	for page := 0; ; page++ {
+		proxies, err := h.service.FindAll(ctx, page, pageSize, "")
+		if err != nil {
+			h.logger.Errorw("Failed to list proxies", "error", err)
+			return
+		}
+		for _, p := range proxies {
+			h.Check(ctx, p)
+		}
+		if len(proxies) < pageSize {
+			return
+		}
+	}
+}
+
+// Check probes the proxy and records the result
+func (h *HealthChecker) Check(ctx context.Context, p *Model) *HealthStatus {
+	start := time.Now()
+	err := h.probe(ctx, p)
+
+	status := &HealthStatus{
+		ProxyID:   p.ID,
+		Healthy:   err == nil,
+		LatencyMs: int(time.Since(start).Milliseconds()),
+		CheckedAt: time.Now().UTC(),
+	}
+	if err != nil {
+		status.Message = err.Error()
+	}
+
+	h.mu.Lock()
+	previous := h.statuses[p.ID]
+	h.statuses[p.ID] = status
+	h.mu.Unlock()
+
+	// A proxy seen for the first time is assumed to have been healthy, so only an
+	// outage is reported
+	wasHealthy := previous == nil || previous.Healthy
+	if wasHealthy != status.Healthy {
+		h.logger.Infow("Proxy health changed", "proxy_id", p.ID, "host", p.Host, "port", p.Port, "healthy", status.Healthy, "message", status.Message)
+		if h.eventBus != nil {
+			h.eventBus.Publish(events.Event{
+				Type: events.ProxyHealthChanged,
+				Payload: &events.ProxyHealthPayload{
+					ProxyID: p.ID,
+					Host:    p.Host,
+					Port:    p.Port,
+					Healthy: status.Healthy,
+					Message: status.Message,
+					Time:    status.CheckedAt,
+				},
+			})
+		}
+	}
+
+	return status
+}
+
+// Status returns the last recorded health of the proxy, probing it when it wasn't
+// checked yet
+func (h *HealthChecker) Status(ctx context.Context, p *Model) *HealthStatus {
+	h.mu.Lock()
+	status, ok := h.statuses[p.ID]
+	h.mu.Unlock()
+	if ok {
+		return status
+	}
+	return h.Check(ctx, p)
+}
@@ -0,0 +1,71 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"peekaping/internal/modules/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+type MockEventBus struct {
+	mock.Mock
+}
+
+func (m *MockEventBus) Subscribe(eventType events.EventType, handler events.EventHandler) {
+	m.Called(eventType, handler)
+}
+
+func (m *MockEventBus) Publish(event events.Event) {
+	m.Called(event)
+}
+
+func (m *MockEventBus) Close() error {
+	return m.Called().Error(0)
+}
+
+func TestHealthChecker_Check(t *testing.T) {
+	ctx := context.Background()
+	p := &Model{ID: "proxy-1", Protocol: "http", Host: "proxy.example.com", Port: 3128}
+
+	var probeErr error
+	eventBus := new(MockEventBus)
+	checker := &HealthChecker{
+		eventBus: eventBus,
+		probe:    func(ctx context.Context, p *Model) error { return probeErr },
+		statuses: make(map[string]*HealthStatus),
+		logger:   zap.NewNop().Sugar(),
+	}
+	healthChanged := func(healthy bool) any {
+		return mock.MatchedBy(func(e events.Event) bool {
+			payload, ok := e.Payload.(*events.ProxyHealthPayload)
+			return e.Type == events.ProxyHealthChanged && ok && payload.ProxyID == "proxy-1" && payload.Healthy == healthy
+		})
+	}
+
+	// A healthy proxy seen for the first time is not reported
+	status := checker.Check(ctx, p)
+	assert.True(t, status.Healthy)
+	eventBus.AssertNotCalled(t, "Publish", mock.Anything)
+
+	probeErr = errors.New("connection refused")
+	eventBus.On("Publish", healthChanged(false)).Once()
+	status = checker.Check(ctx, p)
+	assert.False(t, status.Healthy)
+	assert.Equal(t, "connection refused", status.Message)
+
+	// Still down, no new alert
+	checker.Check(ctx, p)
+
+	probeErr = nil
+	eventBus.On("Publish", healthChanged(true)).Once()
+	checker.Check(ctx, p)
+
+	eventBus.AssertExpectations(t)
+	eventBus.AssertNumberOfCalls(t, "Publish", 2)
+	assert.True(t, checker.Status(ctx, p).Healthy)
+}
@@ -31,6 +31,7 @@ func (uc *Route) ConnectRoute(
 	router.GET("", uc.controller.FindAll)
 	router.POST("", uc.controller.Create)
 	router.GET(":id", uc.controller.FindByID)
+	router.GET(":id/health", uc.controller.Health)
 	router.PUT(":id", uc.controller.UpdateFull)
 	router.PATCH(":id", uc.controller.UpdatePartial)
 	router.DELETE(":id", uc.controller.Delete)
@@ -146,6 +146,11 @@ func (m *MockMonitorService) GetHeartbeats(ctx context.Context, id string, limit
 	return args.Get(0).([]*heartbeat.Model), args.Error(1)
 }
 
+func (m *MockMonitorService) ExportHeartbeats(ctx context.Context, id string, since, until time.Time, fn func(*heartbeat.Model) error) error {
+	args := m.Called(ctx, id, since, until, fn)
+	return args.Error(0)
+}
+
 func (m *MockMonitorService) RemoveProxyReference(ctx context.Context, proxyID string) error {
 	args := m.Called(ctx, proxyID)
 	return args.Error(0)
@@ -0,0 +1,44 @@
+package queue
+
+import (
+	"net/http"
+	"peekaping/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type Controller struct {
+	pressureService *PressureService
+	logger          *zap.SugaredLogger
+}
+
+func NewController(
+	pressureService *PressureService,
+	logger *zap.SugaredLogger,
+) *Controller {
+	return &Controller{
+		pressureService,
+		logger,
+	}
+}
+
+// @Router		/admin/queue-pressure [get]
+// @Summary		Get the pressure on the health check queues
+// @Description	Pending tasks, their average wait and the number of workers recommended to keep up, meant as an autoscaling signal
+// @Tags			Admin
+// @Produce		json
+// @Security  JwtAuth
+// @Security  ApiKeyAuth
+// @Success		200	{object}	utils.ApiResponse[Pressure]
+// @Failure		500	{object}	utils.APIError[any]
+func (c *Controller) GetPressure(ctx *gin.Context) {
+	pressure, err := c.pressureService.Get(ctx)
+	if err != nil {
+		c.logger.Errorw("Failed to get queue pressure", "error", err)
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewSuccessResponse("success", pressure))
+}
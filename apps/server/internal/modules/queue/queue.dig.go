@@ -7,8 +7,9 @@ import (
 )
 
 // RegisterDependencies registers queue module dependencies
-// The actual providers are in infra/queue.go to avoid asynq references in this module
+// The queue service provider is in infra/queue.go to avoid asynq references in this module
 func RegisterDependencies(container *dig.Container, cfg *config.Config) {
-	// No dependencies to register here
-	// The queue service provider is in infra/queue.go
+	container.Provide(NewPressureService)
+	container.Provide(NewController)
+	container.Provide(NewRoute)
 }
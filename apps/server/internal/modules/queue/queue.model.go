@@ -4,6 +4,10 @@ import (
 	"time"
 )
 
+// NotificationQueue is the queue of delayed notification deliveries. The API consumes it,
+// as it holds the notification channel providers.
+const NotificationQueue = "notifications"
+
 // EnqueueOptions contains options for enqueuing a task
 type EnqueueOptions struct {
 	// Queue name (e.g., "critical", "default", "low")
@@ -69,4 +73,21 @@ type QueueInfo struct {
 	Failed    int
 	Paused    bool
 	Timestamp time.Time
+
+	// Latency is the time the oldest pending task has been waiting
+	Latency time.Duration
+
+	// ProcessedTotal counts the tasks processed since the queue was created, Processed
+	// only those of the current day
+	ProcessedTotal int
+}
+
+// ServerInfo represents a worker server consuming queues
+type ServerInfo struct {
+	ID            string
+	Host          string
+	Concurrency   int
+	Queues        map[string]int
+	ActiveWorkers int
+	Started       time.Time
 }
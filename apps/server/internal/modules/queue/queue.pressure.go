@@ -0,0 +1,146 @@
+package queue
+
+import (
+	"context"
+	"math"
+	"peekaping/internal/config"
+	"sync"
+	"time"
+)
+
+// minThroughputSample is the shortest interval the throughput is measured over, requests
+// closer together reuse the previous measurement
+const minThroughputSample = 5 * time.Second
+
+// Pressure is the backlog of the health check queues and the number of workers needed to
+// keep up with it
+type Pressure struct {
+	Pending             int     `json:"pending"`
+	Active              int     `json:"active"`
+	AverageWaitSeconds  float64 `json:"average_wait_seconds"`
+	OldestWaitSeconds   float64 `json:"oldest_wait_seconds"`
+	ThroughputPerSecond float64 `json:"throughput_per_second"`
+	Workers             int     `json:"workers"`
+	RecommendedWorkers  int     `json:"recommended_workers"`
+}
+
+// ComputePressure sums the health check queues and recommends enough workers to keep up
+// with throughput while clearing the pending tasks within targetWait. A worker is assumed
+// to process its share of the current throughput. Without throughput or workers to
+// estimate that from, one worker is added as long as tasks are pending.
+func ComputePressure(queues []*QueueInfo, servers []*ServerInfo, throughput float64, targetWait time.Duration) *Pressure {
+	pressure := &Pressure{ThroughputPerSecond: throughput}
+
+	var oldest time.Duration
+	for _, info := range queues {
+		if info == nil || !IsHealthCheckQueue(info.Queue) {
+			continue
+		}
+		pressure.Pending += info.Pending
+		pressure.Active += info.Active
+		oldest = max(oldest, info.Latency)
+	}
+	for _, server := range servers {
+		if server != nil && consumesHealthChecks(server) {
+			pressure.Workers++
+		}
+	}
+
+	pressure.OldestWaitSeconds = oldest.Seconds()
+	pressure.AverageWaitSeconds = oldest.Seconds()
+	if throughput > 0 {
+		// A new task waits for the pending ones ahead of it (Little's law)
+		pressure.AverageWaitSeconds = math.Min(float64(pressure.Pending)/throughput, oldest.Seconds())
+	}
+
+	pressure.RecommendedWorkers = max(pressure.Workers, 1)
+	switch {
+	case throughput > 0 && pressure.Workers > 0 && targetWait > 0:
+		backlog := float64(pressure.Pending) / targetWait.Seconds()
+		needed := math.Ceil(float64(pressure.Workers) * (throughput + backlog) / throughput)
+		pressure.RecommendedWorkers = max(int(needed), 1)
+	case pressure.Pending > 0:
+		pressure.RecommendedWorkers = pressure.Workers + 1
+	}
+
+	return pressure
+}
+
+func consumesHealthChecks(server *ServerInfo) bool {
+	for name := range server.Queues {
+		if IsHealthCheckQueue(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// PressureService reports the pressure on the health check queues, measuring their
+// throughput between successive requests
+type PressureService struct {
+	queues     Service
+	targetWait time.Duration
+	now        func() time.Time
+
+	mu            sync.Mutex
+	lastProcessed int
+	lastSampledAt time.Time
+	lastRate      float64
+}
+
+func NewPressureService(queues Service, cfg *config.Config) *PressureService {
+	return &PressureService{
+		queues:     queues,
+		targetWait: cfg.QueuePressureTargetWait,
+		now:        time.Now,
+	}
+}
+
+func (s *PressureService) Get(ctx context.Context) (*Pressure, error) {
+	queues, err := s.queues.ListQueues(ctx)
+	if err != nil {
+		return nil, err
+	}
+	servers, err := s.queues.ListServers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return ComputePressure(queues, servers, s.throughput(queues), s.targetWait), nil
+}
+
+// throughput is the rate health checks were processed at since the previous request. On
+// the first request, or when the counters were reset meanwhile, it is today's average rate.
+func (s *PressureService) throughput(queues []*QueueInfo) float64 {
+	total, today := 0, 0
+	for _, info := range queues {
+		if info != nil && IsHealthCheckQueue(info.Queue) {
+			total += info.ProcessedTotal
+			today += info.Processed
+		}
+	}
+
+	now := s.now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elapsed := now.Sub(s.lastSampledAt)
+	if !s.lastSampledAt.IsZero() && total >= s.lastProcessed && elapsed < minThroughputSample {
+		return s.lastRate
+	}
+
+	if !s.lastSampledAt.IsZero() && total >= s.lastProcessed {
+		s.lastRate = float64(total-s.lastProcessed) / elapsed.Seconds()
+	} else {
+		// asynq counts the processed tasks per UTC day
+		utc := now.UTC()
+		sinceMidnight := utc.Sub(time.Date(utc.Year(), utc.Month(), utc.Day(), 0, 0, 0, 0, time.UTC))
+		s.lastRate = 0
+		if sinceMidnight > 0 {
+			s.lastRate = float64(today) / sinceMidnight.Seconds()
+		}
+	}
+	s.lastProcessed = total
+	s.lastSampledAt = now
+	return s.lastRate
+}
@@ -0,0 +1,96 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func healthCheckWorkers(count int) []*ServerInfo {
+	servers := make([]*ServerInfo, count)
+	for i := range servers {
+		servers[i] = &ServerInfo{Concurrency: 128, Queues: map[string]int{HealthCheckQueue: 5}}
+	}
+	return servers
+}
+
+func TestComputePressure(t *testing.T) {
+	t.Run("backlog increases the recommended worker count", func(t *testing.T) {
+		servers := healthCheckWorkers(2)
+		recommended := func(pending int) int {
+			queues := []*QueueInfo{{Queue: HealthCheckQueue, Pending: pending, Latency: time.Minute}}
+			return ComputePressure(queues, servers, 10, 30*time.Second).RecommendedWorkers
+		}
+
+		assert.Equal(t, 2, recommended(0))
+		assert.Equal(t, 4, recommended(300))
+		assert.Equal(t, 8, recommended(900))
+		assert.Greater(t, recommended(3000), recommended(900))
+	})
+
+	t.Run("sums the health check shards only", func(t *testing.T) {
+		queues := []*QueueInfo{
+			{Queue: HealthCheckQueue + ":0", Pending: 10, Active: 2, Latency: 4 * time.Second},
+			{Queue: HealthCheckQueue + ":1", Pending: 20, Active: 3, Latency: 8 * time.Second},
+			{Queue: "ingester", Pending: 1000, Latency: time.Hour},
+		}
+		servers := append(healthCheckWorkers(3), &ServerInfo{Queues: map[string]int{"ingester": 1}})
+
+		pressure := ComputePressure(queues, servers, 10, time.Minute)
+		assert.Equal(t, 30, pressure.Pending)
+		assert.Equal(t, 5, pressure.Active)
+		assert.Equal(t, 3, pressure.Workers)
+		assert.Equal(t, 8.0, pressure.OldestWaitSeconds)
+		assert.Equal(t, 3.0, pressure.AverageWaitSeconds)
+	})
+
+	t.Run("adds a worker while tasks wait without throughput", func(t *testing.T) {
+		queues := []*QueueInfo{{Queue: HealthCheckQueue, Pending: 5, Latency: 10 * time.Second}}
+
+		assert.Equal(t, 1, ComputePressure(queues, nil, 0, time.Minute).RecommendedWorkers)
+		pressure := ComputePressure(queues, healthCheckWorkers(2), 0, time.Minute)
+		assert.Equal(t, 3, pressure.RecommendedWorkers)
+		assert.Equal(t, 10.0, pressure.AverageWaitSeconds)
+		assert.Equal(t, 1, ComputePressure(nil, nil, 0, time.Minute).RecommendedWorkers)
+	})
+}
+
+type fakeQueueService struct {
+	Service
+	queues []*QueueInfo
+}
+
+func (f *fakeQueueService) ListQueues(ctx context.Context) ([]*QueueInfo, error) {
+	return f.queues, nil
+}
+
+func (f *fakeQueueService) ListServers(ctx context.Context) ([]*ServerInfo, error) {
+	return healthCheckWorkers(1), nil
+}
+
+func TestPressureService_MeasuresThroughputBetweenRequests(t *testing.T) {
+	now := time.Date(2025, 10, 1, 1, 0, 0, 0, time.UTC)
+	queues := &fakeQueueService{queues: []*QueueInfo{{Queue: HealthCheckQueue, Processed: 3600, ProcessedTotal: 10000}}}
+	service := &PressureService{queues: queues, targetWait: 30 * time.Second, now: func() time.Time { return now }}
+
+	// First request: today's average rate
+	pressure, err := service.Get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, pressure.ThroughputPerSecond)
+
+	now = now.Add(10 * time.Second)
+	queues.queues[0].ProcessedTotal = 10050
+	pressure, err = service.Get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 5.0, pressure.ThroughputPerSecond)
+
+	// Too close to the previous request to measure again
+	now = now.Add(time.Second)
+	queues.queues[0].ProcessedTotal = 10100
+	pressure, err = service.Get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 5.0, pressure.ThroughputPerSecond)
+}
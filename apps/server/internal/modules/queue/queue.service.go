@@ -43,6 +43,9 @@ type Service interface {
 	// ListScheduledTasks returns a list of scheduled tasks in a queue
 	ListScheduledTasks(ctx context.Context, queueName string, pageSize, pageNum int) ([]*TaskInfo, error)
 
+	// ListServers returns the worker servers currently running
+	ListServers(ctx context.Context) ([]*ServerInfo, error)
+
 	// Close closes the queue connections
 	Close() error
 }
@@ -0,0 +1,43 @@
+package queue
+
+import (
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// HealthCheckQueue is the queue of health check tasks when they are not sharded. Workers
+// keep consuming it with sharding on, so tasks enqueued before the switch still run.
+const HealthCheckQueue = "healthcheck"
+
+// healthCheckQueuePriority is the weight of every health check queue on the worker
+const healthCheckQueuePriority = 5
+
+// HealthCheckQueueName returns the queue of the monitor's health checks. With more than
+// one shard, monitors are spread over "healthcheck:0" to "healthcheck:<shards-1>" by a
+// hash of their id, so a monitor always lands in the same shard.
+func HealthCheckQueueName(monitorID string, shards int) string {
+	if shards <= 1 {
+		return HealthCheckQueue
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(monitorID))
+	return HealthCheckQueue + ":" + strconv.Itoa(int(h.Sum32()%uint32(shards)))
+}
+
+// HealthCheckQueues returns the queues workers consume health checks from, with their
+// weights. Every shard gets the same weight so that under backlog each non-empty shard
+// is equally likely to be served next, whatever the number of tasks it holds.
+func HealthCheckQueues(shards int) map[string]int {
+	queues := map[string]int{HealthCheckQueue: healthCheckQueuePriority}
+	for i := 0; i < shards && shards > 1; i++ {
+		queues[HealthCheckQueue+":"+strconv.Itoa(i)] = healthCheckQueuePriority
+	}
+	return queues
+}
+
+// IsHealthCheckQueue reports whether name is the health check queue or one of its shards
+func IsHealthCheckQueue(name string) bool {
+	return name == HealthCheckQueue || strings.HasPrefix(name, HealthCheckQueue+":")
+}
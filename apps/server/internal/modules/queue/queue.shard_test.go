@@ -0,0 +1,44 @@
+package queue
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthCheckQueueName(t *testing.T) {
+	t.Run("unsharded", func(t *testing.T) {
+		assert.Equal(t, HealthCheckQueue, HealthCheckQueueName("mon-1", 0))
+		assert.Equal(t, HealthCheckQueue, HealthCheckQueueName("mon-1", 1))
+	})
+
+	t.Run("a monitor always lands in the same shard", func(t *testing.T) {
+		assert.Equal(t, HealthCheckQueueName("mon-1", 8), HealthCheckQueueName("mon-1", 8))
+	})
+
+	t.Run("monitors are spread over every shard", func(t *testing.T) {
+		queues := HealthCheckQueues(4)
+		seen := make(map[string]int)
+		for i := 0; i < 400; i++ {
+			name := HealthCheckQueueName(fmt.Sprintf("monitor-%d", i), 4)
+			assert.Contains(t, queues, name)
+			seen[name]++
+		}
+
+		assert.Len(t, seen, 4)
+		for name, count := range seen {
+			assert.Greater(t, count, 50, "shard %s only got %d of 400 monitors", name, count)
+		}
+	})
+}
+
+func TestHealthCheckQueues(t *testing.T) {
+	assert.Equal(t, map[string]int{"healthcheck": 5}, HealthCheckQueues(1))
+	assert.Equal(t, map[string]int{
+		"healthcheck":   5,
+		"healthcheck:0": 5,
+		"healthcheck:1": 5,
+		"healthcheck:2": 5,
+	}, HealthCheckQueues(3))
+}
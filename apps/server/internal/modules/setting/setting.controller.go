@@ -6,6 +6,8 @@ import (
 	"peekaping/internal/utils"
 
 	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -118,3 +120,63 @@ func (ic *Controller) DeleteByKey(ctx *gin.Context) {
 	}
 	ctx.JSON(http.StatusOK, utils.NewSuccessResponse[any]("Setting deleted successfully", nil))
 }
+
+// @Router	/settings/monitoring-paused [get]
+// @Summary	Get whether all monitoring is paused
+// @Tags		Settings
+// @Produce	json
+// @Security	JwtAuth
+// @Security	ApiKeyAuth
+// @Success	200	{object}	utils.ApiResponse[MonitoringPausedDto]
+// @Failure	500	{object}	utils.APIError[any]
+func (ic *Controller) GetMonitoringPaused(ctx *gin.Context) {
+	entity, err := ic.service.GetByKey(ctx, MonitoringPausedSettingKey)
+	if err != nil {
+		ic.logger.Errorw("Failed to fetch monitoring paused setting", "error", err)
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
+		return
+	}
+
+	paused := false
+	if entity != nil {
+		paused, _ = strconv.ParseBool(strings.TrimSpace(entity.Value))
+	}
+	ctx.JSON(http.StatusOK, utils.NewSuccessResponse("success", MonitoringPausedDto{Paused: &paused}))
+}
+
+// @Router	/settings/monitoring-paused [put]
+// @Summary	Pause or resume all monitoring
+// @Description	While paused no checks are enqueued. Schedules are kept and resume when unpaused.
+// @Tags		Settings
+// @Produce	json
+// @Accept	json
+// @Security	JwtAuth
+// @Security	ApiKeyAuth
+// @Param	body	body	MonitoringPausedDto	true	"Pause state"
+// @Success	200	{object}	utils.ApiResponse[MonitoringPausedDto]
+// @Failure	400	{object}	utils.APIError[any]
+// @Failure	500	{object}	utils.APIError[any]
+func (ic *Controller) SetMonitoringPaused(ctx *gin.Context) {
+	var dto MonitoringPausedDto
+	if err := ctx.ShouldBindJSON(&dto); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewFailResponse("Invalid request body"))
+		return
+	}
+	if err := utils.Validate.Struct(dto); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewFailResponse(err.Error()))
+		return
+	}
+
+	_, err := ic.service.SetByKey(ctx, MonitoringPausedSettingKey, &CreateUpdateDto{
+		Value: strconv.FormatBool(*dto.Paused),
+		Type:  "bool",
+	})
+	if err != nil {
+		ic.logger.Errorw("Failed to set monitoring paused setting", "error", err)
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
+		return
+	}
+
+	ic.logger.Infow("Global monitoring pause changed", "paused", *dto.Paused)
+	ctx.JSON(http.StatusOK, utils.NewSuccessResponse("monitoring pause updated successfully", dto))
+}
@@ -3,3 +3,7 @@ package setting
 import "peekaping/internal/modules/shared"
 
 type CreateUpdateDto = shared.SettingCreateUpdateDto
+
+type MonitoringPausedDto struct {
+	Paused *bool `json:"paused" validate:"required" example:"true"`
+}
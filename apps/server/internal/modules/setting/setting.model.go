@@ -3,3 +3,7 @@ package setting
 import "peekaping/internal/modules/shared"
 
 type Model = shared.SettingModel
+
+// MonitoringPausedSettingKey is the global switch that stops the producer from enqueuing
+// any check. Schedules are kept, so monitoring picks up where it left off once unpaused.
+const MonitoringPausedSettingKey = "monitoring_paused"
@@ -31,4 +31,7 @@ func (uc *Route) ConnectRoute(
 	router.GET("key/:key", uc.controller.GetByKey)
 	router.PUT("key/:key", uc.controller.SetByKey)
 	router.DELETE("key/:key", uc.controller.DeleteByKey)
+
+	router.GET("monitoring-paused", uc.controller.GetMonitoringPaused)
+	router.PUT("monitoring-paused", uc.controller.SetMonitoringPaused)
 }
@@ -5,7 +5,9 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"peekaping/internal/modules/maintenance"
 	"peekaping/internal/modules/shared"
+	"peekaping/internal/modules/stats"
 
 	"go.uber.org/zap"
 )
@@ -67,6 +69,26 @@ func (mr *ServiceImpl) InitializeSettings(ctx context.Context) error {
 		return fmt.Errorf("failed to initialize certificate expiry notification days: %w", err)
 	}
 
+	// Checks are skipped or recorded as maintenance during maintenance windows by default
+	if err := mr.initializeDefaultSetting(ctx, maintenance.CheckDuringMaintenanceSettingKey, "false", "bool"); err != nil {
+		return fmt.Errorf("failed to initialize check during maintenance setting: %w", err)
+	}
+
+	// Maintenance windows only wait for approval when they ask for it
+	if err := mr.initializeDefaultSetting(ctx, maintenance.RequireApprovalSettingKey, "false", "bool"); err != nil {
+		return fmt.Errorf("failed to initialize maintenance approval setting: %w", err)
+	}
+
+	// Uptime is the ratio of up checks to all checks unless switched to time-weighted
+	if err := mr.initializeDefaultSetting(ctx, stats.UptimeMethodSettingKey, string(stats.UptimeMethodCount), "string"); err != nil {
+		return fmt.Errorf("failed to initialize uptime calculation method: %w", err)
+	}
+
+	// Monitoring runs unless an admin pauses it globally
+	if err := mr.initializeDefaultSetting(ctx, MonitoringPausedSettingKey, "false", "bool"); err != nil {
+		return fmt.Errorf("failed to initialize monitoring paused setting: %w", err)
+	}
+
 	mr.logger.Info("Settings initialized successfully")
 	return nil
 }
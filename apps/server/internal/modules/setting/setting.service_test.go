@@ -293,6 +293,30 @@ func TestServiceImpl_InitializeSettings(t *testing.T) {
 				repo.On("SetByKey", mock.Anything, "cert_expiry_notify_days", mock.MatchedBy(func(dto *CreateUpdateDto) bool {
 					return dto.Value == "[7,14,21]" && dto.Type == "json"
 				})).Return(&Model{Key: "cert_expiry_notify_days", Value: "[7,14,21]", Type: "json"}, nil)
+
+				// check_during_maintenance - not exists
+				repo.On("GetByKey", mock.Anything, "check_during_maintenance").Return(nil, nil)
+				repo.On("SetByKey", mock.Anything, "check_during_maintenance", mock.MatchedBy(func(dto *CreateUpdateDto) bool {
+					return dto.Value == "false" && dto.Type == "bool"
+				})).Return(&Model{Key: "check_during_maintenance", Value: "false", Type: "bool"}, nil)
+
+				// maintenance_requires_approval - not exists
+				repo.On("GetByKey", mock.Anything, "maintenance_requires_approval").Return(nil, nil)
+				repo.On("SetByKey", mock.Anything, "maintenance_requires_approval", mock.MatchedBy(func(dto *CreateUpdateDto) bool {
+					return dto.Value == "false" && dto.Type == "bool"
+				})).Return(&Model{Key: "maintenance_requires_approval", Value: "false", Type: "bool"}, nil)
+
+				// uptime_calculation_method - not exists
+				repo.On("GetByKey", mock.Anything, "uptime_calculation_method").Return(nil, nil)
+				repo.On("SetByKey", mock.Anything, "uptime_calculation_method", mock.MatchedBy(func(dto *CreateUpdateDto) bool {
+					return dto.Value == "count" && dto.Type == "string"
+				})).Return(&Model{Key: "uptime_calculation_method", Value: "count", Type: "string"}, nil)
+
+				// monitoring_paused - not exists
+				repo.On("GetByKey", mock.Anything, "monitoring_paused").Return(nil, nil)
+				repo.On("SetByKey", mock.Anything, "monitoring_paused", mock.MatchedBy(func(dto *CreateUpdateDto) bool {
+					return dto.Value == "false" && dto.Type == "bool"
+				})).Return(&Model{Key: "monitoring_paused", Value: "false", Type: "bool"}, nil)
 			},
 			expectedError: nil,
 		},
@@ -322,6 +346,30 @@ func TestServiceImpl_InitializeSettings(t *testing.T) {
 				repo.On("SetByKey", mock.Anything, "cert_expiry_notify_days", mock.MatchedBy(func(dto *CreateUpdateDto) bool {
 					return dto.Value == "[7,14,21]" && dto.Type == "json"
 				})).Return(&Model{Key: "cert_expiry_notify_days", Value: "[7,14,21]", Type: "json"}, nil)
+
+				// check_during_maintenance - not exists
+				repo.On("GetByKey", mock.Anything, "check_during_maintenance").Return(nil, nil)
+				repo.On("SetByKey", mock.Anything, "check_during_maintenance", mock.MatchedBy(func(dto *CreateUpdateDto) bool {
+					return dto.Value == "false" && dto.Type == "bool"
+				})).Return(&Model{Key: "check_during_maintenance", Value: "false", Type: "bool"}, nil)
+
+				// maintenance_requires_approval - not exists
+				repo.On("GetByKey", mock.Anything, "maintenance_requires_approval").Return(nil, nil)
+				repo.On("SetByKey", mock.Anything, "maintenance_requires_approval", mock.MatchedBy(func(dto *CreateUpdateDto) bool {
+					return dto.Value == "false" && dto.Type == "bool"
+				})).Return(&Model{Key: "maintenance_requires_approval", Value: "false", Type: "bool"}, nil)
+
+				// uptime_calculation_method - not exists
+				repo.On("GetByKey", mock.Anything, "uptime_calculation_method").Return(nil, nil)
+				repo.On("SetByKey", mock.Anything, "uptime_calculation_method", mock.MatchedBy(func(dto *CreateUpdateDto) bool {
+					return dto.Value == "count" && dto.Type == "string"
+				})).Return(&Model{Key: "uptime_calculation_method", Value: "count", Type: "string"}, nil)
+
+				// monitoring_paused - not exists
+				repo.On("GetByKey", mock.Anything, "monitoring_paused").Return(nil, nil)
+				repo.On("SetByKey", mock.Anything, "monitoring_paused", mock.MatchedBy(func(dto *CreateUpdateDto) bool {
+					return dto.Value == "false" && dto.Type == "bool"
+				})).Return(&Model{Key: "monitoring_paused", Value: "false", Type: "bool"}, nil)
 			},
 			expectedError: nil,
 		},
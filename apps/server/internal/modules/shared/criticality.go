@@ -0,0 +1,34 @@
+package shared
+
+// Criticality levels of a monitor, used to route its notifications. Channels linked with a
+// minimum criticality only receive notifications of monitors at or above it.
+const (
+	CriticalityLow      = "low"
+	CriticalityMedium   = "medium"
+	CriticalityHigh     = "high"
+	CriticalityCritical = "critical"
+)
+
+var criticalityRanks = map[string]int{
+	CriticalityLow:      1,
+	CriticalityMedium:   2,
+	CriticalityHigh:     3,
+	CriticalityCritical: 4,
+}
+
+// CriticalityRank orders criticality levels, monitors without one count as medium
+func CriticalityRank(criticality string) int {
+	if rank, ok := criticalityRanks[criticality]; ok {
+		return rank
+	}
+	return criticalityRanks[CriticalityMedium]
+}
+
+// MeetsMinCriticality reports whether a monitor of the given criticality should notify a
+// channel linked with minCriticality. An empty minimum accepts every monitor.
+func MeetsMinCriticality(criticality, minCriticality string) bool {
+	if minCriticality == "" {
+		return true
+	}
+	return CriticalityRank(criticality) >= CriticalityRank(minCriticality)
+}
@@ -11,6 +11,22 @@ const (
 	MonitorStatusUp
 	MonitorStatusPending
 	MonitorStatusMaintenance
+	// MonitorStatusDegraded is reported by status pages for up monitors with a degraded
+	// check, heartbeats are never stored with it
+	MonitorStatusDegraded
+)
+
+// Error categories recorded on heartbeats to explain why a check failed
+const (
+	ErrorCategoryPortClosed   = "port_closed"   // connection refused
+	ErrorCategoryPortFiltered = "port_filtered" // no response before the timeout
+	ErrorCategoryNetwork      = "network_error" // DNS failure, unreachable host, etc.
+	ErrorCategoryDegraded     = "degraded"      // some of the resolved backends failed
+	ErrorCategoryNoHeartbeat  = "no_heartbeat"  // a push monitor stopped receiving heartbeats
+	ErrorCategoryBodyChanged  = "body_changed"  // the check passed but the response body changed
+	ErrorCategoryProxyDown    = "proxy_down"    // the monitor's proxy could not be reached
+	ErrorCategorySkipped      = "skipped"       // the previous check of a serialized monitor was still running
+	ErrorCategoryTLS          = "tls"           // the server's TLS configuration is insecure
 )
 
 type HeartBeatModel struct {
@@ -26,6 +42,15 @@ type HeartBeatModel struct {
 	Time      time.Time     `json:"time"`
 	EndTime   time.Time     `json:"end_time"`
 	Notified  bool          `json:"notified"`
+	// ErrorCategory classifies why a check failed (e.g. "port_closed"), empty when unknown
+	ErrorCategory string `json:"error_category,omitempty"`
+	// BodyHash is the hash of the normalized response body for monitors detecting body changes
+	BodyHash string `json:"body_hash,omitempty"`
+	// ResponseSize is the size in bytes of the decoded response body, HTTP monitors only
+	ResponseSize *int64 `json:"response_size,omitempty"`
+	// AlertsSuppressed is set on heartbeats of checks that ran inside a maintenance window,
+	// it travels with the heartbeat events and isn't stored
+	AlertsSuppressed bool `json:"alerts_suppressed,omitempty"`
 }
 
 type HeartBeatChartPoint struct {
@@ -28,9 +28,34 @@ type Monitor struct {
 	// Retry interval in seconds to do request to url
 	RetryInterval int `json:"retry_interval" example:"60"`
 
+	// RetryBackoff is fixed, linear or exponential and spreads the retries of a failing
+	// monitor, see RetryDelay
+	RetryBackoff string `json:"retry_backoff" example:"fixed"`
+
 	// Resend Notification if Down X times consecutively
 	ResendInterval int `json:"resend_interval" example:"10"`
 
+	// Suppress notifications for the first N checks after the monitor is created or activated
+	WarmupChecks int `json:"warmup_checks" example:"0"`
+
+	// Notify when response times stay above LatencyLimit milliseconds for LatencyChecks
+	// consecutive checks, 0 disables the alert
+	LatencyLimit  int `json:"latency_limit" example:"0"`
+	LatencyChecks int `json:"latency_checks" example:"0"`
+
+	// Criticality is low, medium, high or critical and decides which notification channels
+	// are notified, see MeetsMinCriticality
+	Criticality string `json:"criticality" example:"medium"`
+
+	// ResultExpression is an optional CEL expression the worker evaluates after each check
+	// to compute the final status and message, see executor.ResultExpression
+	ResultExpression string `json:"result_expression"`
+
+	// UpMessage and DownMessage are optional liquid templates replacing the executor's
+	// message of up and failed checks, see executor.MessageTemplate
+	UpMessage   string `json:"up_message"`
+	DownMessage string `json:"down_message"`
+
 	Active bool          `json:"active"`
 	Status MonitorStatus `json:"status"`
 
@@ -38,27 +63,70 @@ type Monitor struct {
 	ProxyId   string `json:"proxy_id"`
 	PushToken string `json:"push_token"`
 
+	// Proxies tried in order when ProxyId has recently failed
+	FallbackProxyIds []string `json:"fallback_proxy_ids"`
+
+	// Bypass the global default proxy when no proxy is set on the monitor
+	NoProxy bool `json:"no_proxy"`
+
+	// Never run two checks of the monitor at the same time, a check scheduled while the
+	// previous one still runs is skipped
+	Serialize bool `json:"serialize"`
+
+	// Confirm a check result that changes the monitor's state (up to down or back) with an
+	// immediate second check before it is recorded, so one-off blips don't notify
+	ConfirmTransition bool `json:"confirm_transition"`
+
+	// Monitor this one depends on. With SkipWhenParentDown the producer skips the checks
+	// while the parent is down, for at most ParentDownTimeout seconds (0 for as long as
+	// the parent stays down).
+	ParentId           string `json:"parent_id"`
+	SkipWhenParentDown bool   `json:"skip_when_parent_down"`
+	ParentDownTimeout  int    `json:"parent_down_timeout"`
+
 	// Last heartbeat for push monitors
 	LastHeartbeat *HeartBeatModel `json:"last_heartbeat,omitempty"`
 
+	// Latest heartbeat of each child of a group monitor, keyed by monitor ID
+	ChildHeartbeats map[string]*HeartBeatModel `json:"child_heartbeats,omitempty"`
+
+	// Time the monitor was last created or switched from inactive to active
+	ActivatedAt *time.Time `json:"activated_at,omitempty"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
 type UpdateMonitor struct {
-	ID             *string        `json:"id"`
-	Type           *string        `json:"type"`
-	Name           *string        `json:"name"`
-	Interval       *int           `json:"interval"`
-	Timeout        *int           `json:"timeout"`
-	MaxRetries     *int           `json:"max_retries"`
-	RetryInterval  *int           `json:"retry_interval"`
-	ResendInterval *int           `json:"resend_interval"`
-	Active         *bool          `json:"active"`
-	Status         *MonitorStatus `json:"status"`
-	Config         *string        `json:"config"`
-	ProxyId        *string        `json:"proxy_id"`
-	PushToken      *string        `json:"push_token"`
+	ID                 *string        `json:"id"`
+	Type               *string        `json:"type"`
+	Name               *string        `json:"name"`
+	Interval           *int           `json:"interval"`
+	Timeout            *int           `json:"timeout"`
+	MaxRetries         *int           `json:"max_retries"`
+	RetryInterval      *int           `json:"retry_interval"`
+	RetryBackoff       *string        `json:"retry_backoff"`
+	ResendInterval     *int           `json:"resend_interval"`
+	WarmupChecks       *int           `json:"warmup_checks"`
+	LatencyLimit       *int           `json:"latency_limit"`
+	LatencyChecks      *int           `json:"latency_checks"`
+	Criticality        *string        `json:"criticality"`
+	ResultExpression   *string        `json:"result_expression"`
+	UpMessage          *string        `json:"up_message"`
+	DownMessage        *string        `json:"down_message"`
+	Active             *bool          `json:"active"`
+	Status             *MonitorStatus `json:"status"`
+	Config             *string        `json:"config"`
+	ProxyId            *string        `json:"proxy_id"`
+	FallbackProxyIds   *[]string      `json:"fallback_proxy_ids"`
+	NoProxy            *bool          `json:"no_proxy"`
+	Serialize          *bool          `json:"serialize"`
+	ConfirmTransition  *bool          `json:"confirm_transition"`
+	ParentId           *string        `json:"parent_id"`
+	SkipWhenParentDown *bool          `json:"skip_when_parent_down"`
+	ParentDownTimeout  *int           `json:"parent_down_timeout"`
+	PushToken          *string        `json:"push_token"`
+	ActivatedAt        *time.Time     `json:"activated_at"`
 
 	CreatedAt *time.Time `json:"created_at"`
 	UpdatedAt *time.Time `json:"updated_at"`
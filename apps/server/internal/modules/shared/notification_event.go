@@ -0,0 +1,75 @@
+package shared
+
+// Notification event types a monitor-notification link can be limited to. Links without
+// any enabled type receive every event.
+const (
+	NotificationEventDown        = "down"
+	NotificationEventUp          = "up"
+	NotificationEventCertExpiry  = "cert_expiry"
+	NotificationEventDegraded    = "degraded"
+	NotificationEventFlapping    = "flapping"
+	NotificationEventHighLatency = "high_latency"
+	NotificationEventCertIssuer  = "cert_issuer_change"
+)
+
+// notificationEventTypes lists the event types in the order of their bit in the mask
+var notificationEventTypes = []string{
+	NotificationEventDown,
+	NotificationEventUp,
+	NotificationEventCertExpiry,
+	NotificationEventDegraded,
+	NotificationEventFlapping,
+	NotificationEventHighLatency,
+	NotificationEventCertIssuer,
+}
+
+// NotificationEventMask packs event types into the bitmask stored on a link. Unknown
+// types are ignored.
+func NotificationEventMask(eventTypes []string) int {
+	mask := 0
+	for _, eventType := range eventTypes {
+		for bit, known := range notificationEventTypes {
+			if eventType == known {
+				mask |= 1 << bit
+			}
+		}
+	}
+	return mask
+}
+
+// NotificationEventTypes unpacks a bitmask into its event types
+func NotificationEventTypes(mask int) []string {
+	var eventTypes []string
+	for bit, eventType := range notificationEventTypes {
+		if mask&(1<<bit) != 0 {
+			eventTypes = append(eventTypes, eventType)
+		}
+	}
+	return eventTypes
+}
+
+// NotificationEventEnabled reports whether eventType is one of eventTypes. An empty list
+// enables every event type.
+func NotificationEventEnabled(eventTypes []string, eventType string) bool {
+	if len(eventTypes) == 0 {
+		return true
+	}
+	for _, enabled := range eventTypes {
+		if enabled == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// HeartbeatNotificationEvent classifies a heartbeat notification. Up checks with a
+// degraded or changed response are degraded, other checks are up or down by status.
+func HeartbeatNotificationEvent(status MonitorStatus, errorCategory string) string {
+	if status != MonitorStatusUp {
+		return NotificationEventDown
+	}
+	if errorCategory == ErrorCategoryDegraded || errorCategory == ErrorCategoryBodyChanged {
+		return NotificationEventDegraded
+	}
+	return NotificationEventUp
+}
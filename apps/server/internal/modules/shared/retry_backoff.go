@@ -0,0 +1,33 @@
+package shared
+
+import "time"
+
+// Retry backoff strategies of a monitor, deciding how long it waits before each retry of a
+// failed check
+const (
+	RetryBackoffFixed       = "fixed"
+	RetryBackoffLinear      = "linear"
+	RetryBackoffExponential = "exponential"
+)
+
+// RetryDelay returns the delay before the given retry (1 for the first) of a monitor. Fixed
+// waits retryInterval every time, linear retryInterval times the attempt and exponential
+// doubles retryInterval with every attempt. The delay never exceeds the monitor's interval,
+// so a failing monitor isn't checked less often than a healthy one.
+func RetryDelay(backoff string, retryInterval, interval, attempt int) time.Duration {
+	delay := time.Duration(retryInterval) * time.Second
+	attempt = max(attempt, 1)
+
+	switch backoff {
+	case RetryBackoffLinear:
+		delay *= time.Duration(attempt)
+	case RetryBackoffExponential:
+		// Beyond 2^30 the delay is over any interval
+		delay <<= min(attempt-1, 30)
+	}
+
+	if limit := time.Duration(interval) * time.Second; limit > 0 && (delay > limit || delay <= 0) {
+		return limit
+	}
+	return delay
+}
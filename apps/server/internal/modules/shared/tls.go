@@ -34,4 +34,9 @@ type CertificateInfo struct {
 type TLSInfo struct {
 	Valid    bool             `json:"valid"`
 	CertInfo *CertificateInfo `json:"certInfo,omitempty"`
+	// ALPN is the application protocol negotiated with the server, e.g. "h2"
+	ALPN string `json:"alpn,omitempty"`
+	// SessionResumption and SecureRenegotiation are only set when the monitor probes them
+	SessionResumption   *bool `json:"sessionResumption,omitempty"`
+	SecureRenegotiation *bool `json:"secureRenegotiation,omitempty"`
 }
@@ -22,4 +22,37 @@ type Stat struct {
 	Up          int       `json:"up"`
 	Down        int       `json:"down"`
 	Maintenance int       `json:"maintenance"`
+	// Seconds spent in each state, credited from the duration of every heartbeat
+	UpSeconds          int64 `json:"up_seconds"`
+	DownSeconds        int64 `json:"down_seconds"`
+	MaintenanceSeconds int64 `json:"maintenance_seconds"`
+	// Response body size in bytes, averaged over the ResponseSizeCount heartbeats that
+	// reported one
+	ResponseSize      float64 `json:"response_size"`
+	ResponseSizeMin   float64 `json:"response_size_min"`
+	ResponseSizeMax   float64 `json:"response_size_max"`
+	ResponseSizeCount int     `json:"response_size_count"`
+}
+
+// UptimeMethod selects how uptime percentages are computed from stats
+type UptimeMethod string
+
+const (
+	// UptimeMethodCount is the ratio of up checks to all checks
+	UptimeMethodCount UptimeMethod = "count"
+	// UptimeMethodTime is the ratio of time spent up to the total time covered by checks
+	UptimeMethodTime UptimeMethod = "time"
+)
+
+// UptimeMethodSettingKey is the global setting holding the UptimeMethod, "count" by default
+const UptimeMethodSettingKey = "uptime_calculation_method"
+
+// DayUptime is the uptime of a monitor over one UTC day
+type DayUptime struct {
+	Date time.Time
+	// Uptime is the uptime percentage of the day, nil when the monitor wasn't checked
+	Uptime      *float64
+	Up          int
+	Down        int
+	Maintenance int
 }
@@ -81,15 +81,22 @@ func (r *MongoRepository) getStatCollection(period StatPeriod) *mongo.Collection
 }
 
 type mongoModel struct {
-	ID          primitive.ObjectID `bson:"_id"`
-	MonitorID   primitive.ObjectID `bson:"monitor_id"`
-	Timestamp   time.Time          `bson:"timestamp"`
-	Ping        float64            `bson:"ping"`
-	PingMin     float64            `bson:"ping_min"`
-	PingMax     float64            `bson:"ping_max"`
-	Up          int                `bson:"up"`
-	Down        int                `bson:"down"`
-	Maintenance int                `bson:"maintenance"`
+	ID                 primitive.ObjectID `bson:"_id"`
+	MonitorID          primitive.ObjectID `bson:"monitor_id"`
+	Timestamp          time.Time          `bson:"timestamp"`
+	Ping               float64            `bson:"ping"`
+	PingMin            float64            `bson:"ping_min"`
+	PingMax            float64            `bson:"ping_max"`
+	Up                 int                `bson:"up"`
+	Down               int                `bson:"down"`
+	Maintenance        int                `bson:"maintenance"`
+	UpSeconds          int64              `bson:"up_seconds"`
+	DownSeconds        int64              `bson:"down_seconds"`
+	MaintenanceSeconds int64              `bson:"maintenance_seconds"`
+	ResponseSize       float64            `bson:"response_size"`
+	ResponseSizeMin    float64            `bson:"response_size_min"`
+	ResponseSizeMax    float64            `bson:"response_size_max"`
+	ResponseSizeCount  int                `bson:"response_size_count"`
 }
 
 func toDomainModel(mm *mongoModel) *Stat {
@@ -103,6 +110,15 @@ func toDomainModel(mm *mongoModel) *Stat {
 		Up:          mm.Up,
 		Down:        mm.Down,
 		Maintenance: mm.Maintenance,
+
+		UpSeconds:          mm.UpSeconds,
+		DownSeconds:        mm.DownSeconds,
+		MaintenanceSeconds: mm.MaintenanceSeconds,
+
+		ResponseSize:      mm.ResponseSize,
+		ResponseSizeMin:   mm.ResponseSizeMin,
+		ResponseSizeMax:   mm.ResponseSizeMax,
+		ResponseSizeCount: mm.ResponseSizeCount,
 	}
 }
 
@@ -161,6 +177,15 @@ func (r *MongoRepository) UpsertStat(ctx context.Context, stat *Stat, period Sta
 		Up:          stat.Up,
 		Down:        stat.Down,
 		Maintenance: stat.Maintenance,
+
+		UpSeconds:          stat.UpSeconds,
+		DownSeconds:        stat.DownSeconds,
+		MaintenanceSeconds: stat.MaintenanceSeconds,
+
+		ResponseSize:      stat.ResponseSize,
+		ResponseSizeMin:   stat.ResponseSizeMin,
+		ResponseSizeMax:   stat.ResponseSizeMax,
+		ResponseSizeCount: stat.ResponseSizeCount,
 	}
 
 	filter := bson.M{"monitor_id": mm.MonitorID, "timestamp": mm.Timestamp}
@@ -173,6 +198,15 @@ func (r *MongoRepository) UpsertStat(ctx context.Context, stat *Stat, period Sta
 				"up":          mm.Up,
 				"down":        mm.Down,
 				"maintenance": mm.Maintenance,
+
+				"up_seconds":          mm.UpSeconds,
+				"down_seconds":        mm.DownSeconds,
+				"maintenance_seconds": mm.MaintenanceSeconds,
+
+				"response_size":       mm.ResponseSize,
+				"response_size_min":   mm.ResponseSizeMin,
+				"response_size_max":   mm.ResponseSizeMax,
+				"response_size_count": mm.ResponseSizeCount,
 			},
 		}
 	_, err = coll.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
@@ -6,6 +6,7 @@ import (
 	"peekaping/internal/infra"
 	"peekaping/internal/modules/events"
 	"peekaping/internal/modules/shared"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
@@ -16,6 +17,9 @@ type HeartbeatPayload struct {
 	Status    int
 	Ping      int
 	Time      int64 // Unix seconds
+	Duration  int   // Seconds since the previous heartbeat of the monitor
+	// ResponseSize is the response body size of HTTP checks, nil for other monitors
+	ResponseSize *int64
 }
 
 type Service interface {
@@ -23,17 +27,34 @@ type Service interface {
 	RegisterEventHandlers(eventBus events.EventBus)
 	FindStatsByMonitorIDAndTimeRange(ctx context.Context, monitorID string, since, until time.Time, period StatPeriod) ([]*Stat, error)
 	FindStatsByMonitorIDAndTimeRangeWithInterval(ctx context.Context, monitorID string, since, until time.Time, period StatPeriod, monitorInterval int) ([]*Stat, error)
-	StatPointsSummary(statsList []*Stat) *Stats
+	// StatPointsSummary summarizes stats, computing uptime with the configured UptimeMethod
+	StatPointsSummary(ctx context.Context, statsList []*Stat) *Stats
+	// DailyUptime returns the uptime of each of the last days UTC days up to now, oldest first
+	DailyUptime(ctx context.Context, monitorID string, days int, now time.Time) ([]*DayUptime, error)
 	DeleteByMonitorID(ctx context.Context, monitorID string) error
 }
 
+// uptimeMethodRefresh is how long the uptime method setting is cached, so summaries
+// don't read the settings on every request
+const uptimeMethodRefresh = 10 * time.Second
+
 type ServiceImpl struct {
-	repo   Repository
-	logger *zap.SugaredLogger
+	repo           Repository
+	settingService shared.SettingService
+	logger         *zap.SugaredLogger
+
+	uptimeMethodMu        sync.Mutex
+	uptimeMethodCached    UptimeMethod // last read uptime_calculation_method setting
+	uptimeMethodCheckedAt time.Time    // when uptimeMethodCached was last read from the settings
 }
 
-func NewService(repo Repository, logger *zap.SugaredLogger) Service {
-	return &ServiceImpl{repo, logger.Named("[stats-service]")}
+func NewService(repo Repository, settingService shared.SettingService, logger *zap.SugaredLogger) Service {
+	return &ServiceImpl{
+		repo:               repo,
+		settingService:     settingService,
+		logger:             logger.Named("[stats-service]"),
+		uptimeMethodCached: UptimeMethodCount,
+	}
 }
 
 func (s *ServiceImpl) flatStatus(status int) int {
@@ -70,6 +91,7 @@ func (s *ServiceImpl) AggregateHeartbeat(ctx context.Context, hb *HeartbeatPaylo
 		// Up/Down logic (flattened)
 		if s.flatStatus(hb.Status) == 1 { // MonitorStatusUp
 			statToUpsert.Up = stat.Up + 1
+			statToUpsert.UpSeconds = stat.UpSeconds + int64(hb.Duration)
 			// Only update ping stats for true UP
 			if hb.Status == 1 { // MonitorStatusUp
 				fPing := float64(hb.Ping)
@@ -97,11 +119,28 @@ func (s *ServiceImpl) AggregateHeartbeat(ctx context.Context, hb *HeartbeatPaylo
 			}
 		} else if s.flatStatus(hb.Status) == 0 { // MonitorStatusDown
 			statToUpsert.Down = stat.Down + 1
+			statToUpsert.DownSeconds = stat.DownSeconds + int64(hb.Duration)
 		}
 
 		// Aggregate maintenance status separately
 		if hb.Status == 3 { // MonitorStatusMaintenance
 			statToUpsert.Maintenance = stat.Maintenance + 1
+			statToUpsert.MaintenanceSeconds = stat.MaintenanceSeconds + int64(hb.Duration)
+		}
+
+		// Response sizes are kept whatever the status, a truncated body may be why the
+		// check failed
+		if hb.ResponseSize != nil {
+			size := float64(*hb.ResponseSize)
+			count := stat.ResponseSizeCount
+			statToUpsert.ResponseSizeCount = count + 1
+			statToUpsert.ResponseSize = (stat.ResponseSize*float64(count) + size) / float64(count+1)
+			if count == 0 || size < stat.ResponseSizeMin {
+				statToUpsert.ResponseSizeMin = size
+			}
+			if count == 0 || size > stat.ResponseSizeMax {
+				statToUpsert.ResponseSizeMax = size
+			}
 		}
 
 		// Upsert stat
@@ -124,6 +163,9 @@ func (s *ServiceImpl) RegisterEventHandlers(eventBus events.EventBus) {
 			Status:    int(payload.Status),
 			Ping:      payload.Ping,
 			Time:      payload.Time.Unix(),
+			Duration:  payload.Duration,
+
+			ResponseSize: payload.ResponseSize,
 		}
 		_ = s.AggregateHeartbeat(context.Background(), hb)
 	})
@@ -268,13 +310,30 @@ func (s *ServiceImpl) aggregateStats(stats []*Stat, timestamp time.Time, monitor
 
 	var totalPing, minPing, maxPing float64
 	var totalUp, totalDown, totalMaintenance int
+	var upSeconds, downSeconds, maintenanceSeconds int64
 	var pingCount int
 	var hasValidPing bool
+	var totalSize, minSize, maxSize float64
+	var sizeCount int
 
 	for _, stat := range stats {
 		totalUp += stat.Up
 		totalDown += stat.Down
 		totalMaintenance += stat.Maintenance
+		upSeconds += stat.UpSeconds
+		downSeconds += stat.DownSeconds
+		maintenanceSeconds += stat.MaintenanceSeconds
+
+		if stat.ResponseSizeCount > 0 {
+			if sizeCount == 0 || stat.ResponseSizeMin < minSize {
+				minSize = stat.ResponseSizeMin
+			}
+			if sizeCount == 0 || stat.ResponseSizeMax > maxSize {
+				maxSize = stat.ResponseSizeMax
+			}
+			totalSize += stat.ResponseSize * float64(stat.ResponseSizeCount)
+			sizeCount += stat.ResponseSizeCount
+		}
 
 		// Only include stats with valid ping values (> 0) for ping calculations
 		if stat.Up > 0 && stat.Ping > 0 {
@@ -302,6 +361,11 @@ func (s *ServiceImpl) aggregateStats(stats []*Stat, timestamp time.Time, monitor
 		avgPing = totalPing / float64(pingCount)
 	}
 
+	avgSize := 0.0
+	if sizeCount > 0 {
+		avgSize = totalSize / float64(sizeCount)
+	}
+
 	// Set min/max ping to 0 if no valid ping data
 	if !hasValidPing {
 		minPing = 0
@@ -318,6 +382,15 @@ func (s *ServiceImpl) aggregateStats(stats []*Stat, timestamp time.Time, monitor
 		Up:          totalUp,
 		Down:        totalDown,
 		Maintenance: totalMaintenance,
+
+		UpSeconds:          upSeconds,
+		DownSeconds:        downSeconds,
+		MaintenanceSeconds: maintenanceSeconds,
+
+		ResponseSize:      avgSize,
+		ResponseSizeMin:   minSize,
+		ResponseSizeMax:   maxSize,
+		ResponseSizeCount: sizeCount,
 	}
 }
 
@@ -328,17 +401,70 @@ type Stats struct {
 	AvgPing     *float64 `json:"avgPing"`
 	Uptime      *float64 `json:"uptime"`
 	Maintenance *float64 `json:"maintenance"`
+	// Response body sizes in bytes, nil when no heartbeat reported one
+	MaxResponseSize *float64 `json:"maxResponseSize"`
+	MinResponseSize *float64 `json:"minResponseSize"`
+	AvgResponseSize *float64 `json:"avgResponseSize"`
+	// UptimeMethod is the method Uptime and Maintenance were computed with
+	UptimeMethod UptimeMethod `json:"uptimeMethod"`
 }
 
 // StatPointsSummary computes stat points and summary for a period using flatStatus logic
-func (s *ServiceImpl) StatPointsSummary(statsList []*Stat) *Stats {
+func (s *ServiceImpl) StatPointsSummary(ctx context.Context, statsList []*Stat) *Stats {
+	return summarizeStats(statsList, s.uptimeMethod(ctx))
+}
+
+// uptimeMethod returns the configured uptime method, falling back to count-based. The
+// setting is read at most once per uptimeMethodRefresh.
+func (s *ServiceImpl) uptimeMethod(ctx context.Context) UptimeMethod {
+	s.uptimeMethodMu.Lock()
+	defer s.uptimeMethodMu.Unlock()
+
+	if !s.uptimeMethodCheckedAt.IsZero() && time.Since(s.uptimeMethodCheckedAt) < uptimeMethodRefresh {
+		return s.uptimeMethodCached
+	}
+	s.uptimeMethodCheckedAt = time.Now()
+
+	setting, err := s.settingService.GetByKey(ctx, UptimeMethodSettingKey)
+	if err != nil {
+		// Keep the last known method rather than switching on a transient error
+		s.logger.Warnw("Failed to read uptime method setting", "error", err)
+		return s.uptimeMethodCached
+	}
+	s.uptimeMethodCached = UptimeMethodCount
+	if setting != nil && UptimeMethod(setting.Value) == UptimeMethodTime {
+		s.uptimeMethodCached = UptimeMethodTime
+	}
+	return s.uptimeMethodCached
+}
+
+// summarizeStats computes the summary with the given uptime method. Time-weighted uptime
+// needs heartbeat durations, stats recorded before they were tracked have none, in which
+// case the count-based method is used and reported instead.
+func summarizeStats(statsList []*Stat, method UptimeMethod) *Stats {
 	var maxPing *float64
 	var minPing *float64
 	var sumPing float64
 	var upCount int
 	var totalUp, totalDown, totalMaintenance int
+	var upSeconds, downSeconds, maintenanceSeconds int64
+	var maxSize, minSize *float64
+	var sumSize float64
+	var sizeCount int
 
 	for _, s := range statsList {
+		if s.ResponseSizeCount > 0 {
+			if maxSize == nil || s.ResponseSizeMax > *maxSize {
+				v := s.ResponseSizeMax
+				maxSize = &v
+			}
+			if minSize == nil || s.ResponseSizeMin < *minSize {
+				v := s.ResponseSizeMin
+				minSize = &v
+			}
+			sumSize += s.ResponseSize * float64(s.ResponseSizeCount)
+			sizeCount += s.ResponseSizeCount
+		}
 		if s.Up > 0 {
 			if maxPing == nil || s.PingMax > *maxPing {
 				v := s.PingMax
@@ -354,6 +480,9 @@ func (s *ServiceImpl) StatPointsSummary(statsList []*Stat) *Stats {
 		totalUp += s.Up
 		totalDown += s.Down
 		totalMaintenance += s.Maintenance
+		upSeconds += s.UpSeconds
+		downSeconds += s.DownSeconds
+		maintenanceSeconds += s.MaintenanceSeconds
 	}
 
 	var avgPing *float64
@@ -362,24 +491,80 @@ func (s *ServiceImpl) StatPointsSummary(statsList []*Stat) *Stats {
 		avgPing = &v
 	}
 
+	var avgSize *float64
+	if sizeCount > 0 {
+		v := sumSize / float64(sizeCount)
+		avgSize = &v
+	}
+
+	upAmount := float64(totalUp)
+	maintenanceAmount := float64(totalMaintenance)
+	total := float64(totalUp + totalDown + totalMaintenance)
+	if method == UptimeMethodTime {
+		if totalSeconds := upSeconds + downSeconds + maintenanceSeconds; totalSeconds > 0 {
+			upAmount = float64(upSeconds)
+			maintenanceAmount = float64(maintenanceSeconds)
+			total = float64(totalSeconds)
+		} else {
+			method = UptimeMethodCount
+		}
+	}
+
 	var uptime *float64
 	var maintenance *float64
-	total := totalUp + totalDown + totalMaintenance
 	if total > 0 {
-		uptimeV := float64(totalUp) / float64(total) * 100
+		uptimeV := upAmount / total * 100
 		uptime = &uptimeV
 
-		maintenanceV := float64(totalMaintenance) / float64(total) * 100
+		maintenanceV := maintenanceAmount / total * 100
 		maintenance = &maintenanceV
 	}
 
 	return &Stats{
-		MaxPing:     maxPing,
-		MinPing:     minPing,
-		AvgPing:     avgPing,
-		Uptime:      uptime,
-		Maintenance: maintenance,
+		MaxPing:      maxPing,
+		MinPing:      minPing,
+		AvgPing:      avgPing,
+		Uptime:       uptime,
+		Maintenance:  maintenance,
+		UptimeMethod: method,
+
+		MaxResponseSize: maxSize,
+		MinResponseSize: minSize,
+		AvgResponseSize: avgSize,
+	}
+}
+
+// DailyUptime computes the uptime of each day from the daily stats with the configured
+// UptimeMethod, so a 90 day history is a single query of at most 90 rows
+func (s *ServiceImpl) DailyUptime(ctx context.Context, monitorID string, days int, now time.Time) ([]*DayUptime, error) {
+	const day = 24 * time.Hour
+	today := now.UTC().Truncate(day)
+	since := today.AddDate(0, 0, 1-days)
+
+	points, err := s.repo.FindStatsByMonitorIDAndTimeRange(ctx, monitorID, since, now, StatDaily)
+	if err != nil {
+		return nil, err
+	}
+
+	byDay := make(map[int64]*Stat, len(points))
+	for _, point := range points {
+		byDay[point.Timestamp.Truncate(day).Unix()] = point
+	}
+
+	method := s.uptimeMethod(ctx)
+	result := make([]*DayUptime, 0, days)
+	for date := since; !date.After(today); date = date.AddDate(0, 0, 1) {
+		dayUptime := &DayUptime{Date: date}
+		if point, ok := byDay[date.Unix()]; ok {
+			dayUptime.Up = point.Up
+			dayUptime.Down = point.Down
+			dayUptime.Maintenance = point.Maintenance
+			dayUptime.Uptime = summarizeStats([]*Stat{point}, method).Uptime
+		}
+		result = append(result, dayUptime)
 	}
+
+	return result, nil
 }
 
 func (s *ServiceImpl) DeleteByMonitorID(ctx context.Context, monitorID string) error {
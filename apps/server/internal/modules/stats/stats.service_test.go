@@ -0,0 +1,312 @@
+package stats
+
+import (
+	"context"
+	"peekaping/internal/modules/shared"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) GetOrCreateStat(ctx context.Context, monitorID string, timestamp time.Time, period StatPeriod) (*Stat, error) {
+	args := m.Called(ctx, monitorID, timestamp, period)
+	return args.Get(0).(*Stat), args.Error(1)
+}
+
+func (m *MockRepository) UpsertStat(ctx context.Context, stat *Stat, period StatPeriod) error {
+	args := m.Called(ctx, stat, period)
+	return args.Error(0)
+}
+
+func (m *MockRepository) FindStatsByMonitorIDAndTimeRange(ctx context.Context, monitorID string, since, until time.Time, period StatPeriod) ([]*Stat, error) {
+	args := m.Called(ctx, monitorID, since, until, period)
+	return args.Get(0).([]*Stat), args.Error(1)
+}
+
+func (m *MockRepository) DeleteByMonitorID(ctx context.Context, monitorID string) error {
+	args := m.Called(ctx, monitorID)
+	return args.Error(0)
+}
+
+type MockSettingService struct {
+	mock.Mock
+}
+
+func (m *MockSettingService) GetByKey(ctx context.Context, key string) (*shared.SettingModel, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*shared.SettingModel), args.Error(1)
+}
+
+func (m *MockSettingService) SetByKey(ctx context.Context, key string, entity *shared.SettingCreateUpdateDto) (*shared.SettingModel, error) {
+	args := m.Called(ctx, key, entity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*shared.SettingModel), args.Error(1)
+}
+
+func (m *MockSettingService) DeleteByKey(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockSettingService) InitializeSettings(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+// irregularStats describes a monitor checked every 10s while up and every 5 minutes
+// while down: 30 up checks cover 300s and 2 down checks cover 600s
+func irregularStats() []*Stat {
+	return []*Stat{
+		{Up: 18, UpSeconds: 180, Ping: 20, PingMin: 10, PingMax: 30},
+		{Down: 2, DownSeconds: 600},
+		{Up: 12, UpSeconds: 120, Ping: 20, PingMin: 15, PingMax: 25},
+	}
+}
+
+func TestSummarizeStats_CountVsTime(t *testing.T) {
+	count := summarizeStats(irregularStats(), UptimeMethodCount)
+	timeWeighted := summarizeStats(irregularStats(), UptimeMethodTime)
+
+	require.NotNil(t, count.Uptime)
+	require.NotNil(t, timeWeighted.Uptime)
+
+	// 30 of 32 checks were up
+	assert.InDelta(t, 93.75, *count.Uptime, 0.001)
+	assert.Equal(t, UptimeMethodCount, count.UptimeMethod)
+
+	// but the monitor was up for only 300 of 900 seconds
+	assert.InDelta(t, 33.333, *timeWeighted.Uptime, 0.001)
+	assert.Equal(t, UptimeMethodTime, timeWeighted.UptimeMethod)
+
+	// Ping figures do not depend on the uptime method
+	assert.Equal(t, *count.AvgPing, *timeWeighted.AvgPing)
+	assert.Equal(t, *count.MinPing, *timeWeighted.MinPing)
+	assert.Equal(t, *count.MaxPing, *timeWeighted.MaxPing)
+}
+
+func TestSummarizeStats_RegularIntervalsAgree(t *testing.T) {
+	statsList := []*Stat{
+		{Up: 9, UpSeconds: 540},
+		{Down: 1, DownSeconds: 60},
+	}
+
+	count := summarizeStats(statsList, UptimeMethodCount)
+	timeWeighted := summarizeStats(statsList, UptimeMethodTime)
+
+	assert.InDelta(t, 90.0, *count.Uptime, 0.001)
+	assert.InDelta(t, 90.0, *timeWeighted.Uptime, 0.001)
+}
+
+func TestSummarizeStats_TimeWithoutDurationsFallsBackToCount(t *testing.T) {
+	statsList := []*Stat{{Up: 3}, {Down: 1}}
+
+	summary := summarizeStats(statsList, UptimeMethodTime)
+
+	assert.InDelta(t, 75.0, *summary.Uptime, 0.001)
+	assert.Equal(t, UptimeMethodCount, summary.UptimeMethod)
+}
+
+func TestServiceImpl_StatPointsSummary_UsesSetting(t *testing.T) {
+	tests := []struct {
+		name           string
+		setting        *shared.SettingModel
+		expectedMethod UptimeMethod
+		expectedUptime float64
+	}{
+		{
+			name:           "time-weighted",
+			setting:        &shared.SettingModel{Key: UptimeMethodSettingKey, Value: "time"},
+			expectedMethod: UptimeMethodTime,
+			expectedUptime: 300.0 / 900.0 * 100,
+		},
+		{
+			name:           "count-based",
+			setting:        &shared.SettingModel{Key: UptimeMethodSettingKey, Value: "count"},
+			expectedMethod: UptimeMethodCount,
+			expectedUptime: 93.75,
+		},
+		{
+			name:           "setting missing",
+			setting:        nil,
+			expectedMethod: UptimeMethodCount,
+			expectedUptime: 93.75,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settingService := &MockSettingService{}
+			settingService.On("GetByKey", mock.Anything, UptimeMethodSettingKey).Return(tt.setting, nil)
+			service := NewService(&MockRepository{}, settingService, zap.NewNop().Sugar())
+
+			summary := service.StatPointsSummary(context.Background(), irregularStats())
+
+			assert.Equal(t, tt.expectedMethod, summary.UptimeMethod)
+			assert.InDelta(t, tt.expectedUptime, *summary.Uptime, 0.001)
+		})
+	}
+}
+
+func TestServiceImpl_StatPointsSummary_CachesUptimeMethod(t *testing.T) {
+	settingService := &MockSettingService{}
+	settingService.On("GetByKey", mock.Anything, UptimeMethodSettingKey).
+		Return(&shared.SettingModel{Key: UptimeMethodSettingKey, Value: "time"}, nil)
+	service := NewService(&MockRepository{}, settingService, zap.NewNop().Sugar()).(*ServiceImpl)
+
+	for i := 0; i < 3; i++ {
+		summary := service.StatPointsSummary(context.Background(), irregularStats())
+		assert.Equal(t, UptimeMethodTime, summary.UptimeMethod)
+	}
+	settingService.AssertNumberOfCalls(t, "GetByKey", 1)
+
+	// The setting is read again once the cached value expires
+	service.uptimeMethodCheckedAt = time.Now().Add(-uptimeMethodRefresh)
+	service.StatPointsSummary(context.Background(), irregularStats())
+	settingService.AssertNumberOfCalls(t, "GetByKey", 2)
+}
+
+func TestServiceImpl_AggregateHeartbeat_TracksDurations(t *testing.T) {
+	repo := &MockRepository{}
+	service := NewService(repo, &MockSettingService{}, zap.NewNop().Sugar())
+
+	existing := &Stat{MonitorID: "monitor-1", Up: 1, UpSeconds: 60, Down: 1, DownSeconds: 30}
+	repo.On("GetOrCreateStat", mock.Anything, "monitor-1", mock.Anything, mock.Anything).Return(existing, nil)
+
+	var upserted []*Stat
+	repo.On("UpsertStat", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		upserted = append(upserted, args.Get(1).(*Stat))
+	}).Return(nil)
+
+	err := service.(*ServiceImpl).AggregateHeartbeat(context.Background(), &HeartbeatPayload{
+		MonitorID: "monitor-1",
+		Status:    int(shared.MonitorStatusDown),
+		Time:      time.Now().Unix(),
+		Duration:  45,
+	})
+
+	require.NoError(t, err)
+	require.Len(t, upserted, 3)
+	for _, stat := range upserted {
+		assert.Equal(t, int64(60), stat.UpSeconds)
+		assert.Equal(t, int64(75), stat.DownSeconds)
+		assert.Equal(t, 2, stat.Down)
+	}
+}
+
+func TestServiceImpl_AggregateHeartbeat_TracksResponseSize(t *testing.T) {
+	repo := &MockRepository{}
+	service := NewService(repo, &MockSettingService{}, zap.NewNop().Sugar())
+
+	stat := &Stat{MonitorID: "monitor-1"}
+	repo.On("GetOrCreateStat", mock.Anything, "monitor-1", mock.Anything, StatMinutely).Return(stat, nil)
+	repo.On("GetOrCreateStat", mock.Anything, "monitor-1", mock.Anything, mock.Anything).Return(&Stat{MonitorID: "monitor-1"}, nil)
+	repo.On("UpsertStat", mock.Anything, mock.Anything, StatMinutely).Run(func(args mock.Arguments) {
+		*stat = *args.Get(1).(*Stat)
+	}).Return(nil)
+	repo.On("UpsertStat", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	sizes := []int64{300, 100, 200}
+	for _, size := range sizes {
+		err := service.(*ServiceImpl).AggregateHeartbeat(context.Background(), &HeartbeatPayload{
+			MonitorID:    "monitor-1",
+			Status:       int(shared.MonitorStatusUp),
+			Time:         time.Now().Unix(),
+			ResponseSize: &size,
+		})
+		require.NoError(t, err)
+	}
+	// A heartbeat without a size leaves the series alone
+	err := service.(*ServiceImpl).AggregateHeartbeat(context.Background(), &HeartbeatPayload{
+		MonitorID: "monitor-1",
+		Status:    int(shared.MonitorStatusDown),
+		Time:      time.Now().Unix(),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, stat.ResponseSizeCount)
+	assert.Equal(t, 200.0, stat.ResponseSize)
+	assert.Equal(t, 100.0, stat.ResponseSizeMin)
+	assert.Equal(t, 300.0, stat.ResponseSizeMax)
+}
+
+func TestSummarizeStats_ResponseSize(t *testing.T) {
+	summary := summarizeStats([]*Stat{
+		{Up: 2, ResponseSize: 100, ResponseSizeMin: 90, ResponseSizeMax: 110, ResponseSizeCount: 2},
+		{Down: 1},
+		{Up: 1, Down: 1, ResponseSize: 400, ResponseSizeMin: 300, ResponseSizeMax: 500, ResponseSizeCount: 2},
+	}, UptimeMethodCount)
+
+	require.NotNil(t, summary.AvgResponseSize)
+	assert.Equal(t, 250.0, *summary.AvgResponseSize)
+	assert.Equal(t, 90.0, *summary.MinResponseSize)
+	assert.Equal(t, 500.0, *summary.MaxResponseSize)
+
+	summary = summarizeStats([]*Stat{{Up: 3, Ping: 10}}, UptimeMethodCount)
+	assert.Nil(t, summary.AvgResponseSize)
+	assert.Nil(t, summary.MinResponseSize)
+	assert.Nil(t, summary.MaxResponseSize)
+}
+
+func TestServiceImpl_DailyUptime(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 10, 6, 15, 30, 0, 0, time.UTC)
+	day := func(d int) time.Time { return time.Date(2025, 10, d, 0, 0, 0, 0, time.UTC) }
+
+	repo := &MockRepository{}
+	repo.On("FindStatsByMonitorIDAndTimeRange", ctx, "mon-1", day(3), now, StatDaily).Return([]*Stat{
+		// A full day up
+		{Timestamp: day(3), Up: 1440, UpSeconds: 86400},
+		// Down for 6 of 24 hours, checked every minute while up and every 5 minutes while down
+		{Timestamp: day(5), Up: 1080, Down: 72, UpSeconds: 64800, DownSeconds: 21600},
+		// Today, down for the last half hour
+		{Timestamp: day(6), Up: 900, Down: 30, UpSeconds: 54000, DownSeconds: 1800},
+	}, nil)
+
+	tests := []struct {
+		name     string
+		setting  *shared.SettingModel
+		expected []float64
+	}{
+		{"count-based", nil, []float64{100, 0, 1080.0 / 1152 * 100, 900.0 / 930 * 100}},
+		{"time-weighted", &shared.SettingModel{Key: UptimeMethodSettingKey, Value: "time"}, []float64{100, 0, 75, 54000.0 / 55800 * 100}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settingService := &MockSettingService{}
+			settingService.On("GetByKey", mock.Anything, UptimeMethodSettingKey).Return(tt.setting, nil)
+			service := NewService(repo, settingService, zap.NewNop().Sugar())
+
+			days, err := service.DailyUptime(ctx, "mon-1", 4, now)
+			require.NoError(t, err)
+			require.Len(t, days, 4)
+
+			for i, d := range days {
+				assert.Equal(t, day(3+i), d.Date)
+				if i == 1 {
+					// No checks on the 4th
+					assert.Nil(t, d.Uptime)
+					assert.Zero(t, d.Up+d.Down+d.Maintenance)
+					continue
+				}
+				require.NotNil(t, d.Uptime, "day %d", i)
+				assert.InDelta(t, tt.expected[i], *d.Uptime, 0.001, "day %d", i)
+			}
+			assert.Equal(t, 72, days[2].Down)
+			settingService.AssertNumberOfCalls(t, "GetByKey", 1)
+		})
+	}
+}
@@ -11,17 +11,24 @@ import (
 type sqlModel struct {
 	bun.BaseModel `bun:"table:stats,alias:s"`
 
-	ID          string    `bun:"id,pk"`
-	MonitorID   string    `bun:"monitor_id,notnull"`
-	Timestamp   time.Time `bun:"timestamp,notnull"`
-	Ping        float64   `bun:"ping,notnull,default:0"`
-	PingMin     float64   `bun:"ping_min,notnull,default:0"`
-	PingMax     float64   `bun:"ping_max,notnull,default:0"`
-	Up          int       `bun:"up,notnull,default:0"`
-	Down        int       `bun:"down,notnull,default:0"`
-	Maintenance int       `bun:"maintenance,notnull,default:0"`
-	CreatedAt   time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
-	UpdatedAt   time.Time `bun:"updated_at,nullzero,notnull,default:current_timestamp"`
+	ID                 string    `bun:"id,pk"`
+	MonitorID          string    `bun:"monitor_id,notnull"`
+	Timestamp          time.Time `bun:"timestamp,notnull"`
+	Ping               float64   `bun:"ping,notnull,default:0"`
+	PingMin            float64   `bun:"ping_min,notnull,default:0"`
+	PingMax            float64   `bun:"ping_max,notnull,default:0"`
+	Up                 int       `bun:"up,notnull,default:0"`
+	Down               int       `bun:"down,notnull,default:0"`
+	Maintenance        int       `bun:"maintenance,notnull,default:0"`
+	UpSeconds          int64     `bun:"up_seconds,notnull,default:0"`
+	DownSeconds        int64     `bun:"down_seconds,notnull,default:0"`
+	MaintenanceSeconds int64     `bun:"maintenance_seconds,notnull,default:0"`
+	ResponseSize       float64   `bun:"response_size,notnull,default:0"`
+	ResponseSizeMin    float64   `bun:"response_size_min,notnull,default:0"`
+	ResponseSizeMax    float64   `bun:"response_size_max,notnull,default:0"`
+	ResponseSizeCount  int       `bun:"response_size_count,notnull,default:0"`
+	CreatedAt          time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+	UpdatedAt          time.Time `bun:"updated_at,nullzero,notnull,default:current_timestamp"`
 }
 
 func toDomainModelFromSQL(sm *sqlModel) *Stat {
@@ -35,6 +42,15 @@ func toDomainModelFromSQL(sm *sqlModel) *Stat {
 		Up:          sm.Up,
 		Down:        sm.Down,
 		Maintenance: sm.Maintenance,
+
+		UpSeconds:          sm.UpSeconds,
+		DownSeconds:        sm.DownSeconds,
+		MaintenanceSeconds: sm.MaintenanceSeconds,
+
+		ResponseSize:      sm.ResponseSize,
+		ResponseSizeMin:   sm.ResponseSizeMin,
+		ResponseSizeMax:   sm.ResponseSizeMax,
+		ResponseSizeCount: sm.ResponseSizeCount,
 	}
 }
 
@@ -49,6 +65,15 @@ func toSQLModel(s *Stat) *sqlModel {
 		Up:          s.Up,
 		Down:        s.Down,
 		Maintenance: s.Maintenance,
+
+		UpSeconds:          s.UpSeconds,
+		DownSeconds:        s.DownSeconds,
+		MaintenanceSeconds: s.MaintenanceSeconds,
+
+		ResponseSize:      s.ResponseSize,
+		ResponseSizeMin:   s.ResponseSizeMin,
+		ResponseSizeMax:   s.ResponseSizeMax,
+		ResponseSizeCount: s.ResponseSizeCount,
 	}
 }
 
@@ -110,6 +135,13 @@ func (r *SQLRepositoryImpl) UpsertStat(ctx context.Context, stat *Stat, period S
 		Set("up = ?", sm.Up).
 		Set("down = ?", sm.Down).
 		Set("maintenance = ?", sm.Maintenance).
+		Set("up_seconds = ?", sm.UpSeconds).
+		Set("down_seconds = ?", sm.DownSeconds).
+		Set("maintenance_seconds = ?", sm.MaintenanceSeconds).
+		Set("response_size = ?", sm.ResponseSize).
+		Set("response_size_min = ?", sm.ResponseSizeMin).
+		Set("response_size_max = ?", sm.ResponseSizeMax).
+		Set("response_size_count = ?", sm.ResponseSizeCount).
 		Set("updated_at = ?", sm.UpdatedAt).
 		Exec(ctx)
 
@@ -0,0 +1,94 @@
+package status_page
+
+import (
+	"fmt"
+	"net/http"
+	"peekaping/internal/config"
+	"peekaping/internal/modules/bruteforce"
+	"peekaping/internal/utils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// StatusPagePasswordHeader carries the password of a protected status page, HTTP
+// basic auth with any user name is accepted as well
+const StatusPagePasswordHeader = "X-Status-Page-Password"
+
+// AccessGuard unlocks protected status pages for the public endpoints of every module
+// serving them, so they share the password attempt limit and the session cookie
+type AccessGuard struct {
+	service           Service
+	bruteforceService bruteforce.Service
+	cfg               *config.Config
+	logger            *zap.SugaredLogger
+}
+
+func NewAccessGuard(service Service, bruteforceService bruteforce.Service, cfg *config.Config, logger *zap.SugaredLogger) *AccessGuard {
+	return &AccessGuard{
+		service:           service,
+		bruteforceService: bruteforceService,
+		cfg:               cfg,
+		logger:            logger,
+	}
+}
+
+// Authorize checks the session cookie or the password of a protected page and responds
+// with 401 when both are missing or wrong. Wrong passwords are counted per client IP and
+// page like login attempts, a client over the limit gets 429 without the password being
+// checked. An accepted password sets the session cookie of the page.
+func (g *AccessGuard) Authorize(ctx *gin.Context, page *Model) bool {
+	if page.PasswordHash == "" {
+		return true
+	}
+	if token, err := ctx.Cookie(sessionCookieName(page)); err == nil && verifySessionToken(page, token, time.Now()) {
+		return true
+	}
+
+	password := ctx.GetHeader(StatusPagePasswordHeader)
+	if password == "" {
+		_, password, _ = ctx.Request.BasicAuth()
+	}
+	if password == "" {
+		requirePassword(ctx)
+		return false
+	}
+
+	key := "status_page:" + ctx.ClientIP() + ":" + page.Slug
+	locked, until, err := g.bruteforceService.IsLocked(ctx, key)
+	if err != nil {
+		// Fail safe like the login guard, the password is still checked
+		g.logger.Errorw("Failed to check status page password lock", "error", err, "slug", page.Slug)
+	} else if retryAfter := time.Until(until); locked && retryAfter > 0 {
+		ctx.Header("Retry-After", fmt.Sprintf("%.0f", retryAfter.Seconds()))
+		ctx.JSON(http.StatusTooManyRequests, utils.NewFailResponse("Too many password attempts, try later"))
+		return false
+	}
+
+	if !g.service.VerifyPassword(page, password) {
+		locked, until, err := g.bruteforceService.OnFailure(ctx, key, time.Now(),
+			g.cfg.BruteforceWindow, g.cfg.BruteforceMaxAttempts, g.cfg.BruteforceLockout)
+		if err != nil {
+			g.logger.Errorw("Failed to record status page password failure", "error", err, "slug", page.Slug)
+		} else if locked {
+			g.logger.Infow("Status page password locked due to too many failures", "key", key, "until", until)
+		}
+		requirePassword(ctx)
+		return false
+	}
+
+	if err := g.bruteforceService.Reset(ctx, key); err != nil {
+		g.logger.Errorw("Failed to reset status page password failures", "error", err, "slug", page.Slug)
+	}
+	secure := ctx.Request.TLS != nil || ctx.GetHeader("X-Forwarded-Proto") == "https"
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(sessionCookieName(page), newSessionToken(page, time.Now().Add(sessionTTL)),
+		int(sessionTTL.Seconds()), "/", "", secure, true)
+	return true
+}
+
+func requirePassword(ctx *gin.Context) {
+	ctx.Header("WWW-Authenticate", `Basic realm="status page"`)
+	ctx.JSON(http.StatusUnauthorized, utils.NewFailResponse("Status page password required"))
+}
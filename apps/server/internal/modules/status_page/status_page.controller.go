@@ -3,7 +3,10 @@ package status_page
 import (
 	"net/http"
 	"peekaping/internal/modules/heartbeat"
+	"peekaping/internal/modules/maintenance"
 	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/shared"
+	"peekaping/internal/modules/stats"
 	"peekaping/internal/utils"
 	"time"
 
@@ -12,18 +15,24 @@ import (
 )
 
 type Controller struct {
-	service          Service
-	monitorService   monitor.Service
-	heartbeatService heartbeat.Service
-	logger           *zap.SugaredLogger
+	service            Service
+	monitorService     monitor.Service
+	heartbeatService   heartbeat.Service
+	maintenanceService maintenance.Service
+	statsService       stats.Service
+	access             *AccessGuard
+	logger             *zap.SugaredLogger
 }
 
-func NewController(service Service, monitorService monitor.Service, heartbeatService heartbeat.Service, logger *zap.SugaredLogger) *Controller {
+func NewController(service Service, monitorService monitor.Service, heartbeatService heartbeat.Service, maintenanceService maintenance.Service, statsService stats.Service, access *AccessGuard, logger *zap.SugaredLogger) *Controller {
 	return &Controller{
-		service:          service,
-		monitorService:   monitorService,
-		heartbeatService: heartbeatService,
-		logger:           logger,
+		service:            service,
+		monitorService:     monitorService,
+		heartbeatService:   heartbeatService,
+		maintenanceService: maintenanceService,
+		statsService:       statsService,
+		access:             access,
+		logger:             logger,
 	}
 }
 
@@ -100,7 +109,10 @@ func (c *Controller) FindByID(ctx *gin.Context) {
 // @Tags      Status Pages
 // @Produce   json
 // @Param     slug path      string  true  "Status Page Slug"
+// @Param     X-Status-Page-Password header string false "Password of a protected status page"
 // @Success   200  {object}  utils.ApiResponse[Model]
+// @Failure   401  {object}  utils.APIError[any]
+// @Failure   429  {object}  utils.APIError[any]
 // @Failure   404  {object}  utils.APIError[any]
 // @Failure   500  {object}  utils.APIError[any]
 func (c *Controller) FindBySlug(ctx *gin.Context) {
@@ -115,6 +127,9 @@ func (c *Controller) FindBySlug(ctx *gin.Context) {
 		ctx.JSON(http.StatusNotFound, utils.NewFailResponse("Status page not found"))
 		return
 	}
+	if !c.access.Authorize(ctx, page) {
+		return
+	}
 	ctx.JSON(http.StatusOK, utils.NewSuccessResponse("success", page))
 }
 
@@ -123,7 +138,10 @@ func (c *Controller) FindBySlug(ctx *gin.Context) {
 // @Tags      Status Pages
 // @Produce   json
 // @Param     domain path      string  true  "Domain Name"
-// @Success   200  {object}  utils.ApiResponse[Model]
+// @Param     X-Status-Page-Password header string false "Password of a protected status page"
+// @Success   200  {object}  utils.ApiResponse[DomainStatusPageDTO]
+// @Failure   401  {object}  utils.APIError[any]
+// @Failure   429  {object}  utils.APIError[any]
 // @Failure   404  {object}  utils.APIError[any]
 // @Failure   500  {object}  utils.APIError[any]
 func (c *Controller) FindByDomain(ctx *gin.Context) {
@@ -138,7 +156,25 @@ func (c *Controller) FindByDomain(ctx *gin.Context) {
 		ctx.JSON(http.StatusNotFound, utils.NewFailResponse("Status page not found"))
 		return
 	}
-	ctx.JSON(http.StatusOK, utils.NewSuccessResponse("success", page))
+	if !c.access.Authorize(ctx, page) {
+		return
+	}
+
+	monitors, err := c.service.GetMonitorsForStatusPage(ctx, page.ID)
+	if err != nil {
+		c.logger.Errorw("Failed to get monitors for status page", "error", err, "statusPageID", page.ID)
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
+		return
+	}
+	monitorIDs := make([]string, 0, len(monitors))
+	for _, msp := range monitors {
+		monitorIDs = append(monitorIDs, msp.MonitorID)
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewSuccessResponse("success", &DomainStatusPageDTO{
+		Model:        page,
+		Maintenances: c.scheduledMaintenances(ctx, monitorIDs, time.Now()),
+	}))
 }
 
 // @Router    /status-pages [get]
@@ -241,7 +277,11 @@ func (c *Controller) Delete(ctx *gin.Context) {
 // @Tags      Status Pages
 // @Produce   json
 // @Param     slug path      string  true  "Status Page Slug"
+// @Param     days query     int     false "Number of daily uptime bars (1-365), defaults to the page's uptime window"
+// @Param     X-Status-Page-Password header string false "Password of a protected status page"
 // @Success   200  {object}  utils.ApiResponse[[]MonitorWithHeartbeatsAndUptimeDTO]
+// @Failure   401  {object}  utils.APIError[any]
+// @Failure   429  {object}  utils.APIError[any]
 // @Failure   404  {object}  utils.APIError[any]
 // @Failure   500  {object}  utils.APIError[any]
 func (c *Controller) GetMonitorsBySlug(ctx *gin.Context) {
@@ -258,6 +298,9 @@ func (c *Controller) GetMonitorsBySlug(ctx *gin.Context) {
 		ctx.JSON(http.StatusNotFound, utils.NewFailResponse("Status page not found"))
 		return
 	}
+	if !c.access.Authorize(ctx, page) {
+		return
+	}
 
 	// Get monitors for the status page
 	monitors, err := c.service.GetMonitorsForStatusPage(ctx, page.ID)
@@ -267,6 +310,8 @@ func (c *Controller) GetMonitorsBySlug(ctx *gin.Context) {
 		return
 	}
 
+	days := dailyUptimeDays(page, ctx.Query("days"))
+
 	// Convert monitor_status_page models to monitor models with heartbeats and uptime
 	monitorModels := make([]*MonitorWithHeartbeatsAndUptimeDTO, 0, len(monitors))
 	for _, msp := range monitors {
@@ -292,7 +337,7 @@ func (c *Controller) GetMonitorsBySlug(ctx *gin.Context) {
 		for _, hb := range heartbeats {
 			publicHeartbeat := &PublicHeartbeatDTO{
 				ID:      hb.ID,
-				Status:  hb.Status,
+				Status:  publicHeartbeatStatus(page, hb),
 				Time:    hb.Time,
 				EndTime: hb.EndTime,
 				Ping:    hb.Ping,
@@ -330,6 +375,7 @@ func (c *Controller) GetMonitorsBySlug(ctx *gin.Context) {
 			PublicMonitorDTO: publicMonitor,
 			Heartbeats:       publicHeartbeats,
 			Uptime24h:        uptime24h,
+			DailyUptime:      c.dailyUptime(ctx, msp.MonitorID, days, now),
 		}
 
 		monitorModels = append(monitorModels, monitorWithData)
@@ -343,7 +389,10 @@ func (c *Controller) GetMonitorsBySlug(ctx *gin.Context) {
 // @Tags      Status Pages
 // @Produce   json
 // @Param     slug path      string  true  "Status Page Slug"
+// @Param     X-Status-Page-Password header string false "Password of a protected status page"
 // @Success   200  {object}  utils.ApiResponse[[]MonitorWithHeartbeatsAndUptimeDTO]
+// @Failure   401  {object}  utils.APIError[any]
+// @Failure   429  {object}  utils.APIError[any]
 // @Failure   404  {object}  utils.APIError[any]
 // @Failure   500  {object}  utils.APIError[any]
 func (c *Controller) GetMonitorsBySlugForHomepage(ctx *gin.Context) {
@@ -360,6 +409,9 @@ func (c *Controller) GetMonitorsBySlugForHomepage(ctx *gin.Context) {
 		ctx.JSON(http.StatusNotFound, utils.NewFailResponse("Status page not found"))
 		return
 	}
+	if !c.access.Authorize(ctx, page) {
+		return
+	}
 
 	// Get monitors for the status page
 	monitors, err := c.service.GetMonitorsForStatusPage(ctx, page.ID)
@@ -394,7 +446,7 @@ func (c *Controller) GetMonitorsBySlugForHomepage(ctx *gin.Context) {
 		for _, hb := range heartbeats {
 			publicHeartbeat := &PublicHeartbeatDTO{
 				ID:      hb.ID,
-				Status:  hb.Status,
+				Status:  publicHeartbeatStatus(page, hb),
 				Time:    hb.Time,
 				EndTime: hb.EndTime,
 				Ping:    hb.Ping,
@@ -439,3 +491,82 @@ func (c *Controller) GetMonitorsBySlugForHomepage(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, utils.NewSuccessResponse("success", monitorModels))
 }
+
+// @Router    /status-pages/slug/{slug}/status [get]
+// @Summary   Get the overall status of a status page by slug
+// @Tags      Status Pages
+// @Produce   json
+// @Param     slug path      string  true  "Status Page Slug"
+// @Param     X-Status-Page-Password header string false "Password of a protected status page"
+// @Success   200  {object}  utils.ApiResponse[PublicStatusDTO]
+// @Failure   401  {object}  utils.APIError[any]
+// @Failure   429  {object}  utils.APIError[any]
+// @Failure   404  {object}  utils.APIError[any]
+// @Failure   500  {object}  utils.APIError[any]
+func (c *Controller) GetStatusBySlug(ctx *gin.Context) {
+	slug := ctx.Param("slug")
+
+	page, err := c.service.FindBySlug(ctx, slug)
+	if err != nil {
+		c.logger.Errorw("Failed to get status page by slug", "error", err, "slug", slug)
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
+		return
+	}
+	if page == nil {
+		ctx.JSON(http.StatusNotFound, utils.NewFailResponse("Status page not found"))
+		return
+	}
+	if !c.access.Authorize(ctx, page) {
+		return
+	}
+
+	monitors, err := c.service.GetMonitorsForStatusPage(ctx, page.ID)
+	if err != nil {
+		c.logger.Errorw("Failed to get monitors for status page", "error", err, "statusPageID", page.ID)
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
+		return
+	}
+
+	monitorStatuses := make([]*PublicMonitorStatusDTO, 0, len(monitors))
+	latest := make([]*heartbeat.Model, 0, len(monitors))
+	monitorIDs := make([]string, 0, len(monitors))
+	for _, msp := range monitors {
+		monitorModel, err := c.monitorService.FindByID(ctx, msp.MonitorID)
+		if err != nil {
+			c.logger.Errorw("Failed to get monitor by ID", "error", err, "monitorID", msp.MonitorID)
+			continue
+		}
+		if monitorModel == nil || !monitorModel.Active {
+			continue
+		}
+		monitorIDs = append(monitorIDs, monitorModel.ID)
+
+		heartbeats, err := c.heartbeatService.FindByMonitorIDPaginated(ctx, msp.MonitorID, 1, 0, nil, false)
+		if err != nil {
+			c.logger.Errorw("Failed to get heartbeats for monitor", "error", err, "monitorID", msp.MonitorID)
+			continue
+		}
+
+		status := shared.MonitorStatusPending
+		if len(heartbeats) > 0 {
+			status = publicHeartbeatStatus(page, heartbeats[0])
+			latest = append(latest, heartbeats[0])
+		}
+
+		monitorStatuses = append(monitorStatuses, &PublicMonitorStatusDTO{
+			ID:     monitorModel.ID,
+			Name:   monitorModel.Name,
+			Status: status,
+		})
+	}
+
+	now := time.Now()
+	ctx.JSON(http.StatusOK, utils.NewSuccessResponse("success", &PublicStatusDTO{
+		Status:             overallStatus(page, latest),
+		Indicator:          overallIndicator(page, latest),
+		Uptime:             c.overallUptime(ctx, page, monitorIDs, now),
+		UptimeWindowDays:   uptimeWindowDays(page),
+		Monitors:           monitorStatuses,
+		MaintenanceBanners: c.maintenanceBanners(ctx, monitorIDs, now),
+	}))
+}
@@ -0,0 +1,313 @@
+package status_page
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"peekaping/internal/config"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) Create(ctx context.Context, statusPage *Model) (*Model, error) {
+	args := m.Called(ctx, statusPage)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Model), args.Error(1)
+}
+
+func (m *MockRepository) FindByID(ctx context.Context, id string) (*Model, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Model), args.Error(1)
+}
+
+func (m *MockRepository) FindBySlug(ctx context.Context, slug string) (*Model, error) {
+	args := m.Called(ctx, slug)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Model), args.Error(1)
+}
+
+func (m *MockRepository) FindAll(ctx context.Context, page int, limit int, q string) ([]*Model, error) {
+	args := m.Called(ctx, page, limit, q)
+	return args.Get(0).([]*Model), args.Error(1)
+}
+
+func (m *MockRepository) Update(ctx context.Context, id string, statusPage *UpdateModel) error {
+	args := m.Called(ctx, id, statusPage)
+	return args.Error(0)
+}
+
+func (m *MockRepository) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+// fakeBruteforceService keeps the password failures in memory
+type fakeBruteforceService struct {
+	failures map[string]int
+	locked   map[string]time.Time
+}
+
+func newFakeBruteforceService() *fakeBruteforceService {
+	return &fakeBruteforceService{failures: map[string]int{}, locked: map[string]time.Time{}}
+}
+
+func (f *fakeBruteforceService) IsLocked(ctx context.Context, key string) (bool, time.Time, error) {
+	until, ok := f.locked[key]
+	return ok, until, nil
+}
+
+func (f *fakeBruteforceService) OnFailure(ctx context.Context, key string, now time.Time, window time.Duration, max int, lockout time.Duration) (bool, time.Time, error) {
+	f.failures[key]++
+	if f.failures[key] >= max {
+		f.locked[key] = now.Add(lockout)
+		return true, f.locked[key], nil
+	}
+	return false, time.Time{}, nil
+}
+
+func (f *fakeBruteforceService) Reset(ctx context.Context, key string) error {
+	delete(f.failures, key)
+	delete(f.locked, key)
+	return nil
+}
+
+func setupStatusPageControllerRouter(repo *MockRepository) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	service := NewService(repo, nil, nil, nil, zap.NewNop().Sugar())
+	cfg := &config.Config{BruteforceMaxAttempts: 3, BruteforceWindow: time.Minute, BruteforceLockout: time.Minute}
+	access := NewAccessGuard(service, newFakeBruteforceService(), cfg, zap.NewNop().Sugar())
+	controller := NewController(service, nil, nil, nil, nil, access, zap.NewNop().Sugar())
+
+	router := gin.New()
+	router.GET("/status-pages/slug/:slug", controller.FindBySlug)
+	return router
+}
+
+func TestServiceImpl_Create_HashesPassword(t *testing.T) {
+	repo := &MockRepository{}
+	service := NewService(repo, nil, nil, nil, zap.NewNop().Sugar())
+
+	var stored *Model
+	repo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(*Model)
+	}).Return(&Model{ID: "page-1"}, nil)
+
+	_, err := service.Create(context.Background(), &CreateStatusPageDTO{
+		Slug:     "internal",
+		Title:    "Internal",
+		Password: "team-secret",
+	})
+
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+	assert.NotEqual(t, "team-secret", stored.PasswordHash)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(stored.PasswordHash), []byte("team-secret")))
+}
+
+func TestServiceImpl_Update_Password(t *testing.T) {
+	repo := &MockRepository{}
+	service := NewService(repo, nil, nil, nil, zap.NewNop().Sugar())
+	repo.On("FindByID", mock.Anything, "page-1").Return(&Model{ID: "page-1"}, nil)
+
+	t.Run("sets a new hash", func(t *testing.T) {
+		password := "rotated"
+		repo.On("Update", mock.Anything, "page-1", mock.MatchedBy(func(u *UpdateModel) bool {
+			return u.PasswordHash != nil &&
+				bcrypt.CompareHashAndPassword([]byte(*u.PasswordHash), []byte(password)) == nil
+		})).Return(nil).Once()
+
+		_, err := service.Update(context.Background(), "page-1", &UpdateStatusPageDTO{Password: &password})
+		require.NoError(t, err)
+	})
+
+	t.Run("empty password removes the protection", func(t *testing.T) {
+		empty := ""
+		repo.On("Update", mock.Anything, "page-1", mock.MatchedBy(func(u *UpdateModel) bool {
+			return u.PasswordHash != nil && *u.PasswordHash == ""
+		})).Return(nil).Once()
+
+		_, err := service.Update(context.Background(), "page-1", &UpdateStatusPageDTO{Password: &empty})
+		require.NoError(t, err)
+	})
+
+	t.Run("omitted password keeps the current one", func(t *testing.T) {
+		repo.On("Update", mock.Anything, "page-1", mock.MatchedBy(func(u *UpdateModel) bool {
+			return u.PasswordHash == nil
+		})).Return(nil).Once()
+
+		title := "Renamed"
+		_, err := service.Update(context.Background(), "page-1", &UpdateStatusPageDTO{Title: &title})
+		require.NoError(t, err)
+	})
+
+	repo.AssertExpectations(t)
+}
+
+func TestController_FindBySlug_PasswordProtection(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("team-secret"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	repo := &MockRepository{}
+	repo.On("FindBySlug", mock.Anything, "internal").Return(&Model{
+		ID:                "page-1",
+		Slug:              "internal",
+		Title:             "Internal",
+		PasswordHash:      string(hash),
+		PasswordProtected: true,
+	}, nil)
+	repo.On("FindBySlug", mock.Anything, "public").Return(&Model{
+		ID:    "page-2",
+		Slug:  "public",
+		Title: "Public",
+	}, nil)
+	router := setupStatusPageControllerRouter(repo)
+
+	tests := []struct {
+		name       string
+		slug       string
+		prepare    func(req *http.Request)
+		wantStatus int
+	}{
+		{
+			name:       "protected page without password",
+			slug:       "internal",
+			prepare:    func(req *http.Request) {},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "protected page with wrong password",
+			slug: "internal",
+			prepare: func(req *http.Request) {
+				req.Header.Set(StatusPagePasswordHeader, "guess")
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "protected page with password header",
+			slug: "internal",
+			prepare: func(req *http.Request) {
+				req.Header.Set(StatusPagePasswordHeader, "team-secret")
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "protected page with basic auth",
+			slug: "internal",
+			prepare: func(req *http.Request) {
+				req.SetBasicAuth("anyone", "team-secret")
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "public page without password",
+			slug:       "public",
+			prepare:    func(req *http.Request) {},
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/status-pages/slug/"+tt.slug, nil)
+			tt.prepare(req)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusUnauthorized {
+				assert.Equal(t, `Basic realm="status page"`, w.Header().Get("WWW-Authenticate"))
+				assert.NotContains(t, w.Body.String(), "Internal")
+			}
+			// The hash never leaves the server
+			assert.NotContains(t, w.Body.String(), string(hash))
+		})
+	}
+}
+
+func TestController_FindBySlug_PasswordSession(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("team-secret"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	repo := &MockRepository{}
+	repo.On("FindBySlug", mock.Anything, "internal").Return(&Model{
+		ID:                "page-1",
+		Slug:              "internal",
+		PasswordHash:      string(hash),
+		PasswordProtected: true,
+	}, nil)
+	router := setupStatusPageControllerRouter(repo)
+
+	get := func(prepare func(req *http.Request)) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/status-pages/slug/internal", nil)
+		prepare(req)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get(func(req *http.Request) { req.Header.Set(StatusPagePasswordHeader, "team-secret") })
+	require.Equal(t, http.StatusOK, w.Code)
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "status_page_session_page-1", cookies[0].Name)
+	assert.True(t, cookies[0].HttpOnly)
+
+	t.Run("session cookie unlocks the page", func(t *testing.T) {
+		w := get(func(req *http.Request) { req.AddCookie(cookies[0]) })
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("forged cookie is refused", func(t *testing.T) {
+		w := get(func(req *http.Request) {
+			req.AddCookie(&http.Cookie{Name: cookies[0].Name, Value: "9999999999.forged"})
+		})
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("wrong passwords are locked out", func(t *testing.T) {
+		for range 3 {
+			w := get(func(req *http.Request) { req.Header.Set(StatusPagePasswordHeader, "guess") })
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+		}
+
+		w := get(func(req *http.Request) { req.Header.Set(StatusPagePasswordHeader, "team-secret") })
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+		// Visitors with a session are not affected
+		w = get(func(req *http.Request) { req.AddCookie(cookies[0]) })
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestVerifySessionToken(t *testing.T) {
+	now := time.Now()
+	page := &Model{ID: "page-1", PasswordHash: "hash"}
+	token := newSessionToken(page, now.Add(time.Hour))
+
+	assert.True(t, verifySessionToken(page, token, now))
+	assert.False(t, verifySessionToken(page, token, now.Add(2*time.Hour)), "expired")
+	assert.False(t, verifySessionToken(&Model{ID: "page-2", PasswordHash: "hash"}, token, now), "other page")
+	assert.False(t, verifySessionToken(&Model{ID: "page-1", PasswordHash: "rotated"}, token, now), "password changed")
+	assert.False(t, verifySessionToken(&Model{ID: "page-1"}, token, now), "password removed")
+	assert.False(t, verifySessionToken(page, "garbage", now))
+}
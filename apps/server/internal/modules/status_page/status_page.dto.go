@@ -21,6 +21,11 @@ type CreateStatusPageDTO struct {
 	GoogleAnalyticsTagID  string   `json:"google_analytics_tag_id"`
 	ShowCertificateExpiry bool     `json:"show_certificate_expiry"`
 	AutoRefreshInterval   int      `json:"auto_refresh_interval"`
+	ShowDegraded          bool     `json:"show_degraded"`
+	DegradedThreshold     int      `json:"degraded_threshold" validate:"min=0"`
+	UptimeWindowDays      int      `json:"uptime_window_days" validate:"min=0,max=365"`
+	AllowSubscriptions    bool     `json:"allow_subscriptions"`
+	SubscriberChannelID   string   `json:"subscriber_channel_id"`
 	MonitorIDs            []string `json:"monitor_ids,omitempty"`
 	Domains               []string `json:"domains,omitempty"`
 }
@@ -34,13 +39,18 @@ type UpdateStatusPageDTO struct {
 	Published             *bool     `json:"published,omitempty"`
 	SearchEngineIndex     *bool     `json:"search_engine_index,omitempty"`
 	ShowTags              *bool     `json:"show_tags,omitempty"`
-	Password              *string   `json:"password,omitempty"`
+	Password              *string   `json:"password,omitempty"` // empty string removes the password
 	FooterText            *string   `json:"footer_text,omitempty"`
 	CustomCSS             *string   `json:"custom_css,omitempty"`
 	ShowPoweredBy         *bool     `json:"show_powered_by,omitempty"`
 	GoogleAnalyticsTagID  *string   `json:"google_analytics_tag_id,omitempty"`
 	ShowCertificateExpiry *bool     `json:"show_certificate_expiry,omitempty"`
 	AutoRefreshInterval   *int      `json:"auto_refresh_interval,omitempty"`
+	ShowDegraded          *bool     `json:"show_degraded,omitempty"`
+	DegradedThreshold     *int      `json:"degraded_threshold,omitempty" validate:"omitempty,min=0"`
+	UptimeWindowDays      *int      `json:"uptime_window_days,omitempty" validate:"omitempty,min=0,max=365"`
+	AllowSubscriptions    *bool     `json:"allow_subscriptions,omitempty"`
+	SubscriberChannelID   *string   `json:"subscriber_channel_id,omitempty"`
 	MonitorIDs            *[]string `json:"monitor_ids,omitempty"`
 	Domains               *[]string `json:"domains,omitempty"`
 }
@@ -55,7 +65,7 @@ type StatusPageWithMonitorsResponseDTO struct {
 	Published             bool      `json:"published"`
 	SearchEngineIndex     bool      `json:"search_engine_index"`
 	ShowTags              bool      `json:"show_tags"`
-	Password              string    `json:"password,omitempty"`
+	PasswordProtected     bool      `json:"password_protected"`
 	CreatedAt             time.Time `json:"created_at"`
 	UpdatedAt             time.Time `json:"updated_at"`
 	FooterText            string    `json:"footer_text"`
@@ -64,6 +74,11 @@ type StatusPageWithMonitorsResponseDTO struct {
 	GoogleAnalyticsTagID  string    `json:"google_analytics_tag_id"`
 	ShowCertificateExpiry bool      `json:"show_certificate_expiry"`
 	AutoRefreshInterval   int       `json:"auto_refresh_interval"`
+	ShowDegraded          bool      `json:"show_degraded"`
+	DegradedThreshold     int       `json:"degraded_threshold"`
+	UptimeWindowDays      int       `json:"uptime_window_days"`
+	AllowSubscriptions    bool      `json:"allow_subscriptions"`
+	SubscriberChannelID   string    `json:"subscriber_channel_id"`
 	MonitorIDs            []string  `json:"monitor_ids"`
 	Domains               []string  `json:"domains"`
 }
@@ -83,8 +98,69 @@ type PublicHeartbeatDTO struct {
 	Ping    int                  `json:"ping"`
 }
 
+// PublicStatusDTO is the overall status of a status page with the current status of its
+// active monitors. Statuses are 0 down, 1 up, 2 pending, 3 maintenance and 4 degraded.
+type PublicStatusDTO struct {
+	Status shared.MonitorStatus `json:"status"`
+	// Indicator summarizes Status for the page header: operational, degraded or
+	// major_outage
+	Indicator string `json:"indicator"`
+	// Uptime is the average uptime percentage of the active monitors over the last
+	// UptimeWindowDays days, nil when none of them has data yet
+	Uptime           *float64                  `json:"uptime"`
+	UptimeWindowDays int                       `json:"uptime_window_days"`
+	Monitors         []*PublicMonitorStatusDTO `json:"monitors"`
+	// MaintenanceBanners are the notices of the maintenance windows active right now for
+	// the page's monitors
+	MaintenanceBanners []*MaintenanceBannerDTO `json:"maintenance_banners"`
+}
+
+type MaintenanceBannerDTO struct {
+	MaintenanceID string `json:"maintenance_id"`
+	Title         string `json:"title"`
+	Message       string `json:"message"`
+}
+
+// DomainStatusPageDTO is a status page found by its domain with the maintenances of its
+// monitors, for a "Scheduled maintenance" banner
+type DomainStatusPageDTO struct {
+	*Model
+	Maintenances []*ScheduledMaintenanceDTO `json:"maintenances"`
+}
+
+// ScheduledMaintenanceDTO is a maintenance in progress or scheduled for later. StartsAt and
+// EndsAt are its current or next window, recurring maintenances are reported with the
+// next occurrence only. Manual maintenances have no window and last while in progress.
+type ScheduledMaintenanceDTO struct {
+	MaintenanceID string     `json:"maintenance_id"`
+	Title         string     `json:"title"`
+	Description   string     `json:"description"`
+	Strategy      string     `json:"strategy"`
+	InProgress    bool       `json:"in_progress"`
+	StartsAt      *time.Time `json:"starts_at,omitempty"`
+	EndsAt        *time.Time `json:"ends_at,omitempty"`
+}
+
+type PublicMonitorStatusDTO struct {
+	ID     string               `json:"id"`
+	Name   string               `json:"name"`
+	Status shared.MonitorStatus `json:"status"`
+}
+
 type MonitorWithHeartbeatsAndUptimeDTO struct {
 	*PublicMonitorDTO
 	Heartbeats []*PublicHeartbeatDTO `json:"heartbeats"`
 	Uptime24h  float64               `json:"uptime_24h"`
+	// DailyUptime holds one entry per day for the uptime bars, oldest first
+	DailyUptime []*DailyUptimeDTO `json:"daily_uptime,omitempty"`
+}
+
+// DailyUptimeDTO is the uptime of a monitor over one UTC day. Uptime is a percentage,
+// null on days without checks.
+type DailyUptimeDTO struct {
+	Date        string   `json:"date" example:"2025-10-06"`
+	Uptime      *float64 `json:"uptime"`
+	Up          int      `json:"up"`
+	Down        int      `json:"down"`
+	Maintenance int      `json:"maintenance"`
 }
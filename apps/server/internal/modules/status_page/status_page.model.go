@@ -12,6 +12,22 @@ type Model struct {
 	Published           bool   `json:"published" bson:"published"`
 	FooterText          string `json:"footer_text" bson:"footer_text"`
 	AutoRefreshInterval int    `json:"auto_refresh_interval" bson:"auto_refresh_interval"`
+	// PasswordHash is the bcrypt hash of the page password, empty for public pages
+	PasswordHash      string `json:"-" bson:"password_hash"`
+	PasswordProtected bool   `json:"password_protected" bson:"-"`
+	// ShowDegraded renders degraded monitors in their own state instead of up.
+	// DegradedThreshold is how many degraded monitors turn the overall page status
+	// degraded, 0 keeps them from affecting it.
+	ShowDegraded      bool `json:"show_degraded" bson:"show_degraded"`
+	DegradedThreshold int  `json:"degraded_threshold" bson:"degraded_threshold"`
+	// UptimeWindowDays is the period the overall uptime of the page is computed over,
+	// 0 uses DefaultUptimeWindowDays
+	UptimeWindowDays int `json:"uptime_window_days" bson:"uptime_window_days"`
+	// AllowSubscriptions lets visitors subscribe to status changes of the page's monitors.
+	// SubscriberChannelID is the SMTP notification channel emails to subscribers are sent
+	// with, email subscriptions are refused without it.
+	AllowSubscriptions  bool   `json:"allow_subscriptions" bson:"allow_subscriptions"`
+	SubscriberChannelID string `json:"subscriber_channel_id" bson:"subscriber_channel_id"`
 
 	CreatedAt time.Time `json:"created_at" bson:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
@@ -26,4 +42,12 @@ type UpdateModel struct {
 	Published           *bool   `json:"published,omitempty" bson:"published,omitempty"`
 	FooterText          *string `json:"footer_text,omitempty" bson:"footer_text,omitempty"`
 	AutoRefreshInterval *int    `json:"auto_refresh_interval,omitempty" bson:"auto_refresh_interval,omitempty"`
+	// PasswordHash set to an empty string removes the password protection
+	PasswordHash      *string `json:"-" bson:"password_hash,omitempty"`
+	ShowDegraded      *bool   `json:"show_degraded,omitempty" bson:"show_degraded,omitempty"`
+	DegradedThreshold *int    `json:"degraded_threshold,omitempty" bson:"degraded_threshold,omitempty"`
+	UptimeWindowDays  *int    `json:"uptime_window_days,omitempty" bson:"uptime_window_days,omitempty"`
+
+	AllowSubscriptions  *bool   `json:"allow_subscriptions,omitempty" bson:"allow_subscriptions,omitempty"`
+	SubscriberChannelID *string `json:"subscriber_channel_id,omitempty" bson:"subscriber_channel_id,omitempty"`
 }
@@ -21,10 +21,15 @@ type mongoModel struct {
 	Theme                string             `bson:"theme"`
 	Published            bool               `bson:"published"`
 	SearchEngineIndex    bool               `bson:"search_engine_index"`
-	Password             string             `bson:"password,omitempty"`
+	PasswordHash         string             `bson:"password_hash,omitempty"`
 	FooterText           string             `bson:"footer_text"`
 	GoogleAnalyticsTagID string             `bson:"google_analytics_tag_id"`
 	AutoRefreshInterval  int                `bson:"auto_refresh_interval"`
+	ShowDegraded         bool               `bson:"show_degraded"`
+	DegradedThreshold    int                `bson:"degraded_threshold"`
+	UptimeWindowDays     int                `bson:"uptime_window_days"`
+	AllowSubscriptions   bool               `bson:"allow_subscriptions"`
+	SubscriberChannelID  string             `bson:"subscriber_channel_id"`
 
 	CreatedAt time.Time `bson:"created_at"`
 	UpdatedAt time.Time `bson:"updated_at"`
@@ -41,6 +46,13 @@ func toDomainModel(m *mongoModel) *Model {
 		Published:           m.Published,
 		FooterText:          m.FooterText,
 		AutoRefreshInterval: m.AutoRefreshInterval,
+		PasswordHash:        m.PasswordHash,
+		PasswordProtected:   m.PasswordHash != "",
+		ShowDegraded:        m.ShowDegraded,
+		DegradedThreshold:   m.DegradedThreshold,
+		UptimeWindowDays:    m.UptimeWindowDays,
+		AllowSubscriptions:  m.AllowSubscriptions,
+		SubscriberChannelID: m.SubscriberChannelID,
 
 		CreatedAt: m.CreatedAt,
 		UpdatedAt: m.UpdatedAt,
@@ -88,6 +100,12 @@ func (r *MongoRepository) Create(ctx context.Context, statusPage *Model) (*Model
 		UpdatedAt:           time.Now().UTC(),
 		FooterText:          statusPage.FooterText,
 		AutoRefreshInterval: statusPage.AutoRefreshInterval,
+		PasswordHash:        statusPage.PasswordHash,
+		ShowDegraded:        statusPage.ShowDegraded,
+		DegradedThreshold:   statusPage.DegradedThreshold,
+		UptimeWindowDays:    statusPage.UptimeWindowDays,
+		AllowSubscriptions:  statusPage.AllowSubscriptions,
+		SubscriberChannelID: statusPage.SubscriberChannelID,
 	}
 
 	_, err := r.collection.InsertOne(ctx, mm)
@@ -193,6 +211,24 @@ func (r *MongoRepository) Update(ctx context.Context, id string, statusPage *Upd
 	if statusPage.AutoRefreshInterval != nil {
 		updatePayload["auto_refresh_interval"] = *statusPage.AutoRefreshInterval
 	}
+	if statusPage.PasswordHash != nil {
+		updatePayload["password_hash"] = *statusPage.PasswordHash
+	}
+	if statusPage.ShowDegraded != nil {
+		updatePayload["show_degraded"] = *statusPage.ShowDegraded
+	}
+	if statusPage.DegradedThreshold != nil {
+		updatePayload["degraded_threshold"] = *statusPage.DegradedThreshold
+	}
+	if statusPage.UptimeWindowDays != nil {
+		updatePayload["uptime_window_days"] = *statusPage.UptimeWindowDays
+	}
+	if statusPage.AllowSubscriptions != nil {
+		updatePayload["allow_subscriptions"] = *statusPage.AllowSubscriptions
+	}
+	if statusPage.SubscriberChannelID != nil {
+		updatePayload["subscriber_channel_id"] = *statusPage.SubscriberChannelID
+	}
 
 	if len(updatePayload) == 0 {
 		return nil // nothing to update
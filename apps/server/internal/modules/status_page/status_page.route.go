@@ -25,6 +25,7 @@ func (r *Route) ConnectRoute(rg *gin.RouterGroup, controller *Controller) {
 	sp.GET("/domain/:domain", r.controller.FindByDomain)
 	sp.GET("/slug/:slug/monitors", r.controller.GetMonitorsBySlug)
 	sp.GET("/slug/:slug/monitors/homepage", r.controller.GetMonitorsBySlugForHomepage)
+	sp.GET("/slug/:slug/status", r.controller.GetStatusBySlug)
 
 	sp.Use(r.middleware.AllAuth())
 	{
@@ -8,6 +8,7 @@ import (
 	"peekaping/internal/modules/monitor_status_page"
 
 	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type Service interface {
@@ -20,6 +21,9 @@ type Service interface {
 	Update(ctx context.Context, id string, dto *UpdateStatusPageDTO) (*Model, error)
 	Delete(ctx context.Context, id string) error
 
+	// VerifyPassword reports whether password unlocks the page, public pages always pass
+	VerifyPassword(page *Model, password string) bool
+
 	GetMonitorsForStatusPage(ctx context.Context, statusPageID string) ([]*monitor_status_page.Model, error)
 }
 
@@ -98,6 +102,19 @@ func (s *ServiceImpl) Create(ctx context.Context, dto *CreateStatusPageDTO) (*Mo
 		Published:           dto.Published,
 		FooterText:          dto.FooterText,
 		AutoRefreshInterval: dto.AutoRefreshInterval,
+		ShowDegraded:        dto.ShowDegraded,
+		DegradedThreshold:   dto.DegradedThreshold,
+		UptimeWindowDays:    dto.UptimeWindowDays,
+		AllowSubscriptions:  dto.AllowSubscriptions,
+		SubscriberChannelID: dto.SubscriberChannelID,
+	}
+
+	if dto.Password != "" {
+		hash, err := hashPassword(dto.Password)
+		if err != nil {
+			return nil, err
+		}
+		model.PasswordHash = hash
 	}
 
 	created, err := s.repository.Create(ctx, model)
@@ -222,6 +239,22 @@ func (s *ServiceImpl) Update(ctx context.Context, id string, dto *UpdateStatusPa
 		Published:           dto.Published,
 		FooterText:          dto.FooterText,
 		AutoRefreshInterval: dto.AutoRefreshInterval,
+		ShowDegraded:        dto.ShowDegraded,
+		DegradedThreshold:   dto.DegradedThreshold,
+		UptimeWindowDays:    dto.UptimeWindowDays,
+		AllowSubscriptions:  dto.AllowSubscriptions,
+		SubscriberChannelID: dto.SubscriberChannelID,
+	}
+
+	if dto.Password != nil {
+		hash := ""
+		if *dto.Password != "" {
+			var err error
+			if hash, err = hashPassword(*dto.Password); err != nil {
+				return nil, err
+			}
+		}
+		updateModel.PasswordHash = &hash
 	}
 
 	err := s.repository.Update(ctx, id, updateModel)
@@ -333,6 +366,21 @@ func (s *ServiceImpl) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+func (s *ServiceImpl) VerifyPassword(page *Model, password string) bool {
+	if page.PasswordHash == "" {
+		return true
+	}
+	return bcrypt.CompareHashAndPassword([]byte(page.PasswordHash), []byte(password)) == nil
+}
+
+func hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
 func (s *ServiceImpl) GetMonitorsForStatusPage(ctx context.Context, statusPageID string) ([]*monitor_status_page.Model, error) {
 	return s.monitorStatusPageService.GetMonitorsForStatusPage(ctx, statusPageID)
 }
@@ -351,6 +399,12 @@ func (s *ServiceImpl) mapModelToStatusPageWithMonitorsDTO(model *Model, monitorI
 		UpdatedAt:           model.UpdatedAt,
 		FooterText:          model.FooterText,
 		AutoRefreshInterval: model.AutoRefreshInterval,
+		PasswordProtected:   model.PasswordProtected,
+		ShowDegraded:        model.ShowDegraded,
+		DegradedThreshold:   model.DegradedThreshold,
+		UptimeWindowDays:    model.UptimeWindowDays,
+		AllowSubscriptions:  model.AllowSubscriptions,
+		SubscriberChannelID: model.SubscriberChannelID,
 		MonitorIDs:          monitorIDs,
 		Domains:             domains,
 	}
@@ -0,0 +1,50 @@
+package status_page
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sessionTTL is how long a protected page stays unlocked after its password was accepted
+const sessionTTL = 24 * time.Hour
+
+// sessionCookieName is the cookie unlocking a protected page, one per page
+func sessionCookieName(page *Model) string {
+	return "status_page_session_" + page.ID
+}
+
+// newSessionToken returns a token unlocking the page until expires. It is signed with the
+// password hash of the page, which never leaves the server, so changing the password ends
+// the sessions.
+func newSessionToken(page *Model, expires time.Time) string {
+	expiry := strconv.FormatInt(expires.Unix(), 10)
+	return expiry + "." + signSession(page, expiry)
+}
+
+// verifySessionToken reports whether token was issued for the current password of the page
+// and hasn't expired
+func verifySessionToken(page *Model, token string, now time.Time) bool {
+	if page.PasswordHash == "" {
+		return false
+	}
+	expiry, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+	expires, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil || now.Unix() >= expires {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(signSession(page, expiry)))
+}
+
+func signSession(page *Model, expiry string) string {
+	mac := hmac.New(sha256.New, []byte(page.PasswordHash))
+	fmt.Fprintf(mac, "%s:%s", page.ID, expiry)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
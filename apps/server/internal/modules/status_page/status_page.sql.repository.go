@@ -22,6 +22,12 @@ type sqlModel struct {
 	UpdatedAt           time.Time `bun:"updated_at,nullzero,notnull,default:current_timestamp"`
 	FooterText          string    `bun:"footer_text"`
 	AutoRefreshInterval int       `bun:"auto_refresh_interval,notnull,default:30"`
+	PasswordHash        string    `bun:"password_hash"`
+	ShowDegraded        bool      `bun:"show_degraded,notnull,default:false"`
+	DegradedThreshold   int       `bun:"degraded_threshold,notnull,default:0"`
+	UptimeWindowDays    int       `bun:"uptime_window_days,notnull,default:0"`
+	AllowSubscriptions  bool      `bun:"allow_subscriptions,notnull,default:false"`
+	SubscriberChannelID string    `bun:"subscriber_channel_id"`
 }
 
 func toDomainModelFromSQL(sm *sqlModel) *Model {
@@ -37,6 +43,13 @@ func toDomainModelFromSQL(sm *sqlModel) *Model {
 		UpdatedAt:           sm.UpdatedAt,
 		FooterText:          sm.FooterText,
 		AutoRefreshInterval: sm.AutoRefreshInterval,
+		PasswordHash:        sm.PasswordHash,
+		ShowDegraded:        sm.ShowDegraded,
+		DegradedThreshold:   sm.DegradedThreshold,
+		UptimeWindowDays:    sm.UptimeWindowDays,
+		AllowSubscriptions:  sm.AllowSubscriptions,
+		SubscriberChannelID: sm.SubscriberChannelID,
+		PasswordProtected:   sm.PasswordHash != "",
 	}
 }
 
@@ -53,6 +66,12 @@ func toSQLModel(m *Model) *sqlModel {
 		UpdatedAt:           m.UpdatedAt,
 		FooterText:          m.FooterText,
 		AutoRefreshInterval: m.AutoRefreshInterval,
+		PasswordHash:        m.PasswordHash,
+		ShowDegraded:        m.ShowDegraded,
+		DegradedThreshold:   m.DegradedThreshold,
+		UptimeWindowDays:    m.UptimeWindowDays,
+		AllowSubscriptions:  m.AllowSubscriptions,
+		SubscriberChannelID: m.SubscriberChannelID,
 	}
 }
 
@@ -168,6 +187,30 @@ func (r *SQLRepositoryImpl) Update(ctx context.Context, id string, statusPage *U
 		query = query.Set("auto_refresh_interval = ?", *statusPage.AutoRefreshInterval)
 		hasUpdates = true
 	}
+	if statusPage.PasswordHash != nil {
+		query = query.Set("password_hash = ?", *statusPage.PasswordHash)
+		hasUpdates = true
+	}
+	if statusPage.ShowDegraded != nil {
+		query = query.Set("show_degraded = ?", *statusPage.ShowDegraded)
+		hasUpdates = true
+	}
+	if statusPage.DegradedThreshold != nil {
+		query = query.Set("degraded_threshold = ?", *statusPage.DegradedThreshold)
+		hasUpdates = true
+	}
+	if statusPage.UptimeWindowDays != nil {
+		query = query.Set("uptime_window_days = ?", *statusPage.UptimeWindowDays)
+		hasUpdates = true
+	}
+	if statusPage.AllowSubscriptions != nil {
+		query = query.Set("allow_subscriptions = ?", *statusPage.AllowSubscriptions)
+		hasUpdates = true
+	}
+	if statusPage.SubscriberChannelID != nil {
+		query = query.Set("subscriber_channel_id = ?", *statusPage.SubscriberChannelID)
+		hasUpdates = true
+	}
 
 	if !hasUpdates {
 		return nil
@@ -0,0 +1,278 @@
+package status_page
+
+import (
+	"context"
+	"peekaping/internal/modules/heartbeat"
+	"peekaping/internal/modules/shared"
+	"peekaping/internal/modules/stats"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultUptimeWindowDays is the overall uptime window of pages that don't set one
+const DefaultUptimeWindowDays = 90
+
+const (
+	IndicatorOperational = "operational"
+	IndicatorDegraded    = "degraded"
+	IndicatorMajorOutage = "major_outage"
+)
+
+// isDegraded reports whether a heartbeat is up but flagged as degraded, e.g. some of the
+// resolved backends failed or the response body changed
+func isDegraded(hb *heartbeat.Model) bool {
+	return hb.Status == shared.MonitorStatusUp &&
+		(hb.ErrorCategory == shared.ErrorCategoryDegraded || hb.ErrorCategory == shared.ErrorCategoryBodyChanged)
+}
+
+// publicHeartbeatStatus is the status of a heartbeat as shown on the page, degraded
+// heartbeats are shown as up unless the page renders degraded
+func publicHeartbeatStatus(page *Model, hb *heartbeat.Model) shared.MonitorStatus {
+	if page.ShowDegraded && isDegraded(hb) {
+		return shared.MonitorStatusDegraded
+	}
+	return hb.Status
+}
+
+// overallStatus combines the latest heartbeat of every monitor into the page status.
+// Any down monitor makes the page down. Degraded monitors make it degraded once there are
+// at least DegradedThreshold of them, then maintenance wins over up. Pending monitors are
+// still retrying and count as up.
+func overallStatus(page *Model, latest []*heartbeat.Model) shared.MonitorStatus {
+	degraded := 0
+	maintenance := false
+
+	for _, hb := range latest {
+		switch {
+		case hb.Status == shared.MonitorStatusDown:
+			return shared.MonitorStatusDown
+		case hb.Status == shared.MonitorStatusMaintenance:
+			maintenance = true
+		case isDegraded(hb):
+			degraded++
+		}
+	}
+
+	if page.DegradedThreshold > 0 && degraded >= page.DegradedThreshold {
+		return shared.MonitorStatusDegraded
+	}
+	if maintenance {
+		return shared.MonitorStatusMaintenance
+	}
+	return shared.MonitorStatusUp
+}
+
+// overallIndicator summarizes the page status for the header. Down monitors make it a
+// major outage when they are at least half of the monitors with a heartbeat and
+// degraded otherwise, a degraded overall status is degraded, anything else, including
+// maintenance, is operational.
+func overallIndicator(page *Model, latest []*heartbeat.Model) string {
+	down := 0
+	for _, hb := range latest {
+		if hb.Status == shared.MonitorStatusDown {
+			down++
+		}
+	}
+
+	switch {
+	case down > 0 && down*2 >= len(latest):
+		return IndicatorMajorOutage
+	case down > 0:
+		return IndicatorDegraded
+	case overallStatus(page, latest) == shared.MonitorStatusDegraded:
+		return IndicatorDegraded
+	}
+	return IndicatorOperational
+}
+
+// uptimeWindowDays is the number of days the page's overall uptime is computed over
+func uptimeWindowDays(page *Model) int {
+	if page.UptimeWindowDays > 0 {
+		return page.UptimeWindowDays
+	}
+	return DefaultUptimeWindowDays
+}
+
+// dailyUptimeDays is the number of daily uptime bars to return, the days query parameter
+// when it is between 1 and 365 and the page's uptime window otherwise
+func dailyUptimeDays(page *Model, query string) int {
+	if days, err := strconv.Atoi(query); err == nil && days > 0 && days <= 365 {
+		return days
+	}
+	return uptimeWindowDays(page)
+}
+
+// averageUptime averages the uptime percentages, monitors without data are skipped.
+// It returns nil when none has data.
+func averageUptime(uptimes []*float64) *float64 {
+	var sum float64
+	count := 0
+	for _, u := range uptimes {
+		if u == nil {
+			continue
+		}
+		sum += *u
+		count++
+	}
+	if count == 0 {
+		return nil
+	}
+	avg := sum / float64(count)
+	return &avg
+}
+
+// overallUptime is the average uptime of the monitors over the page's uptime window,
+// computed from their daily stats so a 90 day window is a few rows per monitor
+func (c *Controller) overallUptime(ctx context.Context, page *Model, monitorIDs []string, now time.Time) *float64 {
+	if c.statsService == nil {
+		return nil
+	}
+
+	since := now.AddDate(0, 0, -uptimeWindowDays(page))
+	uptimes := make([]*float64, 0, len(monitorIDs))
+	for _, monitorID := range monitorIDs {
+		points, err := c.statsService.FindStatsByMonitorIDAndTimeRange(ctx, monitorID, since, now, stats.StatDaily)
+		if err != nil {
+			c.logger.Errorw("Failed to get stats for monitor", "error", err, "monitorID", monitorID)
+			continue
+		}
+		uptimes = append(uptimes, c.statsService.StatPointsSummary(ctx, points).Uptime)
+	}
+
+	return averageUptime(uptimes)
+}
+
+// dailyUptime returns the uptime bars of a monitor for the last days days, computed from
+// its daily stats. It returns nil when they can't be loaded so the rest of the page
+// still renders.
+func (c *Controller) dailyUptime(ctx context.Context, monitorID string, days int, now time.Time) []*DailyUptimeDTO {
+	if c.statsService == nil {
+		return nil
+	}
+
+	uptimes, err := c.statsService.DailyUptime(ctx, monitorID, days, now)
+	if err != nil {
+		c.logger.Errorw("Failed to get daily uptime for monitor", "error", err, "monitorID", monitorID)
+		return nil
+	}
+
+	result := make([]*DailyUptimeDTO, 0, len(uptimes))
+	for _, u := range uptimes {
+		result = append(result, &DailyUptimeDTO{
+			Date:        u.Date.Format(time.DateOnly),
+			Uptime:      u.Uptime,
+			Up:          u.Up,
+			Down:        u.Down,
+			Maintenance: u.Maintenance,
+		})
+	}
+	return result
+}
+
+// maintenanceBanners returns the banner of every maintenance window with a banner message
+// that is active at the given time for any of the monitors, each window once. Windows
+// are evaluated the same way as for suppressing checks and alerts.
+func (c *Controller) maintenanceBanners(ctx context.Context, monitorIDs []string, at time.Time) []*MaintenanceBannerDTO {
+	banners := []*MaintenanceBannerDTO{}
+	if c.maintenanceService == nil {
+		return banners
+	}
+
+	seen := make(map[string]bool)
+	for _, monitorID := range monitorIDs {
+		maintenances, err := c.maintenanceService.GetMaintenancesByMonitorID(ctx, monitorID)
+		if err != nil {
+			c.logger.Errorw("Failed to get maintenances for monitor", "error", err, "monitorID", monitorID)
+			continue
+		}
+
+		for _, m := range maintenances {
+			if seen[m.ID] || m.BannerMessage == nil || strings.TrimSpace(*m.BannerMessage) == "" {
+				continue
+			}
+			active, err := c.maintenanceService.IsUnderMaintenanceAt(ctx, m, at)
+			if err != nil {
+				c.logger.Warnw("Failed to get maintenance status", "error", err, "maintenanceID", m.ID)
+				continue
+			}
+			if !active {
+				continue
+			}
+
+			seen[m.ID] = true
+			banners = append(banners, &MaintenanceBannerDTO{
+				MaintenanceID: m.ID,
+				Title:         m.Title,
+				Message:       *m.BannerMessage,
+			})
+		}
+	}
+
+	return banners
+}
+
+// scheduledMaintenances returns every maintenance of the monitors that is in progress at
+// the given time or has a window later on, each once. Maintenances in progress come
+// first, then the others by the start of their next window.
+func (c *Controller) scheduledMaintenances(ctx context.Context, monitorIDs []string, at time.Time) []*ScheduledMaintenanceDTO {
+	scheduled := []*ScheduledMaintenanceDTO{}
+	if c.maintenanceService == nil {
+		return scheduled
+	}
+
+	seen := make(map[string]bool)
+	for _, monitorID := range monitorIDs {
+		maintenances, err := c.maintenanceService.GetMaintenancesByMonitorID(ctx, monitorID)
+		if err != nil {
+			c.logger.Errorw("Failed to get maintenances for monitor", "error", err, "monitorID", monitorID)
+			continue
+		}
+
+		for _, m := range maintenances {
+			if seen[m.ID] {
+				continue
+			}
+			seen[m.ID] = true
+
+			inProgress, err := c.maintenanceService.IsUnderMaintenanceAt(ctx, m, at)
+			if err != nil {
+				c.logger.Warnw("Failed to get maintenance status", "error", err, "maintenanceID", m.ID)
+				continue
+			}
+			window, err := c.maintenanceService.NextWindow(ctx, m, at)
+			if err != nil {
+				c.logger.Warnw("Failed to get next maintenance window", "error", err, "maintenanceID", m.ID)
+				continue
+			}
+			if !inProgress && window == nil {
+				continue
+			}
+
+			dto := &ScheduledMaintenanceDTO{
+				MaintenanceID: m.ID,
+				Title:         m.Title,
+				Description:   m.Description,
+				Strategy:      m.Strategy,
+				InProgress:    inProgress,
+			}
+			if window != nil {
+				dto.StartsAt, dto.EndsAt = &window.Start, &window.End
+			}
+			scheduled = append(scheduled, dto)
+		}
+	}
+
+	sort.SliceStable(scheduled, func(i, j int) bool {
+		a, b := scheduled[i], scheduled[j]
+		if a.InProgress != b.InProgress {
+			return a.InProgress
+		}
+		if a.StartsAt == nil || b.StartsAt == nil {
+			return a.StartsAt == nil && b.StartsAt != nil
+		}
+		return a.StartsAt.Before(*b.StartsAt)
+	})
+	return scheduled
+}
@@ -0,0 +1,266 @@
+package status_page
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"peekaping/internal/modules/heartbeat"
+	"peekaping/internal/modules/maintenance"
+	"peekaping/internal/modules/shared"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+func up() *heartbeat.Model {
+	return &heartbeat.Model{Status: shared.MonitorStatusUp}
+}
+
+func degraded() *heartbeat.Model {
+	return &heartbeat.Model{Status: shared.MonitorStatusUp, ErrorCategory: shared.ErrorCategoryDegraded}
+}
+
+func TestPublicHeartbeatStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		page     *Model
+		hb       *heartbeat.Model
+		expected shared.MonitorStatus
+	}{
+		{"degraded shown distinctly", &Model{ShowDegraded: true}, degraded(), shared.MonitorStatusDegraded},
+		{"body change shown as degraded", &Model{ShowDegraded: true}, &heartbeat.Model{Status: shared.MonitorStatusUp, ErrorCategory: shared.ErrorCategoryBodyChanged}, shared.MonitorStatusDegraded},
+		{"degraded hidden", &Model{}, degraded(), shared.MonitorStatusUp},
+		{"healthy monitor", &Model{ShowDegraded: true}, up(), shared.MonitorStatusUp},
+		{"down keeps its status", &Model{ShowDegraded: true}, &heartbeat.Model{Status: shared.MonitorStatusDown, ErrorCategory: shared.ErrorCategoryNetwork}, shared.MonitorStatusDown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, publicHeartbeatStatus(tt.page, tt.hb))
+		})
+	}
+}
+
+func TestOverallStatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold int
+		latest    []*heartbeat.Model
+		expected  shared.MonitorStatus
+	}{
+		{"all up", 1, []*heartbeat.Model{up(), up()}, shared.MonitorStatusUp},
+		{"no monitors", 1, nil, shared.MonitorStatusUp},
+		{"degraded counts against the page", 1, []*heartbeat.Model{up(), degraded()}, shared.MonitorStatusDegraded},
+		{"degraded ignored when threshold is 0", 0, []*heartbeat.Model{up(), degraded()}, shared.MonitorStatusUp},
+		{"below threshold", 2, []*heartbeat.Model{up(), degraded()}, shared.MonitorStatusUp},
+		{"threshold reached", 2, []*heartbeat.Model{degraded(), degraded(), up()}, shared.MonitorStatusDegraded},
+		{"down wins over degraded", 1, []*heartbeat.Model{degraded(), {Status: shared.MonitorStatusDown}}, shared.MonitorStatusDown},
+		{"degraded wins over maintenance", 1, []*heartbeat.Model{degraded(), {Status: shared.MonitorStatusMaintenance}}, shared.MonitorStatusDegraded},
+		{"maintenance", 1, []*heartbeat.Model{up(), {Status: shared.MonitorStatusMaintenance}}, shared.MonitorStatusMaintenance},
+		{"pending counts as up", 1, []*heartbeat.Model{{Status: shared.MonitorStatusPending}}, shared.MonitorStatusUp},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := &Model{DegradedThreshold: tt.threshold}
+			assert.Equal(t, tt.expected, overallStatus(page, tt.latest))
+		})
+	}
+}
+
+func TestOverallIndicator(t *testing.T) {
+	down := func() *heartbeat.Model { return &heartbeat.Model{Status: shared.MonitorStatusDown} }
+
+	tests := []struct {
+		name     string
+		latest   []*heartbeat.Model
+		expected string
+	}{
+		{"no monitors", nil, IndicatorOperational},
+		{"all up", []*heartbeat.Model{up(), up()}, IndicatorOperational},
+		{"maintenance is operational", []*heartbeat.Model{up(), {Status: shared.MonitorStatusMaintenance}}, IndicatorOperational},
+		{"pending is operational", []*heartbeat.Model{{Status: shared.MonitorStatusPending}}, IndicatorOperational},
+		{"degraded status", []*heartbeat.Model{up(), degraded()}, IndicatorDegraded},
+		{"minority down", []*heartbeat.Model{down(), up(), up()}, IndicatorDegraded},
+		{"half down", []*heartbeat.Model{down(), up()}, IndicatorMajorOutage},
+		{"all down", []*heartbeat.Model{down(), down()}, IndicatorMajorOutage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := &Model{DegradedThreshold: 1}
+			assert.Equal(t, tt.expected, overallIndicator(page, tt.latest))
+		})
+	}
+}
+
+func TestAverageUptime(t *testing.T) {
+	pct := func(v float64) *float64 { return &v }
+
+	assert.Nil(t, averageUptime(nil))
+	assert.Nil(t, averageUptime([]*float64{nil, nil}))
+	assert.InDelta(t, 99.0, *averageUptime([]*float64{pct(100), nil, pct(98)}), 0.0001)
+}
+
+func TestUptimeWindowDays(t *testing.T) {
+	assert.Equal(t, DefaultUptimeWindowDays, uptimeWindowDays(&Model{}))
+	assert.Equal(t, 30, uptimeWindowDays(&Model{UptimeWindowDays: 30}))
+}
+
+type MockMaintenanceService struct {
+	mock.Mock
+}
+
+func (m *MockMaintenanceService) Create(ctx context.Context, entity *maintenance.CreateUpdateDto) (*maintenance.Model, error) {
+	args := m.Called(ctx, entity)
+	return args.Get(0).(*maintenance.Model), args.Error(1)
+}
+
+func (m *MockMaintenanceService) FindByID(ctx context.Context, id string) (*maintenance.Model, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*maintenance.Model), args.Error(1)
+}
+
+func (m *MockMaintenanceService) FindAll(ctx context.Context, page int, limit int, q string, strategy string) ([]*maintenance.Model, error) {
+	args := m.Called(ctx, page, limit, q, strategy)
+	return args.Get(0).([]*maintenance.Model), args.Error(1)
+}
+
+func (m *MockMaintenanceService) UpdateFull(ctx context.Context, id string, entity *maintenance.CreateUpdateDto) (*maintenance.Model, error) {
+	args := m.Called(ctx, id, entity)
+	return args.Get(0).(*maintenance.Model), args.Error(1)
+}
+
+func (m *MockMaintenanceService) UpdatePartial(ctx context.Context, id string, entity *maintenance.PartialUpdateDto) (*maintenance.Model, error) {
+	args := m.Called(ctx, id, entity)
+	return args.Get(0).(*maintenance.Model), args.Error(1)
+}
+
+func (m *MockMaintenanceService) Delete(ctx context.Context, id string) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *MockMaintenanceService) SetActive(ctx context.Context, id string, active bool) (*maintenance.Model, error) {
+	args := m.Called(ctx, id, active)
+	return args.Get(0).(*maintenance.Model), args.Error(1)
+}
+
+func (m *MockMaintenanceService) SetApproval(ctx context.Context, id string, status string, approvedBy string) (*maintenance.Model, error) {
+	args := m.Called(ctx, id, status, approvedBy)
+	return args.Get(0).(*maintenance.Model), args.Error(1)
+}
+
+func (m *MockMaintenanceService) IsUnderMaintenance(ctx context.Context, maint *maintenance.Model) (bool, error) {
+	args := m.Called(ctx, maint)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMaintenanceService) IsUnderMaintenanceAt(ctx context.Context, maint *maintenance.Model, at time.Time) (bool, error) {
+	args := m.Called(ctx, maint, at)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMaintenanceService) NextWindow(ctx context.Context, maint *maintenance.Model, after time.Time) (*maintenance.Window, error) {
+	args := m.Called(ctx, maint, after)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*maintenance.Window), args.Error(1)
+}
+
+func (m *MockMaintenanceService) GetMaintenancesByMonitorID(ctx context.Context, monitorID string) ([]*maintenance.Model, error) {
+	args := m.Called(ctx, monitorID)
+	return args.Get(0).([]*maintenance.Model), args.Error(1)
+}
+
+func (m *MockMaintenanceService) GetMonitors(ctx context.Context, id string) ([]string, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockMaintenanceService) EndOnRecovery(ctx context.Context, maintenance *maintenance.Model, latest map[string][]*shared.HeartBeatModel) (bool, error) {
+	args := m.Called(ctx, maintenance, latest)
+	return args.Bool(0), args.Error(1)
+}
+
+func TestMaintenanceBanners(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	banner := func(s string) *string { return &s }
+
+	active := &maintenance.Model{ID: "maint-active", Title: "Database upgrade", BannerMessage: banner("We are upgrading the database.")}
+	inactive := &maintenance.Model{ID: "maint-later", Title: "Network work", BannerMessage: banner("Network maintenance tonight.")}
+	noBanner := &maintenance.Model{ID: "maint-silent", Title: "Silent window"}
+
+	maintenanceSvc := new(MockMaintenanceService)
+	maintenanceSvc.On("GetMaintenancesByMonitorID", ctx, "mon-1").Return([]*maintenance.Model{active, inactive, noBanner}, nil)
+	maintenanceSvc.On("GetMaintenancesByMonitorID", ctx, "mon-2").Return([]*maintenance.Model{active}, nil)
+	maintenanceSvc.On("GetMaintenancesByMonitorID", ctx, "mon-3").Return([]*maintenance.Model{}, nil)
+	maintenanceSvc.On("IsUnderMaintenanceAt", ctx, active, now).Return(true, nil)
+	maintenanceSvc.On("IsUnderMaintenanceAt", ctx, inactive, now).Return(false, nil)
+
+	controller := NewController(nil, nil, nil, maintenanceSvc, nil, nil, zap.NewNop().Sugar())
+
+	t.Run("only active windows of the page's monitors, each once", func(t *testing.T) {
+		banners := controller.maintenanceBanners(ctx, []string{"mon-1", "mon-2"}, now)
+
+		assert.Equal(t, []*MaintenanceBannerDTO{{
+			MaintenanceID: "maint-active",
+			Title:         "Database upgrade",
+			Message:       "We are upgrading the database.",
+		}}, banners)
+	})
+
+	t.Run("no banner when the page's monitors have no active window", func(t *testing.T) {
+		assert.Empty(t, controller.maintenanceBanners(ctx, []string{"mon-3"}, now))
+	})
+
+	maintenanceSvc.AssertNotCalled(t, "IsUnderMaintenanceAt", ctx, noBanner, now)
+}
+
+func TestDailyUptimeDays(t *testing.T) {
+	assert.Equal(t, DefaultUptimeWindowDays, dailyUptimeDays(&Model{}, ""))
+	assert.Equal(t, 30, dailyUptimeDays(&Model{UptimeWindowDays: 30}, ""))
+	assert.Equal(t, 7, dailyUptimeDays(&Model{UptimeWindowDays: 30}, "7"))
+	assert.Equal(t, 30, dailyUptimeDays(&Model{UptimeWindowDays: 30}, "0"))
+	assert.Equal(t, 30, dailyUptimeDays(&Model{UptimeWindowDays: 30}, "1000"))
+	assert.Equal(t, 30, dailyUptimeDays(&Model{UptimeWindowDays: 30}, "week"))
+}
+
+func TestScheduledMaintenances(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 10, 8, 12, 0, 0, 0, time.UTC)
+
+	manual := &maintenance.Model{ID: "maint-manual", Title: "Emergency fix", Strategy: "manual"}
+	weekly := &maintenance.Model{ID: "maint-weekly", Title: "Backups", Description: "Nightly backups", Strategy: "recurring-weekday"}
+	upgrade := &maintenance.Model{ID: "maint-upgrade", Title: "Database upgrade", Strategy: "single"}
+	over := &maintenance.Model{ID: "maint-over", Title: "Old window", Strategy: "single"}
+
+	weeklyWindow := &maintenance.Window{Start: now.Add(5 * 24 * time.Hour), End: now.Add(5*24*time.Hour + 2*time.Hour)}
+	upgradeWindow := &maintenance.Window{Start: now.Add(-time.Hour), End: now.Add(time.Hour)}
+
+	maintenanceSvc := new(MockMaintenanceService)
+	maintenanceSvc.On("GetMaintenancesByMonitorID", ctx, "mon-1").Return([]*maintenance.Model{weekly, over, upgrade}, nil)
+	maintenanceSvc.On("GetMaintenancesByMonitorID", ctx, "mon-2").Return([]*maintenance.Model{manual, weekly}, nil)
+	maintenanceSvc.On("IsUnderMaintenanceAt", ctx, weekly, now).Return(false, nil)
+	maintenanceSvc.On("IsUnderMaintenanceAt", ctx, over, now).Return(false, nil)
+	maintenanceSvc.On("IsUnderMaintenanceAt", ctx, upgrade, now).Return(true, nil)
+	maintenanceSvc.On("IsUnderMaintenanceAt", ctx, manual, now).Return(true, nil)
+	maintenanceSvc.On("NextWindow", ctx, weekly, now).Return(weeklyWindow, nil)
+	maintenanceSvc.On("NextWindow", ctx, over, now).Return(nil, nil)
+	maintenanceSvc.On("NextWindow", ctx, upgrade, now).Return(upgradeWindow, nil)
+	maintenanceSvc.On("NextWindow", ctx, manual, now).Return(nil, nil)
+
+	controller := NewController(nil, nil, nil, maintenanceSvc, nil, nil, zap.NewNop().Sugar())
+	scheduled := controller.scheduledMaintenances(ctx, []string{"mon-1", "mon-2"}, now)
+
+	// In progress first, the manual one without a window ahead of the rest, then upcoming
+	assert.Equal(t, []*ScheduledMaintenanceDTO{
+		{MaintenanceID: "maint-manual", Title: "Emergency fix", Strategy: "manual", InProgress: true},
+		{MaintenanceID: "maint-upgrade", Title: "Database upgrade", Strategy: "single", InProgress: true, StartsAt: &upgradeWindow.Start, EndsAt: &upgradeWindow.End},
+		{MaintenanceID: "maint-weekly", Title: "Backups", Description: "Nightly backups", Strategy: "recurring-weekday", StartsAt: &weeklyWindow.Start, EndsAt: &weeklyWindow.End},
+	}, scheduled)
+	maintenanceSvc.AssertNumberOfCalls(t, "NextWindow", 4)
+}
@@ -0,0 +1,47 @@
+package status_page_subscriber
+
+import (
+	"context"
+	"peekaping/internal/infra"
+	"peekaping/internal/modules/events"
+	"peekaping/internal/modules/heartbeat"
+
+	"go.uber.org/zap"
+)
+
+// EventListener notifies status page subscribers when monitors go up or down
+type EventListener struct {
+	service Service
+	logger  *zap.SugaredLogger
+}
+
+func NewEventListener(service Service, logger *zap.SugaredLogger) *EventListener {
+	return &EventListener{
+		service: service,
+		logger:  logger.Named("[status-page-subscriber-listener]"),
+	}
+}
+
+// Subscribe subscribes to ImportantHeartbeat events, the status changes notification
+// channels are sent as well
+func (l *EventListener) Subscribe(eventBus events.EventBus) {
+	eventBus.Subscribe(events.ImportantHeartbeat, l.handleImportantHeartbeat)
+}
+
+func (l *EventListener) handleImportantHeartbeat(event events.Event) {
+	hb, ok := infra.UnmarshalEventPayload[heartbeat.Model](event)
+	if !ok {
+		l.logger.Errorf("Failed to unmarshal heartbeat event payload")
+		return
+	}
+
+	// Checks inside a maintenance window keep running when alerts are suppressed,
+	// subscribers aren't told about them just like notification channels
+	if hb.AlertsSuppressed {
+		return
+	}
+
+	if err := l.service.NotifyStatusChange(context.Background(), hb); err != nil {
+		l.logger.Errorw("Failed to notify status page subscribers", "monitor_id", hb.MonitorID, "error", err)
+	}
+}
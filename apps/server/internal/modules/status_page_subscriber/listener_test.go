@@ -0,0 +1,34 @@
+package status_page_subscriber
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"peekaping/internal/modules/events"
+)
+
+func TestEventListener_SkipsSuppressedHeartbeats(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+
+	require.NoError(t, env.service.Subscribe(ctx, env.page, &SubscribeDto{Type: TypeEmail, Email: "jane@example.com"}))
+	subscribers, _ := env.service.FindByStatusPageID(ctx, env.page.ID)
+	_, err := env.service.Confirm(ctx, subscribers[0].ConfirmToken)
+	require.NoError(t, err)
+
+	listener := NewEventListener(env.service, zap.NewNop().Sugar())
+
+	suppressed := downBeat()
+	suppressed.AlertsSuppressed = true
+	listener.handleImportantHeartbeat(events.Event{Type: events.ImportantHeartbeat, Payload: suppressed})
+	assert.Len(t, env.email.sent(), 1, "only the confirmation email is sent")
+
+	listener.handleImportantHeartbeat(events.Event{Type: events.ImportantHeartbeat, Payload: downBeat()})
+	emails := env.email.sent()
+	require.Len(t, emails, 2)
+	assert.Equal(t, "[Acme Status] API is down", emails[1].subject)
+}
@@ -0,0 +1,182 @@
+package status_page_subscriber
+
+import (
+	"errors"
+	"net/http"
+	"peekaping/internal/modules/status_page"
+	"peekaping/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type Controller struct {
+	service           Service
+	statusPageService status_page.Service
+	access            *status_page.AccessGuard
+	logger            *zap.SugaredLogger
+}
+
+func NewController(
+	service Service,
+	statusPageService status_page.Service,
+	access *status_page.AccessGuard,
+	logger *zap.SugaredLogger,
+) *Controller {
+	return &Controller{
+		service:           service,
+		statusPageService: statusPageService,
+		access:            access,
+		logger:            logger,
+	}
+}
+
+// @Router    /status-pages/{slug}/subscribe [post]
+// @Summary   Subscribe to status changes of a status page
+// @Description Email subscribers receive a confirmation link and are notified once they opened it
+// @Tags      Status Pages
+// @Accept    json
+// @Produce   json
+// @Param     slug path string true "Status Page Slug"
+// @Param     X-Status-Page-Password header string false "Password of a protected status page"
+// @Param     body body SubscribeDto true "Subscription"
+// @Success   202  {object}  utils.ApiResponse[any]
+// @Failure   400  {object}  utils.APIError[any]
+// @Failure   401  {object}  utils.APIError[any]
+// @Failure   404  {object}  utils.APIError[any]
+// @Failure   429  {object}  utils.APIError[any]
+// @Failure   500  {object}  utils.APIError[any]
+func (c *Controller) Subscribe(ctx *gin.Context) {
+	var dto SubscribeDto
+	if err := ctx.ShouldBindJSON(&dto); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewFailResponse(err.Error()))
+		return
+	}
+	if err := utils.Validate.Struct(&dto); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewFailResponse(err.Error()))
+		return
+	}
+
+	slug := ctx.Param("slug")
+	page, err := c.statusPageService.FindBySlug(ctx, slug)
+	if err != nil {
+		c.logger.Errorw("Failed to get status page by slug", "error", err, "slug", slug)
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
+		return
+	}
+	if page == nil {
+		ctx.JSON(http.StatusNotFound, utils.NewFailResponse("Status page not found"))
+		return
+	}
+
+	if !c.access.Authorize(ctx, page) {
+		return
+	}
+
+	if err := c.service.Subscribe(ctx, page, &dto); err != nil {
+		if errors.Is(err, ErrSubscriptionsDisabled) || errors.Is(err, ErrEmailUnavailable) || errors.Is(err, ErrInvalidWebhookURL) {
+			ctx.JSON(http.StatusBadRequest, utils.NewFailResponse(err.Error()))
+			return
+		}
+		c.logger.Errorw("Failed to subscribe to status page", "error", err, "slug", slug)
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
+		return
+	}
+
+	message := "Subscribed successfully"
+	if dto.Type == TypeEmail {
+		message = "Check your email to confirm the subscription"
+	}
+	ctx.JSON(http.StatusAccepted, utils.NewSuccessResponse[any](message, nil))
+}
+
+// @Router    /status-pages/subscriptions/confirm [get]
+// @Summary   Confirm an email subscription to a status page
+// @Tags      Status Pages
+// @Produce   json
+// @Param     token query string true "Confirmation token from the email"
+// @Success   200  {object}  utils.ApiResponse[any]
+// @Failure   404  {object}  utils.APIError[any]
+// @Failure   500  {object}  utils.APIError[any]
+func (c *Controller) Confirm(ctx *gin.Context) {
+	if _, err := c.service.Confirm(ctx, ctx.Query("token")); err != nil {
+		if errors.Is(err, ErrSubscriberNotFound) {
+			ctx.JSON(http.StatusNotFound, utils.NewFailResponse(err.Error()))
+			return
+		}
+		c.logger.Errorw("Failed to confirm status page subscription", "error", err)
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewSuccessResponse[any]("Subscription confirmed", nil))
+}
+
+// @Router    /status-pages/subscriptions/unsubscribe [get]
+// @Summary   Unsubscribe from a status page
+// @Tags      Status Pages
+// @Produce   json
+// @Param     token query string true "Unsubscribe token from a notification"
+// @Success   200  {object}  utils.ApiResponse[any]
+// @Failure   404  {object}  utils.APIError[any]
+// @Failure   500  {object}  utils.APIError[any]
+func (c *Controller) Unsubscribe(ctx *gin.Context) {
+	if _, err := c.service.Unsubscribe(ctx, ctx.Query("token")); err != nil {
+		if errors.Is(err, ErrSubscriberNotFound) {
+			ctx.JSON(http.StatusNotFound, utils.NewFailResponse(err.Error()))
+			return
+		}
+		c.logger.Errorw("Failed to unsubscribe from status page", "error", err)
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewSuccessResponse[any]("Unsubscribed successfully", nil))
+}
+
+// @Router    /status-pages/{id}/subscribers [get]
+// @Summary   Get the subscribers of a status page
+// @Tags      Status Pages
+// @Produce   json
+// @Security  JwtAuth
+// @Security  ApiKeyAuth
+// @Param     id   path      string  true  "Status Page ID"
+// @Success   200  {object}  utils.ApiResponse[[]Model]
+// @Failure   500  {object}  utils.APIError[any]
+func (c *Controller) FindByStatusPageID(ctx *gin.Context) {
+	id := ctx.Param("id")
+	subscribers, err := c.service.FindByStatusPageID(ctx, id)
+	if err != nil {
+		c.logger.Errorw("Failed to get status page subscribers", "error", err, "id", id)
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewSuccessResponse("success", subscribers))
+}
+
+// @Router    /status-pages/{id}/subscribers/{subscriberId} [delete]
+// @Summary   Remove a subscriber from a status page
+// @Tags      Status Pages
+// @Produce   json
+// @Security  JwtAuth
+// @Security  ApiKeyAuth
+// @Param     id           path  string  true  "Status Page ID"
+// @Param     subscriberId path  string  true  "Subscriber ID"
+// @Success   200  {object}  utils.ApiResponse[any]
+// @Failure   404  {object}  utils.APIError[any]
+// @Failure   500  {object}  utils.APIError[any]
+func (c *Controller) Delete(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if err := c.service.Delete(ctx, id, ctx.Param("subscriberId")); err != nil {
+		if errors.Is(err, ErrSubscriberNotFound) {
+			ctx.JSON(http.StatusNotFound, utils.NewFailResponse(err.Error()))
+			return
+		}
+		c.logger.Errorw("Failed to delete status page subscriber", "error", err, "id", id)
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewSuccessResponse[any]("Subscriber deleted successfully", nil))
+}
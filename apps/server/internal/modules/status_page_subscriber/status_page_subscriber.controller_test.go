@@ -0,0 +1,97 @@
+package status_page_subscriber
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"peekaping/internal/config"
+	"peekaping/internal/modules/status_page"
+)
+
+// fakeStatusPageService serves a single page protected by the password "team-secret"
+type fakeStatusPageService struct {
+	status_page.Service
+	page *status_page.Model
+}
+
+func (f *fakeStatusPageService) FindBySlug(ctx context.Context, slug string) (*status_page.Model, error) {
+	if slug != f.page.Slug {
+		return nil, nil
+	}
+	return f.page, nil
+}
+
+func (f *fakeStatusPageService) VerifyPassword(page *status_page.Model, password string) bool {
+	return password == "team-secret"
+}
+
+// fakeBruteforceService keeps the password failures in memory
+type fakeBruteforceService struct {
+	failures map[string]int
+	locked   map[string]time.Time
+}
+
+func (f *fakeBruteforceService) IsLocked(ctx context.Context, key string) (bool, time.Time, error) {
+	until, ok := f.locked[key]
+	return ok, until, nil
+}
+
+func (f *fakeBruteforceService) OnFailure(ctx context.Context, key string, now time.Time, window time.Duration, max int, lockout time.Duration) (bool, time.Time, error) {
+	f.failures[key]++
+	if f.failures[key] >= max {
+		f.locked[key] = now.Add(lockout)
+		return true, f.locked[key], nil
+	}
+	return false, time.Time{}, nil
+}
+
+func (f *fakeBruteforceService) Reset(ctx context.Context, key string) error {
+	delete(f.failures, key)
+	delete(f.locked, key)
+	return nil
+}
+
+func TestController_Subscribe_PasswordLockout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	statusPageService := &fakeStatusPageService{page: &status_page.Model{
+		ID:                "page-1",
+		Slug:              "internal",
+		PasswordHash:      "hash",
+		PasswordProtected: true,
+	}}
+	bruteforceService := &fakeBruteforceService{failures: map[string]int{}, locked: map[string]time.Time{}}
+	cfg := &config.Config{BruteforceMaxAttempts: 3, BruteforceWindow: time.Minute, BruteforceLockout: time.Minute}
+	access := status_page.NewAccessGuard(statusPageService, bruteforceService, cfg, zap.NewNop().Sugar())
+	controller := NewController(nil, statusPageService, access, zap.NewNop().Sugar())
+
+	router := gin.New()
+	router.POST("/status-pages/:slug/subscribe", controller.Subscribe)
+
+	subscribe := func(password string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/status-pages/internal/subscribe",
+			strings.NewReader(`{"type": "email", "email": "user@example.com"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(status_page.StatusPagePasswordHeader, password)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	for range 3 {
+		w := subscribe("guess")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	}
+
+	// The right password is not even checked once the client is locked out
+	w := subscribe("team-secret")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+}
@@ -0,0 +1,16 @@
+package status_page_subscriber
+
+import (
+	"peekaping/internal/config"
+	"peekaping/internal/utils"
+
+	"go.uber.org/dig"
+)
+
+func RegisterDependencies(container *dig.Container, cfg *config.Config) {
+	utils.RegisterRepositoryByDBType(container, cfg, NewSQLRepository, NewMongoRepository)
+	container.Provide(NewService)
+	container.Provide(NewController)
+	container.Provide(NewRoute)
+	container.Provide(NewEventListener)
+}
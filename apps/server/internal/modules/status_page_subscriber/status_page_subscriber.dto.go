@@ -0,0 +1,30 @@
+package status_page_subscriber
+
+type SubscribeDto struct {
+	Type       string `json:"type" validate:"required,oneof=email webhook" example:"email"`
+	Email      string `json:"email,omitempty" validate:"required_if=Type email,omitempty,email,max=255" example:"jane@example.com"`
+	WebhookURL string `json:"webhook_url,omitempty" validate:"required_if=Type webhook,omitempty,url,max=2048" example:"https://example.com/hooks/status"`
+}
+
+// WebhookPayload is the JSON body posted to webhook subscribers when a monitor of the
+// status page changes status
+type WebhookPayload struct {
+	StatusPage WebhookStatusPage `json:"status_page"`
+	Monitor    WebhookMonitor    `json:"monitor"`
+	// Status is "up" or "down"
+	Status         string `json:"status"`
+	Message        string `json:"message"`
+	Time           string `json:"time"`
+	UnsubscribeURL string `json:"unsubscribe_url"`
+}
+
+type WebhookStatusPage struct {
+	Slug  string `json:"slug"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+type WebhookMonitor struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
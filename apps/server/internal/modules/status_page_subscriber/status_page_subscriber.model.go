@@ -0,0 +1,28 @@
+package status_page_subscriber
+
+import "time"
+
+// Subscriber types
+const (
+	TypeEmail   = "email"
+	TypeWebhook = "webhook"
+)
+
+// Model is a visitor subscribed to the status changes of a status page. Email
+// subscribers are only notified once they confirmed their address.
+type Model struct {
+	ID           string `json:"id"`
+	StatusPageID string `json:"status_page_id"`
+	Type         string `json:"type"`
+	// Target is the email address or the webhook URL notifications are sent to
+	Target    string `json:"target"`
+	Confirmed bool   `json:"confirmed"`
+	// ConfirmToken and UnsubscribeToken are the secrets of the confirmation and
+	// unsubscribe links
+	ConfirmToken     string `json:"-"`
+	UnsubscribeToken string `json:"-"`
+	// ConfirmationSentAt is when the last confirmation link was sent
+	ConfirmationSentAt *time.Time `json:"-"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
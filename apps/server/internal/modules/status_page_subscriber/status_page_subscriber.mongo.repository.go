@@ -0,0 +1,184 @@
+package status_page_subscriber
+
+import (
+	"context"
+	"peekaping/internal/config"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoModel struct {
+	ID                 primitive.ObjectID `bson:"_id"`
+	StatusPageID       string             `bson:"status_page_id"`
+	Type               string             `bson:"type"`
+	Target             string             `bson:"target"`
+	Confirmed          bool               `bson:"confirmed"`
+	ConfirmToken       string             `bson:"confirm_token"`
+	UnsubscribeToken   string             `bson:"unsubscribe_token"`
+	ConfirmationSentAt *time.Time         `bson:"confirmation_sent_at,omitempty"`
+	CreatedAt          time.Time          `bson:"created_at"`
+	UpdatedAt          time.Time          `bson:"updated_at"`
+}
+
+func toDomainModel(mm *mongoModel) *Model {
+	return &Model{
+		ID:                 mm.ID.Hex(),
+		StatusPageID:       mm.StatusPageID,
+		Type:               mm.Type,
+		Target:             mm.Target,
+		Confirmed:          mm.Confirmed,
+		ConfirmToken:       mm.ConfirmToken,
+		UnsubscribeToken:   mm.UnsubscribeToken,
+		ConfirmationSentAt: mm.ConfirmationSentAt,
+		CreatedAt:          mm.CreatedAt,
+		UpdatedAt:          mm.UpdatedAt,
+	}
+}
+
+type MongoRepositoryImpl struct {
+	client     *mongo.Client
+	db         *mongo.Database
+	collection *mongo.Collection
+}
+
+func NewMongoRepository(client *mongo.Client, cfg *config.Config) Repository {
+	db := client.Database(cfg.DBName)
+	collection := db.Collection("status_page_subscriber")
+
+	// Create indexes
+	go func() {
+		_, _ = collection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+			{Keys: bson.D{{Key: "status_page_id", Value: 1}}},
+			{Keys: bson.D{{Key: "confirm_token", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "unsubscribe_token", Value: 1}}, Options: options.Index().SetUnique(true)},
+		})
+	}()
+
+	return &MongoRepositoryImpl{client, db, collection}
+}
+
+func (r *MongoRepositoryImpl) Create(ctx context.Context, entity *Model) (*Model, error) {
+	mm := &mongoModel{
+		ID:                 primitive.NewObjectID(),
+		StatusPageID:       entity.StatusPageID,
+		Type:               entity.Type,
+		Target:             entity.Target,
+		Confirmed:          entity.Confirmed,
+		ConfirmToken:       entity.ConfirmToken,
+		UnsubscribeToken:   entity.UnsubscribeToken,
+		ConfirmationSentAt: entity.ConfirmationSentAt,
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	}
+
+	_, err := r.collection.InsertOne(ctx, mm)
+	if err != nil {
+		return nil, err
+	}
+
+	return toDomainModel(mm), nil
+}
+
+func (r *MongoRepositoryImpl) findOne(ctx context.Context, filter bson.M) (*Model, error) {
+	var mm mongoModel
+	err := r.collection.FindOne(ctx, filter).Decode(&mm)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return toDomainModel(&mm), nil
+}
+
+func (r *MongoRepositoryImpl) FindByID(ctx context.Context, id string) (*Model, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	return r.findOne(ctx, bson.M{"_id": objectID})
+}
+
+func (r *MongoRepositoryImpl) FindByStatusPageID(ctx context.Context, statusPageID string) ([]*Model, error) {
+	options := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{"status_page_id": statusPageID}, options)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	entities := []*Model{}
+	for cursor.Next(ctx) {
+		var mm mongoModel
+		if err := cursor.Decode(&mm); err != nil {
+			return nil, err
+		}
+		entities = append(entities, toDomainModel(&mm))
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return entities, nil
+}
+
+func (r *MongoRepositoryImpl) FindByTarget(ctx context.Context, statusPageID, subscriberType, target string) (*Model, error) {
+	return r.findOne(ctx, bson.M{"status_page_id": statusPageID, "type": subscriberType, "target": target})
+}
+
+func (r *MongoRepositoryImpl) FindByConfirmToken(ctx context.Context, token string) (*Model, error) {
+	return r.findOne(ctx, bson.M{"confirm_token": token})
+}
+
+func (r *MongoRepositoryImpl) FindByUnsubscribeToken(ctx context.Context, token string) (*Model, error) {
+	return r.findOne(ctx, bson.M{"unsubscribe_token": token})
+}
+
+func (r *MongoRepositoryImpl) Confirm(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{"$set": bson.M{"confirmed": true, "updated_at": time.Now()}}
+	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
+	return err
+}
+
+func (r *MongoRepositoryImpl) MarkConfirmationSent(ctx context.Context, id string, sentAt, resendAfter time.Time) (bool, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return false, err
+	}
+
+	filter := bson.M{
+		"_id": objectID,
+		"$or": []bson.M{
+			// nil also matches subscribers without the field
+			{"confirmation_sent_at": nil},
+			{"confirmation_sent_at": bson.M{"$lte": resendAfter}},
+		},
+	}
+	update := bson.M{"$set": bson.M{"confirmation_sent_at": sentAt, "updated_at": time.Now()}}
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+func (r *MongoRepositoryImpl) Delete(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	return err
+}
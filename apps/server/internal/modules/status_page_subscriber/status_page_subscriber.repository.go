@@ -0,0 +1,22 @@
+package status_page_subscriber
+
+import (
+	"context"
+	"time"
+)
+
+type Repository interface {
+	Create(ctx context.Context, entity *Model) (*Model, error)
+	FindByID(ctx context.Context, id string) (*Model, error)
+	FindByStatusPageID(ctx context.Context, statusPageID string) ([]*Model, error)
+	// FindByTarget returns the subscriber of a page with the given type and target, nil
+	// when there is none
+	FindByTarget(ctx context.Context, statusPageID, subscriberType, target string) (*Model, error)
+	FindByConfirmToken(ctx context.Context, token string) (*Model, error)
+	FindByUnsubscribeToken(ctx context.Context, token string) (*Model, error)
+	Confirm(ctx context.Context, id string) error
+	// MarkConfirmationSent sets the confirmation sent time of a subscriber to sentAt
+	// unless a confirmation was sent after resendAfter, and reports whether it did
+	MarkConfirmationSent(ctx context.Context, id string, sentAt, resendAfter time.Time) (bool, error)
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,38 @@
+package status_page_subscriber
+
+import (
+	"peekaping/internal/modules/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Route struct {
+	controller *Controller
+	middleware *middleware.AuthChain
+}
+
+func NewRoute(
+	controller *Controller,
+	middleware *middleware.AuthChain,
+) *Route {
+	return &Route{
+		controller,
+		middleware,
+	}
+}
+
+func (uc *Route) ConnectRoute(
+	rg *gin.RouterGroup,
+	controller *Controller,
+) {
+	// Public routes
+	public := rg.Group("status-pages")
+	public.POST(":slug/subscribe", uc.controller.Subscribe)
+	public.GET("subscriptions/confirm", uc.controller.Confirm)
+	public.GET("subscriptions/unsubscribe", uc.controller.Unsubscribe)
+
+	router := rg.Group("status-pages")
+	router.Use(uc.middleware.AllAuth())
+	router.GET(":id/subscribers", uc.controller.FindByStatusPageID)
+	router.DELETE(":id/subscribers/:subscriberId", uc.controller.Delete)
+}
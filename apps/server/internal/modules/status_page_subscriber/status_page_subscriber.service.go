@@ -0,0 +1,474 @@
+package status_page_subscriber
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"peekaping/internal/config"
+	"peekaping/internal/modules/heartbeat"
+	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/monitor_status_page"
+	"peekaping/internal/modules/notification_channel"
+	"peekaping/internal/modules/notification_channel/providers"
+	"peekaping/internal/modules/shared"
+	"peekaping/internal/modules/status_page"
+	"peekaping/internal/version"
+	"strings"
+	"syscall"
+	"time"
+
+	"go.uber.org/dig"
+	"go.uber.org/zap"
+)
+
+const webhookTimeout = 10 * time.Second
+
+// confirmationResendCooldown is how long subscribing an unconfirmed address again
+// doesn't send another confirmation link, the endpoint is public and would
+// otherwise send any address as many emails as it is called
+const confirmationResendCooldown = 10 * time.Minute
+
+var (
+	ErrSubscriptionsDisabled = errors.New("subscriptions are disabled for this status page")
+	ErrEmailUnavailable      = errors.New("email subscriptions are not available for this status page")
+	ErrInvalidWebhookURL     = errors.New("webhook URL must be an http or https URL")
+	ErrSubscriberNotFound    = errors.New("subscription not found")
+)
+
+type Service interface {
+	// Subscribe adds a subscriber to page. Email subscribers are sent a confirmation link
+	// and only notified once they opened it, webhooks are notified right away.
+	Subscribe(ctx context.Context, page *status_page.Model, dto *SubscribeDto) error
+	Confirm(ctx context.Context, token string) (*Model, error)
+	Unsubscribe(ctx context.Context, token string) (*Model, error)
+	FindByStatusPageID(ctx context.Context, statusPageID string) ([]*Model, error)
+	Delete(ctx context.Context, statusPageID, id string) error
+
+	// NotifyStatusChange notifies the confirmed subscribers of the status pages showing
+	// the monitor of hb that it went up or down
+	NotifyStatusChange(ctx context.Context, hb *heartbeat.Model) error
+}
+
+// The parts of other services the subscriptions use
+type statusPageFinder interface {
+	FindByID(ctx context.Context, id string) (*status_page.Model, error)
+}
+
+type monitorStatusPageFinder interface {
+	GetStatusPagesForMonitor(ctx context.Context, monitorID string) ([]*monitor_status_page.Model, error)
+}
+
+type monitorFinder interface {
+	FindByID(ctx context.Context, id string) (*monitor.Model, error)
+}
+
+type channelFinder interface {
+	FindByID(ctx context.Context, id string) (*notification_channel.Model, error)
+}
+
+type emailSender interface {
+	Send(ctx context.Context, configJSON, message string, monitor *monitor.Model, heartbeat *heartbeat.Model) error
+}
+
+type ServiceImpl struct {
+	repository         Repository
+	statusPages        statusPageFinder
+	monitorStatusPages monitorStatusPageFinder
+	monitors           monitorFinder
+	channels           channelFinder
+	email              emailSender
+	httpClient         *http.Client
+	clientURL          string
+	logger             *zap.SugaredLogger
+}
+
+type ServiceParams struct {
+	dig.In
+	Repository                 Repository
+	StatusPageService          status_page.Service
+	MonitorStatusPageService   monitor_status_page.Service
+	MonitorService             monitor.Service
+	NotificationChannelService notification_channel.Service
+	Config                     *config.Config
+	Logger                     *zap.SugaredLogger
+}
+
+func NewService(p ServiceParams) Service {
+	logger := p.Logger.Named("[status-page-subscriber-service]")
+	return &ServiceImpl{
+		repository:         p.Repository,
+		statusPages:        p.StatusPageService,
+		monitorStatusPages: p.MonitorStatusPageService,
+		monitors:           p.MonitorService,
+		channels:           p.NotificationChannelService,
+		email:              providers.NewEmailSender(logger),
+		httpClient:         newWebhookClient(),
+		clientURL:          strings.TrimRight(p.Config.ClientURL, "/"),
+		logger:             logger,
+	}
+}
+
+func (s *ServiceImpl) Subscribe(ctx context.Context, page *status_page.Model, dto *SubscribeDto) error {
+	if !page.AllowSubscriptions {
+		return ErrSubscriptionsDisabled
+	}
+
+	var target, channelConfig string
+	switch dto.Type {
+	case TypeEmail:
+		target = strings.ToLower(strings.TrimSpace(dto.Email))
+		var err error
+		if channelConfig, err = s.emailChannelConfig(ctx, page); err != nil {
+			return err
+		}
+	case TypeWebhook:
+		parsed, err := url.Parse(dto.WebhookURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return ErrInvalidWebhookURL
+		}
+		target = dto.WebhookURL
+	default:
+		return fmt.Errorf("unknown subscriber type %q", dto.Type)
+	}
+
+	existing, err := s.repository.FindByTarget(ctx, page.ID, dto.Type, target)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		if existing.Confirmed {
+			return nil
+		}
+		// Subscribing again resends the confirmation link, at most once per cooldown
+		now := time.Now()
+		resend, err := s.repository.MarkConfirmationSent(ctx, existing.ID, now, now.Add(-confirmationResendCooldown))
+		if err != nil || !resend {
+			return err
+		}
+		return s.sendConfirmation(ctx, page, channelConfig, existing)
+	}
+
+	confirmToken, err := generateToken()
+	if err != nil {
+		return err
+	}
+	unsubscribeToken, err := generateToken()
+	if err != nil {
+		return err
+	}
+
+	model := &Model{
+		StatusPageID:     page.ID,
+		Type:             dto.Type,
+		Target:           target,
+		Confirmed:        dto.Type == TypeWebhook,
+		ConfirmToken:     confirmToken,
+		UnsubscribeToken: unsubscribeToken,
+	}
+	if dto.Type == TypeEmail {
+		now := time.Now()
+		model.ConfirmationSentAt = &now
+	}
+	subscriber, err := s.repository.Create(ctx, model)
+	if err != nil {
+		return err
+	}
+
+	if subscriber.Type == TypeEmail {
+		if err := s.sendConfirmation(ctx, page, channelConfig, subscriber); err != nil {
+			// Let the visitor try again
+			if deleteErr := s.repository.Delete(ctx, subscriber.ID); deleteErr != nil {
+				s.logger.Errorw("Failed to delete unconfirmed subscriber", "id", subscriber.ID, "error", deleteErr)
+			}
+			return err
+		}
+	}
+
+	s.logger.Infow("Status page subscriber added", "status_page_id", page.ID, "type", subscriber.Type)
+	return nil
+}
+
+func (s *ServiceImpl) Confirm(ctx context.Context, token string) (*Model, error) {
+	if token == "" {
+		return nil, ErrSubscriberNotFound
+	}
+	subscriber, err := s.repository.FindByConfirmToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if subscriber == nil {
+		return nil, ErrSubscriberNotFound
+	}
+
+	if !subscriber.Confirmed {
+		if err := s.repository.Confirm(ctx, subscriber.ID); err != nil {
+			return nil, err
+		}
+		subscriber.Confirmed = true
+	}
+	return subscriber, nil
+}
+
+func (s *ServiceImpl) Unsubscribe(ctx context.Context, token string) (*Model, error) {
+	if token == "" {
+		return nil, ErrSubscriberNotFound
+	}
+	subscriber, err := s.repository.FindByUnsubscribeToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if subscriber == nil {
+		return nil, ErrSubscriberNotFound
+	}
+
+	if err := s.repository.Delete(ctx, subscriber.ID); err != nil {
+		return nil, err
+	}
+	return subscriber, nil
+}
+
+func (s *ServiceImpl) FindByStatusPageID(ctx context.Context, statusPageID string) ([]*Model, error) {
+	return s.repository.FindByStatusPageID(ctx, statusPageID)
+}
+
+func (s *ServiceImpl) Delete(ctx context.Context, statusPageID, id string) error {
+	subscriber, err := s.repository.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if subscriber == nil || subscriber.StatusPageID != statusPageID {
+		return ErrSubscriberNotFound
+	}
+	return s.repository.Delete(ctx, id)
+}
+
+func (s *ServiceImpl) NotifyStatusChange(ctx context.Context, hb *heartbeat.Model) error {
+	var status string
+	switch hb.Status {
+	case shared.MonitorStatusUp:
+		status = "up"
+	case shared.MonitorStatusDown:
+		status = "down"
+	default:
+		return nil
+	}
+
+	links, err := s.monitorStatusPages.GetStatusPagesForMonitor(ctx, hb.MonitorID)
+	if err != nil {
+		return fmt.Errorf("failed to get status pages of monitor %s: %w", hb.MonitorID, err)
+	}
+
+	var mon *monitor.Model
+	var errs []error
+	for _, link := range links {
+		if !link.Active {
+			continue
+		}
+		page, err := s.statusPages.FindByID(ctx, link.StatusPageID)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if page == nil || !page.AllowSubscriptions {
+			continue
+		}
+
+		subscribers, err := s.repository.FindByStatusPageID(ctx, page.ID)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if len(subscribers) == 0 {
+			continue
+		}
+
+		if mon == nil {
+			if mon, err = s.monitors.FindByID(ctx, hb.MonitorID); err != nil {
+				return fmt.Errorf("failed to get monitor %s: %w", hb.MonitorID, err)
+			}
+			if mon == nil {
+				return nil
+			}
+		}
+
+		if err := s.notifyPage(ctx, page, subscribers, mon, hb, status); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *ServiceImpl) notifyPage(
+	ctx context.Context,
+	page *status_page.Model,
+	subscribers []*Model,
+	mon *monitor.Model,
+	hb *heartbeat.Model,
+	status string,
+) error {
+	message := fmt.Sprintf("%s is %s", mon.Name, status)
+	if status == "down" && hb.Msg != "" {
+		message = fmt.Sprintf("%s: %s", message, hb.Msg)
+	}
+
+	// Loaded on the first email subscriber
+	var channelConfig string
+	var channelErr error
+
+	var errs []error
+	for _, subscriber := range subscribers {
+		if !subscriber.Confirmed {
+			continue
+		}
+
+		var err error
+		switch subscriber.Type {
+		case TypeEmail:
+			if channelConfig == "" && channelErr == nil {
+				channelConfig, channelErr = s.emailChannelConfig(ctx, page)
+			}
+			if channelErr != nil {
+				err = channelErr
+				break
+			}
+			body := fmt.Sprintf("%s\n\nStatus page: %s\nUnsubscribe: %s",
+				message, s.pageURL(page), s.unsubscribeURL(subscriber))
+			err = s.sendEmail(ctx, channelConfig, subscriber.Target, fmt.Sprintf("[%s] %s is %s", page.Title, mon.Name, status), body)
+		case TypeWebhook:
+			err = s.postWebhook(ctx, subscriber.Target, &WebhookPayload{
+				StatusPage:     WebhookStatusPage{Slug: page.Slug, Title: page.Title, URL: s.pageURL(page)},
+				Monitor:        WebhookMonitor{ID: mon.ID, Name: mon.Name},
+				Status:         status,
+				Message:        message,
+				Time:           hb.Time.UTC().Format(time.RFC3339),
+				UnsubscribeURL: s.unsubscribeURL(subscriber),
+			})
+		}
+		if err != nil {
+			s.logger.Warnw("Failed to notify status page subscriber", "status_page_id", page.ID, "subscriber_id", subscriber.ID, "type", subscriber.Type, "error", err)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// emailChannelConfig returns the config of the SMTP channel a page sends emails to
+// subscribers with
+func (s *ServiceImpl) emailChannelConfig(ctx context.Context, page *status_page.Model) (string, error) {
+	if page.SubscriberChannelID == "" {
+		return "", ErrEmailUnavailable
+	}
+	channel, err := s.channels.FindByID(ctx, page.SubscriberChannelID)
+	if err != nil {
+		return "", err
+	}
+	if channel == nil || !channel.Active || channel.Type != "smtp" || channel.Config == nil {
+		return "", ErrEmailUnavailable
+	}
+	return *channel.Config, nil
+}
+
+func (s *ServiceImpl) sendConfirmation(ctx context.Context, page *status_page.Model, channelConfig string, subscriber *Model) error {
+	if subscriber.Type != TypeEmail {
+		return nil
+	}
+	confirmURL := fmt.Sprintf("%s/api/v1/status-pages/subscriptions/confirm?token=%s", s.clientURL, url.QueryEscape(subscriber.ConfirmToken))
+	body := fmt.Sprintf("Please confirm your subscription to status updates of %s by opening this link:\n%s\n\nIf you didn't subscribe, ignore this email.",
+		page.Title, confirmURL)
+	return s.sendEmail(ctx, channelConfig, subscriber.Target, fmt.Sprintf("Confirm your subscription to %s", page.Title), body)
+}
+
+// sendEmail sends an email with the SMTP settings of channelConfig to a single recipient
+func (s *ServiceImpl) sendEmail(ctx context.Context, channelConfig, to, subject, body string) error {
+	cfg, err := providers.GenericUnmarshal[providers.EmailConfig](channelConfig)
+	if err != nil {
+		return err
+	}
+	cfg.SMTPTo = to
+	cfg.SMTPCC = ""
+	cfg.SMTPBCC = ""
+	cfg.CustomSubject = subject
+	cfg.CustomBody = ""
+
+	configJSON, err := json.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	return s.email.Send(ctx, string(configJSON), body, nil, nil)
+}
+
+func (s *ServiceImpl) postWebhook(ctx context.Context, webhookURL string, payload *WebhookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Peekaping-Webhook/"+version.Version)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (s *ServiceImpl) pageURL(page *status_page.Model) string {
+	return fmt.Sprintf("%s/status/%s", s.clientURL, page.Slug)
+}
+
+func (s *ServiceImpl) unsubscribeURL(subscriber *Model) string {
+	return fmt.Sprintf("%s/api/v1/status-pages/subscriptions/unsubscribe?token=%s", s.clientURL, url.QueryEscape(subscriber.UnsubscribeToken))
+}
+
+func generateToken() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// newWebhookClient returns the client webhook subscribers are called with. Anyone can
+// subscribe a webhook to a page, so it refuses to connect to loopback, private and
+// link-local addresses.
+func newWebhookClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: webhookTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+				return fmt.Errorf("webhook address %s is not public", host)
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout:   webhookTimeout,
+		Transport: &http.Transport{DialContext: dialer.DialContext},
+	}
+}
+
+func isPublicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsMulticast())
+}
@@ -0,0 +1,429 @@
+package status_page_subscriber
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"peekaping/internal/modules/heartbeat"
+	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/monitor_status_page"
+	"peekaping/internal/modules/notification_channel"
+	"peekaping/internal/modules/notification_channel/providers"
+	"peekaping/internal/modules/shared"
+	"peekaping/internal/modules/status_page"
+)
+
+// memoryRepository keeps subscribers in memory
+type memoryRepository struct {
+	mu          sync.Mutex
+	nextID      int
+	subscribers map[string]*Model
+}
+
+func newMemoryRepository() *memoryRepository {
+	return &memoryRepository{subscribers: map[string]*Model{}}
+}
+
+func (r *memoryRepository) Create(ctx context.Context, entity *Model) (*Model, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	created := *entity
+	created.ID = fmt.Sprintf("subscriber-%d", r.nextID)
+	created.CreatedAt = time.Now()
+	created.UpdatedAt = created.CreatedAt
+	r.subscribers[created.ID] = &created
+	copied := created
+	return &copied, nil
+}
+
+func (r *memoryRepository) find(match func(*Model) bool) *Model {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, subscriber := range r.subscribers {
+		if match(subscriber) {
+			copied := *subscriber
+			return &copied
+		}
+	}
+	return nil
+}
+
+func (r *memoryRepository) FindByID(ctx context.Context, id string) (*Model, error) {
+	return r.find(func(m *Model) bool { return m.ID == id }), nil
+}
+
+func (r *memoryRepository) FindByStatusPageID(ctx context.Context, statusPageID string) ([]*Model, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var subscribers []*Model
+	for _, subscriber := range r.subscribers {
+		if subscriber.StatusPageID == statusPageID {
+			copied := *subscriber
+			subscribers = append(subscribers, &copied)
+		}
+	}
+	return subscribers, nil
+}
+
+func (r *memoryRepository) FindByTarget(ctx context.Context, statusPageID, subscriberType, target string) (*Model, error) {
+	return r.find(func(m *Model) bool {
+		return m.StatusPageID == statusPageID && m.Type == subscriberType && m.Target == target
+	}), nil
+}
+
+func (r *memoryRepository) FindByConfirmToken(ctx context.Context, token string) (*Model, error) {
+	return r.find(func(m *Model) bool { return m.ConfirmToken == token }), nil
+}
+
+func (r *memoryRepository) FindByUnsubscribeToken(ctx context.Context, token string) (*Model, error) {
+	return r.find(func(m *Model) bool { return m.UnsubscribeToken == token }), nil
+}
+
+func (r *memoryRepository) Confirm(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subscribers[id].Confirmed = true
+	return nil
+}
+
+func (r *memoryRepository) MarkConfirmationSent(ctx context.Context, id string, sentAt, resendAfter time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	subscriber := r.subscribers[id]
+	if subscriber.ConfirmationSentAt != nil && subscriber.ConfirmationSentAt.After(resendAfter) {
+		return false, nil
+	}
+	subscriber.ConfirmationSentAt = &sentAt
+	return true, nil
+}
+
+// sentConfirmationAt backdates the last confirmation sent to subscriber id
+func (r *memoryRepository) sentConfirmationAt(id string, at time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subscribers[id].ConfirmationSentAt = &at
+}
+
+func (r *memoryRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.subscribers, id)
+	return nil
+}
+
+type fakeStatusPages map[string]*status_page.Model
+
+func (f fakeStatusPages) FindByID(ctx context.Context, id string) (*status_page.Model, error) {
+	return f[id], nil
+}
+
+type fakeMonitorStatusPages map[string][]*monitor_status_page.Model
+
+func (f fakeMonitorStatusPages) GetStatusPagesForMonitor(ctx context.Context, monitorID string) ([]*monitor_status_page.Model, error) {
+	return f[monitorID], nil
+}
+
+type fakeMonitors map[string]*monitor.Model
+
+func (f fakeMonitors) FindByID(ctx context.Context, id string) (*monitor.Model, error) {
+	return f[id], nil
+}
+
+type fakeChannels map[string]*notification_channel.Model
+
+func (f fakeChannels) FindByID(ctx context.Context, id string) (*notification_channel.Model, error) {
+	return f[id], nil
+}
+
+type sentEmail struct {
+	to, subject, body string
+}
+
+// recordingEmailSender keeps the emails instead of sending them
+type recordingEmailSender struct {
+	mu     sync.Mutex
+	emails []sentEmail
+}
+
+func (r *recordingEmailSender) Send(ctx context.Context, configJSON, message string, _ *monitor.Model, _ *heartbeat.Model) error {
+	cfg, err := providers.GenericUnmarshal[providers.EmailConfig](configJSON)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.emails = append(r.emails, sentEmail{to: cfg.SMTPTo, subject: cfg.CustomSubject, body: message})
+	return nil
+}
+
+func (r *recordingEmailSender) sent() []sentEmail {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]sentEmail(nil), r.emails...)
+}
+
+const smtpConfig = `{"smtp_host": "smtp.example.com", "smtp_port": 587, "username": "user", "password": "pass", "from": "status@example.com", "to": "ops@example.com"}`
+
+type testEnv struct {
+	service *ServiceImpl
+	repo    *memoryRepository
+	email   *recordingEmailSender
+	page    *status_page.Model
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	config := smtpConfig
+	page := &status_page.Model{
+		ID:                  "page-1",
+		Slug:                "acme",
+		Title:               "Acme Status",
+		AllowSubscriptions:  true,
+		SubscriberChannelID: "smtp-1",
+	}
+	env := &testEnv{
+		repo:  newMemoryRepository(),
+		email: &recordingEmailSender{},
+		page:  page,
+	}
+	env.service = &ServiceImpl{
+		repository:  env.repo,
+		statusPages: fakeStatusPages{page.ID: page},
+		monitorStatusPages: fakeMonitorStatusPages{
+			"monitor-1": {{StatusPageID: page.ID, MonitorID: "monitor-1", Active: true}},
+		},
+		monitors: fakeMonitors{"monitor-1": {ID: "monitor-1", Name: "API"}},
+		channels: fakeChannels{"smtp-1": {ID: "smtp-1", Type: "smtp", Active: true, Config: &config}},
+		email:    env.email,
+		// Test servers listen on loopback, which the webhook client refuses
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+		clientURL:  "https://status.example.com",
+		logger:     zap.NewNop().Sugar(),
+	}
+	return env
+}
+
+func downBeat() *heartbeat.Model {
+	return &heartbeat.Model{MonitorID: "monitor-1", Status: shared.MonitorStatusDown, Msg: "connection refused", Time: time.Date(2025, 10, 31, 9, 0, 0, 0, time.UTC)}
+}
+
+// tokenFromLink returns the token query parameter of the link in body that starts with prefix
+func tokenFromLink(t *testing.T, body, prefix string) string {
+	start := strings.Index(body, prefix)
+	require.GreaterOrEqual(t, start, 0, "no %s link in %q", prefix, body)
+	link := strings.Fields(body[start:])[0]
+	parsed, err := url.Parse(link)
+	require.NoError(t, err)
+	return parsed.Query().Get("token")
+}
+
+func TestService_EmailSubscribeConfirmNotifyUnsubscribe(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+
+	err := env.service.Subscribe(ctx, env.page, &SubscribeDto{Type: TypeEmail, Email: " Jane@Example.com "})
+	require.NoError(t, err)
+
+	emails := env.email.sent()
+	require.Len(t, emails, 1)
+	assert.Equal(t, "jane@example.com", emails[0].to)
+	assert.Equal(t, "Confirm your subscription to Acme Status", emails[0].subject)
+	confirmToken := tokenFromLink(t, emails[0].body, "https://status.example.com/api/v1/status-pages/subscriptions/confirm?")
+
+	// Unconfirmed addresses are not notified
+	require.NoError(t, env.service.NotifyStatusChange(ctx, downBeat()))
+	assert.Len(t, env.email.sent(), 1)
+
+	confirmed, err := env.service.Confirm(ctx, confirmToken)
+	require.NoError(t, err)
+	assert.True(t, confirmed.Confirmed)
+
+	require.NoError(t, env.service.NotifyStatusChange(ctx, downBeat()))
+	emails = env.email.sent()
+	require.Len(t, emails, 2)
+	assert.Equal(t, "jane@example.com", emails[1].to)
+	assert.Equal(t, "[Acme Status] API is down", emails[1].subject)
+	assert.Contains(t, emails[1].body, "API is down: connection refused")
+	assert.Contains(t, emails[1].body, "Status page: https://status.example.com/status/acme")
+	unsubscribeToken := tokenFromLink(t, emails[1].body, "https://status.example.com/api/v1/status-pages/subscriptions/unsubscribe?")
+
+	_, err = env.service.Unsubscribe(ctx, unsubscribeToken)
+	require.NoError(t, err)
+
+	require.NoError(t, env.service.NotifyStatusChange(ctx, downBeat()))
+	assert.Len(t, env.email.sent(), 2)
+
+	_, err = env.service.Unsubscribe(ctx, unsubscribeToken)
+	assert.ErrorIs(t, err, ErrSubscriberNotFound)
+}
+
+func TestService_SubscribeAgainResendsConfirmationAfterCooldown(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	dto := &SubscribeDto{Type: TypeEmail, Email: "jane@example.com"}
+
+	require.NoError(t, env.service.Subscribe(ctx, env.page, dto))
+	subscribers, err := env.service.FindByStatusPageID(ctx, env.page.ID)
+	require.NoError(t, err)
+	require.Len(t, subscribers, 1)
+
+	// Within the cooldown no other link is sent
+	require.NoError(t, env.service.Subscribe(ctx, env.page, dto))
+	require.NoError(t, env.service.Subscribe(ctx, env.page, dto))
+	assert.Len(t, env.email.sent(), 1)
+
+	env.repo.sentConfirmationAt(subscribers[0].ID, time.Now().Add(-confirmationResendCooldown-time.Second))
+	require.NoError(t, env.service.Subscribe(ctx, env.page, dto))
+	require.NoError(t, env.service.Subscribe(ctx, env.page, dto))
+
+	emails := env.email.sent()
+	require.Len(t, emails, 2)
+	assert.Equal(t, emails[0].body, emails[1].body)
+
+	subscribers, err = env.service.FindByStatusPageID(ctx, env.page.ID)
+	require.NoError(t, err)
+	assert.Len(t, subscribers, 1)
+
+	// A confirmed address isn't sent another link
+	_, err = env.service.Confirm(ctx, subscribers[0].ConfirmToken)
+	require.NoError(t, err)
+	require.NoError(t, env.service.Subscribe(ctx, env.page, dto))
+	assert.Len(t, env.email.sent(), 2)
+}
+
+func TestService_WebhookSubscriber(t *testing.T) {
+	var mu sync.Mutex
+	var payloads []WebhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload WebhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		payloads = append(payloads, payload)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	env := newTestEnv(t)
+	ctx := context.Background()
+
+	require.NoError(t, env.service.Subscribe(ctx, env.page, &SubscribeDto{Type: TypeWebhook, WebhookURL: server.URL}))
+	assert.Empty(t, env.email.sent())
+
+	require.NoError(t, env.service.NotifyStatusChange(ctx, downBeat()))
+	up := downBeat()
+	up.Status = shared.MonitorStatusUp
+	require.NoError(t, env.service.NotifyStatusChange(ctx, up))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, payloads, 2)
+	assert.Equal(t, "down", payloads[0].Status)
+	assert.Equal(t, "API is down: connection refused", payloads[0].Message)
+	assert.Equal(t, WebhookMonitor{ID: "monitor-1", Name: "API"}, payloads[0].Monitor)
+	assert.Equal(t, "acme", payloads[0].StatusPage.Slug)
+	assert.Equal(t, "2025-10-31T09:00:00Z", payloads[0].Time)
+	assert.Contains(t, payloads[0].UnsubscribeURL, "/api/v1/status-pages/subscriptions/unsubscribe?token=")
+	assert.Equal(t, "up", payloads[1].Status)
+	assert.Equal(t, "API is up", payloads[1].Message)
+}
+
+func TestService_NotifyIgnoresOtherStatusesAndPages(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+
+	require.NoError(t, env.service.Subscribe(ctx, env.page, &SubscribeDto{Type: TypeEmail, Email: "jane@example.com"}))
+	subscribers, _ := env.service.FindByStatusPageID(ctx, env.page.ID)
+	_, err := env.service.Confirm(ctx, subscribers[0].ConfirmToken)
+	require.NoError(t, err)
+
+	pending := downBeat()
+	pending.Status = shared.MonitorStatusPending
+	require.NoError(t, env.service.NotifyStatusChange(ctx, pending))
+
+	other := downBeat()
+	other.MonitorID = "monitor-2"
+	require.NoError(t, env.service.NotifyStatusChange(ctx, other))
+
+	env.page.AllowSubscriptions = false
+	require.NoError(t, env.service.NotifyStatusChange(ctx, downBeat()))
+
+	assert.Len(t, env.email.sent(), 1, "only the confirmation email is sent")
+}
+
+func TestService_SubscribeErrors(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("subscriptions disabled", func(t *testing.T) {
+		env := newTestEnv(t)
+		env.page.AllowSubscriptions = false
+		err := env.service.Subscribe(ctx, env.page, &SubscribeDto{Type: TypeWebhook, WebhookURL: "https://example.com/hook"})
+		assert.ErrorIs(t, err, ErrSubscriptionsDisabled)
+	})
+
+	t.Run("no email channel", func(t *testing.T) {
+		env := newTestEnv(t)
+		env.page.SubscriberChannelID = ""
+		err := env.service.Subscribe(ctx, env.page, &SubscribeDto{Type: TypeEmail, Email: "jane@example.com"})
+		assert.ErrorIs(t, err, ErrEmailUnavailable)
+	})
+
+	t.Run("channel is not smtp", func(t *testing.T) {
+		env := newTestEnv(t)
+		env.service.channels = fakeChannels{"smtp-1": {ID: "smtp-1", Type: "slack", Active: true}}
+		err := env.service.Subscribe(ctx, env.page, &SubscribeDto{Type: TypeEmail, Email: "jane@example.com"})
+		assert.ErrorIs(t, err, ErrEmailUnavailable)
+	})
+
+	t.Run("webhook scheme", func(t *testing.T) {
+		env := newTestEnv(t)
+		err := env.service.Subscribe(ctx, env.page, &SubscribeDto{Type: TypeWebhook, WebhookURL: "ftp://example.com/hook"})
+		assert.ErrorIs(t, err, ErrInvalidWebhookURL)
+	})
+
+	t.Run("unknown tokens", func(t *testing.T) {
+		env := newTestEnv(t)
+		_, err := env.service.Confirm(ctx, "nope")
+		assert.ErrorIs(t, err, ErrSubscriberNotFound)
+		_, err = env.service.Unsubscribe(ctx, "")
+		assert.ErrorIs(t, err, ErrSubscriberNotFound)
+	})
+}
+
+func TestService_DeleteChecksStatusPage(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+
+	require.NoError(t, env.service.Subscribe(ctx, env.page, &SubscribeDto{Type: TypeWebhook, WebhookURL: "https://example.com/hook"}))
+	subscribers, _ := env.service.FindByStatusPageID(ctx, env.page.ID)
+	require.Len(t, subscribers, 1)
+
+	assert.ErrorIs(t, env.service.Delete(ctx, "page-2", subscribers[0].ID), ErrSubscriberNotFound)
+	require.NoError(t, env.service.Delete(ctx, env.page.ID, subscribers[0].ID))
+
+	subscribers, _ = env.service.FindByStatusPageID(ctx, env.page.ID)
+	assert.Empty(t, subscribers)
+}
+
+func TestWebhookClient_RefusesPrivateAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	_, err := newWebhookClient().Get(server.URL)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is not public")
+}
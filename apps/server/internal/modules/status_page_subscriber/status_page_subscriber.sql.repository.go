@@ -0,0 +1,148 @@
+package status_page_subscriber
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+type sqlModel struct {
+	bun.BaseModel `bun:"table:status_page_subscribers,alias:sps"`
+
+	ID                 string     `bun:"id,pk"`
+	StatusPageID       string     `bun:"status_page_id,notnull"`
+	Type               string     `bun:"type,notnull"`
+	Target             string     `bun:"target,notnull"`
+	Confirmed          bool       `bun:"confirmed,notnull,default:false"`
+	ConfirmToken       string     `bun:"confirm_token,notnull"`
+	UnsubscribeToken   string     `bun:"unsubscribe_token,notnull"`
+	ConfirmationSentAt *time.Time `bun:"confirmation_sent_at"`
+	CreatedAt          time.Time  `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+	UpdatedAt          time.Time  `bun:"updated_at,nullzero,notnull,default:current_timestamp"`
+}
+
+func toDomainModelFromSQL(sm *sqlModel) *Model {
+	return &Model{
+		ID:                 sm.ID,
+		StatusPageID:       sm.StatusPageID,
+		Type:               sm.Type,
+		Target:             sm.Target,
+		Confirmed:          sm.Confirmed,
+		ConfirmToken:       sm.ConfirmToken,
+		UnsubscribeToken:   sm.UnsubscribeToken,
+		ConfirmationSentAt: sm.ConfirmationSentAt,
+		CreatedAt:          sm.CreatedAt,
+		UpdatedAt:          sm.UpdatedAt,
+	}
+}
+
+type SQLRepositoryImpl struct {
+	db *bun.DB
+}
+
+func NewSQLRepository(db *bun.DB) Repository {
+	return &SQLRepositoryImpl{db: db}
+}
+
+func (r *SQLRepositoryImpl) Create(ctx context.Context, entity *Model) (*Model, error) {
+	sm := &sqlModel{
+		ID:                 uuid.New().String(),
+		StatusPageID:       entity.StatusPageID,
+		Type:               entity.Type,
+		Target:             entity.Target,
+		Confirmed:          entity.Confirmed,
+		ConfirmToken:       entity.ConfirmToken,
+		UnsubscribeToken:   entity.UnsubscribeToken,
+		ConfirmationSentAt: entity.ConfirmationSentAt,
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	}
+
+	_, err := r.db.NewInsert().Model(sm).Returning("*").Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return toDomainModelFromSQL(sm), nil
+}
+
+func (r *SQLRepositoryImpl) findOne(ctx context.Context, where string, args ...any) (*Model, error) {
+	sm := new(sqlModel)
+	err := r.db.NewSelect().Model(sm).Where(where, args...).Limit(1).Scan(ctx)
+	if err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return toDomainModelFromSQL(sm), nil
+}
+
+func (r *SQLRepositoryImpl) FindByID(ctx context.Context, id string) (*Model, error) {
+	return r.findOne(ctx, "id = ?", id)
+}
+
+func (r *SQLRepositoryImpl) FindByStatusPageID(ctx context.Context, statusPageID string) ([]*Model, error) {
+	var sms []*sqlModel
+	err := r.db.NewSelect().
+		Model(&sms).
+		Where("status_page_id = ?", statusPageID).
+		Order("created_at DESC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	models := make([]*Model, 0, len(sms))
+	for _, sm := range sms {
+		models = append(models, toDomainModelFromSQL(sm))
+	}
+	return models, nil
+}
+
+func (r *SQLRepositoryImpl) FindByTarget(ctx context.Context, statusPageID, subscriberType, target string) (*Model, error) {
+	return r.findOne(ctx, "status_page_id = ? AND type = ? AND target = ?", statusPageID, subscriberType, target)
+}
+
+func (r *SQLRepositoryImpl) FindByConfirmToken(ctx context.Context, token string) (*Model, error) {
+	return r.findOne(ctx, "confirm_token = ?", token)
+}
+
+func (r *SQLRepositoryImpl) FindByUnsubscribeToken(ctx context.Context, token string) (*Model, error) {
+	return r.findOne(ctx, "unsubscribe_token = ?", token)
+}
+
+func (r *SQLRepositoryImpl) Confirm(ctx context.Context, id string) error {
+	_, err := r.db.NewUpdate().
+		Model((*sqlModel)(nil)).
+		Set("confirmed = ?", true).
+		Set("updated_at = ?", time.Now()).
+		Where("id = ?", id).
+		Exec(ctx)
+	return err
+}
+
+func (r *SQLRepositoryImpl) MarkConfirmationSent(ctx context.Context, id string, sentAt, resendAfter time.Time) (bool, error) {
+	result, err := r.db.NewUpdate().
+		Model((*sqlModel)(nil)).
+		Set("confirmation_sent_at = ?", sentAt).
+		Set("updated_at = ?", time.Now()).
+		Where("id = ?", id).
+		Where("(confirmation_sent_at IS NULL OR confirmation_sent_at <= ?)", resendAfter).
+		Exec(ctx)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func (r *SQLRepositoryImpl) Delete(ctx context.Context, id string) error {
+	_, err := r.db.NewDelete().Model((*sqlModel)(nil)).Where("id = ?", id).Exec(ctx)
+	return err
+}
@@ -0,0 +1,319 @@
+package uptime_kuma
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/notification_channel"
+	"peekaping/internal/modules/tag"
+	"peekaping/internal/utils"
+	"slices"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// maxImportMonitors bounds the monitors of a single backup, like the monitor import
+const maxImportMonitors = 1000
+
+// MonitorImporter creates monitors from their definitions, implemented by the monitor
+// controller
+type MonitorImporter interface {
+	Import(ctx *gin.Context, items []monitor.ExportDto) ([]monitor.ImportResultDto, error)
+}
+
+type Controller struct {
+	monitorImporter MonitorImporter
+	channelService  notification_channel.Service
+	tagService      tag.Service
+	logger          *zap.SugaredLogger
+}
+
+func NewController(
+	monitorImporter MonitorImporter,
+	channelService notification_channel.Service,
+	tagService tag.Service,
+	logger *zap.SugaredLogger,
+) *Controller {
+	return &Controller{
+		monitorImporter: monitorImporter,
+		channelService:  channelService,
+		tagService:      tagService,
+		logger:          logger.Named("[uptime-kuma-import]"),
+	}
+}
+
+// @Router		/import/uptime-kuma [post]
+// @Summary		Import an Uptime Kuma backup
+// @Description	Creates the tags, notification channels and monitors of an Uptime Kuma JSON backup. HTTP, keyword, JSON query, TCP port, ping, DNS and push monitors and Telegram, Slack, webhook and SMTP notifications are supported, other types are skipped. Tags and notification channels that already exist with the same name are reused. The report lists what became of every item.
+// @Tags			Import
+// @Produce		json
+// @Accept		json
+// @Security  JwtAuth
+// @Security  ApiKeyAuth
+// @Param     body body   BackupDto  true  "Uptime Kuma backup"
+// @Success		200	{object}	utils.ApiResponse[ImportReportDto]
+// @Failure		400	{object}	utils.APIError[any]
+// @Failure		500	{object}	utils.APIError[any]
+func (c *Controller) Import(ctx *gin.Context) {
+	var backup BackupDto
+	if err := ctx.ShouldBindJSON(&backup); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewFailResponse(err.Error()))
+		return
+	}
+	if len(backup.MonitorList) > maxImportMonitors {
+		ctx.JSON(http.StatusBadRequest, utils.NewFailResponse(fmt.Sprintf("At most %d monitors can be imported at once", maxImportMonitors)))
+		return
+	}
+
+	report, err := c.importBackup(ctx, &backup)
+	if err != nil {
+		c.logger.Errorw("Failed to import Uptime Kuma backup", "error", err)
+		ctx.JSON(http.StatusInternalServerError, utils.NewFailResponse("Internal server error"))
+		return
+	}
+
+	imported := 0
+	for _, result := range report.Monitors {
+		if result.Status == StatusCreated {
+			imported++
+		}
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewSuccessResponse(fmt.Sprintf("Imported %d of %d monitors", imported, len(backup.MonitorList)), report))
+}
+
+// importBackup imports the tags and notifications first so the monitors can reference
+// them by name. A monitor keeps the tags and notifications that could be imported, the
+// others are reported as warnings.
+func (c *Controller) importBackup(ctx *gin.Context, backup *BackupDto) (*ImportReportDto, error) {
+	report := &ImportReportDto{
+		Tags:          make([]ItemResultDto, 0),
+		Notifications: make([]ItemResultDto, 0),
+		Monitors:      make([]ItemResultDto, 0, len(backup.MonitorList)),
+	}
+
+	tags := c.importTags(ctx, backup.MonitorList, report)
+	channels, err := c.importNotifications(ctx, backup.NotificationList, report)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]monitor.ExportDto, 0, len(backup.MonitorList))
+	// reportIndex is the index in the report of each item
+	reportIndex := make([]int, 0, len(backup.MonitorList))
+	for i := range backup.MonitorList {
+		m := &backup.MonitorList[i]
+		result := ItemResultDto{Name: m.Name, Type: m.Type}
+
+		item, warnings, err := mapMonitor(m)
+		if err != nil {
+			result.Status = itemStatus(err)
+			result.Reason = err.Error()
+			report.Monitors = append(report.Monitors, result)
+			continue
+		}
+
+		ids := make([]string, 0, len(m.NotificationIDList))
+		for id, enabled := range m.NotificationIDList {
+			if enabled {
+				ids = append(ids, id)
+			}
+		}
+		slices.Sort(ids)
+		for _, id := range ids {
+			name, ok := channels[id]
+			if !ok {
+				warnings = append(warnings, fmt.Sprintf("notification %s was not imported", id))
+				continue
+			}
+			item.Notifications = append(item.Notifications, monitor.NotificationExportDto{Name: name})
+		}
+
+		for _, t := range m.Tags {
+			if !tags[t.Name] {
+				warnings = append(warnings, fmt.Sprintf("tag %q was not imported", t.Name))
+				continue
+			}
+			if !slices.Contains(item.Tags, t.Name) {
+				item.Tags = append(item.Tags, t.Name)
+			}
+			if t.Value != "" {
+				warnings = append(warnings, fmt.Sprintf("value %q of tag %q dropped", t.Value, t.Name))
+			}
+		}
+
+		result.Warnings = warnings
+		reportIndex = append(reportIndex, len(report.Monitors))
+		items = append(items, *item)
+		report.Monitors = append(report.Monitors, result)
+	}
+
+	if len(items) == 0 {
+		return report, nil
+	}
+
+	results, err := c.monitorImporter.Import(ctx, items)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range results {
+		result := &report.Monitors[reportIndex[r.Index]]
+		if r.Success {
+			result.Status = StatusCreated
+			result.ID = r.ID
+		} else {
+			result.Status = StatusFailed
+			result.Reason = r.Error
+		}
+	}
+
+	return report, nil
+}
+
+// importTags creates the tags of the monitors that don't exist yet and returns the names
+// of the tags that can be referenced
+func (c *Controller) importTags(ctx *gin.Context, monitors []MonitorDto, report *ImportReportDto) map[string]bool {
+	imported := make(map[string]bool)
+	seen := make(map[string]bool)
+
+	for _, m := range monitors {
+		for _, t := range m.Tags {
+			if seen[t.Name] {
+				continue
+			}
+			seen[t.Name] = true
+			result := ItemResultDto{Name: t.Name}
+
+			existing, err := c.tagService.FindByName(ctx, t.Name)
+			if err != nil {
+				c.logger.Errorw("Failed to fetch tag", "name", t.Name, "error", err)
+				result.Status = StatusFailed
+				result.Reason = "Internal server error"
+				report.Tags = append(report.Tags, result)
+				continue
+			}
+			if existing != nil {
+				result.Status = StatusExisting
+				result.ID = existing.ID
+				imported[t.Name] = true
+				report.Tags = append(report.Tags, result)
+				continue
+			}
+
+			dto := &tag.CreateUpdateDto{Name: t.Name, Color: tagColor(t.Color)}
+			if err := utils.Validate.Struct(dto); err != nil {
+				result.Status = StatusFailed
+				result.Reason = err.Error()
+				report.Tags = append(report.Tags, result)
+				continue
+			}
+			created, err := c.tagService.Create(ctx, dto)
+			if err != nil {
+				c.logger.Errorw("Failed to create tag", "name", t.Name, "error", err)
+				result.Status = StatusFailed
+				result.Reason = "Internal server error"
+				report.Tags = append(report.Tags, result)
+				continue
+			}
+
+			result.Status = StatusCreated
+			result.ID = created.ID
+			imported[t.Name] = true
+			report.Tags = append(report.Tags, result)
+		}
+	}
+
+	return imported
+}
+
+// importNotifications creates the notification channels of the backup and returns the
+// names of the imported ones by their id in the backup. A channel with the same name and
+// type is reused, one with the same name and another type fails the notification since
+// monitors reference channels by name.
+func (c *Controller) importNotifications(ctx *gin.Context, notifications []NotificationDto, report *ImportReportDto) (map[string]string, error) {
+	names, err := c.channelService.ChannelNames(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch notification channels: %w", err)
+	}
+	idsByName := make(map[string][]string, len(names))
+	for id, name := range names {
+		idsByName[name] = append(idsByName[name], id)
+	}
+
+	imported := make(map[string]string)
+	for i := range notifications {
+		n := &notifications[i]
+		channelType, config, err := mapNotification(n)
+		result := ItemResultDto{Name: n.Name, Type: channelType}
+		if err == nil && n.Name == "" {
+			err = errors.New("notification has no name")
+		}
+		if err != nil {
+			result.Status = itemStatus(err)
+			result.Reason = err.Error()
+			report.Notifications = append(report.Notifications, result)
+			continue
+		}
+
+		switch ids := idsByName[n.Name]; len(ids) {
+		case 0:
+			if err := notification_channel.ValidateConfig(channelType, config); err != nil {
+				result.Status = StatusFailed
+				result.Reason = err.Error()
+				break
+			}
+			created, err := c.channelService.Create(ctx, &notification_channel.CreateUpdateDto{
+				Name:      n.Name,
+				Type:      channelType,
+				Active:    bool(n.Active),
+				IsDefault: bool(n.IsDefault),
+				Config:    config,
+			})
+			if err != nil {
+				c.logger.Errorw("Failed to create notification channel", "name", n.Name, "error", err)
+				result.Status = StatusFailed
+				result.Reason = "Internal server error"
+				break
+			}
+			result.Status = StatusCreated
+			result.ID = created.ID
+			idsByName[n.Name] = []string{created.ID}
+		case 1:
+			existing, err := c.channelService.FindByID(ctx, ids[0])
+			if err != nil || existing == nil {
+				c.logger.Errorw("Failed to fetch notification channel", "id", ids[0], "error", err)
+				result.Status = StatusFailed
+				result.Reason = "Internal server error"
+				break
+			}
+			if existing.Type != channelType {
+				result.Status = StatusFailed
+				result.Reason = fmt.Sprintf("a %s notification channel named %q already exists", existing.Type, n.Name)
+				break
+			}
+			result.Status = StatusExisting
+			result.ID = existing.ID
+		default:
+			result.Status = StatusFailed
+			result.Reason = fmt.Sprintf("several notification channels are named %q", n.Name)
+		}
+
+		if result.Status != StatusFailed {
+			imported[strconv.Itoa(n.ID)] = n.Name
+		}
+		report.Notifications = append(report.Notifications, result)
+	}
+
+	return imported, nil
+}
+
+// itemStatus is the status of an item that couldn't be mapped
+func itemStatus(err error) string {
+	if errors.Is(err, errUnsupported) {
+		return StatusSkipped
+	}
+	return StatusFailed
+}
@@ -0,0 +1,149 @@
+package uptime_kuma
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// BackupDto is the JSON backup downloaded from the settings of Uptime Kuma. Fields that
+// Peekaping has no equivalent for, e.g. heartbeat history, are ignored.
+type BackupDto struct {
+	Version          string            `json:"version" example:"1.23.16"`
+	NotificationList []NotificationDto `json:"notificationList"`
+	MonitorList      []MonitorDto      `json:"monitorList"`
+}
+
+// NotificationDto is a notification of an Uptime Kuma backup. Config is the JSON of the
+// provider settings, including its type.
+type NotificationDto struct {
+	ID        int      `json:"id"`
+	Name      string   `json:"name"`
+	Active    kumaBool `json:"active"`
+	IsDefault kumaBool `json:"isDefault"`
+	Config    string   `json:"config"`
+}
+
+type MonitorDto struct {
+	ID             int      `json:"id"`
+	Name           string   `json:"name"`
+	Type           string   `json:"type"`
+	Active         kumaBool `json:"active"`
+	Interval       int      `json:"interval"`
+	RetryInterval  int      `json:"retryInterval"`
+	ResendInterval int      `json:"resendInterval"`
+	MaxRetries     int      `json:"maxretries"`
+	Timeout        float64  `json:"timeout"`
+	UpsideDown     kumaBool `json:"upsideDown"`
+
+	URL                 string   `json:"url"`
+	Method              string   `json:"method"`
+	Body                string   `json:"body"`
+	Headers             string   `json:"headers"`
+	HTTPBodyEncoding    string   `json:"httpBodyEncoding"`
+	MaxRedirects        int      `json:"maxredirects"`
+	AcceptedStatusCodes []string `json:"accepted_statuscodes"`
+	IgnoreTLS           kumaBool `json:"ignoreTls"`
+	ExpiryNotification  kumaBool `json:"expiryNotification"`
+	Keyword             string   `json:"keyword"`
+	InvertKeyword       kumaBool `json:"invertKeyword"`
+	JSONPath            string   `json:"jsonPath"`
+	JSONPathOperator    string   `json:"jsonPathOperator"`
+	ExpectedValue       string   `json:"expectedValue"`
+
+	AuthMethod        string `json:"authMethod"`
+	BasicAuthUser     string `json:"basic_auth_user"`
+	BasicAuthPass     string `json:"basic_auth_pass"`
+	AuthDomain        string `json:"authDomain"`
+	AuthWorkstation   string `json:"authWorkstation"`
+	OauthAuthMethod   string `json:"oauth_auth_method"`
+	OauthTokenURL     string `json:"oauth_token_url"`
+	OauthClientID     string `json:"oauth_client_id"`
+	OauthClientSecret string `json:"oauth_client_secret"`
+	OauthScopes       string `json:"oauth_scopes"`
+	TLSCert           string `json:"tlsCert"`
+	TLSKey            string `json:"tlsKey"`
+	TLSCa             string `json:"tlsCa"`
+
+	Hostname         string `json:"hostname"`
+	Port             int    `json:"port"`
+	PacketSize       int    `json:"packetSize"`
+	DNSResolveType   string `json:"dns_resolve_type"`
+	DNSResolveServer string `json:"dns_resolve_server"`
+	PushToken        string `json:"pushToken"`
+
+	// NotificationIDList maps the ids of the monitor's notifications to true
+	NotificationIDList map[string]bool `json:"notificationIDList"`
+	Tags               []MonitorTagDto `json:"tags"`
+}
+
+type MonitorTagDto struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+	Value string `json:"value"`
+}
+
+// Statuses of the items of an import report
+const (
+	StatusCreated  = "created"
+	StatusExisting = "existing"
+	StatusSkipped  = "skipped"
+	StatusFailed   = "failed"
+)
+
+// ImportReportDto lists what became of every tag, notification and monitor of a backup
+type ImportReportDto struct {
+	Tags          []ItemResultDto `json:"tags"`
+	Notifications []ItemResultDto `json:"notifications"`
+	Monitors      []ItemResultDto `json:"monitors"`
+}
+
+// ItemResultDto reports one item of a backup, Type is its type in the backup. Status is
+// created, existing (an item with the same name was reused), skipped (no Peekaping
+// equivalent) or failed, with the Reason for the last two. Warnings list the settings
+// that couldn't be carried over as is.
+type ItemResultDto struct {
+	Name     string   `json:"name" example:"Website"`
+	Type     string   `json:"type,omitempty" example:"http"`
+	Status   string   `json:"status" example:"created"`
+	ID       string   `json:"id,omitempty" example:"6830ad485361f19c598d6d90"`
+	Reason   string   `json:"reason,omitempty" example:"unsupported monitor type \"steam\""`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// kumaBool decodes the flags of a backup, which older Uptime Kuma versions store as 0
+// and 1 instead of booleans
+type kumaBool bool
+
+func (b *kumaBool) UnmarshalJSON(data []byte) error {
+	switch strings.Trim(string(data), `"`) {
+	case "true", "1":
+		*b = true
+	case "false", "0", "null", "":
+		*b = false
+	default:
+		var v bool
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		*b = kumaBool(v)
+	}
+	return nil
+}
+
+// kumaInt decodes numbers that Uptime Kuma may store as strings, e.g. SMTP ports
+type kumaInt int
+
+func (i *kumaInt) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "" || s == "null" {
+		*i = 0
+		return nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+	*i = kumaInt(v)
+	return nil
+}
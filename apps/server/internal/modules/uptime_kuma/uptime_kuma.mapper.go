@@ -0,0 +1,347 @@
+package uptime_kuma
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"peekaping/internal/modules/healthcheck/executor"
+	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/notification_channel/providers"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// minInterval and minTimeout are the lowest check interval and timeout Peekaping accepts
+	minInterval = 20
+	minTimeout  = 16
+	// defaultTagColor is used for tags whose Uptime Kuma color isn't a hex color
+	defaultTagColor = "#3B82F6"
+)
+
+// errUnsupported marks the items of a backup that have no Peekaping equivalent
+var errUnsupported = errors.New("unsupported")
+
+var hexColor = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// tagColor is the color of an imported tag
+func tagColor(color string) string {
+	if hexColor.MatchString(color) {
+		return color
+	}
+	return defaultTagColor
+}
+
+// mapNotification converts the config of an Uptime Kuma notification into the type and
+// config of the equivalent notification channel. The type is returned with the errors too.
+func mapNotification(n *NotificationDto) (string, string, error) {
+	var header struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal([]byte(n.Config), &header); err != nil {
+		return "", "", fmt.Errorf("invalid config: %w", err)
+	}
+
+	var config any
+	switch header.Type {
+	case "telegram":
+		var kuma struct {
+			BotToken        string   `json:"telegramBotToken"`
+			ChatID          string   `json:"telegramChatID"`
+			MessageThreadID string   `json:"telegramMessageThreadID"`
+			ServerURL       string   `json:"telegramServerUrl"`
+			SendSilently    kumaBool `json:"telegramSendSilently"`
+			ProtectContent  kumaBool `json:"telegramProtectContent"`
+		}
+		if err := json.Unmarshal([]byte(n.Config), &kuma); err != nil {
+			return header.Type, "", fmt.Errorf("invalid config: %w", err)
+		}
+		config = providers.TelegramConfig{
+			BotToken:        kuma.BotToken,
+			ChatID:          kuma.ChatID,
+			MessageThreadID: kuma.MessageThreadID,
+			ServerUrl:       kuma.ServerURL,
+			SendSilently:    bool(kuma.SendSilently),
+			ProtectContent:  bool(kuma.ProtectContent),
+		}
+	case "slack":
+		var kuma struct {
+			WebhookURL    string   `json:"slackwebhookURL"`
+			Username      string   `json:"slackusername"`
+			IconEmoji     string   `json:"slackiconemo"`
+			Channel       string   `json:"slackchannel"`
+			RichMessage   kumaBool `json:"slackrichmessage"`
+			ChannelNotify kumaBool `json:"slackchannelnotify"`
+		}
+		if err := json.Unmarshal([]byte(n.Config), &kuma); err != nil {
+			return header.Type, "", fmt.Errorf("invalid config: %w", err)
+		}
+		config = providers.SlackConfig{
+			WebhookURL:    kuma.WebhookURL,
+			Username:      kuma.Username,
+			IconEmoji:     kuma.IconEmoji,
+			Channel:       kuma.Channel,
+			RichMessage:   bool(kuma.RichMessage),
+			ChannelNotify: bool(kuma.ChannelNotify),
+		}
+	case "webhook":
+		var kuma struct {
+			URL               string `json:"webhookURL"`
+			ContentType       string `json:"webhookContentType"`
+			CustomBody        string `json:"webhookCustomBody"`
+			AdditionalHeaders string `json:"webhookAdditionalHeaders"`
+		}
+		if err := json.Unmarshal([]byte(n.Config), &kuma); err != nil {
+			return header.Type, "", fmt.Errorf("invalid config: %w", err)
+		}
+		contentType := kuma.ContentType
+		if contentType == "" {
+			contentType = "json"
+		}
+		config = providers.WebhookConfig{
+			WebhookURL:               kuma.URL,
+			WebhookContentType:       contentType,
+			WebhookCustomBody:        kuma.CustomBody,
+			WebhookAdditionalHeaders: kuma.AdditionalHeaders,
+		}
+	case "smtp":
+		var kuma struct {
+			Host          string   `json:"smtpHost"`
+			Port          kumaInt  `json:"smtpPort"`
+			Secure        kumaBool `json:"smtpSecure"`
+			Username      string   `json:"smtpUsername"`
+			Password      string   `json:"smtpPassword"`
+			From          string   `json:"smtpFrom"`
+			To            string   `json:"smtpTo"`
+			CC            string   `json:"smtpCC"`
+			BCC           string   `json:"smtpBCC"`
+			CustomSubject string   `json:"customSubject"`
+			CustomBody    string   `json:"customBody"`
+		}
+		if err := json.Unmarshal([]byte(n.Config), &kuma); err != nil {
+			return header.Type, "", fmt.Errorf("invalid config: %w", err)
+		}
+		config = providers.EmailConfig{
+			SMTPSecure:    bool(kuma.Secure),
+			SMTPHost:      kuma.Host,
+			SMTPPort:      int(kuma.Port),
+			SMTPUsername:  kuma.Username,
+			SMTPPassword:  kuma.Password,
+			SMTPFrom:      kuma.From,
+			SMTPTo:        kuma.To,
+			SMTPCC:        kuma.CC,
+			SMTPBCC:       kuma.BCC,
+			CustomSubject: kuma.CustomSubject,
+			CustomBody:    kuma.CustomBody,
+		}
+	default:
+		return header.Type, "", fmt.Errorf("%w notification type %q", errUnsupported, header.Type)
+	}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		return header.Type, "", err
+	}
+	return header.Type, string(data), nil
+}
+
+// monitorTypes maps the Uptime Kuma monitor types to Peekaping's
+var monitorTypes = map[string]string{
+	"http":       "http",
+	"keyword":    "http-keyword",
+	"json-query": "http-json-query",
+	"port":       "tcp",
+	"ping":       "ping",
+	"dns":        "dns",
+	"push":       "push",
+}
+
+// mapMonitor converts an Uptime Kuma monitor into a monitor definition without its tags
+// and notifications. The warnings list the settings that were adjusted to fit.
+func mapMonitor(m *MonitorDto) (*monitor.ExportDto, []string, error) {
+	monitorType, ok := monitorTypes[m.Type]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w monitor type %q", errUnsupported, m.Type)
+	}
+	if m.UpsideDown {
+		return nil, nil, fmt.Errorf("upside down mode is %w", errUnsupported)
+	}
+
+	var warnings []string
+	interval := m.Interval
+	if interval < minInterval {
+		warnings = append(warnings, fmt.Sprintf("interval raised from %ds to %ds", m.Interval, minInterval))
+		interval = minInterval
+	}
+	retryInterval := m.RetryInterval
+	if retryInterval <= 0 {
+		retryInterval = interval
+	}
+	retryInterval = max(retryInterval, minInterval)
+	// Uptime Kuma defaults the timeout to 80% of the interval
+	timeout := int(math.Round(m.Timeout))
+	if timeout <= 0 {
+		timeout = interval * 8 / 10
+	}
+	timeout = max(timeout, minTimeout)
+
+	item := &monitor.ExportDto{
+		Type:           monitorType,
+		Name:           m.Name,
+		Interval:       interval,
+		MaxRetries:     max(m.MaxRetries, 0),
+		RetryInterval:  retryInterval,
+		Timeout:        timeout,
+		ResendInterval: max(m.ResendInterval, 0),
+		Active:         bool(m.Active),
+		Tags:           make([]string, 0),
+		Notifications:  make([]monitor.NotificationExportDto, 0),
+	}
+
+	var config any
+	switch monitorType {
+	case "http", "http-keyword", "http-json-query":
+		httpConfig, httpWarnings := mapHTTPConfig(m)
+		warnings = append(warnings, httpWarnings...)
+		config = httpConfig
+	case "tcp":
+		config = executor.TCPConfig{Host: m.Hostname, Port: m.Port}
+	case "ping":
+		config = executor.PingConfig{Host: m.Hostname, PacketSize: m.PacketSize}
+	case "dns":
+		port := m.Port
+		if port == 0 {
+			port = 53
+		}
+		resolveType := m.DNSResolveType
+		if resolveType == "" {
+			resolveType = "A"
+		}
+		config = executor.DNSConfig{
+			Host:           m.Hostname,
+			ResolverServer: m.DNSResolveServer,
+			Port:           port,
+			ResolveType:    resolveType,
+		}
+	case "push":
+		config = executor.PushConfig{PushToken: m.PushToken}
+		item.PushToken = m.PushToken
+	}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	item.Config = string(data)
+
+	return item, warnings, nil
+}
+
+// mapHTTPConfig converts the settings of the HTTP based monitor types
+func mapHTTPConfig(m *MonitorDto) (*executor.HTTPConfig, []string) {
+	var warnings []string
+
+	method := strings.ToUpper(m.Method)
+	if method == "" {
+		method = "GET"
+	}
+	encoding := strings.ToLower(m.HTTPBodyEncoding)
+	if encoding == "" {
+		encoding = "json"
+	}
+	codes, codeWarnings := mapStatusCodes(m.AcceptedStatusCodes)
+	warnings = append(warnings, codeWarnings...)
+
+	config := &executor.HTTPConfig{
+		Url:                 m.URL,
+		Method:              method,
+		Headers:             executor.HTTPHeaders(m.Headers),
+		Encoding:            encoding,
+		Body:                m.Body,
+		AcceptedStatusCodes: codes,
+		MaxRedirects:        max(m.MaxRedirects, 0),
+		IgnoreTlsErrors:     bool(m.IgnoreTLS),
+		CheckCertExpiry:     bool(m.ExpiryNotification),
+		AuthMethod:          "none",
+	}
+
+	switch m.Type {
+	case "keyword":
+		config.Keyword = m.Keyword
+		config.InvertKeyword = bool(m.InvertKeyword)
+	case "json-query":
+		config.JsonQuery = m.JSONPath
+		config.ExpectedValue = m.ExpectedValue
+		config.JsonCondition = "=="
+		switch m.JSONPathOperator {
+		case "", "==":
+		case "!=", ">", "<", ">=", "<=":
+			config.JsonCondition = m.JSONPathOperator
+		default:
+			warnings = append(warnings, fmt.Sprintf("json query operator %q replaced by ==", m.JSONPathOperator))
+		}
+	}
+
+	switch m.AuthMethod {
+	case "":
+	case "basic":
+		config.AuthMethod = "basic"
+		config.BasicAuthUser = m.BasicAuthUser
+		config.BasicAuthPass = m.BasicAuthPass
+	case "ntlm":
+		config.AuthMethod = "ntlm"
+		config.BasicAuthUser = m.BasicAuthUser
+		config.BasicAuthPass = m.BasicAuthPass
+		config.AuthDomain = m.AuthDomain
+		config.AuthWorkstation = m.AuthWorkstation
+	case "oauth2-cc":
+		config.AuthMethod = "oauth2-cc"
+		config.OauthAuthMethod = m.OauthAuthMethod
+		config.OauthTokenUrl = m.OauthTokenURL
+		config.OauthClientId = m.OauthClientID
+		config.OauthClientSecret = m.OauthClientSecret
+		config.OauthScopes = m.OauthScopes
+	case "mtls":
+		config.AuthMethod = "mtls"
+		config.TlsCert = m.TLSCert
+		config.TlsKey = m.TLSKey
+		config.TlsCa = m.TLSCa
+	default:
+		warnings = append(warnings, fmt.Sprintf("authentication method %q dropped", m.AuthMethod))
+	}
+
+	return config, warnings
+}
+
+// mapStatusCodes converts the accepted status codes of Uptime Kuma, single codes and
+// ranges such as "200-299", into the accepted status codes of Peekaping, which takes them
+// as they are. Codes that aren't valid status codes are dropped.
+func mapStatusCodes(accepted []string) ([]string, []string) {
+	var warnings []string
+	codes := make([]string, 0, len(accepted))
+
+	for _, code := range accepted {
+		code = strings.TrimSpace(code)
+		from, to, isRange := strings.Cut(code, "-")
+		if !isRange {
+			to = from
+		}
+		low, errLow := strconv.Atoi(from)
+		high, errHigh := strconv.Atoi(to)
+		if errLow != nil || errHigh != nil || low < 100 || high > 599 || low > high {
+			warnings = append(warnings, fmt.Sprintf("accepted status code %q dropped", code))
+			continue
+		}
+		if !slices.Contains(codes, code) {
+			codes = append(codes, code)
+		}
+	}
+
+	if len(codes) == 0 {
+		codes = append(codes, "200-299")
+	}
+	return codes, warnings
+}
@@ -0,0 +1,246 @@
+package uptime_kuma
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"peekaping/internal/modules/healthcheck/executor"
+	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/notification_channel"
+	"peekaping/internal/modules/notification_channel/providers"
+	"peekaping/internal/modules/tag"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// backupJSON is trimmed from a backup of Uptime Kuma 1.23, with the flags of the second
+// monitor stored as numbers like older versions do
+const backupJSON = `{
+	"version": "1.23.16",
+	"notificationList": [
+		{"id": 1, "name": "Ops Telegram", "active": true, "isDefault": false, "config": "{\"name\":\"Ops Telegram\",\"type\":\"telegram\",\"telegramBotToken\":\"123:abc\",\"telegramChatID\":\"-100200\",\"telegramSendSilently\":true}"},
+		{"id": 2, "name": "Ops Slack", "active": 1, "isDefault": 0, "config": "{\"name\":\"Ops Slack\",\"type\":\"slack\",\"slackwebhookURL\":\"https://hooks.slack.com/services/T/B/X\",\"slackchannel\":\"#ops\"}"},
+		{"id": 3, "name": "Pager", "active": true, "isDefault": false, "config": "{\"name\":\"Pager\",\"type\":\"pagerduty\",\"pagerdutyIntegrationKey\":\"key\"}"},
+		{"id": 4, "name": "Mail", "active": true, "isDefault": false, "config": "{\"name\":\"Mail\",\"type\":\"smtp\",\"smtpHost\":\"smtp.example.com\",\"smtpPort\":\"587\",\"smtpUsername\":\"alerts\",\"smtpPassword\":\"secret\",\"smtpFrom\":\"alerts@example.com\",\"smtpTo\":\"ops@example.com\"}"}
+	],
+	"monitorList": [
+		{"id": 1, "name": "Website", "type": "http", "url": "https://example.com", "method": "GET", "interval": 60, "retryInterval": 60, "maxretries": 2, "timeout": 48, "active": true,
+		 "accepted_statuscodes": ["200-299"], "maxredirects": 10, "ignoreTls": false, "upsideDown": false, "expiryNotification": true,
+		 "notificationIDList": {"1": true, "3": true}, "tags": [{"name": "Production", "color": "#059669", "value": "eu"}]},
+		{"id": 2, "name": "Database", "type": "port", "hostname": "db.internal", "port": 5432, "interval": 30, "retryInterval": 0, "timeout": 0, "active": 1, "upsideDown": 0,
+		 "notificationIDList": {"2": true}, "tags": [{"name": "Production", "color": "#059669", "value": ""}]},
+		{"id": 3, "name": "Steam server", "type": "steam", "hostname": "game.example.com", "port": 27015, "interval": 60, "active": true},
+		{"id": 4, "name": "Inverted", "type": "ping", "hostname": "10.0.0.1", "interval": 60, "active": true, "upsideDown": true}
+	]
+}`
+
+func TestMapMonitor(t *testing.T) {
+	t.Run("http", func(t *testing.T) {
+		item, warnings, err := mapMonitor(&MonitorDto{
+			Name: "Website", Type: "keyword", URL: "https://example.com", Interval: 60, Timeout: 48, Active: true,
+			Keyword: "Welcome", InvertKeyword: true, AcceptedStatusCodes: []string{"200-299", "301"},
+			AuthMethod: "basic", BasicAuthUser: "user", BasicAuthPass: "pass", ExpiryNotification: true,
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "http-keyword", item.Type)
+		assert.Equal(t, 60, item.Interval)
+		assert.Equal(t, 60, item.RetryInterval)
+		assert.Equal(t, 48, item.Timeout)
+		assert.True(t, item.Active)
+
+		var config executor.HTTPConfig
+		require.NoError(t, json.Unmarshal([]byte(item.Config), &config))
+		assert.Equal(t, "https://example.com", config.Url)
+		assert.Equal(t, "GET", config.Method)
+		assert.Equal(t, "json", config.Encoding)
+		assert.Equal(t, []string{"200-299", "301"}, config.AcceptedStatusCodes)
+		assert.Equal(t, "Welcome", config.Keyword)
+		assert.True(t, config.InvertKeyword)
+		assert.True(t, config.CheckCertExpiry)
+		assert.Equal(t, "basic", config.AuthMethod)
+		assert.Equal(t, "user", config.BasicAuthUser)
+		assert.Empty(t, warnings)
+	})
+
+	t.Run("tcp with short interval", func(t *testing.T) {
+		item, warnings, err := mapMonitor(&MonitorDto{Name: "Database", Type: "port", Hostname: "db.internal", Port: 5432, Interval: 10})
+		require.NoError(t, err)
+
+		assert.Equal(t, "tcp", item.Type)
+		assert.Equal(t, 20, item.Interval)
+		assert.Equal(t, 20, item.RetryInterval)
+		assert.Equal(t, 16, item.Timeout)
+		assert.JSONEq(t, `{"host":"db.internal","port":5432}`, item.Config)
+		assert.Equal(t, []string{"interval raised from 10s to 20s"}, warnings)
+	})
+
+	t.Run("dns defaults", func(t *testing.T) {
+		item, _, err := mapMonitor(&MonitorDto{Name: "Resolver", Type: "dns", Hostname: "example.com", DNSResolveServer: "1.1.1.1", Interval: 60})
+		require.NoError(t, err)
+
+		var config executor.DNSConfig
+		require.NoError(t, json.Unmarshal([]byte(item.Config), &config))
+		assert.Equal(t, 53, config.Port)
+		assert.Equal(t, "A", config.ResolveType)
+		assert.Equal(t, "1.1.1.1", config.ResolverServer)
+	})
+
+	t.Run("push keeps its token", func(t *testing.T) {
+		item, _, err := mapMonitor(&MonitorDto{Name: "Backup job", Type: "push", PushToken: "abc123", Interval: 3600})
+		require.NoError(t, err)
+
+		assert.Equal(t, "abc123", item.PushToken)
+		assert.JSONEq(t, `{"pushToken":"abc123"}`, item.Config)
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		_, _, err := mapMonitor(&MonitorDto{Name: "Game", Type: "steam"})
+		assert.True(t, errors.Is(err, errUnsupported))
+		assert.EqualError(t, err, `unsupported monitor type "steam"`)
+
+		_, _, err = mapMonitor(&MonitorDto{Name: "Inverted", Type: "ping", UpsideDown: true})
+		assert.True(t, errors.Is(err, errUnsupported))
+	})
+}
+
+func TestMapStatusCodes(t *testing.T) {
+	codes, warnings := mapStatusCodes([]string{"200-399"})
+	assert.Equal(t, []string{"200-399"}, codes)
+	assert.Empty(t, warnings)
+
+	codes, warnings = mapStatusCodes([]string{"200-299", "404", "teapot", "404"})
+	assert.Equal(t, []string{"200-299", "404"}, codes)
+	assert.Equal(t, []string{`accepted status code "teapot" dropped`}, warnings)
+
+	codes, _ = mapStatusCodes(nil)
+	assert.Equal(t, []string{"200-299"}, codes)
+}
+
+func TestMapNotification(t *testing.T) {
+	channelType, config, err := mapNotification(&NotificationDto{
+		Config: `{"type":"webhook","webhookURL":"https://example.com/hook","webhookAdditionalHeaders":"{\"X-Token\":\"t\"}"}`,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "webhook", channelType)
+
+	var webhook providers.WebhookConfig
+	require.NoError(t, json.Unmarshal([]byte(config), &webhook))
+	assert.Equal(t, "https://example.com/hook", webhook.WebhookURL)
+	assert.Equal(t, "json", webhook.WebhookContentType)
+	assert.Equal(t, `{"X-Token":"t"}`, webhook.WebhookAdditionalHeaders)
+
+	channelType, _, err = mapNotification(&NotificationDto{Config: `{"type":"discord"}`})
+	assert.Equal(t, "discord", channelType)
+	assert.True(t, errors.Is(err, errUnsupported))
+}
+
+type fakeMonitorImporter struct {
+	items []monitor.ExportDto
+}
+
+func (f *fakeMonitorImporter) Import(ctx *gin.Context, items []monitor.ExportDto) ([]monitor.ImportResultDto, error) {
+	f.items = items
+	results := make([]monitor.ImportResultDto, 0, len(items))
+	for i, item := range items {
+		results = append(results, monitor.ImportResultDto{Index: i, Name: item.Name, Success: true, ID: "mon-" + item.Name})
+	}
+	return results, nil
+}
+
+type fakeChannelService struct {
+	notification_channel.Service
+	channels map[string]*notification_channel.Model
+}
+
+func (f *fakeChannelService) ChannelNames(ctx context.Context) (map[string]string, error) {
+	names := make(map[string]string, len(f.channels))
+	for id, c := range f.channels {
+		names[id] = c.Name
+	}
+	return names, nil
+}
+
+func (f *fakeChannelService) FindByID(ctx context.Context, id string) (*notification_channel.Model, error) {
+	return f.channels[id], nil
+}
+
+func (f *fakeChannelService) Create(ctx context.Context, dto *notification_channel.CreateUpdateDto) (*notification_channel.Model, error) {
+	created := &notification_channel.Model{ID: "chan-" + dto.Name, Name: dto.Name, Type: dto.Type, Config: &dto.Config}
+	f.channels[created.ID] = created
+	return created, nil
+}
+
+type fakeTagService struct {
+	tag.Service
+	tags map[string]*tag.Model
+}
+
+func (f *fakeTagService) FindByName(ctx context.Context, name string) (*tag.Model, error) {
+	return f.tags[name], nil
+}
+
+func (f *fakeTagService) Create(ctx context.Context, dto *tag.CreateUpdateDto) (*tag.Model, error) {
+	created := &tag.Model{ID: "tag-" + dto.Name, Name: dto.Name, Color: dto.Color}
+	f.tags[dto.Name] = created
+	return created, nil
+}
+
+func TestController_ImportBackup(t *testing.T) {
+	logger := zap.NewNop().Sugar()
+	notification_channel.RegisterNotificationChannelProvider("telegram", providers.NewTelegramSender(logger))
+	notification_channel.RegisterNotificationChannelProvider("smtp", providers.NewEmailSender(logger))
+	notification_channel.RegisterNotificationChannelProvider("slack", providers.NewSlackSender(logger, nil))
+
+	importer := &fakeMonitorImporter{}
+	channels := &fakeChannelService{channels: map[string]*notification_channel.Model{
+		"existing-slack": {ID: "existing-slack", Name: "Ops Slack", Type: "slack"},
+	}}
+	tags := &fakeTagService{tags: map[string]*tag.Model{}}
+	controller := NewController(importer, channels, tags, logger)
+
+	var backup BackupDto
+	require.NoError(t, json.Unmarshal([]byte(backupJSON), &backup))
+
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	report, err := controller.importBackup(ctx, &backup)
+	require.NoError(t, err)
+
+	assert.Equal(t, []ItemResultDto{{Name: "Production", Status: StatusCreated, ID: "tag-Production"}}, report.Tags)
+
+	require.Len(t, report.Notifications, 4)
+	assert.Equal(t, StatusCreated, report.Notifications[0].Status)
+	assert.Equal(t, "telegram", report.Notifications[0].Type)
+	assert.Equal(t, StatusExisting, report.Notifications[1].Status)
+	assert.Equal(t, "existing-slack", report.Notifications[1].ID)
+	assert.Equal(t, StatusSkipped, report.Notifications[2].Status)
+	assert.Equal(t, `unsupported notification type "pagerduty"`, report.Notifications[2].Reason)
+	assert.Equal(t, StatusCreated, report.Notifications[3].Status)
+	assert.JSONEq(t, `{"smtp_secure":false,"smtp_host":"smtp.example.com","smtp_port":587,"username":"alerts","password":"secret","from":"alerts@example.com","to":"ops@example.com","cc":"","bcc":"","custom_subject":"","custom_body":""}`,
+		*channels.channels["chan-Mail"].Config)
+
+	require.Len(t, report.Monitors, 4)
+	assert.Equal(t, ItemResultDto{
+		Name: "Website", Type: "http", Status: StatusCreated, ID: "mon-Website",
+		Warnings: []string{"notification 3 was not imported", `value "eu" of tag "Production" dropped`},
+	}, report.Monitors[0])
+	assert.Equal(t, ItemResultDto{Name: "Database", Type: "port", Status: StatusCreated, ID: "mon-Database"}, report.Monitors[1])
+	assert.Equal(t, StatusSkipped, report.Monitors[2].Status)
+	assert.Equal(t, StatusSkipped, report.Monitors[3].Status)
+	assert.Equal(t, "upside down mode is unsupported", report.Monitors[3].Reason)
+
+	// Only the supported monitors reach the monitor import, referencing tags and channels by name
+	require.Len(t, importer.items, 2)
+	assert.Equal(t, []monitor.NotificationExportDto{{Name: "Ops Telegram"}}, importer.items[0].Notifications)
+	assert.Equal(t, []string{"Production"}, importer.items[0].Tags)
+	assert.Equal(t, []monitor.NotificationExportDto{{Name: "Ops Slack"}}, importer.items[1].Notifications)
+	assert.True(t, importer.items[1].Active)
+	assert.Equal(t, 30, importer.items[1].RetryInterval)
+	assert.Equal(t, 24, importer.items[1].Timeout)
+}
@@ -0,0 +1,86 @@
+package websocket
+
+import (
+	"peekaping/internal/modules/heartbeat"
+	"sync"
+	"time"
+)
+
+// heartbeatBatcher coalesces the heartbeats broadcast to a room into one message per
+// interval. Important heartbeats (status changes) flush the room's batch right away so
+// they are never held back.
+type heartbeatBatcher struct {
+	interval time.Duration
+	emit     func(room string, heartbeats []*heartbeat.Model)
+
+	mu      sync.Mutex
+	pending map[string][]*heartbeat.Model
+	timers  map[string]*time.Timer
+	closed  bool
+}
+
+func newHeartbeatBatcher(interval time.Duration, emit func(room string, heartbeats []*heartbeat.Model)) *heartbeatBatcher {
+	return &heartbeatBatcher{
+		interval: interval,
+		emit:     emit,
+		pending:  make(map[string][]*heartbeat.Model),
+		timers:   make(map[string]*time.Timer),
+	}
+}
+
+// Add queues hb for room. The batch is emitted once the interval since its first
+// heartbeat is over, or immediately when hb is important.
+func (b *heartbeatBatcher) Add(room string, hb *heartbeat.Model) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+
+	b.pending[room] = append(b.pending[room], hb)
+
+	if hb.Important {
+		b.flushLocked(room)
+		return
+	}
+
+	if _, scheduled := b.timers[room]; !scheduled {
+		b.timers[room] = time.AfterFunc(b.interval, func() { b.flush(room) })
+	}
+}
+
+// Close emits every pending batch and stops batching
+func (b *heartbeatBatcher) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for room := range b.pending {
+		b.flushLocked(room)
+	}
+	b.closed = true
+}
+
+func (b *heartbeatBatcher) flush(room string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.flushLocked(room)
+}
+
+// flushLocked emits the room's batch. It is called with mu held, which keeps the
+// batches of a room in order.
+func (b *heartbeatBatcher) flushLocked(room string) {
+	if timer, ok := b.timers[room]; ok {
+		timer.Stop()
+		delete(b.timers, room)
+	}
+
+	heartbeats := b.pending[room]
+	delete(b.pending, room)
+	if len(heartbeats) == 0 {
+		return
+	}
+
+	b.emit(room, heartbeats)
+}
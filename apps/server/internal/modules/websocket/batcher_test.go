@@ -0,0 +1,84 @@
+package websocket
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"peekaping/internal/modules/heartbeat"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type emitted struct {
+	room       string
+	heartbeats []*heartbeat.Model
+}
+
+type recordingEmitter struct {
+	mu       sync.Mutex
+	messages []emitted
+}
+
+func (r *recordingEmitter) emit(room string, heartbeats []*heartbeat.Model) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, emitted{room, heartbeats})
+}
+
+func (r *recordingEmitter) snapshot() []emitted {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]emitted(nil), r.messages...)
+}
+
+func TestHeartbeatBatcher_CoalescesWithinInterval(t *testing.T) {
+	emitter := &recordingEmitter{}
+	batcher := newHeartbeatBatcher(50*time.Millisecond, emitter.emit)
+	defer batcher.Close()
+
+	hbs := []*heartbeat.Model{{ID: "hb-1"}, {ID: "hb-2"}, {ID: "hb-3"}}
+	for _, hb := range hbs {
+		batcher.Add("monitor:all", hb)
+	}
+	assert.Empty(t, emitter.snapshot(), "nothing is sent before the interval is over")
+
+	require.Eventually(t, func() bool { return len(emitter.snapshot()) > 0 }, time.Second, 5*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+
+	messages := emitter.snapshot()
+	require.Len(t, messages, 1)
+	assert.Equal(t, "monitor:all", messages[0].room)
+	assert.Equal(t, hbs, messages[0].heartbeats)
+}
+
+func TestHeartbeatBatcher_ImportantFlushesImmediately(t *testing.T) {
+	emitter := &recordingEmitter{}
+	batcher := newHeartbeatBatcher(time.Hour, emitter.emit)
+	defer batcher.Close()
+
+	batcher.Add("monitor:mon-1", &heartbeat.Model{ID: "hb-1"})
+	batcher.Add("monitor:mon-2", &heartbeat.Model{ID: "hb-other"})
+	batcher.Add("monitor:mon-1", &heartbeat.Model{ID: "hb-2", Important: true})
+
+	messages := emitter.snapshot()
+	require.Len(t, messages, 1, "only the room of the status change is flushed")
+	assert.Equal(t, "monitor:mon-1", messages[0].room)
+	require.Len(t, messages[0].heartbeats, 2)
+	assert.Equal(t, "hb-1", messages[0].heartbeats[0].ID)
+	assert.Equal(t, "hb-2", messages[0].heartbeats[1].ID)
+}
+
+func TestHeartbeatBatcher_CloseFlushesPending(t *testing.T) {
+	emitter := &recordingEmitter{}
+	batcher := newHeartbeatBatcher(time.Hour, emitter.emit)
+
+	batcher.Add("monitor:mon-1", &heartbeat.Model{ID: "hb-1"})
+	batcher.Close()
+	batcher.Add("monitor:mon-1", &heartbeat.Model{ID: "hb-2"})
+
+	messages := emitter.snapshot()
+	require.Len(t, messages, 1)
+	assert.Equal(t, "hb-1", messages[0].heartbeats[0].ID)
+}
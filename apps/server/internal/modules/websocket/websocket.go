@@ -9,6 +9,7 @@ import (
 	"peekaping/internal/modules/auth"
 	"peekaping/internal/modules/events"
 	"peekaping/internal/modules/heartbeat"
+	"strings"
 
 	"github.com/zishang520/socket.io/v2/socket"
 	"go.uber.org/zap"
@@ -18,6 +19,7 @@ type Server struct {
 	io         *socket.Server
 	eventBus   events.EventBus
 	tokenMaker *auth.TokenMaker
+	batcher    *heartbeatBatcher // nil when heartbeats are broadcast one by one
 }
 
 type SocketData struct {
@@ -28,6 +30,7 @@ func NewServer(
 	cfg *config.Config,
 	eventBus events.EventBus,
 	tokenMaker *auth.TokenMaker,
+	userRepo auth.Repository,
 	logger *zap.SugaredLogger,
 ) (*Server, error) {
 	opts := socket.DefaultServerOptions()
@@ -53,6 +56,10 @@ func NewServer(
 			next(socket.NewExtendedError("Unauthorized", nil))
 			return
 		}
+		if _, err := auth.CheckSession(ctx, userRepo, claims); err != nil {
+			next(socket.NewExtendedError("Unauthorized", nil))
+			return
+		}
 
 		data := SocketData{UserId: fmt.Sprint(claims.UserID)}
 
@@ -67,9 +74,15 @@ func NewServer(
 
 		logger.Debugf("[WS]connection: %s", userId)
 
+		// Lets the sockets of the user be closed when its sessions are revoked
+		client.Join(userRoom(userId))
+
 		client.On("join_room", func(args ...interface{}) {
 			roomName := args[0].(string)
 			logger.Debugf("join_room: %s", roomName)
+			if strings.HasPrefix(roomName, "user:") {
+				return
+			}
 
 			// TODO: validate if user allowed to join room
 			client.Join(socket.Room(roomName))
@@ -85,6 +98,12 @@ func NewServer(
 		})
 	})
 
+	if cfg.WSHeartbeatBatchInterval > 0 {
+		server.batcher = newHeartbeatBatcher(cfg.WSHeartbeatBatchInterval, func(room string, heartbeats []*heartbeat.Model) {
+			server.io.To(socket.Room(room)).Emit(room+":heartbeats", heartbeats)
+		})
+	}
+
 	// Listen for heartbeat events and broadcast to room
 	eventBus.Subscribe(events.HeartbeatEvent, func(event events.Event) {
 		hb, ok := infra.UnmarshalEventPayload[heartbeat.Model](event)
@@ -93,17 +112,42 @@ func NewServer(
 			return
 		}
 		roomName := "monitor:" + hb.MonitorID
+		if server.batcher != nil {
+			server.batcher.Add(roomName, hb)
+			server.batcher.Add("monitor:all", hb)
+			return
+		}
 		server.io.To(socket.Room(roomName)).Emit(roomName+":heartbeat", hb)
 		server.io.To(socket.Room("monitor:all")).Emit("monitor:all:heartbeat", hb)
 	})
 
+	// Tokens are only checked when connecting, close the connections of users whose
+	// tokens were revoked since
+	eventBus.Subscribe(events.SessionsRevoked, func(event events.Event) {
+		payload, ok := infra.UnmarshalEventPayload[events.SessionsRevokedPayload](event)
+		if !ok {
+			logger.Warn("Failed to unmarshal sessions revoked event payload")
+			return
+		}
+		logger.Debugf("[WS]closing connections of user %s", payload.UserID)
+		server.io.In(userRoom(payload.UserID)).DisconnectSockets(true)
+	})
+
 	return server, nil
 }
 
+// userRoom is the room every socket of a user joins
+func userRoom(userId string) socket.Room {
+	return socket.Room("user:" + userId)
+}
+
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	s.io.ServeHandler(nil).ServeHTTP(w, r)
 }
 
 func (s *Server) Close() {
+	if s.batcher != nil {
+		s.batcher.Close()
+	}
 	s.io.Close(nil)
 }
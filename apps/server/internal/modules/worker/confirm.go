@@ -0,0 +1,59 @@
+package worker
+
+import (
+	"context"
+	"peekaping/internal/modules/queue"
+	"peekaping/internal/modules/shared"
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+// isUpState flattens a status to the state transitions are confirmed between, pending
+// counts as down. ok is false for maintenance, which is never confirmed.
+func isUpState(status shared.MonitorStatus) (up bool, ok bool) {
+	switch status {
+	case shared.MonitorStatusUp:
+		return true, true
+	case shared.MonitorStatusDown, shared.MonitorStatusPending:
+		return false, true
+	default:
+		return false, false
+	}
+}
+
+// needsConfirmation reports whether a check result of a monitor confirming its transitions
+// changes the state of its latest heartbeat. Confirmation checks are recorded as they are:
+// when they disagree with the first check, the monitor keeps its state.
+func needsConfirmation(payload *HealthCheckTaskPayload, status shared.MonitorStatus) bool {
+	if !payload.ConfirmTransition || payload.Confirming || payload.IsUnderMaintenance || payload.LastHeartbeat == nil {
+		return false
+	}
+	previous, ok := isUpState(payload.LastHeartbeat.Status)
+	if !ok {
+		return false
+	}
+	current, ok := isUpState(status)
+	return ok && previous != current
+}
+
+// enqueueConfirmation schedules an immediate check of the monitor on the queue of the
+// current task, in place of a result that would change the monitor's state
+func (h *HealthCheckTaskHandler) enqueueConfirmation(ctx context.Context, payload *HealthCheckTaskPayload) error {
+	queueName, ok := asynq.GetQueueName(ctx)
+	if !ok {
+		queueName = queue.HealthCheckQueue
+	}
+
+	confirmation := *payload
+	confirmation.Confirming = true
+	confirmation.ScheduledAt = time.Now().UTC()
+
+	_, err := h.queueService.Enqueue(ctx, TaskTypeHealthCheck, &confirmation, &queue.EnqueueOptions{
+		Queue:     queueName,
+		MaxRetry:  0,
+		Timeout:   time.Duration(payload.Timeout) * time.Second,
+		Retention: 0,
+	})
+	return err
+}
@@ -0,0 +1,163 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"peekaping/internal/config"
+	"peekaping/internal/modules/healthcheck"
+	"peekaping/internal/modules/healthcheck/executor"
+	"peekaping/internal/modules/queue"
+	"peekaping/internal/modules/shared"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// confirmingQueue also keeps the confirmation checks enqueued by the handler
+type confirmingQueue struct {
+	recordingQueue
+	confirmations []*HealthCheckTaskPayload
+	err           error
+}
+
+func (q *confirmingQueue) Enqueue(ctx context.Context, taskType string, payload interface{}, opts *queue.EnqueueOptions) (*queue.TaskInfo, error) {
+	if q.err != nil {
+		return nil, q.err
+	}
+	q.confirmations = append(q.confirmations, payload.(*HealthCheckTaskPayload))
+	return &queue.TaskInfo{}, nil
+}
+
+func TestNeedsConfirmation(t *testing.T) {
+	beat := func(status shared.MonitorStatus) *shared.HeartBeatModel {
+		return &shared.HeartBeatModel{Status: status}
+	}
+
+	tests := []struct {
+		name     string
+		payload  HealthCheckTaskPayload
+		status   shared.MonitorStatus
+		expected bool
+	}{
+		{"up to down", HealthCheckTaskPayload{ConfirmTransition: true, LastHeartbeat: beat(shared.MonitorStatusUp)}, shared.MonitorStatusDown, true},
+		{"pending to up", HealthCheckTaskPayload{ConfirmTransition: true, LastHeartbeat: beat(shared.MonitorStatusPending)}, shared.MonitorStatusUp, true},
+		{"same state", HealthCheckTaskPayload{ConfirmTransition: true, LastHeartbeat: beat(shared.MonitorStatusUp)}, shared.MonitorStatusUp, false},
+		{"pending to down", HealthCheckTaskPayload{ConfirmTransition: true, LastHeartbeat: beat(shared.MonitorStatusPending)}, shared.MonitorStatusDown, false},
+		{"option off", HealthCheckTaskPayload{LastHeartbeat: beat(shared.MonitorStatusUp)}, shared.MonitorStatusDown, false},
+		{"first check", HealthCheckTaskPayload{ConfirmTransition: true}, shared.MonitorStatusDown, false},
+		{"confirmation check", HealthCheckTaskPayload{ConfirmTransition: true, Confirming: true, LastHeartbeat: beat(shared.MonitorStatusUp)}, shared.MonitorStatusDown, false},
+		{"under maintenance", HealthCheckTaskPayload{ConfirmTransition: true, IsUnderMaintenance: true, LastHeartbeat: beat(shared.MonitorStatusUp)}, shared.MonitorStatusDown, false},
+		{"out of maintenance", HealthCheckTaskPayload{ConfirmTransition: true, LastHeartbeat: beat(shared.MonitorStatusMaintenance)}, shared.MonitorStatusDown, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, needsConfirmation(&tt.payload, tt.status))
+		})
+	}
+}
+
+func TestProcessTask_ConfirmTransition(t *testing.T) {
+	tests := []struct {
+		name string
+		// statuses answered by the server to the first and the confirmation check
+		statuses []int
+		expected shared.MonitorStatus
+	}{
+		{"confirmed transition is recorded", []int{http.StatusInternalServerError, http.StatusInternalServerError}, shared.MonitorStatusDown},
+		{"unconfirmed transition keeps the state", []int{http.StatusInternalServerError, http.StatusOK}, shared.MonitorStatusUp},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var checks atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statuses[checks.Add(1)-1])
+			}))
+			defer server.Close()
+
+			logger := zap.NewNop().Sugar()
+			registry := executor.NewExecutorRegistry(logger, &config.Config{})
+			queueService := &confirmingQueue{}
+			handler := NewHealthCheckTaskHandler(registry, nil, healthcheck.NewHealthCheck(nil, registry, logger), queueService, nil, newTestRedis(t), &config.Config{}, logger)
+
+			payload, err := json.Marshal(HealthCheckTaskPayload{
+				MonitorID:         "mon-1",
+				MonitorName:       "API",
+				MonitorType:       "http",
+				Interval:          60,
+				Timeout:           5,
+				Serialize:         true,
+				ConfirmTransition: true,
+				LastHeartbeat:     &shared.HeartBeatModel{Status: shared.MonitorStatusUp},
+				ScheduledAt:       time.Now().UTC(),
+				Config: fmt.Sprintf(`{"url": %q, "method": "GET", "encoding": "json", "accepted_statuscodes": ["2XX"], "authMethod": "none"}`,
+					server.URL),
+			})
+			require.NoError(t, err)
+
+			// The first result changes the state, a confirmation is enqueued instead
+			require.NoError(t, handler.ProcessTask(context.Background(), asynq.NewTask(TaskTypeHealthCheck, payload)))
+			assert.Empty(t, queueService.results())
+			require.Len(t, queueService.confirmations, 1)
+			assert.True(t, queueService.confirmations[0].Confirming)
+			// The lock of the serialized monitor is released for the confirmation
+			token, ok, err := handler.running.TryLock(context.Background(), "mon-1", time.Minute)
+			require.NoError(t, err)
+			assert.True(t, ok)
+			require.NoError(t, handler.running.Unlock(context.Background(), "mon-1", token))
+
+			confirmation, err := json.Marshal(queueService.confirmations[0])
+			require.NoError(t, err)
+			require.NoError(t, handler.ProcessTask(context.Background(), asynq.NewTask(TaskTypeHealthCheck, confirmation)))
+
+			assert.Equal(t, int32(2), checks.Load())
+			assert.Len(t, queueService.confirmations, 1)
+			results := queueService.results()
+			require.Len(t, results, 1)
+			assert.Equal(t, tt.expected, results[0].Status)
+		})
+	}
+}
+
+func TestProcessTask_ConfirmTransition_EnqueueFails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	logger := zap.NewNop().Sugar()
+	registry := executor.NewExecutorRegistry(logger, &config.Config{})
+	queueService := &confirmingQueue{err: errors.New("redis unavailable")}
+	handler := NewHealthCheckTaskHandler(registry, nil, healthcheck.NewHealthCheck(nil, registry, logger), queueService, nil, nil, &config.Config{}, logger)
+
+	payload, err := json.Marshal(HealthCheckTaskPayload{
+		MonitorID:         "mon-1",
+		MonitorName:       "API",
+		MonitorType:       "http",
+		Interval:          60,
+		Timeout:           5,
+		ConfirmTransition: true,
+		LastHeartbeat:     &shared.HeartBeatModel{Status: shared.MonitorStatusUp},
+		ScheduledAt:       time.Now().UTC(),
+		Config: fmt.Sprintf(`{"url": %q, "method": "GET", "encoding": "json", "accepted_statuscodes": ["2XX"], "authMethod": "none"}`,
+			server.URL),
+	})
+	require.NoError(t, err)
+
+	// Without a confirmation the result is recorded as is
+	require.NoError(t, handler.ProcessTask(context.Background(), asynq.NewTask(TaskTypeHealthCheck, payload)))
+	results := queueService.results()
+	require.Len(t, results, 1)
+	assert.Equal(t, shared.MonitorStatusDown, results[0].Status)
+}
@@ -4,16 +4,20 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"peekaping/internal/config"
 	"peekaping/internal/modules/certificate"
+	"peekaping/internal/modules/events"
 	"peekaping/internal/modules/healthcheck"
 	"peekaping/internal/modules/healthcheck/executor"
 	"peekaping/internal/modules/monitor"
 	"peekaping/internal/modules/proxy"
 	"peekaping/internal/modules/queue"
 	"peekaping/internal/modules/shared"
+	"strings"
 	"time"
 
 	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 )
 
@@ -35,22 +39,51 @@ type ProxyData struct {
 	Password string `json:"password,omitempty"`
 }
 
+func (d *ProxyData) toModel() *proxy.Model {
+	return &proxy.Model{
+		ID:       d.ID,
+		Protocol: d.Protocol,
+		Host:     d.Host,
+		Port:     d.Port,
+		Auth:     d.Auth,
+		Username: d.Username,
+		Password: d.Password,
+	}
+}
+
 // HealthCheckTaskPayload is the payload for health check tasks
 type HealthCheckTaskPayload struct {
-	MonitorID          string                 `json:"monitor_id"`
-	MonitorName        string                 `json:"monitor_name"`
-	MonitorType        string                 `json:"monitor_type"`
-	Interval           int                    `json:"interval"`
-	Timeout            int                    `json:"timeout"`
-	MaxRetries         int                    `json:"max_retries"`
-	RetryInterval      int                    `json:"retry_interval"`
-	ResendInterval     int                    `json:"resend_interval"`
-	Config             string                 `json:"config"`
-	Proxy              *ProxyData             `json:"proxy,omitempty"`
-	LastHeartbeat      *shared.HeartBeatModel `json:"last_heartbeat,omitempty"`
-	ScheduledAt        time.Time              `json:"scheduled_at"`
-	IsUnderMaintenance bool                   `json:"is_under_maintenance"`
-	CheckCertExpiry    bool                   `json:"check_cert_expiry"`
+	MonitorID         string       `json:"monitor_id"`
+	MonitorName       string       `json:"monitor_name"`
+	MonitorType       string       `json:"monitor_type"`
+	Interval          int          `json:"interval"`
+	Timeout           int          `json:"timeout"`
+	MaxRetries        int          `json:"max_retries"`
+	RetryInterval     int          `json:"retry_interval"`
+	ResendInterval    int          `json:"resend_interval"`
+	WarmupChecks      int          `json:"warmup_checks"`
+	LatencyLimit      int          `json:"latency_limit"`
+	LatencyChecks     int          `json:"latency_checks"`
+	ResultExpression  string       `json:"result_expression,omitempty"`
+	UpMessage         string       `json:"up_message,omitempty"`
+	DownMessage       string       `json:"down_message,omitempty"`
+	ActivatedAt       *time.Time   `json:"activated_at,omitempty"`
+	Config            string       `json:"config"`
+	Proxy             *ProxyData   `json:"proxy,omitempty"`
+	FallbackProxies   []*ProxyData `json:"fallback_proxies,omitempty"`
+	NoProxy           bool         `json:"no_proxy"`
+	Serialize         bool         `json:"serialize"`
+	ConfirmTransition bool         `json:"confirm_transition"`
+	// Confirming is set on the immediate check confirming a change of state
+	Confirming         bool                              `json:"confirming,omitempty"`
+	LastHeartbeat      *shared.HeartBeatModel            `json:"last_heartbeat,omitempty"`
+	ChildHeartbeats    map[string]*shared.HeartBeatModel `json:"child_heartbeats,omitempty"`
+	ScheduledAt        time.Time                         `json:"scheduled_at"`
+	IsUnderMaintenance bool                              `json:"is_under_maintenance"`
+	// AlertsSuppressed is set on checks running inside a maintenance window, their
+	// results are recorded as usual without alerting
+	AlertsSuppressed bool `json:"alerts_suppressed,omitempty"`
+	CheckCertExpiry  bool `json:"check_cert_expiry"`
 }
 
 // IngesterTaskPayload is the payload for ingester tasks
@@ -63,6 +96,8 @@ type IngesterTaskPayload struct {
 	MonitorMaxRetries  int                  `json:"monitor_max_retries"`
 	MonitorRetryInt    int                  `json:"monitor_retry_interval"`
 	MonitorResendInt   int                  `json:"monitor_resend_interval"`
+	MonitorWarmup      int                  `json:"monitor_warmup_checks"`
+	MonitorActivatedAt *time.Time           `json:"monitor_activated_at,omitempty"`
 	MonitorConfig      string               `json:"monitor_config"`
 	Status             shared.MonitorStatus `json:"status"`
 	Message            string               `json:"message"`
@@ -70,30 +105,77 @@ type IngesterTaskPayload struct {
 	StartTime          time.Time            `json:"start_time"`
 	EndTime            time.Time            `json:"end_time"`
 	IsUnderMaintenance bool                 `json:"is_under_maintenance"`
+	AlertsSuppressed   bool                 `json:"alerts_suppressed,omitempty"`
 	TLSInfo            *certificate.TLSInfo `json:"tls_info,omitempty"`
 	CheckCertExpiry    bool                 `json:"check_cert_expiry"`
+	ErrorCategory      string               `json:"error_category,omitempty"`
+	BodyHash           string               `json:"body_hash,omitempty"`
+	ResponseSize       *int64               `json:"response_size,omitempty"`
 }
 
 // HealthCheckTaskHandler handles health check tasks from the queue
 type HealthCheckTaskHandler struct {
 	execRegistry       *executor.ExecutorRegistry
+	metrics            *executor.ExecutorMetrics
 	healthCheckService *healthcheck.HealthCheckSupervisor
 	queueService       queue.Service
+	eventBus           events.EventBus
+	latency            *LatencyTracker
+	running            *CheckLocks
+	expressions        *executor.ResultExpressionCache
+	messages           *executor.MessageTemplateCache
+	results            *executor.ResultCache
+	defaultProxy       *proxy.Model
+	proxies            *ProxySelector
+	hardTimeoutGrace   time.Duration
+	probeProxy         func(ctx context.Context, p *proxy.Model) error
 	logger             *zap.SugaredLogger
 }
 
 // NewHealthCheckTaskHandler creates a new health check task handler
 func NewHealthCheckTaskHandler(
 	execRegistry *executor.ExecutorRegistry,
+	metrics *executor.ExecutorMetrics,
 	healthCheckService *healthcheck.HealthCheckSupervisor,
 	queueService queue.Service,
+	eventBus events.EventBus,
+	rdb *redis.Client,
+	cfg *config.Config,
 	logger *zap.SugaredLogger,
 ) *HealthCheckTaskHandler {
+	logger = logger.With("component", "healthcheck_handler")
+
+	var defaultProxy *proxy.Model
+	if cfg.DefaultProxyURL != "" {
+		parsed, err := proxy.ParseURL(cfg.DefaultProxyURL)
+		if err != nil {
+			logger.Warnw("Ignoring invalid DEFAULT_PROXY_URL", "error", err)
+		} else {
+			defaultProxy = parsed
+		}
+	}
+
+	var results *executor.ResultCache
+	if cfg.ResultCacheTTL > 0 {
+		results = executor.NewResultCache(cfg.ResultCacheTTL)
+	}
+
 	return &HealthCheckTaskHandler{
 		execRegistry:       execRegistry,
+		metrics:            metrics,
 		healthCheckService: healthCheckService,
 		queueService:       queueService,
-		logger:             logger.With("component", "healthcheck_handler"),
+		eventBus:           eventBus,
+		latency:            NewLatencyTracker(rdb),
+		running:            NewCheckLocks(rdb),
+		expressions:        executor.NewResultExpressionCache(),
+		messages:           executor.NewMessageTemplateCache(),
+		results:            results,
+		defaultProxy:       defaultProxy,
+		proxies:            NewProxySelector(cfg.ProxyFailureCooldown),
+		hardTimeoutGrace:   cfg.ExecutorHardTimeoutGrace,
+		probeProxy:         proxy.Probe,
+		logger:             logger,
 	}
 }
 
@@ -136,40 +218,83 @@ func (h *HealthCheckTaskHandler) ProcessTask(ctx context.Context, task *asynq.Ta
 		return nil
 	}
 
+	unlock := func() {}
+	var lock *checkLock
+	if payload.Serialize {
+		ttl := time.Duration(payload.Timeout)*time.Second + h.hardTimeoutGrace + checkLockGrace
+		token, ok, err := h.running.TryLock(ctx, payload.MonitorID, ttl)
+		switch {
+		case err != nil:
+			// Skipping every check while Redis is unavailable would hide the monitor's state
+			h.logger.Warnw("Running health check without its lock",
+				"monitor_id", payload.MonitorID,
+				"error", err,
+			)
+		case !ok:
+			h.logger.Infow("Skipping health check, previous check still running",
+				"monitor_id", payload.MonitorID,
+				"monitor_name", payload.MonitorName,
+			)
+			return h.enqueueSkipped(ctx, &payload)
+		default:
+			lock = &checkLock{
+				locks:     h.running,
+				monitorID: payload.MonitorID,
+				token:     token,
+				onError: func(err error) {
+					h.logger.Warnw("Failed to release check lock", "monitor_id", payload.MonitorID, "error", err)
+				},
+			}
+			unlock = lock.Release
+			defer unlock()
+		}
+	}
+
 	// Create monitor model from payload
 	m := &monitor.Model{
-		ID:             payload.MonitorID,
-		Type:           payload.MonitorType,
-		Name:           payload.MonitorName,
-		Interval:       payload.Interval,
-		Timeout:        payload.Timeout,
-		MaxRetries:     payload.MaxRetries,
-		RetryInterval:  payload.RetryInterval,
-		ResendInterval: payload.ResendInterval,
-		Config:         payload.Config,
-		LastHeartbeat:  payload.LastHeartbeat,
-	}
-
-	// Create proxy model from payload if present
-	var proxyModel *proxy.Model = nil
-	if payload.Proxy != nil {
-		proxyModel = &proxy.Model{
-			ID:       payload.Proxy.ID,
-			Protocol: payload.Proxy.Protocol,
-			Host:     payload.Proxy.Host,
-			Port:     payload.Proxy.Port,
-			Auth:     payload.Proxy.Auth,
-			Username: payload.Proxy.Username,
-			Password: payload.Proxy.Password,
-		}
+		ID:               payload.MonitorID,
+		Type:             payload.MonitorType,
+		Name:             payload.MonitorName,
+		Interval:         payload.Interval,
+		Timeout:          payload.Timeout,
+		MaxRetries:       payload.MaxRetries,
+		RetryInterval:    payload.RetryInterval,
+		ResendInterval:   payload.ResendInterval,
+		WarmupChecks:     payload.WarmupChecks,
+		LatencyLimit:     payload.LatencyLimit,
+		LatencyChecks:    payload.LatencyChecks,
+		ResultExpression: payload.ResultExpression,
+		UpMessage:        payload.UpMessage,
+		DownMessage:      payload.DownMessage,
+		ActivatedAt:      payload.ActivatedAt,
+		Config:           payload.Config,
+		LastHeartbeat:    payload.LastHeartbeat,
+		ChildHeartbeats:  payload.ChildHeartbeats,
 	}
 
+	// Pick the first healthy proxy of the payload, falling back to the worker's default proxy
+	proxyModel := h.proxyForPayload(&payload)
+
 	// Get the appropriate executor for this monitor type
 	exec, ok := h.execRegistry.GetExecutor(m.Type)
 	if !ok {
 		h.logger.Errorw("Executor not found for monitor type", "monitor_type", m.Type)
 		return fmt.Errorf("executor not found for monitor type: %s", m.Type)
 	}
+	// Inside the hard timeout guard so an abandoned call keeps the monitor locked
+	if lock != nil {
+		exec = lock.wrap(exec)
+	}
+	if h.hardTimeoutGrace > 0 {
+		exec = executor.WithHardTimeout(m.Type, exec, h.hardTimeoutGrace, h.logger)
+	}
+	if h.metrics != nil {
+		exec = h.metrics.Instrument(m.Type, exec)
+	}
+	// Outermost so the metrics only count checks that actually reached the target
+	if h.results != nil {
+		exec = h.results.Wrap(m.Type, exec)
+	}
 
 	// Execute the health check using the supervisor's method
 	tickResult := h.healthCheckService.HandleMonitorTick(ctx, m, exec, proxyModel, payload.IsUnderMaintenance)
@@ -183,6 +308,8 @@ func (h *HealthCheckTaskHandler) ProcessTask(ctx context.Context, task *asynq.Ta
 		return nil
 	}
 
+	h.applyResultExpression(ctx, m, tickResult)
+
 	h.logger.Debugw("Health check executed",
 		"monitor_id", payload.MonitorID,
 		"monitor_name", payload.MonitorName,
@@ -190,6 +317,30 @@ func (h *HealthCheckTaskHandler) ProcessTask(ctx context.Context, task *asynq.Ta
 		"ping_ms", tickResult.PingMs,
 	)
 
+	h.classifyProxyFailure(ctx, m, proxyModel, tickResult.ExecutionResult)
+	h.applyMessageTemplate(m, tickResult)
+	h.trackLatency(ctx, m, tickResult, payload.AlertsSuppressed)
+
+	if needsConfirmation(&payload, tickResult.ExecutionResult.Status) {
+		// The confirmation must not find the monitor still locked by this check
+		unlock()
+		err := h.enqueueConfirmation(ctx, &payload)
+		if err == nil {
+			h.logger.Infow("Change of state awaiting confirmation",
+				"monitor_id", payload.MonitorID,
+				"monitor_name", payload.MonitorName,
+				"previous_status", payload.LastHeartbeat.Status,
+				"status", tickResult.ExecutionResult.Status,
+				"message", tickResult.ExecutionResult.Message,
+			)
+			return nil
+		}
+		h.logger.Warnw("Failed to enqueue confirmation check, recording the result as is",
+			"monitor_id", payload.MonitorID,
+			"error", err,
+		)
+	}
+
 	// Enqueue the result to the ingester queue
 	ingesterPayload := IngesterTaskPayload{
 		MonitorID:          m.ID,
@@ -200,6 +351,8 @@ func (h *HealthCheckTaskHandler) ProcessTask(ctx context.Context, task *asynq.Ta
 		MonitorMaxRetries:  m.MaxRetries,
 		MonitorRetryInt:    m.RetryInterval,
 		MonitorResendInt:   m.ResendInterval,
+		MonitorWarmup:      m.WarmupChecks,
+		MonitorActivatedAt: m.ActivatedAt,
 		MonitorConfig:      m.Config,
 		Status:             tickResult.ExecutionResult.Status,
 		Message:            tickResult.ExecutionResult.Message,
@@ -207,10 +360,29 @@ func (h *HealthCheckTaskHandler) ProcessTask(ctx context.Context, task *asynq.Ta
 		StartTime:          tickResult.ExecutionResult.StartTime,
 		EndTime:            tickResult.ExecutionResult.EndTime,
 		IsUnderMaintenance: tickResult.IsUnderMaintenance,
+		AlertsSuppressed:   payload.AlertsSuppressed,
 		TLSInfo:            tickResult.ExecutionResult.TLSInfo,
 		CheckCertExpiry:    payload.CheckCertExpiry,
+		ErrorCategory:      tickResult.ExecutionResult.ErrorCategory,
+		BodyHash:           tickResult.ExecutionResult.BodyHash,
+		ResponseSize:       tickResult.ExecutionResult.ResponseSize,
 	}
 
+	if err := h.enqueueIngest(ctx, &ingesterPayload); err != nil {
+		return err
+	}
+
+	h.logger.Infow("Successfully enqueued result to ingester",
+		"monitor_id", payload.MonitorID,
+		"monitor_name", payload.MonitorName,
+		"duration", time.Since(start),
+	)
+
+	return nil
+}
+
+// enqueueIngest sends a check result to the ingester queue
+func (h *HealthCheckTaskHandler) enqueueIngest(ctx context.Context, ingesterPayload *IngesterTaskPayload) error {
 	opts := &queue.EnqueueOptions{
 		Queue:     "ingester",
 		MaxRetry:  3,
@@ -220,23 +392,210 @@ func (h *HealthCheckTaskHandler) ProcessTask(ctx context.Context, task *asynq.Ta
 
 	// Use EnqueueUnique to prevent duplicate ingester tasks
 	// The unique key includes monitor ID and start time to ensure each health check result is processed only once
-	uniqueKey := fmt.Sprintf("ingest:%s:%d", m.ID, tickResult.ExecutionResult.StartTime.UnixNano())
+	uniqueKey := fmt.Sprintf("ingest:%s:%d", ingesterPayload.MonitorID, ingesterPayload.StartTime.UnixNano())
 	ttl := 1 * time.Second // TTL to prevent duplicates during processing
 
 	_, err := h.queueService.EnqueueUnique(ctx, TaskTypeIngester, ingesterPayload, uniqueKey, ttl, opts)
 	if err != nil {
 		h.logger.Errorw("Failed to enqueue ingester task",
-			"monitor_id", payload.MonitorID,
+			"monitor_id", ingesterPayload.MonitorID,
 			"error", err,
 		)
 		return fmt.Errorf("failed to enqueue ingester task: %w", err)
 	}
+	return nil
+}
 
-	h.logger.Infow("Successfully enqueued result to ingester",
-		"monitor_id", payload.MonitorID,
-		"monitor_name", payload.MonitorName,
-		"duration", time.Since(start),
+// enqueueSkipped records a heartbeat for a check that did not run because the previous
+// check of the monitor is still running. The ingester keeps the monitor's status.
+func (h *HealthCheckTaskHandler) enqueueSkipped(ctx context.Context, payload *HealthCheckTaskPayload) error {
+	now := time.Now().UTC()
+	return h.enqueueIngest(ctx, &IngesterTaskPayload{
+		MonitorID:          payload.MonitorID,
+		MonitorName:        payload.MonitorName,
+		MonitorType:        payload.MonitorType,
+		MonitorInterval:    payload.Interval,
+		MonitorTimeout:     payload.Timeout,
+		MonitorMaxRetries:  payload.MaxRetries,
+		MonitorRetryInt:    payload.RetryInterval,
+		MonitorResendInt:   payload.ResendInterval,
+		MonitorWarmup:      payload.WarmupChecks,
+		MonitorActivatedAt: payload.ActivatedAt,
+		MonitorConfig:      payload.Config,
+		Status:             shared.MonitorStatusPending,
+		Message:            SkippedCheckMessage,
+		StartTime:          now,
+		EndTime:            now,
+		IsUnderMaintenance: payload.IsUnderMaintenance,
+		AlertsSuppressed:   payload.AlertsSuppressed,
+		ErrorCategory:      shared.ErrorCategorySkipped,
+	})
+}
+
+// applyResultExpression lets the monitor's result expression decide the final status and
+// message of a check. Maintenance results are left alone.
+func (h *HealthCheckTaskHandler) applyResultExpression(ctx context.Context, m *monitor.Model, tickResult *healthcheck.TickResult) {
+	if m.ResultExpression == "" || tickResult.IsUnderMaintenance {
+		return
+	}
+
+	result := tickResult.ExecutionResult
+	expression, err := h.expressions.Get(m.ResultExpression)
+	if err != nil {
+		// Expressions are compiled when the monitor is saved, this only happens to ones
+		// saved before an incompatible change
+		h.logger.Warnw("Invalid result expression", "monitor_id", m.ID, "error", err)
+		result.Status = shared.MonitorStatusDown
+		result.Message = err.Error()
+		return
+	}
+
+	previousStatus := result.Status
+	expression.Apply(ctx, result, tickResult.PingMs)
+	if result.Status != previousStatus {
+		h.logger.Debugw("Result expression changed the status",
+			"monitor_id", m.ID,
+			"from", previousStatus,
+			"to", result.Status,
+		)
+	}
+}
+
+// applyMessageTemplate replaces the executor's message with the monitor's up or down
+// message when it has one. Maintenance results keep their message.
+func (h *HealthCheckTaskHandler) applyMessageTemplate(m *monitor.Model, tickResult *healthcheck.TickResult) {
+	result := tickResult.ExecutionResult
+	template := m.DownMessage
+	if result.Status == shared.MonitorStatusUp {
+		template = m.UpMessage
+	}
+	if template == "" || tickResult.IsUnderMaintenance || result.Status == shared.MonitorStatusMaintenance {
+		return
+	}
+
+	// Templates are parsed when the monitor is saved, one failing anyway keeps the
+	// executor's message
+	compiled, err := h.messages.Get(template)
+	if err != nil {
+		h.logger.Warnw("Invalid message template", "monitor_id", m.ID, "error", err)
+		return
+	}
+	message, err := compiled.Render(m, result, tickResult.PingMs)
+	if err != nil {
+		h.logger.Warnw("Failed to render message template", "monitor_id", m.ID, "error", err)
+		return
+	}
+	result.Message = message
+}
+
+// trackLatency feeds up checks of monitors with a latency limit to the latency tracker and
+// publishes a HighLatency event once the average response time of the last LatencyChecks
+// checks went over the limit, and a recovered one once it is back under it. Checks with
+// suppressed alerts restart the average like maintenance checks.
+func (h *HealthCheckTaskHandler) trackLatency(ctx context.Context, m *monitor.Model, tickResult *healthcheck.TickResult, alertsSuppressed bool) {
+	if m.LatencyLimit <= 0 {
+		return
+	}
+	if tickResult.IsUnderMaintenance || alertsSuppressed || tickResult.ExecutionResult.Status != shared.MonitorStatusUp {
+		if err := h.latency.Reset(ctx, m.ID); err != nil {
+			h.logger.Warnw("Failed to reset latency", "monitor_id", m.ID, "error", err)
+		}
+		return
+	}
+
+	avgPing, change, err := h.latency.Observe(ctx, m.ID, tickResult.PingMs, m.LatencyLimit, m.LatencyChecks)
+	if err != nil {
+		h.logger.Warnw("Failed to track latency", "monitor_id", m.ID, "error", err)
+		return
+	}
+	if change == LatencyUnchanged || h.eventBus == nil {
+		return
+	}
+
+	if change == LatencyHigh {
+		h.logger.Infow("Sustained high latency detected",
+			"monitor_id", m.ID,
+			"monitor_name", m.Name,
+			"avg_ping_ms", avgPing,
+			"limit_ms", m.LatencyLimit,
+		)
+	} else {
+		h.logger.Infow("Latency back under the limit",
+			"monitor_id", m.ID,
+			"monitor_name", m.Name,
+			"avg_ping_ms", avgPing,
+			"limit_ms", m.LatencyLimit,
+		)
+	}
+
+	h.eventBus.Publish(events.Event{
+		Type: events.HighLatency,
+		Payload: &events.HighLatencyPayload{
+			MonitorID:   m.ID,
+			MonitorName: m.Name,
+			AvgPingMs:   avgPing,
+			LimitMs:     m.LatencyLimit,
+			Checks:      max(m.LatencyChecks, 1),
+			Recovered:   change == LatencyRecovered,
+			Time:        tickResult.ExecutionResult.StartTime,
+		},
+	})
+}
+
+// classifyProxyFailure probes the proxy of a failed check. When the proxy itself can't be
+// reached, the failure is categorized as proxy_down so it isn't mistaken for an outage of
+// the target. Only the HTTP executors send their checks through the proxy.
+func (h *HealthCheckTaskHandler) classifyProxyFailure(ctx context.Context, m *monitor.Model, proxyModel *proxy.Model, result *executor.Result) {
+	if proxyModel == nil || !strings.HasPrefix(m.Type, "http") {
+		return
+	}
+	if result.Status != shared.MonitorStatusDown {
+		h.proxies.MarkHealthy(proxyModel)
+		return
+	}
+
+	err := h.probeProxy(ctx, proxyModel)
+	if err == nil {
+		h.proxies.MarkHealthy(proxyModel)
+		return
+	}
+	h.proxies.MarkFailed(proxyModel)
+
+	h.logger.Infow("Check failed because its proxy is down",
+		"monitor_id", m.ID,
+		"proxy_host", proxyModel.Host,
+		"proxy_port", proxyModel.Port,
+		"error", err,
 	)
 
-	return nil
+	result.ErrorCategory = shared.ErrorCategoryProxyDown
+	result.Message = fmt.Sprintf("Proxy %s:%d is down: %v (%s)", proxyModel.Host, proxyModel.Port, err, result.Message)
+}
+
+// proxyForPayload returns the proxy the check goes through: the payload's proxy, or the
+// first of its fallback proxies that hasn't recently failed. Monitors without proxies use
+// the worker's DEFAULT_PROXY_URL unless they opted out with no_proxy.
+func (h *HealthCheckTaskHandler) proxyForPayload(payload *HealthCheckTaskPayload) *proxy.Model {
+	candidates := make([]*proxy.Model, 0, len(payload.FallbackProxies)+1)
+	if payload.Proxy != nil {
+		candidates = append(candidates, payload.Proxy.toModel())
+	}
+	for _, fallback := range payload.FallbackProxies {
+		candidates = append(candidates, fallback.toModel())
+	}
+	if len(candidates) > 0 {
+		selected := h.proxies.Select(candidates)
+		if selected != candidates[0] {
+			h.logger.Infow("Using fallback proxy",
+				"monitor_id", payload.MonitorID,
+				"proxy_host", selected.Host,
+				"proxy_port", selected.Port,
+			)
+		}
+		return selected
+	}
+	if payload.NoProxy {
+		return nil
+	}
+	return h.defaultProxy
 }
@@ -0,0 +1,224 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"peekaping/internal/config"
+	"peekaping/internal/modules/healthcheck"
+	"peekaping/internal/modules/healthcheck/executor"
+	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/proxy"
+	"peekaping/internal/modules/shared"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestProxyForPayload(t *testing.T) {
+	logger := zap.NewNop().Sugar()
+	handler := NewHealthCheckTaskHandler(nil, nil, nil, nil, nil, nil, &config.Config{DefaultProxyURL: "socks5://egress.internal:1080"}, logger)
+	require.NotNil(t, handler.defaultProxy)
+
+	t.Run("monitor without proxy uses the global one", func(t *testing.T) {
+		proxyModel := handler.proxyForPayload(&HealthCheckTaskPayload{MonitorID: "mon-1"})
+		require.NotNil(t, proxyModel)
+		assert.Equal(t, "socks5", proxyModel.Protocol)
+		assert.Equal(t, "egress.internal", proxyModel.Host)
+		assert.Equal(t, 1080, proxyModel.Port)
+	})
+
+	t.Run("monitor proxy takes precedence", func(t *testing.T) {
+		proxyModel := handler.proxyForPayload(&HealthCheckTaskPayload{
+			MonitorID: "mon-1",
+			Proxy:     &ProxyData{ID: "proxy-1", Protocol: "http", Host: "proxy.example.com", Port: 8080},
+		})
+		require.NotNil(t, proxyModel)
+		assert.Equal(t, "proxy-1", proxyModel.ID)
+		assert.Equal(t, "proxy.example.com", proxyModel.Host)
+	})
+
+	t.Run("no_proxy opts out of the global one", func(t *testing.T) {
+		proxyModel := handler.proxyForPayload(&HealthCheckTaskPayload{MonitorID: "mon-1", NoProxy: true})
+		assert.Nil(t, proxyModel)
+	})
+
+	t.Run("no global proxy configured", func(t *testing.T) {
+		h := NewHealthCheckTaskHandler(nil, nil, nil, nil, nil, nil, &config.Config{}, logger)
+		assert.Nil(t, h.proxyForPayload(&HealthCheckTaskPayload{MonitorID: "mon-1"}))
+	})
+
+	t.Run("invalid global proxy is ignored", func(t *testing.T) {
+		h := NewHealthCheckTaskHandler(nil, nil, nil, nil, nil, nil, &config.Config{DefaultProxyURL: "ftp://proxy:21"}, logger)
+		assert.Nil(t, h.proxyForPayload(&HealthCheckTaskPayload{MonitorID: "mon-1"}))
+	})
+}
+
+func TestProxyForPayload_SkipsUnhealthyProxy(t *testing.T) {
+	handler := NewHealthCheckTaskHandler(nil, nil, nil, nil, nil, nil, &config.Config{ProxyFailureCooldown: time.Minute}, zap.NewNop().Sugar())
+	handler.probeProxy = func(ctx context.Context, p *proxy.Model) error {
+		if p.ID == "proxy-1" {
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+
+	payload := &HealthCheckTaskPayload{
+		MonitorID: "mon-1",
+		Proxy:     &ProxyData{ID: "proxy-1", Protocol: "http", Host: "primary.internal", Port: 3128},
+		FallbackProxies: []*ProxyData{
+			{ID: "proxy-2", Protocol: "http", Host: "secondary.internal", Port: 3128},
+		},
+	}
+	mon := &monitor.Model{ID: "mon-1", Type: "http"}
+
+	first := handler.proxyForPayload(payload)
+	require.NotNil(t, first)
+	assert.Equal(t, "proxy-1", first.ID)
+
+	// The check fails because the primary proxy is unreachable
+	result := &executor.Result{Status: shared.MonitorStatusDown, Message: "proxyconnect tcp: connection refused"}
+	handler.classifyProxyFailure(context.Background(), mon, first, result)
+	assert.Equal(t, shared.ErrorCategoryProxyDown, result.ErrorCategory)
+
+	second := handler.proxyForPayload(payload)
+	require.NotNil(t, second)
+	assert.Equal(t, "proxy-2", second.ID)
+
+	// Checks through the healthy fallback keep using it
+	handler.classifyProxyFailure(context.Background(), mon, second, &executor.Result{Status: shared.MonitorStatusUp})
+	assert.Equal(t, "proxy-2", handler.proxyForPayload(payload).ID)
+}
+
+func TestClassifyProxyFailure(t *testing.T) {
+	proxyModel := &proxy.Model{ID: "proxy-1", Protocol: "http", Host: "proxy.example.com", Port: 3128}
+	proxyDown := func(ctx context.Context, p *proxy.Model) error { return errors.New("connection refused") }
+	proxyUp := func(ctx context.Context, p *proxy.Model) error { return nil }
+
+	tests := []struct {
+		name             string
+		monitorType      string
+		proxyModel       *proxy.Model
+		status           shared.MonitorStatus
+		probe            func(ctx context.Context, p *proxy.Model) error
+		expectedCategory string
+	}{
+		{"failure through an unreachable proxy is a proxy outage", "http", proxyModel, shared.MonitorStatusDown, proxyDown, shared.ErrorCategoryProxyDown},
+		{"keyword checks use the proxy as well", "http-keyword", proxyModel, shared.MonitorStatusDown, proxyDown, shared.ErrorCategoryProxyDown},
+		{"failure with a reachable proxy is a target outage", "http", proxyModel, shared.MonitorStatusDown, proxyUp, shared.ErrorCategoryNetwork},
+		{"up checks are left alone", "http", proxyModel, shared.MonitorStatusUp, proxyDown, shared.ErrorCategoryNetwork},
+		{"checks without a proxy are left alone", "http", nil, shared.MonitorStatusDown, proxyDown, shared.ErrorCategoryNetwork},
+		{"executors not using the proxy are left alone", "tcp", proxyModel, shared.MonitorStatusDown, proxyDown, shared.ErrorCategoryNetwork},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewHealthCheckTaskHandler(nil, nil, nil, nil, nil, nil, &config.Config{}, zap.NewNop().Sugar())
+			handler.probeProxy = tt.probe
+
+			result := &executor.Result{Status: tt.status, Message: "dial tcp: i/o timeout", ErrorCategory: shared.ErrorCategoryNetwork}
+			handler.classifyProxyFailure(context.Background(), &monitor.Model{ID: "mon-1", Type: tt.monitorType}, tt.proxyModel, result)
+
+			assert.Equal(t, tt.expectedCategory, result.ErrorCategory)
+			if tt.expectedCategory == shared.ErrorCategoryProxyDown {
+				assert.Contains(t, result.Message, "Proxy proxy.example.com:3128 is down: connection refused")
+			}
+		})
+	}
+}
+
+func TestApplyResultExpression(t *testing.T) {
+	tests := []struct {
+		name               string
+		expression         string
+		ping               int
+		isUnderMaintenance bool
+		expectedStatus     shared.MonitorStatus
+	}{
+		{"no expression keeps the result", "", 500, false, shared.MonitorStatusUp},
+		{"fast response with keyword stays up", `ping < 100 && body.contains("ok")`, 50, false, shared.MonitorStatusUp},
+		{"slow response goes down", `ping < 100 && body.contains("ok")`, 500, false, shared.MonitorStatusDown},
+		{"maintenance is left alone", `ping < 100`, 500, true, shared.MonitorStatusMaintenance},
+		{"invalid expression goes down", `ping <`, 50, false, shared.MonitorStatusDown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewHealthCheckTaskHandler(nil, nil, nil, nil, nil, nil, &config.Config{}, zap.NewNop().Sugar())
+
+			status := shared.MonitorStatusUp
+			if tt.isUnderMaintenance {
+				status = shared.MonitorStatusMaintenance
+			}
+			tickResult := &healthcheck.TickResult{
+				ExecutionResult:    &executor.Result{Status: status, Message: "200 - 200 OK", ResponseBody: "ok"},
+				PingMs:             tt.ping,
+				IsUnderMaintenance: tt.isUnderMaintenance,
+			}
+			handler.applyResultExpression(context.Background(), &monitor.Model{ID: "mon-1", ResultExpression: tt.expression}, tickResult)
+
+			assert.Equal(t, tt.expectedStatus, tickResult.ExecutionResult.Status)
+		})
+	}
+}
+
+func TestProcessTask_MessageTemplates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"version": "1.2.3"}`)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name            string
+		path            string
+		upMessage       string
+		downMessage     string
+		expectedStatus  shared.MonitorStatus
+		expectedMessage string
+	}{
+		{"up message replaces the executor message", "/up", "API {{ json.version }} answered {{ status }} to {{ name }}", "unused", shared.MonitorStatusUp, "API 1.2.3 answered up to Api"},
+		{"down message can include the executor message", "/down", "unused", "Api failed: {{ message }}", shared.MonitorStatusDown, "Api failed: HTTP request failed with status: 503"},
+		{"executor message is kept without templates", "/up", "", "", shared.MonitorStatusUp, "200 - 200 OK"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := zap.NewNop().Sugar()
+			registry := executor.NewExecutorRegistry(logger, &config.Config{})
+			ingest := &recordingQueue{}
+			handler := NewHealthCheckTaskHandler(registry, nil, healthcheck.NewHealthCheck(nil, registry, logger), ingest, nil, nil, &config.Config{}, logger)
+
+			payload, err := json.Marshal(HealthCheckTaskPayload{
+				MonitorID:   "mon-1",
+				MonitorName: "Api",
+				MonitorType: "http",
+				Interval:    60,
+				Timeout:     5,
+				UpMessage:   tt.upMessage,
+				DownMessage: tt.downMessage,
+				ScheduledAt: time.Now().UTC(),
+				Config: fmt.Sprintf(`{"url": %q, "method": "GET", "encoding": "json", "accepted_statuscodes": ["2XX"], "authMethod": "none"}`,
+					server.URL+tt.path),
+			})
+			require.NoError(t, err)
+			require.NoError(t, handler.ProcessTask(context.Background(), asynq.NewTask(TaskTypeHealthCheck, payload)))
+
+			results := ingest.results()
+			require.Len(t, results, 1)
+			assert.Equal(t, tt.expectedStatus, results[0].Status)
+			assert.Equal(t, tt.expectedMessage, results[0].Message)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// latencyPingsKeyPrefix is the prefix of the Redis lists holding the latest pings of a monitor
+	latencyPingsKeyPrefix = "peekaping:latency:pings:"
+	// latencyAlertKeyPrefix is the prefix of the Redis keys set while a monitor's latency is high
+	latencyAlertKeyPrefix = "peekaping:latency:alert:"
+	// latencyStateTTL forgets the latency of monitors that are no longer checked
+	latencyStateTTL = 24 * time.Hour
+)
+
+// LatencyChange is what a check changed about the latency of a monitor
+type LatencyChange int
+
+const (
+	// LatencyUnchanged is reported while the latency stays on the same side of the limit
+	LatencyUnchanged LatencyChange = iota
+	// LatencyHigh is reported by the check taking the average over the limit
+	LatencyHigh
+	// LatencyRecovered is reported by the check bringing a high average back to the limit
+	LatencyRecovered
+)
+
+// LatencyTracker follows the response times of each monitor across checks and detects
+// sustained high latency. Pings are kept in Redis, so the average spans the checks of all
+// worker instances.
+type LatencyTracker struct {
+	client *redis.Client
+}
+
+// NewLatencyTracker creates a latency tracker keeping its state in the given Redis
+func NewLatencyTracker(client *redis.Client) *LatencyTracker {
+	return &LatencyTracker{client: client}
+}
+
+// Observe records the ping of an up check and returns the rolling average of the monitor's
+// last checks pings. The average going over limitMs reports LatencyHigh once, its coming
+// back to the limit or under LatencyRecovered. Nothing is reported before checks pings were
+// recorded.
+func (t *LatencyTracker) Observe(ctx context.Context, monitorID string, pingMs, limitMs, checks int) (int, LatencyChange, error) {
+	checks = max(checks, 1)
+	key := latencyPingsKeyPrefix + monitorID
+
+	pipe := t.client.TxPipeline()
+	pipe.LPush(ctx, key, pingMs)
+	pipe.LTrim(ctx, key, 0, int64(checks-1))
+	pipe.Expire(ctx, key, latencyStateTTL)
+	latest := pipe.LRange(ctx, key, 0, -1)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, LatencyUnchanged, fmt.Errorf("failed to record ping: %w", err)
+	}
+
+	pings := latest.Val()
+	if len(pings) < checks {
+		return 0, LatencyUnchanged, nil
+	}
+	total := 0
+	for _, ping := range pings {
+		value, err := strconv.Atoi(ping)
+		if err != nil {
+			return 0, LatencyUnchanged, fmt.Errorf("invalid ping %q: %w", ping, err)
+		}
+		total += value
+	}
+	avg := total / len(pings)
+
+	// The flag is set and cleared atomically, so each change is reported by one check
+	// whichever worker ran it
+	alertKey := latencyAlertKeyPrefix + monitorID
+	if avg > limitMs {
+		raised, err := t.client.SetNX(ctx, alertKey, 1, latencyStateTTL).Result()
+		if err != nil {
+			return avg, LatencyUnchanged, fmt.Errorf("failed to raise latency alert: %w", err)
+		}
+		if !raised {
+			t.client.Expire(ctx, alertKey, latencyStateTTL)
+			return avg, LatencyUnchanged, nil
+		}
+		return avg, LatencyHigh, nil
+	}
+
+	cleared, err := t.client.Del(ctx, alertKey).Result()
+	if err != nil {
+		return avg, LatencyUnchanged, fmt.Errorf("failed to clear latency alert: %w", err)
+	}
+	if cleared > 0 {
+		return avg, LatencyRecovered, nil
+	}
+	return avg, LatencyUnchanged, nil
+}
+
+// Reset forgets the pings of a monitor, used for checks that are not up. A raised alert
+// stays raised until the average of the next up checks is back under the limit.
+func (t *LatencyTracker) Reset(ctx context.Context, monitorID string) error {
+	if err := t.client.Del(ctx, latencyPingsKeyPrefix+monitorID).Err(); err != nil {
+		return fmt.Errorf("failed to reset latency: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"peekaping/internal/config"
+	"peekaping/internal/modules/events"
+	"peekaping/internal/modules/healthcheck"
+	"peekaping/internal/modules/healthcheck/executor"
+	"peekaping/internal/modules/monitor"
+	"peekaping/internal/modules/shared"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// MockEventBus is a mock implementation of events.EventBus
+type MockEventBus struct {
+	mock.Mock
+}
+
+func (m *MockEventBus) Subscribe(eventType events.EventType, handler events.EventHandler) {
+	m.Called(eventType, handler)
+}
+
+func (m *MockEventBus) Publish(event events.Event) {
+	m.Called(event)
+}
+
+func (m *MockEventBus) Close() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func TestLatencyTracker_Observe(t *testing.T) {
+	tests := []struct {
+		name    string
+		pings   []int
+		changes map[int]LatencyChange
+		avgs    map[int]int
+	}{
+		{
+			name:  "high average alerts once",
+			pings: []int{100, 600, 700, 800, 900, 900},
+			// averages of 100, 600, 700, then 600, 700, 800
+			changes: map[int]LatencyChange{3: LatencyHigh},
+			avgs:    map[int]int{3: 700},
+		},
+		{
+			name:    "single spike under the average does not alert",
+			pings:   []int{100, 1000, 100, 100, 100},
+			changes: map[int]LatencyChange{},
+		},
+		{
+			name:    "one fast check doesn't hide slow ones",
+			pings:   []int{600, 600, 100, 900, 600},
+			changes: map[int]LatencyChange{3: LatencyHigh},
+			avgs:    map[int]int{3: 533},
+		},
+		{
+			name:    "average back under the limit recovers",
+			pings:   []int{800, 800, 800, 100, 100},
+			changes: map[int]LatencyChange{2: LatencyHigh, 4: LatencyRecovered},
+			avgs:    map[int]int{4: 333},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := NewLatencyTracker(newTestRedis(t))
+
+			for i, ping := range tt.pings {
+				avg, change, err := tracker.Observe(context.Background(), "monitor-1", ping, 500, 3)
+				require.NoError(t, err)
+				assert.Equal(t, tt.changes[i], change, "check %d", i)
+				if expected, ok := tt.avgs[i]; ok {
+					assert.Equal(t, expected, avg, "check %d", i)
+				}
+			}
+		})
+	}
+}
+
+func TestLatencyTracker_SharedAcrossWorkers(t *testing.T) {
+	ctx := context.Background()
+	client := newTestRedis(t)
+	first, second := NewLatencyTracker(client), NewLatencyTracker(client)
+
+	_, change, err := first.Observe(ctx, "monitor-1", 800, 500, 2)
+	require.NoError(t, err)
+	assert.Equal(t, LatencyUnchanged, change)
+	_, change, err = second.Observe(ctx, "monitor-1", 800, 500, 2)
+	require.NoError(t, err)
+	assert.Equal(t, LatencyHigh, change)
+
+	// The alert is raised once, whichever worker runs the next check
+	_, change, _ = first.Observe(ctx, "monitor-1", 800, 500, 2)
+	assert.Equal(t, LatencyUnchanged, change)
+}
+
+func TestLatencyTracker_ResetClearsPings(t *testing.T) {
+	ctx := context.Background()
+	tracker := NewLatencyTracker(newTestRedis(t))
+
+	tracker.Observe(ctx, "monitor-1", 800, 500, 2)
+	require.NoError(t, tracker.Reset(ctx, "monitor-1"))
+	_, change, err := tracker.Observe(ctx, "monitor-1", 800, 500, 2)
+
+	require.NoError(t, err)
+	assert.Equal(t, LatencyUnchanged, change)
+}
+
+func TestTrackLatency_PublishesHighLatencyEvent(t *testing.T) {
+	eventBus := &MockEventBus{}
+	h := NewHealthCheckTaskHandler(nil, nil, nil, nil, eventBus, newTestRedis(t), &config.Config{}, zap.NewNop().Sugar())
+	m := &monitor.Model{ID: "monitor-1", Name: "API", LatencyLimit: 500, LatencyChecks: 2}
+
+	var published []events.Event
+	eventBus.On("Publish", mock.Anything).Run(func(args mock.Arguments) {
+		published = append(published, args.Get(0).(events.Event))
+	})
+
+	tick := func(status shared.MonitorStatus, ping int) {
+		h.trackLatency(context.Background(), m, &healthcheck.TickResult{
+			ExecutionResult: &executor.Result{Status: status, StartTime: time.Now()},
+			PingMs:          ping,
+		}, false)
+	}
+
+	tick(shared.MonitorStatusUp, 900)
+	tick(shared.MonitorStatusDown, 900)
+	tick(shared.MonitorStatusUp, 900)
+	assert.Empty(t, published, "a down check restarts the average")
+
+	tick(shared.MonitorStatusUp, 700)
+	if assert.Len(t, published, 1) {
+		assert.Equal(t, events.HighLatency, published[0].Type)
+		payload := published[0].Payload.(*events.HighLatencyPayload)
+		assert.Equal(t, "monitor-1", payload.MonitorID)
+		assert.Equal(t, 800, payload.AvgPingMs)
+		assert.Equal(t, 500, payload.LimitMs)
+		assert.Equal(t, 2, payload.Checks)
+		assert.False(t, payload.Recovered)
+	}
+
+	tick(shared.MonitorStatusUp, 100)
+	tick(shared.MonitorStatusUp, 100)
+	if assert.Len(t, published, 2, "recovery is sent once") {
+		payload := published[1].Payload.(*events.HighLatencyPayload)
+		assert.True(t, payload.Recovered)
+		assert.Equal(t, 400, payload.AvgPingMs)
+	}
+}
@@ -0,0 +1,77 @@
+package worker
+
+import (
+	"fmt"
+	"peekaping/internal/modules/proxy"
+	"sync"
+	"time"
+)
+
+// ProxySelector picks the proxy of a check among a monitor's proxies. Proxies that failed a
+// check are skipped for a cooldown in favor of the next one in the list. State is kept in
+// memory, so each worker process learns about failing proxies from its own checks.
+type ProxySelector struct {
+	mu          sync.Mutex
+	cooldown    time.Duration
+	failedUntil map[string]time.Time
+	now         func() time.Time
+}
+
+// NewProxySelector creates a selector skipping failed proxies for cooldown
+func NewProxySelector(cooldown time.Duration) *ProxySelector {
+	return &ProxySelector{
+		cooldown:    cooldown,
+		failedUntil: make(map[string]time.Time),
+		now:         time.Now,
+	}
+}
+
+// Select returns the first proxy that hasn't failed within the cooldown. When every proxy
+// recently failed, the one whose cooldown ends first is tried again.
+func (s *ProxySelector) Select(candidates []*proxy.Model) *proxy.Model {
+	if len(candidates) <= 1 {
+		if len(candidates) == 0 {
+			return nil
+		}
+		return candidates[0]
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	var soonest *proxy.Model
+	var soonestUntil time.Time
+	for _, candidate := range candidates {
+		until, failed := s.failedUntil[proxyKey(candidate)]
+		if !failed || !now.Before(until) {
+			return candidate
+		}
+		if soonest == nil || until.Before(soonestUntil) {
+			soonest, soonestUntil = candidate, until
+		}
+	}
+	return soonest
+}
+
+// MarkFailed skips the proxy for the cooldown
+func (s *ProxySelector) MarkFailed(p *proxy.Model) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failedUntil[proxyKey(p)] = s.now().Add(s.cooldown)
+}
+
+// MarkHealthy makes the proxy eligible again, e.g. after a check went through it
+func (s *ProxySelector) MarkHealthy(p *proxy.Model) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.failedUntil, proxyKey(p))
+}
+
+// proxyKey identifies a proxy, the DEFAULT_PROXY_URL proxy has no ID
+func proxyKey(p *proxy.Model) string {
+	if p.ID != "" {
+		return p.ID
+	}
+	return fmt.Sprintf("%s:%d", p.Host, p.Port)
+}
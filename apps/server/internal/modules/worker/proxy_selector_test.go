@@ -0,0 +1,67 @@
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"peekaping/internal/modules/proxy"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProxySelector(t *testing.T) {
+	primary := &proxy.Model{ID: "proxy-1", Host: "primary.internal", Port: 3128}
+	secondary := &proxy.Model{ID: "proxy-2", Host: "secondary.internal", Port: 3128}
+	tertiary := &proxy.Model{ID: "proxy-3", Host: "tertiary.internal", Port: 3128}
+	candidates := []*proxy.Model{primary, secondary, tertiary}
+
+	now := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	newSelector := func() *ProxySelector {
+		s := NewProxySelector(time.Minute)
+		s.now = func() time.Time { return now }
+		return s
+	}
+
+	t.Run("healthy proxies are used in order", func(t *testing.T) {
+		assert.Same(t, primary, newSelector().Select(candidates))
+	})
+
+	t.Run("failed proxy is skipped for the cooldown", func(t *testing.T) {
+		s := newSelector()
+		s.MarkFailed(primary)
+		assert.Same(t, secondary, s.Select(candidates))
+
+		s.MarkFailed(secondary)
+		assert.Same(t, tertiary, s.Select(candidates))
+	})
+
+	t.Run("proxy is used again after the cooldown", func(t *testing.T) {
+		s := newSelector()
+		s.MarkFailed(primary)
+		s.now = func() time.Time { return now.Add(time.Minute) }
+		assert.Same(t, primary, s.Select(candidates))
+	})
+
+	t.Run("healthy check clears the failure", func(t *testing.T) {
+		s := newSelector()
+		s.MarkFailed(primary)
+		s.MarkHealthy(primary)
+		assert.Same(t, primary, s.Select(candidates))
+	})
+
+	t.Run("all failed retries the one failed longest ago", func(t *testing.T) {
+		s := newSelector()
+		s.MarkFailed(secondary)
+		s.now = func() time.Time { return now.Add(10 * time.Second) }
+		s.MarkFailed(primary)
+		s.MarkFailed(tertiary)
+		assert.Same(t, secondary, s.Select(candidates))
+	})
+
+	t.Run("single proxy is always used", func(t *testing.T) {
+		s := newSelector()
+		s.MarkFailed(primary)
+		assert.Same(t, primary, s.Select([]*proxy.Model{primary}))
+		assert.Nil(t, s.Select(nil))
+	})
+}
@@ -0,0 +1,132 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"peekaping/internal/modules/healthcheck/executor"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// SkippedCheckMessage is the heartbeat message of a check skipped because the previous
+// check of a serialized monitor is still running
+const SkippedCheckMessage = "Check skipped, previous still running"
+
+// checkLockKeyPrefix is the prefix of the Redis keys locking the checks of serialized monitors
+const checkLockKeyPrefix = "peekaping:check_lock:"
+
+// checkLockGrace is added to the monitor timeout and the hard timeout grace for the lock TTL,
+// so a lock only expires on its own when its worker died or an abandoned check is stuck
+const checkLockGrace = 30 * time.Second
+
+// unlockScript deletes the lock only when it still holds the owner's token, a lock that
+// expired and was taken by another check is left alone
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// CheckLocks tracks the serialized monitors with a check running. Locks are kept in Redis,
+// so checks are serialized among all worker instances.
+type CheckLocks struct {
+	client *redis.Client
+}
+
+// NewCheckLocks creates check locks kept in the given Redis
+func NewCheckLocks(client *redis.Client) *CheckLocks {
+	return &CheckLocks{client: client}
+}
+
+// TryLock marks a check of the monitor as running for at most ttl. It returns the token
+// releasing the lock, and false when a check already runs.
+func (l *CheckLocks) TryLock(ctx context.Context, monitorID string, ttl time.Duration) (string, bool, error) {
+	token := uuid.NewString()
+	ok, err := l.client.SetNX(ctx, checkLockKeyPrefix+monitorID, token, ttl).Result()
+	if err != nil {
+		return "", false, fmt.Errorf("failed to lock check: %w", err)
+	}
+	return token, ok, nil
+}
+
+// Unlock marks the check of the monitor as finished when the lock still holds token
+func (l *CheckLocks) Unlock(ctx context.Context, monitorID string, token string) error {
+	if err := unlockScript.Run(ctx, l.client, []string{checkLockKeyPrefix + monitorID}, token).Err(); err != nil {
+		return fmt.Errorf("failed to unlock check: %w", err)
+	}
+	return nil
+}
+
+// checkLock is the lock held by one check. Release frees it once no executor call of the
+// check runs, so a call abandoned by the hard timeout keeps the monitor locked until it
+// returns or the lock expires.
+type checkLock struct {
+	locks     *CheckLocks
+	monitorID string
+	token     string
+	onError   func(error)
+
+	mu        sync.Mutex
+	calls     int
+	releasing bool
+	unlock    sync.Once
+}
+
+// wrap counts the calls of exec as running calls of the check
+func (l *checkLock) wrap(exec executor.Executor) executor.Executor {
+	return &lockedExecutor{Executor: exec, lock: l}
+}
+
+// Release frees the lock now, or when the running executor call returns
+func (l *checkLock) Release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.releasing = true
+	if l.calls == 0 {
+		l.release()
+	}
+}
+
+func (l *checkLock) begin() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.calls++
+}
+
+func (l *checkLock) end() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.calls--
+	if l.calls == 0 && l.releasing {
+		l.release()
+	}
+}
+
+func (l *checkLock) release() {
+	l.unlock.Do(func() {
+		// The task's context may be done, the lock must still be freed
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := l.locks.Unlock(ctx, l.monitorID, l.token); err != nil && l.onError != nil {
+			l.onError(err)
+		}
+	})
+}
+
+type lockedExecutor struct {
+	executor.Executor
+	lock *checkLock
+}
+
+func (e *lockedExecutor) Execute(ctx context.Context, m *executor.Monitor, proxyModel *executor.Proxy) *executor.Result {
+	e.lock.begin()
+	defer e.lock.end()
+	return e.Executor.Execute(ctx, m, proxyModel)
+}
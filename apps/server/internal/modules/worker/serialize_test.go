@@ -0,0 +1,207 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"peekaping/internal/config"
+	"peekaping/internal/modules/healthcheck"
+	"peekaping/internal/modules/healthcheck/executor"
+	"peekaping/internal/modules/queue"
+	"peekaping/internal/modules/shared"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// recordingQueue keeps the ingester payloads enqueued by the handler
+type recordingQueue struct {
+	queue.Service
+	mu       sync.Mutex
+	payloads []*IngesterTaskPayload
+}
+
+func (q *recordingQueue) EnqueueUnique(ctx context.Context, taskType string, payload interface{}, uniqueKey string, ttl time.Duration, opts *queue.EnqueueOptions) (*queue.TaskInfo, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.payloads = append(q.payloads, payload.(*IngesterTaskPayload))
+	return &queue.TaskInfo{}, nil
+}
+
+func (q *recordingQueue) results() []*IngesterTaskPayload {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]*IngesterTaskPayload(nil), q.payloads...)
+}
+
+// newTestRedis creates a Redis client of a miniredis instance closed with the test
+func newTestRedis(t *testing.T) *redis.Client {
+	mr := miniredis.RunT(t)
+	return redis.NewClient(&redis.Options{Addr: mr.Addr()})
+}
+
+func TestCheckLocks(t *testing.T) {
+	ctx := context.Background()
+	client := newTestRedis(t)
+	locks := NewCheckLocks(client)
+
+	token, ok, err := locks.TryLock(ctx, "mon-1", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// Another worker shares the locks through Redis
+	other := NewCheckLocks(client)
+	_, ok, err = other.TryLock(ctx, "mon-1", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	_, ok, err = other.TryLock(ctx, "mon-2", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// Only the owner releases the lock
+	require.NoError(t, other.Unlock(ctx, "mon-1", "not-the-owner"))
+	_, ok, _ = other.TryLock(ctx, "mon-1", time.Minute)
+	assert.False(t, ok)
+
+	require.NoError(t, locks.Unlock(ctx, "mon-1", token))
+	_, ok, err = other.TryLock(ctx, "mon-1", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+// blockingExecutor runs until released, ignoring the context like a stuck driver
+type blockingExecutor struct {
+	executor.Executor
+	release chan struct{}
+}
+
+func (e *blockingExecutor) Execute(ctx context.Context, m *executor.Monitor, proxyModel *executor.Proxy) *executor.Result {
+	<-e.release
+	return &executor.Result{Status: shared.MonitorStatusUp}
+}
+
+func TestCheckLock_AbandonedCheck(t *testing.T) {
+	ctx := context.Background()
+	locks := NewCheckLocks(newTestRedis(t))
+	token, ok, err := locks.TryLock(ctx, "mon-1", time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	stuck := &blockingExecutor{release: make(chan struct{})}
+	lock := &checkLock{locks: locks, monitorID: "mon-1", token: token}
+	exec := executor.WithHardTimeout("stuck", lock.wrap(stuck), 20*time.Millisecond, zap.NewNop().Sugar())
+
+	result := exec.Execute(ctx, &executor.Monitor{ID: "mon-1"}, nil)
+	assert.Equal(t, shared.MonitorStatusDown, result.Status)
+	lock.Release()
+
+	// The abandoned call still runs, the monitor stays locked
+	_, ok, err = locks.TryLock(ctx, "mon-1", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// It is released once the call returns
+	close(stuck.release)
+	assert.Eventually(t, func() bool {
+		_, ok, err := locks.TryLock(ctx, "mon-1", time.Minute)
+		return err == nil && ok
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestProcessTask_SerializedChecks(t *testing.T) {
+	tests := []struct {
+		name            string
+		serialize       bool
+		expectedChecks  int32
+		expectedSkipped int
+	}{
+		{"overlapping check of a serialized monitor is skipped", true, 1, 1},
+		{"overlapping checks run concurrently by default", false, 2, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var checks atomic.Int32
+			started := make(chan struct{}, 2)
+			release := make(chan struct{})
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				checks.Add(1)
+				started <- struct{}{}
+				<-release
+				fmt.Fprint(w, "ok")
+			}))
+			defer server.Close()
+
+			logger := zap.NewNop().Sugar()
+			registry := executor.NewExecutorRegistry(logger, &config.Config{})
+			ingest := &recordingQueue{}
+			handler := NewHealthCheckTaskHandler(registry, nil, healthcheck.NewHealthCheck(nil, registry, logger), ingest, nil, newTestRedis(t), &config.Config{}, logger)
+
+			payload, err := json.Marshal(HealthCheckTaskPayload{
+				MonitorID:   "mon-1",
+				MonitorName: "Database",
+				MonitorType: "http",
+				Interval:    60,
+				Timeout:     5,
+				Serialize:   tt.serialize,
+				ScheduledAt: time.Now().UTC(),
+				Config: fmt.Sprintf(`{"url": %q, "method": "GET", "encoding": "json", "accepted_statuscodes": ["2XX"], "authMethod": "none"}`,
+					server.URL),
+			})
+			require.NoError(t, err)
+			task := asynq.NewTask(TaskTypeHealthCheck, payload)
+
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, handler.ProcessTask(context.Background(), task))
+			}()
+			<-started
+
+			// The second check is scheduled while the first one still runs
+			second := make(chan error, 1)
+			go func() { second <- handler.ProcessTask(context.Background(), task) }()
+			if tt.serialize {
+				require.NoError(t, <-second)
+				close(release)
+			} else {
+				<-started
+				close(release)
+				require.NoError(t, <-second)
+			}
+			wg.Wait()
+
+			assert.Equal(t, tt.expectedChecks, checks.Load())
+
+			results := ingest.results()
+			require.Len(t, results, 2)
+			skipped := 0
+			for _, result := range results {
+				if result.ErrorCategory == shared.ErrorCategorySkipped {
+					skipped++
+					assert.Equal(t, SkippedCheckMessage, result.Message)
+				} else {
+					assert.Equal(t, shared.MonitorStatusUp, result.Status)
+				}
+			}
+			assert.Equal(t, tt.expectedSkipped, skipped)
+
+			// The lock is released once the check finished
+			_, ok, err := handler.running.TryLock(context.Background(), "mon-1", time.Minute)
+			require.NoError(t, err)
+			assert.True(t, ok)
+		})
+	}
+}
@@ -5,23 +5,29 @@ import (
 	_ "peekaping/docs"
 	"peekaping/internal/config"
 	"peekaping/internal/modules/api_key"
+	"peekaping/internal/modules/audit_log"
 	"peekaping/internal/modules/auth"
+	"peekaping/internal/modules/backup"
 	"peekaping/internal/modules/badge"
 	"peekaping/internal/modules/healthcheck"
 	"peekaping/internal/modules/heartbeat"
 	"peekaping/internal/modules/maintenance"
+	"peekaping/internal/modules/maintenance_template"
 	"peekaping/internal/modules/monitor"
 	"peekaping/internal/modules/notification_channel"
 	"peekaping/internal/modules/proxy"
 	"peekaping/internal/modules/queue"
 	"peekaping/internal/modules/setting"
 	"peekaping/internal/modules/status_page"
+	"peekaping/internal/modules/status_page_subscriber"
 	"peekaping/internal/modules/tag"
+	"peekaping/internal/modules/uptime_kuma"
 	"peekaping/internal/modules/websocket"
 	"peekaping/internal/version"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -72,14 +78,26 @@ func ProvideServer(
 	queueService queue.Service,
 	maintenanceRoute *maintenance.Route,
 	maintenanceController *maintenance.Controller,
+	maintenanceTemplateRoute *maintenance_template.Route,
+	maintenanceTemplateController *maintenance_template.Controller,
 	statusPageRoute *status_page.Route,
 	statusPageController *status_page.Controller,
+	statusPageSubscriberRoute *status_page_subscriber.Route,
+	statusPageSubscriberController *status_page_subscriber.Controller,
 	tagRoute *tag.Route,
 	tagController *tag.Controller,
 	badgeRoute *badge.Route,
 	badgeController *badge.Controller,
 	apiKeyRoute *api_key.Route,
 	apiKeyController *api_key.Controller,
+	auditLogRoute *audit_log.Route,
+	auditLogController *audit_log.Controller,
+	queueRoute *queue.Route,
+	queueController *queue.Controller,
+	uptimeKumaRoute *uptime_kuma.Route,
+	uptimeKumaController *uptime_kuma.Controller,
+	backupRoute *backup.Route,
+	backupController *backup.Controller,
 ) *Server {
 	// Initialize server based on mode
 	var server *gin.Engine
@@ -99,7 +117,7 @@ func ProvideServer(
 	server.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
-		AllowHeaders:     []string{"Origin", "X-Requested-With", "Content-Type", "Accept", "Authorization"},
+		AllowHeaders:     []string{"Origin", "X-Requested-With", "Content-Type", "Accept", "Authorization", "X-Status-Page-Password", "X-Backup-Passphrase"},
 		ExposeHeaders:    []string{"Authorization"},
 		AllowCredentials: true,
 	}))
@@ -107,6 +125,9 @@ func ProvideServer(
 	// server.Use(LogMiddleware(logger))
 
 	server.GET("/health", healthHandler)
+	if cfg.MetricsEnabled {
+		server.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	}
 	router := server.Group("/api/v1")
 	router.GET("/health", healthHandler)
 	router.GET("/version", versionHandler)
@@ -118,10 +139,16 @@ func ProvideServer(
 	proxyRoute.ConnectRoute(router, proxyController)
 	settingRoute.ConnectRoute(router, settingController)
 	maintenanceRoute.ConnectRoute(router, maintenanceController)
+	maintenanceTemplateRoute.ConnectRoute(router, maintenanceTemplateController)
 	statusPageRoute.ConnectRoute(router, statusPageController)
+	statusPageSubscriberRoute.ConnectRoute(router, statusPageSubscriberController)
 	tagRoute.ConnectRoute(router, tagController)
 	badgeRoute.ConnectRoute(router, badgeController)
 	apiKeyRoute.ConnectRoute(router, apiKeyController)
+	auditLogRoute.ConnectRoute(router, auditLogController)
+	queueRoute.ConnectRoute(router, queueController)
+	uptimeKumaRoute.ConnectRoute(router, uptimeKumaController)
+	backupRoute.ConnectRoute(router, backupController)
 
 	// Register push endpoint
 	healthcheck.RegisterPushEndpoint(router, monitorService, heartbeatService, queueService, logger)
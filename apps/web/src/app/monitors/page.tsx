@@ -173,12 +173,19 @@ const MonitorsPage = () => {
       );
     };
 
+    // Sent instead of single heartbeats when the server batches broadcasts
+    const handleHeartbeats = (heartbeats: HeartbeatModel[]) => {
+      heartbeats.forEach(handleHeartbeat);
+    };
+
     socket.on(`${roomName}:heartbeat`, handleHeartbeat);
+    socket.on(`${roomName}:heartbeats`, handleHeartbeats);
     socket.emit("join_room", roomName);
     console.log("Subscribed to heartbeat", roomName);
 
     return () => {
       socket.off(`${roomName}:heartbeat`, handleHeartbeat);
+      socket.off(`${roomName}:heartbeats`, handleHeartbeats);
       console.log("Unsubscribed from heartbeat", `${roomName}:heartbeat`);
 
       if (socketStatus === WebSocketStatus.CONNECTED) {
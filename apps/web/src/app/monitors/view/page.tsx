@@ -321,14 +321,21 @@ const MonitorPage = () => {
       refetchLastImportantHeartbeat();
     };
 
+    // Sent instead of single heartbeats when the server batches broadcasts
+    const handleHeartbeats = (heartbeats: HeartbeatModel[]) => {
+      heartbeats.forEach(handleHeartbeat);
+    };
+
     if (socketStatus === WebSocketStatus.CONNECTED) {
       socket.on(`${roomName}:heartbeat`, handleHeartbeat);
+      socket.on(`${roomName}:heartbeats`, handleHeartbeats);
       socket.emit("join_room", roomName);
       console.log("Subscribed to heartbeat", roomName);
     }
 
     return () => {
       socket.off(`${roomName}:heartbeat`, handleHeartbeat);
+      socket.off(`${roomName}:heartbeats`, handleHeartbeats);
       if (socketStatus === WebSocketStatus.CONNECTED) {
         socket.emit("leave_room", roomName);
       }